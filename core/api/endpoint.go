@@ -1,8 +1,62 @@
 package api
 
+import "strings"
+
 // Endpoint represents an API endpoint.
 type Endpoint struct {
 	URL         string
 	Method      string
 	Middlewares []Middleware
+	// DisableAutoHead disables answering HEAD requests to the URL of a GET
+	// endpoint by running the endpoint without writing the response body.
+	DisableAutoHead bool
+	// DisableAutoOptions disables answering OPTIONS requests to the URL of
+	// the endpoint with the allowed methods.
+	DisableAutoOptions bool
+	// OptionsMiddlewares wrap the automatic OPTIONS handler of the URL, e.g.
+	// the CORS middleware answering the preflight requests. Unlike the
+	// endpoint middlewares they run without the endpoint. The middlewares of
+	// the first endpoint of the URL having them are used.
+	OptionsMiddlewares []Middleware
+}
+
+// JoinURL joins a route prefix such as "/api/v1" and an endpoint URL with a
+// single slash between them.
+//
+//   - prefix: The route prefix.
+//   - url: The endpoint URL.
+func JoinURL(prefix string, url string) string {
+	prefix = strings.TrimSuffix(prefix, "/")
+	if prefix == "" {
+		return url
+	}
+	if url == "" || url == "/" {
+		return prefix + url
+	}
+	return prefix + "/" + strings.TrimPrefix(url, "/")
+}
+
+// Group mounts endpoints under a route prefix and applies middlewares to all
+// of them. The group middlewares run before the endpoint middlewares. The
+// other fields of the endpoints are kept. To group endpoint definitions, use
+// definition.Group instead.
+//
+//   - prefix: The route prefix of the endpoints.
+//   - endpoints: The endpoints to mount.
+//   - middlewares: The middlewares of the group.
+func Group(
+	prefix string,
+	endpoints []Endpoint,
+	middlewares ...Middleware,
+) []Endpoint {
+	grouped := make([]Endpoint, len(endpoints))
+	for i, endpoint := range endpoints {
+		endpoint.URL = JoinURL(prefix, endpoint.URL)
+		endpoint.Middlewares = append(
+			append([]Middleware{}, middlewares...),
+			endpoint.Middlewares...,
+		)
+		grouped[i] = endpoint
+	}
+	return grouped
 }
@@ -0,0 +1,83 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestJoinURL tests joining route prefixes and URLs.
+func TestJoinURL(t *testing.T) {
+	assert.Equal(t, "/api/v1/users", JoinURL("/api/v1", "/users"))
+	assert.Equal(t, "/api/v1/users", JoinURL("/api/v1/", "users"))
+	assert.Equal(t, "/api/v1/", JoinURL("/api/v1", "/"))
+	assert.Equal(t, "/api/v1", JoinURL("/api/v1", ""))
+	assert.Equal(t, "/users", JoinURL("", "/users"))
+	assert.Equal(t, "/users", JoinURL("/", "/users"))
+}
+
+// TestGroup tests mounting endpoints under a prefix with group middlewares.
+func TestGroup(t *testing.T) {
+	var calls []string
+	middleware := func(name string) Middleware {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(
+				func(w http.ResponseWriter, r *http.Request) {
+					calls = append(calls, name)
+					next.ServeHTTP(w, r)
+				},
+			)
+		}
+	}
+
+	endpoints := Group(
+		"/api/v1",
+		[]Endpoint{
+			{
+				URL:         "/users",
+				Method:      http.MethodGet,
+				Middlewares: []Middleware{middleware("endpoint")},
+			},
+		},
+		middleware("group"),
+	)
+
+	assert.Len(t, endpoints, 1)
+	assert.Equal(t, "/api/v1/users", endpoints[0].URL)
+	assert.Equal(t, http.MethodGet, endpoints[0].Method)
+	ApplyMiddlewares(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}),
+		endpoints[0].Middlewares...,
+	).ServeHTTP(
+		httptest.NewRecorder(),
+		httptest.NewRequest(http.MethodGet, "/", nil),
+	)
+	assert.Equal(t, []string{"group", "endpoint"}, calls)
+}
+
+// TestGroup_KeepsFields tests that grouping keeps the other fields of the
+// endpoints.
+func TestGroup_KeepsFields(t *testing.T) {
+	endpoints := Group("/api", []Endpoint{
+		{
+			URL:                "/users",
+			Method:             http.MethodGet,
+			DisableAutoHead:    true,
+			DisableAutoOptions: true,
+		},
+	})
+
+	assert.Equal(
+		t,
+		Endpoint{
+			URL:                "/api/users",
+			Method:             http.MethodGet,
+			Middlewares:        []Middleware{},
+			DisableAutoHead:    true,
+			DisableAutoOptions: true,
+		},
+		endpoints[0],
+	)
+}
@@ -21,4 +21,10 @@ type MiddlewareWrapper struct {
 	ID         string
 	Middleware Middleware
 	Inputs     []any
+	// Priority orders the middlewares of a stack when it is ordered. The
+	// middlewares with lower priorities run first.
+	Priority int
+	// After are the IDs of the middlewares that must run before this one,
+	// e.g. the request ID middleware before the access log.
+	After []string
 }
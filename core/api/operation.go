@@ -0,0 +1,36 @@
+package api
+
+import "context"
+
+// OperationRecorder receives the selectors and updates of the mutating
+// operation of a request, e.g. to audit them.
+type OperationRecorder func(selectors any, updates any)
+
+type operationRecorderKey struct{}
+
+// WithOperationRecorder returns a copy of the context with the operation
+// recorder of the request, e.g. set by the audit middleware.
+//
+//   - ctx: The context.
+//   - recorder: The recorder of the operations.
+func WithOperationRecorder(
+	ctx context.Context,
+	recorder OperationRecorder,
+) context.Context {
+	return context.WithValue(ctx, operationRecorderKey{}, recorder)
+}
+
+// RecordOperation passes the selectors and updates of an operation to the
+// recorder stored in the context, e.g. the database selectors and updates of
+// an update endpoint. It does nothing if there is no recorder.
+//
+//   - ctx: The context.
+//   - selectors: The selectors of the operation.
+//   - updates: The updates of the operation.
+func RecordOperation(ctx context.Context, selectors any, updates any) {
+	recorder, ok := ctx.Value(operationRecorderKey{}).(OperationRecorder)
+	if !ok || recorder == nil {
+		return
+	}
+	recorder(selectors, updates)
+}
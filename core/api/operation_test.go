@@ -0,0 +1,38 @@
+package api
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestRecordOperation tests passing the operations to the recorder of the
+// context.
+func TestRecordOperation(t *testing.T) {
+	var selectors, updates any
+	ctx := WithOperationRecorder(
+		context.Background(),
+		func(s any, u any) {
+			selectors = s
+			updates = u
+		},
+	)
+
+	RecordOperation(ctx, "selectors", "updates")
+
+	assert.Equal(t, "selectors", selectors)
+	assert.Equal(t, "updates", updates)
+}
+
+// TestRecordOperation_NoRecorder tests that recording an operation without a
+// recorder does nothing.
+func TestRecordOperation_NoRecorder(t *testing.T) {
+	assert.NotPanics(t, func() {
+		RecordOperation(context.Background(), "selectors", "updates")
+	})
+	assert.NotPanics(t, func() {
+		ctx := WithOperationRecorder(context.Background(), nil)
+		RecordOperation(ctx, "selectors", "updates")
+	})
+}
@@ -0,0 +1,94 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+type pathParametersKey struct{}
+
+// PathParameterNames returns the names of the parameters of a URL pattern,
+// e.g. ["id", "orderID"] for "/users/{id}/orders/{orderID}". Remainder
+// parameters such as "{path...}" are included without the dots.
+//
+//   - pattern: The URL pattern.
+func PathParameterNames(pattern string) []string {
+	var names []string
+	for _, segment := range strings.Split(pattern, "/") {
+		name, ok := parameterName(segment)
+		if ok {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// ExpandPath replaces the parameters of a URL pattern with the escaped values.
+//
+//   - pattern: The URL pattern.
+//   - values: The values of the parameters by name.
+func ExpandPath(pattern string, values map[string]string) (string, error) {
+	segments := strings.Split(pattern, "/")
+	for i, segment := range segments {
+		name, ok := parameterName(segment)
+		if !ok {
+			continue
+		}
+		value, ok := values[name]
+		if !ok || value == "" {
+			return "", fmt.Errorf("missing path parameter: %s", name)
+		}
+		if strings.HasSuffix(segment, "...}") {
+			segments[i] = (&url.URL{Path: value}).EscapedPath()
+		} else {
+			segments[i] = url.PathEscape(value)
+		}
+	}
+	return strings.Join(segments, "/"), nil
+}
+
+// WithPathParameters returns a copy of the context with the path parameters
+// of the request.
+//
+//   - ctx: The context.
+//   - parameters: The path parameters by name.
+func WithPathParameters(
+	ctx context.Context,
+	parameters map[string]string,
+) context.Context {
+	return context.WithValue(ctx, pathParametersKey{}, parameters)
+}
+
+// PathParameters returns the path parameters stored in the context, or nil if
+// there are none.
+//
+//   - ctx: The context.
+func PathParameters(ctx context.Context) map[string]string {
+	parameters, _ := ctx.Value(pathParametersKey{}).(map[string]string)
+	return parameters
+}
+
+// PathParameter returns the path parameter of the given name stored in the
+// context, or an empty string if it is not set.
+//
+//   - ctx: The context.
+//   - name: The name of the parameter.
+func PathParameter(ctx context.Context, name string) string {
+	return PathParameters(ctx)[name]
+}
+
+// parameterName returns the name of a pattern segment such as "{id}". The
+// exact-match marker "{$}" is not a parameter.
+func parameterName(segment string) (string, bool) {
+	if len(segment) < 3 || segment[0] != '{' ||
+		segment[len(segment)-1] != '}' {
+		return "", false
+	}
+	name := strings.TrimSuffix(segment[1:len(segment)-1], "...")
+	if name == "$" || name == "" {
+		return "", false
+	}
+	return name, true
+}
@@ -0,0 +1,52 @@
+package api
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestPathParameterNames tests extracting the parameter names of patterns.
+func TestPathParameterNames(t *testing.T) {
+	assert.Equal(
+		t,
+		[]string{"id", "orderID"},
+		PathParameterNames("/users/{id}/orders/{orderID}"),
+	)
+	assert.Equal(t, []string{"path"}, PathParameterNames("/files/{path...}"))
+	assert.Nil(t, PathParameterNames("/users/{$}"))
+	assert.Nil(t, PathParameterNames("/users"))
+}
+
+// TestExpandPath tests replacing the parameters of patterns.
+func TestExpandPath(t *testing.T) {
+	path, err := ExpandPath(
+		"/users/{id}/orders/{orderID}",
+		map[string]string{"id": "5", "orderID": "a/b c"},
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, "/users/5/orders/a%2Fb%20c", path)
+
+	path, err = ExpandPath(
+		"/files/{path...}",
+		map[string]string{"path": "a/b c"},
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, "/files/a/b%20c", path)
+
+	_, err = ExpandPath("/users/{id}", nil)
+	assert.EqualError(t, err, "missing path parameter: id")
+}
+
+// TestPathParameters tests storing path parameters in the context.
+func TestPathParameters(t *testing.T) {
+	ctx := context.Background()
+	assert.Nil(t, PathParameters(ctx))
+	assert.Equal(t, "", PathParameter(ctx, "id"))
+
+	ctx = WithPathParameters(ctx, map[string]string{"id": "5"})
+
+	assert.Equal(t, map[string]string{"id": "5"}, PathParameters(ctx))
+	assert.Equal(t, "5", PathParameter(ctx, "id"))
+}
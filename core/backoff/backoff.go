@@ -0,0 +1,22 @@
+// Package backoff provides the default retry delay shared by the job queue
+// and the webhook dispatcher.
+package backoff
+
+import "time"
+
+// Max is the longest delay returned by Exponential.
+const Max = time.Hour
+
+// Exponential returns a delay doubling with every attempt, starting from one
+// second and capped at Max.
+//
+//   - attempt: The number of the attempt that failed, starting from 1.
+func Exponential(attempt int) time.Duration {
+	if attempt < 1 {
+		return time.Second
+	}
+	if attempt > 13 {
+		return Max
+	}
+	return min(time.Second<<(attempt-1), Max)
+}
@@ -0,0 +1,17 @@
+package backoff
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestExponential tests the exponential backoff.
+func TestExponential(t *testing.T) {
+	assert.Equal(t, time.Second, Exponential(0))
+	assert.Equal(t, time.Second, Exponential(1))
+	assert.Equal(t, 4*time.Second, Exponential(3))
+	assert.Equal(t, Max, Exponential(13))
+	assert.Equal(t, Max, Exponential(100))
+}
@@ -0,0 +1,151 @@
+// Package buildinfo provides information about the running build, such as
+// the module version and the VCS revision, for version endpoints, startup
+// banners, access logs and error reports.
+package buildinfo
+
+import (
+	"fmt"
+	"runtime/debug"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Info contains information about the running build.
+type Info struct {
+	// Path is the path of the main module
+	Path string `json:"path,omitempty"`
+	// Version is the version of the main module
+	Version string `json:"version,omitempty"`
+	// GoVersion is the version of the Go toolchain
+	GoVersion string `json:"go_version,omitempty"`
+	// GitSHA is the VCS revision
+	GitSHA string `json:"git_sha,omitempty"`
+	// GitTime is the time of the VCS revision
+	GitTime string `json:"git_time,omitempty"`
+	// GitModified tells if the build had uncommitted changes
+	GitModified bool `json:"git_modified,omitempty"`
+	// StartTime is the start time of the process
+	StartTime time.Time `json:"start_time"`
+	// Features are the enabled features
+	Features []string `json:"features,omitempty"`
+}
+
+var (
+	startTime = time.Now().UTC()
+
+	readOnce  sync.Once
+	buildInfo Info
+
+	featuresMu sync.RWMutex
+	features   []string
+
+	readBuildInfo = debug.ReadBuildInfo
+)
+
+// Get returns the information about the running build. The build information
+// is read once with debug.ReadBuildInfo.
+func Get() Info {
+	readOnce.Do(func() {
+		buildInfo = read()
+	})
+
+	info := buildInfo
+	info.Features = Features()
+	return info
+}
+
+// EnableFeature adds features to the list of enabled features reported in
+// the build information.
+//
+//   - names: The names of the enabled features.
+func EnableFeature(names ...string) {
+	featuresMu.Lock()
+	defer featuresMu.Unlock()
+
+	for _, name := range names {
+		if !slices.Contains(features, name) {
+			features = append(features, name)
+		}
+	}
+}
+
+// Features returns a sorted copy of the enabled features.
+func Features() []string {
+	featuresMu.RLock()
+	defer featuresMu.RUnlock()
+
+	if len(features) == 0 {
+		return nil
+	}
+	enabled := slices.Clone(features)
+	slices.Sort(enabled)
+	return enabled
+}
+
+// Summary returns a short one line description of the build, e.g.
+// "example.com/app v1.2.0 (abc1234)".
+func (i Info) Summary() string {
+	parts := []string{}
+	if i.Path != "" {
+		parts = append(parts, i.Path)
+	}
+	if i.Version != "" {
+		parts = append(parts, i.Version)
+	}
+	if i.GitSHA != "" {
+		revision := i.GitSHA
+		if len(revision) > 7 {
+			revision = revision[:7]
+		}
+		if i.GitModified {
+			revision += "-dirty"
+		}
+		parts = append(parts, fmt.Sprintf("(%s)", revision))
+	}
+	if len(parts) == 0 {
+		return "unknown build"
+	}
+	return strings.Join(parts, " ")
+}
+
+// Banner returns a startup banner describing the running build.
+func Banner() string {
+	info := Get()
+	banner := fmt.Sprintf(
+		"%s, %s, started at %s",
+		info.Summary(),
+		info.GoVersion,
+		info.StartTime.Format(time.RFC3339),
+	)
+	if len(info.Features) > 0 {
+		banner += ", features: " + strings.Join(info.Features, ",")
+	}
+	return banner
+}
+
+func read() Info {
+	info := Info{StartTime: startTime}
+
+	bi, ok := readBuildInfo()
+	if !ok {
+		return info
+	}
+
+	info.Path = bi.Main.Path
+	info.Version = bi.Main.Version
+	info.GoVersion = bi.GoVersion
+	for _, setting := range bi.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			info.GitSHA = setting.Value
+		case "vcs.time":
+			info.GitTime = setting.Value
+		case "vcs.modified":
+			info.GitModified = setting.Value == "true"
+		}
+	}
+
+	return info
+}
@@ -0,0 +1,88 @@
+package buildinfo
+
+import (
+	"runtime/debug"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestRead tests reading the build information.
+func TestRead(t *testing.T) {
+	original := readBuildInfo
+	defer func() { readBuildInfo = original }()
+
+	readBuildInfo = func() (*debug.BuildInfo, bool) {
+		return &debug.BuildInfo{
+			GoVersion: "go1.23.0",
+			Main: debug.Module{
+				Path:    "example.com/app",
+				Version: "v1.2.0",
+			},
+			Settings: []debug.BuildSetting{
+				{Key: "vcs.revision", Value: "abcdef0123456789"},
+				{Key: "vcs.time", Value: "2024-01-01T00:00:00Z"},
+				{Key: "vcs.modified", Value: "true"},
+			},
+		}, true
+	}
+
+	info := read()
+
+	assert.Equal(t, "example.com/app", info.Path)
+	assert.Equal(t, "v1.2.0", info.Version)
+	assert.Equal(t, "go1.23.0", info.GoVersion)
+	assert.Equal(t, "abcdef0123456789", info.GitSHA)
+	assert.Equal(t, "2024-01-01T00:00:00Z", info.GitTime)
+	assert.True(t, info.GitModified)
+	assert.Equal(t, startTime, info.StartTime)
+}
+
+// TestRead_NoBuildInfo tests that the start time is set even without build
+// information.
+func TestRead_NoBuildInfo(t *testing.T) {
+	original := readBuildInfo
+	defer func() { readBuildInfo = original }()
+
+	readBuildInfo = func() (*debug.BuildInfo, bool) {
+		return nil, false
+	}
+
+	info := read()
+
+	assert.Equal(t, Info{StartTime: startTime}, info)
+}
+
+// TestEnableFeature tests that features are reported sorted and without
+// duplicates.
+func TestEnableFeature(t *testing.T) {
+	defer func() { features = nil }()
+
+	EnableFeature("read_only", "cache")
+	EnableFeature("cache")
+
+	assert.Equal(t, []string{"cache", "read_only"}, Features())
+	assert.Equal(t, []string{"cache", "read_only"}, Get().Features)
+}
+
+// TestSummary tests the one line build summary.
+func TestSummary(t *testing.T) {
+	info := Info{
+		Path:        "example.com/app",
+		Version:     "v1.2.0",
+		GitSHA:      "abcdef0123456789",
+		GitModified: true,
+	}
+
+	assert.Equal(t, "example.com/app v1.2.0 (abcdef0-dirty)", info.Summary())
+}
+
+// TestSummary_Unknown tests the summary of an empty build information.
+func TestSummary_Unknown(t *testing.T) {
+	assert.Equal(t, "unknown build", Info{}.Summary())
+}
+
+// TestBanner tests that the banner contains the build summary.
+func TestBanner(t *testing.T) {
+	assert.Contains(t, Banner(), Get().Summary())
+}
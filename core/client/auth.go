@@ -0,0 +1,269 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultAPIKeyHeader is the default header of the API keys.
+const DefaultAPIKeyHeader = "X-API-Key"
+
+// tokenExpiryLeeway is how long before their expiry the OAuth2 tokens are
+// refreshed.
+const tokenExpiryLeeway = 10 * time.Second
+
+// AuthToken is the credential of a request, set as a header.
+type AuthToken struct {
+	// Header is the name of the header, e.g. "Authorization"
+	Header string
+	// Value is the value of the header, e.g. "Bearer abc"
+	Value string
+}
+
+// AuthProvider provides the credentials of the requests. The client asks for
+// a token before each request, and on a 401 Unauthorized response asks the
+// provider to refresh the rejected token and resends the request once if it
+// did.
+type AuthProvider interface {
+	// Token returns the token of a request.
+	Token(ctx context.Context) (*AuthToken, error)
+	// Refresh refreshes a token rejected by the server. It returns whether
+	// a new token is available, e.g. false for static tokens.
+	Refresh(ctx context.Context, rejected *AuthToken) (bool, error)
+}
+
+// BearerToken is an AuthProvider of a static bearer token.
+type BearerToken string
+
+// Token returns the bearer token as an Authorization header.
+func (t BearerToken) Token(ctx context.Context) (*AuthToken, error) {
+	return &AuthToken{
+		Header: "Authorization",
+		Value:  "Bearer " + string(t),
+	}, nil
+}
+
+// Refresh returns false, as static tokens are not refreshed.
+func (t BearerToken) Refresh(
+	ctx context.Context,
+	rejected *AuthToken,
+) (bool, error) {
+	return false, nil
+}
+
+// APIKey is an AuthProvider of a static API key.
+type APIKey struct {
+	// Key is the API key
+	Key string
+	// Header is the header of the key. Defaults to DefaultAPIKeyHeader.
+	Header string
+}
+
+// Token returns the API key as its header.
+func (k APIKey) Token(ctx context.Context) (*AuthToken, error) {
+	header := k.Header
+	if header == "" {
+		header = DefaultAPIKeyHeader
+	}
+	return &AuthToken{Header: header, Value: k.Key}, nil
+}
+
+// Refresh returns false, as static keys are not refreshed.
+func (k APIKey) Refresh(
+	ctx context.Context,
+	rejected *AuthToken,
+) (bool, error) {
+	return false, nil
+}
+
+// ClientCredentials is an AuthProvider of OAuth2 access tokens obtained with
+// the client credentials grant. The tokens are cached until shortly before
+// their expiry, and refreshed when rejected by the server. It is safe for
+// concurrent use and should be reused across the requests.
+type ClientCredentials struct {
+	// TokenURL is the URL of the token endpoint
+	TokenURL string
+	// ClientID is the ID of the client
+	ClientID string
+	// ClientSecret is the secret of the client
+	ClientSecret string
+	// Scopes are the requested scopes. Optional.
+	Scopes []string
+	// HTTPClient sends the token requests. Defaults to a client with
+	// DefaultTimeout.
+	HTTPClient *http.Client
+
+	mu      sync.Mutex
+	token   *AuthToken
+	expires time.Time
+}
+
+// tokenResponse is the response of the token endpoint.
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int    `json:"expires_in"`
+}
+
+// Token returns the cached access token, requesting a new one if there is no
+// token or it expires soon.
+func (c *ClientCredentials) Token(ctx context.Context) (*AuthToken, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.token != nil &&
+		(c.expires.IsZero() || time.Until(c.expires) > tokenExpiryLeeway) {
+		return c.token, nil
+	}
+	return c.requestToken(ctx)
+}
+
+// Refresh requests a new access token, unless the rejected token was already
+// replaced, e.g. by a concurrent request.
+func (c *ClientCredentials) Refresh(
+	ctx context.Context,
+	rejected *AuthToken,
+) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.token != nil && rejected != nil && *c.token != *rejected {
+		return true, nil
+	}
+	if _, err := c.requestToken(ctx); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// requestToken requests and caches a new access token. The caller must hold
+// the lock.
+func (c *ClientCredentials) requestToken(
+	ctx context.Context,
+) (*AuthToken, error) {
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if len(c.Scopes) != 0 {
+		form.Set("scope", strings.Join(c.Scopes, " "))
+	}
+	req, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodPost,
+		c.TokenURL,
+		strings.NewReader(form.Encode()),
+	)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set(contentTypeHeader, "application/x-www-form-urlencoded")
+	req.SetBasicAuth(
+		url.QueryEscape(c.ClientID),
+		url.QueryEscape(c.ClientSecret),
+	)
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: DefaultTimeout}
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf(
+			"token request failed with status %d: %s",
+			resp.StatusCode,
+			string(body),
+		)
+	}
+	var response tokenResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("invalid token response: %w", err)
+	}
+	if response.AccessToken == "" {
+		return nil, fmt.Errorf("token response has no access token")
+	}
+
+	c.token = &AuthToken{
+		Header: "Authorization",
+		Value:  "Bearer " + response.AccessToken,
+	}
+	c.expires = time.Time{}
+	if response.ExpiresIn > 0 {
+		c.expires = time.Now().Add(
+			time.Duration(response.ExpiresIn) * time.Second,
+		)
+	}
+	return c.token, nil
+}
+
+// sendAuthorized sends the request with the token of the provider, resending
+// it once with a refreshed token on a 401 Unauthorized response. A nil
+// provider sends the request without a token.
+func sendAuthorized(
+	client *http.Client,
+	req *http.Request,
+	retry *RetryPolicy,
+	auth AuthProvider,
+) (*http.Response, error) {
+	if auth == nil {
+		return retry.do(client, req)
+	}
+
+	token, err := authorize(req, auth)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := retry.do(client, req)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return resp, nil
+	}
+
+	refreshed, err := auth.Refresh(req.Context(), token)
+	if err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	if !refreshed {
+		return resp, nil
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = body
+	}
+	if _, err := authorize(req, auth); err != nil {
+		return nil, err
+	}
+	return retry.do(client, req)
+}
+
+// authorize sets the token of the provider on the request.
+func authorize(req *http.Request, auth AuthProvider) (*AuthToken, error) {
+	token, err := auth.Token(req.Context())
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set(token.Header, token.Value)
+	return token, nil
+}
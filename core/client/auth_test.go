@@ -0,0 +1,203 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// tokenServer issues the access tokens "token-1", "token-2", etc.
+func tokenServer(
+	t *testing.T,
+	expiresIn int,
+) (*httptest.Server, *atomic.Int32) {
+	var issued atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			id, secret, ok := r.BasicAuth()
+			if !ok || id != "id" || secret != "secret" ||
+				r.FormValue("grant_type") != "client_credentials" {
+				w.WriteHeader(http.StatusUnauthorized)
+				_, _ = w.Write([]byte(`{"error":"invalid_client"}`))
+				return
+			}
+			_, _ = fmt.Fprintf(
+				w,
+				`{"access_token":"token-%d","token_type":"Bearer",`+
+					`"expires_in":%d,"scope":%q}`,
+				issued.Add(1),
+				expiresIn,
+				r.FormValue("scope"),
+			)
+		},
+	))
+	t.Cleanup(server.Close)
+	return server, &issued
+}
+
+// TestStaticProviders tests the bearer token and API key providers.
+func TestStaticProviders(t *testing.T) {
+	tests := []struct {
+		provider AuthProvider
+		expected AuthToken
+	}{
+		{BearerToken("abc"), AuthToken{"Authorization", "Bearer abc"}},
+		{APIKey{Key: "abc"}, AuthToken{DefaultAPIKeyHeader, "abc"}},
+		{APIKey{Key: "abc", Header: "X-Key"}, AuthToken{"X-Key", "abc"}},
+	}
+
+	for _, tt := range tests {
+		token, err := tt.provider.Token(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, tt.expected, *token)
+
+		refreshed, err := tt.provider.Refresh(context.Background(), token)
+		assert.NoError(t, err)
+		assert.False(t, refreshed)
+	}
+}
+
+// TestClientCredentials tests caching and refreshing the access tokens.
+func TestClientCredentials(t *testing.T) {
+	server, issued := tokenServer(t, 3600)
+	provider := &ClientCredentials{
+		TokenURL:     server.URL,
+		ClientID:     "id",
+		ClientSecret: "secret",
+		Scopes:       []string{"read", "write"},
+	}
+	ctx := context.Background()
+
+	token, err := provider.Token(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, AuthToken{"Authorization", "Bearer token-1"}, *token)
+
+	cached, err := provider.Token(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, token, cached)
+	assert.Equal(t, int32(1), issued.Load())
+
+	refreshed, err := provider.Refresh(ctx, token)
+	assert.NoError(t, err)
+	assert.True(t, refreshed)
+	token, err = provider.Token(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "Bearer token-2", token.Value)
+
+	// A token rejected after it was replaced is not refreshed again
+	refreshed, err = provider.Refresh(ctx, cached)
+	assert.NoError(t, err)
+	assert.True(t, refreshed)
+	assert.Equal(t, int32(2), issued.Load())
+}
+
+// TestClientCredentials_Expiry tests requesting a new token for the tokens
+// expiring soon.
+func TestClientCredentials_Expiry(t *testing.T) {
+	server, issued := tokenServer(t, 5)
+	provider := &ClientCredentials{
+		TokenURL:     server.URL,
+		ClientID:     "id",
+		ClientSecret: "secret",
+	}
+
+	for range 2 {
+		_, err := provider.Token(context.Background())
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, int32(2), issued.Load())
+}
+
+// TestClientCredentials_Error tests the failing token requests.
+func TestClientCredentials_Error(t *testing.T) {
+	server, _ := tokenServer(t, 3600)
+	provider := &ClientCredentials{
+		TokenURL:     server.URL,
+		ClientID:     "id",
+		ClientSecret: "invalid",
+	}
+
+	_, err := provider.Token(context.Background())
+	assert.EqualError(
+		t,
+		err,
+		`token request failed with status 401: {"error":"invalid_client"}`,
+	)
+
+	refreshed, err := provider.Refresh(context.Background(), nil)
+	assert.Error(t, err)
+	assert.False(t, refreshed)
+}
+
+// TestSendContext_Auth tests resending the requests rejected with 401
+// Unauthorized with a refreshed token.
+func TestSendContext_Auth(t *testing.T) {
+	tokens, _ := tokenServer(t, 3600)
+	provider := &ClientCredentials{
+		TokenURL:     tokens.URL,
+		ClientID:     "id",
+		ClientSecret: "secret",
+	}
+	var authorizations []string
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			authorization := r.Header.Get("Authorization")
+			authorizations = append(authorizations, authorization)
+			if authorization != "Bearer token-2" {
+				w.WriteHeader(http.StatusUnauthorized)
+				_, _ = w.Write([]byte(`{}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"message":"ok"}`))
+		},
+	))
+	defer server.Close()
+	type Input struct {
+		Name string `json:"name"`
+	}
+
+	response, err := SendContext(
+		context.Background(),
+		&Input{Name: "test"},
+		"/",
+		server.URL,
+		http.MethodPost,
+		&MockURLEncoder{},
+		HandlerOpts[map[string]any]{Auth: provider},
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, response.Response.StatusCode)
+	assert.Equal(t, "ok", (*response.Output)["message"])
+	assert.Equal(
+		t,
+		[]string{"Bearer token-1", "Bearer token-2"},
+		authorizations,
+	)
+}
+
+// TestSendContext_AuthStatic tests returning the 401 Unauthorized responses
+// of the static tokens without resending them.
+func TestSendContext_AuthStatic(t *testing.T) {
+	server, bodies := statusServer(t, nil, http.StatusUnauthorized)
+	type Input struct{}
+
+	_, err := SendContext(
+		context.Background(),
+		&Input{},
+		"/",
+		server.URL,
+		http.MethodGet,
+		&MockURLEncoder{},
+		HandlerOpts[map[string]any]{Auth: BearerToken("abc")},
+	)
+
+	// The empty body of the 401 response is not valid JSON
+	assert.ErrorContains(t, err, "JSON unmarshal error")
+	assert.Len(t, *bodies, 1)
+}
@@ -0,0 +1,114 @@
+package client
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"mime"
+	"strings"
+	"sync"
+)
+
+// Codec marshals the request bodies and unmarshals the response bodies of a
+// media type.
+type Codec interface {
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+}
+
+// CodecFuncs is a Codec of a pair of functions, e.g.
+// CodecFuncs{xml.Marshal, xml.Unmarshal}.
+type CodecFuncs struct {
+	MarshalFunc   func(v any) ([]byte, error)
+	UnmarshalFunc func(data []byte, v any) error
+}
+
+// Marshal marshals the value with MarshalFunc.
+func (c CodecFuncs) Marshal(v any) ([]byte, error) {
+	return c.MarshalFunc(v)
+}
+
+// Unmarshal unmarshals the data with UnmarshalFunc.
+func (c CodecFuncs) Unmarshal(data []byte, v any) error {
+	return c.UnmarshalFunc(data, v)
+}
+
+var (
+	// JSONCodec is the codec of application/json.
+	JSONCodec Codec = jsonCodec{}
+	// XMLCodec is the codec of application/xml and text/xml.
+	XMLCodec Codec = xmlCodec{}
+)
+
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
+
+type xmlCodec struct{}
+
+func (xmlCodec) Marshal(v any) ([]byte, error) {
+	return xml.Marshal(v)
+}
+
+func (xmlCodec) Unmarshal(data []byte, v any) error {
+	return xml.Unmarshal(data, v)
+}
+
+var (
+	codecsMu sync.RWMutex
+	codecs   = map[string]Codec{
+		applicationJSON:   JSONCodec,
+		"application/xml": XMLCodec,
+		"text/xml":        XMLCodec,
+	}
+)
+
+// RegisterCodec registers the codec of a media type, e.g. of protobuf or
+// MessagePack, replacing any codec registered before. The response bodies
+// are unmarshaled with the codec of their Content-Type, and the requests
+// with the media type as their BodyEncoding are marshaled with it. Responses
+// of unregistered media types are unmarshaled as JSON.
+//
+//   - mediaType: The media type, e.g. "application/x-protobuf".
+//   - codec: The codec of the media type.
+func RegisterCodec(mediaType string, codec Codec) {
+	if codec == nil {
+		panic("codec cannot be nil")
+	}
+	codecsMu.Lock()
+	defer codecsMu.Unlock()
+	codecs[normalizeMediaType(mediaType)] = codec
+}
+
+// codecFor returns the codec of a content type. The media types with a
+// structured syntax suffix, e.g. application/problem+json, use the codec of
+// the suffix.
+func codecFor(contentType string) (Codec, bool) {
+	mediaType := normalizeMediaType(contentType)
+
+	codecsMu.RLock()
+	defer codecsMu.RUnlock()
+	if codec, ok := codecs[mediaType]; ok {
+		return codec, true
+	}
+	switch {
+	case strings.HasSuffix(mediaType, "+json"):
+		return codecs[applicationJSON], true
+	case strings.HasSuffix(mediaType, "+xml"):
+		return codecs["application/xml"], true
+	}
+	return nil, false
+}
+
+func normalizeMediaType(contentType string) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return strings.ToLower(strings.TrimSpace(contentType))
+	}
+	return mediaType
+}
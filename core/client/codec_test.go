@@ -0,0 +1,143 @@
+package client
+
+import (
+	"context"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestSendContext_XML tests sending and receiving XML bodies.
+func TestSendContext_XML(t *testing.T) {
+	var contentType, body string
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			contentType = r.Header.Get(contentTypeHeader)
+			data, _ := io.ReadAll(r.Body)
+			body = string(data)
+			w.Header().Set(contentTypeHeader, "application/xml; charset=utf-8")
+			_, _ = w.Write([]byte(`<user><id>1</id><name>test</name></user>`))
+		},
+	))
+	defer server.Close()
+	type Input struct {
+		XMLName xml.Name `xml:"user"`
+		Name    string   `json:"name" xml:"name"`
+		Token   string   `json:"token" source:"header" xml:"-"`
+	}
+	type Output struct {
+		ID   int    `xml:"id"`
+		Name string `xml:"name"`
+	}
+
+	response, err := SendContext(
+		context.Background(),
+		&Input{Name: "test", Token: "abc"},
+		"/users",
+		server.URL,
+		http.MethodPost,
+		&MockURLEncoder{},
+		HandlerOpts[Output]{BodyEncoding: "application/xml"},
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "application/xml", contentType)
+	assert.Equal(t, `<user><name>test</name></user>`, body)
+	assert.Equal(t, Output{ID: 1, Name: "test"}, *response.Output)
+}
+
+// TestRegisterCodec tests unmarshaling the responses with a registered
+// codec.
+func TestRegisterCodec(t *testing.T) {
+	RegisterCodec("Application/X-Test", CodecFuncs{
+		MarshalFunc: func(v any) ([]byte, error) {
+			return []byte("marshaled"), nil
+		},
+		UnmarshalFunc: func(data []byte, v any) error {
+			*v.(*string) = strings.ToUpper(string(data))
+			return nil
+		},
+	})
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set(contentTypeHeader, "application/x-test")
+			_, _ = io.Copy(w, r.Body)
+		},
+	))
+	defer server.Close()
+	type Input struct{}
+
+	response, err := SendContext(
+		context.Background(),
+		&Input{},
+		"/",
+		server.URL,
+		http.MethodPost,
+		&MockURLEncoder{},
+		HandlerOpts[string]{BodyEncoding: "application/x-test"},
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "MARSHALED", *response.Output)
+}
+
+// TestRegisterCodec_Nil tests that a nil codec panics.
+func TestRegisterCodec_Nil(t *testing.T) {
+	assert.PanicsWithValue(t, "codec cannot be nil", func() {
+		RegisterCodec("application/x-nil", nil)
+	})
+}
+
+// TestCodecFor tests the codecs of the content types.
+func TestCodecFor(t *testing.T) {
+	tests := map[string]Codec{
+		"application/json; charset=utf-8": JSONCodec,
+		"application/problem+json":        JSONCodec,
+		"text/xml":                        XMLCodec,
+		"application/atom+xml":            XMLCodec,
+	}
+	for contentType, expected := range tests {
+		codec, ok := codecFor(contentType)
+		assert.True(t, ok, contentType)
+		assert.Equal(t, expected, codec, contentType)
+	}
+
+	_, ok := codecFor("text/plain")
+	assert.False(t, ok)
+}
+
+// TestResponseToPayload_CodecError tests the unmarshal errors of the codecs.
+func TestResponseToPayload_CodecError(t *testing.T) {
+	resp := &http.Response{
+		Header: http.Header{contentTypeHeader: {"application/xml"}},
+		Body:   io.NopCloser(strings.NewReader("<invalid")),
+	}
+
+	_, err := responseToPayload(resp, new(struct{}))
+
+	assert.ErrorContains(t, err, "application/xml unmarshal error")
+}
+
+// TestEncodeBody_Codec tests the errors of the codec encodings.
+func TestEncodeBody_Codec(t *testing.T) {
+	_, _, err := encodeBody(&RequestData{Encoding: "application/x-unknown"})
+	assert.EqualError(t, err, "invalid body encoding: application/x-unknown")
+
+	_, _, err = encodeBody(&RequestData{
+		Form:     map[string]any{"a": 1},
+		Encoding: "application/xml",
+	})
+	assert.Error(t, err)
+
+	reader, contentType, err := encodeBody(
+		&RequestData{Encoding: "application/xml"},
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, "application/xml", contentType)
+	assert.Zero(t, reader.Len())
+}
@@ -0,0 +1,263 @@
+package client
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"maps"
+	"mime/multipart"
+	"net/textproto"
+	"net/url"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// Encodings of the request bodies.
+const (
+	// BodyJSON encodes the body as JSON
+	BodyJSON = "json"
+	// BodyForm encodes the body and the form fields as
+	// application/x-www-form-urlencoded
+	BodyForm = "form"
+	// BodyMultipart encodes the body, the form fields and the files as
+	// multipart/form-data
+	BodyMultipart = "multipart"
+)
+
+const (
+	applicationForm    = "application/x-www-form-urlencoded"
+	defaultContentType = "application/octet-stream"
+)
+
+// File is a file sent in a multipart/form-data request, from an input field
+// tagged with `source:"file"`, e.g.
+//
+//	type UploadInput struct {
+//		Title  string         `json:"title" source:"form"`
+//		Avatar *client.File   `json:"avatar" source:"file"`
+//		Files  []*client.File `json:"files" source:"file"`
+//	}
+//
+// The fields are named by their JSON names. The content is read into memory
+// when the request is sent, so that the request can be retried.
+type File struct {
+	// Filename is the name of the file sent to the server
+	Filename string
+	// ContentType is the content type of the file. Defaults to
+	// application/octet-stream.
+	ContentType string
+	// Content is the content of the file
+	Content io.Reader
+}
+
+var fileType = reflect.TypeOf(File{})
+
+// encodeBody encodes the body of the request and returns its content type.
+// Without an explicit encoding, the requests with files are encoded as
+// multipart/form-data, the requests with form fields as
+// application/x-www-form-urlencoded and the other requests as JSON.
+func encodeBody(input *RequestData) (*bytes.Reader, string, error) {
+	encoding := input.Encoding
+	if encoding == "" {
+		switch {
+		case len(input.Files) != 0:
+			encoding = BodyMultipart
+		case len(input.Form) != 0:
+			encoding = BodyForm
+		default:
+			encoding = BodyJSON
+		}
+	}
+
+	switch encoding {
+	case BodyJSON:
+		if len(input.Form) != 0 || len(input.Files) != 0 {
+			return nil, "", fmt.Errorf(
+				"form and file fields cannot be encoded as JSON",
+			)
+		}
+		bodyReader, err := marshalBody(input.Body)
+		return bodyReader, applicationJSON, err
+	case BodyForm:
+		if len(input.Files) != 0 {
+			return nil, "", fmt.Errorf("file fields require multipart encoding")
+		}
+		fields, err := formFields(input)
+		if err != nil {
+			return nil, "", err
+		}
+		return bytes.NewReader([]byte(formValues(fields).Encode())),
+			applicationForm,
+			nil
+	case BodyMultipart:
+		fields, err := formFields(input)
+		if err != nil {
+			return nil, "", err
+		}
+		return encodeMultipart(fields, input.Files)
+	default:
+		return encodeCodecBody(input, encoding)
+	}
+}
+
+// encodeCodecBody encodes the body with the codec of the media type of the
+// encoding.
+func encodeCodecBody(
+	input *RequestData,
+	mediaType string,
+) (*bytes.Reader, string, error) {
+	codec, ok := codecFor(mediaType)
+	if !ok {
+		return nil, "", fmt.Errorf("invalid body encoding: %s", mediaType)
+	}
+	if len(input.Form) != 0 || len(input.Files) != 0 {
+		return nil, "", fmt.Errorf(
+			"form and file fields cannot be encoded as %s",
+			mediaType,
+		)
+	}
+	if input.Body == nil {
+		return bytes.NewReader(nil), mediaType, nil
+	}
+	body, err := codec.Marshal(input.Body)
+	if err != nil {
+		return nil, "", err
+	}
+	return bytes.NewReader(body), mediaType, nil
+}
+
+// isCodecEncoding returns whether the encoding is the media type of a codec
+// instead of one of the built-in encodings.
+func isCodecEncoding(encoding string) bool {
+	switch encoding {
+	case "", BodyJSON, BodyForm, BodyMultipart:
+		return false
+	}
+	return true
+}
+
+// formFields returns the form fields and the fields of the body of the
+// request.
+func formFields(input *RequestData) (map[string]any, error) {
+	fields := maps.Clone(input.Form)
+	if fields == nil {
+		fields = map[string]any{}
+	}
+	if input.Body == nil {
+		return fields, nil
+	}
+	body, ok := input.Body.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf(
+			"body of type %T cannot be encoded as a form",
+			input.Body,
+		)
+	}
+	maps.Copy(fields, body)
+	return fields, nil
+}
+
+// formValues returns the values of the form fields. The elements of slices
+// are sent as repeated values.
+func formValues(fields map[string]any) url.Values {
+	values := url.Values{}
+	for name, value := range fields {
+		v := reflect.ValueOf(value)
+		if (v.Kind() == reflect.Slice || v.Kind() == reflect.Array) &&
+			v.Type().Elem().Kind() != reflect.Uint8 {
+			for i := 0; i < v.Len(); i++ {
+				values.Add(name, formatListValue(v.Index(i).Interface()))
+			}
+			continue
+		}
+		values.Set(name, formatListValue(value))
+	}
+	return values
+}
+
+// encodeMultipart encodes the form fields and the files as
+// multipart/form-data.
+func encodeMultipart(
+	fields map[string]any,
+	files map[string][]*File,
+) (*bytes.Reader, string, error) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+
+	values := formValues(fields)
+	for _, name := range slices.Sorted(maps.Keys(values)) {
+		for _, value := range values[name] {
+			if err := writer.WriteField(name, value); err != nil {
+				return nil, "", err
+			}
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(files)) {
+		for _, file := range files[name] {
+			if err := writeFile(writer, name, file); err != nil {
+				return nil, "", err
+			}
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, "", err
+	}
+	return bytes.NewReader(body.Bytes()), writer.FormDataContentType(), nil
+}
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+func writeFile(writer *multipart.Writer, name string, file *File) error {
+	contentType := file.ContentType
+	if contentType == "" {
+		contentType = defaultContentType
+	}
+	header := textproto.MIMEHeader{}
+	header.Set(
+		"Content-Disposition",
+		fmt.Sprintf(
+			`form-data; name="%s"; filename="%s"`,
+			quoteEscaper.Replace(name),
+			quoteEscaper.Replace(file.Filename),
+		),
+	)
+	header.Set(contentTypeHeader, contentType)
+
+	part, err := writer.CreatePart(header)
+	if err != nil {
+		return err
+	}
+	if file.Content == nil {
+		return nil
+	}
+	_, err = io.Copy(part, file.Content)
+	return err
+}
+
+// fileValues returns the files of an input field of type File, *File, []File
+// or []*File. Nil files are skipped.
+func fileValues(field reflect.Value) ([]*File, error) {
+	switch {
+	case field.Type() == fileType:
+		file := field.Interface().(File)
+		return []*File{&file}, nil
+	case field.Kind() == reflect.Pointer && field.Type().Elem() == fileType:
+		if field.IsNil() {
+			return nil, nil
+		}
+		return []*File{field.Interface().(*File)}, nil
+	case field.Kind() == reflect.Slice:
+		var files []*File
+		for i := 0; i < field.Len(); i++ {
+			elementFiles, err := fileValues(field.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			files = append(files, elementFiles...)
+		}
+		return files, nil
+	}
+	return nil, fmt.Errorf("invalid type of file field: %s", field.Type())
+}
@@ -0,0 +1,194 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestSendContext_Multipart tests sending the form and file fields as
+// multipart/form-data.
+func TestSendContext_Multipart(t *testing.T) {
+	var form url.Values
+	files := map[string]string{}
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			assert.NoError(t, r.ParseMultipartForm(1<<20))
+			form = r.MultipartForm.Value
+			for name, headers := range r.MultipartForm.File {
+				for _, header := range headers {
+					file, _ := header.Open()
+					content, _ := io.ReadAll(file)
+					files[name+"/"+header.Filename] = header.Header.Get(
+						contentTypeHeader,
+					) + ":" + string(content)
+				}
+			}
+			_, _ = w.Write([]byte(`{}`))
+		},
+	))
+	defer server.Close()
+	type Input struct {
+		Title  string   `json:"title" source:"form"`
+		Tags   []string `json:"tags" source:"form"`
+		Avatar *File    `json:"avatar" source:"file"`
+		Files  []*File  `json:"files" source:"file"`
+		None   *File    `json:"none" source:"file"`
+	}
+
+	_, err := SendContext[Input, map[string]any](
+		context.Background(),
+		&Input{
+			Title: "test",
+			Tags:  []string{"a", "b"},
+			Avatar: &File{
+				Filename:    "avatar.png",
+				ContentType: "image/png",
+				Content:     strings.NewReader("png"),
+			},
+			Files: []*File{
+				{Filename: "a.txt", Content: strings.NewReader("a")},
+				{Filename: "b.txt", Content: strings.NewReader("b")},
+			},
+		},
+		"/upload",
+		server.URL,
+		http.MethodPost,
+		&MockURLEncoder{},
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(t, url.Values{"title": {"test"}, "tags": {"a", "b"}}, form)
+	assert.Equal(t, map[string]string{
+		"avatar/avatar.png": "image/png:png",
+		"files/a.txt":       defaultContentType + ":a",
+		"files/b.txt":       defaultContentType + ":b",
+	}, files)
+}
+
+// TestSendContext_Form tests sending the form fields and the body as
+// application/x-www-form-urlencoded.
+func TestSendContext_Form(t *testing.T) {
+	var contentType string
+	var form url.Values
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			contentType = r.Header.Get(contentTypeHeader)
+			assert.NoError(t, r.ParseForm())
+			form = r.PostForm
+			_, _ = w.Write([]byte(`{}`))
+		},
+	))
+	defer server.Close()
+	type Input struct {
+		Name string `json:"name"`
+		Age  int    `json:"age" source:"form"`
+	}
+
+	for _, opts := range []HandlerOpts[map[string]any]{
+		{},
+		{BodyEncoding: BodyForm},
+	} {
+		_, err := SendContext(
+			context.Background(),
+			&Input{Name: "test", Age: 30},
+			"/users",
+			server.URL,
+			http.MethodPost,
+			&MockURLEncoder{},
+			opts,
+		)
+
+		assert.NoError(t, err)
+		assert.Equal(t, applicationForm, contentType)
+		assert.Equal(t, url.Values{"name": {"test"}, "age": {"30"}}, form)
+	}
+}
+
+// TestEncodeBody tests the encodings of the request bodies.
+func TestEncodeBody(t *testing.T) {
+	reader, contentType, err := encodeBody(&RequestData{
+		Body: map[string]any{"name": "test"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, applicationJSON, contentType)
+	body, _ := io.ReadAll(reader)
+	assert.JSONEq(t, `{"name":"test"}`, string(body))
+
+	_, contentType, err = encodeBody(&RequestData{
+		Body:     map[string]any{"name": "test"},
+		Encoding: BodyMultipart,
+	})
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(contentType, "multipart/form-data"))
+}
+
+// TestEncodeBody_Error tests the bodies that cannot be encoded.
+func TestEncodeBody_Error(t *testing.T) {
+	files := map[string][]*File{"file": {{Filename: "a.txt"}}}
+
+	tests := map[string]*RequestData{
+		"json form": {
+			Form:     map[string]any{"a": 1},
+			Encoding: BodyJSON,
+		},
+		"form files": {Files: files, Encoding: BodyForm},
+		"form body":  {Body: []int{1}, Encoding: BodyForm},
+		"invalid":    {Encoding: "xml"},
+	}
+
+	for name, input := range tests {
+		_, _, err := encodeBody(input)
+		assert.Error(t, err, name)
+	}
+}
+
+// TestFileValues tests the types of the file fields.
+func TestFileValues(t *testing.T) {
+	file := File{Filename: "a.txt"}
+	tests := []struct {
+		value any
+		count int
+	}{
+		{file, 1},
+		{&file, 1},
+		{(*File)(nil), 0},
+		{[]File{file, file}, 2},
+		{[]*File{&file, nil}, 1},
+	}
+
+	for _, tt := range tests {
+		files, err := fileValues(reflect.ValueOf(tt.value))
+		assert.NoError(t, err)
+		assert.Len(t, files, tt.count)
+	}
+
+	_, err := fileValues(reflect.ValueOf("a.txt"))
+	assert.EqualError(t, err, "invalid type of file field: string")
+}
+
+// TestSendContext_FormGet tests that form fields cannot be sent with GET
+// requests.
+func TestSendContext_FormGet(t *testing.T) {
+	type Input struct {
+		Name string `json:"name" source:"form"`
+	}
+
+	_, err := SendContext[Input, map[string]any](
+		context.Background(),
+		&Input{Name: "test"},
+		"/",
+		"http://localhost",
+		http.MethodGet,
+		&MockURLEncoder{},
+	)
+
+	assert.EqualError(t, err, "body cannot be set for GET requests")
+}
@@ -1,9 +1,15 @@
 package client
 
 import (
+	"context"
 	"net/http"
+	"time"
 )
 
+// DefaultTimeout is the timeout of each attempt of the requests sent by the
+// default sender without an HTTP client.
+const DefaultTimeout = 30 * time.Second
+
 type SendResult[Payload any] struct {
 	Response *http.Response
 	Output   *Payload
@@ -11,20 +17,49 @@ type SendResult[Payload any] struct {
 
 // HandlerOpts contains data handler options.
 type HandlerOpts[Payload any] struct {
+	// InputParser parses the input. Defaults to the parser of the source
+	// tags.
 	InputParser func(method string, input any) (*ParsedInput, error)
-	Sender      func(
+	// Sender sends the request. Defaults to sending it as JSON.
+	Sender func(
+		ctx context.Context,
 		host string,
 		url string,
 		method string,
 		inputData *RequestData,
 	) (*SendResult[Payload], error)
+	// Retry is the retry policy of the default sender. Optional.
+	Retry *RetryPolicy
+	// Auth provides the credentials of the requests of the default sender.
+	// Optional.
+	Auth AuthProvider
+	// Signer signs the requests of the default sender. Optional.
+	Signer *RequestSigner
+	// BodyEncoding is the encoding of the request bodies, i.e. BodyJSON,
+	// BodyForm or BodyMultipart. Defaults to multipart/form-data for inputs
+	// with `source:"file"` fields, application/x-www-form-urlencoded for
+	// inputs with `source:"form"` fields and JSON for other inputs. The media
+	// type of a codec, e.g. "application/xml", marshals the whole input with
+	// the codec, see RegisterCodec.
+	BodyEncoding string
+	// HTTPClient is the HTTP client of the default sender. Its Timeout is
+	// the default timeout of each attempt. Defaults to a client with
+	// DefaultTimeout. Use NewTLSClient for custom TLS and mutual TLS.
+	HTTPClient *http.Client
+	// Timeout limits the duration of the request, including its retries.
+	// Optional.
+	Timeout time.Duration
+	// Deadline is the time the request, including its retries, must be done
+	// by. Optional.
+	Deadline time.Time
 }
 
 // Send sends a request to the specified URL with the provided input, host, and
 // HTTP method and returns a Response containing the input, output, and HTTP
 // response.
 //   - input: The request input data.
-//   - url: The endpoint URL path.
+//   - url: The endpoint URL path. Parameters such as "{id}" are replaced
+//     with the input fields tagged with `source:"path"`.
 //   - host: The host server to send the request to.
 //   - method: The HTTP method (e.g., GET, POST).
 //   - opts: Optional SendOpts. If not provided, the default SendOpts will be
@@ -36,24 +71,60 @@ func Send[Input any, Output any](
 	method string,
 	urlEncoder URLEncoder,
 	opts ...HandlerOpts[Output],
+) (*Response[Input, Output], error) {
+	return SendContext(
+		context.Background(),
+		input,
+		url,
+		host,
+		method,
+		urlEncoder,
+		opts...,
+	)
+}
+
+// SendContext is like Send but sends the request with the context, so the
+// request and its retries are canceled when the context is done.
+//   - ctx: The context of the request.
+//   - input: The request input data.
+//   - url: The endpoint URL path. Parameters such as "{id}" are replaced
+//     with the input fields tagged with `source:"path"`.
+//   - host: The host server to send the request to.
+//   - method: The HTTP method (e.g., GET, POST).
+//   - opts: Optional SendOpts. If not provided, the default SendOpts will be
+//     used.
+func SendContext[Input any, Output any](
+	ctx context.Context,
+	input *Input,
+	url string,
+	host string,
+	method string,
+	urlEncoder URLEncoder,
+	opts ...HandlerOpts[Output],
 ) (*Response[Input, Output], error) {
 	useOpts := determineSendOpt(opts, urlEncoder)
 
-	parsedInput, err := useOpts.InputParser(method, input)
+	url, requestData, err := prepareRequest(
+		input,
+		url,
+		method,
+		useOpts.InputParser,
+		useOpts.BodyEncoding,
+	)
 	if err != nil {
 		return nil, err
 	}
 
-	requestData := RequestData{
-		Headers:       parsedInput.Headers,
-		Cookies:       parsedInput.Cookies,
-		URLParameters: parsedInput.URLParameters,
-	}
-	if len(parsedInput.Body) != 0 {
-		requestData.Body = parsedInput.Body
-	}
+	ctx, cancel := withTimeout(ctx, useOpts.Timeout, useOpts.Deadline)
+	defer cancel()
 
-	sendResult, err := useOpts.Sender(host, url, method, &requestData)
+	sendResult, err := useOpts.Sender(
+		ctx,
+		host,
+		url,
+		method,
+		requestData,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -65,30 +136,113 @@ func Send[Input any, Output any](
 	}, nil
 }
 
+// prepareRequest expands the URL path and parses the input into the request
+// data. With the media type of a codec as the encoding, the input is the
+// body.
+func prepareRequest(
+	input any,
+	url string,
+	method string,
+	inputParser func(method string, input any) (*ParsedInput, error),
+	encoding string,
+) (string, *RequestData, error) {
+	url, err := expandURLPath(url, input)
+	if err != nil {
+		return "", nil, err
+	}
+
+	parsedInput, err := inputParser(method, input)
+	if err != nil {
+		return "", nil, err
+	}
+
+	requestData := &RequestData{
+		Headers:       parsedInput.Headers,
+		Cookies:       parsedInput.Cookies,
+		URLParameters: parsedInput.URLParameters,
+		Form:          parsedInput.Form,
+		Files:         parsedInput.Files,
+		Encoding:      encoding,
+	}
+	if isCodecEncoding(encoding) {
+		requestData.Body = input
+	} else if len(parsedInput.Body) != 0 {
+		requestData.Body = parsedInput.Body
+	}
+	return url, requestData, nil
+}
+
+// withTimeout returns the context limited by the timeout and the deadline,
+// if set.
+func withTimeout(
+	ctx context.Context,
+	timeout time.Duration,
+	deadline time.Time,
+) (context.Context, context.CancelFunc) {
+	cancels := []context.CancelFunc{}
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		cancels = append(cancels, cancel)
+	}
+	if !deadline.IsZero() {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, deadline)
+		cancels = append(cancels, cancel)
+	}
+	return ctx, func() {
+		for _, cancel := range cancels {
+			cancel()
+		}
+	}
+}
+
 func determineSendOpt[Payload any](
 	opts []HandlerOpts[Payload],
 	urlEncoder URLEncoder,
 ) *HandlerOpts[Payload] {
+	useOpts := &HandlerOpts[Payload]{}
 	if len(opts) != 0 {
-		return &opts[0]
+		*useOpts = opts[0]
 	}
 
-	return &HandlerOpts[Payload]{
-		InputParser: parseInput,
-		Sender: func(
+	if useOpts.InputParser == nil {
+		useOpts.InputParser = parseInput
+	}
+	if useOpts.Sender == nil {
+		retry := useOpts.Retry
+		auth := useOpts.Auth
+		signer := useOpts.Signer
+		httpClient := orDefaultHTTPClient(useOpts.HTTPClient)
+		useOpts.Sender = func(
+			ctx context.Context,
 			host string,
 			url string,
 			method string,
 			inputData *RequestData,
 		) (*SendResult[Payload], error) {
 			return processAndSend[Payload](
-				&http.Client{},
+				ctx,
+				httpClient,
 				host,
 				url,
 				method,
 				inputData,
 				urlEncoder,
+				retry,
+				auth,
+				signer,
 			)
-		},
+		}
+	}
+	return useOpts
+}
+
+// orDefaultHTTPClient returns the HTTP client, or a client with
+// DefaultTimeout if it is nil.
+func orDefaultHTTPClient(httpClient *http.Client) *http.Client {
+	if httpClient == nil {
+		return &http.Client{Timeout: DefaultTimeout}
 	}
+	return httpClient
 }
@@ -1,11 +1,16 @@
 package client
 
 import (
+	"context"
 	"errors"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -28,6 +33,7 @@ type MockSender struct {
 }
 
 func (m *MockSender) ProcessAndSend(
+	ctx context.Context,
 	host string,
 	url string,
 	method string,
@@ -122,6 +128,53 @@ func TestSend_Success(t *testing.T) {
 	mockSender.AssertExpectations(t)
 }
 
+// TestSend_PathParameters tests that the path parameters of the URL are
+// replaced with the input fields.
+func TestSend_PathParameters(t *testing.T) {
+	type Input struct {
+		ID   int    `json:"id" source:"path"`
+		Name string `json:"name"`
+	}
+	mockParser := new(MockInputParser)
+	mockSender := new(MockSender)
+	mockParser.On("ParseInput", "GET", mock.Anything).
+		Return(&ParsedInput{}, nil)
+	mockSender.On(
+		"ProcessAndSend",
+		"localhost",
+		"/users/5",
+		"GET",
+		mock.Anything,
+	).Return(&http.Response{StatusCode: http.StatusOK}, "output", nil)
+
+	_, err := Send(
+		&Input{ID: 5},
+		"/users/{id}",
+		"localhost",
+		"GET",
+		&MockURLEncoder{},
+		HandlerOpts[any]{
+			InputParser: mockParser.ParseInput,
+			Sender:      mockSender.ProcessAndSend,
+		},
+	)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	mockSender.AssertExpectations(t)
+
+	_, err = Send[struct{}, any](
+		&struct{}{},
+		"/users/{id}",
+		"localhost",
+		"GET",
+		&MockURLEncoder{},
+	)
+	if err == nil || err.Error() != "missing path parameter: id" {
+		t.Errorf("expected missing path parameter error, got %v", err)
+	}
+}
+
 // TestSend_ParseInputError tests the Send function with a parse input error.
 func TestSend_ParseInputError(t *testing.T) {
 	mockParser := new(MockInputParser)
@@ -255,6 +308,7 @@ func TestDetermineSendOpt_CustomOptions(t *testing.T) {
 		nil,
 	).Twice()
 	_, err := opts.Sender(
+		context.Background(),
 		"http://localhost",
 		"/test-url",
 		"GET",
@@ -264,6 +318,7 @@ func TestDetermineSendOpt_CustomOptions(t *testing.T) {
 		t.Errorf("error sending: %v", err)
 	}
 	_, err = mockSender.ProcessAndSend(
+		context.Background(),
 		"http://localhost",
 		"/test-url",
 		"GET",
@@ -304,6 +359,7 @@ func TestDetermineSendOpt_NoOptions(t *testing.T) {
 
 	// Test that the default Sender function works with the mock server
 	_, err := opts.Sender(
+		context.Background(),
 		mockServer.URL, // Use the mock server's URL
 		"/test-url",
 		"GET",
@@ -313,3 +369,140 @@ func TestDetermineSendOpt_NoOptions(t *testing.T) {
 		t.Errorf("error sending with default Sender: %v", err)
 	}
 }
+
+// TestDetermineSendOpt_Retry tests the default sender with the retry policy
+// of the options.
+func TestDetermineSendOpt_Retry(t *testing.T) {
+	server, bodies := statusServer(t, nil, http.StatusServiceUnavailable)
+
+	opts := determineSendOpt(
+		[]HandlerOpts[map[string]any]{{
+			Retry: &RetryPolicy{
+				MaxAttempts:    2,
+				InitialBackoff: time.Millisecond,
+			},
+		}},
+		&MockURLEncoder{},
+	)
+
+	assert.NotNil(t, opts.InputParser)
+	result, err := opts.Sender(
+		context.Background(),
+		server.URL,
+		"/",
+		http.MethodGet,
+		&RequestData{},
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, result.Response.StatusCode)
+	assert.Equal(t, map[string]any{"message": "ok"}, *result.Output)
+	assert.Len(t, *bodies, 2)
+}
+
+// TestSendContext_Canceled tests canceling a request and its retries with
+// the context.
+func TestSendContext_Canceled(t *testing.T) {
+	server, bodies := statusServer(
+		t,
+		nil,
+		http.StatusServiceUnavailable,
+		http.StatusServiceUnavailable,
+	)
+	type Input struct{}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := SendContext[Input, map[string]any](
+		ctx,
+		&Input{},
+		"/",
+		server.URL,
+		http.MethodGet,
+		&MockURLEncoder{},
+	)
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Empty(t, *bodies)
+
+	ctx, cancel = context.WithTimeout(
+		context.Background(),
+		20*time.Millisecond,
+	)
+	defer cancel()
+	_, err = SendContext(
+		ctx,
+		&Input{},
+		"/",
+		server.URL,
+		http.MethodGet,
+		&MockURLEncoder{},
+		HandlerOpts[map[string]any]{
+			Retry: &RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Hour},
+		},
+	)
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Len(t, *bodies, 1)
+}
+
+// TestSendContext_Timeout tests the timeout and the deadline of the
+// requests.
+func TestSendContext_Timeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			<-r.Context().Done()
+		},
+	))
+	defer server.Close()
+	type Input struct{}
+
+	tests := []HandlerOpts[map[string]any]{
+		{Timeout: 10 * time.Millisecond},
+		{Deadline: time.Now().Add(10 * time.Millisecond)},
+		{HTTPClient: &http.Client{Timeout: 10 * time.Millisecond}},
+	}
+
+	for _, opts := range tests {
+		_, err := SendContext(
+			context.Background(),
+			&Input{},
+			"/",
+			server.URL,
+			http.MethodGet,
+			&MockURLEncoder{},
+			opts,
+		)
+
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	}
+}
+
+// TestDetermineSendOpt_HTTPClient tests the default sender with the HTTP
+// client of the options.
+func TestDetermineSendOpt_HTTPClient(t *testing.T) {
+	requests := 0
+	httpClient := &http.Client{Transport: roundTripperFunc(
+		func(r *http.Request) (*http.Response, error) {
+			requests++
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader("{}")),
+			}, nil
+		},
+	)}
+
+	opts := determineSendOpt(
+		[]HandlerOpts[map[string]any]{{HTTPClient: httpClient}},
+		&MockURLEncoder{},
+	)
+	_, err := opts.Sender(
+		context.Background(),
+		"http://example.com",
+		"/",
+		http.MethodGet,
+		&RequestData{},
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, requests)
+}
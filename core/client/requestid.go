@@ -0,0 +1,53 @@
+package client
+
+import (
+	"context"
+	"maps"
+)
+
+// RequestIDHeader is the header carrying the request ID between services.
+const RequestIDHeader = "X-Request-ID"
+
+type requestIDKey struct{}
+
+// WithRequestID returns a context carrying the request ID, so that it can be
+// propagated to the requests made to downstream services.
+//
+//   - ctx: The parent context.
+//   - requestID: The ID of the request.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestID returns the request ID of the context, or an empty string if it
+// has none.
+//
+//   - ctx: The context.
+func RequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+// PropagateRequestID returns a copy of the headers with the request ID of the
+// context set, unless the headers already contain one.
+//
+//   - ctx: The context carrying the request ID.
+//   - headers: The headers of the downstream request.
+func PropagateRequestID(
+	ctx context.Context,
+	headers map[string]string,
+) map[string]string {
+	requestID := RequestID(ctx)
+	if requestID == "" {
+		return headers
+	}
+
+	propagated := maps.Clone(headers)
+	if propagated == nil {
+		propagated = map[string]string{}
+	}
+	if propagated[RequestIDHeader] == "" {
+		propagated[RequestIDHeader] = requestID
+	}
+	return propagated
+}
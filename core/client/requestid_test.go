@@ -0,0 +1,45 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestRequestID tests storing the request ID in the context.
+func TestRequestID(t *testing.T) {
+	assert.Empty(t, RequestID(context.Background()))
+
+	ctx := WithRequestID(context.Background(), "id")
+
+	assert.Equal(t, "id", RequestID(ctx))
+}
+
+// TestPropagateRequestID tests adding the request ID to the headers.
+func TestPropagateRequestID(t *testing.T) {
+	ctx := WithRequestID(context.Background(), "id")
+	headers := map[string]string{"Accept": "application/json"}
+
+	propagated := PropagateRequestID(ctx, headers)
+
+	assert.Equal(t, map[string]string{
+		"Accept":        "application/json",
+		RequestIDHeader: "id",
+	}, propagated)
+	assert.Len(t, headers, 1)
+	assert.Equal(
+		t,
+		map[string]string{RequestIDHeader: "id"},
+		PropagateRequestID(ctx, nil),
+	)
+}
+
+// TestPropagateRequestID_Existing tests keeping an existing request ID.
+func TestPropagateRequestID_Existing(t *testing.T) {
+	ctx := WithRequestID(context.Background(), "id")
+	headers := map[string]string{RequestIDHeader: "other"}
+
+	assert.Equal(t, headers, PropagateRequestID(ctx, headers))
+	assert.Nil(t, PropagateRequestID(context.Background(), nil))
+}
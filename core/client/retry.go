@@ -0,0 +1,219 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// IdempotencyKeyHeader is the header of the idempotency key of a request. The
+// requests with an idempotency key are retried regardless of their method.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// DefaultRetryableStatuses are the status codes retried by default.
+var DefaultRetryableStatuses = []int{
+	http.StatusTooManyRequests,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+const (
+	defaultInitialBackoff = 100 * time.Millisecond
+	defaultMaxBackoff     = 10 * time.Second
+	defaultMultiplier     = 2
+)
+
+// RetryPolicy configures the retries of the requests failing with retryable
+// status codes or network errors. The waits between the attempts grow
+// exponentially, and the Retry-After header of the responses is honored. The
+// retries stop when the context of the request is done.
+//
+// Only the requests with idempotent methods, i.e. GET, HEAD, OPTIONS, TRACE,
+// PUT and DELETE, and the requests with an Idempotency-Key header are
+// retried, unless RetryNonIdempotent is set.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts, including the first.
+	// Values below 2 disable the retries.
+	MaxAttempts int
+	// InitialBackoff is the wait before the first retry. Defaults to 100 ms.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the waits between the attempts. Responses asking to
+	// retry after a longer wait are returned as is. Defaults to 10 s.
+	MaxBackoff time.Duration
+	// Multiplier grows the wait after each retry. Defaults to 2.
+	Multiplier float64
+	// Jitter randomizes the waits by up to the fraction of the wait, e.g.
+	// 0.2 for waits of 80% to 120% of the backoff.
+	Jitter float64
+	// RetryableStatuses are the retried status codes. Defaults to
+	// DefaultRetryableStatuses.
+	RetryableStatuses []int
+	// RetryNetworkErrors retries the requests failing with network errors,
+	// e.g. refused or reset connections.
+	RetryNetworkErrors bool
+	// RetryNonIdempotent retries the requests with any method, e.g. POST
+	// requests the server deduplicates by other means.
+	RetryNonIdempotent bool
+}
+
+// do sends the request, retrying it by the policy. A nil policy sends the
+// request once.
+func (p *RetryPolicy) do(
+	client *http.Client,
+	req *http.Request,
+) (*http.Response, error) {
+	if p == nil || p.MaxAttempts < 2 || !p.canRetry(req) {
+		return client.Do(req)
+	}
+
+	for attempt := 1; ; attempt++ {
+		resp, err := client.Do(req)
+		if attempt >= p.MaxAttempts {
+			return resp, err
+		}
+		wait, ok := p.retryWait(attempt, resp, err)
+		if !ok {
+			return resp, err
+		}
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		if err := sleep(req.Context(), wait); err != nil {
+			return nil, err
+		}
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = body
+		}
+	}
+}
+
+// canRetry returns whether the request is safe to retry.
+func (p *RetryPolicy) canRetry(req *http.Request) bool {
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+	if p.RetryNonIdempotent || req.Header.Get(IdempotencyKeyHeader) != "" {
+		return true
+	}
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions,
+		http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// retryWait returns the wait before the next attempt, or false if the result
+// of the attempt is not retried.
+func (p *RetryPolicy) retryWait(
+	attempt int,
+	resp *http.Response,
+	err error,
+) (time.Duration, bool) {
+	maxBackoff := p.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = defaultMaxBackoff
+	}
+
+	if err != nil {
+		if !p.RetryNetworkErrors || !isNetworkError(err) {
+			return 0, false
+		}
+		return p.backoff(attempt, maxBackoff), true
+	}
+
+	statuses := p.RetryableStatuses
+	if statuses == nil {
+		statuses = DefaultRetryableStatuses
+	}
+	if !slices.Contains(statuses, resp.StatusCode) {
+		return 0, false
+	}
+	if retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+		if retryAfter > maxBackoff {
+			return 0, false
+		}
+		return retryAfter, true
+	}
+	return p.backoff(attempt, maxBackoff), true
+}
+
+// backoff returns the exponential backoff with jitter after the attempt.
+func (p *RetryPolicy) backoff(
+	attempt int,
+	maxBackoff time.Duration,
+) time.Duration {
+	initial := p.InitialBackoff
+	if initial <= 0 {
+		initial = defaultInitialBackoff
+	}
+	multiplier := p.Multiplier
+	if multiplier <= 0 {
+		multiplier = defaultMultiplier
+	}
+
+	backoff := float64(initial) * math.Pow(multiplier, float64(attempt-1))
+	if p.Jitter > 0 {
+		backoff *= 1 + p.Jitter*(2*rand.Float64()-1)
+	}
+	return time.Duration(min(backoff, float64(maxBackoff)))
+}
+
+// isNetworkError returns whether the error is a network error, including the
+// connections closed by the server before the response.
+func isNetworkError(err error) bool {
+	if errors.Is(err, context.Canceled) ||
+		errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var opError *net.OpError
+	if errors.As(err, &opError) {
+		return true
+	}
+	var netError net.Error
+	if errors.As(err, &netError) && netError.Timeout() {
+		return true
+	}
+	return errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// parseRetryAfter parses the Retry-After header in seconds or as an HTTP
+// date.
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0), true
+	}
+	return 0, false
+}
+
+// sleep waits for the duration or until the context is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
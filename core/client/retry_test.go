@@ -0,0 +1,305 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// statusServer responds with the statuses in order, then 200 OK, recording
+// the request bodies.
+func statusServer(
+	t *testing.T,
+	headers http.Header,
+	statuses ...int,
+) (*httptest.Server, *[]string) {
+	var calls atomic.Int32
+	bodies := []string{}
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			bodies = append(bodies, string(body))
+			call := int(calls.Add(1)) - 1
+			if call < len(statuses) {
+				for key, values := range headers {
+					w.Header()[key] = values
+				}
+				w.WriteHeader(statuses[call])
+				return
+			}
+			_, _ = w.Write([]byte(`{"message":"ok"}`))
+		},
+	))
+	t.Cleanup(server.Close)
+	return server, &bodies
+}
+
+func retryRequest(
+	t *testing.T,
+	method string,
+	url string,
+	body string,
+) *http.Request {
+	req, err := http.NewRequest(method, url, bytes.NewReader([]byte(body)))
+	assert.NoError(t, err)
+	return req
+}
+
+// TestRetryPolicy_do tests retrying the retryable statuses with the request
+// body resent.
+func TestRetryPolicy_do(t *testing.T) {
+	server, bodies := statusServer(
+		t,
+		nil,
+		http.StatusServiceUnavailable,
+		http.StatusBadGateway,
+	)
+	policy := &RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond}
+
+	resp, err := policy.do(
+		http.DefaultClient,
+		retryRequest(t, http.MethodPut, server.URL, "data"),
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, []string{"data", "data", "data"}, *bodies)
+}
+
+// TestRetryPolicy_do_MaxAttempts tests returning the last response after
+// the maximum attempts.
+func TestRetryPolicy_do_MaxAttempts(t *testing.T) {
+	server, bodies := statusServer(
+		t,
+		nil,
+		http.StatusServiceUnavailable,
+		http.StatusServiceUnavailable,
+	)
+	policy := &RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond}
+
+	resp, err := policy.do(
+		http.DefaultClient,
+		retryRequest(t, http.MethodGet, server.URL, ""),
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.Len(t, *bodies, 2)
+}
+
+// TestRetryPolicy_do_NotRetried tests the requests and responses that are
+// not retried.
+func TestRetryPolicy_do_NotRetried(t *testing.T) {
+	tests := []struct {
+		name    string
+		method  string
+		headers http.Header
+		status  int
+		policy  RetryPolicy
+	}{
+		{
+			name:   "non-idempotent method",
+			method: http.MethodPost,
+			status: http.StatusServiceUnavailable,
+		},
+		{
+			name:   "status not retryable",
+			method: http.MethodGet,
+			status: http.StatusInternalServerError,
+		},
+		{
+			name:    "Retry-After above max backoff",
+			method:  http.MethodGet,
+			headers: http.Header{"Retry-After": {"60"}},
+			status:  http.StatusTooManyRequests,
+		},
+		{
+			name:   "disabled",
+			method: http.MethodGet,
+			status: http.StatusServiceUnavailable,
+			policy: RetryPolicy{MaxAttempts: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, bodies := statusServer(t, tt.headers, tt.status)
+			policy := tt.policy
+			if policy.MaxAttempts == 0 {
+				policy.MaxAttempts = 3
+			}
+
+			resp, err := policy.do(
+				http.DefaultClient,
+				retryRequest(t, tt.method, server.URL, ""),
+			)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.status, resp.StatusCode)
+			assert.Len(t, *bodies, 1)
+		})
+	}
+}
+
+// TestRetryPolicy_do_NonIdempotent tests retrying non-idempotent requests
+// with an idempotency key or when allowed by the policy.
+func TestRetryPolicy_do_NonIdempotent(t *testing.T) {
+	server, bodies := statusServer(t, nil, http.StatusServiceUnavailable)
+	req := retryRequest(t, http.MethodPost, server.URL, "a")
+	req.Header.Set(IdempotencyKeyHeader, "key")
+	policy := &RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond}
+
+	resp, err := policy.do(http.DefaultClient, req)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Len(t, *bodies, 2)
+
+	server, bodies = statusServer(t, nil, http.StatusServiceUnavailable)
+	policy.RetryNonIdempotent = true
+
+	resp, err = policy.do(
+		http.DefaultClient,
+		retryRequest(t, http.MethodPatch, server.URL, "a"),
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Len(t, *bodies, 2)
+}
+
+// TestRetryPolicy_do_RetryAfter tests waiting for the Retry-After header.
+func TestRetryPolicy_do_RetryAfter(t *testing.T) {
+	server, _ := statusServer(
+		t,
+		http.Header{"Retry-After": {"0"}},
+		http.StatusTooManyRequests,
+	)
+	policy := &RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Hour}
+
+	resp, err := policy.do(
+		http.DefaultClient,
+		retryRequest(t, http.MethodGet, server.URL, ""),
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+// TestRetryPolicy_do_NetworkError tests retrying the network errors.
+func TestRetryPolicy_do_NetworkError(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	address := listener.Addr().String()
+	listener.Close()
+	policy := &RetryPolicy{
+		MaxAttempts:    2,
+		InitialBackoff: time.Millisecond,
+	}
+
+	_, err = policy.do(
+		http.DefaultClient,
+		retryRequest(t, http.MethodGet, "http://"+address, ""),
+	)
+
+	assert.Error(t, err)
+	assert.True(t, isNetworkError(err))
+
+	policy.RetryNetworkErrors = true
+	var attempts atomic.Int32
+	client := &http.Client{Transport: roundTripperFunc(
+		func(r *http.Request) (*http.Response, error) {
+			if attempts.Add(1) == 1 {
+				return nil, &net.OpError{Op: "dial", Err: errors.New("refused")}
+			}
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(bytes.NewReader(nil)),
+			}, nil
+		},
+	)}
+
+	resp, err := policy.do(
+		client,
+		retryRequest(t, http.MethodGet, "http://"+address, ""),
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, int32(2), attempts.Load())
+}
+
+// TestRetryPolicy_do_Canceled tests stopping the waits when the context of
+// the request is canceled.
+func TestRetryPolicy_do_Canceled(t *testing.T) {
+	server, bodies := statusServer(t, nil, http.StatusServiceUnavailable)
+	ctx, cancel := context.WithCancel(context.Background())
+	req := retryRequest(t, http.MethodGet, server.URL, "").WithContext(ctx)
+	policy := &RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Hour}
+	time.AfterFunc(10*time.Millisecond, cancel)
+
+	_, err := policy.do(http.DefaultClient, req)
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Len(t, *bodies, 1)
+}
+
+// TestRetryPolicy_backoff tests the exponential backoffs and their jitter.
+func TestRetryPolicy_backoff(t *testing.T) {
+	policy := &RetryPolicy{InitialBackoff: time.Second}
+
+	assert.Equal(t, time.Second, policy.backoff(1, time.Minute))
+	assert.Equal(t, 4*time.Second, policy.backoff(3, time.Minute))
+	assert.Equal(t, 3*time.Second, policy.backoff(10, 3*time.Second))
+
+	policy.Jitter = 0.5
+	for range 100 {
+		backoff := policy.backoff(2, time.Minute)
+		assert.GreaterOrEqual(t, backoff, time.Second)
+		assert.LessOrEqual(t, backoff, 3*time.Second)
+	}
+}
+
+// TestParseRetryAfter tests parsing the Retry-After header.
+func TestParseRetryAfter(t *testing.T) {
+	wait, ok := parseRetryAfter("3")
+	assert.True(t, ok)
+	assert.Equal(t, 3*time.Second, wait)
+
+	wait, ok = parseRetryAfter(
+		time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat),
+	)
+	assert.True(t, ok)
+	assert.Equal(t, time.Duration(0), wait)
+
+	_, ok = parseRetryAfter("soon")
+	assert.False(t, ok)
+	_, ok = parseRetryAfter("")
+	assert.False(t, ok)
+}
+
+// TestIsNetworkError tests classifying the errors.
+func TestIsNetworkError(t *testing.T) {
+	assert.True(t, isNetworkError(&url.Error{Err: io.EOF}))
+	assert.True(t, isNetworkError(&net.OpError{Err: errors.New("reset")}))
+	assert.False(t, isNetworkError(&url.Error{Err: context.Canceled}))
+	assert.False(t, isNetworkError(&url.Error{
+		Err: errors.New("unsupported protocol scheme"),
+	}))
+}
+
+type roundTripperFunc func(r *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
@@ -0,0 +1,240 @@
+package client
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pakkasys/fluidapi/core/api"
+)
+
+// SignatureHeader is the header of the HMAC signatures of the requests, in
+// the form "t=<unix timestamp>,v1=<hex HMAC-SHA256>".
+const SignatureHeader = "X-Signature"
+
+// DefaultMaxSignedBodySize is the default maximum size of the bodies of the
+// verified requests.
+const DefaultMaxSignedBodySize = 10 << 20
+
+var (
+	InvalidRequestSignatureError = api.NewError[any](
+		"INVALID_REQUEST_SIGNATURE",
+	)
+	RequestSignatureExpiredError = api.NewError[any](
+		"REQUEST_SIGNATURE_EXPIRED",
+	)
+	SignedBodyTooLargeError = api.NewError[any]("SIGNED_BODY_TOO_LARGE")
+)
+
+// RequestSigner signs the requests with HMAC-SHA256 and verifies the
+// signatures. The signature covers the method, the path and query, the
+// timestamp and the SHA-256 hash of the body of the request, in the
+// canonical form:
+//
+//	<method>\n<escaped path>\n<raw query>\n<unix timestamp>\n<hex body hash>
+type RequestSigner struct {
+	// Key is the secret HMAC key shared by the client and the server
+	Key []byte
+	// Header is the header of the signature. Defaults to SignatureHeader.
+	Header string
+	// MaxAge is the maximum age of the verified signatures, and the
+	// tolerated clock difference of signatures from the future. It must be
+	// positive to verify requests, so that the signed requests cannot be
+	// replayed forever.
+	MaxAge time.Duration
+	// MaxBodySize is the maximum size of the bodies of the verified
+	// requests. Defaults to DefaultMaxSignedBodySize.
+	MaxBodySize int64
+	// NowFn is an optional function returning the current time
+	NowFn func() time.Time
+}
+
+// NewRequestSigner creates a new request signer.
+//
+//   - key: The secret HMAC key.
+//   - maxAge: The maximum age of the verified signatures. Must be positive
+//     to verify requests.
+func NewRequestSigner(key []byte, maxAge time.Duration) *RequestSigner {
+	return &RequestSigner{
+		Key:    key,
+		MaxAge: maxAge,
+	}
+}
+
+// Sign sets the signature header of the request. The body of the request is
+// read and replaced, unless it can be read again with GetBody.
+//
+//   - req: The request to sign.
+func (s *RequestSigner) Sign(req *http.Request) error {
+	body, err := readRequestBody(req, 0)
+	if err != nil {
+		return err
+	}
+	timestamp := strconv.FormatInt(s.now().Unix(), 10)
+	req.Header.Set(
+		s.header(),
+		"t="+timestamp+",v1="+s.signature(req, timestamp, body),
+	)
+	return nil
+}
+
+// VerifyRequest verifies the signature header of the request. The body of
+// the request is read and replaced, so it can be read again by the handlers.
+//
+//   - req: The request to verify.
+//
+// Returns:
+//   - InvalidRequestSignatureError if the signature is missing or invalid.
+//   - RequestSignatureExpiredError if the signature is older than MaxAge.
+//   - SignedBodyTooLargeError if the body is larger than MaxBodySize.
+//   - An error if MaxAge is not positive.
+func (s *RequestSigner) VerifyRequest(req *http.Request) error {
+	if s.MaxAge <= 0 {
+		return fmt.Errorf("maximum signature age must be positive")
+	}
+
+	parsed, ok := ParseSignatureHeader(req.Header.Get(s.header()))
+	if !ok {
+		return InvalidRequestSignatureError
+	}
+
+	body, err := readRequestBody(req, s.maxBodySize())
+	if err != nil {
+		return err
+	}
+	expected := s.signature(req, parsed.Timestamp, body)
+	if !hmac.Equal([]byte(parsed.Signature), []byte(expected)) {
+		return InvalidRequestSignatureError
+	}
+
+	age := s.now().Sub(parsed.Time)
+	if age > s.MaxAge || age < -s.MaxAge {
+		return RequestSignatureExpiredError
+	}
+	return nil
+}
+
+// ParsedSignature is a parsed signature header.
+type ParsedSignature struct {
+	// Timestamp is the timestamp as sent, as covered by the signature
+	Timestamp string
+	// Time is the time of the timestamp
+	Time time.Time
+	// Signature is the hex encoded v1 signature
+	Signature string
+}
+
+// ParseSignatureHeader parses a signature header in the form
+// "t=<unix timestamp>,v1=<hex signature>". It is shared by the request
+// signatures and the webhook signatures.
+//
+//   - header: The value of the signature header.
+//
+// Returns false if the timestamp is invalid or the signature is missing.
+func ParseSignatureHeader(header string) (ParsedSignature, bool) {
+	var parsed ParsedSignature
+	for _, part := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(part, "=")
+		switch key {
+		case "t":
+			parsed.Timestamp = value
+		case "v1":
+			parsed.Signature = value
+		}
+	}
+	seconds, err := strconv.ParseInt(parsed.Timestamp, 10, 64)
+	if err != nil || parsed.Signature == "" {
+		return ParsedSignature{}, false
+	}
+	parsed.Time = time.Unix(seconds, 0)
+	return parsed, true
+}
+
+func (s *RequestSigner) signature(
+	req *http.Request,
+	timestamp string,
+	body []byte,
+) string {
+	bodyHash := sha256.Sum256(body)
+
+	mac := hmac.New(sha256.New, s.Key)
+	mac.Write([]byte(req.Method))
+	mac.Write([]byte{'\n'})
+	mac.Write([]byte(req.URL.EscapedPath()))
+	mac.Write([]byte{'\n'})
+	mac.Write([]byte(req.URL.RawQuery))
+	mac.Write([]byte{'\n'})
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte{'\n'})
+	mac.Write([]byte(hex.EncodeToString(bodyHash[:])))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (s *RequestSigner) header() string {
+	if s.Header != "" {
+		return s.Header
+	}
+	return SignatureHeader
+}
+
+func (s *RequestSigner) maxBodySize() int64 {
+	if s.MaxBodySize > 0 {
+		return s.MaxBodySize
+	}
+	return DefaultMaxSignedBodySize
+}
+
+func (s *RequestSigner) now() time.Time {
+	if s.NowFn != nil {
+		return s.NowFn()
+	}
+	return time.Now()
+}
+
+// readRequestBody returns the body of the request, replacing the read body.
+// Bodies larger than a positive maximum size are rejected with
+// SignedBodyTooLargeError.
+func readRequestBody(req *http.Request, maxSize int64) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		defer body.Close()
+		return readLimited(body, maxSize)
+	}
+
+	body, err := readLimited(req.Body, maxSize)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+// readLimited reads a body of at most maxSize bytes, or of any size if
+// maxSize is zero.
+func readLimited(body io.Reader, maxSize int64) ([]byte, error) {
+	if maxSize <= 0 {
+		return io.ReadAll(body)
+	}
+	data, err := io.ReadAll(io.LimitReader(body, maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > maxSize {
+		return nil, SignedBodyTooLargeError
+	}
+	return data, nil
+}
@@ -0,0 +1,185 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func signedRequest(
+	t *testing.T,
+	signer *RequestSigner,
+	method string,
+	target string,
+	body string,
+) *http.Request {
+	r := httptest.NewRequest(method, target, strings.NewReader(body))
+	assert.NoError(t, signer.Sign(r))
+	return r
+}
+
+// TestRequestSigner tests verifying the signed requests with their bodies
+// restored.
+func TestRequestSigner(t *testing.T) {
+	signer := NewRequestSigner([]byte("secret"), time.Minute)
+	r := signedRequest(t, signer, http.MethodPost, "/users?a=1", `{"a":1}`)
+
+	assert.Regexp(t, `^t=\d+,v1=[0-9a-f]{64}$`, r.Header.Get(SignatureHeader))
+	assert.NoError(t, signer.VerifyRequest(r))
+	body, err := io.ReadAll(r.Body)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"a":1}`, string(body))
+}
+
+// TestRequestSigner_Tampered tests rejecting the requests differing from the
+// signed request.
+func TestRequestSigner_Tampered(t *testing.T) {
+	signer := NewRequestSigner([]byte("secret"), time.Minute)
+	signed := signedRequest(t, signer, http.MethodPost, "/users?a=1", "body")
+
+	tests := map[string]*http.Request{
+		"method": httptest.NewRequest(http.MethodPut, "/users?a=1", nil),
+		"path":   httptest.NewRequest(http.MethodPost, "/admins?a=1", nil),
+		"query":  httptest.NewRequest(http.MethodPost, "/users?a=2", nil),
+		"body": httptest.NewRequest(
+			http.MethodPost,
+			"/users?a=1",
+			strings.NewReader("other"),
+		),
+		"unsigned": httptest.NewRequest(http.MethodPost, "/users?a=1", nil),
+	}
+
+	for name, r := range tests {
+		if name != "unsigned" {
+			r.Header.Set(SignatureHeader, signed.Header.Get(SignatureHeader))
+		}
+		assert.ErrorIs(
+			t,
+			signer.VerifyRequest(r),
+			InvalidRequestSignatureError,
+			name,
+		)
+	}
+
+	other := NewRequestSigner([]byte("other"), time.Minute)
+	assert.ErrorIs(
+		t,
+		other.VerifyRequest(signed),
+		InvalidRequestSignatureError,
+	)
+}
+
+// TestRequestSigner_Expired tests rejecting the signatures older than the
+// maximum age or from the future.
+func TestRequestSigner_Expired(t *testing.T) {
+	now := time.Now()
+	signer := &RequestSigner{
+		Key:    []byte("secret"),
+		Header: "X-Custom-Signature",
+		MaxAge: time.Minute,
+		NowFn:  func() time.Time { return now },
+	}
+	r := signedRequest(t, signer, http.MethodGet, "/", "")
+	assert.NotEmpty(t, r.Header.Get("X-Custom-Signature"))
+
+	for _, offset := range []time.Duration{2 * time.Minute, -2 * time.Minute} {
+		now = time.Now().Add(offset)
+		assert.ErrorIs(
+			t,
+			signer.VerifyRequest(r),
+			RequestSignatureExpiredError,
+		)
+	}
+}
+
+// TestRequestSigner_NoMaxAge tests rejecting the verification of the
+// signers without a maximum age.
+func TestRequestSigner_NoMaxAge(t *testing.T) {
+	signer := NewRequestSigner([]byte("secret"), 0)
+	r := signedRequest(t, signer, http.MethodGet, "/", "")
+
+	assert.EqualError(
+		t,
+		signer.VerifyRequest(r),
+		"maximum signature age must be positive",
+	)
+}
+
+// TestRequestSigner_BodyTooLarge tests rejecting the bodies larger than the
+// maximum body size.
+func TestRequestSigner_BodyTooLarge(t *testing.T) {
+	signer := NewRequestSigner([]byte("secret"), time.Minute)
+	signer.MaxBodySize = 4
+
+	for body, expected := range map[string]error{
+		"1234":  nil,
+		"12345": SignedBodyTooLargeError,
+	} {
+		r := signedRequest(t, signer, http.MethodPost, "/", body)
+		r.GetBody = nil
+
+		err := signer.VerifyRequest(r)
+
+		if expected == nil {
+			assert.NoError(t, err, body)
+		} else {
+			assert.ErrorIs(t, err, expected, body)
+		}
+	}
+}
+
+// TestParseSignatureHeader tests parsing the signature headers.
+func TestParseSignatureHeader(t *testing.T) {
+	parsed, ok := ParseSignatureHeader("t=100,v1=abc")
+	assert.True(t, ok)
+	assert.Equal(t, ParsedSignature{
+		Timestamp: "100",
+		Time:      time.Unix(100, 0),
+		Signature: "abc",
+	}, parsed)
+
+	for _, header := range []string{"", "t=100", "v1=abc", "t=x,v1=abc"} {
+		_, ok := ParseSignatureHeader(header)
+		assert.False(t, ok, header)
+	}
+}
+
+// TestSendContext_Signer tests signing the requests of the client.
+func TestSendContext_Signer(t *testing.T) {
+	signer := NewRequestSigner([]byte("secret"), time.Minute)
+	var verifyErr error
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			verifyErr = signer.VerifyRequest(r)
+			_, _ = w.Write([]byte(`{}`))
+		},
+	))
+	defer server.Close()
+	type Input struct {
+		ID   int    `json:"id" source:"url"`
+		Name string `json:"name"`
+	}
+	urlEncoder := &MockURLEncoder{}
+	urlEncoder.On("EncodeURL", map[string]any{"id": int64(1)}).
+		Return(url.Values{"id": {"1"}}, nil)
+
+	_, err := SendContext(
+		context.Background(),
+		&Input{ID: 1, Name: "test"},
+		"/users",
+		server.URL,
+		http.MethodPost,
+		urlEncoder,
+		HandlerOpts[map[string]any]{Signer: signer},
+	)
+
+	assert.NoError(t, err)
+	assert.NoError(t, verifyErr)
+}
@@ -0,0 +1,186 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"iter"
+	"net/http"
+	"sync"
+
+	"github.com/pakkasys/fluidapi/core/api"
+)
+
+// maxNDJSONLine is the maximum length of the lines of the NDJSON streams.
+const maxNDJSONLine = 16 << 20
+
+// StreamResponse is the response of a streamed request. The body is not read
+// by the client, so the caller must close it.
+type StreamResponse[Input any] struct {
+	Response *http.Response // The HTTP response object.
+	Input    *Input         // The original input data for the request.
+	Body     io.ReadCloser  // The unread response body.
+}
+
+// SendStream sends a request like Send but returns the response body unread,
+// e.g. for the download and export endpoints with payloads too large to be
+// buffered. The body is returned for any status code, and its timeouts are
+// canceled when it is closed.
+//
+// The Timeout of the HTTP client covers reading the body, so a client
+// without a timeout, limited by the context, suits long downloads.
+//   - input: The request input data.
+//   - url: The endpoint URL path. Parameters such as "{id}" are replaced
+//     with the input fields tagged with `source:"path"`.
+//   - host: The host server to send the request to.
+//   - method: The HTTP method (e.g., GET, POST).
+//   - opts: Optional SendOpts. The Sender of the options is not used.
+func SendStream[Input any](
+	input *Input,
+	url string,
+	host string,
+	method string,
+	urlEncoder URLEncoder,
+	opts ...HandlerOpts[any],
+) (*StreamResponse[Input], error) {
+	return SendStreamContext(
+		context.Background(),
+		input,
+		url,
+		host,
+		method,
+		urlEncoder,
+		opts...,
+	)
+}
+
+// SendStreamContext is like SendStream but sends the request with the
+// context, so reading the body fails when the context is done.
+//   - ctx: The context of the request.
+//   - input: The request input data.
+//   - url: The endpoint URL path.
+//   - host: The host server to send the request to.
+//   - method: The HTTP method (e.g., GET, POST).
+//   - opts: Optional SendOpts. The Sender of the options is not used.
+func SendStreamContext[Input any](
+	ctx context.Context,
+	input *Input,
+	url string,
+	host string,
+	method string,
+	urlEncoder URLEncoder,
+	opts ...HandlerOpts[any],
+) (*StreamResponse[Input], error) {
+	useOpts := determineSendOpt(opts, urlEncoder)
+
+	url, requestData, err := prepareRequest(
+		input,
+		url,
+		method,
+		useOpts.InputParser,
+		useOpts.BodyEncoding,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := withTimeout(ctx, useOpts.Timeout, useOpts.Deadline)
+	resp, err := sendRequest(
+		ctx,
+		orDefaultHTTPClient(useOpts.HTTPClient),
+		host,
+		url,
+		method,
+		requestData,
+		urlEncoder,
+		useOpts.Retry,
+		useOpts.Auth,
+		useOpts.Signer,
+	)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	resp.Body = &cancelingBody{ReadCloser: resp.Body, cancel: cancel}
+	return &StreamResponse[Input]{
+		Response: resp,
+		Input:    input,
+		Body:     resp.Body,
+	}, nil
+}
+
+// cancelingBody cancels the context of the request when the body is closed.
+type cancelingBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+	once   sync.Once
+}
+
+func (b *cancelingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.cancel)
+	return err
+}
+
+// DecodeNDJSON returns an iterator decoding the items of a newline-delimited
+// JSON stream, e.g. the body of a StreamResponse. Empty lines are skipped.
+// The iteration ends with an error on an invalid line, a read error or an
+// error line of the form {"error":{"id":"..."}} written by the server when
+// the stream fails, which is returned as an *api.Error.
+//
+//   - r: The stream.
+//
+// Example:
+//
+//	for user, err := range client.DecodeNDJSON[User](response.Body) {
+//		if err != nil {
+//			return err
+//		}
+//		...
+//	}
+func DecodeNDJSON[T any](r io.Reader) iter.Seq2[*T, error] {
+	return func(yield func(*T, error) bool) {
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(nil, maxNDJSONLine)
+		for scanner.Scan() {
+			line := bytes.TrimSpace(scanner.Bytes())
+			if len(line) == 0 {
+				continue
+			}
+			if err := streamError(line); err != nil {
+				yield(nil, err)
+				return
+			}
+
+			item := new(T)
+			if err := json.Unmarshal(line, item); err != nil {
+				yield(nil, err)
+				return
+			}
+			if !yield(item, nil) {
+				return
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			yield(nil, err)
+		}
+	}
+}
+
+// streamError returns the error of an NDJSON error line, or nil if the line
+// is not one.
+func streamError(line []byte) error {
+	var object map[string]json.RawMessage
+	if json.Unmarshal(line, &object) != nil || len(object) != 1 {
+		return nil
+	}
+	var apiError api.Error[any]
+	if json.Unmarshal(object["error"], &apiError) != nil ||
+		apiError.ID == "" {
+		return nil
+	}
+	return &apiError
+}
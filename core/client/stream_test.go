@@ -0,0 +1,152 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pakkasys/fluidapi/core/api"
+	"github.com/stretchr/testify/assert"
+)
+
+type streamedItem struct {
+	Name string `json:"name"`
+}
+
+// TestSendStreamContext tests returning the response body unread.
+func TestSendStreamContext(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/x-ndjson")
+			_, _ = w.Write([]byte("{\"name\":\"a\"}\n\n{\"name\":\"b\"}\n"))
+		},
+	))
+	defer server.Close()
+	type Input struct{}
+
+	response, err := SendStreamContext(
+		context.Background(),
+		&Input{},
+		"/export",
+		server.URL,
+		http.MethodGet,
+		&MockURLEncoder{},
+		HandlerOpts[any]{Timeout: time.Minute},
+	)
+	assert.NoError(t, err)
+	defer response.Body.Close()
+
+	assert.Equal(t, http.StatusOK, response.Response.StatusCode)
+	var names []string
+	for item, err := range DecodeNDJSON[streamedItem](response.Body) {
+		assert.NoError(t, err)
+		names = append(names, item.Name)
+	}
+	assert.Equal(t, []string{"a", "b"}, names)
+}
+
+// TestSendStream_Error tests the failing requests.
+func TestSendStream_Error(t *testing.T) {
+	type Input struct{}
+
+	_, err := SendStream(
+		&Input{},
+		"/",
+		"http://127.0.0.1:0",
+		http.MethodGet,
+		&MockURLEncoder{},
+	)
+
+	assert.Error(t, err)
+}
+
+// TestCancelingBody tests canceling the context when the body is closed.
+func TestCancelingBody(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	body := &cancelingBody{
+		ReadCloser: io.NopCloser(strings.NewReader("")),
+		cancel:     cancel,
+	}
+
+	assert.NoError(t, body.Close())
+	assert.NoError(t, body.Close())
+	assert.ErrorIs(t, ctx.Err(), context.Canceled)
+}
+
+// TestDecodeNDJSON_Error tests ending the iteration with the errors of the
+// streams.
+func TestDecodeNDJSON_Error(t *testing.T) {
+	tests := map[string]struct {
+		stream string
+		check  func(t *testing.T, err error)
+	}{
+		"error line": {
+			stream: "{\"name\":\"a\"}\n" +
+				"{\"error\":{\"id\":\"INTERNAL_SERVER_ERROR\"}}\n",
+			check: func(t *testing.T, err error) {
+				var apiError *api.Error[any]
+				assert.True(t, errors.As(err, &apiError))
+				assert.Equal(t, "INTERNAL_SERVER_ERROR", apiError.ID)
+			},
+		},
+		"invalid line": {
+			stream: "{\"name\":\"a\"}\n{invalid\n",
+			check: func(t *testing.T, err error) {
+				assert.Error(t, err)
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var items []*streamedItem
+			var errs []error
+			for item, err := range DecodeNDJSON[streamedItem](
+				strings.NewReader(tt.stream),
+			) {
+				items = append(items, item)
+				errs = append(errs, err)
+			}
+
+			assert.Len(t, items, 2)
+			assert.Equal(t, "a", items[0].Name)
+			assert.Nil(t, items[1])
+			assert.NoError(t, errs[0])
+			tt.check(t, errs[1])
+		})
+	}
+}
+
+// TestDecodeNDJSON_Break tests stopping the iteration early.
+func TestDecodeNDJSON_Break(t *testing.T) {
+	count := 0
+	for range DecodeNDJSON[streamedItem](
+		strings.NewReader("{\"name\":\"a\"}\n{\"name\":\"b\"}\n"),
+	) {
+		count++
+		break
+	}
+
+	assert.Equal(t, 1, count)
+}
+
+// TestDecodeNDJSON_ErrorField tests that the items with other fields besides
+// an error field are decoded as items.
+func TestDecodeNDJSON_ErrorField(t *testing.T) {
+	type result struct {
+		Name  string `json:"name"`
+		Error string `json:"error"`
+	}
+
+	for item, err := range DecodeNDJSON[result](
+		strings.NewReader(`{"name":"a","error":"failed"}`),
+	) {
+		assert.NoError(t, err)
+		assert.Equal(t, "failed", item.Error)
+	}
+}
@@ -0,0 +1,94 @@
+package client
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+)
+
+// TLSConfig configures the TLS of the HTTP clients, e.g. for mutual TLS
+// between services.
+type TLSConfig struct {
+	// Config is the base TLS configuration, which is cloned. Optional.
+	Config *tls.Config
+	// CAFile is the path of a PEM bundle of the CA certificates trusted
+	// instead of the system roots. Optional.
+	CAFile string
+	// CAPEM is a PEM bundle of the trusted CA certificates, added to the
+	// certificates of CAFile. Optional.
+	CAPEM []byte
+	// CertFile and KeyFile are the paths of the PEM client certificate and
+	// its key presented to the servers requiring client certificates.
+	// Optional.
+	CertFile string
+	KeyFile  string
+	// Certificates are the client certificates added to the certificate of
+	// CertFile and KeyFile. Optional.
+	Certificates []tls.Certificate
+	// ServerName is the name the server certificates are verified against.
+	// Defaults to the host of the requests.
+	ServerName string
+}
+
+// Build returns the TLS configuration. It returns an error if a file cannot
+// be read or contains no valid certificates.
+func (c *TLSConfig) Build() (*tls.Config, error) {
+	config := &tls.Config{MinVersion: tls.VersionTLS12}
+	if c.Config != nil {
+		config = c.Config.Clone()
+	}
+	if c.ServerName != "" {
+		config.ServerName = c.ServerName
+	}
+
+	if c.CAFile != "" || len(c.CAPEM) != 0 {
+		pool := x509.NewCertPool()
+		if c.CAFile != "" {
+			pem, err := os.ReadFile(c.CAFile)
+			if err != nil {
+				return nil, fmt.Errorf("read CA file: %w", err)
+			}
+			if !pool.AppendCertsFromPEM(pem) {
+				return nil, fmt.Errorf(
+					"no certificates in CA file: %s",
+					c.CAFile,
+				)
+			}
+		}
+		if len(c.CAPEM) != 0 && !pool.AppendCertsFromPEM(c.CAPEM) {
+			return nil, fmt.Errorf("no certificates in CA PEM")
+		}
+		config.RootCAs = pool
+	}
+
+	if c.CertFile != "" || c.KeyFile != "" {
+		certificate, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("load client certificate: %w", err)
+		}
+		config.Certificates = append(config.Certificates, certificate)
+	}
+	config.Certificates = append(config.Certificates, c.Certificates...)
+
+	return config, nil
+}
+
+// NewTLSClient returns an HTTP client with the TLS configuration and
+// DefaultTimeout, to be set as the HTTPClient of HandlerOpts. The client
+// should be reused across the requests to reuse its connections.
+//   - config: The TLS configuration.
+func NewTLSClient(config *TLSConfig) (*http.Client, error) {
+	if config == nil {
+		panic("config cannot be nil")
+	}
+	tlsConfig, err := config.Build()
+	if err != nil {
+		return nil, err
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	return &http.Client{Transport: transport, Timeout: DefaultTimeout}, nil
+}
@@ -0,0 +1,182 @@
+package client
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// clientCertificate returns a self-signed client certificate and its PEM
+// certificate and key.
+func clientCertificate(t *testing.T) (*x509.Certificate, []byte, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "client"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(
+		rand.Reader,
+		template,
+		template,
+		&key.PublicKey,
+		key,
+	)
+	assert.NoError(t, err)
+	certificate, err := x509.ParseCertificate(der)
+	assert.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	assert.NoError(t, err)
+
+	return certificate,
+		pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+// mutualTLSServer returns a TLS server requiring the client certificate.
+func mutualTLSServer(
+	t *testing.T,
+	clientCert *x509.Certificate,
+) *httptest.Server {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(r.TLS.PeerCertificates[0].Subject.CommonName))
+		},
+	))
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(clientCert)
+	server.TLS = &tls.Config{
+		ClientAuth: tls.RequireAndVerifyClientCert,
+		ClientCAs:  clientCAs,
+	}
+	server.StartTLS()
+	t.Cleanup(server.Close)
+	return server
+}
+
+func serverCAPEM(server *httptest.Server) []byte {
+	return pem.EncodeToMemory(&pem.Block{
+		Type:  "CERTIFICATE",
+		Bytes: server.Certificate().Raw,
+	})
+}
+
+// TestNewTLSClient tests mutual TLS with the certificates of the files.
+func TestNewTLSClient(t *testing.T) {
+	clientCert, certPEM, keyPEM := clientCertificate(t)
+	server := mutualTLSServer(t, clientCert)
+
+	dir := t.TempDir()
+	config := &TLSConfig{
+		CAFile:   filepath.Join(dir, "ca.pem"),
+		CertFile: filepath.Join(dir, "client.pem"),
+		KeyFile:  filepath.Join(dir, "client.key"),
+	}
+	assert.NoError(t, os.WriteFile(config.CAFile, serverCAPEM(server), 0o600))
+	assert.NoError(t, os.WriteFile(config.CertFile, certPEM, 0o600))
+	assert.NoError(t, os.WriteFile(config.KeyFile, keyPEM, 0o600))
+
+	httpClient, err := NewTLSClient(config)
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultTimeout, httpClient.Timeout)
+
+	resp, err := httpClient.Get(server.URL)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+// TestNewTLSClient_NoClientCertificate tests the server rejecting the
+// requests without the client certificate.
+func TestNewTLSClient_NoClientCertificate(t *testing.T) {
+	clientCert, _, _ := clientCertificate(t)
+	server := mutualTLSServer(t, clientCert)
+
+	httpClient, err := NewTLSClient(&TLSConfig{CAPEM: serverCAPEM(server)})
+	assert.NoError(t, err)
+
+	_, err = httpClient.Get(server.URL)
+	assert.Error(t, err)
+}
+
+// TestNewTLSClient_UnknownCA tests the servers not signed by the CAs being
+// rejected.
+func TestNewTLSClient_UnknownCA(t *testing.T) {
+	server := httptest.NewTLSServer(http.NotFoundHandler())
+	defer server.Close()
+	_, caPEM, _ := clientCertificate(t)
+
+	httpClient, err := NewTLSClient(&TLSConfig{CAPEM: caPEM})
+	assert.NoError(t, err)
+
+	_, err = httpClient.Get(server.URL)
+	var unknownAuthority x509.UnknownAuthorityError
+	assert.ErrorAs(t, err, &unknownAuthority)
+}
+
+// TestNewTLSClient_NilConfig tests the panic of a nil configuration.
+func TestNewTLSClient_NilConfig(t *testing.T) {
+	assert.PanicsWithValue(t, "config cannot be nil", func() {
+		_, _ = NewTLSClient(nil)
+	})
+}
+
+// TestTLSConfig_Build tests the base configuration and certificates.
+func TestTLSConfig_Build(t *testing.T) {
+	_, certPEM, keyPEM := clientCertificate(t)
+	certificate, err := tls.X509KeyPair(certPEM, keyPEM)
+	assert.NoError(t, err)
+	base := &tls.Config{MinVersion: tls.VersionTLS13}
+
+	config, err := (&TLSConfig{
+		Config:       base,
+		CAPEM:        certPEM,
+		Certificates: []tls.Certificate{certificate},
+		ServerName:   "service.internal",
+	}).Build()
+
+	assert.NoError(t, err)
+	assert.Equal(t, uint16(tls.VersionTLS13), config.MinVersion)
+	assert.Equal(t, "service.internal", config.ServerName)
+	assert.NotNil(t, config.RootCAs)
+	assert.Len(t, config.Certificates, 1)
+	assert.Empty(t, base.ServerName)
+}
+
+// TestTLSConfig_Build_Errors tests the invalid files and certificates.
+func TestTLSConfig_Build_Errors(t *testing.T) {
+	dir := t.TempDir()
+	invalidFile := filepath.Join(dir, "invalid.pem")
+	assert.NoError(t, os.WriteFile(invalidFile, []byte("invalid"), 0o600))
+
+	tests := map[string]TLSConfig{
+		"missing CA file":   {CAFile: filepath.Join(dir, "missing.pem")},
+		"invalid CA file":   {CAFile: invalidFile},
+		"invalid CA PEM":    {CAPEM: []byte("invalid")},
+		"missing key file":  {CertFile: invalidFile},
+		"invalid cert file": {CertFile: invalidFile, KeyFile: invalidFile},
+	}
+
+	for name, config := range tests {
+		_, err := config.Build()
+		assert.Error(t, err, name)
+	}
+}
@@ -10,6 +10,11 @@ type ParsedInput struct {
 	Cookies       []http.Cookie     // Cookies to include in the request.
 	URLParameters map[string]any    // URL parameters for the request.
 	Body          map[string]any    // Request body data.
+
+	// Form fields of the request.
+	Form map[string]any
+	// Files of the request, by field name.
+	Files map[string][]*File
 }
 
 // RequestData represents the data ready for a request.
@@ -18,6 +23,14 @@ type RequestData struct {
 	Cookies       []http.Cookie     // Cookies to include in the request.
 	URLParameters map[string]any    // URL parameters for the request.
 	Body          any               // Request body data.
+
+	// Form fields of the request.
+	Form map[string]any
+	// Files of the request, by field name.
+	Files map[string][]*File
+	// Encoding is the encoding of the body, e.g. BodyMultipart. Defaults to
+	// an encoding determined by the form and file fields.
+	Encoding string
 }
 
 // Response represents the response from a client request, including the HTTP
@@ -0,0 +1,128 @@
+package client
+
+import (
+	"encoding"
+	"fmt"
+	"maps"
+	"net/url"
+	"reflect"
+	"slices"
+	"strconv"
+)
+
+// DefaultURLEncoder is a URLEncoder encoding the URL parameters of the
+// inputs, e.g. the fields of GET requests:
+//   - Booleans, numbers and strings are formatted as is.
+//   - Types implementing encoding.TextMarshaler or fmt.Stringer, such as
+//     time.Time and time.Duration, are formatted with them.
+//   - Slices and arrays are encoded as repeated parameters.
+//   - Maps with string keys are encoded as parameters named
+//     "<field>.<key>", e.g. filter.status=active.
+//   - Nil pointers, slices and maps are omitted.
+//
+// Other types, e.g. structs and channels, are rejected with an error.
+type DefaultURLEncoder struct{}
+
+// EncodeURL encodes the URL parameters.
+//
+//   - data: The URL parameters by name.
+func (DefaultURLEncoder) EncodeURL(data map[string]any) (url.Values, error) {
+	values := url.Values{}
+	for name, value := range data {
+		err := encodeValue(values, name, reflect.ValueOf(value))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
+
+var (
+	textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+	stringerType      = reflect.TypeFor[fmt.Stringer]()
+)
+
+// encodeValue adds the URL parameters of a value to the values.
+func encodeValue(values url.Values, name string, value reflect.Value) error {
+	if !value.IsValid() {
+		return nil
+	}
+	if value.Kind() == reflect.Interface || value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return nil
+		}
+	}
+
+	switch {
+	case value.Type().Implements(textMarshalerType):
+		text, err := value.Interface().(encoding.TextMarshaler).MarshalText()
+		if err != nil {
+			return fmt.Errorf("URL parameter %s: %w", name, err)
+		}
+		values.Add(name, string(text))
+		return nil
+	case value.Type().Implements(stringerType):
+		values.Add(name, value.Interface().(fmt.Stringer).String())
+		return nil
+	}
+
+	switch value.Kind() {
+	case reflect.Interface, reflect.Pointer:
+		return encodeValue(values, name, value.Elem())
+	case reflect.Bool:
+		values.Add(name, strconv.FormatBool(value.Bool()))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Int64:
+		values.Add(name, strconv.FormatInt(value.Int(), 10))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64:
+		values.Add(name, strconv.FormatUint(value.Uint(), 10))
+	case reflect.Float32, reflect.Float64:
+		values.Add(
+			name,
+			strconv.FormatFloat(value.Float(), 'f', -1, value.Type().Bits()),
+		)
+	case reflect.String:
+		values.Add(name, value.String())
+	case reflect.Slice, reflect.Array:
+		if value.Kind() == reflect.Slice &&
+			value.Type().Elem().Kind() == reflect.Uint8 {
+			values.Add(name, string(value.Bytes()))
+			return nil
+		}
+		for i := 0; i < value.Len(); i++ {
+			if err := encodeValue(values, name, value.Index(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		if value.Type().Key().Kind() != reflect.String {
+			return fmt.Errorf(
+				"unsupported key type %s of URL parameter %s",
+				value.Type().Key(),
+				name,
+			)
+		}
+		keys := make(map[string]reflect.Value, value.Len())
+		for _, key := range value.MapKeys() {
+			keys[key.String()] = key
+		}
+		for _, key := range slices.Sorted(maps.Keys(keys)) {
+			err := encodeValue(
+				values,
+				name+"."+key,
+				value.MapIndex(keys[key]),
+			)
+			if err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf(
+			"unsupported type %s of URL parameter %s",
+			value.Type(),
+			name,
+		)
+	}
+	return nil
+}
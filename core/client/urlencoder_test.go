@@ -0,0 +1,142 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type textStatus int
+
+func (s textStatus) MarshalText() ([]byte, error) {
+	if s < 0 {
+		return nil, errors.New("invalid status")
+	}
+	return []byte("status-" + string(rune('0'+s))), nil
+}
+
+type stringerColor string
+
+func (c stringerColor) String() string {
+	return "color:" + string(c)
+}
+
+// TestDefaultURLEncoder tests encoding the types of the URL parameters.
+func TestDefaultURLEncoder(t *testing.T) {
+	limit := 10
+	date := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	values, err := DefaultURLEncoder{}.EncodeURL(map[string]any{
+		"active":   true,
+		"limit":    &limit,
+		"offset":   uint8(5),
+		"ratio":    float32(0.5),
+		"name":     "a b",
+		"raw":      []byte("raw"),
+		"ids":      []int{1, 2},
+		"pair":     [2]string{"x", "y"},
+		"after":    date,
+		"timeout":  90 * time.Second,
+		"status":   textStatus(1),
+		"color":    stringerColor("red"),
+		"missing":  (*int)(nil),
+		"nothing":  nil,
+		"empty":    []string(nil),
+		"filter":   map[string]any{"status": "active", "tags": []string{"a"}},
+		"nested":   map[string]map[string]int{"a": {"b": 1}},
+		"interval": map[string]time.Duration{"max": time.Minute},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, url.Values{
+		"active":        {"true"},
+		"limit":         {"10"},
+		"offset":        {"5"},
+		"ratio":         {"0.5"},
+		"name":          {"a b"},
+		"raw":           {"raw"},
+		"ids":           {"1", "2"},
+		"pair":          {"x", "y"},
+		"after":         {"2024-01-02T03:04:05Z"},
+		"timeout":       {"1m30s"},
+		"status":        {"status-1"},
+		"color":         {"color:red"},
+		"filter.status": {"active"},
+		"filter.tags":   {"a"},
+		"nested.a.b":    {"1"},
+		"interval.max":  {"1m0s"},
+	}, values)
+}
+
+// TestDefaultURLEncoder_Error tests the types that cannot be encoded.
+func TestDefaultURLEncoder_Error(t *testing.T) {
+	tests := map[string]struct {
+		value    any
+		expected string
+	}{
+		"struct": {
+			value:    struct{}{},
+			expected: "unsupported type struct {} of URL parameter param",
+		},
+		"map key": {
+			value:    map[int]string{1: "a"},
+			expected: "unsupported key type int of URL parameter param",
+		},
+		"channel": {
+			value:    []chan int{make(chan int)},
+			expected: "unsupported type chan int of URL parameter param",
+		},
+		"marshaler": {
+			value:    textStatus(-1),
+			expected: "URL parameter param: invalid status",
+		},
+	}
+
+	for name, tt := range tests {
+		_, err := DefaultURLEncoder{}.EncodeURL(map[string]any{
+			"param": tt.value,
+		})
+		assert.EqualError(t, err, tt.expected, name)
+	}
+}
+
+// TestSendContext_DefaultURLEncoder tests encoding the URL parameters of GET
+// requests.
+func TestSendContext_DefaultURLEncoder(t *testing.T) {
+	var query url.Values
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			query = r.URL.Query()
+			_, _ = w.Write([]byte(`{}`))
+		},
+	))
+	defer server.Close()
+	type Input struct {
+		Timeout time.Duration     `json:"timeout"`
+		Filter  map[string]string `json:"filter"`
+	}
+
+	_, err := SendContext[Input, map[string]any](
+		context.Background(),
+		&Input{
+			Timeout: 5 * time.Second,
+			Filter:  map[string]string{"status": "active"},
+		},
+		"/users",
+		server.URL,
+		http.MethodGet,
+		DefaultURLEncoder{},
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(t, url.Values{
+		"timeout":       {"5s"},
+		"filter.status": {"active"},
+	}, query)
+}
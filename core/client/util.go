@@ -2,6 +2,8 @@ package client
 
 import (
 	"bytes"
+	"context"
+	"encoding"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -9,6 +11,8 @@ import (
 	"net/url"
 	"reflect"
 	"strings"
+
+	"github.com/pakkasys/fluidapi/core/api"
 )
 
 const (
@@ -16,11 +20,14 @@ const (
 	jsonTag   = "json"
 
 	requestURL     = "url"
+	requestPath    = "path"
 	requestBody    = "body"
 	requestHeader  = "header"
 	requestHeaders = "headers"
 	requestCookie  = "cookie"
 	requestCookies = "cookies"
+	requestForm    = "form"
+	requestFile    = "file"
 
 	contentTypeHeader = "Content-Type"
 	applicationJSON   = "application/json"
@@ -32,23 +39,70 @@ type URLEncoder interface {
 }
 
 func processAndSend[Payload any](
+	ctx context.Context,
 	client *http.Client,
 	host string,
 	url string,
 	method string,
 	input *RequestData,
 	urlEncoder URLEncoder,
+	retry *RetryPolicy,
+	auth AuthProvider,
+	signer *RequestSigner,
 ) (*SendResult[Payload], error) {
-	if input.Body != nil && method == http.MethodGet {
+	resp, err := sendRequest(
+		ctx,
+		client,
+		host,
+		url,
+		method,
+		input,
+		urlEncoder,
+		retry,
+		auth,
+		signer,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := responseToPayload(resp, new(Payload))
+	if err != nil {
+		return nil, err
+	}
+
+	return &SendResult[Payload]{resp, output}, nil
+}
+
+// sendRequest sends the request and returns the response with its body
+// unread.
+func sendRequest(
+	ctx context.Context,
+	client *http.Client,
+	host string,
+	url string,
+	method string,
+	input *RequestData,
+	urlEncoder URLEncoder,
+	retry *RetryPolicy,
+	auth AuthProvider,
+	signer *RequestSigner,
+) (*http.Response, error) {
+	hasBody := input.Body != nil || len(input.Form) != 0 ||
+		len(input.Files) != 0
+	if hasBody && method == http.MethodGet {
 		return nil, fmt.Errorf("body cannot be set for GET requests")
 	}
 
-	bodyReader, err := marshalBody(input.Body)
+	bodyReader, contentType, err := encodeBody(input)
 	if err != nil {
 		return nil, err
 	}
+	if input.Headers == nil && contentType != applicationJSON {
+		input.Headers = map[string]string{}
+	}
 	if input.Headers != nil && input.Headers[contentTypeHeader] == "" {
-		input.Headers[contentTypeHeader] = applicationJSON
+		input.Headers[contentTypeHeader] = contentType
 	}
 
 	constructedURL, err := constructURL(
@@ -62,6 +116,7 @@ func processAndSend[Payload any](
 	}
 
 	req, err := createRequest(
+		ctx,
 		method,
 		*constructedURL,
 		bodyReader,
@@ -72,20 +127,17 @@ func processAndSend[Payload any](
 		return nil, err
 	}
 
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, err
-	}
-
-	output, err := responseToPayload(resp, new(Payload))
-	if err != nil {
-		return nil, err
+	if signer != nil {
+		if err := signer.Sign(req); err != nil {
+			return nil, err
+		}
 	}
 
-	return &SendResult[Payload]{resp, output}, nil
+	return sendAuthorized(client, req, retry, auth)
 }
 
 func createRequest(
+	ctx context.Context,
 	method string,
 	fullURL string,
 	bodyReader io.Reader,
@@ -97,7 +149,7 @@ func createRequest(
 		body = bodyReader
 	}
 
-	req, err := http.NewRequest(method, fullURL, body)
+	req, err := http.NewRequestWithContext(ctx, method, fullURL, body)
 	if err != nil {
 		return nil, err
 	}
@@ -175,9 +227,19 @@ func responseToPayload[T any](r *http.Response, output *T) (*T, error) {
 		return nil, err
 	}
 
-	if err := json.Unmarshal(body, output); err != nil {
+	mediaType := normalizeMediaType(r.Header.Get(contentTypeHeader))
+	codec, ok := codecFor(mediaType)
+	if !ok {
+		codec, mediaType = JSONCodec, applicationJSON
+	}
+	if err := codec.Unmarshal(body, output); err != nil {
+		if mediaType == applicationJSON {
+			return nil, fmt.Errorf(
+				"JSON unmarshal error: %v, body: %s", err, string(body),
+			)
+		}
 		return nil, fmt.Errorf(
-			"JSON unmarshal error: %v, body: %s", err, string(body),
+			"%s unmarshal error: %v, body: %s", mediaType, err, string(body),
 		)
 	}
 
@@ -194,6 +256,8 @@ func parseInput(method string, input any) (*ParsedInput, error) {
 	cookies := make([]http.Cookie, 0)
 	urlParameters := make(map[string]any)
 	body := make(map[string]any)
+	form := make(map[string]any)
+	files := make(map[string][]*File)
 
 	defaultPlacement := determineDefaultPlacement(method)
 
@@ -204,6 +268,23 @@ func parseInput(method string, input any) (*ParsedInput, error) {
 	for i := 0; i < inputVal.NumField(); i++ {
 		field := inputVal.Field(i)
 		fieldInfo := inputType.Field(i)
+
+		name := determineFieldName(fieldInfo.Tag.Get(jsonTag), fieldInfo.Name)
+		switch fieldInfo.Tag.Get(sourceTag) {
+		case requestForm:
+			form[name] = extractFieldValue(field)
+			continue
+		case requestFile:
+			fieldFiles, err := fileValues(field)
+			if err != nil {
+				return nil, err
+			}
+			if len(fieldFiles) != 0 {
+				files[name] = fieldFiles
+			}
+			continue
+		}
+
 		updatedCookies, err := processField(
 			field,
 			fieldInfo,
@@ -224,6 +305,8 @@ func parseInput(method string, input any) (*ParsedInput, error) {
 		Cookies:       cookies,
 		URLParameters: urlParameters,
 		Body:          body,
+		Form:          form,
+		Files:         files,
 	}, nil
 }
 
@@ -281,8 +364,14 @@ func determineFieldName(jsonTag string, fieldName string) string {
 	return jsonFieldName
 }
 
-// extractFieldValue extracts the value of a field based on its kind.
+// extractFieldValue extracts the value of a field based on its kind. The
+// values of types implementing encoding.TextMarshaler or fmt.Stringer, e.g.
+// time.Duration, are kept as is so that they can be formatted with them.
 func extractFieldValue(field reflect.Value) any {
+	if field.CanInterface() && (field.Type().Implements(textMarshalerType) ||
+		field.Type().Implements(stringerType)) {
+		return field.Interface()
+	}
 	switch field.Kind() {
 	case reflect.Bool:
 		return field.Bool()
@@ -313,16 +402,18 @@ func placeFieldValue(
 	switch placement {
 	case requestURL:
 		urlParameters[jsonFieldName] = value
+	case requestPath:
+		// Path parameters are expanded into the URL by expandURLPath
 	case requestBody:
 		body[jsonFieldName] = value
 	case requestHeader, requestHeaders:
-		headers[jsonFieldName] = fmt.Sprintf("%v", value)
+		headers[jsonFieldName] = formatListValue(value)
 	case requestCookie, requestCookies:
 		cookies = append(
 			cookies,
 			http.Cookie{
 				Name:  jsonFieldName,
-				Value: fmt.Sprintf("%v", value),
+				Value: formatListValue(value),
 			},
 		)
 	default:
@@ -330,3 +421,73 @@ func placeFieldValue(
 	}
 	return cookies, nil
 }
+
+// formatListValue formats a header or cookie value. Types implementing
+// encoding.TextMarshaler such as time.Time are marshaled, and slices are
+// joined with commas.
+func formatListValue(value any) string {
+	v := reflect.ValueOf(value)
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return ""
+		}
+		return formatListValue(v.Elem().Interface())
+	}
+	if marshaler, ok := value.(encoding.TextMarshaler); ok {
+		text, err := marshaler.MarshalText()
+		if err == nil {
+			return string(text)
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			break
+		}
+		elements := make([]string, v.Len())
+		for i := range elements {
+			elements[i] = formatListValue(v.Index(i).Interface())
+		}
+		return strings.Join(elements, ",")
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+// expandURLPath replaces the parameters of a URL pattern such as
+// "/users/{id}" with the values of the input fields tagged with
+// `source:"path"`.
+func expandURLPath(pattern string, input any) (string, error) {
+	if len(api.PathParameterNames(pattern)) == 0 {
+		return pattern, nil
+	}
+
+	values := map[string]string{}
+	inputVal := reflect.ValueOf(input)
+	for inputVal.Kind() == reflect.Pointer && !inputVal.IsNil() {
+		inputVal = inputVal.Elem()
+	}
+	if inputVal.Kind() == reflect.Struct {
+		inputType := inputVal.Type()
+		for i := 0; i < inputVal.NumField(); i++ {
+			fieldInfo := inputType.Field(i)
+			if fieldInfo.Tag.Get(sourceTag) != requestPath {
+				continue
+			}
+			field := inputVal.Field(i)
+			for field.Kind() == reflect.Pointer && !field.IsNil() {
+				field = field.Elem()
+			}
+			if field.Kind() == reflect.Pointer {
+				continue
+			}
+			name := determineFieldName(
+				fieldInfo.Tag.Get(jsonTag),
+				fieldInfo.Name,
+			)
+			values[name] = fmt.Sprintf("%v", extractFieldValue(field))
+		}
+	}
+
+	return api.ExpandPath(pattern, values)
+}
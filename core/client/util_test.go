@@ -2,6 +2,7 @@ package client
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"io"
@@ -10,6 +11,7 @@ import (
 	"net/url"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -99,12 +101,16 @@ func TestProcessAndSend(t *testing.T) {
 	mockURLEncoder.On("EncodeURL", mock.Anything).Return(url.Values{}, nil)
 
 	result, err := processAndSend[MockOutput](
+		context.Background(),
 		mockClient,
 		mockServer.URL,
 		"/test",
 		http.MethodPost,
 		input,
 		mockURLEncoder,
+		nil,
+		nil,
+		nil,
 	)
 	assert.Nil(t, err)
 	assert.NotNil(t, result)
@@ -116,12 +122,16 @@ func TestProcessAndSend(t *testing.T) {
 	mockURLEncoder.On("EncodeURL", mock.Anything).Return(url.Values{}, nil)
 
 	_, err = processAndSend[MockOutput](
+		context.Background(),
 		mockClient,
 		mockServer.URL,
 		"/test",
 		http.MethodGet,
 		input,
 		mockURLEncoder,
+		nil,
+		nil,
+		nil,
 	)
 	assert.NotNil(t, err)
 	assert.Contains(t, err.Error(), "body cannot be set for GET requests")
@@ -135,12 +145,16 @@ func TestProcessAndSend(t *testing.T) {
 		Body: unmarshalableBody,
 	}
 	_, err = processAndSend[MockOutput](
+		context.Background(),
 		mockClient,
 		mockServer.URL,
 		"/test",
 		http.MethodPost,
 		input,
 		mockURLEncoder,
+		nil,
+		nil,
+		nil,
 	)
 	assert.NotNil(t, err)
 	assert.Contains(t, err.Error(), "json: unsupported type: chan int")
@@ -156,12 +170,16 @@ func TestProcessAndSend(t *testing.T) {
 		"key": "value", // Some values to trigger URL encoding
 	}}
 	_, err = processAndSend[MockOutput](
+		context.Background(),
 		mockClient,
 		mockServer.URL,
 		"/test",
 		http.MethodPost,
 		input,
 		mockURLEncoder,
+		nil,
+		nil,
+		nil,
 	)
 	assert.NotNil(t, err)
 	assert.Contains(t, err.Error(), "encode error")
@@ -172,12 +190,16 @@ func TestProcessAndSend(t *testing.T) {
 
 	input = &RequestData{}
 	_, err = processAndSend[MockOutput](
+		context.Background(),
 		mockClient,
 		"http://[::1]:namedport",
 		"/test",
 		http.MethodGet,
 		input,
 		mockURLEncoder,
+		nil,
+		nil,
+		nil,
 	)
 	assert.NotNil(t, err)
 
@@ -190,12 +212,16 @@ func TestProcessAndSend(t *testing.T) {
 	}
 
 	_, err = processAndSend[MockOutput](
+		context.Background(),
 		mockClient,
 		mockServer.URL,
 		"/test",
 		http.MethodPost,
 		input,
 		mockURLEncoder,
+		nil,
+		nil,
+		nil,
 	)
 	assert.NotNil(t, err)
 	assert.Contains(t, err.Error(), "network error")
@@ -214,12 +240,16 @@ func TestProcessAndSend(t *testing.T) {
 	defer mockServerInvalidJSON.Close()
 
 	_, err = processAndSend[MockOutput](
+		context.Background(),
 		&http.Client{},
 		mockServerInvalidJSON.URL,
 		"/test",
 		http.MethodPost,
 		&RequestData{},
 		mockURLEncoder,
+		nil,
+		nil,
+		nil,
 	)
 	assert.NotNil(t, err)
 	assert.Contains(t, err.Error(), "JSON unmarshal error")
@@ -236,7 +266,14 @@ func TestCreateRequest(t *testing.T) {
 		{Name: "session_id", Value: "12345"},
 	}
 
-	req, err := createRequest(method, fullURL, body, headers, cookies)
+	req, err := createRequest(
+		context.Background(),
+		method,
+		fullURL,
+		body,
+		headers,
+		cookies,
+	)
 	assert.Nil(t, err)
 	assert.NotNil(t, req)
 	assert.Equal(t, http.MethodPost, req.Method)
@@ -257,7 +294,14 @@ func TestCreateRequest(t *testing.T) {
 	assert.Equal(t, `{"key": "value"}`, string(bodyBytes))
 
 	// Case 2: Request with no body
-	req, err = createRequest(http.MethodGet, fullURL, nil, headers, cookies)
+	req, err = createRequest(
+		context.Background(),
+		http.MethodGet,
+		fullURL,
+		nil,
+		headers,
+		cookies,
+	)
 	assert.Nil(t, err)
 	assert.NotNil(t, req)
 	assert.Equal(t, http.MethodGet, req.Method)
@@ -265,7 +309,14 @@ func TestCreateRequest(t *testing.T) {
 
 	// Case 3: Invalid URL
 	invalidURL := "http://[::1]:namedport"
-	req, err = createRequest(http.MethodGet, invalidURL, nil, headers, cookies)
+	req, err = createRequest(
+		context.Background(),
+		http.MethodGet,
+		invalidURL,
+		nil,
+		headers,
+		cookies,
+	)
 	assert.NotNil(t, err)
 	assert.Nil(t, req)
 }
@@ -630,6 +681,9 @@ func TestExtractFieldValue(t *testing.T) {
 
 	val = reflect.ValueOf(struct{}{})
 	assert.Equal(t, val.Interface(), extractFieldValue(val))
+
+	val = reflect.ValueOf(time.Minute)
+	assert.Equal(t, time.Minute, extractFieldValue(val))
 }
 
 // Test placeFieldValue function
@@ -672,3 +726,60 @@ func TestPlaceFieldValue(t *testing.T) {
 	assert.NotNil(t, err)
 	assert.Contains(t, err.Error(), "invalid source tag")
 }
+
+// TestPlaceFieldValue_Path tests that path fields are not placed in the
+// request data.
+func TestPlaceFieldValue_Path(t *testing.T) {
+	urlParameters := make(map[string]any)
+	body := make(map[string]any)
+
+	_, err := placeFieldValue(
+		requestPath,
+		"id",
+		1,
+		map[string]string{},
+		nil,
+		urlParameters,
+		body,
+	)
+
+	assert.Nil(t, err)
+	assert.Empty(t, urlParameters)
+	assert.Empty(t, body)
+}
+
+// TestExpandURLPath tests replacing the URL parameters with input fields.
+func TestExpandURLPath(t *testing.T) {
+	orderID := "a b"
+	input := &struct {
+		UserID  int     `json:"id" source:"path"`
+		OrderID *string `json:"orderID" source:"path"`
+		Name    string  `json:"name"`
+	}{UserID: 5, OrderID: &orderID, Name: "x"}
+
+	url, err := expandURLPath("/users/{id}/orders/{orderID}", input)
+	assert.NoError(t, err)
+	assert.Equal(t, "/users/5/orders/a%20b", url)
+
+	url, err = expandURLPath("/users", input)
+	assert.NoError(t, err)
+	assert.Equal(t, "/users", url)
+
+	input.OrderID = nil
+	_, err = expandURLPath("/users/{id}/orders/{orderID}", input)
+	assert.EqualError(t, err, "missing path parameter: orderID")
+}
+
+// TestFormatListValue tests formatting header and cookie values.
+func TestFormatListValue(t *testing.T) {
+	since := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	var nilTime *time.Time
+
+	assert.Equal(t, "5", formatListValue(int64(5)))
+	assert.Equal(t, "true", formatListValue(true))
+	assert.Equal(t, "2024-01-02T03:04:05Z", formatListValue(since))
+	assert.Equal(t, "2024-01-02T03:04:05Z", formatListValue(&since))
+	assert.Equal(t, "", formatListValue(nilTime))
+	assert.Equal(t, "a,b", formatListValue([]string{"a", "b"}))
+	assert.Equal(t, "1,2", formatListValue([]int{1, 2}))
+}
@@ -0,0 +1,62 @@
+// Package logging defines the structured logger used by the server, the
+// middlewares and the input logic, together with an adapter for log/slog.
+package logging
+
+import (
+	"context"
+	"slices"
+)
+
+// Logger is a structured logger. The arguments are alternating keys and
+// values, or slog.Attr values, as with log/slog. The attributes stored in the
+// context with WithAttrs are added to every message logged with the context.
+type Logger interface {
+	Debug(ctx context.Context, msg string, args ...any)
+	Info(ctx context.Context, msg string, args ...any)
+	Warn(ctx context.Context, msg string, args ...any)
+	Error(ctx context.Context, msg string, args ...any)
+}
+
+type attrsKey struct{}
+
+// WithAttrs returns a context carrying request-scoped log attributes, e.g.
+// the request ID, in addition to the attributes of the parent context.
+//
+//   - ctx: The parent context.
+//   - args: Alternating keys and values, or slog.Attr values.
+func WithAttrs(ctx context.Context, args ...any) context.Context {
+	if len(args) == 0 {
+		return ctx
+	}
+	return context.WithValue(
+		ctx,
+		attrsKey{},
+		append(slices.Clip(Attrs(ctx)), args...),
+	)
+}
+
+// Attrs returns the log attributes stored in the context.
+//
+//   - ctx: The context.
+func Attrs(ctx context.Context) []any {
+	if ctx == nil {
+		return nil
+	}
+	attrs, _ := ctx.Value(attrsKey{}).([]any)
+	return attrs
+}
+
+// NopLogger is a logger that discards all messages.
+type NopLogger struct{}
+
+// Debug discards the message.
+func (NopLogger) Debug(ctx context.Context, msg string, args ...any) {}
+
+// Info discards the message.
+func (NopLogger) Info(ctx context.Context, msg string, args ...any) {}
+
+// Warn discards the message.
+func (NopLogger) Warn(ctx context.Context, msg string, args ...any) {}
+
+// Error discards the message.
+func (NopLogger) Error(ctx context.Context, msg string, args ...any) {}
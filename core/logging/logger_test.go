@@ -0,0 +1,35 @@
+package logging
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestWithAttrs tests storing log attributes in the context.
+func TestWithAttrs(t *testing.T) {
+	ctx := context.Background()
+	assert.Nil(t, Attrs(ctx))
+	assert.Equal(t, ctx, WithAttrs(ctx))
+
+	parent := WithAttrs(ctx, "request_id", "1")
+	first := WithAttrs(parent, "user", "a")
+	second := WithAttrs(parent, "user", "b")
+
+	assert.Equal(t, []any{"request_id", "1"}, Attrs(parent))
+	assert.Equal(t, []any{"request_id", "1", "user", "a"}, Attrs(first))
+	assert.Equal(t, []any{"request_id", "1", "user", "b"}, Attrs(second))
+}
+
+// TestNopLogger tests that NopLogger implements Logger.
+func TestNopLogger(t *testing.T) {
+	var logger Logger = NopLogger{}
+
+	assert.NotPanics(t, func() {
+		logger.Debug(nil, "debug")
+		logger.Info(context.Background(), "info")
+		logger.Warn(context.Background(), "warn")
+		logger.Error(context.Background(), "error", "key", "value")
+	})
+}
@@ -0,0 +1,98 @@
+package mock
+
+import (
+	"context"
+	"sync"
+
+	"github.com/pakkasys/fluidapi/core/logging"
+)
+
+// Entry is a message logged to the Recorder.
+type Entry struct {
+	Level   string
+	Message string
+	Args    []any
+}
+
+// Arg returns the value following the key in the arguments, or nil if the
+// key is not found.
+//
+//   - key: The key of the argument.
+func (e Entry) Arg(key string) any {
+	for i := 0; i+1 < len(e.Args); i += 2 {
+		if e.Args[i] == key {
+			return e.Args[i+1]
+		}
+	}
+	return nil
+}
+
+// Recorder is a logger recording the logged messages for tests.
+type Recorder struct {
+	mu      sync.Mutex
+	entries []Entry
+}
+
+// Debug records the message at the "debug" level.
+func (r *Recorder) Debug(ctx context.Context, msg string, args ...any) {
+	r.record(ctx, "debug", msg, args)
+}
+
+// Info records the message at the "info" level.
+func (r *Recorder) Info(ctx context.Context, msg string, args ...any) {
+	r.record(ctx, "info", msg, args)
+}
+
+// Warn records the message at the "warn" level.
+func (r *Recorder) Warn(ctx context.Context, msg string, args ...any) {
+	r.record(ctx, "warn", msg, args)
+}
+
+// Error records the message at the "error" level.
+func (r *Recorder) Error(ctx context.Context, msg string, args ...any) {
+	r.record(ctx, "error", msg, args)
+}
+
+// Entries returns the recorded messages.
+func (r *Recorder) Entries() []Entry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]Entry(nil), r.entries...)
+}
+
+// Messages returns the recorded messages without levels and arguments.
+func (r *Recorder) Messages() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	messages := make([]string, len(r.entries))
+	for i, entry := range r.entries {
+		messages[i] = entry.Message
+	}
+	return messages
+}
+
+// Reset removes the recorded messages.
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.entries = nil
+}
+
+func (r *Recorder) record(
+	ctx context.Context,
+	level string,
+	msg string,
+	args []any,
+) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.entries = append(r.entries, Entry{
+		Level:   level,
+		Message: msg,
+		Args:    append(append([]any(nil), logging.Attrs(ctx)...), args...),
+	})
+}
@@ -0,0 +1,72 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+)
+
+// SlogLogger is a Logger writing to a slog.Logger.
+type SlogLogger struct {
+	logger *slog.Logger
+}
+
+// NewSlogLogger returns a new Logger writing to the slog logger.
+//
+//   - logger: The slog logger. If nil, slog.Default() is used.
+func NewSlogLogger(logger *slog.Logger) *SlogLogger {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &SlogLogger{logger: logger}
+}
+
+// Debug logs the message at the debug level.
+//
+//   - ctx: The context carrying the request-scoped attributes.
+//   - msg: The message.
+//   - args: Alternating keys and values, or slog.Attr values.
+func (l *SlogLogger) Debug(ctx context.Context, msg string, args ...any) {
+	l.log(ctx, slog.LevelDebug, msg, args)
+}
+
+// Info logs the message at the info level.
+//
+//   - ctx: The context carrying the request-scoped attributes.
+//   - msg: The message.
+//   - args: Alternating keys and values, or slog.Attr values.
+func (l *SlogLogger) Info(ctx context.Context, msg string, args ...any) {
+	l.log(ctx, slog.LevelInfo, msg, args)
+}
+
+// Warn logs the message at the warn level.
+//
+//   - ctx: The context carrying the request-scoped attributes.
+//   - msg: The message.
+//   - args: Alternating keys and values, or slog.Attr values.
+func (l *SlogLogger) Warn(ctx context.Context, msg string, args ...any) {
+	l.log(ctx, slog.LevelWarn, msg, args)
+}
+
+// Error logs the message at the error level.
+//
+//   - ctx: The context carrying the request-scoped attributes.
+//   - msg: The message.
+//   - args: Alternating keys and values, or slog.Attr values.
+func (l *SlogLogger) Error(ctx context.Context, msg string, args ...any) {
+	l.log(ctx, slog.LevelError, msg, args)
+}
+
+func (l *SlogLogger) log(
+	ctx context.Context,
+	level slog.Level,
+	msg string,
+	args []any,
+) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if !l.logger.Enabled(ctx, level) {
+		return
+	}
+	l.logger.Log(ctx, level, msg, append(Attrs(ctx), args...)...)
+}
@@ -0,0 +1,55 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestSlogLogger(buf *bytes.Buffer, level slog.Level) *SlogLogger {
+	return NewSlogLogger(slog.New(slog.NewJSONHandler(
+		buf,
+		&slog.HandlerOptions{Level: level},
+	)))
+}
+
+// TestSlogLogger tests logging with the context attributes.
+func TestSlogLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := newTestSlogLogger(&buf, slog.LevelDebug)
+	ctx := WithAttrs(context.Background(), "request_id", "1")
+
+	logger.Error(ctx, "failed", "status", 500)
+
+	var record map[string]any
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, "ERROR", record["level"])
+	assert.Equal(t, "failed", record["msg"])
+	assert.Equal(t, "1", record["request_id"])
+	assert.Equal(t, float64(500), record["status"])
+}
+
+// TestSlogLogger_Levels tests the levels of the messages.
+func TestSlogLogger_Levels(t *testing.T) {
+	var buf bytes.Buffer
+	logger := newTestSlogLogger(&buf, slog.LevelInfo)
+	ctx := context.Background()
+
+	logger.Debug(ctx, "debug")
+	assert.Empty(t, buf.String())
+
+	logger.Info(ctx, "info")
+	logger.Warn(nil, "warn")
+
+	assert.Contains(t, buf.String(), `"level":"INFO","msg":"info"`)
+	assert.Contains(t, buf.String(), `"level":"WARN","msg":"warn"`)
+}
+
+// TestNewSlogLogger_Default tests using the default slog logger.
+func TestNewSlogLogger_Default(t *testing.T) {
+	assert.Equal(t, slog.Default(), NewSlogLogger(nil).logger)
+}
@@ -0,0 +1,58 @@
+// Package readonly provides a process-wide read-only mode switch. When the
+// read-only mode is enabled, mutating endpoints and entity write helpers
+// refuse to run and return ServiceReadOnlyError instead.
+package readonly
+
+import (
+	"net/http"
+	"sync/atomic"
+
+	"github.com/pakkasys/fluidapi/core/api"
+)
+
+var ServiceReadOnlyError = api.NewError[any]("SERVICE_READ_ONLY")
+
+var enabled atomic.Bool
+
+// Enable enables the read-only mode.
+func Enable() {
+	enabled.Store(true)
+}
+
+// Disable disables the read-only mode.
+func Disable() {
+	enabled.Store(false)
+}
+
+// Set sets the read-only mode.
+//
+//   - readOnly: Whether the read-only mode is enabled.
+func Set(readOnly bool) {
+	enabled.Store(readOnly)
+}
+
+// IsEnabled returns true if the read-only mode is enabled.
+func IsEnabled() bool {
+	return enabled.Load()
+}
+
+// Check returns ServiceReadOnlyError if the read-only mode is enabled.
+func Check() error {
+	if IsEnabled() {
+		return ServiceReadOnlyError
+	}
+	return nil
+}
+
+// IsMutatingMethod returns true if the HTTP method is used for mutating
+// requests.
+//
+//   - method: The HTTP method.
+func IsMutatingMethod(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,51 @@
+package readonly
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestEnableDisable tests toggling the read-only mode.
+func TestEnableDisable(t *testing.T) {
+	defer Disable()
+
+	Enable()
+	assert.True(t, IsEnabled())
+
+	Disable()
+	assert.False(t, IsEnabled())
+
+	Set(true)
+	assert.True(t, IsEnabled())
+}
+
+// TestCheck tests the error returned by Check.
+func TestCheck(t *testing.T) {
+	defer Disable()
+
+	assert.NoError(t, Check())
+
+	Enable()
+	assert.Equal(t, ServiceReadOnlyError, Check())
+}
+
+// TestIsMutatingMethod tests the detection of mutating HTTP methods.
+func TestIsMutatingMethod(t *testing.T) {
+	for _, method := range []string{
+		http.MethodPost,
+		http.MethodPut,
+		http.MethodPatch,
+		http.MethodDelete,
+	} {
+		assert.True(t, IsMutatingMethod(method), method)
+	}
+	for _, method := range []string{
+		http.MethodGet,
+		http.MethodHead,
+		http.MethodOptions,
+	} {
+		assert.False(t, IsMutatingMethod(method), method)
+	}
+}
@@ -0,0 +1,248 @@
+package server
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule returns the run times of a scheduled task.
+type Schedule interface {
+	// Next returns the first run time after the given time.
+	Next(t time.Time) time.Time
+}
+
+// cronField is the range and the names of a field of a cron expression.
+type cronField struct {
+	name  string
+	min   int
+	max   int
+	names map[string]int
+}
+
+var cronFields = []cronField{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}},
+	{name: "day of week", min: 0, max: 7, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}},
+}
+
+var cronDescriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// cronSchedule is a schedule of a cron expression. Each field is a bit set of
+// the matching values.
+type cronSchedule struct {
+	minute     uint64
+	hour       uint64
+	dayOfMonth uint64
+	month      uint64
+	dayOfWeek  uint64
+	// anyDay is set if either day field is "*", in which case a day must
+	// match both fields instead of either of them
+	anyDay bool
+}
+
+// everySchedule is a schedule of a fixed interval.
+type everySchedule struct {
+	interval time.Duration
+}
+
+// ParseSchedule parses a cron expression with the fields minute, hour, day of
+// month, month and day of week. The fields support "*", lists, ranges, steps
+// and the names of the months and the days of the week. The descriptors
+// "@yearly", "@monthly", "@weekly", "@daily", "@hourly" and "@every
+// <duration>" are also supported. The run times are in the location of the
+// times given to Next.
+//
+//   - spec: The cron expression.
+//
+// Example:
+//
+//	schedule, err := server.ParseSchedule("*/15 9-17 * * mon-fri")
+func ParseSchedule(spec string) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if duration, ok := strings.CutPrefix(spec, "@every "); ok {
+		interval, err := time.ParseDuration(strings.TrimSpace(duration))
+		if err != nil {
+			return nil, fmt.Errorf("invalid cron expression %q: %w", spec, err)
+		}
+		if interval <= 0 {
+			return nil, fmt.Errorf(
+				"invalid cron expression %q: interval must be positive",
+				spec,
+			)
+		}
+		return everySchedule{interval: interval}, nil
+	}
+	if expression, ok := cronDescriptors[strings.ToLower(spec)]; ok {
+		spec = expression
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != len(cronFields) {
+		return nil, fmt.Errorf(
+			"invalid cron expression %q: expected %d fields, got %d",
+			spec,
+			len(cronFields),
+			len(fields),
+		)
+	}
+	sets := make([]uint64, len(fields))
+	for i, field := range fields {
+		set, err := parseCronField(field, cronFields[i])
+		if err != nil {
+			return nil, fmt.Errorf("invalid cron expression %q: %w", spec, err)
+		}
+		sets[i] = set
+	}
+
+	// Sunday is both 0 and 7.
+	if sets[4]&(1<<7) != 0 {
+		sets[4] |= 1
+	}
+	return &cronSchedule{
+		minute:     sets[0],
+		hour:       sets[1],
+		dayOfMonth: sets[2],
+		month:      sets[3],
+		dayOfWeek:  sets[4],
+		anyDay:     fields[2] == "*" || fields[4] == "*",
+	}, nil
+}
+
+// MustParseSchedule is like ParseSchedule but panics if the expression is
+// invalid.
+//
+//   - spec: The cron expression.
+func MustParseSchedule(spec string) Schedule {
+	schedule, err := ParseSchedule(spec)
+	if err != nil {
+		panic(err)
+	}
+	return schedule
+}
+
+// Next returns the first matching minute after the given time, or the zero
+// time if there is none within five years.
+func (s *cronSchedule) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.matchDay(t) {
+			t = time.Date(
+				t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location(),
+			)
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(
+				t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location(),
+			)
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+func (s *cronSchedule) matchDay(t time.Time) bool {
+	dayOfMonth := s.dayOfMonth&(1<<uint(t.Day())) != 0
+	dayOfWeek := s.dayOfWeek&(1<<uint(t.Weekday())) != 0
+	if s.anyDay {
+		return dayOfMonth && dayOfWeek
+	}
+	return dayOfMonth || dayOfWeek
+}
+
+// Next returns the time after the interval.
+func (s everySchedule) Next(t time.Time) time.Time {
+	return t.Add(s.interval)
+}
+
+// parseCronField parses a comma separated list of the values of a field.
+func parseCronField(field string, spec cronField) (uint64, error) {
+	var set uint64
+	for _, part := range strings.Split(field, ",") {
+		bits, err := parseCronRange(part, spec)
+		if err != nil {
+			return 0, err
+		}
+		set |= bits
+	}
+	return set, nil
+}
+
+// parseCronRange parses "*", a value or a range of a field with an optional
+// step, e.g. "*/5" or "1-10/2".
+func parseCronRange(part string, spec cronField) (uint64, error) {
+	rangePart, stepPart, hasStep := strings.Cut(part, "/")
+	step := 1
+	if hasStep {
+		var err error
+		step, err = strconv.Atoi(stepPart)
+		if err != nil || step <= 0 {
+			return 0, fmt.Errorf("invalid %s step: %s", spec.name, stepPart)
+		}
+	}
+
+	start, end := spec.min, spec.max
+	if rangePart != "*" {
+		startPart, endPart, isRange := strings.Cut(rangePart, "-")
+		var err error
+		if start, err = parseCronValue(startPart, spec); err != nil {
+			return 0, err
+		}
+		end = start
+		if isRange {
+			if end, err = parseCronValue(endPart, spec); err != nil {
+				return 0, err
+			}
+		} else if hasStep {
+			end = spec.max
+		}
+		if start > end {
+			return 0, fmt.Errorf("invalid %s range: %s", spec.name, rangePart)
+		}
+	}
+
+	var bits uint64
+	for value := start; value <= end; value += step {
+		bits |= 1 << uint(value)
+	}
+	return bits, nil
+}
+
+func parseCronValue(value string, spec cronField) (int, error) {
+	if number, ok := spec.names[strings.ToLower(value)]; ok {
+		return number, nil
+	}
+	number, err := strconv.Atoi(value)
+	if err != nil || number < spec.min || number > spec.max {
+		return 0, fmt.Errorf("invalid %s: %s", spec.name, value)
+	}
+	return number, nil
+}
@@ -0,0 +1,91 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestParseSchedule_Next tests the run times of cron expressions.
+func TestParseSchedule_Next(t *testing.T) {
+	// 2024-01-02 is a Tuesday.
+	from := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		spec     string
+		expected time.Time
+	}{
+		{"* * * * *", time.Date(2024, 1, 2, 3, 5, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2024, 1, 2, 3, 15, 0, 0, time.UTC)},
+		{"0 * * * *", time.Date(2024, 1, 2, 4, 0, 0, 0, time.UTC)},
+		{"30 2 * * *", time.Date(2024, 1, 3, 2, 30, 0, 0, time.UTC)},
+		{"0 9-17/4 * * *", time.Date(2024, 1, 2, 9, 0, 0, 0, time.UTC)},
+		{"0 0 * * fri", time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2024, 1, 7, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1 mar *", time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 0 15 * mon", time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC)},
+		{"5,10 3 * * *", time.Date(2024, 1, 2, 3, 5, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2024, 1, 2, 4, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC)},
+		{"@weekly", time.Date(2024, 1, 7, 0, 0, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{"@yearly", time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"@every 90s", from.Add(90 * time.Second)},
+		{"0 0 31 2 *", time.Time{}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.spec, func(t *testing.T) {
+			schedule, err := ParseSchedule(test.spec)
+
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, schedule.Next(from))
+		})
+	}
+}
+
+// TestParseSchedule_Location tests that the run times are in the location of
+// the given time.
+func TestParseSchedule_Location(t *testing.T) {
+	location := time.FixedZone("UTC+5:30", 5*3600+1800)
+	from := time.Date(2024, 1, 2, 3, 4, 0, 0, location)
+
+	next := MustParseSchedule("0 * * * *").Next(from)
+
+	assert.Equal(t, time.Date(2024, 1, 2, 4, 0, 0, 0, location), next)
+}
+
+// TestParseSchedule_Errors tests invalid cron expressions.
+func TestParseSchedule_Errors(t *testing.T) {
+	tests := []struct {
+		spec     string
+		expected string
+	}{
+		{"* * * *", "expected 5 fields, got 4"},
+		{"60 * * * *", "invalid minute: 60"},
+		{"* 24 * * *", "invalid hour: 24"},
+		{"* * 0 * *", "invalid day of month: 0"},
+		{"* * * foo *", "invalid month: foo"},
+		{"* * * * 8", "invalid day of week: 8"},
+		{"*/0 * * * *", "invalid minute step: 0"},
+		{"10-5 * * * *", "invalid minute range: 10-5"},
+		{"@every x", "invalid duration"},
+		{"@every -1s", "interval must be positive"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.spec, func(t *testing.T) {
+			schedule, err := ParseSchedule(test.spec)
+
+			assert.Nil(t, schedule)
+			assert.ErrorContains(t, err, test.expected)
+		})
+	}
+}
+
+// TestMustParseSchedule tests that invalid expressions panic.
+func TestMustParseSchedule(t *testing.T) {
+	assert.Panics(t, func() { MustParseSchedule("invalid") })
+	assert.NotPanics(t, func() { MustParseSchedule("@daily") })
+}
@@ -2,16 +2,23 @@ package server
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"log"
+	"io/fs"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"runtime"
+	"slices"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/pakkasys/fluidapi/core/api"
+	"github.com/pakkasys/fluidapi/core/buildinfo"
+	"github.com/pakkasys/fluidapi/core/logging"
 )
 
 type multiplexedEndpoints map[string]map[string]http.Handler
@@ -25,45 +32,211 @@ type IServer interface {
 // HTTPServer sets up an HTTP server with the specified port and endpoints,
 // using optional logging functions for requests and errors. If no custom server
 // options are provided, it creates a default http.Server. The server listens
-// for OS interrupt signals to gracefully shut down.
+// for OS interrupt signals to gracefully shut down: it stops accepting
+// connections, drains the active requests within the shutdown timeout and
+// then calls the shutdown callbacks within a shutdown timeout of their own.
 //
 //   - server: Server implementation to use.
-func HTTPServer(server IServer) error {
-	return startServer(make(chan os.Signal, 1), server)
+//   - opts: Options such as WithShutdownTimeout, WithOnShutdown and
+//     WithLogger.
+func HTTPServer(server IServer, opts ...Option) error {
+	return startServer(make(chan os.Signal, 1), server, opts...)
 }
 
-type LoggerFn func(r *http.Request) func(messages ...any)
+// ServerOption configures the server created by NewHTTPServer or
+// DefaultHTTPServer.
+type ServerOption func(*serverOptions)
 
-// HTTPServer returns the default HTTP server implementation.
+type serverOptions struct {
+	host              string
+	unixSocket        string
+	unixSocketMode    fs.FileMode
+	tls               *TLSConfig
+	http2             *HTTP2Config
+	readTimeout       time.Duration
+	readHeaderTimeout time.Duration
+	writeTimeout      time.Duration
+	idleTimeout       time.Duration
+	maxHeaderBytes    int
+	baseContext       func(net.Listener) context.Context
+}
+
+// WithReadTimeout sets the maximum duration for reading the entire request,
+// including the body.
+//
+//   - timeout: The timeout. Zero means no timeout.
+func WithReadTimeout(timeout time.Duration) ServerOption {
+	return func(o *serverOptions) {
+		o.readTimeout = timeout
+	}
+}
+
+// WithReadHeaderTimeout sets the maximum duration for reading the request
+// headers. It protects the server against clients sending the headers
+// slowly.
+//
+//   - timeout: The timeout. Zero means the read timeout is used.
+func WithReadHeaderTimeout(timeout time.Duration) ServerOption {
+	return func(o *serverOptions) {
+		o.readHeaderTimeout = timeout
+	}
+}
+
+// WithWriteTimeout sets the maximum duration before timing out writes of the
+// response. It should be longer than the slowest endpoint.
+//
+//   - timeout: The timeout. Zero means no timeout.
+func WithWriteTimeout(timeout time.Duration) ServerOption {
+	return func(o *serverOptions) {
+		o.writeTimeout = timeout
+	}
+}
+
+// WithIdleTimeout sets the maximum duration to wait for the next request on
+// a keep-alive connection.
+//
+//   - timeout: The timeout. Zero means the read timeout is used.
+func WithIdleTimeout(timeout time.Duration) ServerOption {
+	return func(o *serverOptions) {
+		o.idleTimeout = timeout
+	}
+}
+
+// WithMaxHeaderBytes sets the maximum size of the request headers.
+//
+//   - maxBytes: The maximum size in bytes. Zero means
+//     http.DefaultMaxHeaderBytes.
+func WithMaxHeaderBytes(maxBytes int) ServerOption {
+	return func(o *serverOptions) {
+		o.maxHeaderBytes = maxBytes
+	}
+}
+
+// WithBaseContext sets the function returning the base context of the
+// requests, e.g. to cancel long-running requests when the service stops.
+//
+//   - baseContext: Returns the base context for the listener.
+func WithBaseContext(
+	baseContext func(listener net.Listener) context.Context,
+) ServerOption {
+	return func(o *serverOptions) {
+		o.baseContext = baseContext
+	}
+}
+
+// DefaultHTTPServer returns the default HTTP server implementation. It
+// panics if the options are invalid. Use NewHTTPServer to handle the
+// configuration errors.
 //
 //   - port: Port for the HTTP server.
 //   - httpEndpoints: Endpoints to register.
-//   - loggerInfoFn: Function to log informational messages.
-//   - loggerErrorFn: Function to log error messages.
+//   - logger: Logger for the server messages. If nil, nothing is logged.
+//   - opts: Options such as WithHost, WithUnixSocket, WithTLS, WithHTTP2 and
+//     the timeout and limit options, e.g. WithReadHeaderTimeout.
 func DefaultHTTPServer(
 	port int,
 	httpEndpoints []api.Endpoint,
-	loggerInfoFn LoggerFn,
-	loggerErrorFn LoggerFn,
+	logger logging.Logger,
+	opts ...ServerOption,
 ) IServer {
-	return &http.Server{
-		Addr:    fmt.Sprintf(":%d", port),
-		Handler: setupMux(httpEndpoints, loggerInfoFn, loggerErrorFn),
+	server, err := NewHTTPServer(port, httpEndpoints, logger, opts...)
+	if err != nil {
+		panic(err.Error())
 	}
+	return server
 }
 
-func startServer(stopChan chan os.Signal, server IServer) error {
+// NewHTTPServer returns the default HTTP server implementation. The method
+// and URL pattern of the endpoint are added to the log attributes of the
+// request context.
+//
+//   - port: Port for the HTTP server.
+//   - httpEndpoints: Endpoints to register.
+//   - logger: Logger for the server messages. If nil, nothing is logged.
+//   - opts: Options such as WithHost, WithUnixSocket, WithTLS, WithHTTP2 and
+//     the timeout and limit options, e.g. WithReadHeaderTimeout.
+func NewHTTPServer(
+	port int,
+	httpEndpoints []api.Endpoint,
+	logger logging.Logger,
+	opts ...ServerOption,
+) (IServer, error) {
+	o := &serverOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	if o.unixSocket != "" && o.tls != nil {
+		return nil, fmt.Errorf("TLS is not supported on Unix sockets")
+	}
+
+	server := &http.Server{
+		Addr:    net.JoinHostPort(o.host, strconv.Itoa(port)),
+		Handler: setupMux(httpEndpoints, logger),
+
+		ReadTimeout:       o.readTimeout,
+		ReadHeaderTimeout: o.readHeaderTimeout,
+		WriteTimeout:      o.writeTimeout,
+		IdleTimeout:       o.idleTimeout,
+		MaxHeaderBytes:    o.maxHeaderBytes,
+		BaseContext:       o.baseContext,
+	}
+	var iServer IServer = server
+	if o.unixSocket != "" {
+		iServer = NewUnixServer(server, o.unixSocket, o.unixSocketMode)
+	}
+	if o.tls != nil {
+		tlsServer, err := newTLSServer(server, port, *o.tls)
+		if err != nil {
+			return nil, err
+		}
+		iServer = tlsServer
+	}
+	if o.http2 != nil {
+		if err := configureHTTP2(server, *o.http2); err != nil {
+			return nil, err
+		}
+	}
+	return iServer, nil
+}
+
+func startServer(
+	stopChan chan os.Signal,
+	server IServer,
+	opts ...Option,
+) error {
+	o := newOptions(opts)
+
 	// Listen for shutdown signals
 	signal.Notify(stopChan, os.Interrupt, syscall.SIGTERM)
 
 	// Capture the error from ListenAndServe
 	errChan := make(chan error, 1)
 
+	for _, scheduler := range o.schedulers {
+		if err := scheduler.Start(context.Background()); err != nil {
+			o.logger.Error(
+				context.Background(),
+				"Error starting scheduler",
+				"error", err,
+			)
+		}
+	}
+
 	go func() {
-		log.Printf("Starting HTTP server")
+		o.logger.Info(
+			context.Background(),
+			"Starting HTTP server",
+			"build", buildinfo.Banner(),
+		)
+		o.status.set(StateRunning)
 		err := server.ListenAndServe()
 		if err != nil && err != http.ErrServerClosed {
-			log.Printf("Error starting HTTP server: %v", err)
+			o.logger.Error(
+				context.Background(),
+				"Error starting HTTP server",
+				"error", err,
+			)
 			errChan <- err
 			stopChan <- os.Interrupt
 		} else {
@@ -73,63 +246,113 @@ func startServer(stopChan chan os.Signal, server IServer) error {
 
 	// Wait for a signal to shut down
 	<-stopChan
-	log.Printf("Shutting down HTTP server")
+	o.status.set(StateDraining)
+	o.logger.Info(
+		context.Background(),
+		"Shutting down HTTP server, draining connections",
+		"timeout", o.shutdownTimeout.String(),
+	)
 
 	// Give the server some time to shut down
-	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	ctx, cancel := context.WithTimeout(
+		context.Background(),
+		o.shutdownTimeout,
+	)
 	defer cancel()
 
-	if err := server.Shutdown(ctx); err != nil {
-		return err
+	shutdownErr := server.Shutdown(ctx)
+	if shutdownErr != nil {
+		o.logger.Error(ctx, "Error draining connections", "error", shutdownErr)
+	} else {
+		o.logger.Info(ctx, "Connections drained")
+	}
+
+	// The callbacks get their own timeout, since draining the connections
+	// may have used up the shutdown timeout
+	callbackCtx, cancelCallbacks := context.WithTimeout(
+		context.Background(),
+		o.shutdownTimeout,
+	)
+	defer cancelCallbacks()
+
+	callbackErr := runShutdownCallbacks(callbackCtx, o.onShutdown, o.logger)
+	o.status.set(StateStopped)
+	if shutdownErr != nil || callbackErr != nil {
+		return errors.Join(shutdownErr, callbackErr)
 	}
 
-	log.Printf("HTTP server shutdown")
+	o.logger.Info(context.Background(), "HTTP server shutdown")
 	return <-errChan
 }
 
+func runShutdownCallbacks(
+	ctx context.Context,
+	callbacks []func(ctx context.Context) error,
+	logger logging.Logger,
+) error {
+	var errs []error
+	for _, callback := range callbacks {
+		if err := callback(ctx); err != nil {
+			logger.Error(ctx, "Error in shutdown callback", "error", err)
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
 func setupMux(
 	httpEndpoints []api.Endpoint,
-	loggerInfoFn func(r *http.Request) func(messages ...any),
-	loggerErrorFn func(r *http.Request) func(messages ...any),
+	logger logging.Logger,
 ) *http.ServeMux {
+	if logger == nil {
+		logger = logging.NopLogger{}
+	}
+
 	mux := http.NewServeMux()
-	endpoints := multiplexEndpoints(httpEndpoints, loggerErrorFn)
+	endpoints := multiplexEndpoints(httpEndpoints, logger)
 
 	for url := range endpoints {
-		log.Printf("Registering URL: %s %v", url, mapKeys(endpoints[url]))
+		logger.Info(
+			context.Background(),
+			"Registering URL",
+			"url", url,
+			"methods", mapKeys(endpoints[url]),
+		)
 		iterUrl := url
 		mux.Handle(
 			iterUrl,
-			createEndpointHandler(
-				endpoints[iterUrl],
-				loggerInfoFn,
-				loggerErrorFn,
+			withPathParameters(
+				iterUrl,
+				createEndpointHandler(endpoints[iterUrl], logger),
 			),
 		)
 	}
 
-	mux.Handle("/", createNotFoundHandler(loggerInfoFn))
+	// An endpoint registered at the root, e.g. a single page application,
+	// handles the requests not matching the other endpoints.
+	if _, ok := endpoints["/"]; !ok {
+		mux.Handle("/", createNotFoundHandler(logger))
+	}
 
 	return mux
 }
 
 func createEndpointHandler(
 	endpoints map[string]http.Handler,
-	loggerInfoFn func(r *http.Request) func(messages ...any),
-	loggerErrorFn func(r *http.Request) func(messages ...any),
+	logger logging.Logger,
 ) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if handler, ok := endpoints[r.Method]; ok {
 			handler.ServeHTTP(w, r)
 			return
 		}
-		if loggerErrorFn != nil {
-			loggerInfoFn(r)(fmt.Sprintf(
-				"Method not allowed: %s (%v)",
-				r.URL,
-				r.Method),
-			)
-		}
+		logger.Info(
+			r.Context(),
+			"Method not allowed",
+			"url", r.URL.String(),
+			"method", r.Method,
+		)
+		w.Header().Set("Allow", allowedMethods(endpoints))
 		http.Error(
 			w,
 			http.StatusText(http.StatusMethodNotAllowed),
@@ -138,11 +361,34 @@ func createEndpointHandler(
 	}
 }
 
-func createNotFoundHandler(
-	loggerInfoFn func(r *http.Request) func(messages ...any),
-) http.HandlerFunc {
+// withPathParameters stores the path parameters of the URL pattern, e.g.
+// "/users/{id}", in the request context. Handlers of exact URLs are returned
+// unchanged.
+func withPathParameters(pattern string, next http.Handler) http.Handler {
+	names := api.PathParameterNames(pattern)
+	if len(names) == 0 {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		parameters := make(map[string]string, len(names))
+		for _, name := range names {
+			parameters[name] = r.PathValue(name)
+		}
+		next.ServeHTTP(
+			w,
+			r.WithContext(api.WithPathParameters(r.Context(), parameters)),
+		)
+	})
+}
+
+func createNotFoundHandler(logger logging.Logger) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		loggerInfoFn(r)(fmt.Sprintf("Not found: %s (%v)", r.URL, r.Method))
+		logger.Info(
+			r.Context(),
+			"Not found",
+			"url", r.URL.String(),
+			"method", r.Method,
+		)
 		http.Error(
 			w,
 			http.StatusText(http.StatusNotFound),
@@ -161,7 +407,7 @@ func mapKeys[K comparable, V any](m map[K]V) []K {
 
 func multiplexEndpoints(
 	httpEndpoints []api.Endpoint,
-	loggerErrorFn func(r *http.Request) func(messages ...any),
+	logger logging.Logger,
 ) multiplexedEndpoints {
 	endpoints := multiplexedEndpoints{}
 	for i := range httpEndpoints {
@@ -171,36 +417,132 @@ func multiplexEndpoints(
 			endpoints[url] = make(map[string]http.Handler)
 		}
 		// Include panic handler with other middlewares
-		endpoints[url][method] = serverPanicHandler(
-			api.ApplyMiddlewares(
-				http.HandlerFunc(
-					func(
-						w http.ResponseWriter,
-						r *http.Request,
-					) {
-					},
+		endpoints[url][method] = withLogAttrs(
+			serverPanicHandler(
+				api.ApplyMiddlewares(
+					http.HandlerFunc(
+						func(
+							w http.ResponseWriter,
+							r *http.Request,
+						) {
+						},
+					),
+					httpEndpoints[i].Middlewares...,
 				),
-				httpEndpoints[i].Middlewares...,
+				logger,
 			),
-			loggerErrorFn,
+			"endpoint_method", method,
+			"endpoint_url", url,
 		)
 	}
+	addAutomaticMethods(endpoints, httpEndpoints)
 	return endpoints
 }
 
+// addAutomaticMethods adds the HEAD handlers of the GET endpoints and the
+// OPTIONS handlers of the URLs that have no such handlers registered, unless
+// the endpoints opt out of them. The OPTIONS handlers run the options
+// middlewares of the endpoints, e.g. CORS, so that preflight requests are
+// answered.
+func addAutomaticMethods(
+	endpoints multiplexedEndpoints,
+	httpEndpoints []api.Endpoint,
+) {
+	noHead := map[string]bool{}
+	noOptions := map[string]bool{}
+	optionsMiddlewares := map[string][]api.Middleware{}
+	for _, endpoint := range httpEndpoints {
+		if endpoint.DisableAutoHead {
+			noHead[endpoint.URL] = true
+		}
+		if endpoint.DisableAutoOptions {
+			noOptions[endpoint.URL] = true
+		}
+		if _, ok := optionsMiddlewares[endpoint.URL]; !ok &&
+			len(endpoint.OptionsMiddlewares) != 0 {
+			optionsMiddlewares[endpoint.URL] = endpoint.OptionsMiddlewares
+		}
+	}
+
+	for url, handlers := range endpoints {
+		get, hasGet := handlers[http.MethodGet]
+		_, hasHead := handlers[http.MethodHead]
+		if hasGet && !hasHead && !noHead[url] {
+			handlers[http.MethodHead] = headHandler(get)
+		}
+		_, hasOptions := handlers[http.MethodOptions]
+		if !hasOptions && !noOptions[url] {
+			handlers[http.MethodOptions] = api.ApplyMiddlewares(
+				optionsHandler(allowedMethods(handlers, http.MethodOptions)),
+				optionsMiddlewares[url]...,
+			)
+		}
+	}
+}
+
+// headHandler runs the handler without writing the response body.
+func headHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(&headWriter{ResponseWriter: w}, r)
+	})
+}
+
+// optionsHandler answers with the allowed methods.
+func optionsHandler(allow string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Allow", allow)
+		w.Header().Set("Content-Length", "0")
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+// allowedMethods returns the sorted methods of the handlers and the extra
+// methods as the value of an Allow header.
+func allowedMethods(
+	handlers map[string]http.Handler,
+	extra ...string,
+) string {
+	methods := append(mapKeys(handlers), extra...)
+	slices.Sort(methods)
+	return strings.Join(slices.Compact(methods), ", ")
+}
+
+// headWriter discards the response body.
+type headWriter struct {
+	http.ResponseWriter
+}
+
+func (w *headWriter) Write(b []byte) (int, error) {
+	return len(b), nil
+}
+
+func (w *headWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// withLogAttrs adds the log attributes to the request context.
+func withLogAttrs(next http.Handler, args ...any) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(
+			w,
+			r.WithContext(logging.WithAttrs(r.Context(), args...)),
+		)
+	})
+}
+
 func serverPanicHandler(
 	next http.Handler,
-	loggerErrorFn func(r *http.Request) func(messages ...any),
+	logger logging.Logger,
 ) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		defer func() {
 			if err := recover(); err != nil {
-				if loggerErrorFn != nil {
-					loggerErrorFn(r)(
-						"Server panic",
-						fmt.Sprintf("%v, %v", err, stackTraceSlice()),
-					)
-				}
+				logger.Error(
+					r.Context(),
+					"Server panic",
+					"panic", fmt.Sprintf("%v", err),
+					"stack_trace", stackTraceSlice(),
+				)
 				http.Error(
 					w,
 					http.StatusText(http.StatusInternalServerError),
@@ -0,0 +1,63 @@
+package server
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+)
+
+// HTTP2Config configures HTTP/2 on the server. HTTP/2 is enabled by default
+// for TLS connections. The cleartext and tuning options plug in
+// golang.org/x/net/http2:
+//
+//	h2s := &http2.Server{MaxConcurrentStreams: 250}
+//	server.WithHTTP2(server.HTTP2Config{
+//		H2CHandler: func(handler http.Handler) http.Handler {
+//			return h2c.NewHandler(handler, h2s)
+//		},
+//		Configure: func(server *http.Server) error {
+//			return http2.ConfigureServer(server, h2s)
+//		},
+//	})
+type HTTP2Config struct {
+	// H2CHandler wraps the handler of the server to serve HTTP/2 over
+	// cleartext connections (h2c), e.g. for internal gRPC-style clients.
+	// Optional.
+	H2CHandler func(handler http.Handler) http.Handler
+	// Configure tunes the HTTP/2 parameters of the server, e.g. the maximum
+	// number of concurrent streams. It is called after the TLS configuration
+	// has been set. Optional.
+	Configure func(server *http.Server) error
+	// Disable disables HTTP/2 for TLS connections.
+	Disable bool
+}
+
+// WithHTTP2 configures HTTP/2 on the server.
+//
+//   - config: The HTTP/2 configuration.
+func WithHTTP2(config HTTP2Config) ServerOption {
+	return func(o *serverOptions) {
+		o.http2 = &config
+	}
+}
+
+func configureHTTP2(server *http.Server, config HTTP2Config) error {
+	if config.Disable {
+		// A non-nil empty map disables the automatic HTTP/2 support.
+		server.TLSNextProto = map[string]func(
+			*http.Server,
+			*tls.Conn,
+			http.Handler,
+		){}
+		return nil
+	}
+	if config.H2CHandler != nil {
+		server.Handler = config.H2CHandler(server.Handler)
+	}
+	if config.Configure != nil {
+		if err := config.Configure(server); err != nil {
+			return fmt.Errorf("failed to configure HTTP/2: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package server
+
+import (
+	"crypto/tls"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestDefaultHTTPServer_HTTP2 tests the h2c handler and HTTP/2 tuning.
+func TestDefaultHTTPServer_HTTP2(t *testing.T) {
+	var configured *http.Server
+	server := DefaultHTTPServer(
+		8080,
+		nil,
+		nil,
+		WithHTTP2(HTTP2Config{
+			H2CHandler: func(handler http.Handler) http.Handler {
+				return http.HandlerFunc(
+					func(w http.ResponseWriter, r *http.Request) {
+						w.Header().Set("X-H2C", "true")
+						handler.ServeHTTP(w, r)
+					},
+				)
+			},
+			Configure: func(server *http.Server) error {
+				configured = server
+				return nil
+			},
+		}),
+	).(*http.Server)
+
+	assert.Same(t, server, configured)
+	rr := httptest.NewRecorder()
+	server.Handler.ServeHTTP(rr, httptest.NewRequest("GET", "/", nil))
+	assert.Equal(t, "true", rr.Header().Get("X-H2C"))
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
+
+// TestDefaultHTTPServer_HTTP2Configure tests configuring HTTP/2 after TLS.
+func TestDefaultHTTPServer_HTTP2Configure(t *testing.T) {
+	var tlsConfig *tls.Config
+	DefaultHTTPServer(
+		443,
+		nil,
+		nil,
+		WithTLS(TLSConfig{CertFile: "cert.pem", KeyFile: "key.pem"}),
+		WithHTTP2(HTTP2Config{
+			Configure: func(server *http.Server) error {
+				tlsConfig = server.TLSConfig
+				return nil
+			},
+		}),
+	)
+
+	assert.NotNil(t, tlsConfig)
+}
+
+// TestDefaultHTTPServer_HTTP2Disable tests disabling HTTP/2.
+func TestDefaultHTTPServer_HTTP2Disable(t *testing.T) {
+	server := DefaultHTTPServer(
+		8080,
+		nil,
+		nil,
+		WithHTTP2(HTTP2Config{Disable: true}),
+	).(*http.Server)
+
+	assert.NotNil(t, server.TLSNextProto)
+	assert.Empty(t, server.TLSNextProto)
+}
+
+// TestDefaultHTTPServer_HTTP2ConfigureError tests that configuration errors
+// panic.
+func TestDefaultHTTPServer_HTTP2ConfigureError(t *testing.T) {
+	assert.PanicsWithValue(t, "failed to configure HTTP/2: invalid", func() {
+		DefaultHTTPServer(8080, nil, nil, WithHTTP2(HTTP2Config{
+			Configure: func(server *http.Server) error {
+				return errors.New("invalid")
+			},
+		}))
+	})
+}
+
+// TestNewHTTPServer_HTTP2ConfigureError tests returning the configuration
+// errors.
+func TestNewHTTPServer_HTTP2ConfigureError(t *testing.T) {
+	configureErr := errors.New("invalid")
+
+	server, err := NewHTTPServer(8080, nil, nil, WithHTTP2(HTTP2Config{
+		Configure: func(server *http.Server) error {
+			return configureErr
+		},
+	}))
+
+	assert.ErrorIs(t, err, configureErr)
+	assert.EqualError(t, err, "failed to configure HTTP/2: invalid")
+	assert.Nil(t, server)
+}
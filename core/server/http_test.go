@@ -1,11 +1,10 @@
 package server
 
 import (
-	"bytes"
 	"context"
 	"errors"
 	"fmt"
-	"log"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -14,6 +13,8 @@ import (
 	"time"
 
 	"github.com/pakkasys/fluidapi/core/api"
+	"github.com/pakkasys/fluidapi/core/logging"
+	"github.com/pakkasys/fluidapi/core/logging/mock"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -41,11 +42,7 @@ func startTestHTTPServer(
 	port int,
 	httpEndpoints []api.Endpoint,
 ) (shutdownFunc func()) {
-	mockLogger := func(r *http.Request) func(messages ...any) {
-		return func(messages ...any) {
-			fmt.Println(messages...)
-		}
-	}
+	mockLogger := &mock.Recorder{}
 
 	// Start the HTTP server in a goroutine
 	go func() {
@@ -53,7 +50,6 @@ func startTestHTTPServer(
 			port,
 			httpEndpoints,
 			mockLogger,
-			mockLogger,
 		))
 		if err != nil {
 			fmt.Printf("Error starting server: %v\n", err)
@@ -154,11 +150,7 @@ func TestHTTPServer_CallEndpointWithWrongMethod(t *testing.T) {
 
 // Test case for calling a registered endpoint
 func TestHTTPServer_CallRegisteredEndpoint(t *testing.T) {
-	mockLogger := func(r *http.Request) func(messages ...any) {
-		return func(messages ...any) {
-			fmt.Println(messages...)
-		}
-	}
+	mockLogger := &mock.Recorder{}
 
 	// Define endpoints
 	httpEndpoints := []api.Endpoint{
@@ -195,7 +187,7 @@ func TestHTTPServer_CallRegisteredEndpoint(t *testing.T) {
 	rr := httptest.NewRecorder()
 
 	// Setup mux and test handler
-	mux := setupMux(httpEndpoints, mockLogger, mockLogger)
+	mux := setupMux(httpEndpoints, mockLogger)
 	mux.ServeHTTP(rr, req)
 
 	// Assert the response
@@ -205,11 +197,7 @@ func TestHTTPServer_CallRegisteredEndpoint(t *testing.T) {
 
 // Test case for DefaultHTTPServer function
 func TestDefaultHTTPServer(t *testing.T) {
-	mockLogger := func(r *http.Request) func(messages ...any) {
-		return func(messages ...any) {
-			fmt.Println(messages...)
-		}
-	}
+	mockLogger := &mock.Recorder{}
 
 	// Define test parameters
 	port := 8080
@@ -233,7 +221,7 @@ func TestDefaultHTTPServer(t *testing.T) {
 	}
 
 	// Call DefaultHTTPServer to create the server
-	server := DefaultHTTPServer(port, httpEndpoints, mockLogger, mockLogger)
+	server := DefaultHTTPServer(port, httpEndpoints, mockLogger)
 
 	// Assert that the server is of type *http.Server
 	httpServer, ok := server.(*http.Server)
@@ -257,6 +245,33 @@ func TestDefaultHTTPServer(t *testing.T) {
 	)
 }
 
+// TestDefaultHTTPServer_Options tests the timeout and limit options.
+func TestDefaultHTTPServer_Options(t *testing.T) {
+	type ctxKey struct{}
+	baseCtx := context.WithValue(context.Background(), ctxKey{}, "base")
+
+	server := DefaultHTTPServer(
+		8080,
+		nil,
+		nil,
+		WithReadTimeout(10*time.Second),
+		WithReadHeaderTimeout(5*time.Second),
+		WithWriteTimeout(30*time.Second),
+		WithIdleTimeout(2*time.Minute),
+		WithMaxHeaderBytes(1<<16),
+		WithBaseContext(func(l net.Listener) context.Context {
+			return baseCtx
+		}),
+	).(*http.Server)
+
+	assert.Equal(t, 10*time.Second, server.ReadTimeout)
+	assert.Equal(t, 5*time.Second, server.ReadHeaderTimeout)
+	assert.Equal(t, 30*time.Second, server.WriteTimeout)
+	assert.Equal(t, 2*time.Minute, server.IdleTimeout)
+	assert.Equal(t, 1<<16, server.MaxHeaderBytes)
+	assert.Equal(t, baseCtx, server.BaseContext(nil))
+}
+
 // Test case to simulate a normal server operation and shutdown
 func TestStartServer_NormalOperation(t *testing.T) {
 	stopChan := make(chan os.Signal, 1)
@@ -299,18 +314,26 @@ func TestStartServer_ServerStartError(t *testing.T) {
 		},
 	}
 
-	// Capture log output
-	log.SetFlags(0)
-	var buf bytes.Buffer
-	log.SetOutput(&buf)
+	logger := &mock.Recorder{}
 
-	go func() {
-		err := startServer(stopChan, mockServer)
-		assert.EqualError(t, err, "mock server start error")
-	}()
-
-	// Give some time for graceful shutdown
-	time.Sleep(1 * time.Second)
+	err := startServer(stopChan, mockServer, WithLogger(logger))
+	assert.EqualError(t, err, "mock server start error")
+	assert.Equal(
+		t,
+		[]string{
+			"Starting HTTP server",
+			"Error starting HTTP server",
+			"Shutting down HTTP server, draining connections",
+			"Connections drained",
+			"HTTP server shutdown",
+		},
+		logger.Messages(),
+	)
+	assert.Equal(
+		t,
+		"mock server start error",
+		logger.Entries()[1].Arg("error").(error).Error(),
+	)
 }
 
 // Test case to cover the "Error during shutdown" path
@@ -342,24 +365,14 @@ func TestStartServer_ServerShutdownError(t *testing.T) {
 	time.Sleep(1 * time.Second)
 }
 func TestSetupMux(t *testing.T) {
-	// Mock logger functions to capture log messages
-	var infoLogMessages, errorLogMessages []string
-
-	loggerInfoFn := func(r *http.Request) func(messages ...any) {
-		return func(messages ...any) {
-			infoLogMessages = append(infoLogMessages, fmt.Sprint(messages...))
-		}
-	}
-
-	loggerErrorFn := func(r *http.Request) func(messages ...any) {
-		return func(messages ...any) {
-			errorLogMessages = append(errorLogMessages, fmt.Sprint(messages...))
-		}
-	}
+	// Mock logger to capture log messages
+	logger := &mock.Recorder{}
 
 	// Define test middleware and endpoints
+	var attrs []any
 	getMiddleware := func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			attrs = logging.Attrs(r.Context())
 			w.Header().Set("X-Test-Middleware", "true")
 			next.ServeHTTP(w, r)
 		})
@@ -385,7 +398,7 @@ func TestSetupMux(t *testing.T) {
 	}
 
 	// Setup Mux
-	mux := setupMux(endpoints, loggerInfoFn, loggerErrorFn)
+	mux := setupMux(endpoints, logger)
 
 	// Case 1: Test registered endpoint with allowed GET method
 	recorder := httptest.NewRecorder()
@@ -394,6 +407,11 @@ func TestSetupMux(t *testing.T) {
 
 	assert.Equal(t, http.StatusOK, recorder.Code)
 	assert.Equal(t, "true", recorder.Header().Get("X-Test-Middleware"))
+	assert.Equal(
+		t,
+		[]any{"endpoint_method", "GET", "endpoint_url", "/test"},
+		attrs,
+	)
 
 	// Case 2: Test registered endpoint with allowed POST method
 	recorder = httptest.NewRecorder()
@@ -409,16 +427,96 @@ func TestSetupMux(t *testing.T) {
 	mux.ServeHTTP(recorder, request)
 
 	assert.Equal(t, http.StatusMethodNotAllowed, recorder.Code)
-	assert.Contains(t, infoLogMessages[0], "Method not allowed")
+	entries := logger.Entries()
+	lastEntry := entries[len(entries)-1]
+	assert.Equal(t, "Method not allowed", lastEntry.Message)
+	assert.Equal(t, "PUT", lastEntry.Arg("method"))
 }
 
-// TestCreateEndpointHandler tests the createEndpointHandler function.
-func TestCreateEndpointHandler(t *testing.T) {
-	mockLogger := func(r *http.Request) func(messages ...any) {
-		return func(messages ...any) {
-			fmt.Println(messages...)
+// TestSetupMux_PathParameters tests routing pattern URLs and storing their
+// parameters in the request context.
+func TestSetupMux_PathParameters(t *testing.T) {
+	logger := logging.NopLogger{}
+
+	var parameters map[string]string
+	middleware := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			parameters = api.PathParameters(r.Context())
+		})
+	}
+	mux := setupMux(
+		[]api.Endpoint{
+			{
+				URL:         "/users/{id}/orders/{orderID}",
+				Method:      http.MethodGet,
+				Middlewares: []api.Middleware{middleware},
+			},
+		},
+		logger,
+	)
+
+	recorder := httptest.NewRecorder()
+	mux.ServeHTTP(
+		recorder,
+		httptest.NewRequest(http.MethodGet, "/users/5/orders/a%20b", nil),
+	)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, map[string]string{"id": "5", "orderID": "a b"}, parameters)
+
+	recorder = httptest.NewRecorder()
+	mux.ServeHTTP(
+		recorder,
+		httptest.NewRequest(http.MethodGet, "/users/5/orders", nil),
+	)
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+}
+
+// TestSetupMux_RootEndpoint tests that an endpoint registered at the root
+// handles the requests not matching the other endpoints.
+func TestSetupMux_RootEndpoint(t *testing.T) {
+	handler := func(body string) api.Middleware {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(
+				func(w http.ResponseWriter, r *http.Request) {
+					_, _ = w.Write([]byte(body))
+				},
+			)
 		}
 	}
+	mux := setupMux(
+		[]api.Endpoint{
+			{
+				URL:         "/",
+				Method:      http.MethodGet,
+				Middlewares: []api.Middleware{handler("root")},
+			},
+			{
+				URL:         "/users",
+				Method:      http.MethodGet,
+				Middlewares: []api.Middleware{handler("users")},
+			},
+		},
+		logging.NopLogger{},
+	)
+
+	for target, body := range map[string]string{
+		"/users":     "users",
+		"/":          "root",
+		"/dashboard": "root",
+	} {
+		recorder := httptest.NewRecorder()
+		mux.ServeHTTP(
+			recorder,
+			httptest.NewRequest(http.MethodGet, target, nil),
+		)
+		assert.Equal(t, body, recorder.Body.String(), target)
+	}
+}
+
+// TestCreateEndpointHandler tests the createEndpointHandler function.
+func TestCreateEndpointHandler(t *testing.T) {
+	mockLogger := &mock.Recorder{}
 
 	// Mock handlers for different HTTP methods
 	getHandler := http.HandlerFunc(
@@ -439,7 +537,7 @@ func TestCreateEndpointHandler(t *testing.T) {
 	}
 
 	// Create an instance of the handler
-	handler := createEndpointHandler(endpoints, mockLogger, mockLogger)
+	handler := createEndpointHandler(endpoints, mockLogger)
 
 	tests := []struct {
 		name           string
@@ -479,11 +577,7 @@ func TestCreateEndpointHandler(t *testing.T) {
 
 // TestCreateNotFoundHandler tests the createNotFoundHandler function.
 func TestCreateNotFoundHandler(t *testing.T) {
-	mockLogger := func(r *http.Request) func(messages ...any) {
-		return func(messages ...any) {
-			fmt.Println(messages...)
-		}
-	}
+	mockLogger := &mock.Recorder{}
 
 	// Create an instance of the not found handler
 	handler := createNotFoundHandler(mockLogger)
@@ -532,11 +626,7 @@ func TestMapKeys(t *testing.T) {
 
 // TestMultiplexEndpoints tests the multiplexEndpoints function.
 func TestMultiplexEndpoints(t *testing.T) {
-	loggerErrorFn := func(r *http.Request) func(messages ...any) {
-		return func(messages ...any) {
-			t.Log("Error:", messages) // Logging for verification
-		}
-	}
+	logger := &mock.Recorder{}
 
 	testMiddleware := func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -556,7 +646,7 @@ func TestMultiplexEndpoints(t *testing.T) {
 		},
 	}
 
-	muxEndpoints := multiplexEndpoints(endpoints, loggerErrorFn)
+	muxEndpoints := multiplexEndpoints(endpoints, logger)
 
 	// Test the middleware is applied
 	recorder := httptest.NewRecorder()
@@ -567,16 +657,121 @@ func TestMultiplexEndpoints(t *testing.T) {
 	assert.Equal(t, http.StatusOK, recorder.Code)
 }
 
-// TestServerPanicHandler tests the serverPanicHandler function.
-func TestServerPanicHandler(t *testing.T) {
-	var loggedMessages []string
+// TestMultiplexEndpoints_AutomaticMethods tests answering HEAD and OPTIONS
+// requests automatically.
+func TestMultiplexEndpoints_AutomaticMethods(t *testing.T) {
+	get := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Method", r.Method)
+			_, _ = w.Write([]byte("body"))
+		})
+	}
+	endpoints := multiplexEndpoints([]api.Endpoint{
+		{
+			URL:         "/items",
+			Method:      http.MethodGet,
+			Middlewares: []api.Middleware{get},
+		},
+		{URL: "/items", Method: http.MethodPost},
+		{URL: "/explicit", Method: http.MethodGet},
+		{URL: "/explicit", Method: http.MethodHead},
+		{URL: "/explicit", Method: http.MethodOptions},
+	}, &mock.Recorder{})
 
-	// Mock loggerErrorFn to capture log messages
-	loggerErrorFn := func(r *http.Request) func(messages ...any) {
-		return func(messages ...any) {
-			loggedMessages = append(loggedMessages, fmt.Sprint(messages...))
-		}
+	recorder := httptest.NewRecorder()
+	endpoints["/items"][http.MethodHead].ServeHTTP(
+		recorder,
+		httptest.NewRequest(http.MethodHead, "/items", nil),
+	)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, http.MethodHead, recorder.Header().Get("X-Method"))
+	assert.Empty(t, recorder.Body.String())
+
+	recorder = httptest.NewRecorder()
+	endpoints["/items"][http.MethodOptions].ServeHTTP(
+		recorder,
+		httptest.NewRequest(http.MethodOptions, "/items", nil),
+	)
+	assert.Equal(t, http.StatusNoContent, recorder.Code)
+	assert.Equal(
+		t,
+		"GET, HEAD, OPTIONS, POST",
+		recorder.Header().Get("Allow"),
+	)
+
+	recorder = httptest.NewRecorder()
+	createEndpointHandler(endpoints["/items"], &mock.Recorder{}).ServeHTTP(
+		recorder,
+		httptest.NewRequest(http.MethodPut, "/items", nil),
+	)
+	assert.Equal(t, http.StatusMethodNotAllowed, recorder.Code)
+	assert.Equal(
+		t,
+		"GET, HEAD, OPTIONS, POST",
+		recorder.Header().Get("Allow"),
+	)
+	assert.Len(t, endpoints["/explicit"], 3)
+}
+
+// TestMultiplexEndpoints_OptionsMiddlewares tests running the options
+// middlewares, e.g. CORS, for the automatic OPTIONS requests.
+func TestMultiplexEndpoints_OptionsMiddlewares(t *testing.T) {
+	cors := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Access-Control-Allow-Origin", "*")
+			next.ServeHTTP(w, r)
+		})
 	}
+	called := false
+	endpoint := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			called = true
+		})
+	}
+	endpoints := multiplexEndpoints([]api.Endpoint{
+		{
+			URL:                "/items",
+			Method:             http.MethodPost,
+			Middlewares:        []api.Middleware{cors, endpoint},
+			OptionsMiddlewares: []api.Middleware{cors},
+		},
+	}, &mock.Recorder{})
+
+	recorder := httptest.NewRecorder()
+	endpoints["/items"][http.MethodOptions].ServeHTTP(
+		recorder,
+		httptest.NewRequest(http.MethodOptions, "/items", nil),
+	)
+
+	assert.Equal(t, http.StatusNoContent, recorder.Code)
+	assert.Equal(t, "OPTIONS, POST", recorder.Header().Get("Allow"))
+	assert.Equal(
+		t,
+		"*",
+		recorder.Header().Get("Access-Control-Allow-Origin"),
+	)
+	assert.False(t, called)
+}
+
+// TestMultiplexEndpoints_DisableAutomaticMethods tests opting out of the
+// automatic HEAD and OPTIONS handlers.
+func TestMultiplexEndpoints_DisableAutomaticMethods(t *testing.T) {
+	endpoints := multiplexEndpoints([]api.Endpoint{
+		{URL: "/items", Method: http.MethodGet, DisableAutoHead: true},
+		{URL: "/items", Method: http.MethodPost, DisableAutoOptions: true},
+	}, &mock.Recorder{})
+
+	assert.ElementsMatch(
+		t,
+		[]string{http.MethodGet, http.MethodPost},
+		mapKeys(endpoints["/items"]),
+	)
+}
+
+// TestServerPanicHandler tests the serverPanicHandler function.
+func TestServerPanicHandler(t *testing.T) {
+	// Mock logger to capture log messages
+	logger := &mock.Recorder{}
 
 	// Case 1: Test normal execution without panic
 	normalHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -585,7 +780,7 @@ func TestServerPanicHandler(t *testing.T) {
 		assert.Nil(t, err)
 	})
 
-	handler := serverPanicHandler(normalHandler, loggerErrorFn)
+	handler := serverPanicHandler(normalHandler, logger)
 
 	recorder := httptest.NewRecorder()
 	request := httptest.NewRequest("GET", "/", nil)
@@ -593,16 +788,16 @@ func TestServerPanicHandler(t *testing.T) {
 
 	assert.Equal(t, http.StatusOK, recorder.Code)
 	assert.Equal(t, "OK", recorder.Body.String())
-	assert.Empty(t, loggedMessages) // No panic, no log should be captured
+	assert.Empty(t, logger.Entries()) // No panic, no log should be captured
 
 	// Case 2: Test execution with panic
-	loggedMessages = []string{} // Reset log messages
+	logger.Reset() // Reset log messages
 
 	panicHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		panic("test panic")
 	})
 
-	handler = serverPanicHandler(panicHandler, loggerErrorFn)
+	handler = serverPanicHandler(panicHandler, logger)
 
 	recorder = httptest.NewRecorder()
 	request = httptest.NewRequest("GET", "/", nil)
@@ -610,9 +805,11 @@ func TestServerPanicHandler(t *testing.T) {
 
 	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
 	assert.Contains(t, recorder.Body.String(), http.StatusText(http.StatusInternalServerError))
-	assert.Len(t, loggedMessages, 1)
-	assert.Contains(t, loggedMessages[0], "Server panic")
-	assert.Contains(t, loggedMessages[0], "test panic")
+	entries := logger.Entries()
+	assert.Len(t, entries, 1)
+	assert.Equal(t, "error", entries[0].Level)
+	assert.Equal(t, "Server panic", entries[0].Message)
+	assert.Equal(t, "test panic", entries[0].Arg("panic"))
 }
 
 // TestStackTraceSlice tests the stackTraceSlice function.
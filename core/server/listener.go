@@ -0,0 +1,153 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+)
+
+// WithHost binds the server to the host instead of all interfaces, e.g.
+// "localhost" for an admin server.
+//
+//   - host: The host name or IP address.
+func WithHost(host string) ServerOption {
+	return func(o *serverOptions) {
+		o.host = host
+	}
+}
+
+// WithUnixSocket listens on the Unix domain socket instead of the port.
+//
+//   - path: The path of the socket file.
+//   - mode: The permissions of the socket file. Optional.
+func WithUnixSocket(path string, mode fs.FileMode) ServerOption {
+	return func(o *serverOptions) {
+		o.unixSocket = path
+		o.unixSocketMode = mode
+	}
+}
+
+// UnixServer is an HTTP server listening on a Unix domain socket.
+type UnixServer struct {
+	// Server is the HTTP server.
+	Server *http.Server
+	// Path is the path of the socket file.
+	Path string
+	// Mode is the permissions of the socket file. If zero, the permissions
+	// are set by the umask of the process.
+	Mode fs.FileMode
+}
+
+// NewUnixServer creates a new server listening on a Unix domain socket.
+//
+//   - server: The HTTP server.
+//   - path: The path of the socket file.
+//   - mode: The permissions of the socket file. Optional.
+func NewUnixServer(
+	server *http.Server,
+	path string,
+	mode fs.FileMode,
+) *UnixServer {
+	return &UnixServer{Server: server, Path: path, Mode: mode}
+}
+
+// ListenAndServe listens on the socket and serves the requests. A stale
+// socket file left by a previous process is removed. The socket file is
+// removed when the server is shut down.
+func (s *UnixServer) ListenAndServe() error {
+	if info, err := os.Stat(s.Path); err == nil {
+		if info.Mode().Type() != fs.ModeSocket {
+			return fmt.Errorf("%s exists and is not a socket", s.Path)
+		}
+		if err := os.Remove(s.Path); err != nil {
+			return err
+		}
+	}
+
+	listener, err := net.Listen("unix", s.Path)
+	if err != nil {
+		return err
+	}
+	if s.Mode != 0 {
+		if err := os.Chmod(s.Path, s.Mode); err != nil {
+			_ = listener.Close()
+			return err
+		}
+	}
+	return s.Server.Serve(listener)
+}
+
+// Shutdown gracefully shuts down the server.
+//
+//   - ctx: The context limiting the time of the shutdown.
+func (s *UnixServer) Shutdown(ctx context.Context) error {
+	return s.Server.Shutdown(ctx)
+}
+
+// MultiServer runs several servers together, e.g. a public server and an
+// admin server bound to localhost with a different set of endpoints:
+//
+//	server.HTTPServer(server.NewMultiServer(
+//		server.DefaultHTTPServer(8080, publicEndpoints, logger),
+//		server.DefaultHTTPServer(
+//			9090,
+//			adminEndpoints,
+//			logger,
+//			server.WithHost("localhost"),
+//		),
+//	))
+type MultiServer struct {
+	servers []IServer
+}
+
+// NewMultiServer creates a new server running the servers together.
+//
+//   - servers: The servers to run.
+func NewMultiServer(servers ...IServer) *MultiServer {
+	if len(servers) == 0 {
+		panic("at least one server is required")
+	}
+	return &MultiServer{servers: servers}
+}
+
+// ListenAndServe starts the servers. It returns the first error of a server
+// failing to start, or http.ErrServerClosed once all the servers have been
+// shut down.
+func (s *MultiServer) ListenAndServe() error {
+	errChan := make(chan error, len(s.servers))
+	for _, server := range s.servers {
+		go func() {
+			errChan <- server.ListenAndServe()
+		}()
+	}
+
+	for range s.servers {
+		err := <-errChan
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+	}
+	return http.ErrServerClosed
+}
+
+// Shutdown gracefully shuts down the servers concurrently.
+//
+//   - ctx: The context limiting the time of the shutdown.
+func (s *MultiServer) Shutdown(ctx context.Context) error {
+	errs := make([]error, len(s.servers))
+	var wg sync.WaitGroup
+	for i, server := range s.servers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = server.Shutdown(ctx)
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
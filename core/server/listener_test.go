@@ -0,0 +1,173 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// blockingServer returns a mock server serving until it is shut down.
+func blockingServer(shutdownErr error) *MockServer {
+	stop := make(chan struct{})
+	return &MockServer{
+		ListenAndServeFunc: func() error {
+			<-stop
+			return http.ErrServerClosed
+		},
+		ShutdownFunc: func(ctx context.Context) error {
+			close(stop)
+			return shutdownErr
+		},
+	}
+}
+
+func socketPath(t *testing.T) string {
+	// Socket paths are limited to about 100 bytes, so the path is kept short.
+	dir, err := os.MkdirTemp("", "srv")
+	assert.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+	return filepath.Join(dir, "api.sock")
+}
+
+// TestDefaultHTTPServer_WithHost tests binding the server to a host.
+func TestDefaultHTTPServer_WithHost(t *testing.T) {
+	server := DefaultHTTPServer(
+		9090,
+		nil,
+		nil,
+		WithHost("localhost"),
+	).(*http.Server)
+
+	assert.Equal(t, "localhost:9090", server.Addr)
+}
+
+// TestDefaultHTTPServer_UnixSocketWithTLS tests that TLS cannot be used on
+// Unix sockets.
+func TestDefaultHTTPServer_UnixSocketWithTLS(t *testing.T) {
+	assert.PanicsWithValue(t, "TLS is not supported on Unix sockets", func() {
+		DefaultHTTPServer(
+			0,
+			nil,
+			nil,
+			WithUnixSocket("api.sock", 0),
+			WithTLS(TLSConfig{CertFile: "cert.pem", KeyFile: "key.pem"}),
+		)
+	})
+}
+
+// TestNewHTTPServer_UnixSocketWithTLS tests the error of using TLS on Unix
+// sockets.
+func TestNewHTTPServer_UnixSocketWithTLS(t *testing.T) {
+	server, err := NewHTTPServer(
+		0,
+		nil,
+		nil,
+		WithUnixSocket("api.sock", 0),
+		WithTLS(TLSConfig{CertFile: "cert.pem", KeyFile: "key.pem"}),
+	)
+
+	assert.EqualError(t, err, "TLS is not supported on Unix sockets")
+	assert.Nil(t, server)
+}
+
+// TestUnixServer tests serving requests on a Unix socket.
+func TestUnixServer(t *testing.T) {
+	path := socketPath(t)
+	// A stale socket file is removed.
+	stale, err := net.Listen("unix", path)
+	assert.NoError(t, err)
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	assert.NoError(t, stale.Close())
+
+	server := DefaultHTTPServer(
+		0,
+		nil,
+		nil,
+		WithUnixSocket(path, 0o660),
+	).(*UnixServer)
+	errChan := make(chan error, 1)
+	go func() { errChan <- server.ListenAndServe() }()
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(
+			ctx context.Context,
+			network string,
+			addr string,
+		) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", path)
+		},
+	}}
+	var resp *http.Response
+	assert.Eventually(t, func() bool {
+		resp, err = client.Get("http://unix/missing")
+		return err == nil
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	_ = resp.Body.Close()
+
+	info, err := os.Stat(path)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o660), info.Mode().Perm())
+
+	assert.NoError(t, server.Shutdown(context.Background()))
+	assert.ErrorIs(t, <-errChan, http.ErrServerClosed)
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err), "Expected socket file to be removed")
+}
+
+// TestUnixServer_NotSocket tests that other files are not removed.
+func TestUnixServer_NotSocket(t *testing.T) {
+	path := socketPath(t)
+	assert.NoError(t, os.WriteFile(path, []byte("data"), 0o600))
+
+	server := NewUnixServer(&http.Server{}, path, 0)
+	err := server.ListenAndServe()
+
+	assert.EqualError(t, err, path+" exists and is not a socket")
+	_, err = os.Stat(path)
+	assert.NoError(t, err)
+}
+
+// TestMultiServer tests running and shutting down several servers.
+func TestMultiServer(t *testing.T) {
+	first := blockingServer(nil)
+	second := blockingServer(errors.New("shutdown error"))
+	server := NewMultiServer(first, second)
+
+	errChan := make(chan error, 1)
+	go func() { errChan <- server.ListenAndServe() }()
+
+	err := server.Shutdown(context.Background())
+	assert.EqualError(t, err, "shutdown error")
+	assert.ErrorIs(t, <-errChan, http.ErrServerClosed)
+}
+
+// TestMultiServer_ListenError tests that the first start error is returned.
+func TestMultiServer_ListenError(t *testing.T) {
+	first := blockingServer(nil)
+	second := &MockServer{
+		ListenAndServeFunc: func() error {
+			return errors.New("listen error")
+		},
+	}
+	server := NewMultiServer(first, second)
+
+	err := server.ListenAndServe()
+
+	assert.EqualError(t, err, "listen error")
+	assert.NoError(t, first.Shutdown(context.Background()))
+}
+
+// TestNewMultiServer_NoServers tests that at least one server is required.
+func TestNewMultiServer_NoServers(t *testing.T) {
+	assert.PanicsWithValue(t, "at least one server is required", func() {
+		NewMultiServer()
+	})
+}
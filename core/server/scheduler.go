@@ -0,0 +1,247 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pakkasys/fluidapi/core/logging"
+)
+
+// TaskFunc is a function run by the scheduler.
+type TaskFunc func(ctx context.Context) error
+
+// TaskOption configures a scheduled task.
+type TaskOption func(*task)
+
+// WithTaskTimeout sets the timeout of the context of each run of the task.
+//
+//   - timeout: The timeout of a run.
+func WithTaskTimeout(timeout time.Duration) TaskOption {
+	return func(t *task) {
+		t.timeout = timeout
+	}
+}
+
+type task struct {
+	name     string
+	schedule Schedule
+	fn       TaskFunc
+	timeout  time.Duration
+	running  atomic.Bool
+}
+
+// Scheduler runs functions on cron schedules. A run of a task is skipped if
+// the previous run of the task has not finished. It is safe for concurrent
+// use.
+type Scheduler struct {
+	logger  logging.Logger
+	mu      sync.Mutex
+	tasks   []*task
+	ctx     context.Context
+	cancel  context.CancelFunc
+	stopCtx context.Context
+	stop    context.CancelFunc
+	loops   sync.WaitGroup
+	runs    sync.WaitGroup
+	started bool
+	nowFn   func() time.Time
+}
+
+// NewScheduler creates a new scheduler. The tasks are run after Start.
+//
+//   - logger: Logger for the task errors. If nil, nothing is logged.
+func NewScheduler(logger logging.Logger) *Scheduler {
+	if logger == nil {
+		logger = logging.NopLogger{}
+	}
+	return &Scheduler{logger: logger, nowFn: time.Now}
+}
+
+// Add adds a task running on a cron expression, see ParseSchedule. A task
+// added after Start is scheduled immediately.
+//
+//   - name: The name of the task, added to the log entries.
+//   - spec: The cron expression of the task.
+//   - fn: The function of the task.
+//   - opts: Options such as WithTaskTimeout.
+//
+// Example:
+//
+//	err := scheduler.Add("cleanup", "@daily", cleanup)
+func (s *Scheduler) Add(
+	name string,
+	spec string,
+	fn TaskFunc,
+	opts ...TaskOption,
+) error {
+	schedule, err := ParseSchedule(spec)
+	if err != nil {
+		return err
+	}
+	s.AddSchedule(name, schedule, fn, opts...)
+	return nil
+}
+
+// AddSchedule adds a task running on a schedule.
+//
+//   - name: The name of the task, added to the log entries.
+//   - schedule: The schedule of the task.
+//   - fn: The function of the task.
+//   - opts: Options such as WithTaskTimeout.
+func (s *Scheduler) AddSchedule(
+	name string,
+	schedule Schedule,
+	fn TaskFunc,
+	opts ...TaskOption,
+) {
+	if schedule == nil {
+		panic("schedule cannot be nil")
+	}
+	if fn == nil {
+		panic("task function cannot be nil")
+	}
+	t := &task{name: name, schedule: schedule, fn: fn}
+	for _, opt := range opts {
+		opt(t)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tasks = append(s.tasks, t)
+	if s.started && s.stopCtx.Err() == nil {
+		s.startLoop(t)
+	}
+}
+
+// Start starts running the tasks. The context of the runs is derived from
+// the given context without its cancellation, so that the runs are only
+// canceled by Shutdown.
+//
+//   - ctx: The parent context of the runs, e.g. with logging attributes.
+func (s *Scheduler) Start(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.started {
+		return errors.New("scheduler already started")
+	}
+	s.started = true
+	s.ctx, s.cancel = context.WithCancel(context.WithoutCancel(ctx))
+	s.stopCtx, s.stop = context.WithCancel(context.Background())
+	for _, t := range s.tasks {
+		s.startLoop(t)
+	}
+	return nil
+}
+
+// Shutdown stops scheduling new runs and waits for the running tasks to
+// finish. If the context is done first, the contexts of the running tasks
+// are canceled and the context error is returned. It can be used as a
+// shutdown callback of the server with WithOnShutdown.
+//
+//   - ctx: The context limiting the wait.
+func (s *Scheduler) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	if !s.started {
+		s.mu.Unlock()
+		return nil
+	}
+	s.stop()
+	s.mu.Unlock()
+	s.loops.Wait()
+
+	done := make(chan struct{})
+	go func() {
+		s.runs.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		s.cancel()
+		return nil
+	case <-ctx.Done():
+		s.cancel()
+		return ctx.Err()
+	}
+}
+
+// startLoop starts scheduling the runs of the task. The caller must hold the
+// lock.
+func (s *Scheduler) startLoop(t *task) {
+	s.loops.Add(1)
+	go func() {
+		defer s.loops.Done()
+		s.loop(t)
+	}()
+}
+
+func (s *Scheduler) loop(t *task) {
+	for {
+		now := s.nowFn()
+		next := t.schedule.Next(now)
+		if next.IsZero() {
+			return
+		}
+		timer := time.NewTimer(next.Sub(now))
+		select {
+		case <-s.stopCtx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		s.trigger(t)
+	}
+}
+
+// trigger starts a run of the task unless the previous run is still
+// running.
+func (s *Scheduler) trigger(t *task) {
+	if !t.running.CompareAndSwap(false, true) {
+		s.logger.Warn(
+			s.ctx,
+			"Scheduled task skipped, previous run still running",
+			"task", t.name,
+		)
+		return
+	}
+	s.runs.Add(1)
+	go func() {
+		defer s.runs.Done()
+		defer t.running.Store(false)
+		s.run(t)
+	}()
+}
+
+// run runs the task once with its own context. Errors and panics are logged.
+func (s *Scheduler) run(t *task) {
+	ctx := logging.WithAttrs(s.ctx, "task", t.name)
+	if t.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, t.timeout)
+		defer cancel()
+	}
+	defer func() {
+		if err := recover(); err != nil {
+			s.logger.Error(
+				ctx,
+				"Scheduled task panic",
+				"task", t.name,
+				"panic", fmt.Sprintf("%v", err),
+				"stack_trace", stackTraceSlice(),
+			)
+		}
+	}()
+
+	if err := t.fn(ctx); err != nil {
+		s.logger.Error(
+			ctx,
+			"Scheduled task error",
+			"task", t.name,
+			"error", err,
+		)
+	}
+}
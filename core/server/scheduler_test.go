@@ -0,0 +1,178 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pakkasys/fluidapi/core/logging"
+	"github.com/pakkasys/fluidapi/core/logging/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+// everyMillisecond is a schedule running a task every millisecond.
+var everyMillisecond = everySchedule{interval: time.Millisecond}
+
+// TestScheduler_Run tests running a task with its own context.
+func TestScheduler_Run(t *testing.T) {
+	scheduler := NewScheduler(nil)
+	runs := make(chan context.Context, 10)
+	scheduler.AddSchedule(
+		"task",
+		everyMillisecond,
+		func(ctx context.Context) error {
+			runs <- ctx
+			return nil
+		},
+		WithTaskTimeout(time.Minute),
+	)
+
+	parent, cancel := context.WithCancel(context.Background())
+	assert.NoError(t, scheduler.Start(parent))
+	cancel()
+	ctx := <-runs
+
+	assert.NoError(t, scheduler.Shutdown(context.Background()))
+	assert.Equal(t, []any{"task", "task"}, logging.Attrs(ctx))
+	_, hasDeadline := ctx.Deadline()
+	assert.True(t, hasDeadline)
+}
+
+// TestScheduler_Start_Twice tests that a scheduler can be started once.
+func TestScheduler_Start_Twice(t *testing.T) {
+	scheduler := NewScheduler(nil)
+	assert.NoError(t, scheduler.Start(context.Background()))
+
+	err := scheduler.Start(context.Background())
+
+	assert.EqualError(t, err, "scheduler already started")
+	assert.NoError(t, scheduler.Shutdown(context.Background()))
+}
+
+// TestScheduler_Add tests adding tasks with cron expressions.
+func TestScheduler_Add(t *testing.T) {
+	scheduler := NewScheduler(nil)
+	task := func(ctx context.Context) error { return nil }
+
+	assert.NoError(t, scheduler.Add("task", "@every 1s", task))
+	assert.Error(t, scheduler.Add("task", "invalid", task))
+	assert.Len(t, scheduler.tasks, 1)
+	assert.Panics(t, func() { scheduler.AddSchedule("task", nil, task) })
+	assert.Panics(t, func() {
+		scheduler.AddSchedule("task", everyMillisecond, nil)
+	})
+}
+
+// TestScheduler_AddAfterStart tests that tasks added after Start are run.
+func TestScheduler_AddAfterStart(t *testing.T) {
+	scheduler := NewScheduler(nil)
+	assert.NoError(t, scheduler.Start(context.Background()))
+	runs := make(chan struct{}, 10)
+
+	scheduler.AddSchedule(
+		"task",
+		everyMillisecond,
+		func(ctx context.Context) error {
+			runs <- struct{}{}
+			return nil
+		},
+	)
+
+	<-runs
+	assert.NoError(t, scheduler.Shutdown(context.Background()))
+}
+
+// TestScheduler_ErrorAndPanic tests logging the errors and the panics of the
+// tasks.
+func TestScheduler_ErrorAndPanic(t *testing.T) {
+	logger := &mock.Recorder{}
+	scheduler := NewScheduler(logger)
+	assert.NoError(t, scheduler.Start(context.Background()))
+	defer scheduler.Shutdown(context.Background())
+
+	scheduler.run(&task{
+		name: "error",
+		fn:   func(ctx context.Context) error { return errors.New("failed") },
+	})
+	scheduler.run(&task{
+		name: "panic",
+		fn:   func(ctx context.Context) error { panic("boom") },
+	})
+
+	entries := logger.Entries()
+	assert.Len(t, entries, 2)
+	assert.Equal(t, "Scheduled task error", entries[0].Message)
+	assert.Equal(t, "error", entries[0].Arg("task"))
+	assert.EqualError(t, entries[0].Arg("error").(error), "failed")
+	assert.Equal(t, "Scheduled task panic", entries[1].Message)
+	assert.Equal(t, "panic", entries[1].Arg("task"))
+	assert.Equal(t, "boom", entries[1].Arg("panic"))
+	assert.NotEmpty(t, entries[1].Arg("stack_trace"))
+}
+
+// TestScheduler_Overlap tests that a run is skipped while the previous run
+// is running.
+func TestScheduler_Overlap(t *testing.T) {
+	logger := &mock.Recorder{}
+	scheduler := NewScheduler(logger)
+	var runs atomic.Int32
+	release := make(chan struct{})
+	scheduler.AddSchedule(
+		"task",
+		everyMillisecond,
+		func(ctx context.Context) error {
+			runs.Add(1)
+			<-release
+			return nil
+		},
+	)
+
+	assert.NoError(t, scheduler.Start(context.Background()))
+	assert.Eventually(t, func() bool {
+		return len(logger.Messages()) > 0
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, int32(1), runs.Load())
+	close(release)
+
+	assert.NoError(t, scheduler.Shutdown(context.Background()))
+	assert.Equal(
+		t,
+		"Scheduled task skipped, previous run still running",
+		logger.Messages()[0],
+	)
+}
+
+// TestScheduler_Shutdown_Timeout tests that the running tasks are canceled
+// when the shutdown context is done.
+func TestScheduler_Shutdown_Timeout(t *testing.T) {
+	scheduler := NewScheduler(nil)
+	started := make(chan struct{})
+	canceled := make(chan struct{})
+	scheduler.AddSchedule(
+		"task",
+		everyMillisecond,
+		func(ctx context.Context) error {
+			close(started)
+			<-ctx.Done()
+			close(canceled)
+			return nil
+		},
+	)
+	assert.NoError(t, scheduler.Start(context.Background()))
+	<-started
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := scheduler.Shutdown(ctx)
+
+	assert.ErrorIs(t, err, context.Canceled)
+	<-canceled
+}
+
+// TestScheduler_Shutdown_NotStarted tests shutting down a scheduler that was
+// not started.
+func TestScheduler_Shutdown_NotStarted(t *testing.T) {
+	assert.NoError(t, NewScheduler(nil).Shutdown(context.Background()))
+}
@@ -0,0 +1,165 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/pakkasys/fluidapi/core/logging"
+)
+
+const defaultShutdownTimeout = 60 * time.Second
+
+// State is the lifecycle state of a server.
+type State int32
+
+const (
+	// StateStarting is the state before the server has started.
+	StateStarting State = iota
+	// StateRunning is the state of a server serving requests.
+	StateRunning
+	// StateDraining is the state of a server finishing the active requests
+	// before shutting down.
+	StateDraining
+	// StateStopped is the state of a server that has shut down.
+	StateStopped
+)
+
+// String returns the name of the state.
+func (s State) String() string {
+	switch s {
+	case StateStarting:
+		return "starting"
+	case StateRunning:
+		return "running"
+	case StateDraining:
+		return "draining"
+	case StateStopped:
+		return "stopped"
+	default:
+		return "unknown"
+	}
+}
+
+// Status reports the lifecycle state of a server, e.g. to fail the readiness
+// checks of a load balancer while the connections are drained. It is safe
+// for concurrent use.
+type Status struct {
+	state atomic.Int32
+}
+
+// State returns the current state of the server.
+func (s *Status) State() State {
+	return State(s.state.Load())
+}
+
+// Draining returns whether the server is draining its connections.
+func (s *Status) Draining() bool {
+	return s.State() == StateDraining
+}
+
+// ReadinessHandler returns a handler responding with 200 OK while the server
+// is running and with 503 Service Unavailable otherwise.
+func (s *Status) ReadinessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		state := s.State()
+		if state != StateRunning {
+			http.Error(w, state.String(), http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(state.String()))
+	})
+}
+
+func (s *Status) set(state State) {
+	s.state.Store(int32(state))
+}
+
+type options struct {
+	shutdownTimeout time.Duration
+	onShutdown      []func(ctx context.Context) error
+	status          *Status
+	schedulers      []*Scheduler
+	logger          logging.Logger
+}
+
+// Option configures how HTTPServer runs and shuts down the server.
+type Option func(*options)
+
+// WithShutdownTimeout sets the time given to the active requests to finish,
+// and then the time given to the shutdown callbacks. Defaults to 60 seconds.
+//
+//   - timeout: The shutdown timeout.
+func WithShutdownTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.shutdownTimeout = timeout
+	}
+}
+
+// WithOnShutdown adds callbacks that are called after the server has stopped
+// serving requests, e.g. to close database pools or flush telemetry. They are
+// called in the order they were added, with a context of a new shutdown
+// timeout, so that they run even if draining used up the shutdown timeout.
+//
+//   - callbacks: The shutdown callbacks.
+func WithOnShutdown(callbacks ...func(ctx context.Context) error) Option {
+	return func(o *options) {
+		o.onShutdown = append(o.onShutdown, callbacks...)
+	}
+}
+
+// WithStatus sets the status that reports the lifecycle state of the server.
+//
+//   - status: The status of the server.
+func WithStatus(status *Status) Option {
+	return func(o *options) {
+		o.status = status
+	}
+}
+
+// WithScheduler starts the scheduler with the server and shuts it down with
+// the server after the connections are drained, so that the running tasks
+// can finish within the shutdown timeout.
+//
+//   - scheduler: The scheduler of the tasks.
+func WithScheduler(scheduler *Scheduler) Option {
+	return func(o *options) {
+		o.schedulers = append(o.schedulers, scheduler)
+		o.onShutdown = append(o.onShutdown, scheduler.Shutdown)
+	}
+}
+
+// WithLogger sets the logger for the start and shutdown messages of the
+// server, usually the logger passed to NewHTTPServer. Defaults to a slog
+// logger writing to slog.Default().
+//
+//   - logger: The logger. If nil, nothing is logged.
+func WithLogger(logger logging.Logger) Option {
+	return func(o *options) {
+		o.logger = logger
+		if o.logger == nil {
+			o.logger = logging.NopLogger{}
+		}
+	}
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{
+		shutdownTimeout: defaultShutdownTimeout,
+		status:          &Status{},
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.shutdownTimeout <= 0 {
+		o.shutdownTimeout = defaultShutdownTimeout
+	}
+	if o.status == nil {
+		o.status = &Status{}
+	}
+	if o.logger == nil {
+		o.logger = logging.NewSlogLogger(nil)
+	}
+	return o
+}
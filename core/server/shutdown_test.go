@@ -0,0 +1,199 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestStartServer_Shutdown tests the shutdown callbacks, timeout and status.
+func TestStartServer_Shutdown(t *testing.T) {
+	stopChan := make(chan os.Signal, 1)
+	stopped := make(chan struct{})
+	status := &Status{}
+	var deadline time.Time
+	var calls []string
+	var statesDuringShutdown []State
+
+	mockServer := &MockServer{
+		ListenAndServeFunc: func() error {
+			<-stopped
+			return http.ErrServerClosed
+		},
+		ShutdownFunc: func(ctx context.Context) error {
+			statesDuringShutdown = append(statesDuringShutdown, status.State())
+			deadline, _ = ctx.Deadline()
+			close(stopped)
+			return nil
+		},
+	}
+
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- startServer(
+			stopChan,
+			mockServer,
+			WithShutdownTimeout(5*time.Second),
+			WithStatus(status),
+			WithOnShutdown(func(ctx context.Context) error {
+				calls = append(calls, "db")
+				return nil
+			}),
+			WithOnShutdown(func(ctx context.Context) error {
+				calls = append(calls, "telemetry")
+				return nil
+			}),
+		)
+	}()
+
+	assert.Eventually(t, func() bool {
+		return status.State() == StateRunning
+	}, time.Second, time.Millisecond)
+	start := time.Now()
+	stopChan <- os.Interrupt
+
+	assert.NoError(t, <-errChan)
+	assert.Equal(t, []State{StateDraining}, statesDuringShutdown)
+	assert.Equal(t, []string{"db", "telemetry"}, calls)
+	assert.Equal(t, StateStopped, status.State())
+	assert.WithinDuration(t, start.Add(5*time.Second), deadline, time.Second)
+}
+
+// TestStartServer_ShutdownCallbackError tests that callback errors are
+// returned after all callbacks have been called.
+func TestStartServer_ShutdownCallbackError(t *testing.T) {
+	stopChan := make(chan os.Signal, 1)
+	var called bool
+
+	mockServer := &MockServer{
+		ListenAndServeFunc: func() error { return http.ErrServerClosed },
+	}
+
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- startServer(
+			stopChan,
+			mockServer,
+			WithOnShutdown(
+				func(ctx context.Context) error {
+					return errors.New("callback error")
+				},
+				func(ctx context.Context) error {
+					called = true
+					return nil
+				},
+			),
+		)
+	}()
+	stopChan <- os.Interrupt
+
+	assert.EqualError(t, <-errChan, "callback error")
+	assert.True(t, called)
+}
+
+// TestStartServer_ShutdownCallbackTimeout tests that the callbacks get their
+// own timeout when draining uses up the shutdown timeout.
+func TestStartServer_ShutdownCallbackTimeout(t *testing.T) {
+	stopChan := make(chan os.Signal, 1)
+	var callbackErr error
+	var deadline time.Time
+
+	mockServer := &MockServer{
+		ListenAndServeFunc: func() error { return http.ErrServerClosed },
+		ShutdownFunc: func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		},
+	}
+
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- startServer(
+			stopChan,
+			mockServer,
+			WithShutdownTimeout(50*time.Millisecond),
+			WithOnShutdown(func(ctx context.Context) error {
+				callbackErr = ctx.Err()
+				deadline, _ = ctx.Deadline()
+				return nil
+			}),
+		)
+	}()
+	stopChan <- os.Interrupt
+
+	assert.NoError(t, <-errChan)
+	assert.NoError(t, callbackErr)
+	assert.WithinDuration(
+		t,
+		time.Now().Add(50*time.Millisecond),
+		deadline,
+		50*time.Millisecond,
+	)
+}
+
+// TestStartServer_Scheduler tests that the scheduler is started with the
+// server and shut down with it.
+func TestStartServer_Scheduler(t *testing.T) {
+	stopChan := make(chan os.Signal, 1)
+	scheduler := NewScheduler(nil)
+	runs := make(chan struct{}, 10)
+	scheduler.AddSchedule(
+		"task",
+		everySchedule{interval: time.Millisecond},
+		func(ctx context.Context) error {
+			runs <- struct{}{}
+			return nil
+		},
+	)
+
+	mockServer := blockingServer(nil)
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- startServer(stopChan, mockServer, WithScheduler(scheduler))
+	}()
+	<-runs
+	stopChan <- os.Interrupt
+
+	assert.NoError(t, <-errChan)
+	assert.Error(t, scheduler.stopCtx.Err())
+}
+
+// TestStatus_ReadinessHandler tests the readiness responses of the states.
+func TestStatus_ReadinessHandler(t *testing.T) {
+	status := &Status{}
+	handler := status.ReadinessHandler()
+
+	for _, test := range []struct {
+		state  State
+		status int
+	}{
+		{StateStarting, http.StatusServiceUnavailable},
+		{StateRunning, http.StatusOK},
+		{StateDraining, http.StatusServiceUnavailable},
+		{StateStopped, http.StatusServiceUnavailable},
+	} {
+		status.set(test.state)
+		rr := httptest.NewRecorder()
+
+		handler.ServeHTTP(rr, httptest.NewRequest("GET", "/ready", nil))
+
+		assert.Equal(t, test.status, rr.Code)
+		assert.Contains(t, rr.Body.String(), test.state.String())
+		assert.Equal(t, test.state == StateDraining, status.Draining())
+	}
+}
+
+// TestNewOptions tests the default options.
+func TestNewOptions(t *testing.T) {
+	o := newOptions([]Option{WithShutdownTimeout(0), WithStatus(nil)})
+
+	assert.Equal(t, defaultShutdownTimeout, o.shutdownTimeout)
+	assert.NotNil(t, o.status)
+	assert.Equal(t, "unknown", State(10).String())
+}
@@ -0,0 +1,180 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// CertManager obtains certificates on demand, e.g. from Let's Encrypt. It is
+// implemented by *autocert.Manager of golang.org/x/crypto/acme/autocert:
+//
+//	server.WithTLS(server.TLSConfig{
+//		CertManager: &autocert.Manager{
+//			Prompt:     autocert.AcceptTOS,
+//			HostPolicy: autocert.HostWhitelist("example.com"),
+//			Cache:      autocert.DirCache("certs"),
+//		},
+//		RedirectAddr: ":80",
+//	})
+type CertManager interface {
+	// GetCertificate returns the certificate for the TLS handshake.
+	GetCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error)
+	// HTTPHandler returns a handler answering the HTTP challenges and passing
+	// the other requests to the fallback handler.
+	HTTPHandler(fallback http.Handler) http.Handler
+}
+
+// TLSConfig configures the TLS of the server. The certificates are taken
+// from the certificate files, the tls.Config or the certificate manager.
+type TLSConfig struct {
+	// CertFile is the path of the certificate file, including any
+	// intermediate certificates.
+	CertFile string
+	// KeyFile is the path of the private key file of the certificate.
+	KeyFile string
+	// Config is the TLS configuration of the server. Optional. Defaults to a
+	// configuration requiring TLS 1.2.
+	Config *tls.Config
+	// CertManager obtains the certificates on demand. Optional.
+	CertManager CertManager
+	// RedirectAddr is the address of a plain HTTP server redirecting the
+	// requests to HTTPS, e.g. ":80". The server also answers the HTTP
+	// challenges of the certificate manager. Optional.
+	RedirectAddr string
+}
+
+// WithTLS serves the endpoints over TLS.
+//
+//   - config: The TLS configuration.
+func WithTLS(config TLSConfig) ServerOption {
+	return func(o *serverOptions) {
+		o.tls = &config
+	}
+}
+
+// TLSServer is an HTTPS server with an optional HTTP server redirecting to
+// it.
+type TLSServer struct {
+	// Server is the HTTPS server.
+	Server *http.Server
+	// RedirectServer is the HTTP server redirecting to HTTPS, or nil.
+	RedirectServer *http.Server
+
+	certFile string
+	keyFile  string
+}
+
+func newTLSServer(
+	server *http.Server,
+	port int,
+	config TLSConfig,
+) (*TLSServer, error) {
+	hasFiles := config.CertFile != "" && config.KeyFile != ""
+	hasConfigCerts := config.Config != nil &&
+		(len(config.Config.Certificates) != 0 ||
+			config.Config.GetCertificate != nil)
+	if !hasFiles && !hasConfigCerts && config.CertManager == nil {
+		return nil, fmt.Errorf(
+			"TLS requires certificate files, a tls.Config with " +
+				"certificates or a certificate manager",
+		)
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if config.Config != nil {
+		tlsConfig = config.Config.Clone()
+	}
+	if config.CertManager != nil {
+		tlsConfig.GetCertificate = config.CertManager.GetCertificate
+		if len(tlsConfig.NextProtos) == 0 {
+			tlsConfig.NextProtos = []string{"h2", "http/1.1"}
+		}
+		if !slices.Contains(tlsConfig.NextProtos, "acme-tls/1") {
+			tlsConfig.NextProtos = append(tlsConfig.NextProtos, "acme-tls/1")
+		}
+	}
+	server.TLSConfig = tlsConfig
+
+	tlsServer := &TLSServer{
+		Server:   server,
+		certFile: config.CertFile,
+		keyFile:  config.KeyFile,
+	}
+	if config.RedirectAddr != "" {
+		var handler http.Handler = RedirectToHTTPSHandler(port)
+		if config.CertManager != nil {
+			handler = config.CertManager.HTTPHandler(handler)
+		}
+		tlsServer.RedirectServer = &http.Server{
+			Addr:    config.RedirectAddr,
+			Handler: handler,
+		}
+	}
+	return tlsServer, nil
+}
+
+// ListenAndServe starts the redirect server and serves HTTPS until the
+// servers are shut down.
+func (s *TLSServer) ListenAndServe() error {
+	redirectErr := make(chan error, 1)
+	if s.RedirectServer != nil {
+		go func() {
+			err := s.RedirectServer.ListenAndServe()
+			if err != nil && err != http.ErrServerClosed {
+				redirectErr <- fmt.Errorf("redirect server: %w", err)
+				_ = s.Server.Close()
+			}
+		}()
+	}
+
+	err := s.Server.ListenAndServeTLS(s.certFile, s.keyFile)
+	select {
+	case rErr := <-redirectErr:
+		return rErr
+	default:
+		return err
+	}
+}
+
+// Shutdown gracefully shuts down the servers.
+//
+//   - ctx: The context limiting the time of the shutdown.
+func (s *TLSServer) Shutdown(ctx context.Context) error {
+	var redirectErr error
+	if s.RedirectServer != nil {
+		redirectErr = s.RedirectServer.Shutdown(ctx)
+	}
+	return errors.Join(s.Server.Shutdown(ctx), redirectErr)
+}
+
+// RedirectToHTTPSHandler returns a handler permanently redirecting requests
+// to the same URL over HTTPS.
+//
+//   - port: The HTTPS port. The port is omitted from the URL if it is 443.
+func RedirectToHTTPSHandler(port int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		} else {
+			host = strings.Trim(host, "[]")
+		}
+		if port != 443 {
+			host = net.JoinHostPort(host, fmt.Sprint(port))
+		} else if strings.Contains(host, ":") {
+			host = "[" + host + "]"
+		}
+
+		status := http.StatusPermanentRedirect
+		if r.Method == http.MethodGet || r.Method == http.MethodHead {
+			status = http.StatusMovedPermanently
+		}
+		http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), status)
+	})
+}
@@ -0,0 +1,152 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pakkasys/fluidapi/core/logging"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockCertManager struct{}
+
+func (m *mockCertManager) GetCertificate(
+	hello *tls.ClientHelloInfo,
+) (*tls.Certificate, error) {
+	return &tls.Certificate{}, nil
+}
+
+func (m *mockCertManager) HTTPHandler(fallback http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/.well-known/acme-challenge/token" {
+			_, _ = w.Write([]byte("challenge"))
+			return
+		}
+		fallback.ServeHTTP(w, r)
+	})
+}
+
+// TestDefaultHTTPServer_TLS tests creating a server with certificate files.
+func TestDefaultHTTPServer_TLS(t *testing.T) {
+	server := DefaultHTTPServer(
+		8443,
+		nil,
+		logging.NopLogger{},
+		WithTLS(TLSConfig{CertFile: "cert.pem", KeyFile: "key.pem"}),
+	)
+
+	tlsServer, ok := server.(*TLSServer)
+	assert.True(t, ok, "Expected server to be of type *TLSServer")
+	assert.Equal(t, ":8443", tlsServer.Server.Addr)
+	tlsConfig := tlsServer.Server.TLSConfig
+	assert.Equal(t, uint16(tls.VersionTLS12), tlsConfig.MinVersion)
+	assert.Nil(t, tlsServer.RedirectServer)
+
+	err := tlsServer.ListenAndServe()
+	assert.Error(t, err, "Expected an error for missing certificate files")
+	assert.NoError(t, tlsServer.Shutdown(context.Background()))
+}
+
+// TestDefaultHTTPServer_CertManager tests using a certificate manager with a
+// redirect server.
+func TestDefaultHTTPServer_CertManager(t *testing.T) {
+	config := &tls.Config{MinVersion: tls.VersionTLS13}
+	server := DefaultHTTPServer(
+		443,
+		nil,
+		nil,
+		WithTLS(TLSConfig{
+			Config:       config,
+			CertManager:  &mockCertManager{},
+			RedirectAddr: ":8080",
+		}),
+	).(*TLSServer)
+
+	tlsConfig := server.Server.TLSConfig
+	assert.NotSame(t, config, tlsConfig)
+	assert.Equal(t, uint16(tls.VersionTLS13), tlsConfig.MinVersion)
+	assert.NotNil(t, tlsConfig.GetCertificate)
+	assert.Equal(
+		t,
+		[]string{"h2", "http/1.1", "acme-tls/1"},
+		tlsConfig.NextProtos,
+	)
+	assert.Equal(t, ":8080", server.RedirectServer.Addr)
+
+	rr := httptest.NewRecorder()
+	server.RedirectServer.Handler.ServeHTTP(rr, httptest.NewRequest(
+		"GET",
+		"http://example.com/.well-known/acme-challenge/token",
+		nil,
+	))
+	assert.Equal(t, "challenge", rr.Body.String())
+
+	rr = httptest.NewRecorder()
+	server.RedirectServer.Handler.ServeHTTP(
+		rr,
+		httptest.NewRequest("GET", "http://example.com/users?a=1", nil),
+	)
+	assert.Equal(t, http.StatusMovedPermanently, rr.Code)
+	assert.Equal(
+		t,
+		"https://example.com/users?a=1",
+		rr.Header().Get("Location"),
+	)
+}
+
+// TestDefaultHTTPServer_TLSWithoutCertificates tests that TLS without
+// certificates panics.
+func TestDefaultHTTPServer_TLSWithoutCertificates(t *testing.T) {
+	assert.Panics(t, func() {
+		DefaultHTTPServer(443, nil, nil, WithTLS(TLSConfig{
+			Config: &tls.Config{},
+		}))
+	})
+}
+
+// TestNewHTTPServer_TLSWithoutCertificates tests the error of TLS without
+// certificates.
+func TestNewHTTPServer_TLSWithoutCertificates(t *testing.T) {
+	server, err := NewHTTPServer(443, nil, nil, WithTLS(TLSConfig{
+		Config: &tls.Config{},
+	}))
+
+	assert.EqualError(
+		t,
+		err,
+		"TLS requires certificate files, a tls.Config with certificates or "+
+			"a certificate manager",
+	)
+	assert.Nil(t, server)
+}
+
+// TestRedirectToHTTPSHandler tests the redirect URLs and statuses.
+func TestRedirectToHTTPSHandler(t *testing.T) {
+	tests := []struct {
+		port     int
+		method   string
+		host     string
+		location string
+		status   int
+	}{
+		{443, "GET", "example.com:80", "https://example.com/a?b=c", 301},
+		{8443, "HEAD", "example.com", "https://example.com:8443/a?b=c", 301},
+		{443, "POST", "example.com", "https://example.com/a?b=c", 308},
+		{443, "GET", "[::1]:80", "https://[::1]/a?b=c", 301},
+		{8443, "GET", "[::1]", "https://[::1]:8443/a?b=c", 301},
+	}
+
+	for _, test := range tests {
+		r := httptest.NewRequest(test.method, "/a?b=c", nil)
+		r.Host = test.host
+		rr := httptest.NewRecorder()
+
+		RedirectToHTTPSHandler(test.port).ServeHTTP(rr, r)
+
+		assert.Equal(t, test.status, rr.Code)
+		assert.Equal(t, test.location, rr.Header().Get("Location"))
+	}
+}
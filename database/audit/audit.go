@@ -0,0 +1,234 @@
+// Package audit records the creates, updates and deletes made through the
+// entity helpers into an audit table, and the records of the audit middleware
+// into a request audit table.
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/pakkasys/fluidapi/database/entity"
+	"github.com/pakkasys/fluidapi/database/util"
+)
+
+// DefaultTableName is the default name of the audit table.
+const DefaultTableName = "audit_log"
+
+// Operation is the kind of an audited operation.
+type Operation string
+
+const (
+	OperationCreate Operation = "create"
+	OperationUpdate Operation = "update"
+	OperationDelete Operation = "delete"
+)
+
+// ActorResolver derives the actor of the operation from the context, e.g.
+// the ID of the authenticated user.
+type ActorResolver func(ctx context.Context) string
+
+// Entry is a row of the audit table.
+//
+// The audit table can be created with:
+//
+//	CREATE TABLE audit_log (
+//	  id BIGINT AUTO_INCREMENT PRIMARY KEY,
+//	  table_name VARCHAR(255) NOT NULL,
+//	  operation VARCHAR(16) NOT NULL,
+//	  selectors JSON NOT NULL,
+//	  changes JSON NOT NULL,
+//	  actor VARCHAR(255) NOT NULL,
+//	  created_at DATETIME(6) NOT NULL
+//	);
+type Entry struct {
+	ID        int64     `json:"id"`
+	TableName string    `json:"table_name"`
+	Operation Operation `json:"operation"`
+	// Selectors are the JSON encoded selectors of an update or delete
+	Selectors string `json:"selectors"`
+	// Changes are the JSON encoded changed fields and their new values
+	Changes   string    `json:"changes"`
+	Actor     string    `json:"actor"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Auditor records audit entries with the preparer of the audited operation,
+// so the entries are written in the same transaction as the changes.
+type Auditor struct {
+	// TableName is the name of the audit table
+	TableName string
+	// ActorFn is an optional resolver for the actor of the operation
+	ActorFn ActorResolver
+	// SQLUtil is a utility for working with SQL
+	SQLUtil entity.SQLUtil
+	nowFn   func() time.Time
+}
+
+// NewAuditor creates a new auditor writing to the default audit table.
+//
+//   - actorFn: The resolver for the actor of the operation.
+//   - sqlUtil: The SQL utility used to check database errors.
+func NewAuditor(actorFn ActorResolver, sqlUtil entity.SQLUtil) *Auditor {
+	return &Auditor{
+		TableName: DefaultTableName,
+		ActorFn:   actorFn,
+		SQLUtil:   sqlUtil,
+		nowFn:     time.Now,
+	}
+}
+
+// Attach adds hooks recording every create, update and delete made through
+// the entity helpers. The helpers must have an inserter, which is used to
+// record the fields of the created entities.
+//
+//   - auditor: The auditor recording the entries.
+//   - helpers: The entity helpers to audit.
+func Attach[T any](auditor *Auditor, helpers *entity.EntityHelpers[T]) {
+	table := helpers.TableName
+	hooks := &helpers.Hooks
+
+	hooks.AfterCreate = append(
+		hooks.AfterCreate,
+		func(ctx context.Context, preparer util.Preparer, object *T) error {
+			columns, values := helpers.InserterFn(object)
+			changes := make(map[string]any, len(columns))
+			for i, column := range columns {
+				changes[column] = values[i]
+			}
+			return auditor.Record(
+				ctx,
+				preparer,
+				table,
+				OperationCreate,
+				nil,
+				changes,
+			)
+		},
+	)
+	hooks.AfterUpdate = append(
+		hooks.AfterUpdate,
+		func(
+			ctx context.Context,
+			preparer util.Preparer,
+			selectors []util.Selector,
+			updates entity.Updates,
+		) error {
+			changes := make(map[string]any, len(updates))
+			for _, update := range updates {
+				changes[update.Field] = update.Value
+			}
+			return auditor.Record(
+				ctx,
+				preparer,
+				table,
+				OperationUpdate,
+				selectors,
+				changes,
+			)
+		},
+	)
+	hooks.AfterDelete = append(
+		hooks.AfterDelete,
+		func(
+			ctx context.Context,
+			preparer util.Preparer,
+			selectors []util.Selector,
+		) error {
+			return auditor.Record(
+				ctx,
+				preparer,
+				table,
+				OperationDelete,
+				selectors,
+				nil,
+			)
+		},
+	)
+}
+
+// Record writes an audit entry.
+//
+//   - ctx: The context of the operation.
+//   - preparer: The preparer of the audited operation.
+//   - table: The audited table.
+//   - operation: The audited operation.
+//   - selectors: The selectors of the operation.
+//   - changes: The changed fields and their new values.
+func (a *Auditor) Record(
+	ctx context.Context,
+	preparer util.Preparer,
+	table string,
+	operation Operation,
+	selectors []util.Selector,
+	changes map[string]any,
+) error {
+	if selectors == nil {
+		selectors = []util.Selector{}
+	}
+	if changes == nil {
+		changes = map[string]any{}
+	}
+	selectorsJSON, err := json.Marshal(selectors)
+	if err != nil {
+		return err
+	}
+	changesJSON, err := json.Marshal(changes)
+	if err != nil {
+		return err
+	}
+
+	actor := ""
+	if a.ActorFn != nil {
+		actor = a.ActorFn(ctx)
+	}
+
+	entry := &Entry{
+		TableName: table,
+		Operation: operation,
+		Selectors: string(selectorsJSON),
+		Changes:   string(changesJSON),
+		Actor:     actor,
+		CreatedAt: a.now().UTC(),
+	}
+	_, err = entity.CreateEntity(
+		entry,
+		preparer,
+		a.tableName(),
+		insertEntry,
+		a.SQLUtil,
+	)
+	return err
+}
+
+func (a *Auditor) tableName() string {
+	if a.TableName == "" {
+		return DefaultTableName
+	}
+	return a.TableName
+}
+
+func (a *Auditor) now() time.Time {
+	if a.nowFn == nil {
+		return time.Now()
+	}
+	return a.nowFn()
+}
+
+func insertEntry(entry *Entry) ([]string, []any) {
+	return []string{
+		"table_name",
+		"operation",
+		"selectors",
+		"changes",
+		"actor",
+		"created_at",
+	}, []any{
+		entry.TableName,
+		entry.Operation,
+		entry.Selectors,
+		entry.Changes,
+		entry.Actor,
+		entry.CreatedAt,
+	}
+}
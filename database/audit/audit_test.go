@@ -0,0 +1,176 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/pakkasys/fluidapi/database/entity"
+	entitymock "github.com/pakkasys/fluidapi/database/entity/mock"
+	"github.com/pakkasys/fluidapi/database/util"
+	utilmock "github.com/pakkasys/fluidapi/database/util/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const auditInsertQuery = "INSERT INTO `audit_log` (`table_name`, " +
+	"`operation`, `selectors`, `changes`, `actor`, `created_at`) " +
+	"VALUES (?, ?, ?, ?, ?, ?)"
+
+type testUser struct {
+	ID   int
+	Name string
+}
+
+var testTime = time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+func testAuditor() (*Auditor, *entitymock.MockSQLUtil) {
+	mockSQLUtil := new(entitymock.MockSQLUtil)
+	mockSQLUtil.On("CheckDBError", mock.Anything).Return(nil)
+
+	auditor := NewAuditor(
+		func(ctx context.Context) string { return "alice" },
+		mockSQLUtil,
+	)
+	auditor.nowFn = func() time.Time { return testTime }
+	return auditor, mockSQLUtil
+}
+
+// expectExec expects a single statement execution with the given query and
+// parameters.
+func expectExec(preparer *utilmock.MockDB, query string, params []any) {
+	mockStmt := new(utilmock.MockStmt)
+	mockResult := new(utilmock.MockResult)
+	preparer.On("Prepare", query).Return(mockStmt, nil).Once()
+	mockStmt.On("Exec", params).Return(mockResult, nil)
+	mockStmt.On("Close").Return(nil)
+	mockResult.On("LastInsertId").Return(int64(1), nil)
+	mockResult.On("RowsAffected").Return(int64(1), nil)
+}
+
+func testUserHelpers(sqlUtil entity.SQLUtil) *entity.EntityHelpers[testUser] {
+	return &entity.EntityHelpers[testUser]{
+		TableName: "users",
+		InserterFn: func(user *testUser) ([]string, []any) {
+			return []string{"name"}, []any{user.Name}
+		},
+		SQLUtil: sqlUtil,
+	}
+}
+
+// TestRecord tests that an audit entry is inserted into the audit table.
+func TestRecord(t *testing.T) {
+	auditor, _ := testAuditor()
+	mockPreparer := new(utilmock.MockDB)
+	expectExec(mockPreparer, auditInsertQuery, []any{
+		"users",
+		OperationUpdate,
+		`[{"Table":"users","Field":"id","Predicate":"=","Value":1}]`,
+		`{"name":"bob"}`,
+		"alice",
+		testTime,
+	})
+
+	err := auditor.Record(
+		context.Background(),
+		mockPreparer,
+		"users",
+		OperationUpdate,
+		[]util.Selector{
+			{Table: "users", Field: "id", Predicate: util.EQUAL, Value: 1},
+		},
+		map[string]any{"name": "bob"},
+	)
+
+	assert.NoError(t, err)
+	mockPreparer.AssertExpectations(t)
+}
+
+// TestRecord_Error tests that an insert error is returned.
+func TestRecord_Error(t *testing.T) {
+	mockSQLUtil := new(entitymock.MockSQLUtil)
+	mockSQLUtil.On("CheckDBError", mock.Anything).
+		Return(errors.New("db error"))
+	auditor := NewAuditor(nil, mockSQLUtil)
+	mockPreparer := new(utilmock.MockDB)
+	mockPreparer.On("Prepare", auditInsertQuery).
+		Return(nil, errors.New("prepare error"))
+
+	err := auditor.Record(
+		context.Background(),
+		mockPreparer,
+		"users",
+		OperationDelete,
+		nil,
+		nil,
+	)
+
+	assert.EqualError(t, err, "db error")
+}
+
+// TestAttach_Create tests that creating an entity records its fields.
+func TestAttach_Create(t *testing.T) {
+	auditor, sqlUtil := testAuditor()
+	helpers := testUserHelpers(sqlUtil)
+	Attach(auditor, helpers)
+
+	mockPreparer := new(utilmock.MockDB)
+	expectExec(
+		mockPreparer,
+		"INSERT INTO `users` (`name`) VALUES (?)",
+		[]any{"bob"},
+	)
+	expectExec(mockPreparer, auditInsertQuery, []any{
+		"users",
+		OperationCreate,
+		"[]",
+		`{"name":"bob"}`,
+		"alice",
+		testTime,
+	})
+
+	_, err := helpers.CreateEntity(
+		context.Background(),
+		mockPreparer,
+		&testUser{Name: "bob"},
+		nil,
+	)
+
+	assert.NoError(t, err)
+	mockPreparer.AssertExpectations(t)
+}
+
+// TestAttach_Delete tests that deleting entities records the selectors.
+func TestAttach_Delete(t *testing.T) {
+	auditor, sqlUtil := testAuditor()
+	helpers := testUserHelpers(sqlUtil)
+	Attach(auditor, helpers)
+
+	mockPreparer := new(utilmock.MockDB)
+	expectExec(
+		mockPreparer,
+		"DELETE FROM `users` WHERE `users`.`id` = ?",
+		[]any{1},
+	)
+	expectExec(mockPreparer, auditInsertQuery, []any{
+		"users",
+		OperationDelete,
+		`[{"Table":"users","Field":"id","Predicate":"=","Value":1}]`,
+		"{}",
+		"alice",
+		testTime,
+	})
+
+	_, err := helpers.DeleteEntities(
+		context.Background(),
+		mockPreparer,
+		[]util.Selector{
+			{Table: "users", Field: "id", Predicate: util.EQUAL, Value: 1},
+		},
+		nil,
+	)
+
+	assert.NoError(t, err)
+	mockPreparer.AssertExpectations(t)
+}
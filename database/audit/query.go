@@ -0,0 +1,89 @@
+package audit
+
+import (
+	"context"
+
+	"github.com/pakkasys/fluidapi/database/entity"
+	"github.com/pakkasys/fluidapi/database/util"
+	"github.com/pakkasys/fluidapi/endpoint/page"
+)
+
+// EntityHelpers returns entity helpers for the audit table.
+//
+//   - getTxFn: The function returning a transaction.
+func (a *Auditor) EntityHelpers(
+	getTxFn func(ctx context.Context) (util.Tx, error),
+) *entity.EntityHelpers[Entry] {
+	return &entity.EntityHelpers[Entry]{
+		TableName:  a.tableName(),
+		GetTxFn:    getTxFn,
+		InserterFn: insertEntry,
+		ScanRowFn: func(row util.Row, entry *Entry) error {
+			return row.Scan(entryFields(entry)...)
+		},
+		ScanRowsFn: func(rows util.Rows, entry *Entry) error {
+			return rows.Scan(entryFields(entry)...)
+		},
+		SQLUtil: a.SQLUtil,
+	}
+}
+
+// GetEntries returns the audit entries of a table, newest first.
+//
+//   - ctx: The context of the operation.
+//   - preparer: The preparer used to prepare the query.
+//   - table: The audited table, or empty for all tables.
+//   - selectors: Additional selectors for the entries, e.g. by actor.
+//   - page: The page of the entries, or nil for all entries.
+func (a *Auditor) GetEntries(
+	ctx context.Context,
+	preparer util.Preparer,
+	table string,
+	selectors []util.Selector,
+	page *page.Page,
+) ([]Entry, error) {
+	tableName := a.tableName()
+	if table != "" {
+		selectors = append(
+			[]util.Selector{
+				{
+					Table:     tableName,
+					Field:     "table_name",
+					Predicate: util.EQUAL,
+					Value:     table,
+				},
+			},
+			selectors...,
+		)
+	}
+
+	return a.EntityHelpers(nil).GetEntities(
+		ctx,
+		preparer,
+		entity.GetOptions{
+			Options: entity.Options{
+				Selectors: selectors,
+				Orders: []util.Order{
+					{
+						Table:     tableName,
+						Field:     "id",
+						Direction: util.OrderDesc,
+					},
+				},
+				Page: page,
+			},
+		},
+	)
+}
+
+func entryFields(entry *Entry) []any {
+	return []any{
+		&entry.ID,
+		&entry.TableName,
+		&entry.Operation,
+		&entry.Selectors,
+		&entry.Changes,
+		&entry.Actor,
+		&entry.CreatedAt,
+	}
+}
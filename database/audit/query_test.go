@@ -0,0 +1,62 @@
+package audit
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pakkasys/fluidapi/database/util"
+	utilmock "github.com/pakkasys/fluidapi/database/util/mock"
+	"github.com/pakkasys/fluidapi/endpoint/page"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// TestGetEntries tests that the entries of a table are queried newest first.
+func TestGetEntries(t *testing.T) {
+	auditor, _ := testAuditor()
+	mockPreparer := new(utilmock.MockDB)
+	mockStmt := new(utilmock.MockStmt)
+	mockRows := new(utilmock.MockRows)
+
+	mockPreparer.On(
+		"Prepare",
+		"SELECT * FROM `audit_log` WHERE `audit_log`.`table_name` = ? "+
+			"AND `audit_log`.`actor` = ? "+
+			"ORDER BY `audit_log`.`id` DESC LIMIT 10 OFFSET 0",
+	).Return(mockStmt, nil)
+	mockStmt.On("Query", []any{"users", "alice"}).Return(mockRows, nil)
+	mockStmt.On("Close").Return(nil)
+	mockRows.On("Next").Return(true).Once()
+	mockRows.On("Next").Return(false).Once()
+	mockRows.On("Scan", mock.Anything).Run(func(args mock.Arguments) {
+		dest := args.Get(0).([]any)
+		*dest[0].(*int64) = 7
+		*dest[1].(*string) = "users"
+		*dest[2].(*Operation) = OperationCreate
+	}).Return(nil)
+	mockRows.On("Err").Return(nil)
+	mockRows.On("Close").Return(nil)
+
+	entries, err := auditor.GetEntries(
+		context.Background(),
+		mockPreparer,
+		"users",
+		[]util.Selector{
+			{
+				Table:     "audit_log",
+				Field:     "actor",
+				Predicate: util.EQUAL,
+				Value:     "alice",
+			},
+		},
+		&page.Page{Offset: 0, Limit: 10},
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		[]Entry{{ID: 7, TableName: "users", Operation: OperationCreate}},
+		entries,
+	)
+	mockPreparer.AssertExpectations(t)
+}
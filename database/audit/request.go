@@ -0,0 +1,167 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/pakkasys/fluidapi/database/entity"
+	"github.com/pakkasys/fluidapi/database/util"
+	"github.com/pakkasys/fluidapi/endpoint/middleware"
+)
+
+// DefaultRequestTableName is the default name of the request audit table.
+const DefaultRequestTableName = "request_audit_log"
+
+// RequestEntry is a row of the request audit table.
+//
+// The request audit table can be created with:
+//
+//	CREATE TABLE request_audit_log (
+//	  id BIGINT AUTO_INCREMENT PRIMARY KEY,
+//	  actor VARCHAR(255) NOT NULL,
+//	  method VARCHAR(16) NOT NULL,
+//	  endpoint VARCHAR(255) NOT NULL,
+//	  path VARCHAR(2048) NOT NULL,
+//	  request_id VARCHAR(255) NOT NULL,
+//	  selectors JSON NOT NULL,
+//	  updates JSON NOT NULL,
+//	  status INT NOT NULL,
+//	  outcome VARCHAR(16) NOT NULL,
+//	  duration_ms BIGINT NOT NULL,
+//	  created_at DATETIME(6) NOT NULL
+//	);
+type RequestEntry struct {
+	ID        int64  `json:"id"`
+	Actor     string `json:"actor"`
+	Method    string `json:"method"`
+	Endpoint  string `json:"endpoint"`
+	Path      string `json:"path"`
+	RequestID string `json:"request_id"`
+	// Selectors are the JSON encoded selectors of the request
+	Selectors string `json:"selectors"`
+	// Updates are the JSON encoded updates of the request
+	Updates    string    `json:"updates"`
+	Status     int       `json:"status"`
+	Outcome    string    `json:"outcome"`
+	DurationMS int64     `json:"duration_ms"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// RequestSink stores the records of the audit middleware in the request
+// audit table. It implements middleware.AuditSink.
+type RequestSink struct {
+	// TableName is the name of the request audit table
+	TableName string
+	// Preparer prepares the insert statements, e.g. the database connection
+	Preparer util.Preparer
+	// SQLUtil is a utility for working with SQL
+	SQLUtil entity.SQLUtil
+}
+
+// NewRequestSink creates a new request sink writing to the default request
+// audit table.
+//
+//   - preparer: The preparer of the insert statements.
+//   - sqlUtil: The SQL utility used to check database errors.
+func NewRequestSink(
+	preparer util.Preparer,
+	sqlUtil entity.SQLUtil,
+) *RequestSink {
+	if preparer == nil {
+		panic("preparer cannot be nil")
+	}
+	return &RequestSink{
+		TableName: DefaultRequestTableName,
+		Preparer:  preparer,
+		SQLUtil:   sqlUtil,
+	}
+}
+
+// RecordAudit inserts the audit record into the request audit table.
+//
+//   - ctx: The context of the request.
+//   - record: The audit record.
+func (s *RequestSink) RecordAudit(
+	ctx context.Context,
+	record *middleware.AuditRecord,
+) error {
+	selectors, err := marshalDetails(record.Selectors)
+	if err != nil {
+		return err
+	}
+	updates, err := marshalDetails(record.Updates)
+	if err != nil {
+		return err
+	}
+
+	entry := &RequestEntry{
+		Actor:      record.Actor,
+		Method:     record.Method,
+		Endpoint:   record.Endpoint,
+		Path:       record.Path,
+		RequestID:  record.RequestID,
+		Selectors:  selectors,
+		Updates:    updates,
+		Status:     record.Status,
+		Outcome:    string(record.Outcome),
+		DurationMS: record.Duration.Milliseconds(),
+		CreatedAt:  record.Time.UTC(),
+	}
+	_, err = entity.CreateEntity(
+		entry,
+		s.Preparer,
+		s.tableName(),
+		insertRequestEntry,
+		s.SQLUtil,
+	)
+	return err
+}
+
+func (s *RequestSink) tableName() string {
+	if s.TableName == "" {
+		return DefaultRequestTableName
+	}
+	return s.TableName
+}
+
+// marshalDetails encodes the selectors or updates of a record in JSON. Missing
+// details are encoded as an empty array.
+func marshalDetails(details any) (string, error) {
+	if details == nil {
+		return "[]", nil
+	}
+	data, err := json.Marshal(details)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func insertRequestEntry(entry *RequestEntry) ([]string, []any) {
+	return []string{
+		"actor",
+		"method",
+		"endpoint",
+		"path",
+		"request_id",
+		"selectors",
+		"updates",
+		"status",
+		"outcome",
+		"duration_ms",
+		"created_at",
+	}, []any{
+		entry.Actor,
+		entry.Method,
+		entry.Endpoint,
+		entry.Path,
+		entry.RequestID,
+		entry.Selectors,
+		entry.Updates,
+		entry.Status,
+		entry.Outcome,
+		entry.DurationMS,
+		entry.CreatedAt,
+	}
+}
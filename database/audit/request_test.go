@@ -0,0 +1,93 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	entitymock "github.com/pakkasys/fluidapi/database/entity/mock"
+	"github.com/pakkasys/fluidapi/database/util"
+	utilmock "github.com/pakkasys/fluidapi/database/util/mock"
+	"github.com/pakkasys/fluidapi/endpoint/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const requestInsertQuery = "INSERT INTO `request_audit_log` (`actor`, " +
+	"`method`, `endpoint`, `path`, `request_id`, `selectors`, `updates`, " +
+	"`status`, `outcome`, `duration_ms`, `created_at`) " +
+	"VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)"
+
+// TestRequestSink_RecordAudit tests that a record of the audit middleware is
+// inserted into the request audit table.
+func TestRequestSink_RecordAudit(t *testing.T) {
+	mockSQLUtil := new(entitymock.MockSQLUtil)
+	mockSQLUtil.On("CheckDBError", mock.Anything).Return(nil)
+	mockPreparer := new(utilmock.MockDB)
+	expectExec(mockPreparer, requestInsertQuery, []any{
+		"alice",
+		http.MethodPut,
+		"/users/{id}",
+		"/users/1",
+		"id",
+		`[{"Table":"users","Field":"id","Predicate":"=","Value":1}]`,
+		"[]",
+		http.StatusOK,
+		"success",
+		int64(1500),
+		testTime,
+	})
+
+	err := NewRequestSink(mockPreparer, mockSQLUtil).RecordAudit(
+		context.Background(),
+		&middleware.AuditRecord{
+			Time:      testTime,
+			Actor:     "alice",
+			Method:    http.MethodPut,
+			Endpoint:  "/users/{id}",
+			Path:      "/users/1",
+			RequestID: "id",
+			Selectors: []util.Selector{
+				{Table: "users", Field: "id", Predicate: "=", Value: 1},
+			},
+			Status:   http.StatusOK,
+			Outcome:  middleware.AuditOutcomeSuccess,
+			Duration: 1500 * time.Millisecond,
+		},
+	)
+
+	assert.NoError(t, err)
+	mockPreparer.AssertExpectations(t)
+}
+
+// TestRequestSink_RecordAudit_Error tests the errors of encoding the details
+// and of the insert.
+func TestRequestSink_RecordAudit_Error(t *testing.T) {
+	sink := NewRequestSink(new(utilmock.MockDB), nil)
+
+	err := sink.RecordAudit(
+		context.Background(),
+		&middleware.AuditRecord{Updates: func() {}},
+	)
+	assert.Error(t, err)
+
+	prepareErr := errors.New("prepare error")
+	mockSQLUtil := new(entitymock.MockSQLUtil)
+	mockSQLUtil.On("CheckDBError", prepareErr).Return(prepareErr)
+	mockPreparer := new(utilmock.MockDB)
+	mockPreparer.On("Prepare", mock.Anything).Return(nil, prepareErr)
+	sink = NewRequestSink(mockPreparer, mockSQLUtil)
+	sink.TableName = "custom"
+
+	err = sink.RecordAudit(context.Background(), &middleware.AuditRecord{})
+	assert.Equal(t, prepareErr, err)
+}
+
+// TestNewRequestSink_NilPreparer tests that a nil preparer panics.
+func TestNewRequestSink_NilPreparer(t *testing.T) {
+	assert.PanicsWithValue(t, "preparer cannot be nil", func() {
+		NewRequestSink(nil, nil)
+	})
+}
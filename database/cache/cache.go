@@ -0,0 +1,33 @@
+// Package cache provides caches for database query results. Cached values are
+// grouped by table so that all values of a table can be invalidated when the
+// table is modified.
+//
+// Each invalidation starts a new generation of the table. The generation is
+// read before a value is computed and passed to Set, so that a value computed
+// from rows read before an invalidation is never returned after it.
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// Cache is a cache for query results grouped by table.
+type Cache interface {
+	// Get returns the cached value of the key, and false if it is not cached.
+	Get(ctx context.Context, table string, key string) ([]byte, bool, error)
+	// Generation returns the current generation of the table.
+	Generation(ctx context.Context, table string) (int64, error)
+	// Set caches the value of the key for the given duration. The value is
+	// not returned if the table has been invalidated since the generation.
+	Set(
+		ctx context.Context,
+		table string,
+		generation int64,
+		key string,
+		value []byte,
+		ttl time.Duration,
+	) error
+	// Invalidate removes all cached values of the table.
+	Invalidate(ctx context.Context, table string) error
+}
@@ -0,0 +1,154 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// sweepInterval is the minimum interval between the removals of all expired
+// values of a MemoryCache.
+const sweepInterval = time.Minute
+
+type memoryEntry struct {
+	value     []byte
+	expiresAt time.Time
+}
+
+func (e memoryEntry) expired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && !now.Before(e.expiresAt)
+}
+
+// MemoryCache is an in-memory cache. It is safe for concurrent use. Expired
+// values are removed when they are read, and all expired values are removed
+// at most once a minute when values are set.
+type MemoryCache struct {
+	mu          sync.RWMutex
+	tables      map[string]map[string]memoryEntry
+	generations map[string]int64
+	lastSweep   time.Time
+	nowFn       func() time.Time
+}
+
+// NewMemoryCache creates a new in-memory cache.
+func NewMemoryCache() *MemoryCache {
+	return &MemoryCache{
+		tables:      map[string]map[string]memoryEntry{},
+		generations: map[string]int64{},
+		nowFn:       time.Now,
+	}
+}
+
+// Get returns the cached value of the key, and false if it is not cached or
+// has expired. An expired value is removed.
+//
+//   - ctx: The context of the operation.
+//   - table: The table of the value.
+//   - key: The key of the value.
+func (c *MemoryCache) Get(
+	ctx context.Context,
+	table string,
+	key string,
+) ([]byte, bool, error) {
+	c.mu.RLock()
+	entry, ok := c.tables[table][key]
+	c.mu.RUnlock()
+	if !ok {
+		return nil, false, nil
+	}
+	if entry.expired(c.nowFn()) {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if entry, ok := c.tables[table][key]; ok && entry.expired(c.nowFn()) {
+			delete(c.tables[table], key)
+		}
+		return nil, false, nil
+	}
+	return entry.value, true, nil
+}
+
+// Generation returns the current generation of the table.
+//
+//   - ctx: The context of the operation.
+//   - table: The table.
+func (c *MemoryCache) Generation(
+	ctx context.Context,
+	table string,
+) (int64, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.generations[table], nil
+}
+
+// Set caches the value of the key. A zero TTL caches the value until the
+// table is invalidated. The value is not cached if the table has been
+// invalidated since the generation.
+//
+//   - ctx: The context of the operation.
+//   - table: The table of the value.
+//   - generation: The generation of the table before the value was computed.
+//   - key: The key of the value.
+//   - value: The value to cache.
+//   - ttl: How long the value is cached.
+func (c *MemoryCache) Set(
+	ctx context.Context,
+	table string,
+	generation int64,
+	key string,
+	value []byte,
+	ttl time.Duration,
+) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.nowFn()
+	if now.Sub(c.lastSweep) >= sweepInterval {
+		c.sweep(now)
+	}
+	if generation != c.generations[table] {
+		return nil
+	}
+
+	entries, ok := c.tables[table]
+	if !ok {
+		entries = map[string]memoryEntry{}
+		c.tables[table] = entries
+	}
+
+	entry := memoryEntry{value: value}
+	if ttl > 0 {
+		entry.expiresAt = now.Add(ttl)
+	}
+	entries[key] = entry
+	return nil
+}
+
+// Invalidate removes all cached values of the table and starts a new
+// generation of the table.
+//
+//   - ctx: The context of the operation.
+//   - table: The table to invalidate.
+func (c *MemoryCache) Invalidate(ctx context.Context, table string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.tables, table)
+	c.generations[table]++
+	return nil
+}
+
+// sweep removes all expired values. The caller must hold the write lock.
+func (c *MemoryCache) sweep(now time.Time) {
+	c.lastSweep = now
+	for table, entries := range c.tables {
+		for key, entry := range entries {
+			if entry.expired(now) {
+				delete(entries, key)
+			}
+		}
+		if len(entries) == 0 {
+			delete(c.tables, table)
+		}
+	}
+}
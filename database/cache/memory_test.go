@@ -0,0 +1,104 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestMemoryCache_SetGet tests caching a value.
+func TestMemoryCache_SetGet(t *testing.T) {
+	cache := NewMemoryCache()
+	ctx := context.Background()
+
+	assert.NoError(t, cache.Set(ctx, "users", 0, "key", []byte("value"), 0))
+	value, ok, err := cache.Get(ctx, "users", "key")
+
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []byte("value"), value)
+}
+
+// TestMemoryCache_Miss tests getting a value which is not cached.
+func TestMemoryCache_Miss(t *testing.T) {
+	cache := NewMemoryCache()
+
+	value, ok, err := cache.Get(context.Background(), "users", "key")
+
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	assert.Nil(t, value)
+}
+
+// TestMemoryCache_Expired tests that expired values are not returned.
+func TestMemoryCache_Expired(t *testing.T) {
+	now := time.Unix(1000, 0)
+	cache := NewMemoryCache()
+	cache.nowFn = func() time.Time { return now }
+	ctx := context.Background()
+
+	_ = cache.Set(ctx, "users", 0, "key", []byte("value"), time.Minute)
+	now = now.Add(time.Minute)
+	_, ok, _ := cache.Get(ctx, "users", "key")
+
+	assert.False(t, ok)
+}
+
+// TestMemoryCache_Invalidate tests that invalidating a table removes only
+// the values of the table.
+func TestMemoryCache_Invalidate(t *testing.T) {
+	cache := NewMemoryCache()
+	ctx := context.Background()
+
+	_ = cache.Set(ctx, "users", 0, "key", []byte("user"), 0)
+	_ = cache.Set(ctx, "orders", 0, "key", []byte("order"), 0)
+	assert.NoError(t, cache.Invalidate(ctx, "users"))
+
+	_, ok, _ := cache.Get(ctx, "users", "key")
+	assert.False(t, ok)
+	_, ok, _ = cache.Get(ctx, "orders", "key")
+	assert.True(t, ok)
+}
+
+// TestMemoryCache_ExpiredRemoved tests that expired values are removed when
+// read and swept when values are set.
+func TestMemoryCache_ExpiredRemoved(t *testing.T) {
+	now := time.Unix(1000, 0)
+	cache := NewMemoryCache()
+	cache.nowFn = func() time.Time { return now }
+	ctx := context.Background()
+
+	_ = cache.Set(ctx, "users", 0, "read", []byte("value"), time.Second)
+	_ = cache.Set(ctx, "orders", 0, "unread", []byte("value"), time.Second)
+	now = now.Add(time.Second)
+
+	_, ok, _ := cache.Get(ctx, "users", "read")
+	assert.False(t, ok)
+	assert.NotContains(t, cache.tables["users"], "read")
+	assert.Contains(t, cache.tables["orders"], "unread")
+
+	now = now.Add(sweepInterval)
+	_ = cache.Set(ctx, "users", 0, "key", []byte("value"), 0)
+	assert.NotContains(t, cache.tables, "orders")
+	assert.Contains(t, cache.tables["users"], "key")
+}
+
+// TestMemoryCache_StaleGeneration tests that a value computed before an
+// invalidation of its table is not cached.
+func TestMemoryCache_StaleGeneration(t *testing.T) {
+	cache := NewMemoryCache()
+	ctx := context.Background()
+
+	generation, err := cache.Generation(ctx, "users")
+	assert.NoError(t, err)
+	assert.NoError(t, cache.Invalidate(ctx, "users"))
+	err = cache.Set(ctx, "users", generation, "key", []byte("stale"), 0)
+	assert.NoError(t, err)
+
+	_, ok, _ := cache.Get(ctx, "users", "key")
+	assert.False(t, ok)
+	generation, _ = cache.Generation(ctx, "users")
+	assert.Equal(t, int64(1), generation)
+}
@@ -0,0 +1,139 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// RedisClient is the subset of Redis commands used by RedisCache. It can be
+// implemented with a thin adapter over any Redis client library.
+type RedisClient interface {
+	// Get returns the value of the key, and false if the key does not exist.
+	Get(ctx context.Context, key string) (string, bool, error)
+	// Set sets the value of the key with an expiration. A zero expiration
+	// means the key does not expire.
+	Set(
+		ctx context.Context,
+		key string,
+		value string,
+		expiration time.Duration,
+	) error
+	// Incr increments the integer value of the key by one.
+	Incr(ctx context.Context, key string) (int64, error)
+}
+
+// RedisCache is a cache stored in Redis. Tables are invalidated by
+// incrementing a per-table generation number which is part of every key, so
+// the invalidated values are never read again and expire on their own.
+type RedisCache struct {
+	client RedisClient
+	prefix string
+}
+
+// NewRedisCache creates a new Redis cache.
+//
+//   - client: The Redis client.
+//   - prefix: The prefix of the Redis keys.
+func NewRedisCache(client RedisClient, prefix string) *RedisCache {
+	return &RedisCache{
+		client: client,
+		prefix: prefix,
+	}
+}
+
+// Get returns the cached value of the key, and false if it is not cached.
+//
+//   - ctx: The context of the operation.
+//   - table: The table of the value.
+//   - key: The key of the value.
+func (c *RedisCache) Get(
+	ctx context.Context,
+	table string,
+	key string,
+) ([]byte, bool, error) {
+	redisKey, err := c.key(ctx, table, key)
+	if err != nil {
+		return nil, false, err
+	}
+	value, ok, err := c.client.Get(ctx, redisKey)
+	if err != nil || !ok {
+		return nil, false, err
+	}
+	return []byte(value), true, nil
+}
+
+// Generation returns the current generation of the table.
+//
+//   - ctx: The context of the operation.
+//   - table: The table.
+func (c *RedisCache) Generation(
+	ctx context.Context,
+	table string,
+) (int64, error) {
+	generation, ok, err := c.client.Get(ctx, c.generationKey(table))
+	if err != nil || !ok {
+		return 0, err
+	}
+	return strconv.ParseInt(generation, 10, 64)
+}
+
+// Set caches the value of the key under the generation. If the table has
+// been invalidated since the generation, the value is never read and expires
+// on its own.
+//
+//   - ctx: The context of the operation.
+//   - table: The table of the value.
+//   - generation: The generation of the table before the value was computed.
+//   - key: The key of the value.
+//   - value: The value to cache.
+//   - ttl: How long the value is cached.
+func (c *RedisCache) Set(
+	ctx context.Context,
+	table string,
+	generation int64,
+	key string,
+	value []byte,
+	ttl time.Duration,
+) error {
+	return c.client.Set(
+		ctx,
+		c.generationDataKey(table, generation, key),
+		string(value),
+		ttl,
+	)
+}
+
+// Invalidate invalidates all cached values of the table.
+//
+//   - ctx: The context of the operation.
+//   - table: The table to invalidate.
+func (c *RedisCache) Invalidate(ctx context.Context, table string) error {
+	_, err := c.client.Incr(ctx, c.generationKey(table))
+	return err
+}
+
+func (c *RedisCache) key(
+	ctx context.Context,
+	table string,
+	key string,
+) (string, error) {
+	generation, err := c.Generation(ctx, table)
+	if err != nil {
+		return "", err
+	}
+	return c.generationDataKey(table, generation, key), nil
+}
+
+func (c *RedisCache) generationDataKey(
+	table string,
+	generation int64,
+	key string,
+) string {
+	return fmt.Sprintf("%s%s:%d:%s", c.prefix, table, generation, key)
+}
+
+func (c *RedisCache) generationKey(table string) string {
+	return fmt.Sprintf("%s%s:generation", c.prefix, table)
+}
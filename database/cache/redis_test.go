@@ -0,0 +1,116 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeRedisClient struct {
+	values map[string]string
+	ttls   map[string]time.Duration
+	err    error
+}
+
+func newFakeRedisClient() *fakeRedisClient {
+	return &fakeRedisClient{
+		values: map[string]string{},
+		ttls:   map[string]time.Duration{},
+	}
+}
+
+func (c *fakeRedisClient) Get(
+	ctx context.Context,
+	key string,
+) (string, bool, error) {
+	if c.err != nil {
+		return "", false, c.err
+	}
+	value, ok := c.values[key]
+	return value, ok, nil
+}
+
+func (c *fakeRedisClient) Set(
+	ctx context.Context,
+	key string,
+	value string,
+	expiration time.Duration,
+) error {
+	c.values[key] = value
+	c.ttls[key] = expiration
+	return nil
+}
+
+func (c *fakeRedisClient) Incr(ctx context.Context, key string) (int64, error) {
+	value, _ := strconv.ParseInt(c.values[key], 10, 64)
+	value++
+	c.values[key] = strconv.FormatInt(value, 10)
+	return value, nil
+}
+
+// TestRedisCache_SetGet tests caching a value in Redis.
+func TestRedisCache_SetGet(t *testing.T) {
+	client := newFakeRedisClient()
+	cache := NewRedisCache(client, "app:")
+	ctx := context.Background()
+
+	err := cache.Set(ctx, "users", 0, "key", []byte("value"), time.Minute)
+	assert.NoError(t, err)
+	value, ok, err := cache.Get(ctx, "users", "key")
+
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []byte("value"), value)
+	assert.Equal(t, time.Minute, client.ttls["app:users:0:key"])
+}
+
+// TestRedisCache_Invalidate tests that invalidating a table hides the cached
+// values of the table.
+func TestRedisCache_Invalidate(t *testing.T) {
+	client := newFakeRedisClient()
+	cache := NewRedisCache(client, "app:")
+	ctx := context.Background()
+
+	_ = cache.Set(ctx, "users", 0, "key", []byte("value"), 0)
+	assert.NoError(t, cache.Invalidate(ctx, "users"))
+	_, ok, err := cache.Get(ctx, "users", "key")
+
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	assert.Equal(t, "1", client.values["app:users:generation"])
+}
+
+// TestRedisCache_Error tests that client errors are returned.
+func TestRedisCache_Error(t *testing.T) {
+	client := newFakeRedisClient()
+	client.err = errors.New("connection refused")
+	cache := NewRedisCache(client, "app:")
+
+	_, ok, err := cache.Get(context.Background(), "users", "key")
+
+	assert.EqualError(t, err, "connection refused")
+	assert.False(t, ok)
+}
+
+// TestRedisCache_StaleGeneration tests that a value computed before an
+// invalidation of its table is never read.
+func TestRedisCache_StaleGeneration(t *testing.T) {
+	client := newFakeRedisClient()
+	cache := NewRedisCache(client, "app:")
+	ctx := context.Background()
+
+	generation, err := cache.Generation(ctx, "users")
+	assert.NoError(t, err)
+	assert.NoError(t, cache.Invalidate(ctx, "users"))
+	err = cache.Set(ctx, "users", generation, "key", []byte("stale"), 0)
+	assert.NoError(t, err)
+
+	_, ok, err := cache.Get(ctx, "users", "key")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	assert.Contains(t, client.values, "app:users:0:key")
+}
@@ -49,6 +49,29 @@ func (m *MockDB) Query(query string, args ...any) (util.Rows, error) {
 	return nil, calledArgs.Get(1).(error)
 }
 
+func (m *MockDB) PrepareContext(
+	ctx context.Context,
+	query string,
+) (util.Stmt, error) {
+	return m.Prepare(query)
+}
+
+func (m *MockDB) ExecContext(
+	ctx context.Context,
+	query string,
+	args ...any,
+) (util.Result, error) {
+	return m.Exec(query, args...)
+}
+
+func (m *MockDB) QueryContext(
+	ctx context.Context,
+	query string,
+	args ...any,
+) (util.Rows, error) {
+	return m.Query(query, args...)
+}
+
 func (m *MockDB) Close() error {
 	args := m.Called()
 	return args.Error(0)
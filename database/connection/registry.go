@@ -0,0 +1,64 @@
+package connection
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/pakkasys/fluidapi/database/util"
+)
+
+// Registry holds named database connections, e.g. a primary and a replica.
+// It is safe for concurrent use.
+type Registry struct {
+	mu  sync.RWMutex
+	dbs map[string]util.DB
+}
+
+// NewRegistry creates a new database registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		dbs: map[string]util.DB{},
+	}
+}
+
+// Register registers a database connection with a name. A connection
+// already registered with the name is replaced.
+//
+//   - name: The name of the connection.
+//   - db: The database connection.
+func (r *Registry) Register(name string, db util.DB) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.dbs[name] = db
+}
+
+// Get returns the database connection with the name.
+//
+//   - name: The name of the connection.
+func (r *Registry) Get(name string) (util.DB, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	db, ok := r.dbs[name]
+	if !ok {
+		return nil, fmt.Errorf("database not registered: %s", name)
+	}
+	return db, nil
+}
+
+// Close closes and removes all registered database connections.
+func (r *Registry) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var errs []error
+	for name, db := range r.dbs {
+		if err := db.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("close %s: %w", name, err))
+		}
+		delete(r.dbs, name)
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,50 @@
+package connection
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestRegistry_RegisterGet tests registering and getting a connection.
+func TestRegistry_RegisterGet(t *testing.T) {
+	registry := NewRegistry()
+	db := new(MockDB)
+
+	registry.Register("primary", db)
+	result, err := registry.Get("primary")
+
+	assert.NoError(t, err)
+	assert.Equal(t, db, result)
+}
+
+// TestRegistry_NotRegistered tests getting a connection which is not
+// registered.
+func TestRegistry_NotRegistered(t *testing.T) {
+	registry := NewRegistry()
+
+	result, err := registry.Get("replica")
+
+	assert.Nil(t, result)
+	assert.EqualError(t, err, "database not registered: replica")
+}
+
+// TestRegistry_Close tests that closing the registry closes and removes all
+// connections.
+func TestRegistry_Close(t *testing.T) {
+	registry := NewRegistry()
+	primary := new(MockDB)
+	replica := new(MockDB)
+	primary.On("Close").Return(nil)
+	replica.On("Close").Return(errors.New("close error"))
+	registry.Register("primary", primary)
+	registry.Register("replica", replica)
+
+	err := registry.Close()
+
+	assert.EqualError(t, err, "close replica: close error")
+	_, err = registry.Get("primary")
+	assert.Error(t, err)
+	primary.AssertExpectations(t)
+}
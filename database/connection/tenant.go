@@ -0,0 +1,259 @@
+package connection
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/pakkasys/fluidapi/database/util"
+)
+
+// TenantConfigResolver resolves the tenant of the operation from the context
+// and returns the tenant ID and the configuration of the tenant database.
+type TenantConfigResolver func(ctx context.Context) (string, *Config, error)
+
+type tenantPool struct {
+	db       util.DB
+	lastUsed time.Time
+	// users is the number of unreleased users of the pool
+	users int
+	// evicted is set when the pool has been removed from the router. It is
+	// closed when the last user releases it.
+	evicted bool
+}
+
+// tenantTx releases the connection pool of the tenant when the transaction
+// ends.
+type tenantTx struct {
+	util.Tx
+	release func()
+}
+
+func (t *tenantTx) Commit() error {
+	defer t.release()
+	return t.Tx.Commit()
+}
+
+func (t *tenantTx) Rollback() error {
+	defer t.release()
+	return t.Tx.Rollback()
+}
+
+// TenantRouter routes operations to a database per tenant. The connection
+// pool of a tenant is created on first use and closed when it has been idle
+// for too long or when too many pools are open. The pools are reference
+// counted, so a pool that is in use is not evicted, and an explicitly evicted
+// pool is closed when its last user releases it. It is safe for concurrent
+// use.
+type TenantRouter struct {
+	resolver    TenantConfigResolver
+	dbFactory   DBFactory
+	maxPools    int
+	idleTimeout time.Duration
+	nowFn       func() time.Time
+
+	mu    sync.Mutex
+	pools map[string]*tenantPool
+}
+
+// NewTenantRouter creates a new tenant router.
+//
+//   - resolver: Resolves the tenant database configuration from the context.
+//   - dbFactory: Creates the database connections.
+//   - maxPools: The maximum number of open pools, or 0 for no limit. Pools
+//     in use are not evicted, so the limit may be exceeded temporarily.
+//   - idleTimeout: How long an unused pool is kept open, or 0 for no limit.
+func NewTenantRouter(
+	resolver TenantConfigResolver,
+	dbFactory DBFactory,
+	maxPools int,
+	idleTimeout time.Duration,
+) *TenantRouter {
+	return &TenantRouter{
+		resolver:    resolver,
+		dbFactory:   dbFactory,
+		maxPools:    maxPools,
+		idleTimeout: idleTimeout,
+		nowFn:       time.Now,
+		pools:       map[string]*tenantPool{},
+	}
+}
+
+// DB returns the database connection of the tenant of the context and a
+// function releasing it. The connection is created if the tenant has no open
+// connection pool. The release function must be called once the connection
+// is no longer used, and it is safe to call more than once.
+//
+//   - ctx: The context of the operation.
+func (r *TenantRouter) DB(ctx context.Context) (util.DB, func(), error) {
+	tenantID, cfg, err := r.resolver(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if pool := r.acquire(tenantID); pool != nil {
+		return pool.db, r.releaseFunc(pool), nil
+	}
+
+	db, err := Connect(cfg, r.dbFactory)
+	if err != nil {
+		return nil, nil, fmt.Errorf("tenant %s: %w", tenantID, err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Another request may have connected the tenant concurrently.
+	pool, ok := r.pools[tenantID]
+	if ok {
+		_ = db.Close()
+	} else {
+		pool = &tenantPool{db: db}
+		r.pools[tenantID] = pool
+	}
+	pool.users++
+	pool.lastUsed = r.nowFn()
+	if !ok {
+		_ = r.evictLocked(tenantID)
+	}
+
+	return pool.db, r.releaseFunc(pool), nil
+}
+
+// GetTx begins a transaction in the database of the tenant of the context.
+// The connection pool is released when the transaction is committed or
+// rolled back. It can be used as the GetTxFn of the entity helpers.
+//
+//   - ctx: The context of the operation.
+func (r *TenantRouter) GetTx(ctx context.Context) (util.Tx, error) {
+	db, release, err := r.DB(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	return &tenantTx{Tx: tx, release: release}, nil
+}
+
+// Evict closes the connection pool of the tenant. A pool in use is closed
+// when its last user releases it.
+//
+//   - tenantID: The ID of the tenant.
+func (r *TenantRouter) Evict(tenantID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.closeLocked(tenantID)
+}
+
+// EvictIdle closes the unused connection pools that have been idle for
+// longer than the idle timeout.
+func (r *TenantRouter) EvictIdle() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.evictLocked("")
+}
+
+// Close closes all connection pools. The pools in use are closed when their
+// last users release them.
+func (r *TenantRouter) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var errs []error
+	for tenantID := range r.pools {
+		errs = append(errs, r.closeLocked(tenantID))
+	}
+	return errors.Join(errs...)
+}
+
+func (r *TenantRouter) acquire(tenantID string) *tenantPool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	pool, ok := r.pools[tenantID]
+	if !ok {
+		return nil
+	}
+	pool.users++
+	pool.lastUsed = r.nowFn()
+	return pool
+}
+
+// releaseFunc returns a function releasing one use of the pool. An evicted
+// pool is closed when its last user releases it.
+func (r *TenantRouter) releaseFunc(pool *tenantPool) func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+
+			pool.users--
+			pool.lastUsed = r.nowFn()
+			if pool.evicted && pool.users == 0 {
+				_ = pool.db.Close()
+			}
+		})
+	}
+}
+
+// evictLocked closes the unused idle pools and the least recently used
+// unused pools over the pool limit, except the pool of the given tenant.
+func (r *TenantRouter) evictLocked(keepTenantID string) error {
+	var errs []error
+
+	if r.idleTimeout > 0 {
+		now := r.nowFn()
+		for tenantID, pool := range r.pools {
+			if tenantID != keepTenantID && pool.users == 0 &&
+				now.Sub(pool.lastUsed) > r.idleTimeout {
+				errs = append(errs, r.closeLocked(tenantID))
+			}
+		}
+	}
+
+	for r.maxPools > 0 && len(r.pools) > r.maxPools {
+		oldestID := ""
+		var oldest time.Time
+		for tenantID, pool := range r.pools {
+			if tenantID == keepTenantID || pool.users > 0 {
+				continue
+			}
+			if oldestID == "" || pool.lastUsed.Before(oldest) {
+				oldestID = tenantID
+				oldest = pool.lastUsed
+			}
+		}
+		if oldestID == "" {
+			break
+		}
+		errs = append(errs, r.closeLocked(oldestID))
+	}
+
+	return errors.Join(errs...)
+}
+
+// closeLocked removes the pool of the tenant from the router and closes it,
+// or defers the closing to the release of its last user.
+func (r *TenantRouter) closeLocked(tenantID string) error {
+	pool, ok := r.pools[tenantID]
+	if !ok {
+		return nil
+	}
+	delete(r.pools, tenantID)
+	pool.evicted = true
+	if pool.users > 0 {
+		return nil
+	}
+	if err := pool.db.Close(); err != nil {
+		return fmt.Errorf("close tenant %s: %w", tenantID, err)
+	}
+	return nil
+}
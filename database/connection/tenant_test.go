@@ -0,0 +1,206 @@
+package connection
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/pakkasys/fluidapi/database/util"
+	utilmock "github.com/pakkasys/fluidapi/database/util/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type tenantKey struct{}
+
+func tenantTestRouter(
+	dbs map[string]*MockDB,
+	maxPools int,
+	idleTimeout time.Duration,
+) *TenantRouter {
+	return NewTenantRouter(
+		func(ctx context.Context) (string, *Config, error) {
+			tenantID, ok := ctx.Value(tenantKey{}).(string)
+			if !ok {
+				return "", nil, errors.New("tenant not found")
+			}
+			return tenantID, NewDefaultTCPConfig(
+				"user",
+				"password",
+				tenantID,
+				"mysql",
+			), nil
+		},
+		func(driver string, dsn string) (util.DB, error) {
+			for tenantID, db := range dbs {
+				if dsn == "user:password@tcp(localhost:3306)/"+
+					tenantID+"?parseTime=true&" {
+					return db, nil
+				}
+			}
+			return nil, errors.New("unknown dsn")
+		},
+		maxPools,
+		idleTimeout,
+	)
+}
+
+func newTenantMockDB() *MockDB {
+	db := new(MockDB)
+	db.On("SetConnMaxLifetime", mock.Anything)
+	db.On("SetConnMaxIdleTime", mock.Anything)
+	db.On("SetMaxOpenConns", mock.Anything)
+	db.On("SetMaxIdleConns", mock.Anything)
+	db.On("Ping").Return(nil)
+	db.On("Close").Return(nil)
+	return db
+}
+
+func tenantContext(tenantID string) context.Context {
+	return context.WithValue(context.Background(), tenantKey{}, tenantID)
+}
+
+// TestTenantRouter_DB tests that the connection of a tenant is created once
+// and reused.
+func TestTenantRouter_DB(t *testing.T) {
+	acme := newTenantMockDB()
+	router := tenantTestRouter(map[string]*MockDB{"acme": acme}, 0, 0)
+
+	for range 2 {
+		db, release, err := router.DB(tenantContext("acme"))
+		assert.NoError(t, err)
+		assert.Equal(t, acme, db)
+		release()
+	}
+
+	acme.AssertNumberOfCalls(t, "Ping", 1)
+}
+
+// TestTenantRouter_ResolverError tests that a resolver error is returned.
+func TestTenantRouter_ResolverError(t *testing.T) {
+	router := tenantTestRouter(map[string]*MockDB{}, 0, 0)
+
+	_, _, err := router.DB(context.Background())
+
+	assert.EqualError(t, err, "tenant not found")
+}
+
+// TestTenantRouter_MaxPools tests that the least recently used pool is
+// closed when the pool limit is exceeded.
+func TestTenantRouter_MaxPools(t *testing.T) {
+	acme := newTenantMockDB()
+	globex := newTenantMockDB()
+	router := tenantTestRouter(
+		map[string]*MockDB{"acme": acme, "globex": globex},
+		1,
+		0,
+	)
+
+	_, release, err := router.DB(tenantContext("acme"))
+	assert.NoError(t, err)
+	release()
+	_, _, err = router.DB(tenantContext("globex"))
+	assert.NoError(t, err)
+
+	acme.AssertCalled(t, "Close")
+	globex.AssertNotCalled(t, "Close")
+}
+
+// TestTenantRouter_MaxPoolsInUse tests that pools in use are not closed when
+// the pool limit is exceeded.
+func TestTenantRouter_MaxPoolsInUse(t *testing.T) {
+	acme := newTenantMockDB()
+	globex := newTenantMockDB()
+	router := tenantTestRouter(
+		map[string]*MockDB{"acme": acme, "globex": globex},
+		1,
+		0,
+	)
+
+	_, _, err := router.DB(tenantContext("acme"))
+	assert.NoError(t, err)
+	_, _, err = router.DB(tenantContext("globex"))
+	assert.NoError(t, err)
+
+	acme.AssertNotCalled(t, "Close")
+	globex.AssertNotCalled(t, "Close")
+}
+
+// TestTenantRouter_EvictIdle tests that idle pools are closed.
+func TestTenantRouter_EvictIdle(t *testing.T) {
+	now := time.Unix(1000, 0)
+	acme := newTenantMockDB()
+	router := tenantTestRouter(
+		map[string]*MockDB{"acme": acme},
+		0,
+		time.Minute,
+	)
+	router.nowFn = func() time.Time { return now }
+
+	_, release, err := router.DB(tenantContext("acme"))
+	assert.NoError(t, err)
+
+	now = now.Add(2 * time.Minute)
+	assert.NoError(t, router.EvictIdle())
+	acme.AssertNotCalled(t, "Close")
+
+	release()
+	assert.NoError(t, router.EvictIdle())
+	acme.AssertNotCalled(t, "Close")
+
+	now = now.Add(2 * time.Minute)
+	assert.NoError(t, router.EvictIdle())
+	acme.AssertCalled(t, "Close")
+}
+
+// TestTenantRouter_EvictInUse tests that an evicted pool in use is closed
+// when its last user releases it.
+func TestTenantRouter_EvictInUse(t *testing.T) {
+	acme := newTenantMockDB()
+	router := tenantTestRouter(map[string]*MockDB{"acme": acme}, 0, 0)
+
+	_, release1, err := router.DB(tenantContext("acme"))
+	assert.NoError(t, err)
+	_, release2, err := router.DB(tenantContext("acme"))
+	assert.NoError(t, err)
+
+	assert.NoError(t, router.Evict("acme"))
+	release1()
+	release1()
+	acme.AssertNotCalled(t, "Close")
+
+	release2()
+	acme.AssertNumberOfCalls(t, "Close", 1)
+}
+
+// TestTenantRouter_GetTx tests beginning a transaction in the tenant
+// database and releasing the pool when the transaction ends.
+func TestTenantRouter_GetTx(t *testing.T) {
+	acme := newTenantMockDB()
+	tx := new(utilmock.MockTx)
+	tx.On("Commit").Return(nil)
+	acme.On("BeginTx", mock.Anything, mock.Anything).Return(tx, nil)
+	router := tenantTestRouter(map[string]*MockDB{"acme": acme}, 0, 0)
+
+	result, err := router.GetTx(tenantContext("acme"))
+	assert.NoError(t, err)
+	assert.NoError(t, router.Evict("acme"))
+	acme.AssertNotCalled(t, "Close")
+
+	assert.NoError(t, result.Commit())
+	tx.AssertCalled(t, "Commit")
+	acme.AssertNumberOfCalls(t, "Close", 1)
+}
+
+// TestTenantRouter_Close tests that closing the router closes all pools.
+func TestTenantRouter_Close(t *testing.T) {
+	acme := newTenantMockDB()
+	router := tenantTestRouter(map[string]*MockDB{"acme": acme}, 0, 0)
+	_, release, _ := router.DB(tenantContext("acme"))
+	release()
+
+	assert.NoError(t, router.Close())
+	assert.NoError(t, router.Evict("acme"))
+	acme.AssertNumberOfCalls(t, "Close", 1)
+}
@@ -0,0 +1,343 @@
+// Package dbtest provides helpers for integration tests running against a
+// real database in a container.
+//
+// The container itself is started by a ContainerStarter so that the package
+// does not depend on a specific container library. A starter using
+// testcontainers-go typically looks like:
+//
+//	func startMySQL(ctx context.Context) (dbtest.Container, error) {
+//		c, err := mysql.Run(ctx, "mysql:8")
+//		if err != nil {
+//			return nil, err
+//		}
+//		dsn, err := c.ConnectionString(ctx, "parseTime=true")
+//		if err != nil {
+//			return nil, err
+//		}
+//		return dbtest.NewContainer(dsn, c.Terminate), nil
+//	}
+//
+// Tests then only need:
+//
+//	db := dbtest.Start(t, dbtest.MySQL(startMySQL, schema...))
+//	db.Truncate(t)
+package dbtest
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pakkasys/fluidapi/database/util"
+)
+
+var identifierQuotes = strings.NewReplacer("`", "", `"`, "")
+
+const (
+	defaultReadyTimeout  = 30 * time.Second
+	defaultReadyInterval = 500 * time.Millisecond
+)
+
+// Container is a running database container.
+type Container interface {
+	// DSN returns the connection string of the database.
+	DSN() string
+	// Terminate stops and removes the container.
+	Terminate(ctx context.Context) error
+}
+
+// ContainerStarter starts a database container.
+type ContainerStarter func(ctx context.Context) (Container, error)
+
+// Config configures the database started by Start.
+type Config struct {
+	// Driver is the name of the registered database/sql driver
+	Driver string
+	// Dialect generates the dialect specific statements
+	Dialect Dialect
+	// Start starts the database container
+	Start ContainerStarter
+	// Migrations are the DDL statements applied after the database is ready
+	Migrations []string
+	// Tables are the tables emptied by Truncate. If empty, Truncate empties
+	// the tables created by the migrations.
+	Tables []string
+	// ReadyTimeout is how long to wait for the database to accept
+	// connections. Defaults to 30 seconds.
+	ReadyTimeout time.Duration
+	// OpenFn opens the database connection. Defaults to util.NewSQLDB.
+	OpenFn func(driver string, dsn string) (util.DB, error)
+}
+
+// MySQL returns a configuration for a MySQL database.
+//
+// Parameters:
+//   - start: The function starting the MySQL container.
+//   - migrations: The DDL statements to apply.
+//
+// Returns:
+//   - The database configuration.
+func MySQL(start ContainerStarter, migrations ...string) Config {
+	return Config{
+		Driver:     "mysql",
+		Dialect:    MySQLDialect{},
+		Start:      start,
+		Migrations: migrations,
+	}
+}
+
+// Postgres returns a configuration for a PostgreSQL database.
+//
+// Parameters:
+//   - start: The function starting the PostgreSQL container.
+//   - migrations: The DDL statements to apply.
+//
+// Returns:
+//   - The database configuration.
+func Postgres(start ContainerStarter, migrations ...string) Config {
+	return Config{
+		Driver:     "postgres",
+		Dialect:    PostgresDialect{},
+		Start:      start,
+		Migrations: migrations,
+	}
+}
+
+// Database is a database started for tests.
+type Database struct {
+	// DB is the connection to the database
+	DB util.DB
+	// Container is the container running the database
+	Container Container
+
+	dialect Dialect
+	tables  []string
+}
+
+// Start starts the database container, waits until the database accepts
+// connections and applies the migrations. The connection is closed and the
+// container is terminated when the test finishes. The test is skipped if no
+// container starter is configured.
+//
+// Parameters:
+//   - t: The test using the database.
+//   - cfg: The database configuration.
+//
+// Returns:
+//   - The started database.
+func Start(t testing.TB, cfg Config) *Database {
+	t.Helper()
+
+	if cfg.Start == nil {
+		t.Skip("dbtest: no container starter configured")
+		return nil
+	}
+
+	ctx := context.Background()
+	container, err := cfg.Start(ctx)
+	if err != nil {
+		t.Fatalf("dbtest: starting container: %v", err)
+		return nil
+	}
+	t.Cleanup(func() {
+		if err := container.Terminate(ctx); err != nil {
+			t.Errorf("dbtest: terminating container: %v", err)
+		}
+	})
+
+	db, err := open(cfg, container.DSN())
+	if err != nil {
+		t.Fatalf("dbtest: opening database: %v", err)
+		return nil
+	}
+	t.Cleanup(func() { db.Close() })
+
+	if err := waitReady(db, cfg.ReadyTimeout); err != nil {
+		t.Fatalf("dbtest: waiting for database: %v", err)
+		return nil
+	}
+	for _, migration := range cfg.Migrations {
+		if _, err := db.Exec(migration); err != nil {
+			t.Fatalf("dbtest: applying migration: %v", err)
+			return nil
+		}
+	}
+
+	tables := cfg.Tables
+	if len(tables) == 0 {
+		tables = CreatedTables(cfg.Migrations)
+	}
+	return &Database{
+		DB:        db,
+		Container: container,
+		dialect:   cfg.Dialect,
+		tables:    tables,
+	}
+}
+
+// Truncate empties the tables of the database. It is typically called at the
+// start of each test sharing the database.
+//
+// Parameters:
+//   - t: The test using the database.
+func (d *Database) Truncate(t testing.TB) {
+	t.Helper()
+	if err := d.truncate(); err != nil {
+		t.Fatalf("dbtest: truncating tables: %v", err)
+	}
+}
+
+func (d *Database) truncate() error {
+	if len(d.tables) == 0 {
+		return nil
+	}
+	if d.dialect == nil {
+		return fmt.Errorf("no dialect configured")
+	}
+
+	// The statements are executed in one transaction so that they share the
+	// session settings of a single connection.
+	tx, err := d.DB.BeginTx(context.Background(), nil)
+	if err != nil {
+		return err
+	}
+	for _, statement := range d.dialect.TruncateStatements(d.tables) {
+		if err := execTx(tx, statement); err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func execTx(tx util.Tx, statement string) error {
+	stmt, err := tx.Prepare(statement)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.Exec()
+	return err
+}
+
+func open(cfg Config, dsn string) (util.DB, error) {
+	if cfg.OpenFn != nil {
+		return cfg.OpenFn(cfg.Driver, dsn)
+	}
+	return util.NewSQLDB(cfg.Driver, dsn)
+}
+
+func waitReady(db util.DB, timeout time.Duration) error {
+	if timeout <= 0 {
+		timeout = defaultReadyTimeout
+	}
+	deadline := time.Now().Add(timeout)
+	for {
+		err := db.Ping()
+		if err == nil {
+			return nil
+		}
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return err
+		}
+		time.Sleep(min(defaultReadyInterval, remaining))
+	}
+}
+
+// ReadMigrations reads the .sql files matching the pattern in lexical order
+// and splits them into statements at semicolons. Semicolons inside string
+// literals or procedure bodies are not supported.
+//
+// Parameters:
+//   - fsys: The file system containing the migration files.
+//   - pattern: The glob pattern of the migration files, e.g. "*.sql".
+//
+// Returns:
+//   - The migration statements.
+//   - An error if reading the files fails.
+func ReadMigrations(fsys fs.FS, pattern string) ([]string, error) {
+	files, err := fs.Glob(fsys, pattern)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+
+	var statements []string
+	for _, file := range files {
+		content, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, err
+		}
+		for _, statement := range strings.Split(string(content), ";") {
+			if statement = strings.TrimSpace(statement); statement != "" {
+				statements = append(statements, statement)
+			}
+		}
+	}
+	return statements, nil
+}
+
+// CreatedTables returns the names of the tables created by CREATE TABLE
+// statements in the order they are created.
+//
+// Parameters:
+//   - statements: The DDL statements.
+//
+// Returns:
+//   - The names of the created tables.
+func CreatedTables(statements []string) []string {
+	var tables []string
+	for _, statement := range statements {
+		fields := strings.Fields(statement)
+		if len(fields) < 3 ||
+			!strings.EqualFold(fields[0], "CREATE") ||
+			!strings.EqualFold(fields[1], "TABLE") {
+			continue
+		}
+		name := fields[2]
+		if len(fields) >= 6 && strings.EqualFold(name, "IF") {
+			name = fields[5]
+		}
+		name = strings.SplitN(name, "(", 2)[0]
+		tables = append(tables, identifierQuotes.Replace(name))
+	}
+	return tables
+}
+
+// NewContainer creates a Container from a connection string and a function
+// terminating the container.
+//
+// Parameters:
+//   - dsn: The connection string of the database.
+//   - terminate: The function terminating the container.
+//
+// Returns:
+//   - The container.
+func NewContainer(
+	dsn string,
+	terminate func(ctx context.Context) error,
+) Container {
+	return &container{dsn: dsn, terminate: terminate}
+}
+
+type container struct {
+	dsn       string
+	terminate func(ctx context.Context) error
+}
+
+func (c *container) DSN() string {
+	return c.dsn
+}
+
+func (c *container) Terminate(ctx context.Context) error {
+	if c.terminate == nil {
+		return nil
+	}
+	return c.terminate(ctx)
+}
@@ -0,0 +1,240 @@
+package dbtest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/pakkasys/fluidapi/database/util"
+	utilmock "github.com/pakkasys/fluidapi/database/util/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// recordingTB is a testing.TB recording failures, skips and cleanups.
+type recordingTB struct {
+	testing.TB
+	failures []string
+	skipped  bool
+	cleanups []func()
+}
+
+func (r *recordingTB) Helper() {}
+
+func (r *recordingTB) Fatalf(format string, args ...any) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+func (r *recordingTB) Errorf(format string, args ...any) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+func (r *recordingTB) Skip(args ...any) {
+	r.skipped = true
+}
+
+func (r *recordingTB) Cleanup(fn func()) {
+	r.cleanups = append(r.cleanups, fn)
+}
+
+func (r *recordingTB) runCleanups() {
+	for i := len(r.cleanups) - 1; i >= 0; i-- {
+		r.cleanups[i]()
+	}
+}
+
+func newStarter(terminated *bool) ContainerStarter {
+	return func(ctx context.Context) (Container, error) {
+		return NewContainer("dsn", func(ctx context.Context) error {
+			*terminated = true
+			return nil
+		}), nil
+	}
+}
+
+// TestStart tests starting a database and applying the migrations.
+func TestStart(t *testing.T) {
+	mockDB := new(utilmock.MockDB)
+	mockDB.On("Ping").Return(nil).Once()
+	mockDB.On("Exec", "CREATE TABLE `users` (id INT)", []any(nil)).
+		Return(nil, nil).Once()
+	mockDB.On("Close").Return(nil).Once()
+
+	terminated := false
+	cfg := MySQL(newStarter(&terminated), "CREATE TABLE `users` (id INT)")
+	cfg.OpenFn = func(driver string, dsn string) (util.DB, error) {
+		assert.Equal(t, "mysql", driver)
+		assert.Equal(t, "dsn", dsn)
+		return mockDB, nil
+	}
+
+	tb := &recordingTB{}
+	db := Start(tb, cfg)
+
+	assert.Empty(t, tb.failures)
+	assert.Equal(t, mockDB, db.DB)
+	assert.Equal(t, []string{"users"}, db.tables)
+
+	tb.runCleanups()
+	assert.True(t, terminated)
+	mockDB.AssertExpectations(t)
+}
+
+// TestStart_NoStarter tests that the test is skipped without a starter.
+func TestStart_NoStarter(t *testing.T) {
+	tb := &recordingTB{}
+
+	db := Start(tb, Config{})
+
+	assert.Nil(t, db)
+	assert.True(t, tb.skipped)
+}
+
+// TestStart_StartError tests that a failing container start fails the test.
+func TestStart_StartError(t *testing.T) {
+	tb := &recordingTB{}
+
+	db := Start(tb, MySQL(func(ctx context.Context) (Container, error) {
+		return nil, errors.New("docker unavailable")
+	}))
+
+	assert.Nil(t, db)
+	assert.Equal(
+		t,
+		[]string{"dbtest: starting container: docker unavailable"},
+		tb.failures,
+	)
+}
+
+// TestStart_MigrationError tests that a failing migration fails the test and
+// still terminates the container.
+func TestStart_MigrationError(t *testing.T) {
+	mockDB := new(utilmock.MockDB)
+	mockDB.On("Ping").Return(nil).Once()
+	mockDB.On("Exec", "BROKEN", []any(nil)).
+		Return(nil, errors.New("syntax error")).Once()
+	mockDB.On("Close").Return(nil).Once()
+
+	terminated := false
+	cfg := MySQL(newStarter(&terminated), "BROKEN")
+	cfg.OpenFn = func(driver string, dsn string) (util.DB, error) {
+		return mockDB, nil
+	}
+
+	tb := &recordingTB{}
+	db := Start(tb, cfg)
+	tb.runCleanups()
+
+	assert.Nil(t, db)
+	assert.Equal(
+		t,
+		[]string{"dbtest: applying migration: syntax error"},
+		tb.failures,
+	)
+	assert.True(t, terminated)
+}
+
+// TestWaitReady tests waiting until the database accepts connections.
+func TestWaitReady(t *testing.T) {
+	mockDB := new(utilmock.MockDB)
+	mockDB.On("Ping").Return(errors.New("refused")).Once()
+	mockDB.On("Ping").Return(nil).Once()
+
+	err := waitReady(mockDB, 50*time.Millisecond)
+
+	assert.NoError(t, err)
+	mockDB.AssertExpectations(t)
+}
+
+// TestWaitReady_Timeout tests that the last error is returned after the
+// timeout.
+func TestWaitReady_Timeout(t *testing.T) {
+	mockDB := new(utilmock.MockDB)
+	mockDB.On("Ping").Return(errors.New("refused"))
+
+	err := waitReady(mockDB, time.Millisecond)
+
+	assert.EqualError(t, err, "refused")
+}
+
+// TestTruncate tests emptying the tables in one transaction.
+func TestTruncate(t *testing.T) {
+	mockDB := new(utilmock.MockDB)
+	mockTx := new(utilmock.MockTx)
+	mockStmt := new(utilmock.MockStmt)
+	mockDB.On("BeginTx", mock.Anything, mock.Anything).Return(mockTx, nil)
+	for _, statement := range []string{
+		"SET FOREIGN_KEY_CHECKS = 0",
+		"TRUNCATE TABLE `users`",
+		"SET FOREIGN_KEY_CHECKS = 1",
+	} {
+		mockTx.On("Prepare", statement).Return(mockStmt, nil).Once()
+	}
+	mockStmt.On("Exec", []any(nil)).Return(nil, nil)
+	mockStmt.On("Close").Return(nil)
+	mockTx.On("Commit").Return(nil).Once()
+
+	db := &Database{DB: mockDB, dialect: MySQLDialect{}, tables: []string{
+		"users",
+	}}
+	tb := &recordingTB{}
+	db.Truncate(tb)
+
+	assert.Empty(t, tb.failures)
+	mockTx.AssertExpectations(t)
+}
+
+// TestTruncate_Error tests that a failing statement rolls back and fails the
+// test.
+func TestTruncate_Error(t *testing.T) {
+	mockDB := new(utilmock.MockDB)
+	mockTx := new(utilmock.MockTx)
+	mockDB.On("BeginTx", mock.Anything, mock.Anything).Return(mockTx, nil)
+	mockTx.On("Prepare", mock.Anything).
+		Return(nil, errors.New("denied")).Once()
+	mockTx.On("Rollback").Return(nil).Once()
+
+	db := &Database{DB: mockDB, dialect: MySQLDialect{}, tables: []string{
+		"users",
+	}}
+	tb := &recordingTB{}
+	db.Truncate(tb)
+
+	assert.Equal(t, []string{"dbtest: truncating tables: denied"}, tb.failures)
+	mockTx.AssertExpectations(t)
+}
+
+// TestReadMigrations tests reading and splitting migration files.
+func TestReadMigrations(t *testing.T) {
+	fsys := fstest.MapFS{
+		"002_posts.sql": {Data: []byte("CREATE TABLE posts (id INT);\n")},
+		"001_users.sql": {Data: []byte(
+			"CREATE TABLE users (id INT);\nCREATE INDEX i ON users (id);",
+		)},
+		"readme.md": {Data: []byte("ignored")},
+	}
+
+	statements, err := ReadMigrations(fsys, "*.sql")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"CREATE TABLE users (id INT)",
+		"CREATE INDEX i ON users (id)",
+		"CREATE TABLE posts (id INT)",
+	}, statements)
+}
+
+// TestCreatedTables tests extracting the created tables from DDL.
+func TestCreatedTables(t *testing.T) {
+	tables := CreatedTables([]string{
+		"CREATE TABLE `users` (id INT)",
+		"create table IF NOT EXISTS \"posts\"(id INT)",
+		"CREATE TABLE `app`.`tags` (id INT)",
+		"CREATE INDEX i ON users (id)",
+	})
+
+	assert.Equal(t, []string{"users", "posts", "app.tags"}, tables)
+}
@@ -0,0 +1,49 @@
+package dbtest
+
+import (
+	"strings"
+
+	"github.com/pakkasys/fluidapi/database/util"
+)
+
+// Dialect generates the database specific statements used by the helpers.
+type Dialect interface {
+	// TruncateStatements returns the statements emptying the tables.
+	TruncateStatements(tables []string) []string
+}
+
+// MySQLDialect is the Dialect of MySQL databases.
+type MySQLDialect struct{}
+
+// TruncateStatements returns the statements emptying the tables with the
+// foreign key checks disabled.
+func (MySQLDialect) TruncateStatements(tables []string) []string {
+	statements := []string{"SET FOREIGN_KEY_CHECKS = 0"}
+	for _, table := range tables {
+		statements = append(
+			statements,
+			"TRUNCATE TABLE "+util.QuoteIdentifier(table),
+		)
+	}
+	return append(statements, "SET FOREIGN_KEY_CHECKS = 1")
+}
+
+// PostgresDialect is the Dialect of PostgreSQL databases.
+type PostgresDialect struct{}
+
+// TruncateStatements returns a statement emptying the tables and resetting
+// their sequences.
+func (PostgresDialect) TruncateStatements(tables []string) []string {
+	quoted := make([]string, len(tables))
+	for i, table := range tables {
+		parts := strings.Split(table, ".")
+		for j := range parts {
+			parts[j] = `"` + parts[j] + `"`
+		}
+		quoted[i] = strings.Join(parts, ".")
+	}
+	return []string{
+		"TRUNCATE TABLE " + strings.Join(quoted, ", ") +
+			" RESTART IDENTITY CASCADE",
+	}
+}
@@ -0,0 +1,33 @@
+package dbtest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestMySQLDialect_TruncateStatements tests the MySQL truncate statements.
+func TestMySQLDialect_TruncateStatements(t *testing.T) {
+	statements := MySQLDialect{}.TruncateStatements(
+		[]string{"users", "app.posts"},
+	)
+
+	assert.Equal(t, []string{
+		"SET FOREIGN_KEY_CHECKS = 0",
+		"TRUNCATE TABLE `users`",
+		"TRUNCATE TABLE `app`.`posts`",
+		"SET FOREIGN_KEY_CHECKS = 1",
+	}, statements)
+}
+
+// TestPostgresDialect_TruncateStatements tests the PostgreSQL truncate
+// statement.
+func TestPostgresDialect_TruncateStatements(t *testing.T) {
+	statements := PostgresDialect{}.TruncateStatements(
+		[]string{"users", "app.posts"},
+	)
+
+	assert.Equal(t, []string{
+		`TRUNCATE TABLE "users", "app"."posts" RESTART IDENTITY CASCADE`,
+	}, statements)
+}
@@ -0,0 +1,150 @@
+package entity
+
+import (
+	"context"
+
+	"github.com/pakkasys/fluidapi/database/transaction"
+	"github.com/pakkasys/fluidapi/database/util"
+	"github.com/pakkasys/fluidapi/endpoint/page"
+)
+
+// AggregateOptions is the options struct for grouped aggregate queries.
+type AggregateOptions struct {
+	Selectors []util.Selector
+	Joins     []util.Join
+	// Dimensions are the columns the rows are grouped by
+	Dimensions []util.Projection
+	// Measures are the aggregates computed for each group
+	Measures []util.Aggregate
+	// Orders may refer to the aliases of the measures
+	Orders []util.Order
+	Page   *page.Page
+}
+
+// GetAggregates runs a grouped aggregate query and returns the rows as
+// tables of values. Each row has the values of the dimensions followed by
+// the values of the measures. Byte slices returned by the driver are
+// converted to strings.
+//
+//   - preparer: The preparer used to prepare the query.
+//   - tableName: The name of the database table.
+//   - dbOptions: The options for the query.
+func GetAggregates(
+	preparer util.Preparer,
+	tableName string,
+	dbOptions *AggregateOptions,
+) ([][]any, error) {
+	query, whereValues := buildAggregateQuery(tableName, dbOptions)
+
+	rows, statement, err := RowsQuery(preparer, query, whereValues)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	defer statement.Close()
+
+	columnCount := len(dbOptions.Dimensions) + len(dbOptions.Measures)
+	result := [][]any{}
+	for rows.Next() {
+		values := make([]any, columnCount)
+		dest := make([]any, columnCount)
+		for i := range values {
+			dest[i] = &values[i]
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		for i, value := range values {
+			if bytes, ok := value.([]byte); ok {
+				values[i] = string(bytes)
+			}
+		}
+		result = append(result, values)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// GetAggregates runs a grouped aggregate query on the table of the tenant.
+// The row scope selectors are added to the selectors of the query.
+//
+//   - ctx: The context of the operation.
+//   - preparer: The preparer used to prepare the query.
+//   - opts: The options for the query.
+func (e *EntityHelpers[T]) GetAggregates(
+	ctx context.Context,
+	preparer util.Preparer,
+	opts AggregateOptions,
+) ([][]any, error) {
+	tenant, err := e.tenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+	opts = tenant.scopeAggregateOptions(opts)
+	opts.Selectors = tenant.scopeSelectors(e.scopeRows(ctx, opts.Selectors))
+
+	return retryStaleRead(
+		e.RetryStaleReads,
+		preparer,
+		func() ([][]any, error) {
+			return GetAggregates(
+				util.WithContext(ctx, preparer),
+				tenant.TableName(e.TableName),
+				&opts,
+			)
+		},
+	)
+}
+
+// GetAggregatesWithManagedTransaction wraps the aggregate query in a
+// transaction.
+//
+//   - ctx: The context to use when getting and setting the transaction.
+//   - opts: The options for the query.
+func (e *EntityHelpers[T]) GetAggregatesWithManagedTransaction(
+	ctx context.Context,
+	opts AggregateOptions,
+) ([][]any, error) {
+	return retryStaleManagedRead(
+		ctx,
+		e.RetryStaleReads,
+		func(ctx context.Context) ([][]any, error) {
+			return transaction.ExecuteManagedTransaction(
+				ctx,
+				e.GetTxFn,
+				func(ctx context.Context, tx util.Tx) ([][]any, error) {
+					return e.GetAggregates(ctx, tx, opts)
+				},
+			)
+		},
+	)
+}
+
+func buildAggregateQuery(
+	tableName string,
+	dbOptions *AggregateOptions,
+) (string, []any) {
+	groupBy := make([]util.ColumSelector, len(dbOptions.Dimensions))
+	for i, dimension := range dbOptions.Dimensions {
+		groupBy[i] = util.ColumSelector{
+			Table:  dimension.Table,
+			Column: dimension.Column,
+		}
+	}
+
+	builder := util.NewSelect(tableName).
+		Columns(dbOptions.Dimensions...).
+		Aggregates(dbOptions.Measures...).
+		Join(dbOptions.Joins...).
+		Where(dbOptions.Selectors...).
+		GroupBy(groupBy...).
+		OrderBy(dbOptions.Orders...)
+	if dbOptions.Page != nil {
+		builder.Limit(dbOptions.Page.Limit, dbOptions.Page.Offset)
+	}
+
+	return builder.Build()
+}
@@ -0,0 +1,120 @@
+package entity
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/pakkasys/fluidapi/database/util"
+	utilmock "github.com/pakkasys/fluidapi/database/util/mock"
+	"github.com/pakkasys/fluidapi/endpoint/page"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var aggregateOptions = &AggregateOptions{
+	Selectors: []util.Selector{{
+		Table:     "orders",
+		Field:     "total",
+		Predicate: util.GREATER,
+		Value:     0,
+	}},
+	Dimensions: []util.Projection{{Table: "orders", Column: "status"}},
+	Measures: []util.Aggregate{{
+		Function: util.AggregateSum,
+		Table:    "orders",
+		Column:   "total",
+		Alias:    "sum_total",
+	}},
+	Orders: []util.Order{{Field: "sum_total", Direction: util.OrderDesc}},
+	Page:   &page.Page{Limit: 10},
+}
+
+// TestGetAggregates tests that the grouped rows are returned as values.
+func TestGetAggregates(t *testing.T) {
+	mockDB := new(utilmock.MockDB)
+	mockStmt := new(utilmock.MockStmt)
+	mockRows := new(utilmock.MockRows)
+	mockDB.On(
+		"Prepare",
+		"SELECT `orders`.`status`,SUM(`orders`.`total`) AS `sum_total` "+
+			"FROM `orders` WHERE `orders`.`total` > ? "+
+			"GROUP BY `orders`.`status` ORDER BY `sum_total` DESC "+
+			"LIMIT 10 OFFSET 0",
+	).Return(mockStmt, nil)
+	mockStmt.On("Query", []any{0}).Return(mockRows, nil)
+	mockStmt.On("Close").Return(nil)
+	mockRows.On("Next").Return(true).Once()
+	mockRows.On("Scan", mock.Anything).Run(func(args mock.Arguments) {
+		dest := args.Get(0).([]any)
+		*dest[0].(*any) = []byte("paid")
+		*dest[1].(*any) = 12.5
+	}).Return(nil).Once()
+	mockRows.On("Next").Return(false)
+	mockRows.On("Err").Return(nil)
+	mockRows.On("Close").Return(nil)
+
+	rows, err := GetAggregates(mockDB, "orders", aggregateOptions)
+
+	assert.NoError(t, err)
+	assert.Equal(t, [][]any{{"paid", 12.5}}, rows)
+	mockDB.AssertExpectations(t)
+	mockRows.AssertExpectations(t)
+}
+
+// TestGetAggregates_ScanError tests that scan errors are returned.
+func TestGetAggregates_ScanError(t *testing.T) {
+	mockDB := new(utilmock.MockDB)
+	mockStmt := new(utilmock.MockStmt)
+	mockRows := new(utilmock.MockRows)
+	mockDB.On("Prepare", mock.Anything).Return(mockStmt, nil)
+	mockStmt.On("Query", mock.Anything).Return(mockRows, nil)
+	mockStmt.On("Close").Return(nil)
+	mockRows.On("Next").Return(true)
+	mockRows.On("Scan", mock.Anything).Return(errors.New("scan error"))
+	mockRows.On("Close").Return(nil)
+
+	rows, err := GetAggregates(mockDB, "orders", aggregateOptions)
+
+	assert.Nil(t, rows)
+	assert.EqualError(t, err, "scan error")
+}
+
+// TestEntityHelpers_GetAggregates tests that the aggregate query is scoped to
+// the tenant and the row scope.
+func TestEntityHelpers_GetAggregates(t *testing.T) {
+	mockDB := new(utilmock.MockDB)
+	mockStmt := new(utilmock.MockStmt)
+	mockRows := new(utilmock.MockRows)
+	entityHelpers := EntityHelpers[TestEntity]{
+		TableName: "orders",
+		TenantResolver: func(ctx context.Context) (*Tenant, error) {
+			return &Tenant{TablePrefix: "t1_"}, nil
+		},
+		RowScope: testRowScope,
+	}
+	mockDB.On(
+		"Prepare",
+		"SELECT `t1_orders`.`status`,SUM(`t1_orders`.`total`) AS `sum_total` "+
+			"FROM `t1_orders` WHERE `t1_orders`.`total` > ? "+
+			"AND `t1_users`.`tenant_id` = ? "+
+			"GROUP BY `t1_orders`.`status` ORDER BY `sum_total` DESC "+
+			"LIMIT 10 OFFSET 0",
+	).Return(mockStmt, nil)
+	mockStmt.On("Query", []any{0, 7}).Return(mockRows, nil)
+	mockStmt.On("Close").Return(nil)
+	mockRows.On("Next").Return(false)
+	mockRows.On("Err").Return(nil)
+	mockRows.On("Close").Return(nil)
+
+	rows, err := entityHelpers.GetAggregates(
+		context.WithValue(context.Background(), rowScopeKey{}, 7),
+		mockDB,
+		*aggregateOptions,
+	)
+
+	assert.NoError(t, err)
+	assert.Empty(t, rows)
+	assert.Equal(t, "orders", aggregateOptions.Dimensions[0].Table)
+	mockDB.AssertExpectations(t)
+}
@@ -0,0 +1,94 @@
+package entity
+
+import (
+	"context"
+
+	"github.com/pakkasys/fluidapi/database/util"
+)
+
+// DefaultIDChunkSize is the default maximum number of IDs in a single IN
+// query, keeping the queries below the placeholder limits of the databases.
+const DefaultIDChunkSize = 1000
+
+// ByIDsResult is the result of GetEntitiesByIDs.
+type ByIDsResult[T any, K comparable] struct {
+	// Entities are the found entities in the order of the requested IDs
+	Entities []T
+	// Missing are the requested IDs without an entity
+	Missing []K
+}
+
+// GetEntitiesByIDs gets the entities with the given IDs using IN queries, at
+// most chunkSize IDs per query. The entities are returned in the order of
+// the requested IDs and the IDs without an entity are reported as missing.
+//
+//   - ctx: The context of the operation.
+//   - preparer: The preparer used to prepare the queries.
+//   - helpers: The entity helpers of the table.
+//   - idField: The ID field of the table.
+//   - ids: The IDs of the entities to get.
+//   - idFn: The function returning the ID of an entity.
+//   - chunkSize: The maximum number of IDs per query, or 0 for the default.
+func GetEntitiesByIDs[T any, K comparable](
+	ctx context.Context,
+	preparer util.Preparer,
+	helpers *EntityHelpers[T],
+	idField string,
+	ids []K,
+	idFn func(entity *T) K,
+	chunkSize int,
+) (*ByIDsResult[T, K], error) {
+	if chunkSize <= 0 {
+		chunkSize = DefaultIDChunkSize
+	}
+
+	uniqueIDs := make([]K, 0, len(ids))
+	seen := make(map[K]bool, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			uniqueIDs = append(uniqueIDs, id)
+		}
+	}
+
+	found := make(map[K]T, len(uniqueIDs))
+	for start := 0; start < len(uniqueIDs); start += chunkSize {
+		chunk := uniqueIDs[start:min(start+chunkSize, len(uniqueIDs))]
+		entities, err := helpers.GetEntities(
+			ctx,
+			preparer,
+			GetOptions{
+				Options: Options{
+					Selectors: []util.Selector{
+						{
+							Table:     helpers.TableName,
+							Field:     idField,
+							Predicate: util.IN,
+							Value:     chunk,
+						},
+					},
+				},
+			},
+		)
+		if err != nil {
+			return nil, err
+		}
+		for i := range entities {
+			found[idFn(&entities[i])] = entities[i]
+		}
+	}
+
+	result := &ByIDsResult[T, K]{
+		Entities: make([]T, 0, len(ids)),
+		Missing:  []K{},
+	}
+	for _, id := range ids {
+		if entity, ok := found[id]; ok {
+			result.Entities = append(result.Entities, entity)
+		} else {
+			result.Missing = append(result.Missing, id)
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,134 @@
+package entity
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/pakkasys/fluidapi/database/util"
+	utilmock "github.com/pakkasys/fluidapi/database/util/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func expectByIDsQuery(
+	preparer *utilmock.MockDB,
+	query string,
+	params []any,
+	ids []int,
+) {
+	mockStmt := new(utilmock.MockStmt)
+	mockRows := new(utilmock.MockRows)
+	preparer.On("Prepare", query).Return(mockStmt, nil).Once()
+	mockStmt.On("Query", params).Return(mockRows, nil)
+	mockStmt.On("Close").Return(nil)
+	for _, id := range ids {
+		mockRows.On("Next").Return(true).Once()
+		mockRows.On("Scan", mock.Anything).Run(func(args mock.Arguments) {
+			*args.Get(0).([]any)[0].(*int) = id
+		}).Return(nil).Once()
+	}
+	mockRows.On("Next").Return(false)
+	mockRows.On("Err").Return(nil)
+	mockRows.On("Close").Return(nil)
+}
+
+func byIDsEntityHelpers() *EntityHelpers[TestEntity] {
+	return &EntityHelpers[TestEntity]{
+		TableName: "users",
+		ScanRowsFn: func(rows util.Rows, entity *TestEntity) error {
+			return rows.Scan(&entity.ID)
+		},
+	}
+}
+
+func testEntityID(entity *TestEntity) int {
+	return entity.ID
+}
+
+// TestGetEntitiesByIDs tests that the entities are returned in the requested
+// order and that missing IDs are reported.
+func TestGetEntitiesByIDs(t *testing.T) {
+	mockPreparer := new(utilmock.MockDB)
+	expectByIDsQuery(
+		mockPreparer,
+		"SELECT * FROM `users` WHERE `users`.`id` IN (?,?,?)",
+		[]any{3, 1, 2},
+		[]int{1, 3},
+	)
+
+	result, err := GetEntitiesByIDs(
+		context.Background(),
+		mockPreparer,
+		byIDsEntityHelpers(),
+		"id",
+		[]int{3, 1, 2, 3},
+		testEntityID,
+		0,
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		[]TestEntity{{ID: 3}, {ID: 1}, {ID: 3}},
+		result.Entities,
+	)
+	assert.Equal(t, []int{2}, result.Missing)
+	mockPreparer.AssertExpectations(t)
+}
+
+// TestGetEntitiesByIDs_Chunked tests that the IDs are queried in chunks.
+func TestGetEntitiesByIDs_Chunked(t *testing.T) {
+	mockPreparer := new(utilmock.MockDB)
+	expectByIDsQuery(
+		mockPreparer,
+		"SELECT * FROM `users` WHERE `users`.`id` IN (?,?)",
+		[]any{1, 2},
+		[]int{1, 2},
+	)
+	expectByIDsQuery(
+		mockPreparer,
+		"SELECT * FROM `users` WHERE `users`.`id` IN (?)",
+		[]any{3},
+		[]int{3},
+	)
+
+	result, err := GetEntitiesByIDs(
+		context.Background(),
+		mockPreparer,
+		byIDsEntityHelpers(),
+		"id",
+		[]int{1, 2, 3},
+		testEntityID,
+		2,
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		[]TestEntity{{ID: 1}, {ID: 2}, {ID: 3}},
+		result.Entities,
+	)
+	assert.Empty(t, result.Missing)
+	mockPreparer.AssertExpectations(t)
+}
+
+// TestGetEntitiesByIDs_Error tests that a query error is returned.
+func TestGetEntitiesByIDs_Error(t *testing.T) {
+	mockPreparer := new(utilmock.MockDB)
+	mockPreparer.On("Prepare", mock.Anything).
+		Return(nil, errors.New("query error"))
+
+	result, err := GetEntitiesByIDs(
+		context.Background(),
+		mockPreparer,
+		byIDsEntityHelpers(),
+		"id",
+		[]int{1},
+		testEntityID,
+		0,
+	)
+
+	assert.EqualError(t, err, "query error")
+	assert.Nil(t, result)
+}
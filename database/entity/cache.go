@@ -0,0 +1,154 @@
+package entity
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+
+	"github.com/pakkasys/fluidapi/database/cache"
+	"github.com/pakkasys/fluidapi/database/transaction"
+	"github.com/pakkasys/fluidapi/database/util"
+)
+
+// cachedRead serves the read from the entity cache if the result of the same
+// read is cached. Otherwise, the read is run and its result cached. Locking
+// reads, reads inside a transaction and reads without a cache are always run.
+// Cache errors fall back to running the read.
+//
+// The results are copied through JSON, so only their exported fields without
+// a `json:"-"` tag are cached.
+//
+//   - ctx: The context of the operation.
+//   - e: The entity helpers holding the cache.
+//   - preparer: The preparer of the read.
+//   - table: The table read.
+//   - query: The query parameters identifying the read.
+//   - joins: The joins of the read, whose tables also invalidate the result.
+//   - readFn: The read to run.
+func cachedRead[T any, R any](
+	ctx context.Context,
+	e *EntityHelpers[T],
+	preparer util.Preparer,
+	table string,
+	query any,
+	joins []util.Join,
+	readFn func() (*R, error),
+) (*R, error) {
+	// Reads inside a transaction may see uncommitted changes.
+	if _, isTx := preparer.(util.Tx); e.Cache == nil || isTx {
+		return readFn()
+	}
+
+	query, err := withJoinGenerations(ctx, e.Cache, query, joins)
+	if err != nil {
+		return readFn()
+	}
+	key, err := cacheKey(query)
+	if err != nil {
+		return readFn()
+	}
+
+	// The generation is read first, so that the result of a read racing an
+	// invalidation is not cached.
+	generation, err := e.Cache.Generation(ctx, table)
+	if err != nil {
+		return readFn()
+	}
+
+	data, ok, err := e.Cache.Get(ctx, table, key)
+	if err == nil && ok {
+		var cached R
+		if err := json.Unmarshal(data, &cached); err == nil {
+			return &cached, nil
+		}
+	}
+
+	result, err := readFn()
+	if err != nil || result == nil {
+		return result, err
+	}
+	if data, err := json.Marshal(result); err == nil {
+		_ = e.Cache.Set(ctx, table, generation, key, data, e.CacheTTL)
+	}
+	return result, nil
+}
+
+// invalidateCache invalidates the cached reads and counts of the table of
+// the tenant. Inside a transaction, the caches are invalidated after the
+// transaction commits, so that reads outside the transaction do not cache the
+// rows from before the commit. A transaction without an after-commit scope
+// invalidates the caches immediately. The entity loaders of the context are
+// cleared immediately, since they are only used within the request.
+func (e *EntityHelpers[T]) invalidateCache(
+	ctx context.Context,
+	preparer util.Preparer,
+	tenant *Tenant,
+) error {
+	clearLoaders(ctx, e.TableName)
+
+	tableName := tenant.TableName(e.TableName)
+	if _, isTx := preparer.(util.Tx); isTx {
+		err := transaction.RegisterAfterCommit(
+			ctx,
+			func(ctx context.Context) {
+				_ = e.invalidateTable(ctx, tableName)
+			},
+		)
+		if err == nil {
+			return nil
+		}
+	}
+	return e.invalidateTable(ctx, tableName)
+}
+
+func (e *EntityHelpers[T]) invalidateTable(
+	ctx context.Context,
+	tableName string,
+) error {
+	if e.Cache != nil {
+		if err := e.Cache.Invalidate(ctx, tableName); err != nil {
+			return err
+		}
+	}
+	if e.CountCache != nil {
+		return e.CountCache.Cache.Invalidate(ctx, tableName)
+	}
+	return nil
+}
+
+// withJoinGenerations adds the generations of the joined tables to the query
+// identifying a cached read or count. Invalidating a joined table changes
+// its generation and so the cache key, and the results cached before are no
+// longer returned.
+func withJoinGenerations(
+	ctx context.Context,
+	c cache.Cache,
+	query any,
+	joins []util.Join,
+) (any, error) {
+	if len(joins) == 0 {
+		return query, nil
+	}
+	generations := make(map[string]int64, len(joins))
+	for _, join := range joins {
+		generation, err := c.Generation(ctx, join.Table)
+		if err != nil {
+			return nil, err
+		}
+		generations[join.Table] = generation
+	}
+	return struct {
+		Query       any
+		Generations map[string]int64
+	}{Query: query, Generations: generations}, nil
+}
+
+func cacheKey(query any) (string, error) {
+	data, err := json.Marshal(query)
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:]), nil
+}
@@ -0,0 +1,291 @@
+package entity
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pakkasys/fluidapi/database/cache"
+	"github.com/pakkasys/fluidapi/database/transaction"
+	"github.com/pakkasys/fluidapi/database/util"
+	utilmock "github.com/pakkasys/fluidapi/database/util/mock"
+	endpointutil "github.com/pakkasys/fluidapi/endpoint/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func cachedEntityHelpers() *EntityHelpers[TestEntity] {
+	return &EntityHelpers[TestEntity]{
+		TableName: "users",
+		ScanRowFn: func(row util.Row, entity *TestEntity) error {
+			entity.ID = 1
+			entity.Name = "Alice"
+			return nil
+		},
+		Cache: cache.NewMemoryCache(),
+	}
+}
+
+func expectGetEntity(preparer *utilmock.MockDB) {
+	mockStmt := new(utilmock.MockStmt)
+	mockRow := new(utilmock.MockRow)
+	preparer.On("Prepare", mock.Anything).Return(mockStmt, nil).Once()
+	mockStmt.On("QueryRow", mock.Anything).Return(mockRow)
+	mockStmt.On("Close").Return(nil)
+	mockRow.On("Err").Return(nil)
+}
+
+func getOptionsByID(id int) GetOptions {
+	return GetOptions{
+		Options: Options{
+			Selectors: []util.Selector{
+				{Field: "id", Predicate: util.EQUAL, Value: id},
+			},
+		},
+	}
+}
+
+// TestGetEntity_CacheHit tests that a repeated get is served from the cache.
+func TestGetEntity_CacheHit(t *testing.T) {
+	mockPreparer := new(utilmock.MockDB)
+	entityHelpers := cachedEntityHelpers()
+	expectGetEntity(mockPreparer)
+
+	for range 2 {
+		entity, err := entityHelpers.GetEntity(
+			context.Background(),
+			mockPreparer,
+			getOptionsByID(1),
+		)
+		assert.NoError(t, err)
+		assert.Equal(t, &TestEntity{ID: 1, Name: "Alice"}, entity)
+	}
+
+	mockPreparer.AssertNumberOfCalls(t, "Prepare", 1)
+}
+
+// TestGetEntity_CacheLock tests that locking gets bypass the cache.
+func TestGetEntity_CacheLock(t *testing.T) {
+	mockPreparer := new(utilmock.MockDB)
+	entityHelpers := cachedEntityHelpers()
+	expectGetEntity(mockPreparer)
+	expectGetEntity(mockPreparer)
+
+	opts := getOptionsByID(1)
+	opts.Lock = true
+	for range 2 {
+		_, err := entityHelpers.GetEntity(
+			context.Background(),
+			mockPreparer,
+			opts,
+		)
+		assert.NoError(t, err)
+	}
+
+	mockPreparer.AssertNumberOfCalls(t, "Prepare", 2)
+}
+
+// TestGetEntity_CacheInvalidatedByUpdate tests that an update invalidates the
+// cached gets of the table.
+func TestGetEntity_CacheInvalidatedByUpdate(t *testing.T) {
+	mockPreparer := new(utilmock.MockDB)
+	mockUpdateStmt := new(utilmock.MockStmt)
+	mockResult := new(utilmock.MockResult)
+	entityHelpers := cachedEntityHelpers()
+
+	expectGetEntity(mockPreparer)
+	_, err := entityHelpers.GetEntity(
+		context.Background(),
+		mockPreparer,
+		getOptionsByID(1),
+	)
+	assert.NoError(t, err)
+
+	mockPreparer.On("Prepare", mock.Anything).Return(mockUpdateStmt, nil).Once()
+	mockUpdateStmt.On("Exec", mock.Anything).Return(mockResult, nil)
+	mockUpdateStmt.On("Close").Return(nil)
+	mockResult.On("RowsAffected").Return(int64(1), nil)
+	_, err = entityHelpers.UpdateEntities(
+		context.Background(),
+		mockPreparer,
+		getOptionsByID(1).Selectors,
+		Updates{{Field: "name", Value: "Bob"}},
+	)
+	assert.NoError(t, err)
+
+	expectGetEntity(mockPreparer)
+	_, err = entityHelpers.GetEntity(
+		context.Background(),
+		mockPreparer,
+		getOptionsByID(1),
+	)
+	assert.NoError(t, err)
+
+	mockPreparer.AssertNumberOfCalls(t, "Prepare", 3)
+}
+
+// TestGetEntity_CacheInvalidatedByJoinedTable tests that a write to a joined
+// table invalidates the cached gets joining it.
+func TestGetEntity_CacheInvalidatedByJoinedTable(t *testing.T) {
+	mockPreparer := new(utilmock.MockDB)
+	mockUpdateStmt := new(utilmock.MockStmt)
+	mockResult := new(utilmock.MockResult)
+	entityHelpers := cachedEntityHelpers()
+	postHelpers := &EntityHelpers[TestEntity]{
+		TableName: "posts",
+		Cache:     entityHelpers.Cache,
+	}
+	opts := getOptionsByID(1)
+	opts.Joins = []util.Join{
+		{
+			Type:    util.JoinTypeInner,
+			Table:   "posts",
+			OnLeft:  util.ColumSelector{Table: "users", Column: "id"},
+			OnRight: util.ColumSelector{Table: "posts", Column: "user_id"},
+		},
+	}
+
+	expectGetEntity(mockPreparer)
+	for range 2 {
+		_, err := entityHelpers.GetEntity(
+			context.Background(),
+			mockPreparer,
+			opts,
+		)
+		assert.NoError(t, err)
+	}
+	mockPreparer.AssertNumberOfCalls(t, "Prepare", 1)
+
+	mockPreparer.On("Prepare", mock.Anything).Return(mockUpdateStmt, nil).Once()
+	mockUpdateStmt.On("Exec", mock.Anything).Return(mockResult, nil)
+	mockUpdateStmt.On("Close").Return(nil)
+	mockResult.On("RowsAffected").Return(int64(1), nil)
+	_, err := postHelpers.UpdateEntities(
+		context.Background(),
+		mockPreparer,
+		getOptionsByID(1).Selectors,
+		Updates{{Field: "title", Value: "Hello"}},
+	)
+	assert.NoError(t, err)
+
+	expectGetEntity(mockPreparer)
+	_, err = entityHelpers.GetEntity(
+		context.Background(),
+		mockPreparer,
+		opts,
+	)
+	assert.NoError(t, err)
+
+	mockPreparer.AssertNumberOfCalls(t, "Prepare", 3)
+}
+
+// TestGetEntity_CacheTransaction tests that gets inside a transaction bypass
+// the cache.
+func TestGetEntity_CacheTransaction(t *testing.T) {
+	mockTx := new(utilmock.MockTx)
+	entityHelpers := cachedEntityHelpers()
+	expectGetEntity(&mockTx.MockDB)
+	expectGetEntity(&mockTx.MockDB)
+
+	for range 2 {
+		_, err := entityHelpers.GetEntity(
+			context.Background(),
+			mockTx,
+			getOptionsByID(1),
+		)
+		assert.NoError(t, err)
+	}
+
+	mockTx.AssertNumberOfCalls(t, "Prepare", 2)
+}
+
+// TestUpdateEntities_CacheInvalidatedAfterCommit tests that an update inside
+// a transaction invalidates the cache after the transaction commits.
+func TestUpdateEntities_CacheInvalidatedAfterCommit(t *testing.T) {
+	mockPreparer := new(utilmock.MockDB)
+	mockTx := new(utilmock.MockTx)
+	mockUpdateStmt := new(utilmock.MockStmt)
+	mockResult := new(utilmock.MockResult)
+	entityHelpers := cachedEntityHelpers()
+	ctx := endpointutil.NewContext(context.Background())
+
+	expectGetEntity(mockPreparer)
+	_, err := entityHelpers.GetEntity(ctx, mockPreparer, getOptionsByID(1))
+	assert.NoError(t, err)
+
+	mockTx.On("Prepare", mock.Anything).Return(mockUpdateStmt, nil).Once()
+	mockTx.On("Commit").Return(nil).Once()
+	mockUpdateStmt.On("Exec", mock.Anything).Return(mockResult, nil)
+	mockUpdateStmt.On("Close").Return(nil)
+	mockResult.On("RowsAffected").Return(int64(1), nil)
+	_, err = transaction.ExecuteManagedTransaction(
+		ctx,
+		func(ctx context.Context) (util.Tx, error) { return mockTx, nil },
+		func(ctx context.Context, tx util.Tx) (int64, error) {
+			count, err := entityHelpers.UpdateEntities(
+				ctx,
+				tx,
+				getOptionsByID(1).Selectors,
+				Updates{{Field: "name", Value: "Bob"}},
+			)
+			if err != nil {
+				return 0, err
+			}
+
+			// Not invalidated before the commit
+			_, err = entityHelpers.GetEntity(
+				ctx,
+				mockPreparer,
+				getOptionsByID(1),
+			)
+			assert.NoError(t, err)
+			mockPreparer.AssertNumberOfCalls(t, "Prepare", 1)
+			return count, err
+		},
+	)
+	assert.NoError(t, err)
+
+	expectGetEntity(mockPreparer)
+	_, err = entityHelpers.GetEntity(ctx, mockPreparer, getOptionsByID(1))
+	assert.NoError(t, err)
+
+	mockPreparer.AssertNumberOfCalls(t, "Prepare", 2)
+	mockTx.AssertExpectations(t)
+}
+
+// TestGetEntity_CacheJSONFields tests that only the JSON encoded fields of
+// the entities are restored from the cache.
+func TestGetEntity_CacheJSONFields(t *testing.T) {
+	type cachedEntity struct {
+		ID       int
+		Secret   string `json:"-"`
+		internal string
+	}
+	mockPreparer := new(utilmock.MockDB)
+	entityHelpers := &EntityHelpers[cachedEntity]{
+		TableName: "users",
+		ScanRowFn: func(row util.Row, entity *cachedEntity) error {
+			entity.ID = 1
+			entity.Secret = "secret"
+			entity.internal = "internal"
+			return nil
+		},
+		Cache: cache.NewMemoryCache(),
+	}
+	expectGetEntity(mockPreparer)
+
+	entity, err := entityHelpers.GetEntity(
+		context.Background(),
+		mockPreparer,
+		getOptionsByID(1),
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, &cachedEntity{1, "secret", "internal"}, entity)
+
+	entity, err = entityHelpers.GetEntity(
+		context.Background(),
+		mockPreparer,
+		getOptionsByID(1),
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, &cachedEntity{ID: 1}, entity)
+}
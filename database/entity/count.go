@@ -1,9 +1,6 @@
 package entity
 
 import (
-	"fmt"
-	"strings"
-
 	"github.com/pakkasys/fluidapi/database/util"
 )
 
@@ -43,14 +40,8 @@ func buildBaseCountQuery(
 	tableName string,
 	dbOptions *DBOptionsCount,
 ) (string, []any) {
-	whereClause, whereValues := whereClause(dbOptions.Selectors)
-
-	query := strings.Trim(fmt.Sprintf(
-		"SELECT COUNT(*) FROM `%s` %s %s",
-		tableName,
-		joinClause(dbOptions.Joins),
-		whereClause,
-	), " ")
-
-	return query, whereValues
+	return util.NewCount(tableName).
+		Join(dbOptions.Joins...).
+		Where(dbOptions.Selectors...).
+		Build()
 }
@@ -0,0 +1,176 @@
+package entity
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/pakkasys/fluidapi/database/cache"
+	"github.com/pakkasys/fluidapi/database/util"
+	endpointutil "github.com/pakkasys/fluidapi/endpoint/util"
+)
+
+type cachedCount struct {
+	Count     int       `json:"count"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// CountCache caches entity counts for a TTL. After the TTL, the stale count
+// is still served for up to StaleTTL while it is refreshed in the background.
+type CountCache struct {
+	// Cache stores the counts
+	Cache cache.Cache
+	// TTL is how long a count is fresh
+	TTL time.Duration
+	// StaleTTL is how long a count is served after it has expired while it is
+	// being refreshed
+	StaleTTL time.Duration
+
+	refreshing sync.Map
+	nowFn      func() time.Time
+}
+
+// NewCountCache creates a new count cache.
+//
+//   - cache: The cache storing the counts.
+//   - ttl: How long a count is fresh.
+//   - staleTTL: How long an expired count is served while it is refreshed.
+func NewCountCache(
+	cache cache.Cache,
+	ttl time.Duration,
+	staleTTL time.Duration,
+) *CountCache {
+	return &CountCache{
+		Cache:    cache,
+		TTL:      ttl,
+		StaleTTL: staleTTL,
+	}
+}
+
+// Count returns the cached count of the query, or counts and caches it. An
+// expired count within the stale period is returned immediately and
+// refreshed in the background with a context detached from the request.
+//
+//   - ctx: The context of the operation.
+//   - table: The table counted.
+//   - query: The query parameters identifying the count.
+//   - countFn: The function counting the entities.
+func (c *CountCache) Count(
+	ctx context.Context,
+	table string,
+	query any,
+	countFn func(ctx context.Context) (int, error),
+) (int, error) {
+	key, err := cacheKey(query)
+	if err != nil {
+		return countFn(ctx)
+	}
+
+	data, ok, err := c.Cache.Get(ctx, table, key)
+	if err == nil && ok {
+		var cached cachedCount
+		if err := json.Unmarshal(data, &cached); err == nil {
+			if c.now().After(cached.ExpiresAt) {
+				c.refresh(ctx, table, key, countFn)
+			}
+			return cached.Count, nil
+		}
+	}
+
+	return c.count(ctx, table, key, countFn)
+}
+
+func (c *CountCache) count(
+	ctx context.Context,
+	table string,
+	key string,
+	countFn func(ctx context.Context) (int, error),
+) (int, error) {
+	generation, err := c.Cache.Generation(ctx, table)
+	if err != nil {
+		return countFn(ctx)
+	}
+	count, err := countFn(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	data, err := json.Marshal(cachedCount{
+		Count:     count,
+		ExpiresAt: c.now().Add(c.TTL),
+	})
+	if err == nil {
+		_ = c.Cache.Set(
+			ctx,
+			table,
+			generation,
+			key,
+			data,
+			c.TTL+c.StaleTTL,
+		)
+	}
+	return count, nil
+}
+
+func (c *CountCache) refresh(
+	ctx context.Context,
+	table string,
+	key string,
+	countFn func(ctx context.Context) (int, error),
+) {
+	refreshKey := table + ":" + key
+	if _, running := c.refreshing.LoadOrStore(refreshKey, true); running {
+		return
+	}
+
+	// The request context data, e.g. its transaction, must not be shared
+	// with the background refresh.
+	refreshCtx := endpointutil.NewContext(context.WithoutCancel(ctx))
+	go func() {
+		defer c.refreshing.Delete(refreshKey)
+		_, _ = c.count(refreshCtx, table, key, countFn)
+	}()
+}
+
+func (c *CountCache) now() time.Time {
+	if c.nowFn != nil {
+		return c.nowFn()
+	}
+	return time.Now()
+}
+
+// countOptions returns the count options scoped to the tenant and the row
+// scope of the context.
+// cachedCount returns the count from the count cache. The generations of the
+// joined tables are part of the cache key, so that writes to the joined
+// tables invalidate the count.
+func (e *EntityHelpers[T]) cachedCount(
+	ctx context.Context,
+	tableName string,
+	countOpts *DBOptionsCount,
+	countFn func(ctx context.Context) (int, error),
+) (int, error) {
+	query, err := withJoinGenerations(
+		ctx,
+		e.CountCache.Cache,
+		countOpts,
+		countOpts.Joins,
+	)
+	if err != nil {
+		return countFn(ctx)
+	}
+	return e.CountCache.Count(ctx, tableName, query, countFn)
+}
+
+func (e *EntityHelpers[T]) countOptions(
+	ctx context.Context,
+	tenant *Tenant,
+	selectors []util.Selector,
+	joins []util.Join,
+) *DBOptionsCount {
+	return &DBOptionsCount{
+		Selectors: tenant.scopeSelectors(e.scopeRows(ctx, selectors)),
+		Joins:     tenant.scopeJoins(joins),
+	}
+}
@@ -0,0 +1,281 @@
+package entity
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pakkasys/fluidapi/database/cache"
+	"github.com/pakkasys/fluidapi/database/util"
+	utilmock "github.com/pakkasys/fluidapi/database/util/mock"
+	endpointutil "github.com/pakkasys/fluidapi/endpoint/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// TestCountCache_Fresh tests that a fresh count is served from the cache.
+func TestCountCache_Fresh(t *testing.T) {
+	countCache := NewCountCache(cache.NewMemoryCache(), time.Minute, 0)
+	calls := 0
+	countFn := func(ctx context.Context) (int, error) {
+		calls++
+		return 5, nil
+	}
+
+	for range 2 {
+		count, err := countCache.Count(
+			context.Background(),
+			"users",
+			"query",
+			countFn,
+		)
+		assert.NoError(t, err)
+		assert.Equal(t, 5, count)
+	}
+
+	assert.Equal(t, 1, calls)
+}
+
+// TestCountCache_StaleWhileRevalidate tests that an expired count is served
+// while it is refreshed in the background.
+func TestCountCache_StaleWhileRevalidate(t *testing.T) {
+	now := time.Unix(1000, 0)
+	countCache := NewCountCache(cache.NewMemoryCache(), time.Minute, time.Hour)
+	countCache.nowFn = func() time.Time { return now }
+
+	var calls atomic.Int32
+	refreshed := make(chan struct{})
+	countFn := func(ctx context.Context) (int, error) {
+		if calls.Add(1) == 2 {
+			defer close(refreshed)
+		}
+		return int(calls.Load()), nil
+	}
+
+	count, err := countCache.Count(context.Background(), "users", "q", countFn)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	now = now.Add(2 * time.Minute)
+	count, err = countCache.Count(context.Background(), "users", "q", countFn)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	select {
+	case <-refreshed:
+	case <-time.After(time.Second):
+		t.Fatal("count was not refreshed")
+	}
+	assert.Eventually(t, func() bool {
+		count, _ := countCache.Count(
+			context.Background(),
+			"users",
+			"q",
+			countFn,
+		)
+		return count == 2
+	}, time.Second, time.Millisecond)
+}
+
+// TestCountCache_Error tests that count errors are returned and not cached.
+func TestCountCache_Error(t *testing.T) {
+	countCache := NewCountCache(cache.NewMemoryCache(), time.Minute, 0)
+
+	_, err := countCache.Count(
+		context.Background(),
+		"users",
+		"query",
+		func(ctx context.Context) (int, error) {
+			return 0, errors.New("count error")
+		},
+	)
+
+	assert.EqualError(t, err, "count error")
+	_, ok, _ := countCache.Cache.Get(context.Background(), "users", "query")
+	assert.False(t, ok)
+}
+
+// TestGetEntityCount_CountCache tests that the entity helpers cache counts
+// of non-transactional reads.
+func TestGetEntityCount_CountCache(t *testing.T) {
+	mockPreparer := new(utilmock.MockDB)
+	mockStmt := new(utilmock.MockStmt)
+	mockRow := new(utilmock.MockRow)
+
+	entityHelpers := EntityHelpers[TestEntity]{
+		TableName: "users",
+		CountCache: NewCountCache(
+			cache.NewMemoryCache(),
+			time.Minute,
+			0,
+		),
+	}
+
+	mockPreparer.On("Prepare", mock.Anything).Return(mockStmt, nil)
+	mockStmt.On("QueryRow", mock.Anything).Return(mockRow)
+	mockStmt.On("Close").Return(nil)
+	mockRow.On("Scan", mock.Anything).Run(func(args mock.Arguments) {
+		*args.Get(0).([]any)[0].(*int) = 3
+	}).Return(nil)
+
+	selectors := []util.Selector{{Field: "age", Value: 20}}
+	for range 2 {
+		count, err := entityHelpers.GetEntityCount(
+			context.Background(),
+			mockPreparer,
+			selectors,
+			nil,
+		)
+		assert.NoError(t, err)
+		assert.Equal(t, 3, count)
+	}
+
+	mockPreparer.AssertNumberOfCalls(t, "Prepare", 1)
+}
+
+// TestGetEntityCount_CountCacheJoinedTable tests that invalidating a joined
+// table invalidates the cached counts joining it.
+func TestGetEntityCount_CountCacheJoinedTable(t *testing.T) {
+	mockPreparer := new(utilmock.MockDB)
+	mockStmt := new(utilmock.MockStmt)
+	mockRow := new(utilmock.MockRow)
+	memoryCache := cache.NewMemoryCache()
+
+	entityHelpers := EntityHelpers[TestEntity]{
+		TableName:  "users",
+		CountCache: NewCountCache(memoryCache, time.Minute, 0),
+	}
+
+	mockPreparer.On("Prepare", mock.Anything).Return(mockStmt, nil)
+	mockStmt.On("QueryRow", mock.Anything).Return(mockRow)
+	mockStmt.On("Close").Return(nil)
+	mockRow.On("Scan", mock.Anything).Run(func(args mock.Arguments) {
+		*args.Get(0).([]any)[0].(*int) = 3
+	}).Return(nil)
+
+	joins := []util.Join{
+		{
+			Type:    util.JoinTypeInner,
+			Table:   "posts",
+			OnLeft:  util.ColumSelector{Table: "users", Column: "id"},
+			OnRight: util.ColumSelector{Table: "posts", Column: "user_id"},
+		},
+	}
+	count := func() {
+		count, err := entityHelpers.GetEntityCount(
+			context.Background(),
+			mockPreparer,
+			nil,
+			joins,
+		)
+		assert.NoError(t, err)
+		assert.Equal(t, 3, count)
+	}
+
+	count()
+	count()
+	mockPreparer.AssertNumberOfCalls(t, "Prepare", 1)
+
+	assert.NoError(t, memoryCache.Invalidate(context.Background(), "posts"))
+	count()
+	mockPreparer.AssertNumberOfCalls(t, "Prepare", 2)
+}
+
+// TestGetEntityCount_CountCacheTransaction tests that counts in a transaction
+// are not cached.
+func TestGetEntityCount_CountCacheTransaction(t *testing.T) {
+	mockTx := new(utilmock.MockTx)
+	mockStmt := new(utilmock.MockStmt)
+	mockRow := new(utilmock.MockRow)
+
+	entityHelpers := EntityHelpers[TestEntity]{
+		TableName: "users",
+		CountCache: NewCountCache(
+			cache.NewMemoryCache(),
+			time.Minute,
+			0,
+		),
+	}
+
+	mockTx.On("Prepare", mock.Anything).Return(mockStmt, nil)
+	mockStmt.On("QueryRow", mock.Anything).Return(mockRow)
+	mockStmt.On("Close").Return(nil)
+	mockRow.On("Scan", mock.Anything).Return(nil)
+
+	for range 2 {
+		_, err := entityHelpers.GetEntityCount(
+			context.Background(),
+			mockTx,
+			nil,
+			nil,
+		)
+		assert.NoError(t, err)
+	}
+
+	mockTx.AssertNumberOfCalls(t, "Prepare", 2)
+}
+
+// TestGetEntityCountWithManagedTransaction_CountCacheRefreshScope tests that
+// the background refresh counts with the row scope of the request.
+func TestGetEntityCountWithManagedTransaction_CountCacheRefreshScope(
+	t *testing.T,
+) {
+	now := time.Unix(1000, 0)
+	countCache := NewCountCache(cache.NewMemoryCache(), time.Minute, time.Hour)
+	countCache.nowFn = func() time.Time { return now }
+
+	mockTx := new(utilmock.MockTx)
+	mockStmt := new(utilmock.MockStmt)
+	mockRow := new(utilmock.MockRow)
+
+	entityHelpers := EntityHelpers[TestEntity]{
+		TableName: "users",
+		RowScope: func(ctx context.Context) []util.Selector {
+			tenantID := endpointutil.GetContextValue(ctx, rowScopeKey{}, 0)
+			return []util.Selector{
+				{Field: "tenant_id", Predicate: util.EQUAL, Value: tenantID},
+			}
+		},
+		CountCache: countCache,
+		GetTxFn: func(ctx context.Context) (util.Tx, error) {
+			return mockTx, nil
+		},
+	}
+
+	var queryArgs [][]any
+	var mu sync.Mutex
+	mockTx.On("Prepare", mock.Anything).Return(mockStmt, nil)
+	mockTx.On("Commit").Return(nil)
+	mockStmt.On("QueryRow", mock.Anything).Run(func(args mock.Arguments) {
+		mu.Lock()
+		defer mu.Unlock()
+		queryArgs = append(queryArgs, args.Get(0).([]any))
+	}).Return(mockRow)
+	mockStmt.On("Close").Return(nil)
+	mockRow.On("Scan", mock.Anything).Return(nil)
+
+	ctx := endpointutil.SetContextValue(
+		endpointutil.NewContext(context.Background()),
+		rowScopeKey{},
+		7,
+	)
+	_, err := entityHelpers.GetEntityCountWithManagedTransaction(ctx, nil, nil)
+	assert.NoError(t, err)
+
+	now = now.Add(2 * time.Minute)
+	_, err = entityHelpers.GetEntityCountWithManagedTransaction(ctx, nil, nil)
+	assert.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(queryArgs) == 2
+	}, time.Second, time.Millisecond)
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []any{7}, queryArgs[0])
+	assert.Equal(t, []any{7}, queryArgs[1])
+}
@@ -93,8 +93,8 @@ func insertQuery[T any](
 	)
 
 	query := fmt.Sprintf(
-		"INSERT INTO `%s` (%s) VALUES (%s)",
-		tableName,
+		"INSERT INTO %s (%s) VALUES (%s)",
+		util.QuoteIdentifier(tableName),
 		columnNames,
 		valuePlaceholders,
 	)
@@ -149,8 +149,8 @@ func insertManyQuery[T any](
 	}
 
 	query := fmt.Sprintf(
-		"INSERT INTO `%s` (%s) VALUES %s",
-		tableName,
+		"INSERT INTO %s (%s) VALUES %s",
+		util.QuoteIdentifier(tableName),
 		columnNames,
 		strings.Join(valuePlaceholders, ", "),
 	)
@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/pakkasys/fluidapi/database/internal"
 	"github.com/pakkasys/fluidapi/database/util"
 )
 
@@ -46,7 +45,28 @@ func delete(
 	selectors []util.Selector,
 	opts *DeleteOptions,
 ) (sql.Result, error) {
-	whereColumns, whereValues := internal.ProcessSelectors(selectors)
+	query, whereValues := deleteQuery(tableName, selectors, opts)
+
+	statement, err := preparer.Prepare(query)
+	if err != nil {
+		return nil, err
+	}
+	defer statement.Close()
+
+	res, err := statement.Exec(whereValues...)
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func deleteQuery(
+	tableName string,
+	selectors []util.Selector,
+	opts *DeleteOptions,
+) (string, []any) {
+	whereColumns, whereValues := util.ProcessSelectors(selectors)
 
 	whereClause := ""
 	if len(whereColumns) > 0 {
@@ -55,25 +75,18 @@ func delete(
 
 	builder := strings.Builder{}
 	builder.WriteString(
-		fmt.Sprintf("DELETE FROM `%s` %s", tableName, whereClause),
+		fmt.Sprintf(
+			"DELETE FROM %s %s",
+			util.QuoteIdentifier(tableName),
+			whereClause,
+		),
 	)
 
 	if opts != nil {
 		writeDeleteOptions(&builder, opts)
 	}
 
-	statement, err := preparer.Prepare(builder.String())
-	if err != nil {
-		return nil, err
-	}
-	defer statement.Close()
-
-	res, err := statement.Exec(whereValues...)
-	if err != nil {
-		return nil, err
-	}
-
-	return res, nil
+	return builder.String(), whereValues
 }
 
 func writeDeleteOptions(
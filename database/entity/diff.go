@@ -0,0 +1,115 @@
+package entity
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// dbTag is the struct tag naming the column of a field.
+const dbTag = "db"
+
+// Diff compares two entities and returns the updates of the columns whose
+// values differ, in the column order of the mapper. The inserter of the
+// entity can be used as the mapper.
+//
+//   - old: The current entity.
+//   - new: The changed entity.
+//   - mapper: The function returning the columns and values of an entity.
+func Diff[T any](old *T, new *T, mapper Inserter[*T]) Updates {
+	oldColumns, oldValues := mapper(old)
+	oldByColumn := make(map[string]any, len(oldColumns))
+	for i, column := range oldColumns {
+		oldByColumn[column] = oldValues[i]
+	}
+
+	newColumns, newValues := mapper(new)
+	return diffColumns(oldByColumn, newColumns, newValues)
+}
+
+// DiffTagged compares two struct entities using their fields tagged with
+// `db:"column"` and returns the updates of the columns whose values differ,
+// in field order. Fields without the tag or tagged with `db:"-"` are ignored.
+//
+//   - old: The current entity.
+//   - new: The changed entity.
+func DiffTagged[T any](old *T, new *T) (Updates, error) {
+	oldColumns, oldValues, err := taggedColumns(old)
+	if err != nil {
+		return nil, err
+	}
+	oldByColumn := make(map[string]any, len(oldColumns))
+	for i, column := range oldColumns {
+		oldByColumn[column] = oldValues[i]
+	}
+
+	newColumns, newValues, err := taggedColumns(new)
+	if err != nil {
+		return nil, err
+	}
+	return diffColumns(oldByColumn, newColumns, newValues), nil
+}
+
+func diffColumns(
+	old map[string]any,
+	columns []string,
+	values []any,
+) Updates {
+	updates := Updates{}
+	for i, column := range columns {
+		oldValue, ok := old[column]
+		if ok && valuesEqual(oldValue, values[i]) {
+			continue
+		}
+		updates = append(updates, Update{Field: column, Value: values[i]})
+	}
+	return updates
+}
+
+func taggedColumns(entity any) ([]string, []any, error) {
+	value := reflect.ValueOf(entity)
+	if value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return nil, nil, fmt.Errorf("entity must not be nil")
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return nil, nil, fmt.Errorf(
+			"entity must be a struct, got %s",
+			value.Kind(),
+		)
+	}
+
+	var columns []string
+	var values []any
+	valueType := value.Type()
+	for i := 0; i < valueType.NumField(); i++ {
+		field := valueType.Field(i)
+		column := strings.Split(field.Tag.Get(dbTag), ",")[0]
+		if !field.IsExported() || column == "" || column == "-" {
+			continue
+		}
+		columns = append(columns, column)
+		values = append(values, value.Field(i).Interface())
+	}
+	return columns, values, nil
+}
+
+// valuesEqual compares column values. Times are compared by instant so that
+// differing locations and monotonic readings are not reported as changes.
+func valuesEqual(a any, b any) bool {
+	if aTime, ok := a.(time.Time); ok {
+		bTime, ok := b.(time.Time)
+		return ok && aTime.Equal(bTime)
+	}
+	if aTime, ok := a.(*time.Time); ok {
+		bTime, ok := b.(*time.Time)
+		if !ok || aTime == nil || bTime == nil {
+			return ok && aTime == bTime
+		}
+		return aTime.Equal(*bTime)
+	}
+	return reflect.DeepEqual(a, b)
+}
@@ -0,0 +1,127 @@
+package entity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type taggedEntity struct {
+	ID        int        `db:"id"`
+	Name      string     `db:"name"`
+	Tags      []string   `db:"tags"`
+	DeletedAt *time.Time `db:"deleted_at,omitempty"`
+	Ignored   string     `db:"-"`
+	Untagged  string
+	hidden    string `db:"hidden"`
+}
+
+// TestDiff tests that only the changed columns are returned.
+func TestDiff(t *testing.T) {
+	mapper := func(e *TestEntity) ([]string, []any) {
+		return []string{"id", "name", "age"}, []any{e.ID, e.Name, e.Age}
+	}
+
+	updates := Diff(
+		&TestEntity{ID: 1, Name: "a", Age: 1},
+		&TestEntity{ID: 1, Name: "b", Age: 2},
+		mapper,
+	)
+
+	assert.Equal(t, Updates{
+		{Field: "name", Value: "b"},
+		{Field: "age", Value: 2},
+	}, updates)
+}
+
+// TestDiff_NoChanges tests that equal entities produce no updates.
+func TestDiff_NoChanges(t *testing.T) {
+	mapper := func(e *TestEntity) ([]string, []any) {
+		return []string{"name"}, []any{e.Name}
+	}
+
+	updates := Diff(&TestEntity{Name: "a"}, &TestEntity{Name: "a"}, mapper)
+
+	assert.Equal(t, Updates{}, updates)
+}
+
+// TestDiff_NewColumn tests that columns missing from the old entity are
+// reported as changed.
+func TestDiff_NewColumn(t *testing.T) {
+	mapper := func(e *TestEntity) ([]string, []any) {
+		if e.Age == 0 {
+			return []string{"name"}, []any{e.Name}
+		}
+		return []string{"name", "age"}, []any{e.Name, e.Age}
+	}
+
+	updates := Diff(
+		&TestEntity{Name: "a"},
+		&TestEntity{Name: "a", Age: 3},
+		mapper,
+	)
+
+	assert.Equal(t, Updates{{Field: "age", Value: 3}}, updates)
+}
+
+// TestDiffTagged tests comparing the tagged fields of entities.
+func TestDiffTagged(t *testing.T) {
+	deletedAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	sameInstant := deletedAt.In(time.FixedZone("X", 3600))
+
+	updates, err := DiffTagged(
+		&taggedEntity{
+			ID:        1,
+			Name:      "a",
+			Tags:      []string{"x"},
+			DeletedAt: &deletedAt,
+			Ignored:   "a",
+			Untagged:  "a",
+			hidden:    "a",
+		},
+		&taggedEntity{
+			ID:        1,
+			Name:      "a",
+			Tags:      []string{"x", "y"},
+			DeletedAt: &sameInstant,
+			Ignored:   "b",
+			Untagged:  "b",
+			hidden:    "b",
+		},
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		Updates{{Field: "tags", Value: []string{"x", "y"}}},
+		updates,
+	)
+}
+
+// TestDiffTagged_NilPointer tests that clearing a pointer is reported.
+func TestDiffTagged_NilPointer(t *testing.T) {
+	deletedAt := time.Now()
+
+	updates, err := DiffTagged(
+		&taggedEntity{DeletedAt: &deletedAt},
+		&taggedEntity{},
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		Updates{{Field: "deleted_at", Value: (*time.Time)(nil)}},
+		updates,
+	)
+}
+
+// TestDiffTagged_Errors tests the errors of invalid entities.
+func TestDiffTagged_Errors(t *testing.T) {
+	_, err := DiffTagged[taggedEntity](nil, &taggedEntity{})
+	assert.EqualError(t, err, "entity must not be nil")
+
+	value := 1
+	_, err = DiffTagged(&value, &value)
+	assert.EqualError(t, err, "entity must be a struct, got int")
+}
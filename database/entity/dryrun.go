@@ -0,0 +1,244 @@
+package entity
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pakkasys/fluidapi/database/util"
+)
+
+// Query is a generated SQL query with its bound arguments.
+type Query struct {
+	SQL  string
+	Args []any
+}
+
+// BuildCreateEntity returns the query CreateEntity would run without
+// executing it. The ID generator and the timestamp handler are applied to a
+// copy of the entity.
+//
+//   - ctx: The context of the operation.
+//   - object: The entity to create or upsert.
+//   - opts: The options struct for upserting the entity.
+func (e *EntityHelpers[T]) BuildCreateEntity(
+	ctx context.Context,
+	object *T,
+	opts *UpsertOptions,
+) (*Query, error) {
+	tenant, err := e.tenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	copied := *object
+	if err := e.generateIDs(&copied); err != nil {
+		return nil, err
+	}
+	e.TimestampHandler.onCreate(&copied)
+	opts = e.TimestampHandler.onUpsert(opts)
+
+	tableName := tenant.TableName(e.TableName)
+	if opts != nil && opts.Mode != InsertModeUpsert {
+		return buildInsertWithModeQuery(
+			[]*T{&copied},
+			tableName,
+			e.InserterFn,
+			opts.Mode,
+		)
+	}
+	if opts != nil {
+		return buildUpsertQuery(
+			[]*T{&copied},
+			tableName,
+			e.InserterFn,
+			opts.UpdateProjection,
+			opts.UpdateExpressions,
+		)
+	}
+	query, args := insertQuery(&copied, tableName, e.InserterFn)
+	return &Query{SQL: query, Args: args}, nil
+}
+
+// BuildCreateEntities returns the query CreateEntities would run without
+// executing it. The ID generator and the timestamp handler are applied to
+// copies of the entities.
+//
+//   - ctx: The context of the operation.
+//   - entities: The entities to create or upsert.
+//   - opts: The options struct for upserting the entities.
+func (e *EntityHelpers[T]) BuildCreateEntities(
+	ctx context.Context,
+	entities []*T,
+	opts *UpsertOptions,
+) (*Query, error) {
+	tenant, err := e.tenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	copies := make([]*T, len(entities))
+	for i, entity := range entities {
+		copied := *entity
+		copies[i] = &copied
+	}
+	if err := e.generateIDs(copies...); err != nil {
+		return nil, err
+	}
+	e.TimestampHandler.onCreate(copies...)
+	opts = e.TimestampHandler.onUpsert(opts)
+
+	tableName := tenant.TableName(e.TableName)
+	if opts != nil && opts.Mode != InsertModeUpsert {
+		return buildInsertWithModeQuery(
+			copies,
+			tableName,
+			e.InserterFn,
+			opts.Mode,
+		)
+	}
+	if opts != nil {
+		return buildUpsertQuery(
+			copies,
+			tableName,
+			e.InserterFn,
+			opts.UpdateProjection,
+			opts.UpdateExpressions,
+		)
+	}
+	query, args := insertManyQuery(copies, tableName, e.InserterFn)
+	return &Query{SQL: query, Args: args}, nil
+}
+
+// BuildGetEntities returns the query GetEntity and GetEntities would run
+// without executing it. Preloaded relations are not included.
+//
+//   - ctx: The context of the operation.
+//   - opts: The options struct for getting the entities.
+func (e *EntityHelpers[T]) BuildGetEntities(
+	ctx context.Context,
+	opts GetOptions,
+) (*Query, error) {
+	tenant, err := e.tenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+	opts.Selectors = e.scopeRows(ctx, opts.Selectors)
+	opts.Options = tenant.scopeOptions(opts.Options)
+
+	query, args := buildBaseGetQuery(tenant.TableName(e.TableName), &opts)
+	return &Query{SQL: query, Args: args}, nil
+}
+
+// BuildGetEntityCount returns the query GetEntityCount would run without
+// executing it.
+//
+//   - ctx: The context of the operation.
+//   - selectors: The selectors for the query.
+//   - joins: The joins for the query.
+func (e *EntityHelpers[T]) BuildGetEntityCount(
+	ctx context.Context,
+	selectors []util.Selector,
+	joins []util.Join,
+) (*Query, error) {
+	tenant, err := e.tenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query, args := buildBaseCountQuery(
+		tenant.TableName(e.TableName),
+		e.countOptions(ctx, tenant, selectors, joins),
+	)
+	return &Query{SQL: query, Args: args}, nil
+}
+
+// BuildUpdateEntities returns the query UpdateEntities would run without
+// executing it.
+//
+//   - ctx: The context of the operation.
+//   - selectors: The selectors for the query.
+//   - updates: The update options for the query.
+func (e *EntityHelpers[T]) BuildUpdateEntities(
+	ctx context.Context,
+	selectors []util.Selector,
+	updates Updates,
+) (*Query, error) {
+	tenant, err := e.tenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query, args := updateQuery(
+		tenant.TableName(e.TableName),
+		e.handleUpdates(updates[:len(updates):len(updates)]),
+		tenant.scopeSelectors(e.scopeRows(ctx, selectors)),
+	)
+	return &Query{SQL: query, Args: args}, nil
+}
+
+// BuildDeleteEntities returns the query DeleteEntities would run without
+// executing it.
+//
+//   - ctx: The context of the operation.
+//   - selectors: The selectors for the query.
+//   - opts: The options struct for deleting the entities.
+func (e *EntityHelpers[T]) BuildDeleteEntities(
+	ctx context.Context,
+	selectors []util.Selector,
+	opts *DeleteOptions,
+) (*Query, error) {
+	tenant, err := e.tenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query, args := deleteQuery(
+		tenant.TableName(e.TableName),
+		tenant.scopeSelectors(e.scopeRows(ctx, selectors)),
+		tenant.scopeDeleteOptions(opts),
+	)
+	return &Query{SQL: query, Args: args}, nil
+}
+
+func buildUpsertQuery[T any](
+	entities []*T,
+	tableName string,
+	inserter Inserter[*T],
+	updateProjections []util.Projection,
+	updateExpressions []UpdateExpression,
+) (*Query, error) {
+	if len(entities) == 0 {
+		return nil, fmt.Errorf("must provide entities to upsert")
+	}
+	err := validateUpsertUpdates(updateProjections, updateExpressions)
+	if err != nil {
+		return nil, err
+	}
+
+	query, args := upsertManyQuery(
+		entities,
+		tableName,
+		inserter,
+		updateProjections,
+		updateExpressions...,
+	)
+	return &Query{SQL: query, Args: args}, nil
+}
+
+func buildInsertWithModeQuery[T any](
+	entities []*T,
+	tableName string,
+	inserter Inserter[*T],
+	mode InsertMode,
+) (*Query, error) {
+	query, args, err := insertWithModeQuery(
+		entities,
+		tableName,
+		inserter,
+		mode,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &Query{SQL: query, Args: args}, nil
+}
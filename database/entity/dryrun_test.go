@@ -0,0 +1,223 @@
+package entity
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/pakkasys/fluidapi/database/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func dryRunEntityHelpers() *EntityHelpers[TestEntity] {
+	return &EntityHelpers[TestEntity]{
+		TableName: "users",
+		InserterFn: func(entity *TestEntity) ([]string, []any) {
+			return []string{"id", "name"}, []any{entity.ID, entity.Name}
+		},
+		RowScope: testRowScope,
+	}
+}
+
+func dryRunContext() context.Context {
+	return context.WithValue(context.Background(), rowScopeKey{}, 7)
+}
+
+// TestBuildCreateEntity tests the generated insert query.
+func TestBuildCreateEntity(t *testing.T) {
+	query, err := dryRunEntityHelpers().BuildCreateEntity(
+		context.Background(),
+		&TestEntity{ID: 1, Name: "a"},
+		nil,
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		&Query{
+			SQL:  "INSERT INTO `users` (`id`, `name`) VALUES (?, ?)",
+			Args: []any{1, "a"},
+		},
+		query,
+	)
+}
+
+// TestBuildCreateEntity_DoesNotModifyEntity tests that the ID generator is
+// applied to a copy of the entity.
+func TestBuildCreateEntity_DoesNotModifyEntity(t *testing.T) {
+	entityHelpers := dryRunEntityHelpers()
+	entityHelpers.IDGenerator = func(entity *TestEntity) error {
+		entity.ID = 9
+		return nil
+	}
+	entity := &TestEntity{Name: "a"}
+
+	query, err := entityHelpers.BuildCreateEntity(
+		context.Background(),
+		entity,
+		nil,
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []any{9, "a"}, query.Args)
+	assert.Equal(t, 0, entity.ID)
+}
+
+// TestBuildCreateEntities_Upsert tests the generated upsert query.
+func TestBuildCreateEntities_Upsert(t *testing.T) {
+	query, err := dryRunEntityHelpers().BuildCreateEntities(
+		context.Background(),
+		[]*TestEntity{{ID: 1, Name: "a"}, {ID: 2, Name: "b"}},
+		&UpsertOptions{
+			UpdateProjection: []util.Projection{
+				{Column: "name", Alias: "new"},
+			},
+		},
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		&Query{
+			SQL: "INSERT INTO `users` (`id`, `name`) VALUES (?, ?), " +
+				"(?, ?) ON DUPLICATE KEY UPDATE `name` = VALUES(`name`)",
+			Args: []any{1, "a", 2, "b"},
+		},
+		query,
+	)
+}
+
+// TestBuildCreateEntities_UpsertWithoutProjections tests that an upsert
+// without projections returns an error.
+func TestBuildCreateEntities_UpsertWithoutProjections(t *testing.T) {
+	_, err := dryRunEntityHelpers().BuildCreateEntities(
+		context.Background(),
+		[]*TestEntity{{ID: 1}},
+		&UpsertOptions{},
+	)
+
+	assert.EqualError(t, err, "must provide update projections")
+}
+
+// TestBuildGetEntities tests that the generated select query includes the
+// row scope and the tenant.
+func TestBuildGetEntities(t *testing.T) {
+	entityHelpers := dryRunEntityHelpers()
+	entityHelpers.TenantResolver = func(ctx context.Context) (*Tenant, error) {
+		return &Tenant{TablePrefix: "t_"}, nil
+	}
+
+	query, err := entityHelpers.BuildGetEntities(
+		dryRunContext(),
+		GetOptions{
+			Options: Options{
+				Selectors: []util.Selector{
+					{
+						Table:     "users",
+						Field:     "name",
+						Predicate: util.EQUAL,
+						Value:     "a",
+					},
+				},
+			},
+			Lock: true,
+		},
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		&Query{
+			SQL: "SELECT * FROM `t_users` WHERE `t_users`.`name` = ? " +
+				"AND `t_users`.`tenant_id` = ? FOR UPDATE",
+			Args: []any{"a", 7},
+		},
+		query,
+	)
+}
+
+// TestBuildGetEntities_TenantError tests that a tenant resolver error is
+// returned.
+func TestBuildGetEntities_TenantError(t *testing.T) {
+	entityHelpers := dryRunEntityHelpers()
+	entityHelpers.TenantResolver = func(ctx context.Context) (*Tenant, error) {
+		return nil, errors.New("tenant error")
+	}
+
+	_, err := entityHelpers.BuildGetEntities(
+		context.Background(),
+		GetOptions{},
+	)
+
+	assert.EqualError(t, err, "tenant error")
+}
+
+// TestBuildGetEntityCount tests the generated count query.
+func TestBuildGetEntityCount(t *testing.T) {
+	query, err := dryRunEntityHelpers().BuildGetEntityCount(
+		dryRunContext(),
+		nil,
+		nil,
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		&Query{
+			SQL:  "SELECT COUNT(*) FROM `users`  WHERE `users`.`tenant_id` = ?",
+			Args: []any{7},
+		},
+		query,
+	)
+}
+
+// TestBuildUpdateEntities tests that the generated update query includes the
+// update timestamp without modifying the updates.
+func TestBuildUpdateEntities(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	entityHelpers := dryRunEntityHelpers()
+	entityHelpers.TimestampHandler = &TimestampHandler[TestEntity]{
+		UpdatedField: "updated_at",
+		NowFn:        func() time.Time { return now },
+	}
+	updates := make(Updates, 1, 2)
+	updates[0] = Update{Field: "name", Value: "b"}
+
+	query, err := entityHelpers.BuildUpdateEntities(
+		dryRunContext(),
+		nil,
+		updates,
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		&Query{
+			SQL: "UPDATE `users` SET name = ?, updated_at = ? " +
+				"WHERE `users`.`tenant_id` = ?",
+			Args: []any{"b", now, 7},
+		},
+		query,
+	)
+	assert.Equal(t, Update{}, updates[:2][1])
+}
+
+// TestBuildDeleteEntities tests the generated delete query.
+func TestBuildDeleteEntities(t *testing.T) {
+	query, err := dryRunEntityHelpers().BuildDeleteEntities(
+		dryRunContext(),
+		nil,
+		&DeleteOptions{Limit: 5},
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		&Query{
+			SQL:  "DELETE FROM `users` WHERE `users`.`tenant_id` = ? LIMIT 5",
+			Args: []any{7},
+		},
+		query,
+	)
+}
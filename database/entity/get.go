@@ -2,20 +2,25 @@ package entity
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
-	"strings"
 
-	"github.com/pakkasys/fluidapi/database/internal"
 	util "github.com/pakkasys/fluidapi/database/util"
 	"github.com/pakkasys/fluidapi/endpoint/page"
 )
 
+// ErrEntityNotFound is returned by the entity helpers and loaders for missing
+// entities when no EntityNotFoundFn is set.
+var ErrEntityNotFound = errors.New("entity not found")
+
 type RowScanner[T any] func(row util.Row, entity *T) error
 type RowScannerMultiple[T any] func(rows util.Rows, entity *T) error
 
 type GetOptions struct {
 	Options
 	Lock bool
+	// Preload contains the names of the relations to load for the entities
+	Preload []string
 }
 
 func GetEntity[T any](
@@ -154,62 +159,30 @@ func projectionsToStrings(projections []util.Projection) []string {
 }
 
 func joinClause(joins []util.Join) string {
-	var joinClause string
-	for _, join := range joins {
-		if joinClause != "" {
-			joinClause += " "
-		}
-		joinClause += fmt.Sprintf(
-			"%s JOIN `%s` ON %s = %s",
-			join.Type,
-			join.Table,
-			join.OnLeft.String(),
-			join.OnRight.String(),
-		)
-	}
-	return joinClause
+	return util.JoinClause(joins)
 }
 
 func whereClause(selectors []util.Selector) (string, []any) {
-	whereColumns, whereValues := internal.ProcessSelectors(selectors)
-
-	var whereClause string
-	if len(whereColumns) > 0 {
-		whereClause = "WHERE " + strings.Join(whereColumns, " AND ")
-	}
-
-	return strings.Trim(whereClause, " "), whereValues
+	return util.WhereClause(selectors)
 }
 
 func buildBaseGetQuery(
 	tableName string,
 	dbOptions *GetOptions,
 ) (string, []any) {
-	whereClause, whereValues := whereClause(dbOptions.Selectors)
-
-	builder := strings.Builder{}
-	builder.WriteString(fmt.Sprintf(
-		"SELECT %s",
-		strings.Join(projectionsToStrings(dbOptions.Projections), ","),
-	))
-	builder.WriteString(fmt.Sprintf(" FROM `%s`", tableName))
-	if len(dbOptions.Joins) != 0 {
-		builder.WriteString(" " + joinClause(dbOptions.Joins))
-	}
-	if whereClause != "" {
-		builder.WriteString(" " + whereClause)
-	}
-	if len(dbOptions.Orders) != 0 {
-		builder.WriteString(" " + getOrderClauseFromOrders(dbOptions.Orders))
-	}
+	builder := util.NewSelect(tableName).
+		Columns(dbOptions.Projections...).
+		Join(dbOptions.Joins...).
+		Where(dbOptions.Selectors...).
+		OrderBy(dbOptions.Orders...)
 	if dbOptions.Page != nil {
-		builder.WriteString(" " + getLimitOffsetClauseFromPage(dbOptions.Page))
+		builder.Limit(dbOptions.Page.Limit, dbOptions.Page.Offset)
 	}
 	if dbOptions.Lock {
-		builder.WriteString(" FOR UPDATE")
+		builder.ForUpdate()
 	}
 
-	return builder.String(), whereValues
+	return builder.Build()
 }
 
 func getLimitOffsetClauseFromPage(page *page.Page) string {
@@ -225,29 +198,7 @@ func getLimitOffsetClauseFromPage(page *page.Page) string {
 }
 
 func getOrderClauseFromOrders(orders []util.Order) string {
-	if len(orders) == 0 {
-		return ""
-	}
-
-	orderClause := "ORDER BY"
-	for _, readOrder := range orders {
-		if readOrder.Table == "" {
-			orderClause += fmt.Sprintf(
-				" `%s` %s,",
-				readOrder.Field,
-				readOrder.Direction,
-			)
-		} else {
-			orderClause += fmt.Sprintf(
-				" `%s`.`%s` %s,",
-				readOrder.Table,
-				readOrder.Field,
-				readOrder.Direction,
-			)
-		}
-	}
-
-	return strings.TrimSuffix(orderClause, ",")
+	return util.OrderClause(orders)
 }
 
 func rowsToEntities[T any](
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/pakkasys/fluidapi/database/util"
+	"github.com/pakkasys/fluidapi/database/util/fake"
 	utilmock "github.com/pakkasys/fluidapi/database/util/mock"
 	"github.com/pakkasys/fluidapi/endpoint/page"
 	"github.com/stretchr/testify/assert"
@@ -1202,3 +1203,54 @@ func TestRowsToEntities_RowsError(t *testing.T) {
 	mockRows.AssertExpectations(t)
 	mockRowScanner.AssertExpectations(t)
 }
+
+// TestGetEntities_ProjectionsScanNamed tests that a scanner reading the
+// columns by name reads the right values of a projected query, unlike a
+// positional scanner.
+func TestGetEntities_ProjectionsScanNamed(t *testing.T) {
+	db := fake.NewDB()
+	db.CreateTable("users", fake.TableOptions{
+		Columns:    []string{"id", "name", "age"},
+		PrimaryKey: "id",
+	})
+	_, err := db.Exec(
+		"INSERT INTO `users` (`name`, `age`) VALUES (?, ?)",
+		"alice",
+		30,
+	)
+	assert.NoError(t, err)
+	options := &GetOptions{
+		Options: Options{
+			Projections: []util.Projection{
+				{Table: "users", Column: "age"},
+				{Table: "users", Column: "id"},
+			},
+		},
+	}
+
+	entities, err := GetEntities(
+		"users",
+		func(rows util.Rows, entity *TestEntity) error {
+			return util.ScanNamed(rows, map[string]any{
+				"id":   &entity.ID,
+				"name": &entity.Name,
+				"age":  &entity.Age,
+			})
+		},
+		db,
+		options,
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, []TestEntity{{ID: 1, Age: 30}}, entities)
+
+	entities, err = GetEntities(
+		"users",
+		func(rows util.Rows, entity *TestEntity) error {
+			return rows.Scan(&entity.ID, &entity.Age)
+		},
+		db,
+		options,
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, []TestEntity{{ID: 30, Age: 1}}, entities)
+}
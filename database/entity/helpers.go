@@ -2,8 +2,10 @@ package entity
 
 import (
 	"context"
-	"fmt"
+	"time"
 
+	"github.com/pakkasys/fluidapi/core/readonly"
+	"github.com/pakkasys/fluidapi/database/cache"
 	"github.com/pakkasys/fluidapi/database/transaction"
 	"github.com/pakkasys/fluidapi/database/util"
 )
@@ -11,6 +13,11 @@ import (
 // UpsertOptions is the options struct used for upserts.
 type UpsertOptions struct {
 	UpdateProjection []util.Projection
+	// UpdateExpressions are expressions for updating columns of existing rows
+	UpdateExpressions []UpdateExpression
+	// Mode selects how conflicting rows are handled. The update projection
+	// and the update expressions are only used by InsertModeUpsert.
+	Mode InsertMode
 }
 
 // Updates contains a list of updates.
@@ -69,32 +76,104 @@ type EntityHelpers[T any] struct {
 	ScanRowFn RowScanner[T]
 	// ScannerMultipleFn is a function that returns a multiple rows scanner
 	ScanRowsFn RowScannerMultiple[T]
-	// EntityNotFoundFn is a function that returns an entity not found error
+	// EntityNotFoundFn is a function that returns an entity not found error.
+	// Defaults to ErrEntityNotFound.
 	EntityNotFoundFn func() error
 	// UpdateHandler is an optional handler for entity updates
 	UpdateHandler *UpdateHandler
 	// SQLUtil is a utility for working with SQL
 	SQLUtil SQLUtil
+	// RetryStaleReads enables a single transparent retry of reads that fail
+	// because of a stale database connection
+	RetryStaleReads bool
+	// TenantResolver is an optional resolver for the tenant of the operation
+	TenantResolver TenantResolver
+	// RowScope is an optional hook for selectors that are added to every get,
+	// count, update and delete
+	RowScope RowScope
+	// Cache is an optional cache for GetEntity results. The cached results of
+	// the table are invalidated on entity creation, update and deletion. The
+	// results of reads with joins are also invalidated by the writes to the
+	// joined tables, if the entity helpers of those tables share the cache.
+	// The results are cached as JSON, so only their exported fields without
+	// a `json:"-"` tag are restored from the cache.
+	Cache cache.Cache
+	// CacheTTL is how long GetEntity results are cached
+	CacheTTL time.Duration
+	// CountCache is an optional cache for GetEntityCount results
+	CountCache *CountCache
+	// Relations are the relations that can be preloaded with the entities
+	Relations []Relation[T]
+	// Hooks are the lifecycle hooks of the entities
+	Hooks Hooks[T]
+	// TimestampHandler is an optional handler for the creation and update
+	// timestamps of the entities
+	TimestampHandler *TimestampHandler[T]
+	// IDGenerator is an optional generator filling the primary keys of the
+	// entities before they are inserted
+	IDGenerator IDGenerator[T]
+}
+
+// tenant returns the tenant of the operation, or nil if no tenant resolver is
+// set.
+func (e *EntityHelpers[T]) tenant(ctx context.Context) (*Tenant, error) {
+	if e.TenantResolver == nil {
+		return nil, nil
+	}
+	return e.TenantResolver(ctx)
 }
 
 // CreateEntity is a generic function for creating or upserting an entity.
 //
+//   - ctx: The context of the operation.
 //   - preparer: The preparer used to prepare the query.
 //   - object: The entity to create or upsert.
 //   - opts: The options struct for upserting the entity.
 func (e *EntityHelpers[T]) CreateEntity(
+	ctx context.Context,
 	preparer util.Preparer,
 	object *T,
 	opts *UpsertOptions,
 ) (*T, error) {
-	if opts != nil {
+	if err := readonly.Check(); err != nil {
+		return nil, err
+	}
+	tenant, err := e.tenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := e.generateIDs(object); err != nil {
+		return nil, err
+	}
+	e.TimestampHandler.onCreate(object)
+	opts = e.TimestampHandler.onUpsert(opts)
+	err = runEntityHooks(ctx, preparer, e.Hooks.BeforeCreate, object)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts != nil && opts.Mode != InsertModeUpsert {
+		_, err := InsertEntitiesWithMode(
+			util.WithContext(ctx, preparer),
+			tenant.TableName(e.TableName),
+			[]*T{object},
+			e.InserterFn,
+			opts.Mode,
+			e.SQLUtil,
+		)
+		if err != nil {
+			return nil, err
+		}
+	} else if opts != nil {
 		_, err := UpsertEntity(
-			preparer,
-			e.TableName,
+			util.WithContext(ctx, preparer),
+			tenant.TableName(e.TableName),
 			object,
 			e.InserterFn,
 			opts.UpdateProjection,
 			e.SQLUtil,
+			opts.UpdateExpressions...,
 		)
 		if err != nil {
 			return nil, err
@@ -102,8 +181,8 @@ func (e *EntityHelpers[T]) CreateEntity(
 	} else {
 		_, err := CreateEntity(
 			object,
-			preparer,
-			e.TableName,
+			util.WithContext(ctx, preparer),
+			tenant.TableName(e.TableName),
 			e.InserterFn,
 			e.SQLUtil,
 		)
@@ -112,6 +191,14 @@ func (e *EntityHelpers[T]) CreateEntity(
 		}
 	}
 
+	err = runEntityHooks(ctx, preparer, e.Hooks.AfterCreate, object)
+	if err != nil {
+		return nil, err
+	}
+	if err := e.invalidateCache(ctx, preparer, tenant); err != nil {
+		return nil, err
+	}
+
 	return object, nil
 }
 
@@ -129,7 +216,7 @@ func (e *EntityHelpers[T]) CreateEntityWithManagedTransaction(
 		ctx,
 		e.GetTxFn,
 		func(ctx context.Context, tx util.Tx) (*T, error) {
-			return e.CreateEntity(tx, entity, opts)
+			return e.CreateEntity(ctx, tx, entity, opts)
 		},
 	)
 }
@@ -137,22 +224,55 @@ func (e *EntityHelpers[T]) CreateEntityWithManagedTransaction(
 // CreateEntities is a generic function for creating or upserting multiple
 // entities.
 //
+//   - ctx: The context of the operation.
 //   - preparer: The preparer used to prepare the query.
 //   - entities: The entities to create or upsert.
 //   - opts: The options struct for upserting the entities.
 func (e *EntityHelpers[T]) CreateEntities(
+	ctx context.Context,
 	preparer util.Preparer,
 	entities []*T,
 	opts *UpsertOptions,
 ) ([]*T, error) {
-	if opts != nil {
+	if err := readonly.Check(); err != nil {
+		return nil, err
+	}
+	tenant, err := e.tenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := e.generateIDs(entities...); err != nil {
+		return nil, err
+	}
+	e.TimestampHandler.onCreate(entities...)
+	opts = e.TimestampHandler.onUpsert(opts)
+	err = runEntityHooks(ctx, preparer, e.Hooks.BeforeCreate, entities...)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts != nil && opts.Mode != InsertModeUpsert {
+		_, err := InsertEntitiesWithMode(
+			util.WithContext(ctx, preparer),
+			tenant.TableName(e.TableName),
+			entities,
+			e.InserterFn,
+			opts.Mode,
+			e.SQLUtil,
+		)
+		if err != nil {
+			return nil, err
+		}
+	} else if opts != nil {
 		_, err := UpsertEntities(
-			preparer,
-			e.TableName,
+			util.WithContext(ctx, preparer),
+			tenant.TableName(e.TableName),
 			entities,
 			e.InserterFn,
 			opts.UpdateProjection,
 			e.SQLUtil,
+			opts.UpdateExpressions...,
 		)
 		if err != nil {
 			return nil, err
@@ -160,8 +280,8 @@ func (e *EntityHelpers[T]) CreateEntities(
 	} else {
 		_, err := CreateEntities(
 			entities,
-			preparer,
-			e.TableName,
+			util.WithContext(ctx, preparer),
+			tenant.TableName(e.TableName),
 			e.InserterFn,
 			e.SQLUtil,
 		)
@@ -170,6 +290,14 @@ func (e *EntityHelpers[T]) CreateEntities(
 		}
 	}
 
+	err = runEntityHooks(ctx, preparer, e.Hooks.AfterCreate, entities...)
+	if err != nil {
+		return nil, err
+	}
+	if err := e.invalidateCache(ctx, preparer, tenant); err != nil {
+		return nil, err
+	}
+
 	return entities, nil
 }
 
@@ -187,20 +315,58 @@ func (e *EntityHelpers[T]) CreateEntitiesWithManagedTransaction(
 		ctx,
 		e.GetTxFn,
 		func(ctx context.Context, tx util.Tx) ([]*T, error) {
-			return e.CreateEntities(tx, entities, opts)
+			return e.CreateEntities(ctx, tx, entities, opts)
 		},
 	)
 }
 
 // GetEntity is a generic function for getting an entity.
 //
+//   - ctx: The context of the operation.
 //   - preparer: The preparer used to prepare the query.
 //   - opts: The options struct for getting the entity.
 func (e *EntityHelpers[T]) GetEntity(
+	ctx context.Context,
 	preparer util.Preparer,
 	opts GetOptions,
 ) (*T, error) {
-	entity, err := GetEntity(e.TableName, e.ScanRowFn, preparer, &opts)
+	tenant, err := e.tenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+	opts.Selectors = e.scopeRows(ctx, opts.Selectors)
+	opts.Options = tenant.scopeOptions(opts.Options)
+
+	tableName := tenant.TableName(e.TableName)
+	read := func() (*T, error) {
+		return retryStaleRead(
+			e.RetryStaleReads,
+			preparer,
+			func() (*T, error) {
+				return GetEntity(
+					tableName,
+					e.ScanRowFn,
+					util.WithContext(ctx, preparer),
+					&opts,
+				)
+			},
+		)
+	}
+
+	var entity *T
+	if opts.Lock {
+		entity, err = read()
+	} else {
+		entity, err = cachedRead(
+			ctx,
+			e,
+			preparer,
+			tableName,
+			opts.Options,
+			opts.Joins,
+			read,
+		)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -208,11 +374,17 @@ func (e *EntityHelpers[T]) GetEntity(
 		if e.EntityNotFoundFn != nil {
 			return nil, e.EntityNotFoundFn()
 		} else {
-			return nil, fmt.Errorf("entity not found")
+			return nil, ErrEntityNotFound
 		}
 	}
 
-	return entity, nil
+	entities := []T{*entity}
+	err = e.preload(ctx, preparer, tenant, entities, opts.Preload)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entities[0], nil
 }
 
 // GetEntityWithManagedTransaction wraps entity get in a transaction.
@@ -223,24 +395,60 @@ func (e *EntityHelpers[T]) GetEntityWithManagedTransaction(
 	ctx context.Context,
 	opts GetOptions,
 ) (*T, error) {
-	return transaction.ExecuteManagedTransaction(
+	return retryStaleManagedRead(
 		ctx,
-		e.GetTxFn,
-		func(ctx context.Context, tx util.Tx) (*T, error) {
-			return e.GetEntity(tx, opts)
+		e.RetryStaleReads,
+		func(ctx context.Context) (*T, error) {
+			return transaction.ExecuteManagedTransaction(
+				ctx,
+				e.GetTxFn,
+				func(ctx context.Context, tx util.Tx) (*T, error) {
+					return e.GetEntity(ctx, tx, opts)
+				},
+			)
 		},
 	)
 }
 
 // GetEntities is a generic function for getting multiple entities.
 //
+//   - ctx: The context of the operation.
 //   - preparer: The preparer used to prepare the query.
 //   - opts: The options struct for getting the entities.
 func (e *EntityHelpers[T]) GetEntities(
+	ctx context.Context,
 	preparer util.Preparer,
 	opts GetOptions,
 ) ([]T, error) {
-	return GetEntities(e.TableName, e.ScanRowsFn, preparer, &opts)
+	tenant, err := e.tenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+	opts.Selectors = e.scopeRows(ctx, opts.Selectors)
+	opts.Options = tenant.scopeOptions(opts.Options)
+
+	entities, err := retryStaleRead(
+		e.RetryStaleReads,
+		preparer,
+		func() ([]T, error) {
+			return GetEntities(
+				tenant.TableName(e.TableName),
+				e.ScanRowsFn,
+				util.WithContext(ctx, preparer),
+				&opts,
+			)
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	err = e.preload(ctx, preparer, tenant, entities, opts.Preload)
+	if err != nil {
+		return nil, err
+	}
+
+	return entities, nil
 }
 
 // GetEntitiesWithManagedTransaction wraps entity get in a transaction.
@@ -251,33 +459,59 @@ func (e *EntityHelpers[T]) GetEntitiesWithManagedTransaction(
 	ctx context.Context,
 	opts GetOptions,
 ) ([]T, error) {
-	return transaction.ExecuteManagedTransaction(
+	return retryStaleManagedRead(
 		ctx,
-		e.GetTxFn,
-		func(ctx context.Context, tx util.Tx) ([]T, error) {
-			return e.GetEntities(tx, opts)
+		e.RetryStaleReads,
+		func(ctx context.Context) ([]T, error) {
+			return transaction.ExecuteManagedTransaction(
+				ctx,
+				e.GetTxFn,
+				func(ctx context.Context, tx util.Tx) ([]T, error) {
+					return e.GetEntities(ctx, tx, opts)
+				},
+			)
 		},
 	)
 }
 
 // GetEntityCount is a generic function for getting the count of entities.
 //
+//   - ctx: The context of the operation.
 //   - preparer: The preparer used to prepare the query.
 //   - selectors: The selectors for the query.
 //   - joins: The joins for the query.
 func (e *EntityHelpers[T]) GetEntityCount(
+	ctx context.Context,
 	preparer util.Preparer,
 	selectors []util.Selector,
 	joins []util.Join,
 ) (int, error) {
-	return CountEntities(
-		preparer,
-		e.TableName,
-		&DBOptionsCount{
-			Selectors: selectors,
-			Joins:     joins,
-		},
-	)
+	tenant, err := e.tenant(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	tableName := tenant.TableName(e.TableName)
+	countOpts := e.countOptions(ctx, tenant, selectors, joins)
+	count := func(ctx context.Context) (int, error) {
+		return retryStaleRead(
+			e.RetryStaleReads,
+			preparer,
+			func() (int, error) {
+				return CountEntities(
+					util.WithContext(ctx, preparer),
+					tableName,
+					countOpts,
+				)
+			},
+		)
+	}
+
+	// Counts inside a transaction may see uncommitted changes.
+	if _, isTx := preparer.(util.Tx); e.CountCache == nil || isTx {
+		return count(ctx)
+	}
+	return e.cachedCount(ctx, tableName, countOpts, count)
 }
 
 // GetEntityCountWithManagedTransaction wraps entity count in a transaction.
@@ -290,42 +524,113 @@ func (e *EntityHelpers[T]) GetEntityCountWithManagedTransaction(
 	selectors []util.Selector,
 	joins []util.Join,
 ) (int, error) {
-	return transaction.ExecuteManagedTransaction(
-		ctx,
-		e.GetTxFn,
-		func(ctx context.Context, tx util.Tx) (int, error) {
-			return e.GetEntityCount(tx, selectors, joins)
-		},
-	)
+	tenant, err := e.tenant(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	// The table and the selectors are resolved from the request context, since
+	// the count may be refreshed in the background without it.
+	tableName := tenant.TableName(e.TableName)
+	countOpts := e.countOptions(ctx, tenant, selectors, joins)
+	count := func(ctx context.Context) (int, error) {
+		return retryStaleManagedRead(
+			ctx,
+			e.RetryStaleReads,
+			func(ctx context.Context) (int, error) {
+				return transaction.ExecuteManagedTransaction(
+					ctx,
+					e.GetTxFn,
+					func(ctx context.Context, tx util.Tx) (int, error) {
+						return CountEntities(tx, tableName, countOpts)
+					},
+				)
+			},
+		)
+	}
+
+	// Counts in a transaction from the context may see uncommitted changes.
+	hasTx := transaction.GetTransactionFromContext(ctx) != nil
+	if e.CountCache == nil || hasTx {
+		return count(ctx)
+	}
+	return e.cachedCount(ctx, tableName, countOpts, count)
 }
 
 // UpdateEntities updates entities and returns the number of updated
 // rows. If update options are set they will be used to update the "updated"
 // timestamp field only if that field update options is not explicitly set.
 //
+//   - ctx: The context of the operation.
 //   - preparer: The preparer used to prepare the query.
 //   - selectors: The selectors for the query.
 //   - updates: The update options for the query.
 func (e *EntityHelpers[T]) UpdateEntities(
+	ctx context.Context,
 	preparer util.Preparer,
 	selectors []util.Selector,
 	updates Updates,
 ) (int64, error) {
-	if e.UpdateHandler != nil {
-		update := updates.GetByField(e.UpdateHandler.UpdatedField)
-		// Add update options if not explicitly set.
-		if update == nil {
-			updates = append(updates, e.UpdateHandler.GetUpdateOptionsFn())
-		}
+	if err := readonly.Check(); err != nil {
+		return 0, err
+	}
+	tenant, err := e.tenant(ctx)
+	if err != nil {
+		return 0, err
 	}
 
-	return UpdateEntities(
+	updates = e.handleUpdates(updates)
+
+	selectors = tenant.scopeSelectors(e.scopeRows(ctx, selectors))
+	err = runUpdateHooks(
+		ctx,
 		preparer,
-		e.TableName,
+		e.Hooks.BeforeUpdate,
+		selectors,
+		updates,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	count, err := UpdateEntities(
+		util.WithContext(ctx, preparer),
+		tenant.TableName(e.TableName),
 		selectors,
 		updates,
 		e.SQLUtil,
 	)
+	if err != nil {
+		return 0, err
+	}
+	err = runUpdateHooks(
+		ctx,
+		preparer,
+		e.Hooks.AfterUpdate,
+		selectors,
+		updates,
+	)
+	if err != nil {
+		return 0, err
+	}
+	if err := e.invalidateCache(ctx, preparer, tenant); err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// handleUpdates adds the update timestamps to the updates unless they are
+// explicitly set.
+func (e *EntityHelpers[T]) handleUpdates(updates Updates) Updates {
+	if e.UpdateHandler != nil {
+		update := updates.GetByField(e.UpdateHandler.UpdatedField)
+		// Add update options if not explicitly set.
+		if update == nil {
+			updates = append(updates, e.UpdateHandler.GetUpdateOptionsFn())
+		}
+	}
+	return e.TimestampHandler.onUpdate(updates)
 }
 
 // UpdateEntitiesWithManagedTransaction wraps entity update in a transaction.
@@ -342,25 +647,55 @@ func (e *EntityHelpers[T]) UpdateEntitiesWithManagedTransaction(
 		ctx,
 		e.GetTxFn,
 		func(ctx context.Context, tx util.Tx) (int64, error) {
-			return e.UpdateEntities(tx, selectors, updates)
+			return e.UpdateEntities(ctx, tx, selectors, updates)
 		},
 	)
 }
 
 // DeleteEntities is a generic function for deleting multiple entities.
 //
+//   - ctx: The context of the operation.
 //   - preparer: The preparer used to prepare the query.
 //   - opts: The options struct for getting the entity.
 func (e *EntityHelpers[T]) DeleteEntities(
+	ctx context.Context,
 	preparer util.Preparer,
 	selectors []util.Selector,
 	opts *DeleteOptions,
 ) (int64, error) {
-	count, err := DeleteEntities(preparer, e.TableName, selectors, opts)
+	if err := readonly.Check(); err != nil {
+		return 0, err
+	}
+	tenant, err := e.tenant(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	opts = tenant.scopeDeleteOptions(opts)
+
+	selectors = tenant.scopeSelectors(e.scopeRows(ctx, selectors))
+	err = runDeleteHooks(ctx, preparer, e.Hooks.BeforeDelete, selectors)
 	if err != nil {
 		return 0, err
 	}
 
+	count, err := DeleteEntities(
+		util.WithContext(ctx, preparer),
+		tenant.TableName(e.TableName),
+		selectors,
+		opts,
+	)
+	if err != nil {
+		return 0, err
+	}
+	err = runDeleteHooks(ctx, preparer, e.Hooks.AfterDelete, selectors)
+	if err != nil {
+		return 0, err
+	}
+	if err := e.invalidateCache(ctx, preparer, tenant); err != nil {
+		return 0, err
+	}
+
 	return count, nil
 }
 
@@ -379,17 +714,22 @@ func (e *EntityHelpers[T]) DeleteEntitiesWithManagedTransaction(
 		ctx,
 		e.GetTxFn,
 		func(ctx context.Context, tx util.Tx) (int64, error) {
-			return e.DeleteEntities(tx, selectors, opts)
+			return e.DeleteEntities(ctx, tx, selectors, opts)
 		},
 	)
 }
 
 func (e *EntityHelpers[T]) ExecQuery(
+	ctx context.Context,
 	preparer util.Preparer,
 	query string,
 	params []any,
 ) (util.Result, error) {
-	return ExecQuery(preparer, query, params)
+	if err := readonly.Check(); err != nil {
+		return nil, err
+	}
+
+	return ExecQuery(util.WithContext(ctx, preparer), query, params)
 }
 
 func (e *EntityHelpers[T]) ExecQueryWithManagedTransaction(
@@ -401,7 +741,7 @@ func (e *EntityHelpers[T]) ExecQueryWithManagedTransaction(
 		ctx,
 		e.GetTxFn,
 		func(ctx context.Context, tx util.Tx) (util.Result, error) {
-			return e.ExecQuery(tx, query, params)
+			return e.ExecQuery(ctx, tx, query, params)
 		},
 	)
 }
@@ -6,8 +6,10 @@ import (
 	"errors"
 	"testing"
 
+	"github.com/pakkasys/fluidapi/core/readonly"
 	entitymock "github.com/pakkasys/fluidapi/database/entity/mock"
 	"github.com/pakkasys/fluidapi/database/util"
+	"github.com/pakkasys/fluidapi/database/util/fake"
 	utilmock "github.com/pakkasys/fluidapi/database/util/mock"
 	"github.com/pakkasys/fluidapi/endpoint/page"
 	endpointutil "github.com/pakkasys/fluidapi/endpoint/util"
@@ -165,7 +167,12 @@ func TestCreateEntity_CreateWithoutUpsertOptions(t *testing.T) {
 	mockSQLUtil.On("CheckDBError", mock.Anything).Return(nil)
 	mockResult.On("LastInsertId").Return(int64(1), nil)
 
-	result, err := entityHelpers.CreateEntity(mockPreparer, entity, nil)
+	result, err := entityHelpers.CreateEntity(
+		context.Background(),
+		mockPreparer,
+		entity,
+		nil,
+	)
 
 	assert.NoError(t, err)
 	assert.NotNil(t, result)
@@ -205,7 +212,12 @@ func TestCreateEntity_UpsertWithOptions(t *testing.T) {
 	mockSQLUtil.On("CheckDBError", mock.Anything).Return(nil)
 	mockResult.On("LastInsertId").Return(int64(1), nil)
 
-	result, err := entityHelpers.CreateEntity(mockPreparer, entity, opts)
+	result, err := entityHelpers.CreateEntity(
+		context.Background(),
+		mockPreparer,
+		entity,
+		opts,
+	)
 
 	assert.NoError(t, err)
 	assert.NotNil(t, result)
@@ -240,7 +252,12 @@ func TestCreateEntity_UpsertEntityFailure(t *testing.T) {
 	mockPreparer.On("Prepare", mock.Anything).Return(nil, expectedErr)
 	mockSQLUtil.On("CheckDBError", mock.Anything).Return(expectedErr)
 
-	result, err := entityHelpers.CreateEntity(mockPreparer, entity, opts)
+	result, err := entityHelpers.CreateEntity(
+		context.Background(),
+		mockPreparer,
+		entity,
+		opts,
+	)
 
 	assert.EqualError(t, err, expectedErr.Error())
 	assert.Nil(t, result)
@@ -271,7 +288,12 @@ func TestCreateEntity_EntityCreationFailure(t *testing.T) {
 	mockStmt.On("Close").Return(nil)
 	mockSQLUtil.On("CheckDBError", mock.Anything).Return(expectedErr)
 
-	result, err := entityHelpers.CreateEntity(mockPreparer, entity, nil)
+	result, err := entityHelpers.CreateEntity(
+		context.Background(),
+		mockPreparer,
+		entity,
+		nil,
+	)
 
 	assert.EqualError(t, err, expectedErr.Error())
 	assert.Nil(t, result)
@@ -398,7 +420,12 @@ func TestCreateEntities_CreateSuccess(t *testing.T) {
 	mockSQLUtil.On("CheckDBError", mock.Anything).Return(nil)
 	mockResult.On("LastInsertId").Return(int64(1), nil)
 
-	result, err := entityHelpers.CreateEntities(mockPreparer, entities, nil)
+	result, err := entityHelpers.CreateEntities(
+		context.Background(),
+		mockPreparer,
+		entities,
+		nil,
+	)
 
 	assert.NoError(t, err)
 	assert.NotNil(t, result)
@@ -436,7 +463,12 @@ func TestCreateEntities_UpsertError(t *testing.T) {
 	mockPreparer.On("Prepare", mock.Anything).Return(nil, expectedErr)
 	mockSQLUtil.On("CheckDBError", mock.Anything).Return(expectedErr)
 
-	result, err := entityHelpers.CreateEntities(mockPreparer, entities, opts)
+	result, err := entityHelpers.CreateEntities(
+		context.Background(),
+		mockPreparer,
+		entities,
+		opts,
+	)
 
 	assert.EqualError(t, err, expectedErr.Error())
 	assert.Nil(t, result)
@@ -467,7 +499,12 @@ func TestCreateEntities_PreparerFailure(t *testing.T) {
 	mockPreparer.On("Prepare", mock.Anything).Return(nil, expectedErr)
 	mockSQLUtil.On("CheckDBError", mock.Anything).Return(expectedErr)
 
-	result, err := entityHelpers.CreateEntities(mockPreparer, entities, nil)
+	result, err := entityHelpers.CreateEntities(
+		context.Background(),
+		mockPreparer,
+		entities,
+		nil,
+	)
 
 	assert.EqualError(t, err, expectedErr.Error())
 	assert.Nil(t, result)
@@ -581,7 +618,11 @@ func TestGetEntity_SuccessfulRetrieval(t *testing.T) {
 	mockStmt.On("Close").Return(nil)
 	mockRow.On("Err").Return(nil)
 
-	result, err := entityHelpers.GetEntity(mockPreparer, getOpts)
+	result, err := entityHelpers.GetEntity(
+		context.Background(),
+		mockPreparer,
+		getOpts,
+	)
 
 	assert.NoError(t, err)
 	assert.NotNil(t, result)
@@ -621,7 +662,11 @@ func TestGetEntity_EntityNotFoundFnDefined(t *testing.T) {
 	mockStmt.On("Close").Return(nil)
 	mockRow.On("Err").Return(sql.ErrNoRows)
 
-	result, err := entityHelpers.GetEntity(mockPreparer, getOpts)
+	result, err := entityHelpers.GetEntity(
+		context.Background(),
+		mockPreparer,
+		getOpts,
+	)
 
 	assert.EqualError(t, err, "custom entity not found")
 	assert.Nil(t, result)
@@ -656,7 +701,11 @@ func TestGetEntity_EntityNotFoundFnNotDefined(t *testing.T) {
 	mockStmt.On("Close").Return(nil)
 	mockRow.On("Err").Return(sql.ErrNoRows)
 
-	result, err := entityHelpers.GetEntity(mockPreparer, getOpts)
+	result, err := entityHelpers.GetEntity(
+		context.Background(),
+		mockPreparer,
+		getOpts,
+	)
 
 	assert.EqualError(t, err, "entity not found")
 	assert.Nil(t, result)
@@ -689,7 +738,11 @@ func TestGetEntity_PreparerFailure(t *testing.T) {
 	expectedErr := errors.New("prepare error")
 	mockPreparer.On("Prepare", mock.Anything).Return(nil, expectedErr)
 
-	result, err := entityHelpers.GetEntity(mockPreparer, getOpts)
+	result, err := entityHelpers.GetEntity(
+		context.Background(),
+		mockPreparer,
+		getOpts,
+	)
 
 	assert.EqualError(t, err, expectedErr.Error())
 	assert.Nil(t, result)
@@ -811,7 +864,11 @@ func TestGetEntities_SuccessfulRetrieval(t *testing.T) {
 	mockRows.On("Close").Return(nil)
 	mockRows.On("Err").Return(nil)
 
-	result, err := entityHelpers.GetEntities(mockPreparer, opts)
+	result, err := entityHelpers.GetEntities(
+		context.Background(),
+		mockPreparer,
+		opts,
+	)
 
 	assert.NoError(t, err)
 	assert.NotNil(t, result)
@@ -846,7 +903,11 @@ func TestGetEntities_PreparerFailure(t *testing.T) {
 	expectedErr := errors.New("prepare error")
 	mockPreparer.On("Prepare", mock.Anything).Return(nil, expectedErr)
 
-	result, err := entityHelpers.GetEntities(mockPreparer, opts)
+	result, err := entityHelpers.GetEntities(
+		context.Background(),
+		mockPreparer,
+		opts,
+	)
 
 	assert.EqualError(t, err, expectedErr.Error())
 	assert.Nil(t, result)
@@ -996,7 +1057,12 @@ func TestGetEntityCount_SuccessfulCountWithJoins(t *testing.T) {
 		*countPtr = expectedCount
 	}).Return(nil)
 
-	result, err := entityHelpers.GetEntityCount(mockPreparer, selectors, joins)
+	result, err := entityHelpers.GetEntityCount(
+		context.Background(),
+		mockPreparer,
+		selectors,
+		joins,
+	)
 
 	assert.NoError(t, err)
 	assert.Equal(t, expectedCount, result)
@@ -1037,7 +1103,12 @@ func TestGetEntityCount_PreparerFailure(t *testing.T) {
 	expectedErr := errors.New("prepare error")
 	mockPreparer.On("Prepare", mock.Anything).Return(nil, expectedErr)
 
-	result, err := entityHelpers.GetEntityCount(mockPreparer, selectors, joins)
+	result, err := entityHelpers.GetEntityCount(
+		context.Background(),
+		mockPreparer,
+		selectors,
+		joins,
+	)
 
 	assert.EqualError(t, err, expectedErr.Error())
 	assert.Equal(t, 0, result)
@@ -1186,7 +1257,12 @@ func TestUpdateEntities_SuccessfulUpdate(t *testing.T) {
 	mockResult.On("RowsAffected").Return(int64(1), nil)
 
 	result, err :=
-		entityHelpers.UpdateEntities(mockPreparer, selectors, updates)
+		entityHelpers.UpdateEntities(
+			context.Background(),
+			mockPreparer,
+			selectors,
+			updates,
+		)
 
 	assert.NoError(t, err)
 	assert.Equal(t, int64(1), result)
@@ -1218,7 +1294,12 @@ func TestUpdateEntities_PreparerFailure(t *testing.T) {
 	mockPreparer.On("Prepare", mock.Anything).Return(nil, expectedErr)
 	mockSQLUtil.On("CheckDBError", mock.Anything).Return(expectedErr)
 
-	result, err := entityHelpers.UpdateEntities(mockPreparer, selectors, updates)
+	result, err := entityHelpers.UpdateEntities(
+		context.Background(),
+		mockPreparer,
+		selectors,
+		updates,
+	)
 
 	assert.EqualError(t, err, expectedErr.Error())
 	assert.Equal(t, int64(0), result)
@@ -1357,6 +1438,7 @@ func TestUpdateEntities_WithUpdateHandler_ExistingUpdateOption(t *testing.T) {
 	mockResult.On("RowsAffected").Return(expectedRowsAffected, nil)
 
 	result, err := entityHelpers.UpdateEntities(
+		context.Background(),
 		mockPreparer,
 		selectors,
 		updates,
@@ -1405,6 +1487,7 @@ func TestUpdateEntities_WithUpdateHandler_NoExistingUpdateOption(t *testing.T) {
 	mockResult.On("RowsAffected").Return(expectedRowsAffected, nil)
 
 	result, err := entityHelpers.UpdateEntities(
+		context.Background(),
 		mockPreparer,
 		selectors,
 		updates,
@@ -1444,7 +1527,12 @@ func TestDeleteEntities_SuccessfulDeletion(t *testing.T) {
 	mockStmt.On("Close").Return(nil)
 	mockResult.On("RowsAffected").Return(int64(5), nil)
 
-	result, err := entityHelpers.DeleteEntities(mockPreparer, selectors, opts)
+	result, err := entityHelpers.DeleteEntities(
+		context.Background(),
+		mockPreparer,
+		selectors,
+		opts,
+	)
 
 	assert.NoError(t, err)
 	assert.Equal(t, int64(5), result)
@@ -1476,7 +1564,12 @@ func TestDeleteEntities_PreparerFailure(t *testing.T) {
 	mockPreparer.On("Prepare", mock.Anything).Return(nil, expectedErr)
 
 	// Run the delete function
-	result, err := entityHelpers.DeleteEntities(mockPreparer, selectors, opts)
+	result, err := entityHelpers.DeleteEntities(
+		context.Background(),
+		mockPreparer,
+		selectors,
+		opts,
+	)
 
 	assert.EqualError(t, err, expectedErr.Error())
 	assert.Equal(t, int64(0), result)
@@ -1585,3 +1678,65 @@ func TestDeleteEntitiesWithManagedTransaction_TransactionFailure(t *testing.T) {
 	mockTx.AssertExpectations(t)
 	mockStmt.AssertExpectations(t)
 }
+
+// TestEntityHelpers_ReadOnly tests that the write helpers return the read-only
+// error without touching the database when the read-only mode is enabled.
+func TestEntityHelpers_ReadOnly(t *testing.T) {
+	readonly.Enable()
+	defer readonly.Disable()
+
+	ctx := context.Background()
+	mockPreparer := new(utilmock.MockDB)
+	entityHelpers := EntityHelpers[TestEntity]{TableName: "test_table"}
+
+	_, err := entityHelpers.CreateEntity(ctx, mockPreparer, &TestEntity{}, nil)
+	assert.ErrorIs(t, err, readonly.ServiceReadOnlyError)
+
+	_, err = entityHelpers.CreateEntities(
+		ctx,
+		mockPreparer,
+		[]*TestEntity{{}},
+		nil,
+	)
+	assert.ErrorIs(t, err, readonly.ServiceReadOnlyError)
+
+	_, err = entityHelpers.UpdateEntities(
+		ctx,
+		mockPreparer,
+		nil,
+		Updates{{Field: "name", Value: "Bob"}},
+	)
+	assert.ErrorIs(t, err, readonly.ServiceReadOnlyError)
+
+	_, err = entityHelpers.DeleteEntities(ctx, mockPreparer, nil, nil)
+	assert.ErrorIs(t, err, readonly.ServiceReadOnlyError)
+
+	_, err = entityHelpers.ExecQuery(ctx, mockPreparer, "DELETE FROM x", nil)
+	assert.ErrorIs(t, err, readonly.ServiceReadOnlyError)
+
+	mockPreparer.AssertNotCalled(t, "Prepare")
+}
+
+// TestEntityHelpers_ContextCancelled tests that the queries of the entity
+// helpers are run with the context, so that a done context cancels them.
+func TestEntityHelpers_ContextCancelled(t *testing.T) {
+	db := fake.NewDB()
+	db.CreateTable("users", fake.TableOptions{
+		Columns:    []string{"id", "name"},
+		PrimaryKey: "id",
+	})
+	helpers := &EntityHelpers[TestEntity]{
+		TableName: "users",
+		ScanRowsFn: func(rows util.Rows, entity *TestEntity) error {
+			return rows.Scan(&entity.ID, &entity.Name)
+		},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := helpers.GetEntities(ctx, db, GetOptions{})
+	assert.ErrorIs(t, err, context.Canceled)
+
+	_, err = helpers.ExecQuery(ctx, db, "DELETE FROM `users`", nil)
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,93 @@
+package entity
+
+import (
+	"context"
+
+	"github.com/pakkasys/fluidapi/database/util"
+)
+
+// EntityHook is a lifecycle hook receiving a created entity.
+type EntityHook[T any] func(
+	ctx context.Context,
+	preparer util.Preparer,
+	entity *T,
+) error
+
+// UpdateHook is a lifecycle hook receiving the selectors and the updates of
+// an update.
+type UpdateHook func(
+	ctx context.Context,
+	preparer util.Preparer,
+	selectors []util.Selector,
+	updates Updates,
+) error
+
+// DeleteHook is a lifecycle hook receiving the selectors of a delete.
+type DeleteHook func(
+	ctx context.Context,
+	preparer util.Preparer,
+	selectors []util.Selector,
+) error
+
+// Hooks are lifecycle hooks run by the entity helpers. The hooks are run in
+// order with the preparer of the operation, so they take part in the same
+// transaction. An error returned by a hook aborts the operation.
+type Hooks[T any] struct {
+	// BeforeCreate hooks are run for each entity before it is created
+	BeforeCreate []EntityHook[T]
+	// AfterCreate hooks are run for each entity after it is created
+	AfterCreate []EntityHook[T]
+	// BeforeUpdate hooks are run before entities are updated
+	BeforeUpdate []UpdateHook
+	// AfterUpdate hooks are run after entities are updated
+	AfterUpdate []UpdateHook
+	// BeforeDelete hooks are run before entities are deleted
+	BeforeDelete []DeleteHook
+	// AfterDelete hooks are run after entities are deleted
+	AfterDelete []DeleteHook
+}
+
+func runEntityHooks[T any](
+	ctx context.Context,
+	preparer util.Preparer,
+	hooks []EntityHook[T],
+	entities ...*T,
+) error {
+	for _, entity := range entities {
+		for _, hook := range hooks {
+			if err := hook(ctx, preparer, entity); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func runUpdateHooks(
+	ctx context.Context,
+	preparer util.Preparer,
+	hooks []UpdateHook,
+	selectors []util.Selector,
+	updates Updates,
+) error {
+	for _, hook := range hooks {
+		if err := hook(ctx, preparer, selectors, updates); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func runDeleteHooks(
+	ctx context.Context,
+	preparer util.Preparer,
+	hooks []DeleteHook,
+	selectors []util.Selector,
+) error {
+	for _, hook := range hooks {
+		if err := hook(ctx, preparer, selectors); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,224 @@
+package entity
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	entitymock "github.com/pakkasys/fluidapi/database/entity/mock"
+	"github.com/pakkasys/fluidapi/database/util"
+	utilmock "github.com/pakkasys/fluidapi/database/util/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func expectHookExec(preparer *utilmock.MockDB) *entitymock.MockSQLUtil {
+	mockStmt := new(utilmock.MockStmt)
+	mockResult := new(utilmock.MockResult)
+	mockSQLUtil := new(entitymock.MockSQLUtil)
+	preparer.On("Prepare", mock.Anything).Return(mockStmt, nil)
+	mockStmt.On("Exec", mock.Anything).Return(mockResult, nil)
+	mockStmt.On("Close").Return(nil)
+	mockSQLUtil.On("CheckDBError", mock.Anything).Return(nil)
+	mockResult.On("LastInsertId").Return(int64(1), nil)
+	mockResult.On("RowsAffected").Return(int64(2), nil)
+	return mockSQLUtil
+}
+
+func recordEntityHook(
+	calls *[]string,
+	name string,
+) EntityHook[TestEntity] {
+	return func(
+		ctx context.Context,
+		preparer util.Preparer,
+		entity *TestEntity,
+	) error {
+		*calls = append(*calls, name+":"+entity.Name)
+		return nil
+	}
+}
+
+// TestCreateEntities_Hooks tests that the create hooks are run for each
+// entity around the insert.
+func TestCreateEntities_Hooks(t *testing.T) {
+	mockPreparer := new(utilmock.MockDB)
+	calls := []string{}
+
+	entityHelpers := EntityHelpers[TestEntity]{
+		TableName: "test_table",
+		InserterFn: func(entity *TestEntity) ([]string, []any) {
+			calls = append(calls, "insert:"+entity.Name)
+			return []string{"name"}, []any{entity.Name}
+		},
+		SQLUtil: expectHookExec(mockPreparer),
+		Hooks: Hooks[TestEntity]{
+			BeforeCreate: []EntityHook[TestEntity]{
+				recordEntityHook(&calls, "before"),
+			},
+			AfterCreate: []EntityHook[TestEntity]{
+				recordEntityHook(&calls, "after"),
+			},
+		},
+	}
+
+	_, err := entityHelpers.CreateEntities(
+		context.Background(),
+		mockPreparer,
+		[]*TestEntity{{Name: "a"}, {Name: "b"}},
+		nil,
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "before:a", calls[0])
+	assert.Equal(t, "before:b", calls[1])
+	assert.Equal(t, []string{"after:a", "after:b"}, calls[len(calls)-2:])
+}
+
+// TestCreateEntity_BeforeCreateError tests that an error from a hook aborts
+// the creation.
+func TestCreateEntity_BeforeCreateError(t *testing.T) {
+	mockPreparer := new(utilmock.MockDB)
+	hookErr := errors.New("hook error")
+
+	entityHelpers := EntityHelpers[TestEntity]{
+		TableName: "test_table",
+		Hooks: Hooks[TestEntity]{
+			BeforeCreate: []EntityHook[TestEntity]{
+				func(
+					ctx context.Context,
+					preparer util.Preparer,
+					entity *TestEntity,
+				) error {
+					return hookErr
+				},
+			},
+		},
+	}
+
+	_, err := entityHelpers.CreateEntity(
+		context.Background(),
+		mockPreparer,
+		&TestEntity{Name: "a"},
+		nil,
+	)
+
+	assert.ErrorIs(t, err, hookErr)
+	mockPreparer.AssertNotCalled(t, "Prepare", mock.Anything)
+}
+
+// TestUpdateEntities_Hooks tests that the update hooks receive the scoped
+// selectors and the updates.
+func TestUpdateEntities_Hooks(t *testing.T) {
+	mockPreparer := new(utilmock.MockDB)
+	calls := []string{}
+
+	hook := func(name string) UpdateHook {
+		return func(
+			ctx context.Context,
+			preparer util.Preparer,
+			selectors []util.Selector,
+			updates Updates,
+		) error {
+			assert.Equal(t, "t_test_table", selectors[0].Table)
+			assert.Equal(t, "name", updates[0].Field)
+			calls = append(calls, name)
+			return nil
+		}
+	}
+
+	entityHelpers := EntityHelpers[TestEntity]{
+		TableName: "test_table",
+		SQLUtil:   expectHookExec(mockPreparer),
+		TenantResolver: func(ctx context.Context) (*Tenant, error) {
+			return &Tenant{TablePrefix: "t_"}, nil
+		},
+		Hooks: Hooks[TestEntity]{
+			BeforeUpdate: []UpdateHook{hook("before")},
+			AfterUpdate:  []UpdateHook{hook("after")},
+		},
+	}
+
+	count, err := entityHelpers.UpdateEntities(
+		context.Background(),
+		mockPreparer,
+		[]util.Selector{
+			{Table: "test_table", Field: "id", Predicate: "=", Value: 1},
+		},
+		Updates{{Field: "name", Value: "b"}},
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+	assert.Equal(t, []string{"before", "after"}, calls)
+}
+
+// TestDeleteEntities_Hooks tests that the delete hooks are run around the
+// delete.
+func TestDeleteEntities_Hooks(t *testing.T) {
+	mockPreparer := new(utilmock.MockDB)
+	calls := []string{}
+
+	hook := func(name string) DeleteHook {
+		return func(
+			ctx context.Context,
+			preparer util.Preparer,
+			selectors []util.Selector,
+		) error {
+			assert.Equal(t, "id", selectors[0].Field)
+			calls = append(calls, name)
+			return nil
+		}
+	}
+
+	entityHelpers := EntityHelpers[TestEntity]{
+		TableName: "test_table",
+		Hooks: Hooks[TestEntity]{
+			BeforeDelete: []DeleteHook{hook("before")},
+			AfterDelete:  []DeleteHook{hook("after")},
+		},
+	}
+	expectHookExec(mockPreparer)
+
+	_, err := entityHelpers.DeleteEntities(
+		context.Background(),
+		mockPreparer,
+		[]util.Selector{{Field: "id", Predicate: "=", Value: 1}},
+		nil,
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"before", "after"}, calls)
+}
+
+// TestDeleteEntities_AfterDeleteError tests that an error from an after hook
+// is returned.
+func TestDeleteEntities_AfterDeleteError(t *testing.T) {
+	mockPreparer := new(utilmock.MockDB)
+	hookErr := errors.New("hook error")
+
+	entityHelpers := EntityHelpers[TestEntity]{
+		TableName: "test_table",
+		Hooks: Hooks[TestEntity]{
+			AfterDelete: []DeleteHook{
+				func(
+					ctx context.Context,
+					preparer util.Preparer,
+					selectors []util.Selector,
+				) error {
+					return hookErr
+				},
+			},
+		},
+	}
+	expectHookExec(mockPreparer)
+
+	_, err := entityHelpers.DeleteEntities(
+		context.Background(),
+		mockPreparer,
+		nil,
+		nil,
+	)
+
+	assert.ErrorIs(t, err, hookErr)
+}
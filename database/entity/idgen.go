@@ -0,0 +1,46 @@
+package entity
+
+import (
+	"github.com/pakkasys/fluidapi/database/idgen"
+)
+
+// IDGenerator fills the primary key of an entity before it is inserted.
+type IDGenerator[T any] func(entity *T) error
+
+// GenerateIDs creates an IDGenerator setting the ID field of the entities
+// with the generator. IDs that are already set are left unchanged.
+//
+//   - generator: The generator of the IDs.
+//   - idFieldFn: Returns a pointer to the ID field of an entity.
+func GenerateIDs[T any, K comparable](
+	generator idgen.Generator[K],
+	idFieldFn func(entity *T) *K,
+) IDGenerator[T] {
+	return func(entity *T) error {
+		var zero K
+		idField := idFieldFn(entity)
+		if *idField != zero {
+			return nil
+		}
+
+		id, err := generator.NewID()
+		if err != nil {
+			return err
+		}
+		*idField = id
+		return nil
+	}
+}
+
+// generateIDs fills the primary keys of the entities.
+func (e *EntityHelpers[T]) generateIDs(entities ...*T) error {
+	if e.IDGenerator == nil {
+		return nil
+	}
+	for _, entity := range entities {
+		if err := e.IDGenerator(entity); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package entity
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/pakkasys/fluidapi/database/idgen"
+	utilmock "github.com/pakkasys/fluidapi/database/util/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+type idgenEntity struct {
+	ID   string
+	Name string
+}
+
+func testIDGenerator() IDGenerator[idgenEntity] {
+	next := 0
+	return GenerateIDs(
+		idgen.GeneratorFunc[string](func() (string, error) {
+			next++
+			return string(rune('a' + next - 1)), nil
+		}),
+		func(entity *idgenEntity) *string { return &entity.ID },
+	)
+}
+
+// TestGenerateIDs tests that missing IDs are generated and existing IDs are
+// kept.
+func TestGenerateIDs(t *testing.T) {
+	generator := testIDGenerator()
+	missing := &idgenEntity{}
+	existing := &idgenEntity{ID: "x"}
+
+	assert.NoError(t, generator(missing))
+	assert.NoError(t, generator(existing))
+
+	assert.Equal(t, "a", missing.ID)
+	assert.Equal(t, "x", existing.ID)
+}
+
+// TestGenerateIDs_Error tests that a generator error is returned.
+func TestGenerateIDs_Error(t *testing.T) {
+	generator := GenerateIDs(
+		idgen.GeneratorFunc[string](func() (string, error) {
+			return "", errors.New("generator error")
+		}),
+		func(entity *idgenEntity) *string { return &entity.ID },
+	)
+
+	assert.EqualError(t, generator(&idgenEntity{}), "generator error")
+}
+
+// TestCreateEntities_IDGenerator tests that the entity helpers fill the IDs
+// before a multi-row insert.
+func TestCreateEntities_IDGenerator(t *testing.T) {
+	mockPreparer := new(utilmock.MockDB)
+	expectExecQuery(
+		mockPreparer,
+		"INSERT INTO `items` (`id`, `name`) VALUES (?, ?), (?, ?)",
+		[]any{"a", "first", "b", "second"},
+	)
+
+	entityHelpers := EntityHelpers[idgenEntity]{
+		TableName: "items",
+		InserterFn: func(entity *idgenEntity) ([]string, []any) {
+			return []string{"id", "name"}, []any{entity.ID, entity.Name}
+		},
+		SQLUtil:     newTimestampSQLUtil(),
+		IDGenerator: testIDGenerator(),
+	}
+
+	entities, err := entityHelpers.CreateEntities(
+		context.Background(),
+		mockPreparer,
+		[]*idgenEntity{{Name: "first"}, {Name: "second"}},
+		nil,
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "a", entities[0].ID)
+	assert.Equal(t, "b", entities[1].ID)
+	mockPreparer.AssertExpectations(t)
+}
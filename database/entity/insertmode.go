@@ -0,0 +1,86 @@
+package entity
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pakkasys/fluidapi/database/util"
+)
+
+// InsertMode selects how inserts handle rows conflicting with existing keys.
+type InsertMode string
+
+const (
+	// InsertModeUpsert updates the conflicting rows with ON DUPLICATE KEY
+	// UPDATE using the update projections.
+	InsertModeUpsert InsertMode = ""
+	// InsertModeIgnore skips the conflicting rows with INSERT IGNORE.
+	InsertModeIgnore InsertMode = "IGNORE"
+	// InsertModeReplace replaces the conflicting rows with REPLACE INTO.
+	InsertModeReplace InsertMode = "REPLACE"
+	// InsertModeDoNothing skips the conflicting rows with the Postgres
+	// ON CONFLICT DO NOTHING clause.
+	InsertModeDoNothing InsertMode = "DO_NOTHING"
+)
+
+// InsertEntitiesWithMode inserts entities using an insert mode other than
+// InsertModeUpsert.
+//
+//   - preparer: The preparer used to prepare the query.
+//   - tableName: The name of the database table.
+//   - entities: The entities to insert.
+//   - inserter: The function used to get the columns and values to insert.
+//   - mode: The insert mode.
+//   - sqlUtil: The SQL utility used to check database errors.
+func InsertEntitiesWithMode[T any](
+	preparer util.Preparer,
+	tableName string,
+	entities []*T,
+	inserter Inserter[*T],
+	mode InsertMode,
+	sqlUtil SQLUtil,
+) (int64, error) {
+	query, values, err := insertWithModeQuery(
+		entities,
+		tableName,
+		inserter,
+		mode,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	statement, err := preparer.Prepare(query)
+	if err != nil {
+		return 0, sqlUtil.CheckDBError(err)
+	}
+	defer statement.Close()
+
+	res, err := statement.Exec(values...)
+	return checkInsertResult(res, err, sqlUtil)
+}
+
+func insertWithModeQuery[T any](
+	entities []*T,
+	tableName string,
+	inserter Inserter[*T],
+	mode InsertMode,
+) (string, []any, error) {
+	if len(entities) == 0 {
+		return "", nil, fmt.Errorf("must provide entities to insert")
+	}
+
+	query, values := insertManyQuery(entities, tableName, inserter)
+	switch mode {
+	case InsertModeIgnore:
+		query = strings.Replace(query, "INSERT INTO", "INSERT IGNORE INTO", 1)
+	case InsertModeReplace:
+		query = strings.Replace(query, "INSERT INTO", "REPLACE INTO", 1)
+	case InsertModeDoNothing:
+		query += " ON CONFLICT DO NOTHING"
+	default:
+		return "", nil, fmt.Errorf("unsupported insert mode: %s", mode)
+	}
+
+	return query, values, nil
+}
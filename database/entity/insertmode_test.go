@@ -0,0 +1,140 @@
+package entity
+
+import (
+	"context"
+	"testing"
+
+	utilmock "github.com/pakkasys/fluidapi/database/util/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+func insertModeInserter(entity *TestEntity) ([]string, []any) {
+	return []string{"id", "name"}, []any{entity.ID, entity.Name}
+}
+
+// TestInsertWithModeQuery tests the queries of the insert modes.
+func TestInsertWithModeQuery(t *testing.T) {
+	entities := []*TestEntity{{ID: 1, Name: "a"}, {ID: 2, Name: "b"}}
+	values := " (`id`, `name`) VALUES (?, ?), (?, ?)"
+
+	for mode, expected := range map[InsertMode]string{
+		InsertModeIgnore:  "INSERT IGNORE INTO `users`" + values,
+		InsertModeReplace: "REPLACE INTO `users`" + values,
+		InsertModeDoNothing: "INSERT INTO `users`" + values +
+			" ON CONFLICT DO NOTHING",
+	} {
+		query, args, err := insertWithModeQuery(
+			entities,
+			"users",
+			insertModeInserter,
+			mode,
+		)
+
+		assert.NoError(t, err)
+		assert.Equal(t, expected, query)
+		assert.Equal(t, []any{1, "a", 2, "b"}, args)
+	}
+}
+
+// TestInsertWithModeQuery_Unsupported tests that an unsupported mode returns
+// an error.
+func TestInsertWithModeQuery_Unsupported(t *testing.T) {
+	_, _, err := insertWithModeQuery(
+		[]*TestEntity{{ID: 1}},
+		"users",
+		insertModeInserter,
+		InsertMode("MERGE"),
+	)
+
+	assert.EqualError(t, err, "unsupported insert mode: MERGE")
+}
+
+// TestInsertWithModeQuery_NoEntities tests that inserting no entities
+// returns an error.
+func TestInsertWithModeQuery_NoEntities(t *testing.T) {
+	_, _, err := insertWithModeQuery(
+		[]*TestEntity{},
+		"users",
+		insertModeInserter,
+		InsertModeIgnore,
+	)
+
+	assert.EqualError(t, err, "must provide entities to insert")
+}
+
+// TestCreateEntity_InsertIgnore tests creating an entity with INSERT IGNORE
+// through the entity helpers.
+func TestCreateEntity_InsertIgnore(t *testing.T) {
+	mockPreparer := new(utilmock.MockDB)
+	expectExecQuery(
+		mockPreparer,
+		"INSERT IGNORE INTO `users` (`id`, `name`) VALUES (?, ?)",
+		[]any{1, "a"},
+	)
+
+	entityHelpers := EntityHelpers[TestEntity]{
+		TableName:  "users",
+		InserterFn: insertModeInserter,
+		SQLUtil:    newTimestampSQLUtil(),
+	}
+
+	_, err := entityHelpers.CreateEntity(
+		context.Background(),
+		mockPreparer,
+		&TestEntity{ID: 1, Name: "a"},
+		&UpsertOptions{Mode: InsertModeIgnore},
+	)
+
+	assert.NoError(t, err)
+	mockPreparer.AssertExpectations(t)
+}
+
+// TestCreateEntities_Replace tests creating entities with REPLACE INTO
+// through the entity helpers.
+func TestCreateEntities_Replace(t *testing.T) {
+	mockPreparer := new(utilmock.MockDB)
+	expectExecQuery(
+		mockPreparer,
+		"REPLACE INTO `users` (`id`, `name`) VALUES (?, ?), (?, ?)",
+		[]any{1, "a", 2, "b"},
+	)
+
+	entityHelpers := EntityHelpers[TestEntity]{
+		TableName:  "users",
+		InserterFn: insertModeInserter,
+		SQLUtil:    newTimestampSQLUtil(),
+	}
+
+	_, err := entityHelpers.CreateEntities(
+		context.Background(),
+		mockPreparer,
+		[]*TestEntity{{ID: 1, Name: "a"}, {ID: 2, Name: "b"}},
+		&UpsertOptions{Mode: InsertModeReplace},
+	)
+
+	assert.NoError(t, err)
+	mockPreparer.AssertExpectations(t)
+}
+
+// TestBuildCreateEntity_DoNothing tests the dry-run query of the
+// ON CONFLICT DO NOTHING mode.
+func TestBuildCreateEntity_DoNothing(t *testing.T) {
+	entityHelpers := EntityHelpers[TestEntity]{
+		TableName:  "users",
+		InserterFn: insertModeInserter,
+	}
+
+	query, err := entityHelpers.BuildCreateEntity(
+		context.Background(),
+		&TestEntity{ID: 1, Name: "a"},
+		&UpsertOptions{Mode: InsertModeDoNothing},
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		"INSERT INTO `users` (`id`, `name`) VALUES (?, ?) "+
+			"ON CONFLICT DO NOTHING",
+		query.SQL,
+	)
+}
@@ -0,0 +1,77 @@
+package entity
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pakkasys/fluidapi/database/util"
+)
+
+// IterateEntities streams the entities matching the options to the iterator
+// function one row at a time, without loading all entities into memory. The
+// iteration stops at the first error returned by the iterator function.
+//
+//   - tableName: The name of the database table.
+//   - rowScannerMultiple: The function used to scan the rows.
+//   - preparer: The preparer used to prepare the query.
+//   - dbOptions: The options for the query.
+//   - iteratorFn: The function called for each entity.
+func IterateEntities[T any](
+	tableName string,
+	rowScannerMultiple RowScannerMultiple[T],
+	preparer util.Preparer,
+	dbOptions *GetOptions,
+	iteratorFn func(entity *T) error,
+) error {
+	if rowScannerMultiple == nil {
+		return fmt.Errorf("must provide rowScannerMultiple")
+	}
+
+	query, whereValues := buildBaseGetQuery(tableName, dbOptions)
+	rows, statement, err := RowsQuery(preparer, query, whereValues)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	defer statement.Close()
+
+	for rows.Next() {
+		var entity T
+		if err := rowScannerMultiple(rows, &entity); err != nil {
+			return err
+		}
+		if err := iteratorFn(&entity); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// IterateEntities streams the entities matching the options to the iterator
+// function one row at a time.
+//
+//   - ctx: The context of the operation.
+//   - preparer: The preparer used to prepare the query.
+//   - opts: The options struct for getting the entities.
+//   - iteratorFn: The function called for each entity.
+func (e *EntityHelpers[T]) IterateEntities(
+	ctx context.Context,
+	preparer util.Preparer,
+	opts GetOptions,
+	iteratorFn func(entity *T) error,
+) error {
+	tenant, err := e.tenant(ctx)
+	if err != nil {
+		return err
+	}
+	opts.Selectors = e.scopeRows(ctx, opts.Selectors)
+	opts.Options = tenant.scopeOptions(opts.Options)
+
+	return IterateEntities(
+		tenant.TableName(e.TableName),
+		e.ScanRowsFn,
+		util.WithContext(ctx, preparer),
+		&opts,
+		iteratorFn,
+	)
+}
@@ -0,0 +1,127 @@
+package entity
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/pakkasys/fluidapi/database/util"
+	utilmock "github.com/pakkasys/fluidapi/database/util/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func expectIterateRows(
+	preparer *utilmock.MockDB,
+	query string,
+	count int,
+) *utilmock.MockRows {
+	mockStmt := new(utilmock.MockStmt)
+	mockRows := new(utilmock.MockRows)
+	preparer.On("Prepare", query).Return(mockStmt, nil)
+	mockStmt.On("Query", mock.Anything).Return(mockRows, nil)
+	mockStmt.On("Close").Return(nil)
+	mockRows.On("Next").Return(true).Times(count)
+	mockRows.On("Next").Return(false)
+	mockRows.On("Err").Return(nil)
+	mockRows.On("Close").Return(nil)
+	return mockRows
+}
+
+func countingRowsScanner() RowScannerMultiple[TestEntity] {
+	id := 0
+	return func(rows util.Rows, entity *TestEntity) error {
+		id++
+		entity.ID = id
+		return nil
+	}
+}
+
+// TestIterateEntities tests that each row is passed to the iterator.
+func TestIterateEntities(t *testing.T) {
+	mockPreparer := new(utilmock.MockDB)
+	mockRows := expectIterateRows(mockPreparer, "SELECT * FROM `users`", 3)
+
+	ids := []int{}
+	err := IterateEntities(
+		"users",
+		countingRowsScanner(),
+		mockPreparer,
+		&GetOptions{},
+		func(entity *TestEntity) error {
+			ids = append(ids, entity.ID)
+			return nil
+		},
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 2, 3}, ids)
+	mockRows.AssertExpectations(t)
+}
+
+// TestIterateEntities_IteratorError tests that the iteration stops at an
+// iterator error.
+func TestIterateEntities_IteratorError(t *testing.T) {
+	mockPreparer := new(utilmock.MockDB)
+	expectIterateRows(mockPreparer, "SELECT * FROM `users`", 3)
+
+	calls := 0
+	err := IterateEntities(
+		"users",
+		countingRowsScanner(),
+		mockPreparer,
+		&GetOptions{},
+		func(entity *TestEntity) error {
+			calls++
+			return errors.New("stop")
+		},
+	)
+
+	assert.EqualError(t, err, "stop")
+	assert.Equal(t, 1, calls)
+}
+
+// TestIterateEntities_NoScanner tests that a scanner is required.
+func TestIterateEntities_NoScanner(t *testing.T) {
+	err := IterateEntities[TestEntity](
+		"users",
+		nil,
+		new(utilmock.MockDB),
+		&GetOptions{},
+		nil,
+	)
+
+	assert.EqualError(t, err, "must provide rowScannerMultiple")
+}
+
+// TestEntityHelpers_IterateEntities tests that the entity helpers apply the
+// row scope to the iterated query.
+func TestEntityHelpers_IterateEntities(t *testing.T) {
+	mockPreparer := new(utilmock.MockDB)
+	expectIterateRows(
+		mockPreparer,
+		"SELECT * FROM `users` WHERE `users`.`tenant_id` = ?",
+		1,
+	)
+
+	entityHelpers := EntityHelpers[TestEntity]{
+		TableName:  "users",
+		ScanRowsFn: countingRowsScanner(),
+		RowScope:   testRowScope,
+	}
+
+	calls := 0
+	err := entityHelpers.IterateEntities(
+		context.WithValue(context.Background(), rowScopeKey{}, 7),
+		mockPreparer,
+		GetOptions{},
+		func(entity *TestEntity) error {
+			calls++
+			return nil
+		},
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, calls)
+	mockPreparer.AssertExpectations(t)
+}
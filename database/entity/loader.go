@@ -0,0 +1,357 @@
+package entity
+
+import (
+	"context"
+	"maps"
+	"strings"
+	"sync"
+
+	"github.com/pakkasys/fluidapi/database/transaction"
+	"github.com/pakkasys/fluidapi/database/util"
+	endpointutil "github.com/pakkasys/fluidapi/endpoint/util"
+)
+
+var (
+	loadersContextKey = endpointutil.NewDataKey()
+	loadersLock       sync.Mutex
+)
+
+// BatchFunc loads the entities of the keys with a single query. Keys without
+// an entity are left out of the result.
+type BatchFunc[T any, K comparable] func(
+	ctx context.Context,
+	keys []K,
+) (map[K]T, error)
+
+type loaderResult[T any] struct {
+	entity *T
+	err    error
+	// loading is closed when the batch loading the entity is done
+	loading chan struct{}
+}
+
+// Loader coalesces entity loads by key into batched queries and caches the
+// loaded entities. Loads queued with LoadThunk are resolved together with a
+// single query when the first of their thunks is called, which removes N+1
+// query patterns, e.g. when output mappers resolve related entities. It is
+// safe for concurrent use, and the batches are loaded without holding the
+// lock of the loader, so concurrent loads of other keys are not blocked.
+type Loader[T any, K comparable] struct {
+	batchFn    BatchFunc[T, K]
+	notFoundFn func() error
+
+	mu      sync.Mutex
+	results map[K]loaderResult[T]
+	queue   []K
+}
+
+// NewLoader creates a new loader.
+//
+//   - batchFn: The function loading the entities of a batch of keys.
+//   - notFoundFn: Returns the error of a key without an entity, optional.
+func NewLoader[T any, K comparable](
+	batchFn BatchFunc[T, K],
+	notFoundFn func() error,
+) *Loader[T, K] {
+	return &Loader[T, K]{
+		batchFn:    batchFn,
+		notFoundFn: notFoundFn,
+		results:    map[K]loaderResult[T]{},
+	}
+}
+
+// EntityLoader returns the loader of the entity table and key field stored in
+// the endpoint context, creating it if needed. The loaded entities of the
+// table are cleared when the entity helpers write to the table within the
+// context. Without a custom context a new loader is returned for each call,
+// and its batches are loaded in a new transaction from the GetTxFn of the
+// helpers.
+//
+//   - ctx: The endpoint context storing the loader.
+//   - helpers: The entity helpers of the table.
+//   - keyField: The key field of the table.
+//   - keyFn: Returns the key of an entity.
+func EntityLoader[T any, K comparable](
+	ctx context.Context,
+	helpers *EntityHelpers[T],
+	keyField string,
+	keyFn func(entity *T) K,
+) *Loader[T, K] {
+	newLoader := func() *Loader[T, K] {
+		return NewLoader(
+			entityBatchFunc(helpers, keyField, keyFn),
+			helpers.EntityNotFoundFn,
+		)
+	}
+	if !endpointutil.IsContextSet(ctx) {
+		return newLoader()
+	}
+
+	loadersLock.Lock()
+	defer loadersLock.Unlock()
+
+	loaders := endpointutil.GetContextValue[map[string]any](
+		ctx,
+		loadersContextKey,
+		nil,
+	)
+	if loaders == nil {
+		loaders = map[string]any{}
+		endpointutil.SetContextValue(ctx, loadersContextKey, loaders)
+	}
+
+	name := helpers.TableName + "." + keyField
+	if loader, ok := loaders[name].(*Loader[T, K]); ok {
+		return loader
+	}
+	loader := newLoader()
+	loaders[name] = loader
+	return loader
+}
+
+// Prime adds an already loaded entity to the loader.
+//
+//   - key: The key of the entity.
+//   - entity: The entity.
+func (l *Loader[T, K]) Prime(key K, entity T) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.results[key] = loaderResult[T]{entity: &entity}
+}
+
+// Clear removes the loaded entity of the key, so that it is loaded again. An
+// entity being loaded by a running batch is kept.
+//
+//   - key: The key of the entity.
+func (l *Loader[T, K]) Clear(key K) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	maps.DeleteFunc(l.results, func(k K, result loaderResult[T]) bool {
+		return k == key && result.loading == nil
+	})
+}
+
+// ClearAll removes all loaded entities, so that they are loaded again.
+// Entities being loaded by a running batch are kept.
+func (l *Loader[T, K]) ClearAll() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	maps.DeleteFunc(l.results, func(_ K, result loaderResult[T]) bool {
+		return result.loading == nil
+	})
+}
+
+// LoadThunk queues the key to be loaded and returns a function returning the
+// entity of the key. All queued keys are loaded with one batch when the first
+// returned function is called.
+//
+//   - ctx: The context of the operation.
+//   - key: The key of the entity.
+func (l *Loader[T, K]) LoadThunk(
+	ctx context.Context,
+	key K,
+) func() (*T, error) {
+	l.mu.Lock()
+	if _, ok := l.results[key]; !ok {
+		l.queue = append(l.queue, key)
+	}
+	l.mu.Unlock()
+
+	return func() (*T, error) {
+		for {
+			if err := l.dispatch(ctx); err != nil {
+				return nil, err
+			}
+
+			l.mu.Lock()
+			result, ok := l.results[key]
+			l.mu.Unlock()
+			if ok && result.loading != nil {
+				// The key is loaded by the batch of a concurrent call
+				select {
+				case <-result.loading:
+				case <-ctx.Done():
+					return nil, ctx.Err()
+				}
+				l.mu.Lock()
+				result, ok = l.results[key]
+				l.mu.Unlock()
+			}
+			if ok {
+				return result.entity, result.err
+			}
+
+			// The batch loading the key was cancelled by the context of
+			// another call, so the key is loaded again.
+			l.mu.Lock()
+			l.queue = append(l.queue, key)
+			l.mu.Unlock()
+		}
+	}
+}
+
+// Load loads the entity of the key together with the queued keys.
+//
+//   - ctx: The context of the operation.
+//   - key: The key of the entity.
+func (l *Loader[T, K]) Load(ctx context.Context, key K) (*T, error) {
+	return l.LoadThunk(ctx, key)()
+}
+
+// LoadMany loads the entities of the keys with one batch. The entities are
+// returned in the order of the keys. The first error of the keys is
+// returned.
+//
+//   - ctx: The context of the operation.
+//   - keys: The keys of the entities.
+func (l *Loader[T, K]) LoadMany(ctx context.Context, keys []K) ([]*T, error) {
+	thunks := make([]func() (*T, error), len(keys))
+	for i, key := range keys {
+		thunks[i] = l.LoadThunk(ctx, key)
+	}
+
+	entities := make([]*T, len(keys))
+	for i, thunk := range thunks {
+		entity, err := thunk()
+		if err != nil {
+			return nil, err
+		}
+		entities[i] = entity
+	}
+	return entities, nil
+}
+
+// dispatch loads the queued keys that are neither loaded nor being loaded
+// by another batch. If the batch fails because the context is done, the keys
+// are not cached so that they can be loaded again with another context, and
+// the error is returned.
+func (l *Loader[T, K]) dispatch(ctx context.Context) error {
+	loading := make(chan struct{})
+	defer close(loading)
+
+	l.mu.Lock()
+	keys := []K{}
+	for _, key := range l.queue {
+		if _, ok := l.results[key]; !ok {
+			keys = append(keys, key)
+			l.results[key] = loaderResult[T]{loading: loading}
+		}
+	}
+	l.queue = nil
+	l.mu.Unlock()
+	if len(keys) == 0 {
+		return nil
+	}
+
+	entities, err := l.batchFn(ctx, keys)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err != nil && ctx.Err() != nil {
+		maps.DeleteFunc(l.results, func(_ K, result loaderResult[T]) bool {
+			return result.loading == loading
+		})
+		return err
+	}
+	for _, key := range keys {
+		if err != nil {
+			l.results[key] = loaderResult[T]{err: err}
+			continue
+		}
+		entity, ok := entities[key]
+		if !ok {
+			l.results[key] = loaderResult[T]{err: l.notFound()}
+			continue
+		}
+		l.results[key] = loaderResult[T]{entity: &entity}
+	}
+	return nil
+}
+
+func (l *Loader[T, K]) notFound() error {
+	if l.notFoundFn != nil {
+		return l.notFoundFn()
+	}
+	return ErrEntityNotFound
+}
+
+// entityBatchFunc returns a batch function getting the entities with
+// GetEntitiesByIDs in a managed transaction.
+func entityBatchFunc[T any, K comparable](
+	helpers *EntityHelpers[T],
+	keyField string,
+	keyFn func(entity *T) K,
+) BatchFunc[T, K] {
+	return func(ctx context.Context, keys []K) (map[K]T, error) {
+		readFn := func(ctx context.Context, tx util.Tx) (map[K]T, error) {
+			result, err := GetEntitiesByIDs(
+				ctx,
+				tx,
+				helpers,
+				keyField,
+				keys,
+				keyFn,
+				0,
+			)
+			if err != nil {
+				return nil, err
+			}
+			found := make(map[K]T, len(result.Entities))
+			for i := range result.Entities {
+				entity := result.Entities[i]
+				found[keyFn(&entity)] = entity
+			}
+			return found, nil
+		}
+
+		// A managed transaction is stored in the custom context, so without
+		// one the batch is loaded in a new transaction.
+		if !endpointutil.IsContextSet(ctx) {
+			tx, err := helpers.GetTxFn(ctx)
+			if err != nil {
+				return nil, err
+			}
+			return transaction.ExecuteTransaction(ctx, tx, readFn)
+		}
+		return retryStaleManagedRead(
+			ctx,
+			helpers.RetryStaleReads,
+			func(ctx context.Context) (map[K]T, error) {
+				return transaction.ExecuteManagedTransaction(
+					ctx,
+					helpers.GetTxFn,
+					readFn,
+				)
+			},
+		)
+	}
+}
+
+// clearLoaders clears the loaded entities of the loaders of the table stored
+// in the endpoint context.
+//
+//   - ctx: The endpoint context storing the loaders.
+//   - tableName: The table written to.
+func clearLoaders(ctx context.Context, tableName string) {
+	if !endpointutil.IsContextSet(ctx) {
+		return
+	}
+
+	loadersLock.Lock()
+	defer loadersLock.Unlock()
+
+	loaders := endpointutil.GetContextValue[map[string]any](
+		ctx,
+		loadersContextKey,
+		nil,
+	)
+	for name, loader := range loaders {
+		clearer, ok := loader.(interface{ ClearAll() })
+		if ok && strings.HasPrefix(name, tableName+".") {
+			clearer.ClearAll()
+		}
+	}
+}
@@ -0,0 +1,285 @@
+package entity
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/pakkasys/fluidapi/database/util"
+	utilmock "github.com/pakkasys/fluidapi/database/util/mock"
+	endpointutil "github.com/pakkasys/fluidapi/endpoint/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func recordingBatchFunc(batches *[][]int) BatchFunc[TestEntity, int] {
+	return func(ctx context.Context, keys []int) (map[int]TestEntity, error) {
+		*batches = append(*batches, keys)
+		found := map[int]TestEntity{}
+		for _, key := range keys {
+			if key > 0 {
+				found[key] = TestEntity{ID: key}
+			}
+		}
+		return found, nil
+	}
+}
+
+// TestLoader_LoadThunk tests that queued keys are loaded with one batch.
+func TestLoader_LoadThunk(t *testing.T) {
+	batches := [][]int{}
+	loader := NewLoader(recordingBatchFunc(&batches), nil)
+
+	first := loader.LoadThunk(context.Background(), 1)
+	second := loader.LoadThunk(context.Background(), 2)
+	duplicate := loader.LoadThunk(context.Background(), 1)
+
+	entity, err := second()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, entity.ID)
+	entity, err = first()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, entity.ID)
+	entity, err = duplicate()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, entity.ID)
+
+	assert.Equal(t, [][]int{{1, 2}}, batches)
+}
+
+// TestLoader_Cache tests that loaded entities are not loaded again.
+func TestLoader_Cache(t *testing.T) {
+	batches := [][]int{}
+	loader := NewLoader(recordingBatchFunc(&batches), nil)
+	loader.Prime(3, TestEntity{ID: 3, Name: "primed"})
+
+	_, err := loader.Load(context.Background(), 1)
+	assert.NoError(t, err)
+	_, err = loader.Load(context.Background(), 1)
+	assert.NoError(t, err)
+	entity, err := loader.Load(context.Background(), 3)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "primed", entity.Name)
+	assert.Equal(t, [][]int{{1}}, batches)
+}
+
+// TestLoader_LoadMany tests loading many keys in order.
+func TestLoader_LoadMany(t *testing.T) {
+	batches := [][]int{}
+	loader := NewLoader(recordingBatchFunc(&batches), nil)
+
+	entities, err := loader.LoadMany(context.Background(), []int{2, 1})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, entities[0].ID)
+	assert.Equal(t, 1, entities[1].ID)
+	assert.Len(t, batches, 1)
+}
+
+// TestLoader_NotFound tests the error of a key without an entity.
+func TestLoader_NotFound(t *testing.T) {
+	batches := [][]int{}
+	notFoundErr := errors.New("user not found")
+	loader := NewLoader(
+		recordingBatchFunc(&batches),
+		func() error { return notFoundErr },
+	)
+
+	_, err := loader.Load(context.Background(), -1)
+
+	assert.Equal(t, notFoundErr, err)
+}
+
+// TestLoader_BatchError tests that a batch error is returned for each key of
+// the batch.
+func TestLoader_BatchError(t *testing.T) {
+	loader := NewLoader(
+		func(ctx context.Context, keys []int) (map[int]TestEntity, error) {
+			return nil, errors.New("batch error")
+		},
+		nil,
+	)
+
+	_, err := loader.LoadMany(context.Background(), []int{1, 2})
+
+	assert.EqualError(t, err, "batch error")
+}
+
+// TestLoader_ContextError tests that the keys of a batch cancelled by its
+// context are not cached and are loaded again, also by a concurrent load
+// waiting for the cancelled batch.
+func TestLoader_ContextError(t *testing.T) {
+	started := make(chan struct{})
+	var batches atomic.Int32
+	loader := NewLoader(
+		func(ctx context.Context, keys []int) (map[int]TestEntity, error) {
+			if batches.Add(1) == 1 {
+				close(started)
+				<-ctx.Done()
+				return nil, ctx.Err()
+			}
+			return map[int]TestEntity{keys[0]: {ID: keys[0]}}, nil
+		},
+		nil,
+	)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancelled := make(chan error, 1)
+	go func() {
+		_, err := loader.Load(ctx, 1)
+		cancelled <- err
+	}()
+	<-started
+	waiting := loader.LoadThunk(context.Background(), 1)
+	cancel()
+
+	entity, err := waiting()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, entity.ID)
+	assert.ErrorIs(t, <-cancelled, context.Canceled)
+	assert.Equal(t, int32(2), batches.Load())
+}
+
+// TestLoader_Clear tests that cleared entities are loaded again.
+func TestLoader_Clear(t *testing.T) {
+	batches := [][]int{}
+	loader := NewLoader(recordingBatchFunc(&batches), nil)
+
+	_, err := loader.LoadMany(context.Background(), []int{1, 2})
+	assert.NoError(t, err)
+	loader.Clear(1)
+	_, err = loader.LoadMany(context.Background(), []int{1, 2})
+	assert.NoError(t, err)
+	loader.ClearAll()
+	_, err = loader.LoadMany(context.Background(), []int{1, 2})
+	assert.NoError(t, err)
+
+	assert.Equal(t, [][]int{{1, 2}, {1}, {1, 2}}, batches)
+}
+
+// TestLoader_Concurrent tests that the loads of other keys are not blocked
+// by a running batch, and that the loads of the keys of a running batch wait
+// for it.
+func TestLoader_Concurrent(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var batches atomic.Int32
+	loader := NewLoader(
+		func(ctx context.Context, keys []int) (map[int]TestEntity, error) {
+			batches.Add(1)
+			if keys[0] == 1 {
+				close(started)
+				<-release
+			}
+			return map[int]TestEntity{keys[0]: {ID: keys[0]}}, nil
+		},
+		nil,
+	)
+	loaded := make(chan *TestEntity, 2)
+	load := func() {
+		entity, err := loader.Load(context.Background(), 1)
+		assert.NoError(t, err)
+		loaded <- entity
+	}
+
+	go load()
+	<-started
+	entity, err := loader.Load(context.Background(), 2)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, entity.ID)
+	go load()
+	close(release)
+
+	assert.Equal(t, 1, (<-loaded).ID)
+	assert.Equal(t, 1, (<-loaded).ID)
+	assert.Equal(t, int32(2), batches.Load())
+}
+
+// TestEntityLoader_Context tests that the loader of a table and key field is
+// shared within the endpoint context and loads through the entity helpers.
+func TestEntityLoader_Context(t *testing.T) {
+	ctx := endpointutil.NewContext(context.Background())
+	mockTx := new(utilmock.MockTx)
+	mockTx.On("Commit").Return(nil)
+
+	helpers := &EntityHelpers[TestEntity]{
+		TableName: "users",
+		GetTxFn: func(ctx context.Context) (util.Tx, error) {
+			return mockTx, nil
+		},
+		ScanRowsFn: func(rows util.Rows, entity *TestEntity) error {
+			return rows.Scan(&entity.ID)
+		},
+	}
+
+	mockDB := &mockTx.MockDB
+	expectByIDsQuery(
+		mockDB,
+		"SELECT * FROM `users` WHERE `users`.`id` IN (?,?)",
+		[]any{1, 2},
+		[]int{1, 2},
+	)
+
+	loader := EntityLoader(ctx, helpers, "id", testEntityID)
+	assert.Same(t, loader, EntityLoader(ctx, helpers, "id", testEntityID))
+	assert.NotSame(t, loader, EntityLoader(ctx, helpers, "name", testEntityID))
+
+	first := loader.LoadThunk(ctx, 1)
+	second := EntityLoader(ctx, helpers, "id", testEntityID).LoadThunk(ctx, 2)
+	entity, err := first()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, entity.ID)
+	entity, err = second()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, entity.ID)
+
+	mockTx.AssertExpectations(t)
+}
+
+// TestEntityLoader_NoContext tests that the batches of a loader without a
+// custom context are loaded in a new transaction.
+func TestEntityLoader_NoContext(t *testing.T) {
+	ctx := context.Background()
+	mockTx := new(utilmock.MockTx)
+	mockTx.On("Commit").Return(nil).Once()
+
+	helpers := &EntityHelpers[TestEntity]{
+		TableName: "users",
+		GetTxFn: func(ctx context.Context) (util.Tx, error) {
+			return mockTx, nil
+		},
+		ScanRowsFn: func(rows util.Rows, entity *TestEntity) error {
+			return rows.Scan(&entity.ID)
+		},
+	}
+	expectByIDsQuery(
+		&mockTx.MockDB,
+		"SELECT * FROM `users` WHERE `users`.`id` IN (?)",
+		[]any{1},
+		[]int{1},
+	)
+
+	entity, err := EntityLoader(ctx, helpers, "id", testEntityID).Load(ctx, 1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, entity.ID)
+	mockTx.AssertExpectations(t)
+}
+
+// TestEntityLoader_ClearedOnWrite tests that writes of the entity helpers
+// clear the loaders of their table in the context.
+func TestEntityLoader_ClearedOnWrite(t *testing.T) {
+	ctx := endpointutil.NewContext(context.Background())
+	users := &EntityHelpers[TestEntity]{TableName: "users"}
+	groups := &EntityHelpers[TestEntity]{TableName: "groups"}
+	userLoader := EntityLoader(ctx, users, "id", testEntityID)
+	groupLoader := EntityLoader(ctx, groups, "id", testEntityID)
+	userLoader.Prime(1, TestEntity{ID: 1})
+	groupLoader.Prime(1, TestEntity{ID: 1})
+
+	err := users.invalidateCache(ctx, new(utilmock.MockDB), nil)
+
+	assert.NoError(t, err)
+	assert.Empty(t, userLoader.results)
+	assert.Len(t, groupLoader.results, 1)
+}
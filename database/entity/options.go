@@ -5,7 +5,10 @@ import (
 	"github.com/pakkasys/fluidapi/endpoint/page"
 )
 
-// Options is the options struct used for queries.
+// Options is the options struct used for queries. If Projections are set,
+// the scanner of the rows must read the columns by name, e.g. with
+// util.ScanNamed, since a positional scanner reads the wrong columns of a
+// projected query.
 type Options struct {
 	Selectors   []util.Selector
 	Orders      []util.Order
@@ -0,0 +1,194 @@
+package entity
+
+import (
+	"context"
+
+	"github.com/pakkasys/fluidapi/core/api"
+	"github.com/pakkasys/fluidapi/database/util"
+)
+
+var RelationNotFoundError = api.NewError[string]("RELATION_NOT_FOUND")
+
+// Relation describes how the entities of a table relate to the entities of
+// another table. Relations are created with HasMany and BelongsTo.
+type Relation[T any] struct {
+	// Name is the name used to request the relation in GetOptions.Preload
+	Name    string
+	preload func(
+		ctx context.Context,
+		preparer util.Preparer,
+		tenant *Tenant,
+		entities []T,
+	) error
+}
+
+// HasMany creates a relation where each entity has any number of related
+// entities, e.g. a user having many posts. The related entities are fetched
+// with a single query and attached to the entities with the attach callback.
+//
+//   - name: The name of the relation.
+//   - localKeyFn: Returns the key of the entity, e.g. the user ID.
+//   - foreignTable: The table of the related entities.
+//   - foreignKey: The column of the foreign table referencing the key.
+//   - scanner: The row scanner of the related entities.
+//   - foreignKeyFn: Returns the foreign key value of a related entity.
+//   - attachFn: Attaches the related entities to an entity.
+func HasMany[T any, R any, K comparable](
+	name string,
+	localKeyFn func(entity *T) K,
+	foreignTable string,
+	foreignKey string,
+	scanner RowScannerMultiple[R],
+	foreignKeyFn func(related *R) K,
+	attachFn func(entity *T, related []R),
+) Relation[T] {
+	return Relation[T]{
+		Name: name,
+		preload: func(
+			ctx context.Context,
+			preparer util.Preparer,
+			tenant *Tenant,
+			entities []T,
+		) error {
+			related, err := getRelated(
+				preparer,
+				tenant,
+				entities,
+				localKeyFn,
+				foreignTable,
+				foreignKey,
+				scanner,
+			)
+			if err != nil {
+				return err
+			}
+
+			byKey := map[K][]R{}
+			for i := range related {
+				key := foreignKeyFn(&related[i])
+				byKey[key] = append(byKey[key], related[i])
+			}
+			for i := range entities {
+				attachFn(&entities[i], byKey[localKeyFn(&entities[i])])
+			}
+			return nil
+		},
+	}
+}
+
+// BelongsTo creates a relation where each entity references at most one
+// related entity, e.g. a post belonging to a user. The related entities are
+// fetched with a single query and attached to the entities with the attach
+// callback. The callback receives nil if the related entity does not exist.
+//
+//   - name: The name of the relation.
+//   - localKeyFn: Returns the referencing key of the entity, e.g. the user ID
+//     of a post.
+//   - foreignTable: The table of the related entities.
+//   - foreignKey: The referenced column of the foreign table.
+//   - scanner: The row scanner of the related entities.
+//   - foreignKeyFn: Returns the referenced key of a related entity.
+//   - attachFn: Attaches the related entity to an entity.
+func BelongsTo[T any, R any, K comparable](
+	name string,
+	localKeyFn func(entity *T) K,
+	foreignTable string,
+	foreignKey string,
+	scanner RowScannerMultiple[R],
+	foreignKeyFn func(related *R) K,
+	attachFn func(entity *T, related *R),
+) Relation[T] {
+	return HasMany(
+		name,
+		localKeyFn,
+		foreignTable,
+		foreignKey,
+		scanner,
+		foreignKeyFn,
+		func(entity *T, related []R) {
+			if len(related) == 0 {
+				attachFn(entity, nil)
+				return
+			}
+			attachFn(entity, &related[0])
+		},
+	)
+}
+
+// preload loads the requested relations of the entities.
+func (e *EntityHelpers[T]) preload(
+	ctx context.Context,
+	preparer util.Preparer,
+	tenant *Tenant,
+	entities []T,
+	names []string,
+) error {
+	if len(names) == 0 || len(entities) == 0 {
+		return nil
+	}
+
+	for _, name := range names {
+		relation := e.relation(name)
+		if relation == nil {
+			return RelationNotFoundError.WithData(name)
+		}
+		err := relation.preload(
+			ctx,
+			util.WithContext(ctx, preparer),
+			tenant,
+			entities,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *EntityHelpers[T]) relation(name string) *Relation[T] {
+	for i := range e.Relations {
+		if e.Relations[i].Name == name {
+			return &e.Relations[i]
+		}
+	}
+	return nil
+}
+
+func getRelated[T any, R any, K comparable](
+	preparer util.Preparer,
+	tenant *Tenant,
+	entities []T,
+	localKeyFn func(entity *T) K,
+	foreignTable string,
+	foreignKey string,
+	scanner RowScannerMultiple[R],
+) ([]R, error) {
+	keys := make([]K, 0, len(entities))
+	seen := make(map[K]bool, len(entities))
+	for i := range entities {
+		key := localKeyFn(&entities[i])
+		if !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+
+	tableName := tenant.TableName(foreignTable)
+	return GetEntities(
+		tableName,
+		scanner,
+		preparer,
+		&GetOptions{
+			Options: Options{
+				Selectors: []util.Selector{
+					{
+						Table:     tableName,
+						Field:     foreignKey,
+						Predicate: util.IN,
+						Value:     keys,
+					},
+				},
+			},
+		},
+	)
+}
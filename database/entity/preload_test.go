@@ -0,0 +1,210 @@
+package entity
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pakkasys/fluidapi/database/util"
+	utilmock "github.com/pakkasys/fluidapi/database/util/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type preloadUser struct {
+	ID      int
+	GroupID int
+	Posts   []preloadPost
+	Group   *preloadGroup
+}
+
+type preloadPost struct {
+	ID     int
+	UserID int
+}
+
+type preloadGroup struct {
+	ID int
+}
+
+func expectPreloadQuery(
+	preparer *utilmock.MockDB,
+	query string,
+	params []any,
+	rows [][]int,
+) {
+	mockStmt := new(utilmock.MockStmt)
+	mockRows := new(utilmock.MockRows)
+	preparer.On("Prepare", query).Return(mockStmt, nil).Once()
+	mockStmt.On("Query", params).Return(mockRows, nil)
+	mockStmt.On("Close").Return(nil)
+	for _, row := range rows {
+		mockRows.On("Next").Return(true).Once()
+		mockRows.On("Scan", mock.Anything).Run(func(args mock.Arguments) {
+			for i, dest := range args.Get(0).([]any) {
+				*dest.(*int) = row[i]
+			}
+		}).Return(nil).Once()
+	}
+	mockRows.On("Next").Return(false).Once()
+	mockRows.On("Err").Return(nil)
+	mockRows.On("Close").Return(nil)
+}
+
+func preloadEntityHelpers() *EntityHelpers[preloadUser] {
+	return &EntityHelpers[preloadUser]{
+		TableName: "users",
+		ScanRowFn: func(row util.Row, entity *preloadUser) error {
+			return row.Scan(&entity.ID, &entity.GroupID)
+		},
+		ScanRowsFn: func(rows util.Rows, entity *preloadUser) error {
+			return rows.Scan(&entity.ID, &entity.GroupID)
+		},
+		Relations: []Relation[preloadUser]{
+			HasMany(
+				"posts",
+				func(user *preloadUser) int { return user.ID },
+				"posts",
+				"user_id",
+				func(rows util.Rows, post *preloadPost) error {
+					return rows.Scan(&post.ID, &post.UserID)
+				},
+				func(post *preloadPost) int { return post.UserID },
+				func(user *preloadUser, posts []preloadPost) {
+					user.Posts = posts
+				},
+			),
+			BelongsTo(
+				"group",
+				func(user *preloadUser) int { return user.GroupID },
+				"groups",
+				"id",
+				func(rows util.Rows, group *preloadGroup) error {
+					return rows.Scan(&group.ID)
+				},
+				func(group *preloadGroup) int { return group.ID },
+				func(user *preloadUser, group *preloadGroup) {
+					user.Group = group
+				},
+			),
+		},
+	}
+}
+
+// TestGetEntities_Preload tests that the requested relations are loaded with
+// one query per relation and attached to the entities.
+func TestGetEntities_Preload(t *testing.T) {
+	mockPreparer := new(utilmock.MockDB)
+	expectPreloadQuery(
+		mockPreparer,
+		"SELECT * FROM `users`",
+		[]any(nil),
+		[][]int{{1, 10}, {2, 10}, {3, 20}},
+	)
+	expectPreloadQuery(
+		mockPreparer,
+		"SELECT * FROM `posts` WHERE `posts`.`user_id` IN (?,?,?)",
+		[]any{1, 2, 3},
+		[][]int{{100, 1}, {101, 1}, {102, 3}},
+	)
+	expectPreloadQuery(
+		mockPreparer,
+		"SELECT * FROM `groups` WHERE `groups`.`id` IN (?,?)",
+		[]any{10, 20},
+		[][]int{{10}},
+	)
+
+	users, err := preloadEntityHelpers().GetEntities(
+		context.Background(),
+		mockPreparer,
+		GetOptions{Preload: []string{"posts", "group"}},
+	)
+
+	assert.NoError(t, err)
+	assert.Len(t, users, 3)
+	assert.Equal(
+		t,
+		[]preloadPost{{ID: 100, UserID: 1}, {ID: 101, UserID: 1}},
+		users[0].Posts,
+	)
+	assert.Nil(t, users[1].Posts)
+	assert.Equal(t, []preloadPost{{ID: 102, UserID: 3}}, users[2].Posts)
+	assert.Equal(t, &preloadGroup{ID: 10}, users[0].Group)
+	assert.Equal(t, &preloadGroup{ID: 10}, users[1].Group)
+	assert.Nil(t, users[2].Group)
+	mockPreparer.AssertExpectations(t)
+}
+
+// TestGetEntities_NoPreload tests that no relations are loaded when none are
+// requested.
+func TestGetEntities_NoPreload(t *testing.T) {
+	mockPreparer := new(utilmock.MockDB)
+	expectPreloadQuery(
+		mockPreparer,
+		"SELECT * FROM `users`",
+		[]any(nil),
+		[][]int{{1, 10}},
+	)
+
+	users, err := preloadEntityHelpers().GetEntities(
+		context.Background(),
+		mockPreparer,
+		GetOptions{},
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []preloadUser{{ID: 1, GroupID: 10}}, users)
+	mockPreparer.AssertExpectations(t)
+}
+
+// TestGetEntities_PreloadUnknownRelation tests that an unknown relation
+// returns an error.
+func TestGetEntities_PreloadUnknownRelation(t *testing.T) {
+	mockPreparer := new(utilmock.MockDB)
+	expectPreloadQuery(
+		mockPreparer,
+		"SELECT * FROM `users`",
+		[]any(nil),
+		[][]int{{1, 10}},
+	)
+
+	_, err := preloadEntityHelpers().GetEntities(
+		context.Background(),
+		mockPreparer,
+		GetOptions{Preload: []string{"comments"}},
+	)
+
+	assert.Equal(t, RelationNotFoundError.WithData("comments"), err)
+}
+
+// TestGetEntity_Preload tests that the relations of a single entity are
+// loaded.
+func TestGetEntity_Preload(t *testing.T) {
+	mockPreparer := new(utilmock.MockDB)
+	mockStmt := new(utilmock.MockStmt)
+	mockRow := new(utilmock.MockRow)
+	mockPreparer.On("Prepare", "SELECT * FROM `users`").
+		Return(mockStmt, nil).Once()
+	mockStmt.On("QueryRow", []any(nil)).Return(mockRow)
+	mockStmt.On("Close").Return(nil)
+	mockRow.On("Scan", mock.Anything).Run(func(args mock.Arguments) {
+		*args.Get(0).([]any)[0].(*int) = 1
+		*args.Get(0).([]any)[1].(*int) = 10
+	}).Return(nil)
+	mockRow.On("Err").Return(nil)
+	expectPreloadQuery(
+		mockPreparer,
+		"SELECT * FROM `groups` WHERE `groups`.`id` IN (?)",
+		[]any{10},
+		[][]int{{10}},
+	)
+
+	user, err := preloadEntityHelpers().GetEntity(
+		context.Background(),
+		mockPreparer,
+		GetOptions{Preload: []string{"group"}},
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(t, &preloadGroup{ID: 10}, user.Group)
+	mockPreparer.AssertExpectations(t)
+}
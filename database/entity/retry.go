@@ -0,0 +1,52 @@
+package entity
+
+import (
+	"context"
+
+	"github.com/pakkasys/fluidapi/database/transaction"
+	"github.com/pakkasys/fluidapi/database/util"
+)
+
+// retryStaleRead runs an idempotent read and retries it once if it failed
+// because of a stale connection. Reads bound to a transaction are never
+// retried since the transaction cannot continue on a fresh connection.
+//
+//   - enabled: Whether the retry is enabled.
+//   - preparer: The preparer used by the read.
+//   - readFn: The read to run.
+func retryStaleRead[R any](
+	enabled bool,
+	preparer util.Preparer,
+	readFn func() (R, error),
+) (R, error) {
+	result, err := readFn()
+	if !enabled || !util.IsStaleConnectionError(err) {
+		return result, err
+	}
+	if _, isTx := preparer.(util.Tx); isTx {
+		return result, err
+	}
+	return readFn()
+}
+
+// retryStaleManagedRead runs an idempotent read in a managed transaction and
+// retries it once in a new transaction if it failed because of a stale
+// connection. The read is only retried if the managed transaction was created
+// for it, since a transaction from the context cannot be restarted.
+//
+//   - ctx: The context used for the managed transaction.
+//   - enabled: Whether the retry is enabled.
+//   - readFn: The managed read to run.
+func retryStaleManagedRead[R any](
+	ctx context.Context,
+	enabled bool,
+	readFn func(ctx context.Context) (R, error),
+) (R, error) {
+	ownsTx := transaction.GetTransactionFromContext(ctx) == nil
+
+	result, err := readFn(ctx)
+	if !enabled || !ownsTx || !util.IsStaleConnectionError(err) {
+		return result, err
+	}
+	return readFn(ctx)
+}
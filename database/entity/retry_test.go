@@ -0,0 +1,192 @@
+package entity
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"testing"
+
+	"github.com/pakkasys/fluidapi/database/transaction"
+	"github.com/pakkasys/fluidapi/database/util"
+	utilmock "github.com/pakkasys/fluidapi/database/util/mock"
+	endpointutil "github.com/pakkasys/fluidapi/endpoint/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// TestRetryStaleRead_Retries tests that a stale connection error is retried
+// once when the preparer is not a transaction.
+func TestRetryStaleRead_Retries(t *testing.T) {
+	calls := 0
+	result, err := retryStaleRead(
+		true,
+		new(utilmock.MockDB),
+		func() (int, error) {
+			calls++
+			if calls == 1 {
+				return 0, driver.ErrBadConn
+			}
+			return 42, nil
+		},
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 42, result)
+	assert.Equal(t, 2, calls)
+}
+
+// TestRetryStaleRead_RetriesOnlyOnce tests that the read is retried at most
+// once.
+func TestRetryStaleRead_RetriesOnlyOnce(t *testing.T) {
+	calls := 0
+	_, err := retryStaleRead(
+		true,
+		new(utilmock.MockDB),
+		func() (int, error) {
+			calls++
+			return 0, driver.ErrBadConn
+		},
+	)
+
+	assert.ErrorIs(t, err, driver.ErrBadConn)
+	assert.Equal(t, 2, calls)
+}
+
+// TestRetryStaleRead_Disabled tests that the read is not retried when the
+// retry is disabled.
+func TestRetryStaleRead_Disabled(t *testing.T) {
+	calls := 0
+	_, err := retryStaleRead(
+		false,
+		new(utilmock.MockDB),
+		func() (int, error) {
+			calls++
+			return 0, driver.ErrBadConn
+		},
+	)
+
+	assert.ErrorIs(t, err, driver.ErrBadConn)
+	assert.Equal(t, 1, calls)
+}
+
+// TestRetryStaleRead_Transaction tests that reads bound to a transaction are
+// not retried.
+func TestRetryStaleRead_Transaction(t *testing.T) {
+	calls := 0
+	_, err := retryStaleRead(
+		true,
+		new(utilmock.MockTx),
+		func() (int, error) {
+			calls++
+			return 0, driver.ErrBadConn
+		},
+	)
+
+	assert.ErrorIs(t, err, driver.ErrBadConn)
+	assert.Equal(t, 1, calls)
+}
+
+// TestRetryStaleRead_OtherError tests that other errors are not retried.
+func TestRetryStaleRead_OtherError(t *testing.T) {
+	calls := 0
+	_, err := retryStaleRead(
+		true,
+		new(utilmock.MockDB),
+		func() (int, error) {
+			calls++
+			return 0, errors.New("syntax error")
+		},
+	)
+
+	assert.EqualError(t, err, "syntax error")
+	assert.Equal(t, 1, calls)
+}
+
+// TestRetryStaleManagedRead_Retries tests that a managed read owning its
+// transaction is retried once on a stale connection error.
+func TestRetryStaleManagedRead_Retries(t *testing.T) {
+	ctx := endpointutil.NewContext(context.Background())
+
+	calls := 0
+	result, err := retryStaleManagedRead(
+		ctx,
+		true,
+		func(ctx context.Context) (int, error) {
+			calls++
+			if calls == 1 {
+				return 0, driver.ErrBadConn
+			}
+			return 42, nil
+		},
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 42, result)
+	assert.Equal(t, 2, calls)
+}
+
+// TestRetryStaleManagedRead_ExistingTransaction tests that a managed read
+// using a transaction from the context is not retried.
+func TestRetryStaleManagedRead_ExistingTransaction(t *testing.T) {
+	ctx := endpointutil.NewContext(context.Background())
+	transaction.SetTransactionToContext(ctx, new(utilmock.MockTx))
+
+	calls := 0
+	_, err := retryStaleManagedRead(
+		ctx,
+		true,
+		func(ctx context.Context) (int, error) {
+			calls++
+			return 0, driver.ErrBadConn
+		},
+	)
+
+	assert.ErrorIs(t, err, driver.ErrBadConn)
+	assert.Equal(t, 1, calls)
+}
+
+// TestGetEntityWithManagedTransaction_RetryStaleReads tests that the entity
+// helpers retry a managed read in a new transaction after a stale connection
+// error.
+func TestGetEntityWithManagedTransaction_RetryStaleReads(t *testing.T) {
+	ctx := endpointutil.NewContext(context.Background())
+
+	staleTx := new(utilmock.MockTx)
+	freshTx := new(utilmock.MockTx)
+	mockStmt := new(utilmock.MockStmt)
+	mockRow := new(utilmock.MockRow)
+
+	txs := []util.Tx{staleTx, freshTx}
+	entityHelpers := EntityHelpers[TestEntity]{
+		TableName: "test_table",
+		GetTxFn: func(ctx context.Context) (util.Tx, error) {
+			tx := txs[0]
+			txs = txs[1:]
+			return tx, nil
+		},
+		ScanRowFn: func(row util.Row, entity *TestEntity) error {
+			entity.ID = 1
+			return nil
+		},
+		RetryStaleReads: true,
+	}
+
+	staleTx.On("Prepare", mock.Anything).Return(nil, driver.ErrBadConn)
+	staleTx.On("Rollback").Return(nil)
+	freshTx.On("Prepare", mock.Anything).Return(mockStmt, nil)
+	freshTx.On("Commit").Return(nil)
+	mockStmt.On("QueryRow", mock.Anything).Return(mockRow)
+	mockStmt.On("Close").Return(nil)
+	mockRow.On("Err").Return(nil)
+
+	result, err := entityHelpers.GetEntityWithManagedTransaction(
+		ctx,
+		GetOptions{},
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.ID)
+
+	staleTx.AssertExpectations(t)
+	freshTx.AssertExpectations(t)
+}
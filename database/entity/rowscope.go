@@ -0,0 +1,29 @@
+package entity
+
+import (
+	"context"
+
+	"github.com/pakkasys/fluidapi/database/util"
+)
+
+// RowScope derives selectors from the context that restrict the rows an
+// operation can see, e.g. a tenant or ownership condition.
+type RowScope func(ctx context.Context) []util.Selector
+
+// scopeRows returns the selectors extended with the row scope selectors of
+// the context. The given selectors are not modified.
+func (e *EntityHelpers[T]) scopeRows(
+	ctx context.Context,
+	selectors []util.Selector,
+) []util.Selector {
+	if e.RowScope == nil {
+		return selectors
+	}
+	scope := e.RowScope(ctx)
+	if len(scope) == 0 {
+		return selectors
+	}
+	scoped := make([]util.Selector, 0, len(selectors)+len(scope))
+	scoped = append(scoped, selectors...)
+	return append(scoped, scope...)
+}
@@ -0,0 +1,135 @@
+package entity
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pakkasys/fluidapi/database/util"
+	utilmock "github.com/pakkasys/fluidapi/database/util/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+type rowScopeKey struct{}
+
+func testRowScope(ctx context.Context) []util.Selector {
+	tenantID, ok := ctx.Value(rowScopeKey{}).(int)
+	if !ok {
+		return nil
+	}
+	return []util.Selector{
+		{
+			Table:     "users",
+			Field:     "tenant_id",
+			Predicate: util.EQUAL,
+			Value:     tenantID,
+		},
+	}
+}
+
+// TestScopeRows_NoRowScope tests that the selectors are unchanged without a
+// row scope.
+func TestScopeRows_NoRowScope(t *testing.T) {
+	entityHelpers := EntityHelpers[TestEntity]{}
+	selectors := []util.Selector{{Field: "id", Value: 1}}
+
+	scoped := entityHelpers.scopeRows(context.Background(), selectors)
+
+	assert.Equal(t, selectors, scoped)
+}
+
+// TestScopeRows_AppendsSelectors tests that the row scope selectors are
+// appended without modifying the given selectors.
+func TestScopeRows_AppendsSelectors(t *testing.T) {
+	entityHelpers := EntityHelpers[TestEntity]{RowScope: testRowScope}
+	selectors := make([]util.Selector, 1, 2)
+	selectors[0] = util.Selector{Field: "id", Value: 1}
+	ctx := context.WithValue(context.Background(), rowScopeKey{}, 7)
+
+	scoped := entityHelpers.scopeRows(ctx, selectors)
+
+	assert.Len(t, scoped, 2)
+	assert.Equal(t, "tenant_id", scoped[1].Field)
+	assert.Equal(t, 7, scoped[1].Value)
+	assert.Len(t, selectors, 1)
+	assert.Equal(t, util.Selector{}, selectors[:2][1])
+}
+
+// TestGetEntities_RowScope tests that the row scope selectors are added to
+// the get query.
+func TestGetEntities_RowScope(t *testing.T) {
+	mockPreparer := new(utilmock.MockDB)
+	mockStmt := new(utilmock.MockStmt)
+	mockRows := new(utilmock.MockRows)
+
+	entityHelpers := EntityHelpers[TestEntity]{
+		TableName: "users",
+		ScanRowsFn: func(rows util.Rows, entity *TestEntity) error {
+			return nil
+		},
+		RowScope: testRowScope,
+	}
+
+	mockPreparer.On(
+		"Prepare",
+		"SELECT * FROM `users` WHERE `users`.`id` = ? AND"+
+			" `users`.`tenant_id` = ?",
+	).Return(mockStmt, nil)
+	mockStmt.On("Query", []any{1, 7}).Return(mockRows, nil)
+	mockStmt.On("Close").Return(nil)
+	mockRows.On("Next").Return(false)
+	mockRows.On("Err").Return(nil)
+	mockRows.On("Close").Return(nil)
+
+	_, err := entityHelpers.GetEntities(
+		context.WithValue(context.Background(), rowScopeKey{}, 7),
+		mockPreparer,
+		GetOptions{
+			Options: Options{
+				Selectors: []util.Selector{
+					{
+						Table:     "users",
+						Field:     "id",
+						Predicate: util.EQUAL,
+						Value:     1,
+					},
+				},
+			},
+		},
+	)
+
+	assert.NoError(t, err)
+	mockPreparer.AssertExpectations(t)
+	mockStmt.AssertExpectations(t)
+}
+
+// TestDeleteEntities_RowScope tests that the row scope selectors are added
+// to the delete query.
+func TestDeleteEntities_RowScope(t *testing.T) {
+	mockPreparer := new(utilmock.MockDB)
+	mockStmt := new(utilmock.MockStmt)
+	mockResult := new(utilmock.MockResult)
+
+	entityHelpers := EntityHelpers[TestEntity]{
+		TableName: "users",
+		RowScope:  testRowScope,
+	}
+
+	mockPreparer.On(
+		"Prepare",
+		"DELETE FROM `users` WHERE `users`.`tenant_id` = ?",
+	).Return(mockStmt, nil)
+	mockStmt.On("Exec", []any{7}).Return(mockResult, nil)
+	mockStmt.On("Close").Return(nil)
+	mockResult.On("RowsAffected").Return(int64(1), nil)
+
+	count, err := entityHelpers.DeleteEntities(
+		context.WithValue(context.Background(), rowScopeKey{}, 7),
+		mockPreparer,
+		nil,
+		nil,
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+	mockPreparer.AssertExpectations(t)
+}
@@ -0,0 +1,393 @@
+package entity
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"slices"
+	"sync"
+
+	"github.com/pakkasys/fluidapi/core/api"
+	"github.com/pakkasys/fluidapi/database/util"
+	"github.com/pakkasys/fluidapi/endpoint/page"
+)
+
+var ShardKeyNotFoundError = api.NewError[any]("SHARD_KEY_NOT_FOUND")
+
+// ShardMergeOrderError is returned when entities gathered from several shards
+// are ordered or paged without a CompareFn.
+var ShardMergeOrderError = api.NewError[any]("SHARD_MERGE_ORDER")
+
+// ShardResolver maps a shard key value to the index of a shard.
+type ShardResolver func(key any, shardCount int) (int, error)
+
+// HashShardResolver maps a shard key value to a shard by hashing its string
+// representation.
+//
+//   - key: The shard key value.
+//   - shardCount: The number of shards.
+func HashShardResolver(key any, shardCount int) (int, error) {
+	if shardCount <= 0 {
+		return 0, fmt.Errorf("no shards configured")
+	}
+	hash := fnv.New32a()
+	hash.Write([]byte(fmt.Sprint(key)))
+	return int(hash.Sum32() % uint32(shardCount)), nil
+}
+
+// ShardedEntityHelpers routes entity operations to one of several database
+// shards. Operations are routed by the value of the shard key selector, or by
+// the shard key of the entity when creating entities.
+type ShardedEntityHelpers[T any] struct {
+	// Helpers are the entity helpers used for the operations on a shard
+	Helpers *EntityHelpers[T]
+	// Shards are the database handles of the shards
+	Shards []util.DB
+	// ShardKeyField is the field of the selector containing the shard key
+	ShardKeyField string
+	// ShardKeyFn returns the shard key of an entity
+	ShardKeyFn func(entity *T) any
+	// ShardResolver maps a shard key to a shard, defaults to
+	// HashShardResolver
+	ShardResolver ShardResolver
+	// CompareFn orders the entities merged from several shards. It is
+	// required for ordered and paged gets without the shard key.
+	CompareFn func(a *T, b *T, orders []util.Order) int
+}
+
+// NewShardedEntityHelpers creates new sharded entity helpers routing by the
+// shard key field of the selectors and the shard key of the entities.
+//
+//   - helpers: The entity helpers used for the operations on a shard.
+//   - shards: The database handles of the shards.
+//   - shardKeyField: The field of the selector containing the shard key.
+//   - shardKeyFn: Returns the shard key of an entity.
+func NewShardedEntityHelpers[T any](
+	helpers *EntityHelpers[T],
+	shards []util.DB,
+	shardKeyField string,
+	shardKeyFn func(entity *T) any,
+) *ShardedEntityHelpers[T] {
+	if helpers == nil {
+		panic("helpers cannot be nil")
+	}
+	if shardKeyFn == nil {
+		panic("shard key function cannot be nil")
+	}
+	return &ShardedEntityHelpers[T]{
+		Helpers:       helpers,
+		Shards:        shards,
+		ShardKeyField: shardKeyField,
+		ShardKeyFn:    shardKeyFn,
+	}
+}
+
+// Shard returns the shard of the shard key.
+//
+//   - key: The shard key value.
+func (s *ShardedEntityHelpers[T]) Shard(key any) (util.DB, error) {
+	index, err := s.shardIndex(key)
+	if err != nil {
+		return nil, err
+	}
+	return s.Shards[index], nil
+}
+
+// CreateEntity creates or upserts an entity in the shard of its shard key.
+//
+//   - ctx: The context of the operation.
+//   - entity: The entity to create or upsert.
+//   - opts: The options struct for upserting the entity.
+func (s *ShardedEntityHelpers[T]) CreateEntity(
+	ctx context.Context,
+	entity *T,
+	opts *UpsertOptions,
+) (*T, error) {
+	shard, err := s.shardForEntity(entity)
+	if err != nil {
+		return nil, err
+	}
+	return s.Helpers.CreateEntity(ctx, shard, entity, opts)
+}
+
+// CreateEntities creates or upserts entities, grouped by the shards of their
+// shard keys. The shards are written one after another and not atomically:
+// if writing to a shard fails, the entities already written to the previous
+// shards are returned with the error.
+//
+//   - ctx: The context of the operation.
+//   - entities: The entities to create or upsert.
+//   - opts: The options struct for upserting the entities.
+func (s *ShardedEntityHelpers[T]) CreateEntities(
+	ctx context.Context,
+	entities []*T,
+	opts *UpsertOptions,
+) ([]*T, error) {
+	// The entities are grouped by the index of the shard, since the
+	// database handles are not necessarily comparable.
+	indexes := []int{}
+	groups := map[int][]*T{}
+	for _, entity := range entities {
+		index, err := s.shardIndexForEntity(entity)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := groups[index]; !ok {
+			indexes = append(indexes, index)
+		}
+		groups[index] = append(groups[index], entity)
+	}
+
+	written := []*T{}
+	for _, index := range indexes {
+		_, err := s.Helpers.CreateEntities(
+			ctx,
+			s.Shards[index],
+			groups[index],
+			opts,
+		)
+		if err != nil {
+			return written, err
+		}
+		written = append(written, groups[index]...)
+	}
+
+	return entities, nil
+}
+
+// GetEntity gets an entity from the shard of the shard key selector.
+//
+//   - ctx: The context of the operation.
+//   - opts: The options struct for getting the entity.
+func (s *ShardedEntityHelpers[T]) GetEntity(
+	ctx context.Context,
+	opts GetOptions,
+) (*T, error) {
+	shard, err := s.shardForSelectors(opts.Selectors)
+	if err != nil {
+		return nil, err
+	}
+	return s.Helpers.GetEntity(ctx, shard, opts)
+}
+
+// GetEntities gets entities from the shard of the shard key selector. If the
+// selectors do not contain the shard key, the entities are gathered from all
+// shards and merged using CompareFn. The page of the options is applied to
+// the merged entities. Ordered or paged gets without the shard key return
+// ShardMergeOrderError if CompareFn is not set.
+//
+//   - ctx: The context of the operation.
+//   - opts: The options struct for getting the entities.
+func (s *ShardedEntityHelpers[T]) GetEntities(
+	ctx context.Context,
+	opts GetOptions,
+) ([]T, error) {
+	if s.hasShardKey(opts.Selectors) {
+		shard, err := s.shardForSelectors(opts.Selectors)
+		if err != nil {
+			return nil, err
+		}
+		return s.Helpers.GetEntities(ctx, shard, opts)
+	}
+	if s.CompareFn == nil && (len(opts.Orders) != 0 || opts.Page != nil) {
+		return nil, ShardMergeOrderError
+	}
+
+	shardOpts := opts
+	if opts.Page != nil {
+		shardOpts.Page = &page.Page{
+			Offset: 0,
+			Limit:  opts.Page.Offset + opts.Page.Limit,
+		}
+	}
+
+	results, err := scatter(
+		s.Shards,
+		func(shard util.DB) ([]T, error) {
+			return s.Helpers.GetEntities(ctx, shard, shardOpts)
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	merged := []T{}
+	for _, result := range results {
+		merged = append(merged, result...)
+	}
+	if s.CompareFn != nil {
+		slices.SortStableFunc(merged, func(a T, b T) int {
+			return s.CompareFn(&a, &b, opts.Orders)
+		})
+	}
+
+	return applyPage(merged, opts.Page), nil
+}
+
+// GetEntityCount counts the entities in the shard of the shard key selector.
+// If the selectors do not contain the shard key, the counts of all shards are
+// summed.
+//
+//   - ctx: The context of the operation.
+//   - selectors: The selectors for the query.
+//   - joins: The joins for the query.
+func (s *ShardedEntityHelpers[T]) GetEntityCount(
+	ctx context.Context,
+	selectors []util.Selector,
+	joins []util.Join,
+) (int, error) {
+	if s.hasShardKey(selectors) {
+		shard, err := s.shardForSelectors(selectors)
+		if err != nil {
+			return 0, err
+		}
+		return s.Helpers.GetEntityCount(ctx, shard, selectors, joins)
+	}
+
+	counts, err := scatter(
+		s.Shards,
+		func(shard util.DB) (int, error) {
+			return s.Helpers.GetEntityCount(ctx, shard, selectors, joins)
+		},
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	total := 0
+	for _, count := range counts {
+		total += count
+	}
+	return total, nil
+}
+
+// UpdateEntities updates entities in the shard of the shard key selector.
+//
+//   - ctx: The context of the operation.
+//   - selectors: The selectors for the query.
+//   - updates: The update options for the query.
+func (s *ShardedEntityHelpers[T]) UpdateEntities(
+	ctx context.Context,
+	selectors []util.Selector,
+	updates Updates,
+) (int64, error) {
+	shard, err := s.shardForSelectors(selectors)
+	if err != nil {
+		return 0, err
+	}
+	return s.Helpers.UpdateEntities(ctx, shard, selectors, updates)
+}
+
+// DeleteEntities deletes entities in the shard of the shard key selector.
+//
+//   - ctx: The context of the operation.
+//   - selectors: The selectors for the query.
+//   - opts: The options struct for deleting the entities.
+func (s *ShardedEntityHelpers[T]) DeleteEntities(
+	ctx context.Context,
+	selectors []util.Selector,
+	opts *DeleteOptions,
+) (int64, error) {
+	shard, err := s.shardForSelectors(selectors)
+	if err != nil {
+		return 0, err
+	}
+	return s.Helpers.DeleteEntities(ctx, shard, selectors, opts)
+}
+
+func (s *ShardedEntityHelpers[T]) shardIndex(key any) (int, error) {
+	resolver := s.ShardResolver
+	if resolver == nil {
+		resolver = HashShardResolver
+	}
+	index, err := resolver(key, len(s.Shards))
+	if err != nil {
+		return 0, err
+	}
+	if index < 0 || index >= len(s.Shards) {
+		return 0, fmt.Errorf("shard index out of range: %d", index)
+	}
+	return index, nil
+}
+
+func (s *ShardedEntityHelpers[T]) shardForEntity(entity *T) (util.DB, error) {
+	index, err := s.shardIndexForEntity(entity)
+	if err != nil {
+		return nil, err
+	}
+	return s.Shards[index], nil
+}
+
+func (s *ShardedEntityHelpers[T]) shardIndexForEntity(entity *T) (int, error) {
+	if s.ShardKeyFn == nil {
+		return 0, fmt.Errorf("shard key function not configured")
+	}
+	return s.shardIndex(s.ShardKeyFn(entity))
+}
+
+func (s *ShardedEntityHelpers[T]) shardKeySelector(
+	selectors []util.Selector,
+) *util.Selector {
+	for i := range selectors {
+		if selectors[i].Field == s.ShardKeyField &&
+			selectors[i].Predicate == util.EQUAL {
+			return &selectors[i]
+		}
+	}
+	return nil
+}
+
+func (s *ShardedEntityHelpers[T]) hasShardKey(
+	selectors []util.Selector,
+) bool {
+	return s.shardKeySelector(selectors) != nil
+}
+
+func (s *ShardedEntityHelpers[T]) shardForSelectors(
+	selectors []util.Selector,
+) (util.DB, error) {
+	selector := s.shardKeySelector(selectors)
+	if selector == nil {
+		return nil, ShardKeyNotFoundError
+	}
+	return s.Shard(selector.Value)
+}
+
+func scatter[R any](
+	shards []util.DB,
+	queryFn func(shard util.DB) (R, error),
+) ([]R, error) {
+	results := make([]R, len(shards))
+	errs := make([]error, len(shards))
+
+	var wg sync.WaitGroup
+	for i, shard := range shards {
+		wg.Add(1)
+		go func(i int, shard util.DB) {
+			defer wg.Done()
+			results[i], errs[i] = queryFn(shard)
+		}(i, shard)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
+
+func applyPage[T any](entities []T, p *page.Page) []T {
+	if p == nil {
+		return entities
+	}
+	if p.Offset >= len(entities) {
+		return []T{}
+	}
+	end := p.Offset + p.Limit
+	if end > len(entities) {
+		end = len(entities)
+	}
+	return entities[p.Offset:end]
+}
@@ -0,0 +1,381 @@
+package entity
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	entitymock "github.com/pakkasys/fluidapi/database/entity/mock"
+	"github.com/pakkasys/fluidapi/database/util"
+	utilmock "github.com/pakkasys/fluidapi/database/util/mock"
+	"github.com/pakkasys/fluidapi/endpoint/page"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func modShardResolver(key any, shardCount int) (int, error) {
+	return key.(int) % shardCount, nil
+}
+
+func shardRows(
+	shard *utilmock.MockDB,
+	rows *utilmock.MockRows,
+	count int,
+) {
+	mockStmt := new(utilmock.MockStmt)
+	shard.On("Prepare", mock.Anything).Return(mockStmt, nil)
+	mockStmt.On("Query", mock.Anything).Return(rows, nil)
+	mockStmt.On("Close").Return(nil)
+	rows.On("Next").Return(true).Times(count)
+	rows.On("Next").Return(false)
+	rows.On("Err").Return(nil)
+	rows.On("Close").Return(nil)
+}
+
+// TestHashShardResolver tests that the hash resolver is deterministic and
+// within the shard range.
+func TestHashShardResolver(t *testing.T) {
+	first, err := HashShardResolver("user-1", 4)
+	assert.NoError(t, err)
+	second, err := HashShardResolver("user-1", 4)
+	assert.NoError(t, err)
+
+	assert.Equal(t, first, second)
+	assert.GreaterOrEqual(t, first, 0)
+	assert.Less(t, first, 4)
+}
+
+// TestHashShardResolver_NoShards tests that the hash resolver returns an
+// error without shards.
+func TestHashShardResolver_NoShards(t *testing.T) {
+	_, err := HashShardResolver("user-1", 0)
+	assert.EqualError(t, err, "no shards configured")
+}
+
+// TestShard_OutOfRange tests that an out of range shard index is an error.
+func TestShard_OutOfRange(t *testing.T) {
+	helpers := ShardedEntityHelpers[TestEntity]{
+		Shards: []util.DB{new(utilmock.MockDB)},
+		ShardResolver: func(key any, shardCount int) (int, error) {
+			return 1, nil
+		},
+	}
+
+	_, err := helpers.Shard(1)
+
+	assert.EqualError(t, err, "shard index out of range: 1")
+}
+
+// TestShardedGetEntity_Routes tests that a get is routed to the shard of the
+// shard key selector.
+func TestShardedGetEntity_Routes(t *testing.T) {
+	shard0 := new(utilmock.MockDB)
+	shard1 := new(utilmock.MockDB)
+	mockStmt := new(utilmock.MockStmt)
+	mockRow := new(utilmock.MockRow)
+
+	helpers := ShardedEntityHelpers[TestEntity]{
+		Helpers: &EntityHelpers[TestEntity]{
+			TableName: "users",
+			ScanRowFn: func(row util.Row, entity *TestEntity) error {
+				entity.ID = 3
+				return nil
+			},
+		},
+		Shards:        []util.DB{shard0, shard1},
+		ShardKeyField: "id",
+		ShardResolver: modShardResolver,
+	}
+
+	shard1.On("Prepare", mock.Anything).Return(mockStmt, nil)
+	mockStmt.On("QueryRow", mock.Anything).Return(mockRow)
+	mockStmt.On("Close").Return(nil)
+	mockRow.On("Err").Return(nil)
+
+	entity, err := helpers.GetEntity(
+		context.Background(),
+		GetOptions{
+			Options: Options{
+				Selectors: []util.Selector{
+					{Field: "id", Predicate: util.EQUAL, Value: 3},
+				},
+			},
+		},
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, entity.ID)
+	shard0.AssertNotCalled(t, "Prepare", mock.Anything)
+	shard1.AssertExpectations(t)
+}
+
+// TestShardedUpdateEntities_NoShardKey tests that an update without the shard
+// key selector returns ShardKeyNotFoundError.
+func TestShardedUpdateEntities_NoShardKey(t *testing.T) {
+	helpers := ShardedEntityHelpers[TestEntity]{
+		Helpers:       &EntityHelpers[TestEntity]{TableName: "users"},
+		Shards:        []util.DB{new(utilmock.MockDB)},
+		ShardKeyField: "id",
+	}
+
+	_, err := helpers.UpdateEntities(
+		context.Background(),
+		[]util.Selector{{Field: "name", Predicate: util.EQUAL, Value: "A"}},
+		Updates{{Field: "name", Value: "B"}},
+	)
+
+	assert.Equal(t, ShardKeyNotFoundError, err)
+}
+
+// TestShardedCreateEntities_GroupsByShard tests that entities are created in
+// the shards of their shard keys.
+func TestShardedCreateEntities_GroupsByShard(t *testing.T) {
+	shard0 := new(utilmock.MockDB)
+	shard1 := new(utilmock.MockDB)
+
+	helpers := ShardedEntityHelpers[TestEntity]{
+		Helpers: &EntityHelpers[TestEntity]{
+			TableName: "users",
+			InserterFn: func(entity *TestEntity) ([]string, []any) {
+				return []string{"id"}, []any{entity.ID}
+			},
+		},
+		Shards:        []util.DB{shard0, shard1},
+		ShardKeyFn:    func(entity *TestEntity) any { return entity.ID },
+		ShardResolver: modShardResolver,
+	}
+
+	for shard, values := range map[*utilmock.MockDB][]any{
+		shard0: {2},
+		shard1: {1, 3},
+	} {
+		mockStmt := new(utilmock.MockStmt)
+		mockResult := new(utilmock.MockResult)
+		shard.On("Prepare", mock.Anything).Return(mockStmt, nil).Once()
+		mockStmt.On("Exec", values).Return(mockResult, nil)
+		mockStmt.On("Close").Return(nil)
+		mockResult.On("LastInsertId").Return(int64(1), nil)
+	}
+
+	entities := []*TestEntity{{ID: 1}, {ID: 2}, {ID: 3}}
+	result, err := helpers.CreateEntities(
+		context.Background(),
+		entities,
+		nil,
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(t, entities, result)
+	shard0.AssertExpectations(t)
+	shard1.AssertExpectations(t)
+}
+
+// uncomparableDB is a database handle that cannot be used as a map key.
+type uncomparableDB struct {
+	*utilmock.MockDB
+	tags []string
+}
+
+// TestShardedCreateEntities_UncomparableShards tests that entities are
+// grouped by shard when the database handles are not comparable.
+func TestShardedCreateEntities_UncomparableShards(t *testing.T) {
+	mockDB := new(utilmock.MockDB)
+	helpers := ShardedEntityHelpers[TestEntity]{
+		Helpers: &EntityHelpers[TestEntity]{
+			TableName: "users",
+			InserterFn: func(entity *TestEntity) ([]string, []any) {
+				return []string{"id"}, []any{entity.ID}
+			},
+		},
+		Shards: []util.DB{
+			uncomparableDB{MockDB: mockDB, tags: []string{"a"}},
+		},
+		ShardKeyFn:    func(entity *TestEntity) any { return entity.ID },
+		ShardResolver: modShardResolver,
+	}
+	mockStmt := new(utilmock.MockStmt)
+	mockResult := new(utilmock.MockResult)
+	mockDB.On("Prepare", mock.Anything).Return(mockStmt, nil).Once()
+	mockStmt.On("Exec", []any{1, 2}).Return(mockResult, nil)
+	mockStmt.On("Close").Return(nil)
+	mockResult.On("LastInsertId").Return(int64(1), nil)
+
+	entities := []*TestEntity{{ID: 1}, {ID: 2}}
+	result, err := helpers.CreateEntities(
+		context.Background(),
+		entities,
+		nil,
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(t, entities, result)
+	mockDB.AssertExpectations(t)
+}
+
+// TestShardedGetEntities_ScatterGather tests that entities are gathered from
+// all shards, merged in order and paged when the shard key is missing.
+func TestShardedGetEntities_ScatterGather(t *testing.T) {
+	shard0 := new(utilmock.MockDB)
+	shard1 := new(utilmock.MockDB)
+	rows0 := new(utilmock.MockRows)
+	rows1 := new(utilmock.MockRows)
+
+	shardRows(shard0, rows0, 2)
+	shardRows(shard1, rows1, 2)
+
+	values := map[util.Rows][]int{rows0: {4, 1}, rows1: {3, 2}}
+	scanned := map[util.Rows]int{}
+	var mu sync.Mutex
+
+	helpers := ShardedEntityHelpers[TestEntity]{
+		Helpers: &EntityHelpers[TestEntity]{
+			TableName: "users",
+			ScanRowsFn: func(rows util.Rows, entity *TestEntity) error {
+				mu.Lock()
+				defer mu.Unlock()
+				entity.ID = values[rows][scanned[rows]]
+				scanned[rows]++
+				return nil
+			},
+		},
+		Shards:        []util.DB{shard0, shard1},
+		ShardKeyField: "id",
+		CompareFn: func(a *TestEntity, b *TestEntity, _ []util.Order) int {
+			return a.ID - b.ID
+		},
+	}
+
+	entities, err := helpers.GetEntities(
+		context.Background(),
+		GetOptions{
+			Options: Options{
+				Page: &page.Page{Offset: 1, Limit: 2},
+			},
+		},
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []TestEntity{{ID: 2}, {ID: 3}}, entities)
+}
+
+// TestShardedGetEntityCount_Sum tests that the counts of all shards are
+// summed when the shard key is missing.
+func TestShardedGetEntityCount_Sum(t *testing.T) {
+	shards := []util.DB{}
+	for range 2 {
+		shard := new(utilmock.MockDB)
+		mockStmt := new(utilmock.MockStmt)
+		mockRow := new(utilmock.MockRow)
+		shard.On("Prepare", mock.Anything).Return(mockStmt, nil)
+		mockStmt.On("QueryRow", mock.Anything).Return(mockRow)
+		mockStmt.On("Close").Return(nil)
+		mockRow.On("Scan", mock.Anything).Run(func(args mock.Arguments) {
+			*args.Get(0).([]any)[0].(*int) = 2
+		}).Return(nil)
+		shards = append(shards, shard)
+	}
+
+	helpers := ShardedEntityHelpers[TestEntity]{
+		Helpers:       &EntityHelpers[TestEntity]{TableName: "users"},
+		Shards:        shards,
+		ShardKeyField: "id",
+	}
+
+	count, err := helpers.GetEntityCount(context.Background(), nil, nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 4, count)
+}
+
+// TestNewShardedEntityHelpers tests that the constructor requires the
+// helpers and the shard key function.
+func TestNewShardedEntityHelpers(t *testing.T) {
+	helpers := &EntityHelpers[TestEntity]{TableName: "users"}
+	shardKeyFn := func(entity *TestEntity) any { return entity.ID }
+
+	sharded := NewShardedEntityHelpers(helpers, nil, "id", shardKeyFn)
+	assert.Equal(t, helpers, sharded.Helpers)
+	assert.Equal(t, "id", sharded.ShardKeyField)
+
+	assert.PanicsWithValue(t, "helpers cannot be nil", func() {
+		NewShardedEntityHelpers[TestEntity](nil, nil, "id", shardKeyFn)
+	})
+	assert.PanicsWithValue(t, "shard key function cannot be nil", func() {
+		NewShardedEntityHelpers(helpers, nil, "id", nil)
+	})
+}
+
+// TestShardedCreateEntities_PartialWrite tests that the entities written
+// before a failing shard are returned with the error.
+func TestShardedCreateEntities_PartialWrite(t *testing.T) {
+	shard0 := new(utilmock.MockDB)
+	shard1 := new(utilmock.MockDB)
+	mockSQLUtil := new(entitymock.MockSQLUtil)
+	helpers := ShardedEntityHelpers[TestEntity]{
+		Helpers: &EntityHelpers[TestEntity]{
+			TableName: "users",
+			InserterFn: func(entity *TestEntity) ([]string, []any) {
+				return []string{"id"}, []any{entity.ID}
+			},
+			SQLUtil: mockSQLUtil,
+		},
+		Shards:        []util.DB{shard0, shard1},
+		ShardKeyFn:    func(entity *TestEntity) any { return entity.ID },
+		ShardResolver: modShardResolver,
+	}
+
+	mockStmt := new(utilmock.MockStmt)
+	mockResult := new(utilmock.MockResult)
+	shard1.On("Prepare", mock.Anything).Return(mockStmt, nil).Once()
+	mockStmt.On("Exec", mock.Anything).Return(mockResult, nil)
+	mockStmt.On("Close").Return(nil)
+	mockResult.On("LastInsertId").Return(int64(1), nil)
+	shard0.On("Prepare", mock.Anything).
+		Return(nil, assert.AnError).
+		Once()
+	mockSQLUtil.On("CheckDBError", assert.AnError).Return(assert.AnError)
+
+	entities := []*TestEntity{{ID: 1}, {ID: 2}}
+	written, err := helpers.CreateEntities(
+		context.Background(),
+		entities,
+		nil,
+	)
+
+	assert.ErrorIs(t, err, assert.AnError)
+	assert.Equal(t, []*TestEntity{{ID: 1}}, written)
+}
+
+// TestShardedCreateEntity_NoShardKeyFn tests that creating entities requires
+// the shard key function.
+func TestShardedCreateEntity_NoShardKeyFn(t *testing.T) {
+	helpers := ShardedEntityHelpers[TestEntity]{
+		Helpers: &EntityHelpers[TestEntity]{TableName: "users"},
+		Shards:  []util.DB{new(utilmock.MockDB)},
+	}
+
+	_, err := helpers.CreateEntity(context.Background(), &TestEntity{}, nil)
+
+	assert.EqualError(t, err, "shard key function not configured")
+}
+
+// TestShardedGetEntities_NoCompareFn tests that ordered and paged gets
+// without the shard key require CompareFn.
+func TestShardedGetEntities_NoCompareFn(t *testing.T) {
+	helpers := ShardedEntityHelpers[TestEntity]{
+		Helpers:       &EntityHelpers[TestEntity]{TableName: "users"},
+		Shards:        []util.DB{new(utilmock.MockDB)},
+		ShardKeyField: "id",
+	}
+
+	for _, opts := range []Options{
+		{Orders: []util.Order{{Field: "name"}}},
+		{Page: &page.Page{Limit: 10}},
+	} {
+		_, err := helpers.GetEntities(
+			context.Background(),
+			GetOptions{Options: opts},
+		)
+		assert.ErrorIs(t, err, ShardMergeOrderError)
+	}
+}
@@ -0,0 +1,119 @@
+package entity
+
+import (
+	"context"
+
+	"github.com/pakkasys/fluidapi/database/util"
+)
+
+// snapshotRestoreChunkSize is the number of rows inserted per query when a
+// snapshot is restored.
+const snapshotRestoreChunkSize = 500
+
+// Restorer restores a snapshot.
+type Restorer interface {
+	Restore(preparer util.Preparer) error
+}
+
+// Cleaner registers cleanup functions, e.g. *testing.T.
+type Cleaner interface {
+	Cleanup(fn func())
+	Errorf(format string, args ...any)
+}
+
+// TableSnapshot is a snapshot of the rows of an entity table.
+type TableSnapshot[T any] struct {
+	tableName string
+	inserter  Inserter[*T]
+	sqlUtil   SQLUtil
+	entities  []*T
+}
+
+// Snapshot takes a snapshot of all rows of the table of the entity helpers.
+// The rows are read with the streaming iterator.
+//
+//   - ctx: The context of the operation.
+//   - preparer: The preparer used to prepare the query.
+//   - helpers: The entity helpers of the table.
+func Snapshot[T any](
+	ctx context.Context,
+	preparer util.Preparer,
+	helpers *EntityHelpers[T],
+) (*TableSnapshot[T], error) {
+	tenant, err := helpers.tenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := &TableSnapshot[T]{
+		tableName: tenant.TableName(helpers.TableName),
+		inserter:  helpers.InserterFn,
+		sqlUtil:   helpers.SQLUtil,
+	}
+	err = IterateEntities(
+		snapshot.tableName,
+		helpers.ScanRowsFn,
+		preparer,
+		&GetOptions{},
+		func(entity *T) error {
+			snapshot.entities = append(snapshot.entities, entity)
+			return nil
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return snapshot, nil
+}
+
+// Len returns the number of rows in the snapshot.
+func (s *TableSnapshot[T]) Len() int {
+	return len(s.entities)
+}
+
+// Restore deletes all rows of the table and inserts the rows of the
+// snapshot.
+//
+//   - preparer: The preparer used to prepare the queries.
+func (s *TableSnapshot[T]) Restore(preparer util.Preparer) error {
+	if _, err := DeleteEntities(preparer, s.tableName, nil, nil); err != nil {
+		return err
+	}
+
+	for start := 0; start < len(s.entities); start += snapshotRestoreChunkSize {
+		end := min(start+snapshotRestoreChunkSize, len(s.entities))
+		_, err := CreateEntities(
+			s.entities[start:end],
+			preparer,
+			s.tableName,
+			s.inserter,
+			s.sqlUtil,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RestoreOnCleanup restores the snapshots in reverse order when the test
+// finishes, e.g. to isolate integration tests using a real database.
+//
+//   - cleaner: The cleaner registering the restore, e.g. *testing.T.
+//   - preparer: The preparer used to prepare the queries.
+//   - snapshots: The snapshots to restore.
+func RestoreOnCleanup(
+	cleaner Cleaner,
+	preparer util.Preparer,
+	snapshots ...Restorer,
+) {
+	cleaner.Cleanup(func() {
+		for i := len(snapshots) - 1; i >= 0; i-- {
+			if err := snapshots[i].Restore(preparer); err != nil {
+				cleaner.Errorf("restore snapshot: %v", err)
+			}
+		}
+	})
+}
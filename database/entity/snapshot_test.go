@@ -0,0 +1,125 @@
+package entity
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	entitymock "github.com/pakkasys/fluidapi/database/entity/mock"
+	"github.com/pakkasys/fluidapi/database/util"
+	utilmock "github.com/pakkasys/fluidapi/database/util/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockCleaner struct {
+	cleanups []func()
+	errors   []string
+}
+
+func (c *mockCleaner) Cleanup(fn func()) {
+	c.cleanups = append(c.cleanups, fn)
+}
+
+func (c *mockCleaner) Errorf(format string, args ...any) {
+	c.errors = append(c.errors, fmt.Sprintf(format, args...))
+}
+
+type mockRestorer struct {
+	name     string
+	restored *[]string
+	err      error
+}
+
+func (r mockRestorer) Restore(preparer util.Preparer) error {
+	*r.restored = append(*r.restored, r.name)
+	return r.err
+}
+
+func snapshotEntityHelpers() *EntityHelpers[TestEntity] {
+	return &EntityHelpers[TestEntity]{
+		TableName:  "users",
+		ScanRowsFn: countingRowsScanner(),
+		InserterFn: func(entity *TestEntity) ([]string, []any) {
+			return []string{"id"}, []any{entity.ID}
+		},
+		SQLUtil: new(entitymock.MockSQLUtil),
+	}
+}
+
+// TestSnapshot tests taking a snapshot of a table.
+func TestSnapshot(t *testing.T) {
+	mockPreparer := new(utilmock.MockDB)
+	expectIterateRows(mockPreparer, "SELECT * FROM `users`", 2)
+
+	snapshot, err := Snapshot(
+		context.Background(),
+		mockPreparer,
+		snapshotEntityHelpers(),
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, snapshot.Len())
+}
+
+// TestTableSnapshot_Restore tests that restoring a snapshot replaces the rows
+// of the table.
+func TestTableSnapshot_Restore(t *testing.T) {
+	mockPreparer := new(utilmock.MockDB)
+	mockDeleteStmt := new(utilmock.MockStmt)
+	mockInsertStmt := new(utilmock.MockStmt)
+	mockResult := new(utilmock.MockResult)
+
+	snapshot := &TableSnapshot[TestEntity]{
+		tableName: "users",
+		inserter:  snapshotEntityHelpers().InserterFn,
+		entities:  []*TestEntity{{ID: 1}, {ID: 2}},
+	}
+
+	mockPreparer.On("Prepare", "DELETE FROM `users` ").
+		Return(mockDeleteStmt, nil)
+	mockDeleteStmt.On("Exec", mock.Anything).Return(mockResult, nil)
+	mockDeleteStmt.On("Close").Return(nil)
+	mockResult.On("RowsAffected").Return(int64(3), nil)
+	mockPreparer.On(
+		"Prepare",
+		"INSERT INTO `users` (`id`) VALUES (?), (?)",
+	).Return(mockInsertStmt, nil)
+	mockInsertStmt.On("Exec", []any{1, 2}).Return(mockResult, nil)
+	mockInsertStmt.On("Close").Return(nil)
+	mockResult.On("LastInsertId").Return(int64(2), nil)
+
+	err := snapshot.Restore(mockPreparer)
+
+	assert.NoError(t, err)
+	mockPreparer.AssertExpectations(t)
+	mockInsertStmt.AssertExpectations(t)
+}
+
+// TestRestoreOnCleanup tests that the snapshots are restored in reverse
+// order on cleanup and that restore errors are reported.
+func TestRestoreOnCleanup(t *testing.T) {
+	cleaner := &mockCleaner{}
+	restored := []string{}
+
+	RestoreOnCleanup(
+		cleaner,
+		new(utilmock.MockDB),
+		mockRestorer{name: "users", restored: &restored},
+		mockRestorer{
+			name:     "orders",
+			restored: &restored,
+			err:      errors.New("restore error"),
+		},
+	)
+
+	assert.Len(t, cleaner.cleanups, 1)
+	cleaner.cleanups[0]()
+	assert.Equal(t, []string{"orders", "users"}, restored)
+	assert.Equal(
+		t,
+		[]string{"restore snapshot: restore error"},
+		cleaner.errors,
+	)
+}
@@ -0,0 +1,126 @@
+package entity
+
+import (
+	"context"
+
+	"github.com/pakkasys/fluidapi/database/util"
+)
+
+// TenantResolver derives the tenant of the operation from the context.
+type TenantResolver func(ctx context.Context) (*Tenant, error)
+
+// Tenant describes where the tables of a tenant are located. It is applied to
+// every table reference generated by the entity helpers.
+type Tenant struct {
+	// Schema is the database schema containing the tenant tables
+	Schema string
+	// TablePrefix is the prefix of the tenant table names
+	TablePrefix string
+}
+
+// TableName returns the tenant specific name of the table. A nil tenant
+// returns the table name unchanged.
+//
+//   - table: The name of the table.
+func (t *Tenant) TableName(table string) string {
+	if t == nil || table == "" {
+		return table
+	}
+	tableName := t.TablePrefix + table
+	if t.Schema != "" {
+		tableName = t.Schema + "." + tableName
+	}
+	return tableName
+}
+
+func (t *Tenant) scopeOptions(opts Options) Options {
+	if t == nil {
+		return opts
+	}
+	opts.Selectors = t.scopeSelectors(opts.Selectors)
+	opts.Orders = t.scopeOrders(opts.Orders)
+	opts.Joins = t.scopeJoins(opts.Joins)
+	opts.Projections = t.scopeProjections(opts.Projections)
+	return opts
+}
+
+func (t *Tenant) scopeDeleteOptions(opts *DeleteOptions) *DeleteOptions {
+	if t == nil || opts == nil {
+		return opts
+	}
+	scoped := *opts
+	scoped.Orders = t.scopeOrders(opts.Orders)
+	return &scoped
+}
+
+func (t *Tenant) scopeAggregateOptions(
+	opts AggregateOptions,
+) AggregateOptions {
+	if t == nil {
+		return opts
+	}
+	opts.Joins = t.scopeJoins(opts.Joins)
+	opts.Dimensions = t.scopeProjections(opts.Dimensions)
+	opts.Orders = t.scopeOrders(opts.Orders)
+	if opts.Measures != nil {
+		measures := make([]util.Aggregate, len(opts.Measures))
+		for i, measure := range opts.Measures {
+			measure.Table = t.TableName(measure.Table)
+			measures[i] = measure
+		}
+		opts.Measures = measures
+	}
+	return opts
+}
+
+func (t *Tenant) scopeSelectors(selectors []util.Selector) []util.Selector {
+	if t == nil || selectors == nil {
+		return selectors
+	}
+	scoped := make([]util.Selector, len(selectors))
+	for i, selector := range selectors {
+		selector.Table = t.TableName(selector.Table)
+		scoped[i] = selector
+	}
+	return scoped
+}
+
+func (t *Tenant) scopeOrders(orders []util.Order) []util.Order {
+	if t == nil || orders == nil {
+		return orders
+	}
+	scoped := make([]util.Order, len(orders))
+	for i, order := range orders {
+		order.Table = t.TableName(order.Table)
+		scoped[i] = order
+	}
+	return scoped
+}
+
+func (t *Tenant) scopeJoins(joins []util.Join) []util.Join {
+	if t == nil || joins == nil {
+		return joins
+	}
+	scoped := make([]util.Join, len(joins))
+	for i, join := range joins {
+		join.Table = t.TableName(join.Table)
+		join.OnLeft.Table = t.TableName(join.OnLeft.Table)
+		join.OnRight.Table = t.TableName(join.OnRight.Table)
+		scoped[i] = join
+	}
+	return scoped
+}
+
+func (t *Tenant) scopeProjections(
+	projections []util.Projection,
+) []util.Projection {
+	if t == nil || projections == nil {
+		return projections
+	}
+	scoped := make([]util.Projection, len(projections))
+	for i, projection := range projections {
+		projection.Table = t.TableName(projection.Table)
+		scoped[i] = projection
+	}
+	return scoped
+}
@@ -0,0 +1,141 @@
+package entity
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/pakkasys/fluidapi/database/util"
+	utilmock "github.com/pakkasys/fluidapi/database/util/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestTenantTableName_Nil tests that a nil tenant keeps the table name.
+func TestTenantTableName_Nil(t *testing.T) {
+	var tenant *Tenant
+	assert.Equal(t, "users", tenant.TableName("users"))
+}
+
+// TestTenantTableName_Prefix tests the table prefix of a tenant.
+func TestTenantTableName_Prefix(t *testing.T) {
+	tenant := &Tenant{TablePrefix: "acme_"}
+	assert.Equal(t, "acme_users", tenant.TableName("users"))
+}
+
+// TestTenantTableName_Schema tests the schema of a tenant.
+func TestTenantTableName_Schema(t *testing.T) {
+	tenant := &Tenant{Schema: "acme", TablePrefix: "t_"}
+	assert.Equal(t, "acme.t_users", tenant.TableName("users"))
+}
+
+// TestTenantTableName_EmptyTable tests that an empty table name is kept
+// empty.
+func TestTenantTableName_EmptyTable(t *testing.T) {
+	tenant := &Tenant{Schema: "acme"}
+	assert.Equal(t, "", tenant.TableName(""))
+}
+
+// TestTenantScopeOptions tests that all table references of the options are
+// scoped to the tenant without modifying the original options.
+func TestTenantScopeOptions(t *testing.T) {
+	tenant := &Tenant{Schema: "acme"}
+	opts := Options{
+		Selectors: []util.Selector{
+			{Table: "users", Field: "id", Predicate: util.EQUAL, Value: 1},
+			{Field: "name", Predicate: util.EQUAL, Value: "Alice"},
+		},
+		Orders: []util.Order{{Table: "users", Field: "id"}},
+		Joins: []util.Join{
+			{
+				Type:    util.JoinTypeInner,
+				Table:   "orders",
+				OnLeft:  util.ColumSelector{Table: "users", Column: "id"},
+				OnRight: util.ColumSelector{Table: "orders", Column: "user_id"},
+			},
+		},
+		Projections: []util.Projection{{Table: "users", Column: "id"}},
+	}
+
+	scoped := tenant.scopeOptions(opts)
+
+	assert.Equal(t, "acme.users", scoped.Selectors[0].Table)
+	assert.Equal(t, "", scoped.Selectors[1].Table)
+	assert.Equal(t, "acme.users", scoped.Orders[0].Table)
+	assert.Equal(t, "acme.orders", scoped.Joins[0].Table)
+	assert.Equal(t, "acme.users", scoped.Joins[0].OnLeft.Table)
+	assert.Equal(t, "acme.orders", scoped.Joins[0].OnRight.Table)
+	assert.Equal(t, "acme.users", scoped.Projections[0].Table)
+	assert.Equal(t, "users", opts.Selectors[0].Table)
+}
+
+// TestGetEntities_TenantResolver tests that the entity helpers generate
+// queries for the tenant resolved from the context.
+func TestGetEntities_TenantResolver(t *testing.T) {
+	mockPreparer := new(utilmock.MockDB)
+	mockStmt := new(utilmock.MockStmt)
+	mockRows := new(utilmock.MockRows)
+
+	entityHelpers := EntityHelpers[TestEntity]{
+		TableName: "users",
+		ScanRowsFn: func(rows util.Rows, entity *TestEntity) error {
+			return nil
+		},
+		TenantResolver: func(ctx context.Context) (*Tenant, error) {
+			return &Tenant{Schema: "acme"}, nil
+		},
+	}
+
+	mockPreparer.On(
+		"Prepare",
+		"SELECT * FROM `acme`.`users` WHERE `acme`.`users`.`id` = ?",
+	).Return(mockStmt, nil)
+	mockStmt.On("Query", []any{1}).Return(mockRows, nil)
+	mockStmt.On("Close").Return(nil)
+	mockRows.On("Next").Return(false)
+	mockRows.On("Err").Return(nil)
+	mockRows.On("Close").Return(nil)
+
+	_, err := entityHelpers.GetEntities(
+		context.Background(),
+		mockPreparer,
+		GetOptions{
+			Options: Options{
+				Selectors: []util.Selector{
+					{
+						Table:     "users",
+						Field:     "id",
+						Predicate: util.EQUAL,
+						Value:     1,
+					},
+				},
+			},
+		},
+	)
+
+	assert.NoError(t, err)
+	mockPreparer.AssertExpectations(t)
+}
+
+// TestUpdateEntities_TenantResolverError tests that a tenant resolver error
+// is returned without running the query.
+func TestUpdateEntities_TenantResolverError(t *testing.T) {
+	mockPreparer := new(utilmock.MockDB)
+
+	entityHelpers := EntityHelpers[TestEntity]{
+		TableName: "users",
+		TenantResolver: func(ctx context.Context) (*Tenant, error) {
+			return nil, errors.New("unknown tenant")
+		},
+	}
+
+	count, err := entityHelpers.UpdateEntities(
+		context.Background(),
+		mockPreparer,
+		nil,
+		Updates{{Field: "name", Value: "Bob"}},
+	)
+
+	assert.EqualError(t, err, "unknown tenant")
+	assert.Equal(t, int64(0), count)
+	mockPreparer.AssertNotCalled(t, "Prepare")
+}
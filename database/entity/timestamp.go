@@ -0,0 +1,111 @@
+package entity
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/pakkasys/fluidapi/database/util"
+)
+
+// TimestampHandler maintains the creation and update timestamps of entities.
+// It sets both timestamps of created entities, keeps the updated timestamp
+// current on upserts and adds the updated timestamp to updates.
+type TimestampHandler[T any] struct {
+	// CreatedField is the column of the creation timestamp
+	CreatedField string
+	// UpdatedField is the column of the update timestamp
+	UpdatedField string
+	// SetCreatedFn sets the creation timestamp of an entity
+	SetCreatedFn func(entity *T, now time.Time)
+	// SetUpdatedFn sets the update timestamp of an entity
+	SetUpdatedFn func(entity *T, now time.Time)
+	// NowFn is an optional clock, time.Now is used by default
+	NowFn func() time.Time
+}
+
+func (h *TimestampHandler[T]) now() time.Time {
+	if h.NowFn == nil {
+		return time.Now()
+	}
+	return h.NowFn()
+}
+
+// onCreate sets the timestamps of the created entities.
+func (h *TimestampHandler[T]) onCreate(entities ...*T) {
+	if h == nil {
+		return
+	}
+	now := h.now()
+	for _, entity := range entities {
+		if h.SetCreatedFn != nil {
+			h.SetCreatedFn(entity, now)
+		}
+		if h.SetUpdatedFn != nil {
+			h.SetUpdatedFn(entity, now)
+		}
+	}
+}
+
+// onUpsert adds the update timestamp to the update projections of an upsert
+// unless it is already updated.
+func (h *TimestampHandler[T]) onUpsert(opts *UpsertOptions) *UpsertOptions {
+	if h == nil || opts == nil || h.UpdatedField == "" {
+		return opts
+	}
+	if opts.Mode != InsertModeUpsert {
+		return opts
+	}
+	for _, expression := range opts.UpdateExpressions {
+		if expression.Column == h.UpdatedField {
+			return opts
+		}
+	}
+	alias := ""
+	for _, projection := range opts.UpdateProjection {
+		if projection.Column == h.UpdatedField {
+			return opts
+		}
+		alias = projection.Alias
+	}
+
+	updated := *opts
+	if len(opts.UpdateProjection) == 0 && len(opts.UpdateExpressions) != 0 {
+		// Without projections the update timestamp is set as an expression.
+		updated.UpdateExpressions = append(
+			slices.Clip(opts.UpdateExpressions),
+			UpdateExpression{
+				Column:     h.UpdatedField,
+				Expression: fmt.Sprintf("VALUES(`%s`)", h.UpdatedField),
+			},
+		)
+		return &updated
+	}
+
+	projections := make(
+		[]util.Projection,
+		len(opts.UpdateProjection),
+		len(opts.UpdateProjection)+1,
+	)
+	copy(projections, opts.UpdateProjection)
+	updated.UpdateProjection = append(projections, util.Projection{
+		Column: h.UpdatedField,
+		Alias:  alias,
+	})
+	return &updated
+}
+
+// onUpdate adds the update timestamp to the updates unless it is already
+// set.
+func (h *TimestampHandler[T]) onUpdate(updates Updates) Updates {
+	if h == nil || h.UpdatedField == "" {
+		return updates
+	}
+	if updates.GetByField(h.UpdatedField) != nil {
+		return updates
+	}
+	return append(
+		updates[:len(updates):len(updates)],
+		Update{Field: h.UpdatedField, Value: h.now()},
+	)
+}
@@ -0,0 +1,193 @@
+package entity
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	entitymock "github.com/pakkasys/fluidapi/database/entity/mock"
+	"github.com/pakkasys/fluidapi/database/util"
+	utilmock "github.com/pakkasys/fluidapi/database/util/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type timestampEntity struct {
+	Name      string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+var timestampNow = time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)
+
+// expectExecQuery expects a single statement execution with the given query
+// and parameters.
+func expectExecQuery(preparer *utilmock.MockDB, query string, params []any) {
+	mockStmt := new(utilmock.MockStmt)
+	mockResult := new(utilmock.MockResult)
+	preparer.On("Prepare", query).Return(mockStmt, nil).Once()
+	mockStmt.On("Exec", params).Return(mockResult, nil)
+	mockStmt.On("Close").Return(nil)
+	mockResult.On("LastInsertId").Return(int64(1), nil)
+	mockResult.On("RowsAffected").Return(int64(1), nil)
+}
+
+func newTimestampSQLUtil() *entitymock.MockSQLUtil {
+	mockSQLUtil := new(entitymock.MockSQLUtil)
+	mockSQLUtil.On("CheckDBError", mock.Anything).Return(nil)
+	return mockSQLUtil
+}
+
+func testTimestampHandler() *TimestampHandler[timestampEntity] {
+	return &TimestampHandler[timestampEntity]{
+		CreatedField: "created_at",
+		UpdatedField: "updated_at",
+		SetCreatedFn: func(entity *timestampEntity, now time.Time) {
+			entity.CreatedAt = now
+		},
+		SetUpdatedFn: func(entity *timestampEntity, now time.Time) {
+			entity.UpdatedAt = now
+		},
+		NowFn: func() time.Time { return timestampNow },
+	}
+}
+
+// TestTimestampHandler_OnCreate tests that both timestamps are set on
+// creation.
+func TestTimestampHandler_OnCreate(t *testing.T) {
+	entities := []*timestampEntity{{Name: "a"}, {Name: "b"}}
+
+	testTimestampHandler().onCreate(entities...)
+
+	for _, entity := range entities {
+		assert.Equal(t, timestampNow, entity.CreatedAt)
+		assert.Equal(t, timestampNow, entity.UpdatedAt)
+	}
+}
+
+// TestTimestampHandler_Nil tests that a nil handler changes nothing.
+func TestTimestampHandler_Nil(t *testing.T) {
+	var handler *TimestampHandler[timestampEntity]
+	entity := &timestampEntity{}
+	updates := Updates{{Field: "name", Value: "a"}}
+	opts := &UpsertOptions{}
+
+	handler.onCreate(entity)
+
+	assert.True(t, entity.CreatedAt.IsZero())
+	assert.Equal(t, updates, handler.onUpdate(updates))
+	assert.Same(t, opts, handler.onUpsert(opts))
+}
+
+// TestTimestampHandler_OnUpsert tests that the update timestamp is added to
+// the update projections.
+func TestTimestampHandler_OnUpsert(t *testing.T) {
+	opts := &UpsertOptions{
+		UpdateProjection: []util.Projection{{Column: "name", Alias: "a"}},
+	}
+
+	result := testTimestampHandler().onUpsert(opts)
+
+	assert.Equal(
+		t,
+		[]util.Projection{
+			{Column: "name", Alias: "a"},
+			{Column: "updated_at", Alias: "a"},
+		},
+		result.UpdateProjection,
+	)
+	assert.Len(t, opts.UpdateProjection, 1)
+}
+
+// TestTimestampHandler_OnUpsert_AlreadyUpdated tests that an existing update
+// timestamp projection is kept.
+func TestTimestampHandler_OnUpsert_AlreadyUpdated(t *testing.T) {
+	opts := &UpsertOptions{
+		UpdateProjection: []util.Projection{
+			{Column: "updated_at", Alias: "a"},
+		},
+	}
+
+	assert.Same(t, opts, testTimestampHandler().onUpsert(opts))
+}
+
+// TestTimestampHandler_OnUpdate tests that the update timestamp is added to
+// the updates unless explicitly set.
+func TestTimestampHandler_OnUpdate(t *testing.T) {
+	handler := testTimestampHandler()
+	explicit := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	assert.Equal(
+		t,
+		Updates{
+			{Field: "name", Value: "a"},
+			{Field: "updated_at", Value: timestampNow},
+		},
+		handler.onUpdate(Updates{{Field: "name", Value: "a"}}),
+	)
+	assert.Equal(
+		t,
+		Updates{{Field: "updated_at", Value: explicit}},
+		handler.onUpdate(Updates{{Field: "updated_at", Value: explicit}}),
+	)
+}
+
+// TestCreateEntity_TimestampHandler tests that the entity helpers set the
+// timestamps of created entities.
+func TestCreateEntity_TimestampHandler(t *testing.T) {
+	mockPreparer := new(utilmock.MockDB)
+	expectExecQuery(
+		mockPreparer,
+		"INSERT INTO `items` (`name`, `created_at`, `updated_at`) "+
+			"VALUES (?, ?, ?)",
+		[]any{"a", timestampNow, timestampNow},
+	)
+
+	entityHelpers := EntityHelpers[timestampEntity]{
+		TableName: "items",
+		InserterFn: func(entity *timestampEntity) ([]string, []any) {
+			return []string{"name", "created_at", "updated_at"},
+				[]any{entity.Name, entity.CreatedAt, entity.UpdatedAt}
+		},
+		SQLUtil:          newTimestampSQLUtil(),
+		TimestampHandler: testTimestampHandler(),
+	}
+
+	entity, err := entityHelpers.CreateEntity(
+		context.Background(),
+		mockPreparer,
+		&timestampEntity{Name: "a"},
+		nil,
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(t, timestampNow, entity.CreatedAt)
+	mockPreparer.AssertExpectations(t)
+}
+
+// TestUpdateEntities_TimestampHandler tests that the entity helpers add the
+// update timestamp to updates.
+func TestUpdateEntities_TimestampHandler(t *testing.T) {
+	mockPreparer := new(utilmock.MockDB)
+	expectExecQuery(
+		mockPreparer,
+		"UPDATE `items` SET name = ?, updated_at = ?",
+		[]any{"b", timestampNow},
+	)
+
+	entityHelpers := EntityHelpers[timestampEntity]{
+		TableName:        "items",
+		SQLUtil:          newTimestampSQLUtil(),
+		TimestampHandler: testTimestampHandler(),
+	}
+
+	_, err := entityHelpers.UpdateEntities(
+		context.Background(),
+		mockPreparer,
+		nil,
+		Updates{{Field: "name", Value: "b"}},
+	)
+
+	assert.NoError(t, err)
+	mockPreparer.AssertExpectations(t)
+}
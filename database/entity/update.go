@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/pakkasys/fluidapi/database/internal"
 	"github.com/pakkasys/fluidapi/database/util"
 )
 
@@ -79,15 +78,15 @@ func updateQuery(
 	updates []Update,
 	selectors []util.Selector,
 ) (string, []any) {
-	whereColumns, whereValues := internal.ProcessSelectors(selectors)
+	whereColumns, whereValues := util.ProcessSelectors(selectors)
 
 	setClause, values := getSetClause(updates)
 	values = append(values, whereValues...)
 
 	builder := strings.Builder{}
 	builder.WriteString(fmt.Sprintf(
-		"UPDATE `%s` SET %s",
-		tableName,
+		"UPDATE %s SET %s",
+		util.QuoteIdentifier(tableName),
 		setClause,
 	))
 	if len(whereColumns) != 0 {
@@ -15,6 +15,8 @@ import (
 //   - entity: The entity to upsert.
 //   - inserter: The function used to get the columns and values to insert.
 //   - updateProjections: The projections of the entity to update.
+//   - sqlUtil: The SQL utility used to check database errors.
+//   - updateExpressions: Optional expressions for updating columns.
 func UpsertEntity[T any](
 	preparer util.Preparer,
 	tableName string,
@@ -22,8 +24,16 @@ func UpsertEntity[T any](
 	inserter Inserter[*T],
 	updateProjections []util.Projection,
 	sqlUtil SQLUtil,
+	updateExpressions ...UpdateExpression,
 ) (int64, error) {
-	res, err := upsert(preparer, tableName, entity, inserter, updateProjections)
+	res, err := upsert(
+		preparer,
+		tableName,
+		entity,
+		inserter,
+		updateProjections,
+		updateExpressions...,
+	)
 	return checkInsertResult(res, err, sqlUtil)
 }
 
@@ -34,6 +44,8 @@ func UpsertEntity[T any](
 //   - entities: The entities to upsert.
 //   - inserter: The function used to get the columns and values to insert.
 //   - updateProjections: The projections of the entities to update.
+//   - sqlUtil: The SQL utility used to check database errors.
+//   - updateExpressions: Optional expressions for updating columns.
 func UpsertEntities[T any](
 	preparer util.Preparer,
 	tableName string,
@@ -41,6 +53,7 @@ func UpsertEntities[T any](
 	inserter Inserter[*T],
 	updateProjections []util.Projection,
 	sqlUtil SQLUtil,
+	updateExpressions ...UpdateExpression,
 ) (int64, error) {
 	res, err := upsertMany(
 		preparer,
@@ -48,22 +61,49 @@ func UpsertEntities[T any](
 		tableName,
 		inserter,
 		updateProjections,
+		updateExpressions...,
 	)
 	return checkInsertResult(res, err, sqlUtil)
 }
 
+// UpdateExpression sets a column to an SQL expression when an upsert
+// updates an existing row, e.g. "`count` + VALUES(`count`)" or "NOW()".
+type UpdateExpression struct {
+	// Column is the column to update
+	Column string
+	// Expression is the SQL expression of the new value
+	Expression string
+	// Args are the values of the placeholders in the expression
+	Args []any
+}
+
+func validateUpsertUpdates(
+	updateProjections []util.Projection,
+	updateExpressions []UpdateExpression,
+) error {
+	if len(updateProjections) == 0 {
+		if len(updateExpressions) != 0 {
+			return nil
+		}
+		return fmt.Errorf("must provide update projections")
+	}
+	if len(updateProjections[0].Alias) == 0 {
+		return fmt.Errorf("must provide update projections alias")
+	}
+	return nil
+}
+
 func upsert[T any](
 	preparer util.Preparer,
 	tableName string,
 	entity *T,
 	inserter Inserter[*T],
 	updateProjections []util.Projection,
+	updateExpressions ...UpdateExpression,
 ) (sql.Result, error) {
-	if len(updateProjections) == 0 {
-		return nil, fmt.Errorf("must provide update projections")
-	}
-	if len(updateProjections[0].Alias) == 0 {
-		return nil, fmt.Errorf("must provide update projections alias")
+	err := validateUpsertUpdates(updateProjections, updateExpressions)
+	if err != nil {
+		return nil, err
 	}
 
 	upsertQuery, values := upsertManyQuery(
@@ -71,6 +111,7 @@ func upsert[T any](
 		tableName,
 		inserter,
 		updateProjections,
+		updateExpressions...,
 	)
 
 	statement, err := preparer.Prepare(upsertQuery)
@@ -92,12 +133,17 @@ func upsertManyQuery[T any](
 	tableName string,
 	inserter Inserter[*T],
 	updateProjections []util.Projection,
+	updateExpressions ...UpdateExpression,
 ) (string, []any) {
 	if len(entities) == 0 {
 		return "", nil
 	}
 
-	updateParts := make([]string, len(updateProjections))
+	updateParts := make(
+		[]string,
+		len(updateProjections),
+		len(updateProjections)+len(updateExpressions),
+	)
 	for i, proj := range updateProjections {
 		updateParts[i] = fmt.Sprintf(
 			"`%s` = VALUES(`%s`)",
@@ -107,6 +153,14 @@ func upsertManyQuery[T any](
 	}
 
 	insertQueryPart, allValues := insertManyQuery(entities, tableName, inserter)
+	for _, expression := range updateExpressions {
+		updateParts = append(updateParts, fmt.Sprintf(
+			"`%s` = %s",
+			expression.Column,
+			expression.Expression,
+		))
+		allValues = append(allValues, expression.Args...)
+	}
 
 	builder := strings.Builder{}
 	builder.WriteString(insertQueryPart)
@@ -125,15 +179,14 @@ func upsertMany[T any](
 	tableName string,
 	inserter Inserter[*T],
 	updateProjections []util.Projection,
+	updateExpressions ...UpdateExpression,
 ) (sql.Result, error) {
 	if len(entities) == 0 {
 		return nil, fmt.Errorf("must provide entities to upsert")
 	}
-	if len(updateProjections) == 0 {
-		return nil, fmt.Errorf("must provide update projections")
-	}
-	if len(updateProjections[0].Alias) == 0 {
-		return nil, fmt.Errorf("must provide update projections alias")
+	err := validateUpsertUpdates(updateProjections, updateExpressions)
+	if err != nil {
+		return nil, err
 	}
 
 	query, values := upsertManyQuery(
@@ -141,6 +194,7 @@ func upsertMany[T any](
 		tableName,
 		inserter,
 		updateProjections,
+		updateExpressions...,
 	)
 	statement, err := preparer.Prepare(query)
 	if err != nil {
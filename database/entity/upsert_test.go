@@ -1,9 +1,11 @@
 package entity
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"testing"
+	"time"
 
 	entitymock "github.com/pakkasys/fluidapi/database/entity/mock"
 	"github.com/pakkasys/fluidapi/database/util"
@@ -543,3 +545,113 @@ func TestUpsertMany_ExecError(t *testing.T) {
 	assert.EqualError(t, err, "exec error")
 	mockDB.AssertExpectations(t)
 }
+
+// TestUpsertManyQuery_Expressions tests that update expressions are added
+// after the projections and that their arguments follow the insert values.
+func TestUpsertManyQuery_Expressions(t *testing.T) {
+	query, values := upsertManyQuery(
+		[]*TestEntity{{ID: 1, Name: "a"}},
+		"counters",
+		insertModeInserter,
+		[]util.Projection{{Column: "name", Alias: "new"}},
+		UpdateExpression{
+			Column:     "id",
+			Expression: "`id` + VALUES(`id`)",
+		},
+		UpdateExpression{
+			Column:     "hits",
+			Expression: "`hits` + ?",
+			Args:       []any{5},
+		},
+	)
+
+	assert.Equal(
+		t,
+		"INSERT INTO `counters` (`id`, `name`) VALUES (?, ?) "+
+			"ON DUPLICATE KEY UPDATE `name` = VALUES(`name`), "+
+			"`id` = `id` + VALUES(`id`), `hits` = `hits` + ?",
+		query,
+	)
+	assert.Equal(t, []any{1, "a", 5}, values)
+}
+
+// TestValidateUpsertUpdates tests the validation of the upsert updates.
+func TestValidateUpsertUpdates(t *testing.T) {
+	assert.EqualError(
+		t,
+		validateUpsertUpdates(nil, nil),
+		"must provide update projections",
+	)
+	assert.EqualError(
+		t,
+		validateUpsertUpdates([]util.Projection{{Column: "name"}}, nil),
+		"must provide update projections alias",
+	)
+	assert.NoError(t, validateUpsertUpdates(
+		nil,
+		[]UpdateExpression{{Column: "name", Expression: "NOW()"}},
+	))
+}
+
+// TestCreateEntity_UpsertExpressions tests upserting through the entity
+// helpers with only update expressions.
+func TestCreateEntity_UpsertExpressions(t *testing.T) {
+	mockPreparer := new(utilmock.MockDB)
+	expectExecQuery(
+		mockPreparer,
+		"INSERT INTO `users` (`id`, `name`) VALUES (?, ?) "+
+			"ON DUPLICATE KEY UPDATE `updated_at` = NOW()",
+		[]any{1, "a"},
+	)
+
+	entityHelpers := EntityHelpers[TestEntity]{
+		TableName:  "users",
+		InserterFn: insertModeInserter,
+		SQLUtil:    newTimestampSQLUtil(),
+	}
+
+	_, err := entityHelpers.CreateEntity(
+		context.Background(),
+		mockPreparer,
+		&TestEntity{ID: 1, Name: "a"},
+		&UpsertOptions{
+			UpdateExpressions: []UpdateExpression{
+				{Column: "updated_at", Expression: "NOW()"},
+			},
+		},
+	)
+
+	assert.NoError(t, err)
+	mockPreparer.AssertExpectations(t)
+}
+
+// TestTimestampHandler_OnUpsert_Expressions tests that the update timestamp
+// is added as an expression when only expressions are used.
+func TestTimestampHandler_OnUpsert_Expressions(t *testing.T) {
+	handler := &TimestampHandler[TestEntity]{
+		UpdatedField: "updated_at",
+		NowFn:        time.Now,
+	}
+	opts := &UpsertOptions{
+		UpdateExpressions: []UpdateExpression{
+			{Column: "hits", Expression: "`hits` + 1"},
+		},
+	}
+
+	result := handler.onUpsert(opts)
+
+	assert.Equal(
+		t,
+		[]UpdateExpression{
+			{Column: "hits", Expression: "`hits` + 1"},
+			{Column: "updated_at", Expression: "VALUES(`updated_at`)"},
+		},
+		result.UpdateExpressions,
+	)
+	assert.Len(t, opts.UpdateExpressions, 1)
+	assert.Same(
+		t,
+		result,
+		handler.onUpsert(result),
+	)
+}
@@ -0,0 +1,352 @@
+// Package fixture loads rows described in fixture files into the database for
+// seeding tests and local development environments.
+//
+// A fixture file maps table names to lists of rows:
+//
+//	{
+//		"users": [{"id": 1, "name": "alice"}],
+//		"posts": [{"id": 1, "user_id": 1, "title": "hello"}]
+//	}
+//
+// The rows are decoded into the entity types registered for the tables and
+// inserted with their inserter functions. Tables are inserted after the
+// tables they depend on, regardless of the order in the files.
+package fixture
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/pakkasys/fluidapi/database/entity"
+	"github.com/pakkasys/fluidapi/database/transaction"
+	"github.com/pakkasys/fluidapi/database/util"
+)
+
+// Decoder decodes the content of a fixture file into v. json.Unmarshal and
+// the Unmarshal functions of common YAML libraries satisfy it. Decoders must
+// produce JSON compatible values, i.e. maps with string keys.
+type Decoder func(data []byte, v any) error
+
+// Loader loads fixture files into the registered tables.
+type Loader struct {
+	tables   map[string]*table
+	order    []string
+	decoders map[string]Decoder
+}
+
+type table struct {
+	name      string
+	dependsOn []string
+	insert    func(preparer util.Preparer, rows []json.RawMessage) error
+}
+
+// passthroughSQLUtil returns database errors unchanged.
+type passthroughSQLUtil struct{}
+
+func (passthroughSQLUtil) CheckDBError(err error) error {
+	return err
+}
+
+// NewLoader creates a new fixture loader decoding .json files.
+//
+// Returns:
+//   - A new Loader.
+func NewLoader() *Loader {
+	return &Loader{
+		tables:   map[string]*table{},
+		decoders: map[string]Decoder{".json": json.Unmarshal},
+	}
+}
+
+// RegisterDecoder registers a decoder for the fixture files with the given
+// extension, e.g. ".yaml".
+//
+// Parameters:
+//   - extension: The file extension including the leading dot.
+//   - decoder: The decoder of the files.
+//
+// Returns:
+//   - The Loader.
+func (l *Loader) RegisterDecoder(extension string, decoder Decoder) *Loader {
+	l.decoders[strings.ToLower(extension)] = decoder
+	return l
+}
+
+// Register registers a table of the fixtures. The rows of the table are
+// decoded into T using its JSON field names and inserted with the inserter.
+//
+// Parameters:
+//   - loader: The fixture loader.
+//   - tableName: The name of the table.
+//   - inserter: The inserter of the entities.
+//   - sqlUtil: The SQL utilities used for the inserts. May be nil.
+//   - dependsOn: The tables that must be loaded before this table.
+func Register[T any](
+	loader *Loader,
+	tableName string,
+	inserter entity.Inserter[*T],
+	sqlUtil entity.SQLUtil,
+	dependsOn ...string,
+) {
+	if sqlUtil == nil {
+		sqlUtil = passthroughSQLUtil{}
+	}
+
+	if _, ok := loader.tables[tableName]; !ok {
+		loader.order = append(loader.order, tableName)
+	}
+	loader.tables[tableName] = &table{
+		name:      tableName,
+		dependsOn: dependsOn,
+		insert: func(preparer util.Preparer, rows []json.RawMessage) error {
+			entities := make([]*T, len(rows))
+			for i, row := range rows {
+				entities[i] = new(T)
+				if err := json.Unmarshal(row, entities[i]); err != nil {
+					return fmt.Errorf(
+						"fixture: decoding row %d of table %s: %w",
+						i,
+						tableName,
+						err,
+					)
+				}
+			}
+			_, err := entity.CreateEntities(
+				entities,
+				preparer,
+				tableName,
+				inserter,
+				sqlUtil,
+			)
+			return err
+		},
+	}
+}
+
+// RegisterHelpers registers the table of the entity helpers, using their
+// table name, inserter and SQL utilities.
+//
+// Parameters:
+//   - loader: The fixture loader.
+//   - helpers: The entity helpers of the table.
+//   - dependsOn: The tables that must be loaded before this table.
+func RegisterHelpers[T any](
+	loader *Loader,
+	helpers *entity.EntityHelpers[T],
+	dependsOn ...string,
+) {
+	Register(
+		loader,
+		helpers.TableName,
+		helpers.InserterFn,
+		helpers.SQLUtil,
+		dependsOn...,
+	)
+}
+
+// Load reads the fixture files matching the patterns and inserts their rows.
+// Files are read in lexical order and the rows of a table in the order they
+// appear in the files.
+//
+// Parameters:
+//   - preparer: The preparer used to insert the rows.
+//   - fsys: The file system containing the fixture files.
+//   - patterns: The glob patterns of the fixture files.
+//
+// Returns:
+//   - An error if reading the files or inserting the rows fails.
+func (l *Loader) Load(
+	preparer util.Preparer,
+	fsys fs.FS,
+	patterns ...string,
+) error {
+	rows, err := l.readFiles(fsys, patterns)
+	if err != nil {
+		return err
+	}
+
+	tables, err := l.insertOrder(rows)
+	if err != nil {
+		return err
+	}
+	for _, table := range tables {
+		if err := table.insert(preparer, rows[table.name]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LoadWithManagedTransaction loads the fixture files in a transaction.
+//
+// Parameters:
+//   - ctx: The context to use when getting and setting the transaction.
+//   - getTxFn: The function returning a new transaction.
+//   - fsys: The file system containing the fixture files.
+//   - patterns: The glob patterns of the fixture files.
+//
+// Returns:
+//   - An error if reading the files or inserting the rows fails.
+func (l *Loader) LoadWithManagedTransaction(
+	ctx context.Context,
+	getTxFn func(ctx context.Context) (util.Tx, error),
+	fsys fs.FS,
+	patterns ...string,
+) error {
+	_, err := transaction.ExecuteManagedTransaction(
+		ctx,
+		getTxFn,
+		func(ctx context.Context, tx util.Tx) (struct{}, error) {
+			return struct{}{}, l.Load(tx, fsys, patterns...)
+		},
+	)
+	return err
+}
+
+func (l *Loader) readFiles(
+	fsys fs.FS,
+	patterns []string,
+) (map[string][]json.RawMessage, error) {
+	var files []string
+	for _, pattern := range patterns {
+		matches, err := fs.Glob(fsys, pattern)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, matches...)
+	}
+	sort.Strings(files)
+
+	rows := map[string][]json.RawMessage{}
+	for i, file := range files {
+		if i > 0 && files[i-1] == file {
+			continue
+		}
+		fileRows, err := l.readFile(fsys, file)
+		if err != nil {
+			return nil, err
+		}
+		for _, tableRows := range fileRows {
+			rows[tableRows.table] = append(
+				rows[tableRows.table],
+				tableRows.rows...,
+			)
+		}
+	}
+	return rows, nil
+}
+
+type tableRows struct {
+	table string
+	rows  []json.RawMessage
+}
+
+func (l *Loader) readFile(fsys fs.FS, file string) ([]tableRows, error) {
+	decoder, ok := l.decoders[strings.ToLower(path.Ext(file))]
+	if !ok {
+		return nil, fmt.Errorf("fixture: no decoder for file %s", file)
+	}
+
+	data, err := fs.ReadFile(fsys, file)
+	if err != nil {
+		return nil, err
+	}
+	var content map[string][]any
+	if err := decoder(data, &content); err != nil {
+		return nil, fmt.Errorf("fixture: decoding file %s: %w", file, err)
+	}
+
+	tableNames := make([]string, 0, len(content))
+	for tableName := range content {
+		tableNames = append(tableNames, tableName)
+	}
+	sort.Strings(tableNames)
+
+	var result []tableRows
+	for _, tableName := range tableNames {
+		if _, ok := l.tables[tableName]; !ok {
+			return nil, fmt.Errorf(
+				"fixture: table %s of file %s is not registered",
+				tableName,
+				file,
+			)
+		}
+		rows := make([]json.RawMessage, len(content[tableName]))
+		for i, row := range content[tableName] {
+			encoded, err := json.Marshal(row)
+			if err != nil {
+				return nil, fmt.Errorf(
+					"fixture: encoding row %d of table %s: %w",
+					i,
+					tableName,
+					err,
+				)
+			}
+			rows[i] = encoded
+		}
+		result = append(result, tableRows{table: tableName, rows: rows})
+	}
+	return result, nil
+}
+
+// insertOrder returns the tables having rows so that every table comes after
+// the tables it depends on. Independent tables keep their registration order.
+func (l *Loader) insertOrder(
+	rows map[string][]json.RawMessage,
+) ([]*table, error) {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	states := map[string]int{}
+	var ordered []*table
+
+	var visit func(name string, path []string) error
+	visit = func(name string, path []string) error {
+		switch states[name] {
+		case visited:
+			return nil
+		case visiting:
+			return fmt.Errorf(
+				"fixture: circular table dependency: %s",
+				strings.Join(append(path, name), " -> "),
+			)
+		}
+
+		table, ok := l.tables[name]
+		if !ok {
+			return fmt.Errorf(
+				"fixture: dependency %s of table %s is not registered",
+				name,
+				path[len(path)-1],
+			)
+		}
+		states[name] = visiting
+		for _, dependency := range table.dependsOn {
+			if err := visit(dependency, append(path, name)); err != nil {
+				return err
+			}
+		}
+		states[name] = visited
+		if len(rows[name]) > 0 {
+			ordered = append(ordered, table)
+		}
+		return nil
+	}
+
+	for _, name := range l.order {
+		if len(rows[name]) == 0 {
+			continue
+		}
+		if err := visit(name, nil); err != nil {
+			return nil, err
+		}
+	}
+	return ordered, nil
+}
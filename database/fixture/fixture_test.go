@@ -0,0 +1,252 @@
+package fixture
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/pakkasys/fluidapi/database/entity"
+	"github.com/pakkasys/fluidapi/database/util"
+	"github.com/pakkasys/fluidapi/database/util/fake"
+	endpointutil "github.com/pakkasys/fluidapi/endpoint/util"
+	"github.com/stretchr/testify/assert"
+)
+
+type testUser struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+type testPost struct {
+	ID     int    `json:"id"`
+	UserID int    `json:"user_id"`
+	Title  string `json:"title"`
+}
+
+// recordingPreparer records the prepared queries.
+type recordingPreparer struct {
+	util.Preparer
+	queries []string
+}
+
+func (p *recordingPreparer) Prepare(query string) (util.Stmt, error) {
+	p.queries = append(p.queries, query)
+	return p.Preparer.Prepare(query)
+}
+
+func (p *recordingPreparer) PrepareContext(
+	ctx context.Context,
+	query string,
+) (util.Stmt, error) {
+	p.queries = append(p.queries, query)
+	return p.Preparer.PrepareContext(ctx, query)
+}
+
+func newTestDB() *fake.DB {
+	db := fake.NewDB()
+	db.CreateTable("users", fake.TableOptions{
+		Columns:    []string{"id", "name"},
+		PrimaryKey: "id",
+	})
+	db.CreateTable("posts", fake.TableOptions{
+		Columns:    []string{"id", "user_id", "title"},
+		PrimaryKey: "id",
+	})
+	return db
+}
+
+func newTestLoader() *Loader {
+	loader := NewLoader()
+	Register(
+		loader,
+		"posts",
+		func(p *testPost) ([]string, []any) {
+			return []string{"id", "user_id", "title"},
+				[]any{p.ID, p.UserID, p.Title}
+		},
+		nil,
+		"users",
+	)
+	RegisterHelpers(loader, &entity.EntityHelpers[testUser]{
+		TableName: "users",
+		InserterFn: func(u *testUser) ([]string, []any) {
+			return []string{"id", "name"}, []any{u.ID, u.Name}
+		},
+		SQLUtil: fake.SQLUtil{},
+	})
+	return loader
+}
+
+// TestLoad tests loading fixture files in dependency order.
+func TestLoad(t *testing.T) {
+	db := newTestDB()
+	preparer := &recordingPreparer{Preparer: db}
+	fsys := fstest.MapFS{
+		"fixtures/posts.json": {Data: []byte(`{
+			"posts": [{"id": 1, "user_id": 2, "title": "hello"}]
+		}`)},
+		"fixtures/users.json": {Data: []byte(`{
+			"users": [{"id": 1, "name": "alice"}, {"id": 2, "name": "bob"}]
+		}`)},
+	}
+
+	err := newTestLoader().Load(preparer, fsys, "fixtures/*.json")
+
+	assert.NoError(t, err)
+	assert.Len(t, preparer.queries, 2)
+	assert.True(
+		t,
+		strings.HasPrefix(preparer.queries[0], "INSERT INTO `users`"),
+	)
+	assert.True(
+		t,
+		strings.HasPrefix(preparer.queries[1], "INSERT INTO `posts`"),
+	)
+	assert.Equal(t, []map[string]any{
+		{"id": int64(1), "name": "alice"},
+		{"id": int64(2), "name": "bob"},
+	}, db.Rows("users"))
+	assert.Equal(t, []map[string]any{
+		{"id": int64(1), "user_id": int64(2), "title": "hello"},
+	}, db.Rows("posts"))
+}
+
+// TestLoad_CustomDecoder tests loading files with a registered decoder.
+func TestLoad_CustomDecoder(t *testing.T) {
+	db := newTestDB()
+	fsys := fstest.MapFS{"users.txt": {Data: []byte("alice")}}
+	loader := newTestLoader().RegisterDecoder(
+		".TXT",
+		func(data []byte, v any) error {
+			*(v.(*map[string][]any)) = map[string][]any{
+				"users": {map[string]any{"id": 1, "name": string(data)}},
+			}
+			return nil
+		},
+	)
+
+	err := loader.Load(db, fsys, "*.txt")
+
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		[]map[string]any{{"id": int64(1), "name": "alice"}},
+		db.Rows("users"),
+	)
+}
+
+// TestLoad_Errors tests the errors of invalid fixtures.
+func TestLoad_Errors(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		files    fstest.MapFS
+		expected string
+	}{
+		{
+			name:     "no decoder",
+			files:    fstest.MapFS{"users.yaml": {Data: []byte("")}},
+			expected: "fixture: no decoder for file users.yaml",
+		},
+		{
+			name:     "invalid file",
+			files:    fstest.MapFS{"users.json": {Data: []byte("{")}},
+			expected: "fixture: decoding file users.json",
+		},
+		{
+			name: "unknown table",
+			files: fstest.MapFS{
+				"tags.json": {Data: []byte(`{"tags": [{"id": 1}]}`)},
+			},
+			expected: "fixture: table tags of file tags.json is not " +
+				"registered",
+		},
+		{
+			name: "invalid row",
+			files: fstest.MapFS{
+				"users.json": {Data: []byte(`{"users": [{"id": "x"}]}`)},
+			},
+			expected: "fixture: decoding row 0 of table users",
+		},
+		{
+			name: "duplicate entry",
+			files: fstest.MapFS{
+				"users.json": {Data: []byte(
+					`{"users": [{"id": 1}, {"id": 1}]}`,
+				)},
+			},
+			expected: "DUPLICATE_ENTRY",
+		},
+	} {
+		err := newTestLoader().Load(newTestDB(), tc.files, "*")
+		assert.ErrorContains(t, err, tc.expected, tc.name)
+	}
+}
+
+// TestInsertOrder_Cycle tests that circular dependencies are rejected.
+func TestInsertOrder_Cycle(t *testing.T) {
+	loader := NewLoader()
+	inserter := func(u *testUser) ([]string, []any) { return nil, nil }
+	Register(loader, "a", inserter, nil, "b")
+	Register(loader, "b", inserter, nil, "a")
+
+	_, err := loader.insertOrder(map[string][]json.RawMessage{
+		"a": {json.RawMessage("{}")},
+	})
+
+	assert.EqualError(
+		t,
+		err,
+		"fixture: circular table dependency: a -> b -> a",
+	)
+}
+
+// TestInsertOrder_UnknownDependency tests that unregistered dependencies are
+// rejected.
+func TestInsertOrder_UnknownDependency(t *testing.T) {
+	loader := NewLoader()
+	inserter := func(u *testUser) ([]string, []any) { return nil, nil }
+	Register(loader, "a", inserter, nil, "missing")
+
+	_, err := loader.insertOrder(map[string][]json.RawMessage{
+		"a": {json.RawMessage("{}")},
+	})
+
+	assert.EqualError(
+		t,
+		err,
+		"fixture: dependency missing of table a is not registered",
+	)
+}
+
+// TestLoadWithManagedTransaction tests that a failing load is rolled back.
+func TestLoadWithManagedTransaction(t *testing.T) {
+	db := newTestDB()
+	fsys := fstest.MapFS{
+		"users.json": {Data: []byte(`{"users": [{"id": 1, "name": "a"}]}`)},
+		"posts.json": {Data: []byte(`{"posts": [{"id": 1, "bad": 1}]}`)},
+	}
+	loader := newTestLoader()
+	Register(
+		loader,
+		"posts",
+		func(p *testPost) ([]string, []any) {
+			return []string{"id", "unknown"}, []any{p.ID, nil}
+		},
+		nil,
+		"users",
+	)
+
+	err := loader.LoadWithManagedTransaction(
+		endpointutil.NewContext(context.Background()),
+		func(ctx context.Context) (util.Tx, error) {
+			return db.BeginTx(ctx, nil)
+		},
+		fsys,
+		"*.json",
+	)
+
+	assert.EqualError(t, err, "fake: unknown column 'unknown'")
+	assert.Empty(t, db.Rows("users"))
+}
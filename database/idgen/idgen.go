@@ -0,0 +1,31 @@
+// Package idgen contains generators for client-side primary keys.
+package idgen
+
+import (
+	"crypto/rand"
+	"io"
+	"time"
+)
+
+// Generator generates unique IDs.
+type Generator[K any] interface {
+	NewID() (K, error)
+}
+
+// GeneratorFunc is a function implementing Generator.
+type GeneratorFunc[K any] func() (K, error)
+
+// NewID calls the function.
+func (f GeneratorFunc[K]) NewID() (K, error) {
+	return f()
+}
+
+// source contains the clock and the randomness of a generator.
+type source struct {
+	nowFn  func() time.Time
+	random io.Reader
+}
+
+func defaultSource() source {
+	return source{nowFn: time.Now, random: rand.Reader}
+}
@@ -0,0 +1,34 @@
+package idgen
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testSource(now time.Time, random []byte) source {
+	return source{
+		nowFn:  func() time.Time { return now },
+		random: bytes.NewReader(random),
+	}
+}
+
+// TestGeneratorFunc tests that the function is called.
+func TestGeneratorFunc(t *testing.T) {
+	generator := GeneratorFunc[int](func() (int, error) { return 5, nil })
+
+	id, err := generator.NewID()
+
+	assert.NoError(t, err)
+	assert.Equal(t, 5, id)
+}
+
+// TestDefaultSource tests the default clock and randomness.
+func TestDefaultSource(t *testing.T) {
+	s := defaultSource()
+
+	assert.NotNil(t, s.nowFn)
+	assert.NotNil(t, s.random)
+}
@@ -0,0 +1,89 @@
+package idgen
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	snowflakeNodeBits     = 10
+	snowflakeSequenceBits = 12
+	snowflakeMaxNode      = 1<<snowflakeNodeBits - 1
+	snowflakeMaxSequence  = 1<<snowflakeSequenceBits - 1
+)
+
+// DefaultSnowflakeEpoch is the default epoch of Snowflake IDs.
+var DefaultSnowflakeEpoch = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// SnowflakeGenerator generates 64-bit time-ordered Snowflake IDs consisting
+// of a 41-bit millisecond timestamp, a 10-bit node ID and a 12-bit sequence.
+// Each concurrently running node must use a distinct node ID.
+type SnowflakeGenerator struct {
+	epoch      time.Time
+	node       int64
+	nowFn      func() time.Time
+	sleepFn    func(time.Duration)
+	mu         sync.Mutex
+	lastMillis int64
+	sequence   int64
+}
+
+// NewSnowflakeGenerator creates a new Snowflake generator.
+//
+//   - node: The node ID, between 0 and 1023.
+//   - epoch: The epoch of the timestamps, or zero for DefaultSnowflakeEpoch.
+func NewSnowflakeGenerator(
+	node int64,
+	epoch time.Time,
+) (*SnowflakeGenerator, error) {
+	if node < 0 || node > snowflakeMaxNode {
+		return nil, fmt.Errorf("snowflake node out of range: %d", node)
+	}
+	if epoch.IsZero() {
+		epoch = DefaultSnowflakeEpoch
+	}
+	return &SnowflakeGenerator{
+		epoch:      epoch,
+		node:       node,
+		nowFn:      time.Now,
+		sleepFn:    time.Sleep,
+		lastMillis: -1,
+	}, nil
+}
+
+// NewID generates a new Snowflake ID.
+func (g *SnowflakeGenerator) NewID() (int64, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	ms := g.millis()
+	if ms < g.lastMillis {
+		return 0, fmt.Errorf(
+			"snowflake clock moved backwards by %dms",
+			g.lastMillis-ms,
+		)
+	}
+
+	if ms == g.lastMillis {
+		g.sequence = (g.sequence + 1) & snowflakeMaxSequence
+		if g.sequence == 0 {
+			// The sequence is exhausted, wait for the next millisecond.
+			for ms <= g.lastMillis {
+				g.sleepFn(time.Millisecond)
+				ms = g.millis()
+			}
+		}
+	} else {
+		g.sequence = 0
+	}
+	g.lastMillis = ms
+
+	return ms<<(snowflakeNodeBits+snowflakeSequenceBits) |
+		g.node<<snowflakeSequenceBits |
+		g.sequence, nil
+}
+
+func (g *SnowflakeGenerator) millis() int64 {
+	return g.nowFn().Sub(g.epoch).Milliseconds()
+}
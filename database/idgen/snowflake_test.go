@@ -0,0 +1,70 @@
+package idgen
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testSnowflakeGenerator(
+	t *testing.T,
+	now *time.Time,
+) *SnowflakeGenerator {
+	generator, err := NewSnowflakeGenerator(3, time.Time{})
+	assert.NoError(t, err)
+	generator.nowFn = func() time.Time { return *now }
+	generator.sleepFn = func(d time.Duration) { *now = now.Add(d) }
+	return generator
+}
+
+// TestNewSnowflakeGenerator_InvalidNode tests that the node is validated.
+func TestNewSnowflakeGenerator_InvalidNode(t *testing.T) {
+	_, err := NewSnowflakeGenerator(1024, time.Time{})
+
+	assert.EqualError(t, err, "snowflake node out of range: 1024")
+}
+
+// TestSnowflakeGenerator_NewID tests the layout of generated IDs.
+func TestSnowflakeGenerator_NewID(t *testing.T) {
+	now := DefaultSnowflakeEpoch.Add(5 * time.Millisecond)
+	generator := testSnowflakeGenerator(t, &now)
+
+	first, err := generator.NewID()
+	assert.NoError(t, err)
+	second, err := generator.NewID()
+	assert.NoError(t, err)
+
+	assert.Equal(t, int64(5<<22|3<<12), first)
+	assert.Equal(t, int64(5<<22|3<<12|1), second)
+}
+
+// TestSnowflakeGenerator_SequenceExhausted tests that the generator waits
+// for the next millisecond when the sequence is exhausted.
+func TestSnowflakeGenerator_SequenceExhausted(t *testing.T) {
+	now := DefaultSnowflakeEpoch
+	generator := testSnowflakeGenerator(t, &now)
+
+	var id int64
+	for range snowflakeMaxSequence + 2 {
+		var err error
+		id, err = generator.NewID()
+		assert.NoError(t, err)
+	}
+
+	assert.Equal(t, int64(1<<22|3<<12), id)
+}
+
+// TestSnowflakeGenerator_ClockBackwards tests that a clock moving backwards
+// returns an error.
+func TestSnowflakeGenerator_ClockBackwards(t *testing.T) {
+	now := DefaultSnowflakeEpoch.Add(time.Second)
+	generator := testSnowflakeGenerator(t, &now)
+
+	_, err := generator.NewID()
+	assert.NoError(t, err)
+	now = now.Add(-2 * time.Millisecond)
+	_, err = generator.NewID()
+
+	assert.EqualError(t, err, "snowflake clock moved backwards by 2ms")
+}
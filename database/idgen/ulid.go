@@ -0,0 +1,85 @@
+package idgen
+
+import (
+	"fmt"
+	"io"
+	"sync"
+)
+
+// crockford is the Crockford base32 alphabet used by ULIDs.
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// ULIDGenerator generates lexicographically sortable ULIDs, e.g.
+// "01ARZ3NDEKTSV4RRFFQ69G5FAV". IDs generated within the same millisecond
+// are monotonically increasing.
+type ULIDGenerator struct {
+	source
+	mu         sync.Mutex
+	lastMillis uint64
+	lastRandom [10]byte
+}
+
+// NewULIDGenerator creates a new ULID generator.
+func NewULIDGenerator() *ULIDGenerator {
+	return &ULIDGenerator{source: defaultSource()}
+}
+
+// NewID generates a new ULID.
+func (g *ULIDGenerator) NewID() (string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	ms := uint64(g.nowFn().UnixMilli())
+	if ms == g.lastMillis {
+		if !incrementBytes(g.lastRandom[:]) {
+			return "", fmt.Errorf("ulid random part overflow")
+		}
+	} else {
+		if _, err := io.ReadFull(g.random, g.lastRandom[:]); err != nil {
+			return "", err
+		}
+		g.lastMillis = ms
+	}
+
+	var id [16]byte
+	for i := 0; i < 6; i++ {
+		id[i] = byte(ms >> (40 - 8*i))
+	}
+	copy(id[6:], g.lastRandom[:])
+
+	return encodeULID(id), nil
+}
+
+// incrementBytes increments the big-endian number in place and reports
+// whether it did not overflow.
+func incrementBytes(b []byte) bool {
+	for i := len(b) - 1; i >= 0; i-- {
+		b[i]++
+		if b[i] != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// encodeULID encodes the 128 bits of the ULID as 26 base32 characters.
+func encodeULID(id [16]byte) string {
+	var buf [26]byte
+	// The 130 bits of the 26 characters are filled from the lowest bits up,
+	// leaving the two highest bits zero.
+	bits := 0
+	acc := uint(0)
+	pos := len(buf) - 1
+	for i := len(id) - 1; i >= 0; i-- {
+		acc |= uint(id[i]) << bits
+		bits += 8
+		for bits >= 5 {
+			buf[pos] = crockford[acc&0x1f]
+			pos--
+			acc >>= 5
+			bits -= 5
+		}
+	}
+	buf[pos] = crockford[acc&0x1f]
+	return string(buf[:])
+}
@@ -0,0 +1,63 @@
+package idgen
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestULIDGenerator_NewID tests the encoding of a generated ULID.
+func TestULIDGenerator_NewID(t *testing.T) {
+	generator := &ULIDGenerator{
+		source: testSource(time.UnixMilli(1469918176385), make([]byte, 10)),
+	}
+
+	id, err := generator.NewID()
+
+	assert.NoError(t, err)
+	assert.Equal(t, "01ARYZ6S410000000000000000", id)
+}
+
+// TestULIDGenerator_Monotonic tests that ULIDs of the same millisecond are
+// increasing.
+func TestULIDGenerator_Monotonic(t *testing.T) {
+	generator := &ULIDGenerator{
+		source: testSource(time.UnixMilli(1469918176385), make([]byte, 10)),
+	}
+
+	first, _ := generator.NewID()
+	second, err := generator.NewID()
+
+	assert.NoError(t, err)
+	assert.Equal(t, "01ARYZ6S410000000000000001", second)
+	assert.Less(t, first, second)
+}
+
+// TestULIDGenerator_Overflow tests that an overflowing random part within
+// the same millisecond returns an error.
+func TestULIDGenerator_Overflow(t *testing.T) {
+	generator := &ULIDGenerator{
+		source: testSource(time.UnixMilli(1469918176385), bytes10(0xff)),
+	}
+
+	_, err := generator.NewID()
+	assert.NoError(t, err)
+	_, err = generator.NewID()
+
+	assert.EqualError(t, err, "ulid random part overflow")
+}
+
+// TestULIDGenerator_Sortable tests that ULIDs sort by time.
+func TestULIDGenerator_Sortable(t *testing.T) {
+	generator := NewULIDGenerator()
+
+	first, err := generator.NewID()
+	assert.NoError(t, err)
+	time.Sleep(2 * time.Millisecond)
+	second, err := generator.NewID()
+	assert.NoError(t, err)
+
+	assert.Len(t, first, 26)
+	assert.Less(t, first, second)
+}
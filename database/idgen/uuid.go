@@ -0,0 +1,44 @@
+package idgen
+
+import (
+	"encoding/hex"
+	"io"
+)
+
+// UUIDv7Generator generates time-ordered version 7 UUIDs as defined in
+// RFC 9562, e.g. "01890a5d-ac96-774b-bcce-b302099a8057".
+type UUIDv7Generator struct {
+	source
+}
+
+// NewUUIDv7Generator creates a new UUIDv7 generator.
+func NewUUIDv7Generator() *UUIDv7Generator {
+	return &UUIDv7Generator{source: defaultSource()}
+}
+
+// NewID generates a new UUID.
+func (g *UUIDv7Generator) NewID() (string, error) {
+	var uuid [16]byte
+	if _, err := io.ReadFull(g.random, uuid[6:]); err != nil {
+		return "", err
+	}
+
+	ms := uint64(g.nowFn().UnixMilli())
+	for i := 0; i < 6; i++ {
+		uuid[i] = byte(ms >> (40 - 8*i))
+	}
+	uuid[6] = 0x70 | uuid[6]&0x0f
+	uuid[8] = 0x80 | uuid[8]&0x3f
+
+	var buf [36]byte
+	hex.Encode(buf[0:8], uuid[0:4])
+	buf[8] = '-'
+	hex.Encode(buf[9:13], uuid[4:6])
+	buf[13] = '-'
+	hex.Encode(buf[14:18], uuid[6:8])
+	buf[18] = '-'
+	hex.Encode(buf[19:23], uuid[8:10])
+	buf[23] = '-'
+	hex.Encode(buf[24:], uuid[10:])
+	return string(buf[:]), nil
+}
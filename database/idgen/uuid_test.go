@@ -0,0 +1,58 @@
+package idgen
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestUUIDv7Generator_NewID tests the layout of a generated UUID.
+func TestUUIDv7Generator_NewID(t *testing.T) {
+	generator := &UUIDv7Generator{
+		source: testSource(
+			time.UnixMilli(0x017F22E279B0),
+			bytes10(0xff),
+		),
+	}
+
+	id, err := generator.NewID()
+
+	assert.NoError(t, err)
+	assert.Equal(t, "017f22e2-79b0-7fff-bfff-ffffffffffff", id)
+}
+
+// TestUUIDv7Generator_Unique tests that random UUIDs are unique and valid.
+func TestUUIDv7Generator_Unique(t *testing.T) {
+	generator := NewUUIDv7Generator()
+	pattern := regexp.MustCompile(
+		`^[0-9a-f]{8}-[0-9a-f]{4}-7[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`,
+	)
+
+	seen := map[string]bool{}
+	for range 100 {
+		id, err := generator.NewID()
+		assert.NoError(t, err)
+		assert.Regexp(t, pattern, id)
+		assert.False(t, seen[id])
+		seen[id] = true
+	}
+}
+
+// TestUUIDv7Generator_RandomError tests that a randomness error is returned.
+func TestUUIDv7Generator_RandomError(t *testing.T) {
+	generator := &UUIDv7Generator{source: testSource(time.Now(), nil)}
+
+	_, err := generator.NewID()
+
+	assert.Error(t, err)
+}
+
+func bytes10(b byte) []byte {
+	random := make([]byte, 10)
+	for i := range random {
+		random[i] = b
+	}
+	return random
+}
@@ -0,0 +1,518 @@
+// Package job runs asynchronous work stored in a database table. Jobs are
+// enqueued in the transaction of the context, so that the work of a mutation
+// is only run if the mutation commits. A pool of workers runs the due jobs
+// and retries the failed ones with backoff.
+package job
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/pakkasys/fluidapi/core/backoff"
+	"github.com/pakkasys/fluidapi/core/logging"
+	"github.com/pakkasys/fluidapi/database/entity"
+	"github.com/pakkasys/fluidapi/database/idgen"
+	"github.com/pakkasys/fluidapi/database/transaction"
+	"github.com/pakkasys/fluidapi/database/util"
+	"github.com/pakkasys/fluidapi/endpoint/page"
+	endpointutil "github.com/pakkasys/fluidapi/endpoint/util"
+)
+
+const (
+	// DefaultTableName is the default name of the job table.
+	DefaultTableName = "jobs"
+
+	defaultMaxAttempts  = 5
+	defaultPollInterval = time.Second
+	defaultLockTimeout  = 5 * time.Minute
+)
+
+// Status is the status of a job.
+type Status string
+
+const (
+	// StatusPending jobs are run when they are due.
+	StatusPending Status = "pending"
+	// StatusRunning jobs are being run by a worker.
+	StatusRunning Status = "running"
+	// StatusDone jobs have been run successfully.
+	StatusDone Status = "done"
+	// StatusFailed jobs have failed on their last attempt.
+	StatusFailed Status = "failed"
+)
+
+// Job is a row of the job table.
+//
+// The job table can be created with:
+//
+//	CREATE TABLE jobs (
+//	  id VARCHAR(36) PRIMARY KEY,
+//	  type VARCHAR(255) NOT NULL,
+//	  payload JSON NOT NULL,
+//	  status VARCHAR(16) NOT NULL,
+//	  attempts INT NOT NULL,
+//	  max_attempts INT NOT NULL,
+//	  run_at DATETIME(6) NOT NULL,
+//	  last_error TEXT NOT NULL,
+//	  created_at DATETIME(6) NOT NULL,
+//	  updated_at DATETIME(6) NOT NULL,
+//	  INDEX jobs_status_run_at (status, run_at)
+//	);
+type Job struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+	// Payload is the JSON encoded payload of the job
+	Payload     string `json:"payload"`
+	Status      Status `json:"status"`
+	Attempts    int    `json:"attempts"`
+	MaxAttempts int    `json:"max_attempts"`
+	// RunAt is when a pending job is due. While the job is running it is when
+	// the lock of the worker expires, after which another worker may run the
+	// job again.
+	RunAt     time.Time `json:"run_at"`
+	LastError string    `json:"last_error"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Decode decodes the JSON payload of the job.
+//
+//   - v: The value to decode the payload into.
+func (j *Job) Decode(v any) error {
+	return json.Unmarshal([]byte(j.Payload), v)
+}
+
+// Handler runs a job. A returned error fails the attempt.
+type Handler func(ctx context.Context, job *Job) error
+
+// Option configures an enqueued job.
+type Option func(job *Job)
+
+// WithRunAt schedules the job to run at the given time.
+//
+//   - runAt: The time the job is due.
+func WithRunAt(runAt time.Time) Option {
+	return func(job *Job) {
+		job.RunAt = runAt.UTC()
+	}
+}
+
+// WithDelay schedules the job to run after the given delay.
+//
+//   - delay: The delay from the time the job is enqueued.
+func WithDelay(delay time.Duration) Option {
+	return func(job *Job) {
+		job.RunAt = job.RunAt.Add(delay)
+	}
+}
+
+// WithMaxAttempts sets the number of attempts of the job.
+//
+//   - maxAttempts: The number of attempts.
+func WithMaxAttempts(maxAttempts int) Option {
+	return func(job *Job) {
+		job.MaxAttempts = maxAttempts
+	}
+}
+
+// Config configures the job queue.
+type Config struct {
+	// TableName is the name of the job table. Defaults to "jobs".
+	TableName string
+	// GetTxFn returns a new transaction for the workers and for the jobs
+	// enqueued without a transaction in the context
+	GetTxFn func(ctx context.Context) (util.Tx, error)
+	// SQLUtil is a utility for working with SQL
+	SQLUtil entity.SQLUtil
+	// Workers is the number of concurrent workers. Defaults to 1.
+	Workers int
+	// PollInterval is how often an idle worker checks for due jobs. Defaults
+	// to one second.
+	PollInterval time.Duration
+	// MaxAttempts is the default number of attempts of a job. Defaults to 5.
+	MaxAttempts int
+	// Backoff returns the delay before the next attempt after the given
+	// number of failed attempts. Defaults to an exponential backoff starting
+	// from one second and capped at one hour.
+	Backoff func(attempt int) time.Duration
+	// LockTimeout is how long a worker may run a job. The job is canceled
+	// after it and may be run again by another worker, e.g. if the worker
+	// crashed. Defaults to five minutes.
+	LockTimeout time.Duration
+	// IDGenerator generates the IDs of the jobs. Defaults to UUIDv7.
+	IDGenerator idgen.Generator[string]
+	// Logger logs the failed jobs. Optional.
+	Logger logging.Logger
+}
+
+// Queue enqueues jobs and runs them with a pool of workers. It is safe for
+// concurrent use.
+type Queue struct {
+	config   Config
+	helpers  *entity.EntityHelpers[Job]
+	mu       sync.RWMutex
+	handlers map[string]Handler
+	nowFn    func() time.Time
+}
+
+// NewQueue creates a new job queue. The jobs are run by Run.
+//
+//   - config: The configuration of the queue.
+func NewQueue(config Config) *Queue {
+	if config.GetTxFn == nil {
+		panic("get transaction function cannot be nil")
+	}
+	if config.TableName == "" {
+		config.TableName = DefaultTableName
+	}
+	if config.Workers <= 0 {
+		config.Workers = 1
+	}
+	if config.PollInterval <= 0 {
+		config.PollInterval = defaultPollInterval
+	}
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = defaultMaxAttempts
+	}
+	if config.Backoff == nil {
+		config.Backoff = backoff.Exponential
+	}
+	if config.LockTimeout <= 0 {
+		config.LockTimeout = defaultLockTimeout
+	}
+	if config.IDGenerator == nil {
+		config.IDGenerator = idgen.NewUUIDv7Generator()
+	}
+	if config.Logger == nil {
+		config.Logger = logging.NopLogger{}
+	}
+	return &Queue{
+		config: config,
+		helpers: &entity.EntityHelpers[Job]{
+			TableName:  config.TableName,
+			GetTxFn:    config.GetTxFn,
+			InserterFn: insertJob,
+			ScanRowFn:  scanJob,
+			ScanRowsFn: scanJobs,
+			SQLUtil:    config.SQLUtil,
+		},
+		handlers: map[string]Handler{},
+		nowFn:    time.Now,
+	}
+}
+
+// Helpers returns the entity helpers of the job table, e.g. for listing the
+// failed jobs.
+func (q *Queue) Helpers() *entity.EntityHelpers[Job] {
+	return q.helpers
+}
+
+// Register sets the handler of a job type. A handler of the same type is
+// replaced.
+//
+//   - jobType: The type of the jobs.
+//   - handler: The handler running the jobs.
+func (q *Queue) Register(jobType string, handler Handler) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.handlers[jobType] = handler
+}
+
+// Enqueue inserts a job into the job table. The job is inserted in the
+// transaction of the context, so that it is only run if the transaction
+// commits. Without a transaction a new one is used.
+//
+//   - ctx: The context of the transaction.
+//   - jobType: The type of the job.
+//   - payload: The payload of the job, which must be JSON marshalable.
+//   - opts: The options of the job.
+func (q *Queue) Enqueue(
+	ctx context.Context,
+	jobType string,
+	payload any,
+	opts ...Option,
+) (*Job, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("job: %w", err)
+	}
+	id, err := q.config.IDGenerator.NewID()
+	if err != nil {
+		return nil, err
+	}
+
+	now := q.nowFn().UTC()
+	job := &Job{
+		ID:          id,
+		Type:        jobType,
+		Payload:     string(data),
+		Status:      StatusPending,
+		MaxAttempts: q.config.MaxAttempts,
+		RunAt:       now,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	for _, opt := range opts {
+		opt(job)
+	}
+	return q.helpers.CreateEntityWithManagedTransaction(ctx, job, nil)
+}
+
+// Retry resets a job to run again immediately with its attempts reset, e.g. a
+// failed job.
+//
+//   - ctx: The context of the operation.
+//   - id: The ID of the job.
+func (q *Queue) Retry(ctx context.Context, id string) error {
+	_, err := q.helpers.UpdateEntitiesWithManagedTransaction(
+		ctx,
+		[]util.Selector{idSelector(id)},
+		entity.Updates{
+			{Field: "status", Value: StatusPending},
+			{Field: "attempts", Value: 0},
+			{Field: "run_at", Value: q.nowFn().UTC()},
+			{Field: "last_error", Value: ""},
+			{Field: "updated_at", Value: q.nowFn().UTC()},
+		},
+	)
+	return err
+}
+
+// Run runs the due jobs until the context is done.
+//
+//   - ctx: The context stopping the workers.
+func (q *Queue) Run(ctx context.Context) error {
+	var wg sync.WaitGroup
+	for range q.config.Workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			q.work(ctx)
+		}()
+	}
+	wg.Wait()
+	return ctx.Err()
+}
+
+func (q *Queue) work(ctx context.Context) {
+	for {
+		job, err := q.claim(ctx)
+		if err != nil && ctx.Err() == nil {
+			q.config.Logger.Error(ctx, "Job queue error", "error", err)
+		}
+		if job != nil {
+			q.process(ctx, job)
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(q.config.PollInterval):
+		}
+	}
+}
+
+// claim locks the earliest due job and marks it as running. It returns nil if
+// no job is due.
+func (q *Queue) claim(ctx context.Context) (*Job, error) {
+	ctx = endpointutil.NewContext(ctx)
+	return transaction.ExecuteManagedTransaction(
+		ctx,
+		q.config.GetTxFn,
+		func(ctx context.Context, tx util.Tx) (*Job, error) {
+			now := q.nowFn().UTC()
+			jobs, err := q.helpers.GetEntities(ctx, tx, entity.GetOptions{
+				Options: entity.Options{
+					Selectors: []util.Selector{
+						{
+							Field:     "status",
+							Predicate: util.IN,
+							Value: []string{
+								string(StatusPending),
+								string(StatusRunning),
+							},
+						},
+						{
+							Field:     "run_at",
+							Predicate: util.LESS_OR_EQUAL,
+							Value:     now,
+						},
+					},
+					Orders: []util.Order{
+						{Field: "run_at", Direction: util.OrderAsc},
+					},
+					Page: &page.Page{Limit: 1},
+				},
+				Lock: true,
+			})
+			if err != nil || len(jobs) == 0 {
+				return nil, err
+			}
+
+			job := &jobs[0]
+			job.Status = StatusRunning
+			job.Attempts++
+			job.RunAt = now.Add(q.config.LockTimeout)
+			job.UpdatedAt = now
+			_, err = q.helpers.UpdateEntities(
+				ctx,
+				tx,
+				[]util.Selector{idSelector(job.ID)},
+				entity.Updates{
+					{Field: "status", Value: job.Status},
+					{Field: "attempts", Value: job.Attempts},
+					{Field: "run_at", Value: job.RunAt},
+					{Field: "updated_at", Value: job.UpdatedAt},
+				},
+			)
+			if err != nil {
+				return nil, err
+			}
+			return job, nil
+		},
+	)
+}
+
+// process runs the job and marks it as done, or as pending for a retry or as
+// failed if it fails.
+func (q *Queue) process(ctx context.Context, job *Job) {
+	runErr := q.run(ctx, job)
+
+	now := q.nowFn().UTC()
+	updates := entity.Updates{{Field: "updated_at", Value: now}}
+	switch {
+	case runErr == nil:
+		job.Status = StatusDone
+	case job.Attempts >= job.MaxAttempts:
+		job.Status = StatusFailed
+		job.LastError = runErr.Error()
+		q.config.Logger.Error(
+			ctx,
+			"Job failed",
+			"job_id", job.ID,
+			"job_type", job.Type,
+			"attempts", job.Attempts,
+			"error", runErr,
+		)
+	default:
+		job.Status = StatusPending
+		job.LastError = runErr.Error()
+		job.RunAt = now.Add(q.config.Backoff(job.Attempts))
+		updates = append(updates, entity.Update{
+			Field: "run_at",
+			Value: job.RunAt,
+		})
+	}
+	job.UpdatedAt = now
+	updates = append(
+		updates,
+		entity.Update{Field: "status", Value: job.Status},
+		entity.Update{Field: "last_error", Value: job.LastError},
+	)
+
+	// The result is stored even if the workers are stopping. It is only
+	// stored if the claim is still held: after the lock timeout the job may
+	// have been claimed again by another worker, which increments attempts.
+	count, err := q.helpers.UpdateEntitiesWithManagedTransaction(
+		endpointutil.NewContext(context.WithoutCancel(ctx)),
+		[]util.Selector{
+			idSelector(job.ID),
+			{Field: "status", Predicate: util.EQUAL, Value: StatusRunning},
+			{Field: "attempts", Predicate: util.EQUAL, Value: job.Attempts},
+		},
+		updates,
+	)
+	switch {
+	case err != nil:
+		q.config.Logger.Error(
+			ctx,
+			"Job queue error",
+			"job_id", job.ID,
+			"error", err,
+		)
+	case count == 0:
+		q.config.Logger.Warn(
+			ctx,
+			"Job claim lost",
+			"job_id", job.ID,
+			"attempts", job.Attempts,
+		)
+	}
+}
+
+// run runs the handler of the job within the lock timeout. Panics of the
+// handler are returned as errors.
+func (q *Queue) run(ctx context.Context, job *Job) (err error) {
+	q.mu.RLock()
+	handler, ok := q.handlers[job.Type]
+	q.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("no handler for job type: %s", job.Type)
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic in job handler: %v", r)
+		}
+	}()
+	ctx, cancel := context.WithTimeout(ctx, q.config.LockTimeout)
+	defer cancel()
+	return handler(ctx, job)
+}
+
+func idSelector(id string) util.Selector {
+	return util.Selector{Field: "id", Predicate: util.EQUAL, Value: id}
+}
+
+func insertJob(job *Job) ([]string, []any) {
+	return []string{
+		"id",
+		"type",
+		"payload",
+		"status",
+		"attempts",
+		"max_attempts",
+		"run_at",
+		"last_error",
+		"created_at",
+		"updated_at",
+	}, []any{
+		job.ID,
+		job.Type,
+		job.Payload,
+		job.Status,
+		job.Attempts,
+		job.MaxAttempts,
+		job.RunAt,
+		job.LastError,
+		job.CreatedAt,
+		job.UpdatedAt,
+	}
+}
+
+func scanJob(row util.Row, job *Job) error {
+	return row.Scan(jobFields(job)...)
+}
+
+func scanJobs(rows util.Rows, job *Job) error {
+	return rows.Scan(jobFields(job)...)
+}
+
+func jobFields(job *Job) []any {
+	return []any{
+		&job.ID,
+		&job.Type,
+		&job.Payload,
+		&job.Status,
+		&job.Attempts,
+		&job.MaxAttempts,
+		&job.RunAt,
+		&job.LastError,
+		&job.CreatedAt,
+		&job.UpdatedAt,
+	}
+}
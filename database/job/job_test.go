@@ -0,0 +1,431 @@
+package job
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pakkasys/fluidapi/core/logging/mock"
+	entitymock "github.com/pakkasys/fluidapi/database/entity/mock"
+	"github.com/pakkasys/fluidapi/database/idgen"
+	"github.com/pakkasys/fluidapi/database/transaction"
+	"github.com/pakkasys/fluidapi/database/util"
+	utilmock "github.com/pakkasys/fluidapi/database/util/mock"
+	endpointutil "github.com/pakkasys/fluidapi/endpoint/util"
+	"github.com/stretchr/testify/assert"
+	testifymock "github.com/stretchr/testify/mock"
+)
+
+var testTime = time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+// sequentialIDs returns a generator of the IDs "1", "2", ...
+func sequentialIDs() idgen.Generator[string] {
+	var mu sync.Mutex
+	next := 0
+	return idgen.GeneratorFunc[string](func() (string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		next++
+		return strconv.Itoa(next), nil
+	})
+}
+
+// testQueue returns a queue using the transaction for its operations.
+func testQueue(tx *utilmock.MockTx, config Config) *Queue {
+	config.GetTxFn = func(ctx context.Context) (util.Tx, error) {
+		return tx, nil
+	}
+	config.IDGenerator = sequentialIDs()
+	if config.SQLUtil == nil {
+		sqlUtil := new(entitymock.MockSQLUtil)
+		sqlUtil.On("CheckDBError", testifymock.Anything).Return(nil)
+		config.SQLUtil = sqlUtil
+	}
+	queue := NewQueue(config)
+	queue.nowFn = func() time.Time { return testTime }
+	return queue
+}
+
+// expectExec expects a prepared statement with the query prefix and returns
+// the query and the parameters of its execution.
+func expectExec(tx *utilmock.MockTx, prefix string) (*string, *[]any) {
+	var query string
+	var values []any
+	stmt := new(utilmock.MockStmt)
+	result := new(utilmock.MockResult)
+	tx.On("Prepare", testifymock.MatchedBy(func(q string) bool {
+		return strings.HasPrefix(q, prefix)
+	})).Run(func(args testifymock.Arguments) {
+		query = args.String(0)
+	}).Return(stmt, nil).Once()
+	stmt.On("Exec", testifymock.Anything).Run(func(args testifymock.Arguments) {
+		values = args.Get(0).([]any)
+	}).Return(result, nil).Once()
+	stmt.On("Close").Return(nil)
+	result.On("LastInsertId").Return(int64(0), nil)
+	result.On("RowsAffected").Return(int64(1), nil)
+	return &query, &values
+}
+
+// expectSelect expects a select query returning the jobs and returns the
+// query and its parameters.
+func expectSelect(tx *utilmock.MockTx, jobs ...Job) (*string, *[]any) {
+	var query string
+	var values []any
+	stmt := new(utilmock.MockStmt)
+	rows := new(utilmock.MockRows)
+	tx.On("Prepare", testifymock.MatchedBy(func(q string) bool {
+		return strings.HasPrefix(q, "SELECT")
+	})).Run(func(args testifymock.Arguments) {
+		query = args.String(0)
+	}).Return(stmt, nil).Once()
+	stmt.On("Query", testifymock.Anything).
+		Run(func(args testifymock.Arguments) {
+			values = args.Get(0).([]any)
+		}).
+		Return(rows, nil).Once()
+	stmt.On("Close").Return(nil)
+	for _, job := range jobs {
+		rows.On("Next").Return(true).Once()
+		scan := func(args testifymock.Arguments) {
+			dest := args.Get(0).([]any)
+			*dest[0].(*string) = job.ID
+			*dest[1].(*string) = job.Type
+			*dest[2].(*string) = job.Payload
+			*dest[3].(*Status) = job.Status
+			*dest[4].(*int) = job.Attempts
+			*dest[5].(*int) = job.MaxAttempts
+			*dest[6].(*time.Time) = job.RunAt
+		}
+		rows.On("Scan", testifymock.Anything).Run(scan).Return(nil).Once()
+	}
+	rows.On("Next").Return(false)
+	rows.On("Err").Return(nil)
+	rows.On("Close").Return(nil)
+	return &query, &values
+}
+
+// TestNewQueue_NilGetTxFn tests that a queue requires a transaction function.
+func TestNewQueue_NilGetTxFn(t *testing.T) {
+	assert.PanicsWithValue(
+		t,
+		"get transaction function cannot be nil",
+		func() { NewQueue(Config{}) },
+	)
+}
+
+// TestQueue_Enqueue tests that jobs are inserted in the transaction of the
+// context.
+func TestQueue_Enqueue(t *testing.T) {
+	tx := new(utilmock.MockTx)
+	queue := testQueue(tx, Config{})
+	queue.config.GetTxFn = func(ctx context.Context) (util.Tx, error) {
+		return nil, errors.New("new transaction")
+	}
+	query, values := expectExec(tx, "INSERT")
+	tx.On("Commit").Return(nil).Once()
+
+	job, err := transaction.ExecuteManagedTransaction(
+		endpointutil.NewContext(context.Background()),
+		func(ctx context.Context) (util.Tx, error) { return tx, nil },
+		func(ctx context.Context, tx util.Tx) (*Job, error) {
+			return queue.Enqueue(ctx, "email", map[string]string{"to": "a"})
+		},
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(t, &Job{
+		ID:          "1",
+		Type:        "email",
+		Payload:     `{"to":"a"}`,
+		Status:      StatusPending,
+		MaxAttempts: defaultMaxAttempts,
+		RunAt:       testTime,
+		CreatedAt:   testTime,
+		UpdatedAt:   testTime,
+	}, job)
+	assert.True(t, strings.HasPrefix(*query, "INSERT INTO `jobs`"))
+	assert.Equal(t, []any{
+		"1", "email", `{"to":"a"}`, StatusPending, 0, defaultMaxAttempts,
+		testTime, "", testTime, testTime,
+	}, *values)
+	tx.AssertExpectations(t)
+}
+
+// TestQueue_Enqueue_Options tests scheduling a job and setting its attempts.
+func TestQueue_Enqueue_Options(t *testing.T) {
+	tx := new(utilmock.MockTx)
+	queue := testQueue(tx, Config{})
+	expectExec(tx, "INSERT")
+	expectExec(tx, "INSERT")
+	tx.On("Commit").Return(nil)
+
+	ctx := endpointutil.NewContext(context.Background())
+	job, err := queue.Enqueue(
+		ctx,
+		"email",
+		nil,
+		WithDelay(time.Minute),
+		WithMaxAttempts(2),
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, testTime.Add(time.Minute), job.RunAt)
+	assert.Equal(t, 2, job.MaxAttempts)
+
+	runAt := testTime.Add(time.Hour)
+	job, err = queue.Enqueue(ctx, "email", nil, WithRunAt(runAt))
+	assert.NoError(t, err)
+	assert.Equal(t, runAt, job.RunAt)
+}
+
+// TestQueue_Enqueue_MarshalError tests that the payload must be JSON
+// marshalable.
+func TestQueue_Enqueue_MarshalError(t *testing.T) {
+	queue := testQueue(new(utilmock.MockTx), Config{})
+
+	job, err := queue.Enqueue(context.Background(), "email", func() {})
+
+	assert.Nil(t, job)
+	assert.ErrorContains(t, err, "job:")
+}
+
+// TestQueue_Claim tests locking the earliest due job and marking it as
+// running.
+func TestQueue_Claim(t *testing.T) {
+	tx := new(utilmock.MockTx)
+	queue := testQueue(tx, Config{LockTimeout: time.Minute})
+	selectQuery, selectValues := expectSelect(tx, Job{
+		ID:          "1",
+		Type:        "email",
+		Status:      StatusPending,
+		Attempts:    1,
+		MaxAttempts: 3,
+		RunAt:       testTime,
+	})
+	updateQuery, updateValues := expectExec(tx, "UPDATE")
+	tx.On("Commit").Return(nil).Once()
+
+	job, err := queue.claim(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, StatusRunning, job.Status)
+	assert.Equal(t, 2, job.Attempts)
+	assert.Equal(t, testTime.Add(time.Minute), job.RunAt)
+	assert.Contains(t, *selectQuery, "`status` IN (?,?)")
+	assert.Contains(t, *selectQuery, "`run_at` <= ?")
+	assert.Contains(t, *selectQuery, "FOR UPDATE")
+	assert.Equal(t, []any{"pending", "running", testTime}, *selectValues)
+	assert.Equal(
+		t,
+		"UPDATE `jobs` SET status = ?, attempts = ?, run_at = ?, "+
+			"updated_at = ? WHERE `id` = ?",
+		*updateQuery,
+	)
+	assert.Equal(t, []any{
+		StatusRunning, 2, testTime.Add(time.Minute), testTime, "1",
+	}, *updateValues)
+	tx.AssertExpectations(t)
+}
+
+// TestQueue_Claim_NoJobs tests that nil is returned if no job is due.
+func TestQueue_Claim_NoJobs(t *testing.T) {
+	tx := new(utilmock.MockTx)
+	queue := testQueue(tx, Config{})
+	expectSelect(tx)
+	tx.On("Commit").Return(nil).Once()
+
+	job, err := queue.claim(context.Background())
+
+	assert.NoError(t, err)
+	assert.Nil(t, job)
+	tx.AssertExpectations(t)
+}
+
+// TestQueue_Process tests storing the results of the jobs.
+func TestQueue_Process(t *testing.T) {
+	jobErr := errors.New("job error")
+	tests := []struct {
+		name     string
+		jobType  string
+		attempts int
+		expected []any
+		logged   []string
+	}{
+		{
+			name:     "success",
+			jobType:  "ok",
+			attempts: 1,
+			expected: []any{testTime, StatusDone, "", "1"},
+			logged:   []string{},
+		},
+		{
+			name:     "retry",
+			jobType:  "error",
+			attempts: 2,
+			expected: []any{
+				testTime, testTime.Add(2 * time.Second), StatusPending,
+				"job error", "1",
+			},
+			logged: []string{},
+		},
+		{
+			name:     "failed",
+			jobType:  "error",
+			attempts: 3,
+			expected: []any{testTime, StatusFailed, "job error", "1"},
+			logged:   []string{"Job failed"},
+		},
+		{
+			name:     "panic",
+			jobType:  "panic",
+			attempts: 3,
+			expected: []any{
+				testTime, StatusFailed, "panic in job handler: boom", "1",
+			},
+			logged: []string{"Job failed"},
+		},
+		{
+			name:     "no handler",
+			jobType:  "unknown",
+			attempts: 3,
+			expected: []any{
+				testTime, StatusFailed, "no handler for job type: unknown",
+				"1",
+			},
+			logged: []string{"Job failed"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tx := new(utilmock.MockTx)
+			logger := &mock.Recorder{}
+			queue := testQueue(tx, Config{Logger: logger})
+			queue.Register("ok", func(ctx context.Context, job *Job) error {
+				return nil
+			})
+			queue.Register("error", func(ctx context.Context, job *Job) error {
+				return jobErr
+			})
+			queue.Register("panic", func(ctx context.Context, job *Job) error {
+				panic("boom")
+			})
+			_, values := expectExec(tx, "UPDATE")
+			tx.On("Commit").Return(nil).Once()
+
+			queue.process(context.Background(), &Job{
+				ID:          "1",
+				Type:        test.jobType,
+				Status:      StatusRunning,
+				Attempts:    test.attempts,
+				MaxAttempts: 3,
+			})
+
+			expected := append(test.expected, StatusRunning, test.attempts)
+			assert.Equal(t, expected, *values)
+			assert.Equal(t, test.logged, logger.Messages())
+			tx.AssertExpectations(t)
+		})
+	}
+}
+
+// TestQueue_Process_ClaimLost tests that the result is not stored when the
+// job has been claimed again.
+func TestQueue_Process_ClaimLost(t *testing.T) {
+	tx := new(utilmock.MockTx)
+	logger := &mock.Recorder{}
+	queue := testQueue(tx, Config{Logger: logger})
+	queue.Register("ok", func(ctx context.Context, job *Job) error {
+		return nil
+	})
+	stmt := new(utilmock.MockStmt)
+	result := new(utilmock.MockResult)
+	var query string
+	tx.On("Prepare", testifymock.Anything).Run(func(args testifymock.Arguments) {
+		query = args.String(0)
+	}).Return(stmt, nil).Once()
+	stmt.On("Exec", testifymock.Anything).Return(result, nil).Once()
+	stmt.On("Close").Return(nil)
+	result.On("RowsAffected").Return(int64(0), nil)
+	tx.On("Commit").Return(nil).Once()
+
+	queue.process(context.Background(), &Job{
+		ID:          "1",
+		Type:        "ok",
+		Status:      StatusRunning,
+		Attempts:    1,
+		MaxAttempts: 3,
+	})
+
+	assert.Contains(t, query, "`status` = ?")
+	assert.Contains(t, query, "`attempts` = ?")
+	assert.Equal(t, []string{"Job claim lost"}, logger.Messages())
+	tx.AssertExpectations(t)
+}
+
+// TestQueue_Retry tests resetting a failed job.
+func TestQueue_Retry(t *testing.T) {
+	tx := new(utilmock.MockTx)
+	queue := testQueue(tx, Config{})
+	query, values := expectExec(tx, "UPDATE")
+	tx.On("Commit").Return(nil).Once()
+
+	err := queue.Retry(
+		endpointutil.NewContext(context.Background()),
+		"1",
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		"UPDATE `jobs` SET status = ?, attempts = ?, run_at = ?, "+
+			"last_error = ?, updated_at = ? WHERE `id` = ?",
+		*query,
+	)
+	assert.Equal(t, []any{
+		StatusPending, 0, testTime, "", testTime, "1",
+	}, *values)
+}
+
+// TestQueue_Run tests that the workers stop when the context is done and log
+// the errors of the queue.
+func TestQueue_Run(t *testing.T) {
+	logger := &mock.Recorder{}
+	queue := testQueue(new(utilmock.MockTx), Config{
+		Workers:      2,
+		PollInterval: time.Hour,
+		Logger:       logger,
+	})
+	queue.config.GetTxFn = func(ctx context.Context) (util.Tx, error) {
+		return nil, errors.New("connection error")
+	}
+	queue.helpers.GetTxFn = queue.config.GetTxFn
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+
+	go func() { done <- queue.Run(ctx) }()
+	assert.Eventually(t, func() bool {
+		return len(logger.Messages()) == 2
+	}, time.Second, time.Millisecond)
+	cancel()
+
+	assert.ErrorIs(t, <-done, context.Canceled)
+	assert.Equal(
+		t,
+		[]string{"Job queue error", "Job queue error"},
+		logger.Messages(),
+	)
+}
+
+// TestJob_Decode tests decoding the payload of a job.
+func TestJob_Decode(t *testing.T) {
+	var payload map[string]string
+	job := &Job{Payload: `{"to":"a"}`}
+
+	assert.NoError(t, job.Decode(&payload))
+	assert.Equal(t, map[string]string{"to": "a"}, payload)
+}
@@ -0,0 +1,113 @@
+package transaction
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	endpointutil "github.com/pakkasys/fluidapi/endpoint/util"
+)
+
+var afterCommitContextKey = endpointutil.NewDataKey()
+
+// ErrNoTransactionScope is returned when registering an after-commit function
+// outside a transaction started with a custom context.
+var ErrNoTransactionScope = errors.New(
+	"no transaction scope for the after-commit function",
+)
+
+// AfterCommitFunc is a side effect run after a transaction commits.
+type AfterCommitFunc func(ctx context.Context)
+
+type afterCommitCallbacks struct {
+	mu     sync.Mutex
+	fns    []AfterCommitFunc
+	parent *afterCommitCallbacks
+}
+
+// RegisterAfterCommit registers a function to run after the transaction of
+// the context commits. The functions are run in registration order and are
+// dropped if the transaction is rolled back. The functions of a nested
+// transaction are added to the outer transaction when the nested one
+// commits, so that they wait for the outer commit.
+//
+// The callbacks are stored in the custom context, so the transaction must be
+// started with a context created by endpointutil.NewContext.
+//
+//   - ctx: The context of the transaction.
+//   - fn: The function to run after the commit.
+//
+// Returns ErrNoTransactionScope if the context has no transaction started
+// with a custom context.
+func RegisterAfterCommit(ctx context.Context, fn AfterCommitFunc) error {
+	callbacks := endpointutil.GetContextValue[*afterCommitCallbacks](
+		ctx,
+		afterCommitContextKey,
+		nil,
+	)
+	if callbacks == nil {
+		return ErrNoTransactionScope
+	}
+
+	callbacks.mu.Lock()
+	defer callbacks.mu.Unlock()
+	callbacks.fns = append(callbacks.fns, fn)
+	return nil
+}
+
+// startAfterCommit sets up the after-commit callbacks of a new transaction.
+// The callbacks of an outer transaction become the parent of the new ones.
+func startAfterCommit(ctx context.Context) *afterCommitCallbacks {
+	if !endpointutil.IsContextSet(ctx) {
+		return nil
+	}
+	callbacks := &afterCommitCallbacks{
+		parent: endpointutil.GetContextValue[*afterCommitCallbacks](
+			ctx,
+			afterCommitContextKey,
+			nil,
+		),
+	}
+	endpointutil.SetContextValue(ctx, afterCommitContextKey, callbacks)
+	return callbacks
+}
+
+// finishAfterCommit restores the after-commit callbacks of the outer
+// transaction. If the transaction was committed, its callbacks are added to
+// the outer transaction, or run if there is none.
+func finishAfterCommit(
+	ctx context.Context,
+	callbacks *afterCommitCallbacks,
+	committed bool,
+) {
+	if callbacks == nil {
+		return
+	}
+	if callbacks.parent != nil {
+		endpointutil.SetContextValue(
+			ctx,
+			afterCommitContextKey,
+			callbacks.parent,
+		)
+	} else {
+		endpointutil.ClearContextValue(ctx, afterCommitContextKey)
+	}
+	if !committed {
+		return
+	}
+
+	callbacks.mu.Lock()
+	fns := callbacks.fns
+	callbacks.fns = nil
+	callbacks.mu.Unlock()
+
+	if callbacks.parent != nil {
+		callbacks.parent.mu.Lock()
+		defer callbacks.parent.mu.Unlock()
+		callbacks.parent.fns = append(callbacks.parent.fns, fns...)
+		return
+	}
+	for _, fn := range fns {
+		fn(ctx)
+	}
+}
@@ -0,0 +1,248 @@
+package transaction
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/pakkasys/fluidapi/database/util"
+	"github.com/pakkasys/fluidapi/database/util/mock"
+	endpointutil "github.com/pakkasys/fluidapi/endpoint/util"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestRegisterAfterCommit_NoTransaction tests that the function is not
+// registered without a transaction scope.
+func TestRegisterAfterCommit_NoTransaction(t *testing.T) {
+	called := false
+	fn := func(ctx context.Context) { called = true }
+
+	err := RegisterAfterCommit(
+		endpointutil.NewContext(context.Background()),
+		fn,
+	)
+	assert.ErrorIs(t, err, ErrNoTransactionScope)
+
+	err = RegisterAfterCommit(context.Background(), fn)
+	assert.ErrorIs(t, err, ErrNoTransactionScope)
+
+	assert.False(t, called)
+}
+
+// TestRegisterAfterCommit_NoCustomContext tests that a transaction without a
+// custom context has no scope for the functions.
+func TestRegisterAfterCommit_NoCustomContext(t *testing.T) {
+	mockTx := new(mock.MockTx)
+	mockTx.On("Rollback").Return(nil).Once()
+
+	_, err := ExecuteTransaction(
+		context.Background(),
+		mockTx,
+		func(ctx context.Context, tx util.Tx) (int, error) {
+			return 0, RegisterAfterCommit(ctx, func(ctx context.Context) {})
+		},
+	)
+
+	assert.ErrorIs(t, err, ErrNoTransactionScope)
+}
+
+// TestRegisterAfterCommit_Commit tests that the functions are run in order
+// after the managed transaction commits.
+func TestRegisterAfterCommit_Commit(t *testing.T) {
+	mockTx := new(mock.MockTx)
+	mockTx.On("Commit").Return(nil).Once()
+	ctx := endpointutil.NewContext(context.Background())
+	calls := []string{}
+
+	_, err := ExecuteManagedTransaction(
+		ctx,
+		func(ctx context.Context) (util.Tx, error) { return mockTx, nil },
+		func(ctx context.Context, tx util.Tx) (int, error) {
+			assert.NoError(t, RegisterAfterCommit(
+				ctx,
+				func(ctx context.Context) {
+					assert.Nil(t, GetTransactionFromContext(ctx))
+					calls = append(calls, "first")
+				},
+			))
+			assert.NoError(t, RegisterAfterCommit(
+				ctx,
+				func(ctx context.Context) {
+					calls = append(calls, "second")
+				},
+			))
+			assert.Empty(t, calls)
+			return 0, nil
+		},
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"first", "second"}, calls)
+	mockTx.AssertExpectations(t)
+}
+
+// TestRegisterAfterCommit_NestedTransaction tests that functions registered
+// in a nested managed transaction wait for the outer transaction.
+func TestRegisterAfterCommit_NestedTransaction(t *testing.T) {
+	mockTx := new(mock.MockTx)
+	mockTx.On("Commit").Return(nil).Once()
+	ctx := endpointutil.NewContext(context.Background())
+	getTxFn := func(ctx context.Context) (util.Tx, error) { return mockTx, nil }
+	called := false
+
+	_, err := ExecuteManagedTransaction(
+		ctx,
+		getTxFn,
+		func(ctx context.Context, tx util.Tx) (int, error) {
+			_, err := ExecuteManagedTransaction(
+				ctx,
+				getTxFn,
+				func(ctx context.Context, tx util.Tx) (int, error) {
+					err := RegisterAfterCommit(
+						ctx,
+						func(ctx context.Context) { called = true },
+					)
+					return 0, err
+				},
+			)
+			assert.False(t, called)
+			return 0, err
+		},
+	)
+
+	assert.NoError(t, err)
+	assert.True(t, called)
+}
+
+// TestRegisterAfterCommit_NestedExecuteTransaction tests that the functions
+// of a committed nested transaction are added to the outer transaction and
+// the functions of a rolled back one are dropped.
+func TestRegisterAfterCommit_NestedExecuteTransaction(t *testing.T) {
+	outerTx := new(mock.MockTx)
+	outerTx.On("Commit").Return(nil).Once()
+	committedTx := new(mock.MockTx)
+	committedTx.On("Commit").Return(nil).Once()
+	rolledBackTx := new(mock.MockTx)
+	rolledBackTx.On("Rollback").Return(nil).Once()
+	ctx := endpointutil.NewContext(context.Background())
+	calls := []string{}
+	register := func(ctx context.Context, call string) error {
+		return RegisterAfterCommit(ctx, func(ctx context.Context) {
+			calls = append(calls, call)
+		})
+	}
+
+	_, err := ExecuteManagedTransaction(
+		ctx,
+		func(ctx context.Context) (util.Tx, error) { return outerTx, nil },
+		func(ctx context.Context, tx util.Tx) (int, error) {
+			assert.NoError(t, register(ctx, "outer"))
+			_, err := ExecuteTransaction(
+				ctx,
+				committedTx,
+				func(ctx context.Context, tx util.Tx) (int, error) {
+					return 0, register(ctx, "committed")
+				},
+			)
+			assert.NoError(t, err)
+			_, err = ExecuteTransaction(
+				ctx,
+				rolledBackTx,
+				func(ctx context.Context, tx util.Tx) (int, error) {
+					assert.NoError(t, register(ctx, "rolled back"))
+					return 0, errors.New("failure")
+				},
+			)
+			assert.EqualError(t, err, "failure")
+			assert.NoError(t, register(ctx, "after"))
+			assert.Empty(t, calls)
+			return 0, nil
+		},
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"outer", "committed", "after"}, calls)
+	outerTx.AssertExpectations(t)
+	committedTx.AssertExpectations(t)
+	rolledBackTx.AssertExpectations(t)
+}
+
+// TestRegisterAfterCommit_Rollback tests that the functions are dropped when
+// the transaction is rolled back.
+func TestRegisterAfterCommit_Rollback(t *testing.T) {
+	mockTx := new(mock.MockTx)
+	mockTx.On("Rollback").Return(nil).Once()
+	ctx := endpointutil.NewContext(context.Background())
+	called := false
+
+	_, err := ExecuteTransaction(
+		ctx,
+		mockTx,
+		func(ctx context.Context, tx util.Tx) (int, error) {
+			assert.NoError(t, RegisterAfterCommit(
+				ctx,
+				func(ctx context.Context) { called = true },
+			))
+			return 0, errors.New("failure")
+		},
+	)
+
+	assert.EqualError(t, err, "failure")
+	assert.False(t, called)
+
+	// The scope of the transaction ends with the transaction.
+	assert.ErrorIs(
+		t,
+		RegisterAfterCommit(ctx, func(ctx context.Context) {}),
+		ErrNoTransactionScope,
+	)
+}
+
+// TestRegisterAfterCommit_CommitError tests that the functions are dropped
+// when the commit fails.
+func TestRegisterAfterCommit_CommitError(t *testing.T) {
+	mockTx := new(mock.MockTx)
+	mockTx.On("Commit").Return(errors.New("commit error")).Once()
+	ctx := endpointutil.NewContext(context.Background())
+	called := false
+
+	_, err := ExecuteTransaction(
+		ctx,
+		mockTx,
+		func(ctx context.Context, tx util.Tx) (int, error) {
+			err := RegisterAfterCommit(
+				ctx,
+				func(ctx context.Context) { called = true },
+			)
+			return 0, err
+		},
+	)
+
+	assert.Error(t, err)
+	assert.False(t, called)
+}
+
+// TestRegisterAfterCommit_Panic tests that the functions are dropped when
+// the transactional function panics.
+func TestRegisterAfterCommit_Panic(t *testing.T) {
+	mockTx := new(mock.MockTx)
+	mockTx.On("Rollback").Return(nil).Once()
+	ctx := endpointutil.NewContext(context.Background())
+	called := false
+
+	assert.Panics(t, func() {
+		_, _ = ExecuteTransaction(
+			ctx,
+			mockTx,
+			func(ctx context.Context, tx util.Tx) (int, error) {
+				_ = RegisterAfterCommit(
+					ctx,
+					func(ctx context.Context) { called = true },
+				)
+				panic("boom")
+			},
+		)
+	})
+
+	assert.False(t, called)
+}
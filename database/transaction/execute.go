@@ -23,6 +23,7 @@ func ExecuteTransaction[Result any](
 	tx util.Tx,
 	transactionalFn TransactionalFunc[Result],
 ) (result Result, txErr error) {
+	callbacks := startAfterCommit(ctx)
 	defer func() {
 		var r any
 		hasPanic := false
@@ -39,8 +40,10 @@ func ExecuteTransaction[Result any](
 
 		// Propagate the panic if there was one
 		if hasPanic {
+			finishAfterCommit(ctx, callbacks, false)
 			panic(r)
 		}
+		finishAfterCommit(ctx, callbacks, txErr == nil)
 	}()
 	return transactionalFn(ctx, tx)
 }
@@ -66,6 +69,7 @@ func ExecuteManagedTransaction[Result any](
 
 	if isNewTx {
 		SetTransactionToContext(ctx, tx)
+		callbacks := startAfterCommit(ctx)
 
 		defer func() {
 			var r any
@@ -83,8 +87,10 @@ func ExecuteManagedTransaction[Result any](
 
 			// Propagate the panic if there was one
 			if hasPanic {
+				finishAfterCommit(ctx, callbacks, false)
 				panic(r)
 			}
+			finishAfterCommit(ctx, callbacks, txErr == nil)
 		}()
 	}
 
@@ -0,0 +1,71 @@
+package util
+
+import (
+	"fmt"
+	"strings"
+)
+
+// AggregateFunction represents an SQL aggregate function.
+type AggregateFunction string
+
+const (
+	AggregateCount AggregateFunction = "COUNT"
+	AggregateSum   AggregateFunction = "SUM"
+	AggregateAvg   AggregateFunction = "AVG"
+	AggregateMin   AggregateFunction = "MIN"
+	AggregateMax   AggregateFunction = "MAX"
+)
+
+// AggregateFunctions is a list of all aggregate functions.
+var AggregateFunctions = []AggregateFunction{
+	AggregateCount,
+	AggregateSum,
+	AggregateAvg,
+	AggregateMin,
+	AggregateMax,
+}
+
+// Aggregate represents an aggregate of a column in a query, e.g.
+// SUM(`orders`.`total`) AS `total`. An aggregate without a column counts the
+// rows, i.e. COUNT(*).
+type Aggregate struct {
+	Function AggregateFunction
+	Table    string
+	Column   string
+	Alias    string
+}
+
+// String returns the string representation of the Aggregate
+func (a *Aggregate) String() string {
+	var column string
+	switch {
+	case a.Column == "":
+		column = "*"
+	case a.Table == "":
+		column = fmt.Sprintf("`%s`", a.Column)
+	default:
+		column = fmt.Sprintf("%s.`%s`", QuoteIdentifier(a.Table), a.Column)
+	}
+
+	aggregate := fmt.Sprintf("%s(%s)", a.Function, column)
+	if a.Alias != "" {
+		aggregate += fmt.Sprintf(" AS `%s`", a.Alias)
+	}
+	return aggregate
+}
+
+// GroupByClause renders the columns as a GROUP BY clause. It returns an empty
+// clause if there are no columns.
+//
+//   - columns: The columns of the clause.
+func GroupByClause(columns []ColumSelector) string {
+	if len(columns) == 0 {
+		return ""
+	}
+
+	columnStrings := make([]string, len(columns))
+	for i, column := range columns {
+		columnStrings[i] = column.String()
+	}
+	return "GROUP BY " + strings.Join(columnStrings, ", ")
+}
@@ -0,0 +1,78 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestAggregate_String tests the string representation of aggregates.
+func TestAggregate_String(t *testing.T) {
+	tests := []struct {
+		aggregate Aggregate
+		expected  string
+	}{
+		{Aggregate{Function: AggregateCount}, "COUNT(*)"},
+		{
+			Aggregate{Function: AggregateSum, Column: "total", Alias: "sum"},
+			"SUM(`total`) AS `sum`",
+		},
+		{
+			Aggregate{Function: AggregateMax, Table: "orders", Column: "total"},
+			"MAX(`orders`.`total`)",
+		},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expected, test.aggregate.String())
+	}
+}
+
+// TestGroupByClause tests rendering columns as a GROUP BY clause.
+func TestGroupByClause(t *testing.T) {
+	assert.Equal(t, "", GroupByClause(nil))
+	assert.Equal(
+		t,
+		"GROUP BY `orders`.`status`, `users`.`country`",
+		GroupByClause([]ColumSelector{
+			{Table: "orders", Column: "status"},
+			{Table: "users", Column: "country"},
+		}),
+	)
+}
+
+// TestSelectBuilder_GroupBy tests building a grouped SELECT statement with
+// aggregates.
+func TestSelectBuilder_GroupBy(t *testing.T) {
+	query, values := NewSelect("orders").
+		Columns(Projection{Table: "orders", Column: "status"}).
+		Aggregates(
+			Aggregate{Function: AggregateCount, Alias: "count"},
+			Aggregate{
+				Function: AggregateSum,
+				Table:    "orders",
+				Column:   "total",
+				Alias:    "sum_total",
+			},
+		).
+		Where(Selector{
+			Table:     "orders",
+			Field:     "total",
+			Predicate: GREATER,
+			Value:     0,
+		}).
+		GroupBy(ColumSelector{Table: "orders", Column: "status"}).
+		OrderBy(Order{Field: "sum_total", Direction: OrderDesc}).
+		Limit(10, 0).
+		Build()
+
+	assert.Equal(
+		t,
+		"SELECT `orders`.`status`,COUNT(*) AS `count`,"+
+			"SUM(`orders`.`total`) AS `sum_total` FROM `orders` "+
+			"WHERE `orders`.`total` > ? GROUP BY `orders`.`status` "+
+			"ORDER BY `sum_total` DESC LIMIT 10 OFFSET 0",
+		query,
+	)
+	assert.Equal(t, []any{0}, values)
+}
@@ -0,0 +1,104 @@
+package util
+
+import "context"
+
+// WithContext binds a context to a preparer. The statements prepared with the
+// returned preparer are prepared and run with the context, so that their
+// queries are cancelled when the context is done, e.g. at the deadline of a
+// request. A transaction stays a transaction, so the returned preparer of a
+// Tx implements Tx.
+//
+//   - ctx: The context of the queries.
+//   - preparer: The preparer to bind the context to.
+func WithContext(ctx context.Context, preparer Preparer) Preparer {
+	if ctx == nil || preparer == nil {
+		return preparer
+	}
+	if bound, ok := preparer.(contextBound); ok {
+		preparer = bound.unbound()
+	}
+	if tx, ok := preparer.(Tx); ok {
+		return &contextTx{contextPreparer: contextPreparer{ctx, tx}, tx: tx}
+	}
+	return &contextPreparer{ctx: ctx, preparer: preparer}
+}
+
+type contextBound interface {
+	unbound() Preparer
+}
+
+type contextPreparer struct {
+	ctx      context.Context
+	preparer Preparer
+}
+
+func (p *contextPreparer) Prepare(query string) (Stmt, error) {
+	return p.PrepareContext(p.ctx, query)
+}
+
+func (p *contextPreparer) PrepareContext(
+	ctx context.Context,
+	query string,
+) (Stmt, error) {
+	stmt, err := p.preparer.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return &contextStmt{ctx: ctx, stmt: stmt}, nil
+}
+
+func (p *contextPreparer) unbound() Preparer {
+	return p.preparer
+}
+
+type contextTx struct {
+	contextPreparer
+	tx Tx
+}
+
+func (tx *contextTx) Commit() error {
+	return tx.tx.Commit()
+}
+
+func (tx *contextTx) Rollback() error {
+	return tx.tx.Rollback()
+}
+
+type contextStmt struct {
+	ctx  context.Context
+	stmt Stmt
+}
+
+func (s *contextStmt) Close() error {
+	return s.stmt.Close()
+}
+
+func (s *contextStmt) QueryRow(args ...any) Row {
+	return s.stmt.QueryRowContext(s.ctx, args...)
+}
+
+func (s *contextStmt) QueryRowContext(ctx context.Context, args ...any) Row {
+	return s.stmt.QueryRowContext(ctx, args...)
+}
+
+func (s *contextStmt) Exec(args ...any) (Result, error) {
+	return s.stmt.ExecContext(s.ctx, args...)
+}
+
+func (s *contextStmt) ExecContext(
+	ctx context.Context,
+	args ...any,
+) (Result, error) {
+	return s.stmt.ExecContext(ctx, args...)
+}
+
+func (s *contextStmt) Query(args ...any) (Rows, error) {
+	return s.stmt.QueryContext(s.ctx, args...)
+}
+
+func (s *contextStmt) QueryContext(
+	ctx context.Context,
+	args ...any,
+) (Rows, error) {
+	return s.stmt.QueryContext(ctx, args...)
+}
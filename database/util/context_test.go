@@ -0,0 +1,79 @@
+package util
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type ctxKey struct{}
+
+// contextRecorder is a transaction recording the contexts of the calls.
+type contextRecorder struct {
+	Tx
+	contexts []context.Context
+}
+
+func (r *contextRecorder) PrepareContext(
+	ctx context.Context,
+	query string,
+) (Stmt, error) {
+	r.contexts = append(r.contexts, ctx)
+	return &contextRecorderStmt{recorder: r}, nil
+}
+
+type contextRecorderStmt struct {
+	Stmt
+	recorder *contextRecorder
+}
+
+func (s *contextRecorderStmt) ExecContext(
+	ctx context.Context,
+	args ...any,
+) (Result, error) {
+	s.recorder.contexts = append(s.recorder.contexts, ctx)
+	return nil, nil
+}
+
+func (s *contextRecorderStmt) QueryContext(
+	ctx context.Context,
+	args ...any,
+) (Rows, error) {
+	s.recorder.contexts = append(s.recorder.contexts, ctx)
+	return nil, nil
+}
+
+// TestWithContext tests that the statements of the preparer are prepared and
+// run with the bound context.
+func TestWithContext(t *testing.T) {
+	ctx := context.WithValue(context.Background(), ctxKey{}, "a")
+	recorder := &contextRecorder{}
+
+	preparer := WithContext(ctx, recorder)
+	stmt, err := preparer.Prepare("SELECT 1")
+	assert.NoError(t, err)
+	_, err = stmt.Query()
+	assert.NoError(t, err)
+	_, err = stmt.Exec()
+	assert.NoError(t, err)
+
+	assert.Equal(t, []context.Context{ctx, ctx, ctx}, recorder.contexts)
+}
+
+// TestWithContext_Tx tests that a bound transaction is a transaction and that
+// binding a bound preparer replaces its context.
+func TestWithContext_Tx(t *testing.T) {
+	first := context.WithValue(context.Background(), ctxKey{}, "a")
+	second := context.WithValue(context.Background(), ctxKey{}, "b")
+	recorder := &contextRecorder{}
+
+	preparer := WithContext(second, WithContext(first, recorder))
+	_, isTx := preparer.(Tx)
+	_, err := preparer.Prepare("SELECT 1")
+
+	assert.True(t, isTx)
+	assert.NoError(t, err)
+	assert.Equal(t, []context.Context{second}, recorder.contexts)
+	assert.Same(t, recorder, preparer.(*contextTx).tx)
+}
@@ -19,7 +19,54 @@ func NewSQLDB(driver string, dsn string) (DB, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &SQLDB{DB: db}, nil
+	return WrapSQLDB(db), nil
+}
+
+// WrapSQLDB wraps an existing *sql.DB as a DB. It allows using database
+// handles created elsewhere, e.g. by go-sqlmock, so that tests can verify the
+// exact queries, arguments and transaction boundaries generated by the entity
+// helpers:
+//
+//	sqlDB, mock, _ := sqlmock.New(
+//		sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual),
+//	)
+//	db := util.WrapSQLDB(sqlDB)
+//
+//	mock.ExpectBegin()
+//	mock.ExpectPrepare("INSERT INTO `users` (`name`) VALUES (?)").
+//		ExpectExec().
+//		WithArgs("alice").
+//		WillReturnResult(sqlmock.NewResult(1, 1))
+//	mock.ExpectCommit()
+//
+// The statements of the entity helpers are always prepared, so they are
+// matched with ExpectPrepare, while Exec and Query of the DB are sent to the
+// driver without a prepared statement, as they are by sqlmock.
+//
+// Parameters:
+//   - db: The database handle to wrap
+//
+// Returns:
+//   - A DB using the database handle
+func WrapSQLDB(db *sql.DB) DB {
+	if db == nil {
+		panic("db cannot be nil")
+	}
+	return &SQLDB{DB: db}
+}
+
+// WrapSQLTx wraps an existing *sql.Tx as a Tx.
+//
+// Parameters:
+//   - tx: The transaction to wrap
+//
+// Returns:
+//   - A Tx using the transaction
+func WrapSQLTx(tx *sql.Tx) Tx {
+	if tx == nil {
+		panic("tx cannot be nil")
+	}
+	return &RealTx{Tx: tx}
 }
 
 // SQLDB wraps *sql.DB to implement DBInterface.
@@ -61,6 +108,18 @@ func (db *SQLDB) Prepare(query string) (Stmt, error) {
 	return &RealStmt{Stmt: stmt}, nil
 }
 
+// PrepareContext creates a prepared statement bound to the context.
+func (db *SQLDB) PrepareContext(
+	ctx context.Context,
+	query string,
+) (Stmt, error) {
+	stmt, err := db.DB.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return &RealStmt{Stmt: stmt}, nil
+}
+
 // BeginTx creates a transaction and returns it.
 func (db *SQLDB) BeginTx(
 	ctx context.Context,
@@ -70,7 +129,7 @@ func (db *SQLDB) BeginTx(
 	if err != nil {
 		return nil, err
 	}
-	return &RealTx{Tx: tx}, nil
+	return WrapSQLTx(tx), nil
 }
 
 // Exec executes a query without returning rows.
@@ -91,6 +150,32 @@ func (db *SQLDB) Query(query string, args ...any) (Rows, error) {
 	return &RealRows{Rows: rows}, nil
 }
 
+// ExecContext executes a query without returning rows with the context.
+func (db *SQLDB) ExecContext(
+	ctx context.Context,
+	query string,
+	args ...any,
+) (Result, error) {
+	res, err := db.DB.ExecContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	return &RealResult{Result: res}, nil
+}
+
+// QueryContext executes a query that returns rows with the context.
+func (db *SQLDB) QueryContext(
+	ctx context.Context,
+	query string,
+	args ...any,
+) (Rows, error) {
+	rows, err := db.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	return &RealRows{Rows: rows}, nil
+}
+
 // RealStmt wraps *sql.Stmt to implement the Stmt interface.
 type RealStmt struct {
 	*sql.Stmt
@@ -116,6 +201,30 @@ func (s *RealStmt) Query(args ...any) (Rows, error) {
 	return s.Stmt.Query(args...)
 }
 
+// QueryRowContext executes a prepared query statement with the context and
+// the given arguments.
+func (s *RealStmt) QueryRowContext(ctx context.Context, args ...any) Row {
+	return s.Stmt.QueryRowContext(ctx, args...)
+}
+
+// ExecContext executes a prepared statement with the context and the given
+// arguments.
+func (s *RealStmt) ExecContext(
+	ctx context.Context,
+	args ...any,
+) (Result, error) {
+	return s.Stmt.ExecContext(ctx, args...)
+}
+
+// QueryContext executes a prepared query statement with the context and the
+// given arguments.
+func (s *RealStmt) QueryContext(
+	ctx context.Context,
+	args ...any,
+) (Rows, error) {
+	return s.Stmt.QueryContext(ctx, args...)
+}
+
 // RealRows wraps *sql.Rows to implement the Rows interface.
 type RealRows struct {
 	*sql.Rows
@@ -189,3 +298,15 @@ func (tx *RealTx) Prepare(query string) (Stmt, error) {
 	}
 	return &RealStmt{Stmt: stmt}, nil
 }
+
+// PrepareContext prepares the statement bound to the context.
+func (tx *RealTx) PrepareContext(
+	ctx context.Context,
+	query string,
+) (Stmt, error) {
+	stmt, err := tx.Tx.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return &RealStmt{Stmt: stmt}, nil
+}
@@ -0,0 +1,452 @@
+package util
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingConnector is a database/sql connector recording the calls made
+// through it.
+type recordingConnector struct {
+	calls []string
+}
+
+func (c *recordingConnector) Connect(context.Context) (driver.Conn, error) {
+	return &recordingConn{connector: c}, nil
+}
+
+func (c *recordingConnector) Driver() driver.Driver {
+	return nil
+}
+
+type recordingConn struct {
+	connector *recordingConnector
+}
+
+func (c *recordingConn) Prepare(query string) (driver.Stmt, error) {
+	c.connector.calls = append(c.connector.calls, "prepare "+query)
+	return &recordingStmt{connector: c.connector}, nil
+}
+
+func (c *recordingConn) Close() error {
+	return nil
+}
+
+func (c *recordingConn) Begin() (driver.Tx, error) {
+	c.connector.calls = append(c.connector.calls, "begin")
+	return &recordingTx{connector: c.connector}, nil
+}
+
+type recordingTx struct {
+	connector *recordingConnector
+}
+
+func (tx *recordingTx) Commit() error {
+	tx.connector.calls = append(tx.connector.calls, "commit")
+	return nil
+}
+
+func (tx *recordingTx) Rollback() error {
+	tx.connector.calls = append(tx.connector.calls, "rollback")
+	return nil
+}
+
+type recordingStmt struct {
+	connector *recordingConnector
+}
+
+func (s *recordingStmt) Close() error {
+	return nil
+}
+
+func (s *recordingStmt) NumInput() int {
+	return -1
+}
+
+func (s *recordingStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.connector.calls = append(s.connector.calls, "exec")
+	return driver.RowsAffected(len(args)), nil
+}
+
+func (s *recordingStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.connector.calls = append(s.connector.calls, "query")
+	return &recordingRows{}, nil
+}
+
+type recordingRows struct{}
+
+func (r *recordingRows) Columns() []string {
+	return []string{"id"}
+}
+
+func (r *recordingRows) Close() error {
+	return nil
+}
+
+func (r *recordingRows) Next(dest []driver.Value) error {
+	return io.EOF
+}
+
+// expectation is a call expected by the mock driver.
+type expectation struct {
+	call  string
+	query string
+	args  []driver.Value
+	rows  [][]driver.Value
+}
+
+// mockDriver is a database/sql connector verifying the calls in the order of
+// its expectations. Like go-sqlmock, its connections implement the context
+// interfaces of the driver package, so database/sql sends Exec and Query of
+// the DB to them without preparing a statement.
+type mockDriver struct {
+	expectations []expectation
+	err          error
+}
+
+func (d *mockDriver) Connect(context.Context) (driver.Conn, error) {
+	return &mockDriverConn{driver: d}, nil
+}
+
+func (d *mockDriver) Driver() driver.Driver {
+	return nil
+}
+
+// next checks that the call is the next expectation and returns it.
+func (d *mockDriver) next(
+	call string,
+	query string,
+	args []driver.NamedValue,
+) (*expectation, error) {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		values[i] = arg.Value
+	}
+	if len(d.expectations) == 0 {
+		d.err = fmt.Errorf("unexpected %s %q", call, query)
+		return nil, d.err
+	}
+	expected := d.expectations[0]
+	if expected.call != call ||
+		expected.query != query ||
+		(args != nil && !reflect.DeepEqual(expected.args, values)) {
+		d.err = fmt.Errorf(
+			"expected %s %q %v, got %s %q %v",
+			expected.call,
+			expected.query,
+			expected.args,
+			call,
+			query,
+			values,
+		)
+		return nil, d.err
+	}
+	d.expectations = d.expectations[1:]
+	return &expected, nil
+}
+
+type mockDriverConn struct {
+	driver *mockDriver
+}
+
+func (c *mockDriverConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *mockDriverConn) PrepareContext(
+	ctx context.Context,
+	query string,
+) (driver.Stmt, error) {
+	if _, err := c.driver.next("prepare", query, nil); err != nil {
+		return nil, err
+	}
+	return &mockDriverStmt{driver: c.driver, query: query}, nil
+}
+
+func (c *mockDriverConn) Close() error {
+	return nil
+}
+
+func (c *mockDriverConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *mockDriverConn) BeginTx(
+	ctx context.Context,
+	opts driver.TxOptions,
+) (driver.Tx, error) {
+	if _, err := c.driver.next("begin", "", nil); err != nil {
+		return nil, err
+	}
+	return &mockDriverTx{driver: c.driver}, nil
+}
+
+func (c *mockDriverConn) ExecContext(
+	ctx context.Context,
+	query string,
+	args []driver.NamedValue,
+) (driver.Result, error) {
+	if _, err := c.driver.next("exec", query, args); err != nil {
+		return nil, err
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func (c *mockDriverConn) QueryContext(
+	ctx context.Context,
+	query string,
+	args []driver.NamedValue,
+) (driver.Rows, error) {
+	expected, err := c.driver.next("query", query, args)
+	if err != nil {
+		return nil, err
+	}
+	return &mockDriverRows{rows: expected.rows}, nil
+}
+
+type mockDriverTx struct {
+	driver *mockDriver
+}
+
+func (tx *mockDriverTx) Commit() error {
+	_, err := tx.driver.next("commit", "", nil)
+	return err
+}
+
+func (tx *mockDriverTx) Rollback() error {
+	_, err := tx.driver.next("rollback", "", nil)
+	return err
+}
+
+type mockDriverStmt struct {
+	driver *mockDriver
+	query  string
+}
+
+func (s *mockDriverStmt) Close() error {
+	return nil
+}
+
+func (s *mockDriverStmt) NumInput() int {
+	return -1
+}
+
+func (s *mockDriverStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return nil, driver.ErrSkip
+}
+
+func (s *mockDriverStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return nil, driver.ErrSkip
+}
+
+func (s *mockDriverStmt) ExecContext(
+	ctx context.Context,
+	args []driver.NamedValue,
+) (driver.Result, error) {
+	if _, err := s.driver.next("exec", s.query, args); err != nil {
+		return nil, err
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func (s *mockDriverStmt) QueryContext(
+	ctx context.Context,
+	args []driver.NamedValue,
+) (driver.Rows, error) {
+	expected, err := s.driver.next("query", s.query, args)
+	if err != nil {
+		return nil, err
+	}
+	return &mockDriverRows{rows: expected.rows}, nil
+}
+
+type mockDriverRows struct {
+	rows [][]driver.Value
+}
+
+func (r *mockDriverRows) Columns() []string {
+	return []string{"id", "name"}
+}
+
+func (r *mockDriverRows) Close() error {
+	return nil
+}
+
+func (r *mockDriverRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+// TestWrapSQLDB_MockDriver tests the exact calls of the statements and the
+// transactions of a wrapped database handle of a mock driver implementing
+// the context interfaces like go-sqlmock.
+func TestWrapSQLDB_MockDriver(t *testing.T) {
+	mock := &mockDriver{expectations: []expectation{
+		{call: "begin"},
+		{call: "prepare", query: "INSERT INTO `users` (`name`) VALUES (?)"},
+		{
+			call:  "exec",
+			query: "INSERT INTO `users` (`name`) VALUES (?)",
+			args:  []driver.Value{"alice"},
+		},
+		{call: "commit"},
+		{call: "prepare", query: "SELECT * FROM `users` WHERE `id` = ?"},
+		{
+			call:  "query",
+			query: "SELECT * FROM `users` WHERE `id` = ?",
+			args:  []driver.Value{int64(1)},
+			rows:  [][]driver.Value{{int64(1), "alice"}},
+		},
+		{
+			call:  "exec",
+			query: "DELETE FROM `users` WHERE `id` = ?",
+			args:  []driver.Value{int64(1)},
+		},
+		{
+			call:  "query",
+			query: "SELECT * FROM `users`",
+			args:  []driver.Value{},
+		},
+	}}
+	db := WrapSQLDB(sql.OpenDB(mock))
+	defer db.Close()
+
+	tx, err := db.BeginTx(context.Background(), nil)
+	assert.NoError(t, err)
+	stmt, err := tx.Prepare("INSERT INTO `users` (`name`) VALUES (?)")
+	assert.NoError(t, err)
+	_, err = stmt.Exec("alice")
+	assert.NoError(t, err)
+	assert.NoError(t, stmt.Close())
+	assert.NoError(t, tx.Commit())
+
+	stmt, err = db.Prepare("SELECT * FROM `users` WHERE `id` = ?")
+	assert.NoError(t, err)
+	var id int64
+	var name string
+	assert.NoError(t, stmt.QueryRow(1).Scan(&id, &name))
+	assert.Equal(t, int64(1), id)
+	assert.Equal(t, "alice", name)
+	assert.NoError(t, stmt.Close())
+
+	_, err = db.Exec("DELETE FROM `users` WHERE `id` = ?", 1)
+	assert.NoError(t, err)
+	rows, err := db.Query("SELECT * FROM `users`")
+	assert.NoError(t, err)
+	assert.False(t, rows.Next())
+	assert.NoError(t, rows.Close())
+
+	assert.NoError(t, mock.err)
+	assert.Empty(t, mock.expectations)
+}
+
+// TestWrapSQLDB_Unexpected tests that the errors of the mock driver for
+// unexpected calls are returned through the wrapped database handle.
+func TestWrapSQLDB_Unexpected(t *testing.T) {
+	mock := &mockDriver{expectations: []expectation{{call: "begin"}}}
+	db := WrapSQLDB(sql.OpenDB(mock))
+	defer db.Close()
+
+	_, err := db.Exec("DELETE FROM `users`")
+
+	assert.EqualError(
+		t,
+		err,
+		"expected begin \"\" [], got exec \"DELETE FROM `users`\" []",
+	)
+}
+
+// TestWrapSQLDB_Nil tests that wrapping nil handles panics.
+func TestWrapSQLDB_Nil(t *testing.T) {
+	assert.PanicsWithValue(t, "db cannot be nil", func() { WrapSQLDB(nil) })
+	assert.PanicsWithValue(t, "tx cannot be nil", func() { WrapSQLTx(nil) })
+}
+
+// TestWrapSQLDB tests that the wrapped database handle is used for the
+// statements and the transactions.
+func TestWrapSQLDB(t *testing.T) {
+	connector := &recordingConnector{}
+	db := WrapSQLDB(sql.OpenDB(connector))
+	defer db.Close()
+
+	tx, err := db.BeginTx(context.Background(), nil)
+	assert.NoError(t, err)
+	stmt, err := tx.Prepare("UPDATE `t` SET a = ?")
+	assert.NoError(t, err)
+	result, err := stmt.Exec(1)
+	assert.NoError(t, err)
+	affected, err := result.RowsAffected()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), affected)
+	assert.NoError(t, stmt.Close())
+	assert.NoError(t, tx.Commit())
+
+	rows, err := db.Query("SELECT * FROM `t`")
+	assert.NoError(t, err)
+	assert.False(t, rows.Next())
+	assert.NoError(t, rows.Close())
+
+	assert.Equal(t, []string{
+		"begin",
+		"prepare UPDATE `t` SET a = ?",
+		"exec",
+		"commit",
+		"prepare SELECT * FROM `t`",
+		"query",
+	}, connector.calls)
+}
+
+// TestWrapSQLTx tests that the wrapped transaction is used for the
+// statements.
+func TestWrapSQLTx(t *testing.T) {
+	connector := &recordingConnector{}
+	sqlDB := sql.OpenDB(connector)
+	defer sqlDB.Close()
+	sqlTx, err := sqlDB.Begin()
+	assert.NoError(t, err)
+
+	tx := WrapSQLTx(sqlTx)
+	stmt, err := tx.Prepare("DELETE FROM `t`")
+	assert.NoError(t, err)
+	assert.NoError(t, stmt.Close())
+	assert.NoError(t, tx.Rollback())
+
+	assert.Equal(
+		t,
+		[]string{"begin", "prepare DELETE FROM `t`", "rollback"},
+		connector.calls,
+	)
+}
+
+// TestWrapSQLDB_Context tests that the statements prepared with a context
+// are run with it.
+func TestWrapSQLDB_Context(t *testing.T) {
+	connector := &recordingConnector{}
+	db := WrapSQLDB(sql.OpenDB(connector))
+	defer db.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	stmt, err := db.PrepareContext(ctx, "SELECT * FROM `t`")
+	assert.NoError(t, err)
+	rows, err := stmt.QueryContext(ctx)
+	assert.NoError(t, err)
+	assert.NoError(t, rows.Close())
+
+	cancel()
+	_, err = stmt.ExecContext(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+	_, err = db.QueryContext(ctx, "SELECT * FROM `t`")
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.NoError(t, stmt.Close())
+}
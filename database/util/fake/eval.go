@@ -0,0 +1,840 @@
+package fake
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrDuplicateEntry is returned when an inserted or updated row violates the
+// primary key or a unique key of a table.
+var ErrDuplicateEntry = errors.New("fake: duplicate entry")
+
+// table is the in-memory representation of a database table.
+type table struct {
+	columns       []string
+	schemaless    bool
+	primaryKey    string
+	uniqueKeys    [][]string
+	autoIncrement int64
+	rows          []map[string]any
+}
+
+// state holds the tables of the database.
+type state struct {
+	tables map[string]*table
+}
+
+// result is the outcome of an executed statement.
+type result struct {
+	columns      []string
+	rows         [][]any
+	lastInsertID int64
+	rowsAffected int64
+}
+
+func newState() *state {
+	return &state{tables: map[string]*table{}}
+}
+
+func (s *state) clone() *state {
+	cloned := newState()
+	for name, t := range s.tables {
+		copied := *t
+		copied.columns = append([]string{}, t.columns...)
+		copied.rows = make([]map[string]any, len(t.rows))
+		for i, row := range t.rows {
+			copied.rows[i] = copyRow(row)
+		}
+		cloned.tables[name] = &copied
+	}
+	return cloned
+}
+
+func copyRow(row map[string]any) map[string]any {
+	copied := make(map[string]any, len(row))
+	for column, value := range row {
+		copied[column] = value
+	}
+	return copied
+}
+
+func (s *state) execute(stmt *statement, args []any) (*result, error) {
+	if len(args) != stmt.params {
+		return nil, fmt.Errorf(
+			"fake: expected %d arguments, got %d",
+			stmt.params,
+			len(args),
+		)
+	}
+	values := make([]any, len(args))
+	for i, arg := range args {
+		value, err := normalize(arg)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = value
+	}
+
+	t, ok := s.tables[stmt.table]
+	if !ok {
+		return nil, fmt.Errorf("fake: table '%s' doesn't exist", stmt.table)
+	}
+
+	switch stmt.kind {
+	case statementSelect:
+		return t.selectRows(stmt, values)
+	case statementCount:
+		return t.count(stmt, values)
+	case statementInsert:
+		return t.insert(stmt, values)
+	case statementUpdate:
+		return t.update(stmt, values)
+	default:
+		return t.delete(stmt, values)
+	}
+}
+
+func (t *table) selectRows(stmt *statement, args []any) (*result, error) {
+	rows, err := t.matchingRows(stmt, args)
+	if err != nil {
+		return nil, err
+	}
+
+	columns := t.columns
+	if stmt.projections != nil {
+		columns = make([]string, len(stmt.projections))
+		for i, projection := range stmt.projections {
+			if err := t.checkColumn(projection.column); err != nil {
+				return nil, err
+			}
+			columns[i] = projection.column
+		}
+	}
+
+	res := &result{columns: columns, rows: make([][]any, len(rows))}
+	for i, index := range rows {
+		values := make([]any, len(columns))
+		for j, column := range columns {
+			values[j] = t.rows[index][column]
+		}
+		res.rows[i] = values
+	}
+	return res, nil
+}
+
+func (t *table) count(stmt *statement, args []any) (*result, error) {
+	rows, err := t.matchingRows(stmt, args)
+	if err != nil {
+		return nil, err
+	}
+	return &result{
+		columns: []string{"COUNT(*)"},
+		rows:    [][]any{{int64(len(rows))}},
+	}, nil
+}
+
+func (t *table) insert(stmt *statement, args []any) (*result, error) {
+	for _, column := range stmt.columns {
+		if err := t.checkColumn(column); err != nil {
+			return nil, err
+		}
+	}
+
+	res := &result{}
+	for _, values := range stmt.rows {
+		row := map[string]any{}
+		for i, column := range stmt.columns {
+			value, err := evaluate(values[i], args, nil, nil)
+			if err != nil {
+				return nil, err
+			}
+			row[column] = value
+		}
+
+		inserted, affected, err := t.insertRow(stmt, row, args)
+		if err != nil {
+			return nil, err
+		}
+		if inserted != nil && res.lastInsertID == 0 {
+			res.lastInsertID = *inserted
+		}
+		res.rowsAffected += affected
+	}
+	return res, nil
+}
+
+func (t *table) insertRow(
+	stmt *statement,
+	row map[string]any,
+	args []any,
+) (*int64, int64, error) {
+	id := t.assignPrimaryKey(row)
+
+	conflicts := t.conflictingRows(row, -1)
+	if len(conflicts) == 0 {
+		t.addRow(row, stmt.columns)
+		return id, 1, nil
+	}
+
+	switch stmt.conflict {
+	case conflictIgnore:
+		return nil, 0, nil
+	case conflictReplace:
+		t.removeRows(conflicts)
+		t.addRow(row, stmt.columns)
+		return id, int64(len(conflicts)) + 1, nil
+	case conflictUpdate:
+		index := conflicts[0]
+		changed, err := t.assign(index, stmt.assignments, args, row)
+		if err != nil || !changed {
+			return nil, 0, err
+		}
+		return nil, 2, nil
+	default:
+		return nil, 0, duplicateEntryError(row, t.conflictingKey(row, -1))
+	}
+}
+
+// assignPrimaryKey assigns the next auto-increment value to the row if it has
+// no primary key value.
+func (t *table) assignPrimaryKey(row map[string]any) *int64 {
+	if t.primaryKey == "" {
+		return nil
+	}
+
+	switch value := row[t.primaryKey].(type) {
+	case nil:
+	case int64:
+		if value != 0 {
+			if value > t.autoIncrement {
+				t.autoIncrement = value
+			}
+			return &value
+		}
+	default:
+		return nil
+	}
+
+	t.autoIncrement++
+	id := t.autoIncrement
+	row[t.primaryKey] = id
+	return &id
+}
+
+func (t *table) addRow(row map[string]any, columns []string) {
+	if t.primaryKey != "" {
+		t.addColumn(t.primaryKey)
+	}
+	for _, column := range columns {
+		t.addColumn(column)
+	}
+	t.rows = append(t.rows, row)
+}
+
+// addColumn adds a column to a schemaless table.
+func (t *table) addColumn(column string) {
+	if !t.hasColumn(column) {
+		t.columns = append(t.columns, column)
+	}
+}
+
+func (t *table) removeRows(indexes []int) {
+	remove := make(map[int]bool, len(indexes))
+	for _, index := range indexes {
+		remove[index] = true
+	}
+	kept := t.rows[:0]
+	for i, row := range t.rows {
+		if !remove[i] {
+			kept = append(kept, row)
+		}
+	}
+	t.rows = kept
+}
+
+func (t *table) update(stmt *statement, args []any) (*result, error) {
+	rows, err := t.matchingRows(stmt, args)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &result{}
+	for _, index := range rows {
+		changed, err := t.assign(index, stmt.assignments, args, nil)
+		if err != nil {
+			return nil, err
+		}
+		if changed {
+			res.rowsAffected++
+		}
+	}
+	return res, nil
+}
+
+// assign applies the assignments to the row at the index. The inserted row is
+// used to resolve VALUES() expressions of upserts.
+func (t *table) assign(
+	index int,
+	assignments []assignment,
+	args []any,
+	inserted map[string]any,
+) (bool, error) {
+	updated := copyRow(t.rows[index])
+	for _, assignment := range assignments {
+		if err := t.checkColumn(assignment.column); err != nil {
+			return false, err
+		}
+		value, err := evaluate(assignment.value, args, t.rows[index], inserted)
+		if err != nil {
+			return false, err
+		}
+		updated[assignment.column] = value
+	}
+
+	if key := t.conflictingKey(updated, index); key != nil {
+		return false, duplicateEntryError(updated, key)
+	}
+
+	changed := false
+	for column, value := range updated {
+		if c, ok := compare(value, t.rows[index][column]); !ok || c != 0 {
+			changed = true
+		}
+	}
+	for _, assignment := range assignments {
+		t.addColumn(assignment.column)
+	}
+	t.rows[index] = updated
+	return changed, nil
+}
+
+func (t *table) delete(stmt *statement, args []any) (*result, error) {
+	rows, err := t.matchingRows(stmt, args)
+	if err != nil {
+		return nil, err
+	}
+	t.removeRows(rows)
+	return &result{rowsAffected: int64(len(rows))}, nil
+}
+
+// matchingRows returns the indexes of the rows matching the conditions of the
+// statement, sorted and limited as requested by the statement.
+func (t *table) matchingRows(stmt *statement, args []any) ([]int, error) {
+	var indexes []int
+	for i, row := range t.rows {
+		matches, err := t.matches(row, stmt.conditions, args)
+		if err != nil {
+			return nil, err
+		}
+		if matches {
+			indexes = append(indexes, i)
+		}
+	}
+
+	for _, order := range stmt.orders {
+		if err := t.checkColumn(order.column); err != nil {
+			return nil, err
+		}
+	}
+	sort.SliceStable(indexes, func(i, j int) bool {
+		return t.less(t.rows[indexes[i]], t.rows[indexes[j]], stmt.orders)
+	})
+
+	if stmt.offset > 0 {
+		if stmt.offset >= len(indexes) {
+			return nil, nil
+		}
+		indexes = indexes[stmt.offset:]
+	}
+	if stmt.limit >= 0 && stmt.limit < len(indexes) {
+		indexes = indexes[:stmt.limit]
+	}
+	return indexes, nil
+}
+
+func (t *table) less(a, b map[string]any, orders []order) bool {
+	for _, order := range orders {
+		c := compareForSort(a[order.column], b[order.column])
+		if c == 0 {
+			continue
+		}
+		if order.descending {
+			return c > 0
+		}
+		return c < 0
+	}
+	return false
+}
+
+func (t *table) matches(
+	row map[string]any,
+	conditions []condition,
+	args []any,
+) (bool, error) {
+	for _, condition := range conditions {
+		if err := t.checkColumn(condition.column); err != nil {
+			return false, err
+		}
+		matches, err := evaluateCondition(condition, row, args)
+		if err != nil || !matches {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func evaluateCondition(
+	condition condition,
+	row map[string]any,
+	args []any,
+) (bool, error) {
+	value := row[condition.column]
+	switch condition.operator {
+	case "IS NULL":
+		return value == nil, nil
+	case "IS NOT NULL":
+		return value != nil, nil
+	}
+
+	var operands []any
+	for _, e := range condition.values {
+		operand, err := evaluate(e, args, row, nil)
+		if err != nil {
+			return false, err
+		}
+		operands = append(operands, operand)
+	}
+	if value == nil {
+		return false, nil
+	}
+
+	switch condition.operator {
+	case "IN", "NOT IN":
+		found := false
+		for _, operand := range expandList(operands) {
+			if c, ok := compare(value, operand); ok && c == 0 {
+				found = true
+				break
+			}
+		}
+		return found == (condition.operator == "IN"), nil
+	}
+
+	c, ok := compare(value, operands[0])
+	if !ok {
+		return false, nil
+	}
+	switch condition.operator {
+	case "=":
+		return c == 0, nil
+	case "!=":
+		return c != 0, nil
+	case "<":
+		return c < 0, nil
+	case "<=":
+		return c <= 0, nil
+	case ">":
+		return c > 0, nil
+	default:
+		return c >= 0, nil
+	}
+}
+
+// expandList expands slice operands of IN conditions into their elements.
+func expandList(operands []any) []any {
+	var expanded []any
+	for _, operand := range operands {
+		if list, ok := operand.([]any); ok {
+			expanded = append(expanded, list...)
+		} else {
+			expanded = append(expanded, operand)
+		}
+	}
+	return expanded
+}
+
+func evaluate(
+	e expr,
+	args []any,
+	row map[string]any,
+	inserted map[string]any,
+) (any, error) {
+	switch e.kind {
+	case exprParam:
+		return args[e.param], nil
+	case exprLiteral:
+		return e.value, nil
+	case exprColumn:
+		return row[e.column], nil
+	case exprValues:
+		if inserted == nil {
+			return nil, fmt.Errorf("fake: VALUES() used outside of an upsert")
+		}
+		return inserted[e.column], nil
+	}
+
+	left, err := evaluate(*e.left, args, row, inserted)
+	if err != nil {
+		return nil, err
+	}
+	right, err := evaluate(*e.right, args, row, inserted)
+	if err != nil {
+		return nil, err
+	}
+	return arithmetic(e.operator, left, right)
+}
+
+func arithmetic(operator string, left any, right any) (any, error) {
+	if left == nil || right == nil {
+		return nil, nil
+	}
+
+	sign := int64(1)
+	if operator == "-" {
+		sign = -1
+	}
+	l, lok := left.(int64)
+	r, rok := right.(int64)
+	if lok && rok {
+		return l + sign*r, nil
+	}
+
+	lf, lok := toFloat(left)
+	rf, rok := toFloat(right)
+	if !lok || !rok {
+		return nil, fmt.Errorf(
+			"fake: invalid operands for %s: %v, %v",
+			operator,
+			left,
+			right,
+		)
+	}
+	return lf + float64(sign)*rf, nil
+}
+
+// conflictingRows returns the indexes of the rows sharing the primary key or
+// a unique key with the row. The row at the skip index is ignored.
+func (t *table) conflictingRows(row map[string]any, skip int) []int {
+	var conflicts []int
+	for i, existing := range t.rows {
+		if i == skip {
+			continue
+		}
+		for _, key := range t.keys() {
+			if sameKey(key, row, existing) {
+				conflicts = append(conflicts, i)
+				break
+			}
+		}
+	}
+	return conflicts
+}
+
+func (t *table) conflictingKey(row map[string]any, skip int) []string {
+	for i, existing := range t.rows {
+		if i == skip {
+			continue
+		}
+		for _, key := range t.keys() {
+			if sameKey(key, row, existing) {
+				return key
+			}
+		}
+	}
+	return nil
+}
+
+func (t *table) keys() [][]string {
+	if t.primaryKey == "" {
+		return t.uniqueKeys
+	}
+	return append([][]string{{t.primaryKey}}, t.uniqueKeys...)
+}
+
+func sameKey(key []string, a map[string]any, b map[string]any) bool {
+	for _, column := range key {
+		if a[column] == nil || b[column] == nil {
+			return false
+		}
+		if c, ok := compare(a[column], b[column]); !ok || c != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func duplicateEntryError(row map[string]any, key []string) error {
+	values := make([]string, len(key))
+	for i, column := range key {
+		values[i] = fmt.Sprint(row[column])
+	}
+	return fmt.Errorf(
+		"%w '%s' for key '%s'",
+		ErrDuplicateEntry,
+		strings.Join(values, "-"),
+		strings.Join(key, ","),
+	)
+}
+
+func (t *table) hasColumn(column string) bool {
+	for _, c := range t.columns {
+		if c == column {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *table) checkColumn(column string) error {
+	if t.schemaless || t.hasColumn(column) {
+		return nil
+	}
+	return fmt.Errorf("fake: unknown column '%s'", column)
+}
+
+// normalize converts a query argument to the representation stored in the
+// tables: integers as int64, floats as float64, byte slices as strings and
+// slices as []any.
+func normalize(value any) (any, error) {
+	if valuer, ok := value.(driver.Valuer); ok {
+		v, err := valuer.Value()
+		if err != nil {
+			return nil, err
+		}
+		value = v
+	}
+	if value == nil {
+		return nil, nil
+	}
+
+	switch v := value.(type) {
+	case time.Time:
+		return v, nil
+	case []byte:
+		return string(v), nil
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Pointer:
+		if rv.IsNil() {
+			return nil, nil
+		}
+		return normalize(rv.Elem().Interface())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Int64:
+		return rv.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64:
+		return int64(rv.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), nil
+	case reflect.String:
+		return rv.String(), nil
+	case reflect.Bool:
+		return rv.Bool(), nil
+	case reflect.Slice, reflect.Array:
+		if rv.Kind() == reflect.Slice &&
+			rv.Type().Elem().Kind() == reflect.Uint8 {
+			return string(rv.Bytes()), nil
+		}
+		list := make([]any, rv.Len())
+		for i := range list {
+			element, err := normalize(rv.Index(i).Interface())
+			if err != nil {
+				return nil, err
+			}
+			list[i] = element
+		}
+		return list, nil
+	}
+	return nil, fmt.Errorf("fake: unsupported argument type %T", value)
+}
+
+// compare compares two normalized values. It returns false if the values are
+// not comparable.
+func compare(a any, b any) (int, bool) {
+	if a == nil || b == nil {
+		return 0, a == nil && b == nil
+	}
+
+	switch av := a.(type) {
+	case int64:
+		if bv, ok := b.(int64); ok {
+			return compareOrdered(av, bv), true
+		}
+	case string:
+		if bv, ok := b.(string); ok {
+			return strings.Compare(av, bv), true
+		}
+	case bool:
+		if bv, ok := b.(bool); ok {
+			return compareOrdered(boolToInt(av), boolToInt(bv)), true
+		}
+	case time.Time:
+		if bv, ok := b.(time.Time); ok {
+			return av.Compare(bv), true
+		}
+	}
+
+	af, aok := toFloat(a)
+	bf, bok := toFloat(b)
+	if aok && bok {
+		return compareOrdered(af, bf), true
+	}
+	return 0, false
+}
+
+// compareForSort orders NULL values before all other values.
+func compareForSort(a any, b any) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	}
+	c, _ := compare(a, b)
+	return c
+}
+
+func compareOrdered[T int64 | float64](a T, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func boolToInt(b bool) int64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func toFloat(value any) (float64, bool) {
+	switch v := value.(type) {
+	case int64:
+		return float64(v), true
+	case float64:
+		return v, true
+	case bool:
+		return float64(boolToInt(v)), true
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// assignValue stores a value of a result row in a Scan destination.
+func assignValue(dest any, value any) error {
+	if scanner, ok := dest.(sql.Scanner); ok {
+		return scanner.Scan(value)
+	}
+
+	target := reflect.ValueOf(dest)
+	if target.Kind() != reflect.Pointer || target.IsNil() {
+		return fmt.Errorf("fake: destination not a pointer")
+	}
+	return assignReflect(target.Elem(), value)
+}
+
+func assignReflect(target reflect.Value, value any) error {
+	if value == nil {
+		switch target.Kind() {
+		case reflect.Pointer, reflect.Interface, reflect.Slice, reflect.Map:
+			target.Set(reflect.Zero(target.Type()))
+			return nil
+		}
+		return fmt.Errorf(
+			"fake: converting NULL to %s is unsupported",
+			target.Type(),
+		)
+	}
+
+	if target.Kind() == reflect.Pointer {
+		element := reflect.New(target.Type().Elem())
+		if err := assignReflect(element.Elem(), value); err != nil {
+			return err
+		}
+		target.Set(element)
+		return nil
+	}
+
+	source := reflect.ValueOf(value)
+	if source.Type().AssignableTo(target.Type()) {
+		target.Set(source)
+		return nil
+	}
+
+	switch target.Kind() {
+	case reflect.String:
+		target.SetString(fmt.Sprint(value))
+		return nil
+	case reflect.Slice:
+		if s, ok := value.(string); ok &&
+			target.Type().Elem().Kind() == reflect.Uint8 {
+			target.SetBytes([]byte(s))
+			return nil
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16,
+		reflect.Uint32, reflect.Uint64, reflect.Float32, reflect.Float64,
+		reflect.Bool:
+		return assignNumber(target, value)
+	}
+
+	if source.Type().ConvertibleTo(target.Type()) {
+		target.Set(source.Convert(target.Type()))
+		return nil
+	}
+	return fmt.Errorf(
+		"fake: unsupported Scan, storing %T into %s",
+		value,
+		target.Type(),
+	)
+}
+
+func assignNumber(target reflect.Value, value any) error {
+	f, ok := toFloat(value)
+	if !ok {
+		return fmt.Errorf(
+			"fake: converting %T to %s is unsupported",
+			value,
+			target.Type(),
+		)
+	}
+
+	switch target.Kind() {
+	case reflect.Bool:
+		target.SetBool(f != 0)
+	case reflect.Float32, reflect.Float64:
+		target.SetFloat(f)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64:
+		if i, ok := value.(int64); ok {
+			target.SetUint(uint64(i))
+		} else {
+			target.SetUint(uint64(f))
+		}
+	default:
+		if i, ok := value.(int64); ok {
+			target.SetInt(i)
+		} else {
+			target.SetInt(int64(f))
+		}
+	}
+	return nil
+}
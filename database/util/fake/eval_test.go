@@ -0,0 +1,163 @@
+package fake
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testID int
+
+// TestNormalize tests the conversion of query arguments.
+func TestNormalize(t *testing.T) {
+	now := time.Now()
+	name := "alice"
+	var nilName *string
+
+	for _, tc := range []struct {
+		arg      any
+		expected any
+	}{
+		{arg: 1, expected: int64(1)},
+		{arg: uint8(2), expected: int64(2)},
+		{arg: testID(3), expected: int64(3)},
+		{arg: float32(1.5), expected: 1.5},
+		{arg: []byte("b"), expected: "b"},
+		{arg: &name, expected: "alice"},
+		{arg: nilName, expected: nil},
+		{arg: now, expected: now},
+		{arg: sql.NullInt64{Int64: 4, Valid: true}, expected: int64(4)},
+		{arg: sql.NullString{}, expected: nil},
+		{arg: []int{1, 2}, expected: []any{int64(1), int64(2)}},
+	} {
+		value, err := normalize(tc.arg)
+		assert.NoError(t, err)
+		assert.Equal(t, tc.expected, value)
+	}
+
+	_, err := normalize(map[string]int{})
+	assert.Error(t, err)
+}
+
+// TestCompare tests comparing normalized values.
+func TestCompare(t *testing.T) {
+	now := time.Now()
+
+	for _, tc := range []struct {
+		a, b     any
+		expected int
+		ok       bool
+	}{
+		{a: int64(1), b: int64(2), expected: -1, ok: true},
+		{a: int64(2), b: 1.5, expected: 1, ok: true},
+		{a: "b", b: "a", expected: 1, ok: true},
+		{a: "2", b: int64(2), expected: 0, ok: true},
+		{a: true, b: false, expected: 1, ok: true},
+		{a: now, b: now.Add(time.Second), expected: -1, ok: true},
+		{a: nil, b: nil, expected: 0, ok: true},
+		{a: nil, b: int64(1), ok: false},
+		{a: "a", b: int64(1), ok: false},
+	} {
+		c, ok := compare(tc.a, tc.b)
+		assert.Equal(t, tc.ok, ok, "%v %v", tc.a, tc.b)
+		if tc.ok {
+			assert.Equal(t, tc.expected, c, "%v %v", tc.a, tc.b)
+		}
+	}
+}
+
+// TestAssignValue tests storing result values in Scan destinations.
+func TestAssignValue(t *testing.T) {
+	var i int
+	assert.NoError(t, assignValue(&i, int64(5)))
+	assert.Equal(t, 5, i)
+
+	var id testID
+	assert.NoError(t, assignValue(&id, int64(6)))
+	assert.Equal(t, testID(6), id)
+
+	var f float64
+	assert.NoError(t, assignValue(&f, int64(2)))
+	assert.Equal(t, 2.0, f)
+
+	var s string
+	assert.NoError(t, assignValue(&s, int64(7)))
+	assert.Equal(t, "7", s)
+
+	var b []byte
+	assert.NoError(t, assignValue(&b, "bytes"))
+	assert.Equal(t, []byte("bytes"), b)
+
+	var p *string
+	assert.NoError(t, assignValue(&p, "ptr"))
+	assert.Equal(t, "ptr", *p)
+	assert.NoError(t, assignValue(&p, nil))
+	assert.Nil(t, p)
+
+	var ns sql.NullString
+	assert.NoError(t, assignValue(&ns, "null"))
+	assert.Equal(t, sql.NullString{String: "null", Valid: true}, ns)
+
+	var a any
+	assert.NoError(t, assignValue(&a, int64(8)))
+	assert.Equal(t, int64(8), a)
+
+	assert.Error(t, assignValue(&i, nil))
+	assert.Error(t, assignValue(i, int64(1)))
+	assert.Error(t, assignValue(&i, "not a number"))
+}
+
+// TestState_ExecuteInsertAndSelect tests inserting and selecting rows.
+func TestState_ExecuteInsertAndSelect(t *testing.T) {
+	s := newState()
+	s.tables["t"] = &table{schemaless: true, primaryKey: "id"}
+
+	res := mustExecute(
+		t,
+		s,
+		"INSERT INTO `t` (`name`, `age`) VALUES (?, ?), (?, ?)",
+		"a", 30, "b", nil,
+	)
+	assert.Equal(t, int64(1), res.lastInsertID)
+	assert.Equal(t, int64(2), res.rowsAffected)
+
+	res = mustExecute(t, s, "SELECT * FROM `t` ORDER BY `t`.`age` DESC")
+	assert.Equal(t, []string{"id", "name", "age"}, res.columns)
+	assert.Equal(t, [][]any{
+		{int64(1), "a", int64(30)},
+		{int64(2), "b", nil},
+	}, res.rows)
+
+	res = mustExecute(t, s, "SELECT * FROM `t` WHERE `age` IS NULL")
+	assert.Equal(t, [][]any{{int64(2), "b", nil}}, res.rows)
+}
+
+// TestState_ExecuteUnknownTable tests that unknown tables are rejected.
+func TestState_ExecuteUnknownTable(t *testing.T) {
+	stmt, err := parse("SELECT * FROM `missing`")
+	assert.NoError(t, err)
+
+	_, err = newState().execute(stmt, nil)
+
+	assert.EqualError(t, err, "fake: table 'missing' doesn't exist")
+}
+
+// TestState_ExecuteArgumentCount tests that the argument count is checked.
+func TestState_ExecuteArgumentCount(t *testing.T) {
+	stmt, err := parse("SELECT * FROM `t` WHERE `id` = ?")
+	assert.NoError(t, err)
+
+	_, err = newState().execute(stmt, nil)
+
+	assert.EqualError(t, err, "fake: expected 1 arguments, got 0")
+}
+
+func mustExecute(t *testing.T, s *state, query string, args ...any) *result {
+	stmt, err := parse(query)
+	assert.NoError(t, err)
+	res, err := s.execute(stmt, args)
+	assert.NoError(t, err)
+	return res
+}
@@ -0,0 +1,509 @@
+// Package fake provides an in-memory database implementing util.DB for tests.
+//
+// The fake database understands the SQL generated by the database packages:
+// single table SELECT, SELECT COUNT(*), INSERT (including INSERT IGNORE,
+// REPLACE INTO, ON DUPLICATE KEY UPDATE and ON CONFLICT DO NOTHING), UPDATE
+// and DELETE statements with AND-joined selectors, ORDER BY and LIMIT. Joins
+// and SQL functions are not supported.
+package fake
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	dberrors "github.com/pakkasys/fluidapi/database/errors"
+	"github.com/pakkasys/fluidapi/database/util"
+)
+
+// TableOptions configures a table of the fake database.
+type TableOptions struct {
+	// Columns are the columns of the table in the order returned by
+	// SELECT *. If empty, the table accepts any column and the columns are
+	// added in the order they are first written.
+	Columns []string
+	// PrimaryKey is the primary key column. Inserted rows without a value
+	// for it get the next auto-increment value.
+	PrimaryKey string
+	// UniqueKeys are the unique column combinations of the table.
+	UniqueKeys [][]string
+}
+
+// DB is an in-memory database implementing util.DB. Tables must be created
+// with CreateTable before they are used.
+type DB struct {
+	mu     sync.Mutex
+	state  *state
+	closed bool
+}
+
+// NewDB creates a new empty in-memory database.
+//
+// Returns:
+//   - A new DB.
+func NewDB() *DB {
+	return &DB{state: newState()}
+}
+
+// CreateTable creates a table, replacing any existing table with the same
+// name. Schema qualified names like "tenant.users" are supported.
+//
+// Parameters:
+//   - name: The name of the table.
+//   - opts: The options of the table.
+func (db *DB) CreateTable(name string, opts TableOptions) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	var uniqueKeys [][]string
+	for _, key := range opts.UniqueKeys {
+		uniqueKeys = append(uniqueKeys, append([]string{}, key...))
+	}
+	db.state.tables[name] = &table{
+		columns:    append([]string{}, opts.Columns...),
+		schemaless: len(opts.Columns) == 0,
+		primaryKey: opts.PrimaryKey,
+		uniqueKeys: uniqueKeys,
+	}
+}
+
+// Rows returns a copy of the rows of a table in insertion order, keyed by
+// column name. Integers are returned as int64 and floats as float64.
+//
+// Parameters:
+//   - name: The name of the table.
+//
+// Returns:
+//   - The rows of the table, or nil if the table does not exist.
+func (db *DB) Rows(name string) []map[string]any {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	t, ok := db.state.tables[name]
+	if !ok {
+		return nil
+	}
+	rows := make([]map[string]any, len(t.rows))
+	for i, row := range t.rows {
+		rows[i] = copyRow(row)
+	}
+	return rows
+}
+
+// Ping reports whether the database is open.
+func (db *DB) Ping() error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if db.closed {
+		return errors.New("sql: database is closed")
+	}
+	return nil
+}
+
+// SetConnMaxLifetime is a no-op for the in-memory database.
+func (db *DB) SetConnMaxLifetime(d time.Duration) {}
+
+// SetConnMaxIdleTime is a no-op for the in-memory database.
+func (db *DB) SetConnMaxIdleTime(d time.Duration) {}
+
+// SetMaxOpenConns is a no-op for the in-memory database.
+func (db *DB) SetMaxOpenConns(n int) {}
+
+// SetMaxIdleConns is a no-op for the in-memory database.
+func (db *DB) SetMaxIdleConns(n int) {}
+
+// Prepare parses the query and returns a statement executed directly against
+// the database.
+func (db *DB) Prepare(query string) (util.Stmt, error) {
+	if err := db.Ping(); err != nil {
+		return nil, err
+	}
+	stmt, err := parse(query)
+	if err != nil {
+		return nil, err
+	}
+	return &Stmt{statement: stmt, run: db.run}, nil
+}
+
+// PrepareContext prepares the query like Prepare. It returns the error of the
+// context if it is done.
+func (db *DB) PrepareContext(
+	ctx context.Context,
+	query string,
+) (util.Stmt, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return db.Prepare(query)
+}
+
+// BeginTx starts a transaction. The transaction works on a snapshot of the
+// database which replaces the database state when it is committed, so
+// changes made outside of the transaction in the meantime are discarded.
+func (db *DB) BeginTx(
+	ctx context.Context,
+	opts *sql.TxOptions,
+) (util.Tx, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if db.closed {
+		return nil, errors.New("sql: database is closed")
+	}
+	return &Tx{db: db, state: db.state.clone()}, nil
+}
+
+// Exec executes a query without returning rows.
+func (db *DB) Exec(query string, args ...any) (util.Result, error) {
+	stmt, err := db.Prepare(query)
+	if err != nil {
+		return nil, err
+	}
+	return stmt.Exec(args...)
+}
+
+// Query executes a query that returns rows.
+func (db *DB) Query(query string, args ...any) (util.Rows, error) {
+	stmt, err := db.Prepare(query)
+	if err != nil {
+		return nil, err
+	}
+	return stmt.Query(args...)
+}
+
+// ExecContext executes a query without returning rows with the context.
+func (db *DB) ExecContext(
+	ctx context.Context,
+	query string,
+	args ...any,
+) (util.Result, error) {
+	stmt, err := db.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return stmt.ExecContext(ctx, args...)
+}
+
+// QueryContext executes a query that returns rows with the context.
+func (db *DB) QueryContext(
+	ctx context.Context,
+	query string,
+	args ...any,
+) (util.Rows, error) {
+	stmt, err := db.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return stmt.QueryContext(ctx, args...)
+}
+
+// Close closes the database. Later operations return an error.
+func (db *DB) Close() error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.closed = true
+	return nil
+}
+
+func (db *DB) run(stmt *statement, args []any) (*result, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if db.closed {
+		return nil, errors.New("sql: database is closed")
+	}
+	return runStatement(&db.state, stmt, args)
+}
+
+// Tx is a transaction of the in-memory database.
+type Tx struct {
+	mu    sync.Mutex
+	db    *DB
+	state *state
+	done  bool
+}
+
+// Prepare parses the query and returns a statement executed within the
+// transaction.
+func (tx *Tx) Prepare(query string) (util.Stmt, error) {
+	tx.mu.Lock()
+	done := tx.done
+	tx.mu.Unlock()
+	if done {
+		return nil, sql.ErrTxDone
+	}
+
+	stmt, err := parse(query)
+	if err != nil {
+		return nil, err
+	}
+	return &Stmt{statement: stmt, run: tx.run}, nil
+}
+
+// PrepareContext prepares the query like Prepare. It returns the error of the
+// context if it is done.
+func (tx *Tx) PrepareContext(
+	ctx context.Context,
+	query string,
+) (util.Stmt, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return tx.Prepare(query)
+}
+
+// Commit replaces the database state with the state of the transaction.
+func (tx *Tx) Commit() error {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+	if tx.done {
+		return sql.ErrTxDone
+	}
+	tx.done = true
+
+	tx.db.mu.Lock()
+	defer tx.db.mu.Unlock()
+	tx.db.state = tx.state
+	return nil
+}
+
+// Rollback discards the changes of the transaction.
+func (tx *Tx) Rollback() error {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+	if tx.done {
+		return sql.ErrTxDone
+	}
+	tx.done = true
+	return nil
+}
+
+func (tx *Tx) run(stmt *statement, args []any) (*result, error) {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+	if tx.done {
+		return nil, sql.ErrTxDone
+	}
+	return runStatement(&tx.state, stmt, args)
+}
+
+// runStatement executes the statement. Writing statements are executed on a
+// copy of the state which replaces the state only if the statement succeeds.
+func runStatement(
+	current **state,
+	stmt *statement,
+	args []any,
+) (*result, error) {
+	if stmt.kind == statementSelect || stmt.kind == statementCount {
+		return (*current).execute(stmt, args)
+	}
+
+	working := (*current).clone()
+	res, err := working.execute(stmt, args)
+	if err != nil {
+		return nil, err
+	}
+	*current = working
+	return res, nil
+}
+
+// Stmt is a prepared statement of the in-memory database.
+type Stmt struct {
+	statement *statement
+	run       func(stmt *statement, args []any) (*result, error)
+}
+
+// Close is a no-op for the in-memory database.
+func (s *Stmt) Close() error {
+	return nil
+}
+
+// QueryRow executes the statement and returns the first row of the result.
+func (s *Stmt) QueryRow(args ...any) util.Row {
+	rows, err := s.Query(args...)
+	if err != nil {
+		return &Row{err: err}
+	}
+	return &Row{rows: rows.(*Rows)}
+}
+
+// Exec executes the statement without returning rows.
+func (s *Stmt) Exec(args ...any) (util.Result, error) {
+	res, err := s.run(s.statement, args)
+	if err != nil {
+		return nil, err
+	}
+	return &Result{
+		lastInsertID: res.lastInsertID,
+		rowsAffected: res.rowsAffected,
+	}, nil
+}
+
+// Query executes the statement and returns the resulting rows.
+func (s *Stmt) Query(args ...any) (util.Rows, error) {
+	res, err := s.run(s.statement, args)
+	if err != nil {
+		return nil, err
+	}
+	return &Rows{columns: res.columns, values: res.rows, pos: -1}, nil
+}
+
+// QueryRowContext executes the statement like QueryRow. The row returns the
+// error of the context if it is done.
+func (s *Stmt) QueryRowContext(ctx context.Context, args ...any) util.Row {
+	if err := ctx.Err(); err != nil {
+		return &Row{err: err}
+	}
+	return s.QueryRow(args...)
+}
+
+// ExecContext executes the statement like Exec. It returns the error of the
+// context if it is done.
+func (s *Stmt) ExecContext(
+	ctx context.Context,
+	args ...any,
+) (util.Result, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return s.Exec(args...)
+}
+
+// QueryContext executes the statement like Query. It returns the error of the
+// context if it is done.
+func (s *Stmt) QueryContext(
+	ctx context.Context,
+	args ...any,
+) (util.Rows, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return s.Query(args...)
+}
+
+// Rows are the rows returned by a query of the in-memory database.
+type Rows struct {
+	columns []string
+	values  [][]any
+	pos     int
+	closed  bool
+}
+
+// Columns returns the column names of the rows.
+func (r *Rows) Columns() ([]string, error) {
+	return append([]string{}, r.columns...), nil
+}
+
+// Next advances to the next row. It returns false when there are no more
+// rows.
+func (r *Rows) Next() bool {
+	if r.closed || r.pos+1 >= len(r.values) {
+		r.closed = true
+		return false
+	}
+	r.pos++
+	return true
+}
+
+// Scan copies the columns of the current row into the destinations.
+func (r *Rows) Scan(dest ...any) error {
+	if r.closed {
+		return errors.New("sql: Rows are closed")
+	}
+	if r.pos < 0 {
+		return errors.New("sql: Scan called without calling Next")
+	}
+	if len(dest) != len(r.columns) {
+		return fmt.Errorf(
+			"sql: expected %d destination arguments in Scan, not %d",
+			len(r.columns),
+			len(dest),
+		)
+	}
+	for i, value := range r.values[r.pos] {
+		if err := assignValue(dest[i], value); err != nil {
+			return fmt.Errorf(
+				"sql: Scan error on column %s: %w",
+				r.columns[i],
+				err,
+			)
+		}
+	}
+	return nil
+}
+
+// Close closes the rows.
+func (r *Rows) Close() error {
+	r.closed = true
+	return nil
+}
+
+// Err returns nil as the rows are fully materialized.
+func (r *Rows) Err() error {
+	return nil
+}
+
+// Row is the result of QueryRow.
+type Row struct {
+	rows *Rows
+	err  error
+}
+
+// Scan copies the columns of the first row into the destinations. It returns
+// sql.ErrNoRows if the query returned no rows.
+func (r *Row) Scan(dest ...any) error {
+	if r.err != nil {
+		return r.err
+	}
+	defer r.rows.Close()
+	if !r.rows.Next() {
+		return sql.ErrNoRows
+	}
+	return r.rows.Scan(dest...)
+}
+
+// Err returns the error of the query.
+func (r *Row) Err() error {
+	return r.err
+}
+
+// Result is the result of an executed statement.
+type Result struct {
+	lastInsertID int64
+	rowsAffected int64
+}
+
+// LastInsertId returns the auto-increment value of the first row inserted by
+// the statement.
+func (r *Result) LastInsertId() (int64, error) {
+	return r.lastInsertID, nil
+}
+
+// RowsAffected returns the number of rows inserted, changed or deleted by the
+// statement.
+func (r *Result) RowsAffected() (int64, error) {
+	return r.rowsAffected, nil
+}
+
+// SQLUtil is an entity.SQLUtil for the in-memory database.
+type SQLUtil struct{}
+
+// CheckDBError converts duplicate entry errors of the in-memory database to
+// DuplicateEntryError.
+//
+// Parameters:
+//   - err: The error to check.
+//
+// Returns:
+//   - The converted error.
+func (SQLUtil) CheckDBError(err error) error {
+	if errors.Is(err, ErrDuplicateEntry) {
+		return dberrors.DuplicateEntryError.WithData(err)
+	}
+	return err
+}
@@ -0,0 +1,351 @@
+package fake
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/pakkasys/fluidapi/database/entity"
+	dberrors "github.com/pakkasys/fluidapi/database/errors"
+	"github.com/pakkasys/fluidapi/database/util"
+	"github.com/pakkasys/fluidapi/endpoint/page"
+	endpointutil "github.com/pakkasys/fluidapi/endpoint/util"
+	"github.com/stretchr/testify/assert"
+)
+
+type testUser struct {
+	ID   int
+	Name string
+	Age  int
+}
+
+func newTestDB() *DB {
+	db := NewDB()
+	db.CreateTable("users", TableOptions{
+		Columns:    []string{"id", "name", "age"},
+		PrimaryKey: "id",
+		UniqueKeys: [][]string{{"name"}},
+	})
+	return db
+}
+
+func newUserHelpers(db *DB) *entity.EntityHelpers[testUser] {
+	return &entity.EntityHelpers[testUser]{
+		TableName: "users",
+		GetTxFn: func(ctx context.Context) (util.Tx, error) {
+			return db.BeginTx(ctx, nil)
+		},
+		InserterFn: func(u *testUser) ([]string, []any) {
+			return []string{"name", "age"}, []any{u.Name, u.Age}
+		},
+		ScanRowFn: func(row util.Row, u *testUser) error {
+			return row.Scan(&u.ID, &u.Name, &u.Age)
+		},
+		ScanRowsFn: func(rows util.Rows, u *testUser) error {
+			return rows.Scan(&u.ID, &u.Name, &u.Age)
+		},
+		SQLUtil: SQLUtil{},
+	}
+}
+
+// TestDB_ExecAndQuery tests executing statements directly on the database.
+func TestDB_ExecAndQuery(t *testing.T) {
+	db := newTestDB()
+
+	res, err := db.Exec(
+		"INSERT INTO `users` (`name`, `age`) VALUES (?, ?), (?, ?)",
+		"alice", 30, "bob", 25,
+	)
+	assert.NoError(t, err)
+	id, _ := res.LastInsertId()
+	assert.Equal(t, int64(1), id)
+	affected, _ := res.RowsAffected()
+	assert.Equal(t, int64(2), affected)
+
+	rows, err := db.Query(
+		"SELECT `users`.`name` FROM `users` WHERE `users`.`age` < ?",
+		28,
+	)
+	assert.NoError(t, err)
+	var names []string
+	for rows.Next() {
+		var name string
+		assert.NoError(t, rows.Scan(&name))
+		names = append(names, name)
+	}
+	assert.NoError(t, rows.Err())
+	assert.NoError(t, rows.Close())
+	assert.Equal(t, []string{"bob"}, names)
+}
+
+// TestDB_UnknownColumn tests that tables with columns reject unknown columns.
+func TestDB_UnknownColumn(t *testing.T) {
+	db := newTestDB()
+
+	_, err := db.Exec("INSERT INTO `users` (`email`) VALUES (?)", "a@b.c")
+
+	assert.EqualError(t, err, "fake: unknown column 'email'")
+}
+
+// TestDB_DuplicateEntry tests that duplicate keys are rejected without
+// applying any rows of the statement.
+func TestDB_DuplicateEntry(t *testing.T) {
+	db := newTestDB()
+
+	_, err := db.Exec(
+		"INSERT INTO `users` (`name`, `age`) VALUES (?, ?), (?, ?)",
+		"alice", 30, "alice", 31,
+	)
+
+	assert.ErrorIs(t, err, ErrDuplicateEntry)
+	assert.EqualError(
+		t,
+		err,
+		"fake: duplicate entry 'alice' for key 'name'",
+	)
+	assert.Empty(t, db.Rows("users"))
+}
+
+// TestDB_InsertModes tests the conflict handling of the insert modes.
+func TestDB_InsertModes(t *testing.T) {
+	db := newTestDB()
+	_, err := db.Exec(
+		"INSERT INTO `users` (`name`, `age`) VALUES (?, ?)",
+		"alice", 30,
+	)
+	assert.NoError(t, err)
+
+	res, err := db.Exec(
+		"INSERT IGNORE INTO `users` (`name`, `age`) VALUES (?, ?)",
+		"alice", 31,
+	)
+	assert.NoError(t, err)
+	affected, _ := res.RowsAffected()
+	assert.Equal(t, int64(0), affected)
+
+	_, err = db.Exec(
+		"INSERT INTO `users` (`name`, `age`) VALUES (?, ?) "+
+			"ON DUPLICATE KEY UPDATE `age` = VALUES(`age`) + ?",
+		"alice", 40, 2,
+	)
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		[]map[string]any{{"id": int64(1), "name": "alice", "age": int64(42)}},
+		db.Rows("users"),
+	)
+
+	_, err = db.Exec(
+		"REPLACE INTO `users` (`name`, `age`) VALUES (?, ?)",
+		"alice", 50,
+	)
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		[]map[string]any{{"id": int64(4), "name": "alice", "age": int64(50)}},
+		db.Rows("users"),
+	)
+}
+
+// TestDB_UpdateAndDelete tests updating and deleting rows.
+func TestDB_UpdateAndDelete(t *testing.T) {
+	db := newTestDB()
+	_, err := db.Exec(
+		"INSERT INTO `users` (`name`, `age`) VALUES (?, ?), (?, ?), (?, ?)",
+		"a", 1, "b", 2, "c", 3,
+	)
+	assert.NoError(t, err)
+
+	res, err := db.Exec(
+		"UPDATE `users` SET age = ? WHERE `users`.`age` >= ?",
+		10, 2,
+	)
+	assert.NoError(t, err)
+	affected, _ := res.RowsAffected()
+	assert.Equal(t, int64(2), affected)
+
+	_, err = db.Exec("UPDATE `users` SET name = ? WHERE `id` = ?", "a", 2)
+	assert.ErrorIs(t, err, ErrDuplicateEntry)
+
+	res, err = db.Exec(
+		"DELETE FROM `users` WHERE `users`.`age` = ? "+
+			"ORDER BY `users`.`id` DESC LIMIT 1",
+		10,
+	)
+	assert.NoError(t, err)
+	affected, _ = res.RowsAffected()
+	assert.Equal(t, int64(1), affected)
+	assert.Equal(t, []map[string]any{
+		{"id": int64(1), "name": "a", "age": int64(1)},
+		{"id": int64(2), "name": "b", "age": int64(10)},
+	}, db.Rows("users"))
+}
+
+// TestDB_QueryRowNoRows tests that QueryRow returns sql.ErrNoRows when the
+// query returns no rows.
+func TestDB_QueryRowNoRows(t *testing.T) {
+	db := newTestDB()
+	stmt, err := db.Prepare("SELECT * FROM `users` WHERE `id` = ?")
+	assert.NoError(t, err)
+
+	row := stmt.QueryRow(1)
+
+	assert.NoError(t, row.Err())
+	var user testUser
+	assert.Equal(
+		t,
+		sql.ErrNoRows,
+		row.Scan(&user.ID, &user.Name, &user.Age),
+	)
+}
+
+// TestDB_Close tests that a closed database rejects operations.
+func TestDB_Close(t *testing.T) {
+	db := newTestDB()
+
+	assert.NoError(t, db.Close())
+
+	assert.Error(t, db.Ping())
+	_, err := db.Prepare("SELECT * FROM `users`")
+	assert.Error(t, err)
+	_, err = db.BeginTx(context.Background(), nil)
+	assert.Error(t, err)
+}
+
+// TestTx_Commit tests that committed changes are visible in the database.
+func TestTx_Commit(t *testing.T) {
+	db := newTestDB()
+	tx, err := db.BeginTx(context.Background(), nil)
+	assert.NoError(t, err)
+
+	stmt, err := tx.Prepare("INSERT INTO `users` (`name`, `age`) VALUES (?, ?)")
+	assert.NoError(t, err)
+	_, err = stmt.Exec("alice", 30)
+	assert.NoError(t, err)
+	assert.Empty(t, db.Rows("users"))
+
+	assert.NoError(t, tx.Commit())
+	assert.Len(t, db.Rows("users"), 1)
+	assert.Equal(t, sql.ErrTxDone, tx.Commit())
+	_, err = tx.Prepare("SELECT * FROM `users`")
+	assert.Equal(t, sql.ErrTxDone, err)
+}
+
+// TestTx_Rollback tests that rolled back changes are discarded.
+func TestTx_Rollback(t *testing.T) {
+	db := newTestDB()
+	tx, err := db.BeginTx(context.Background(), nil)
+	assert.NoError(t, err)
+
+	stmt, err := tx.Prepare("INSERT INTO `users` (`name`, `age`) VALUES (?, ?)")
+	assert.NoError(t, err)
+	_, err = stmt.Exec("alice", 30)
+	assert.NoError(t, err)
+
+	assert.NoError(t, tx.Rollback())
+	assert.Empty(t, db.Rows("users"))
+	_, err = stmt.Exec("bob", 30)
+	assert.Equal(t, sql.ErrTxDone, err)
+}
+
+// TestTx_CanceledContext tests that a transaction is not started with a
+// canceled context.
+func TestTx_CanceledContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := newTestDB().BeginTx(ctx, nil)
+
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+// TestSQLUtil_CheckDBError tests the conversion of duplicate entry errors.
+func TestSQLUtil_CheckDBError(t *testing.T) {
+	err := duplicateEntryError(map[string]any{"id": 1}, []string{"id"})
+
+	assert.Equal(
+		t,
+		dberrors.DuplicateEntryError.WithData(err),
+		SQLUtil{}.CheckDBError(err),
+	)
+
+	other := errors.New("other")
+	assert.Equal(t, other, SQLUtil{}.CheckDBError(other))
+}
+
+// TestEntityHelpers tests running the entity helpers against the fake
+// database.
+func TestEntityHelpers(t *testing.T) {
+	db := newTestDB()
+	helpers := newUserHelpers(db)
+	ctx := endpointutil.NewContext(context.Background())
+
+	_, err := helpers.CreateEntitiesWithManagedTransaction(
+		ctx,
+		[]*testUser{{Name: "alice", Age: 30}, {Name: "bob", Age: 25}},
+		nil,
+	)
+	assert.NoError(t, err)
+
+	user, err := helpers.GetEntityWithManagedTransaction(
+		ctx,
+		entity.GetOptions{Options: entity.Options{
+			Selectors: []util.Selector{
+				{Table: "users", Field: "name", Predicate: "=", Value: "bob"},
+			},
+		}},
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, &testUser{ID: 2, Name: "bob", Age: 25}, user)
+
+	count, err := helpers.UpdateEntitiesWithManagedTransaction(
+		ctx,
+		[]util.Selector{
+			{Table: "users", Field: "id", Predicate: "=", Value: 1},
+		},
+		entity.Updates{{Field: "age", Value: 31}},
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+
+	users, err := helpers.GetEntitiesWithManagedTransaction(
+		ctx,
+		entity.GetOptions{Options: entity.Options{
+			Orders: []util.Order{
+				{Table: "users", Field: "age", Direction: util.OrderDesc},
+			},
+			Page: &page.Page{Limit: 10},
+		}},
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, []testUser{
+		{ID: 1, Name: "alice", Age: 31},
+		{ID: 2, Name: "bob", Age: 25},
+	}, users)
+
+	_, err = helpers.CreateEntityWithManagedTransaction(
+		ctx,
+		&testUser{Name: "alice"},
+		nil,
+	)
+	assert.Equal(
+		t,
+		dberrors.DuplicateEntryError.ID,
+		err.(interface{ GetID() string }).GetID(),
+	)
+
+	deleted, err := helpers.DeleteEntitiesWithManagedTransaction(
+		ctx,
+		[]util.Selector{
+			{Table: "users", Field: "id", Predicate: "IN", Value: []int{1, 2}},
+		},
+		nil,
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), deleted)
+
+	total, err := helpers.GetEntityCountWithManagedTransaction(ctx, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, total)
+}
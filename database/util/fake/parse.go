@@ -0,0 +1,759 @@
+package fake
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdentifier
+	tokenWord
+	tokenNumber
+	tokenString
+	tokenParam
+	tokenSymbol
+)
+
+type token struct {
+	kind  tokenKind
+	value string
+}
+
+type statementKind int
+
+const (
+	statementSelect statementKind = iota
+	statementCount
+	statementInsert
+	statementUpdate
+	statementDelete
+)
+
+type conflictMode int
+
+const (
+	conflictError conflictMode = iota
+	conflictIgnore
+	conflictReplace
+	conflictUpdate
+)
+
+type exprKind int
+
+const (
+	exprParam exprKind = iota
+	exprLiteral
+	exprColumn
+	exprValues
+	exprBinary
+)
+
+// expr is a value expression of a statement.
+type expr struct {
+	kind     exprKind
+	param    int
+	value    any
+	column   string
+	operator string
+	left     *expr
+	right    *expr
+}
+
+type condition struct {
+	column   string
+	operator string
+	values   []expr
+}
+
+type order struct {
+	column     string
+	descending bool
+}
+
+type assignment struct {
+	column string
+	value  expr
+}
+
+type projection struct {
+	column string
+}
+
+// statement is a parsed SQL statement.
+type statement struct {
+	kind        statementKind
+	table       string
+	projections []projection
+	conditions  []condition
+	orders      []order
+	limit       int
+	offset      int
+	columns     []string
+	rows        [][]expr
+	conflict    conflictMode
+	assignments []assignment
+	params      int
+}
+
+// parser parses the SQL dialect generated by the database packages.
+type parser struct {
+	tokens []token
+	pos    int
+	params int
+}
+
+func parse(query string) (*statement, error) {
+	tokens, err := tokenize(query)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	stmt, err := p.parseStatement()
+	if err != nil {
+		return nil, fmt.Errorf("fake: %w in query: %s", err, query)
+	}
+	stmt.params = p.params
+	return stmt, nil
+}
+
+func tokenize(query string) ([]token, error) {
+	var tokens []token
+	runes := []rune(query)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '`':
+			end := indexRune(runes, i+1, '`')
+			if end < 0 {
+				return nil, fmt.Errorf("fake: unterminated identifier")
+			}
+			tokens = append(tokens, token{
+				kind:  tokenIdentifier,
+				value: string(runes[i+1 : end]),
+			})
+			i = end + 1
+		case r == '\'':
+			end := indexRune(runes, i+1, '\'')
+			if end < 0 {
+				return nil, fmt.Errorf("fake: unterminated string")
+			}
+			tokens = append(tokens, token{
+				kind:  tokenString,
+				value: string(runes[i+1 : end]),
+			})
+			i = end + 1
+		case r == '?':
+			tokens = append(tokens, token{kind: tokenParam, value: "?"})
+			i++
+		case unicode.IsDigit(r):
+			start := i
+			for i < len(runes) &&
+				(unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, token{
+				kind:  tokenNumber,
+				value: string(runes[start:i]),
+			})
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i < len(runes) &&
+				(unicode.IsLetter(runes[i]) ||
+					unicode.IsDigit(runes[i]) ||
+					runes[i] == '_') {
+				i++
+			}
+			tokens = append(tokens, token{
+				kind:  tokenWord,
+				value: string(runes[start:i]),
+			})
+		default:
+			symbol := string(r)
+			if i+1 < len(runes) {
+				switch string(runes[i : i+2]) {
+				case "!=", "<>", "<=", ">=":
+					symbol = string(runes[i : i+2])
+				}
+			}
+			if !strings.Contains("(),.=<>*+-!", symbol[:1]) ||
+				symbol == "!" {
+				return nil, fmt.Errorf("fake: unexpected character %q", r)
+			}
+			tokens = append(tokens, token{kind: tokenSymbol, value: symbol})
+			i += len(symbol)
+		}
+	}
+	return append(tokens, token{kind: tokenEOF}), nil
+}
+
+func indexRune(runes []rune, start int, r rune) int {
+	for i := start; i < len(runes); i++ {
+		if runes[i] == r {
+			return i
+		}
+	}
+	return -1
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) isKeyword(keywords ...string) bool {
+	for i, keyword := range keywords {
+		if p.pos+i >= len(p.tokens) {
+			return false
+		}
+		t := p.tokens[p.pos+i]
+		if t.kind != tokenWord || !strings.EqualFold(t.value, keyword) {
+			return false
+		}
+	}
+	return true
+}
+
+func (p *parser) acceptKeyword(keywords ...string) bool {
+	if !p.isKeyword(keywords...) {
+		return false
+	}
+	p.pos += len(keywords)
+	return true
+}
+
+func (p *parser) expectKeyword(keywords ...string) error {
+	if !p.acceptKeyword(keywords...) {
+		return p.unexpected(strings.Join(keywords, " "))
+	}
+	return nil
+}
+
+func (p *parser) acceptSymbol(symbol string) bool {
+	t := p.peek()
+	if t.kind == tokenSymbol && t.value == symbol {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expectSymbol(symbol string) error {
+	if !p.acceptSymbol(symbol) {
+		return p.unexpected(symbol)
+	}
+	return nil
+}
+
+func (p *parser) unexpected(expected string) error {
+	t := p.peek()
+	if t.kind == tokenEOF {
+		return fmt.Errorf("expected %s, got end of query", expected)
+	}
+	return fmt.Errorf("expected %s, got %q", expected, t.value)
+}
+
+func (p *parser) parseStatement() (*statement, error) {
+	var stmt *statement
+	var err error
+	switch {
+	case p.isKeyword("SELECT"):
+		stmt, err = p.parseSelect()
+	case p.isKeyword("INSERT"), p.isKeyword("REPLACE"):
+		stmt, err = p.parseInsert()
+	case p.isKeyword("UPDATE"):
+		stmt, err = p.parseUpdate()
+	case p.isKeyword("DELETE"):
+		stmt, err = p.parseDelete()
+	default:
+		return nil, p.unexpected("SELECT, INSERT, REPLACE, UPDATE or DELETE")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().kind != tokenEOF {
+		return nil, p.unexpected("end of query")
+	}
+	return stmt, nil
+}
+
+func (p *parser) parseSelect() (*statement, error) {
+	if err := p.expectKeyword("SELECT"); err != nil {
+		return nil, err
+	}
+
+	stmt := &statement{kind: statementSelect, limit: -1}
+	if p.acceptKeyword("COUNT") {
+		if err := p.expectCountStar(); err != nil {
+			return nil, err
+		}
+		stmt.kind = statementCount
+	} else if !p.acceptSymbol("*") {
+		projections, err := p.parseProjections()
+		if err != nil {
+			return nil, err
+		}
+		stmt.projections = projections
+	}
+
+	if err := p.expectKeyword("FROM"); err != nil {
+		return nil, err
+	}
+	table, err := p.parseTableName()
+	if err != nil {
+		return nil, err
+	}
+	stmt.table = table
+
+	if p.isKeyword("INNER") || p.isKeyword("LEFT") ||
+		p.isKeyword("RIGHT") || p.isKeyword("JOIN") {
+		return nil, fmt.Errorf("joins are not supported")
+	}
+	if err := p.parseWhere(stmt); err != nil {
+		return nil, err
+	}
+	if err := p.parseOrderBy(stmt); err != nil {
+		return nil, err
+	}
+	if p.acceptKeyword("LIMIT") {
+		if stmt.limit, err = p.parseInt(); err != nil {
+			return nil, err
+		}
+		if p.acceptKeyword("OFFSET") {
+			if stmt.offset, err = p.parseInt(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	p.acceptKeyword("FOR", "UPDATE")
+
+	return stmt, nil
+}
+
+func (p *parser) expectCountStar() error {
+	if err := p.expectSymbol("("); err != nil {
+		return err
+	}
+	if err := p.expectSymbol("*"); err != nil {
+		return err
+	}
+	return p.expectSymbol(")")
+}
+
+func (p *parser) parseProjections() ([]projection, error) {
+	var projections []projection
+	for {
+		column, err := p.parseColumnName()
+		if err != nil {
+			return nil, err
+		}
+		if p.acceptKeyword("AS") {
+			if _, err := p.parseIdentifier(); err != nil {
+				return nil, err
+			}
+		}
+		projections = append(projections, projection{column: column})
+		if !p.acceptSymbol(",") {
+			return projections, nil
+		}
+	}
+}
+
+func (p *parser) parseInsert() (*statement, error) {
+	stmt := &statement{kind: statementInsert}
+	if p.acceptKeyword("REPLACE") {
+		stmt.conflict = conflictReplace
+	} else {
+		if err := p.expectKeyword("INSERT"); err != nil {
+			return nil, err
+		}
+		if p.acceptKeyword("IGNORE") {
+			stmt.conflict = conflictIgnore
+		}
+	}
+	if err := p.expectKeyword("INTO"); err != nil {
+		return nil, err
+	}
+
+	table, err := p.parseTableName()
+	if err != nil {
+		return nil, err
+	}
+	stmt.table = table
+
+	if err := p.expectSymbol("("); err != nil {
+		return nil, err
+	}
+	for {
+		column, err := p.parseIdentifier()
+		if err != nil {
+			return nil, err
+		}
+		stmt.columns = append(stmt.columns, column)
+		if !p.acceptSymbol(",") {
+			break
+		}
+	}
+	if err := p.expectSymbol(")"); err != nil {
+		return nil, err
+	}
+
+	if err := p.expectKeyword("VALUES"); err != nil {
+		return nil, err
+	}
+	for {
+		row, err := p.parseValueRow()
+		if err != nil {
+			return nil, err
+		}
+		if len(row) != len(stmt.columns) {
+			return nil, fmt.Errorf("column count doesn't match value count")
+		}
+		stmt.rows = append(stmt.rows, row)
+		if !p.acceptSymbol(",") {
+			break
+		}
+	}
+
+	return stmt, p.parseConflictClause(stmt)
+}
+
+func (p *parser) parseValueRow() ([]expr, error) {
+	if err := p.expectSymbol("("); err != nil {
+		return nil, err
+	}
+	var row []expr
+	for {
+		value, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		row = append(row, value)
+		if !p.acceptSymbol(",") {
+			break
+		}
+	}
+	return row, p.expectSymbol(")")
+}
+
+func (p *parser) parseConflictClause(stmt *statement) error {
+	if p.acceptKeyword("ON", "CONFLICT", "DO", "NOTHING") {
+		stmt.conflict = conflictIgnore
+		return nil
+	}
+	if !p.acceptKeyword("ON", "DUPLICATE", "KEY", "UPDATE") {
+		return nil
+	}
+	if stmt.conflict != conflictError {
+		return fmt.Errorf("ON DUPLICATE KEY UPDATE with conflicting mode")
+	}
+
+	stmt.conflict = conflictUpdate
+	assignments, err := p.parseAssignments()
+	if err != nil {
+		return err
+	}
+	stmt.assignments = assignments
+	return nil
+}
+
+func (p *parser) parseUpdate() (*statement, error) {
+	if err := p.expectKeyword("UPDATE"); err != nil {
+		return nil, err
+	}
+
+	table, err := p.parseTableName()
+	if err != nil {
+		return nil, err
+	}
+	stmt := &statement{kind: statementUpdate, table: table, limit: -1}
+
+	if err := p.expectKeyword("SET"); err != nil {
+		return nil, err
+	}
+	if stmt.assignments, err = p.parseAssignments(); err != nil {
+		return nil, err
+	}
+
+	return stmt, p.parseWhere(stmt)
+}
+
+func (p *parser) parseDelete() (*statement, error) {
+	if err := p.expectKeyword("DELETE", "FROM"); err != nil {
+		return nil, err
+	}
+
+	table, err := p.parseTableName()
+	if err != nil {
+		return nil, err
+	}
+	stmt := &statement{kind: statementDelete, table: table, limit: -1}
+
+	if err := p.parseWhere(stmt); err != nil {
+		return nil, err
+	}
+	if err := p.parseOrderBy(stmt); err != nil {
+		return nil, err
+	}
+	if p.acceptKeyword("LIMIT") {
+		if stmt.limit, err = p.parseInt(); err != nil {
+			return nil, err
+		}
+	}
+
+	return stmt, nil
+}
+
+func (p *parser) parseAssignments() ([]assignment, error) {
+	var assignments []assignment
+	for {
+		column, err := p.parseColumnName()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expectSymbol("="); err != nil {
+			return nil, err
+		}
+		value, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		assignments = append(
+			assignments,
+			assignment{column: column, value: value},
+		)
+		if !p.acceptSymbol(",") {
+			return assignments, nil
+		}
+	}
+}
+
+func (p *parser) parseWhere(stmt *statement) error {
+	if !p.acceptKeyword("WHERE") {
+		return nil
+	}
+	for {
+		condition, err := p.parseCondition()
+		if err != nil {
+			return err
+		}
+		stmt.conditions = append(stmt.conditions, condition)
+		if !p.acceptKeyword("AND") {
+			return nil
+		}
+	}
+}
+
+func (p *parser) parseCondition() (condition, error) {
+	column, err := p.parseColumnName()
+	if err != nil {
+		return condition{}, err
+	}
+
+	switch {
+	case p.acceptKeyword("IS", "NOT", "NULL"):
+		return condition{column: column, operator: "IS NOT NULL"}, nil
+	case p.acceptKeyword("IS", "NULL"):
+		return condition{column: column, operator: "IS NULL"}, nil
+	case p.acceptKeyword("NOT", "IN"):
+		values, err := p.parseInValues()
+		return condition{column: column, operator: "NOT IN", values: values},
+			err
+	case p.acceptKeyword("IN"):
+		values, err := p.parseInValues()
+		return condition{column: column, operator: "IN", values: values}, err
+	}
+
+	t := p.peek()
+	if t.kind != tokenSymbol || !isComparison(t.value) {
+		return condition{}, p.unexpected("comparison operator")
+	}
+	p.pos++
+	value, err := p.parseExpr()
+	if err != nil {
+		return condition{}, err
+	}
+	operator := t.value
+	if operator == "<>" {
+		operator = "!="
+	}
+	return condition{
+		column:   column,
+		operator: operator,
+		values:   []expr{value},
+	}, nil
+}
+
+func isComparison(operator string) bool {
+	switch operator {
+	case "=", "!=", "<>", "<", "<=", ">", ">=":
+		return true
+	}
+	return false
+}
+
+func (p *parser) parseInValues() ([]expr, error) {
+	if !p.acceptSymbol("(") {
+		value, err := p.parseExpr()
+		return []expr{value}, err
+	}
+	var values []expr
+	for {
+		value, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+		if !p.acceptSymbol(",") {
+			break
+		}
+	}
+	return values, p.expectSymbol(")")
+}
+
+func (p *parser) parseOrderBy(stmt *statement) error {
+	if !p.acceptKeyword("ORDER", "BY") {
+		return nil
+	}
+	for {
+		column, err := p.parseColumnName()
+		if err != nil {
+			return err
+		}
+		descending := false
+		if p.acceptKeyword("DESC") {
+			descending = true
+		} else {
+			p.acceptKeyword("ASC")
+		}
+		stmt.orders = append(
+			stmt.orders,
+			order{column: column, descending: descending},
+		)
+		if !p.acceptSymbol(",") {
+			return nil
+		}
+	}
+}
+
+func (p *parser) parseExpr() (expr, error) {
+	left, err := p.parseOperand()
+	if err != nil {
+		return expr{}, err
+	}
+	for {
+		t := p.peek()
+		if t.kind != tokenSymbol || (t.value != "+" && t.value != "-") {
+			return left, nil
+		}
+		p.pos++
+		right, err := p.parseOperand()
+		if err != nil {
+			return expr{}, err
+		}
+		l, r := left, right
+		left = expr{kind: exprBinary, operator: t.value, left: &l, right: &r}
+	}
+}
+
+func (p *parser) parseOperand() (expr, error) {
+	t := p.peek()
+	switch {
+	case t.kind == tokenParam:
+		p.pos++
+		p.params++
+		return expr{kind: exprParam, param: p.params - 1}, nil
+	case t.kind == tokenNumber:
+		p.pos++
+		if number, err := strconv.ParseInt(t.value, 10, 64); err == nil {
+			return expr{kind: exprLiteral, value: number}, nil
+		}
+		number, err := strconv.ParseFloat(t.value, 64)
+		if err != nil {
+			return expr{}, fmt.Errorf("invalid number %q", t.value)
+		}
+		return expr{kind: exprLiteral, value: number}, nil
+	case t.kind == tokenString:
+		p.pos++
+		return expr{kind: exprLiteral, value: t.value}, nil
+	case p.acceptKeyword("NULL"):
+		return expr{kind: exprLiteral, value: nil}, nil
+	case p.isKeyword("VALUES"):
+		p.pos++
+		if err := p.expectSymbol("("); err != nil {
+			return expr{}, err
+		}
+		column, err := p.parseColumnName()
+		if err != nil {
+			return expr{}, err
+		}
+		return expr{kind: exprValues, column: column}, p.expectSymbol(")")
+	case t.kind == tokenIdentifier || t.kind == tokenWord:
+		column, err := p.parseColumnName()
+		if err != nil {
+			return expr{}, err
+		}
+		if p.peek().kind == tokenSymbol && p.peek().value == "(" {
+			return expr{}, fmt.Errorf("function %s is not supported", column)
+		}
+		return expr{kind: exprColumn, column: column}, nil
+	}
+	return expr{}, p.unexpected("expression")
+}
+
+// parseTableName parses a possibly schema qualified table name.
+func (p *parser) parseTableName() (string, error) {
+	parts, err := p.parseQualifiedName()
+	if err != nil {
+		return "", err
+	}
+	return strings.Join(parts, "."), nil
+}
+
+// parseColumnName parses a possibly table qualified column name and returns
+// the column part of it.
+func (p *parser) parseColumnName() (string, error) {
+	parts, err := p.parseQualifiedName()
+	if err != nil {
+		return "", err
+	}
+	return parts[len(parts)-1], nil
+}
+
+func (p *parser) parseQualifiedName() ([]string, error) {
+	var parts []string
+	for {
+		part, err := p.parseIdentifier()
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, part)
+		if !p.acceptSymbol(".") {
+			return parts, nil
+		}
+	}
+}
+
+func (p *parser) parseIdentifier() (string, error) {
+	t := p.peek()
+	if t.kind != tokenIdentifier && t.kind != tokenWord {
+		return "", p.unexpected("identifier")
+	}
+	p.pos++
+	return t.value, nil
+}
+
+func (p *parser) parseInt() (int, error) {
+	t := p.peek()
+	if t.kind != tokenNumber {
+		return 0, p.unexpected("number")
+	}
+	p.pos++
+	return strconv.Atoi(t.value)
+}
@@ -0,0 +1,149 @@
+package fake
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestParse_Select tests parsing a select statement with all clauses.
+func TestParse_Select(t *testing.T) {
+	stmt, err := parse(
+		"SELECT `users`.`id`,`users`.`name` AS `n` FROM `users` " +
+			"WHERE `users`.`age` >= ? AND `users`.`id` IN (?,?) " +
+			"ORDER BY `users`.`name` DESC, `users`.`id` ASC " +
+			"LIMIT 10 OFFSET 5 FOR UPDATE",
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(t, statementSelect, stmt.kind)
+	assert.Equal(t, "users", stmt.table)
+	assert.Equal(
+		t,
+		[]projection{{column: "id"}, {column: "name"}},
+		stmt.projections,
+	)
+	assert.Equal(t, []condition{
+		{
+			column:   "age",
+			operator: ">=",
+			values:   []expr{{kind: exprParam, param: 0}},
+		},
+		{
+			column:   "id",
+			operator: "IN",
+			values: []expr{
+				{kind: exprParam, param: 1},
+				{kind: exprParam, param: 2},
+			},
+		},
+	}, stmt.conditions)
+	assert.Equal(
+		t,
+		[]order{{column: "name", descending: true}, {column: "id"}},
+		stmt.orders,
+	)
+	assert.Equal(t, 10, stmt.limit)
+	assert.Equal(t, 5, stmt.offset)
+	assert.Equal(t, 3, stmt.params)
+}
+
+// TestParse_Count tests parsing a count statement with a schema qualified
+// table.
+func TestParse_Count(t *testing.T) {
+	stmt, err := parse(
+		"SELECT COUNT(*) FROM `tenant`.`users`  WHERE `name` IS NOT NULL",
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(t, statementCount, stmt.kind)
+	assert.Equal(t, "tenant.users", stmt.table)
+	assert.Equal(
+		t,
+		[]condition{{column: "name", operator: "IS NOT NULL"}},
+		stmt.conditions,
+	)
+	assert.Equal(t, -1, stmt.limit)
+}
+
+// TestParse_InsertModes tests parsing the insert conflict modes.
+func TestParse_InsertModes(t *testing.T) {
+	for _, tc := range []struct {
+		query string
+		mode  conflictMode
+	}{
+		{query: "INSERT INTO `t` (`a`) VALUES (?)", mode: conflictError},
+		{
+			query: "INSERT IGNORE INTO `t` (`a`) VALUES (?)",
+			mode:  conflictIgnore,
+		},
+		{query: "REPLACE INTO `t` (`a`) VALUES (?)", mode: conflictReplace},
+		{
+			query: "INSERT INTO `t` (`a`) VALUES (?) ON CONFLICT DO NOTHING",
+			mode:  conflictIgnore,
+		},
+	} {
+		stmt, err := parse(tc.query)
+		assert.NoError(t, err, tc.query)
+		assert.Equal(t, tc.mode, stmt.conflict, tc.query)
+	}
+}
+
+// TestParse_Upsert tests parsing an upsert with update expressions.
+func TestParse_Upsert(t *testing.T) {
+	stmt, err := parse(
+		"INSERT INTO `t` (`id`, `n`) VALUES (?, ?), (?, ?) " +
+			"ON DUPLICATE KEY UPDATE `n` = VALUES(`n`), `c` = `c` + ?",
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(t, statementInsert, stmt.kind)
+	assert.Equal(t, conflictUpdate, stmt.conflict)
+	assert.Equal(t, []string{"id", "n"}, stmt.columns)
+	assert.Len(t, stmt.rows, 2)
+	assert.Equal(t, 5, stmt.params)
+	assert.Equal(
+		t,
+		assignment{column: "n", value: expr{kind: exprValues, column: "n"}},
+		stmt.assignments[0],
+	)
+	assert.Equal(t, exprBinary, stmt.assignments[1].value.kind)
+	assert.Equal(t, "+", stmt.assignments[1].value.operator)
+}
+
+// TestParse_UpdateAndDelete tests parsing update and delete statements.
+func TestParse_UpdateAndDelete(t *testing.T) {
+	update, err := parse(
+		"UPDATE `t` SET name = ?, `age` = ? WHERE `t`.`id` = ?",
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, statementUpdate, update.kind)
+	assert.Equal(t, "name", update.assignments[0].column)
+	assert.Equal(t, "age", update.assignments[1].column)
+	assert.Equal(t, 3, update.params)
+
+	del, err := parse(
+		"DELETE FROM `t` WHERE `t`.`id` = ? ORDER BY `t`.`id` ASC LIMIT 2",
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, statementDelete, del.kind)
+	assert.Equal(t, 2, del.limit)
+	assert.Len(t, del.orders, 1)
+}
+
+// TestParse_Errors tests that unsupported queries are rejected.
+func TestParse_Errors(t *testing.T) {
+	for _, query := range []string{
+		"",
+		"TRUNCATE `t`",
+		"SELECT * FROM `t` INNER JOIN `u` ON `t`.`id` = `u`.`id`",
+		"SELECT * FROM `t` WHERE `id` LIKE ?",
+		"INSERT INTO `t` (`a`, `b`) VALUES (?)",
+		"UPDATE `t` SET `a` = NOW()",
+		"SELECT * FROM `t",
+		"SELECT * FROM `t` WHERE `a` = ? extra",
+	} {
+		_, err := parse(query)
+		assert.Error(t, err, query)
+	}
+}
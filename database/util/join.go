@@ -28,5 +28,5 @@ type ColumSelector struct {
 
 // String returns the string representation of the ColumnSelector
 func (c *ColumSelector) String() string {
-	return fmt.Sprintf("`%s`.`%s`", c.Table, c.Column)
+	return fmt.Sprintf("%s.`%s`", QuoteIdentifier(c.Table), c.Column)
 }
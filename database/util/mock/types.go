@@ -1,6 +1,10 @@
 package mock
 
 import (
+	"context"
+	"database/sql"
+	"time"
+
 	"github.com/pakkasys/fluidapi/database/util"
 	"github.com/stretchr/testify/mock"
 )
@@ -19,6 +23,83 @@ func (m *MockDB) Prepare(query string) (util.Stmt, error) {
 	}
 }
 
+// PrepareContext calls Prepare, so the expectations of Prepare apply.
+func (m *MockDB) PrepareContext(
+	ctx context.Context,
+	query string,
+) (util.Stmt, error) {
+	return m.Prepare(query)
+}
+
+func (m *MockDB) Ping() error {
+	return m.Called().Error(0)
+}
+
+func (m *MockDB) SetConnMaxLifetime(d time.Duration) {
+	m.Called(d)
+}
+
+func (m *MockDB) SetConnMaxIdleTime(d time.Duration) {
+	m.Called(d)
+}
+
+func (m *MockDB) SetMaxOpenConns(n int) {
+	m.Called(n)
+}
+
+func (m *MockDB) SetMaxIdleConns(n int) {
+	m.Called(n)
+}
+
+func (m *MockDB) BeginTx(
+	ctx context.Context,
+	opts *sql.TxOptions,
+) (util.Tx, error) {
+	args := m.Called(ctx, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(util.Tx), args.Error(1)
+}
+
+func (m *MockDB) Exec(query string, args ...any) (util.Result, error) {
+	called := m.Called(query, args)
+	if called.Get(0) == nil {
+		return nil, called.Error(1)
+	}
+	return called.Get(0).(util.Result), called.Error(1)
+}
+
+func (m *MockDB) Query(query string, args ...any) (util.Rows, error) {
+	called := m.Called(query, args)
+	if called.Get(0) == nil {
+		return nil, called.Error(1)
+	}
+	return called.Get(0).(util.Rows), called.Error(1)
+}
+
+// ExecContext calls Exec, so the expectations of Exec apply.
+func (m *MockDB) ExecContext(
+	ctx context.Context,
+	query string,
+	args ...any,
+) (util.Result, error) {
+	return m.Exec(query, args...)
+}
+
+// QueryContext calls Query, so the expectations of Query apply.
+func (m *MockDB) QueryContext(
+	ctx context.Context,
+	query string,
+	args ...any,
+) (util.Rows, error) {
+	return m.Query(query, args...)
+}
+
+func (m *MockDB) Close() error {
+	return m.Called().Error(0)
+}
+
 // MockTx is a mock implementation of the Tx interface.
 type MockTx struct {
 	MockDB
@@ -62,6 +143,27 @@ func (m *MockStmt) Query(args ...any) (util.Rows, error) {
 	return argsCalled.Get(0).(util.Rows), argsCalled.Error(1)
 }
 
+// QueryRowContext calls QueryRow, so the expectations of QueryRow apply.
+func (m *MockStmt) QueryRowContext(ctx context.Context, args ...any) util.Row {
+	return m.QueryRow(args...)
+}
+
+// ExecContext calls Exec, so the expectations of Exec apply.
+func (m *MockStmt) ExecContext(
+	ctx context.Context,
+	args ...any,
+) (util.Result, error) {
+	return m.Exec(args...)
+}
+
+// QueryContext calls Query, so the expectations of Query apply.
+func (m *MockStmt) QueryContext(
+	ctx context.Context,
+	args ...any,
+) (util.Rows, error) {
+	return m.Query(args...)
+}
+
 // MockRows is a mock implementation of the Rows interface.
 type MockRows struct {
 	mock.Mock
@@ -12,4 +12,10 @@ const (
 	LESS_OR_EQUAL    Predicate = "<="
 	IN               Predicate = "IN"
 	NOT_IN           Predicate = "NOT IN"
+	LIKE             Predicate = "LIKE"
+	// OR matches if any of the groups of selectors in the value of the
+	// selector matches. The value is a [][]Selector and the selectors of a
+	// group are joined with AND. The table and the field of the selector are
+	// not used.
+	OR Predicate = "OR"
 )
@@ -19,7 +19,11 @@ func (c *Projection) String() string {
 	if c.Table == "" {
 		builder.WriteString(fmt.Sprintf("`%s`", c.Column))
 	} else {
-		builder.WriteString(fmt.Sprintf("`%s`.`%s`", c.Table, c.Column))
+		builder.WriteString(fmt.Sprintf(
+			"%s.`%s`",
+			QuoteIdentifier(c.Table),
+			c.Column,
+		))
 	}
 
 	if c.Alias != "" {
@@ -28,3 +32,32 @@ func (c *Projection) String() string {
 
 	return builder.String()
 }
+
+// ScanNamed scans the current row into the destinations of its columns by
+// column name, so that it reads the right values whatever the projections of
+// the query are. Columns without a destination are discarded and
+// destinations without a column are left unchanged. The rows must report
+// their columns, as sql.Rows does.
+//
+//   - rows: The rows positioned at the row to scan.
+//   - destinations: The destinations of the columns by column name.
+func ScanNamed(rows Rows, destinations map[string]any) error {
+	columnRows, ok := rows.(interface{ Columns() ([]string, error) })
+	if !ok {
+		return fmt.Errorf("rows do not report their columns")
+	}
+	columns, err := columnRows.Columns()
+	if err != nil {
+		return err
+	}
+
+	dest := make([]any, len(columns))
+	for i, column := range columns {
+		if destination, ok := destinations[column]; ok {
+			dest[i] = destination
+		} else {
+			dest[i] = new(any)
+		}
+	}
+	return rows.Scan(dest...)
+}
@@ -79,3 +79,56 @@ func TestProjectionString_EmptyFields(t *testing.T) {
 	expected := "``"
 	assert.Equal(t, expected, result)
 }
+
+// columnRows is a row with named columns.
+type columnRows struct {
+	columns []string
+	values  []any
+}
+
+func (r *columnRows) Columns() ([]string, error) { return r.columns, nil }
+func (r *columnRows) Next() bool                 { return false }
+func (r *columnRows) Close() error               { return nil }
+func (r *columnRows) Err() error                 { return nil }
+
+func (r *columnRows) Scan(dest ...any) error {
+	for i, value := range r.values {
+		switch d := dest[i].(type) {
+		case *int:
+			*d = value.(int)
+		case *string:
+			*d = value.(string)
+		case *any:
+			*d = value
+		}
+	}
+	return nil
+}
+
+// TestScanNamed tests scanning the columns by name.
+func TestScanNamed(t *testing.T) {
+	rows := &columnRows{
+		columns: []string{"age", "extra", "id"},
+		values:  []any{30, "x", 1},
+	}
+	var id, age int
+	name := "unchanged"
+
+	err := ScanNamed(rows, map[string]any{
+		"id":   &id,
+		"name": &name,
+		"age":  &age,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, id)
+	assert.Equal(t, 30, age)
+	assert.Equal(t, "unchanged", name)
+}
+
+// TestScanNamed_NoColumns tests rows that do not report their columns.
+func TestScanNamed_NoColumns(t *testing.T) {
+	err := ScanNamed(struct{ Rows }{}, map[string]any{})
+
+	assert.EqualError(t, err, "rows do not report their columns")
+}
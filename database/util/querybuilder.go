@@ -0,0 +1,398 @@
+package util
+
+import (
+	"fmt"
+	"strings"
+)
+
+// WhereClause renders the selectors as a WHERE clause joined with AND. It
+// returns an empty clause if there are no selectors.
+//
+//   - selectors: The selectors of the clause.
+func WhereClause(selectors []Selector) (string, []any) {
+	whereColumns, whereValues := ProcessSelectors(selectors)
+
+	var whereClause string
+	if len(whereColumns) > 0 {
+		whereClause = "WHERE " + strings.Join(whereColumns, " AND ")
+	}
+
+	return strings.Trim(whereClause, " "), whereValues
+}
+
+// JoinClause renders the joins as JOIN clauses.
+//
+//   - joins: The joins of the clause.
+func JoinClause(joins []Join) string {
+	var joinClause string
+	for _, join := range joins {
+		if joinClause != "" {
+			joinClause += " "
+		}
+		joinClause += fmt.Sprintf(
+			"%s JOIN %s ON %s = %s",
+			join.Type,
+			QuoteIdentifier(join.Table),
+			join.OnLeft.String(),
+			join.OnRight.String(),
+		)
+	}
+	return joinClause
+}
+
+// OrderClause renders the orders as an ORDER BY clause. It returns an empty
+// clause if there are no orders.
+//
+//   - orders: The orders of the clause.
+func OrderClause(orders []Order) string {
+	if len(orders) == 0 {
+		return ""
+	}
+
+	orderClause := "ORDER BY"
+	for _, order := range orders {
+		if order.Table == "" {
+			orderClause += fmt.Sprintf(
+				" `%s` %s,",
+				order.Field,
+				order.Direction,
+			)
+		} else {
+			orderClause += fmt.Sprintf(
+				" %s.`%s` %s,",
+				QuoteIdentifier(order.Table),
+				order.Field,
+				order.Direction,
+			)
+		}
+	}
+
+	return strings.TrimSuffix(orderClause, ",")
+}
+
+// ProjectionList renders the projections as a column list. It returns "*" if
+// there are no projections.
+//
+//   - projections: The projections of the list.
+func ProjectionList(projections []Projection) string {
+	if len(projections) == 0 {
+		return "*"
+	}
+
+	projectionStrings := make([]string, len(projections))
+	for i, projection := range projections {
+		projectionStrings[i] = projection.String()
+	}
+	return strings.Join(projectionStrings, ",")
+}
+
+// SelectBuilder builds SELECT statements.
+type SelectBuilder struct {
+	table       string
+	count       bool
+	projections []Projection
+	aggregates  []Aggregate
+	joins       []Join
+	selectors   []Selector
+	groupBy     []ColumSelector
+	orders      []Order
+	limit       *int
+	offset      int
+	forUpdate   bool
+}
+
+// NewSelect creates a builder for a SELECT statement.
+//
+//   - table: The table to select from.
+func NewSelect(table string) *SelectBuilder {
+	return &SelectBuilder{table: table}
+}
+
+// NewCount creates a builder for a SELECT COUNT(*) statement.
+//
+//   - table: The table to count from.
+func NewCount(table string) *SelectBuilder {
+	return &SelectBuilder{table: table, count: true}
+}
+
+// Columns adds projections to the statement.
+//
+//   - projections: The projections to add.
+func (b *SelectBuilder) Columns(projections ...Projection) *SelectBuilder {
+	b.projections = append(b.projections, projections...)
+	return b
+}
+
+// Aggregates adds aggregates to the statement. They are selected after the
+// projections.
+//
+//   - aggregates: The aggregates to add.
+func (b *SelectBuilder) Aggregates(aggregates ...Aggregate) *SelectBuilder {
+	b.aggregates = append(b.aggregates, aggregates...)
+	return b
+}
+
+// GroupBy adds grouping columns to the statement.
+//
+//   - columns: The columns to group by.
+func (b *SelectBuilder) GroupBy(columns ...ColumSelector) *SelectBuilder {
+	b.groupBy = append(b.groupBy, columns...)
+	return b
+}
+
+// Join adds joins to the statement.
+//
+//   - joins: The joins to add.
+func (b *SelectBuilder) Join(joins ...Join) *SelectBuilder {
+	b.joins = append(b.joins, joins...)
+	return b
+}
+
+// Where adds selectors to the statement.
+//
+//   - selectors: The selectors to add.
+func (b *SelectBuilder) Where(selectors ...Selector) *SelectBuilder {
+	b.selectors = append(b.selectors, selectors...)
+	return b
+}
+
+// OrderBy adds orders to the statement.
+//
+//   - orders: The orders to add.
+func (b *SelectBuilder) OrderBy(orders ...Order) *SelectBuilder {
+	b.orders = append(b.orders, orders...)
+	return b
+}
+
+// Limit sets the limit and the offset of the statement.
+//
+//   - limit: The maximum number of rows.
+//   - offset: The number of rows to skip.
+func (b *SelectBuilder) Limit(limit int, offset int) *SelectBuilder {
+	b.limit = &limit
+	b.offset = offset
+	return b
+}
+
+// ForUpdate locks the selected rows.
+func (b *SelectBuilder) ForUpdate() *SelectBuilder {
+	b.forUpdate = true
+	return b
+}
+
+// Build returns the statement and its arguments.
+func (b *SelectBuilder) Build() (string, []any) {
+	whereClause, whereValues := WhereClause(b.selectors)
+
+	if b.count {
+		query := strings.Trim(fmt.Sprintf(
+			"SELECT COUNT(*) FROM %s %s %s",
+			QuoteIdentifier(b.table),
+			JoinClause(b.joins),
+			whereClause,
+		), " ")
+		return query, whereValues
+	}
+
+	builder := strings.Builder{}
+	builder.WriteString("SELECT " + b.columnList())
+	builder.WriteString(" FROM " + QuoteIdentifier(b.table))
+	if len(b.joins) != 0 {
+		builder.WriteString(" " + JoinClause(b.joins))
+	}
+	if whereClause != "" {
+		builder.WriteString(" " + whereClause)
+	}
+	if len(b.groupBy) != 0 {
+		builder.WriteString(" " + GroupByClause(b.groupBy))
+	}
+	if len(b.orders) != 0 {
+		builder.WriteString(" " + OrderClause(b.orders))
+	}
+	if b.limit != nil {
+		builder.WriteString(
+			fmt.Sprintf(" LIMIT %d OFFSET %d", *b.limit, b.offset),
+		)
+	}
+	if b.forUpdate {
+		builder.WriteString(" FOR UPDATE")
+	}
+
+	return builder.String(), whereValues
+}
+
+// columnList renders the projections and the aggregates of the statement.
+func (b *SelectBuilder) columnList() string {
+	if len(b.aggregates) == 0 {
+		return ProjectionList(b.projections)
+	}
+
+	columns := make([]string, 0, len(b.projections)+len(b.aggregates))
+	for _, projection := range b.projections {
+		columns = append(columns, projection.String())
+	}
+	for _, aggregate := range b.aggregates {
+		columns = append(columns, aggregate.String())
+	}
+	return strings.Join(columns, ",")
+}
+
+// InsertBuilder builds INSERT statements.
+type InsertBuilder struct {
+	table   string
+	columns []string
+	rows    [][]any
+}
+
+// NewInsert creates a builder for an INSERT statement.
+//
+//   - table: The table to insert into.
+//   - columns: The columns of the inserted rows.
+func NewInsert(table string, columns ...string) *InsertBuilder {
+	return &InsertBuilder{table: table, columns: columns}
+}
+
+// Values adds a row to the statement.
+//
+//   - values: The values of the row in the order of the columns.
+func (b *InsertBuilder) Values(values ...any) *InsertBuilder {
+	b.rows = append(b.rows, values)
+	return b
+}
+
+// Build returns the statement and its arguments.
+func (b *InsertBuilder) Build() (string, []any) {
+	columns := make([]string, len(b.columns))
+	for i, column := range b.columns {
+		columns[i] = "`" + column + "`"
+	}
+
+	var values []any
+	rows := make([]string, len(b.rows))
+	for i, row := range b.rows {
+		rows[i] = "(" + strings.TrimSuffix(
+			strings.Repeat("?, ", len(row)),
+			", ",
+		) + ")"
+		values = append(values, row...)
+	}
+
+	return fmt.Sprintf(
+		"INSERT INTO %s (%s) VALUES %s",
+		QuoteIdentifier(b.table),
+		strings.Join(columns, ", "),
+		strings.Join(rows, ", "),
+	), values
+}
+
+// UpdateBuilder builds UPDATE statements.
+type UpdateBuilder struct {
+	table     string
+	columns   []string
+	values    []any
+	selectors []Selector
+}
+
+// NewUpdate creates a builder for an UPDATE statement.
+//
+//   - table: The table to update.
+func NewUpdate(table string) *UpdateBuilder {
+	return &UpdateBuilder{table: table}
+}
+
+// Set adds a column assignment to the statement.
+//
+//   - column: The column to set.
+//   - value: The new value of the column.
+func (b *UpdateBuilder) Set(column string, value any) *UpdateBuilder {
+	b.columns = append(b.columns, column)
+	b.values = append(b.values, value)
+	return b
+}
+
+// Where adds selectors to the statement.
+//
+//   - selectors: The selectors to add.
+func (b *UpdateBuilder) Where(selectors ...Selector) *UpdateBuilder {
+	b.selectors = append(b.selectors, selectors...)
+	return b
+}
+
+// Build returns the statement and its arguments.
+func (b *UpdateBuilder) Build() (string, []any) {
+	setParts := make([]string, len(b.columns))
+	for i, column := range b.columns {
+		setParts[i] = "`" + column + "` = ?"
+	}
+
+	whereClause, whereValues := WhereClause(b.selectors)
+	query := fmt.Sprintf(
+		"UPDATE %s SET %s",
+		QuoteIdentifier(b.table),
+		strings.Join(setParts, ", "),
+	)
+	if whereClause != "" {
+		query += " " + whereClause
+	}
+
+	values := append(append([]any{}, b.values...), whereValues...)
+	return query, values
+}
+
+// DeleteBuilder builds DELETE statements.
+type DeleteBuilder struct {
+	table     string
+	selectors []Selector
+	orders    []Order
+	limit     int
+}
+
+// NewDelete creates a builder for a DELETE statement.
+//
+//   - table: The table to delete from.
+func NewDelete(table string) *DeleteBuilder {
+	return &DeleteBuilder{table: table}
+}
+
+// Where adds selectors to the statement.
+//
+//   - selectors: The selectors to add.
+func (b *DeleteBuilder) Where(selectors ...Selector) *DeleteBuilder {
+	b.selectors = append(b.selectors, selectors...)
+	return b
+}
+
+// OrderBy adds orders to the statement.
+//
+//   - orders: The orders to add.
+func (b *DeleteBuilder) OrderBy(orders ...Order) *DeleteBuilder {
+	b.orders = append(b.orders, orders...)
+	return b
+}
+
+// Limit sets the maximum number of deleted rows.
+//
+//   - limit: The maximum number of rows.
+func (b *DeleteBuilder) Limit(limit int) *DeleteBuilder {
+	b.limit = limit
+	return b
+}
+
+// Build returns the statement and its arguments.
+func (b *DeleteBuilder) Build() (string, []any) {
+	whereClause, whereValues := WhereClause(b.selectors)
+
+	builder := strings.Builder{}
+	builder.WriteString("DELETE FROM " + QuoteIdentifier(b.table))
+	if whereClause != "" {
+		builder.WriteString(" " + whereClause)
+	}
+	if len(b.orders) != 0 {
+		builder.WriteString(" " + OrderClause(b.orders))
+	}
+	if b.limit > 0 {
+		builder.WriteString(fmt.Sprintf(" LIMIT %d", b.limit))
+	}
+
+	return builder.String(), whereValues
+}
@@ -0,0 +1,154 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestWhereClause tests rendering selectors as a WHERE clause.
+func TestWhereClause(t *testing.T) {
+	clause, values := WhereClause([]Selector{
+		{Table: "users", Field: "id", Predicate: IN, Value: []int{1, 2}},
+		{Table: "users", Field: "deleted_at", Predicate: EQUAL, Value: nil},
+	})
+
+	assert.Equal(
+		t,
+		"WHERE `users`.`id` IN (?,?) AND `users`.`deleted_at` IS NULL",
+		clause,
+	)
+	assert.Equal(t, []any{1, 2}, values)
+}
+
+// TestWhereClause_Empty tests that no selectors render an empty clause.
+func TestWhereClause_Empty(t *testing.T) {
+	clause, values := WhereClause(nil)
+
+	assert.Equal(t, "", clause)
+	assert.Nil(t, values)
+}
+
+// TestOrderClause tests rendering orders as an ORDER BY clause.
+func TestOrderClause(t *testing.T) {
+	assert.Equal(t, "", OrderClause(nil))
+	assert.Equal(
+		t,
+		"ORDER BY `users`.`name` ASC, `id` DESC",
+		OrderClause([]Order{
+			{Table: "users", Field: "name", Direction: OrderAsc},
+			{Field: "id", Direction: OrderDesc},
+		}),
+	)
+}
+
+// TestProjectionList tests rendering projections as a column list.
+func TestProjectionList(t *testing.T) {
+	assert.Equal(t, "*", ProjectionList(nil))
+	assert.Equal(
+		t,
+		"`users`.`id`,`name` AS `n`",
+		ProjectionList([]Projection{
+			{Table: "users", Column: "id"},
+			{Column: "name", Alias: "n"},
+		}),
+	)
+}
+
+// TestSelectBuilder tests building a SELECT statement with all clauses.
+func TestSelectBuilder(t *testing.T) {
+	query, values := NewSelect("users").
+		Columns(Projection{Table: "users", Column: "id"}).
+		Join(Join{
+			Type:    JoinTypeLeft,
+			Table:   "groups",
+			OnLeft:  ColumSelector{Table: "users", Column: "group_id"},
+			OnRight: ColumSelector{Table: "groups", Column: "id"},
+		}).
+		Where(Selector{
+			Table:     "groups",
+			Field:     "name",
+			Predicate: EQUAL,
+			Value:     "admins",
+		}).
+		OrderBy(Order{Table: "users", Field: "id", Direction: OrderAsc}).
+		Limit(10, 20).
+		ForUpdate().
+		Build()
+
+	assert.Equal(
+		t,
+		"SELECT `users`.`id` FROM `users` "+
+			"LEFT JOIN `groups` ON `users`.`group_id` = `groups`.`id` "+
+			"WHERE `groups`.`name` = ? ORDER BY `users`.`id` ASC "+
+			"LIMIT 10 OFFSET 20 FOR UPDATE",
+		query,
+	)
+	assert.Equal(t, []any{"admins"}, values)
+}
+
+// TestSelectBuilder_Minimal tests building a SELECT statement without
+// clauses.
+func TestSelectBuilder_Minimal(t *testing.T) {
+	query, values := NewSelect("tenant.users").Build()
+
+	assert.Equal(t, "SELECT * FROM `tenant`.`users`", query)
+	assert.Nil(t, values)
+}
+
+// TestCountBuilder tests building a SELECT COUNT(*) statement.
+func TestCountBuilder(t *testing.T) {
+	query, values := NewCount("users").
+		Where(Selector{Field: "age", Predicate: GREATER, Value: 18}).
+		Build()
+
+	assert.Equal(t, "SELECT COUNT(*) FROM `users`  WHERE `age` > ?", query)
+	assert.Equal(t, []any{18}, values)
+}
+
+// TestInsertBuilder tests building a multi-row INSERT statement.
+func TestInsertBuilder(t *testing.T) {
+	query, values := NewInsert("users", "id", "name").
+		Values(1, "a").
+		Values(2, "b").
+		Build()
+
+	assert.Equal(
+		t,
+		"INSERT INTO `users` (`id`, `name`) VALUES (?, ?), (?, ?)",
+		query,
+	)
+	assert.Equal(t, []any{1, "a", 2, "b"}, values)
+}
+
+// TestUpdateBuilder tests building an UPDATE statement.
+func TestUpdateBuilder(t *testing.T) {
+	query, values := NewUpdate("users").
+		Set("name", "b").
+		Set("age", 30).
+		Where(Selector{Field: "id", Predicate: EQUAL, Value: 1}).
+		Build()
+
+	assert.Equal(
+		t,
+		"UPDATE `users` SET `name` = ?, `age` = ? WHERE `id` = ?",
+		query,
+	)
+	assert.Equal(t, []any{"b", 30, 1}, values)
+}
+
+// TestDeleteBuilder tests building a DELETE statement.
+func TestDeleteBuilder(t *testing.T) {
+	query, values := NewDelete("users").
+		Where(Selector{Field: "active", Predicate: EQUAL, Value: false}).
+		OrderBy(Order{Field: "id", Direction: OrderAsc}).
+		Limit(100).
+		Build()
+
+	assert.Equal(
+		t,
+		"DELETE FROM `users` WHERE `active` = ? ORDER BY `id` ASC LIMIT 100",
+		query,
+	)
+	assert.Equal(t, []any{false}, values)
+}
@@ -0,0 +1,19 @@
+package util
+
+import "strings"
+
+// QuoteIdentifier quotes a table identifier. The identifier may be qualified
+// with a schema name, e.g. "tenant.users" is quoted as "`tenant`.`users`".
+//
+// Parameters:
+//   - identifier: The identifier to quote.
+//
+// Returns:
+//   - The quoted identifier.
+func QuoteIdentifier(identifier string) string {
+	parts := strings.Split(identifier, ".")
+	for i := range parts {
+		parts[i] = "`" + parts[i] + "`"
+	}
+	return strings.Join(parts, ".")
+}
@@ -0,0 +1,18 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestQuoteIdentifier_Table tests quoting of a plain table name.
+func TestQuoteIdentifier_Table(t *testing.T) {
+	assert.Equal(t, "`users`", QuoteIdentifier("users"))
+}
+
+// TestQuoteIdentifier_SchemaQualified tests quoting of a schema qualified
+// table name.
+func TestQuoteIdentifier_SchemaQualified(t *testing.T) {
+	assert.Equal(t, "`tenant`.`users`", QuoteIdentifier("tenant.users"))
+}
@@ -1,23 +1,23 @@
-package internal
+package util
 
 import (
 	"fmt"
 	"reflect"
 	"strings"
-
-	"github.com/pakkasys/fluidapi/database/util"
 )
 
 const (
-	predicateIn = "IN"
-	isNotClause = "IS NOT"
-	isClause    = "IS"
-	sqlNull     = "NULL"
+	predicateIn    = "IN"
+	predicateNotIn = "NOT IN"
+	predicateOr    = "OR"
+	isNotClause    = "IS NOT"
+	isClause       = "IS"
+	sqlNull        = "NULL"
 )
 
 // ProcessSelectors processes selectors into where clauses and corresponding
 // parameters array.
-func ProcessSelectors(selectors []util.Selector) ([]string, []any) {
+func ProcessSelectors(selectors []Selector) ([]string, []any) {
 	var whereColumns []string
 	var whereValues []any
 	for _, selector := range selectors {
@@ -28,36 +28,64 @@ func ProcessSelectors(selectors []util.Selector) ([]string, []any) {
 	return whereColumns, whereValues
 }
 
-func processSelector(selector util.Selector) (string, []any) {
-	if selector.Predicate == predicateIn {
+func processSelector(selector Selector) (string, []any) {
+	if selector.Predicate == predicateIn ||
+		selector.Predicate == predicateNotIn {
 		return processInSelector(selector)
 	}
+	if selector.Predicate == predicateOr {
+		return processOrSelector(selector)
+	}
 	return processDefaultSelector(selector)
 }
 
-func processInSelector(selector util.Selector) (string, []any) {
+// processOrSelector joins the groups of the selector with OR. A selector
+// without groups matches nothing.
+func processOrSelector(selector Selector) (string, []any) {
+	groups, _ := selector.Value.([][]Selector)
+	if len(groups) == 0 {
+		return "1 = 0", nil
+	}
+
+	clauses := make([]string, len(groups))
+	var values []any
+	for i, group := range groups {
+		columns, groupValues := ProcessSelectors(group)
+		if len(columns) == 0 {
+			clauses[i] = "1 = 1"
+		} else {
+			clauses[i] = "(" + strings.Join(columns, " AND ") + ")"
+		}
+		values = append(values, groupValues...)
+	}
+	return "(" + strings.Join(clauses, " OR ") + ")", values
+}
+
+// processInSelector expands the values of an IN or a NOT IN selector into
+// placeholders.
+func processInSelector(selector Selector) (string, []any) {
 	value := reflect.ValueOf(selector.Value)
 	if value.Kind() == reflect.Slice {
 		placeholders, values := createPlaceholdersAndValues(value)
 		column := fmt.Sprintf(
-			"`%s`.`%s` %s (%s)",
-			selector.Table,
+			"%s.`%s` %s (%s)",
+			QuoteIdentifier(selector.Table),
 			selector.Field,
-			predicateIn,
+			selector.Predicate,
 			placeholders,
 		)
 		return column, values
 	}
 	// If value is not a slice, treat as a single value
 	return fmt.Sprintf(
-		"`%s`.`%s` %s (?)",
-		selector.Table,
+		"%s.`%s` %s (?)",
+		QuoteIdentifier(selector.Table),
 		selector.Field,
-		predicateIn,
+		selector.Predicate,
 	), []any{selector.Value}
 }
 
-func processDefaultSelector(selector util.Selector) (string, []any) {
+func processDefaultSelector(selector Selector) (string, []any) {
 	if selector.Value == nil {
 		return processNullSelector(selector)
 	}
@@ -69,15 +97,15 @@ func processDefaultSelector(selector util.Selector) (string, []any) {
 		), []any{selector.Value}
 	} else {
 		return fmt.Sprintf(
-			"`%s`.`%s` %s ?",
-			selector.Table,
+			"%s.`%s` %s ?",
+			QuoteIdentifier(selector.Table),
 			selector.Field,
 			selector.Predicate,
 		), []any{selector.Value}
 	}
 }
 
-func processNullSelector(selector util.Selector) (string, []any) {
+func processNullSelector(selector Selector) (string, []any) {
 	if selector.Predicate == "=" {
 		return buildNullClause(selector, isClause), nil
 	}
@@ -87,13 +115,13 @@ func processNullSelector(selector util.Selector) (string, []any) {
 	return "", nil
 }
 
-func buildNullClause(selector util.Selector, clause string) string {
+func buildNullClause(selector Selector, clause string) string {
 	if selector.Table == "" {
 		return fmt.Sprintf("`%s` %s %s", selector.Field, clause, sqlNull)
 	}
 	return fmt.Sprintf(
-		"`%s`.`%s` %s %s",
-		selector.Table,
+		"%s.`%s` %s %s",
+		QuoteIdentifier(selector.Table),
 		selector.Field,
 		clause,
 		sqlNull,
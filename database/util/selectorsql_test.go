@@ -1,17 +1,16 @@
-package internal
+package util
 
 import (
 	"reflect"
 	"testing"
 
-	"github.com/pakkasys/fluidapi/database/util"
 	"github.com/stretchr/testify/assert"
 )
 
 // TestProcessSelectors_NoSelectors tests the case where no selectors are
 // provided.
 func TestProcessSelectors_NoSelectors(t *testing.T) {
-	selectors := []util.Selector{}
+	selectors := []Selector{}
 
 	whereColumns, whereValues := ProcessSelectors(selectors)
 
@@ -23,7 +22,7 @@ func TestProcessSelectors_NoSelectors(t *testing.T) {
 // TestProcessSelectors_SingleSelector tests the case where a single selector is
 // provided.
 func TestProcessSelectors_SingleSelector(t *testing.T) {
-	selectors := []util.Selector{
+	selectors := []Selector{
 		{Table: "user", Field: "id", Predicate: "=", Value: 1},
 	}
 
@@ -39,7 +38,7 @@ func TestProcessSelectors_SingleSelector(t *testing.T) {
 // TestProcessSelectors_MultipleSelectors tests the case where multiple
 // selectors are provided.
 func TestProcessSelectors_MultipleSelectors(t *testing.T) {
-	selectors := []util.Selector{
+	selectors := []Selector{
 		{Table: "user", Field: "id", Predicate: "=", Value: 1},
 		{Table: "user", Field: "age", Predicate: ">", Value: 18},
 	}
@@ -56,7 +55,7 @@ func TestProcessSelectors_MultipleSelectors(t *testing.T) {
 // TestProcessSelectors_WithInPredicate tests the case where a selector with
 // "IN" predicate is provided.
 func TestProcessSelectors_WithInPredicate(t *testing.T) {
-	selectors := []util.Selector{
+	selectors := []Selector{
 		{Table: "user", Field: "id", Predicate: "IN", Value: []int{1, 2, 3}},
 	}
 
@@ -72,7 +71,7 @@ func TestProcessSelectors_WithInPredicate(t *testing.T) {
 // TestProcessSelectors_WithNilValue tests the case where a selector with a nil
 // value is provided.
 func TestProcessSelectors_WithNilValue(t *testing.T) {
-	selectors := []util.Selector{
+	selectors := []Selector{
 		{Table: "user", Field: "deleted_at", Predicate: "=", Value: nil},
 	}
 
@@ -87,7 +86,7 @@ func TestProcessSelectors_WithNilValue(t *testing.T) {
 // TestProcessSelectors_WithDifferentPredicates tests the case where different
 // predicates are provided.
 func TestProcessSelectors_WithDifferentPredicates(t *testing.T) {
-	selectors := []util.Selector{
+	selectors := []Selector{
 		{Table: "user", Field: "name", Predicate: "LIKE", Value: "%Alice%"},
 		{Table: "user", Field: "age", Predicate: "<", Value: 30},
 	}
@@ -104,7 +103,7 @@ func TestProcessSelectors_WithDifferentPredicates(t *testing.T) {
 // TestProcessSelectors_EmptyTableField tests the case where a selector with an
 // empty table and field is provided.
 func TestProcessSelectors_EmptyTableField(t *testing.T) {
-	selectors := []util.Selector{
+	selectors := []Selector{
 		{Table: "", Field: "", Predicate: "=", Value: 1},
 	}
 
@@ -120,7 +119,7 @@ func TestProcessSelectors_EmptyTableField(t *testing.T) {
 // TestProcessSelector_WithInPredicate tests the processSelector function with
 // an "IN" predicate.
 func TestProcessSelector_WithInPredicate(t *testing.T) {
-	selector := util.Selector{
+	selector := Selector{
 		Table:     "user",
 		Field:     "id",
 		Predicate: "IN",
@@ -136,10 +135,26 @@ func TestProcessSelector_WithInPredicate(t *testing.T) {
 	assert.Equal(t, expectedValues, values)
 }
 
+// TestProcessSelector_WithNotInPredicate tests the processSelector function
+// with a "NOT IN" predicate.
+func TestProcessSelector_WithNotInPredicate(t *testing.T) {
+	selector := Selector{
+		Table:     "user",
+		Field:     "id",
+		Predicate: "NOT IN",
+		Value:     []int{1, 2},
+	}
+
+	column, values := processSelector(selector)
+
+	assert.Equal(t, "`user`.`id` NOT IN (?,?)", column)
+	assert.Equal(t, []any{1, 2}, values)
+}
+
 // TestProcessSelector_WithDefaultPredicate tests the processSelector function
 // with a default predicate.
 func TestProcessSelector_WithDefaultPredicate(t *testing.T) {
-	selector := util.Selector{
+	selector := Selector{
 		Table:     "user",
 		Field:     "name",
 		Predicate: "=",
@@ -155,10 +170,49 @@ func TestProcessSelector_WithDefaultPredicate(t *testing.T) {
 	assert.Equal(t, expectedValues, values)
 }
 
+// TestProcessSelector_WithOrPredicate tests joining the groups of a selector
+// with OR.
+func TestProcessSelector_WithOrPredicate(t *testing.T) {
+	selector := Selector{
+		Predicate: OR,
+		Value: [][]Selector{
+			{{Table: "user", Field: "age", Predicate: ">", Value: 18}},
+			{
+				{Table: "user", Field: "age", Predicate: "=", Value: 18},
+				{Table: "user", Field: "id", Predicate: ">", Value: 5},
+			},
+		},
+	}
+
+	whereColumn, whereValues := processSelector(selector)
+
+	assert.Equal(
+		t,
+		"((`user`.`age` > ?) OR (`user`.`age` = ? AND `user`.`id` > ?))",
+		whereColumn,
+	)
+	assert.Equal(t, []any{18, 18, 5}, whereValues)
+}
+
+// TestProcessOrSelector_EmptyGroups tests that a selector without groups
+// matches nothing and an empty group matches everything.
+func TestProcessOrSelector_EmptyGroups(t *testing.T) {
+	whereColumn, whereValues := processOrSelector(Selector{Predicate: OR})
+	assert.Equal(t, "1 = 0", whereColumn)
+	assert.Empty(t, whereValues)
+
+	whereColumn, whereValues = processOrSelector(Selector{
+		Predicate: OR,
+		Value:     [][]Selector{{}},
+	})
+	assert.Equal(t, "(1 = 1)", whereColumn)
+	assert.Empty(t, whereValues)
+}
+
 // TestProcessInSelector_WithSliceValue tests the processInSelector function
 // with a slice value.
 func TestProcessInSelector_WithSliceValue(t *testing.T) {
-	selector := util.Selector{
+	selector := Selector{
 		Table:     "user",
 		Field:     "id",
 		Predicate: "IN",
@@ -177,7 +231,7 @@ func TestProcessInSelector_WithSliceValue(t *testing.T) {
 // TestProcessInSelector_WithNonSliceValue tests the processInSelector function
 // with a non-slice value.
 func TestProcessInSelector_WithNonSliceValue(t *testing.T) {
-	selector := util.Selector{
+	selector := Selector{
 		Table:     "user",
 		Field:     "id",
 		Predicate: "IN",
@@ -196,7 +250,7 @@ func TestProcessInSelector_WithNonSliceValue(t *testing.T) {
 // TestProcessInSelector_EmptySlice tests the processInSelector function with an
 // empty slice value.
 func TestProcessInSelector_EmptySlice(t *testing.T) {
-	selector := util.Selector{
+	selector := Selector{
 		Table:     "user",
 		Field:     "id",
 		Predicate: "IN",
@@ -215,7 +269,7 @@ func TestProcessInSelector_EmptySlice(t *testing.T) {
 // TestProcessInSelector_WithStringSliceValue tests the processInSelector
 // function with a slice of strings.
 func TestProcessInSelector_WithStringSliceValue(t *testing.T) {
-	selector := util.Selector{
+	selector := Selector{
 		Table:     "user",
 		Field:     "name",
 		Predicate: "IN",
@@ -234,7 +288,7 @@ func TestProcessInSelector_WithStringSliceValue(t *testing.T) {
 // TestProcessInSelector_NilValue tests the processInSelector function with a
 // nil value.
 func TestProcessInSelector_NilValue(t *testing.T) {
-	selector := util.Selector{
+	selector := Selector{
 		Table:     "user",
 		Field:     "id",
 		Predicate: "IN",
@@ -253,7 +307,7 @@ func TestProcessInSelector_NilValue(t *testing.T) {
 // TestProcessDefaultSelector_WithValue tests the processDefaultSelector
 // function with a non-nil value.
 func TestProcessDefaultSelector_WithValue(t *testing.T) {
-	selector := util.Selector{
+	selector := Selector{
 		Table:     "user",
 		Field:     "name",
 		Predicate: "=",
@@ -272,7 +326,7 @@ func TestProcessDefaultSelector_WithValue(t *testing.T) {
 // TestProcessDefaultSelector_WithoutTable tests the processDefaultSelector
 // function without an empty field value.
 func TestProcessDefaultSelector_WithoutTable(t *testing.T) {
-	selector := util.Selector{
+	selector := Selector{
 		Table:     "",
 		Field:     "name",
 		Predicate: "=",
@@ -291,7 +345,7 @@ func TestProcessDefaultSelector_WithoutTable(t *testing.T) {
 // TestProcessDefaultSelector_NilValue tests the processDefaultSelector function
 // with a nil value and "=" predicate.
 func TestProcessDefaultSelector_NilValue(t *testing.T) {
-	selector := util.Selector{
+	selector := Selector{
 		Table:     "user",
 		Field:     "name",
 		Predicate: "=",
@@ -308,7 +362,7 @@ func TestProcessDefaultSelector_NilValue(t *testing.T) {
 // TestProcessNullSelector_NotEquals tests the processNullSelector function with
 // a "!=" predicate.
 func TestProcessNullSelector_NotEquals(t *testing.T) {
-	selector := util.Selector{
+	selector := Selector{
 		Table:     "user",
 		Field:     "name",
 		Predicate: "!=",
@@ -325,7 +379,7 @@ func TestProcessNullSelector_NotEquals(t *testing.T) {
 // TestProcessNullSelector_InvalidPredicate tests the processNullSelector
 // function with an unsupported predicate.
 func TestProcessNullSelector_InvalidPredicate(t *testing.T) {
-	selector := util.Selector{
+	selector := Selector{
 		Table:     "user",
 		Field:     "name",
 		Predicate: ">",
@@ -342,7 +396,7 @@ func TestProcessNullSelector_InvalidPredicate(t *testing.T) {
 // TestProcessNullSelector_WithoutTable tests the processNullSelector function
 // without a table.
 func TestProcessNullSelector_WithoutTable(t *testing.T) {
-	selector := util.Selector{
+	selector := Selector{
 		Table:     "",
 		Field:     "name",
 		Predicate: "=",
@@ -360,7 +414,7 @@ func TestProcessNullSelector_WithoutTable(t *testing.T) {
 // is provided.
 func TestBuildNullClause_WithTable(t *testing.T) {
 	// Test case where a table is provided
-	selector := util.Selector{
+	selector := Selector{
 		Table: "user",
 		Field: "name",
 	}
@@ -375,7 +429,7 @@ func TestBuildNullClause_WithTable(t *testing.T) {
 // table is provided.
 func TestBuildNullClause_WithoutTable(t *testing.T) {
 	// Test case where no table is provided
-	selector := util.Selector{
+	selector := Selector{
 		Table: "",
 		Field: "name",
 	}
@@ -390,7 +444,7 @@ func TestBuildNullClause_WithoutTable(t *testing.T) {
 // empty field.
 func TestBuildNullClause_EmptyField(t *testing.T) {
 	// Test case with an empty field
-	selector := util.Selector{
+	selector := Selector{
 		Table: "user",
 		Field: "",
 	}
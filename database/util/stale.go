@@ -0,0 +1,49 @@
+package util
+
+import (
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"syscall"
+)
+
+// staleConnectionMessages contains error message fragments that drivers use
+// when the server side of a connection has gone away.
+var staleConnectionMessages = []string{
+	"server has gone away",
+	"lost connection to mysql server",
+	"broken pipe",
+	"connection reset by peer",
+	"invalid connection",
+	"bad connection",
+}
+
+// IsStaleConnectionError reports whether the error indicates that the
+// database connection used for the query is no longer usable, e.g. after a
+// database failover or a server side connection timeout.
+//
+// Parameters:
+//   - err: The error to check.
+//
+// Returns:
+//   - True if the error is caused by a stale connection, false otherwise.
+func IsStaleConnectionError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, driver.ErrBadConn) ||
+		errors.Is(err, syscall.EPIPE) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+
+	message := strings.ToLower(err.Error())
+	for _, fragment := range staleConnectionMessages {
+		if strings.Contains(message, fragment) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,51 @@
+package util
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestIsStaleConnectionError_Nil tests that a nil error is not stale.
+func TestIsStaleConnectionError_Nil(t *testing.T) {
+	assert.False(t, IsStaleConnectionError(nil))
+}
+
+// TestIsStaleConnectionError_SentinelErrors tests the wrapped sentinel errors
+// that indicate a stale connection.
+func TestIsStaleConnectionError_SentinelErrors(t *testing.T) {
+	for _, err := range []error{
+		driver.ErrBadConn,
+		syscall.EPIPE,
+		syscall.ECONNRESET,
+		io.ErrUnexpectedEOF,
+		fmt.Errorf("query failed: %w", driver.ErrBadConn),
+	} {
+		assert.True(t, IsStaleConnectionError(err), err.Error())
+	}
+}
+
+// TestIsStaleConnectionError_Messages tests the driver error messages that
+// indicate a stale connection.
+func TestIsStaleConnectionError_Messages(t *testing.T) {
+	for _, err := range []error{
+		errors.New("Error 2006: MySQL server has gone away"),
+		errors.New("Error 2013: Lost connection to MySQL server during query"),
+		errors.New("write tcp 127.0.0.1:3306: write: broken pipe"),
+		errors.New("read tcp: connection reset by peer"),
+		errors.New("invalid connection"),
+	} {
+		assert.True(t, IsStaleConnectionError(err), err.Error())
+	}
+}
+
+// TestIsStaleConnectionError_OtherError tests that unrelated errors are not
+// considered stale.
+func TestIsStaleConnectionError_OtherError(t *testing.T) {
+	assert.False(t, IsStaleConnectionError(errors.New("duplicate entry")))
+}
@@ -6,9 +6,12 @@ import (
 	"time"
 )
 
-// Preparer is an interface that allows to prepare a query.
+// Preparer is an interface that allows to prepare a query. The statements
+// prepared with PrepareContext are bound to the context, so that their
+// queries are cancelled when the context is done.
 type Preparer interface {
 	Prepare(query string) (Stmt, error)
+	PrepareContext(ctx context.Context, query string) (Stmt, error)
 }
 
 // DB is an interface that wraps the basic methods used from sql.DB.
@@ -21,7 +24,9 @@ type DB interface {
 	SetMaxIdleConns(n int)
 	BeginTx(ctx context.Context, opts *sql.TxOptions) (Tx, error)
 	Exec(query string, args ...any) (Result, error)
+	ExecContext(ctx context.Context, query string, args ...any) (Result, error)
 	Query(query string, args ...any) (Rows, error)
+	QueryContext(ctx context.Context, query string, args ...any) (Rows, error)
 	Close() error
 }
 
@@ -36,8 +41,11 @@ type Tx interface {
 type Stmt interface {
 	Close() error
 	QueryRow(args ...any) Row
+	QueryRowContext(ctx context.Context, args ...any) Row
 	Exec(args ...any) (Result, error)
+	ExecContext(ctx context.Context, args ...any) (Result, error)
 	Query(args ...any) (Rows, error)
+	QueryContext(ctx context.Context, args ...any) (Rows, error)
 }
 
 // Rows is an interface that wraps the basic methods used from sql.Rows.
@@ -0,0 +1,131 @@
+// Package auth provides the authentication of requests with JWTs and the
+// identity of the authenticated client.
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/pakkasys/fluidapi/core/api"
+	"github.com/pakkasys/fluidapi/endpoint/middleware/inputlogic"
+	"github.com/pakkasys/fluidapi/endpoint/util"
+)
+
+var UnauthorizedError = api.NewError[any]("UNAUTHORIZED")
+
+// Errors are the errors of the package. The middlewares of the package map
+// them to their statuses, and RegisterErrors registers them in the default
+// error catalog for the endpoints returning them.
+var Errors = []inputlogic.ExpectedError{
+	{
+		ID:     UnauthorizedError.ID,
+		Status: http.StatusUnauthorized,
+	},
+	{
+		ID:     ForbiddenError.ID,
+		Status: http.StatusForbidden,
+	},
+	{
+		ID:     KeySetUnavailableError.ID,
+		Status: http.StatusServiceUnavailable,
+	},
+}
+
+var claimsDataKey = util.NewDataKey()
+
+// RegisterErrors registers Errors in the default error catalog, e.g. at
+// startup when the endpoints return UnauthorizedError from
+// runner.IdentitySelector.
+func RegisterErrors() {
+	inputlogic.RegisterErrors(Errors...)
+}
+
+// Claims are the claims of a JWT.
+type Claims map[string]any
+
+// Subject returns the "sub" claim.
+func (c Claims) Subject() string {
+	return c.String("sub")
+}
+
+// String returns a string claim, or an empty string if the claim is not a
+// string.
+//
+//   - name: The name of the claim.
+func (c Claims) String(name string) string {
+	value, _ := c[name].(string)
+	return value
+}
+
+// Strings returns a claim that is a string or a list of strings, such as the
+// "aud" claim.
+//
+//   - name: The name of the claim.
+func (c Claims) Strings(name string) []string {
+	switch value := c[name].(type) {
+	case string:
+		return []string{value}
+	case []string:
+		return value
+	case []any:
+		values := make([]string, 0, len(value))
+		for _, item := range value {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+	return nil
+}
+
+// Time returns a claim that is a numeric date, such as the "exp" claim.
+//
+//   - name: The name of the claim.
+func (c Claims) Time(name string) (time.Time, bool) {
+	var seconds float64
+	switch value := c[name].(type) {
+	case float64:
+		seconds = value
+	case int64:
+		seconds = float64(value)
+	case int:
+		seconds = float64(value)
+	case json.Number:
+		parsed, err := value.Float64()
+		if err != nil {
+			return time.Time{}, false
+		}
+		seconds = parsed
+	default:
+		return time.Time{}, false
+	}
+	return time.Unix(0, int64(seconds*float64(time.Second))).UTC(), true
+}
+
+// SetClaims stores the claims of the authenticated client in the custom
+// context.
+//
+//   - ctx: The context with the custom context set.
+//   - claims: The claims of the client.
+func SetClaims(ctx context.Context, claims Claims) {
+	util.SetContextValue(ctx, claimsDataKey, claims)
+}
+
+// GetClaims returns the claims of the authenticated client, or nil if the
+// request is not authenticated.
+//
+//   - ctx: The context of the request.
+func GetClaims(ctx context.Context) Claims {
+	return util.GetContextValue[Claims](ctx, claimsDataKey, nil)
+}
+
+// Subject returns the subject of the authenticated client, or an empty string
+// if the request is not authenticated.
+//
+//   - ctx: The context of the request.
+func Subject(ctx context.Context) string {
+	return GetClaims(ctx).Subject()
+}
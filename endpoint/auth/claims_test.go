@@ -0,0 +1,71 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/pakkasys/fluidapi/endpoint/middleware/inputlogic"
+	"github.com/pakkasys/fluidapi/endpoint/util"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestClaims tests the claim accessors.
+func TestClaims(t *testing.T) {
+	claims := Claims{
+		"sub":   "user",
+		"aud":   []any{"a", 1, "b"},
+		"role":  "admin",
+		"exp":   float64(1700000000),
+		"iat":   json.Number("1700000000.5"),
+		"other": true,
+	}
+
+	assert.Equal(t, "user", claims.Subject())
+	assert.Equal(t, []string{"a", "b"}, claims.Strings("aud"))
+	assert.Equal(t, []string{"admin"}, claims.Strings("role"))
+	assert.Nil(t, claims.Strings("other"))
+	assert.Empty(t, claims.String("other"))
+
+	expiresAt, ok := claims.Time("exp")
+	assert.True(t, ok)
+	assert.Equal(t, time.Unix(1700000000, 0).UTC(), expiresAt)
+	issuedAt, ok := claims.Time("iat")
+	assert.True(t, ok)
+	assert.Equal(t, time.Unix(1700000000, 5e8).UTC(), issuedAt)
+	_, ok = claims.Time("other")
+	assert.False(t, ok)
+}
+
+// TestSetClaims tests storing the claims in the custom context.
+func TestSetClaims(t *testing.T) {
+	ctx := util.NewContext(context.Background())
+	assert.Nil(t, GetClaims(ctx))
+	assert.Empty(t, Subject(ctx))
+
+	SetClaims(ctx, Claims{"sub": "user"})
+
+	assert.Equal(t, Claims{"sub": "user"}, GetClaims(ctx))
+	assert.Equal(t, "user", Subject(ctx))
+}
+
+// TestRegisterErrors tests registering the errors in the error catalog.
+func TestRegisterErrors(t *testing.T) {
+	_, ok := inputlogic.DefaultErrorCatalog.Get(UnauthorizedError.ID)
+	assert.False(t, ok)
+
+	RegisterErrors()
+	t.Cleanup(func() {
+		for _, expectedError := range Errors {
+			inputlogic.DefaultErrorCatalog.Unregister(expectedError.ID)
+		}
+	})
+	statusCode, _ := inputlogic.ErrorHandler{}.Handle(
+		UnauthorizedError.WithMessage("test"),
+		nil,
+	)
+
+	assert.Equal(t, http.StatusUnauthorized, statusCode)
+}
@@ -0,0 +1,214 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+	"time"
+
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+)
+
+// Algorithms are the supported JWT signing algorithms.
+var Algorithms = []string{
+	"HS256", "HS384", "HS512",
+	"RS256", "RS384", "RS512",
+	"ES256", "ES384", "ES512",
+}
+
+var algorithmHashes = map[string]crypto.Hash{
+	"256": crypto.SHA256,
+	"384": crypto.SHA384,
+	"512": crypto.SHA512,
+}
+
+type jwtHeader struct {
+	Algorithm string `json:"alg"`
+	KeyID     string `json:"kid"`
+}
+
+// Verifier verifies JWTs and returns their claims.
+type Verifier struct {
+	// Keys provides the keys verifying the signatures: []byte for HS, and
+	// *rsa.PublicKey and *ecdsa.PublicKey for RS and ES algorithms.
+	Keys KeyProvider
+	// Algorithms are the accepted signing algorithms. Defaults to Algorithms.
+	Algorithms []string
+	// Issuer is the required "iss" claim. Optional.
+	Issuer string
+	// Audience is the required value of the "aud" claim. Optional.
+	Audience string
+	// Leeway is the allowed clock skew when checking the time claims.
+	Leeway time.Duration
+	// Now returns the current time. Defaults to time.Now.
+	Now func() time.Time
+}
+
+// NewVerifier returns a new JWT verifier.
+//
+//   - keys: The provider of the verification keys.
+func NewVerifier(keys KeyProvider) *Verifier {
+	return &Verifier{Keys: keys}
+}
+
+// Verify verifies the signature and the time, issuer and audience claims of
+// the token and returns its claims. Invalid tokens are rejected with
+// UnauthorizedError.
+//
+//   - ctx: The context of the request.
+//   - token: The JWT.
+func (v *Verifier) Verify(ctx context.Context, token string) (Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, unauthorized("malformed token")
+	}
+
+	var header jwtHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, unauthorized("malformed header")
+	}
+	algorithms := v.Algorithms
+	if algorithms == nil {
+		algorithms = Algorithms
+	}
+	if !slices.Contains(algorithms, header.Algorithm) {
+		return nil, unauthorized("unsupported algorithm: " + header.Algorithm)
+	}
+
+	key, err := v.Keys.Key(ctx, header.KeyID, header.Algorithm)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, unauthorized("malformed signature")
+	}
+	err = verifySignature(
+		header.Algorithm,
+		key,
+		[]byte(parts[0]+"."+parts[1]),
+		signature,
+	)
+	if err != nil {
+		return nil, unauthorized(err.Error())
+	}
+
+	var claims Claims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, unauthorized("malformed claims")
+	}
+	if err := v.validateClaims(claims); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+func (v *Verifier) validateClaims(claims Claims) error {
+	now := time.Now()
+	if v.Now != nil {
+		now = v.Now()
+	}
+
+	if expiresAt, ok := claims.Time("exp"); ok &&
+		!now.Before(expiresAt.Add(v.Leeway)) {
+		return unauthorized("token expired")
+	}
+	if notBefore, ok := claims.Time("nbf"); ok &&
+		now.Add(v.Leeway).Before(notBefore) {
+		return unauthorized("token not valid yet")
+	}
+	if v.Issuer != "" && claims.String("iss") != v.Issuer {
+		return unauthorized("invalid issuer")
+	}
+	if v.Audience != "" &&
+		!slices.Contains(claims.Strings("aud"), v.Audience) {
+		return unauthorized("invalid audience")
+	}
+	return nil
+}
+
+func verifySignature(
+	algorithm string,
+	key any,
+	signed []byte,
+	signature []byte,
+) error {
+	if len(algorithm) != 5 {
+		return fmt.Errorf("unsupported algorithm: %s", algorithm)
+	}
+	hash := algorithmHashes[algorithm[2:]]
+	if !hash.Available() {
+		return fmt.Errorf("unavailable hash: %s", algorithm)
+	}
+
+	switch algorithm[:2] {
+	case "HS":
+		secret, ok := key.([]byte)
+		if !ok {
+			return fmt.Errorf("invalid key for algorithm: %s", algorithm)
+		}
+		mac := hmac.New(hash.New, secret)
+		mac.Write(signed)
+		if !hmac.Equal(mac.Sum(nil), signature) {
+			return fmt.Errorf("invalid signature")
+		}
+		return nil
+	case "RS":
+		publicKey, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("invalid key for algorithm: %s", algorithm)
+		}
+		if rsa.VerifyPKCS1v15(
+			publicKey,
+			hash,
+			digest(hash, signed),
+			signature,
+		) != nil {
+			return fmt.Errorf("invalid signature")
+		}
+		return nil
+	case "ES":
+		publicKey, ok := key.(*ecdsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("invalid key for algorithm: %s", algorithm)
+		}
+		size := (publicKey.Curve.Params().BitSize + 7) / 8
+		if len(signature) != 2*size {
+			return fmt.Errorf("invalid signature")
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		if !ecdsa.Verify(publicKey, digest(hash, signed), r, s) {
+			return fmt.Errorf("invalid signature")
+		}
+		return nil
+	}
+	return fmt.Errorf("unsupported algorithm: %s", algorithm)
+}
+
+func digest(hash crypto.Hash, data []byte) []byte {
+	h := hash.New()
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+func decodeSegment(segment string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+func unauthorized(message string) error {
+	return UnauthorizedError.WithMessage(message)
+}
@@ -0,0 +1,200 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testSecret = []byte("secret")
+
+// signToken signs a JWT with the algorithm and key for tests.
+func signToken(
+	t *testing.T,
+	algorithm string,
+	keyID string,
+	key any,
+	claims Claims,
+) string {
+	header, err := json.Marshal(map[string]string{
+		"alg": algorithm,
+		"kid": keyID,
+		"typ": "JWT",
+	})
+	assert.NoError(t, err)
+	payload, err := json.Marshal(claims)
+	assert.NoError(t, err)
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." +
+		base64.RawURLEncoding.EncodeToString(payload)
+
+	hash := algorithmHashes[algorithm[2:]]
+	var signature []byte
+	switch key := key.(type) {
+	case []byte:
+		mac := hmac.New(hash.New, key)
+		mac.Write([]byte(signed))
+		signature = mac.Sum(nil)
+	case *rsa.PrivateKey:
+		signature, err = rsa.SignPKCS1v15(
+			rand.Reader,
+			key,
+			hash,
+			digest(hash, []byte(signed)),
+		)
+		assert.NoError(t, err)
+	case *ecdsa.PrivateKey:
+		r, s, err := ecdsa.Sign(rand.Reader, key, digest(hash, []byte(signed)))
+		assert.NoError(t, err)
+		size := (key.Curve.Params().BitSize + 7) / 8
+		signature = make([]byte, 2*size)
+		r.FillBytes(signature[:size])
+		s.FillBytes(signature[size:])
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+// TestVerifier_Verify tests verifying tokens of the supported algorithms.
+func TestVerifier_Verify(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	verifier := NewVerifier(StaticKeys{
+		"hs": testSecret,
+		"rs": &rsaKey.PublicKey,
+		"es": &ecKey.PublicKey,
+	})
+
+	tests := []struct {
+		algorithm string
+		keyID     string
+		key       any
+	}{
+		{"HS256", "hs", testSecret},
+		{"HS512", "hs", testSecret},
+		{"RS256", "rs", rsaKey},
+		{"RS384", "rs", rsaKey},
+		{"ES256", "es", ecKey},
+	}
+	for _, test := range tests {
+		token := signToken(
+			t,
+			test.algorithm,
+			test.keyID,
+			test.key,
+			Claims{"sub": "user"},
+		)
+
+		claims, err := verifier.Verify(context.Background(), token)
+
+		assert.NoError(t, err, test.algorithm)
+		assert.Equal(t, "user", claims.Subject(), test.algorithm)
+	}
+}
+
+// TestVerifier_Verify_Invalid tests rejecting invalid tokens.
+func TestVerifier_Verify_Invalid(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	verifier := &Verifier{
+		Keys:       StaticKey(testSecret),
+		Algorithms: []string{"HS256"},
+		Issuer:     "issuer",
+		Audience:   "api",
+		Leeway:     time.Minute,
+		Now:        func() time.Time { return now },
+	}
+	valid := Claims{"iss": "issuer", "aud": []string{"other", "api"}}
+	with := func(name string, value any) Claims {
+		claims := Claims{}
+		for k, v := range valid {
+			claims[k] = v
+		}
+		claims[name] = value
+		return claims
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		message string
+	}{
+		{"malformed", "a.b", "malformed token"},
+		{"header", "!.b.c", "malformed header"},
+		{
+			"algorithm",
+			signToken(t, "HS384", "", testSecret, valid),
+			"unsupported algorithm: HS384",
+		},
+		{
+			"signature",
+			signToken(t, "HS256", "", []byte("other"), valid),
+			"invalid signature",
+		},
+		{
+			"expired",
+			signToken(t, "HS256", "", testSecret,
+				with("exp", now.Add(-time.Minute).Unix())),
+			"token expired",
+		},
+		{
+			"not before",
+			signToken(t, "HS256", "", testSecret,
+				with("nbf", now.Add(2*time.Minute).Unix())),
+			"token not valid yet",
+		},
+		{
+			"issuer",
+			signToken(t, "HS256", "", testSecret, with("iss", "other")),
+			"invalid issuer",
+		},
+		{
+			"audience",
+			signToken(t, "HS256", "", testSecret, with("aud", "other")),
+			"invalid audience",
+		},
+	}
+	for _, test := range tests {
+		claims, err := verifier.Verify(context.Background(), test.token)
+
+		assert.Nil(t, claims, test.name)
+		assert.Equal(
+			t,
+			UnauthorizedError.WithMessage(test.message),
+			err,
+			test.name,
+		)
+	}
+
+	claims, err := verifier.Verify(context.Background(), signToken(
+		t, "HS256", "", testSecret,
+		with("exp", now.Add(30*time.Second).Unix()),
+	))
+	assert.NoError(t, err)
+	assert.Equal(t, "issuer", claims.String("iss"))
+}
+
+// TestVerifier_Verify_KeyType tests that keys of other algorithms are not
+// accepted.
+func TestVerifier_Verify_KeyType(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	verifier := NewVerifier(StaticKey(&rsaKey.PublicKey))
+	token := signToken(t, "HS256", "", testSecret, Claims{})
+
+	_, err = verifier.Verify(context.Background(), token)
+
+	assert.Equal(
+		t,
+		UnauthorizedError.WithMessage("invalid key for algorithm: HS256"),
+		err,
+	)
+}
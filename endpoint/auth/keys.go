@@ -0,0 +1,307 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/pakkasys/fluidapi/core/api"
+)
+
+const (
+	defaultJWKSRefreshInterval    = time.Hour
+	defaultJWKSMinRefreshInterval = time.Minute
+	defaultJWKSTimeout            = 10 * time.Second
+)
+
+// KeySetUnavailableError is returned when the keys cannot be fetched and no
+// previously fetched key can be used.
+var KeySetUnavailableError = api.NewError[any]("KEY_SET_UNAVAILABLE")
+
+var defaultJWKSClient = &http.Client{Timeout: defaultJWKSTimeout}
+
+// KeyProvider provides the keys verifying JWT signatures.
+type KeyProvider interface {
+	// Key returns the key with the ID for the algorithm. Unknown keys are
+	// rejected with UnauthorizedError.
+	Key(ctx context.Context, keyID string, algorithm string) (any, error)
+}
+
+// StaticKeys are fixed keys by key ID. The key with an empty ID is used for
+// tokens whose key ID is not in the keys.
+type StaticKeys map[string]any
+
+// StaticKey returns static keys with a single key used for all tokens.
+//
+//   - key: The key, e.g. an HMAC secret or a public key.
+func StaticKey(key any) StaticKeys {
+	return StaticKeys{"": key}
+}
+
+// Key returns the key with the ID.
+func (k StaticKeys) Key(
+	ctx context.Context,
+	keyID string,
+	algorithm string,
+) (any, error) {
+	if key, ok := k[keyID]; ok {
+		return key, nil
+	}
+	if key, ok := k[""]; ok {
+		return key, nil
+	}
+	return nil, unauthorized("unknown key: " + keyID)
+}
+
+// JWKS provides the keys of a JSON Web Key Set URL. The keys are refreshed
+// periodically and when a token is signed with an unknown key. The keys are
+// fetched without blocking the requests served with the current keys, and
+// the last fetched keys are served while the key set cannot be fetched. It is
+// safe for concurrent use.
+type JWKS struct {
+	// URL of the key set.
+	URL string
+	// Client fetches the key set. Defaults to a client with a 10 second
+	// timeout.
+	Client *http.Client
+	// RefreshInterval is the maximum age of the keys. Defaults to an hour.
+	RefreshInterval time.Duration
+	// MinRefreshInterval is the minimum time between refreshes caused by
+	// unknown keys, and between the attempts after a failed refresh.
+	// Defaults to a minute.
+	MinRefreshInterval time.Duration
+
+	// refreshMu serializes the fetches of the key set
+	refreshMu   sync.Mutex
+	mu          sync.Mutex
+	keys        map[string]any
+	fetchedAt   time.Time
+	attemptedAt time.Time
+	now         func() time.Time
+}
+
+// NewJWKS returns a new key provider for a JSON Web Key Set URL.
+//
+//   - url: The URL of the key set.
+func NewJWKS(url string) *JWKS {
+	return &JWKS{URL: url}
+}
+
+// Key returns the key with the ID, fetching the key set if needed. If the key
+// set cannot be fetched, the last fetched keys are used. Without them the
+// request is rejected with KeySetUnavailableError.
+func (j *JWKS) Key(
+	ctx context.Context,
+	keyID string,
+	algorithm string,
+) (any, error) {
+	keys, refresh := j.cachedKeys(keyID)
+	if refresh {
+		j.refreshMu.Lock()
+		// The keys may have been refreshed while waiting for the lock.
+		keys, refresh = j.cachedKeys(keyID)
+		var err error
+		if refresh {
+			err = j.refresh(ctx)
+			keys, _ = j.cachedKeys(keyID)
+		}
+		j.refreshMu.Unlock()
+
+		if _, ok := keys[keyID]; err != nil && !ok {
+			return nil, KeySetUnavailableError.WithMessage(err.Error())
+		}
+	}
+
+	key, ok := keys[keyID]
+	if !ok {
+		return nil, unauthorized("unknown key: " + keyID)
+	}
+	return key, nil
+}
+
+// Refresh fetches the key set.
+//
+//   - ctx: The context of the request.
+func (j *JWKS) Refresh(ctx context.Context) error {
+	j.refreshMu.Lock()
+	defer j.refreshMu.Unlock()
+
+	return j.refresh(ctx)
+}
+
+// cachedKeys returns the current keys and whether they should be refreshed
+// for the key ID. The keys are not refreshed within MinRefreshInterval of the
+// previous attempt.
+func (j *JWKS) cachedKeys(keyID string) (map[string]any, bool) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	now := j.currentTime()
+	if now.Sub(j.attemptedAt) < j.minRefreshInterval() {
+		return j.keys, false
+	}
+	if j.keys == nil || now.Sub(j.fetchedAt) >= j.refreshInterval() {
+		return j.keys, true
+	}
+	_, ok := j.keys[keyID]
+	return j.keys, !ok
+}
+
+// refresh fetches the key set. The caller must hold refreshMu.
+func (j *JWKS) refresh(ctx context.Context) error {
+	j.mu.Lock()
+	j.attemptedAt = j.currentTime()
+	j.mu.Unlock()
+
+	keys, err := j.fetch(ctx)
+	if err != nil {
+		return err
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.keys = keys
+	j.fetchedAt = j.currentTime()
+	return nil
+}
+
+func (j *JWKS) fetch(ctx context.Context) (map[string]any, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, j.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	client := j.Client
+	if client == nil {
+		client = defaultJWKSClient
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("fetch JWKS: %w", err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch JWKS: status %d", response.StatusCode)
+	}
+	data, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, fmt.Errorf("fetch JWKS: %w", err)
+	}
+
+	return ParseJWKS(data)
+}
+
+func (j *JWKS) currentTime() time.Time {
+	if j.now != nil {
+		return j.now()
+	}
+	return time.Now()
+}
+
+func (j *JWKS) refreshInterval() time.Duration {
+	if j.RefreshInterval > 0 {
+		return j.RefreshInterval
+	}
+	return defaultJWKSRefreshInterval
+}
+
+func (j *JWKS) minRefreshInterval() time.Duration {
+	if j.MinRefreshInterval > 0 {
+		return j.MinRefreshInterval
+	}
+	return defaultJWKSMinRefreshInterval
+}
+
+type jsonWebKey struct {
+	KeyType string `json:"kty"`
+	KeyID   string `json:"kid"`
+	Use     string `json:"use"`
+	Curve   string `json:"crv"`
+	N       string `json:"n"`
+	E       string `json:"e"`
+	X       string `json:"x"`
+	Y       string `json:"y"`
+	K       string `json:"k"`
+}
+
+var jwkCurves = map[string]elliptic.Curve{
+	"P-256": elliptic.P256(),
+	"P-384": elliptic.P384(),
+	"P-521": elliptic.P521(),
+}
+
+// ParseJWKS parses the signing keys of a JSON Web Key Set by key ID. Keys of
+// unsupported types are skipped.
+//
+//   - data: The key set in JSON.
+func ParseJWKS(data []byte) (map[string]any, error) {
+	var keySet struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &keySet); err != nil {
+		return nil, fmt.Errorf("parse JWKS: %w", err)
+	}
+
+	keys := map[string]any{}
+	for _, jwk := range keySet.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.publicKey()
+		if err != nil {
+			return nil, fmt.Errorf("parse JWK %s: %w", jwk.KeyID, err)
+		}
+		if key != nil {
+			keys[jwk.KeyID] = key
+		}
+	}
+	return keys, nil
+}
+
+func (jwk *jsonWebKey) publicKey() (any, error) {
+	switch jwk.KeyType {
+	case "RSA":
+		n, err := decodeBigInt(jwk.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(jwk.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		curve, ok := jwkCurves[jwk.Curve]
+		if !ok {
+			return nil, fmt.Errorf("unsupported curve: %s", jwk.Curve)
+		}
+		x, err := decodeBigInt(jwk.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(jwk.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	case "oct":
+		return base64.RawURLEncoding.DecodeString(jwk.K)
+	}
+	return nil, nil
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(data), nil
+}
@@ -0,0 +1,187 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func encodeBigInt(value *big.Int) string {
+	return base64.RawURLEncoding.EncodeToString(value.Bytes())
+}
+
+// TestStaticKeys tests looking up static keys.
+func TestStaticKeys(t *testing.T) {
+	keys := StaticKeys{"a": "key a"}
+
+	key, err := keys.Key(context.Background(), "a", "HS256")
+	assert.NoError(t, err)
+	assert.Equal(t, "key a", key)
+
+	_, err = keys.Key(context.Background(), "b", "HS256")
+	assert.Equal(t, UnauthorizedError.WithMessage("unknown key: b"), err)
+
+	key, err = StaticKey("key").Key(context.Background(), "b", "HS256")
+	assert.NoError(t, err)
+	assert.Equal(t, "key", key)
+}
+
+// TestParseJWKS tests parsing the supported key types.
+func TestParseJWKS(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	assert.NoError(t, err)
+	data := fmt.Sprintf(`{"keys": [
+		{"kty": "RSA", "kid": "rs", "n": %q, "e": %q},
+		{"kty": "EC", "kid": "es", "crv": "P-384", "x": %q, "y": %q},
+		{"kty": "oct", "kid": "hs", "k": %q},
+		{"kty": "RSA", "kid": "enc", "use": "enc", "n": "AQ", "e": "AQ"},
+		{"kty": "OKP", "kid": "ed"}
+	]}`,
+		encodeBigInt(rsaKey.N),
+		encodeBigInt(big.NewInt(int64(rsaKey.E))),
+		encodeBigInt(ecKey.X),
+		encodeBigInt(ecKey.Y),
+		base64.RawURLEncoding.EncodeToString(testSecret),
+	)
+
+	keys, err := ParseJWKS([]byte(data))
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"rs": &rsaKey.PublicKey,
+		"es": &ecKey.PublicKey,
+		"hs": testSecret,
+	}, keys)
+
+	_, err = ParseJWKS([]byte(`{"keys": [{"kty": "EC", "crv": "P-1"}]}`))
+	assert.Error(t, err)
+	_, err = ParseJWKS([]byte(`{`))
+	assert.Error(t, err)
+}
+
+// TestJWKS_Key tests fetching and refreshing the key set.
+func TestJWKS_Key(t *testing.T) {
+	var fetches atomic.Int32
+	keyIDs := []string{"a"}
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			fetches.Add(1)
+			fmt.Fprint(w, `{"keys": [`)
+			for i, keyID := range keyIDs {
+				if i > 0 {
+					fmt.Fprint(w, ",")
+				}
+				fmt.Fprintf(w, `{"kty": "oct", "kid": %q, "k": "AQ"}`, keyID)
+			}
+			fmt.Fprint(w, `]}`)
+		},
+	))
+	defer server.Close()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	jwks := NewJWKS(server.URL)
+	jwks.now = func() time.Time { return now }
+
+	key, err := jwks.Key(context.Background(), "a", "HS256")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{1}, key)
+	assert.Equal(t, int32(1), fetches.Load())
+
+	// Unknown keys do not cause refreshes within the minimum interval.
+	keyIDs = []string{"a", "b"}
+	_, err = jwks.Key(context.Background(), "b", "HS256")
+	assert.Equal(t, UnauthorizedError.WithMessage("unknown key: b"), err)
+	assert.Equal(t, int32(1), fetches.Load())
+
+	now = now.Add(time.Minute)
+	_, err = jwks.Key(context.Background(), "b", "HS256")
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), fetches.Load())
+
+	now = now.Add(time.Hour)
+	_, err = jwks.Key(context.Background(), "a", "HS256")
+	assert.NoError(t, err)
+	assert.Equal(t, int32(3), fetches.Load())
+}
+
+// TestJWKS_Refresh_Error tests the errors of fetching the key set.
+func TestJWKS_Refresh_Error(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	err := NewJWKS(server.URL).Refresh(context.Background())
+
+	assert.EqualError(t, err, "fetch JWKS: status 404")
+}
+
+// TestJWKS_Key_RefreshError tests serving the fetched keys while the key set
+// cannot be fetched, and backing off between the failed refreshes.
+func TestJWKS_Key_RefreshError(t *testing.T) {
+	var fetches atomic.Int32
+	var failing atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			fetches.Add(1)
+			if failing.Load() {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			fmt.Fprint(w, `{"keys": [{"kty": "oct", "kid": "a", "k": "AQ"}]}`)
+		},
+	))
+	defer server.Close()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	jwks := NewJWKS(server.URL)
+	jwks.now = func() time.Time { return now }
+
+	_, err := jwks.Key(context.Background(), "a", "HS256")
+	assert.NoError(t, err)
+
+	failing.Store(true)
+	now = now.Add(2 * time.Hour)
+	key, err := jwks.Key(context.Background(), "a", "HS256")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{1}, key)
+	assert.Equal(t, int32(2), fetches.Load())
+
+	_, err = jwks.Key(context.Background(), "a", "HS256")
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), fetches.Load())
+
+	now = now.Add(time.Minute)
+	_, err = jwks.Key(context.Background(), "b", "HS256")
+	assert.Equal(
+		t,
+		KeySetUnavailableError.WithMessage("fetch JWKS: status 500"),
+		err,
+	)
+	assert.Equal(t, int32(3), fetches.Load())
+}
+
+// TestJWKS_Key_Unavailable tests rejecting the requests before the key set
+// has been fetched.
+func TestJWKS_Key_Unavailable(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	_, err := NewJWKS(server.URL).Key(context.Background(), "a", "HS256")
+
+	assert.Equal(
+		t,
+		KeySetUnavailableError.WithMessage("fetch JWKS: status 404"),
+		err,
+	)
+}
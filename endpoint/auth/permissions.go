@@ -0,0 +1,110 @@
+package auth
+
+import (
+	"context"
+	"slices"
+
+	"github.com/pakkasys/fluidapi/core/api"
+)
+
+// AllPermissions is the permission granting all permissions.
+const AllPermissions = "*"
+
+var ForbiddenError = api.NewError[any]("FORBIDDEN")
+
+type requiredPermissionsKey struct{}
+
+// Authorizer checks whether the authenticated client has permissions.
+type Authorizer interface {
+	// Authorize returns an error if the client does not have all of the
+	// permissions.
+	Authorize(ctx context.Context, permissions []string) error
+}
+
+// RBAC is a role-based Authorizer granting permissions by the roles and
+// permissions claims of the client.
+type RBAC struct {
+	// RolePermissions are the permissions granted by each role.
+	RolePermissions map[string][]string
+	// RolesClaim is the claim listing the roles of the client. Defaults to
+	// "roles".
+	RolesClaim string
+	// PermissionsClaim is the claim listing the permissions granted to the
+	// client directly. Defaults to "permissions".
+	PermissionsClaim string
+}
+
+// NewRBAC returns a new role-based authorizer.
+//
+//   - rolePermissions: The permissions granted by each role.
+func NewRBAC(rolePermissions map[string][]string) *RBAC {
+	return &RBAC{RolePermissions: rolePermissions}
+}
+
+// Authorize checks that the roles and permissions of the client grant all of
+// the permissions. Unauthenticated clients are rejected with
+// UnauthorizedError and clients without a permission with ForbiddenError.
+//
+//   - ctx: The context of the request.
+//   - permissions: The required permissions.
+func (a *RBAC) Authorize(ctx context.Context, permissions []string) error {
+	claims := GetClaims(ctx)
+	if claims == nil {
+		return UnauthorizedError.WithMessage("missing identity")
+	}
+
+	granted := a.GrantedPermissions(claims)
+	if slices.Contains(granted, AllPermissions) {
+		return nil
+	}
+	for _, permission := range permissions {
+		if !slices.Contains(granted, permission) {
+			return ForbiddenError.WithMessage(
+				"missing permission: " + permission,
+			)
+		}
+	}
+	return nil
+}
+
+// GrantedPermissions returns the permissions granted to the client by its
+// roles and permissions claims.
+//
+//   - claims: The claims of the client.
+func (a *RBAC) GrantedPermissions(claims Claims) []string {
+	rolesClaim := a.RolesClaim
+	if rolesClaim == "" {
+		rolesClaim = "roles"
+	}
+	permissionsClaim := a.PermissionsClaim
+	if permissionsClaim == "" {
+		permissionsClaim = "permissions"
+	}
+
+	granted := claims.Strings(permissionsClaim)
+	for _, role := range claims.Strings(rolesClaim) {
+		granted = append(granted, a.RolePermissions[role]...)
+	}
+	return granted
+}
+
+// WithRequiredPermissions returns a copy of the context with the permissions
+// required by the endpoint.
+//
+//   - ctx: The context of the request.
+//   - permissions: The required permissions.
+func WithRequiredPermissions(
+	ctx context.Context,
+	permissions []string,
+) context.Context {
+	return context.WithValue(ctx, requiredPermissionsKey{}, permissions)
+}
+
+// RequiredPermissions returns the permissions required by the endpoint, or
+// nil if there are none.
+//
+//   - ctx: The context of the request.
+func RequiredPermissions(ctx context.Context) []string {
+	permissions, _ := ctx.Value(requiredPermissionsKey{}).([]string)
+	return permissions
+}
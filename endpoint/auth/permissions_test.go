@@ -0,0 +1,68 @@
+package auth
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pakkasys/fluidapi/endpoint/util"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestRBAC_Authorize tests granting permissions by roles and permissions.
+func TestRBAC_Authorize(t *testing.T) {
+	rbac := NewRBAC(map[string][]string{
+		"reader": {"orders:read"},
+		"admin":  {AllPermissions},
+	})
+	ctx := util.NewContext(context.Background())
+
+	err := rbac.Authorize(ctx, []string{"orders:read"})
+	assert.Equal(t, UnauthorizedError.WithMessage("missing identity"), err)
+
+	SetClaims(ctx, Claims{
+		"roles":       []any{"reader"},
+		"permissions": []any{"orders:export"},
+	})
+	assert.NoError(
+		t,
+		rbac.Authorize(ctx, []string{"orders:read", "orders:export"}),
+	)
+	assert.Equal(
+		t,
+		ForbiddenError.WithMessage("missing permission: orders:write"),
+		rbac.Authorize(ctx, []string{"orders:read", "orders:write"}),
+	)
+
+	SetClaims(ctx, Claims{"roles": "admin"})
+	assert.NoError(t, rbac.Authorize(ctx, []string{"orders:write"}))
+}
+
+// TestRBAC_GrantedPermissions tests the custom claims of the authorizer.
+func TestRBAC_GrantedPermissions(t *testing.T) {
+	rbac := &RBAC{
+		RolePermissions:  map[string][]string{"reader": {"orders:read"}},
+		RolesClaim:       "groups",
+		PermissionsClaim: "scope",
+	}
+
+	granted := rbac.GrantedPermissions(Claims{
+		"groups": []string{"reader", "unknown"},
+		"scope":  "orders:export",
+		"roles":  "admin",
+	})
+
+	assert.Equal(t, []string{"orders:export", "orders:read"}, granted)
+}
+
+// TestRequiredPermissions tests storing the required permissions in the
+// context.
+func TestRequiredPermissions(t *testing.T) {
+	assert.Nil(t, RequiredPermissions(context.Background()))
+
+	ctx := WithRequiredPermissions(
+		context.Background(),
+		[]string{"orders:read"},
+	)
+
+	assert.Equal(t, []string{"orders:read"}, RequiredPermissions(ctx))
+}
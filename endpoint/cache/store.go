@@ -0,0 +1,130 @@
+// Package cache provides the stores of the response cache middleware.
+package cache
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Entry is a cached response.
+type Entry struct {
+	// StatusCode is the status code of the response.
+	StatusCode int
+	// Header is the header of the response.
+	Header http.Header
+	// Body is the body of the response.
+	Body []byte
+	// ETag is the entity tag of the response.
+	ETag string
+	// LastModified is the time the response was last modified.
+	LastModified time.Time
+	// StoredAt is the time the response was stored.
+	StoredAt time.Time
+}
+
+// Store stores the cached responses, e.g. in memory or in Redis.
+type Store interface {
+	// Get returns the entry of the key, or nil if there is no entry or it has
+	// expired.
+	Get(ctx context.Context, key string) (*Entry, error)
+	// Set stores the entry of the key for the duration.
+	Set(ctx context.Context, key string, entry *Entry, ttl time.Duration) error
+	// Delete deletes the entry of the key.
+	Delete(ctx context.Context, key string) error
+}
+
+type memoryEntry struct {
+	entry     *Entry
+	expiresAt time.Time
+}
+
+// MemoryStore is an in-memory cache store for single instance services and
+// tests. It is safe for concurrent use.
+type MemoryStore struct {
+	mu         sync.Mutex
+	entries    map[string]memoryEntry
+	maxEntries int
+	now        func() time.Time
+}
+
+// NewMemoryStore creates a new in-memory cache store.
+//
+//   - maxEntries: The maximum number of entries. When the store is full, the
+//     expired entries are removed and, if the store is still full, the entry
+//     expiring first. Zero means no limit.
+func NewMemoryStore(maxEntries int) *MemoryStore {
+	return &MemoryStore{
+		entries:    map[string]memoryEntry{},
+		maxEntries: maxEntries,
+		now:        time.Now,
+	}
+}
+
+// Get returns the entry of the key.
+func (s *MemoryStore) Get(ctx context.Context, key string) (*Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[key]
+	if !ok {
+		return nil, nil
+	}
+	if !s.now().Before(entry.expiresAt) {
+		delete(s.entries, key)
+		return nil, nil
+	}
+	return entry.entry, nil
+}
+
+// Set stores the entry of the key.
+func (s *MemoryStore) Set(
+	ctx context.Context,
+	key string,
+	entry *Entry,
+	ttl time.Duration,
+) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.entries[key]; !ok && s.maxEntries > 0 &&
+		len(s.entries) >= s.maxEntries {
+		s.evict()
+	}
+	s.entries[key] = memoryEntry{
+		entry:     entry,
+		expiresAt: s.now().Add(ttl),
+	}
+	return nil
+}
+
+// Delete deletes the entry of the key.
+func (s *MemoryStore) Delete(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, key)
+	return nil
+}
+
+// evict removes the expired entries, or the entry expiring first if none has
+// expired.
+func (s *MemoryStore) evict() {
+	now := s.now()
+	var firstKey string
+	var first time.Time
+	for key, entry := range s.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(s.entries, key)
+			continue
+		}
+		if firstKey == "" || entry.expiresAt.Before(first) {
+			firstKey = key
+			first = entry.expiresAt
+		}
+	}
+	if len(s.entries) >= s.maxEntries {
+		delete(s.entries, firstKey)
+	}
+}
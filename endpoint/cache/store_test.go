@@ -0,0 +1,61 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestMemoryStore tests storing, expiring and deleting entries.
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore(0)
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	store.now = func() time.Time { return now }
+
+	a := &Entry{StatusCode: 200, Body: []byte("a")}
+	b := &Entry{StatusCode: 200, Body: []byte("b")}
+	assert.NoError(t, store.Set(ctx, "a", a, time.Minute))
+	assert.NoError(t, store.Set(ctx, "b", b, time.Hour))
+
+	entry, err := store.Get(ctx, "a")
+	assert.NoError(t, err)
+	assert.Same(t, a, entry)
+
+	now = now.Add(time.Minute)
+	entry, err = store.Get(ctx, "a")
+	assert.NoError(t, err)
+	assert.Nil(t, entry)
+
+	assert.NoError(t, store.Delete(ctx, "b"))
+	entry, err = store.Get(ctx, "b")
+	assert.NoError(t, err)
+	assert.Nil(t, entry)
+}
+
+// TestMemoryStore_MaxEntries tests evicting entries from a full store.
+func TestMemoryStore_MaxEntries(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore(2)
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	store.now = func() time.Time { return now }
+
+	assert.NoError(t, store.Set(ctx, "a", &Entry{}, time.Hour))
+	assert.NoError(t, store.Set(ctx, "b", &Entry{}, time.Minute))
+	// Replacing an entry does not evict.
+	assert.NoError(t, store.Set(ctx, "a", &Entry{}, time.Hour))
+	assert.Len(t, store.entries, 2)
+
+	// The entry expiring first is evicted.
+	assert.NoError(t, store.Set(ctx, "c", &Entry{}, time.Hour))
+	assert.Len(t, store.entries, 2)
+	assert.NotContains(t, store.entries, "b")
+
+	// Expired entries are evicted.
+	now = now.Add(2 * time.Hour)
+	assert.NoError(t, store.Set(ctx, "d", &Entry{}, time.Hour))
+	assert.Len(t, store.entries, 1)
+	assert.Contains(t, store.entries, "d")
+}
@@ -4,4 +4,6 @@ package dbfield
 type DBField struct {
 	Table  string
 	Column string
+	// Deprecated marks the API field as deprecated
+	Deprecated bool
 }
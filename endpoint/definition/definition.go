@@ -1,42 +1,147 @@
 package definition
 
 import (
+	"fmt"
+	"net/http"
+	"slices"
+
 	"github.com/pakkasys/fluidapi/core/api"
+	"github.com/pakkasys/fluidapi/endpoint/auth"
 	"github.com/pakkasys/fluidapi/endpoint/middleware"
 )
 
 type EndpointDefinition struct {
+	// Name identifies the endpoint, e.g. "users.get" in the links of the
+	// LinkBuilder. Optional.
+	Name            string
 	URL             string
 	Method          string
 	MiddlewareStack middleware.Stack
+	// Permissions required to call the endpoint. They are checked by the
+	// authorization middleware, which must be in the stack.
+	Permissions []string
+	// Version of the endpoint, e.g. "v1". Optional.
+	Version string
+	// Deprecation marks the endpoint deprecated. The Deprecation and Sunset
+	// headers are set on its responses. Optional.
+	Deprecation *Deprecation
+	// DisableAutoHead disables answering HEAD requests by running the GET
+	// endpoint without the response body.
+	DisableAutoHead bool
+	// DisableAutoOptions disables answering OPTIONS requests to the URL with
+	// the allowed methods.
+	DisableAutoOptions bool
+	// MiddlewareOverrides replace the middlewares of the stack with the same
+	// IDs in place, e.g. a concurrency limit of an upload endpoint replacing
+	// the limit of its group. They are resolved when the stack is converted
+	// to the middlewares of the API endpoint. Optional.
+	MiddlewareOverrides []api.MiddlewareWrapper
 }
 
 // EndpointDefinitionsToAPIEndpoints converts a list of endpoint definitions to
-// a list of API endpoints
+// a list of API endpoints. An endpoint with Permissions must have the
+// authorization middleware in its resolved stack, since the permissions are
+// only checked by it. The CORS middleware of the stack also wraps the
+// automatic OPTIONS handler of the URL, so that preflight requests get the
+// CORS headers.
 //
 //   - endpointDefinitions: A list of endpoint definitions to convert
 func EndpointDefinitionsToAPIEndpoints(
 	endpointDefinitions []EndpointDefinition,
-) []api.Endpoint {
+) ([]api.Endpoint, error) {
 	endpoints := []api.Endpoint{}
 
 	for _, endpointDefinition := range endpointDefinitions {
+		stack, err := endpointDefinition.ResolvedMiddlewareStack()
+		if err != nil {
+			return nil, err
+		}
+
 		middlewares := []api.Middleware{}
-		for _, mw := range endpointDefinition.MiddlewareStack {
+		optionsMiddlewares := []api.Middleware{}
+		for _, mw := range stack {
+			if mw.ID == middleware.CORSMiddlewareID {
+				optionsMiddlewares = append(optionsMiddlewares, mw.Middleware)
+			}
+		}
+		if endpointDefinition.Deprecation != nil {
+			middlewares = append(
+				middlewares,
+				deprecationMiddleware(*endpointDefinition.Deprecation),
+			)
+		}
+		if len(endpointDefinition.Permissions) != 0 {
+			if !hasMiddleware(stack, middleware.AuthorizationMiddlewareID) {
+				return nil, fmt.Errorf(
+					"endpoint %s %s requires permissions but has no %q "+
+						"middleware",
+					endpointDefinition.Method,
+					endpointDefinition.URL,
+					middleware.AuthorizationMiddlewareID,
+				)
+			}
+			middlewares = append(
+				middlewares,
+				permissionsMiddleware(endpointDefinition.Permissions),
+			)
+		}
+		for _, mw := range stack {
 			middlewares = append(middlewares, mw.Middleware)
 		}
 
 		endpoints = append(
 			endpoints,
 			api.Endpoint{
-				URL:         endpointDefinition.URL,
-				Method:      endpointDefinition.Method,
-				Middlewares: middlewares,
+				URL:                endpointDefinition.URL,
+				Method:             endpointDefinition.Method,
+				Middlewares:        middlewares,
+				DisableAutoHead:    endpointDefinition.DisableAutoHead,
+				DisableAutoOptions: endpointDefinition.DisableAutoOptions,
+				OptionsMiddlewares: optionsMiddlewares,
 			},
 		)
 	}
 
-	return endpoints
+	return endpoints, nil
+}
+
+func hasMiddleware(stack middleware.Stack, id string) bool {
+	return slices.ContainsFunc(stack, func(mw api.MiddlewareWrapper) bool {
+		return mw.ID == id
+	})
+}
+
+// ResolvedMiddlewareStack returns the middleware stack with the middleware
+// overrides applied. It returns an error if an override has no middleware
+// with the same ID in the stack.
+func (e *EndpointDefinition) ResolvedMiddlewareStack() (
+	middleware.Stack,
+	error,
+) {
+	stack := append(middleware.Stack{}, e.MiddlewareStack...)
+	for _, override := range e.MiddlewareOverrides {
+		if !stack.ReplaceID(override.ID, override) {
+			return nil, fmt.Errorf(
+				"override of missing middleware %q in endpoint %s %s",
+				override.ID,
+				e.Method,
+				e.URL,
+			)
+		}
+	}
+	return stack, nil
+}
+
+// permissionsMiddleware stores the permissions required by the endpoint in the
+// request context.
+func permissionsMiddleware(permissions []string) api.Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(
+				auth.WithRequiredPermissions(r.Context(), permissions),
+			))
+		})
+	}
 }
 
 // Option is a function that modifies an endpoint definition when it is cloned
@@ -61,6 +166,24 @@ func WithURL(url string) Option {
 	}
 }
 
+// WithName clones an endpoint definition with the provided name
+func WithName(name string) Option {
+	return func(e *EndpointDefinition) {
+		e.Name = name
+	}
+}
+
+// WithMiddlewareOverrides clones an endpoint definition with the provided
+// middleware overrides added
+func WithMiddlewareOverrides(overrides ...api.MiddlewareWrapper) Option {
+	return func(e *EndpointDefinition) {
+		e.MiddlewareOverrides = append(
+			slices.Clip(e.MiddlewareOverrides),
+			overrides...,
+		)
+	}
+}
+
 // WithMethod clones an endpoint definition with the provided HTTP method
 func WithMethod(method string) Option {
 	return func(e *EndpointDefinition) {
@@ -68,6 +191,14 @@ func WithMethod(method string) Option {
 	}
 }
 
+// WithPermissions clones an endpoint definition with the provided required
+// permissions.
+func WithPermissions(permissions ...string) Option {
+	return func(e *EndpointDefinition) {
+		e.Permissions = permissions
+	}
+}
+
 // WithMiddlewareStack clones an endpoint definition with the provided
 // middleware stack.
 func WithMiddlewareStack(
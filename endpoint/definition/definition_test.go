@@ -2,9 +2,11 @@ package definition
 
 import (
 	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/pakkasys/fluidapi/core/api"
+	"github.com/pakkasys/fluidapi/endpoint/auth"
 	"github.com/pakkasys/fluidapi/endpoint/middleware"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -32,7 +34,8 @@ func TestEndpointDefinitionsToAPIEndpoints_NoMiddlewares(t *testing.T) {
 		},
 	}
 
-	apiEndpoints := EndpointDefinitionsToAPIEndpoints(endpointDefinitions)
+	apiEndpoints, err := EndpointDefinitionsToAPIEndpoints(endpointDefinitions)
+	assert.NoError(t, err)
 
 	assert.Len(t, apiEndpoints, 1)
 	assert.Equal(t, "/test-url", apiEndpoints[0].URL)
@@ -65,7 +68,8 @@ func TestEndpointDefinitionsToAPIEndpoints_WithMiddlewares(t *testing.T) {
 		},
 	}
 
-	apiEndpoints := EndpointDefinitionsToAPIEndpoints(endpointDefinitions)
+	apiEndpoints, err := EndpointDefinitionsToAPIEndpoints(endpointDefinitions)
+	assert.NoError(t, err)
 
 	assert.Len(t, apiEndpoints, 1)
 	assert.Equal(t, "/test-url", apiEndpoints[0].URL)
@@ -107,7 +111,8 @@ func TestEndpointDefinitionsToAPIEndpoints_MultipleDefinitions(t *testing.T) {
 		},
 	}
 
-	apiEndpoints := EndpointDefinitionsToAPIEndpoints(endpointDefinitions)
+	apiEndpoints, err := EndpointDefinitionsToAPIEndpoints(endpointDefinitions)
+	assert.NoError(t, err)
 
 	assert.Len(t, apiEndpoints, 2)
 
@@ -124,7 +129,8 @@ func TestEndpointDefinitionsToAPIEndpoints_MultipleDefinitions(t *testing.T) {
 // Test converting empty endpoint definitions (no definitions)
 func TestEndpointDefinitionsToAPIEndpoints_EmptyDefinitions(t *testing.T) {
 	endpointDefinitions := []EndpointDefinition{}
-	apiEndpoints := EndpointDefinitionsToAPIEndpoints(endpointDefinitions)
+	apiEndpoints, err := EndpointDefinitionsToAPIEndpoints(endpointDefinitions)
+	assert.NoError(t, err)
 	assert.Len(t, apiEndpoints, 0)
 }
 
@@ -176,6 +182,15 @@ func TestWithURL(t *testing.T) {
 	assert.Equal(t, "/new-url", original.URL, "URL should be /new-url")
 }
 
+// TestWithName tests the WithName function
+func TestWithName(t *testing.T) {
+	original := &EndpointDefinition{}
+	option := WithName("users.get")
+	option(original)
+
+	assert.Equal(t, "users.get", original.Name, "name should be users.get")
+}
+
 // TestWithMethod tests the WithMethod function
 func TestWithMethod(t *testing.T) {
 	original := &EndpointDefinition{Method: "GET"}
@@ -221,3 +236,181 @@ func TestWithMiddlewareWrappersFunc(t *testing.T) {
 		"MiddlewareStack should be dynamically updated",
 	)
 }
+
+// TestEndpointDefinitionsToAPIEndpoints_Permissions tests that the required
+// permissions are set to the request context before the stack runs.
+func TestEndpointDefinitionsToAPIEndpoints_Permissions(t *testing.T) {
+	var permissions []string
+	definition := CloneEndpointDefinition(
+		&EndpointDefinition{
+			URL:    "/orders",
+			Method: http.MethodGet,
+			MiddlewareStack: middleware.Stack{{
+				ID: middleware.AuthorizationMiddlewareID,
+				Middleware: func(next http.Handler) http.Handler {
+					return http.HandlerFunc(
+						func(w http.ResponseWriter, r *http.Request) {
+							permissions = auth.RequiredPermissions(
+								r.Context(),
+							)
+						},
+					)
+				},
+			}},
+		},
+		WithPermissions("orders:read"),
+	)
+
+	endpoints, err := EndpointDefinitionsToAPIEndpoints(
+		[]EndpointDefinition{*definition},
+	)
+	assert.NoError(t, err)
+	assert.Len(t, endpoints[0].Middlewares, 2)
+
+	handler := endpoints[0].Middlewares[0](
+		endpoints[0].Middlewares[1](http.NotFoundHandler()),
+	)
+	handler.ServeHTTP(
+		httptest.NewRecorder(),
+		httptest.NewRequest(http.MethodGet, "/orders", nil),
+	)
+
+	assert.Equal(t, []string{"orders:read"}, permissions)
+}
+
+// TestEndpointDefinitionsToAPIEndpoints_PermissionsWithoutAuthorization
+// tests that the permissions require the authorization middleware.
+func TestEndpointDefinitionsToAPIEndpoints_PermissionsWithoutAuthorization(
+	t *testing.T,
+) {
+	endpoints, err := EndpointDefinitionsToAPIEndpoints([]EndpointDefinition{
+		{
+			URL:             "/orders",
+			Method:          http.MethodGet,
+			MiddlewareStack: middleware.Stack{{ID: "auth"}},
+			Permissions:     []string{"orders:read"},
+		},
+	})
+
+	assert.EqualError(
+		t,
+		err,
+		`endpoint GET /orders requires permissions but has no `+
+			`"authorization" middleware`,
+	)
+	assert.Nil(t, endpoints)
+}
+
+// TestEndpointDefinitionsToAPIEndpoints_AutoMethods tests passing the opt-outs
+// of the automatic HEAD and OPTIONS handlers to the API endpoints.
+func TestEndpointDefinitionsToAPIEndpoints_AutoMethods(t *testing.T) {
+	endpoints, err := EndpointDefinitionsToAPIEndpoints([]EndpointDefinition{
+		{
+			URL:                "/test",
+			Method:             http.MethodGet,
+			DisableAutoHead:    true,
+			DisableAutoOptions: true,
+		},
+	})
+	assert.NoError(t, err)
+
+	assert.True(t, endpoints[0].DisableAutoHead)
+	assert.True(t, endpoints[0].DisableAutoOptions)
+}
+
+// TestEndpointDefinitionsToAPIEndpoints_OptionsMiddlewares tests that the
+// CORS middleware of the stack wraps the automatic OPTIONS handler.
+func TestEndpointDefinitionsToAPIEndpoints_OptionsMiddlewares(t *testing.T) {
+	cors := middleware.CORSMiddlewareWrapper([]string{"*"}, nil, nil)
+	endpoints, err := EndpointDefinitionsToAPIEndpoints([]EndpointDefinition{
+		{
+			URL:    "/test",
+			Method: http.MethodPost,
+			MiddlewareStack: middleware.Stack{
+				*cors,
+				{ID: "input", Middleware: func(next http.Handler) http.Handler {
+					return next
+				}},
+			},
+		},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, endpoints[0].OptionsMiddlewares, 1)
+
+	w := httptest.NewRecorder()
+	api.ApplyMiddlewares(
+		http.NotFoundHandler(),
+		endpoints[0].OptionsMiddlewares...,
+	).ServeHTTP(w, httptest.NewRequest(http.MethodOptions, "/test", nil))
+	assert.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
+}
+
+// TestEndpointDefinitionsToAPIEndpoints_MiddlewareOverrides tests replacing
+// the shared middlewares of a group in place.
+func TestEndpointDefinitionsToAPIEndpoints_MiddlewareOverrides(t *testing.T) {
+	var calls []string
+	named := func(name string) api.Middleware {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(
+				func(w http.ResponseWriter, r *http.Request) {
+					calls = append(calls, name)
+					next.ServeHTTP(w, r)
+				},
+			)
+		}
+	}
+	group := NewGroup(
+		"api",
+		api.MiddlewareWrapper{ID: "limit", Middleware: named("limit 1MB")},
+		api.MiddlewareWrapper{ID: "auth", Middleware: named("auth")},
+	)
+	group.Register(CloneEndpointDefinition(
+		&EndpointDefinition{
+			URL:    "/uploads",
+			Method: http.MethodPost,
+			MiddlewareStack: middleware.Stack{
+				{ID: "input", Middleware: named("input")},
+			},
+		},
+		WithMiddlewareOverrides(api.MiddlewareWrapper{
+			ID:         "limit",
+			Middleware: named("limit 100MB"),
+		}),
+	))
+
+	endpoints, err := group.APIEndpoints()
+	assert.NoError(t, err)
+	api.ApplyMiddlewares(
+		http.NotFoundHandler(),
+		endpoints[0].Middlewares...,
+	).ServeHTTP(
+		httptest.NewRecorder(),
+		httptest.NewRequest(http.MethodPost, "/uploads", nil),
+	)
+
+	assert.Equal(t, []string{"limit 100MB", "auth", "input"}, calls)
+}
+
+// TestResolvedMiddlewareStack_MissingMiddleware tests that the overridden
+// middlewares must be in the stack.
+func TestResolvedMiddlewareStack_MissingMiddleware(t *testing.T) {
+	definition := &EndpointDefinition{
+		URL:                 "/uploads",
+		Method:              http.MethodPost,
+		MiddlewareOverrides: []api.MiddlewareWrapper{{ID: "limit"}},
+	}
+
+	stack, err := definition.ResolvedMiddlewareStack()
+	assert.Nil(t, stack)
+	assert.EqualError(
+		t,
+		err,
+		`override of missing middleware "limit" in endpoint POST /uploads`,
+	)
+
+	endpoints, err := EndpointDefinitionsToAPIEndpoints(
+		[]EndpointDefinition{*definition},
+	)
+	assert.Nil(t, endpoints)
+	assert.Error(t, err)
+}
@@ -0,0 +1,120 @@
+package definition
+
+import (
+	"bytes"
+	"html/template"
+	"net/http"
+
+	"github.com/pakkasys/fluidapi/core/api"
+	"github.com/pakkasys/fluidapi/endpoint/middleware"
+)
+
+// DocsMiddlewareID is the ID of the middlewares serving the API docs.
+const DocsMiddlewareID = "docs"
+
+// DefaultSwaggerUIURL is the default base URL of the Swagger UI assets.
+const DefaultSwaggerUIURL = "https://unpkg.com/swagger-ui-dist@5"
+
+// DocsConfig configures the API docs endpoints.
+type DocsConfig struct {
+	// URL of the API explorer page. The spec is served at URL +
+	// "/openapi.json". Defaults to "/docs".
+	URL string
+	// Title of the API explorer page. Defaults to "API Docs".
+	Title string
+	// SwaggerUIURL is the base URL of the Swagger UI assets. Defaults to
+	// DefaultSwaggerUIURL.
+	SwaggerUIURL string
+	// Middlewares run before the docs are served, e.g. an authentication
+	// middleware restricting access to the docs.
+	Middlewares []api.MiddlewareWrapper
+}
+
+var docsTemplate = template.Must(template.New("docs").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<link rel="stylesheet" href="{{.SwaggerUIURL}}/swagger-ui.css">
+</head>
+<body>
+<div id="swagger-ui"></div>
+<script src="{{.SwaggerUIURL}}/swagger-ui-bundle.js"></script>
+<script>
+window.onload = function() {
+	window.ui = SwaggerUIBundle({url: {{.SpecURL}}, dom_id: "#swagger-ui"});
+};
+</script>
+</body>
+</html>
+`))
+
+// DocsEndpoint creates the endpoint definitions serving an interactive API
+// explorer for the OpenAPI spec: the explorer page at the docs URL and the
+// spec at the docs URL + "/openapi.json".
+//
+//   - spec: The OpenAPI spec in JSON.
+//   - config: The configuration of the docs.
+func DocsEndpoint(spec []byte, config DocsConfig) []EndpointDefinition {
+	if config.URL == "" {
+		config.URL = "/docs"
+	}
+	if config.Title == "" {
+		config.Title = "API Docs"
+	}
+	if config.SwaggerUIURL == "" {
+		config.SwaggerUIURL = DefaultSwaggerUIURL
+	}
+	specURL := api.JoinURL(config.URL, "/openapi.json")
+
+	var page bytes.Buffer
+	err := docsTemplate.Execute(&page, map[string]string{
+		"Title":        config.Title,
+		"SwaggerUIURL": config.SwaggerUIURL,
+		"SpecURL":      specURL,
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	return []EndpointDefinition{
+		{
+			URL:    config.URL,
+			Method: http.MethodGet,
+			MiddlewareStack: docsStack(
+				config.Middlewares,
+				"text/html; charset=utf-8",
+				page.Bytes(),
+			),
+		},
+		{
+			URL:    specURL,
+			Method: http.MethodGet,
+			MiddlewareStack: docsStack(
+				config.Middlewares,
+				"application/json",
+				spec,
+			),
+		},
+	}
+}
+
+func docsStack(
+	middlewares []api.MiddlewareWrapper,
+	contentType string,
+	content []byte,
+) middleware.Stack {
+	stack := append(middleware.Stack{}, middlewares...)
+	return append(stack, api.MiddlewareWrapper{
+		ID: DocsMiddlewareID,
+		Middleware: func(next http.Handler) http.Handler {
+			return http.HandlerFunc(
+				func(w http.ResponseWriter, r *http.Request) {
+					w.Header().Set("Content-Type", contentType)
+					_, _ = w.Write(content)
+				},
+			)
+		},
+	})
+}
@@ -0,0 +1,78 @@
+package definition
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pakkasys/fluidapi/core/api"
+	"github.com/stretchr/testify/assert"
+)
+
+func serveDefinition(
+	definition EndpointDefinition,
+) *httptest.ResponseRecorder {
+	rr := httptest.NewRecorder()
+	api.ApplyMiddlewares(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}),
+		definition.MiddlewareStack.Middlewares()...,
+	).ServeHTTP(rr, httptest.NewRequest(definition.Method, "/", nil))
+	return rr
+}
+
+// TestDocsEndpoint tests serving the explorer page and the spec.
+func TestDocsEndpoint(t *testing.T) {
+	definitions := DocsEndpoint([]byte(`{"openapi":"3.1.0"}`), DocsConfig{
+		Title: "Users <API>",
+	})
+
+	assert.Len(t, definitions, 2)
+	assert.Equal(t, "/docs", definitions[0].URL)
+	assert.Equal(t, "/docs/openapi.json", definitions[1].URL)
+
+	page := serveDefinition(definitions[0])
+	assert.Equal(
+		t,
+		"text/html; charset=utf-8",
+		page.Header().Get("Content-Type"),
+	)
+	assert.Contains(t, page.Body.String(), "<title>Users &lt;API&gt;</title>")
+	assert.Contains(t, page.Body.String(), DefaultSwaggerUIURL)
+	assert.Contains(t, page.Body.String(), `"/docs/openapi.json"`)
+
+	spec := serveDefinition(definitions[1])
+	assert.Equal(t, "application/json", spec.Header().Get("Content-Type"))
+	assert.Equal(t, `{"openapi":"3.1.0"}`, spec.Body.String())
+}
+
+// TestDocsEndpoint_Middlewares tests that the docs middlewares run before
+// the docs are served.
+func TestDocsEndpoint_Middlewares(t *testing.T) {
+	auth := api.MiddlewareWrapper{
+		ID: "auth",
+		Middleware: func(next http.Handler) http.Handler {
+			return http.HandlerFunc(
+				func(w http.ResponseWriter, r *http.Request) {
+					w.WriteHeader(http.StatusUnauthorized)
+				},
+			)
+		},
+	}
+
+	definitions := DocsEndpoint(nil, DocsConfig{
+		URL:         "/api/docs",
+		Middlewares: []api.MiddlewareWrapper{auth},
+	})
+
+	assert.Equal(t, "/api/docs/openapi.json", definitions[1].URL)
+	for _, definition := range definitions {
+		assert.Equal(
+			t,
+			[]string{"auth", DocsMiddlewareID},
+			stackIDs(definition.MiddlewareStack),
+		)
+		rr := serveDefinition(definition)
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+		assert.Empty(t, rr.Body.String())
+	}
+}
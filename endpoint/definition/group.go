@@ -0,0 +1,162 @@
+package definition
+
+import (
+	"github.com/pakkasys/fluidapi/core/api"
+	"github.com/pakkasys/fluidapi/endpoint/middleware"
+)
+
+// Group is a group of endpoint definitions sharing the same middleware
+// defaults, e.g. CORS, authentication and rate limit policies of public,
+// internal or admin endpoints.
+type Group struct {
+	name        string
+	prefix      string
+	middlewares middleware.Stack
+	definitions []EndpointDefinition
+	groups      []*Group
+}
+
+// NewGroup creates a new endpoint group.
+//
+//   - name: The name of the group.
+//   - middlewares: The default middlewares of the group.
+func NewGroup(name string, middlewares ...api.MiddlewareWrapper) *Group {
+	return &Group{
+		name:        name,
+		middlewares: mergeStacks(nil, middlewares),
+	}
+}
+
+// Name returns the name of the group.
+func (g *Group) Name() string {
+	return g.name
+}
+
+// Mount sets the route prefix of the group, e.g. "/api/v1". The URLs of the
+// group endpoints are prefixed with it, and the URLs of the subgroup endpoints
+// with the prefixes of the group and the subgroup.
+//
+//   - prefix: The route prefix of the group.
+func (g *Group) Mount(prefix string) *Group {
+	g.prefix = prefix
+	return g
+}
+
+// Prefix returns the route prefix of the group.
+func (g *Group) Prefix() string {
+	return g.prefix
+}
+
+// Middlewares returns the default middlewares of the group.
+func (g *Group) Middlewares() middleware.Stack {
+	return append(middleware.Stack{}, g.middlewares...)
+}
+
+// Group creates a subgroup inheriting the middleware defaults of the group. A
+// subgroup middleware replaces the group middleware with the same ID.
+//
+//   - name: The name of the subgroup.
+//   - middlewares: The additional default middlewares of the subgroup.
+func (g *Group) Group(
+	name string,
+	middlewares ...api.MiddlewareWrapper,
+) *Group {
+	subgroup := &Group{
+		name:        name,
+		middlewares: mergeStacks(g.middlewares, middlewares),
+	}
+	g.groups = append(g.groups, subgroup)
+	return subgroup
+}
+
+// Register registers endpoint definitions to the group.
+//
+//   - definitions: The endpoint definitions to register.
+func (g *Group) Register(definitions ...*EndpointDefinition) *Group {
+	for _, definition := range definitions {
+		g.definitions = append(g.definitions, *definition)
+	}
+	return g
+}
+
+// EndpointDefinitions returns the endpoint definitions of the group and its
+// subgroups with the group prefixes and middlewares applied. The group
+// middlewares run before the endpoint middlewares. A group middleware is
+// skipped if the endpoint already has a middleware with the same ID, so
+// endpoints can override the group defaults.
+func (g *Group) EndpointDefinitions() []EndpointDefinition {
+	return g.endpointDefinitions("")
+}
+
+// APIEndpoints returns the endpoint definitions of the group as API endpoints
+// that can be passed to the server. See EndpointDefinitionsToAPIEndpoints for
+// the errors.
+func (g *Group) APIEndpoints() ([]api.Endpoint, error) {
+	return EndpointDefinitionsToAPIEndpoints(g.EndpointDefinitions())
+}
+
+func (g *Group) endpointDefinitions(
+	parentPrefix string,
+) []EndpointDefinition {
+	prefix := api.JoinURL(parentPrefix, g.prefix)
+	definitions := []EndpointDefinition{}
+	for _, definition := range g.definitions {
+		definition.URL = api.JoinURL(prefix, definition.URL)
+		definition.MiddlewareStack = applyGroupStack(
+			g.middlewares,
+			definition.MiddlewareStack,
+		)
+		definitions = append(definitions, definition)
+	}
+	for _, subgroup := range g.groups {
+		definitions = append(
+			definitions,
+			subgroup.endpointDefinitions(prefix)...,
+		)
+	}
+	return definitions
+}
+
+func mergeStacks(
+	stack middleware.Stack,
+	middlewares []api.MiddlewareWrapper,
+) middleware.Stack {
+	merged := append(middleware.Stack{}, stack...)
+	for _, mw := range middlewares {
+		replaced := false
+		for i := range merged {
+			if mw.ID != "" && merged[i].ID == mw.ID {
+				merged[i] = mw
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			merged = append(merged, mw)
+		}
+	}
+	return merged
+}
+
+func applyGroupStack(
+	groupStack middleware.Stack,
+	endpointStack middleware.Stack,
+) middleware.Stack {
+	stack := middleware.Stack{}
+	for _, mw := range groupStack {
+		if mw.ID != "" && hasMiddlewareID(endpointStack, mw.ID) {
+			continue
+		}
+		stack = append(stack, mw)
+	}
+	return append(stack, endpointStack...)
+}
+
+func hasMiddlewareID(stack middleware.Stack, id string) bool {
+	for _, mw := range stack {
+		if mw.ID == id {
+			return true
+		}
+	}
+	return false
+}
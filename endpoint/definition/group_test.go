@@ -0,0 +1,123 @@
+package definition
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/pakkasys/fluidapi/core/api"
+	"github.com/pakkasys/fluidapi/endpoint/middleware"
+	"github.com/stretchr/testify/assert"
+)
+
+func groupTestMiddleware(id string) api.MiddlewareWrapper {
+	return api.MiddlewareWrapper{
+		ID: id,
+		Middleware: func(next http.Handler) http.Handler {
+			return next
+		},
+		Inputs: []any{id},
+	}
+}
+
+func stackIDs(stack middleware.Stack) []string {
+	ids := []string{}
+	for _, mw := range stack {
+		ids = append(ids, mw.ID)
+	}
+	return ids
+}
+
+// TestGroup_EndpointDefinitions tests that the group middlewares are applied
+// before the endpoint middlewares.
+func TestGroup_EndpointDefinitions(t *testing.T) {
+	group := NewGroup(
+		"public",
+		groupTestMiddleware("cors"),
+		groupTestMiddleware("rate_limit"),
+	)
+	group.Register(&EndpointDefinition{
+		URL:             "/users",
+		Method:          http.MethodGet,
+		MiddlewareStack: middleware.Stack{groupTestMiddleware("inputlogic")},
+	})
+
+	definitions := group.EndpointDefinitions()
+
+	assert.Len(t, definitions, 1)
+	assert.Equal(t, "/users", definitions[0].URL)
+	assert.Equal(
+		t,
+		[]string{"cors", "rate_limit", "inputlogic"},
+		stackIDs(definitions[0].MiddlewareStack),
+	)
+	assert.Equal(t, "public", group.Name())
+}
+
+// TestGroup_EndpointOverride tests that an endpoint middleware replaces the
+// group middleware with the same ID.
+func TestGroup_EndpointOverride(t *testing.T) {
+	group := NewGroup("public", groupTestMiddleware("cors"))
+	group.Register(&EndpointDefinition{
+		MiddlewareStack: middleware.Stack{groupTestMiddleware("cors")},
+	})
+
+	definitions := group.EndpointDefinitions()
+
+	assert.Equal(t, []string{"cors"}, stackIDs(definitions[0].MiddlewareStack))
+}
+
+// TestGroup_Subgroup tests that subgroups inherit and override the group
+// middlewares.
+func TestGroup_Subgroup(t *testing.T) {
+	internalCORS := groupTestMiddleware("cors")
+	internalCORS.Inputs = []any{"internal"}
+
+	group := NewGroup(
+		"api",
+		groupTestMiddleware("cors"),
+		groupTestMiddleware("auth"),
+	)
+	group.Register(&EndpointDefinition{URL: "/public"})
+	admin := group.Group("admin", internalCORS, groupTestMiddleware("rbac"))
+	admin.Register(&EndpointDefinition{URL: "/admin"})
+
+	definitions := group.EndpointDefinitions()
+
+	assert.Len(t, definitions, 2)
+	assert.Equal(t, "/public", definitions[0].URL)
+	assert.Equal(t, "/admin", definitions[1].URL)
+	assert.Equal(
+		t,
+		[]string{"cors", "auth", "rbac"},
+		stackIDs(definitions[1].MiddlewareStack),
+	)
+	assert.Equal(
+		t,
+		[]any{"internal"},
+		definitions[1].MiddlewareStack[0].Inputs,
+	)
+	assert.Equal(t, []string{"cors", "auth"}, stackIDs(group.Middlewares()))
+}
+
+// TestGroup_Mount tests that the group prefixes are applied to the endpoint
+// URLs.
+func TestGroup_Mount(t *testing.T) {
+	group := NewGroup("api", groupTestMiddleware("cors")).Mount("/api/v1/")
+	group.Register(&EndpointDefinition{URL: "/users/{id}", Method: "GET"})
+	admin := group.Group("admin").Mount("admin")
+	admin.Register(&EndpointDefinition{URL: "/stats", Method: "GET"})
+	group.Group("internal").Register(&EndpointDefinition{URL: "/health"})
+
+	definitions := group.EndpointDefinitions()
+
+	assert.Equal(t, "/api/v1/", group.Prefix())
+	assert.Equal(t, "/api/v1/users/{id}", definitions[0].URL)
+	assert.Equal(t, "/api/v1/admin/stats", definitions[1].URL)
+	assert.Equal(t, "/api/v1/health", definitions[2].URL)
+
+	endpoints, err := group.APIEndpoints()
+	assert.NoError(t, err)
+	assert.Len(t, endpoints, 3)
+	assert.Equal(t, "/api/v1/users/{id}", endpoints[0].URL)
+	assert.Len(t, endpoints[0].Middlewares, 1)
+}
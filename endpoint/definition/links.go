@@ -0,0 +1,102 @@
+package definition
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/pakkasys/fluidapi/core/api"
+)
+
+// Link is a hypermedia link of an output.
+type Link struct {
+	Href   string `json:"href"`
+	Method string `json:"method,omitempty"`
+}
+
+// Links are the links of an output by their relation types, e.g. "self",
+// "next" or the name of a related resource. Outputs embed them e.g. as
+//
+//	Links definition.Links `json:"_links,omitempty"`
+type Links map[string]Link
+
+// LinkBuilder builds links to endpoints by their names. The URLs of the links
+// are the URLs of the endpoint definitions, so the links follow the routes
+// when they change.
+type LinkBuilder struct {
+	baseURL     string
+	definitions map[string]EndpointDefinition
+}
+
+// NewLinkBuilder creates a new link builder for the named endpoint
+// definitions, e.g. the endpoint definitions of a group with the group
+// prefixes applied. Definitions without a name are skipped. It panics if
+// two definitions have the same name.
+//
+//   - baseURL: The base URL of the links, e.g. "https://api.example.com". If
+//     empty, the links are paths.
+//   - definitions: The endpoint definitions.
+func NewLinkBuilder(
+	baseURL string,
+	definitions ...EndpointDefinition,
+) *LinkBuilder {
+	builder := &LinkBuilder{
+		baseURL:     baseURL,
+		definitions: map[string]EndpointDefinition{},
+	}
+	for _, definition := range definitions {
+		if definition.Name == "" {
+			continue
+		}
+		if _, ok := builder.definitions[definition.Name]; ok {
+			panic(fmt.Sprintf("duplicate endpoint name %q", definition.Name))
+		}
+		builder.definitions[definition.Name] = definition
+	}
+	return builder
+}
+
+// Link returns the link to an endpoint.
+//
+//   - name: The name of the endpoint.
+//   - parameters: The values of the path parameters of the endpoint URL.
+//   - query: The query of the link. Optional.
+func (b *LinkBuilder) Link(
+	name string,
+	parameters map[string]string,
+	query url.Values,
+) (Link, error) {
+	definition, ok := b.definitions[name]
+	if !ok {
+		return Link{}, fmt.Errorf("unknown endpoint: %s", name)
+	}
+	path, err := api.ExpandPath(definition.URL, parameters)
+	if err != nil {
+		return Link{}, err
+	}
+
+	href := api.JoinURL(b.baseURL, path)
+	if len(query) > 0 {
+		href += "?" + query.Encode()
+	}
+	return Link{Href: href, Method: definition.Method}, nil
+}
+
+// Links returns the links to endpoints sharing the same path parameters,
+// e.g. the "self" link and the links to the related resources of an entity.
+//
+//   - names: The names of the endpoints by the relation types of the links.
+//   - parameters: The values of the path parameters of the endpoint URLs.
+func (b *LinkBuilder) Links(
+	names map[string]string,
+	parameters map[string]string,
+) (Links, error) {
+	links := Links{}
+	for rel, name := range names {
+		link, err := b.Link(name, parameters, nil)
+		if err != nil {
+			return nil, err
+		}
+		links[rel] = link
+	}
+	return links, nil
+}
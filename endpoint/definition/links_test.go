@@ -0,0 +1,80 @@
+package definition
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testLinkBuilder(baseURL string) *LinkBuilder {
+	group := NewGroup("api").Mount("/api/v1")
+	group.Register(
+		&EndpointDefinition{
+			Name:   "users.get",
+			URL:    "/users/{id}",
+			Method: http.MethodGet,
+		},
+		&EndpointDefinition{
+			Name:   "users.teams",
+			URL:    "/users/{id}/teams",
+			Method: http.MethodGet,
+		},
+		&EndpointDefinition{URL: "/health", Method: http.MethodGet},
+	)
+	return NewLinkBuilder(baseURL, group.EndpointDefinitions()...)
+}
+
+// TestLinkBuilder_Link tests building links with the URLs of the endpoint
+// definitions.
+func TestLinkBuilder_Link(t *testing.T) {
+	link, err := testLinkBuilder("https://api.example.com").Link(
+		"users.get",
+		map[string]string{"id": "a b"},
+		url.Values{"fields": {"name"}},
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(t, Link{
+		Href:   "https://api.example.com/api/v1/users/a%20b?fields=name",
+		Method: http.MethodGet,
+	}, link)
+}
+
+// TestLinkBuilder_Link_Errors tests the errors of unknown endpoints and
+// missing path parameters.
+func TestLinkBuilder_Link_Errors(t *testing.T) {
+	_, err := testLinkBuilder("").Link("users.delete", nil, nil)
+
+	assert.EqualError(t, err, "unknown endpoint: users.delete")
+
+	_, err = testLinkBuilder("").Link("users.get", nil, nil)
+
+	assert.EqualError(t, err, "missing path parameter: id")
+}
+
+// TestLinkBuilder_Links tests building the links of a resource.
+func TestLinkBuilder_Links(t *testing.T) {
+	links, err := testLinkBuilder("").Links(
+		map[string]string{"self": "users.get", "teams": "users.teams"},
+		map[string]string{"id": "1"},
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(t, Links{
+		"self":  {Href: "/api/v1/users/1", Method: http.MethodGet},
+		"teams": {Href: "/api/v1/users/1/teams", Method: http.MethodGet},
+	}, links)
+}
+
+// TestNewLinkBuilder_Panics tests that the endpoint names must be unique.
+func TestNewLinkBuilder_Panics(t *testing.T) {
+	assert.PanicsWithValue(t, `duplicate endpoint name "users.get"`, func() {
+		NewLinkBuilder(
+			"",
+			EndpointDefinition{Name: "users.get", URL: "/users/{id}"},
+			EndpointDefinition{Name: "users.get", URL: "/v2/users/{id}"},
+		)
+	})
+}
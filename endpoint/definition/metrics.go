@@ -0,0 +1,51 @@
+package definition
+
+import (
+	"net/http"
+
+	"github.com/pakkasys/fluidapi/core/api"
+	"github.com/pakkasys/fluidapi/endpoint/middleware"
+)
+
+// MetricsMiddlewareID is the ID of the middleware serving the metrics.
+const MetricsMiddlewareID = "metrics_endpoint"
+
+// MetricsConfig configures the metrics endpoint.
+type MetricsConfig struct {
+	// URL of the metrics endpoint. Defaults to "/metrics".
+	URL string
+	// Middlewares run before the metrics are served, e.g. an authentication
+	// middleware restricting access to the metrics.
+	Middlewares []api.MiddlewareWrapper
+}
+
+// MetricsEndpoint creates the endpoint definition serving the metrics, e.g.
+// metrics.Metrics in the Prometheus text format.
+//
+//   - handler: The handler serving the metrics.
+//   - config: The configuration of the endpoint.
+func MetricsEndpoint(
+	handler http.Handler,
+	config MetricsConfig,
+) EndpointDefinition {
+	if handler == nil {
+		panic("handler cannot be nil")
+	}
+	if config.URL == "" {
+		config.URL = "/metrics"
+	}
+
+	stack := append(middleware.Stack{}, config.Middlewares...)
+	stack = append(stack, api.MiddlewareWrapper{
+		ID: MetricsMiddlewareID,
+		Middleware: func(next http.Handler) http.Handler {
+			return handler
+		},
+	})
+
+	return EndpointDefinition{
+		URL:             config.URL,
+		Method:          http.MethodGet,
+		MiddlewareStack: stack,
+	}
+}
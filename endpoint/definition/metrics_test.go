@@ -0,0 +1,56 @@
+package definition
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/pakkasys/fluidapi/core/api"
+	"github.com/pakkasys/fluidapi/endpoint/metrics"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestMetricsEndpoint tests serving the metrics.
+func TestMetricsEndpoint(t *testing.T) {
+	m := metrics.NewMetrics(metrics.Config{})
+	m.RequestStarted("/users", http.MethodGet)
+
+	definition := MetricsEndpoint(m, MetricsConfig{})
+
+	assert.Equal(t, "/metrics", definition.URL)
+	assert.Equal(t, http.MethodGet, definition.Method)
+	rr := serveDefinition(definition)
+	assert.Equal(t, metrics.ContentType, rr.Header().Get("Content-Type"))
+	assert.Contains(
+		t,
+		rr.Body.String(),
+		`http_requests_in_flight{url="/users",method="GET"} 1`,
+	)
+}
+
+// TestMetricsEndpoint_Config tests the URL and middlewares of the endpoint.
+func TestMetricsEndpoint_Config(t *testing.T) {
+	auth := api.MiddlewareWrapper{
+		ID: "auth",
+		Middleware: func(next http.Handler) http.Handler {
+			return next
+		},
+	}
+
+	definition := MetricsEndpoint(
+		metrics.NewMetrics(metrics.Config{}),
+		MetricsConfig{
+			URL:         "/internal/metrics",
+			Middlewares: []api.MiddlewareWrapper{auth},
+		},
+	)
+
+	assert.Equal(t, "/internal/metrics", definition.URL)
+	assert.Len(t, definition.MiddlewareStack, 2)
+	assert.Equal(t, "auth", definition.MiddlewareStack[0].ID)
+	assert.Equal(t, MetricsMiddlewareID, definition.MiddlewareStack[1].ID)
+}
+
+// TestMetricsEndpoint_NilHandler tests that a nil handler panics.
+func TestMetricsEndpoint_NilHandler(t *testing.T) {
+	assert.Panics(t, func() { MetricsEndpoint(nil, MetricsConfig{}) })
+}
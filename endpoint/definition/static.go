@@ -0,0 +1,178 @@
+package definition
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/pakkasys/fluidapi/core/api"
+	"github.com/pakkasys/fluidapi/endpoint/middleware"
+)
+
+// StaticMiddlewareID is the ID of the middleware serving the static files.
+const StaticMiddlewareID = "static"
+
+// StaticConfig configures the static file endpoints.
+type StaticConfig struct {
+	// URL is the path prefix of the files, e.g. "/assets/". The files are
+	// served at the paths below it. A single page application is usually
+	// served at "/", in which case the endpoint handles all the requests not
+	// matching the other endpoints.
+	URL string
+	// FS contains the files, e.g. os.DirFS("public") for a directory or an
+	// embed.FS. Directories are not listed.
+	FS fs.FS
+	// Index is the file served for directories. Defaults to "index.html".
+	Index string
+	// SPA serves the index file of the root for the paths without a file
+	// extension that do not match a file, so that the client-side router of a
+	// single page application can handle them.
+	SPA bool
+	// CacheControl is sent in the Cache-Control header of the files, e.g.
+	// "public, max-age=31536000, immutable" for fingerprinted assets. The
+	// index files are sent with "no-cache". Optional.
+	CacheControl string
+	// Middlewares run before the files are served, e.g. the compression
+	// middleware.
+	Middlewares []api.MiddlewareWrapper
+}
+
+// StaticEndpoints creates the GET and HEAD endpoint definitions serving the
+// files of a directory or an embedded file system.
+//
+//   - config: The configuration of the endpoints.
+//
+// Example:
+//
+//	//go:embed dist
+//	var dist embed.FS
+//
+//	files, _ := fs.Sub(dist, "dist")
+//	definition.StaticEndpoints(definition.StaticConfig{
+//		URL: "/",
+//		FS:  files,
+//		SPA: true,
+//	})
+func StaticEndpoints(config StaticConfig) []EndpointDefinition {
+	if config.FS == nil {
+		panic("file system cannot be nil")
+	}
+	if config.Index == "" {
+		config.Index = "index.html"
+	}
+	prefix := "/" + strings.Trim(config.URL, "/") + "/"
+	if prefix == "//" {
+		prefix = "/"
+	}
+
+	stack := append(middleware.Stack{}, config.Middlewares...)
+	stack = append(stack, api.MiddlewareWrapper{
+		ID: StaticMiddlewareID,
+		Middleware: func(next http.Handler) http.Handler {
+			return staticHandler(prefix, &config)
+		},
+	})
+
+	return []EndpointDefinition{
+		{
+			URL:             prefix,
+			Method:          http.MethodGet,
+			MiddlewareStack: stack,
+		},
+		{
+			URL:             prefix,
+			Method:          http.MethodHead,
+			MiddlewareStack: stack,
+		},
+	}
+}
+
+func staticHandler(prefix string, config *StaticConfig) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := strings.TrimPrefix(r.URL.Path, prefix)
+		name = strings.TrimPrefix(path.Clean("/"+name), "/")
+		if name == "" {
+			name = "."
+		}
+
+		served, err := serveStaticFile(w, r, config, name)
+		if err == nil && !served && config.SPA && path.Ext(name) == "" {
+			served, err = serveStaticFile(w, r, config, ".")
+		}
+		if err != nil {
+			http.Error(
+				w,
+				http.StatusText(http.StatusInternalServerError),
+				http.StatusInternalServerError,
+			)
+			return
+		}
+		if !served {
+			http.Error(
+				w,
+				http.StatusText(http.StatusNotFound),
+				http.StatusNotFound,
+			)
+		}
+	})
+}
+
+// serveStaticFile serves the file, or the index file if it is a directory.
+// It returns false if there is no such file.
+func serveStaticFile(
+	w http.ResponseWriter,
+	r *http.Request,
+	config *StaticConfig,
+	name string,
+) (bool, error) {
+	info, err := fs.Stat(config.FS, name)
+	if err != nil {
+		return false, ignoreNotExist(err)
+	}
+	isIndex := info.IsDir()
+	if isIndex {
+		name = path.Join(name, config.Index)
+		info, err = fs.Stat(config.FS, name)
+		if err != nil {
+			return false, ignoreNotExist(err)
+		}
+		if info.IsDir() {
+			return false, nil
+		}
+	}
+	isIndex = isIndex || path.Base(name) == config.Index
+
+	file, err := config.FS.Open(name)
+	if err != nil {
+		return false, ignoreNotExist(err)
+	}
+	defer file.Close()
+
+	content, ok := file.(io.ReadSeeker)
+	if !ok {
+		data, err := io.ReadAll(file)
+		if err != nil {
+			return false, err
+		}
+		content = bytes.NewReader(data)
+	}
+
+	if isIndex {
+		w.Header().Set("Cache-Control", "no-cache")
+	} else if config.CacheControl != "" {
+		w.Header().Set("Cache-Control", config.CacheControl)
+	}
+	http.ServeContent(w, r, info.Name(), info.ModTime(), content)
+	return true, nil
+}
+
+func ignoreNotExist(err error) error {
+	if errors.Is(err, fs.ErrNotExist) || errors.Is(err, fs.ErrInvalid) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,126 @@
+package definition
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/pakkasys/fluidapi/core/api"
+	"github.com/stretchr/testify/assert"
+)
+
+var staticFS = fstest.MapFS{
+	"index.html":      {Data: []byte("<html>app</html>")},
+	"app.js":          {Data: []byte("console.log(1)")},
+	"docs/index.html": {Data: []byte("<html>docs</html>")},
+	"empty/.keep":     {Data: []byte{}},
+}
+
+func serveStatic(
+	definition EndpointDefinition,
+	method string,
+	target string,
+) *httptest.ResponseRecorder {
+	rr := httptest.NewRecorder()
+	api.ApplyMiddlewares(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}),
+		definition.MiddlewareStack.Middlewares()...,
+	).ServeHTTP(rr, httptest.NewRequest(method, target, nil))
+	return rr
+}
+
+// TestStaticEndpoints tests serving files under a prefix.
+func TestStaticEndpoints(t *testing.T) {
+	definitions := StaticEndpoints(StaticConfig{
+		URL:          "/assets",
+		FS:           staticFS,
+		CacheControl: "public, max-age=60",
+	})
+
+	assert.Len(t, definitions, 2)
+	assert.Equal(t, "/assets/", definitions[0].URL)
+	assert.Equal(t, http.MethodGet, definitions[0].Method)
+	assert.Equal(t, http.MethodHead, definitions[1].Method)
+
+	rr := serveStatic(definitions[0], "GET", "/assets/app.js")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "console.log(1)", rr.Body.String())
+	assert.Equal(t, "public, max-age=60", rr.Header().Get("Cache-Control"))
+	assert.Contains(t, rr.Header().Get("Content-Type"), "javascript")
+
+	rr = serveStatic(definitions[0], "GET", "/assets/docs/")
+	assert.Equal(t, "<html>docs</html>", rr.Body.String())
+	assert.Equal(t, "no-cache", rr.Header().Get("Cache-Control"))
+
+	rr = serveStatic(definitions[1], "HEAD", "/assets/app.js")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Empty(t, rr.Body.String())
+
+	for _, target := range []string{
+		"/assets/missing.js",
+		"/assets/missing",
+		"/assets/empty/",
+		"/assets/../../etc/passwd",
+	} {
+		rr = serveStatic(definitions[0], "GET", target)
+		assert.Equal(t, http.StatusNotFound, rr.Code, target)
+	}
+}
+
+// TestStaticEndpoints_SPA tests the index fallback of single page
+// applications.
+func TestStaticEndpoints_SPA(t *testing.T) {
+	definitions := StaticEndpoints(StaticConfig{
+		URL: "/",
+		FS:  staticFS,
+		SPA: true,
+	})
+
+	assert.Equal(t, "/", definitions[0].URL)
+	for target, body := range map[string]string{
+		"/":             "<html>app</html>",
+		"/app.js":       "console.log(1)",
+		"/users/1/edit": "<html>app</html>",
+		"/docs":         "<html>docs</html>",
+	} {
+		rr := serveStatic(definitions[0], "GET", target)
+		assert.Equal(t, http.StatusOK, rr.Code, target)
+		assert.Equal(t, body, rr.Body.String(), target)
+	}
+
+	rr := serveStatic(definitions[0], "GET", "/missing.js")
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
+
+// TestStaticEndpoints_Middlewares tests that the middlewares run before the
+// files are served.
+func TestStaticEndpoints_Middlewares(t *testing.T) {
+	definitions := StaticEndpoints(StaticConfig{
+		FS: staticFS,
+		Middlewares: []api.MiddlewareWrapper{{
+			ID: "header",
+			Middleware: func(next http.Handler) http.Handler {
+				return http.HandlerFunc(
+					func(w http.ResponseWriter, r *http.Request) {
+						w.Header().Set("X-Test", "value")
+						next.ServeHTTP(w, r)
+					},
+				)
+			},
+		}},
+	})
+
+	rr := serveStatic(definitions[0], "GET", "/app.js")
+
+	assert.Equal(t, "value", rr.Header().Get("X-Test"))
+	assert.Len(t, definitions[0].MiddlewareStack, 2)
+	assert.Equal(t, StaticMiddlewareID, definitions[0].MiddlewareStack[1].ID)
+}
+
+// TestStaticEndpoints_NilFS tests that the file system is required.
+func TestStaticEndpoints_NilFS(t *testing.T) {
+	assert.PanicsWithValue(t, "file system cannot be nil", func() {
+		StaticEndpoints(StaticConfig{})
+	})
+}
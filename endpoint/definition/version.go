@@ -0,0 +1,176 @@
+package definition
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pakkasys/fluidapi/core/api"
+)
+
+// Deprecation describes the deprecation of an endpoint.
+type Deprecation struct {
+	// Date when the endpoint was deprecated. If zero, the Deprecation header
+	// is set to "true".
+	Date time.Time
+	// Sunset is the date after which the endpoint will be removed. Optional.
+	Sunset time.Time
+	// Link is the URL of the deprecation notice, e.g. a migration guide.
+	// Optional.
+	Link string
+}
+
+// Version is a version of a logical endpoint.
+type Version struct {
+	// Name of the version, e.g. "v1". The endpoint URL is prefixed with "/"
+	// + Name.
+	Name string
+	// Deprecation marks the version deprecated. Optional.
+	Deprecation *Deprecation
+	// Options modify the endpoint definition of the version, e.g. to use a
+	// different middleware stack. Optional.
+	Options []Option
+}
+
+// Versioned creates the endpoint definitions of a logical endpoint registered
+// under multiple versions, e.g.
+//
+//	definition.Versioned(
+//		getUserDefinition,
+//		definition.Version{
+//			Name: "v1",
+//			Deprecation: &definition.Deprecation{
+//				Sunset: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+//			},
+//		},
+//		definition.Version{Name: "v2"},
+//	)
+//
+// registers the endpoint under "/v1/users/{id}" and "/v2/users/{id}".
+//
+//   - definition: The endpoint definition shared by the versions.
+//   - versions: The versions of the endpoint.
+func Versioned(
+	definition *EndpointDefinition,
+	versions ...Version,
+) []EndpointDefinition {
+	definitions := []EndpointDefinition{}
+	for _, version := range versions {
+		options := []Option{
+			WithURL(api.JoinURL("/"+version.Name, definition.URL)),
+			WithVersion(version.Name),
+			WithDeprecation(version.Deprecation),
+		}
+		definitions = append(
+			definitions,
+			*CloneEndpointDefinition(
+				definition,
+				append(options, version.Options...)...,
+			),
+		)
+	}
+	return definitions
+}
+
+// WithVersion clones an endpoint definition with the provided version.
+func WithVersion(version string) Option {
+	return func(e *EndpointDefinition) {
+		e.Version = version
+	}
+}
+
+// WithDeprecation clones an endpoint definition with the provided
+// deprecation.
+func WithDeprecation(deprecation *Deprecation) Option {
+	return func(e *EndpointDefinition) {
+		e.Deprecation = deprecation
+	}
+}
+
+// deprecationMiddleware sets the Deprecation, Sunset and Link headers of the
+// responses of a deprecated endpoint.
+func deprecationMiddleware(deprecation Deprecation) api.Middleware {
+	value := "true"
+	if !deprecation.Date.IsZero() {
+		value = "@" + strconv.FormatInt(deprecation.Date.Unix(), 10)
+	}
+	var sunset string
+	if !deprecation.Sunset.IsZero() {
+		sunset = deprecation.Sunset.UTC().Format(http.TimeFormat)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Deprecation", value)
+			if sunset != "" {
+				w.Header().Set("Sunset", sunset)
+			}
+			if deprecation.Link != "" {
+				w.Header().Add(
+					"Link",
+					fmt.Sprintf(`<%s>; rel="deprecation"`, deprecation.Link),
+				)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// AnnotateSpec adds the version information of the endpoint definitions to
+// the operations of an OpenAPI spec, so that it can be served with
+// DocsEndpoint. The version is set as "x-api-version", deprecated operations
+// are marked "deprecated" and their sunset dates are set as "x-sunset".
+// Operations are matched by the URL and method of the definitions.
+//
+//   - spec: The OpenAPI spec in JSON.
+//   - definitions: The endpoint definitions.
+func AnnotateSpec(
+	spec []byte,
+	definitions []EndpointDefinition,
+) ([]byte, error) {
+	var document map[string]any
+	if err := json.Unmarshal(spec, &document); err != nil {
+		return nil, fmt.Errorf("invalid OpenAPI spec: %w", err)
+	}
+	paths, _ := document["paths"].(map[string]any)
+
+	for _, definition := range definitions {
+		if definition.Version == "" && definition.Deprecation == nil {
+			continue
+		}
+		path, ok := paths[definition.URL].(map[string]any)
+		if !ok {
+			continue
+		}
+		for method, operation := range path {
+			operation, ok := operation.(map[string]any)
+			if !ok || (definition.Method != "" &&
+				!strings.EqualFold(method, definition.Method)) {
+				continue
+			}
+			annotateOperation(operation, definition)
+		}
+	}
+
+	return json.Marshal(document)
+}
+
+func annotateOperation(
+	operation map[string]any,
+	definition EndpointDefinition,
+) {
+	if definition.Version != "" {
+		operation["x-api-version"] = definition.Version
+	}
+	if definition.Deprecation == nil {
+		return
+	}
+	operation["deprecated"] = true
+	if !definition.Deprecation.Sunset.IsZero() {
+		operation["x-sunset"] = definition.Deprecation.Sunset.
+			UTC().Format(time.DateOnly)
+	}
+}
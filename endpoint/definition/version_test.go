@@ -0,0 +1,152 @@
+package definition
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/pakkasys/fluidapi/core/api"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestVersioned tests registering an endpoint under multiple versions.
+func TestVersioned(t *testing.T) {
+	deprecation := &Deprecation{
+		Sunset: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	definition := &EndpointDefinition{
+		URL:    "/users/{id}",
+		Method: http.MethodGet,
+	}
+
+	definitions := Versioned(
+		definition,
+		Version{Name: "v1", Deprecation: deprecation},
+		Version{
+			Name:    "v2",
+			Options: []Option{WithPermissions("users:read")},
+		},
+	)
+
+	assert.Equal(t, []EndpointDefinition{
+		{
+			URL:         "/v1/users/{id}",
+			Method:      http.MethodGet,
+			Version:     "v1",
+			Deprecation: deprecation,
+		},
+		{
+			URL:         "/v2/users/{id}",
+			Method:      http.MethodGet,
+			Version:     "v2",
+			Permissions: []string{"users:read"},
+		},
+	}, definitions)
+	assert.Equal(t, "/users/{id}", definition.URL)
+}
+
+// TestEndpointDefinitionsToAPIEndpoints_Deprecation tests the deprecation
+// headers of deprecated endpoints.
+func TestEndpointDefinitionsToAPIEndpoints_Deprecation(t *testing.T) {
+	endpoints, err := EndpointDefinitionsToAPIEndpoints([]EndpointDefinition{
+		{
+			URL:    "/v1/users",
+			Method: http.MethodGet,
+			Deprecation: &Deprecation{
+				Date:   time.Unix(1700000000, 0),
+				Sunset: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+				Link:   "https://example.com/migrate",
+			},
+		},
+		{
+			URL:         "/v0/users",
+			Method:      http.MethodGet,
+			Deprecation: &Deprecation{},
+		},
+	})
+	assert.NoError(t, err)
+
+	w := serveEndpoint(endpoints[0])
+	assert.Equal(t, "@1700000000", w.Header().Get("Deprecation"))
+	assert.Equal(t, "Wed, 01 Jan 2025 00:00:00 GMT", w.Header().Get("Sunset"))
+	assert.Equal(
+		t,
+		`<https://example.com/migrate>; rel="deprecation"`,
+		w.Header().Get("Link"),
+	)
+
+	w = serveEndpoint(endpoints[1])
+	assert.Equal(t, "true", w.Header().Get("Deprecation"))
+	assert.Empty(t, w.Header().Get("Sunset"))
+	assert.Empty(t, w.Header().Get("Link"))
+}
+
+// TestAnnotateSpec tests adding the version information to an OpenAPI spec.
+func TestAnnotateSpec(t *testing.T) {
+	spec := []byte(`{
+		"openapi": "3.0.0",
+		"paths": {
+			"/v1/users": {
+				"get": {"summary": "List"},
+				"post": {"summary": "Create"},
+				"parameters": []
+			},
+			"/v2/users": {"get": {"summary": "List"}},
+			"/health": {"get": {"summary": "Health"}}
+		}
+	}`)
+
+	annotated, err := AnnotateSpec(spec, []EndpointDefinition{
+		{
+			URL:     "/v1/users",
+			Method:  http.MethodGet,
+			Version: "v1",
+			Deprecation: &Deprecation{
+				Sunset: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{URL: "/v2/users", Method: http.MethodGet, Version: "v2"},
+		{URL: "/v3/users", Method: http.MethodGet, Version: "v3"},
+		{URL: "/health", Method: http.MethodGet},
+	})
+
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"openapi": "3.0.0",
+		"paths": {
+			"/v1/users": {
+				"get": {
+					"summary": "List",
+					"x-api-version": "v1",
+					"deprecated": true,
+					"x-sunset": "2025-01-01"
+				},
+				"post": {"summary": "Create"},
+				"parameters": []
+			},
+			"/v2/users": {"get": {"summary": "List", "x-api-version": "v2"}},
+			"/health": {"get": {"summary": "Health"}}
+		}
+	}`, string(annotated))
+}
+
+// TestAnnotateSpec_InvalidSpec tests annotating an invalid spec.
+func TestAnnotateSpec_InvalidSpec(t *testing.T) {
+	_, err := AnnotateSpec([]byte("{"), nil)
+
+	assert.ErrorContains(t, err, "invalid OpenAPI spec")
+}
+
+func serveEndpoint(endpoint api.Endpoint) *httptest.ResponseRecorder {
+	var handler http.Handler = http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {},
+	)
+	for i := len(endpoint.Middlewares) - 1; i >= 0; i-- {
+		handler = endpoint.Middlewares[i](handler)
+	}
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(endpoint.Method, endpoint.URL, nil)
+	handler.ServeHTTP(w, r)
+	return w
+}
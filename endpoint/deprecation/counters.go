@@ -0,0 +1,78 @@
+package deprecation
+
+import "sync"
+
+// Usage is the usage count of an API capability.
+type Usage struct {
+	Kind       Kind   `json:"kind"`
+	Name       string `json:"name"`
+	Deprecated bool   `json:"deprecated"`
+	Count      int64  `json:"count"`
+}
+
+// Counters is an in-memory Recorder counting the usage of API capabilities.
+// It is safe for concurrent use.
+type Counters struct {
+	mu     sync.Mutex
+	usages map[Kind]map[string]*Usage
+}
+
+// NewCounters creates new usage counters.
+func NewCounters() *Counters {
+	return &Counters{
+		usages: map[Kind]map[string]*Usage{},
+	}
+}
+
+// Record increments the usage count of an API capability.
+//
+//   - kind: The kind of the capability.
+//   - name: The name of the capability.
+//   - deprecated: Whether the capability is deprecated.
+func (c *Counters) Record(kind Kind, name string, deprecated bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	usages, ok := c.usages[kind]
+	if !ok {
+		usages = map[string]*Usage{}
+		c.usages[kind] = usages
+	}
+	usage, ok := usages[name]
+	if !ok {
+		usage = &Usage{Kind: kind, Name: name}
+		usages[name] = usage
+	}
+	usage.Deprecated = deprecated
+	usage.Count++
+}
+
+// Usage returns the usage count of an API capability.
+//
+//   - kind: The kind of the capability.
+//   - name: The name of the capability.
+func (c *Counters) Usage(kind Kind, name string) Usage {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if usage, ok := c.usages[kind][name]; ok {
+		return *usage
+	}
+	return Usage{Kind: kind, Name: name}
+}
+
+// Deprecated returns the usage counts of the deprecated capabilities.
+func (c *Counters) Deprecated() []Usage {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	deprecated := []Usage{}
+	for _, usages := range c.usages {
+		for _, usage := range usages {
+			if usage.Deprecated {
+				deprecated = append(deprecated, *usage)
+			}
+		}
+	}
+	return deprecated
+}
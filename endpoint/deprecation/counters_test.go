@@ -0,0 +1,50 @@
+package deprecation
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCounters_Record tests the counting of usages.
+func TestCounters_Record(t *testing.T) {
+	counters := NewCounters()
+
+	counters.Record(KindField, "name", false)
+	counters.Record(KindField, "name", false)
+	counters.Record(KindField, "old", true)
+
+	assert.Equal(t, int64(2), counters.Usage(KindField, "name").Count)
+	assert.Equal(
+		t,
+		[]Usage{{Kind: KindField, Name: "old", Deprecated: true, Count: 1}},
+		counters.Deprecated(),
+	)
+}
+
+// TestCounters_Usage_Unknown tests the usage of an unrecorded capability.
+func TestCounters_Usage_Unknown(t *testing.T) {
+	assert.Equal(
+		t,
+		Usage{Kind: KindPredicate, Name: "x"},
+		NewCounters().Usage(KindPredicate, "x"),
+	)
+}
+
+// TestCounters_Concurrent tests that the counters are safe for concurrent use.
+func TestCounters_Concurrent(t *testing.T) {
+	counters := NewCounters()
+
+	var wg sync.WaitGroup
+	for range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			counters.Record(KindField, "name", false)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int64(50), counters.Usage(KindField, "name").Count)
+}
@@ -0,0 +1,151 @@
+// Package deprecation tracks the usage of filter fields and predicates and
+// warns clients about deprecated ones, so that API owners can remove old
+// query capabilities based on real usage data.
+package deprecation
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/pakkasys/fluidapi/endpoint/dbfield"
+	"github.com/pakkasys/fluidapi/endpoint/predicate"
+	"github.com/pakkasys/fluidapi/endpoint/selector"
+	"github.com/pakkasys/fluidapi/endpoint/util"
+)
+
+// Kind is the kind of a used API capability.
+type Kind string
+
+const (
+	KindField     Kind = "field"
+	KindPredicate Kind = "predicate"
+)
+
+// Recorder records the usage of API capabilities, e.g. as metrics counters.
+type Recorder interface {
+	Record(kind Kind, name string, deprecated bool)
+}
+
+var (
+	warningsContextKey = util.NewDataKey()
+
+	recorderMu sync.RWMutex
+	recorder   Recorder
+)
+
+type warnings struct {
+	mu       sync.Mutex
+	messages []string
+}
+
+// SetRecorder sets the recorder of the usage counters.
+//
+//   - r: The recorder, or nil to disable recording.
+func SetRecorder(r Recorder) {
+	recorderMu.Lock()
+	defer recorderMu.Unlock()
+
+	recorder = r
+}
+
+// CheckSelectors records the usage of the fields and predicates of the
+// selectors and adds a warning to the context for each deprecated field or
+// predicate used. The selectors of OR groups are checked recursively.
+//
+//   - ctx: The endpoint context.
+//   - selectors: The selectors of the request.
+//   - apiFields: A map translating API field names to database fields.
+func CheckSelectors(
+	ctx context.Context,
+	selectors []selector.Selector,
+	apiFields map[string]dbfield.DBField,
+) {
+	for _, s := range selectors {
+		if s.Predicate == predicate.OR {
+			groups, _ := s.Value.([][]selector.Selector)
+			for _, group := range groups {
+				CheckSelectors(ctx, group, apiFields)
+			}
+			continue
+		}
+
+		fieldDeprecated := apiFields[s.Field].Deprecated
+		record(KindField, s.Field, fieldDeprecated)
+		if fieldDeprecated {
+			AddWarning(ctx, fmt.Sprintf("field %q is deprecated", s.Field))
+		}
+
+		predicateDeprecated := slices.Contains(
+			s.DeprecatedPredicates,
+			s.Predicate,
+		)
+		record(
+			KindPredicate,
+			s.Field+":"+string(s.Predicate),
+			predicateDeprecated,
+		)
+		if predicateDeprecated {
+			AddWarning(ctx, fmt.Sprintf(
+				"predicate %q is deprecated for field %q",
+				s.Predicate,
+				s.Field,
+			))
+		}
+	}
+}
+
+// AddWarning adds a warning to the context. It does nothing if the context
+// was not prepared with WithWarnings.
+//
+//   - ctx: The endpoint context.
+//   - message: The warning message.
+func AddWarning(ctx context.Context, message string) {
+	w := util.GetContextValue[*warnings](ctx, warningsContextKey, nil)
+	if w == nil {
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if !slices.Contains(w.messages, message) {
+		w.messages = append(w.messages, message)
+	}
+}
+
+// Warnings returns the warnings added to the context.
+//
+//   - ctx: The endpoint context.
+func Warnings(ctx context.Context) []string {
+	w := util.GetContextValue[*warnings](ctx, warningsContextKey, nil)
+	if w == nil {
+		return nil
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return slices.Clone(w.messages)
+}
+
+// WithWarnings prepares the endpoint context for collecting warnings. It does
+// nothing if the custom context is not set.
+//
+//   - ctx: The endpoint context.
+func WithWarnings(ctx context.Context) context.Context {
+	if !util.IsContextSet(ctx) {
+		return ctx
+	}
+	return util.SetContextValue(ctx, warningsContextKey, &warnings{})
+}
+
+func record(kind Kind, name string, deprecated bool) {
+	recorderMu.RLock()
+	defer recorderMu.RUnlock()
+
+	if recorder != nil {
+		recorder.Record(kind, name, deprecated)
+	}
+}
@@ -0,0 +1,132 @@
+package deprecation
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pakkasys/fluidapi/endpoint/dbfield"
+	"github.com/pakkasys/fluidapi/endpoint/predicate"
+	"github.com/pakkasys/fluidapi/endpoint/selector"
+	"github.com/pakkasys/fluidapi/endpoint/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func setTestRecorder(t *testing.T) *Counters {
+	counters := NewCounters()
+	SetRecorder(counters)
+	t.Cleanup(func() { SetRecorder(nil) })
+	return counters
+}
+
+// TestCheckSelectors_DeprecatedField tests that a deprecated field is counted
+// and a warning is added.
+func TestCheckSelectors_DeprecatedField(t *testing.T) {
+	counters := setTestRecorder(t)
+	ctx := WithWarnings(util.NewContext(context.Background()))
+
+	CheckSelectors(
+		ctx,
+		[]selector.Selector{{Field: "old_name", Predicate: predicate.EQUAL}},
+		map[string]dbfield.DBField{
+			"old_name": {Table: "users", Column: "name", Deprecated: true},
+		},
+	)
+
+	assert.Equal(t, []string{`field "old_name" is deprecated`}, Warnings(ctx))
+	assert.Equal(
+		t,
+		Usage{Kind: KindField, Name: "old_name", Deprecated: true, Count: 1},
+		counters.Usage(KindField, "old_name"),
+	)
+}
+
+// TestCheckSelectors_DeprecatedPredicate tests that a deprecated predicate is
+// counted and a warning is added.
+func TestCheckSelectors_DeprecatedPredicate(t *testing.T) {
+	counters := setTestRecorder(t)
+	ctx := WithWarnings(util.NewContext(context.Background()))
+
+	CheckSelectors(
+		ctx,
+		[]selector.Selector{{
+			Field:                "name",
+			Predicate:            predicate.EQUAL_SHORT,
+			DeprecatedPredicates: []predicate.Predicate{predicate.EQUAL_SHORT},
+		}},
+		map[string]dbfield.DBField{"name": {Table: "users", Column: "name"}},
+	)
+
+	assert.Equal(
+		t,
+		[]string{`predicate "EQ" is deprecated for field "name"`},
+		Warnings(ctx),
+	)
+	assert.False(t, counters.Usage(KindField, "name").Deprecated)
+	assert.Equal(t, int64(1), counters.Usage(KindField, "name").Count)
+	assert.True(t, counters.Usage(KindPredicate, "name:EQ").Deprecated)
+}
+
+// TestCheckSelectors_OrGroups tests that the selectors of OR groups are
+// checked and the OR selector itself is not counted.
+func TestCheckSelectors_OrGroups(t *testing.T) {
+	counters := setTestRecorder(t)
+	ctx := WithWarnings(util.NewContext(context.Background()))
+
+	CheckSelectors(
+		ctx,
+		[]selector.Selector{{
+			Predicate: predicate.OR,
+			Value: [][]selector.Selector{
+				{{Field: "name", Predicate: predicate.EQUAL}},
+				{{Field: "old_name", Predicate: predicate.EQUAL}},
+			},
+		}},
+		map[string]dbfield.DBField{
+			"name":     {Table: "users", Column: "name"},
+			"old_name": {Table: "users", Column: "name", Deprecated: true},
+		},
+	)
+
+	assert.Equal(t, []string{`field "old_name" is deprecated`}, Warnings(ctx))
+	assert.Equal(t, int64(1), counters.Usage(KindField, "name").Count)
+	assert.Equal(t, int64(0), counters.Usage(KindField, "").Count)
+}
+
+// TestCheckSelectors_NotDeprecated tests that usage is counted without
+// warnings when nothing is deprecated.
+func TestCheckSelectors_NotDeprecated(t *testing.T) {
+	counters := setTestRecorder(t)
+	ctx := WithWarnings(util.NewContext(context.Background()))
+
+	selectors := []selector.Selector{
+		{Field: "name", Predicate: predicate.EQUAL},
+	}
+	fields := map[string]dbfield.DBField{"name": {Column: "name"}}
+	CheckSelectors(ctx, selectors, fields)
+	CheckSelectors(ctx, selectors, fields)
+
+	assert.Empty(t, Warnings(ctx))
+	assert.Equal(t, int64(2), counters.Usage(KindPredicate, "name:=").Count)
+	assert.Empty(t, counters.Deprecated())
+}
+
+// TestAddWarning_Deduplicates tests that the same warning is added once.
+func TestAddWarning_Deduplicates(t *testing.T) {
+	ctx := WithWarnings(util.NewContext(context.Background()))
+
+	AddWarning(ctx, "a")
+	AddWarning(ctx, "a")
+	AddWarning(ctx, "b")
+
+	assert.Equal(t, []string{"a", "b"}, Warnings(ctx))
+}
+
+// TestAddWarning_NoContext tests that warnings are ignored when the context
+// is not prepared.
+func TestAddWarning_NoContext(t *testing.T) {
+	ctx := WithWarnings(context.Background())
+
+	AddWarning(ctx, "a")
+
+	assert.Nil(t, Warnings(ctx))
+}
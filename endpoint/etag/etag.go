@@ -0,0 +1,86 @@
+// Package etag provides entity tags and the evaluation of the conditional
+// request headers If-None-Match and If-Match.
+package etag
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// FromBytes returns a strong entity tag derived from the content, e.g. the
+// body of a response.
+//
+//   - content: The content.
+func FromBytes(content []byte) string {
+	sum := sha256.Sum256(content)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// FromVersion returns a strong entity tag derived from the version of an
+// entity, e.g. a version number or an updated_at timestamp.
+//
+//   - version: The version of the entity.
+func FromVersion(version any) string {
+	return FromBytes([]byte(fmt.Sprint(version)))
+}
+
+// Weak returns the weak form of the entity tag.
+//
+//   - tag: The entity tag.
+func Weak(tag string) string {
+	if strings.HasPrefix(tag, "W/") {
+		return tag
+	}
+	return "W/" + tag
+}
+
+// MatchesNoneMatch returns whether the If-None-Match header matches the
+// entity tag using the weak comparison, in which case a GET request is
+// answered with 304 Not Modified.
+//
+//   - header: The value of the If-None-Match header.
+//   - tag: The current entity tag.
+func MatchesNoneMatch(header string, tag string) bool {
+	return matches(header, tag, false)
+}
+
+// MatchesIfMatch returns whether the If-Match header matches the entity tag
+// using the strong comparison. If it does not, the request fails with 412
+// Precondition Failed. An empty header always matches.
+//
+//   - header: The value of the If-Match header.
+//   - tag: The current entity tag.
+func MatchesIfMatch(header string, tag string) bool {
+	if strings.TrimSpace(header) == "" {
+		return true
+	}
+	return matches(header, tag, true)
+}
+
+func matches(header string, tag string, strong bool) bool {
+	if tag == "" {
+		return false
+	}
+	if strong && strings.HasPrefix(tag, "W/") {
+		return false
+	}
+	weakTag := strings.TrimPrefix(tag, "W/")
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" {
+			return true
+		}
+		if strong {
+			if candidate == tag {
+				return true
+			}
+			continue
+		}
+		if strings.TrimPrefix(candidate, "W/") == weakTag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,76 @@
+package etag
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestFromBytes tests deriving entity tags from content.
+func TestFromBytes(t *testing.T) {
+	tag := FromBytes([]byte("content"))
+
+	assert.Len(t, tag, 34)
+	assert.Equal(t, `"`, tag[:1])
+	assert.Equal(t, tag, FromBytes([]byte("content")))
+	assert.NotEqual(t, tag, FromBytes([]byte("other")))
+}
+
+// TestFromVersion tests deriving entity tags from versions.
+func TestFromVersion(t *testing.T) {
+	assert.Equal(t, FromBytes([]byte("3")), FromVersion(3))
+	assert.NotEqual(t, FromVersion(3), FromVersion(4))
+}
+
+// TestWeak tests the weak form of entity tags.
+func TestWeak(t *testing.T) {
+	assert.Equal(t, `W/"a"`, Weak(`"a"`))
+	assert.Equal(t, `W/"a"`, Weak(`W/"a"`))
+}
+
+// TestMatchesNoneMatch tests the weak comparison of If-None-Match.
+func TestMatchesNoneMatch(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		tag    string
+		want   bool
+	}{
+		{"Equal", `"a"`, `"a"`, true},
+		{"List", `"b", "a"`, `"a"`, true},
+		{"Weak header", `W/"a"`, `"a"`, true},
+		{"Weak tag", `"a"`, `W/"a"`, true},
+		{"Any", `*`, `"a"`, true},
+		{"Different", `"b"`, `"a"`, false},
+		{"Empty header", ``, `"a"`, false},
+		{"Empty tag", `*`, ``, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, MatchesNoneMatch(tt.header, tt.tag))
+		})
+	}
+}
+
+// TestMatchesIfMatch tests the strong comparison of If-Match.
+func TestMatchesIfMatch(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		tag    string
+		want   bool
+	}{
+		{"Equal", `"a"`, `"a"`, true},
+		{"List", `"b", "a"`, `"a"`, true},
+		{"Any", `*`, `"a"`, true},
+		{"Empty header", ` `, `"a"`, true},
+		{"Weak header", `W/"a"`, `"a"`, false},
+		{"Weak tag", `"a"`, `W/"a"`, false},
+		{"Different", `"b"`, `"a"`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, MatchesIfMatch(tt.header, tt.tag))
+		})
+	}
+}
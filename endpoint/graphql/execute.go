@@ -0,0 +1,447 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/pakkasys/fluidapi/core/api"
+	"github.com/pakkasys/fluidapi/endpoint/middleware/inputlogic"
+)
+
+const (
+	defaultMaxRootFields = 10
+	defaultMaxBodySize   = 1 << 20
+)
+
+// Request is a GraphQL request, e.g. the JSON body of a POST request.
+type Request struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName,omitempty"`
+	Variables     map[string]any `json:"variables,omitempty"`
+}
+
+// Response is a GraphQL response. The data is omitted if the document could
+// not be executed.
+type Response struct {
+	Data   any     `json:"data,omitempty"`
+	Errors []Error `json:"errors,omitempty"`
+}
+
+// Error is an error of a GraphQL response. The extensions are the API error
+// returned by the error handler.
+type Error struct {
+	Message string `json:"message"`
+	// Path is the response key of the failed field
+	Path       []any           `json:"path,omitempty"`
+	Extensions *api.Error[any] `json:"extensions,omitempty"`
+}
+
+// object is an object of a response. Its fields are encoded in the order of
+// the selections.
+type object struct {
+	keys   []string
+	values map[string]any
+}
+
+func (o *object) set(key string, value any) {
+	if o.values == nil {
+		o.values = map[string]any{}
+	}
+	if _, ok := o.values[key]; !ok {
+		o.keys = append(o.keys, key)
+	}
+	o.values[key] = value
+}
+
+// MarshalJSON encodes the fields of the object in order.
+func (o *object) MarshalJSON() ([]byte, error) {
+	var buffer bytes.Buffer
+	buffer.WriteByte('{')
+	for i, key := range o.keys {
+		if i > 0 {
+			buffer.WriteByte(',')
+		}
+		encodedKey, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		encodedValue, err := json.Marshal(o.values[key])
+		if err != nil {
+			return nil, err
+		}
+		buffer.Write(encodedKey)
+		buffer.WriteByte(':')
+		buffer.Write(encodedValue)
+	}
+	buffer.WriteByte('}')
+	return buffer.Bytes(), nil
+}
+
+// Execute executes an operation of a request. The root fields are resolved
+// in order, and the data of a failed field is null. Errors of the document
+// and the variables, and operations with more than MaxRootFields root fields
+// are returned without data.
+//
+//   - ctx: The context passed to the service functions.
+//   - request: The request.
+func (s *Schema) Execute(ctx context.Context, request Request) *Response {
+	return s.execute(ctx, request, true)
+}
+
+// ServeHTTP serves GraphQL requests. POST requests have a JSON body of at
+// most MaxBodySize bytes and GET requests the query, operationName and
+// variables query parameters. Mutations are only executed in POST requests.
+// The response status is 200 OK if the document was executed, even if fields
+// failed.
+func (s *Schema) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var request Request
+	switch r.Method {
+	case http.MethodPost:
+		maxBodySize := s.MaxBodySize
+		if maxBodySize <= 0 {
+			maxBodySize = defaultMaxBodySize
+		}
+		body := http.MaxBytesReader(w, r.Body, maxBodySize)
+		if err := json.NewDecoder(body).Decode(&request); err != nil {
+			s.writeResponse(w, http.StatusBadRequest, &Response{
+				Errors: []Error{s.toError(invalidQueryError(
+					"invalid request body: "+err.Error(),
+				), nil)},
+			})
+			return
+		}
+	case http.MethodGet:
+		query := r.URL.Query()
+		request.Query = query.Get("query")
+		request.OperationName = query.Get("operationName")
+		if variables := query.Get("variables"); variables != "" {
+			err := json.Unmarshal([]byte(variables), &request.Variables)
+			if err != nil {
+				s.writeResponse(w, http.StatusBadRequest, &Response{
+					Errors: []Error{s.toError(invalidQueryError(
+						"invalid variables: "+err.Error(),
+					), nil)},
+				})
+				return
+			}
+		}
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		http.Error(
+			w,
+			http.StatusText(http.StatusMethodNotAllowed),
+			http.StatusMethodNotAllowed,
+		)
+		return
+	}
+
+	response := s.execute(r.Context(), request, r.Method == http.MethodPost)
+	status := http.StatusOK
+	if response.Data == nil {
+		status = http.StatusBadRequest
+	}
+	s.writeResponse(w, status, response)
+}
+
+func (s *Schema) writeResponse(
+	w http.ResponseWriter,
+	status int,
+	response *Response,
+) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(response)
+}
+
+func (s *Schema) execute(
+	ctx context.Context,
+	request Request,
+	allowMutations bool,
+) *Response {
+	doc, err := parseDocument(request.Query)
+	if err != nil {
+		return &Response{Errors: []Error{s.toError(err, nil)}}
+	}
+	op, err := selectOperation(doc, request.OperationName)
+	if err != nil {
+		return &Response{Errors: []Error{s.toError(err, nil)}}
+	}
+	if op.kind == "mutation" && !allowMutations {
+		return &Response{Errors: []Error{s.toError(invalidQueryError(
+			"mutations are not allowed in GET requests",
+		), nil)}}
+	}
+	variables, err := coerceVariables(op.variables, request.Variables)
+	if err != nil {
+		return &Response{Errors: []Error{s.toError(err, nil)}}
+	}
+
+	maxRootFields := s.MaxRootFields
+	if maxRootFields <= 0 {
+		maxRootFields = defaultMaxRootFields
+	}
+	rootFields := 0
+	for _, sel := range op.selections {
+		if sel.name != "__typename" {
+			rootFields++
+		}
+	}
+	if rootFields > maxRootFields {
+		return &Response{Errors: []Error{s.toError(invalidQueryError(
+			fmt.Sprintf("too many root fields, max %d", maxRootFields),
+		), nil)}}
+	}
+
+	typeName := "Query"
+	fields := s.queries
+	if op.kind == "mutation" {
+		typeName = "Mutation"
+		fields = s.mutations
+	}
+
+	// The fields are validated before any of them is resolved, so a
+	// mutation is not run partially because of an invalid document.
+	arguments := make([]map[string]any, len(op.selections))
+	for i, sel := range op.selections {
+		if sel.name == "__typename" {
+			continue
+		}
+		field, ok := fields[sel.name]
+		if !ok {
+			return &Response{Errors: []Error{s.toError(invalidQueryError(
+				fmt.Sprintf("unknown field %q on type %q", sel.name, typeName),
+			), nil)}}
+		}
+		fieldArguments, err := coerceArguments(sel, field, variables)
+		if err != nil {
+			return &Response{Errors: []Error{s.toError(err, nil)}}
+		}
+		arguments[i] = fieldArguments
+	}
+
+	response := &Response{}
+	data := &object{}
+	for i, sel := range op.selections {
+		key := sel.responseKey()
+		if sel.name == "__typename" {
+			data.set(key, typeName)
+			continue
+		}
+		field := fields[sel.name]
+		value, err := field.resolve(ctx, arguments[i], sel.selections)
+		if err != nil {
+			data.set(key, nil)
+			response.Errors = append(
+				response.Errors,
+				s.toError(err, []any{key}),
+			)
+			continue
+		}
+		data.set(key, value)
+	}
+	response.Data = data
+	return response
+}
+
+// toError maps an error to an error of the response with the error handler.
+func (s *Schema) toError(err error, path []any) Error {
+	expectedErrors := append(
+		append([]inputlogic.ExpectedError{}, s.ExpectedErrors...),
+		Errors...,
+	)
+	_, apiError := s.ErrorHandler.Handle(err, expectedErrors)
+	if apiError.Data != nil && isNil(*apiError.Data) {
+		apiError.Data = nil
+	}
+
+	message := apiError.ID
+	if apiError.Message != nil {
+		message = *apiError.Message
+	}
+	return Error{Message: message, Path: path, Extensions: apiError}
+}
+
+func isNil(value any) bool {
+	if value == nil {
+		return true
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Slice, reflect.Interface:
+		return v.IsNil()
+	}
+	return false
+}
+
+// selectOperation returns the operation of the request. The operation name
+// may be omitted if the document has a single operation.
+func selectOperation(doc *document, name string) (*operation, error) {
+	if name == "" {
+		if len(doc.operations) > 1 {
+			return nil, invalidQueryError(
+				"operation name is required for multiple operations",
+			)
+		}
+		return doc.operations[0], nil
+	}
+
+	for _, op := range doc.operations {
+		if op.name == name {
+			return op, nil
+		}
+	}
+	return nil, invalidQueryError(fmt.Sprintf("unknown operation %q", name))
+}
+
+// coerceVariables returns the values of the variables of an operation,
+// falling back to their default values.
+func coerceVariables(
+	definitions []variableDefinition,
+	values map[string]any,
+) (map[string]any, error) {
+	variables := map[string]any{}
+	for _, definition := range definitions {
+		value, ok := values[definition.name]
+		if !ok && definition.hasDefault {
+			value = definition.defaultValue
+		}
+		if value == nil && definition.nonNull {
+			return nil, invalidQueryError(fmt.Sprintf(
+				"variable %q of a non-null type is missing",
+				definition.name,
+			))
+		}
+		variables[definition.name] = value
+	}
+	return variables, nil
+}
+
+// coerceArguments resolves the variables of the arguments of a root field
+// and coerces the arguments to their types.
+func coerceArguments(
+	sel *selection,
+	field *rootField,
+	variables map[string]any,
+) (map[string]any, error) {
+	for name := range sel.arguments {
+		if !hasArgument(field, name) {
+			return nil, invalidQueryError(fmt.Sprintf(
+				"unknown argument %q on field %q",
+				name,
+				sel.name,
+			))
+		}
+	}
+
+	arguments := map[string]any{}
+	for _, a := range field.arguments {
+		value, err := resolveVariables(sel.arguments[a.name], variables)
+		if err != nil {
+			return nil, err
+		}
+		kind, nonNull := strings.CutSuffix(a.kind, "!")
+		if value == nil {
+			if nonNull {
+				return nil, invalidQueryError(fmt.Sprintf(
+					"argument %q of field %q is required",
+					a.name,
+					sel.name,
+				))
+			}
+			continue
+		}
+
+		coerced, ok := coerceValue(value, kind)
+		if !ok {
+			return nil, invalidQueryError(fmt.Sprintf(
+				"argument %q of field %q must be of type %s",
+				a.name,
+				sel.name,
+				a.kind,
+			))
+		}
+		arguments[a.name] = coerced
+	}
+	return arguments, nil
+}
+
+func hasArgument(field *rootField, name string) bool {
+	for _, a := range field.arguments {
+		if a.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveVariables replaces the variables of a value with their values.
+func resolveVariables(value any, variables map[string]any) (any, error) {
+	switch v := value.(type) {
+	case variable:
+		resolved, ok := variables[string(v)]
+		if !ok {
+			return nil, invalidQueryError(
+				fmt.Sprintf("variable %q is not defined", string(v)),
+			)
+		}
+		return resolved, nil
+	case []any:
+		list := make([]any, len(v))
+		for i, item := range v {
+			resolved, err := resolveVariables(item, variables)
+			if err != nil {
+				return nil, err
+			}
+			list[i] = resolved
+		}
+		return list, nil
+	case map[string]any:
+		fields := make(map[string]any, len(v))
+		for name, item := range v {
+			resolved, err := resolveVariables(item, variables)
+			if err != nil {
+				return nil, err
+			}
+			fields[name] = resolved
+		}
+		return fields, nil
+	}
+	return value, nil
+}
+
+// coerceValue coerces a value to a type: String, Int or an input object.
+// The integers of the variables are decoded from JSON as floats.
+func coerceValue(value any, kind string) (any, bool) {
+	switch kind {
+	case "String":
+		s, ok := value.(string)
+		return s, ok
+	case "Int":
+		switch v := value.(type) {
+		case int64:
+			return v, true
+		case float64:
+			if v == math.Trunc(v) && math.Abs(v) <= math.MaxInt32 {
+				return int64(v), true
+			}
+		}
+		return nil, false
+	default:
+		fields, ok := value.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		for name, field := range fields {
+			if enum, ok := field.(enumValue); ok {
+				fields[name] = string(enum)
+			}
+		}
+		return fields, true
+	}
+}
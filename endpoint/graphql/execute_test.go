@@ -0,0 +1,405 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/pakkasys/fluidapi/database/entity"
+	"github.com/pakkasys/fluidapi/database/util"
+	"github.com/pakkasys/fluidapi/endpoint/page"
+	"github.com/pakkasys/fluidapi/endpoint/runner"
+	"github.com/stretchr/testify/assert"
+)
+
+func executeJSON(t *testing.T, schema *Schema, request Request) string {
+	data, err := json.Marshal(schema.Execute(context.Background(), request))
+	assert.NoError(t, err)
+	return string(data)
+}
+
+// TestSchema_Execute_List tests translating the arguments of a list query to
+// the options of the service and selecting the requested fields.
+func TestSchema_Execute_List(t *testing.T) {
+	schema, services := testSchema()
+
+	response := executeJSON(t, schema, Request{
+		Query: `query($limit: Int) {
+			__typename
+			users(filter: "id>0", sort: "-name", limit: $limit) {
+				name
+				userID: id
+				__typename
+			}
+		}`,
+		Variables: map[string]any{"limit": 2.0},
+	})
+
+	assert.JSONEq(t, `{"data": {
+		"__typename": "Query",
+		"users": [
+			{"name": "alice", "userID": 1, "__typename": "User"},
+			{"name": "bob", "userID": 2, "__typename": "User"}
+		]
+	}}`, response)
+	assert.True(t, strings.Index(response, `"name"`) <
+		strings.Index(response, `"userID"`))
+	assert.Equal(t, entity.GetOptions{Options: entity.Options{
+		Selectors: []util.Selector{{
+			Table:     "users",
+			Field:     "id",
+			Predicate: util.GREATER,
+			Value:     int64(0),
+		}},
+		Orders: []util.Order{{
+			Table:     "users",
+			Field:     "name",
+			Direction: util.OrderDesc,
+		}},
+		Page: &page.Page{Limit: 2},
+		Projections: []util.Projection{
+			{Table: "users", Column: "name"},
+			{Table: "users", Column: "id"},
+		},
+	}}, services.getOptions)
+}
+
+// TestSchema_Execute_Count tests counting the filtered entities.
+func TestSchema_Execute_Count(t *testing.T) {
+	schema, services := testSchema()
+
+	response := executeJSON(t, schema, Request{
+		Query: `{ userCount(filter: "name=\"alice\"") }`,
+	})
+
+	assert.JSONEq(t, `{"data": {"userCount": 2}}`, response)
+	assert.Equal(t, []util.Selector{{
+		Table:     "users",
+		Field:     "name",
+		Predicate: util.EQUAL,
+		Value:     "alice",
+	}}, services.selectors)
+}
+
+// TestSchema_Execute_Mutations tests the create, update and delete
+// mutations of an operation selected by name.
+func TestSchema_Execute_Mutations(t *testing.T) {
+	schema, services := testSchema()
+
+	response := executeJSON(t, schema, Request{
+		Query: `
+			query Users { users { id } }
+			mutation Create($name: String!) {
+				createUser(input: {name: $name, active: true}) { id name }
+			}
+		`,
+		OperationName: "Create",
+		Variables:     map[string]any{"name": "carol"},
+	})
+
+	assert.JSONEq(t, `{"data": {"createUser": {"id": 3, "name": "carol"}}}`,
+		response)
+	assert.Equal(t, &testUser{Name: "carol", Active: true}, services.created)
+
+	response = executeJSON(t, schema, Request{
+		Query: `mutation {
+			updateUsers(filter: "id=1", set: {name: "dave", active: false})
+		}`,
+	})
+
+	assert.JSONEq(t, `{"data": {"updateUsers": 1}}`, response)
+	assert.Equal(t, []entity.Update{
+		{Field: "active", Value: false},
+		{Field: "name", Value: "dave"},
+	}, services.updates)
+
+	response = executeJSON(t, schema, Request{
+		Query: `mutation { deleted: deleteUsers(filter: "id=2") }`,
+	})
+
+	assert.JSONEq(t, `{"data": {"deleted": 2}}`, response)
+	assert.Equal(t, []util.Selector{{
+		Table:     "users",
+		Field:     "id",
+		Predicate: util.EQUAL,
+		Value:     int64(2),
+	}}, services.selectors)
+}
+
+// TestSchema_Execute_FieldError tests that a failed field is null and its
+// error is mapped with the expected errors.
+func TestSchema_Execute_FieldError(t *testing.T) {
+	schema, _ := testSchema()
+	schema.ExpectedErrors = runner.GetErrors
+
+	response := executeJSON(t, schema, Request{
+		Query: `{
+			count: userCount
+			users(filter: "name>\"a\"") { id }
+		}`,
+	})
+
+	assert.JSONEq(t, `{
+		"data": {"count": 2, "users": null},
+		"errors": [{
+			"message": "PREDICATE_NOT_ALLOWED",
+			"path": ["users"],
+			"extensions": {
+				"id": "PREDICATE_NOT_ALLOWED",
+				"data": {"predicate": ">"}
+			}
+		}]
+	}`, response)
+}
+
+// TestSchema_Execute_InternalError tests that unexpected errors are masked.
+func TestSchema_Execute_InternalError(t *testing.T) {
+	schema := NewSchema()
+	Register(schema, Entity[testUser]{
+		Type:       "User",
+		APIFields:  testAPIFields,
+		CountQuery: "userCount",
+		GetCountFn: func(
+			ctx context.Context,
+			selectors []util.Selector,
+			joins []util.Join,
+		) (int, error) {
+			return 0, assert.AnError
+		},
+	})
+
+	response := executeJSON(t, schema, Request{Query: "{ userCount }"})
+
+	assert.JSONEq(t, `{
+		"data": {"userCount": null},
+		"errors": [{
+			"message": "INTERNAL_SERVER_ERROR",
+			"path": ["userCount"],
+			"extensions": {"id": "INTERNAL_SERVER_ERROR"}
+		}]
+	}`, response)
+}
+
+// TestSchema_Execute_InvalidQuery tests the errors of invalid documents,
+// which are returned without data.
+func TestSchema_Execute_InvalidQuery(t *testing.T) {
+	tests := []struct {
+		name     string
+		request  Request
+		expected string
+	}{
+		{
+			name:     "unknown root field",
+			request:  Request{Query: "{ posts { id } }"},
+			expected: `unknown field "posts" on type "Query"`,
+		},
+		{
+			name:     "unknown argument",
+			request:  Request{Query: "{ users(first: 1) { id } }"},
+			expected: `unknown argument "first" on field "users"`,
+		},
+		{
+			name:     "missing argument",
+			request:  Request{Query: "mutation { deleteUsers }"},
+			expected: `argument "filter" of field "deleteUsers" is required`,
+		},
+		{
+			name:     "argument type",
+			request:  Request{Query: "{ users(limit: \"1\") { id } }"},
+			expected: `argument "limit" of field "users" must be of type Int`,
+		},
+		{
+			name:     "undefined variable",
+			request:  Request{Query: "{ users(limit: $limit) { id } }"},
+			expected: `variable "limit" is not defined`,
+		},
+		{
+			name: "missing variable",
+			request: Request{
+				Query: "query($f: String!) { userCount(filter: $f) }",
+			},
+			expected: `variable "f" of a non-null type is missing`,
+		},
+		{
+			name: "operation name required",
+			request: Request{
+				Query: "query A { userCount } query B { userCount }",
+			},
+			expected: "operation name is required for multiple operations",
+		},
+		{
+			name: "unknown operation",
+			request: Request{
+				Query:         "query A { userCount }",
+				OperationName: "B",
+			},
+			expected: `unknown operation "B"`,
+		},
+		{
+			name: "too many root fields",
+			request: Request{
+				Query: "{ __typename " +
+					strings.Repeat("userCount ", 11) + "}",
+			},
+			expected: "too many root fields, max 10",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			schema, _ := testSchema()
+
+			response := schema.Execute(context.Background(), test.request)
+
+			assert.Nil(t, response.Data)
+			assert.Len(t, response.Errors, 1)
+			assert.Equal(t, InvalidQueryError.ID, response.Errors[0].Message)
+			assert.Equal(
+				t,
+				&InvalidQueryErrorData{Message: test.expected},
+				*response.Errors[0].Extensions.Data,
+			)
+		})
+	}
+}
+
+// TestSchema_Execute_InvalidSelection tests the errors of the selections of
+// entities, which fail the field.
+func TestSchema_Execute_InvalidSelection(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		expected string
+	}{
+		{
+			name:     "no selection",
+			query:    "{ users }",
+			expected: `type "User" must have a selection of fields`,
+		},
+		{
+			name:     "unknown field",
+			query:    "{ users { id secret } }",
+			expected: `unknown field "secret" on type "User"`,
+		},
+		{
+			name:     "scalar selection",
+			query:    "{ users { name { first } } }",
+			expected: `field "name" of type "User" is a scalar`,
+		},
+		{
+			name:     "unknown input field",
+			query:    `mutation { createUser(input: {secret: "x"}) { id } }`,
+			expected: `unknown field "secret" on input type "UserInput"`,
+		},
+		{
+			name:     "negative limit",
+			query:    "{ users(limit: -1) { id } }",
+			expected: "limit and offset must not be negative",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			schema, _ := testSchema()
+
+			response := schema.Execute(
+				context.Background(),
+				Request{Query: test.query},
+			)
+
+			assert.NotNil(t, response.Data)
+			assert.Len(t, response.Errors, 1)
+			assert.Equal(
+				t,
+				&InvalidQueryErrorData{Message: test.expected},
+				*response.Errors[0].Extensions.Data,
+			)
+		})
+	}
+}
+
+// TestSchema_ServeHTTP tests serving queries over GET and POST requests.
+func TestSchema_ServeHTTP(t *testing.T) {
+	schema, _ := testSchema()
+
+	recorder := httptest.NewRecorder()
+	schema.ServeHTTP(recorder, httptest.NewRequest(
+		http.MethodPost,
+		"/graphql",
+		strings.NewReader(`{
+			"query": "query($f: String) { userCount(filter: $f) }",
+			"variables": {"f": "id=1"}
+		}`),
+	))
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"data": {"userCount": 2}}`, recorder.Body.String())
+
+	recorder = httptest.NewRecorder()
+	schema.ServeHTTP(recorder, httptest.NewRequest(
+		http.MethodGet,
+		"/graphql?query="+url.QueryEscape("{ userCount }"),
+		nil,
+	))
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.JSONEq(t, `{"data": {"userCount": 2}}`, recorder.Body.String())
+}
+
+// TestSchema_ServeHTTP_Errors tests the responses of invalid requests.
+func TestSchema_ServeHTTP_Errors(t *testing.T) {
+	schema, _ := testSchema()
+
+	recorder := httptest.NewRecorder()
+	schema.ServeHTTP(recorder, httptest.NewRequest(
+		http.MethodGet,
+		"/graphql?query="+url.QueryEscape(
+			`mutation { deleteUsers(filter: "id=1") }`,
+		),
+		nil,
+	))
+
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.Contains(
+		t,
+		recorder.Body.String(),
+		"mutations are not allowed in GET requests",
+	)
+
+	recorder = httptest.NewRecorder()
+	schema.ServeHTTP(recorder, httptest.NewRequest(
+		http.MethodPost,
+		"/graphql",
+		strings.NewReader("{"),
+	))
+
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), InvalidQueryError.ID)
+
+	schema.MaxBodySize = 16
+	recorder = httptest.NewRecorder()
+	schema.ServeHTTP(recorder, httptest.NewRequest(
+		http.MethodPost,
+		"/graphql",
+		strings.NewReader(`{"query": "{ userCount }"}`),
+	))
+
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "request body too large")
+	schema.MaxBodySize = 0
+
+	recorder = httptest.NewRecorder()
+	schema.ServeHTTP(recorder, httptest.NewRequest(
+		http.MethodDelete,
+		"/graphql",
+		nil,
+	))
+
+	assert.Equal(t, http.StatusMethodNotAllowed, recorder.Code)
+	assert.Equal(t, "GET, POST", recorder.Header().Get("Allow"))
+}
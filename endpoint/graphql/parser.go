@@ -0,0 +1,598 @@
+package graphql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pakkasys/fluidapi/core/api"
+)
+
+type SyntaxErrorData struct {
+	Position int    `json:"position"`
+	Message  string `json:"message"`
+}
+
+var SyntaxError = api.NewError[SyntaxErrorData]("GRAPHQL_SYNTAX_ERROR")
+
+// maxSelectionDepth is the maximum nesting depth of the selection sets and
+// the values of a document.
+const maxSelectionDepth = 32
+
+// document is a parsed GraphQL document.
+type document struct {
+	operations []*operation
+}
+
+// operation is a query or a mutation of a document.
+type operation struct {
+	// kind is "query" or "mutation"
+	kind       string
+	name       string
+	variables  []variableDefinition
+	selections []*selection
+}
+
+// variableDefinition is a variable declared by an operation.
+type variableDefinition struct {
+	name         string
+	nonNull      bool
+	defaultValue any
+	hasDefault   bool
+}
+
+// selection is a field of a selection set.
+type selection struct {
+	alias      string
+	name       string
+	arguments  map[string]any
+	selections []*selection
+	position   int
+}
+
+// responseKey returns the key of the field in the response.
+func (s *selection) responseKey() string {
+	if s.alias != "" {
+		return s.alias
+	}
+	return s.name
+}
+
+// variable is a reference to a variable in a value, e.g. $limit.
+type variable string
+
+// enumValue is an enum value, e.g. ASC.
+type enumValue string
+
+// parseDocument parses the executable subset of the GraphQL query language:
+// queries and mutations with variables, aliases, arguments and nested
+// selection sets. Fragments, directives and subscriptions are not supported.
+//
+//   - source: The GraphQL document.
+func parseDocument(source string) (*document, error) {
+	tokens, err := tokenizeDocument(source)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &documentParser{tokens: tokens}
+	doc := &document{}
+	for p.peek().kind != tokenEnd {
+		op, err := p.parseOperation()
+		if err != nil {
+			return nil, err
+		}
+		doc.operations = append(doc.operations, op)
+	}
+	if len(doc.operations) == 0 {
+		return nil, syntaxError(0, "document has no operations")
+	}
+	return doc, nil
+}
+
+type tokenKind int
+
+const (
+	tokenEnd tokenKind = iota
+	tokenName
+	tokenInt
+	tokenFloat
+	tokenString
+	tokenPunctuator
+)
+
+type documentToken struct {
+	kind     tokenKind
+	text     string
+	position int
+}
+
+func tokenizeDocument(source string) ([]documentToken, error) {
+	var tokens []documentToken
+	for i := 0; i < len(source); {
+		c := source[i]
+		start := i
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ',':
+			i++
+		case c == '#':
+			for i < len(source) && source[i] != '\n' && source[i] != '\r' {
+				i++
+			}
+		case strings.HasPrefix(source[i:], "\uFEFF"):
+			i += len("\uFEFF")
+		case strings.HasPrefix(source[i:], "..."):
+			return nil, syntaxError(start, "fragments are not supported")
+		case c == '@':
+			return nil, syntaxError(start, "directives are not supported")
+		case strings.IndexByte("!$():=[]{}", c) >= 0:
+			tokens = append(tokens, documentToken{
+				tokenPunctuator,
+				string(c),
+				start,
+			})
+			i++
+		case c == '"':
+			if strings.HasPrefix(source[i:], `"""`) {
+				return nil, syntaxError(
+					start,
+					"block strings are not supported",
+				)
+			}
+			value, end, err := scanString(source, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, documentToken{tokenString, value, start})
+			i = end
+		case c == '-' || isDigit(c):
+			token, end, err := scanNumber(source, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token)
+			i = end
+		case isNameStart(c):
+			for i < len(source) && isNameContinue(source[i]) {
+				i++
+			}
+			tokens = append(tokens, documentToken{
+				tokenName,
+				source[start:i],
+				start,
+			})
+		default:
+			r, _ := utf8.DecodeRuneInString(source[i:])
+			return nil, syntaxError(start, fmt.Sprintf("unexpected %q", r))
+		}
+	}
+	return append(tokens, documentToken{tokenEnd, "", len(source)}), nil
+}
+
+// scanString scans a string in double quotes starting at i.
+func scanString(source string, i int) (string, int, error) {
+	start := i
+	var builder strings.Builder
+	for i++; i < len(source); {
+		switch c := source[i]; c {
+		case '"':
+			return builder.String(), i + 1, nil
+		case '\n', '\r':
+			return "", 0, syntaxError(start, "unterminated string")
+		case '\\':
+			if i+1 == len(source) {
+				return "", 0, syntaxError(start, "unterminated string")
+			}
+			escaped := source[i+1]
+			if escaped == 'u' {
+				if i+6 > len(source) {
+					return "", 0, syntaxError(i, "invalid unicode escape")
+				}
+				code, err := strconv.ParseUint(source[i+2:i+6], 16, 32)
+				if err != nil {
+					return "", 0, syntaxError(i, "invalid unicode escape")
+				}
+				builder.WriteRune(rune(code))
+				i += 6
+				continue
+			}
+			replacement, ok := stringEscapes[escaped]
+			if !ok {
+				return "", 0, syntaxError(i, "invalid escape sequence")
+			}
+			builder.WriteByte(replacement)
+			i += 2
+		default:
+			builder.WriteByte(c)
+			i++
+		}
+	}
+	return "", 0, syntaxError(start, "unterminated string")
+}
+
+var stringEscapes = map[byte]byte{
+	'"':  '"',
+	'\\': '\\',
+	'/':  '/',
+	'b':  '\b',
+	'f':  '\f',
+	'n':  '\n',
+	'r':  '\r',
+	't':  '\t',
+}
+
+// scanNumber scans an integer or a float starting at i.
+func scanNumber(source string, i int) (documentToken, int, error) {
+	start := i
+	kind := tokenInt
+	if source[i] == '-' {
+		i++
+	}
+	digits := i
+	for i < len(source) && isDigit(source[i]) {
+		i++
+	}
+	if i == digits {
+		return documentToken{}, 0, syntaxError(start, "invalid number")
+	}
+	if i < len(source) && source[i] == '.' {
+		kind = tokenFloat
+		i++
+		fraction := i
+		for i < len(source) && isDigit(source[i]) {
+			i++
+		}
+		if i == fraction {
+			return documentToken{}, 0, syntaxError(start, "invalid number")
+		}
+	}
+	if i < len(source) && (source[i] == 'e' || source[i] == 'E') {
+		kind = tokenFloat
+		i++
+		if i < len(source) && (source[i] == '+' || source[i] == '-') {
+			i++
+		}
+		exponent := i
+		for i < len(source) && isDigit(source[i]) {
+			i++
+		}
+		if i == exponent {
+			return documentToken{}, 0, syntaxError(start, "invalid number")
+		}
+	}
+	if i < len(source) && (isNameStart(source[i]) || source[i] == '.') {
+		return documentToken{}, 0, syntaxError(start, "invalid number")
+	}
+	return documentToken{kind, source[start:i], start}, i, nil
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isNameStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isNameContinue(c byte) bool {
+	return isNameStart(c) || isDigit(c)
+}
+
+type documentParser struct {
+	tokens   []documentToken
+	position int
+}
+
+func (p *documentParser) peek() documentToken {
+	return p.tokens[p.position]
+}
+
+func (p *documentParser) next() documentToken {
+	token := p.tokens[p.position]
+	if token.kind != tokenEnd {
+		p.position++
+	}
+	return token
+}
+
+func (p *documentParser) peekPunctuator(punctuator string) bool {
+	token := p.peek()
+	return token.kind == tokenPunctuator && token.text == punctuator
+}
+
+func (p *documentParser) expectPunctuator(punctuator string) error {
+	if !p.peekPunctuator(punctuator) {
+		return p.unexpected(fmt.Sprintf("expected %q", punctuator))
+	}
+	p.next()
+	return nil
+}
+
+func (p *documentParser) expectName() (string, error) {
+	if p.peek().kind != tokenName {
+		return "", p.unexpected("expected a name")
+	}
+	return p.next().text, nil
+}
+
+func (p *documentParser) unexpected(message string) error {
+	token := p.peek()
+	if token.kind == tokenEnd {
+		return syntaxError(token.position, message+", got end of document")
+	}
+	return syntaxError(
+		token.position,
+		fmt.Sprintf("%s, got %q", message, token.text),
+	)
+}
+
+func (p *documentParser) parseOperation() (*operation, error) {
+	if p.peekPunctuator("{") {
+		selections, err := p.parseSelectionSet(0)
+		if err != nil {
+			return nil, err
+		}
+		return &operation{kind: "query", selections: selections}, nil
+	}
+
+	token := p.peek()
+	if token.kind != tokenName ||
+		(token.text != "query" && token.text != "mutation") {
+		if token.kind == tokenName && token.text == "subscription" {
+			return nil, syntaxError(
+				token.position,
+				"subscriptions are not supported",
+			)
+		}
+		return nil, p.unexpected("expected an operation")
+	}
+	p.next()
+
+	op := &operation{kind: token.text}
+	if p.peek().kind == tokenName {
+		op.name = p.next().text
+	}
+	if p.peekPunctuator("(") {
+		variables, err := p.parseVariableDefinitions()
+		if err != nil {
+			return nil, err
+		}
+		op.variables = variables
+	}
+	selections, err := p.parseSelectionSet(0)
+	if err != nil {
+		return nil, err
+	}
+	op.selections = selections
+	return op, nil
+}
+
+func (p *documentParser) parseVariableDefinitions() (
+	[]variableDefinition,
+	error,
+) {
+	p.next()
+	var definitions []variableDefinition
+	for !p.peekPunctuator(")") {
+		if err := p.expectPunctuator("$"); err != nil {
+			return nil, err
+		}
+		name, err := p.expectName()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expectPunctuator(":"); err != nil {
+			return nil, err
+		}
+		nonNull, err := p.parseType()
+		if err != nil {
+			return nil, err
+		}
+
+		definition := variableDefinition{name: name, nonNull: nonNull}
+		if p.peekPunctuator("=") {
+			p.next()
+			value, err := p.parseValue(true, 0)
+			if err != nil {
+				return nil, err
+			}
+			definition.defaultValue = value
+			definition.hasDefault = true
+		}
+		definitions = append(definitions, definition)
+	}
+	p.next()
+	return definitions, nil
+}
+
+// parseType parses a type reference and returns whether it is non-null.
+func (p *documentParser) parseType() (bool, error) {
+	if p.peekPunctuator("[") {
+		p.next()
+		if _, err := p.parseType(); err != nil {
+			return false, err
+		}
+		if err := p.expectPunctuator("]"); err != nil {
+			return false, err
+		}
+	} else if _, err := p.expectName(); err != nil {
+		return false, err
+	}
+
+	if p.peekPunctuator("!") {
+		p.next()
+		return true, nil
+	}
+	return false, nil
+}
+
+func (p *documentParser) parseSelectionSet(depth int) ([]*selection, error) {
+	if depth > maxSelectionDepth {
+		return nil, syntaxError(
+			p.peek().position,
+			"selection set is nested too deeply",
+		)
+	}
+	if err := p.expectPunctuator("{"); err != nil {
+		return nil, err
+	}
+
+	var selections []*selection
+	for !p.peekPunctuator("}") {
+		s, err := p.parseSelection(depth)
+		if err != nil {
+			return nil, err
+		}
+		selections = append(selections, s)
+	}
+	if len(selections) == 0 {
+		return nil, p.unexpected("expected a field")
+	}
+	p.next()
+	return selections, nil
+}
+
+func (p *documentParser) parseSelection(depth int) (*selection, error) {
+	position := p.peek().position
+	name, err := p.expectName()
+	if err != nil {
+		return nil, err
+	}
+
+	s := &selection{name: name, position: position}
+	if p.peekPunctuator(":") {
+		p.next()
+		s.alias = name
+		if s.name, err = p.expectName(); err != nil {
+			return nil, err
+		}
+	}
+	if p.peekPunctuator("(") {
+		p.next()
+		s.arguments = map[string]any{}
+		for !p.peekPunctuator(")") {
+			argumentPosition := p.peek().position
+			argument, err := p.expectName()
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := s.arguments[argument]; ok {
+				return nil, syntaxError(
+					argumentPosition,
+					fmt.Sprintf("duplicate argument %q", argument),
+				)
+			}
+			if err := p.expectPunctuator(":"); err != nil {
+				return nil, err
+			}
+			value, err := p.parseValue(false, 0)
+			if err != nil {
+				return nil, err
+			}
+			s.arguments[argument] = value
+		}
+		p.next()
+	}
+	if p.peekPunctuator("{") {
+		if s.selections, err = p.parseSelectionSet(depth + 1); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// parseValue parses an input value. Constant values, e.g. the default values
+// of variables, can not refer to variables.
+func (p *documentParser) parseValue(constant bool, depth int) (any, error) {
+	if depth > maxSelectionDepth {
+		return nil, syntaxError(
+			p.peek().position,
+			"value is nested too deeply",
+		)
+	}
+
+	token := p.peek()
+	switch token.kind {
+	case tokenInt:
+		p.next()
+		value, err := strconv.ParseInt(token.text, 10, 64)
+		if err != nil {
+			return nil, syntaxError(token.position, "integer out of range")
+		}
+		return value, nil
+	case tokenFloat:
+		p.next()
+		value, err := strconv.ParseFloat(token.text, 64)
+		if err != nil {
+			return nil, syntaxError(token.position, "float out of range")
+		}
+		return value, nil
+	case tokenString:
+		p.next()
+		return token.text, nil
+	case tokenName:
+		p.next()
+		switch token.text {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		case "null":
+			return nil, nil
+		}
+		return enumValue(token.text), nil
+	case tokenPunctuator:
+		switch token.text {
+		case "$":
+			if constant {
+				return nil, p.unexpected("expected a constant value")
+			}
+			p.next()
+			name, err := p.expectName()
+			if err != nil {
+				return nil, err
+			}
+			return variable(name), nil
+		case "[":
+			p.next()
+			list := []any{}
+			for !p.peekPunctuator("]") {
+				value, err := p.parseValue(constant, depth+1)
+				if err != nil {
+					return nil, err
+				}
+				list = append(list, value)
+			}
+			p.next()
+			return list, nil
+		case "{":
+			p.next()
+			object := map[string]any{}
+			for !p.peekPunctuator("}") {
+				field, err := p.expectName()
+				if err != nil {
+					return nil, err
+				}
+				if err := p.expectPunctuator(":"); err != nil {
+					return nil, err
+				}
+				value, err := p.parseValue(constant, depth+1)
+				if err != nil {
+					return nil, err
+				}
+				object[field] = value
+			}
+			p.next()
+			return object, nil
+		}
+	}
+	return nil, p.unexpected("expected a value")
+}
+
+func syntaxError(position int, message string) error {
+	return SyntaxError.WithData(SyntaxErrorData{
+		Position: position,
+		Message:  message,
+	})
+}
@@ -0,0 +1,194 @@
+package graphql
+
+import (
+	"testing"
+
+	"github.com/pakkasys/fluidapi/core/api"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestParseDocument tests parsing an operation with variables, aliases,
+// arguments and nested selection sets.
+func TestParseDocument(t *testing.T) {
+	doc, err := parseDocument(`
+		# List the users.
+		query Users($limit: Int! = 10, $names: [String!]) {
+			first: users(filter: "name~\"A%\"", limit: $limit, offset: -1) {
+				id, name
+			}
+			__typename
+		}
+	`)
+
+	assert.NoError(t, err)
+	assert.Equal(t, &document{operations: []*operation{{
+		kind: "query",
+		name: "Users",
+		variables: []variableDefinition{
+			{
+				name:         "limit",
+				nonNull:      true,
+				defaultValue: int64(10),
+				hasDefault:   true,
+			},
+			{name: "names"},
+		},
+		selections: []*selection{
+			{
+				alias: "first",
+				name:  "users",
+				arguments: map[string]any{
+					"filter": `name~"A%"`,
+					"limit":  variable("limit"),
+					"offset": int64(-1),
+				},
+				selections: []*selection{
+					{name: "id", position: 147},
+					{name: "name", position: 151},
+				},
+				position: 78,
+			},
+			{name: "__typename", position: 164},
+		},
+	}}}, doc)
+}
+
+// TestParseDocument_Values tests parsing the input values.
+func TestParseDocument_Values(t *testing.T) {
+	doc, err := parseDocument(`mutation {
+		create(input: {
+			a: 1.5e1, b: true, c: null, d: ACTIVE, e: [1, "é\n"]
+		}) { id }
+	}`)
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"a": 15.0,
+		"b": true,
+		"c": nil,
+		"d": enumValue("ACTIVE"),
+		"e": []any{int64(1), "é\n"},
+	}, doc.operations[0].selections[0].arguments["input"])
+}
+
+// TestParseDocument_Shorthand tests that a selection set is a query.
+func TestParseDocument_Shorthand(t *testing.T) {
+	doc, err := parseDocument("{ users { id } }")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "query", doc.operations[0].kind)
+}
+
+// TestParseDocument_Errors tests the syntax errors of documents.
+func TestParseDocument_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		document string
+		expected SyntaxErrorData
+	}{
+		{
+			name:     "empty",
+			document: " ",
+			expected: SyntaxErrorData{Message: "document has no operations"},
+		},
+		{
+			name:     "fragment",
+			document: "{ users { ...UserFields } }",
+			expected: SyntaxErrorData{
+				Position: 10,
+				Message:  "fragments are not supported",
+			},
+		},
+		{
+			name:     "directive",
+			document: "{ users @skip(if: true) { id } }",
+			expected: SyntaxErrorData{
+				Position: 8,
+				Message:  "directives are not supported",
+			},
+		},
+		{
+			name:     "subscription",
+			document: "subscription { users { id } }",
+			expected: SyntaxErrorData{
+				Message: "subscriptions are not supported",
+			},
+		},
+		{
+			name:     "unterminated string",
+			document: `{ users(filter: "a) { id } }`,
+			expected: SyntaxErrorData{
+				Position: 16,
+				Message:  "unterminated string",
+			},
+		},
+		{
+			name:     "invalid number",
+			document: "{ users(limit: 1x) { id } }",
+			expected: SyntaxErrorData{
+				Position: 15,
+				Message:  "invalid number",
+			},
+		},
+		{
+			name:     "duplicate argument",
+			document: "{ users(limit: 1, limit: 2) { id } }",
+			expected: SyntaxErrorData{
+				Position: 18,
+				Message:  `duplicate argument "limit"`,
+			},
+		},
+		{
+			name:     "variable in default value",
+			document: "query($a: Int = $b) { users { id } }",
+			expected: SyntaxErrorData{
+				Position: 16,
+				Message:  `expected a constant value, got "$"`,
+			},
+		},
+		{
+			name:     "empty selection set",
+			document: "{ users { } }",
+			expected: SyntaxErrorData{
+				Position: 10,
+				Message:  `expected a field, got "}"`,
+			},
+		},
+		{
+			name:     "unclosed selection set",
+			document: "{ users { id }",
+			expected: SyntaxErrorData{
+				Position: 14,
+				Message:  "expected a name, got end of document",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			doc, err := parseDocument(test.document)
+
+			assert.Nil(t, doc)
+			apiErr, ok := err.(*api.Error[SyntaxErrorData])
+			assert.True(t, ok)
+			assert.Equal(t, SyntaxError.ID, apiErr.ID)
+			assert.Equal(t, test.expected, *apiErr.Data)
+		})
+	}
+}
+
+// TestParseDocument_Depth tests that deeply nested selection sets are
+// rejected.
+func TestParseDocument_Depth(t *testing.T) {
+	document := ""
+	for i := 0; i <= maxSelectionDepth+1; i++ {
+		document += "{ a "
+	}
+
+	doc, err := parseDocument(document)
+
+	assert.Nil(t, doc)
+	apiErr, ok := err.(*api.Error[SyntaxErrorData])
+	assert.True(t, ok)
+	assert.Equal(t, "selection set is nested too deeply", apiErr.Data.Message)
+}
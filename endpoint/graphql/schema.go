@@ -0,0 +1,797 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pakkasys/fluidapi/core/api"
+	"github.com/pakkasys/fluidapi/database/entity"
+	"github.com/pakkasys/fluidapi/endpoint/middleware/inputlogic"
+	"github.com/pakkasys/fluidapi/endpoint/page"
+	"github.com/pakkasys/fluidapi/endpoint/predicate"
+	"github.com/pakkasys/fluidapi/endpoint/runner"
+	"github.com/pakkasys/fluidapi/endpoint/selector"
+	"github.com/pakkasys/fluidapi/endpoint/update"
+)
+
+type InvalidQueryErrorData struct {
+	Message string `json:"message"`
+}
+
+var InvalidQueryError = api.NewError[InvalidQueryErrorData]("INVALID_GRAPHQL_QUERY")
+
+// Errors are the errors of the GraphQL documents, which are always returned
+// to the clients with their data.
+var Errors []inputlogic.ExpectedError = []inputlogic.ExpectedError{
+	{
+		ID:         SyntaxError.ID,
+		Status:     http.StatusBadRequest,
+		PublicData: true,
+	},
+	{
+		ID:         InvalidQueryError.ID,
+		Status:     http.StatusBadRequest,
+		PublicData: true,
+	},
+}
+
+// Entity exposes the service functions of an entity in a Schema. An
+// operation is exposed if its name is set, e.g.
+//
+//	type Query {
+//	  users(filter: String, sort: String, limit: Int, offset: Int): [User!]!
+//	  userCount(filter: String): Int!
+//	}
+//
+//	type Mutation {
+//	  createUser(input: UserInput!): User!
+//	  updateUsers(filter: String!, set: UserInput!): Int!
+//	  deleteUsers(filter: String!): Int!
+//	}
+//
+// The filter arguments are filter expressions, see selector.ParseFilter, and
+// the sort arguments compact sort expressions, see runner.ParseSort. The
+// update and delete mutations return the number of affected entities.
+type Entity[E any] struct {
+	// Type is the name of the object type of the entity, e.g. "User". The
+	// name of the input type of the mutations is the name followed by
+	// "Input".
+	Type string
+	// APIFields are the fields of the type and their database fields
+	APIFields runner.APIFields
+	// AllowedPredicates are the predicates allowed in the filters
+	AllowedPredicates map[string][]predicate.Predicate
+	// AllowedOrderFields are the fields allowed in the sort expressions
+	AllowedOrderFields []string
+	// MaxPageCount is the maximum number of entities of a list query
+	MaxPageCount int
+	// ToOutput converts an entity to the values of its API fields. If nil,
+	// the values are the JSON encodings of the struct fields of the API
+	// fields, see Register.
+	ToOutput func(entity *E) (map[string]any, error)
+
+	// ListQuery is the name of the query listing the entities
+	ListQuery     string
+	GetEntitiesFn runner.GetServiceFunc[E]
+	// CountQuery is the name of the query counting the entities
+	CountQuery string
+	GetCountFn runner.GetCountFunc
+	// CreateMutation is the name of the mutation creating an entity
+	CreateMutation string
+	CreateEntityFn runner.CreateServiceFunc[E]
+	// UpdateMutation is the name of the mutation updating the entities
+	UpdateMutation   string
+	UpdateEntitiesFn runner.UpdateServiceFunc
+	// DeleteMutation is the name of the mutation deleting the entities
+	DeleteMutation   string
+	DeleteEntitiesFn runner.DeleteServiceFunc
+
+	// fields are the struct fields of the API fields
+	fields map[string]reflect.StructField
+}
+
+// Schema is a GraphQL schema of entities. The entities are added with
+// Register before the schema is used.
+type Schema struct {
+	// ErrorHandler maps the errors of the fields to the errors of the
+	// response.
+	ErrorHandler inputlogic.ErrorHandler
+	// ExpectedErrors are the errors returned to the clients in addition to
+	// Errors and the error catalog of the ErrorHandler.
+	ExpectedErrors []inputlogic.ExpectedError
+	// MaxRootFields is the maximum number of root fields of an operation,
+	// including the aliased fields, since each of them runs a service
+	// function. Defaults to 10.
+	MaxRootFields int
+	// MaxBodySize is the maximum size of the body of a POST request in
+	// bytes. Defaults to 1 MB.
+	MaxBodySize int64
+
+	queries   map[string]*rootField
+	mutations map[string]*rootField
+	types     []objectType
+}
+
+// rootField is a field of the query or the mutation type.
+type rootField struct {
+	arguments []argument
+	// result is the type of the field, e.g. "[User!]!"
+	result  string
+	resolve func(
+		ctx context.Context,
+		arguments map[string]any,
+		selections []*selection,
+	) (any, error)
+}
+
+// argument is an argument of a root field. Arguments with a non-null type,
+// e.g. "String!", are required.
+type argument struct {
+	name string
+	kind string
+}
+
+// objectType is the object type and the input type of an entity.
+type objectType struct {
+	name   string
+	fields []typeField
+}
+
+type typeField struct {
+	name string
+	kind string
+}
+
+// NewSchema creates an empty schema.
+func NewSchema() *Schema {
+	return &Schema{
+		queries:   map[string]*rootField{},
+		mutations: map[string]*rootField{},
+	}
+}
+
+// Register adds the operations of an entity to the schema. It panics if the
+// type or an operation is already registered, or if the service function of
+// an exposed operation is nil.
+//
+// The API fields are mapped to the struct fields of the entity by their
+// database columns: a struct field matches if its db tag is the column of
+// the API field. Otherwise, a struct field matches if its JSON name is the
+// API field or the column. The values of the input objects are decoded into
+// the matched fields from JSON.
+//
+//   - schema: The schema.
+//   - definition: The entity and its operations.
+func Register[E any](schema *Schema, definition Entity[E]) {
+	if definition.Type == "" {
+		panic("graphql: entity type is empty")
+	}
+	for _, t := range schema.types {
+		if t.name == definition.Type {
+			panic(fmt.Sprintf("graphql: type %q already registered", t.name))
+		}
+	}
+
+	definition.fields = structFields[E](definition.APIFields)
+	input := definition.Type + "Input!"
+	registerField(
+		schema.queries,
+		definition.ListQuery,
+		definition.GetEntitiesFn,
+		&rootField{
+			arguments: []argument{
+				{name: "filter", kind: "String"},
+				{name: "sort", kind: "String"},
+				{name: "limit", kind: "Int"},
+				{name: "offset", kind: "Int"},
+			},
+			result:  fmt.Sprintf("[%s!]!", definition.Type),
+			resolve: definition.resolveList,
+		},
+	)
+	registerField(
+		schema.queries,
+		definition.CountQuery,
+		definition.GetCountFn,
+		&rootField{
+			arguments: []argument{{name: "filter", kind: "String"}},
+			result:    "Int!",
+			resolve:   definition.resolveCount,
+		},
+	)
+	registerField(
+		schema.mutations,
+		definition.CreateMutation,
+		definition.CreateEntityFn,
+		&rootField{
+			arguments: []argument{{name: "input", kind: input}},
+			result:    definition.Type + "!",
+			resolve:   definition.resolveCreate,
+		},
+	)
+	registerField(
+		schema.mutations,
+		definition.UpdateMutation,
+		definition.UpdateEntitiesFn,
+		&rootField{
+			arguments: []argument{
+				{name: "filter", kind: "String!"},
+				{name: "set", kind: input},
+			},
+			result:  "Int!",
+			resolve: definition.resolveUpdate,
+		},
+	)
+	registerField(
+		schema.mutations,
+		definition.DeleteMutation,
+		definition.DeleteEntitiesFn,
+		&rootField{
+			arguments: []argument{{name: "filter", kind: "String!"}},
+			result:    "Int!",
+			resolve:   definition.resolveDelete,
+		},
+	)
+
+	schema.types = append(schema.types, objectType{
+		name:   definition.Type,
+		fields: typeFields(definition.fields, definition.APIFields),
+	})
+}
+
+func registerField[F any](
+	fields map[string]*rootField,
+	name string,
+	serviceFn F,
+	field *rootField,
+) {
+	if name == "" {
+		return
+	}
+	if _, ok := fields[name]; ok || name == "__typename" {
+		panic(fmt.Sprintf("graphql: field %q already registered", name))
+	}
+	if reflect.ValueOf(serviceFn).IsNil() {
+		panic(fmt.Sprintf("graphql: service function of %q is nil", name))
+	}
+	fields[name] = field
+}
+
+// SDL returns the schema in the GraphQL schema definition language. The
+// types of the fields are derived from the struct fields of the API fields.
+// Fields of other types than booleans, numbers and strings have the JSON
+// scalar type.
+func (s *Schema) SDL() string {
+	var builder strings.Builder
+	builder.WriteString("scalar JSON\n")
+	for _, t := range s.types {
+		for _, keyword := range []string{"type", "input"} {
+			name := t.name
+			if keyword == "input" {
+				name += "Input"
+			}
+			fmt.Fprintf(&builder, "\n%s %s {\n", keyword, name)
+			for _, field := range t.fields {
+				fmt.Fprintf(&builder, "  %s: %s\n", field.name, field.kind)
+			}
+			builder.WriteString("}\n")
+		}
+	}
+	writeRootType(&builder, "Query", s.queries)
+	writeRootType(&builder, "Mutation", s.mutations)
+	return builder.String()
+}
+
+func writeRootType(
+	builder *strings.Builder,
+	name string,
+	fields map[string]*rootField,
+) {
+	if len(fields) == 0 {
+		return
+	}
+
+	fmt.Fprintf(builder, "\ntype %s {\n", name)
+	for _, fieldName := range sortedKeys(fields) {
+		field := fields[fieldName]
+		arguments := make([]string, len(field.arguments))
+		for i, a := range field.arguments {
+			arguments[i] = a.name + ": " + a.kind
+		}
+		fmt.Fprintf(
+			builder,
+			"  %s(%s): %s\n",
+			fieldName,
+			strings.Join(arguments, ", "),
+			field.result,
+		)
+	}
+	builder.WriteString("}\n")
+}
+
+// typeFields returns the API fields and their scalar types.
+func typeFields(
+	fields map[string]reflect.StructField,
+	apiFields runner.APIFields,
+) []typeField {
+	typeFields := []typeField{}
+	for _, name := range sortedKeys(apiFields) {
+		kind := "JSON"
+		if field, ok := fields[name]; ok {
+			kind = scalarKind(field.Type)
+		}
+		typeFields = append(typeFields, typeField{name: name, kind: kind})
+	}
+	return typeFields
+}
+
+var timeType = reflect.TypeFor[time.Time]()
+
+// structFields returns the struct fields of the API fields, including the
+// fields of the embedded structs. A struct field is matched by its db tag
+// being the column of the API field, or else by its JSON name being the API
+// field or the column.
+func structFields[E any](
+	apiFields runner.APIFields,
+) map[string]reflect.StructField {
+	t := reflect.TypeFor[E]()
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	fields := map[string]reflect.StructField{}
+	if t.Kind() != reflect.Struct {
+		return fields
+	}
+
+	byColumn := map[string]reflect.StructField{}
+	byJSONName := map[string]reflect.StructField{}
+	for _, field := range reflect.VisibleFields(t) {
+		jsonName, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if !field.IsExported() || (field.Anonymous && jsonName == "") {
+			continue
+		}
+		column, _, _ := strings.Cut(field.Tag.Get("db"), ",")
+		if column != "" && column != "-" {
+			byColumn[column] = field
+		}
+		if jsonName == "-" {
+			continue
+		}
+		if jsonName == "" {
+			jsonName = field.Name
+		}
+		byJSONName[jsonName] = field
+	}
+
+	for name, dbField := range apiFields {
+		if field, ok := byColumn[dbField.Column]; ok {
+			fields[name] = field
+		} else if field, ok := byJSONName[name]; ok {
+			fields[name] = field
+		} else if field, ok := byJSONName[dbField.Column]; ok {
+			fields[name] = field
+		}
+	}
+	return fields
+}
+
+// fieldByIndex returns the nested field of a struct value. Nil embedded
+// pointers are allocated if alloc is true, otherwise false is returned.
+func fieldByIndex(
+	value reflect.Value,
+	index []int,
+	alloc bool,
+) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && value.Kind() == reflect.Pointer {
+			if value.IsNil() {
+				if !alloc || !value.CanSet() {
+					return reflect.Value{}, false
+				}
+				value.Set(reflect.New(value.Type().Elem()))
+			}
+			value = value.Elem()
+		}
+		value = value.Field(x)
+	}
+	return value, true
+}
+
+func scalarKind(t reflect.Type) string {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == timeType {
+		return "String"
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return "Boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16,
+		reflect.Uint32, reflect.Uint64:
+		return "Int"
+	case reflect.Float32, reflect.Float64:
+		return "Float"
+	case reflect.String:
+		return "String"
+	}
+	return "JSON"
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (e Entity[E]) resolveList(
+	ctx context.Context,
+	arguments map[string]any,
+	selections []*selection,
+) (any, error) {
+	fields, err := e.selectedFields(selections)
+	if err != nil {
+		return nil, err
+	}
+	selectors, err := e.filterArgument(arguments)
+	if err != nil {
+		return nil, err
+	}
+	inputPage, err := pageArgument(arguments, e.MaxPageCount)
+	if err != nil {
+		return nil, err
+	}
+	sortExpression, _ := arguments["sort"].(string)
+
+	parsed, err := runner.ParseGetEndpointInput(
+		e.APIFields,
+		selectors,
+		nil,
+		e.AllowedOrderFields,
+		inputPage,
+		e.MaxPageCount,
+		false,
+		runner.WithFields(fields),
+		runner.WithSort(sortExpression),
+	)
+	if err != nil {
+		return nil, err
+	}
+	parsed.CheckDeprecations(ctx)
+
+	entities, err := e.GetEntitiesFn(ctx, getOptions(parsed))
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]any, len(entities))
+	for i := range entities {
+		output, err := e.toObject(&entities[i], selections)
+		if err != nil {
+			return nil, err
+		}
+		list[i] = output
+	}
+	return list, nil
+}
+
+func (e Entity[E]) resolveCount(
+	ctx context.Context,
+	arguments map[string]any,
+	selections []*selection,
+) (any, error) {
+	selectors, err := e.filterArgument(arguments)
+	if err != nil {
+		return nil, err
+	}
+
+	parsed, err := runner.ParseGetEndpointInput(
+		e.APIFields,
+		selectors,
+		nil,
+		nil,
+		nil,
+		e.MaxPageCount,
+		true,
+	)
+	if err != nil {
+		return nil, err
+	}
+	parsed.CheckDeprecations(ctx)
+
+	return e.GetCountFn(ctx, parsed.DatabaseSelectors, parsed.Joins)
+}
+
+func (e Entity[E]) resolveCreate(
+	ctx context.Context,
+	arguments map[string]any,
+	selections []*selection,
+) (any, error) {
+	if _, err := e.selectedFields(selections); err != nil {
+		return nil, err
+	}
+	input, err := e.inputArgument(arguments, "input")
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := e.decodeInput(input)
+	if err != nil {
+		return nil, err
+	}
+
+	created, err := e.CreateEntityFn(ctx, value, nil)
+	if err != nil {
+		return nil, err
+	}
+	return e.toObject(created, selections)
+}
+
+func (e Entity[E]) resolveUpdate(
+	ctx context.Context,
+	arguments map[string]any,
+	selections []*selection,
+) (any, error) {
+	selectors, err := e.filterArgument(arguments)
+	if err != nil {
+		return nil, err
+	}
+	set, err := e.inputArgument(arguments, "set")
+	if err != nil {
+		return nil, err
+	}
+
+	updates := make([]update.Update, 0, len(set))
+	for _, field := range sortedKeys(set) {
+		updates = append(updates, update.Update{
+			Field: field,
+			Value: set[field],
+		})
+	}
+
+	parsed, err := runner.ParseUpdateEndpointInput(
+		e.APIFields,
+		selectors,
+		updates,
+		false,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return e.UpdateEntitiesFn(
+		ctx,
+		parsed.DatabaseSelectors,
+		parsed.DatabaseUpdates,
+	)
+}
+
+func (e Entity[E]) resolveDelete(
+	ctx context.Context,
+	arguments map[string]any,
+	selections []*selection,
+) (any, error) {
+	selectors, err := e.filterArgument(arguments)
+	if err != nil {
+		return nil, err
+	}
+
+	parsed, err := runner.ParseDeleteEndpointInput(
+		e.APIFields,
+		selectors,
+		nil,
+		nil,
+		0,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return e.DeleteEntitiesFn(ctx, parsed.DatabaseSelectors, parsed.DeleteOpts)
+}
+
+// selectedFields validates the selections of an object and returns the
+// selected API fields.
+func (e Entity[E]) selectedFields(selections []*selection) ([]string, error) {
+	if len(selections) == 0 {
+		return nil, invalidQueryError(
+			fmt.Sprintf("type %q must have a selection of fields", e.Type),
+		)
+	}
+
+	var fields []string
+	for _, s := range selections {
+		if s.arguments != nil || s.selections != nil {
+			return nil, invalidQueryError(
+				fmt.Sprintf("field %q of type %q is a scalar", s.name, e.Type),
+			)
+		}
+		if s.name == "__typename" {
+			continue
+		}
+		if _, ok := e.APIFields[s.name]; !ok {
+			return nil, invalidQueryError(
+				fmt.Sprintf("unknown field %q on type %q", s.name, e.Type),
+			)
+		}
+		if !slices.Contains(fields, s.name) {
+			fields = append(fields, s.name)
+		}
+	}
+	return fields, nil
+}
+
+// toObject returns the selected fields of an entity.
+func (e Entity[E]) toObject(value *E, selections []*selection) (any, error) {
+	if value == nil {
+		return nil, nil
+	}
+
+	var values map[string]any
+	if e.ToOutput != nil {
+		output, err := e.ToOutput(value)
+		if err != nil {
+			return nil, err
+		}
+		values = output
+	} else {
+		output, err := e.fieldValues(value, selections)
+		if err != nil {
+			return nil, err
+		}
+		values = output
+	}
+
+	output := &object{}
+	for _, s := range selections {
+		if s.name == "__typename" {
+			output.set(s.responseKey(), e.Type)
+		} else {
+			output.set(s.responseKey(), values[s.name])
+		}
+	}
+	return output, nil
+}
+
+// fieldValues returns the JSON encodings of the struct fields of the
+// selected API fields.
+func (e Entity[E]) fieldValues(
+	value *E,
+	selections []*selection,
+) (map[string]any, error) {
+	structValue := reflect.ValueOf(value).Elem()
+	values := map[string]any{}
+	for _, s := range selections {
+		field, ok := e.fields[s.name]
+		if !ok {
+			continue
+		}
+		fieldValue, ok := fieldByIndex(structValue, field.Index, false)
+		if !ok {
+			continue
+		}
+		data, err := json.Marshal(fieldValue.Interface())
+		if err != nil {
+			return nil, err
+		}
+		var decoded any
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber()
+		if err := decoder.Decode(&decoded); err != nil {
+			return nil, err
+		}
+		values[s.name] = decoded
+	}
+	return values, nil
+}
+
+// decodeInput decodes the fields of an input object into the struct fields
+// of an entity.
+func (e Entity[E]) decodeInput(input map[string]any) (*E, error) {
+	value := new(E)
+	structValue := reflect.ValueOf(value).Elem()
+	for _, name := range sortedKeys(input) {
+		field, ok := e.fields[name]
+		var fieldValue reflect.Value
+		if ok {
+			fieldValue, ok = fieldByIndex(structValue, field.Index, true)
+		}
+		if !ok {
+			return nil, invalidQueryError(fmt.Sprintf(
+				"field %q on input type %q can not be set",
+				name,
+				e.Type+"Input",
+			))
+		}
+		data, err := json.Marshal(input[name])
+		if err != nil {
+			return nil, err
+		}
+		err = json.Unmarshal(data, fieldValue.Addr().Interface())
+		if err != nil {
+			return nil, invalidQueryError(fmt.Sprintf(
+				"invalid value of field %q on input type %q: %s",
+				name,
+				e.Type+"Input",
+				err,
+			))
+		}
+	}
+	return value, nil
+}
+
+func (e Entity[E]) filterArgument(
+	arguments map[string]any,
+) ([]selector.Selector, error) {
+	filter, _ := arguments["filter"].(string)
+	return selector.ParseFilter(filter, e.APIFields, e.AllowedPredicates)
+}
+
+// inputArgument returns an input object argument, validating its fields.
+func (e Entity[E]) inputArgument(
+	arguments map[string]any,
+	name string,
+) (map[string]any, error) {
+	input, _ := arguments[name].(map[string]any)
+	for field := range input {
+		if _, ok := e.APIFields[field]; !ok {
+			return nil, invalidQueryError(fmt.Sprintf(
+				"unknown field %q on input type %q",
+				field,
+				e.Type+"Input",
+			))
+		}
+	}
+	return input, nil
+}
+
+// pageArgument returns the page of the limit and offset arguments, or nil if
+// neither is given. The limit defaults to the maximum page count.
+func pageArgument(
+	arguments map[string]any,
+	maxPageCount int,
+) (*page.Page, error) {
+	limit, _ := arguments["limit"].(int64)
+	offset, _ := arguments["offset"].(int64)
+	if arguments["limit"] == nil && arguments["offset"] == nil {
+		return nil, nil
+	}
+	if limit < 0 || offset < 0 {
+		return nil, invalidQueryError("limit and offset must not be negative")
+	}
+
+	inputPage := &page.Page{Limit: int(limit), Offset: int(offset)}
+	if arguments["limit"] == nil {
+		inputPage.Limit = maxPageCount
+	}
+	return inputPage, nil
+}
+
+// getOptions returns the options of the service function of a list query.
+func getOptions(parsed *runner.ParsedGetEndpointInput) entity.GetOptions {
+	return entity.GetOptions{
+		Options: entity.Options{
+			Selectors:   parsed.DatabaseSelectors,
+			Orders:      parsed.Orders,
+			Page:        parsed.Page,
+			Joins:       parsed.Joins,
+			Projections: parsed.Projections,
+		},
+	}
+}
+
+func invalidQueryError(message string) error {
+	return InvalidQueryError.WithData(InvalidQueryErrorData{Message: message})
+}
@@ -0,0 +1,265 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/pakkasys/fluidapi/database/entity"
+	"github.com/pakkasys/fluidapi/database/util"
+	"github.com/pakkasys/fluidapi/endpoint/dbfield"
+	"github.com/pakkasys/fluidapi/endpoint/predicate"
+	"github.com/pakkasys/fluidapi/endpoint/runner"
+	"github.com/stretchr/testify/assert"
+)
+
+type testTimestamps struct {
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type testUser struct {
+	testTimestamps
+	ID     int64          `json:"id"`
+	Name   string         `json:"name"`
+	Score  *float64       `json:"score,omitempty"`
+	Active bool           `json:"active"`
+	Tags   []string       `json:"tags"`
+	Secret string         `json:"-"`
+	Extra  map[string]any `json:"extra"`
+}
+
+var testAPIFields = runner.APIFields{
+	"id":         dbfield.DBField{Table: "users", Column: "id"},
+	"name":       dbfield.DBField{Table: "users", Column: "name"},
+	"score":      dbfield.DBField{Table: "users", Column: "score"},
+	"active":     dbfield.DBField{Table: "users", Column: "active"},
+	"created_at": dbfield.DBField{Table: "users", Column: "created_at"},
+	"tags":       dbfield.DBField{Table: "users", Column: "tags"},
+}
+
+// testServices records the calls of the service functions.
+type testServices struct {
+	getOptions entity.GetOptions
+	selectors  []util.Selector
+	updates    []entity.Update
+	created    *testUser
+}
+
+func (s *testServices) entity() Entity[testUser] {
+	return Entity[testUser]{
+		Type:      "User",
+		APIFields: testAPIFields,
+		AllowedPredicates: map[string][]predicate.Predicate{
+			"id":   {predicate.EQUAL, predicate.GREATER},
+			"name": {predicate.EQUAL},
+		},
+		AllowedOrderFields: []string{"id", "name"},
+		MaxPageCount:       50,
+		ListQuery:          "users",
+		GetEntitiesFn: func(
+			ctx context.Context,
+			opts entity.GetOptions,
+		) ([]testUser, error) {
+			s.getOptions = opts
+			return []testUser{
+				{ID: 1, Name: "alice", Active: true},
+				{ID: 2, Name: "bob"},
+			}, nil
+		},
+		CountQuery: "userCount",
+		GetCountFn: func(
+			ctx context.Context,
+			selectors []util.Selector,
+			joins []util.Join,
+		) (int, error) {
+			s.selectors = selectors
+			return 2, nil
+		},
+		CreateMutation: "createUser",
+		CreateEntityFn: func(
+			ctx context.Context,
+			user *testUser,
+			opts *entity.UpsertOptions,
+		) (*testUser, error) {
+			s.created = user
+			created := *user
+			created.ID = 3
+			return &created, nil
+		},
+		UpdateMutation: "updateUsers",
+		UpdateEntitiesFn: func(
+			ctx context.Context,
+			selectors []util.Selector,
+			updates []entity.Update,
+		) (int64, error) {
+			s.selectors = selectors
+			s.updates = updates
+			return 1, nil
+		},
+		DeleteMutation: "deleteUsers",
+		DeleteEntitiesFn: func(
+			ctx context.Context,
+			selectors []util.Selector,
+			opts *entity.DeleteOptions,
+		) (int64, error) {
+			s.selectors = selectors
+			return 2, nil
+		},
+	}
+}
+
+func testSchema() (*Schema, *testServices) {
+	services := &testServices{}
+	schema := NewSchema()
+	Register(schema, services.entity())
+	return schema, services
+}
+
+// TestSchema_SDL tests rendering the schema with the types of the JSON
+// fields of the entity.
+func TestSchema_SDL(t *testing.T) {
+	schema, _ := testSchema()
+
+	assert.Equal(t, `scalar JSON
+
+type User {
+  active: Boolean
+  created_at: String
+  id: Int
+  name: String
+  score: Float
+  tags: JSON
+}
+
+input UserInput {
+  active: Boolean
+  created_at: String
+  id: Int
+  name: String
+  score: Float
+  tags: JSON
+}
+
+type Query {
+  userCount(filter: String): Int!
+  users(filter: String, sort: String, limit: Int, offset: Int): [User!]!
+}
+
+type Mutation {
+  createUser(input: UserInput!): User!
+  deleteUsers(filter: String!): Int!
+  updateUsers(filter: String!, set: UserInput!): Int!
+}
+`, schema.SDL())
+}
+
+// TestRegister_OnlyNamedOperations tests that only the operations with a
+// name are exposed.
+func TestRegister_OnlyNamedOperations(t *testing.T) {
+	schema := NewSchema()
+
+	Register(schema, Entity[testUser]{
+		Type:       "User",
+		APIFields:  testAPIFields,
+		CountQuery: "userCount",
+		GetCountFn: func(
+			ctx context.Context,
+			selectors []util.Selector,
+			joins []util.Join,
+		) (int, error) {
+			return 0, nil
+		},
+	})
+
+	assert.Len(t, schema.queries, 1)
+	assert.Contains(t, schema.queries, "userCount")
+	assert.Empty(t, schema.mutations)
+}
+
+// TestRegister_Panics tests the panics of invalid registrations.
+func TestRegister_Panics(t *testing.T) {
+	services := &testServices{}
+
+	assert.PanicsWithValue(t, "graphql: entity type is empty", func() {
+		Register(NewSchema(), Entity[testUser]{})
+	})
+	assert.PanicsWithValue(t, `graphql: type "User" already registered`,
+		func() {
+			schema := NewSchema()
+			Register(schema, Entity[testUser]{Type: "User"})
+			Register(schema, Entity[testUser]{Type: "User"})
+		},
+	)
+	assert.PanicsWithValue(t, `graphql: field "users" already registered`,
+		func() {
+			schema := NewSchema()
+			Register(schema, services.entity())
+			Register(schema, Entity[testUser]{
+				Type:          "Admin",
+				ListQuery:     "users",
+				GetEntitiesFn: services.entity().GetEntitiesFn,
+			})
+		},
+	)
+	assert.PanicsWithValue(t, `graphql: service function of "users" is nil`,
+		func() {
+			Register(NewSchema(), Entity[testUser]{
+				Type:      "User",
+				ListQuery: "users",
+			})
+		},
+	)
+}
+
+type testProfile struct {
+	ID          int64  `json:"id"`
+	DisplayName string `json:"display_name"`
+	Email       string `db:"email_address" json:"mail"`
+}
+
+// TestSchema_Execute_FieldMapping tests mapping the API fields to the struct
+// fields of the entity by their database columns.
+func TestSchema_Execute_FieldMapping(t *testing.T) {
+	var created *testProfile
+	schema := NewSchema()
+	Register(schema, Entity[testProfile]{
+		Type: "Profile",
+		APIFields: runner.APIFields{
+			"id":          {Table: "profiles", Column: "id"},
+			"displayName": {Table: "profiles", Column: "display_name"},
+			"email":       {Table: "profiles", Column: "email_address"},
+		},
+		CreateMutation: "createProfile",
+		CreateEntityFn: func(
+			ctx context.Context,
+			profile *testProfile,
+			opts *entity.UpsertOptions,
+		) (*testProfile, error) {
+			created = profile
+			return profile, nil
+		},
+	})
+
+	response, err := json.Marshal(schema.Execute(
+		context.Background(),
+		Request{Query: `mutation {
+			createProfile(input: {id: 1, displayName: "Alice", email: "a@b"}) {
+				id displayName email
+			}
+		}`},
+	))
+
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"data": {"createProfile": {
+		"id": 1,
+		"displayName": "Alice",
+		"email": "a@b"
+	}}}`, string(response))
+	assert.Equal(
+		t,
+		&testProfile{ID: 1, DisplayName: "Alice", Email: "a@b"},
+		created,
+	)
+	assert.Contains(t, schema.SDL(), "displayName: String")
+}
@@ -0,0 +1,306 @@
+package grpc
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// ServiceDescriptor describes a service and its methods.
+type ServiceDescriptor struct {
+	Name    string             `json:"name"`
+	Methods []MethodDescriptor `json:"methods"`
+
+	messages []messageDescriptor
+}
+
+// MethodDescriptor describes a unary method and the HTTP endpoint serving the
+// same callback.
+type MethodDescriptor struct {
+	Name string `json:"name"`
+	// FullMethod is the path of the method, e.g. "/users.v1.Users/Create"
+	FullMethod string `json:"full_method"`
+	// InputType and OutputType are the names of the messages
+	InputType  string `json:"input_type"`
+	OutputType string `json:"output_type"`
+	HTTPMethod string `json:"http_method,omitempty"`
+	URL        string `json:"url,omitempty"`
+}
+
+// messageDescriptor describes a message derived from a Go type.
+type messageDescriptor struct {
+	name   string
+	fields []fieldDescriptor
+}
+
+type fieldDescriptor struct {
+	name string
+	// kind is the type of the field, e.g. "repeated string"
+	kind string
+}
+
+// Descriptors returns the descriptors of the registered services sorted by
+// name.
+func (s *Server) Descriptors() []ServiceDescriptor {
+	names := make([]string, 0, len(s.services))
+	for name := range s.services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	descriptors := make([]ServiceDescriptor, len(names))
+	for i, name := range names {
+		descriptors[i] = s.services[name].Descriptor()
+	}
+	return descriptors
+}
+
+// Descriptor returns the descriptor of the service. The messages are derived
+// from the JSON fields of the inputs and the outputs of the methods.
+func (s *Service) Descriptor() ServiceDescriptor {
+	builder := &messageBuilder{names: map[reflect.Type]string{}}
+	descriptor := ServiceDescriptor{Name: s.Name}
+	for _, method := range s.Methods {
+		descriptor.Methods = append(descriptor.Methods, MethodDescriptor{
+			Name:       method.Name,
+			FullMethod: "/" + s.Name + "/" + method.Name,
+			InputType:  builder.message(method.inputType),
+			OutputType: builder.message(method.outputType),
+			HTTPMethod: method.HTTPMethod,
+			URL:        method.URL,
+		})
+	}
+	descriptor.messages = builder.messages
+	return descriptor
+}
+
+// Proto renders the service and its messages as a proto3 file. The proto
+// file describes the JSON encoding of the messages and is meant as a
+// starting point for the proto definitions of the service.
+func (d ServiceDescriptor) Proto() string {
+	var builder strings.Builder
+	builder.WriteString("syntax = \"proto3\";\n")
+
+	serviceName := d.Name
+	if i := strings.LastIndex(d.Name, "."); i >= 0 {
+		fmt.Fprintf(&builder, "\npackage %s;\n", d.Name[:i])
+		serviceName = d.Name[i+1:]
+	}
+
+	fmt.Fprintf(&builder, "\nservice %s {\n", serviceName)
+	for _, method := range d.Methods {
+		if method.HTTPMethod != "" || method.URL != "" {
+			fmt.Fprintf(&builder, "  // %s %s\n", method.HTTPMethod, method.URL)
+		}
+		fmt.Fprintf(
+			&builder,
+			"  rpc %s(%s) returns (%s);\n",
+			method.Name,
+			method.InputType,
+			method.OutputType,
+		)
+	}
+	builder.WriteString("}\n")
+
+	for _, message := range d.messages {
+		fmt.Fprintf(&builder, "\nmessage %s {\n", message.name)
+		for i, field := range message.fields {
+			fmt.Fprintf(
+				&builder,
+				"  %s %s = %d;\n",
+				field.kind,
+				field.name,
+				i+1,
+			)
+		}
+		builder.WriteString("}\n")
+	}
+	return builder.String()
+}
+
+var timeType = reflect.TypeFor[time.Time]()
+
+// messageBuilder derives the messages of Go struct types.
+type messageBuilder struct {
+	names    map[reflect.Type]string
+	messages []messageDescriptor
+}
+
+// message returns the name of the message of a type, adding the messages of
+// the type and its nested struct types.
+func (b *messageBuilder) message(t reflect.Type) string {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if name, ok := b.names[t]; ok {
+		return name
+	}
+
+	name := b.uniqueName(messageName(t))
+	b.names[t] = name
+	index := len(b.messages)
+	b.messages = append(b.messages, messageDescriptor{name: name})
+
+	var fields []fieldDescriptor
+	if t.Kind() == reflect.Struct {
+		fields = b.fields(t)
+	}
+	b.messages[index].fields = fields
+	return name
+}
+
+func (b *messageBuilder) uniqueName(name string) string {
+	unique := name
+	for i := 2; ; i++ {
+		taken := false
+		for _, message := range b.messages {
+			if message.name == unique {
+				taken = true
+				break
+			}
+		}
+		if !taken {
+			return unique
+		}
+		unique = fmt.Sprintf("%s%d", name, i)
+	}
+}
+
+// fields returns the JSON fields of a struct type, including the fields of
+// its embedded structs.
+func (b *messageBuilder) fields(t reflect.Type) []fieldDescriptor {
+	var fields []fieldDescriptor
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		fieldType := field.Type
+		for fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+		if field.Anonymous && name == "" &&
+			fieldType.Kind() == reflect.Struct {
+			fields = append(fields, b.fields(fieldType)...)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fields = append(fields, fieldDescriptor{
+			name: name,
+			kind: b.fieldKind(field.Type),
+		})
+	}
+	return fields
+}
+
+// fieldKind returns the proto type of a field. Types without a proto
+// equivalent are bytes.
+func (b *messageBuilder) fieldKind(t reflect.Type) string {
+	optional := false
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+		optional = true
+	}
+
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return "bytes"
+		}
+		element := t.Elem()
+		for element.Kind() == reflect.Pointer {
+			element = element.Elem()
+		}
+		if element.Kind() == reflect.Slice || element.Kind() == reflect.Map {
+			return "bytes"
+		}
+		return "repeated " + b.scalarOrMessage(element)
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return "bytes"
+		}
+		value := t.Elem()
+		for value.Kind() == reflect.Pointer {
+			value = value.Elem()
+		}
+		if value.Kind() == reflect.Slice || value.Kind() == reflect.Map {
+			return "bytes"
+		}
+		return fmt.Sprintf("map<string, %s>", b.scalarOrMessage(value))
+	}
+
+	kind := b.scalarOrMessage(t)
+	if optional && t.Kind() != reflect.Struct {
+		return "optional " + kind
+	}
+	return kind
+}
+
+func (b *messageBuilder) scalarOrMessage(t reflect.Type) string {
+	if t == timeType {
+		return "string"
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return "bool"
+	case reflect.String:
+		return "string"
+	case reflect.Int, reflect.Int64:
+		return "int64"
+	case reflect.Int8, reflect.Int16, reflect.Int32:
+		return "int32"
+	case reflect.Uint, reflect.Uint64:
+		return "uint64"
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return "uint32"
+	case reflect.Float32:
+		return "float"
+	case reflect.Float64:
+		return "double"
+	case reflect.Struct:
+		return b.message(t)
+	}
+	return "bytes"
+}
+
+// messageName returns the name of the message of a type, e.g. "User" for
+// User and "ListOutputUser" for ListOutput[User].
+func messageName(t reflect.Type) string {
+	base, arguments, generic := strings.Cut(t.Name(), "[")
+	if base == "" {
+		return "Message"
+	}
+	if !generic {
+		return base
+	}
+
+	var builder strings.Builder
+	builder.WriteString(base)
+	for _, argument := range strings.Split(arguments, ",") {
+		// Type arguments are qualified by their packages.
+		if i := strings.LastIndex(argument, "."); i >= 0 {
+			argument = argument[i+1:]
+		}
+		runes := []rune(strings.Map(func(r rune) rune {
+			if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' {
+				return r
+			}
+			return -1
+		}, argument))
+		if len(runes) > 0 {
+			runes[0] = unicode.ToUpper(runes[0])
+			builder.WriteString(string(runes))
+		}
+	}
+	return builder.String()
+}
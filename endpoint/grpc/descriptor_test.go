@@ -0,0 +1,134 @@
+package grpc
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/pakkasys/fluidapi/endpoint/middleware/inputlogic"
+	"github.com/pakkasys/fluidapi/endpoint/runner"
+	"github.com/stretchr/testify/assert"
+)
+
+type descriptorAddress struct {
+	City string `json:"city"`
+}
+
+type descriptorAudit struct {
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type descriptorInput struct {
+	descriptorAudit
+	Name     string              `json:"name"`
+	Age      *int32              `json:"age,omitempty"`
+	Score    float64             `json:"score"`
+	Tags     []string            `json:"tags"`
+	Avatar   []byte              `json:"avatar"`
+	Address  *descriptorAddress  `json:"address"`
+	Previous []descriptorAddress `json:"previous"`
+	Labels   map[string]string   `json:"labels"`
+	Matrix   [][]int             `json:"matrix"`
+	Extra    any                 `json:"extra"`
+	Ignored  string              `json:"-"`
+	Plain    bool
+	hidden   string
+	Nested   map[string]descriptorAddress `json:"nested"`
+}
+
+func (i descriptorInput) Validate() []inputlogic.FieldError {
+	return nil
+}
+
+// TestService_Descriptor tests describing the methods of a service.
+func TestService_Descriptor(t *testing.T) {
+	descriptor := testService().Descriptor()
+
+	assert.Equal(t, "users.v1.Users", descriptor.Name)
+	assert.Equal(t, []MethodDescriptor{{
+		Name:       "Create",
+		FullMethod: "/users.v1.Users/Create",
+		InputType:  "createUserInput",
+		OutputType: "createUserOutput",
+		HTTPMethod: http.MethodPost,
+		URL:        "/users",
+	}}, descriptor.Methods)
+}
+
+// TestServiceDescriptor_Proto tests rendering the proto file of a service
+// with messages derived from the JSON fields.
+func TestServiceDescriptor_Proto(t *testing.T) {
+	service := &Service{
+		Name: "profiles.v1.Profiles",
+		Methods: []Method{
+			NewMethod(
+				"Update",
+				runner.InputSpecification[descriptorInput]{
+					URL:    "/profiles",
+					Method: http.MethodPatch,
+				},
+				func(
+					w http.ResponseWriter,
+					r *http.Request,
+					i *descriptorInput,
+				) (*runner.ListOutput[descriptorAddress], error) {
+					return nil, nil
+				},
+				nil,
+			),
+		},
+	}
+
+	assert.Equal(t, `syntax = "proto3";
+
+package profiles.v1;
+
+service Profiles {
+  // PATCH /profiles
+  rpc Update(descriptorInput) returns (ListOutputDescriptorAddress);
+}
+
+message descriptorInput {
+  string created_at = 1;
+  string name = 2;
+  optional int32 age = 3;
+  double score = 4;
+  repeated string tags = 5;
+  bytes avatar = 6;
+  descriptorAddress address = 7;
+  repeated descriptorAddress previous = 8;
+  map<string, string> labels = 9;
+  bytes matrix = 10;
+  bytes extra = 11;
+  bool Plain = 12;
+  map<string, descriptorAddress> nested = 13;
+}
+
+message descriptorAddress {
+  string city = 1;
+}
+
+message ListOutputDescriptorAddress {
+  repeated descriptorAddress items = 1;
+  optional int64 count = 2;
+  int64 limit = 3;
+  optional int64 offset = 4;
+  string next = 5;
+  string previous = 6;
+}
+`, service.Descriptor().Proto())
+}
+
+// TestServer_Descriptors tests describing the registered services.
+func TestServer_Descriptors(t *testing.T) {
+	server := NewServer()
+	other := testService()
+	other.Name = "accounts.v1.Accounts"
+	server.Register(testService(), other)
+
+	descriptors := server.Descriptors()
+
+	assert.Len(t, descriptors, 2)
+	assert.Equal(t, "accounts.v1.Accounts", descriptors[0].Name)
+	assert.Equal(t, "users.v1.Users", descriptors[1].Name)
+}
@@ -0,0 +1,397 @@
+package grpc
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pakkasys/fluidapi/endpoint/middleware/inputlogic"
+)
+
+// MediaTypeGRPC is the media type of gRPC requests. Its subtypes, e.g.
+// "application/grpc+json", select the codec of the messages.
+const MediaTypeGRPC = "application/grpc"
+
+// DefaultMaxMessageSize is the default maximum size of a request message.
+const DefaultMaxMessageSize = 4 << 20
+
+// ErrorTrailer is the binary trailer carrying the JSON encoding of the API
+// error of a failed call.
+const ErrorTrailer = "Api-Error-Bin"
+
+// Codec encodes and decodes messages, e.g. with google.golang.org/protobuf:
+//
+//	grpc.Codec{
+//		Marshal: func(message any) ([]byte, error) {
+//			return proto.Marshal(message.(proto.Message))
+//		},
+//		Unmarshal: func(data []byte, message any) error {
+//			return proto.Unmarshal(data, message.(proto.Message))
+//		},
+//	}
+//
+// The messages are the inputs and the outputs of the callbacks as pointers,
+// so they must be message types such as generated structs.
+type Codec struct {
+	Marshal   func(message any) ([]byte, error)
+	Unmarshal func(data []byte, message any) error
+}
+
+// JSONCodec encodes the messages as JSON. It is the codec of the "json"
+// subtype of the default server.
+var JSONCodec = Codec{Marshal: json.Marshal, Unmarshal: json.Unmarshal}
+
+// Server serves the unary methods of gRPC services over HTTP/2. It is an
+// http.Handler, so it is served by an HTTP/2 server, e.g. with the HTTP/2
+// configuration of the server package.
+type Server struct {
+	// Codecs are the codecs of the content subtypes, e.g. "json" for
+	// "application/grpc+json". The codec of the "proto" subtype is also used
+	// for requests without a subtype.
+	Codecs map[string]Codec
+	// Interceptors intercept the calls, the first of which runs first.
+	Interceptors []UnaryInterceptor
+	// ErrorHandler maps the errors of the calls to API errors.
+	ErrorHandler inputlogic.ErrorHandler
+	// Codes override the status codes of the API errors by their IDs. The
+	// status codes are derived from the HTTP statuses of the expected errors
+	// by default.
+	Codes map[string]Code
+	// MaxMessageSize is the maximum size of a request message.
+	// DefaultMaxMessageSize is used if zero.
+	MaxMessageSize int
+
+	services map[string]*Service
+}
+
+// NewServer creates a new server with the JSON codec. The calls of content
+// subtypes without a codec, e.g. "application/grpc" without a "proto" codec,
+// fail with CodeUnimplemented.
+//
+//   - interceptors: The interceptors of the calls.
+func NewServer(interceptors ...UnaryInterceptor) *Server {
+	return &Server{
+		Codecs:       map[string]Codec{"json": JSONCodec},
+		Interceptors: interceptors,
+		services:     map[string]*Service{},
+	}
+}
+
+// Register registers services. It panics if a service is already
+// registered or has duplicate methods.
+//
+//   - services: The services to register.
+func (s *Server) Register(services ...*Service) {
+	if s.services == nil {
+		s.services = map[string]*Service{}
+	}
+	for _, service := range services {
+		if _, ok := s.services[service.Name]; ok {
+			panic(fmt.Sprintf("service %q already registered", service.Name))
+		}
+		names := map[string]bool{}
+		for _, method := range service.Methods {
+			if names[method.Name] {
+				panic(fmt.Sprintf(
+					"duplicate method %q in service %q",
+					method.Name,
+					service.Name,
+				))
+			}
+			names[method.Name] = true
+		}
+		s.services[service.Name] = service
+	}
+}
+
+// ServeHTTP serves a unary call. The path of the request is the full method,
+// e.g. "/users.v1.Users/Create", and the body a single length-prefixed
+// message. The grpc-timeout header sets the deadline of the call.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(
+			w,
+			http.StatusText(http.StatusMethodNotAllowed),
+			http.StatusMethodNotAllowed,
+		)
+		return
+	}
+	contentType := r.Header.Get("Content-Type")
+	subtype, ok := contentSubtype(contentType)
+	if !ok {
+		http.Error(
+			w,
+			http.StatusText(http.StatusUnsupportedMediaType),
+			http.StatusUnsupportedMediaType,
+		)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	codec, ok := s.Codecs[subtype]
+	if !ok {
+		writeStatus(w, Status(
+			CodeUnimplemented,
+			fmt.Sprintf("unsupported content subtype %q", subtype),
+		), nil)
+		return
+	}
+	method, ok := s.method(r.URL.Path)
+	if !ok {
+		writeStatus(w, Status(
+			CodeUnimplemented,
+			"unknown method "+r.URL.Path,
+		), nil)
+		return
+	}
+
+	ctx := r.Context()
+	if timeout := r.Header.Get("Grpc-Timeout"); timeout != "" {
+		duration, err := parseTimeout(timeout)
+		if err != nil {
+			writeStatus(w, Status(CodeInvalidArgument, err.Error()), nil)
+			return
+		}
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, duration)
+		defer cancel()
+	}
+
+	var data []byte
+	response, err := s.call(ctx, w, r, method, codec)
+	if err == nil {
+		data, err = codec.Marshal(response)
+	}
+	// The interceptors may have set the content type of an HTTP response.
+	w.Header().Set("Content-Type", contentType)
+	if err != nil {
+		s.writeError(w, method, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(frame(data))
+	w.Header().Set(http.TrailerPrefix+"Grpc-Status", "0")
+}
+
+// call decodes the request message and calls the method through the
+// interceptors.
+func (s *Server) call(
+	ctx context.Context,
+	w http.ResponseWriter,
+	r *http.Request,
+	method *Method,
+	codec Codec,
+) (any, error) {
+	data, err := s.readMessage(r.Body)
+	if err != nil {
+		return nil, err
+	}
+	input := method.newInput()
+	if err := codec.Unmarshal(data, input); err != nil {
+		return nil, Status(CodeInvalidArgument, "invalid request message")
+	}
+	if err := method.validate(input); err != nil {
+		return nil, err
+	}
+
+	info := &UnaryServerInfo{
+		FullMethod: r.URL.Path,
+		Request:    r,
+		Header:     w.Header(),
+	}
+	handler := chainInterceptors(s.Interceptors, info, method.handler(info))
+	return handler(ctx, input)
+}
+
+// readMessage reads the single length-prefixed message of a request body.
+func (s *Server) readMessage(body io.Reader) ([]byte, error) {
+	var prefix [5]byte
+	if _, err := io.ReadFull(body, prefix[:]); err != nil {
+		return nil, Status(CodeInvalidArgument, "missing request message")
+	}
+	if prefix[0] != 0 {
+		return nil, Status(CodeUnimplemented, "compression is not supported")
+	}
+
+	maxSize := s.MaxMessageSize
+	if maxSize == 0 {
+		maxSize = DefaultMaxMessageSize
+	}
+	size := binary.BigEndian.Uint32(prefix[1:])
+	if uint64(size) > uint64(maxSize) {
+		return nil, Status(
+			CodeResourceExhausted,
+			fmt.Sprintf("request message larger than %d bytes", maxSize),
+		)
+	}
+
+	data := make([]byte, size)
+	if _, err := io.ReadFull(body, data); err != nil {
+		return nil, Status(CodeInvalidArgument, "truncated request message")
+	}
+	if n, _ := body.Read(make([]byte, 1)); n != 0 {
+		return nil, Status(
+			CodeUnimplemented,
+			"streaming requests are not supported",
+		)
+	}
+	return data, nil
+}
+
+// contentSubtype returns the codec subtype of a gRPC content type, "proto"
+// for requests without a subtype, and false for other media types.
+func contentSubtype(contentType string) (string, bool) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return "", false
+	}
+	subtype, ok := strings.CutPrefix(mediaType, MediaTypeGRPC)
+	if !ok {
+		return "", false
+	}
+	if subtype == "" {
+		return "proto", true
+	}
+	return strings.CutPrefix(subtype, "+")
+}
+
+func (s *Server) method(path string) (*Method, bool) {
+	serviceName, methodName, ok := strings.Cut(
+		strings.TrimPrefix(path, "/"),
+		"/",
+	)
+	if !ok {
+		return nil, false
+	}
+	service, ok := s.services[serviceName]
+	if !ok {
+		return nil, false
+	}
+	for i := range service.Methods {
+		if service.Methods[i].Name == methodName {
+			return &service.Methods[i], true
+		}
+	}
+	return nil, false
+}
+
+// writeError writes the status of a failed call. Status errors are written
+// as is, context errors as CodeDeadlineExceeded and CodeCanceled, and other
+// errors are mapped with the error handler and the expected errors of the
+// method.
+func (s *Server) writeError(w http.ResponseWriter, method *Method, err error) {
+	var statusError *StatusError
+	switch {
+	case errors.As(err, &statusError):
+		writeStatus(w, statusError, nil)
+		return
+	case errors.Is(err, context.Canceled):
+		writeStatus(w, Status(CodeCanceled, err.Error()), nil)
+		return
+	case errors.Is(err, context.DeadlineExceeded):
+		writeStatus(w, Status(CodeDeadlineExceeded, err.Error()), nil)
+		return
+	}
+
+	status, apiError := s.ErrorHandler.Handle(err, method.ExpectedErrors)
+	code := CodeFromHTTPStatus(status)
+	if override, ok := s.Codes[apiError.ID]; ok {
+		code = override
+	}
+	message := apiError.ID
+	if apiError.Message != nil {
+		message = *apiError.Message
+	}
+
+	details, marshalErr := json.Marshal(apiError)
+	if marshalErr != nil {
+		details = nil
+	}
+	writeStatus(w, Status(code, message), details)
+}
+
+// writeStatus writes a trailers-only response with the status of a call and
+// the details of the API error.
+func writeStatus(w http.ResponseWriter, status *StatusError, details []byte) {
+	header := w.Header()
+	header.Set("Grpc-Status", strconv.Itoa(int(status.Code)))
+	header.Set("Grpc-Message", encodeMessage(status.Message))
+	if details != nil {
+		header.Set(
+			ErrorTrailer,
+			base64.RawStdEncoding.EncodeToString(details),
+		)
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// encodeMessage percent-encodes a status message as required by the gRPC
+// protocol.
+func encodeMessage(message string) string {
+	var builder strings.Builder
+	for i := 0; i < len(message); i++ {
+		c := message[i]
+		if c < 0x20 || c > 0x7e || c == '%' {
+			fmt.Fprintf(&builder, "%%%02X", c)
+		} else {
+			builder.WriteByte(c)
+		}
+	}
+	return builder.String()
+}
+
+// DecodeMessage decodes a percent-encoded status message.
+//
+//   - message: The value of the grpc-message trailer.
+func DecodeMessage(message string) string {
+	decoded, err := url.PathUnescape(message)
+	if err != nil {
+		return message
+	}
+	return decoded
+}
+
+// frame prefixes a message with its length.
+func frame(data []byte) []byte {
+	framed := make([]byte, 5+len(data))
+	binary.BigEndian.PutUint32(framed[1:5], uint32(len(data)))
+	copy(framed[5:], data)
+	return framed
+}
+
+var timeoutUnits = map[byte]time.Duration{
+	'H': time.Hour,
+	'M': time.Minute,
+	'S': time.Second,
+	'm': time.Millisecond,
+	'u': time.Microsecond,
+	'n': time.Nanosecond,
+}
+
+// parseTimeout parses the value of a grpc-timeout header, e.g. "100m".
+func parseTimeout(timeout string) (time.Duration, error) {
+	if len(timeout) < 2 || len(timeout) > 9 {
+		return 0, fmt.Errorf("invalid timeout: %q", timeout)
+	}
+	unit, ok := timeoutUnits[timeout[len(timeout)-1]]
+	if !ok {
+		return 0, fmt.Errorf("invalid timeout: %q", timeout)
+	}
+	value, err := strconv.ParseInt(timeout[:len(timeout)-1], 10, 64)
+	if err != nil || value < 0 {
+		return 0, fmt.Errorf("invalid timeout: %q", timeout)
+	}
+	return time.Duration(value) * unit, nil
+}
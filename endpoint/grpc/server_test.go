@@ -0,0 +1,459 @@
+package grpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/pakkasys/fluidapi/core/api"
+	"github.com/pakkasys/fluidapi/endpoint/middleware/inputlogic"
+	"github.com/pakkasys/fluidapi/endpoint/runner"
+	"github.com/stretchr/testify/assert"
+)
+
+type createUserInput struct {
+	Name string `json:"name"`
+}
+
+func (i createUserInput) Validate() []inputlogic.FieldError {
+	if i.Name == "" {
+		return []inputlogic.FieldError{{Field: "name", Message: "required"}}
+	}
+	return nil
+}
+
+type createUserOutput struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+var userExistsError = api.NewError[any]("USER_EXISTS")
+
+func createUserCallback(
+	w http.ResponseWriter,
+	r *http.Request,
+	input *createUserInput,
+) (*createUserOutput, error) {
+	if input.Name == "taken" {
+		return nil, userExistsError
+	}
+	w.Header().Set("X-Created", "true")
+	return &createUserOutput{ID: 1, Name: input.Name}, nil
+}
+
+func testService() *Service {
+	return &Service{
+		Name: "users.v1.Users",
+		Methods: []Method{NewMethod(
+			"Create",
+			runner.InputSpecification[createUserInput]{
+				URL:    "/users",
+				Method: http.MethodPost,
+			},
+			createUserCallback,
+			[]inputlogic.ExpectedError{
+				{
+					ID:         userExistsError.ID,
+					Status:     http.StatusConflict,
+					PublicData: true,
+				},
+				{
+					ID:         inputlogic.ValidationError.ID,
+					Status:     http.StatusBadRequest,
+					PublicData: true,
+				},
+			},
+		)},
+	}
+}
+
+func grpcRequest(path string, message string) *http.Request {
+	body := frame([]byte(message))
+	request := httptest.NewRequest(
+		http.MethodPost,
+		path,
+		bytes.NewReader(body),
+	)
+	request.Header.Set("Content-Type", "application/grpc+json")
+	return request
+}
+
+func serve(server *Server, request *http.Request) *http.Response {
+	recorder := httptest.NewRecorder()
+	server.ServeHTTP(recorder, request)
+	return recorder.Result()
+}
+
+// grpcStatus returns the status of a response from the trailers or, for
+// trailers-only responses, the headers.
+func grpcStatus(response *http.Response) string {
+	if status := response.Trailer.Get("Grpc-Status"); status != "" {
+		return status
+	}
+	return response.Header.Get("Grpc-Status")
+}
+
+// TestServer_ServeHTTP tests serving a unary call with the callback of an
+// endpoint.
+func TestServer_ServeHTTP(t *testing.T) {
+	server := NewServer()
+	server.Register(testService())
+
+	response := serve(server, grpcRequest(
+		"/users.v1.Users/Create",
+		`{"name":"alice"}`,
+	))
+
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(
+		t,
+		"application/grpc+json",
+		response.Header.Get("Content-Type"),
+	)
+	assert.Equal(t, "true", response.Header.Get("X-Created"))
+	assert.Equal(t, "0", grpcStatus(response))
+	body := new(bytes.Buffer)
+	body.ReadFrom(response.Body)
+	assert.Equal(t, frame([]byte(`{"id":1,"name":"alice"}`)), body.Bytes())
+}
+
+// TestServer_ServeHTTP_APIError tests mapping the API errors of the callback
+// to status codes with the expected errors of the method.
+func TestServer_ServeHTTP_APIError(t *testing.T) {
+	server := NewServer()
+	server.Register(testService())
+
+	response := serve(server, grpcRequest(
+		"/users.v1.Users/Create",
+		`{"name":"taken"}`,
+	))
+
+	assert.Equal(t, "6", grpcStatus(response))
+	assert.Equal(t, "USER_EXISTS", response.Header.Get("Grpc-Message"))
+	details, err := base64.RawStdEncoding.DecodeString(
+		response.Header.Get(ErrorTrailer),
+	)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"id":"USER_EXISTS","data":null}`, string(details))
+
+	server.Codes = map[string]Code{userExistsError.ID: CodeAborted}
+
+	response = serve(server, grpcRequest(
+		"/users.v1.Users/Create",
+		`{"name":"taken"}`,
+	))
+
+	assert.Equal(t, "10", grpcStatus(response))
+}
+
+// TestServer_ServeHTTP_ValidationError tests that invalid inputs are
+// rejected before the callback.
+func TestServer_ServeHTTP_ValidationError(t *testing.T) {
+	server := NewServer()
+	server.Register(testService())
+
+	response := serve(server, grpcRequest("/users.v1.Users/Create", `{}`))
+
+	assert.Equal(t, "3", grpcStatus(response))
+	assert.Equal(t, "VALIDATION_ERROR", response.Header.Get("Grpc-Message"))
+}
+
+// TestServer_ServeHTTP_InternalError tests that unexpected errors are masked.
+func TestServer_ServeHTTP_InternalError(t *testing.T) {
+	server := NewServer(func(
+		ctx context.Context,
+		request any,
+		info *UnaryServerInfo,
+		handler UnaryHandler,
+	) (any, error) {
+		return nil, assert.AnError
+	})
+	server.Register(testService())
+
+	response := serve(server, grpcRequest(
+		"/users.v1.Users/Create",
+		`{"name":"alice"}`,
+	))
+
+	assert.Equal(t, "13", grpcStatus(response))
+	assert.Equal(
+		t,
+		"INTERNAL_SERVER_ERROR",
+		response.Header.Get("Grpc-Message"),
+	)
+}
+
+// TestServer_ServeHTTP_Timeout tests the deadline of the grpc-timeout header.
+func TestServer_ServeHTTP_Timeout(t *testing.T) {
+	var deadline time.Time
+	server := NewServer(func(
+		ctx context.Context,
+		request any,
+		info *UnaryServerInfo,
+		handler UnaryHandler,
+	) (any, error) {
+		deadline, _ = ctx.Deadline()
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	server.Register(testService())
+	request := grpcRequest("/users.v1.Users/Create", `{"name":"alice"}`)
+	request.Header.Set("Grpc-Timeout", "10m")
+
+	response := serve(server, request)
+
+	assert.WithinDuration(t, time.Now(), deadline, time.Second)
+	assert.Equal(t, "4", grpcStatus(response))
+}
+
+// TestServer_ServeHTTP_InvalidCalls tests the statuses of invalid calls.
+func TestServer_ServeHTTP_InvalidCalls(t *testing.T) {
+	oversized := make([]byte, 5)
+	binary.BigEndian.PutUint32(oversized[1:], 11)
+	compressed := frame([]byte("{}"))
+	compressed[0] = 1
+
+	tests := []struct {
+		name     string
+		path     string
+		body     []byte
+		timeout  string
+		expected Code
+		message  string
+	}{
+		{
+			name:     "unknown method",
+			path:     "/users.v1.Users/Delete",
+			body:     frame([]byte("{}")),
+			expected: CodeUnimplemented,
+			message:  "unknown method /users.v1.Users/Delete",
+		},
+		{
+			name:     "unknown service",
+			path:     "/users.v2.Users/Create",
+			body:     frame([]byte("{}")),
+			expected: CodeUnimplemented,
+			message:  "unknown method /users.v2.Users/Create",
+		},
+		{
+			name:     "missing message",
+			path:     "/users.v1.Users/Create",
+			expected: CodeInvalidArgument,
+			message:  "missing request message",
+		},
+		{
+			name:     "compressed message",
+			path:     "/users.v1.Users/Create",
+			body:     compressed,
+			expected: CodeUnimplemented,
+			message:  "compression is not supported",
+		},
+		{
+			name:     "oversized message",
+			path:     "/users.v1.Users/Create",
+			body:     oversized,
+			expected: CodeResourceExhausted,
+			message:  "request message larger than 10 bytes",
+		},
+		{
+			name:     "truncated message",
+			path:     "/users.v1.Users/Create",
+			body:     frame([]byte("{}"))[:6],
+			expected: CodeInvalidArgument,
+			message:  "truncated request message",
+		},
+		{
+			name:     "multiple messages",
+			path:     "/users.v1.Users/Create",
+			body:     append(frame([]byte("{}")), frame([]byte("{}"))...),
+			expected: CodeUnimplemented,
+			message:  "streaming requests are not supported",
+		},
+		{
+			name:     "invalid message",
+			path:     "/users.v1.Users/Create",
+			body:     frame([]byte("[")),
+			expected: CodeInvalidArgument,
+			message:  "invalid request message",
+		},
+		{
+			name:     "invalid timeout",
+			path:     "/users.v1.Users/Create",
+			body:     frame([]byte("{}")),
+			timeout:  "1x",
+			expected: CodeInvalidArgument,
+			message:  `invalid timeout: "1x"`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := NewServer()
+			server.MaxMessageSize = 10
+			server.Register(testService())
+			request := httptest.NewRequest(
+				http.MethodPost,
+				test.path,
+				bytes.NewReader(test.body),
+			)
+			request.Header.Set("Content-Type", "application/grpc+json")
+			if test.timeout != "" {
+				request.Header.Set("Grpc-Timeout", test.timeout)
+			}
+
+			response := serve(server, request)
+
+			assert.Equal(t, http.StatusOK, response.StatusCode)
+			assert.Equal(
+				t,
+				strconv.Itoa(int(test.expected)),
+				grpcStatus(response),
+			)
+			assert.Equal(
+				t,
+				test.message,
+				DecodeMessage(response.Header.Get("Grpc-Message")),
+			)
+		})
+	}
+}
+
+// TestServer_ServeHTTP_UnsupportedSubtype tests the status of the calls of
+// content subtypes without a codec.
+func TestServer_ServeHTTP_UnsupportedSubtype(t *testing.T) {
+	tests := []struct {
+		contentType string
+		message     string
+	}{
+		{"application/grpc", `unsupported content subtype "proto"`},
+		{"application/grpc+xml", `unsupported content subtype "xml"`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.contentType, func(t *testing.T) {
+			server := NewServer()
+			server.Register(testService())
+			request := grpcRequest("/users.v1.Users/Create", `{}`)
+			request.Header.Set("Content-Type", test.contentType)
+
+			response := serve(server, request)
+
+			assert.Equal(t, http.StatusOK, response.StatusCode)
+			assert.Equal(
+				t,
+				test.contentType,
+				response.Header.Get("Content-Type"),
+			)
+			assert.Equal(
+				t,
+				strconv.Itoa(int(CodeUnimplemented)),
+				grpcStatus(response),
+			)
+			assert.Equal(
+				t,
+				test.message,
+				DecodeMessage(response.Header.Get("Grpc-Message")),
+			)
+		})
+	}
+}
+
+// TestServer_ServeHTTP_DeadlineExceeded tests the status of the calls
+// failing with a deadline error that was not caused by the grpc-timeout
+// header.
+func TestServer_ServeHTTP_DeadlineExceeded(t *testing.T) {
+	server := NewServer(func(
+		ctx context.Context,
+		request any,
+		info *UnaryServerInfo,
+		handler UnaryHandler,
+	) (any, error) {
+		return nil, fmt.Errorf("query failed: %w", context.DeadlineExceeded)
+	})
+	server.Register(testService())
+
+	response := serve(
+		server,
+		grpcRequest("/users.v1.Users/Create", `{"name":"alice"}`),
+	)
+
+	assert.Equal(
+		t,
+		strconv.Itoa(int(CodeDeadlineExceeded)),
+		grpcStatus(response),
+	)
+	assert.Equal(
+		t,
+		"query failed: context deadline exceeded",
+		DecodeMessage(response.Header.Get("Grpc-Message")),
+	)
+}
+
+// TestServer_ServeHTTP_InvalidRequests tests the HTTP errors of requests
+// that are not gRPC calls.
+func TestServer_ServeHTTP_InvalidRequests(t *testing.T) {
+	server := NewServer()
+	server.Register(testService())
+
+	response := serve(server, httptest.NewRequest(
+		http.MethodGet,
+		"/users.v1.Users/Create",
+		nil,
+	))
+
+	assert.Equal(t, http.StatusMethodNotAllowed, response.StatusCode)
+
+	request := grpcRequest("/users.v1.Users/Create", `{"name":"alice"}`)
+	request.Header.Set("Content-Type", "application/json")
+
+	response = serve(server, request)
+
+	assert.Equal(t, http.StatusUnsupportedMediaType, response.StatusCode)
+}
+
+// TestServer_Register_Panics tests the panics of duplicate registrations.
+func TestServer_Register_Panics(t *testing.T) {
+	server := NewServer()
+	server.Register(testService())
+
+	assert.PanicsWithValue(t, `service "users.v1.Users" already registered`,
+		func() { server.Register(testService()) })
+
+	service := testService()
+	service.Name = "users.v2.Users"
+	service.Methods = append(service.Methods, service.Methods[0])
+
+	assert.PanicsWithValue(
+		t,
+		`duplicate method "Create" in service "users.v2.Users"`,
+		func() { server.Register(service) },
+	)
+}
+
+// TestEncodeMessage tests percent-encoding status messages.
+func TestEncodeMessage(t *testing.T) {
+	encoded := encodeMessage("100% ok\nä")
+
+	assert.Equal(t, "100%25 ok%0A%C3%A4", encoded)
+	assert.Equal(t, "100% ok\nä", DecodeMessage(encoded))
+}
+
+// TestParseTimeout tests parsing grpc-timeout values.
+func TestParseTimeout(t *testing.T) {
+	duration, err := parseTimeout("250m")
+
+	assert.NoError(t, err)
+	assert.Equal(t, 250*time.Millisecond, duration)
+
+	for _, invalid := range []string{"", "S", "1", "-1S", "123456789S"} {
+		_, err := parseTimeout(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
@@ -0,0 +1,218 @@
+package grpc
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/pakkasys/fluidapi/core/api"
+	"github.com/pakkasys/fluidapi/endpoint/middleware/inputlogic"
+	"github.com/pakkasys/fluidapi/endpoint/runner"
+)
+
+// UnaryHandler handles a decoded request message and returns the response
+// message.
+type UnaryHandler func(ctx context.Context, request any) (any, error)
+
+// UnaryServerInfo is the information of a call passed to the interceptors.
+type UnaryServerInfo struct {
+	// FullMethod is the path of the method, e.g. "/users.v1.Users/Create"
+	FullMethod string
+	// Request is the HTTP/2 request of the call
+	Request *http.Request
+	// Header is the header metadata of the response
+	Header http.Header
+}
+
+// UnaryInterceptor intercepts the calls of unary methods, e.g. to
+// authenticate or log them. An interceptor calls the handler to continue the
+// call.
+type UnaryInterceptor func(
+	ctx context.Context,
+	request any,
+	info *UnaryServerInfo,
+	handler UnaryHandler,
+) (any, error)
+
+// Service is a gRPC service of unary methods.
+type Service struct {
+	// Name is the full name of the service, e.g. "users.v1.Users"
+	Name    string
+	Methods []Method
+}
+
+// Method is a unary method serving the callback of an endpoint.
+type Method struct {
+	// Name of the method, e.g. "Create"
+	Name string
+	// HTTPMethod and URL are the method and the URL of the HTTP endpoint of
+	// the callback
+	HTTPMethod string
+	URL        string
+	// ExpectedErrors are the expected errors of the endpoint
+	ExpectedErrors []inputlogic.ExpectedError
+
+	inputType  reflect.Type
+	outputType reflect.Type
+	newInput   func() any
+	handler    func(info *UnaryServerInfo) UnaryHandler
+}
+
+// NewMethod creates a unary method from the specification and the callback
+// of an endpoint, so the same callback is served over HTTP and gRPC. The
+// request message is decoded into a new input of the input factory and
+// validated before the interceptors are called. The callback receives the
+// HTTP/2 request of the call, and the headers it sets are sent as the header
+// metadata of the response.
+//
+//   - name: The name of the method, e.g. "Create".
+//   - specification: The input specification of the endpoint.
+//   - callback: The callback of the endpoint.
+//   - expectedErrors: The expected errors of the endpoint.
+func NewMethod[I inputlogic.ValidatedInput, O any](
+	name string,
+	specification runner.InputSpecification[I],
+	callback inputlogic.Callback[I, O],
+	expectedErrors []inputlogic.ExpectedError,
+) Method {
+	if callback == nil {
+		panic("callback cannot be nil")
+	}
+
+	return Method{
+		Name:           name,
+		HTTPMethod:     specification.Method,
+		URL:            specification.URL,
+		ExpectedErrors: expectedErrors,
+		inputType:      reflect.TypeFor[I](),
+		outputType:     reflect.TypeFor[O](),
+		newInput: func() any {
+			if specification.InputFactory != nil {
+				return specification.InputFactory()
+			}
+			return new(I)
+		},
+		handler: func(info *UnaryServerInfo) UnaryHandler {
+			return func(ctx context.Context, request any) (any, error) {
+				return callback(
+					&metadataWriter{header: info.Header},
+					info.Request.WithContext(ctx),
+					request.(*I),
+				)
+			}
+		},
+	}
+}
+
+// validate validates a decoded input.
+func (m *Method) validate(input any) error {
+	validated, ok := input.(inputlogic.ValidatedInput)
+	if !ok {
+		return nil
+	}
+	if fieldErrors := validated.Validate(); len(fieldErrors) > 0 {
+		return inputlogic.ValidationError.WithData(
+			inputlogic.ValidationErrorData{Errors: fieldErrors},
+		)
+	}
+	return nil
+}
+
+// metadataWriter is the response writer of the callbacks. The headers are
+// sent as metadata and the body is discarded.
+type metadataWriter struct {
+	header http.Header
+}
+
+func (w *metadataWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *metadataWriter) Write(data []byte) (int, error) {
+	return len(data), nil
+}
+
+func (w *metadataWriter) WriteHeader(statusCode int) {}
+
+// chainInterceptors returns a handler calling the interceptors in order
+// before the handler.
+func chainInterceptors(
+	interceptors []UnaryInterceptor,
+	info *UnaryServerInfo,
+	handler UnaryHandler,
+) UnaryHandler {
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptor := interceptors[i]
+		next := handler
+		handler = func(ctx context.Context, request any) (any, error) {
+			return interceptor(ctx, request, info, next)
+		}
+	}
+	return handler
+}
+
+// MiddlewareInterceptor returns an interceptor running HTTP middlewares
+// around the call, e.g. the authentication and rate limiting middlewares of
+// the HTTP endpoints. The middlewares receive the HTTP/2 request of the call,
+// and the context of the request passed to the next handler is the context
+// of the call. The headers set by the middlewares are sent as metadata. If a
+// middleware responds instead of calling the next handler, the call fails
+// with the status code of the HTTP status and the body as the message.
+//
+//   - middlewares: The middlewares, the first of which runs first.
+func MiddlewareInterceptor(middlewares ...api.Middleware) UnaryInterceptor {
+	return func(
+		ctx context.Context,
+		request any,
+		info *UnaryServerInfo,
+		handler UnaryHandler,
+	) (any, error) {
+		var response any
+		var err error
+		called := false
+		next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			called = true
+			response, err = handler(r.Context(), request)
+		})
+
+		recorder := &middlewareRecorder{header: info.Header}
+		api.ApplyMiddlewares(next, middlewares...).ServeHTTP(
+			recorder,
+			info.Request.WithContext(ctx),
+		)
+		if !called {
+			code := CodeFromHTTPStatus(recorder.status)
+			if code == CodeOK {
+				code = CodeUnknown
+			}
+			return nil, Status(code, strings.TrimSpace(recorder.body.String()))
+		}
+		return response, err
+	}
+}
+
+// middlewareRecorder records the response of a middleware that does not call
+// the next handler.
+type middlewareRecorder struct {
+	header http.Header
+	status int
+	body   strings.Builder
+}
+
+func (r *middlewareRecorder) Header() http.Header {
+	return r.header
+}
+
+func (r *middlewareRecorder) Write(data []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	return r.body.Write(data)
+}
+
+func (r *middlewareRecorder) WriteHeader(statusCode int) {
+	if r.status == 0 {
+		r.status = statusCode
+	}
+}
@@ -0,0 +1,134 @@
+package grpc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pakkasys/fluidapi/core/api"
+	"github.com/pakkasys/fluidapi/endpoint/runner"
+	"github.com/stretchr/testify/assert"
+)
+
+type contextKey string
+
+// TestChainInterceptors tests that the interceptors run in order before the
+// handler.
+func TestChainInterceptors(t *testing.T) {
+	var calls []string
+	interceptor := func(name string) UnaryInterceptor {
+		return func(
+			ctx context.Context,
+			request any,
+			info *UnaryServerInfo,
+			handler UnaryHandler,
+		) (any, error) {
+			calls = append(calls, name)
+			return handler(ctx, request)
+		}
+	}
+
+	handler := chainInterceptors(
+		[]UnaryInterceptor{interceptor("first"), interceptor("second")},
+		&UnaryServerInfo{},
+		func(ctx context.Context, request any) (any, error) {
+			calls = append(calls, "handler")
+			return request, nil
+		},
+	)
+	response, err := handler(context.Background(), "request")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "request", response)
+	assert.Equal(t, []string{"first", "second", "handler"}, calls)
+}
+
+// TestMiddlewareInterceptor tests running HTTP middlewares around a call.
+func TestMiddlewareInterceptor(t *testing.T) {
+	interceptor := MiddlewareInterceptor(
+		func(next http.Handler) http.Handler {
+			return http.HandlerFunc(
+				func(w http.ResponseWriter, r *http.Request) {
+					w.Header().Set("X-Rate-Limit", "10")
+					ctx := context.WithValue(
+						r.Context(),
+						contextKey("user"),
+						"alice",
+					)
+					next.ServeHTTP(w, r.WithContext(ctx))
+				},
+			)
+		},
+	)
+	info := &UnaryServerInfo{
+		Request: httptest.NewRequest(http.MethodPost, "/s/M", nil),
+		Header:  http.Header{},
+	}
+
+	response, err := interceptor(
+		context.Background(),
+		"request",
+		info,
+		func(ctx context.Context, request any) (any, error) {
+			return ctx.Value(contextKey("user")), nil
+		},
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "alice", response)
+	assert.Equal(t, "10", info.Header.Get("X-Rate-Limit"))
+}
+
+// TestMiddlewareInterceptor_Rejected tests that a middleware responding
+// instead of calling the next handler fails the call.
+func TestMiddlewareInterceptor_Rejected(t *testing.T) {
+	var middleware api.Middleware = func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "missing token", http.StatusUnauthorized)
+		})
+	}
+	called := false
+
+	response, err := MiddlewareInterceptor(middleware)(
+		context.Background(),
+		"request",
+		&UnaryServerInfo{
+			Request: httptest.NewRequest(http.MethodPost, "/s/M", nil),
+			Header:  http.Header{},
+		},
+		func(ctx context.Context, request any) (any, error) {
+			called = true
+			return nil, nil
+		},
+	)
+
+	assert.Nil(t, response)
+	assert.Equal(t, Status(CodeUnauthenticated, "missing token"), err)
+	assert.False(t, called)
+}
+
+// TestNewMethod_InputFactory tests decoding the requests into the inputs of
+// the input factory.
+func TestNewMethod_InputFactory(t *testing.T) {
+	method := NewMethod(
+		"Create",
+		runner.InputSpecification[createUserInput]{
+			InputFactory: func() *createUserInput {
+				return &createUserInput{Name: "default"}
+			},
+		},
+		createUserCallback,
+		nil,
+	)
+
+	assert.Equal(t, &createUserInput{Name: "default"}, method.newInput())
+	assert.Panics(t, func() {
+		NewMethod[createUserInput, createUserOutput](
+			"Create",
+			runner.InputSpecification[createUserInput]{},
+			nil,
+			nil,
+		)
+	})
+}
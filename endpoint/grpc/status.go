@@ -0,0 +1,116 @@
+package grpc
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// Code is a gRPC status code.
+type Code uint32
+
+const (
+	CodeOK                 Code = 0
+	CodeCanceled           Code = 1
+	CodeUnknown            Code = 2
+	CodeInvalidArgument    Code = 3
+	CodeDeadlineExceeded   Code = 4
+	CodeNotFound           Code = 5
+	CodeAlreadyExists      Code = 6
+	CodePermissionDenied   Code = 7
+	CodeResourceExhausted  Code = 8
+	CodeFailedPrecondition Code = 9
+	CodeAborted            Code = 10
+	CodeOutOfRange         Code = 11
+	CodeUnimplemented      Code = 12
+	CodeInternal           Code = 13
+	CodeUnavailable        Code = 14
+	CodeDataLoss           Code = 15
+	CodeUnauthenticated    Code = 16
+)
+
+var codeNames = map[Code]string{
+	CodeOK:                 "OK",
+	CodeCanceled:           "CANCELED",
+	CodeUnknown:            "UNKNOWN",
+	CodeInvalidArgument:    "INVALID_ARGUMENT",
+	CodeDeadlineExceeded:   "DEADLINE_EXCEEDED",
+	CodeNotFound:           "NOT_FOUND",
+	CodeAlreadyExists:      "ALREADY_EXISTS",
+	CodePermissionDenied:   "PERMISSION_DENIED",
+	CodeResourceExhausted:  "RESOURCE_EXHAUSTED",
+	CodeFailedPrecondition: "FAILED_PRECONDITION",
+	CodeAborted:            "ABORTED",
+	CodeOutOfRange:         "OUT_OF_RANGE",
+	CodeUnimplemented:      "UNIMPLEMENTED",
+	CodeInternal:           "INTERNAL",
+	CodeUnavailable:        "UNAVAILABLE",
+	CodeDataLoss:           "DATA_LOSS",
+	CodeUnauthenticated:    "UNAUTHENTICATED",
+}
+
+// String returns the name of the code, e.g. "NOT_FOUND".
+func (c Code) String() string {
+	if name, ok := codeNames[c]; ok {
+		return name
+	}
+	return fmt.Sprintf("CODE(%d)", uint32(c))
+}
+
+// CodeFromHTTPStatus returns the gRPC status code corresponding to an HTTP
+// status, e.g. CodeNotFound for 404 Not Found. It is used to map the HTTP
+// statuses of the expected errors of the endpoints to gRPC status codes.
+//
+//   - status: The HTTP status.
+func CodeFromHTTPStatus(status int) Code {
+	switch status {
+	case http.StatusOK, http.StatusCreated, http.StatusNoContent:
+		return CodeOK
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return CodeInvalidArgument
+	case http.StatusUnauthorized:
+		return CodeUnauthenticated
+	case http.StatusForbidden:
+		return CodePermissionDenied
+	case http.StatusNotFound:
+		return CodeNotFound
+	case http.StatusConflict:
+		return CodeAlreadyExists
+	case http.StatusPreconditionFailed, http.StatusPreconditionRequired:
+		return CodeFailedPrecondition
+	case http.StatusRequestEntityTooLarge, http.StatusTooManyRequests:
+		return CodeResourceExhausted
+	case http.StatusNotImplemented, http.StatusMethodNotAllowed:
+		return CodeUnimplemented
+	case http.StatusServiceUnavailable:
+		return CodeUnavailable
+	case http.StatusGatewayTimeout, http.StatusRequestTimeout:
+		return CodeDeadlineExceeded
+	}
+	if status >= 400 && status < 500 {
+		return CodeFailedPrecondition
+	}
+	if status >= 500 {
+		return CodeInternal
+	}
+	return CodeUnknown
+}
+
+// StatusError is an error with a gRPC status code. It is returned to the
+// client as is, without the error handler of the server.
+type StatusError struct {
+	Code    Code
+	Message string
+}
+
+// Status returns a new StatusError.
+//
+//   - code: The status code.
+//   - message: The status message.
+func Status(code Code, message string) *StatusError {
+	return &StatusError{Code: code, Message: message}
+}
+
+// Error returns the code and the message of the status.
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("grpc: %s: %s", e.Code, e.Message)
+}
@@ -0,0 +1,44 @@
+package grpc
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCode_String tests the names of the codes.
+func TestCode_String(t *testing.T) {
+	assert.Equal(t, "NOT_FOUND", CodeNotFound.String())
+	assert.Equal(t, "CODE(99)", Code(99).String())
+}
+
+// TestCodeFromHTTPStatus tests mapping HTTP statuses to status codes.
+func TestCodeFromHTTPStatus(t *testing.T) {
+	tests := map[int]Code{
+		http.StatusOK:                  CodeOK,
+		http.StatusBadRequest:          CodeInvalidArgument,
+		http.StatusUnauthorized:        CodeUnauthenticated,
+		http.StatusForbidden:           CodePermissionDenied,
+		http.StatusNotFound:            CodeNotFound,
+		http.StatusConflict:            CodeAlreadyExists,
+		http.StatusPreconditionFailed:  CodeFailedPrecondition,
+		http.StatusTooManyRequests:     CodeResourceExhausted,
+		http.StatusServiceUnavailable:  CodeUnavailable,
+		http.StatusGatewayTimeout:      CodeDeadlineExceeded,
+		http.StatusTeapot:              CodeFailedPrecondition,
+		http.StatusInternalServerError: CodeInternal,
+		http.StatusFound:               CodeUnknown,
+	}
+
+	for status, expected := range tests {
+		assert.Equal(t, expected, CodeFromHTTPStatus(status), status)
+	}
+}
+
+// TestStatus tests the message of a status error.
+func TestStatus(t *testing.T) {
+	err := Status(CodeNotFound, "user not found")
+
+	assert.EqualError(t, err, "grpc: NOT_FOUND: user not found")
+}
@@ -0,0 +1,118 @@
+// Package manifest assembles endpoint definitions from a declarative manifest
+// of the endpoints, e.g. a JSON or YAML file, wiring the endpoints to the
+// callbacks and the middlewares registered by name.
+package manifest
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/pakkasys/fluidapi/endpoint/middleware/inputlogic"
+)
+
+// Manifest declares the endpoints of an API, e.g.
+//
+//	{
+//	  "endpoints": [{
+//	    "name": "users.create",
+//	    "url": "/users",
+//	    "method": "POST",
+//	    "handler": "createUser",
+//	    "middlewares": [
+//	      {"id": "request_id"},
+//	      {"id": "rate_limit", "config": {"requests_per_minute": 60}}
+//	    ],
+//	    "expected_errors": [
+//	      {"id": "USER_EXISTS", "status": 409, "public_data": true}
+//	    ]
+//	  }]
+//	}
+type Manifest struct {
+	Endpoints []Endpoint `json:"endpoints" yaml:"endpoints"`
+}
+
+// Endpoint declares an endpoint.
+type Endpoint struct {
+	// Name identifies the endpoint, e.g. in links. Optional.
+	Name   string `json:"name,omitempty" yaml:"name,omitempty"`
+	URL    string `json:"url" yaml:"url"`
+	Method string `json:"method" yaml:"method"`
+	// Handler is the name of the registered handler of the endpoint
+	Handler string `json:"handler" yaml:"handler"`
+	// Middlewares are the registered middlewares of the endpoint in the
+	// order they run, before the handler
+	Middlewares []Middleware `json:"middlewares,omitempty" yaml:"middlewares,omitempty"`
+	// ExpectedErrors are the errors returned to the clients
+	ExpectedErrors []ExpectedError `json:"expected_errors,omitempty" yaml:"expected_errors,omitempty"`
+	// Permissions required to call the endpoint
+	Permissions []string `json:"permissions,omitempty" yaml:"permissions,omitempty"`
+	// Version of the endpoint, e.g. "v1"
+	Version string `json:"version,omitempty" yaml:"version,omitempty"`
+}
+
+// Middleware declares a middleware of an endpoint.
+type Middleware struct {
+	// ID is the ID of the registered middleware
+	ID string `json:"id" yaml:"id"`
+	// Config is passed to the factory of the middleware, e.g. the limits of
+	// a rate limit. Optional.
+	Config map[string]any `json:"config,omitempty" yaml:"config,omitempty"`
+}
+
+// ExpectedError declares an expected error of an endpoint.
+type ExpectedError struct {
+	ID            string  `json:"id" yaml:"id"`
+	MaskedID      *string `json:"masked_id,omitempty" yaml:"masked_id,omitempty"`
+	MaskedMessage *string `json:"masked_message,omitempty" yaml:"masked_message,omitempty"`
+	Status        int     `json:"status" yaml:"status"`
+	PublicData    bool    `json:"public_data,omitempty" yaml:"public_data,omitempty"`
+}
+
+// UnmarshalFunc decodes a manifest, e.g. json.Unmarshal or the Unmarshal
+// function of a YAML package.
+type UnmarshalFunc func(data []byte, v any) error
+
+// Parse decodes a manifest.
+//
+//   - data: The encoded manifest.
+//   - unmarshal: The function decoding the manifest. If nil, the manifest is
+//     decoded as JSON.
+func Parse(data []byte, unmarshal UnmarshalFunc) (*Manifest, error) {
+	if unmarshal == nil {
+		unmarshal = json.Unmarshal
+	}
+	manifest := &Manifest{}
+	if err := unmarshal(data, manifest); err != nil {
+		return nil, fmt.Errorf("invalid manifest: %w", err)
+	}
+	return manifest, nil
+}
+
+// LoadFile reads and decodes a manifest file.
+//
+//   - path: The path of the manifest file.
+//   - unmarshal: The function decoding the manifest. If nil, the manifest is
+//     decoded as JSON.
+func LoadFile(path string, unmarshal UnmarshalFunc) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data, unmarshal)
+}
+
+// expectedErrors converts the declared expected errors.
+func (e *Endpoint) expectedErrors() []inputlogic.ExpectedError {
+	expectedErrors := make([]inputlogic.ExpectedError, len(e.ExpectedErrors))
+	for i, expectedError := range e.ExpectedErrors {
+		expectedErrors[i] = inputlogic.ExpectedError{
+			ID:            expectedError.ID,
+			MaskedID:      expectedError.MaskedID,
+			MaskedMessage: expectedError.MaskedMessage,
+			Status:        expectedError.Status,
+			PublicData:    expectedError.PublicData,
+		}
+	}
+	return expectedErrors
+}
@@ -0,0 +1,107 @@
+package manifest
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pakkasys/fluidapi/endpoint/middleware/inputlogic"
+	"github.com/stretchr/testify/assert"
+)
+
+const testManifest = `{
+	"endpoints": [{
+		"name": "users.create",
+		"url": "/users",
+		"method": "POST",
+		"handler": "createUser",
+		"middlewares": [
+			{"id": "request_id"},
+			{"id": "rate_limit", "config": {"requests_per_minute": 60}}
+		],
+		"expected_errors": [
+			{"id": "USER_EXISTS", "status": 409, "public_data": true},
+			{"id": "DB_ERROR", "status": 500, "masked_id": "INTERNAL"}
+		],
+		"permissions": ["users:create"],
+		"version": "v1"
+	}]
+}`
+
+// TestParse tests decoding a JSON manifest.
+func TestParse(t *testing.T) {
+	manifest, err := Parse([]byte(testManifest), nil)
+
+	assert.NoError(t, err)
+	masked := "INTERNAL"
+	assert.Equal(t, &Manifest{Endpoints: []Endpoint{{
+		Name:    "users.create",
+		URL:     "/users",
+		Method:  http.MethodPost,
+		Handler: "createUser",
+		Middlewares: []Middleware{
+			{ID: "request_id"},
+			{
+				ID:     "rate_limit",
+				Config: map[string]any{"requests_per_minute": float64(60)},
+			},
+		},
+		ExpectedErrors: []ExpectedError{
+			{ID: "USER_EXISTS", Status: 409, PublicData: true},
+			{ID: "DB_ERROR", Status: 500, MaskedID: &masked},
+		},
+		Permissions: []string{"users:create"},
+		Version:     "v1",
+	}}}, manifest)
+}
+
+// TestParse_Unmarshal tests decoding a manifest with a custom unmarshal
+// function, e.g. of a YAML package.
+func TestParse_Unmarshal(t *testing.T) {
+	manifest, err := Parse(
+		[]byte("endpoints: []"),
+		func(data []byte, v any) error {
+			v.(*Manifest).Endpoints = []Endpoint{{URL: "/yaml"}}
+			return nil
+		},
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "/yaml", manifest.Endpoints[0].URL)
+
+	_, err = Parse(nil, func([]byte, any) error {
+		return errors.New("bad yaml")
+	})
+
+	assert.EqualError(t, err, "invalid manifest: bad yaml")
+}
+
+// TestLoadFile tests reading a manifest file.
+func TestLoadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "endpoints.json")
+	assert.NoError(t, os.WriteFile(path, []byte(testManifest), 0o600))
+
+	manifest, err := LoadFile(path, nil)
+
+	assert.NoError(t, err)
+	assert.Len(t, manifest.Endpoints, 1)
+
+	_, err = LoadFile(filepath.Join(t.TempDir(), "missing.json"), nil)
+
+	assert.Error(t, err)
+}
+
+// TestEndpoint_expectedErrors tests converting the expected errors of an
+// endpoint.
+func TestEndpoint_expectedErrors(t *testing.T) {
+	masked := "hidden"
+	endpoint := Endpoint{ExpectedErrors: []ExpectedError{
+		{ID: "A", Status: 400, MaskedMessage: &masked, PublicData: true},
+	}}
+
+	assert.Equal(t, []inputlogic.ExpectedError{
+		{ID: "A", Status: 400, MaskedMessage: &masked, PublicData: true},
+	}, endpoint.expectedErrors())
+}
@@ -0,0 +1,253 @@
+package manifest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/pakkasys/fluidapi/core/api"
+	"github.com/pakkasys/fluidapi/endpoint/definition"
+	"github.com/pakkasys/fluidapi/endpoint/middleware"
+	"github.com/pakkasys/fluidapi/endpoint/middleware/inputlogic"
+)
+
+// HandlerFactory creates the handler middleware of an endpoint with the
+// expected errors of the endpoint.
+type HandlerFactory func(
+	expectedErrors []inputlogic.ExpectedError,
+) *api.MiddlewareWrapper
+
+// MiddlewareFactory creates a middleware with the configuration of an
+// endpoint in the manifest.
+type MiddlewareFactory func(
+	config map[string]any,
+) (api.MiddlewareWrapper, error)
+
+// Registry holds the handlers and the middlewares referenced by name in the
+// manifests.
+type Registry struct {
+	handlers    map[string]HandlerFactory
+	middlewares map[string]MiddlewareFactory
+}
+
+// NewRegistry returns a new registry without handlers and middlewares.
+func NewRegistry() *Registry {
+	return &Registry{
+		handlers:    map[string]HandlerFactory{},
+		middlewares: map[string]MiddlewareFactory{},
+	}
+}
+
+// RegisterHandler registers a callback as the handler of the endpoints
+// referencing its name. It panics if the callback is nil or the name is
+// already registered.
+//
+// Input is the type of input to the callback.
+// Output is the type of output expected from the callback.
+//
+//   - registry: The registry.
+//   - name: The name of the handler in the manifests.
+//   - callback: The function that handles the requests.
+//   - inputFactory: A function that returns a pointer to a new input object.
+//     If nil, the input object is the zero value of Input.
+//   - opts: The options of the inputlogic middleware of the handler.
+func RegisterHandler[Input inputlogic.ValidatedInput, Output any](
+	registry *Registry,
+	name string,
+	callback inputlogic.Callback[Input, Output],
+	inputFactory func() *Input,
+	opts inputlogic.Options[Input],
+) {
+	if callback == nil {
+		panic("callback cannot be nil")
+	}
+	if inputFactory == nil {
+		inputFactory = func() *Input { return new(Input) }
+	}
+	registry.RegisterHandlerFactory(
+		name,
+		func(expectedErrors []inputlogic.ExpectedError) *api.MiddlewareWrapper {
+			return inputlogic.MiddlewareWrapper(
+				callback,
+				inputFactory,
+				expectedErrors,
+				opts,
+			)
+		},
+	)
+}
+
+// RegisterHandlerFactory registers the factory of the handler of the
+// endpoints referencing its name, e.g. a handler of the runner package. It
+// panics if the factory is nil or the name is already registered.
+//
+//   - name: The name of the handler in the manifests.
+//   - factory: The factory of the handler middleware.
+func (r *Registry) RegisterHandlerFactory(name string, factory HandlerFactory) {
+	if factory == nil {
+		panic("factory cannot be nil")
+	}
+	if _, ok := r.handlers[name]; ok {
+		panic(fmt.Sprintf("duplicate handler %q", name))
+	}
+	r.handlers[name] = factory
+}
+
+// RegisterMiddleware registers a middleware by its ID. The configuration of
+// the middleware in the manifests is ignored. It panics if the ID is empty or
+// already registered.
+//
+//   - wrapper: The middleware.
+func (r *Registry) RegisterMiddleware(wrapper api.MiddlewareWrapper) {
+	r.RegisterMiddlewareFactory(
+		wrapper.ID,
+		func(map[string]any) (api.MiddlewareWrapper, error) {
+			return wrapper, nil
+		},
+	)
+}
+
+// RegisterMiddlewareFactory registers the factory of a middleware configured
+// in the manifests, e.g. a rate limit with the limits of each endpoint. It
+// panics if the ID is empty or already registered, or the factory is nil.
+//
+//   - id: The ID of the middleware in the manifests.
+//   - factory: The factory of the middleware.
+func (r *Registry) RegisterMiddlewareFactory(
+	id string,
+	factory MiddlewareFactory,
+) {
+	if id == "" {
+		panic("middleware ID cannot be empty")
+	}
+	if factory == nil {
+		panic("factory cannot be nil")
+	}
+	if _, ok := r.middlewares[id]; ok {
+		panic(fmt.Sprintf("duplicate middleware %q", id))
+	}
+	r.middlewares[id] = factory
+}
+
+// RegisterConfiguredMiddleware registers the factory of a middleware with a
+// typed configuration. The configuration in the manifests is decoded to
+// Config by its JSON fields. It panics like RegisterMiddlewareFactory.
+//
+// Config is the type of the configuration of the middleware.
+//
+//   - registry: The registry.
+//   - id: The ID of the middleware in the manifests.
+//   - factory: The factory of the middleware.
+func RegisterConfiguredMiddleware[Config any](
+	registry *Registry,
+	id string,
+	factory func(config Config) (api.MiddlewareWrapper, error),
+) {
+	if factory == nil {
+		panic("factory cannot be nil")
+	}
+	registry.RegisterMiddlewareFactory(
+		id,
+		func(config map[string]any) (api.MiddlewareWrapper, error) {
+			var typed Config
+			data, err := json.Marshal(config)
+			if err != nil {
+				return api.MiddlewareWrapper{}, err
+			}
+			if err := json.Unmarshal(data, &typed); err != nil {
+				return api.MiddlewareWrapper{}, err
+			}
+			return factory(typed)
+		},
+	)
+}
+
+// Definitions returns the endpoint definitions of a manifest. The middleware
+// stack of each endpoint has its middlewares in the order of the manifest,
+// followed by its handler. It returns an error if an endpoint has no URL, an
+// unknown method, or references an unknown handler or middleware.
+//
+//   - manifest: The manifest of the endpoints.
+func (r *Registry) Definitions(
+	manifest *Manifest,
+) ([]definition.EndpointDefinition, error) {
+	definitions := []definition.EndpointDefinition{}
+	for _, endpoint := range manifest.Endpoints {
+		endpointDefinition, err := r.definition(endpoint)
+		if err != nil {
+			return nil, err
+		}
+		definitions = append(definitions, *endpointDefinition)
+	}
+	return definitions, nil
+}
+
+// definition returns the endpoint definition of an endpoint of a manifest.
+func (r *Registry) definition(
+	endpoint Endpoint,
+) (*definition.EndpointDefinition, error) {
+	if endpoint.URL == "" {
+		return nil, fmt.Errorf("missing URL of endpoint %s", endpoint.Method)
+	}
+	if !validMethods[endpoint.Method] {
+		return nil, fmt.Errorf(
+			"invalid method %q of endpoint %s",
+			endpoint.Method,
+			endpoint.URL,
+		)
+	}
+
+	handlerFactory, ok := r.handlers[endpoint.Handler]
+	if !ok {
+		return nil, fmt.Errorf(
+			"unknown handler %q of endpoint %s %s",
+			endpoint.Handler,
+			endpoint.Method,
+			endpoint.URL,
+		)
+	}
+
+	stack := middleware.Stack{}
+	for _, entry := range endpoint.Middlewares {
+		factory, ok := r.middlewares[entry.ID]
+		if !ok {
+			return nil, fmt.Errorf(
+				"unknown middleware %q of endpoint %s %s",
+				entry.ID,
+				endpoint.Method,
+				endpoint.URL,
+			)
+		}
+		wrapper, err := factory(entry.Config)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"invalid config of middleware %q of endpoint %s %s: %w",
+				entry.ID,
+				endpoint.Method,
+				endpoint.URL,
+				err,
+			)
+		}
+		stack = append(stack, wrapper)
+	}
+	stack = append(stack, *handlerFactory(endpoint.expectedErrors()))
+
+	return &definition.EndpointDefinition{
+		Name:            endpoint.Name,
+		URL:             endpoint.URL,
+		Method:          endpoint.Method,
+		MiddlewareStack: stack,
+		Permissions:     endpoint.Permissions,
+		Version:         endpoint.Version,
+	}, nil
+}
+
+var validMethods = map[string]bool{
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodPost:    true,
+	http.MethodPut:     true,
+	http.MethodPatch:   true,
+	http.MethodDelete:  true,
+	http.MethodOptions: true,
+}
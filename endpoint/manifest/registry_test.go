@@ -0,0 +1,279 @@
+package manifest
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pakkasys/fluidapi/core/api"
+	"github.com/pakkasys/fluidapi/endpoint/definition"
+	"github.com/pakkasys/fluidapi/endpoint/middleware"
+	"github.com/pakkasys/fluidapi/endpoint/middleware/inputlogic"
+	"github.com/stretchr/testify/assert"
+)
+
+type testInput struct {
+	Name string
+}
+
+func (i testInput) Validate() []inputlogic.FieldError {
+	return nil
+}
+
+type testObjectPicker struct{}
+
+func (p testObjectPicker) PickObject(
+	r *http.Request,
+	w http.ResponseWriter,
+	obj testInput,
+) (*testInput, error) {
+	obj.Name = r.URL.Query().Get("name")
+	return &obj, nil
+}
+
+type testOutputHandler struct {
+	out        any
+	outError   error
+	statusCode int
+}
+
+func (h *testOutputHandler) ProcessOutput(
+	w http.ResponseWriter,
+	r *http.Request,
+	out any,
+	outError error,
+	statusCode int,
+) error {
+	h.out = out
+	h.outError = outError
+	h.statusCode = statusCode
+	w.WriteHeader(statusCode)
+	return nil
+}
+
+var errUserExists = api.NewError[any]("USER_EXISTS")
+
+func testMiddleware(id string, calls *[]string) api.MiddlewareWrapper {
+	return api.MiddlewareWrapper{
+		ID: id,
+		Middleware: func(next http.Handler) http.Handler {
+			return http.HandlerFunc(
+				func(w http.ResponseWriter, r *http.Request) {
+					*calls = append(*calls, id)
+					next.ServeHTTP(w, r)
+				},
+			)
+		},
+	}
+}
+
+func testRegistry(
+	outputHandler *testOutputHandler,
+	calls *[]string,
+) *Registry {
+	registry := NewRegistry()
+	RegisterHandler(
+		registry,
+		"createUser",
+		func(
+			w http.ResponseWriter,
+			r *http.Request,
+			input *testInput,
+		) (*testInput, error) {
+			if input.Name == "taken" {
+				return nil, errUserExists
+			}
+			return input, nil
+		},
+		nil,
+		inputlogic.Options[testInput]{
+			ObjectPicker:  testObjectPicker{},
+			OutputHandler: outputHandler,
+		},
+	)
+	registry.RegisterMiddleware(testMiddleware("request_id", calls))
+	RegisterConfiguredMiddleware(
+		registry,
+		"rate_limit",
+		func(config struct {
+			RequestsPerMinute int `json:"requests_per_minute"`
+		}) (api.MiddlewareWrapper, error) {
+			if config.RequestsPerMinute <= 0 {
+				return api.MiddlewareWrapper{}, errors.New("invalid limit")
+			}
+			*calls = append(*calls, "rate_limit config")
+			return testMiddleware("rate_limit", calls), nil
+		},
+	)
+	return registry
+}
+
+// TestRegistry_Definitions tests wiring the endpoints of a manifest to the
+// registered handlers and middlewares.
+func TestRegistry_Definitions(t *testing.T) {
+	outputHandler := &testOutputHandler{}
+	calls := []string{}
+	manifest, err := Parse([]byte(testManifest), nil)
+	assert.NoError(t, err)
+
+	definitions, err := testRegistry(outputHandler, &calls).
+		Definitions(manifest)
+
+	assert.NoError(t, err)
+	assert.Len(t, definitions, 1)
+	endpoint := definitions[0]
+	assert.Equal(t, "users.create", endpoint.Name)
+	assert.Equal(t, "/users", endpoint.URL)
+	assert.Equal(t, http.MethodPost, endpoint.Method)
+	assert.Equal(t, []string{"users:create"}, endpoint.Permissions)
+	assert.Equal(t, "v1", endpoint.Version)
+	ids := []string{}
+	for _, wrapper := range endpoint.MiddlewareStack {
+		ids = append(ids, wrapper.ID)
+	}
+	assert.Equal(
+		t,
+		[]string{"request_id", "rate_limit", inputlogic.MiddlewareID},
+		ids,
+	)
+	assert.Equal(t, []any{testInput{}}, endpoint.MiddlewareStack[2].Inputs)
+
+	// The permissions of the endpoint require the authorization middleware
+	definitions[0].MiddlewareStack = append(
+		definitions[0].MiddlewareStack,
+		api.MiddlewareWrapper{
+			ID:         middleware.AuthorizationMiddlewareID,
+			Middleware: func(next http.Handler) http.Handler { return next },
+		},
+	)
+	endpoints, err := definition.EndpointDefinitionsToAPIEndpoints(definitions)
+	assert.NoError(t, err)
+	middlewares := endpoints[0].Middlewares
+	serve := func(query string) {
+		var h http.Handler = http.NotFoundHandler()
+		for i := len(middlewares) - 1; i >= 0; i-- {
+			h = middlewares[i](h)
+		}
+		h.ServeHTTP(
+			httptest.NewRecorder(),
+			httptest.NewRequest(http.MethodPost, "/users?"+query, nil),
+		)
+	}
+
+	serve("name=alice")
+
+	assert.Equal(
+		t,
+		[]string{"rate_limit config", "request_id", "rate_limit"},
+		calls,
+	)
+	assert.Equal(t, http.StatusOK, outputHandler.statusCode)
+	assert.Equal(t, &testInput{Name: "alice"}, outputHandler.out)
+
+	serve("name=taken")
+
+	assert.Equal(t, http.StatusConflict, outputHandler.statusCode)
+	apiError := outputHandler.outError.(api.APIError)
+	assert.Equal(t, "USER_EXISTS", apiError.GetID())
+}
+
+// TestRegistry_Definitions_Errors tests the errors of invalid endpoints.
+func TestRegistry_Definitions_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		endpoint Endpoint
+		err      string
+	}{
+		{
+			name:     "missing URL",
+			endpoint: Endpoint{Method: http.MethodGet},
+			err:      "missing URL of endpoint GET",
+		},
+		{
+			name:     "invalid method",
+			endpoint: Endpoint{URL: "/users", Method: "FETCH"},
+			err:      `invalid method "FETCH" of endpoint /users`,
+		},
+		{
+			name: "unknown handler",
+			endpoint: Endpoint{
+				URL:     "/users",
+				Method:  http.MethodGet,
+				Handler: "listUsers",
+			},
+			err: `unknown handler "listUsers" of endpoint GET /users`,
+		},
+		{
+			name: "unknown middleware",
+			endpoint: Endpoint{
+				URL:         "/users",
+				Method:      http.MethodPost,
+				Handler:     "createUser",
+				Middlewares: []Middleware{{ID: "cors"}},
+			},
+			err: `unknown middleware "cors" of endpoint POST /users`,
+		},
+		{
+			name: "invalid middleware config",
+			endpoint: Endpoint{
+				URL:     "/users",
+				Method:  http.MethodPost,
+				Handler: "createUser",
+				Middlewares: []Middleware{{
+					ID:     "rate_limit",
+					Config: map[string]any{"requests_per_minute": 0},
+				}},
+			},
+			err: `invalid config of middleware "rate_limit" of endpoint ` +
+				`POST /users: invalid limit`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := []string{}
+			registry := testRegistry(&testOutputHandler{}, &calls)
+
+			definitions, err := registry.Definitions(&Manifest{
+				Endpoints: []Endpoint{tt.endpoint},
+			})
+
+			assert.Nil(t, definitions)
+			assert.EqualError(t, err, tt.err)
+		})
+	}
+}
+
+// TestRegistry_Register_Panics tests the panics of invalid registrations.
+func TestRegistry_Register_Panics(t *testing.T) {
+	calls := []string{}
+	registry := testRegistry(&testOutputHandler{}, &calls)
+
+	assert.PanicsWithValue(t, "callback cannot be nil", func() {
+		RegisterHandler[testInput, testInput](
+			registry,
+			"nil",
+			nil,
+			nil,
+			inputlogic.Options[testInput]{},
+		)
+	})
+	assert.PanicsWithValue(t, `duplicate handler "createUser"`, func() {
+		registry.RegisterHandlerFactory(
+			"createUser",
+			func([]inputlogic.ExpectedError) *api.MiddlewareWrapper {
+				return nil
+			},
+		)
+	})
+	assert.PanicsWithValue(t, "factory cannot be nil", func() {
+		registry.RegisterHandlerFactory("other", nil)
+	})
+	assert.PanicsWithValue(t, `duplicate middleware "request_id"`, func() {
+		registry.RegisterMiddleware(testMiddleware("request_id", &calls))
+	})
+	assert.PanicsWithValue(t, "middleware ID cannot be empty", func() {
+		registry.RegisterMiddleware(api.MiddlewareWrapper{})
+	})
+}
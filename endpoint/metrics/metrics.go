@@ -0,0 +1,281 @@
+// Package metrics records HTTP request metrics of the endpoints and exposes
+// them in the Prometheus text format.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ContentType is the content type of the Prometheus text format.
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// DefaultBuckets are the default upper bounds of the request duration
+// histogram in seconds.
+var DefaultBuckets = []float64{
+	0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10,
+}
+
+// Recorder records the metrics of requests. It can be implemented with a
+// Prometheus or OpenTelemetry client to export the metrics elsewhere.
+type Recorder interface {
+	// RequestStarted records the start of a request.
+	RequestStarted(url string, method string)
+	// RequestFinished records the end of a request started with
+	// RequestStarted.
+	RequestFinished(
+		url string,
+		method string,
+		status int,
+		duration time.Duration,
+	)
+}
+
+// Config configures the metrics.
+type Config struct {
+	// Namespace is the prefix of the metric names, e.g. "myapp" for
+	// "myapp_http_requests_total". Optional.
+	Namespace string
+	// Buckets are the upper bounds of the request duration histogram in
+	// seconds. Defaults to DefaultBuckets.
+	Buckets []float64
+}
+
+type endpointKey struct {
+	url    string
+	method string
+}
+
+type requestKey struct {
+	endpointKey
+	status int
+}
+
+type histogram struct {
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+// Metrics is an in-memory Recorder of the request count, the request
+// duration histogram and the number of in-flight requests, labeled by the
+// endpoint URL, method and status. It serves the metrics in the Prometheus
+// text format. It is safe for concurrent use.
+type Metrics struct {
+	mu        sync.Mutex
+	namespace string
+	buckets   []float64
+	requests  map[requestKey]*histogram
+	inFlight  map[endpointKey]int64
+}
+
+// NewMetrics creates new metrics.
+//
+//   - config: The configuration of the metrics.
+func NewMetrics(config Config) *Metrics {
+	buckets := config.Buckets
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+	buckets = slices.Clone(buckets)
+	slices.Sort(buckets)
+
+	namespace := config.Namespace
+	if namespace != "" {
+		namespace += "_"
+	}
+
+	return &Metrics{
+		namespace: namespace,
+		buckets:   buckets,
+		requests:  map[requestKey]*histogram{},
+		inFlight:  map[endpointKey]int64{},
+	}
+}
+
+// RequestStarted increments the number of in-flight requests.
+//
+//   - url: The URL of the endpoint.
+//   - method: The HTTP method of the endpoint.
+func (m *Metrics) RequestStarted(url string, method string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.inFlight[endpointKey{url: url, method: method}]++
+}
+
+// RequestFinished decrements the number of in-flight requests and records
+// the request in the count and duration histogram.
+//
+//   - url: The URL of the endpoint.
+//   - method: The HTTP method of the endpoint.
+//   - status: The status code of the response.
+//   - duration: The duration of the request.
+func (m *Metrics) RequestFinished(
+	url string,
+	method string,
+	status int,
+	duration time.Duration,
+) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	endpoint := endpointKey{url: url, method: method}
+	m.inFlight[endpoint]--
+
+	key := requestKey{endpointKey: endpoint, status: status}
+	h, ok := m.requests[key]
+	if !ok {
+		h = &histogram{counts: make([]uint64, len(m.buckets))}
+		m.requests[key] = h
+	}
+	seconds := duration.Seconds()
+	for i, bucket := range m.buckets {
+		if seconds <= bucket {
+			h.counts[i]++
+		}
+	}
+	h.sum += seconds
+	h.count++
+}
+
+// ServeHTTP writes the metrics in the Prometheus text format.
+//
+//   - w: The HTTP response writer.
+//   - r: The HTTP request.
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", ContentType)
+	_ = m.Write(w)
+}
+
+// Write writes the metrics in the Prometheus text format.
+//
+//   - w: The writer.
+func (m *Metrics) Write(w io.Writer) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var b strings.Builder
+	m.writeRequests(&b)
+	m.writeDurations(&b)
+	m.writeInFlight(&b)
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func (m *Metrics) writeRequests(b *strings.Builder) {
+	name := m.namespace + "http_requests_total"
+	writeHeader(b, name, "counter", "Total number of HTTP requests.")
+	for _, key := range m.sortedRequestKeys() {
+		fmt.Fprintf(
+			b,
+			"%s{%s} %d\n",
+			name,
+			requestLabels(key),
+			m.requests[key].count,
+		)
+	}
+}
+
+func (m *Metrics) writeDurations(b *strings.Builder) {
+	name := m.namespace + "http_request_duration_seconds"
+	writeHeader(b, name, "histogram", "Duration of HTTP requests in seconds.")
+	for _, key := range m.sortedRequestKeys() {
+		h := m.requests[key]
+		labels := requestLabels(key)
+		for i, bucket := range m.buckets {
+			fmt.Fprintf(
+				b,
+				"%s_bucket{%s,le=\"%s\"} %d\n",
+				name,
+				labels,
+				formatFloat(bucket),
+				h.counts[i],
+			)
+		}
+		fmt.Fprintf(b, "%s_bucket{%s,le=\"+Inf\"} %d\n", name, labels, h.count)
+		fmt.Fprintf(b, "%s_sum{%s} %s\n", name, labels, formatFloat(h.sum))
+		fmt.Fprintf(b, "%s_count{%s} %d\n", name, labels, h.count)
+	}
+}
+
+func (m *Metrics) writeInFlight(b *strings.Builder) {
+	name := m.namespace + "http_requests_in_flight"
+	writeHeader(b, name, "gauge", "Number of HTTP requests being served.")
+	keys := make([]endpointKey, 0, len(m.inFlight))
+	for key := range m.inFlight {
+		keys = append(keys, key)
+	}
+	slices.SortFunc(keys, compareEndpointKeys)
+	for _, key := range keys {
+		fmt.Fprintf(
+			b,
+			"%s{%s} %d\n",
+			name,
+			endpointLabels(key),
+			m.inFlight[key],
+		)
+	}
+}
+
+func (m *Metrics) sortedRequestKeys() []requestKey {
+	keys := make([]requestKey, 0, len(m.requests))
+	for key := range m.requests {
+		keys = append(keys, key)
+	}
+	slices.SortFunc(keys, func(a, b requestKey) int {
+		if c := compareEndpointKeys(a.endpointKey, b.endpointKey); c != 0 {
+			return c
+		}
+		return a.status - b.status
+	})
+	return keys
+}
+
+func compareEndpointKeys(a, b endpointKey) int {
+	if c := strings.Compare(a.url, b.url); c != 0 {
+		return c
+	}
+	return strings.Compare(a.method, b.method)
+}
+
+func writeHeader(b *strings.Builder, name string, kind string, help string) {
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+func endpointLabels(key endpointKey) string {
+	return fmt.Sprintf(
+		"url=\"%s\",method=\"%s\"",
+		escapeLabelValue(key.url),
+		escapeLabelValue(key.method),
+	)
+}
+
+func requestLabels(key requestKey) string {
+	return fmt.Sprintf(
+		"%s,status=\"%d\"",
+		endpointLabels(key.endpointKey),
+		key.status,
+	)
+}
+
+var labelValueReplacer = strings.NewReplacer(
+	`\`, `\\`,
+	`"`, `\"`,
+	"\n", `\n`,
+)
+
+func escapeLabelValue(value string) string {
+	return labelValueReplacer.Replace(value)
+}
+
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
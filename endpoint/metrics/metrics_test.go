@@ -0,0 +1,71 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestMetrics tests writing the recorded metrics in the text format.
+func TestMetrics(t *testing.T) {
+	metrics := NewMetrics(Config{
+		Namespace: "app",
+		Buckets:   []float64{1, 0.1},
+	})
+
+	metrics.RequestStarted("/users", "GET")
+	metrics.RequestStarted("/users", "GET")
+	metrics.RequestFinished("/users", "GET", 200, 50*time.Millisecond)
+	metrics.RequestStarted("/users", "POST")
+	metrics.RequestFinished("/users", "POST", 500, 2*time.Second)
+
+	var b strings.Builder
+	assert.NoError(t, metrics.Write(&b))
+
+	assert.Equal(t, `# HELP app_http_requests_total Total number of HTTP requests.
+# TYPE app_http_requests_total counter
+app_http_requests_total{url="/users",method="GET",status="200"} 1
+app_http_requests_total{url="/users",method="POST",status="500"} 1
+# HELP app_http_request_duration_seconds Duration of HTTP requests in seconds.
+# TYPE app_http_request_duration_seconds histogram
+app_http_request_duration_seconds_bucket{url="/users",method="GET",status="200",le="0.1"} 1
+app_http_request_duration_seconds_bucket{url="/users",method="GET",status="200",le="1"} 1
+app_http_request_duration_seconds_bucket{url="/users",method="GET",status="200",le="+Inf"} 1
+app_http_request_duration_seconds_sum{url="/users",method="GET",status="200"} 0.05
+app_http_request_duration_seconds_count{url="/users",method="GET",status="200"} 1
+app_http_request_duration_seconds_bucket{url="/users",method="POST",status="500",le="0.1"} 0
+app_http_request_duration_seconds_bucket{url="/users",method="POST",status="500",le="1"} 0
+app_http_request_duration_seconds_bucket{url="/users",method="POST",status="500",le="+Inf"} 1
+app_http_request_duration_seconds_sum{url="/users",method="POST",status="500"} 2
+app_http_request_duration_seconds_count{url="/users",method="POST",status="500"} 1
+# HELP app_http_requests_in_flight Number of HTTP requests being served.
+# TYPE app_http_requests_in_flight gauge
+app_http_requests_in_flight{url="/users",method="GET"} 1
+app_http_requests_in_flight{url="/users",method="POST"} 0
+`, b.String())
+}
+
+// TestMetrics_ServeHTTP tests serving the metrics.
+func TestMetrics_ServeHTTP(t *testing.T) {
+	metrics := NewMetrics(Config{})
+	metrics.RequestStarted("/a\"b", "GET")
+	rr := httptest.NewRecorder()
+
+	metrics.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Equal(t, ContentType, rr.Header().Get("Content-Type"))
+	assert.Contains(
+		t,
+		rr.Body.String(),
+		`http_requests_in_flight{url="/a\"b",method="GET"} 1`,
+	)
+}
+
+// TestNewMetrics_DefaultBuckets tests the default buckets.
+func TestNewMetrics_DefaultBuckets(t *testing.T) {
+	assert.Equal(t, DefaultBuckets, NewMetrics(Config{}).buckets)
+}
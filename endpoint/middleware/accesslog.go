@@ -0,0 +1,187 @@
+package middleware
+
+import (
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/pakkasys/fluidapi/core/api"
+	"github.com/pakkasys/fluidapi/core/logging"
+	"github.com/pakkasys/fluidapi/endpoint/util"
+)
+
+const (
+	AccessLogMiddlewareID = "access_log"
+
+	// RedactedValue replaces the values of redacted fields.
+	RedactedValue = "REDACTED"
+)
+
+// AccessLogEntry is the information logged for a request.
+type AccessLogEntry struct {
+	Method        string        // HTTP method of the request.
+	Path          string        // Path of the request URL.
+	Query         url.Values    // Query parameters of the request URL.
+	Status        int           // Status code of the response.
+	Bytes         int           // Size of the response body in bytes.
+	Duration      time.Duration // Time taken to handle the request.
+	RequestID     string        // Unique identifier for the request.
+	RemoteAddress string        // Remote IP address of the client.
+	UserAgent     string        // User agent of the client.
+}
+
+// AccessLogConfig configures the access log middleware.
+type AccessLogConfig struct {
+	// RedactQueryParameters are the names of the query parameters whose values
+	// are replaced with RedactedValue, e.g. "token".
+	RedactQueryParameters []string
+	// Redact modifies the entry before it is logged, e.g. to remove personal
+	// data from the path. Optional.
+	Redact func(r *http.Request, entry *AccessLogEntry)
+	// Skip disables the logging of a request if it returns true, e.g. for
+	// health checks. Optional.
+	Skip func(r *http.Request) bool
+}
+
+// AccessLogMiddlewareWrapper creates a new MiddlewareWrapper for the Access
+// Log middleware. This middleware logs one line per request.
+//
+//   - logger: The logger of the access log.
+//   - config: The configuration of the middleware.
+func AccessLogMiddlewareWrapper(
+	logger logging.Logger,
+	config AccessLogConfig,
+) *api.MiddlewareWrapper {
+	return &api.MiddlewareWrapper{
+		ID:         AccessLogMiddlewareID,
+		Middleware: AccessLogMiddleware(logger, config),
+	}
+}
+
+// AccessLogMiddleware constructs a middleware that logs the method, path,
+// status, response size, duration, request ID and remote address of each
+// request after it has been handled. The status and size are read from the
+// ResponseWrapper if it is set, and otherwise captured by the middleware.
+// Server errors are logged at the error level and other requests at the info
+// level.
+//
+//   - logger: The logger of the access log.
+//   - config: The configuration of the middleware.
+func AccessLogMiddleware(
+	logger logging.Logger,
+	config AccessLogConfig,
+) api.Middleware {
+	if logger == nil {
+		panic("logger cannot be nil")
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if config.Skip != nil && config.Skip(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			start := time.Now()
+			aw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(aw, r)
+
+			entry := newAccessLogEntry(r, aw, time.Since(start))
+			redactQueryParameters(entry.Query, config.RedactQueryParameters)
+			if config.Redact != nil {
+				config.Redact(r, entry)
+			}
+			logAccess(r, logger, entry)
+		})
+	}
+}
+
+func newAccessLogEntry(
+	r *http.Request,
+	aw *statusWriter,
+	duration time.Duration,
+) *AccessLogEntry {
+	entry := &AccessLogEntry{
+		Method:        r.Method,
+		Path:          r.URL.Path,
+		Query:         r.URL.Query(),
+		Status:        aw.status,
+		Bytes:         aw.bytes,
+		Duration:      duration,
+		RequestID:     GetRequestID(r.Context()),
+		RemoteAddress: util.RequestIPAddress(r),
+		UserAgent:     r.UserAgent(),
+	}
+	if rw := GetResponseWrapper(r); rw != nil {
+		entry.Status = rw.StatusCode
+		entry.Bytes = len(rw.Body)
+	}
+	return entry
+}
+
+func redactQueryParameters(query url.Values, names []string) {
+	for _, name := range names {
+		values := query[name]
+		for i := range values {
+			values[i] = RedactedValue
+		}
+	}
+}
+
+func logAccess(r *http.Request, logger logging.Logger, entry *AccessLogEntry) {
+	args := []any{
+		"method", entry.Method,
+		"path", entry.Path,
+		"status", entry.Status,
+		"bytes", entry.Bytes,
+		"duration_ms", float64(entry.Duration.Microseconds()) / 1000,
+		"remote_address", entry.RemoteAddress,
+	}
+	if len(entry.Query) != 0 {
+		args = append(args, "query", entry.Query.Encode())
+	}
+	if entry.RequestID != "" {
+		args = append(args, "request_id", entry.RequestID)
+	}
+	if entry.UserAgent != "" {
+		args = append(args, "user_agent", entry.UserAgent)
+	}
+
+	if entry.Status >= http.StatusInternalServerError {
+		logger.Error(r.Context(), "Access", args...)
+	} else {
+		logger.Info(r.Context(), "Access", args...)
+	}
+}
+
+// statusWriter captures the status and size of the response.
+type statusWriter struct {
+	http.ResponseWriter
+	status      int
+	bytes       int
+	wroteHeader bool
+}
+
+func (w *statusWriter) WriteHeader(statusCode int) {
+	if !w.wroteHeader {
+		w.status = statusCode
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *statusWriter) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	n, err := w.ResponseWriter.Write(b)
+	w.bytes += n
+	return n, err
+}
+
+func (w *statusWriter) Flush() {
+	w.wroteHeader = true
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
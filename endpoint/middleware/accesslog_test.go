@@ -0,0 +1,143 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pakkasys/fluidapi/core/api"
+	"github.com/pakkasys/fluidapi/core/logging/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+// unwrapWriter is a response writer that only exposes the wrapped writer
+// through Unwrap.
+type unwrapWriter struct {
+	http.ResponseWriter
+}
+
+func (w *unwrapWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// TestAccessLogMiddlewareWrapper tests the ID of the wrapper.
+func TestAccessLogMiddlewareWrapper(t *testing.T) {
+	wrapper := AccessLogMiddlewareWrapper(&mock.Recorder{}, AccessLogConfig{})
+
+	assert.Equal(t, AccessLogMiddlewareID, wrapper.ID)
+	assert.NotNil(t, wrapper.Middleware)
+}
+
+// TestAccessLogMiddleware tests logging a request.
+func TestAccessLogMiddleware(t *testing.T) {
+	logger := &mock.Recorder{}
+	handler := AccessLogMiddleware(logger, AccessLogConfig{})(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte("created"))
+		}),
+	)
+	r := httptest.NewRequest("POST", "/users?a=1", nil)
+	r.RemoteAddr = "10.0.0.1:1234"
+	r.Header.Set("User-Agent", "test")
+
+	handler.ServeHTTP(httptest.NewRecorder(), r)
+
+	entries := logger.Entries()
+	assert.Len(t, entries, 1)
+	entry := entries[0]
+	assert.Equal(t, "info", entry.Level)
+	assert.Equal(t, "Access", entry.Message)
+	assert.Equal(t, "POST", entry.Arg("method"))
+	assert.Equal(t, "/users", entry.Arg("path"))
+	assert.Equal(t, "a=1", entry.Arg("query"))
+	assert.Equal(t, http.StatusCreated, entry.Arg("status"))
+	assert.Equal(t, 7, entry.Arg("bytes"))
+	assert.Equal(t, "10.0.0.1", entry.Arg("remote_address"))
+	assert.Equal(t, "test", entry.Arg("user_agent"))
+	assert.IsType(t, float64(0), entry.Arg("duration_ms"))
+	assert.Nil(t, entry.Arg("request_id"))
+}
+
+// TestAccessLogMiddleware_ResponseWrapper tests reading the status, size and
+// request ID set by the other middlewares.
+func TestAccessLogMiddleware_ResponseWrapper(t *testing.T) {
+	logger := &mock.Recorder{}
+	handler := api.ApplyMiddlewares(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = w.Write([]byte("error"))
+		}),
+		ContextMiddleware(),
+		RequestIDMiddleware(func() string { return "id" }),
+		AccessLogMiddleware(logger, AccessLogConfig{}),
+		ResponseWrapperMiddlewareWrapper().Middleware,
+	)
+
+	handler.ServeHTTP(
+		httptest.NewRecorder(),
+		httptest.NewRequest("GET", "/", nil),
+	)
+
+	entries := logger.Entries()
+	assert.Len(t, entries, 1)
+	assert.Equal(t, "error", entries[0].Level)
+	assert.Equal(t, http.StatusInternalServerError, entries[0].Arg("status"))
+	assert.Equal(t, 5, entries[0].Arg("bytes"))
+	assert.Equal(t, "id", entries[0].Arg("request_id"))
+}
+
+// TestAccessLogMiddleware_Redact tests redacting the logged fields.
+func TestAccessLogMiddleware_Redact(t *testing.T) {
+	logger := &mock.Recorder{}
+	config := AccessLogConfig{
+		RedactQueryParameters: []string{"token"},
+		Redact: func(r *http.Request, entry *AccessLogEntry) {
+			entry.Path = "/users/{id}"
+		},
+		Skip: func(r *http.Request) bool {
+			return r.URL.Path == "/health"
+		},
+	}
+	handler := AccessLogMiddleware(logger, config)(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}),
+	)
+
+	handler.ServeHTTP(
+		httptest.NewRecorder(),
+		httptest.NewRequest("GET", "/users/5?token=secret&a=1", nil),
+	)
+	handler.ServeHTTP(
+		httptest.NewRecorder(),
+		httptest.NewRequest("GET", "/health", nil),
+	)
+
+	entries := logger.Entries()
+	assert.Len(t, entries, 1)
+	assert.Equal(t, "/users/{id}", entries[0].Arg("path"))
+	assert.Equal(t, "a=1&token=REDACTED", entries[0].Arg("query"))
+}
+
+// TestAccessLogMiddleware_NilLogger tests that a nil logger panics.
+func TestAccessLogMiddleware_NilLogger(t *testing.T) {
+	assert.Panics(t, func() { AccessLogMiddleware(nil, AccessLogConfig{}) })
+}
+
+// TestAccessLogMiddleware_Flush tests flushing through writers that only
+// implement Unwrap.
+func TestAccessLogMiddleware_Flush(t *testing.T) {
+	handler := AccessLogMiddleware(&mock.Recorder{}, AccessLogConfig{})(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte("chunk"))
+			w.(http.Flusher).Flush()
+		}),
+	)
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(
+		&unwrapWriter{ResponseWriter: w},
+		httptest.NewRequest("GET", "/", nil),
+	)
+
+	assert.True(t, w.Flushed)
+}
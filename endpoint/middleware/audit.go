@@ -0,0 +1,228 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/pakkasys/fluidapi/core/api"
+	"github.com/pakkasys/fluidapi/core/logging"
+	"github.com/pakkasys/fluidapi/endpoint/auth"
+)
+
+const AuditMiddlewareID = "audit"
+
+// AuditOutcome is the outcome of an audited request.
+type AuditOutcome string
+
+const (
+	AuditOutcomeSuccess AuditOutcome = "success"
+	AuditOutcomeFailure AuditOutcome = "failure"
+)
+
+// AuditRecord is the audit record of a mutating request.
+type AuditRecord struct {
+	Time      time.Time     // Time when the request was received.
+	Actor     string        // Actor of the request, e.g. the user ID.
+	Method    string        // HTTP method of the request.
+	Endpoint  string        // URL pattern of the endpoint, e.g. "/users/{id}".
+	Path      string        // Path of the request URL.
+	RequestID string        // Unique identifier for the request.
+	Selectors any           // Selectors of the request set by the handler.
+	Updates   any           // Updates of the request set by the handler.
+	Status    int           // Status code of the response.
+	Outcome   AuditOutcome  // Outcome of the request.
+	Duration  time.Duration // Time taken to handle the request.
+}
+
+// AuditSink stores audit records, e.g. in a database table or a log stream.
+type AuditSink interface {
+	RecordAudit(ctx context.Context, record *AuditRecord) error
+}
+
+// AuditSinkFunc is a function implementing AuditSink.
+type AuditSinkFunc func(ctx context.Context, record *AuditRecord) error
+
+// RecordAudit calls the function.
+func (f AuditSinkFunc) RecordAudit(
+	ctx context.Context,
+	record *AuditRecord,
+) error {
+	return f(ctx, record)
+}
+
+// AuditConfig configures the audit middleware.
+type AuditConfig struct {
+	// Sink stores the audit records.
+	Sink AuditSink
+	// Methods are the audited HTTP methods. Defaults to POST, PUT, PATCH and
+	// DELETE.
+	Methods []string
+	// ActorFn returns the actor of the request. Defaults to the subject of
+	// the authenticated client.
+	ActorFn func(r *http.Request) string
+}
+
+// auditDetails are the selectors and updates of an audited request.
+type auditDetails struct {
+	mu        sync.Mutex
+	selectors any
+	updates   any
+}
+
+// record is the api.OperationRecorder of the audited request.
+func (d *auditDetails) record(selectors any, updates any) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.selectors = selectors
+	d.updates = updates
+}
+
+// AuditMiddlewareWrapper creates a new MiddlewareWrapper for the Audit
+// middleware. This middleware records the mutating requests. It can be set
+// as a group default and overridden by the endpoints of the group.
+//
+//   - config: The configuration of the middleware.
+//   - logger: The logger of the sink errors. Optional.
+func AuditMiddlewareWrapper(
+	config AuditConfig,
+	logger logging.Logger,
+) *api.MiddlewareWrapper {
+	return &api.MiddlewareWrapper{
+		ID:         AuditMiddlewareID,
+		Middleware: AuditMiddleware(config, logger),
+	}
+}
+
+// AuditMiddleware constructs a middleware that records the actor, endpoint,
+// selectors and updates, and outcome of each request with an audited method
+// after it has been handled. The selectors and updates are recorded by the
+// handler with api.RecordOperation. The status is read from the ResponseWrapper
+// if it is set, and otherwise captured by the middleware. Sink errors are
+// logged and do not affect the response.
+//
+//   - config: The configuration of the middleware.
+//   - logger: The logger of the sink errors. Optional.
+func AuditMiddleware(config AuditConfig, logger logging.Logger) api.Middleware {
+	if config.Sink == nil {
+		panic("sink cannot be nil")
+	}
+	if logger == nil {
+		logger = logging.NopLogger{}
+	}
+	if config.Methods == nil {
+		config.Methods = []string{
+			http.MethodPost,
+			http.MethodPut,
+			http.MethodPatch,
+			http.MethodDelete,
+		}
+	}
+	if config.ActorFn == nil {
+		config.ActorFn = func(r *http.Request) string {
+			return auth.Subject(r.Context())
+		}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !slices.Contains(config.Methods, r.Method) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			start := time.Now()
+			details := &auditDetails{}
+			r = r.WithContext(
+				api.WithOperationRecorder(r.Context(), details.record),
+			)
+			aw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(aw, r)
+
+			record := newAuditRecord(r, aw, details, start, config.ActorFn)
+			if err := config.Sink.RecordAudit(r.Context(), record); err != nil {
+				logger.Error(r.Context(), "Audit error", "error", err)
+			}
+		})
+	}
+}
+
+func newAuditRecord(
+	r *http.Request,
+	aw *statusWriter,
+	details *auditDetails,
+	start time.Time,
+	actorFn func(r *http.Request) string,
+) *AuditRecord {
+	details.mu.Lock()
+	defer details.mu.Unlock()
+
+	record := &AuditRecord{
+		Time:      start.UTC(),
+		Actor:     actorFn(r),
+		Method:    r.Method,
+		Endpoint:  r.Pattern,
+		Path:      r.URL.Path,
+		RequestID: GetRequestID(r.Context()),
+		Selectors: details.selectors,
+		Updates:   details.updates,
+		Status:    aw.status,
+		Duration:  time.Since(start),
+	}
+	if record.Endpoint == "" {
+		record.Endpoint = r.URL.Path
+	}
+	if rw := GetResponseWrapper(r); rw != nil {
+		record.Status = rw.StatusCode
+	}
+	record.Outcome = AuditOutcomeSuccess
+	if record.Status >= http.StatusBadRequest {
+		record.Outcome = AuditOutcomeFailure
+	}
+	return record
+}
+
+// LogAuditSink writes the audit records to a logger, e.g. a dedicated audit
+// log stream.
+type LogAuditSink struct {
+	Logger logging.Logger
+}
+
+// NewLogAuditSink returns a new log audit sink.
+//
+//   - logger: The logger of the audit records.
+func NewLogAuditSink(logger logging.Logger) *LogAuditSink {
+	if logger == nil {
+		panic("logger cannot be nil")
+	}
+	return &LogAuditSink{Logger: logger}
+}
+
+// RecordAudit logs the audit record at the info level.
+func (s *LogAuditSink) RecordAudit(
+	ctx context.Context,
+	record *AuditRecord,
+) error {
+	args := []any{
+		"actor", record.Actor,
+		"method", record.Method,
+		"endpoint", record.Endpoint,
+		"path", record.Path,
+		"status", record.Status,
+		"outcome", record.Outcome,
+		"duration_ms", float64(record.Duration.Microseconds()) / 1000,
+	}
+	if record.RequestID != "" {
+		args = append(args, "request_id", record.RequestID)
+	}
+	if record.Selectors != nil {
+		args = append(args, "selectors", record.Selectors)
+	}
+	if record.Updates != nil {
+		args = append(args, "updates", record.Updates)
+	}
+	s.Logger.Info(ctx, "Audit", args...)
+	return nil
+}
@@ -0,0 +1,198 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pakkasys/fluidapi/core/api"
+	"github.com/pakkasys/fluidapi/core/logging/mock"
+	"github.com/pakkasys/fluidapi/endpoint/auth"
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingSink collects the audit records.
+type recordingSink struct {
+	records []*AuditRecord
+	err     error
+}
+
+func (s *recordingSink) RecordAudit(
+	ctx context.Context,
+	record *AuditRecord,
+) error {
+	s.records = append(s.records, record)
+	return s.err
+}
+
+// TestAuditMiddlewareWrapper tests the ID of the wrapper.
+func TestAuditMiddlewareWrapper(t *testing.T) {
+	wrapper := AuditMiddlewareWrapper(
+		AuditConfig{Sink: &recordingSink{}},
+		nil,
+	)
+
+	assert.Equal(t, AuditMiddlewareID, wrapper.ID)
+	assert.NotNil(t, wrapper.Middleware)
+}
+
+// TestAuditMiddleware tests recording a mutating request.
+func TestAuditMiddleware(t *testing.T) {
+	sink := &recordingSink{}
+	handler := api.ApplyMiddlewares(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			api.RecordOperation(r.Context(), []string{"id = 1"}, "name")
+			w.WriteHeader(http.StatusNoContent)
+		}),
+		ContextMiddleware(),
+		RequestIDMiddleware(func() string { return "id" }),
+		func(next http.Handler) http.Handler {
+			return http.HandlerFunc(
+				func(w http.ResponseWriter, r *http.Request) {
+					auth.SetClaims(r.Context(), auth.Claims{"sub": "alice"})
+					next.ServeHTTP(w, r)
+				},
+			)
+		},
+		AuditMiddleware(AuditConfig{Sink: sink}, nil),
+	)
+
+	handler.ServeHTTP(
+		httptest.NewRecorder(),
+		httptest.NewRequest(http.MethodPut, "/users/1", nil),
+	)
+
+	assert.Len(t, sink.records, 1)
+	record := sink.records[0]
+	assert.Equal(t, "alice", record.Actor)
+	assert.Equal(t, http.MethodPut, record.Method)
+	assert.Equal(t, "/users/1", record.Endpoint)
+	assert.Equal(t, "/users/1", record.Path)
+	assert.Equal(t, "id", record.RequestID)
+	assert.Equal(t, []string{"id = 1"}, record.Selectors)
+	assert.Equal(t, "name", record.Updates)
+	assert.Equal(t, http.StatusNoContent, record.Status)
+	assert.Equal(t, AuditOutcomeSuccess, record.Outcome)
+	assert.False(t, record.Time.IsZero())
+}
+
+// TestAuditMiddleware_Failure tests recording a failed request with the
+// endpoint pattern and a custom actor.
+func TestAuditMiddleware_Failure(t *testing.T) {
+	sink := &recordingSink{}
+	mux := http.NewServeMux()
+	mux.Handle("/users/{id}", AuditMiddleware(
+		AuditConfig{
+			Sink:    sink,
+			ActorFn: func(r *http.Request) string { return "bob" },
+		},
+		nil,
+	)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusConflict)
+	})))
+
+	mux.ServeHTTP(
+		httptest.NewRecorder(),
+		httptest.NewRequest(http.MethodDelete, "/users/1", nil),
+	)
+
+	assert.Len(t, sink.records, 1)
+	record := sink.records[0]
+	assert.Equal(t, "bob", record.Actor)
+	assert.Equal(t, "/users/{id}", record.Endpoint)
+	assert.Equal(t, http.StatusConflict, record.Status)
+	assert.Equal(t, AuditOutcomeFailure, record.Outcome)
+	assert.Nil(t, record.Selectors)
+}
+
+// TestAuditMiddleware_Methods tests that only the configured methods are
+// audited.
+func TestAuditMiddleware_Methods(t *testing.T) {
+	sink := &recordingSink{}
+	handler := AuditMiddleware(
+		AuditConfig{Sink: sink, Methods: []string{http.MethodPost}},
+		nil,
+	)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	for _, method := range []string{http.MethodGet, http.MethodPut} {
+		handler.ServeHTTP(
+			httptest.NewRecorder(),
+			httptest.NewRequest(method, "/", nil),
+		)
+	}
+	assert.Empty(t, sink.records)
+
+	handler.ServeHTTP(
+		httptest.NewRecorder(),
+		httptest.NewRequest(http.MethodPost, "/", nil),
+	)
+	assert.Len(t, sink.records, 1)
+}
+
+// TestAuditMiddleware_SinkError tests logging the errors of the sink.
+func TestAuditMiddleware_SinkError(t *testing.T) {
+	logger := &mock.Recorder{}
+	handler := AuditMiddleware(
+		AuditConfig{Sink: &recordingSink{err: errors.New("sink error")}},
+		logger,
+	)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}))
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/", nil))
+
+	assert.Equal(t, "ok", w.Body.String())
+	assert.Equal(t, []string{"Audit error"}, logger.Messages())
+}
+
+// TestAuditMiddleware_NilSink tests that a nil sink panics.
+func TestAuditMiddleware_NilSink(t *testing.T) {
+	assert.PanicsWithValue(t, "sink cannot be nil", func() {
+		AuditMiddleware(AuditConfig{}, nil)
+	})
+}
+
+// TestLogAuditSink tests logging the audit records.
+func TestLogAuditSink(t *testing.T) {
+	logger := &mock.Recorder{}
+
+	err := NewLogAuditSink(logger).RecordAudit(
+		context.Background(),
+		&AuditRecord{
+			Actor:     "alice",
+			Method:    http.MethodPost,
+			Endpoint:  "/users",
+			Status:    http.StatusCreated,
+			Outcome:   AuditOutcomeSuccess,
+			RequestID: "id",
+			Updates:   "name",
+		},
+	)
+
+	assert.NoError(t, err)
+	entries := logger.Entries()
+	assert.Len(t, entries, 1)
+	assert.Equal(t, "Audit", entries[0].Message)
+	assert.Equal(t, "alice", entries[0].Arg("actor"))
+	assert.Equal(t, "/users", entries[0].Arg("endpoint"))
+	assert.Equal(t, AuditOutcomeSuccess, entries[0].Arg("outcome"))
+	assert.Equal(t, "id", entries[0].Arg("request_id"))
+	assert.Equal(t, "name", entries[0].Arg("updates"))
+	assert.Nil(t, entries[0].Arg("selectors"))
+}
+
+// TestAuditSinkFunc tests the function adapter of the sink.
+func TestAuditSinkFunc(t *testing.T) {
+	var recorded *AuditRecord
+	sink := AuditSinkFunc(func(ctx context.Context, r *AuditRecord) error {
+		recorded = r
+		return nil
+	})
+	record := &AuditRecord{Actor: "alice"}
+
+	assert.NoError(t, sink.RecordAudit(context.Background(), record))
+	assert.Same(t, record, recorded)
+}
@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/pakkasys/fluidapi/core/api"
+	"github.com/pakkasys/fluidapi/endpoint/auth"
+	"github.com/pakkasys/fluidapi/endpoint/middleware/inputlogic"
+)
+
+const AuthorizationMiddlewareID = "authorization"
+
+// AuthorizationMiddlewareWrapper creates a new MiddlewareWrapper for the
+// Authorization middleware. This middleware checks the permissions required
+// by the endpoint.
+//
+//   - authorizer: The authorizer checking the permissions, e.g. auth.RBAC.
+//   - outputHandler: The handler sending the errors to the client.
+func AuthorizationMiddlewareWrapper(
+	authorizer auth.Authorizer,
+	outputHandler inputlogic.IOutputHandler,
+) *api.MiddlewareWrapper {
+	return &api.MiddlewareWrapper{
+		ID:         AuthorizationMiddlewareID,
+		Middleware: AuthorizationMiddleware(authorizer, outputHandler),
+	}
+}
+
+// AuthorizationMiddleware constructs a middleware that checks that the
+// authenticated client has the permissions required by the endpoint, which
+// are set from the Permissions of the endpoint definition. Requests without
+// the permissions are rejected with the error of the authorizer, e.g.
+// auth.ForbiddenError, whose status is looked up in auth.Errors and the
+// error catalog. It must run after the authentication middleware.
+//
+//   - authorizer: The authorizer checking the permissions, e.g. auth.RBAC.
+//   - outputHandler: The handler sending the errors to the client.
+func AuthorizationMiddleware(
+	authorizer auth.Authorizer,
+	outputHandler inputlogic.IOutputHandler,
+) api.Middleware {
+	if authorizer == nil {
+		panic("authorizer cannot be nil")
+	}
+	if outputHandler == nil {
+		panic("output handler cannot be nil")
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			permissions := auth.RequiredPermissions(r.Context())
+			if len(permissions) == 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			err := authorizer.Authorize(r.Context(), permissions)
+			if err != nil {
+				statusCode, outError := inputlogic.ErrorHandler{}.Handle(
+					err,
+					auth.Errors,
+				)
+				_ = outputHandler.ProcessOutput(
+					w,
+					r,
+					nil,
+					outError,
+					statusCode,
+				)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pakkasys/fluidapi/endpoint/auth"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubAuthorizer returns the error for all permissions.
+type stubAuthorizer struct {
+	err         error
+	permissions []string
+}
+
+func (a *stubAuthorizer) Authorize(
+	ctx context.Context,
+	permissions []string,
+) error {
+	a.permissions = permissions
+	return a.err
+}
+
+func authorizationTestRequest(permissions ...string) *http.Request {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	if len(permissions) == 0 {
+		return r
+	}
+	return r.WithContext(
+		auth.WithRequiredPermissions(r.Context(), permissions),
+	)
+}
+
+// TestAuthorizationMiddlewareWrapper tests the ID of the middleware wrapper.
+func TestAuthorizationMiddlewareWrapper(t *testing.T) {
+	wrapper := AuthorizationMiddlewareWrapper(
+		&stubAuthorizer{},
+		timeoutOutputHandler{},
+	)
+
+	assert.Equal(t, AuthorizationMiddlewareID, wrapper.ID)
+	assert.NotNil(t, wrapper.Middleware)
+}
+
+// TestAuthorizationMiddleware tests checking the required permissions.
+func TestAuthorizationMiddleware(t *testing.T) {
+	tests := []struct {
+		name        string
+		err         error
+		permissions []string
+		statusCode  int
+		checked     []string
+	}{
+		{"no permissions", errors.New("denied"), nil, http.StatusOK, nil},
+		{
+			"authorized",
+			nil,
+			[]string{"a"},
+			http.StatusOK,
+			[]string{"a"},
+		},
+		{
+			"forbidden",
+			auth.ForbiddenError.WithMessage("missing permission: a"),
+			[]string{"a"},
+			http.StatusForbidden,
+			[]string{"a"},
+		},
+		{
+			"unauthorized",
+			auth.UnauthorizedError,
+			[]string{"a", "b"},
+			http.StatusUnauthorized,
+			[]string{"a", "b"},
+		},
+	}
+	for _, test := range tests {
+		authorizer := &stubAuthorizer{err: test.err}
+		handler := AuthorizationMiddleware(authorizer, timeoutOutputHandler{})(
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}),
+		)
+		w := httptest.NewRecorder()
+
+		handler.ServeHTTP(w, authorizationTestRequest(test.permissions...))
+
+		assert.Equal(t, test.statusCode, w.Code, test.name)
+		assert.Equal(t, test.checked, authorizer.permissions, test.name)
+	}
+}
+
+// TestAuthorizationMiddleware_Nil tests that the middleware panics without an
+// authorizer or an output handler.
+func TestAuthorizationMiddleware_Nil(t *testing.T) {
+	assert.Panics(t, func() {
+		AuthorizationMiddleware(nil, timeoutOutputHandler{})
+	})
+	assert.Panics(t, func() {
+		AuthorizationMiddleware(&stubAuthorizer{}, nil)
+	})
+}
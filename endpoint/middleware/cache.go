@@ -0,0 +1,306 @@
+package middleware
+
+import (
+	"bytes"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pakkasys/fluidapi/core/api"
+	"github.com/pakkasys/fluidapi/core/logging"
+	"github.com/pakkasys/fluidapi/endpoint/cache"
+	"github.com/pakkasys/fluidapi/endpoint/etag"
+)
+
+const (
+	CacheMiddlewareID = "cache"
+
+	defaultCacheTTL         = time.Minute
+	defaultCacheMaxBodySize = 1 << 20
+)
+
+// CacheConfig configures the response cache middleware.
+type CacheConfig struct {
+	// Store stores the cached responses, e.g. cache.NewMemoryStore.
+	Store cache.Store
+	// TTL is the time the responses are cached. Defaults to 1 minute.
+	TTL time.Duration
+	// VaryHeaders are the request headers whose values are part of the cache
+	// key, e.g. "Accept-Language". They are added to the Vary header.
+	VaryHeaders []string
+	// MaxBodySize is the maximum size of cached responses in bytes. Larger
+	// responses are not cached. Defaults to 1 MB.
+	MaxBodySize int
+	// Key returns the cache key of the request. It can be used to cache the
+	// responses of authenticated requests per client. Optional. Defaults to
+	// the URL and the vary headers.
+	Key func(r *http.Request) string
+	// Skip disables the caching of a request if it returns true. Optional.
+	Skip func(r *http.Request) bool
+}
+
+// CacheMiddlewareWrapper creates a new MiddlewareWrapper for the Cache
+// middleware. This middleware caches successful GET responses.
+//
+//   - config: The configuration of the middleware.
+//   - logger: The logger of the store errors. Optional.
+func CacheMiddlewareWrapper(
+	config CacheConfig,
+	logger logging.Logger,
+) *api.MiddlewareWrapper {
+	return &api.MiddlewareWrapper{
+		ID:         CacheMiddlewareID,
+		Middleware: CacheMiddleware(config, logger),
+	}
+}
+
+// CacheMiddleware constructs a middleware that stores the successful
+// responses of GET requests in the store and serves HEAD and GET requests
+// from it. The responses are sent with ETag and Last-Modified headers, and
+// requests with a matching If-None-Match or If-Modified-Since header are
+// answered with 304 Not Modified.
+//
+// Responses with a Set-Cookie header or a Cache-Control header containing
+// no-store or private are not cached. Requests with an Authorization or a
+// Cookie header are not cached unless the Key function is set, since their
+// responses may be private to the client. Only the headers set by the
+// handler are cached, so the headers of the outer middlewares, such as the
+// request ID, are not replayed from the cache. The response is buffered
+// until the request has been handled, unless it is larger than the maximum
+// size or flushed.
+//
+//   - config: The configuration of the middleware.
+//   - logger: The logger of the store errors. Optional.
+func CacheMiddleware(
+	config CacheConfig,
+	logger logging.Logger,
+) api.Middleware {
+	if config.Store == nil {
+		panic("store cannot be nil")
+	}
+	if logger == nil {
+		logger = logging.NopLogger{}
+	}
+	if config.TTL <= 0 {
+		config.TTL = defaultCacheTTL
+	}
+	if config.MaxBodySize <= 0 {
+		config.MaxBodySize = defaultCacheMaxBodySize
+	}
+	keyFn := config.Key
+	if keyFn == nil {
+		keyFn = func(r *http.Request) string {
+			return cacheKey(r, config.VaryHeaders)
+		}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !isCacheable(r, &config) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			for _, header := range config.VaryHeaders {
+				w.Header().Add("Vary", header)
+			}
+
+			key := keyFn(r)
+			entry, err := config.Store.Get(r.Context(), key)
+			if err != nil {
+				logger.Error(r.Context(), "Cache error", "error", err)
+			}
+			if entry != nil {
+				writeCacheEntry(w, r, entry)
+				return
+			}
+
+			cw := &cacheWriter{
+				ResponseWriter: w,
+				baseHeader:     w.Header().Clone(),
+				statusCode:     http.StatusOK,
+				maxBodySize:    config.MaxBodySize,
+			}
+			next.ServeHTTP(cw, r)
+			if cw.passThrough {
+				return
+			}
+
+			entry = cw.entry()
+			if r.Method == http.MethodGet && isStorable(entry) {
+				err := config.Store.Set(r.Context(), key, entry, config.TTL)
+				if err != nil {
+					logger.Error(r.Context(), "Cache error", "error", err)
+				}
+			}
+			writeCacheEntry(w, r, entry)
+		})
+	}
+}
+
+func isCacheable(r *http.Request, config *CacheConfig) bool {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+	if config.Key == nil && (r.Header.Get("Authorization") != "" ||
+		r.Header.Get("Cookie") != "") {
+		return false
+	}
+	return config.Skip == nil || !config.Skip(r)
+}
+
+func cacheKey(r *http.Request, varyHeaders []string) string {
+	var key strings.Builder
+	key.WriteString(r.URL.RequestURI())
+	for _, header := range varyHeaders {
+		key.WriteString("\n")
+		key.WriteString(strings.Join(r.Header.Values(header), ","))
+	}
+	return key.String()
+}
+
+func isStorable(entry *cache.Entry) bool {
+	if entry.StatusCode != http.StatusOK {
+		return false
+	}
+	if len(entry.Header.Values("Set-Cookie")) != 0 {
+		return false
+	}
+	cacheControl := strings.ToLower(entry.Header.Get("Cache-Control"))
+	return !strings.Contains(cacheControl, "no-store") &&
+		!strings.Contains(cacheControl, "private")
+}
+
+// writeCacheEntry writes the entry, or 304 Not Modified if the conditional
+// headers of the request match it.
+func writeCacheEntry(
+	w http.ResponseWriter,
+	r *http.Request,
+	entry *cache.Entry,
+) {
+	header := w.Header()
+	for key, values := range entry.Header {
+		header[key] = slices.Clone(values)
+	}
+	if entry.StatusCode == http.StatusOK {
+		header.Set("ETag", entry.ETag)
+		header.Set("Last-Modified", entry.LastModified.UTC().Format(
+			http.TimeFormat,
+		))
+		age := int(time.Since(entry.StoredAt).Seconds())
+		if age > 0 {
+			header.Set("Age", strconv.Itoa(age))
+		}
+		if isNotModified(r, entry) {
+			header.Del("Content-Type")
+			header.Del("Content-Length")
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+	}
+
+	w.WriteHeader(entry.StatusCode)
+	if r.Method != http.MethodHead {
+		_, _ = w.Write(entry.Body)
+	}
+}
+
+func isNotModified(r *http.Request, entry *cache.Entry) bool {
+	if ifNoneMatch := r.Header.Get("If-None-Match"); ifNoneMatch != "" {
+		return etag.MatchesNoneMatch(ifNoneMatch, entry.ETag)
+	}
+	since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+	return !entry.LastModified.Truncate(time.Second).After(since)
+}
+
+// cacheWriter buffers the response until the request has been handled. The
+// response is passed through once it exceeds the maximum size or is flushed.
+type cacheWriter struct {
+	http.ResponseWriter
+	baseHeader  http.Header
+	statusCode  int
+	wroteHeader bool
+	body        bytes.Buffer
+	maxBodySize int
+	passThrough bool
+}
+
+func (w *cacheWriter) WriteHeader(statusCode int) {
+	if w.passThrough {
+		w.ResponseWriter.WriteHeader(statusCode)
+		return
+	}
+	if !w.wroteHeader {
+		w.statusCode = statusCode
+		w.wroteHeader = true
+	}
+}
+
+func (w *cacheWriter) Write(b []byte) (int, error) {
+	if w.passThrough {
+		return w.ResponseWriter.Write(b)
+	}
+	w.wroteHeader = true
+	if w.body.Len()+len(b) > w.maxBodySize {
+		if err := w.startPassThrough(); err != nil {
+			return 0, err
+		}
+		return w.ResponseWriter.Write(b)
+	}
+	return w.body.Write(b)
+}
+
+func (w *cacheWriter) Flush() {
+	if !w.passThrough {
+		_ = w.startPassThrough()
+	}
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+func (w *cacheWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *cacheWriter) startPassThrough() error {
+	w.passThrough = true
+	w.ResponseWriter.WriteHeader(w.statusCode)
+	_, err := w.ResponseWriter.Write(w.body.Bytes())
+	w.body.Reset()
+	return err
+}
+
+// entry returns the buffered response as a cache entry. Only the headers
+// that the handler added or changed are kept, including the entity tag and
+// the modification time.
+func (w *cacheWriter) entry() *cache.Entry {
+	now := time.Now()
+	header := http.Header{}
+	for key, values := range w.Header() {
+		if !slices.Equal(values, w.baseHeader[key]) {
+			header[key] = slices.Clone(values)
+		}
+	}
+	tag := header.Get("ETag")
+	if tag == "" {
+		tag = etag.FromBytes(w.body.Bytes())
+	}
+	lastModified, err := http.ParseTime(header.Get("Last-Modified"))
+	if err != nil {
+		lastModified = now
+	}
+	header.Del("ETag")
+	header.Del("Last-Modified")
+	header.Del("Vary")
+	return &cache.Entry{
+		StatusCode:   w.statusCode,
+		Header:       header,
+		Body:         bytes.Clone(w.body.Bytes()),
+		ETag:         tag,
+		LastModified: lastModified,
+		StoredAt:     now,
+	}
+}
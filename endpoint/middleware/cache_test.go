@@ -0,0 +1,377 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pakkasys/fluidapi/core/logging/mock"
+	"github.com/pakkasys/fluidapi/endpoint/cache"
+	"github.com/stretchr/testify/assert"
+)
+
+// failingCacheStore fails all operations.
+type failingCacheStore struct{}
+
+func (s failingCacheStore) Get(
+	ctx context.Context,
+	key string,
+) (*cache.Entry, error) {
+	return nil, errors.New("get error")
+}
+
+func (s failingCacheStore) Set(
+	ctx context.Context,
+	key string,
+	entry *cache.Entry,
+	ttl time.Duration,
+) error {
+	return errors.New("set error")
+}
+
+func (s failingCacheStore) Delete(ctx context.Context, key string) error {
+	return errors.New("delete error")
+}
+
+// countingHandler counts the calls and writes the body.
+func countingHandler(calls *int, body string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*calls++
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write([]byte(body))
+	})
+}
+
+func serveCache(
+	handler http.Handler,
+	method string,
+	target string,
+	header map[string]string,
+) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, target, nil)
+	for key, value := range header {
+		r.Header.Set(key, value)
+	}
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	return w
+}
+
+// TestCacheMiddlewareWrapper tests the ID of the middleware wrapper.
+func TestCacheMiddlewareWrapper(t *testing.T) {
+	wrapper := CacheMiddlewareWrapper(
+		CacheConfig{Store: cache.NewMemoryStore(0)},
+		nil,
+	)
+
+	assert.Equal(t, CacheMiddlewareID, wrapper.ID)
+	assert.NotNil(t, wrapper.Middleware)
+}
+
+// TestCacheMiddleware_NilStore tests that the store is required.
+func TestCacheMiddleware_NilStore(t *testing.T) {
+	assert.PanicsWithValue(t, "store cannot be nil", func() {
+		CacheMiddleware(CacheConfig{}, nil)
+	})
+}
+
+// TestCacheMiddleware_HitAndMiss tests storing and serving responses.
+func TestCacheMiddleware_HitAndMiss(t *testing.T) {
+	calls := 0
+	handler := CacheMiddleware(
+		CacheConfig{Store: cache.NewMemoryStore(0)},
+		nil,
+	)(countingHandler(&calls, "hello"))
+
+	first := serveCache(handler, "GET", "/items?a=1", nil)
+	second := serveCache(handler, "GET", "/items?a=1", nil)
+	head := serveCache(handler, "HEAD", "/items?a=1", nil)
+	other := serveCache(handler, "GET", "/items?a=2", nil)
+
+	assert.Equal(t, 2, calls)
+	assert.Equal(t, http.StatusOK, first.Code)
+	assert.Equal(t, "hello", first.Body.String())
+	assert.NotEmpty(t, first.Header().Get("ETag"))
+	assert.NotEmpty(t, first.Header().Get("Last-Modified"))
+	assert.Equal(t, "text/plain", first.Header().Get("Content-Type"))
+	assert.Equal(t, "hello", second.Body.String())
+	assert.Equal(t, first.Header().Get("ETag"), second.Header().Get("ETag"))
+	assert.Equal(t, "text/plain", second.Header().Get("Content-Type"))
+	assert.Equal(t, http.StatusOK, head.Code)
+	assert.Empty(t, head.Body.String())
+	assert.Equal(t, "hello", other.Body.String())
+}
+
+// TestCacheMiddleware_NotModified tests answering conditional requests.
+func TestCacheMiddleware_NotModified(t *testing.T) {
+	calls := 0
+	handler := CacheMiddleware(
+		CacheConfig{Store: cache.NewMemoryStore(0)},
+		nil,
+	)(countingHandler(&calls, "hello"))
+
+	first := serveCache(handler, "GET", "/", nil)
+	tag := first.Header().Get("ETag")
+	lastModified := first.Header().Get("Last-Modified")
+
+	w := serveCache(handler, "GET", "/", map[string]string{
+		"If-None-Match": tag,
+	})
+	assert.Equal(t, http.StatusNotModified, w.Code)
+	assert.Empty(t, w.Body.String())
+	assert.Equal(t, tag, w.Header().Get("ETag"))
+	assert.Empty(t, w.Header().Get("Content-Type"))
+
+	w = serveCache(handler, "GET", "/", map[string]string{
+		"If-Modified-Since": lastModified,
+	})
+	assert.Equal(t, http.StatusNotModified, w.Code)
+
+	w = serveCache(handler, "GET", "/", map[string]string{
+		"If-None-Match":     `"other"`,
+		"If-Modified-Since": lastModified,
+	})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "hello", w.Body.String())
+	assert.Equal(t, 1, calls)
+}
+
+// TestCacheMiddleware_HandlerETag tests keeping the entity tag of the
+// handler.
+func TestCacheMiddleware_HandlerETag(t *testing.T) {
+	handler := CacheMiddleware(
+		CacheConfig{Store: cache.NewMemoryStore(0)},
+		nil,
+	)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = w.Write([]byte("hello"))
+	}))
+
+	w := serveCache(handler, "GET", "/", map[string]string{
+		"If-None-Match": `W/"v1"`,
+	})
+
+	assert.Equal(t, http.StatusNotModified, w.Code)
+	assert.Equal(t, `"v1"`, w.Header().Get("ETag"))
+}
+
+// TestCacheMiddleware_VaryHeaders tests caching per vary header.
+func TestCacheMiddleware_VaryHeaders(t *testing.T) {
+	handler := CacheMiddleware(
+		CacheConfig{
+			Store:       cache.NewMemoryStore(0),
+			VaryHeaders: []string{"Accept-Language"},
+		},
+		nil,
+	)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.Header.Get("Accept-Language")))
+	}))
+
+	en := serveCache(handler, "GET", "/", map[string]string{
+		"Accept-Language": "en",
+	})
+	fi := serveCache(handler, "GET", "/", map[string]string{
+		"Accept-Language": "fi",
+	})
+	cached := serveCache(handler, "GET", "/", map[string]string{
+		"Accept-Language": "en",
+	})
+
+	assert.Equal(t, "en", en.Body.String())
+	assert.Equal(t, "fi", fi.Body.String())
+	assert.Equal(t, "en", cached.Body.String())
+	assert.Equal(t, []string{"Accept-Language"}, cached.Header()["Vary"])
+}
+
+// TestCacheMiddleware_NotStored tests the responses and requests that are not
+// cached.
+func TestCacheMiddleware_NotStored(t *testing.T) {
+	tests := []struct {
+		name    string
+		method  string
+		header  map[string]string
+		handler http.HandlerFunc
+	}{
+		{
+			name:   "Error status",
+			method: "GET",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNotFound)
+			},
+		},
+		{
+			name:   "No store",
+			method: "GET",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Cache-Control", "no-store")
+			},
+		},
+		{
+			name:   "Set cookie",
+			method: "GET",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Set-Cookie", "a=b")
+			},
+		},
+		{
+			name:    "Authorization",
+			method:  "GET",
+			header:  map[string]string{"Authorization": "Bearer token"},
+			handler: func(w http.ResponseWriter, r *http.Request) {},
+		},
+		{
+			name:    "Cookie",
+			method:  "GET",
+			header:  map[string]string{"Cookie": "session=a"},
+			handler: func(w http.ResponseWriter, r *http.Request) {},
+		},
+		{
+			name:    "Post",
+			method:  "POST",
+			handler: func(w http.ResponseWriter, r *http.Request) {},
+		},
+		{
+			name:    "Head",
+			method:  "HEAD",
+			handler: func(w http.ResponseWriter, r *http.Request) {},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			handler := CacheMiddleware(
+				CacheConfig{Store: cache.NewMemoryStore(0)},
+				nil,
+			)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				tt.handler(w, r)
+			}))
+
+			serveCache(handler, tt.method, "/", tt.header)
+			serveCache(handler, tt.method, "/", tt.header)
+
+			assert.Equal(t, 2, calls)
+		})
+	}
+}
+
+// TestCacheMiddleware_Key tests caching authenticated requests with a
+// custom key.
+func TestCacheMiddleware_Key(t *testing.T) {
+	calls := 0
+	handler := CacheMiddleware(
+		CacheConfig{
+			Store: cache.NewMemoryStore(0),
+			Key: func(r *http.Request) string {
+				return r.Header.Get("Authorization") + r.URL.Path
+			},
+		},
+		nil,
+	)(countingHandler(&calls, "private"))
+
+	auth := map[string]string{"Authorization": "Bearer a"}
+	serveCache(handler, "GET", "/", auth)
+	serveCache(handler, "GET", "/", auth)
+	serveCache(handler, "GET", "/", map[string]string{
+		"Authorization": "Bearer b",
+	})
+
+	assert.Equal(t, 2, calls)
+}
+
+// TestCacheMiddleware_OuterHeaders tests that the headers set before the
+// handler are not replayed from the cache.
+func TestCacheMiddleware_OuterHeaders(t *testing.T) {
+	calls := 0
+	cacheHandler := CacheMiddleware(
+		CacheConfig{Store: cache.NewMemoryStore(0)},
+		nil,
+	)(countingHandler(&calls, "hello"))
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-ID", r.Header.Get("X-Test-ID"))
+		cacheHandler.ServeHTTP(w, r)
+	})
+
+	first := serveCache(handler, "GET", "/", map[string]string{
+		"X-Test-ID": "a",
+	})
+	second := serveCache(handler, "GET", "/", map[string]string{
+		"X-Test-ID": "b",
+	})
+
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, "a", first.Header().Get("X-Request-ID"))
+	assert.Equal(t, "b", second.Header().Get("X-Request-ID"))
+	assert.Equal(t, "text/plain", second.Header().Get("Content-Type"))
+}
+
+// TestCacheMiddleware_LargeBody tests that responses larger than the maximum
+// size are passed through and not cached.
+func TestCacheMiddleware_LargeBody(t *testing.T) {
+	calls := 0
+	body := strings.Repeat("a", 10)
+	handler := CacheMiddleware(
+		CacheConfig{Store: cache.NewMemoryStore(0), MaxBodySize: 8},
+		nil,
+	)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write([]byte(body[:5]))
+		_, _ = w.Write([]byte(body[5:]))
+	}))
+
+	w := serveCache(handler, "GET", "/", nil)
+	serveCache(handler, "GET", "/", nil)
+
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.Equal(t, body, w.Body.String())
+	assert.Empty(t, w.Header().Get("ETag"))
+	assert.Equal(t, 2, calls)
+}
+
+// TestCacheMiddleware_Flush tests that flushed responses are passed through
+// writers that only implement Unwrap and not cached.
+func TestCacheMiddleware_Flush(t *testing.T) {
+	calls := 0
+	handler := CacheMiddleware(
+		CacheConfig{Store: cache.NewMemoryStore(0)},
+		nil,
+	)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		_, _ = w.Write([]byte("event"))
+		w.(http.Flusher).Flush()
+	}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(&unwrapWriter{ResponseWriter: w}, httptest.NewRequest(
+		"GET",
+		"/",
+		nil,
+	))
+	serveCache(handler, "GET", "/", nil)
+
+	assert.True(t, w.Flushed)
+	assert.Equal(t, "event", w.Body.String())
+	assert.Equal(t, 2, calls)
+}
+
+// TestCacheMiddleware_StoreError tests that store errors are logged and the
+// request is handled.
+func TestCacheMiddleware_StoreError(t *testing.T) {
+	calls := 0
+	logger := &mock.Recorder{}
+	handler := CacheMiddleware(
+		CacheConfig{Store: failingCacheStore{}},
+		logger,
+	)(countingHandler(&calls, "hello"))
+
+	w := serveCache(handler, "GET", "/", nil)
+
+	assert.Equal(t, "hello", w.Body.String())
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, []string{"Cache error", "Cache error"}, logger.Messages())
+}
@@ -0,0 +1,319 @@
+package middleware
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/pakkasys/fluidapi/core/api"
+)
+
+const (
+	CompressionMiddlewareID = "compression"
+
+	defaultCompressionMinSize = 1024
+)
+
+// GzipEncoding compresses responses with gzip.
+var GzipEncoding = CompressionEncoding{
+	Name: "gzip",
+	NewWriter: func(w io.Writer) (io.WriteCloser, error) {
+		return gzip.NewWriter(w), nil
+	},
+}
+
+// DeflateEncoding compresses responses with deflate.
+var DeflateEncoding = CompressionEncoding{
+	Name: "deflate",
+	NewWriter: func(w io.Writer) (io.WriteCloser, error) {
+		return flate.NewWriter(w, flate.DefaultCompression)
+	},
+}
+
+// CompressionEncoding is a content encoding used to compress responses, e.g.
+// brotli with github.com/andybalholm/brotli:
+//
+//	middleware.CompressionEncoding{
+//		Name: "br",
+//		NewWriter: func(w io.Writer) (io.WriteCloser, error) {
+//			return brotli.NewWriter(w), nil
+//		},
+//	}
+type CompressionEncoding struct {
+	// Name of the encoding in the Accept-Encoding and Content-Encoding
+	// headers.
+	Name string
+	// NewWriter returns a writer compressing the data written to w.
+	NewWriter func(w io.Writer) (io.WriteCloser, error)
+}
+
+// CompressionConfig configures the compression middleware.
+type CompressionConfig struct {
+	// Encodings in the order of preference. Defaults to gzip and deflate.
+	Encodings []CompressionEncoding
+	// MinSize is the minimum size of compressed responses in bytes. Defaults
+	// to 1024.
+	MinSize int
+	// SkipContentTypes are the prefixes of the content types that are not
+	// compressed. Defaults to the types of already compressed content such as
+	// images and archives.
+	SkipContentTypes []string
+	// Skip disables the compression of a request if it returns true, e.g. for
+	// endpoints serving event streams. Optional.
+	Skip func(r *http.Request) bool
+}
+
+var defaultSkipContentTypes = []string{
+	"image/jpeg",
+	"image/png",
+	"image/gif",
+	"image/webp",
+	"image/avif",
+	"video/",
+	"audio/",
+	"font/woff",
+	"application/zip",
+	"application/gzip",
+	"application/x-gzip",
+	"application/x-7z-compressed",
+	"application/x-rar-compressed",
+	"application/zstd",
+	"text/event-stream",
+}
+
+// CompressionMiddlewareWrapper creates a new MiddlewareWrapper for the
+// Compression middleware. The middleware can be enabled for an endpoint by
+// adding it to the stack of the endpoint, or for all endpoints with the Skip
+// function of the config disabling it for some of them.
+//
+//   - config: The configuration of the middleware.
+func CompressionMiddlewareWrapper(
+	config CompressionConfig,
+) *api.MiddlewareWrapper {
+	return &api.MiddlewareWrapper{
+		ID:         CompressionMiddlewareID,
+		Middleware: CompressionMiddleware(config),
+	}
+}
+
+// CompressionMiddleware constructs a middleware that compresses responses with
+// the encoding negotiated from the Accept-Encoding header. Responses smaller
+// than the minimum size, responses with a Content-Encoding and responses with
+// skipped content types are not compressed.
+//
+//   - config: The configuration of the middleware.
+func CompressionMiddleware(config CompressionConfig) api.Middleware {
+	if len(config.Encodings) == 0 {
+		config.Encodings = []CompressionEncoding{GzipEncoding, DeflateEncoding}
+	}
+	if config.MinSize <= 0 {
+		config.MinSize = defaultCompressionMinSize
+	}
+	if config.SkipContentTypes == nil {
+		config.SkipContentTypes = defaultSkipContentTypes
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("Vary", "Accept-Encoding")
+
+			if config.Skip != nil && config.Skip(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			encoding := negotiateEncoding(
+				r.Header.Get("Accept-Encoding"),
+				config.Encodings,
+			)
+			if encoding == nil || r.Method == http.MethodHead {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			cw := &compressionWriter{
+				ResponseWriter: w,
+				encoding:       encoding,
+				config:         &config,
+				statusCode:     http.StatusOK,
+			}
+			defer cw.close()
+			next.ServeHTTP(cw, r)
+		})
+	}
+}
+
+// negotiateEncoding returns the encoding the client prefers, or nil if the
+// client accepts none of the encodings.
+func negotiateEncoding(
+	acceptEncoding string,
+	encodings []CompressionEncoding,
+) *CompressionEncoding {
+	qualities := map[string]float64{}
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		name, parameters, _ := strings.Cut(part, ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		quality := 1.0
+		for _, parameter := range strings.Split(parameters, ";") {
+			key, value, _ := strings.Cut(strings.TrimSpace(parameter), "=")
+			if key != "q" {
+				continue
+			}
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err == nil {
+				quality = parsed
+			}
+		}
+		qualities[name] = quality
+	}
+
+	var best *CompressionEncoding
+	bestQuality := 0.0
+	for i := range encodings {
+		quality, ok := qualities[encodings[i].Name]
+		if !ok {
+			quality = qualities["*"]
+		}
+		if quality > bestQuality {
+			best = &encodings[i]
+			bestQuality = quality
+		}
+	}
+	return best
+}
+
+// compressionWriter buffers the response until the minimum size is reached
+// and then decides whether to compress it.
+type compressionWriter struct {
+	http.ResponseWriter
+	encoding    *CompressionEncoding
+	config      *CompressionConfig
+	statusCode  int
+	wroteHeader bool
+	decided     bool
+	buffer      []byte
+	writer      io.WriteCloser
+}
+
+func (w *compressionWriter) WriteHeader(statusCode int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	w.statusCode = statusCode
+	if statusCode < http.StatusOK || statusCode == http.StatusNoContent ||
+		statusCode == http.StatusNotModified {
+		w.decide(false)
+	}
+}
+
+func (w *compressionWriter) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	if w.decided {
+		if w.writer != nil {
+			return w.writer.Write(b)
+		}
+		return w.ResponseWriter.Write(b)
+	}
+
+	w.buffer = append(w.buffer, b...)
+	if len(w.buffer) >= w.config.MinSize {
+		if err := w.start(); err != nil {
+			return 0, err
+		}
+	}
+	return len(b), nil
+}
+
+func (w *compressionWriter) Flush() {
+	if !w.decided {
+		_ = w.start()
+	}
+	if flusher, ok := w.writer.(interface{ Flush() error }); ok {
+		_ = flusher.Flush()
+	}
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+func (w *compressionWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// start decides whether to compress the response and writes the buffer.
+func (w *compressionWriter) start() error {
+	if err := w.decide(w.compressible()); err != nil {
+		return err
+	}
+	buffer := w.buffer
+	w.buffer = nil
+	if len(buffer) == 0 {
+		return nil
+	}
+	if w.writer != nil {
+		_, err := w.writer.Write(buffer)
+		return err
+	}
+	_, err := w.ResponseWriter.Write(buffer)
+	return err
+}
+
+func (w *compressionWriter) decide(compress bool) error {
+	if w.decided {
+		return nil
+	}
+	w.decided = true
+
+	if compress {
+		writer, err := w.encoding.NewWriter(w.ResponseWriter)
+		if err != nil {
+			return err
+		}
+		w.writer = writer
+		header := w.Header()
+		header.Set("Content-Encoding", w.encoding.Name)
+		header.Del("Content-Length")
+	}
+	w.ResponseWriter.WriteHeader(w.statusCode)
+	return nil
+}
+
+func (w *compressionWriter) compressible() bool {
+	header := w.Header()
+	if header.Get("Content-Encoding") != "" {
+		return false
+	}
+	contentType := strings.ToLower(header.Get("Content-Type"))
+	if contentType == "" {
+		contentType = http.DetectContentType(w.buffer)
+	}
+	return !slices.ContainsFunc(
+		w.config.SkipContentTypes,
+		func(prefix string) bool {
+			return strings.HasPrefix(contentType, prefix)
+		},
+	)
+}
+
+// close writes the buffered response uncompressed if the minimum size was not
+// reached and closes the compressing writer.
+func (w *compressionWriter) close() {
+	if !w.decided {
+		if !w.wroteHeader && len(w.buffer) == 0 {
+			return
+		}
+		_ = w.decide(false)
+		if len(w.buffer) > 0 {
+			_, _ = w.ResponseWriter.Write(w.buffer)
+		}
+		return
+	}
+	if w.writer != nil {
+		_ = w.writer.Close()
+	}
+}
@@ -0,0 +1,171 @@
+package middleware
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func compressionTestHandler(
+	config CompressionConfig,
+	contentType string,
+	body string,
+) http.Handler {
+	return CompressionMiddleware(config)(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if contentType != "" {
+				w.Header().Set("Content-Type", contentType)
+			}
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(body[:len(body)/2]))
+			_, _ = w.Write([]byte(body[len(body)/2:]))
+		},
+	))
+}
+
+func compressionTestRequest(acceptEncoding string) *http.Request {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Accept-Encoding", acceptEncoding)
+	return r
+}
+
+// TestCompressionMiddlewareWrapper tests the ID of the middleware wrapper.
+func TestCompressionMiddlewareWrapper(t *testing.T) {
+	wrapper := CompressionMiddlewareWrapper(CompressionConfig{})
+
+	assert.Equal(t, CompressionMiddlewareID, wrapper.ID)
+	assert.NotNil(t, wrapper.Middleware)
+}
+
+// TestCompressionMiddleware_Gzip tests compressing large responses.
+func TestCompressionMiddleware_Gzip(t *testing.T) {
+	body := strings.Repeat("a", 2000)
+	w := httptest.NewRecorder()
+
+	compressionTestHandler(CompressionConfig{}, "application/json", body).
+		ServeHTTP(w, compressionTestRequest("deflate;q=0.5, gzip"))
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+	assert.Equal(t, "Accept-Encoding", w.Header().Get("Vary"))
+	reader, err := gzip.NewReader(w.Body)
+	assert.NoError(t, err)
+	decompressed, err := io.ReadAll(reader)
+	assert.NoError(t, err)
+	assert.Equal(t, body, string(decompressed))
+}
+
+// TestCompressionMiddleware_Deflate tests compressing with the preferred
+// encoding of the client.
+func TestCompressionMiddleware_Deflate(t *testing.T) {
+	body := strings.Repeat("b", 100)
+	w := httptest.NewRecorder()
+
+	compressionTestHandler(CompressionConfig{MinSize: 10}, "", body).
+		ServeHTTP(w, compressionTestRequest("gzip;q=0.2, *"))
+
+	assert.Equal(t, "deflate", w.Header().Get("Content-Encoding"))
+	decompressed, err := io.ReadAll(flate.NewReader(w.Body))
+	assert.NoError(t, err)
+	assert.Equal(t, body, string(decompressed))
+}
+
+// TestCompressionMiddleware_NotCompressed tests the responses that are not
+// compressed.
+func TestCompressionMiddleware_NotCompressed(t *testing.T) {
+	large := strings.Repeat("c", 2000)
+	tests := []struct {
+		name           string
+		config         CompressionConfig
+		contentType    string
+		body           string
+		acceptEncoding string
+	}{
+		{"small", CompressionConfig{}, "text/plain", "small", "gzip"},
+		{"not accepted", CompressionConfig{}, "text/plain", large, "br"},
+		{"rejected", CompressionConfig{}, "text/plain", large, "gzip;q=0"},
+		{"compressed type", CompressionConfig{}, "image/png", large, "gzip"},
+		{
+			"skipped",
+			CompressionConfig{Skip: func(*http.Request) bool { return true }},
+			"text/plain",
+			large,
+			"gzip",
+		},
+	}
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+
+		compressionTestHandler(test.config, test.contentType, test.body).
+			ServeHTTP(w, compressionTestRequest(test.acceptEncoding))
+
+		assert.Equal(t, http.StatusCreated, w.Code, test.name)
+		assert.Empty(t, w.Header().Get("Content-Encoding"), test.name)
+		assert.Equal(t, test.body, w.Body.String(), test.name)
+	}
+}
+
+// TestCompressionMiddleware_ContentEncoding tests that responses that are
+// already encoded are not compressed again.
+func TestCompressionMiddleware_ContentEncoding(t *testing.T) {
+	body := strings.Repeat("d", 2000)
+	handler := CompressionMiddleware(CompressionConfig{})(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Encoding", "br")
+			_, _ = w.Write([]byte(body))
+		},
+	))
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, compressionTestRequest("gzip"))
+
+	assert.Equal(t, "br", w.Header().Get("Content-Encoding"))
+	assert.Equal(t, body, w.Body.String())
+}
+
+// TestCompressionMiddleware_Flush tests that flushing starts the compression
+// and flushes through writers that only implement Unwrap.
+func TestCompressionMiddleware_Flush(t *testing.T) {
+	handler := CompressionMiddleware(CompressionConfig{})(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/plain")
+			_, _ = w.Write([]byte("event"))
+			w.(http.Flusher).Flush()
+		},
+	))
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(
+		&unwrapWriter{ResponseWriter: w},
+		compressionTestRequest("gzip"),
+	)
+
+	assert.True(t, w.Flushed)
+	assert.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+	reader, err := gzip.NewReader(w.Body)
+	assert.NoError(t, err)
+	decompressed, err := io.ReadAll(reader)
+	assert.NoError(t, err)
+	assert.Equal(t, "event", string(decompressed))
+}
+
+// TestCompressionMiddleware_NoContent tests responses without a body.
+func TestCompressionMiddleware_NoContent(t *testing.T) {
+	handler := CompressionMiddleware(CompressionConfig{})(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNoContent)
+		},
+	))
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, compressionTestRequest("gzip"))
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Empty(t, w.Header().Get("Content-Encoding"))
+}
@@ -0,0 +1,131 @@
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/pakkasys/fluidapi/core/api"
+	"github.com/pakkasys/fluidapi/endpoint/middleware/inputlogic"
+)
+
+const ConcurrencyLimitMiddlewareID = "concurrency_limit"
+
+const defaultRetryAfter = time.Second
+
+// ConcurrencyLimitConfig configures the concurrency limit middleware.
+type ConcurrencyLimitConfig struct {
+	// MaxConcurrent is the maximum number of requests handled at the same
+	// time.
+	MaxConcurrent int
+	// MaxWait is the time a request waits for a free slot before it is shed.
+	// If zero, the excess requests are shed immediately.
+	MaxWait time.Duration
+	// RetryAfter is sent in the Retry-After header of the shed requests,
+	// rounded up to whole seconds. Defaults to 1 second.
+	RetryAfter time.Duration
+	// OnShed is called for each shed request, e.g. to count them. Optional.
+	OnShed func(r *http.Request)
+}
+
+// ConcurrencyLimitMiddlewareWrapper creates a new MiddlewareWrapper for the
+// Concurrency Limit middleware. This middleware caps the number of requests
+// in flight.
+//
+//   - config: The configuration of the middleware.
+//   - outputHandler: The handler sending the errors to the client.
+func ConcurrencyLimitMiddlewareWrapper(
+	config ConcurrencyLimitConfig,
+	outputHandler inputlogic.IOutputHandler,
+) *api.MiddlewareWrapper {
+	return &api.MiddlewareWrapper{
+		ID:         ConcurrencyLimitMiddlewareID,
+		Middleware: ConcurrencyLimitMiddleware(config, outputHandler),
+	}
+}
+
+// ConcurrencyLimitMiddleware constructs a middleware that limits the number
+// of requests handled at the same time, protecting the database from
+// overload during traffic spikes. The excess requests wait up to MaxWait for
+// a free slot and are then shed with inputlogic.ServiceUnavailableError, a
+// 503 Service Unavailable status and a Retry-After header.
+//
+// Each middleware has its own limit, so a middleware is created for each
+// endpoint to limit the endpoints separately, or shared by the endpoints to
+// limit them together.
+//
+//   - config: The configuration of the middleware.
+//   - outputHandler: The handler sending the errors to the client.
+func ConcurrencyLimitMiddleware(
+	config ConcurrencyLimitConfig,
+	outputHandler inputlogic.IOutputHandler,
+) api.Middleware {
+	if config.MaxConcurrent <= 0 {
+		panic("max concurrent must be positive")
+	}
+	if outputHandler == nil {
+		panic("output handler cannot be nil")
+	}
+	if config.RetryAfter <= 0 {
+		config.RetryAfter = defaultRetryAfter
+	}
+	retryAfter := strconv.Itoa(int(math.Ceil(config.RetryAfter.Seconds())))
+	semaphore := make(chan struct{}, config.MaxConcurrent)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			acquired, cancelled := acquire(r, semaphore, config.MaxWait)
+			if cancelled {
+				// The client cancelled the request while waiting.
+				return
+			}
+			if !acquired {
+				if config.OnShed != nil {
+					config.OnShed(r)
+				}
+				w.Header().Set("Retry-After", retryAfter)
+				_ = outputHandler.ProcessOutput(
+					w,
+					r,
+					nil,
+					inputlogic.ServiceUnavailableError,
+					http.StatusServiceUnavailable,
+				)
+				return
+			}
+			defer func() { <-semaphore }()
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// acquire takes a slot of the semaphore, waiting up to maxWait. It returns
+// whether a slot was taken and whether the request was cancelled while
+// waiting.
+func acquire(
+	r *http.Request,
+	semaphore chan struct{},
+	maxWait time.Duration,
+) (bool, bool) {
+	select {
+	case semaphore <- struct{}{}:
+		return true, false
+	default:
+	}
+	if maxWait <= 0 {
+		return false, false
+	}
+
+	timer := time.NewTimer(maxWait)
+	defer timer.Stop()
+	select {
+	case semaphore <- struct{}{}:
+		return true, false
+	case <-timer.C:
+		return false, false
+	case <-r.Context().Done():
+		return false, true
+	}
+}
@@ -0,0 +1,159 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// blockingHandler returns a handler blocking until release is closed. Each
+// started request is signalled on started.
+func blockingHandler(
+	started chan<- struct{},
+	release <-chan struct{},
+) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		started <- struct{}{}
+		<-release
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+// TestConcurrencyLimitMiddlewareWrapper tests the ID of the middleware
+// wrapper.
+func TestConcurrencyLimitMiddlewareWrapper(t *testing.T) {
+	wrapper := ConcurrencyLimitMiddlewareWrapper(
+		ConcurrencyLimitConfig{MaxConcurrent: 1},
+		timeoutOutputHandler{},
+	)
+
+	assert.Equal(t, ConcurrencyLimitMiddlewareID, wrapper.ID)
+	assert.NotNil(t, wrapper.Middleware)
+}
+
+// TestConcurrencyLimitMiddleware_InvalidConfig tests the required
+// configuration.
+func TestConcurrencyLimitMiddleware_InvalidConfig(t *testing.T) {
+	assert.PanicsWithValue(t, "max concurrent must be positive", func() {
+		ConcurrencyLimitMiddleware(
+			ConcurrencyLimitConfig{},
+			timeoutOutputHandler{},
+		)
+	})
+	assert.PanicsWithValue(t, "output handler cannot be nil", func() {
+		ConcurrencyLimitMiddleware(
+			ConcurrencyLimitConfig{MaxConcurrent: 1},
+			nil,
+		)
+	})
+}
+
+// TestConcurrencyLimitMiddleware_Shed tests that the excess requests are
+// shed with a 503 status and a Retry-After header.
+func TestConcurrencyLimitMiddleware_Shed(t *testing.T) {
+	started := make(chan struct{}, 2)
+	release := make(chan struct{})
+	shed := 0
+	handler := ConcurrencyLimitMiddleware(
+		ConcurrencyLimitConfig{
+			MaxConcurrent: 2,
+			RetryAfter:    1500 * time.Millisecond,
+			OnShed:        func(r *http.Request) { shed++ },
+		},
+		timeoutOutputHandler{},
+	)(blockingHandler(started, release))
+
+	var wg sync.WaitGroup
+	for range 2 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+			assert.Equal(t, http.StatusOK, w.Code)
+		}()
+	}
+	<-started
+	<-started
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "2", w.Header().Get("Retry-After"))
+	assert.Equal(t, "SERVICE_UNAVAILABLE", w.Body.String())
+	assert.Equal(t, 1, shed)
+
+	close(release)
+	wg.Wait()
+
+	// The slots are released after the requests have been handled.
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+// TestConcurrencyLimitMiddleware_Wait tests that the excess requests wait
+// for a free slot.
+func TestConcurrencyLimitMiddleware_Wait(t *testing.T) {
+	started := make(chan struct{}, 2)
+	release := make(chan struct{})
+	handler := ConcurrencyLimitMiddleware(
+		ConcurrencyLimitConfig{MaxConcurrent: 1, MaxWait: time.Second},
+		timeoutOutputHandler{},
+	)(blockingHandler(started, release))
+
+	done := make(chan struct{})
+	go func() {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+		assert.Equal(t, http.StatusOK, w.Code)
+		close(done)
+	}()
+	<-started
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		close(release)
+	}()
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	<-done
+}
+
+// TestConcurrencyLimitMiddleware_Cancelled tests that nothing is written for
+// requests cancelled while waiting.
+func TestConcurrencyLimitMiddleware_Cancelled(t *testing.T) {
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	defer close(release)
+	handler := ConcurrencyLimitMiddleware(
+		ConcurrencyLimitConfig{MaxConcurrent: 1, MaxWait: time.Minute},
+		timeoutOutputHandler{},
+	)(blockingHandler(started, release))
+
+	go handler.ServeHTTP(
+		httptest.NewRecorder(),
+		httptest.NewRequest("GET", "/", nil),
+	)
+	<-started
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(
+		w,
+		httptest.NewRequest("GET", "/", nil).WithContext(ctx),
+	)
+
+	assert.False(t, w.Flushed)
+	assert.Empty(t, w.Body.String())
+	assert.Equal(t, http.StatusOK, w.Code)
+}
@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/pakkasys/fluidapi/core/api"
+	"github.com/pakkasys/fluidapi/endpoint/deprecation"
+)
+
+const DeprecationMiddlewareID = "deprecation"
+
+// DeprecationMiddlewareWrapper creates a new MiddlewareWrapper for the
+// Deprecation middleware. This middleware informs clients about the usage of
+// deprecated API capabilities.
+func DeprecationMiddlewareWrapper() *api.MiddlewareWrapper {
+	return &api.MiddlewareWrapper{
+		ID:         DeprecationMiddlewareID,
+		Middleware: DeprecationMiddleware(),
+	}
+}
+
+// DeprecationMiddleware constructs a middleware that collects the
+// deprecation warnings added during the request and sends them to the client
+// as "Warning" headers together with a "Deprecation" header, unless the
+// endpoint has already set one. The custom context must be set before this
+// middleware.
+func DeprecationMiddleware() api.Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := deprecation.WithWarnings(r.Context())
+			next.ServeHTTP(
+				&deprecationWriter{ResponseWriter: w, ctx: ctx},
+				r.WithContext(ctx),
+			)
+		})
+	}
+}
+
+type deprecationWriter struct {
+	http.ResponseWriter
+	ctx         context.Context
+	wroteHeader bool
+}
+
+func (w *deprecationWriter) WriteHeader(statusCode int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		w.addHeaders()
+	}
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *deprecationWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *deprecationWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *deprecationWriter) addHeaders() {
+	warnings := deprecation.Warnings(w.ctx)
+	if len(warnings) == 0 {
+		return
+	}
+
+	// A Deprecation header set by the endpoint, e.g. with the deprecation
+	// date of its version, is kept.
+	header := w.Header()
+	if header.Get("Deprecation") == "" {
+		header.Set("Deprecation", "true")
+	}
+	for _, warning := range warnings {
+		header.Add("Warning", fmt.Sprintf("299 - %q", warning))
+	}
+}
@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pakkasys/fluidapi/endpoint/deprecation"
+	"github.com/pakkasys/fluidapi/endpoint/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func deprecationTestRequest() *http.Request {
+	r := httptest.NewRequest(http.MethodGet, "/users", nil)
+	return r.WithContext(util.NewContext(r.Context()))
+}
+
+// TestDeprecationMiddlewareWrapper tests the ID of the middleware wrapper.
+func TestDeprecationMiddlewareWrapper(t *testing.T) {
+	wrapper := DeprecationMiddlewareWrapper()
+
+	assert.Equal(t, DeprecationMiddlewareID, wrapper.ID)
+	assert.NotNil(t, wrapper.Middleware)
+}
+
+// TestDeprecationMiddleware_Warnings tests that the warnings added by the
+// handler are sent as headers.
+func TestDeprecationMiddleware_Warnings(t *testing.T) {
+	handler := DeprecationMiddleware()(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			deprecation.AddWarning(r.Context(), `field "a" is deprecated`)
+			_, _ = w.Write([]byte("ok"))
+		},
+	))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, deprecationTestRequest())
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "true", w.Header().Get("Deprecation"))
+	assert.Equal(
+		t,
+		[]string{`299 - "field \"a\" is deprecated"`},
+		w.Header().Values("Warning"),
+	)
+}
+
+// TestDeprecationMiddleware_NoWarnings tests that no headers are sent when
+// no warnings were added.
+func TestDeprecationMiddleware_NoWarnings(t *testing.T) {
+	handler := DeprecationMiddleware()(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNoContent)
+		},
+	))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, deprecationTestRequest())
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Empty(t, w.Header().Get("Deprecation"))
+	assert.Empty(t, w.Header().Values("Warning"))
+}
+
+// TestDeprecationMiddleware_ExistingHeader tests that a Deprecation header
+// set by the endpoint is kept.
+func TestDeprecationMiddleware_ExistingHeader(t *testing.T) {
+	handler := DeprecationMiddleware()(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Deprecation", "@1704164645")
+			deprecation.AddWarning(r.Context(), `field "a" is deprecated`)
+			_, _ = w.Write([]byte("ok"))
+		},
+	))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, deprecationTestRequest())
+
+	assert.Equal(t, []string{"@1704164645"}, w.Header().Values("Deprecation"))
+	assert.Len(t, w.Header().Values("Warning"), 1)
+}
@@ -0,0 +1,92 @@
+package inputlogic
+
+import (
+	"slices"
+	"strings"
+	"sync"
+)
+
+// DefaultErrorCatalog is the process-wide error catalog consulted by the
+// error handler for errors that are not in the expected errors of an endpoint.
+var DefaultErrorCatalog = NewErrorCatalog()
+
+// ErrorCatalog maps error IDs to the way they are returned to clients: their
+// HTTP status, whether their data is public and their masked ID and message.
+// It is safe for concurrent use.
+type ErrorCatalog struct {
+	mu     sync.RWMutex
+	errors map[string]ExpectedError
+}
+
+// NewErrorCatalog creates a new error catalog.
+//
+//   - expectedErrors: The initial errors of the catalog.
+func NewErrorCatalog(expectedErrors ...ExpectedError) *ErrorCatalog {
+	catalog := &ErrorCatalog{
+		errors: map[string]ExpectedError{},
+	}
+	catalog.Register(expectedErrors...)
+	return catalog
+}
+
+// RegisterErrors registers errors in the default error catalog.
+//
+//   - expectedErrors: The errors to register.
+//
+// Example:
+//
+//	inputlogic.RegisterErrors(runner.GetErrors...)
+func RegisterErrors(expectedErrors ...ExpectedError) {
+	DefaultErrorCatalog.Register(expectedErrors...)
+}
+
+// Register registers errors in the catalog. An error already registered with
+// the same ID is replaced.
+//
+//   - expectedErrors: The errors to register.
+func (c *ErrorCatalog) Register(expectedErrors ...ExpectedError) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, expectedError := range expectedErrors {
+		c.errors[expectedError.ID] = expectedError
+	}
+}
+
+// Unregister removes errors from the catalog.
+//
+//   - ids: The IDs of the errors to remove.
+func (c *ErrorCatalog) Unregister(ids ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, id := range ids {
+		delete(c.errors, id)
+	}
+}
+
+// Get returns the error registered with the ID.
+//
+//   - id: The ID of the error.
+func (c *ErrorCatalog) Get(id string) (ExpectedError, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	expectedError, ok := c.errors[id]
+	return expectedError, ok
+}
+
+// ExpectedErrors returns the registered errors sorted by ID.
+func (c *ErrorCatalog) ExpectedErrors() []ExpectedError {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	expectedErrors := make([]ExpectedError, 0, len(c.errors))
+	for _, expectedError := range c.errors {
+		expectedErrors = append(expectedErrors, expectedError)
+	}
+	slices.SortFunc(expectedErrors, func(a, b ExpectedError) int {
+		return strings.Compare(a.ID, b.ID)
+	})
+	return expectedErrors
+}
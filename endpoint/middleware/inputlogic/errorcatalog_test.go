@@ -0,0 +1,78 @@
+package inputlogic
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/pakkasys/fluidapi/core/api"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestErrorCatalog tests registering and removing errors.
+func TestErrorCatalog(t *testing.T) {
+	catalog := NewErrorCatalog(ExpectedError{ID: "B", Status: 400})
+	catalog.Register(
+		ExpectedError{ID: "A", Status: 404},
+		ExpectedError{ID: "B", Status: 409},
+	)
+
+	expectedError, ok := catalog.Get("B")
+	assert.True(t, ok)
+	assert.Equal(t, ExpectedError{ID: "B", Status: 409}, expectedError)
+	assert.Equal(t, []ExpectedError{
+		{ID: "A", Status: 404},
+		{ID: "B", Status: 409},
+	}, catalog.ExpectedErrors())
+
+	catalog.Unregister("A")
+
+	_, ok = catalog.Get("A")
+	assert.False(t, ok)
+	assert.Len(t, catalog.ExpectedErrors(), 1)
+}
+
+// TestRegisterErrors tests registering errors in the default catalog.
+func TestRegisterErrors(t *testing.T) {
+	RegisterErrors(ExpectedError{ID: "CATALOG_TEST", Status: 418})
+	defer DefaultErrorCatalog.Unregister("CATALOG_TEST")
+
+	status, apiErr := ErrorHandler{}.Handle(
+		api.NewError[any]("CATALOG_TEST"),
+		nil,
+	)
+
+	assert.Equal(t, http.StatusTeapot, status)
+	assert.Equal(t, "CATALOG_TEST", apiErr.ID)
+}
+
+// TestErrorHandler_Handle_Catalog tests that the catalog is consulted for
+// errors that are not expected and that the expected errors override it.
+func TestErrorHandler_Handle_Catalog(t *testing.T) {
+	maskedID := "MASKED"
+	message := "Something went wrong"
+	handler := ErrorHandler{Catalog: NewErrorCatalog(ExpectedError{
+		ID:            "CATALOG_ERROR",
+		MaskedID:      &maskedID,
+		MaskedMessage: &message,
+		Status:        http.StatusConflict,
+	})}
+	err := api.NewError[string]("CATALOG_ERROR").WithData("data")
+
+	status, apiErr := handler.Handle(err, nil)
+
+	assert.Equal(t, http.StatusConflict, status)
+	assert.Equal(t, "MASKED", apiErr.ID)
+	assert.Equal(t, &message, apiErr.Message)
+	assert.Nil(t, *apiErr.Data)
+
+	status, apiErr = handler.Handle(err, []ExpectedError{{
+		ID:         "CATALOG_ERROR",
+		Status:     http.StatusBadRequest,
+		PublicData: true,
+	}})
+
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, "CATALOG_ERROR", apiErr.ID)
+	assert.Nil(t, apiErr.Message)
+	assert.NotNil(t, *apiErr.Data)
+}
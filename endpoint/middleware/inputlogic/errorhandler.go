@@ -1,32 +1,46 @@
 package inputlogic
 
 import (
+	"context"
+	"errors"
 	"net/http"
 
 	"github.com/pakkasys/fluidapi/core/api"
 )
 
 var InternalServerError = api.NewError[any]("INTERNAL_SERVER_ERROR")
+var RequestTimeoutError = api.NewError[any]("REQUEST_TIMEOUT")
+var ServiceUnavailableError = api.NewError[any]("SERVICE_UNAVAILABLE")
 
 // ErrorHandler handles errors and maps them to appropriate HTTP responses.
-type ErrorHandler struct{}
+// Errors that are not in the expected errors are looked up in the error
+// catalog, so the expected errors of an endpoint override the catalog.
+type ErrorHandler struct {
+	// Catalog of the errors. Defaults to DefaultErrorCatalog.
+	Catalog *ErrorCatalog
+}
 
 // ExpectedError represents an expected error configuration.
 // It defines how to handle specific errors that are anticipated.
 type ExpectedError struct {
-	ID         string  // The ID of the expected error.
-	MaskedID   *string // An optional ID to mask the original error ID in the response.
-	Status     int     // The HTTP status code to return for this error.
-	PublicData bool    // Whether to include the error data in the response.
+	ID            string  // The ID of the expected error.
+	MaskedID      *string // An optional ID to mask the original error ID in the response.
+	MaskedMessage *string // An optional message to return in the response.
+	Status        int     // The HTTP status code to return for this error.
+	PublicData    bool    // Whether to include the error data in the response.
 }
 
 // Handle processes an error and returns the corresponding HTTP status code and
 // API error. It checks if the error is an *api.Error[any] and handles it
-// accordingly.
+// accordingly. Errors caused by an exceeded context deadline are returned as
+// RequestTimeoutError with a 504 Gateway Timeout status.
 func (e ErrorHandler) Handle(
 	handleError error,
 	expectedErrors []ExpectedError,
 ) (int, *api.Error[any]) {
+	if errors.Is(handleError, context.DeadlineExceeded) {
+		return http.StatusGatewayTimeout, RequestTimeoutError
+	}
 	apiError, ok := handleError.(api.APIError)
 	if !ok {
 		return http.StatusInternalServerError, InternalServerError
@@ -40,11 +54,22 @@ func (e *ErrorHandler) handleAPIError(
 ) (int, *api.Error[any]) {
 	expectedError := e.getExpectedError(apiError, expectedErrors)
 	if expectedError == nil {
-		return http.StatusInternalServerError, InternalServerError
+		catalogError, ok := e.catalog().Get(apiError.GetID())
+		if !ok {
+			return http.StatusInternalServerError, InternalServerError
+		}
+		expectedError = &catalogError
 	}
 	return expectedError.maskAPIError(apiError)
 }
 
+func (e *ErrorHandler) catalog() *ErrorCatalog {
+	if e.Catalog != nil {
+		return e.Catalog
+	}
+	return DefaultErrorCatalog
+}
+
 func (e *ErrorHandler) getExpectedError(
 	apiError api.APIError,
 	expectedErrors []ExpectedError,
@@ -74,5 +99,9 @@ func (expectedError *ExpectedError) maskAPIError(
 		useData = nil
 	}
 
-	return expectedError.Status, &api.Error[any]{ID: useErrorID, Data: &useData}
+	return expectedError.Status, &api.Error[any]{
+		ID:      useErrorID,
+		Data:    &useData,
+		Message: expectedError.MaskedMessage,
+	}
 }
@@ -1,7 +1,9 @@
 package inputlogic
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"net/http"
 	"testing"
 
@@ -158,3 +160,14 @@ func TestExpectedError_MaskAPIError_DataNotVisible(t *testing.T) {
 	assert.Equal(t, "MASKED_ID", apiErr.ID)
 	assert.Nil(t, apiErr.Data)
 }
+
+// TestErrorHandler_Handle_DeadlineExceeded tests that exceeded deadlines are
+// returned as timeout errors.
+func TestErrorHandler_Handle_DeadlineExceeded(t *testing.T) {
+	err := fmt.Errorf("query: %w", context.DeadlineExceeded)
+
+	statusCode, apiErr := ErrorHandler{}.Handle(err, nil)
+
+	assert.Equal(t, http.StatusGatewayTimeout, statusCode)
+	assert.Equal(t, RequestTimeoutError, apiErr)
+}
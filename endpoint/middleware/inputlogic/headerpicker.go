@@ -0,0 +1,149 @@
+package inputlogic
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+const (
+	sourceHeader  = "header"
+	sourceHeaders = "headers"
+	sourceCookie  = "cookie"
+	sourceCookies = "cookies"
+)
+
+// HeaderObjectPicker is an object picker that binds the headers of the
+// request to the input fields tagged with `source:"headers"` and the cookies
+// to the fields tagged with `source:"cookies"`, after picking the rest of the
+// input with the wrapped picker. The header or cookie of a field is named by
+// its JSON name, e.g.
+//
+//	type ListOrdersInput struct {
+//		Version int       `json:"X-API-Version" source:"headers"`
+//		Since   time.Time `json:"X-Since" source:"headers"`
+//		Tags    []string  `json:"X-Tags" source:"headers"`
+//		Beta    bool      `json:"beta" source:"cookies"`
+//	}
+//
+// Fields can be strings, numbers, bools, types implementing
+// encoding.TextUnmarshaler such as time.Time (RFC 3339), and slices of these,
+// which are bound from comma-separated values. The singular tags "header" and
+// "cookie" are accepted as well.
+type HeaderObjectPicker[T any] struct {
+	// ObjectPicker picks the input before the headers and cookies are bound.
+	// If nil, only the headers and cookies are bound.
+	ObjectPicker IObjectPicker[T]
+}
+
+// NewHeaderObjectPicker returns a new header object picker.
+//
+//   - objectPicker: The picker of the rest of the input.
+func NewHeaderObjectPicker[T any](
+	objectPicker IObjectPicker[T],
+) *HeaderObjectPicker[T] {
+	return &HeaderObjectPicker[T]{ObjectPicker: objectPicker}
+}
+
+// PickObject picks the input and binds its headers and cookies.
+func (p *HeaderObjectPicker[T]) PickObject(
+	r *http.Request,
+	w http.ResponseWriter,
+	obj T,
+) (*T, error) {
+	picked := &obj
+	if p.ObjectPicker != nil {
+		var err error
+		picked, err = p.ObjectPicker.PickObject(r, w, obj)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := BindHeadersAndCookies(r, picked); err != nil {
+		return nil, err
+	}
+	return picked, nil
+}
+
+// BindHeadersAndCookies sets the fields of obj tagged with `source:"headers"`
+// to the headers of the request and the fields tagged with
+// `source:"cookies"` to its cookies. Missing headers and cookies leave the
+// fields unchanged. Values that cannot be converted to the type of their
+// field are returned as the field errors of a ValidationError.
+//
+//   - r: The HTTP request.
+//   - obj: A pointer to the input struct.
+func BindHeadersAndCookies(r *http.Request, obj any) error {
+	value := reflect.ValueOf(obj)
+	if value.Kind() != reflect.Pointer {
+		return nil
+	}
+	value = value.Elem()
+	if value.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var fieldErrors []FieldError
+	valueType := value.Type()
+	for i := 0; i < valueType.NumField(); i++ {
+		field := valueType.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "" || name == "-" {
+			name = field.Name
+		}
+
+		var values []string
+		var message string
+		switch field.Tag.Get(sourceTag) {
+		case sourceHeader, sourceHeaders:
+			values = r.Header.Values(name)
+			message = "invalid header"
+		case sourceCookie, sourceCookies:
+			if cookie, err := r.Cookie(name); err == nil {
+				values = []string{cookie.Value}
+			}
+			message = "invalid cookie"
+		default:
+			continue
+		}
+		if len(values) == 0 {
+			continue
+		}
+
+		if err := setListField(value.Field(i), values); err != nil {
+			fieldErrors = append(fieldErrors, FieldError{
+				Field:   name,
+				Message: message,
+			})
+		}
+	}
+
+	if len(fieldErrors) != 0 {
+		return ValidationError.WithData(ValidationErrorData{
+			Errors: fieldErrors,
+		})
+	}
+	return nil
+}
+
+// setListField sets the field to the values. Slice fields are set to the
+// comma-separated elements of all values and other fields to the first value.
+func setListField(field reflect.Value, values []string) error {
+	if field.Kind() != reflect.Slice {
+		return setFieldValue(field, strings.TrimSpace(values[0]))
+	}
+
+	var elements []string
+	for _, value := range values {
+		for _, element := range strings.Split(value, ",") {
+			element = strings.TrimSpace(element)
+			if element != "" {
+				elements = append(elements, element)
+			}
+		}
+	}
+	return setFormField(field, elements)
+}
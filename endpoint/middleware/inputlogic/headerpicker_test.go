@@ -0,0 +1,112 @@
+package inputlogic
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type headerInput struct {
+	Version int        `json:"X-Version" source:"headers"`
+	Since   *time.Time `json:"X-Since" source:"headers"`
+	Tags    []string   `json:"X-Tags" source:"headers"`
+	IDs     []int      `json:"X-IDs" source:"header"`
+	Beta    bool       `json:"beta" source:"cookies"`
+	Limits  []int      `json:"limits" source:"cookie"`
+	Name    string     `json:"name"`
+}
+
+// headerStubPicker picks the input by setting its name.
+type headerStubPicker struct {
+	err error
+}
+
+func (p headerStubPicker) PickObject(
+	r *http.Request,
+	w http.ResponseWriter,
+	obj headerInput,
+) (*headerInput, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	obj.Name = "picked"
+	return &obj, nil
+}
+
+// TestHeaderObjectPicker tests binding the headers and cookies of the
+// request.
+func TestHeaderObjectPicker(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("X-Version", "2")
+	r.Header.Set("X-Since", "2024-01-02T03:04:05Z")
+	r.Header.Add("X-Tags", "a, b")
+	r.Header.Add("X-Tags", "c")
+	r.Header.Set("X-IDs", "1,2")
+	r.AddCookie(&http.Cookie{Name: "beta", Value: "true"})
+	r.AddCookie(&http.Cookie{Name: "limits", Value: "5,10"})
+
+	input, err := NewHeaderObjectPicker[headerInput](headerStubPicker{}).
+		PickObject(r, httptest.NewRecorder(), headerInput{})
+
+	since := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	assert.NoError(t, err)
+	assert.Equal(t, &headerInput{
+		Version: 2,
+		Since:   &since,
+		Tags:    []string{"a", "b", "c"},
+		IDs:     []int{1, 2},
+		Beta:    true,
+		Limits:  []int{5, 10},
+		Name:    "picked",
+	}, input)
+}
+
+// TestHeaderObjectPicker_Missing tests that missing headers and cookies leave
+// the fields unchanged.
+func TestHeaderObjectPicker_Missing(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+
+	input, err := NewHeaderObjectPicker[headerInput](nil).
+		PickObject(r, httptest.NewRecorder(), headerInput{Version: 1})
+
+	assert.NoError(t, err)
+	assert.Equal(t, &headerInput{Version: 1}, input)
+}
+
+// TestHeaderObjectPicker_Errors tests the errors of the wrapped picker and
+// the conversion errors of the headers and cookies.
+func TestHeaderObjectPicker_Errors(t *testing.T) {
+	pickErr := errors.New("pick error")
+	input, err := NewHeaderObjectPicker[headerInput](
+		headerStubPicker{err: pickErr},
+	).PickObject(
+		httptest.NewRequest(http.MethodGet, "/", nil),
+		httptest.NewRecorder(),
+		headerInput{},
+	)
+	assert.Nil(t, input)
+	assert.Equal(t, pickErr, err)
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("X-Version", "x")
+	r.Header.Set("X-Since", "yesterday")
+	r.Header.Set("X-IDs", "1,a")
+	r.AddCookie(&http.Cookie{Name: "beta", Value: "maybe"})
+
+	input, err = NewHeaderObjectPicker[headerInput](nil).
+		PickObject(r, httptest.NewRecorder(), headerInput{})
+
+	assert.Nil(t, input)
+	assert.Equal(t, ValidationError.WithData(ValidationErrorData{
+		Errors: []FieldError{
+			{Field: "X-Version", Message: "invalid header"},
+			{Field: "X-Since", Message: "invalid header"},
+			{Field: "X-IDs", Message: "invalid header"},
+			{Field: "beta", Message: "invalid cookie"},
+		},
+	}), err)
+}
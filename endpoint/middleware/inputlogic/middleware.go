@@ -1,11 +1,12 @@
 package inputlogic
 
 import (
-	"fmt"
 	"net/http"
 	"slices"
 
 	"github.com/pakkasys/fluidapi/core/api"
+	"github.com/pakkasys/fluidapi/core/logging"
+	"github.com/pakkasys/fluidapi/core/readonly"
 )
 
 // MiddlewareID is a constant used to identify the middleware within the system.
@@ -24,6 +25,16 @@ type ValidationErrorData struct {
 
 var ValidationError = api.NewError[ValidationErrorData]("VALIDATION_ERROR")
 
+// NotModifiedError is returned by a callback to answer the request with 304
+// Not Modified and no body, e.g. when the If-None-Match header matches the
+// entity tag of the resource.
+var NotModifiedError = api.NewError[any]("NOT_MODIFIED")
+
+// PreconditionFailedError is returned by a callback when a precondition of
+// the request fails, e.g. the If-Match header does not match the entity tag
+// of the resource.
+var PreconditionFailedError = api.NewError[any]("PRECONDITION_FAILED")
+
 // Internal server expected errors for validation failures.
 var internalExpectedErrors []ExpectedError = []ExpectedError{
 	{
@@ -31,6 +42,16 @@ var internalExpectedErrors []ExpectedError = []ExpectedError{
 		Status:     http.StatusBadRequest,
 		PublicData: true,
 	},
+	{
+		ID:         readonly.ServiceReadOnlyError.GetID(),
+		Status:     http.StatusServiceUnavailable,
+		PublicData: false,
+	},
+	{
+		ID:         PreconditionFailedError.GetID(),
+		Status:     http.StatusPreconditionFailed,
+		PublicData: false,
+	},
 }
 
 // Callback represents the function signature used by the middleware to process
@@ -54,12 +75,6 @@ type IObjectPicker[T any] interface {
 	PickObject(r *http.Request, w http.ResponseWriter, obj T) (*T, error)
 }
 
-// ILogger represents an interface for logging messages.
-type ILogger interface {
-	Trace(message ...any)
-	Error(message ...any)
-}
-
 // IOutputHandler represents an interface for processing and sending output
 // based on the request context.
 type IOutputHandler interface {
@@ -72,17 +87,18 @@ type IOutputHandler interface {
 	) error
 }
 
-type LoggerFn func(r *http.Request) ILogger
-
 // Options represents options that can be configured for the middleware.
-// It includes an object picker, output handler, and logging functions.
+// It includes an object picker, output handler, and logger.
 type Options[Input any] struct {
 	// Used to extract the input object from the request.
 	ObjectPicker IObjectPicker[Input]
 	// Handles output processing.
 	OutputHandler IOutputHandler
-	// Gets an instance of the logger.
-	LoggerFn LoggerFn
+	// Logs the picked input and errors. Optional.
+	Logger logging.Logger
+	// Validates the picked input before its Validate method is called, e.g.
+	// TagValidator. Optional.
+	Validator Validator
 }
 
 // MiddlewareWrapper wraps the callback and creates a MiddlewareWrapper
@@ -96,15 +112,23 @@ func MiddlewareWrapper[Input ValidatedInput, Output any](
 	expectedErrors []ExpectedError,
 	opts Options[Input],
 ) *api.MiddlewareWrapper {
+	var objectPicker IObjectPicker[Input] = opts.ObjectPicker
+	if opts.Validator != nil && objectPicker != nil {
+		objectPicker = &validatingObjectPicker[Input]{
+			objectPicker: objectPicker,
+			validator:    opts.Validator,
+		}
+	}
+
 	return &api.MiddlewareWrapper{
 		ID: MiddlewareID,
 		Middleware: Middleware(
 			callback,
 			inputFactory,
 			expectedErrors,
-			opts.ObjectPicker,
+			objectPicker,
 			opts.OutputHandler,
-			opts.LoggerFn,
+			opts.Logger,
 		),
 		Inputs: []any{*inputFactory()},
 	}
@@ -124,15 +148,15 @@ func MiddlewareWrapper[Input ValidatedInput, Output any](
 //     object from the request. If nil, the middleware will panic.
 //   - outputHandler: The handler that processes and sends the output to the
 //     client. If nil, the middleware will panic.
-//   - traceLoggerFn: A function that can be used to log trace messages.
-//   - errorLoggerFn: A function that can be used to log error messages.
+//   - logger: The logger of the picked input and errors. If nil, nothing is
+//     logged.
 func Middleware[Input ValidatedInput, Output any](
 	callback Callback[Input, Output],
 	inputFactory func() *Input,
 	expectedErrors []ExpectedError,
 	objectPicker IObjectPicker[Input],
 	outputHandler IOutputHandler,
-	loggerFn func(*http.Request) ILogger,
+	logger logging.Logger,
 ) api.Middleware {
 	if objectPicker == nil {
 		panic("object picker cannot be nil")
@@ -140,6 +164,9 @@ func Middleware[Input ValidatedInput, Output any](
 	if outputHandler == nil {
 		panic("output handler cannot be nil")
 	}
+	if logger == nil {
+		logger = logging.NopLogger{}
+	}
 
 	allErrors := slices.Concat(internalExpectedErrors, expectedErrors)
 
@@ -150,16 +177,20 @@ func Middleware[Input ValidatedInput, Output any](
 				r,
 				*inputFactory(),
 				objectPicker,
-				loggerFn,
+				logger,
 			)
 			if err != nil {
-				handleError(w, r, err, outputHandler, allErrors, loggerFn)
+				handleError(w, r, err, outputHandler, allErrors, logger)
 				return
 			}
 
 			out, err := callback(w, r, input)
+			if isNotModified(err) {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
 			if err != nil {
-				handleError(w, r, err, outputHandler, allErrors, loggerFn)
+				handleError(w, r, err, outputHandler, allErrors, logger)
 				return
 			}
 
@@ -170,7 +201,7 @@ func Middleware[Input ValidatedInput, Output any](
 				nil,
 				http.StatusOK,
 				outputHandler,
-				loggerFn,
+				logger,
 			)
 
 			next.ServeHTTP(w, r)
@@ -178,26 +209,30 @@ func Middleware[Input ValidatedInput, Output any](
 	}
 }
 
+func isNotModified(err error) bool {
+	apiError, ok := err.(api.APIError)
+	return ok && apiError.GetID() == NotModifiedError.GetID()
+}
+
 func handleError(
 	w http.ResponseWriter,
 	r *http.Request,
 	handleError error,
 	outputHandler IOutputHandler,
 	expectedErrors []ExpectedError,
-	loggerFn func(*http.Request) ILogger,
+	logger logging.Logger,
 ) {
 	statusCode, outError := ErrorHandler{}.Handle(handleError, expectedErrors)
 
-	if loggerFn != nil {
-		loggerFn(r).Trace(fmt.Sprintf(
-			"Error handler, status code: %d, callback error: %s, output error: %s",
-			statusCode,
-			handleError,
-			outError,
-		))
-	}
+	logger.Debug(
+		r.Context(),
+		"Error handler",
+		"status_code", statusCode,
+		"callback_error", handleError,
+		"output_error", outError,
+	)
 
-	handleOutput(w, r, nil, outError, statusCode, outputHandler, loggerFn)
+	handleOutput(w, r, nil, outError, statusCode, outputHandler, logger)
 }
 
 func handleInput[Input ValidatedInput](
@@ -205,15 +240,13 @@ func handleInput[Input ValidatedInput](
 	r *http.Request,
 	inputObject Input,
 	objectPicker IObjectPicker[Input],
-	loggerFn func(*http.Request) ILogger,
+	logger logging.Logger,
 ) (*Input, error) {
 	input, err := objectPicker.PickObject(r, w, inputObject)
 	if err != nil {
 		return nil, err
 	}
-	if loggerFn != nil {
-		loggerFn(r).Trace("Picked object", *input)
-	}
+	logger.Debug(r.Context(), "Picked object", "input", *input)
 
 	validationErrors := (*input).Validate()
 	if len(validationErrors) > 0 {
@@ -232,17 +265,12 @@ func handleOutput(
 	outputError error,
 	statusCode int,
 	outputHandler IOutputHandler,
-	loggerFn func(*http.Request) ILogger,
+	logger logging.Logger,
 ) {
 	err := outputHandler.ProcessOutput(w, r, output, outputError, statusCode)
 
 	if err != nil {
-		if loggerFn != nil {
-			loggerFn(r).Error(fmt.Sprintf(
-				"Error processing output: %s",
-				err,
-			))
-		}
+		logger.Error(r.Context(), "Error processing output", "error", err)
 		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
@@ -1,12 +1,14 @@
 package inputlogic
 
 import (
+	"context"
 	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
 	"github.com/pakkasys/fluidapi/core/api"
+	"github.com/pakkasys/fluidapi/core/readonly"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -44,17 +46,25 @@ func (m *MockOutputHandler) ProcessOutput(
 	return args.Error(0)
 }
 
-// MockLogger is a mock implementation of the ILogger interface.
+// MockLogger is a mock implementation of the logging.Logger interface.
 type MockLogger struct {
 	mock.Mock
 }
 
-func (m *MockLogger) Trace(messages ...any) {
-	m.Called(messages)
+func (m *MockLogger) Debug(ctx context.Context, msg string, args ...any) {
+	m.Called(msg)
 }
 
-func (m *MockLogger) Error(messages ...any) {
-	m.Called(messages)
+func (m *MockLogger) Info(ctx context.Context, msg string, args ...any) {
+	m.Called(msg)
+}
+
+func (m *MockLogger) Warn(ctx context.Context, msg string, args ...any) {
+	m.Called(msg)
+}
+
+func (m *MockLogger) Error(ctx context.Context, msg string, args ...any) {
+	m.Called(msg)
 }
 
 // TODO: Get working without helper
@@ -98,9 +108,7 @@ func TestMiddlewareWrapper_Success(t *testing.T) {
 	opts := Options[MockValidatedInput]{
 		ObjectPicker:  mockObjectPicker,
 		OutputHandler: mockOutputHandler,
-		LoggerFn: func(*http.Request) ILogger {
-			return mockLogger
-		},
+		Logger:        mockLogger,
 	}
 
 	callback := func(
@@ -143,9 +151,7 @@ func TestMiddleware_Success(t *testing.T) {
 	}
 
 	opts := Options[MockValidatedInput]{
-		LoggerFn: func(*http.Request) ILogger {
-			return mockLogger
-		},
+		Logger: mockLogger,
 	}
 
 	expectedOutput := "Success"
@@ -158,7 +164,7 @@ func TestMiddleware_Success(t *testing.T) {
 	}
 
 	mockObjectPicker.On("PickObject", r, w, input).Return(&input, nil)
-	mockLogger.On("Trace", mock.Anything)
+	mockLogger.On("Debug", mock.Anything)
 	mockOutputHandler.
 		On("ProcessOutput", w, r, &expectedOutput, nil, http.StatusOK).
 		Return(nil)
@@ -170,7 +176,7 @@ func TestMiddleware_Success(t *testing.T) {
 		nil,
 		mockObjectPicker,
 		mockOutputHandler,
-		opts.LoggerFn,
+		opts.Logger,
 	)
 	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusTeapot)
@@ -204,9 +210,7 @@ func TestMiddleware_InputValidationError(t *testing.T) {
 	}
 
 	opts := Options[MockValidatedInput]{
-		LoggerFn: func(*http.Request) ILogger {
-			return mockLogger
-		},
+		Logger: mockLogger,
 	}
 
 	callback := func(
@@ -218,7 +222,7 @@ func TestMiddleware_InputValidationError(t *testing.T) {
 	}
 
 	mockObjectPicker.On("PickObject", r, w, input).Return(&input, nil)
-	mockLogger.On("Trace", mock.Anything)
+	mockLogger.On("Debug", mock.Anything)
 	mockOutputHandler.
 		On(
 			"ProcessOutput",
@@ -240,7 +244,7 @@ func TestMiddleware_InputValidationError(t *testing.T) {
 		nil,
 		mockObjectPicker,
 		mockOutputHandler,
-		opts.LoggerFn,
+		opts.Logger,
 	)
 	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
@@ -274,9 +278,7 @@ func TestMiddleware_CallbackError(t *testing.T) {
 	}
 
 	opts := Options[MockValidatedInput]{
-		LoggerFn: func(*http.Request) ILogger {
-			return mockLogger
-		},
+		Logger: mockLogger,
 	}
 
 	expectedError := errors.New("callback failed")
@@ -289,7 +291,7 @@ func TestMiddleware_CallbackError(t *testing.T) {
 	}
 
 	mockObjectPicker.On("PickObject", r, w, input).Return(&input, nil)
-	mockLogger.On("Trace", mock.Anything)
+	mockLogger.On("Debug", mock.Anything)
 	mockOutputHandler.
 		On(
 			"ProcessOutput",
@@ -307,7 +309,7 @@ func TestMiddleware_CallbackError(t *testing.T) {
 		nil,
 		mockObjectPicker,
 		mockOutputHandler,
-		opts.LoggerFn,
+		opts.Logger,
 	)
 	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
@@ -323,6 +325,154 @@ func TestMiddleware_CallbackError(t *testing.T) {
 	mockLogger.AssertExpectations(t)
 }
 
+// TestMiddleware_ServiceReadOnlyError tests that the read-only error returned
+// by a callback is handled as a service unavailable error.
+func TestMiddleware_ServiceReadOnlyError(t *testing.T) {
+	mockObjectPicker := new(MockObjectPicker[MockValidatedInput])
+	mockOutputHandler := new(MockOutputHandler)
+
+	mockHelper := new(MockHelper)
+	input := NewMockValidatedInput(mockHelper)
+
+	r := httptest.NewRequest("POST", "/", nil)
+	w := httptest.NewRecorder()
+
+	inputFactory := func() *MockValidatedInput {
+		return &input
+	}
+
+	callback := func(
+		w http.ResponseWriter,
+		r *http.Request,
+		i *MockValidatedInput,
+	) (*string, error) {
+		return nil, readonly.ServiceReadOnlyError
+	}
+
+	mockObjectPicker.On("PickObject", r, w, input).Return(&input, nil)
+	mockOutputHandler.
+		On(
+			"ProcessOutput",
+			w,
+			r,
+			nil,
+			mock.Anything,
+			http.StatusServiceUnavailable,
+		).Return(nil)
+	mockHelper.On("Validate").Return([]FieldError{})
+
+	middleware := Middleware(
+		callback,
+		inputFactory,
+		nil,
+		mockObjectPicker,
+		mockOutputHandler,
+		nil,
+	)
+	middleware(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})).
+		ServeHTTP(w, r)
+
+	mockObjectPicker.AssertExpectations(t)
+	mockOutputHandler.AssertExpectations(t)
+}
+
+// TestMiddleware_NotModifiedError tests that the not modified error returned
+// by a callback is answered with 304 and no body.
+func TestMiddleware_NotModifiedError(t *testing.T) {
+	mockObjectPicker := new(MockObjectPicker[MockValidatedInput])
+	mockOutputHandler := new(MockOutputHandler)
+
+	mockHelper := new(MockHelper)
+	input := NewMockValidatedInput(mockHelper)
+
+	r := httptest.NewRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+
+	inputFactory := func() *MockValidatedInput {
+		return &input
+	}
+
+	callback := func(
+		w http.ResponseWriter,
+		r *http.Request,
+		i *MockValidatedInput,
+	) (*string, error) {
+		w.Header().Set("ETag", `"v1"`)
+		return nil, NotModifiedError
+	}
+
+	mockObjectPicker.On("PickObject", r, w, input).Return(&input, nil)
+	mockHelper.On("Validate").Return([]FieldError{})
+
+	middleware := Middleware(
+		callback,
+		inputFactory,
+		nil,
+		mockObjectPicker,
+		mockOutputHandler,
+		nil,
+	)
+	middleware(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})).
+		ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusNotModified, w.Code)
+	assert.Equal(t, `"v1"`, w.Header().Get("ETag"))
+	assert.Empty(t, w.Body.String())
+	mockObjectPicker.AssertExpectations(t)
+	mockOutputHandler.AssertNotCalled(t, "ProcessOutput")
+}
+
+// TestMiddleware_PreconditionFailedError tests that the precondition failed
+// error returned by a callback is handled with 412.
+func TestMiddleware_PreconditionFailedError(t *testing.T) {
+	mockObjectPicker := new(MockObjectPicker[MockValidatedInput])
+	mockOutputHandler := new(MockOutputHandler)
+
+	mockHelper := new(MockHelper)
+	input := NewMockValidatedInput(mockHelper)
+
+	r := httptest.NewRequest("PUT", "/", nil)
+	w := httptest.NewRecorder()
+
+	inputFactory := func() *MockValidatedInput {
+		return &input
+	}
+
+	callback := func(
+		w http.ResponseWriter,
+		r *http.Request,
+		i *MockValidatedInput,
+	) (*string, error) {
+		return nil, PreconditionFailedError
+	}
+
+	mockObjectPicker.On("PickObject", r, w, input).Return(&input, nil)
+	mockOutputHandler.
+		On(
+			"ProcessOutput",
+			w,
+			r,
+			nil,
+			mock.Anything,
+			http.StatusPreconditionFailed,
+		).Return(nil)
+	mockHelper.On("Validate").Return([]FieldError{})
+
+	middleware := Middleware(
+		callback,
+		inputFactory,
+		nil,
+		mockObjectPicker,
+		mockOutputHandler,
+		nil,
+	)
+	middleware(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})).
+		ServeHTTP(w, r)
+
+	mockObjectPicker.AssertExpectations(t)
+	mockOutputHandler.AssertExpectations(t)
+}
+
 // TestMiddleware_ObjectPickerNil_Panics tests that the middleware panics when
 // the objectPicker is nil.
 func TestMiddleware_ObjectPickerNil_Panics(t *testing.T) {
@@ -342,9 +492,7 @@ func TestMiddleware_ObjectPickerNil_Panics(t *testing.T) {
 	}
 
 	opts := Options[MockValidatedInput]{
-		LoggerFn: func(*http.Request) ILogger {
-			return mockLogger
-		},
+		Logger: mockLogger,
 	}
 
 	Middleware(
@@ -359,7 +507,7 @@ func TestMiddleware_ObjectPickerNil_Panics(t *testing.T) {
 		nil,
 		nil,
 		mockOutputHandler,
-		opts.LoggerFn,
+		opts.Logger,
 	)
 }
 
@@ -382,9 +530,7 @@ func TestMiddleware_OutputHandlerNil_Panics(t *testing.T) {
 	}
 
 	opts := Options[MockValidatedInput]{
-		LoggerFn: func(*http.Request) ILogger {
-			return mockLogger
-		},
+		Logger: mockLogger,
 	}
 
 	Middleware(
@@ -399,7 +545,7 @@ func TestMiddleware_OutputHandlerNil_Panics(t *testing.T) {
 		nil,
 		mockObjectPicker,
 		nil,
-		opts.LoggerFn,
+		opts.Logger,
 	)
 }
 
@@ -423,7 +569,7 @@ func TestHandleError_ExpectedError(t *testing.T) {
 		},
 	}
 
-	mockLogger.On("Trace", mock.Anything)
+	mockLogger.On("Debug", mock.Anything)
 	mockOutputHandler.
 		On("ProcessOutput", w, r, nil, expectedError, expectedStatusCode).
 		Return(nil)
@@ -434,9 +580,7 @@ func TestHandleError_ExpectedError(t *testing.T) {
 		expectedError,
 		mockOutputHandler,
 		expectedErrors,
-		func(*http.Request) ILogger {
-			return mockLogger
-		},
+		mockLogger,
 	)
 
 	mockOutputHandler.AssertExpectations(t)
@@ -456,7 +600,7 @@ func TestHandleError_UnexpectedError(t *testing.T) {
 	expectedStatusCode := http.StatusInternalServerError
 	expectedApiError := InternalServerError
 
-	mockLogger.On("Trace", mock.Anything)
+	mockLogger.On("Debug", mock.Anything)
 	mockOutputHandler.
 		On("ProcessOutput", w, r, nil, expectedApiError, expectedStatusCode).
 		Return(nil)
@@ -467,9 +611,7 @@ func TestHandleError_UnexpectedError(t *testing.T) {
 		unexpectedError,
 		mockOutputHandler,
 		nil,
-		func(*http.Request) ILogger {
-			return mockLogger
-		},
+		mockLogger,
 	)
 
 	mockOutputHandler.AssertExpectations(t)
@@ -490,7 +632,7 @@ func TestHandleInput_Success(t *testing.T) {
 
 	// Set up mock expectations
 	mockObjectPicker.On("PickObject", r, w, input).Return(&input, nil)
-	mockLogger.On("Trace", mock.Anything)
+	mockLogger.On("Debug", mock.Anything)
 	mockHelper.On("Validate").Return([]FieldError{})
 
 	// Call handleInput function
@@ -499,9 +641,7 @@ func TestHandleInput_Success(t *testing.T) {
 		r,
 		input,
 		mockObjectPicker,
-		func(*http.Request) ILogger {
-			return mockLogger
-		},
+		mockLogger,
 	)
 
 	// Assertions
@@ -535,9 +675,7 @@ func TestHandleInput_ObjectPickerFailure(t *testing.T) {
 		r,
 		input,
 		mockObjectPicker,
-		func(*http.Request) ILogger {
-			return mockLogger
-		},
+		mockLogger,
 	)
 
 	// Assertions
@@ -560,7 +698,7 @@ func TestHandleInput_ValidationError(t *testing.T) {
 	w := httptest.NewRecorder()
 
 	mockObjectPicker.On("PickObject", r, w, input).Return(&input, nil)
-	mockLogger.On("Trace", mock.Anything)
+	mockLogger.On("Debug", mock.Anything)
 	validationErrors := []FieldError{
 		{Field: "testField", Message: "invalid value"},
 	}
@@ -571,9 +709,7 @@ func TestHandleInput_ValidationError(t *testing.T) {
 		r,
 		input,
 		mockObjectPicker,
-		func(*http.Request) ILogger {
-			return mockLogger
-		},
+		mockLogger,
 	)
 
 	assert.Nil(t, returnedInput)
@@ -605,9 +741,7 @@ func TestHandleOutput_Success(t *testing.T) {
 		nil,
 		statusCode,
 		mockOutputHandler,
-		func(*http.Request) ILogger {
-			return mockLogger
-		},
+		mockLogger,
 	)
 
 	assert.Equal(t, http.StatusOK, w.Result().StatusCode)
@@ -640,9 +774,7 @@ func TestHandleOutput_Failure(t *testing.T) {
 		nil,
 		statusCode,
 		mockOutputHandler,
-		func(*http.Request) ILogger {
-			return mockLogger
-		},
+		mockLogger,
 	)
 
 	assert.Equal(t, http.StatusInternalServerError, w.Result().StatusCode)
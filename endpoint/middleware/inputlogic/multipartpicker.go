@@ -0,0 +1,287 @@
+package inputlogic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+const (
+	sourceForm = "form"
+	sourceFile = "file"
+
+	defaultMaxFormMemory   = 32 << 20
+	defaultMaxFormBodySize = 32 << 20
+)
+
+// UploadedFile is a file uploaded with a multipart/form-data request. The
+// file is closed and its temporary copy removed when the request ends.
+type UploadedFile struct {
+	multipart.File
+	// Filename is the name of the file given by the client. It must not be
+	// used as a path without sanitizing it.
+	Filename string
+	// ContentType is the content type of the file given by the client.
+	ContentType string
+	// Size is the size of the file in bytes.
+	Size int64
+}
+
+var uploadedFileType = reflect.TypeOf((*UploadedFile)(nil))
+
+// MultipartObjectPicker is an object picker that binds the fields of form
+// requests to the input fields tagged with `source:"form"` and the uploaded
+// files to the fields tagged with `source:"file"`, e.g.
+//
+//	type UploadInput struct {
+//		Title  string                     `json:"title" source:"form"`
+//		Tags   []string                   `json:"tags" source:"form"`
+//		Avatar *inputlogic.UploadedFile   `json:"avatar" source:"file"`
+//		Files  []*inputlogic.UploadedFile `json:"files" source:"file"`
+//	}
+//
+// The fields are named by their JSON names. Requests that are not
+// multipart/form-data or application/x-www-form-urlencoded are picked with the
+// wrapped picker.
+type MultipartObjectPicker[T any] struct {
+	// ObjectPicker picks the inputs of requests that are not form requests.
+	ObjectPicker IObjectPicker[T]
+	// MaxMemory is the number of bytes of the files kept in memory. The rest
+	// is stored in temporary files. Defaults to 32 MB.
+	MaxMemory int64
+	// MaxBodySize is the maximum size of the request body in bytes. Defaults
+	// to 32 MB.
+	MaxBodySize int64
+	// MaxFileSize is the maximum size of an uploaded file in bytes. Zero
+	// means that only the size of the body is limited.
+	MaxFileSize int64
+}
+
+// NewMultipartObjectPicker returns a new multipart object picker.
+//
+//   - objectPicker: The picker of the inputs of other requests.
+//   - maxBodySize: The maximum size of the request body in bytes.
+//   - maxFileSize: The maximum size of an uploaded file in bytes.
+func NewMultipartObjectPicker[T any](
+	objectPicker IObjectPicker[T],
+	maxBodySize int64,
+	maxFileSize int64,
+) *MultipartObjectPicker[T] {
+	return &MultipartObjectPicker[T]{
+		ObjectPicker: objectPicker,
+		MaxBodySize:  maxBodySize,
+		MaxFileSize:  maxFileSize,
+	}
+}
+
+// PickObject binds the form fields and files of the request or picks the
+// input with the wrapped picker.
+func (p *MultipartObjectPicker[T]) PickObject(
+	r *http.Request,
+	w http.ResponseWriter,
+	obj T,
+) (*T, error) {
+	mediaType, ok := formMediaType(r)
+	if !ok {
+		if p.ObjectPicker == nil {
+			return &obj, nil
+		}
+		return p.ObjectPicker.PickObject(r, w, obj)
+	}
+
+	if err := p.parseForm(r, w, mediaType); err != nil {
+		return nil, err
+	}
+	if err := bindForm(r, &obj, p.MaxFileSize); err != nil {
+		return nil, err
+	}
+	return &obj, nil
+}
+
+func (p *MultipartObjectPicker[T]) parseForm(
+	r *http.Request,
+	w http.ResponseWriter,
+	mediaType string,
+) error {
+	maxBodySize := p.MaxBodySize
+	if maxBodySize <= 0 {
+		maxBodySize = defaultMaxFormBodySize
+	}
+	maxMemory := p.MaxMemory
+	if maxMemory <= 0 {
+		maxMemory = defaultMaxFormMemory
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, maxBodySize)
+
+	var err error
+	if mediaType == "multipart/form-data" {
+		err = r.ParseMultipartForm(maxMemory)
+	} else {
+		err = r.ParseForm()
+	}
+	if err != nil {
+		message := "invalid form body"
+		var maxBytesError *http.MaxBytesError
+		if errors.As(err, &maxBytesError) {
+			message = "request body too large"
+		}
+		return ValidationError.WithData(ValidationErrorData{
+			Errors: []FieldError{{Field: "body", Message: message}},
+		})
+	}
+	return nil
+}
+
+// bindForm sets the form and file fields of obj. The opened files are closed
+// when the request context is done.
+func bindForm(r *http.Request, obj any, maxFileSize int64) error {
+	value := reflect.ValueOf(obj).Elem()
+	if value.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var files []multipart.File
+	if r.MultipartForm != nil {
+		form := r.MultipartForm
+		context.AfterFunc(r.Context(), func() {
+			for _, file := range files {
+				_ = file.Close()
+			}
+			_ = form.RemoveAll()
+		})
+	}
+
+	var fieldErrors []FieldError
+	valueType := value.Type()
+	for i := 0; i < valueType.NumField(); i++ {
+		field := valueType.Field(i)
+		source := field.Tag.Get(sourceTag)
+		if (source != sourceForm && source != sourceFile) ||
+			!field.IsExported() {
+			continue
+		}
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "" || name == "-" {
+			name = field.Name
+		}
+
+		if source == sourceForm {
+			err := setFormField(value.Field(i), r.PostForm[name])
+			if err != nil {
+				fieldErrors = append(fieldErrors, FieldError{
+					Field:   name,
+					Message: "invalid form field",
+				})
+			}
+			continue
+		}
+
+		if r.MultipartForm == nil {
+			continue
+		}
+		headers := r.MultipartForm.File[name]
+		if maxFileSize > 0 && hasLargerFile(headers, maxFileSize) {
+			fieldErrors = append(fieldErrors, FieldError{
+				Field:   name,
+				Message: "file too large",
+			})
+			continue
+		}
+		opened, err := setFileField(value.Field(i), headers)
+		files = append(files, opened...)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(fieldErrors) != 0 {
+		return ValidationError.WithData(ValidationErrorData{
+			Errors: fieldErrors,
+		})
+	}
+	return nil
+}
+
+func setFormField(field reflect.Value, values []string) error {
+	if len(values) == 0 {
+		return nil
+	}
+	if field.Kind() != reflect.Slice {
+		return setFieldValue(field, values[0])
+	}
+	slice := reflect.MakeSlice(field.Type(), len(values), len(values))
+	for i, value := range values {
+		if err := setFieldValue(slice.Index(i), value); err != nil {
+			return err
+		}
+	}
+	field.Set(slice)
+	return nil
+}
+
+func setFileField(
+	field reflect.Value,
+	headers []*multipart.FileHeader,
+) ([]multipart.File, error) {
+	isSlice := field.Kind() == reflect.Slice
+	if field.Type() != uploadedFileType &&
+		(!isSlice || field.Type().Elem() != uploadedFileType) {
+		return nil, fmt.Errorf("unsupported file field type: %s", field.Type())
+	}
+	if len(headers) == 0 {
+		return nil, nil
+	}
+	if !isSlice {
+		headers = headers[:1]
+	}
+
+	var files []multipart.File
+	uploaded := make([]*UploadedFile, 0, len(headers))
+	for _, header := range headers {
+		file, err := header.Open()
+		if err != nil {
+			return files, err
+		}
+		files = append(files, file)
+		uploaded = append(uploaded, &UploadedFile{
+			File:        file,
+			Filename:    header.Filename,
+			ContentType: header.Header.Get("Content-Type"),
+			Size:        header.Size,
+		})
+	}
+
+	if isSlice {
+		field.Set(reflect.ValueOf(uploaded))
+	} else {
+		field.Set(reflect.ValueOf(uploaded[0]))
+	}
+	return files, nil
+}
+
+func hasLargerFile(headers []*multipart.FileHeader, size int64) bool {
+	for _, header := range headers {
+		if header.Size > size {
+			return true
+		}
+	}
+	return false
+}
+
+// formMediaType returns the media type of form requests.
+func formMediaType(r *http.Request) (string, bool) {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		return "", false
+	}
+	switch mediaType {
+	case "multipart/form-data", "application/x-www-form-urlencoded":
+		return mediaType, true
+	}
+	return "", false
+}
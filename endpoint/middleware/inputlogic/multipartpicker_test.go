@@ -0,0 +1,219 @@
+package inputlogic
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type uploadInput struct {
+	Title  string          `json:"title" source:"form"`
+	Count  *int            `json:"count" source:"form"`
+	Tags   []string        `json:"tags" source:"form"`
+	Avatar *UploadedFile   `json:"avatar" source:"file"`
+	Files  []*UploadedFile `json:"files" source:"file"`
+	Name   string          `json:"name"`
+}
+
+type formFile struct {
+	field   string
+	name    string
+	content string
+}
+
+// multipartRequest returns a multipart/form-data request with the fields
+// and files.
+func multipartRequest(
+	t *testing.T,
+	fields url.Values,
+	files []formFile,
+) *http.Request {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	for name, values := range fields {
+		for _, value := range values {
+			assert.NoError(t, writer.WriteField(name, value))
+		}
+	}
+	for _, file := range files {
+		part, err := writer.CreateFormFile(file.field, file.name)
+		assert.NoError(t, err)
+		_, err = part.Write([]byte(file.content))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, writer.Close())
+
+	r := httptest.NewRequest(http.MethodPost, "/", body)
+	r.Header.Set("Content-Type", writer.FormDataContentType())
+	return r
+}
+
+func readUploadedFile(t *testing.T, file *UploadedFile) string {
+	content, err := io.ReadAll(file)
+	assert.NoError(t, err)
+	return string(content)
+}
+
+// TestMultipartObjectPicker tests binding the form fields and files.
+func TestMultipartObjectPicker(t *testing.T) {
+	r := multipartRequest(
+		t,
+		url.Values{"title": {"Photos"}, "count": {"2"}, "tags": {"a", "b"}},
+		[]formFile{
+			{field: "avatar", name: "me.png", content: "avatar"},
+			{field: "files", name: "1.txt", content: "one"},
+			{field: "files", name: "2.txt", content: "two"},
+		},
+	)
+	ctx, cancel := context.WithCancel(r.Context())
+	r = r.WithContext(ctx)
+	picker := NewMultipartObjectPicker[uploadInput](nil, 0, 0)
+	// The files are stored in temporary files.
+	picker.MaxMemory = 1
+
+	input, err := picker.PickObject(r, httptest.NewRecorder(), uploadInput{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Photos", input.Title)
+	assert.Equal(t, 2, *input.Count)
+	assert.Equal(t, []string{"a", "b"}, input.Tags)
+	assert.Equal(t, "me.png", input.Avatar.Filename)
+	assert.Equal(t, int64(6), input.Avatar.Size)
+	assert.Equal(
+		t,
+		"application/octet-stream",
+		input.Avatar.ContentType,
+	)
+	assert.Equal(t, "avatar", readUploadedFile(t, input.Avatar))
+	assert.Len(t, input.Files, 2)
+	assert.Equal(t, "two", readUploadedFile(t, input.Files[1]))
+
+	// The files are closed and removed when the request ends.
+	cancel()
+	assert.Eventually(t, func() bool {
+		_, err := input.Files[0].Read(make([]byte, 1))
+		return err != nil && err != io.EOF
+	}, time.Second, time.Millisecond)
+}
+
+// TestMultipartObjectPicker_URLEncoded tests binding URL encoded forms.
+func TestMultipartObjectPicker_URLEncoded(t *testing.T) {
+	r := httptest.NewRequest(
+		http.MethodPost,
+		"/",
+		strings.NewReader("title=Photos&tags=a&tags=b"),
+	)
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	input, err := NewMultipartObjectPicker[uploadInput](nil, 0, 0).
+		PickObject(r, httptest.NewRecorder(), uploadInput{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Photos", input.Title)
+	assert.Equal(t, []string{"a", "b"}, input.Tags)
+	assert.Nil(t, input.Avatar)
+}
+
+// TestMultipartObjectPicker_OtherRequest tests picking other requests with
+// the wrapped picker.
+func TestMultipartObjectPicker_OtherRequest(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("{}"))
+	r.Header.Set("Content-Type", "application/json")
+	picker := &MockObjectPicker[uploadInput]{}
+	picker.On("PickObject", r, nil, uploadInput{}).
+		Return(&uploadInput{Name: "picked"}, nil)
+
+	input, err := NewMultipartObjectPicker[uploadInput](picker, 0, 0).
+		PickObject(r, nil, uploadInput{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "picked", input.Name)
+	picker.AssertExpectations(t)
+
+	input, err = NewMultipartObjectPicker[uploadInput](nil, 0, 0).
+		PickObject(r, nil, uploadInput{})
+	assert.NoError(t, err)
+	assert.Equal(t, uploadInput{}, *input)
+}
+
+// TestMultipartObjectPicker_Limits tests the size limits.
+func TestMultipartObjectPicker_Limits(t *testing.T) {
+	tests := []struct {
+		name        string
+		maxBodySize int64
+		maxFileSize int64
+		wantField   string
+		wantMessage string
+	}{
+		{"File too large", 0, 3, "avatar", "file too large"},
+		{"Body too large", 64, 0, "body", "request body too large"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := multipartRequest(t, nil, []formFile{
+				{field: "avatar", name: "me.png", content: "avatar"},
+			})
+
+			input, err := NewMultipartObjectPicker[uploadInput](
+				nil,
+				tt.maxBodySize,
+				tt.maxFileSize,
+			).PickObject(r, httptest.NewRecorder(), uploadInput{})
+
+			assert.Nil(t, input)
+			assert.Equal(
+				t,
+				ValidationError.WithData(ValidationErrorData{
+					Errors: []FieldError{
+						{Field: tt.wantField, Message: tt.wantMessage},
+					},
+				}),
+				err,
+			)
+		})
+	}
+}
+
+// TestMultipartObjectPicker_InvalidField tests invalid form fields.
+func TestMultipartObjectPicker_InvalidField(t *testing.T) {
+	r := multipartRequest(t, url.Values{"count": {"many"}}, nil)
+
+	input, err := NewMultipartObjectPicker[uploadInput](nil, 0, 0).
+		PickObject(r, httptest.NewRecorder(), uploadInput{})
+
+	assert.Nil(t, input)
+	assert.Equal(
+		t,
+		ValidationError.WithData(ValidationErrorData{
+			Errors: []FieldError{
+				{Field: "count", Message: "invalid form field"},
+			},
+		}),
+		err,
+	)
+}
+
+// TestMultipartObjectPicker_UnsupportedFileField tests that file fields must
+// be uploaded files.
+func TestMultipartObjectPicker_UnsupportedFileField(t *testing.T) {
+	type input struct {
+		Avatar []byte `json:"avatar" source:"file"`
+	}
+	r := multipartRequest(t, nil, []formFile{
+		{field: "avatar", name: "me.png", content: "avatar"},
+	})
+
+	_, err := NewMultipartObjectPicker[input](nil, 0, 0).
+		PickObject(r, httptest.NewRecorder(), input{})
+
+	assert.EqualError(t, err, "unsupported file field type: []uint8")
+}
@@ -0,0 +1,156 @@
+package inputlogic
+
+import (
+	"encoding"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/pakkasys/fluidapi/core/api"
+)
+
+const (
+	sourceTag  = "source"
+	sourcePath = "path"
+)
+
+// PathObjectPicker is an object picker that binds the path parameters of the
+// request to the input fields tagged with `source:"path"`, after picking the
+// rest of the input with the wrapped picker. The parameter of a field is named
+// by its JSON name, e.g.
+//
+//	type GetOrderInput struct {
+//		UserID  int    `json:"id" source:"path"`
+//		OrderID string `json:"orderID" source:"path"`
+//	}
+//
+// for the URL "/users/{id}/orders/{orderID}".
+type PathObjectPicker[T any] struct {
+	// ObjectPicker picks the input before the path parameters are bound. If
+	// nil, only the path parameters are bound.
+	ObjectPicker IObjectPicker[T]
+}
+
+// NewPathObjectPicker returns a new path object picker.
+//
+//   - objectPicker: The picker of the rest of the input.
+func NewPathObjectPicker[T any](
+	objectPicker IObjectPicker[T],
+) *PathObjectPicker[T] {
+	return &PathObjectPicker[T]{ObjectPicker: objectPicker}
+}
+
+// PickObject picks the input and binds its path parameters.
+func (p *PathObjectPicker[T]) PickObject(
+	r *http.Request,
+	w http.ResponseWriter,
+	obj T,
+) (*T, error) {
+	picked := &obj
+	if p.ObjectPicker != nil {
+		var err error
+		picked, err = p.ObjectPicker.PickObject(r, w, obj)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := BindPathParameters(r, picked); err != nil {
+		return nil, err
+	}
+	return picked, nil
+}
+
+// BindPathParameters sets the fields of obj tagged with `source:"path"` to the
+// path parameters of the request. The parameters stored in the request
+// context by the server are preferred over the ones of the request pattern.
+//
+//   - r: The HTTP request.
+//   - obj: A pointer to the input struct.
+func BindPathParameters(r *http.Request, obj any) error {
+	value := reflect.ValueOf(obj)
+	if value.Kind() != reflect.Pointer {
+		return nil
+	}
+	value = value.Elem()
+	if value.Kind() != reflect.Struct {
+		return nil
+	}
+
+	parameters := api.PathParameters(r.Context())
+	valueType := value.Type()
+	for i := 0; i < valueType.NumField(); i++ {
+		field := valueType.Field(i)
+		if field.Tag.Get(sourceTag) != sourcePath || !field.IsExported() {
+			continue
+		}
+
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "" || name == "-" {
+			name = field.Name
+		}
+		parameter, ok := parameters[name]
+		if !ok {
+			parameter = r.PathValue(name)
+		}
+		if parameter == "" {
+			continue
+		}
+
+		if err := setFieldValue(value.Field(i), parameter); err != nil {
+			return ValidationError.WithData(ValidationErrorData{
+				Errors: []FieldError{
+					{Field: name, Message: "invalid path parameter"},
+				},
+			})
+		}
+	}
+	return nil
+}
+
+func setFieldValue(field reflect.Value, parameter string) error {
+	if field.Kind() == reflect.Pointer {
+		if field.IsNil() {
+			field.Set(reflect.New(field.Type().Elem()))
+		}
+		return setFieldValue(field.Elem(), parameter)
+	}
+	unmarshaler, ok := field.Addr().Interface().(encoding.TextUnmarshaler)
+	if ok {
+		return unmarshaler.UnmarshalText([]byte(parameter))
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(parameter)
+	case reflect.Bool:
+		parsed, err := strconv.ParseBool(parameter)
+		if err != nil {
+			return err
+		}
+		field.SetBool(parsed)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		parsed, err := strconv.ParseInt(parameter, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(parsed)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64:
+		parsed, err := strconv.ParseUint(parameter, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(parsed)
+	case reflect.Float32, reflect.Float64:
+		parsed, err := strconv.ParseFloat(parameter, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetFloat(parsed)
+	default:
+		return fmt.Errorf("unsupported path parameter type: %s", field.Type())
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package inputlogic
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/pakkasys/fluidapi/core/api"
+	"github.com/stretchr/testify/assert"
+)
+
+type pathInput struct {
+	ID      int        `json:"id" source:"path"`
+	OrderID *string    `json:"orderID" source:"path"`
+	Active  bool       `source:"path"`
+	Since   *time.Time `json:"since" source:"path"`
+	Name    string     `json:"name"`
+}
+
+func (i pathInput) Validate() []FieldError {
+	return nil
+}
+
+// stubPicker picks the input by setting its name.
+type stubPicker struct {
+	err error
+}
+
+func (p stubPicker) PickObject(
+	r *http.Request,
+	w http.ResponseWriter,
+	obj pathInput,
+) (*pathInput, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	obj.Name = "picked"
+	return &obj, nil
+}
+
+// TestPathObjectPicker tests binding the path parameters of the context.
+func TestPathObjectPicker(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r = r.WithContext(api.WithPathParameters(
+		r.Context(),
+		map[string]string{
+			"id":      "5",
+			"orderID": "a1",
+			"Active":  "true",
+			"since":   "2024-01-02T03:04:05Z",
+		},
+	))
+
+	input, err := NewPathObjectPicker[pathInput](stubPicker{}).
+		PickObject(r, httptest.NewRecorder(), pathInput{})
+
+	orderID := "a1"
+	since := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	assert.NoError(t, err)
+	assert.Equal(t, &pathInput{
+		ID:      5,
+		OrderID: &orderID,
+		Active:  true,
+		Since:   &since,
+		Name:    "picked",
+	}, input)
+}
+
+// TestPathObjectPicker_PathValue tests binding the path values of the
+// request pattern without a wrapped picker.
+func TestPathObjectPicker_PathValue(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.SetPathValue("id", "7")
+
+	input, err := NewPathObjectPicker[pathInput](nil).
+		PickObject(r, httptest.NewRecorder(), pathInput{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, &pathInput{ID: 7}, input)
+}
+
+// TestPathObjectPicker_Errors tests the errors of picking the input.
+func TestPathObjectPicker_Errors(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.SetPathValue("id", "x")
+
+	_, err := NewPathObjectPicker[pathInput](nil).
+		PickObject(r, httptest.NewRecorder(), pathInput{})
+	assert.Equal(t, ValidationError.WithData(ValidationErrorData{
+		Errors: []FieldError{{Field: "id", Message: "invalid path parameter"}},
+	}), err)
+
+	_, err = NewPathObjectPicker[pathInput](
+		stubPicker{err: errors.New("decode error")},
+	).PickObject(r, httptest.NewRecorder(), pathInput{})
+	assert.EqualError(t, err, "decode error")
+}
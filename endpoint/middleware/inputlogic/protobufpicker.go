@@ -0,0 +1,80 @@
+package inputlogic
+
+import (
+	"io"
+	"mime"
+	"net/http"
+)
+
+// MediaTypeProtobuf is the media type of protobuf bodies.
+const MediaTypeProtobuf = "application/x-protobuf"
+
+// ProtobufObjectPicker is an object picker that decodes request bodies with
+// the protobuf media type into the input, e.g. with google.golang.org/protobuf:
+//
+//	inputlogic.NewProtobufObjectPicker(
+//		jsonPicker,
+//		func(data []byte, message any) error {
+//			return proto.Unmarshal(data, message.(proto.Message))
+//		},
+//	)
+//
+// The input is passed to the unmarshal function as a pointer, so the input
+// type must be a message type such as a generated struct. Other requests are
+// picked with the wrapped picker.
+type ProtobufObjectPicker[T any] struct {
+	// ObjectPicker picks the inputs of requests that are not protobuf.
+	ObjectPicker IObjectPicker[T]
+	// Unmarshal decodes the protobuf data into the message.
+	Unmarshal func(data []byte, message any) error
+}
+
+// NewProtobufObjectPicker returns a new protobuf object picker.
+//
+//   - objectPicker: The picker of the inputs of other requests.
+//   - unmarshal: The function decoding protobuf data into a message.
+func NewProtobufObjectPicker[T any](
+	objectPicker IObjectPicker[T],
+	unmarshal func(data []byte, message any) error,
+) *ProtobufObjectPicker[T] {
+	return &ProtobufObjectPicker[T]{
+		ObjectPicker: objectPicker,
+		Unmarshal:    unmarshal,
+	}
+}
+
+// PickObject decodes the protobuf body of the request or picks the input with
+// the wrapped picker.
+func (p *ProtobufObjectPicker[T]) PickObject(
+	r *http.Request,
+	w http.ResponseWriter,
+	obj T,
+) (*T, error) {
+	if !IsProtobufRequest(r) {
+		if p.ObjectPicker == nil {
+			return &obj, nil
+		}
+		return p.ObjectPicker.PickObject(r, w, obj)
+	}
+
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.Unmarshal(data, &obj); err != nil {
+		return nil, ValidationError.WithData(ValidationErrorData{
+			Errors: []FieldError{
+				{Field: "body", Message: "invalid protobuf body"},
+			},
+		})
+	}
+	return &obj, nil
+}
+
+// IsProtobufRequest returns whether the request body is protobuf.
+//
+//   - r: The HTTP request.
+func IsProtobufRequest(r *http.Request) bool {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return err == nil && mediaType == MediaTypeProtobuf
+}
@@ -0,0 +1,65 @@
+package inputlogic
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// unmarshalName is a fake protobuf unmarshal function setting the name.
+func unmarshalName(data []byte, message any) error {
+	if string(data) == "invalid" {
+		return errors.New("invalid")
+	}
+	message.(*pathInput).Name = string(data)
+	return nil
+}
+
+// TestProtobufObjectPicker tests decoding protobuf bodies.
+func TestProtobufObjectPicker(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("name"))
+	r.Header.Set("Content-Type", "application/x-protobuf; charset=utf-8")
+
+	input, err := NewProtobufObjectPicker[pathInput](
+		stubPicker{err: errors.New("not called")},
+		unmarshalName,
+	).PickObject(r, httptest.NewRecorder(), pathInput{ID: 1})
+
+	assert.NoError(t, err)
+	assert.Equal(t, &pathInput{ID: 1, Name: "name"}, input)
+}
+
+// TestProtobufObjectPicker_Other tests that other requests are picked with the
+// wrapped picker.
+func TestProtobufObjectPicker_Other(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("{}"))
+	r.Header.Set("Content-Type", "application/json")
+
+	picker := NewProtobufObjectPicker[pathInput](stubPicker{}, unmarshalName)
+	input, err := picker.PickObject(r, httptest.NewRecorder(), pathInput{})
+	assert.NoError(t, err)
+	assert.Equal(t, &pathInput{Name: "picked"}, input)
+
+	input, err = NewProtobufObjectPicker[pathInput](nil, unmarshalName).
+		PickObject(r, httptest.NewRecorder(), pathInput{})
+	assert.NoError(t, err)
+	assert.Equal(t, &pathInput{}, input)
+}
+
+// TestProtobufObjectPicker_Invalid tests decoding invalid protobuf bodies.
+func TestProtobufObjectPicker_Invalid(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("invalid"))
+	r.Header.Set("Content-Type", MediaTypeProtobuf)
+
+	input, err := NewProtobufObjectPicker[pathInput](nil, unmarshalName).
+		PickObject(r, httptest.NewRecorder(), pathInput{})
+
+	assert.Nil(t, input)
+	assert.Equal(t, ValidationError.WithData(ValidationErrorData{
+		Errors: []FieldError{{Field: "body", Message: "invalid protobuf body"}},
+	}), err)
+}
@@ -0,0 +1,242 @@
+package inputlogic
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Validator validates input structs in addition to their Validate methods,
+// e.g. using struct tags.
+type Validator interface {
+	// ValidateStruct returns the field errors of the input.
+	ValidateStruct(input any) []FieldError
+}
+
+// ValidatorFunc is a function implementing Validator.
+type ValidatorFunc func(input any) []FieldError
+
+// ValidateStruct calls the function.
+func (f ValidatorFunc) ValidateStruct(input any) []FieldError {
+	return f(input)
+}
+
+// NewValidatorAdapter adapts a struct validation function returning an error,
+// such as Struct of go-playground/validator, to a Validator.
+//
+//   - validate: The validation function.
+//   - toFieldErrors: The function converting validation errors to field
+//     errors.
+//
+// Example with go-playground/validator, using JSON names as field paths:
+//
+//	validate := validator.New()
+//	validate.RegisterTagNameFunc(func(f reflect.StructField) string {
+//		return strings.Split(f.Tag.Get("json"), ",")[0]
+//	})
+//	adapter := inputlogic.NewValidatorAdapter(
+//		validate.Struct,
+//		func(err error) []inputlogic.FieldError {
+//			var fieldErrors []inputlogic.FieldError
+//			for _, e := range err.(validator.ValidationErrors) {
+//				_, path, _ := strings.Cut(e.Namespace(), ".")
+//				fieldErrors = append(fieldErrors, inputlogic.FieldError{
+//					Field:   path,
+//					Message: e.Tag(),
+//				})
+//			}
+//			return fieldErrors
+//		},
+//	)
+func NewValidatorAdapter(
+	validate func(input any) error,
+	toFieldErrors func(err error) []FieldError,
+) Validator {
+	return ValidatorFunc(func(input any) []FieldError {
+		err := validate(input)
+		if err == nil {
+			return nil
+		}
+		return toFieldErrors(err)
+	})
+}
+
+// TagValidator validates input structs using `validate` struct tags. Fields
+// are reported with their JSON paths, e.g. "items[0].name". Nested structs,
+// pointers, slices and arrays are validated recursively.
+//
+// The supported rules are:
+//   - required: The value must not be the zero value.
+//   - min=N: Numbers must be at least N; strings, slices and maps must have
+//     at least N elements.
+//   - max=N: Numbers must be at most N; strings, slices and maps must have at
+//     most N elements.
+//   - len=N: Strings, slices and maps must have exactly N elements.
+//   - oneof=a b c: The value must be one of the space-separated values.
+//
+// Rules other than required are skipped for nil pointers.
+type TagValidator struct{}
+
+// ValidateStruct validates the tagged fields of the input.
+func (v TagValidator) ValidateStruct(input any) []FieldError {
+	var fieldErrors []FieldError
+	validateValue(reflect.ValueOf(input), "", &fieldErrors)
+	return fieldErrors
+}
+
+func validateValue(value reflect.Value, path string, errs *[]FieldError) {
+	for value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return
+		}
+		value = value.Elem()
+	}
+
+	switch value.Kind() {
+	case reflect.Struct:
+		valueType := value.Type()
+		for i := 0; i < valueType.NumField(); i++ {
+			field := valueType.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			name := strings.Split(field.Tag.Get("json"), ",")[0]
+			if name == "-" {
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+			fieldPath := name
+			if path != "" {
+				fieldPath = path + "." + name
+			}
+
+			rules := field.Tag.Get("validate")
+			if rules != "" {
+				message := validateRules(value.Field(i), rules)
+				if message != "" {
+					*errs = append(*errs, FieldError{
+						Field:   fieldPath,
+						Message: message,
+					})
+					continue
+				}
+			}
+			validateValue(value.Field(i), fieldPath, errs)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			validateValue(
+				value.Index(i),
+				fmt.Sprintf("%s[%d]", path, i),
+				errs,
+			)
+		}
+	}
+}
+
+// validateRules returns the message of the first rule the value violates, or
+// an empty string if the value is valid.
+func validateRules(value reflect.Value, rules string) string {
+	for _, rule := range strings.Split(rules, ",") {
+		name, parameter, _ := strings.Cut(strings.TrimSpace(rule), "=")
+		if name == "required" {
+			if value.IsZero() {
+				return "is required"
+			}
+			continue
+		}
+
+		for value.Kind() == reflect.Pointer {
+			if value.IsNil() {
+				return ""
+			}
+			value = value.Elem()
+		}
+
+		var message string
+		switch name {
+		case "min", "max", "len":
+			message = validateBound(value, name, parameter)
+		case "oneof":
+			if !slices.Contains(
+				strings.Fields(parameter),
+				fmt.Sprintf("%v", value.Interface()),
+			) {
+				message = "must be one of: " + parameter
+			}
+		default:
+			message = "has unknown validation rule: " + name
+		}
+		if message != "" {
+			return message
+		}
+	}
+	return ""
+}
+
+func validateBound(value reflect.Value, rule string, parameter string) string {
+	bound, err := strconv.ParseFloat(parameter, 64)
+	if err != nil {
+		return "has invalid validation rule: " + rule + "=" + parameter
+	}
+
+	var actual float64
+	unit := ""
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Int64:
+		actual = float64(value.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64:
+		actual = float64(value.Uint())
+	case reflect.Float32, reflect.Float64:
+		actual = value.Float()
+	case reflect.String:
+		actual = float64(len([]rune(value.String())))
+		unit = " characters"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		actual = float64(value.Len())
+		unit = " elements"
+	default:
+		return "has unsupported validation rule: " + rule
+	}
+
+	switch {
+	case rule == "min" && actual < bound:
+		return fmt.Sprintf("must be at least %s%s", parameter, unit)
+	case rule == "max" && actual > bound:
+		return fmt.Sprintf("must be at most %s%s", parameter, unit)
+	case rule == "len" && actual != bound:
+		return fmt.Sprintf("must have exactly %s%s", parameter, unit)
+	}
+	return ""
+}
+
+// validatingObjectPicker validates the picked inputs with a validator.
+type validatingObjectPicker[T any] struct {
+	objectPicker IObjectPicker[T]
+	validator    Validator
+}
+
+func (p *validatingObjectPicker[T]) PickObject(
+	r *http.Request,
+	w http.ResponseWriter,
+	obj T,
+) (*T, error) {
+	picked, err := p.objectPicker.PickObject(r, w, obj)
+	if err != nil {
+		return nil, err
+	}
+	fieldErrors := p.validator.ValidateStruct(picked)
+	if len(fieldErrors) > 0 {
+		return nil, ValidationError.WithData(ValidationErrorData{
+			Errors: fieldErrors,
+		})
+	}
+	return picked, nil
+}
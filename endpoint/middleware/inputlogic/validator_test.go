@@ -0,0 +1,207 @@
+package inputlogic
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pakkasys/fluidapi/core/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type tagItem struct {
+	Name     string `json:"name" validate:"required,max=3"`
+	Quantity int    `json:"quantity" validate:"min=1"`
+}
+
+func (i tagItem) Validate() []FieldError {
+	return nil
+}
+
+type tagInput struct {
+	Title    string    `json:"title" validate:"required"`
+	Code     string    `json:"code" validate:"len=2"`
+	Status   *string   `json:"status" validate:"oneof=open closed"`
+	Tags     []string  `json:"tags" validate:"max=2"`
+	Items    []tagItem `json:"items"`
+	Owner    *tagItem  `json:"owner,omitempty"`
+	Internal string    `json:"-" validate:"required"`
+	Plain    float64   `validate:"max=1.5"`
+}
+
+// TestTagValidator_Valid tests that valid inputs have no field errors.
+func TestTagValidator_Valid(t *testing.T) {
+	status := "open"
+	input := tagInput{
+		Title:  "title",
+		Code:   "ab",
+		Status: &status,
+		Items:  []tagItem{{Name: "a", Quantity: 1}},
+	}
+
+	assert.Empty(t, TagValidator{}.ValidateStruct(&input))
+}
+
+// TestTagValidator_Invalid tests the field errors and their JSON paths.
+func TestTagValidator_Invalid(t *testing.T) {
+	status := "pending"
+	input := tagInput{
+		Code:   "abc",
+		Status: &status,
+		Tags:   []string{"a", "b", "c"},
+		Items: []tagItem{
+			{Name: "a", Quantity: 1},
+			{Name: "long", Quantity: 0},
+		},
+		Owner: &tagItem{Quantity: 1},
+		Plain: 2,
+	}
+
+	fieldErrors := TagValidator{}.ValidateStruct(&input)
+
+	assert.Equal(t, []FieldError{
+		{Field: "title", Message: "is required"},
+		{Field: "code", Message: "must have exactly 2 characters"},
+		{Field: "status", Message: "must be one of: open closed"},
+		{Field: "tags", Message: "must be at most 2 elements"},
+		{Field: "items[1].name", Message: "must be at most 3 characters"},
+		{Field: "items[1].quantity", Message: "must be at least 1"},
+		{Field: "owner.name", Message: "is required"},
+		{Field: "Plain", Message: "must be at most 1.5"},
+	}, fieldErrors)
+}
+
+// TestTagValidator_InvalidRules tests the messages of invalid rules.
+func TestTagValidator_InvalidRules(t *testing.T) {
+	input := struct {
+		A string `json:"a" validate:"email"`
+		B string `json:"b" validate:"min=x"`
+		C bool   `json:"c" validate:"max=1"`
+	}{}
+
+	fieldErrors := TagValidator{}.ValidateStruct(input)
+
+	assert.Equal(t, []FieldError{
+		{Field: "a", Message: "has unknown validation rule: email"},
+		{Field: "b", Message: "has invalid validation rule: min=x"},
+		{Field: "c", Message: "has unsupported validation rule: max"},
+	}, fieldErrors)
+}
+
+// TestNewValidatorAdapter tests adapting validation functions.
+func TestNewValidatorAdapter(t *testing.T) {
+	validationErr := errors.New("invalid")
+	adapter := NewValidatorAdapter(
+		func(input any) error {
+			if input == "invalid" {
+				return validationErr
+			}
+			return nil
+		},
+		func(err error) []FieldError {
+			return []FieldError{{Field: "field", Message: err.Error()}}
+		},
+	)
+
+	assert.Nil(t, adapter.ValidateStruct("valid"))
+	assert.Equal(
+		t,
+		[]FieldError{{Field: "field", Message: "invalid"}},
+		adapter.ValidateStruct("invalid"),
+	)
+}
+
+// TestValidatingObjectPicker tests validating the picked input.
+func TestValidatingObjectPicker(t *testing.T) {
+	r := httptest.NewRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+	objectPicker := &MockObjectPicker[tagItem]{}
+	objectPicker.On("PickObject", r, w, tagItem{}).
+		Return(&tagItem{Name: "a", Quantity: 1}, nil).Once()
+	objectPicker.On("PickObject", r, w, tagItem{}).
+		Return(&tagItem{Quantity: 1}, nil).Once()
+	picker := &validatingObjectPicker[tagItem]{
+		objectPicker: objectPicker,
+		validator:    TagValidator{},
+	}
+
+	picked, err := picker.PickObject(r, w, tagItem{})
+	assert.NoError(t, err)
+	assert.Equal(t, &tagItem{Name: "a", Quantity: 1}, picked)
+
+	picked, err = picker.PickObject(r, w, tagItem{})
+	assert.Nil(t, picked)
+	assert.Equal(t, ValidationError.WithData(ValidationErrorData{
+		Errors: []FieldError{{Field: "name", Message: "is required"}},
+	}), err)
+}
+
+// TestValidatingObjectPicker_PickError tests that picker errors are returned.
+func TestValidatingObjectPicker_PickError(t *testing.T) {
+	r := httptest.NewRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+	pickErr := errors.New("pick error")
+	objectPicker := &MockObjectPicker[tagItem]{}
+	objectPicker.On("PickObject", r, w, tagItem{}).Return(nil, pickErr)
+	picker := &validatingObjectPicker[tagItem]{
+		objectPicker: objectPicker,
+		validator:    TagValidator{},
+	}
+
+	picked, err := picker.PickObject(r, w, tagItem{})
+
+	assert.Nil(t, picked)
+	assert.Equal(t, pickErr, err)
+	objectPicker.AssertExpectations(t)
+}
+
+// TestMiddlewareWrapper_Validator tests that the middleware responds with the
+// field errors of the validator.
+func TestMiddlewareWrapper_Validator(t *testing.T) {
+	r := httptest.NewRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+	objectPicker := &MockObjectPicker[tagItem]{}
+	outputHandler := &MockOutputHandler{}
+	logger := &MockLogger{}
+	objectPicker.On("PickObject", r, w, tagItem{}).
+		Return(&tagItem{Quantity: 1}, nil)
+	logger.On("Debug", mock.Anything)
+	outputHandler.
+		On(
+			"ProcessOutput",
+			w,
+			r,
+			mock.Anything,
+			mock.MatchedBy(func(err error) bool {
+				apiErr, ok := err.(*api.Error[any])
+				return ok && apiErr.ID == ValidationError.ID
+			}),
+			http.StatusBadRequest,
+		).
+		Return(nil)
+
+	wrapper := MiddlewareWrapper(
+		func(
+			w http.ResponseWriter,
+			r *http.Request,
+			i *tagItem,
+		) (*string, error) {
+			t.Fatal("callback must not be called")
+			return nil, nil
+		},
+		func() *tagItem { return &tagItem{} },
+		nil,
+		Options[tagItem]{
+			ObjectPicker:  objectPicker,
+			OutputHandler: outputHandler,
+			Logger:        logger,
+			Validator:     TagValidator{},
+		},
+	)
+	wrapper.Middleware(http.NotFoundHandler()).ServeHTTP(w, r)
+
+	objectPicker.AssertExpectations(t)
+	outputHandler.AssertExpectations(t)
+}
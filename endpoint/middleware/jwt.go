@@ -0,0 +1,90 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/pakkasys/fluidapi/core/api"
+	"github.com/pakkasys/fluidapi/endpoint/auth"
+	"github.com/pakkasys/fluidapi/endpoint/middleware/inputlogic"
+)
+
+const JWTMiddlewareID = "jwt"
+
+// TokenVerifier verifies bearer tokens, e.g. auth.Verifier.
+type TokenVerifier interface {
+	Verify(ctx context.Context, token string) (auth.Claims, error)
+}
+
+// JWTMiddlewareWrapper creates a new MiddlewareWrapper for the JWT
+// middleware. This middleware authenticates requests with bearer JWTs.
+//
+//   - verifier: The verifier of the tokens.
+//   - outputHandler: The handler sending the errors to the client.
+func JWTMiddlewareWrapper(
+	verifier TokenVerifier,
+	outputHandler inputlogic.IOutputHandler,
+) *api.MiddlewareWrapper {
+	return &api.MiddlewareWrapper{
+		ID:         JWTMiddlewareID,
+		Middleware: JWTMiddleware(verifier, outputHandler),
+	}
+}
+
+// JWTMiddleware constructs a middleware that verifies the bearer token of the
+// Authorization header and stores its claims in the custom context, from
+// where they can be read with auth.GetClaims. Requests without a valid token
+// are rejected with auth.UnauthorizedError, whose status is looked up in
+// auth.Errors. The custom context must be set before this middleware.
+//
+//   - verifier: The verifier of the tokens.
+//   - outputHandler: The handler sending the errors to the client.
+func JWTMiddleware(
+	verifier TokenVerifier,
+	outputHandler inputlogic.IOutputHandler,
+) api.Middleware {
+	if verifier == nil {
+		panic("verifier cannot be nil")
+	}
+	if outputHandler == nil {
+		panic("output handler cannot be nil")
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims, err := verifyBearerToken(r, verifier)
+			if err != nil {
+				statusCode, outError := inputlogic.ErrorHandler{}.Handle(
+					err,
+					auth.Errors,
+				)
+				if statusCode == http.StatusUnauthorized {
+					w.Header().Set("WWW-Authenticate", "Bearer")
+				}
+				_ = outputHandler.ProcessOutput(
+					w,
+					r,
+					nil,
+					outError,
+					statusCode,
+				)
+				return
+			}
+
+			auth.SetClaims(r.Context(), claims)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func verifyBearerToken(
+	r *http.Request,
+	verifier TokenVerifier,
+) (auth.Claims, error) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") || token == "" {
+		return nil, auth.UnauthorizedError.WithMessage("missing bearer token")
+	}
+	return verifier.Verify(r.Context(), strings.TrimSpace(token))
+}
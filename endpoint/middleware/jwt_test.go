@@ -0,0 +1,108 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pakkasys/fluidapi/endpoint/auth"
+	"github.com/pakkasys/fluidapi/endpoint/util"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubTokenVerifier accepts the token "valid".
+type stubTokenVerifier struct {
+	err error
+}
+
+func (v stubTokenVerifier) Verify(
+	ctx context.Context,
+	token string,
+) (auth.Claims, error) {
+	if v.err != nil {
+		return nil, v.err
+	}
+	if token != "valid" {
+		return nil, auth.UnauthorizedError.WithMessage("invalid token")
+	}
+	return auth.Claims{"sub": "user"}, nil
+}
+
+func jwtTestRequest(authorization string) *http.Request {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	if authorization != "" {
+		r.Header.Set("Authorization", authorization)
+	}
+	return r.WithContext(util.NewContext(r.Context()))
+}
+
+// TestJWTMiddlewareWrapper tests the ID of the middleware wrapper.
+func TestJWTMiddlewareWrapper(t *testing.T) {
+	wrapper := JWTMiddlewareWrapper(
+		stubTokenVerifier{},
+		timeoutOutputHandler{},
+	)
+
+	assert.Equal(t, JWTMiddlewareID, wrapper.ID)
+	assert.NotNil(t, wrapper.Middleware)
+}
+
+// TestJWTMiddleware_Valid tests that the claims of valid tokens are stored.
+func TestJWTMiddleware_Valid(t *testing.T) {
+	var subject string
+	handler := JWTMiddleware(stubTokenVerifier{}, timeoutOutputHandler{})(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			subject = auth.Subject(r.Context())
+		}),
+	)
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, jwtTestRequest("bearer valid"))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "user", subject)
+}
+
+// TestJWTMiddleware_Unauthorized tests rejecting requests without a valid
+// token.
+func TestJWTMiddleware_Unauthorized(t *testing.T) {
+	handler := JWTMiddleware(stubTokenVerifier{}, timeoutOutputHandler{})(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			t.Fatal("handler must not be called")
+		}),
+	)
+
+	for _, authorization := range []string{"", "Basic abc", "Bearer bad"} {
+		w := httptest.NewRecorder()
+
+		handler.ServeHTTP(w, jwtTestRequest(authorization))
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code, authorization)
+		assert.Equal(t, "Bearer", w.Header().Get("WWW-Authenticate"))
+		assert.Equal(t, auth.UnauthorizedError.ID, w.Body.String())
+	}
+}
+
+// TestJWTMiddleware_Error tests that other verification errors are internal
+// server errors.
+func TestJWTMiddleware_Error(t *testing.T) {
+	handler := JWTMiddleware(
+		stubTokenVerifier{err: errors.New("fetch JWKS")},
+		timeoutOutputHandler{},
+	)(http.NotFoundHandler())
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, jwtTestRequest("Bearer valid"))
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Empty(t, w.Header().Get("WWW-Authenticate"))
+}
+
+// TestJWTMiddleware_Nil tests that the middleware panics without a verifier
+// or an output handler.
+func TestJWTMiddleware_Nil(t *testing.T) {
+	assert.Panics(t, func() { JWTMiddleware(nil, timeoutOutputHandler{}) })
+	assert.Panics(t, func() { JWTMiddleware(stubTokenVerifier{}, nil) })
+}
@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/pakkasys/fluidapi/core/api"
+	"github.com/pakkasys/fluidapi/endpoint/metrics"
+)
+
+const MetricsMiddlewareID = "metrics"
+
+// MetricsMiddlewareWrapper creates a new MiddlewareWrapper for the Metrics
+// middleware. This middleware records the metrics of the requests.
+//
+//   - recorder: The recorder of the metrics, e.g. metrics.Metrics.
+func MetricsMiddlewareWrapper(
+	recorder metrics.Recorder,
+) *api.MiddlewareWrapper {
+	return &api.MiddlewareWrapper{
+		ID:         MetricsMiddlewareID,
+		Middleware: MetricsMiddleware(recorder),
+	}
+}
+
+// MetricsMiddleware constructs a middleware that records the start and end
+// of each request with its status and duration. The requests are labeled by
+// the URL pattern the server routed them with, so that URLs with path
+// parameters share their metrics. Panics are recorded as server errors.
+//
+//   - recorder: The recorder of the metrics, e.g. metrics.Metrics.
+func MetricsMiddleware(recorder metrics.Recorder) api.Middleware {
+	if recorder == nil {
+		panic("recorder cannot be nil")
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			url := r.Pattern
+			if url == "" {
+				url = r.URL.Path
+			}
+
+			recorder.RequestStarted(url, r.Method)
+			start := time.Now()
+			sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+			defer func() {
+				status := sw.status
+				err := recover()
+				if err != nil {
+					status = http.StatusInternalServerError
+				}
+				recorder.RequestFinished(
+					url,
+					r.Method,
+					status,
+					time.Since(start),
+				)
+				if err != nil {
+					panic(err)
+				}
+			}()
+
+			next.ServeHTTP(sw, r)
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type finishedRequest struct {
+	url    string
+	method string
+	status int
+}
+
+type recordingMetrics struct {
+	started  []string
+	finished []finishedRequest
+}
+
+func (m *recordingMetrics) RequestStarted(url string, method string) {
+	m.started = append(m.started, method+" "+url)
+}
+
+func (m *recordingMetrics) RequestFinished(
+	url string,
+	method string,
+	status int,
+	duration time.Duration,
+) {
+	m.finished = append(m.finished, finishedRequest{url, method, status})
+}
+
+// TestMetricsMiddlewareWrapper tests the ID of the wrapper.
+func TestMetricsMiddlewareWrapper(t *testing.T) {
+	wrapper := MetricsMiddlewareWrapper(&recordingMetrics{})
+
+	assert.Equal(t, MetricsMiddlewareID, wrapper.ID)
+	assert.NotNil(t, wrapper.Middleware)
+}
+
+// TestMetricsMiddleware tests recording requests labeled by the URL pattern.
+func TestMetricsMiddleware(t *testing.T) {
+	recorder := &recordingMetrics{}
+	mux := http.NewServeMux()
+	mux.Handle("/users/{id}", MetricsMiddleware(recorder)(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		}),
+	))
+
+	mux.ServeHTTP(
+		httptest.NewRecorder(),
+		httptest.NewRequest("GET", "/users/5", nil),
+	)
+
+	assert.Equal(t, []string{"GET /users/{id}"}, recorder.started)
+	assert.Equal(
+		t,
+		[]finishedRequest{{"/users/{id}", "GET", http.StatusNotFound}},
+		recorder.finished,
+	)
+}
+
+// TestMetricsMiddleware_Panic tests recording a panic as a server error.
+func TestMetricsMiddleware_Panic(t *testing.T) {
+	recorder := &recordingMetrics{}
+	handler := MetricsMiddleware(recorder)(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			panic("test panic")
+		}),
+	)
+
+	assert.PanicsWithValue(t, "test panic", func() {
+		handler.ServeHTTP(
+			httptest.NewRecorder(),
+			httptest.NewRequest("GET", "/", nil),
+		)
+	})
+	assert.Equal(
+		t,
+		[]finishedRequest{{"/", "GET", http.StatusInternalServerError}},
+		recorder.finished,
+	)
+}
+
+// TestMetricsMiddleware_NilRecorder tests that a nil recorder panics.
+func TestMetricsMiddleware_NilRecorder(t *testing.T) {
+	assert.Panics(t, func() { MetricsMiddleware(nil) })
+}
@@ -2,12 +2,16 @@ package middleware
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"net/http"
 	"runtime"
 
 	"github.com/pakkasys/fluidapi/core/api"
+	"github.com/pakkasys/fluidapi/core/buildinfo"
+	"github.com/pakkasys/fluidapi/core/logging"
+	"github.com/pakkasys/fluidapi/endpoint/middleware/inputlogic"
 )
 
 const (
@@ -23,7 +27,8 @@ type ResponseWrapper interface {
 	Header() http.Header
 }
 
-type requestDumpData struct {
+// RequestDumpData is the dump of the request and the response of a panic.
+type RequestDumpData struct {
 	StatusCode int
 	Request    struct {
 		URL     string
@@ -43,43 +48,143 @@ type responseData struct {
 	Body       string
 }
 
-type panicData struct {
+// PanicData is the information logged and notified of a panic.
+type PanicData struct {
 	Err         any             `json:"err"`
-	RequestDump requestDumpData `json:"request_dump"`
+	RequestID   string          `json:"request_id"`
+	RequestDump RequestDumpData `json:"request_dump"`
 	StackTrace  []string        `json:"stack_trace"`
+	Build       buildinfo.Info  `json:"build"`
+}
+
+// PanicNotifier is notified of the panics, e.g. to report them to an error
+// tracker such as Sentry.
+type PanicNotifier interface {
+	// NotifyPanic is called with the information of a recovered panic.
+	NotifyPanic(ctx context.Context, data PanicData)
+}
+
+// PanicNotifierFunc is a function implementing PanicNotifier.
+type PanicNotifierFunc func(ctx context.Context, data PanicData)
+
+// NotifyPanic calls the function.
+func (f PanicNotifierFunc) NotifyPanic(ctx context.Context, data PanicData) {
+	f(ctx, data)
+}
+
+// PanicDumpLimits are the size limits of the request dump of a panic. The
+// dumped values are truncated to the limits.
+type PanicDumpLimits struct {
+	// Body is the maximum size of the request body
+	Body int
+	// Header is the maximum size of a header value
+	Header int
+	// Query is the maximum size of the query parameters
+	Query int
+}
+
+// PanicHandlerOption configures the panic handler middleware.
+type PanicHandlerOption func(*panicHandlerOptions)
+
+type panicHandlerOptions struct {
+	outputHandler inputlogic.IOutputHandler
+	apiError      api.APIError
+	limits        PanicDumpLimits
+	notifiers     []PanicNotifier
+}
+
+// WithPanicOutputHandler writes the error responses of the panics with an
+// output handler instead of a plain text 500 response.
+//
+//   - outputHandler: The handler sending the errors to the client.
+//   - apiError: The error of the responses. If nil,
+//     inputlogic.InternalServerError is used.
+func WithPanicOutputHandler(
+	outputHandler inputlogic.IOutputHandler,
+	apiError api.APIError,
+) PanicHandlerOption {
+	return func(o *panicHandlerOptions) {
+		o.outputHandler = outputHandler
+		o.apiError = apiError
+	}
+}
+
+// WithPanicDumpLimits sets the size limits of the request dumps. A zero
+// limit is 1 MiB.
+//
+//   - limits: The size limits.
+func WithPanicDumpLimits(limits PanicDumpLimits) PanicHandlerOption {
+	return func(o *panicHandlerOptions) {
+		o.limits = limits
+	}
+}
+
+// WithPanicNotifier adds a notifier of the panics.
+//
+//   - notifier: The notifier.
+func WithPanicNotifier(notifier PanicNotifier) PanicHandlerOption {
+	return func(o *panicHandlerOptions) {
+		o.notifiers = append(o.notifiers, notifier)
+	}
+}
+
+func newPanicHandlerOptions(opts []PanicHandlerOption) *panicHandlerOptions {
+	o := &panicHandlerOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.limits.Body <= 0 {
+		o.limits.Body = maxDumpSize
+	}
+	if o.limits.Header <= 0 {
+		o.limits.Header = maxDumpSize
+	}
+	if o.limits.Query <= 0 {
+		o.limits.Query = maxDumpSize
+	}
+	if o.apiError == nil {
+		o.apiError = inputlogic.InternalServerError
+	}
+	return o
 }
 
 // PanicHandlerMiddlewareWrapper creates a new MiddlewareWrapper for
 // the Panic Handler middleware. This middleware catches and logs any panics
 // during the request lifecycle.
 //
-//   - loggerFn: A function that logs panic information for the request.
+//   - logger: The logger of the panic information.
+//   - opts: Options such as WithPanicOutputHandler and WithPanicNotifier.
 func PanicHandlerMiddlewareWrapper(
-	loggerFn func(r *http.Request) func(messages ...any),
+	logger logging.Logger,
+	opts ...PanicHandlerOption,
 ) *api.MiddlewareWrapper {
 	return &api.MiddlewareWrapper{
 		ID:         PanicHandlerMiddlewareID,
-		Middleware: PanicHandlerMiddleware(loggerFn),
+		Middleware: PanicHandlerMiddleware(logger, opts...),
 	}
 }
 
 // PanicHandlerMiddleware constructs a middleware that captures and logs any
-// panic events during request handling. It uses the provided panic handler
-// logger function to log the details.
+// panic events during request handling. The details are logged as an error
+// with the "panic" attribute and passed to the notifiers. The response is a
+// plain text 500 unless an output handler is set.
 //
-//   - panicHandlerLoggerFn: A function that logs messages in the event of a panic.
+//   - logger: The logger of the panic information.
+//   - opts: Options such as WithPanicOutputHandler and WithPanicNotifier.
 func PanicHandlerMiddleware(
-	panicHandlerLoggerFn func(r *http.Request) func(messages ...any),
+	logger logging.Logger,
+	opts ...PanicHandlerOption,
 ) api.Middleware {
-	if panicHandlerLoggerFn == nil {
-		panic("panicHandlerLoggerFn cannot be nil")
+	if logger == nil {
+		panic("logger cannot be nil")
 	}
+	o := newPanicHandlerOptions(opts)
 
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			defer func() {
 				if err := recover(); err != nil {
-					handlePanic(w, r, err, panicHandlerLoggerFn)
+					handlePanic(w, r, err, logger, o)
 				}
 			}()
 
@@ -92,32 +197,53 @@ func handlePanic(
 	w http.ResponseWriter,
 	r *http.Request,
 	err any,
-	panicHandlerLoggerFn func(r *http.Request) func(messages ...any),
+	logger logging.Logger,
+	o *panicHandlerOptions,
 ) {
 	var rd responseData
 	rw := GetResponseWrapper(r)
 	if rw != nil {
 		rd = responseData{
 			StatusCode: rw.StatusCode,
-			Headers:    limitHeaders(rw.Header(), maxDumpSize),
+			Headers:    limitHeaders(rw.Header(), o.limits.Header),
 			Body:       string(rw.Body),
 		}
 	}
 
-	panicHandlerLoggerFn(r)(
-		"Panic",
-		panicData{
-			Err:         err,
-			RequestDump: *createRequestDumpData(rd, r),
-			StackTrace:  stackTraceSlice(),
-		},
-	)
+	data := PanicData{
+		Err:         err,
+		RequestID:   GetRequestID(r.Context()),
+		RequestDump: *createRequestDumpData(rd, r, o.limits),
+		StackTrace:  stackTraceSlice(),
+		Build:       buildinfo.Get(),
+	}
+	logger.Error(r.Context(), "Panic", "panic", data)
+	for _, notifier := range o.notifiers {
+		notifier.NotifyPanic(r.Context(), data)
+	}
 
-	http.Error(
+	if o.outputHandler == nil {
+		http.Error(
+			w,
+			http.StatusText(http.StatusInternalServerError),
+			http.StatusInternalServerError,
+		)
+		return
+	}
+	outputErr := o.outputHandler.ProcessOutput(
 		w,
-		http.StatusText(http.StatusInternalServerError),
+		r,
+		nil,
+		o.apiError,
 		http.StatusInternalServerError,
 	)
+	if outputErr != nil {
+		logger.Error(
+			r.Context(),
+			"Error processing panic output",
+			"error", outputErr,
+		)
+	}
 }
 
 func stackTraceSlice() []string {
@@ -144,13 +270,14 @@ func stackTraceSlice() []string {
 func createRequestDumpData(
 	rd responseData,
 	r *http.Request,
-) *requestDumpData {
-	requestBody, err := readBodyWithLimit(r.Body, maxDumpSize)
+	limits PanicDumpLimits,
+) *RequestDumpData {
+	requestBody, err := readBodyWithLimit(r.Body, int64(limits.Body))
 	if err != nil {
 		requestBody = "Error reading request body"
 	}
 
-	return &requestDumpData{
+	return &RequestDumpData{
 		StatusCode: rd.StatusCode,
 		Request: struct {
 			URL     string
@@ -159,15 +286,15 @@ func createRequestDumpData(
 			Body    string
 		}{
 			URL:     r.URL.String(),
-			Params:  limitQueryParameters(r.URL.RawQuery, maxDumpSize),
-			Headers: limitHeaders(r.Header, maxDumpSize),
+			Params:  limitQueryParameters(r.URL.RawQuery, limits.Query),
+			Headers: limitHeaders(r.Header, limits.Header),
 			Body:    requestBody,
 		},
 		Response: struct {
 			Headers map[string][]string
 			Body    string
 		}{
-			Headers: limitHeaders(rd.Headers, maxDumpSize),
+			Headers: limitHeaders(rd.Headers, limits.Header),
 			Body:    rd.Body,
 		},
 	}
@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"net/http"
@@ -8,6 +9,10 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/pakkasys/fluidapi/core/api"
+	"github.com/pakkasys/fluidapi/core/buildinfo"
+	"github.com/pakkasys/fluidapi/core/logging/mock"
+	"github.com/pakkasys/fluidapi/endpoint/middleware/inputlogic"
 	"github.com/pakkasys/fluidapi/endpoint/util"
 	"github.com/stretchr/testify/assert"
 )
@@ -33,14 +38,9 @@ func (errReader) Read([]byte) (int, error) {
 // TestPanicHandlerMiddlewareWrapper tests the PanicHandlerMiddlewareWrapper
 // function.
 func TestPanicHandlerMiddlewareWrapper(t *testing.T) {
-	var loggedMessages []any
-	mockLoggerFn := func(r *http.Request) func(messages ...any) {
-		return func(messages ...any) {
-			loggedMessages = append(loggedMessages, messages...)
-		}
-	}
+	logger := &mock.Recorder{}
 
-	wrapper := PanicHandlerMiddlewareWrapper(mockLoggerFn)
+	wrapper := PanicHandlerMiddlewareWrapper(logger)
 	assert.Equal(t, PanicHandlerMiddlewareID, wrapper.ID)
 
 	mockHandler := http.HandlerFunc(
@@ -56,17 +56,13 @@ func TestPanicHandlerMiddlewareWrapper(t *testing.T) {
 	handler.ServeHTTP(w, req)
 
 	assert.Equal(t, http.StatusInternalServerError, w.Code)
-	assert.Len(t, loggedMessages, 2, "Expected Panic and panicData messages")
+	entries := logger.Entries()
+	assert.Len(t, entries, 1, "Expected Panic message")
 }
 
 // TestPanicHandlerMiddleware tests the PanicHandlerMiddleware function.
 func TestPanicHandlerMiddleware(t *testing.T) {
-	var loggedMessages []any
-	mockLoggerFn := func(r *http.Request) func(messages ...any) {
-		return func(messages ...any) {
-			loggedMessages = append(loggedMessages, messages...)
-		}
-	}
+	logger := &mock.Recorder{}
 
 	mockHandler := http.HandlerFunc(
 		func(w http.ResponseWriter, r *http.Request) {
@@ -75,7 +71,7 @@ func TestPanicHandlerMiddleware(t *testing.T) {
 	)
 
 	// Call the middleware
-	middleware := PanicHandlerMiddleware(mockLoggerFn)
+	middleware := PanicHandlerMiddleware(logger)
 	wrappedHandler := middleware(mockHandler)
 	req := httptest.NewRequest("GET", "/panic", nil)
 	w := httptest.NewRecorder()
@@ -90,25 +86,50 @@ func TestPanicHandlerMiddleware(t *testing.T) {
 	)
 
 	// Check that the panic was logged
-	assert.Len(t, loggedMessages, 2, "Expected Panic and panicData messages")
-	assert.Equal(t, "Panic", loggedMessages[0], "Expected panic msg")
-	assert.IsType(t, panicData{}, loggedMessages[1], "Expected panicData msg")
+	entries := logger.Entries()
+	assert.Len(t, entries, 1, "Expected Panic message")
+	assert.Equal(t, "Panic", entries[0].Message, "Expected panic msg")
+	assert.IsType(
+		t,
+		PanicData{},
+		entries[0].Arg("panic"),
+		"Expected panicData msg",
+	)
 
 	// Check that the panic data includes the correct error and stack trace
-	panicDataLogged := loggedMessages[1].(panicData)
+	panicDataLogged := entries[0].Arg("panic").(PanicData)
 	assert.Equal(t, "test panic", panicDataLogged.Err, "Expected panic message")
 	assert.NotEmpty(t, panicDataLogged.StackTrace, "Expected a stack trace")
+	assert.Equal(t, buildinfo.Get(), panicDataLogged.Build, "Expected build")
+}
+
+// TestPanicHandlerMiddleware_RequestID tests that the request ID is included
+// in the panic data.
+func TestPanicHandlerMiddleware_RequestID(t *testing.T) {
+	logger := &mock.Recorder{}
+	handler := RequestIDMiddleware(func() string { return "test-request-id" })(
+		PanicHandlerMiddleware(logger)(http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				panic("test panic")
+			},
+		)),
+	)
+	req := httptest.NewRequest("GET", "/panic", nil)
+	req = req.WithContext(util.NewContext(req.Context()))
+
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	entries := logger.Entries()
+	assert.Len(t, entries, 1, "Expected Panic message")
+	panicDataLogged := entries[0].Arg("panic").(PanicData)
+	assert.Equal(t, "test-request-id", panicDataLogged.RequestID)
+	assert.Equal(t, "test-request-id", entries[0].Arg("request_id"))
 }
 
 // TestPanicHandlerMiddleware_NoPanic tests the PanicHandlerMiddleware function
 // when there is no panic.
 func TestPanicHandlerMiddleware_NoPanic(t *testing.T) {
-	var loggerCalled bool
-	mockLoggerFn := func(r *http.Request) func(messages ...any) {
-		return func(messages ...any) {
-			loggerCalled = true
-		}
-	}
+	logger := &mock.Recorder{}
 
 	mockHandler := http.HandlerFunc(
 		func(w http.ResponseWriter, r *http.Request) {
@@ -117,14 +138,14 @@ func TestPanicHandlerMiddleware_NoPanic(t *testing.T) {
 	)
 
 	// Call the middleware
-	middleware := PanicHandlerMiddleware(mockLoggerFn)
+	middleware := PanicHandlerMiddleware(logger)
 	wrappedHandler := middleware(mockHandler)
 	req := httptest.NewRequest("GET", "/no-panic", nil)
 	w := httptest.NewRecorder()
 	wrappedHandler.ServeHTTP(w, req)
 
 	assert.Equal(t, http.StatusOK, w.Code, "Expected 200 status code")
-	assert.False(t, loggerCalled, "Expected logger not to be called")
+	assert.Empty(t, logger.Entries(), "Expected logger not to be called")
 }
 
 // TestPanicHandlerMiddleware_NilLogger tests the PanicHandlerMiddleware
@@ -132,24 +153,77 @@ func TestPanicHandlerMiddleware_NoPanic(t *testing.T) {
 func TestPanicHandlerMiddleware_NilLogger(t *testing.T) {
 	assert.Panics(t, func() {
 		PanicHandlerMiddleware(nil)
-	}, "Expected panic when passing nil panicHandlerLoggerFn")
+	}, "Expected panic when passing nil logger")
+}
+
+// TestPanicHandlerMiddleware_Options tests writing the error with the output
+// handler, notifying the panic and limiting the dump.
+func TestPanicHandlerMiddleware_Options(t *testing.T) {
+	logger := &mock.Recorder{}
+	var notified []PanicData
+	handler := PanicHandlerMiddleware(
+		logger,
+		WithPanicOutputHandler(
+			timeoutOutputHandler{},
+			api.NewError[any]("PANIC"),
+		),
+		WithPanicNotifier(PanicNotifierFunc(
+			func(ctx context.Context, data PanicData) {
+				notified = append(notified, data)
+			},
+		)),
+		WithPanicDumpLimits(PanicDumpLimits{Body: 4, Header: 2, Query: 3}),
+	)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("test panic")
+	}))
+	req := httptest.NewRequest(
+		"POST",
+		"/panic?name=value",
+		strings.NewReader("request body"),
+	)
+	req.Header.Set("X-Token", "secret")
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, "PANIC", w.Body.String())
+	assert.Len(t, notified, 1)
+	assert.Equal(t, "test panic", notified[0].Err)
+	dump := notified[0].RequestDump.Request
+	assert.Equal(t, "requ... (truncated)", dump.Body)
+	assert.Equal(t, []string{"se... (truncated)"}, dump.Headers["X-Token"])
+	assert.Equal(t, "nam... (truncated)", dump.Params)
+	assert.Equal(t, notified[0], logger.Entries()[0].Arg("panic"))
+}
+
+// TestPanicHandlerMiddleware_DefaultError tests that the output handler
+// writes the internal server error by default.
+func TestPanicHandlerMiddleware_DefaultError(t *testing.T) {
+	handler := PanicHandlerMiddleware(
+		&mock.Recorder{},
+		WithPanicOutputHandler(timeoutOutputHandler{}, nil),
+	)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("test panic")
+	}))
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/panic", nil))
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, inputlogic.InternalServerError.ID, w.Body.String())
 }
 
 // TestHandlePanic tests the handlePanic function.
 func TestHandlePanic(t *testing.T) {
-	var loggedMessages []any
-	mockLoggerFn := func(r *http.Request) func(messages ...any) {
-		return func(messages ...any) {
-			loggedMessages = append(loggedMessages, messages...)
-		}
-	}
+	logger := &mock.Recorder{}
 
 	req := httptest.NewRequest("GET", "/panic", nil)
 	w := httptest.NewRecorder()
 	rw := util.NewResponseWrapper(w)
 
 	err := "test panic"
-	handlePanic(rw, req, err, mockLoggerFn)
+	handlePanic(rw, req, err, logger, newPanicHandlerOptions(nil))
 
 	// Check that the response has a 500 status code
 	assert.Equal(
@@ -160,11 +234,12 @@ func TestHandlePanic(t *testing.T) {
 	)
 
 	// Check that the panic was logged
-	assert.Len(t, loggedMessages, 2, "Expected Panic and panicData messages")
-	assert.Equal(t, "Panic", loggedMessages[0], "Expected 'Panic' message")
+	entries := logger.Entries()
+	assert.Len(t, entries, 1, "Expected Panic message")
+	assert.Equal(t, "Panic", entries[0].Message, "Expected 'Panic' message")
 
 	// Validate the logged panic data
-	panicDataLogged := loggedMessages[1].(panicData)
+	panicDataLogged := entries[0].Arg("panic").(PanicData)
 	assert.Equal(t, "test panic", panicDataLogged.Err, "Expected panic message")
 	assert.Equal(
 		t,
@@ -178,12 +253,7 @@ func TestHandlePanic(t *testing.T) {
 // TestHandlePanic_WithNonNilResponseData tests the handlePanic function with
 // non nil response data.
 func TestHandlePanic_WithNonNilResponseData(t *testing.T) {
-	var loggedMessages []any
-	mockLoggerFn := func(r *http.Request) func(messages ...any) {
-		return func(messages ...any) {
-			loggedMessages = append(loggedMessages, messages...)
-		}
-	}
+	logger := &mock.Recorder{}
 
 	req := httptest.NewRequest("GET", "/panic", nil)
 	w := httptest.NewRecorder()
@@ -194,14 +264,15 @@ func TestHandlePanic_WithNonNilResponseData(t *testing.T) {
 	rw.Body = []byte("test body")
 
 	err := "test panic"
-	handlePanic(rw, req, err, mockLoggerFn)
+	handlePanic(rw, req, err, logger, newPanicHandlerOptions(nil))
 
 	// Check that the panic was logged and that response data was included
-	assert.Len(t, loggedMessages, 2, "Expected Panic and panicData messages")
-	assert.Equal(t, "Panic", loggedMessages[0], "Expected 'Panic' message")
+	entries := logger.Entries()
+	assert.Len(t, entries, 1, "Expected Panic message")
+	assert.Equal(t, "Panic", entries[0].Message, "Expected 'Panic' message")
 
 	// Validate the logged panic data
-	panicDataLogged := loggedMessages[1].(panicData)
+	panicDataLogged := entries[0].Arg("panic").(PanicData)
 	assert.Equal(
 		t,
 		req.URL.String(),
@@ -262,7 +333,11 @@ func TestCreateRequestDumpData_ValidRequest(t *testing.T) {
 		StatusCode: http.StatusOK,
 	}
 
-	dumpData := createRequestDumpData(rd, req)
+	dumpData := createRequestDumpData(
+		rd,
+		req,
+		newPanicHandlerOptions(nil).limits,
+	)
 
 	// Assertions for request part
 	h := dumpData.Request.Headers
@@ -291,7 +366,11 @@ func TestCreateRequestDumpData_ErrorReadingBody(t *testing.T) {
 		StatusCode: http.StatusOK,
 	}
 
-	dumpData := createRequestDumpData(rd, req)
+	dumpData := createRequestDumpData(
+		rd,
+		req,
+		newPanicHandlerOptions(nil).limits,
+	)
 
 	// Assertions for request part
 	assert.Equal(
@@ -7,10 +7,19 @@ import (
 	"time"
 
 	"github.com/pakkasys/fluidapi/core/api"
+	"github.com/pakkasys/fluidapi/core/client"
+	"github.com/pakkasys/fluidapi/core/logging"
 	"github.com/pakkasys/fluidapi/endpoint/util"
 )
 
-const RequestIDMiddlewareID = "request_metadata"
+const (
+	RequestIDMiddlewareID = "request_metadata"
+
+	// RequestIDHeader is the header carrying the request ID.
+	RequestIDHeader = client.RequestIDHeader
+
+	maxRequestIDLength = 128
+)
 
 var dataKey = util.NewDataKey()
 
@@ -26,8 +35,8 @@ type RequestMetadata struct {
 }
 
 // RequestIDMiddlewareWrapper creates a new MiddlewareWrapper for the Request ID
-// middleware. This middleware generates a unique ID for each request and stores
-// it as metadata in the context.
+// middleware. This middleware reads or generates a unique ID for each request
+// and stores it as metadata in the context.
 //
 //   - requestIDFn: A function that generates a unique request ID.
 func RequestIDMiddlewareWrapper(
@@ -43,6 +52,11 @@ func RequestIDMiddlewareWrapper(
 // metadata and stores it in the request's context. This metadata can be used
 // for logging and tracking purposes.
 //
+// The request ID is read from the X-Request-ID header, or generated if the
+// header is missing or invalid. It is added to the response header and to the
+// request context, from where client.PropagateRequestID passes it on to
+// downstream services, and to the log attributes as "request_id".
+//
 //   - requestIDFn: A function that generates a unique request ID.
 func RequestIDMiddleware(requestIDFn func() string) api.Middleware {
 	if requestIDFn == nil {
@@ -51,15 +65,24 @@ func RequestIDMiddleware(requestIDFn func() string) api.Middleware {
 
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requestID := r.Header.Get(RequestIDHeader)
+			if !isValidRequestID(requestID) {
+				requestID = requestIDFn()
+			}
+			w.Header().Set(RequestIDHeader, requestID)
+
 			requestMetadata := RequestMetadata{
 				TimeStart:     time.Now().UTC(),
-				RequestID:     requestIDFn(),
+				RequestID:     requestID,
 				RemoteAddress: util.RequestIPAddress(r),
 				Protocol:      r.Proto,
 				HTTPMethod:    r.Method,
 				URL:           fmt.Sprintf("%s%s", r.Host, r.URL.Path),
 			}
 			util.SetContextValue(r.Context(), dataKey, &requestMetadata)
+			ctx := client.WithRequestID(r.Context(), requestID)
+			ctx = logging.WithAttrs(ctx, "request_id", requestID)
+			r = r.WithContext(ctx)
 			next.ServeHTTP(w, r)
 		})
 	}
@@ -72,3 +95,29 @@ func RequestIDMiddleware(requestIDFn func() string) api.Middleware {
 func GetRequestMetadata(ctx context.Context) *RequestMetadata {
 	return util.GetContextValue[*RequestMetadata](ctx, dataKey, nil)
 }
+
+// GetRequestID returns the ID of the request, or an empty string if the
+// request ID middleware has not run.
+//
+//   - ctx: The context of the request.
+func GetRequestID(ctx context.Context) string {
+	requestMetadata := GetRequestMetadata(ctx)
+	if requestMetadata == nil {
+		return ""
+	}
+	return requestMetadata.RequestID
+}
+
+// isValidRequestID reports whether a request ID received from the client is
+// non-empty, of reasonable length and consists of visible ASCII characters.
+func isValidRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(requestID); i++ {
+		if requestID[i] < '!' || requestID[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
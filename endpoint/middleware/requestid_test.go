@@ -3,9 +3,12 @@ package middleware
 import (
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/pakkasys/fluidapi/core/client"
+	"github.com/pakkasys/fluidapi/core/logging"
 	"github.com/pakkasys/fluidapi/endpoint/util"
 	"github.com/stretchr/testify/assert"
 )
@@ -53,6 +56,53 @@ func TestRequestIDMiddleware_Success(t *testing.T) {
 	assert.Equal(t, http.StatusOK, w.Result().StatusCode, "Expected status 200")
 }
 
+// TestRequestIDMiddleware_Header tests using the request ID of the request
+// header and propagating it.
+func TestRequestIDMiddleware_Header(t *testing.T) {
+	middleware := RequestIDMiddleware(func() string { return "generated" })
+	req := httptest.NewRequest("GET", "/test", nil)
+	req.Header.Set(RequestIDHeader, "incoming-id")
+	req = req.WithContext(util.NewContext(req.Context()))
+	w := httptest.NewRecorder()
+
+	handler := middleware(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "incoming-id", GetRequestID(r.Context()))
+			assert.Equal(t, "incoming-id", client.RequestID(r.Context()))
+			assert.Equal(
+				t,
+				[]any{"request_id", "incoming-id"},
+				logging.Attrs(r.Context()),
+			)
+		},
+	))
+	handler.ServeHTTP(w, req)
+
+	assert.Equal(t, "incoming-id", w.Header().Get(RequestIDHeader))
+}
+
+// TestRequestIDMiddleware_InvalidHeader tests generating a request ID when
+// the request header is invalid.
+func TestRequestIDMiddleware_InvalidHeader(t *testing.T) {
+	invalidIDs := []string{"", "with space", strings.Repeat("a", 129)}
+	for _, invalidID := range invalidIDs {
+		middleware := RequestIDMiddleware(func() string { return "generated" })
+		req := httptest.NewRequest("GET", "/test", nil)
+		req.Header.Set(RequestIDHeader, invalidID)
+		req = req.WithContext(util.NewContext(req.Context()))
+		w := httptest.NewRecorder()
+
+		handler := middleware(http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "generated", GetRequestID(r.Context()))
+			},
+		))
+		handler.ServeHTTP(w, req)
+
+		assert.Equal(t, "generated", w.Header().Get(RequestIDHeader))
+	}
+}
+
 // TestRequestIDMiddleware_NilRequestIDFn tests that RequestIDMiddleware panics
 // when the requestIDFn is nil.
 func TestRequestIDMiddleware_NilRequestIDFn(t *testing.T) {
@@ -99,3 +149,13 @@ func TestGetRequestMetadata_NoMetadata(t *testing.T) {
 
 	assert.Nil(t, metadata, "Metadata should be nil")
 }
+
+// TestGetRequestID tests getting the request ID from the context.
+func TestGetRequestID(t *testing.T) {
+	ctx := util.NewContext(httptest.NewRequest("GET", "/", nil).Context())
+	assert.Empty(t, GetRequestID(ctx))
+
+	util.SetContextValue(ctx, dataKey, &RequestMetadata{RequestID: "id"})
+
+	assert.Equal(t, "id", GetRequestID(ctx))
+}
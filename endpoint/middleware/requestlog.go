@@ -6,6 +6,8 @@ import (
 	"time"
 
 	"github.com/pakkasys/fluidapi/core/api"
+	"github.com/pakkasys/fluidapi/core/buildinfo"
+	"github.com/pakkasys/fluidapi/core/logging"
 )
 
 const RequestLogMiddlewareID = "request_log"
@@ -16,44 +18,46 @@ type GetRequestMetadataFunc func(ctx context.Context) *RequestMetadata
 
 type requestLog struct {
 	StartTime     time.Time `json:"start_time"`     // Start time of the request.
+	RequestID     string    `json:"request_id"`     // Unique identifier for the request.
 	RemoteAddress string    `json:"remote_address"` // Remote IP address of the client making the request.
 	Protocol      string    `json:"protocol"`       // Protocol used in the request (e.g., HTTP/1.1).
 	HTTPMethod    string    `json:"http_method"`    // HTTP method used for the request.
 	URL           string    `json:"url"`            // Full URL of the request.
+	Build         string    `json:"build"`          // Build serving the request.
 }
 
 // RequestLogMiddlewareWrapper creates a new MiddlewareWrapper for the
 // Request Log middleware. This middleware logs request information.
 //
-//   - requestLoggerFn: A function that logs messages for the request.
+//   - logger: The logger of the request information.
 func RequestLogMiddlewareWrapper(
-	requestLoggerFn func(r *http.Request) func(messages ...any),
+	logger logging.Logger,
 ) *api.MiddlewareWrapper {
 	return &api.MiddlewareWrapper{
 		ID:         RequestLogMiddlewareID,
-		Middleware: RequestLogMiddleware(GetRequestMetadata, requestLoggerFn),
+		Middleware: RequestLogMiddleware(GetRequestMetadata, logger),
 	}
 }
 
 // RequestLogMiddleware constructs a middleware that logs information about
 // incoming requests. It uses the given request metadata retrieval function and
-// the request logger function.
+// logger.
 //
 //   - getMetadataFn: A function that gets metadata from the request context.
-//   - requestLoggerFn: A function that logs messages for the request.
+//   - logger: The logger of the request information.
 func RequestLogMiddleware(
 	getMetadataFn GetRequestMetadataFunc,
-	requestLoggerFn func(r *http.Request) func(messages ...any),
+	logger logging.Logger,
 ) api.Middleware {
-	if requestLoggerFn == nil {
-		panic("requestLoggerFn cannot be nil")
+	if logger == nil {
+		panic("logger cannot be nil")
 	}
 
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			logRequest(r, getMetadataFn, requestLoggerFn)
+			logRequest(r, getMetadataFn, logger)
 			next.ServeHTTP(w, r)
-			requestLoggerFn(r)("Request completed")
+			logger.Info(r.Context(), "Request completed")
 		})
 	}
 }
@@ -61,20 +65,28 @@ func RequestLogMiddleware(
 func logRequest(
 	r *http.Request,
 	getMetadataFn GetRequestMetadataFunc,
-	requestLoggerFn func(r *http.Request) func(messages ...any),
+	logger logging.Logger,
 ) {
 	requestMetadata := getMetadataFn(r.Context())
 	if requestMetadata == nil {
-		requestLoggerFn(r)("Request started", "Request metadata not found")
+		logger.Info(
+			r.Context(),
+			"Request started",
+			"error", "Request metadata not found",
+		)
 	} else {
-		requestLoggerFn(r)(
+		logger.Info(
+			r.Context(),
 			"Request started",
+			"request",
 			requestLog{
 				StartTime:     time.Now().UTC(),
+				RequestID:     requestMetadata.RequestID,
 				RemoteAddress: requestMetadata.RemoteAddress,
 				Protocol:      requestMetadata.Protocol,
 				HTTPMethod:    requestMetadata.HTTPMethod,
 				URL:           requestMetadata.URL,
+				Build:         buildinfo.Get().Summary(),
 			},
 		)
 	}
@@ -7,29 +7,14 @@ import (
 	"testing"
 	"time"
 
+	"github.com/pakkasys/fluidapi/core/logging/mock"
 	"github.com/stretchr/testify/assert"
-	"github.com/stretchr/testify/mock"
 )
 
-// MockLogger is a mock implementation of the logger function.
-type MockLogger struct {
-	mock.Mock
-}
-
-func (m *MockLogger) Log(messages ...any) {
-	m.Called(messages)
-}
-
 // TestRequestLogMiddlewareWrapper tests the RequestLogMiddlewareWrapper
 // function
 func TestRequestLogMiddlewareWrapper(t *testing.T) {
-	mockLogger := new(MockLogger)
-
-	requestLoggerFn := func(r *http.Request) func(messages ...any) {
-		return mockLogger.Log
-	}
-
-	middlewareWrapper := RequestLogMiddlewareWrapper(requestLoggerFn)
+	middlewareWrapper := RequestLogMiddlewareWrapper(&mock.Recorder{})
 
 	assert.NotNil(t, middlewareWrapper, "Wrapper should not be nil")
 	assert.Equal(
@@ -47,13 +32,9 @@ func TestRequestLogMiddlewareWrapper(t *testing.T) {
 
 // TestRequestLogMiddleware tests the RequestLogMiddleware function.
 func TestRequestLogMiddleware(t *testing.T) {
-	mockLogger := new(MockLogger)
+	logger := &mock.Recorder{}
 
-	requestLoggerFn := func(r *http.Request) func(messages ...any) {
-		return mockLogger.Log
-	}
-
-	middleware := RequestLogMiddleware(GetRequestMetadata, requestLoggerFn)
+	middleware := RequestLogMiddleware(GetRequestMetadata, logger)
 
 	req := httptest.NewRequest("GET", "/test", nil)
 	w := httptest.NewRecorder()
@@ -66,8 +47,6 @@ func TestRequestLogMiddleware(t *testing.T) {
 		},
 	)
 
-	mockLogger.On("Log", mock.AnythingOfType("[]interface {}")).Return()
-
 	handler := middleware(nextHandler)
 	handler.ServeHTTP(w, req)
 
@@ -79,24 +58,25 @@ func TestRequestLogMiddleware(t *testing.T) {
 		"Expected 'Request passed through' body",
 	)
 
-	mockLogger.AssertNumberOfCalls(t, "Log", 2)
-	mockLogger.AssertExpectations(t)
+	assert.Equal(
+		t,
+		[]string{"Request started", "Request completed"},
+		logger.Messages(),
+	)
 }
 
 // TestRequestLogMiddleware_NilLogger tests the scenario where the
-// requestLoggerFn is nil.
+// logger is nil.
 func TestRequestLogMiddleware_NilLogger(t *testing.T) {
 	assert.Panics(t, func() {
 		_ = RequestLogMiddleware(GetRequestMetadata, nil)
-	}, "Expected panic with nil requestLoggerFn")
+	}, "Expected panic with nil logger")
 }
 
 // TestLogRequest_MetadataNotFound tests the scenario where the request metadata
 // is not found in the context.
 func TestLogRequest_MetadataNotFound(t *testing.T) {
-	mockLogger := new(MockLogger)
-
-	mockLogger.On("Log", mock.Anything, mock.Anything).Return()
+	logger := &mock.Recorder{}
 
 	req := httptest.NewRequest("GET", "/test", nil)
 	req = req.WithContext(context.Background())
@@ -105,56 +85,40 @@ func TestLogRequest_MetadataNotFound(t *testing.T) {
 		return nil
 	}
 
-	logRequest(
-		req,
-		getMetadataFunc,
-		func(r *http.Request) func(messages ...any) {
-			return mockLogger.Log
-		},
-	)
+	logRequest(req, getMetadataFunc, logger)
 
-	mockLogger.AssertExpectations(t)
+	entries := logger.Entries()
+	assert.Len(t, entries, 1)
+	assert.Equal(t, "Request started", entries[0].Message)
+	assert.Equal(t, "Request metadata not found", entries[0].Arg("error"))
 }
 
 // TestLogRequest_MetadataFound tests the scenario where the request metadata
 // is found in the context.
 func TestLogRequest_MetadataFound(t *testing.T) {
-	mockLogger := new(MockLogger)
+	logger := &mock.Recorder{}
 
 	expectedMetadata := &RequestMetadata{
 		TimeStart:     time.Now().UTC(),
+		RequestID:     "test-request-id",
 		RemoteAddress: "127.0.0.1",
 		Protocol:      "HTTP/1.1",
 		HTTPMethod:    "GET",
 		URL:           "/test",
 	}
 
-	mockLogger.On(
-		"Log",
-		mock.MatchedBy(func(args []any) bool {
-			if len(args) != 2 {
-				return false
-			}
-			if args[0] != "Request started" {
-				return false
-			}
-			_, ok := args[1].(requestLog)
-			return ok
-		}),
-	).Return()
-
 	getMetadataFunc := func(ctx context.Context) *RequestMetadata {
 		return expectedMetadata
 	}
 
 	req := httptest.NewRequest("GET", "/test", nil)
 
-	logRequest(
-		req,
-		getMetadataFunc, func(r *http.Request) func(messages ...any) {
-			return mockLogger.Log
-		},
-	)
+	logRequest(req, getMetadataFunc, logger)
 
-	mockLogger.AssertExpectations(t)
+	entries := logger.Entries()
+	assert.Len(t, entries, 1)
+	assert.Equal(t, "Request started", entries[0].Message)
+	log, ok := entries[0].Arg("request").(requestLog)
+	assert.True(t, ok, "Expected requestLog")
+	assert.Equal(t, "test-request-id", log.RequestID)
 }
@@ -0,0 +1,95 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/pakkasys/fluidapi/core/api"
+	"github.com/pakkasys/fluidapi/core/client"
+	"github.com/pakkasys/fluidapi/endpoint/middleware/inputlogic"
+)
+
+const RequestSignatureMiddlewareID = "request_signature"
+
+// RequestSignatureErrors are the expected errors of the signature
+// verification of client.RequestSigner.
+var RequestSignatureErrors = []inputlogic.ExpectedError{
+	{
+		ID:         client.InvalidRequestSignatureError.ID,
+		Status:     http.StatusUnauthorized,
+		PublicData: true,
+	},
+	{
+		ID:         client.RequestSignatureExpiredError.ID,
+		Status:     http.StatusUnauthorized,
+		PublicData: true,
+	},
+	{
+		ID:         client.SignedBodyTooLargeError.ID,
+		Status:     http.StatusRequestEntityTooLarge,
+		PublicData: true,
+	},
+}
+
+// RequestVerifier verifies signed requests, e.g. client.RequestSigner.
+type RequestVerifier interface {
+	VerifyRequest(r *http.Request) error
+}
+
+// RequestSignatureMiddlewareWrapper creates a new MiddlewareWrapper for the
+// Request Signature middleware. This middleware rejects requests without a
+// valid HMAC signature.
+//
+//   - verifier: The verifier of the requests.
+//   - outputHandler: The handler sending the errors to the client.
+func RequestSignatureMiddlewareWrapper(
+	verifier RequestVerifier,
+	outputHandler inputlogic.IOutputHandler,
+) *api.MiddlewareWrapper {
+	return &api.MiddlewareWrapper{
+		ID:         RequestSignatureMiddlewareID,
+		Middleware: RequestSignatureMiddleware(verifier, outputHandler),
+	}
+}
+
+// RequestSignatureMiddleware constructs a middleware that verifies the
+// signature of the requests signed by the clients with the Signer option, see
+// client.RequestSigner. The errors of the verification are mapped with
+// RequestSignatureErrors and the error catalog, so requests with a missing,
+// invalid or expired signature are rejected with a 401 Unauthorized response
+// and requests with too large bodies with a 413 Request Entity Too Large
+// response. The body is restored after the verification, so it can be read
+// by the next handlers.
+//
+//   - verifier: The verifier of the requests.
+//   - outputHandler: The handler sending the errors to the client.
+func RequestSignatureMiddleware(
+	verifier RequestVerifier,
+	outputHandler inputlogic.IOutputHandler,
+) api.Middleware {
+	if verifier == nil {
+		panic("verifier cannot be nil")
+	}
+	if outputHandler == nil {
+		panic("output handler cannot be nil")
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if err := verifier.VerifyRequest(r); err != nil {
+				statusCode, outError := inputlogic.ErrorHandler{}.Handle(
+					err,
+					RequestSignatureErrors,
+				)
+				_ = outputHandler.ProcessOutput(
+					w,
+					r,
+					nil,
+					outError,
+					statusCode,
+				)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pakkasys/fluidapi/core/client"
+	"github.com/stretchr/testify/assert"
+)
+
+var _ RequestVerifier = &client.RequestSigner{}
+
+func requestSignatureTestHandler(body *string) http.Handler {
+	signer := client.NewRequestSigner([]byte("secret"), time.Minute)
+	signer.MaxBodySize = 32
+	middleware := RequestSignatureMiddleware(signer, timeoutOutputHandler{})
+	return middleware(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			data, _ := io.ReadAll(r.Body)
+			*body = string(data)
+			w.WriteHeader(http.StatusOK)
+		},
+	))
+}
+
+// TestRequestSignatureMiddlewareWrapper tests the ID of the middleware
+// wrapper.
+func TestRequestSignatureMiddlewareWrapper(t *testing.T) {
+	wrapper := RequestSignatureMiddlewareWrapper(
+		&client.RequestSigner{},
+		timeoutOutputHandler{},
+	)
+
+	assert.Equal(t, RequestSignatureMiddlewareID, wrapper.ID)
+	assert.NotNil(t, wrapper.Middleware)
+}
+
+// TestRequestSignatureMiddleware_Valid tests that a signed request is passed
+// to the next handler with its body.
+func TestRequestSignatureMiddleware_Valid(t *testing.T) {
+	body := ""
+	r := httptest.NewRequest(
+		http.MethodPost,
+		"/users?notify=true",
+		strings.NewReader(`{"name":"test"}`),
+	)
+	signer := client.NewRequestSigner([]byte("secret"), time.Minute)
+	assert.NoError(t, signer.Sign(r))
+
+	w := httptest.NewRecorder()
+	requestSignatureTestHandler(&body).ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `{"name":"test"}`, body)
+}
+
+// TestRequestSignatureMiddleware_Invalid tests that unsigned and tampered
+// requests are rejected.
+func TestRequestSignatureMiddleware_Invalid(t *testing.T) {
+	signed := httptest.NewRequest(
+		http.MethodPost,
+		"/users",
+		strings.NewReader(`{"name":"test"}`),
+	)
+	signer := client.NewRequestSigner([]byte("secret"), time.Minute)
+	assert.NoError(t, signer.Sign(signed))
+	tampered := httptest.NewRequest(
+		http.MethodPost,
+		"/users",
+		strings.NewReader(`{"name":"admin"}`),
+	)
+	tampered.Header = signed.Header
+
+	for _, r := range []*http.Request{
+		httptest.NewRequest(http.MethodPost, "/users", nil),
+		tampered,
+	} {
+		body := ""
+		w := httptest.NewRecorder()
+		requestSignatureTestHandler(&body).ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Equal(
+			t,
+			client.InvalidRequestSignatureError.ID,
+			w.Body.String(),
+		)
+		assert.Empty(t, body)
+	}
+}
+
+// TestRequestSignatureMiddleware_BodyTooLarge tests that requests with too
+// large bodies are rejected before they are passed to the next handler.
+func TestRequestSignatureMiddleware_BodyTooLarge(t *testing.T) {
+	r := httptest.NewRequest(
+		http.MethodPost,
+		"/users",
+		strings.NewReader(strings.Repeat("a", 64)),
+	)
+	signer := client.NewRequestSigner([]byte("secret"), time.Minute)
+	assert.NoError(t, signer.Sign(r))
+
+	body := ""
+	w := httptest.NewRecorder()
+	requestSignatureTestHandler(&body).ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	assert.Equal(t, client.SignedBodyTooLargeError.ID, w.Body.String())
+	assert.Empty(t, body)
+}
+
+// TestRequestSignatureMiddleware_NilVerifier tests that a nil verifier or
+// output handler panics.
+func TestRequestSignatureMiddleware_NilVerifier(t *testing.T) {
+	assert.Panics(t, func() {
+		RequestSignatureMiddleware(nil, timeoutOutputHandler{})
+	})
+	assert.Panics(t, func() {
+		RequestSignatureMiddleware(&client.RequestSigner{}, nil)
+	})
+}
@@ -0,0 +1,104 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/pakkasys/fluidapi/core/api"
+	"github.com/pakkasys/fluidapi/core/logging"
+	"github.com/pakkasys/fluidapi/endpoint/session"
+)
+
+const SessionMiddlewareID = "session"
+
+// SessionMiddlewareWrapper creates a new MiddlewareWrapper for the Session
+// middleware. This middleware loads and saves the session of the client.
+//
+//   - manager: The manager of the sessions.
+//   - logger: The logger of the session errors. Optional.
+func SessionMiddlewareWrapper(
+	manager *session.Manager,
+	logger logging.Logger,
+) *api.MiddlewareWrapper {
+	return &api.MiddlewareWrapper{
+		ID:         SessionMiddlewareID,
+		Middleware: SessionMiddleware(manager, logger),
+	}
+}
+
+// SessionMiddleware constructs a middleware that loads the session of the
+// request and stores it in the custom context, from where it can be read with
+// session.GetSession. The modified session is saved before the response
+// header is written. The custom context must be set before this middleware.
+//
+//   - manager: The manager of the sessions.
+//   - logger: The logger of the session errors. Optional.
+func SessionMiddleware(
+	manager *session.Manager,
+	logger logging.Logger,
+) api.Middleware {
+	if manager == nil {
+		panic("manager cannot be nil")
+	}
+	if logger == nil {
+		logger = logging.NopLogger{}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			s, err := manager.Load(r)
+			if err != nil {
+				logger.Error(r.Context(), "Session error", "error", err)
+				http.Error(
+					w,
+					http.StatusText(http.StatusInternalServerError),
+					http.StatusInternalServerError,
+				)
+				return
+			}
+			session.SetSession(r.Context(), s)
+
+			sw := &sessionWriter{
+				ResponseWriter: w,
+				save: func() {
+					if err := manager.Save(w, r, s); err != nil {
+						logger.Error(
+							r.Context(),
+							"Session error",
+							"error", err,
+						)
+					}
+				},
+			}
+			next.ServeHTTP(sw, r)
+			sw.saveOnce()
+		})
+	}
+}
+
+// sessionWriter saves the session before the response header is written.
+type sessionWriter struct {
+	http.ResponseWriter
+	save  func()
+	saved bool
+}
+
+func (w *sessionWriter) WriteHeader(statusCode int) {
+	w.saveOnce()
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *sessionWriter) Write(b []byte) (int, error) {
+	w.saveOnce()
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *sessionWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *sessionWriter) saveOnce() {
+	if !w.saved {
+		w.saved = true
+		w.save()
+	}
+}
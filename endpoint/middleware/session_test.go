@@ -0,0 +1,100 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pakkasys/fluidapi/core/logging/mock"
+	"github.com/pakkasys/fluidapi/endpoint/session"
+	"github.com/pakkasys/fluidapi/endpoint/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestSessionManager(t *testing.T, store session.Store) *session.Manager {
+	codec, err := session.NewCookieCodec(
+		[]byte("0123456789abcdef0123456789abcdef"),
+		nil,
+	)
+	assert.NoError(t, err)
+	return session.NewManager(session.Config{Codec: codec, Store: store})
+}
+
+// TestSessionMiddlewareWrapper tests the ID of the wrapper.
+func TestSessionMiddlewareWrapper(t *testing.T) {
+	wrapper := SessionMiddlewareWrapper(newTestSessionManager(t, nil), nil)
+
+	assert.Equal(t, SessionMiddlewareID, wrapper.ID)
+	assert.NotNil(t, wrapper.Middleware)
+}
+
+// TestSessionMiddleware tests saving the session before the header is
+// written.
+func TestSessionMiddleware(t *testing.T) {
+	handler := SessionMiddleware(newTestSessionManager(t, nil), nil)(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			s := session.GetSession(r.Context())
+			assert.NotNil(t, s)
+			s.Set("user", "alice")
+			w.WriteHeader(http.StatusCreated)
+		}),
+	)
+	r := httptest.NewRequest("GET", "/", nil)
+	r = r.WithContext(util.NewContext(r.Context()))
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	cookies := w.Result().Cookies()
+	assert.Len(t, cookies, 1)
+	assert.Equal(t, "session", cookies[0].Name)
+}
+
+// TestSessionMiddleware_NilManager tests that a nil manager panics.
+func TestSessionMiddleware_NilManager(t *testing.T) {
+	assert.Panics(t, func() { SessionMiddleware(nil, nil) })
+}
+
+type errorSessionStore struct {
+	session.MemoryStore
+}
+
+func (s *errorSessionStore) Get(
+	ctx context.Context,
+	id string,
+) (map[string]any, error) {
+	return nil, errors.New("store error")
+}
+
+// TestSessionMiddleware_LoadError tests the response to a store error.
+func TestSessionMiddleware_LoadError(t *testing.T) {
+	manager := newTestSessionManager(t, &errorSessionStore{})
+	codec, err := session.NewCookieCodec(
+		[]byte("0123456789abcdef0123456789abcdef"),
+		nil,
+	)
+	assert.NoError(t, err)
+	value, err := codec.Encode("session", []byte("id"))
+	assert.NoError(t, err)
+	logger := &mock.Recorder{}
+	handler := SessionMiddleware(manager, logger)(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			t.Fatal("handler should not be called")
+		},
+	))
+	r := httptest.NewRequest("GET", "/", nil)
+	r.AddCookie(&http.Cookie{Name: "session", Value: value})
+	r = r.WithContext(util.NewContext(r.Context()))
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	entries := logger.Entries()
+	assert.Len(t, entries, 1)
+	assert.Equal(t, "Session error", entries[0].Message)
+	assert.EqualError(t, entries[0].Arg("error").(error), "store error")
+}
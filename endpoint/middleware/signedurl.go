@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/pakkasys/fluidapi/core/api"
+)
+
+const SignedURLMiddlewareID = "signed_url"
+
+// URLVerifier verifies signed URLs.
+type URLVerifier interface {
+	Verify(u *url.URL) error
+}
+
+// SignedURLMiddlewareWrapper creates a new MiddlewareWrapper for the Signed
+// URL middleware. This middleware rejects requests without a valid URL
+// signature.
+//
+//   - verifier: The verifier of the request URLs.
+func SignedURLMiddlewareWrapper(verifier URLVerifier) *api.MiddlewareWrapper {
+	return &api.MiddlewareWrapper{
+		ID:         SignedURLMiddlewareID,
+		Middleware: SignedURLMiddleware(verifier),
+	}
+}
+
+// SignedURLMiddleware constructs a middleware that verifies the signature of
+// the request URL. Requests with an invalid or expired signature are rejected
+// with a 403 Forbidden response.
+//
+//   - verifier: The verifier of the request URLs.
+func SignedURLMiddleware(verifier URLVerifier) api.Middleware {
+	if verifier == nil {
+		panic("verifier cannot be nil")
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if err := verifier.Verify(r.URL); err != nil {
+				http.Error(
+					w,
+					http.StatusText(http.StatusForbidden),
+					http.StatusForbidden,
+				)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/pakkasys/fluidapi/endpoint/signedurl"
+	"github.com/stretchr/testify/assert"
+)
+
+func signedURLTestHandler(called *bool) http.Handler {
+	return SignedURLMiddleware(
+		signedurl.NewSigner([]byte("secret"), 0),
+	)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*called = true
+		w.WriteHeader(http.StatusOK)
+	}))
+}
+
+// TestSignedURLMiddlewareWrapper tests the ID of the middleware wrapper.
+func TestSignedURLMiddlewareWrapper(t *testing.T) {
+	wrapper := SignedURLMiddlewareWrapper(signedurl.NewSigner(nil, 0))
+
+	assert.Equal(t, SignedURLMiddlewareID, wrapper.ID)
+	assert.NotNil(t, wrapper.Middleware)
+}
+
+// TestSignedURLMiddleware_Valid tests that a request with a valid signature
+// is passed to the next handler.
+func TestSignedURLMiddleware_Valid(t *testing.T) {
+	called := false
+	u, _ := url.Parse("/downloads?file=report.csv")
+	signed := signedurl.NewSigner([]byte("secret"), 0).Sign(u, time.Hour)
+
+	w := httptest.NewRecorder()
+	signedURLTestHandler(&called).ServeHTTP(
+		w,
+		httptest.NewRequest(http.MethodGet, signed.String(), nil),
+	)
+
+	assert.True(t, called)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+// TestSignedURLMiddleware_Invalid tests that a request without a valid
+// signature is rejected.
+func TestSignedURLMiddleware_Invalid(t *testing.T) {
+	called := false
+
+	w := httptest.NewRecorder()
+	signedURLTestHandler(&called).ServeHTTP(
+		w,
+		httptest.NewRequest(http.MethodGet, "/downloads?file=report.csv", nil),
+	)
+
+	assert.False(t, called)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+// TestSignedURLMiddleware_NilVerifier tests that a nil verifier panics.
+func TestSignedURLMiddleware_NilVerifier(t *testing.T) {
+	assert.Panics(t, func() {
+		SignedURLMiddleware(nil)
+	})
+}
@@ -0,0 +1,141 @@
+package middleware
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/pakkasys/fluidapi/core/api"
+)
+
+// RequestPredicate reports whether a request matches a condition.
+type RequestPredicate func(r *http.Request) bool
+
+// SkipMiddleware constructs a middleware that runs a middleware unless the
+// request matches the predicate, e.g. to skip authentication for health
+// checks. The skipped requests go straight to the next handler.
+//
+//   - mw: The middleware to run conditionally.
+//   - skip: The predicate of the skipped requests.
+func SkipMiddleware(mw api.Middleware, skip RequestPredicate) api.Middleware {
+	if mw == nil {
+		panic("middleware cannot be nil")
+	}
+	if skip == nil {
+		panic("skip predicate cannot be nil")
+	}
+
+	return func(next http.Handler) http.Handler {
+		wrapped := mw(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if skip(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			wrapped.ServeHTTP(w, r)
+		})
+	}
+}
+
+// SkipWrapper returns a copy of a middleware wrapper whose middleware is
+// skipped for the requests matching the predicate, see SkipMiddleware. The
+// ID and the other metadata of the wrapper are kept, so the wrapper can
+// replace the original one in a stack.
+//
+//   - wrapper: The middleware wrapper.
+//   - skip: The predicate of the skipped requests.
+func SkipWrapper(
+	wrapper api.MiddlewareWrapper,
+	skip RequestPredicate,
+) api.MiddlewareWrapper {
+	wrapper.Middleware = SkipMiddleware(wrapper.Middleware, skip)
+	return wrapper
+}
+
+// MatchPathPrefix returns a predicate matching the requests whose paths are
+// below one of the prefixes. A prefix matches whole path segments, e.g.
+// "/healthz" matches "/healthz" and "/healthz/ready" but not "/healthzz".
+//
+//   - prefixes: The path prefixes.
+func MatchPathPrefix(prefixes ...string) RequestPredicate {
+	return func(r *http.Request) bool {
+		for _, prefix := range prefixes {
+			rest, ok := strings.CutPrefix(r.URL.Path, prefix)
+			if ok && (rest == "" || strings.HasSuffix(prefix, "/") ||
+				strings.HasPrefix(rest, "/")) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// MatchMethod returns a predicate matching the requests with one of the
+// methods.
+//
+//   - methods: The HTTP methods, e.g. http.MethodOptions.
+func MatchMethod(methods ...string) RequestPredicate {
+	return func(r *http.Request) bool {
+		return slices.Contains(methods, r.Method)
+	}
+}
+
+// MatchHeader returns a predicate matching the requests with a header. If
+// values are given, the header must have one of them.
+//
+//   - name: The name of the header.
+//   - values: The accepted values of the header. Optional.
+func MatchHeader(name string, values ...string) RequestPredicate {
+	return func(r *http.Request) bool {
+		headerValues := r.Header.Values(name)
+		if len(values) == 0 {
+			return len(headerValues) > 0
+		}
+		for _, value := range headerValues {
+			if slices.Contains(values, value) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// MatchAny returns a predicate matching the requests matching any of the
+// predicates.
+//
+//   - predicates: The predicates.
+func MatchAny(predicates ...RequestPredicate) RequestPredicate {
+	return func(r *http.Request) bool {
+		for _, predicate := range predicates {
+			if predicate(r) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// MatchAll returns a predicate matching the requests matching all of the
+// predicates.
+//
+//   - predicates: The predicates.
+func MatchAll(predicates ...RequestPredicate) RequestPredicate {
+	return func(r *http.Request) bool {
+		for _, predicate := range predicates {
+			if !predicate(r) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// MatchNot returns a predicate matching the requests not matching the
+// predicate.
+//
+//   - predicate: The predicate.
+func MatchNot(predicate RequestPredicate) RequestPredicate {
+	return func(r *http.Request) bool {
+		return !predicate(r)
+	}
+}
@@ -0,0 +1,107 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pakkasys/fluidapi/core/api"
+	"github.com/stretchr/testify/assert"
+)
+
+// rejectMiddleware responds with 401 without calling the next handler.
+func rejectMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	})
+}
+
+// TestSkipMiddleware tests skipping the middleware for matching requests.
+func TestSkipMiddleware(t *testing.T) {
+	handler := SkipMiddleware(rejectMiddleware, MatchPathPrefix("/healthz"))(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNoContent)
+		}),
+	)
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users", nil))
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+// TestSkipMiddleware_Nil tests that the middleware and the predicate cannot
+// be nil.
+func TestSkipMiddleware_Nil(t *testing.T) {
+	assert.PanicsWithValue(t, "middleware cannot be nil", func() {
+		SkipMiddleware(nil, MatchMethod(http.MethodGet))
+	})
+	assert.PanicsWithValue(t, "skip predicate cannot be nil", func() {
+		SkipMiddleware(rejectMiddleware, nil)
+	})
+}
+
+// TestSkipWrapper tests that the metadata of the wrapper is kept.
+func TestSkipWrapper(t *testing.T) {
+	wrapper := SkipWrapper(api.MiddlewareWrapper{
+		ID:         "auth",
+		Middleware: rejectMiddleware,
+		Priority:   10,
+	}, MatchMethod(http.MethodOptions))
+
+	assert.Equal(t, "auth", wrapper.ID)
+	assert.Equal(t, 10, wrapper.Priority)
+
+	w := httptest.NewRecorder()
+	wrapper.Middleware(http.NotFoundHandler()).ServeHTTP(
+		w,
+		httptest.NewRequest(http.MethodOptions, "/users", nil),
+	)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+// TestRequestPredicates tests matching requests with the predicates.
+func TestRequestPredicates(t *testing.T) {
+	request := httptest.NewRequest(http.MethodPost, "/healthz/ready", nil)
+	request.Header.Set("X-Internal", "true")
+
+	tests := []struct {
+		name      string
+		predicate RequestPredicate
+		expected  bool
+	}{
+		{"path prefix", MatchPathPrefix("/healthz"), true},
+		{"path prefix slash", MatchPathPrefix("/healthz/"), true},
+		{"partial segment", MatchPathPrefix("/health"), false},
+		{"other path", MatchPathPrefix("/users", "/metrics"), false},
+		{"method", MatchMethod(http.MethodGet, http.MethodPost), true},
+		{"other method", MatchMethod(http.MethodGet), false},
+		{"header", MatchHeader("X-Internal"), true},
+		{"header value", MatchHeader("X-Internal", "false", "true"), true},
+		{"other header value", MatchHeader("X-Internal", "false"), false},
+		{"missing header", MatchHeader("X-Debug"), false},
+		{
+			"any",
+			MatchAny(MatchMethod(http.MethodGet), MatchHeader("X-Internal")),
+			true,
+		},
+		{
+			"all",
+			MatchAll(MatchMethod(http.MethodGet), MatchHeader("X-Internal")),
+			false,
+		},
+		{"not", MatchNot(MatchMethod(http.MethodGet)), true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, test.predicate(request))
+		})
+	}
+}
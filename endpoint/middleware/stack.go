@@ -1,6 +1,12 @@
 package middleware
 
-import "github.com/pakkasys/fluidapi/core/api"
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/pakkasys/fluidapi/core/api"
+)
 
 type Stack []api.MiddlewareWrapper
 
@@ -46,3 +52,134 @@ func (s *Stack) InsertAfterID(id string, wrapper api.MiddlewareWrapper) bool {
 	}
 	return false
 }
+
+// InsertBeforeID inserts a middleware wrapper before the given ID.
+//
+// Parameters:
+//   - id: The ID of the middleware to insert before.
+//   - wrapper: The middleware wrapper to insert.
+//
+// Returns:
+//   - True if the middleware was inserted, false otherwise.
+func (s *Stack) InsertBeforeID(id string, wrapper api.MiddlewareWrapper) bool {
+	for i, mw := range *s {
+		if mw.ID == id {
+			*s = slices.Insert(*s, i, wrapper)
+			return true
+		}
+	}
+	return false
+}
+
+// ReplaceID replaces the middleware wrapper with the given ID.
+//
+// Parameters:
+//   - id: The ID of the middleware to replace.
+//   - wrapper: The middleware wrapper replacing it.
+//
+// Returns:
+//   - True if the middleware was replaced, false otherwise.
+func (s Stack) ReplaceID(id string, wrapper api.MiddlewareWrapper) bool {
+	for i, mw := range s {
+		if mw.ID == id {
+			s[i] = wrapper
+			return true
+		}
+	}
+	return false
+}
+
+// RemoveID removes the middleware wrapper with the given ID.
+//
+// Parameters:
+//   - id: The ID of the middleware to remove.
+//
+// Returns:
+//   - True if the middleware was removed, false otherwise.
+func (s *Stack) RemoveID(id string) bool {
+	for i, mw := range *s {
+		if mw.ID == id {
+			*s = slices.Delete(*s, i, i+1)
+			return true
+		}
+	}
+	return false
+}
+
+// Ordered returns the stack ordered by the dependencies and the priorities of
+// the middlewares. A middleware runs after the middlewares of its After IDs.
+// Of the middlewares whose dependencies have run, the one with the lowest
+// priority runs first, and the one earlier in the stack if the priorities
+// are equal, so the order is deterministic.
+//
+// Returns:
+//   - The ordered stack.
+//   - An error if a dependency is not in the stack or the dependencies form
+//     a cycle.
+func (s Stack) Ordered() (Stack, error) {
+	indices := map[string][]int{}
+	for i, mw := range s {
+		if mw.ID != "" {
+			indices[mw.ID] = append(indices[mw.ID], i)
+		}
+	}
+
+	// dependents are the indices of the middlewares running after a
+	// middleware and pending the counts of their dependencies left to run.
+	dependents := make([][]int, len(s))
+	pending := make([]int, len(s))
+	for i, mw := range s {
+		for _, id := range mw.After {
+			dependencies, ok := indices[id]
+			if !ok {
+				return nil, fmt.Errorf(
+					"middleware %q must run after missing middleware %q",
+					mw.ID,
+					id,
+				)
+			}
+			for _, dependency := range dependencies {
+				dependents[dependency] = append(dependents[dependency], i)
+				pending[i]++
+			}
+		}
+	}
+
+	ordered := make(Stack, 0, len(s))
+	done := make([]bool, len(s))
+	for len(ordered) < len(s) {
+		next := -1
+		for i, mw := range s {
+			if done[i] || pending[i] > 0 {
+				continue
+			}
+			if next == -1 || mw.Priority < s[next].Priority {
+				next = i
+			}
+		}
+		if next == -1 {
+			return nil, fmt.Errorf(
+				"middleware dependency cycle: %s",
+				strings.Join(s.pendingIDs(done), ", "),
+			)
+		}
+
+		done[next] = true
+		ordered = append(ordered, s[next])
+		for _, dependent := range dependents[next] {
+			pending[dependent]--
+		}
+	}
+	return ordered, nil
+}
+
+// pendingIDs returns the IDs of the middlewares not yet ordered.
+func (s Stack) pendingIDs(done []bool) []string {
+	var ids []string
+	for i, mw := range s {
+		if !done[i] {
+			ids = append(ids, mw.ID)
+		}
+	}
+	return ids
+}
@@ -154,3 +154,109 @@ func TestInsertAfterID_IDNotFound(t *testing.T) {
 	assert.Equal(t, "auth", mwStack[0].ID)
 	assert.Equal(t, "logging", mwStack[1].ID)
 }
+
+// TestInsertBeforeID tests the InsertBeforeID function.
+func TestInsertBeforeID(t *testing.T) {
+	mwStack := Stack{{ID: "auth"}, {ID: "logging"}}
+
+	inserted := mwStack.InsertBeforeID("auth", api.MiddlewareWrapper{
+		ID: "metrics",
+	})
+
+	assert.True(t, inserted, "Middleware should be inserted")
+	assert.Equal(t, []string{"metrics", "auth", "logging"}, stackIDs(mwStack))
+
+	inserted = mwStack.InsertBeforeID("non-existent-id", api.MiddlewareWrapper{
+		ID: "cors",
+	})
+
+	assert.False(t, inserted, "Middleware should not be inserted")
+	assert.Equal(t, []string{"metrics", "auth", "logging"}, stackIDs(mwStack))
+}
+
+// TestReplaceID tests the ReplaceID function.
+func TestReplaceID(t *testing.T) {
+	mwStack := Stack{{ID: "auth"}, {ID: "logging"}}
+
+	replaced := mwStack.ReplaceID("auth", api.MiddlewareWrapper{
+		ID:       "jwt",
+		Priority: 1,
+	})
+
+	assert.True(t, replaced, "Middleware should be replaced")
+	assert.Equal(t, []string{"jwt", "logging"}, stackIDs(mwStack))
+	assert.Equal(t, 1, mwStack[0].Priority)
+
+	replaced = mwStack.ReplaceID("auth", api.MiddlewareWrapper{ID: "jwt"})
+
+	assert.False(t, replaced, "Middleware should not be replaced")
+}
+
+// TestRemoveID tests the RemoveID function.
+func TestRemoveID(t *testing.T) {
+	mwStack := Stack{{ID: "auth"}, {ID: "logging"}, {ID: "metrics"}}
+
+	removed := mwStack.RemoveID("logging")
+
+	assert.True(t, removed, "Middleware should be removed")
+	assert.Equal(t, []string{"auth", "metrics"}, stackIDs(mwStack))
+
+	removed = mwStack.RemoveID("logging")
+
+	assert.False(t, removed, "Middleware should not be removed")
+	assert.Equal(t, []string{"auth", "metrics"}, stackIDs(mwStack))
+}
+
+func stackIDs(stack Stack) []string {
+	ids := []string{}
+	for _, mw := range stack {
+		ids = append(ids, mw.ID)
+	}
+	return ids
+}
+
+// TestOrdered tests ordering the stack by the dependencies and priorities.
+func TestOrdered(t *testing.T) {
+	stack := Stack{
+		{ID: "access_log", After: []string{"request_id"}},
+		{ID: "auth", Priority: 10},
+		{ID: "request_id", Priority: 5},
+		{ID: "panic_handler", Priority: -10},
+		{ID: "cors"},
+	}
+
+	ordered, err := stack.Ordered()
+
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		[]string{"panic_handler", "cors", "request_id", "access_log", "auth"},
+		stackIDs(ordered),
+	)
+	assert.Equal(t, "access_log", stack[0].ID, "stack should not change")
+}
+
+// TestOrdered_MissingDependency tests that the dependencies must be in the
+// stack.
+func TestOrdered_MissingDependency(t *testing.T) {
+	_, err := Stack{{ID: "access_log", After: []string{"request_id"}}}.
+		Ordered()
+
+	assert.EqualError(
+		t,
+		err,
+		`middleware "access_log" must run after missing middleware `+
+			`"request_id"`,
+	)
+}
+
+// TestOrdered_Cycle tests that the dependencies cannot form a cycle.
+func TestOrdered_Cycle(t *testing.T) {
+	_, err := Stack{
+		{ID: "cors"},
+		{ID: "a", After: []string{"b"}},
+		{ID: "b", After: []string{"a", "cors"}},
+	}.Ordered()
+
+	assert.EqualError(t, err, "middleware dependency cycle: a, b")
+}
@@ -0,0 +1,144 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/pakkasys/fluidapi/core/api"
+	"github.com/pakkasys/fluidapi/endpoint/middleware/inputlogic"
+)
+
+const TimeoutMiddlewareID = "timeout"
+
+// TimeoutMiddlewareWrapper creates a new MiddlewareWrapper for the Timeout
+// middleware. This middleware limits the time spent handling a request.
+//
+//   - timeout: The maximum duration of the request.
+//   - outputHandler: The handler sending the timeout error to the client.
+func TimeoutMiddlewareWrapper(
+	timeout time.Duration,
+	outputHandler inputlogic.IOutputHandler,
+) *api.MiddlewareWrapper {
+	return &api.MiddlewareWrapper{
+		ID:         TimeoutMiddlewareID,
+		Middleware: TimeoutMiddleware(timeout, outputHandler),
+	}
+}
+
+// TimeoutMiddleware constructs a middleware that sets a deadline to the
+// request context. The entity helpers prepare and run their queries with the
+// context of the operation, so queries run with the request context and still
+// running at the deadline are cancelled. If the request has not been
+// handled by the deadline, inputlogic.RequestTimeoutError is sent with a 504
+// Gateway Timeout status and later writes of the handler are discarded.
+//
+// The response is buffered until the request has been handled, so the
+// middleware is not suited for streaming endpoints.
+//
+//   - timeout: The maximum duration of the request.
+//   - outputHandler: The handler sending the timeout error to the client.
+func TimeoutMiddleware(
+	timeout time.Duration,
+	outputHandler inputlogic.IOutputHandler,
+) api.Middleware {
+	if outputHandler == nil {
+		panic("output handler cannot be nil")
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, cancel := context.WithTimeout(r.Context(), timeout)
+			defer cancel()
+			r = r.WithContext(ctx)
+
+			tw := &timeoutWriter{header: http.Header{}}
+			done := make(chan struct{})
+			panicked := make(chan any, 1)
+			go func() {
+				defer func() {
+					if p := recover(); p != nil {
+						panicked <- p
+					}
+				}()
+				next.ServeHTTP(tw, r)
+				close(done)
+			}()
+
+			select {
+			case p := <-panicked:
+				panic(p)
+			case <-done:
+				tw.mu.Lock()
+				defer tw.mu.Unlock()
+				tw.writeTo(w)
+			case <-ctx.Done():
+				tw.mu.Lock()
+				defer tw.mu.Unlock()
+				tw.timedOut = true
+				if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+					// The client cancelled the request.
+					return
+				}
+				_ = outputHandler.ProcessOutput(
+					w,
+					r,
+					nil,
+					inputlogic.RequestTimeoutError,
+					http.StatusGatewayTimeout,
+				)
+			}
+		})
+	}
+}
+
+// timeoutWriter buffers the response until the request has been handled.
+type timeoutWriter struct {
+	mu         sync.Mutex
+	header     http.Header
+	body       bytes.Buffer
+	statusCode int
+	timedOut   bool
+}
+
+func (w *timeoutWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *timeoutWriter) WriteHeader(statusCode int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.timedOut || w.statusCode != 0 {
+		return
+	}
+	w.statusCode = statusCode
+}
+
+func (w *timeoutWriter) Write(b []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	if w.statusCode == 0 {
+		w.statusCode = http.StatusOK
+	}
+	return w.body.Write(b)
+}
+
+func (w *timeoutWriter) writeTo(dst http.ResponseWriter) {
+	header := dst.Header()
+	for key, values := range w.header {
+		header[key] = values
+	}
+	if w.statusCode == 0 {
+		w.statusCode = http.StatusOK
+	}
+	dst.WriteHeader(w.statusCode)
+	_, _ = dst.Write(w.body.Bytes())
+}
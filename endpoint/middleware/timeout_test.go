@@ -0,0 +1,117 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/pakkasys/fluidapi/endpoint/middleware/inputlogic"
+	"github.com/stretchr/testify/assert"
+)
+
+// timeoutOutputHandler writes the ID of the error with the status code.
+type timeoutOutputHandler struct{}
+
+func (h timeoutOutputHandler) ProcessOutput(
+	w http.ResponseWriter,
+	r *http.Request,
+	out any,
+	outError error,
+	statusCode int,
+) error {
+	w.WriteHeader(statusCode)
+	_, err := w.Write([]byte(outError.Error()))
+	return err
+}
+
+// TestTimeoutMiddlewareWrapper tests the ID of the middleware wrapper.
+func TestTimeoutMiddlewareWrapper(t *testing.T) {
+	wrapper := TimeoutMiddlewareWrapper(time.Second, timeoutOutputHandler{})
+
+	assert.Equal(t, TimeoutMiddlewareID, wrapper.ID)
+	assert.NotNil(t, wrapper.Middleware)
+}
+
+// TestTimeoutMiddleware_Completed tests that the response of requests handled
+// in time is written.
+func TestTimeoutMiddleware_Completed(t *testing.T) {
+	handler := TimeoutMiddleware(time.Second, timeoutOutputHandler{})(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, ok := r.Context().Deadline()
+			assert.True(t, ok)
+			w.Header().Set("X-Test", "value")
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte("created"))
+		}),
+	)
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, "value", w.Header().Get("X-Test"))
+	assert.Equal(t, "created", w.Body.String())
+}
+
+// TestTimeoutMiddleware_Timeout tests that the timeout error is sent when the
+// deadline is exceeded and that later writes are discarded.
+func TestTimeoutMiddleware_Timeout(t *testing.T) {
+	writeErr := make(chan error, 1)
+	handler := TimeoutMiddleware(10*time.Millisecond, timeoutOutputHandler{})(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-r.Context().Done()
+			time.Sleep(10 * time.Millisecond)
+			_, err := w.Write([]byte("late"))
+			writeErr <- err
+		}),
+	)
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Equal(t, http.StatusGatewayTimeout, w.Code)
+	assert.Equal(t, inputlogic.RequestTimeoutError.ID, w.Body.String())
+	assert.ErrorIs(t, <-writeErr, http.ErrHandlerTimeout)
+}
+
+// TestTimeoutMiddleware_Cancelled tests that nothing is written for requests
+// cancelled by the client.
+func TestTimeoutMiddleware_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	handler := TimeoutMiddleware(time.Second, timeoutOutputHandler{})(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			cancel()
+			<-r.Context().Done()
+		}),
+	)
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx)
+
+	handler.ServeHTTP(w, r)
+
+	assert.Empty(t, w.Body.String())
+}
+
+// TestTimeoutMiddleware_Panic tests that panics of the handler are propagated.
+func TestTimeoutMiddleware_Panic(t *testing.T) {
+	handler := TimeoutMiddleware(time.Second, timeoutOutputHandler{})(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			panic("test panic")
+		}),
+	)
+
+	assert.PanicsWithValue(t, "test panic", func() {
+		handler.ServeHTTP(
+			httptest.NewRecorder(),
+			httptest.NewRequest(http.MethodGet, "/", nil),
+		)
+	})
+}
+
+// TestTimeoutMiddleware_NilOutputHandler tests that the middleware panics
+// without an output handler.
+func TestTimeoutMiddleware_NilOutputHandler(t *testing.T) {
+	assert.Panics(t, func() { TimeoutMiddleware(time.Second, nil) })
+}
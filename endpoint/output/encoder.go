@@ -0,0 +1,192 @@
+// Package output provides an output handler that encodes the endpoint output
+// in the media type negotiated from the Accept header of the request.
+package output
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Media types of the common encoders.
+const (
+	MediaTypeJSON        = "application/json"
+	MediaTypeXML         = "application/xml"
+	MediaTypeMessagePack = "application/msgpack"
+	MediaTypeCBOR        = "application/cbor"
+)
+
+// Encoder encodes values to a writer.
+type Encoder interface {
+	// Encode writes the encoding of the value to the writer.
+	Encode(w io.Writer, value any) error
+}
+
+// EncoderFunc is a function implementing Encoder, e.g. for MessagePack:
+//
+//	registry.Register(
+//		output.MediaTypeMessagePack,
+//		output.EncoderFunc(func(w io.Writer, value any) error {
+//			return msgpack.NewEncoder(w).Encode(value)
+//		}),
+//	)
+type EncoderFunc func(w io.Writer, value any) error
+
+// Encode calls the function.
+func (f EncoderFunc) Encode(w io.Writer, value any) error {
+	return f(w, value)
+}
+
+// JSONEncoder encodes values as JSON.
+type JSONEncoder struct{}
+
+// Encode writes the JSON encoding of the value to the writer.
+func (e JSONEncoder) Encode(w io.Writer, value any) error {
+	return json.NewEncoder(w).Encode(value)
+}
+
+// XMLEncoder encodes values as XML.
+type XMLEncoder struct{}
+
+// Encode writes the XML encoding of the value to the writer.
+func (e XMLEncoder) Encode(w io.Writer, value any) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	return xml.NewEncoder(w).Encode(value)
+}
+
+// Registry holds the encoders by media type. JSON is used when the client
+// accepts none of the registered media types. It is safe for concurrent use.
+type Registry struct {
+	mu         sync.RWMutex
+	encoders   map[string]Encoder
+	mediaTypes []string
+}
+
+// NewRegistry creates a new encoder registry with the JSON and XML encoders.
+// Encoders for e.g. MessagePack and CBOR can be registered with Register.
+func NewRegistry() *Registry {
+	registry := &Registry{
+		encoders: map[string]Encoder{},
+	}
+	registry.Register(MediaTypeJSON, JSONEncoder{})
+	registry.Register(MediaTypeXML, XMLEncoder{})
+	return registry
+}
+
+// Register registers an encoder for a media type. An encoder already
+// registered for the media type is replaced.
+//
+//   - mediaType: The media type, e.g. "application/cbor".
+//   - encoder: The encoder.
+func (r *Registry) Register(mediaType string, encoder Encoder) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	mediaType = strings.ToLower(mediaType)
+	if _, ok := r.encoders[mediaType]; !ok {
+		r.mediaTypes = append(r.mediaTypes, mediaType)
+	}
+	r.encoders[mediaType] = encoder
+}
+
+// Encoder returns the encoder registered for a media type.
+//
+//   - mediaType: The media type.
+func (r *Registry) Encoder(mediaType string) (Encoder, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	encoder, ok := r.encoders[strings.ToLower(mediaType)]
+	return encoder, ok
+}
+
+// Negotiate returns the media type and encoder best matching the Accept
+// header. Media ranges are preferred by their quality values and then by
+// their order. If no registered media type is acceptable, the JSON encoder is
+// returned.
+//
+//   - accept: The value of the Accept header.
+func (r *Registry) Negotiate(accept string) (string, Encoder) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, mediaRange := range parseAccept(accept) {
+		for _, mediaType := range r.mediaTypes {
+			if matchesMediaRange(mediaType, mediaRange) {
+				return mediaType, r.encoders[mediaType]
+			}
+		}
+	}
+
+	if encoder, ok := r.encoders[MediaTypeJSON]; ok {
+		return MediaTypeJSON, encoder
+	}
+	return MediaTypeJSON, JSONEncoder{}
+}
+
+type acceptedRange struct {
+	mediaRange string
+	quality    float64
+}
+
+// parseAccept returns the acceptable media ranges of the Accept header sorted
+// by their quality values.
+func parseAccept(accept string) []string {
+	var ranges []acceptedRange
+	for _, part := range strings.Split(accept, ",") {
+		mediaRange, parameters, _ := strings.Cut(part, ";")
+		mediaRange = strings.ToLower(strings.TrimSpace(mediaRange))
+		if mediaRange == "" {
+			continue
+		}
+
+		quality := 1.0
+		for _, parameter := range strings.Split(parameters, ";") {
+			name, value, _ := strings.Cut(strings.TrimSpace(parameter), "=")
+			if name != "q" {
+				continue
+			}
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err == nil {
+				quality = parsed
+			}
+		}
+		if quality <= 0 {
+			continue
+		}
+		ranges = append(ranges, acceptedRange{
+			mediaRange: mediaRange,
+			quality:    quality,
+		})
+	}
+
+	slices.SortStableFunc(ranges, func(a, b acceptedRange) int {
+		switch {
+		case a.quality > b.quality:
+			return -1
+		case a.quality < b.quality:
+			return 1
+		}
+		return 0
+	})
+
+	mediaRanges := make([]string, len(ranges))
+	for i := range ranges {
+		mediaRanges[i] = ranges[i].mediaRange
+	}
+	return mediaRanges
+}
+
+func matchesMediaRange(mediaType string, mediaRange string) bool {
+	if mediaRange == "*/*" || mediaRange == mediaType {
+		return true
+	}
+	prefix, ok := strings.CutSuffix(mediaRange, "/*")
+	return ok && strings.HasPrefix(mediaType, prefix+"/")
+}
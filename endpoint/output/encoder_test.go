@@ -0,0 +1,71 @@
+package output
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestRegistry_Negotiate tests selecting encoders from Accept headers.
+func TestRegistry_Negotiate(t *testing.T) {
+	cbor := EncoderFunc(func(w io.Writer, value any) error { return nil })
+	registry := NewRegistry()
+	registry.Register("Application/CBOR", cbor)
+
+	tests := []struct {
+		accept    string
+		mediaType string
+	}{
+		{"", MediaTypeJSON},
+		{"text/html", MediaTypeJSON},
+		{"application/xml", MediaTypeXML},
+		{"text/html, application/xml;q=0.9, */*;q=0.8", MediaTypeXML},
+		{"application/json;q=0.5, application/cbor", MediaTypeCBOR},
+		{"application/xml;q=0, application/*", MediaTypeJSON},
+		{"application/cbor;q=0.1, application/xml;q=0.1", MediaTypeCBOR},
+		{"*/*", MediaTypeJSON},
+	}
+	for _, test := range tests {
+		mediaType, encoder := registry.Negotiate(test.accept)
+
+		assert.Equal(t, test.mediaType, mediaType, test.accept)
+		expected, _ := registry.Encoder(test.mediaType)
+		assert.NotNil(t, encoder)
+		assert.IsType(t, expected, encoder)
+	}
+}
+
+// TestRegistry_Negotiate_NoJSON tests the fallback when no JSON encoder is
+// registered.
+func TestRegistry_Negotiate_NoJSON(t *testing.T) {
+	registry := &Registry{encoders: map[string]Encoder{}}
+
+	mediaType, encoder := registry.Negotiate("application/xml")
+
+	assert.Equal(t, MediaTypeJSON, mediaType)
+	assert.Equal(t, JSONEncoder{}, encoder)
+}
+
+// TestEncoders tests the JSON and XML encoders.
+func TestEncoders(t *testing.T) {
+	type value struct {
+		Name string `json:"name" xml:"name"`
+	}
+
+	var jsonBuffer bytes.Buffer
+	err := JSONEncoder{}.Encode(&jsonBuffer, value{Name: "a"})
+	assert.NoError(t, err)
+	assert.Equal(t, "{\"name\":\"a\"}\n", jsonBuffer.String())
+
+	var xmlBuffer bytes.Buffer
+	err = XMLEncoder{}.Encode(&xmlBuffer, value{Name: "a"})
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		"<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n"+
+			"<value><name>a</name></value>",
+		xmlBuffer.String(),
+	)
+}
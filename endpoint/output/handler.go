@@ -0,0 +1,265 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"net/http"
+	"reflect"
+	"slices"
+
+	"github.com/pakkasys/fluidapi/core/api"
+	"github.com/pakkasys/fluidapi/endpoint/middleware"
+)
+
+// Default keys of the envelope.
+const (
+	DefaultPayloadKey = "payload"
+	DefaultErrorKey   = "error"
+)
+
+// Output is the body of the responses written by the handler. It is encoded
+// as JSON and XML with the keys of the envelope of the handler.
+type Output struct {
+	XMLName xml.Name `json:"-" xml:"output"`
+	// The output of the endpoint, omitted on errors.
+	Payload any `json:"payload,omitempty" xml:"payload,omitempty"`
+	// The error of the endpoint, omitted on success.
+	Error error `json:"error,omitempty" xml:"error,omitempty"`
+
+	payloadKey string
+	errorKey   string
+}
+
+// Envelope configures how the handler wraps the outputs and the errors.
+type Envelope struct {
+	// PayloadKey is the key of the output, DefaultPayloadKey if empty
+	PayloadKey string
+	// ErrorKey is the key of the error, DefaultErrorKey if empty
+	ErrorKey string
+	// Disabled writes the output or the error as the body as is
+	Disabled bool
+}
+
+// Handler is an output handler for the inputlogic middleware. It encodes the
+// output with the encoder negotiated from the Accept header of the request
+// and sets the Content-Type header accordingly.
+type Handler struct {
+	// Encoders holds the available encoders.
+	Encoders *Registry
+	// Envelope configures the wrapping of the outputs and the errors.
+	Envelope Envelope
+	// RedactErrorFields are the JSON fields of the error data whose values
+	// are replaced with middleware.RedactedValue, e.g. "email". The fields
+	// are redacted at any depth of the data.
+	RedactErrorFields []string
+	// RedactError modifies the error data before it is encoded, e.g. to mask
+	// personal data. It is called after the fields are redacted. Optional.
+	RedactError func(r *http.Request, data any) any
+}
+
+// NewHandler returns a new output handler.
+//
+//   - encoders: The available encoders. If nil, a new registry with the JSON
+//     and XML encoders is used.
+func NewHandler(encoders *Registry) *Handler {
+	if encoders == nil {
+		encoders = NewRegistry()
+	}
+	return &Handler{Encoders: encoders}
+}
+
+// ProcessOutput writes the output or the error with the status code. Stream
+// outputs are streamed in their own format.
+//
+//   - w: The HTTP response writer.
+//   - r: The HTTP request.
+//   - out: The output of the endpoint.
+//   - outError: The error of the endpoint.
+//   - statusCode: The HTTP status code.
+func (h *Handler) ProcessOutput(
+	w http.ResponseWriter,
+	r *http.Request,
+	out any,
+	outError error,
+	statusCode int,
+) error {
+	if stream, ok := out.(*Stream); ok {
+		if stream != nil && outError == nil {
+			return writeStream(w, r, stream, statusCode)
+		}
+		out = nil
+	}
+
+	outError, err := h.redact(r, outError)
+	if err != nil {
+		return err
+	}
+
+	mediaType, encoder := h.Encoders.Negotiate(r.Header.Get("Accept"))
+
+	w.Header().Set("Content-Type", mediaType)
+	w.Header().Add("Vary", "Accept")
+	w.WriteHeader(statusCode)
+
+	return encoder.Encode(w, h.body(nilIfNilPointer(out), outError))
+}
+
+// body returns the body of the output or the error wrapped in the envelope.
+func (h *Handler) body(out any, outError error) any {
+	if !h.Envelope.Disabled {
+		return Output{
+			Payload:    out,
+			Error:      outError,
+			payloadKey: h.Envelope.PayloadKey,
+			errorKey:   h.Envelope.ErrorKey,
+		}
+	}
+	if outError != nil {
+		return outError
+	}
+	return out
+}
+
+// redact returns a copy of an API error with its data redacted.
+func (h *Handler) redact(r *http.Request, outError error) (error, error) {
+	if len(h.RedactErrorFields) == 0 && h.RedactError == nil {
+		return outError, nil
+	}
+	apiError, ok := outError.(api.APIError)
+	if !ok {
+		return outError, nil
+	}
+	data := errorData(apiError.GetData())
+	if data == nil {
+		return outError, nil
+	}
+
+	if len(h.RedactErrorFields) != 0 {
+		var decoded any
+		if err := decodeFields(data, &decoded); err != nil {
+			return nil, err
+		}
+		data = redactFields(decoded, h.RedactErrorFields)
+	}
+	if h.RedactError != nil {
+		data = h.RedactError(r, data)
+	}
+	return &api.Error[any]{
+		ID:      apiError.GetID(),
+		Data:    &data,
+		Message: apiError.GetMessage(),
+	}, nil
+}
+
+// redactFields replaces the values of the fields of decoded JSON.
+func redactFields(value any, fields []string) any {
+	switch value := value.(type) {
+	case map[string]any:
+		for key, field := range value {
+			if slices.Contains(fields, key) {
+				value[key] = middleware.RedactedValue
+			} else {
+				value[key] = redactFields(field, fields)
+			}
+		}
+	case []any:
+		for i, element := range value {
+			value[i] = redactFields(element, fields)
+		}
+	}
+	return value
+}
+
+// MarshalJSON encodes the output as a JSON object with the keys of the
+// envelope.
+func (o Output) MarshalJSON() ([]byte, error) {
+	payloadKey, errorKey := o.keys()
+	var buffer bytes.Buffer
+	buffer.WriteByte('{')
+	for _, member := range []struct {
+		key   string
+		value any
+	}{
+		{key: payloadKey, value: o.Payload},
+		{key: errorKey, value: errorValue(o.Error)},
+	} {
+		if member.value == nil {
+			continue
+		}
+		key, err := json.Marshal(member.key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(member.value)
+		if err != nil {
+			return nil, err
+		}
+		if buffer.Len() > 1 {
+			buffer.WriteByte(',')
+		}
+		buffer.Write(key)
+		buffer.WriteByte(':')
+		buffer.Write(value)
+	}
+	buffer.WriteByte('}')
+	return buffer.Bytes(), nil
+}
+
+// MarshalXML encodes the output as an XML element with the keys of the
+// envelope as the names of the child elements.
+func (o Output) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	payloadKey, errorKey := o.keys()
+	start.Name = o.XMLName
+	if start.Name.Local == "" {
+		start.Name.Local = "output"
+	}
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	if o.Payload != nil {
+		err := e.EncodeElement(o.Payload, xml.StartElement{
+			Name: xml.Name{Local: payloadKey},
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if o.Error != nil {
+		err := e.EncodeElement(o.Error, xml.StartElement{
+			Name: xml.Name{Local: errorKey},
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return e.EncodeToken(start.End())
+}
+
+func (o Output) keys() (string, string) {
+	payloadKey, errorKey := o.payloadKey, o.errorKey
+	if payloadKey == "" {
+		payloadKey = DefaultPayloadKey
+	}
+	if errorKey == "" {
+		errorKey = DefaultErrorKey
+	}
+	return payloadKey, errorKey
+}
+
+// errorValue returns nil for nil errors so that they are omitted.
+func errorValue(err error) any {
+	if err == nil {
+		return nil
+	}
+	return err
+}
+
+// nilIfNilPointer returns nil for nil pointers so that they are omitted.
+func nilIfNilPointer(value any) any {
+	reflectValue := reflect.ValueOf(value)
+	if reflectValue.Kind() == reflect.Pointer && reflectValue.IsNil() {
+		return nil
+	}
+	return value
+}
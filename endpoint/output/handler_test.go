@@ -0,0 +1,184 @@
+package output
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pakkasys/fluidapi/core/api"
+	"github.com/pakkasys/fluidapi/endpoint/middleware/inputlogic"
+	"github.com/stretchr/testify/assert"
+)
+
+var _ inputlogic.IOutputHandler = &Handler{}
+
+// TestHandler_ProcessOutput tests writing the output as JSON by default.
+func TestHandler_ProcessOutput(t *testing.T) {
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	payload := "value"
+
+	err := NewHandler(nil).ProcessOutput(w, r, &payload, nil, http.StatusOK)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, MediaTypeJSON, w.Header().Get("Content-Type"))
+	assert.Equal(t, "Accept", w.Header().Get("Vary"))
+	assert.Equal(t, "{\"payload\":\"value\"}\n", w.Body.String())
+}
+
+// TestHandler_ProcessOutput_Error tests that nil outputs are omitted.
+func TestHandler_ProcessOutput_Error(t *testing.T) {
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	var payload *string
+
+	err := NewHandler(nil).ProcessOutput(
+		w,
+		r,
+		payload,
+		api.NewError[any]("ERROR"),
+		http.StatusBadRequest,
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "{\"error\":{\"id\":\"ERROR\"}}\n", w.Body.String())
+}
+
+// TestHandler_ProcessOutput_XML tests writing the negotiated media type.
+func TestHandler_ProcessOutput_XML(t *testing.T) {
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Accept", "application/xml")
+	payload := "value"
+
+	err := NewHandler(nil).ProcessOutput(w, r, &payload, nil, http.StatusOK)
+
+	assert.NoError(t, err)
+	assert.Equal(t, MediaTypeXML, w.Header().Get("Content-Type"))
+	assert.Equal(
+		t,
+		"<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n"+
+			"<output><payload>value</payload></output>",
+		w.Body.String(),
+	)
+}
+
+// TestHandler_ProcessOutput_Envelope tests writing the output with the keys
+// of the envelope.
+func TestHandler_ProcessOutput_Envelope(t *testing.T) {
+	handler := NewHandler(nil)
+	handler.Envelope = Envelope{PayloadKey: "data", ErrorKey: "problem"}
+	payload := "value"
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	err := handler.ProcessOutput(w, r, &payload, nil, http.StatusOK)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "{\"data\":\"value\"}\n", w.Body.String())
+
+	w = httptest.NewRecorder()
+	r.Header.Set("Accept", "application/xml")
+	err = handler.ProcessOutput(
+		w,
+		r,
+		nil,
+		api.NewError[any]("ERROR"),
+		http.StatusBadRequest,
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		"<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n"+
+			"<output><problem><ID>ERROR</ID></problem></output>",
+		w.Body.String(),
+	)
+}
+
+// TestHandler_ProcessOutput_EnvelopeDisabled tests writing the output and the
+// error without the envelope.
+func TestHandler_ProcessOutput_EnvelopeDisabled(t *testing.T) {
+	handler := NewHandler(nil)
+	handler.Envelope.Disabled = true
+	payload := map[string]string{"name": "alice"}
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	err := handler.ProcessOutput(w, r, payload, nil, http.StatusOK)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "{\"name\":\"alice\"}\n", w.Body.String())
+
+	w = httptest.NewRecorder()
+	err = handler.ProcessOutput(
+		w,
+		r,
+		nil,
+		api.NewError[any]("ERROR"),
+		http.StatusBadRequest,
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "{\"id\":\"ERROR\"}\n", w.Body.String())
+}
+
+type accountErrorData struct {
+	Email    string            `json:"email"`
+	Accounts []accountErrorRow `json:"accounts"`
+}
+
+type accountErrorRow struct {
+	ID    int    `json:"id"`
+	Email string `json:"email"`
+	Token string `json:"token"`
+}
+
+// TestHandler_ProcessOutput_Redaction tests redacting the fields of the
+// error data.
+func TestHandler_ProcessOutput_Redaction(t *testing.T) {
+	handler := NewHandler(nil)
+	handler.RedactErrorFields = []string{"email"}
+	handler.RedactError = func(r *http.Request, data any) any {
+		fields := data.(map[string]any)
+		for _, account := range fields["accounts"].([]any) {
+			delete(account.(map[string]any), "token")
+		}
+		return fields
+	}
+	_, outError := inputlogic.ErrorHandler{}.Handle(
+		api.NewError[accountErrorData]("ACCOUNT_EXISTS").WithData(
+			accountErrorData{
+				Email: "alice@example.com",
+				Accounts: []accountErrorRow{
+					{ID: 1, Email: "alice@example.com", Token: "secret"},
+				},
+			},
+		),
+		[]inputlogic.ExpectedError{{
+			ID:         "ACCOUNT_EXISTS",
+			Status:     http.StatusConflict,
+			PublicData: true,
+		}},
+	)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	err := handler.ProcessOutput(w, r, nil, outError, http.StatusConflict)
+
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"error": {
+		"id": "ACCOUNT_EXISTS",
+		"data": {
+			"email": "REDACTED",
+			"accounts": [{"id": 1, "email": "REDACTED"}]
+		}
+	}}`, w.Body.String())
+	assert.Equal(
+		t,
+		"alice@example.com",
+		(*outError.Data).(*accountErrorData).Email,
+	)
+}
@@ -0,0 +1,443 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/pakkasys/fluidapi/core/api"
+	"github.com/pakkasys/fluidapi/endpoint/middleware/inputlogic"
+)
+
+// MediaTypeJSONAPI is the media type of JSON:API documents.
+const MediaTypeJSONAPI = "application/vnd.api+json"
+
+// Resource describes how the outputs of a type are rendered as JSON:API
+// resource objects. It is declared alongside the APIFields of the entity,
+// e.g.
+//
+//	output.RegisterResource[UserOutput](handler, output.Resource{
+//		Type: "users",
+//		Relationships: map[string]output.Relationship{
+//			"team_id": {Name: "team", Type: "teams"},
+//		},
+//	})
+type Resource struct {
+	// Type is the type of the resources, e.g. "users"
+	Type string
+	// IDField is the JSON field of the ID, "id" if empty
+	IDField string
+	// Relationships map the JSON fields of the related resources to their
+	// relationships. The other fields are the attributes of the resources.
+	Relationships map[string]Relationship
+}
+
+// Relationship describes a relationship of a resource. The JSON field of the
+// relationship is either the ID of the related resource, e.g. a foreign key,
+// or the related resource itself, e.g. an included relation. Related
+// resources with fields other than the ID are added to the included
+// resources of the document. Arrays are to-many relationships.
+type Relationship struct {
+	// Name is the name of the relationship, the JSON field if empty
+	Name string
+	// Type is the type of the related resources
+	Type string
+}
+
+// JSONAPIHandler is an output handler for the inputlogic middleware. It
+// renders the outputs as JSON:API documents with the resources of their
+// types, and the errors as JSON:API error objects. Slices of resources and
+// list outputs, i.e. structs with an Items slice such as runner.ListOutput,
+// are rendered as collections with the other fields of the list outputs as
+// the meta of the document.
+type JSONAPIHandler struct {
+	resources map[reflect.Type]*Resource
+	types     map[string]*Resource
+}
+
+// NewJSONAPIHandler returns a new JSON:API output handler without
+// resources. The resources are registered with RegisterResource.
+func NewJSONAPIHandler() *JSONAPIHandler {
+	return &JSONAPIHandler{
+		resources: map[reflect.Type]*Resource{},
+		types:     map[string]*Resource{},
+	}
+}
+
+// RegisterResource registers the resource of the outputs of type T. It
+// panics if the type of the resource is empty.
+//
+//   - handler: The JSON:API output handler.
+//   - resource: The resource of the outputs.
+func RegisterResource[T any](handler *JSONAPIHandler, resource Resource) {
+	if resource.Type == "" {
+		panic("resource type cannot be empty")
+	}
+	registered := &resource
+	handler.resources[reflect.TypeFor[T]()] = registered
+	if _, ok := handler.types[resource.Type]; !ok {
+		handler.types[resource.Type] = registered
+	}
+}
+
+// ProcessOutput writes the output or the error as a JSON:API document with
+// the status code. It returns an error without writing the response if the
+// type of the output has no registered resource.
+//
+//   - w: The HTTP response writer.
+//   - r: The HTTP request.
+//   - out: The output of the endpoint.
+//   - outError: The error of the endpoint.
+//   - statusCode: The HTTP status code.
+func (h *JSONAPIHandler) ProcessOutput(
+	w http.ResponseWriter,
+	r *http.Request,
+	out any,
+	outError error,
+	statusCode int,
+) error {
+	var document map[string]any
+	if outError != nil {
+		document = map[string]any{
+			"errors": errorObjects(outError, statusCode),
+		}
+	} else {
+		var err error
+		document, err = h.document(nilIfNilPointer(out))
+		if err != nil {
+			return err
+		}
+	}
+
+	w.Header().Set("Content-Type", MediaTypeJSONAPI)
+	w.WriteHeader(statusCode)
+	return json.NewEncoder(w).Encode(document)
+}
+
+// jsonapiResource is a JSON:API resource object.
+type jsonapiResource struct {
+	Type          string                         `json:"type"`
+	ID            string                         `json:"id,omitempty"`
+	Attributes    map[string]any                 `json:"attributes,omitempty"`
+	Relationships map[string]jsonapiRelationship `json:"relationships,omitempty"`
+}
+
+type jsonapiRelationship struct {
+	// Data is an identifier, a slice of identifiers or nil
+	Data any `json:"data"`
+}
+
+type jsonapiIdentifier struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+}
+
+type jsonapiError struct {
+	Status string              `json:"status"`
+	Code   string              `json:"code,omitempty"`
+	Title  string              `json:"title,omitempty"`
+	Detail string              `json:"detail,omitempty"`
+	Source *jsonapiErrorSource `json:"source,omitempty"`
+	Meta   any                 `json:"meta,omitempty"`
+}
+
+type jsonapiErrorSource struct {
+	Pointer string `json:"pointer"`
+}
+
+// includedResources collects the included resources of a document, each
+// type and ID pair once.
+type includedResources struct {
+	seen      map[string]bool
+	resources []jsonapiResource
+}
+
+func (i *includedResources) add(resource jsonapiResource) {
+	key := resource.Type + "/" + resource.ID
+	if i.seen[key] {
+		return
+	}
+	i.seen[key] = true
+	i.resources = append(i.resources, resource)
+}
+
+// document returns the document of an output.
+func (h *JSONAPIHandler) document(out any) (map[string]any, error) {
+	if out == nil {
+		return map[string]any{"data": nil}, nil
+	}
+
+	outType := reflect.TypeOf(out)
+	for outType.Kind() == reflect.Pointer {
+		outType = outType.Elem()
+	}
+	if resource, ok := h.resources[outType]; ok {
+		var fields map[string]any
+		if err := decodeFields(out, &fields); err != nil {
+			return nil, err
+		}
+		return h.collectionDocument(resource, []any{fields}, nil, false)
+	}
+
+	itemsType, itemsField := listItems(outType)
+	if itemsType == nil {
+		return nil, fmt.Errorf("no JSON:API resource for type %T", out)
+	}
+	for itemsType.Kind() == reflect.Pointer {
+		itemsType = itemsType.Elem()
+	}
+	resource, ok := h.resources[itemsType]
+	if !ok {
+		return nil, fmt.Errorf("no JSON:API resource for type %T", out)
+	}
+
+	if itemsField == "" {
+		var items []any
+		if err := decodeFields(out, &items); err != nil {
+			return nil, err
+		}
+		return h.collectionDocument(resource, items, nil, true)
+	}
+	var meta map[string]any
+	if err := decodeFields(out, &meta); err != nil {
+		return nil, err
+	}
+	items, _ := meta[itemsField].([]any)
+	delete(meta, itemsField)
+	return h.collectionDocument(resource, items, meta, true)
+}
+
+// collectionDocument returns the document of the resources of a single
+// resource or a collection.
+func (h *JSONAPIHandler) collectionDocument(
+	resource *Resource,
+	items []any,
+	meta map[string]any,
+	collection bool,
+) (map[string]any, error) {
+	included := &includedResources{seen: map[string]bool{}}
+	for _, item := range items {
+		fields, _ := item.(map[string]any)
+		included.seen[resource.Type+"/"+resourceID(resource, fields)] = true
+	}
+
+	data := make([]jsonapiResource, 0, len(items))
+	for _, item := range items {
+		fields, ok := item.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf(
+				"resource %q is not an object",
+				resource.Type,
+			)
+		}
+		data = append(data, h.resourceObject(resource, fields, included))
+	}
+
+	document := map[string]any{}
+	if collection {
+		document["data"] = data
+	} else {
+		document["data"] = data[0]
+	}
+	if len(included.resources) > 0 {
+		document["included"] = included.resources
+	}
+	if len(meta) > 0 {
+		document["meta"] = meta
+	}
+	return document, nil
+}
+
+// resourceObject returns the resource object of the JSON fields of an
+// output, adding its related resources to the included resources.
+func (h *JSONAPIHandler) resourceObject(
+	resource *Resource,
+	fields map[string]any,
+	included *includedResources,
+) jsonapiResource {
+	object := jsonapiResource{
+		Type: resource.Type,
+		ID:   resourceID(resource, fields),
+	}
+	for field, value := range fields {
+		if field == idField(resource) {
+			continue
+		}
+		relationship, ok := resource.Relationships[field]
+		if !ok {
+			if object.Attributes == nil {
+				object.Attributes = map[string]any{}
+			}
+			object.Attributes[field] = value
+			continue
+		}
+
+		name := relationship.Name
+		if name == "" {
+			name = field
+		}
+		if object.Relationships == nil {
+			object.Relationships = map[string]jsonapiRelationship{}
+		}
+		object.Relationships[name] = jsonapiRelationship{
+			Data: h.linkage(relationship, value, included),
+		}
+	}
+	return object
+}
+
+// linkage returns the resource linkage of the value of a relationship.
+func (h *JSONAPIHandler) linkage(
+	relationship Relationship,
+	value any,
+	included *includedResources,
+) any {
+	switch value := value.(type) {
+	case nil:
+		return nil
+	case []any:
+		identifiers := make([]jsonapiIdentifier, 0, len(value))
+		for _, element := range value {
+			identifiers = append(
+				identifiers,
+				h.identifier(relationship, element, included),
+			)
+		}
+		return identifiers
+	default:
+		return h.identifier(relationship, value, included)
+	}
+}
+
+// identifier returns the identifier of a related resource. Related resources
+// with fields other than the ID are added to the included resources.
+func (h *JSONAPIHandler) identifier(
+	relationship Relationship,
+	value any,
+	included *includedResources,
+) jsonapiIdentifier {
+	fields, ok := value.(map[string]any)
+	if !ok {
+		return jsonapiIdentifier{Type: relationship.Type, ID: idString(value)}
+	}
+
+	resource, ok := h.types[relationship.Type]
+	if !ok {
+		resource = &Resource{Type: relationship.Type}
+	}
+	if len(fields) > 1 {
+		included.add(h.resourceObject(resource, fields, included))
+	}
+	return jsonapiIdentifier{
+		Type: relationship.Type,
+		ID:   resourceID(resource, fields),
+	}
+}
+
+// errorObjects returns the error objects of an error. The field errors of
+// validation errors are separate error objects pointing to the attributes.
+func errorObjects(err error, statusCode int) []jsonapiError {
+	base := jsonapiError{
+		Status: strconv.Itoa(statusCode),
+		Title:  http.StatusText(statusCode),
+	}
+	apiError, ok := err.(api.APIError)
+	if !ok {
+		return []jsonapiError{base}
+	}
+
+	base.Code = apiError.GetID()
+	if message := apiError.GetMessage(); message != nil {
+		base.Detail = *message
+	}
+	data := errorData(apiError.GetData())
+	validationData, ok := data.(*inputlogic.ValidationErrorData)
+	if !ok || apiError.GetID() != inputlogic.ValidationError.ID ||
+		validationData == nil || len(validationData.Errors) == 0 {
+		base.Meta = data
+		return []jsonapiError{base}
+	}
+
+	objects := make([]jsonapiError, len(validationData.Errors))
+	for i, fieldError := range validationData.Errors {
+		objects[i] = base
+		objects[i].Detail = fieldError.Message
+		objects[i].Source = &jsonapiErrorSource{
+			Pointer: "/data/attributes/" + fieldError.Field,
+		}
+	}
+	return objects
+}
+
+// errorData returns the data of an API error without the pointers to the
+// interfaces of the masked errors.
+func errorData(data any) any {
+	for {
+		pointer, ok := data.(*any)
+		if !ok {
+			return nilIfNilPointer(data)
+		}
+		if pointer == nil {
+			return nil
+		}
+		data = *pointer
+	}
+}
+
+// listItems returns the element type of a slice, or the element type and the
+// JSON field of the Items slice of a list output.
+func listItems(t reflect.Type) (reflect.Type, string) {
+	switch t.Kind() {
+	case reflect.Slice:
+		return t.Elem(), ""
+	case reflect.Struct:
+		field, ok := t.FieldByName("Items")
+		if !ok || field.Type.Kind() != reflect.Slice {
+			return nil, ""
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "" {
+			name = field.Name
+		}
+		return field.Type.Elem(), name
+	}
+	return nil, ""
+}
+
+// decodeFields decodes the JSON encoding of a value, keeping the numbers as
+// they are encoded.
+func decodeFields(value any, target any) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return decoder.Decode(target)
+}
+
+func idField(resource *Resource) string {
+	if resource.IDField == "" {
+		return "id"
+	}
+	return resource.IDField
+}
+
+func resourceID(resource *Resource, fields map[string]any) string {
+	return idString(fields[idField(resource)])
+}
+
+func idString(value any) string {
+	switch value := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	default:
+		return fmt.Sprint(value)
+	}
+}
@@ -0,0 +1,234 @@
+package output
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pakkasys/fluidapi/core/api"
+	"github.com/pakkasys/fluidapi/endpoint/middleware/inputlogic"
+	"github.com/stretchr/testify/assert"
+)
+
+var _ inputlogic.IOutputHandler = &JSONAPIHandler{}
+
+type teamOutput struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+type roleOutput struct {
+	ID int `json:"id"`
+}
+
+type userOutput struct {
+	ID     int          `json:"id"`
+	Name   string       `json:"name"`
+	TeamID *int         `json:"team_id"`
+	Team   *teamOutput  `json:"team,omitempty"`
+	Roles  []roleOutput `json:"roles,omitempty"`
+}
+
+type userListOutput struct {
+	Items []userOutput `json:"items"`
+	Count *int         `json:"count,omitempty"`
+	Limit int          `json:"limit"`
+}
+
+func testJSONAPIHandler() *JSONAPIHandler {
+	handler := NewJSONAPIHandler()
+	RegisterResource[userOutput](handler, Resource{
+		Type: "users",
+		Relationships: map[string]Relationship{
+			"team_id": {Name: "owner", Type: "teams"},
+			"team":    {Type: "teams"},
+			"roles":   {Type: "roles"},
+		},
+	})
+	RegisterResource[teamOutput](handler, Resource{Type: "teams"})
+	return handler
+}
+
+func processJSONAPI(
+	handler *JSONAPIHandler,
+	out any,
+	outError error,
+	statusCode int,
+) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	err := handler.ProcessOutput(w, r, out, outError, statusCode)
+	if err != nil {
+		panic(err)
+	}
+	return w
+}
+
+// TestJSONAPIHandler_ProcessOutput tests rendering a single resource with
+// its attributes and relationships.
+func TestJSONAPIHandler_ProcessOutput(t *testing.T) {
+	teamID := 3
+
+	w := processJSONAPI(testJSONAPIHandler(), &userOutput{
+		ID:     1,
+		Name:   "alice",
+		TeamID: &teamID,
+		Team:   &teamOutput{ID: 3, Name: "core"},
+		Roles:  []roleOutput{{ID: 7}},
+	}, nil, http.StatusOK)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, MediaTypeJSONAPI, w.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{
+		"data": {
+			"type": "users",
+			"id": "1",
+			"attributes": {"name": "alice"},
+			"relationships": {
+				"owner": {"data": {"type": "teams", "id": "3"}},
+				"team": {"data": {"type": "teams", "id": "3"}},
+				"roles": {"data": [{"type": "roles", "id": "7"}]}
+			}
+		},
+		"included": [
+			{"type": "teams", "id": "3", "attributes": {"name": "core"}}
+		]
+	}`, w.Body.String())
+}
+
+// TestJSONAPIHandler_ProcessOutput_List tests rendering list outputs as
+// collections with the other fields as the meta.
+func TestJSONAPIHandler_ProcessOutput_List(t *testing.T) {
+	count := 2
+
+	w := processJSONAPI(testJSONAPIHandler(), &userListOutput{
+		Items: []userOutput{
+			{ID: 1, Name: "alice", Team: &teamOutput{ID: 3, Name: "core"}},
+			{ID: 2, Name: "bob", Team: &teamOutput{ID: 3, Name: "core"}},
+		},
+		Count: &count,
+		Limit: 10,
+	}, nil, http.StatusOK)
+
+	assert.JSONEq(t, `{
+		"data": [
+			{
+				"type": "users",
+				"id": "1",
+				"attributes": {"name": "alice"},
+				"relationships": {
+					"owner": {"data": null},
+					"team": {"data": {"type": "teams", "id": "3"}}
+				}
+			},
+			{
+				"type": "users",
+				"id": "2",
+				"attributes": {"name": "bob"},
+				"relationships": {
+					"owner": {"data": null},
+					"team": {"data": {"type": "teams", "id": "3"}}
+				}
+			}
+		],
+		"included": [
+			{"type": "teams", "id": "3", "attributes": {"name": "core"}}
+		],
+		"meta": {"count": 2, "limit": 10}
+	}`, w.Body.String())
+}
+
+// TestJSONAPIHandler_ProcessOutput_Slice tests rendering slices as
+// collections and nil outputs as null data.
+func TestJSONAPIHandler_ProcessOutput_Slice(t *testing.T) {
+	w := processJSONAPI(
+		testJSONAPIHandler(),
+		[]teamOutput{{ID: 1, Name: "core"}},
+		nil,
+		http.StatusOK,
+	)
+
+	assert.JSONEq(t, `{"data": [
+		{"type": "teams", "id": "1", "attributes": {"name": "core"}}
+	]}`, w.Body.String())
+
+	var out *userOutput
+	w = processJSONAPI(testJSONAPIHandler(), out, nil, http.StatusOK)
+
+	assert.JSONEq(t, `{"data": null}`, w.Body.String())
+}
+
+// TestJSONAPIHandler_ProcessOutput_UnknownType tests that outputs without a
+// resource are not written.
+func TestJSONAPIHandler_ProcessOutput_UnknownType(t *testing.T) {
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+
+	err := testJSONAPIHandler().ProcessOutput(
+		w,
+		r,
+		map[string]string{},
+		nil,
+		http.StatusOK,
+	)
+
+	assert.EqualError(
+		t,
+		err,
+		"no JSON:API resource for type map[string]string",
+	)
+	assert.Empty(t, w.Body.String())
+}
+
+// TestJSONAPIHandler_ProcessOutput_Error tests rendering errors as error
+// objects.
+func TestJSONAPIHandler_ProcessOutput_Error(t *testing.T) {
+	w := processJSONAPI(
+		testJSONAPIHandler(),
+		nil,
+		api.NewError[any]("USER_EXISTS").WithMessage("name is taken"),
+		http.StatusConflict,
+	)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.JSONEq(t, `{"errors": [{
+		"status": "409",
+		"code": "USER_EXISTS",
+		"title": "Conflict",
+		"detail": "name is taken"
+	}]}`, w.Body.String())
+}
+
+// TestJSONAPIHandler_ProcessOutput_ValidationError tests rendering the field
+// errors of masked validation errors with pointers to the attributes.
+func TestJSONAPIHandler_ProcessOutput_ValidationError(t *testing.T) {
+	status, outError := inputlogic.ErrorHandler{}.Handle(
+		inputlogic.ValidationError.WithData(inputlogic.ValidationErrorData{
+			Errors: []inputlogic.FieldError{
+				{Field: "name", Message: "required"},
+			},
+		}),
+		[]inputlogic.ExpectedError{{
+			ID:         inputlogic.ValidationError.ID,
+			Status:     http.StatusBadRequest,
+			PublicData: true,
+		}},
+	)
+
+	w := processJSONAPI(testJSONAPIHandler(), nil, outError, status)
+
+	assert.JSONEq(t, `{"errors": [{
+		"status": "400",
+		"code": "VALIDATION_ERROR",
+		"title": "Bad Request",
+		"detail": "required",
+		"source": {"pointer": "/data/attributes/name"}
+	}]}`, w.Body.String())
+}
+
+// TestRegisterResource_Panics tests that resources must have a type.
+func TestRegisterResource_Panics(t *testing.T) {
+	assert.PanicsWithValue(t, "resource type cannot be empty", func() {
+		RegisterResource[userOutput](NewJSONAPIHandler(), Resource{})
+	})
+}
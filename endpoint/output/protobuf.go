@@ -0,0 +1,57 @@
+package output
+
+import (
+	"io"
+)
+
+// MediaTypeProtobuf is the media type of protobuf bodies.
+const MediaTypeProtobuf = "application/x-protobuf"
+
+// ProtobufEncoder encodes outputs as protobuf. Messages cannot be wrapped in
+// an Output, so the payload is encoded on success and the error otherwise.
+// The marshal function can convert errors to a message of the API, e.g.
+//
+//	registry.Register(output.MediaTypeProtobuf, output.NewProtobufEncoder(
+//		func(message any) ([]byte, error) {
+//			if apiError, ok := message.(api.APIError); ok {
+//				message = &pb.Error{Id: apiError.GetID()}
+//			}
+//			return proto.Marshal(message.(proto.Message))
+//		},
+//	))
+type ProtobufEncoder struct {
+	// Marshal encodes a message.
+	Marshal func(message any) ([]byte, error)
+}
+
+// NewProtobufEncoder returns a new protobuf encoder.
+//
+//   - marshal: The function encoding a message.
+func NewProtobufEncoder(
+	marshal func(message any) ([]byte, error),
+) *ProtobufEncoder {
+	return &ProtobufEncoder{Marshal: marshal}
+}
+
+// Encode writes the protobuf encoding of the value to the writer. Nothing is
+// written for outputs without a payload or an error.
+func (e *ProtobufEncoder) Encode(w io.Writer, value any) error {
+	message := value
+	if output, ok := value.(Output); ok {
+		switch {
+		case output.Error != nil:
+			message = output.Error
+		case output.Payload != nil:
+			message = output.Payload
+		default:
+			return nil
+		}
+	}
+
+	data, err := e.Marshal(message)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
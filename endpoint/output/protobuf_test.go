@@ -0,0 +1,69 @@
+package output
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pakkasys/fluidapi/core/api"
+	"github.com/stretchr/testify/assert"
+)
+
+// marshalString is a fake protobuf marshal function.
+func marshalString(message any) ([]byte, error) {
+	if apiError, ok := message.(api.APIError); ok {
+		return []byte("error " + apiError.GetID()), nil
+	}
+	return []byte(fmt.Sprint(*message.(*string))), nil
+}
+
+// TestProtobufEncoder tests encoding the payload or the error of outputs.
+func TestProtobufEncoder(t *testing.T) {
+	encoder := NewProtobufEncoder(marshalString)
+	payload := "payload"
+
+	var buffer bytes.Buffer
+	assert.NoError(t, encoder.Encode(&buffer, Output{Payload: &payload}))
+	assert.Equal(t, "payload", buffer.String())
+
+	buffer.Reset()
+	assert.NoError(t, encoder.Encode(&buffer, Output{
+		Error: api.NewError[any]("ERROR"),
+	}))
+	assert.Equal(t, "error ERROR", buffer.String())
+
+	buffer.Reset()
+	assert.NoError(t, encoder.Encode(&buffer, Output{}))
+	assert.Empty(t, buffer.String())
+}
+
+// TestProtobufEncoder_Error tests that marshal errors are returned.
+func TestProtobufEncoder_Error(t *testing.T) {
+	encoder := NewProtobufEncoder(func(message any) ([]byte, error) {
+		return nil, errors.New("marshal error")
+	})
+
+	err := encoder.Encode(&bytes.Buffer{}, "message")
+
+	assert.EqualError(t, err, "marshal error")
+}
+
+// TestHandler_ProcessOutput_Protobuf tests serving protobuf clients.
+func TestHandler_ProcessOutput_Protobuf(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register(MediaTypeProtobuf, NewProtobufEncoder(marshalString))
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Accept", MediaTypeProtobuf)
+	payload := "payload"
+
+	err := NewHandler(registry).
+		ProcessOutput(w, r, &payload, nil, http.StatusOK)
+
+	assert.NoError(t, err)
+	assert.Equal(t, MediaTypeProtobuf, w.Header().Get("Content-Type"))
+	assert.Equal(t, "payload", w.Body.String())
+}
@@ -0,0 +1,200 @@
+package output
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"mime"
+	"net/http"
+
+	"github.com/pakkasys/fluidapi/core/api"
+	"github.com/pakkasys/fluidapi/endpoint/middleware/inputlogic"
+)
+
+// Media types of the streams.
+const (
+	MediaTypeNDJSON = "application/x-ndjson"
+	MediaTypeCSV    = "text/csv; charset=utf-8"
+)
+
+const defaultFlushEvery = 100
+
+// Stream is an endpoint output that is streamed to the client instead of
+// being encoded as a whole, e.g. for large exports. The items are pulled from
+// the iterator as the client reads the response, so a slow client slows the
+// producer down instead of the payload being buffered in memory. Callbacks
+// return it as their output:
+//
+//	func(
+//		w http.ResponseWriter,
+//		r *http.Request,
+//		i *Input,
+//	) (*output.Stream, error) {
+//		return output.NewNDJSONStream(service.AllUsers(r.Context())), nil
+//	}
+//
+// The status and headers are sent before the first item, so an error of the
+// iterator cannot change them. For NDJSON streams the error is written as the
+// last line, e.g. {"error":{"id":"INTERNAL_SERVER_ERROR"}}, and CSV streams
+// are cut short.
+type Stream struct {
+	// ContentType is the media type of the stream.
+	ContentType string
+	// Filename, if set, is sent in a Content-Disposition header so that
+	// browsers download the stream as a file.
+	Filename string
+	// FlushEvery is the number of items after which the response is flushed.
+	// Defaults to 100.
+	FlushEvery int
+
+	write func(w io.Writer, flush func() error) error
+}
+
+// NewNDJSONStream returns a stream writing each item as a line of JSON.
+//
+//   - items: The items and the error ending the iteration.
+func NewNDJSONStream[T any](items iter.Seq2[T, error]) *Stream {
+	return &Stream{
+		ContentType: MediaTypeNDJSON,
+		write: func(w io.Writer, flush func() error) error {
+			encoder := json.NewEncoder(w)
+			for item, err := range items {
+				if err != nil {
+					_ = encoder.Encode(Output{Error: streamError(err)})
+					return err
+				}
+				if err := encoder.Encode(item); err != nil {
+					return err
+				}
+				if err := flush(); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+}
+
+// NewCSVStream returns a stream writing each item as a CSV record.
+//
+//   - header: The header record. Optional.
+//   - records: The records and the error ending the iteration.
+func NewCSVStream(
+	header []string,
+	records iter.Seq2[[]string, error],
+) *Stream {
+	return &Stream{
+		ContentType: MediaTypeCSV,
+		write: func(w io.Writer, flush func() error) error {
+			writer := csv.NewWriter(w)
+			if header != nil {
+				if err := writer.Write(header); err != nil {
+					return err
+				}
+			}
+			for record, err := range records {
+				if err != nil {
+					writer.Flush()
+					return err
+				}
+				if err := writer.Write(record); err != nil {
+					return err
+				}
+				if err := flush(); err != nil {
+					return err
+				}
+			}
+			writer.Flush()
+			return writer.Error()
+		},
+	}
+}
+
+// NewReaderStream returns a stream copying the reader, e.g. a file or the
+// output of an export command. The reader is closed if it is an io.Closer.
+//
+//   - contentType: The media type of the content.
+//   - reader: The content.
+func NewReaderStream(contentType string, reader io.Reader) *Stream {
+	return &Stream{
+		ContentType: contentType,
+		write: func(w io.Writer, flush func() error) error {
+			if closer, ok := reader.(io.Closer); ok {
+				defer closer.Close()
+			}
+			_, err := io.Copy(w, reader)
+			return err
+		},
+	}
+}
+
+// WithFilename sets the file name of the stream and returns the stream.
+//
+//   - filename: The file name, e.g. "users.csv".
+func (s *Stream) WithFilename(filename string) *Stream {
+	s.Filename = filename
+	return s
+}
+
+// writeStream writes the headers and streams the items. Buffered writers are
+// flushed every FlushEvery items.
+func writeStream(
+	w http.ResponseWriter,
+	r *http.Request,
+	stream *Stream,
+	statusCode int,
+) error {
+	w.Header().Set("Content-Type", stream.ContentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	if stream.Filename != "" {
+		w.Header().Set(
+			"Content-Disposition",
+			mime.FormatMediaType(
+				"attachment",
+				map[string]string{"filename": stream.Filename},
+			),
+		)
+	}
+	w.WriteHeader(statusCode)
+	if r.Method == http.MethodHead {
+		return nil
+	}
+
+	flushEvery := stream.FlushEvery
+	if flushEvery <= 0 {
+		flushEvery = defaultFlushEvery
+	}
+	controller := http.NewResponseController(w)
+	count := 0
+	flush := func() error {
+		count++
+		if count%flushEvery != 0 {
+			return nil
+		}
+		return ignoreNotSupported(controller.Flush())
+	}
+
+	if err := stream.write(w, flush); err != nil {
+		return fmt.Errorf("stream: %w", err)
+	}
+	return ignoreNotSupported(controller.Flush())
+}
+
+// streamError returns the error sent to the client. Errors other than API
+// errors are not exposed.
+func streamError(err error) error {
+	if apiError, ok := err.(api.APIError); ok {
+		return apiError
+	}
+	return inputlogic.InternalServerError
+}
+
+func ignoreNotSupported(err error) error {
+	if errors.Is(err, http.ErrNotSupported) {
+		return nil
+	}
+	return err
+}
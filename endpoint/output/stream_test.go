@@ -0,0 +1,181 @@
+package output
+
+import (
+	"errors"
+	"io"
+	"iter"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/pakkasys/fluidapi/core/api"
+	"github.com/stretchr/testify/assert"
+)
+
+type streamedUser struct {
+	Name string `json:"name"`
+}
+
+// items returns an iterator yielding the values and then the error, if any.
+func items[T any](values []T, err error) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for _, value := range values {
+			if !yield(value, nil) {
+				return
+			}
+		}
+		if err != nil {
+			var zero T
+			yield(zero, err)
+		}
+	}
+}
+
+// closingReader records whether it has been closed.
+type closingReader struct {
+	io.Reader
+	closed bool
+}
+
+func (r *closingReader) Close() error {
+	r.closed = true
+	return nil
+}
+
+func processStream(
+	method string,
+	stream *Stream,
+) (*httptest.ResponseRecorder, error) {
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(method, "/", nil)
+	err := NewHandler(nil).ProcessOutput(w, r, stream, nil, http.StatusOK)
+	return w, err
+}
+
+// TestNewNDJSONStream tests streaming items as newline-delimited JSON.
+func TestNewNDJSONStream(t *testing.T) {
+	stream := NewNDJSONStream(items(
+		[]streamedUser{{Name: "a"}, {Name: "b"}},
+		nil,
+	))
+	stream.FlushEvery = 1
+
+	w, err := processStream(http.MethodGet, stream)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, MediaTypeNDJSON, w.Header().Get("Content-Type"))
+	assert.Equal(t, "{\"name\":\"a\"}\n{\"name\":\"b\"}\n", w.Body.String())
+	assert.True(t, w.Flushed)
+}
+
+// TestNewNDJSONStream_Error tests that the error ends the stream.
+func TestNewNDJSONStream_Error(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		wantLine string
+	}{
+		{
+			name:     "Internal error",
+			err:      errors.New("database error"),
+			wantLine: `{"error":{"id":"INTERNAL_SERVER_ERROR"}}`,
+		},
+		{
+			name:     "API error",
+			err:      api.NewError[any]("EXPORT_FAILED"),
+			wantLine: `{"error":{"id":"EXPORT_FAILED"}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, err := processStream(
+				http.MethodGet,
+				NewNDJSONStream(items([]streamedUser{{Name: "a"}}, tt.err)),
+			)
+
+			assert.ErrorIs(t, err, tt.err)
+			assert.Equal(t, http.StatusOK, w.Code)
+			lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+			assert.Equal(t, []string{`{"name":"a"}`, tt.wantLine}, lines)
+		})
+	}
+}
+
+// TestNewCSVStream tests streaming records as CSV.
+func TestNewCSVStream(t *testing.T) {
+	stream := NewCSVStream(
+		[]string{"id", "name"},
+		items([][]string{{"1", "a"}, {"2", "b, c"}}, nil),
+	).WithFilename("users.csv")
+
+	w, err := processStream(http.MethodGet, stream)
+
+	assert.NoError(t, err)
+	assert.Equal(t, MediaTypeCSV, w.Header().Get("Content-Type"))
+	assert.Equal(
+		t,
+		`attachment; filename=users.csv`,
+		w.Header().Get("Content-Disposition"),
+	)
+	assert.Equal(t, "id,name\n1,a\n2,\"b, c\"\n", w.Body.String())
+}
+
+// TestNewCSVStream_Error tests that the error cuts the stream short.
+func TestNewCSVStream_Error(t *testing.T) {
+	streamErr := errors.New("database error")
+
+	w, err := processStream(
+		http.MethodGet,
+		NewCSVStream(nil, items([][]string{{"1", "a"}}, streamErr)),
+	)
+
+	assert.ErrorIs(t, err, streamErr)
+	assert.Equal(t, "1,a\n", w.Body.String())
+}
+
+// TestNewReaderStream tests copying a reader.
+func TestNewReaderStream(t *testing.T) {
+	reader := &closingReader{Reader: strings.NewReader("content")}
+
+	w, err := processStream(
+		http.MethodGet,
+		NewReaderStream("text/plain", reader),
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "text/plain", w.Header().Get("Content-Type"))
+	assert.Equal(t, "content", w.Body.String())
+	assert.True(t, reader.closed)
+}
+
+// TestStream_Head tests that the body of HEAD requests is not written.
+func TestStream_Head(t *testing.T) {
+	w, err := processStream(
+		http.MethodHead,
+		NewNDJSONStream(items([]streamedUser{{Name: "a"}}, nil)),
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(t, MediaTypeNDJSON, w.Header().Get("Content-Type"))
+	assert.Empty(t, w.Body.String())
+}
+
+// TestStream_OutputError tests that errors are not streamed.
+func TestStream_OutputError(t *testing.T) {
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+
+	err := NewHandler(nil).ProcessOutput(
+		w,
+		r,
+		NewNDJSONStream(items([]streamedUser{{Name: "a"}}, nil)),
+		api.NewError[any]("ERROR"),
+		http.StatusBadRequest,
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(t, MediaTypeJSON, w.Header().Get("Content-Type"))
+	assert.Equal(t, "{\"error\":{\"id\":\"ERROR\"}}\n", w.Body.String())
+}
@@ -0,0 +1,92 @@
+package page
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+
+	"github.com/pakkasys/fluidapi/core/api"
+)
+
+var InvalidCursorError = api.NewError[any]("INVALID_CURSOR")
+
+// Cursor is a position in a result set ordered by a keyset. It is sent to
+// the clients as an opaque token. The keys must end in a unique field, e.g.
+// the ID, so that the position is unambiguous, and their values must not be
+// null.
+type Cursor struct {
+	// Keys are the order fields and their values at the position
+	Keys []CursorKey `json:"k"`
+	// Before selects the rows before the position, i.e. the previous page,
+	// instead of the rows after it
+	Before bool `json:"b,omitempty"`
+}
+
+// CursorKey is an order field of a cursor and its value at the position.
+type CursorKey struct {
+	// Field is the API field of the order
+	Field string `json:"f"`
+	// Descending is set if the field is ordered in descending order
+	Descending bool `json:"d,omitempty"`
+	// Value is the value of the field at the position
+	Value any `json:"v"`
+}
+
+// Cursors are the cursor tokens of the next and the previous pages of a
+// response. A token is empty if there is no such page.
+type Cursors struct {
+	Next     string `json:"next,omitempty"`
+	Previous string `json:"previous,omitempty"`
+}
+
+// Encode returns the token of the cursor. The values of the keys must be JSON
+// marshalable.
+func (c *Cursor) Encode() (string, error) {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// DecodeCursor decodes a cursor token. Integer values are decoded as int64
+// and other numbers as float64. It returns InvalidCursorError if the token is
+// malformed or a value is not a string, a number or a boolean.
+//
+//   - token: The cursor token.
+func DecodeCursor(token string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, InvalidCursorError
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var cursor Cursor
+	if err := decoder.Decode(&cursor); err != nil || len(cursor.Keys) == 0 {
+		return nil, InvalidCursorError
+	}
+	for i, key := range cursor.Keys {
+		if key.Field == "" {
+			return nil, InvalidCursorError
+		}
+		switch value := key.Value.(type) {
+		case string, bool:
+		case json.Number:
+			cursor.Keys[i].Value = decodeNumber(value)
+		default:
+			return nil, InvalidCursorError
+		}
+	}
+	return &cursor, nil
+}
+
+func decodeNumber(number json.Number) any {
+	if value, err := number.Int64(); err == nil {
+		return value
+	}
+	if value, err := number.Float64(); err == nil {
+		return value
+	}
+	return number.String()
+}
@@ -0,0 +1,65 @@
+package page
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCursor_EncodeDecode tests that a decoded token equals the encoded
+// cursor.
+func TestCursor_EncodeDecode(t *testing.T) {
+	cursor := &Cursor{
+		Keys: []CursorKey{
+			{Field: "name", Value: "alice"},
+			{Field: "score", Descending: true, Value: 1.5},
+			{Field: "active", Value: true},
+			{Field: "id", Value: int64(42)},
+		},
+		Before: true,
+	}
+
+	token, err := cursor.Encode()
+	assert.NoError(t, err)
+	assert.NotContains(t, token, "=")
+
+	decoded, err := DecodeCursor(token)
+	assert.NoError(t, err)
+	assert.Equal(t, cursor, decoded)
+}
+
+// TestCursor_Encode_Error tests that the values must be JSON marshalable.
+func TestCursor_Encode_Error(t *testing.T) {
+	cursor := &Cursor{Keys: []CursorKey{{Field: "id", Value: func() {}}}}
+
+	token, err := cursor.Encode()
+
+	assert.Error(t, err)
+	assert.Empty(t, token)
+}
+
+// TestDecodeCursor_Invalid tests malformed cursor tokens.
+func TestDecodeCursor_Invalid(t *testing.T) {
+	encode := func(data string) string {
+		return base64.RawURLEncoding.EncodeToString([]byte(data))
+	}
+	tokens := map[string]string{
+		"not base64":    "!!!",
+		"not JSON":      encode("{"),
+		"no keys":       encode(`{"k":[]}`),
+		"no field":      encode(`{"k":[{"v":1}]}`),
+		"null value":    encode(`{"k":[{"f":"id","v":null}]}`),
+		"object value":  encode(`{"k":[{"f":"id","v":{}}]}`),
+		"unknown shape": encode(`[]`),
+	}
+
+	for name, token := range tokens {
+		t.Run(name, func(t *testing.T) {
+			cursor, err := DecodeCursor(token)
+
+			assert.Nil(t, cursor)
+			assert.ErrorIs(t, err, InvalidCursorError)
+		})
+	}
+}
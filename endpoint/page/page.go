@@ -12,6 +12,9 @@ var MaxPageLimitExceededError = api.NewError[MaxPageLimitExceededErrorData]("MAX
 type Page struct {
 	Offset int `json:"offset" validate:"min=0"`
 	Limit  int `json:"limit" validate:"min=0"`
+	// Cursor is an optional cursor token of a previous response. The offset
+	// is ignored if it is set.
+	Cursor string `json:"cursor,omitempty"`
 }
 
 // Validate validates the input page.
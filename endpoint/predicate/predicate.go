@@ -25,6 +25,16 @@ const (
 
 	IN     Predicate = "IN"
 	NOT_IN Predicate = "NOT_IN"
+
+	// LIKE matches a pattern, where % matches any characters and _ a single
+	// character
+	LIKE       Predicate = "~"
+	LIKE_SHORT Predicate = "LIKE"
+
+	// OR matches if any of the groups of selectors in the value of the
+	// selector matches. The value is a [][]selector.Selector and the selectors
+	// of a group are joined with AND. The field of the selector is not used.
+	OR Predicate = "OR"
 )
 
 var AllPredicates = []Predicate{
@@ -75,6 +85,13 @@ var OnlyInAndNotInPredicates = []Predicate{
 	NOT_IN,
 }
 
+// OnlyLikePredicates must be allowed explicitly, since the patterns may not
+// be able to use an index.
+var OnlyLikePredicates = []Predicate{
+	LIKE,
+	LIKE_SHORT,
+}
+
 var ToDBPredicates = map[Predicate]util.Predicate{
 	GREATER:                util.GREATER,
 	GREATER_SHORT:          util.GREATER,
@@ -90,4 +107,6 @@ var ToDBPredicates = map[Predicate]util.Predicate{
 	LESS_OR_EQUAL_SHORT:    util.LESS_OR_EQUAL,
 	IN:                     util.IN,
 	NOT_IN:                 util.NOT_IN,
+	LIKE:                   util.LIKE,
+	LIKE_SHORT:             util.LIKE,
 }
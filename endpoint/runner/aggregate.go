@@ -0,0 +1,310 @@
+package runner
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/pakkasys/fluidapi/core/api"
+	"github.com/pakkasys/fluidapi/database/entity"
+	databaseutil "github.com/pakkasys/fluidapi/database/util"
+	"github.com/pakkasys/fluidapi/endpoint/middleware/inputlogic"
+	"github.com/pakkasys/fluidapi/endpoint/order"
+	"github.com/pakkasys/fluidapi/endpoint/page"
+	"github.com/pakkasys/fluidapi/endpoint/selector"
+)
+
+type InvalidDimensionErrorData struct {
+	Dimension string `json:"dimension"`
+}
+
+var InvalidDimensionError = api.NewError[InvalidDimensionErrorData]("INVALID_DIMENSION")
+
+type InvalidMeasureErrorData struct {
+	Measure Measure `json:"measure"`
+}
+
+var InvalidMeasureError = api.NewError[InvalidMeasureErrorData]("INVALID_MEASURE")
+
+// Measure is an aggregate of an API field requested from an aggregate
+// endpoint, e.g. {"function": "SUM", "field": "total"}. A COUNT measure
+// without a field counts the rows.
+type Measure struct {
+	Function databaseutil.AggregateFunction `json:"function"`
+	Field    string                         `json:"field,omitempty"`
+}
+
+// Alias returns the column name of the measure in the output, e.g.
+// "sum_total", or "count" for the row count.
+func (m Measure) Alias() string {
+	function := strings.ToLower(string(m.Function))
+	if m.Field == "" {
+		return function
+	}
+	return function + "_" + m.Field
+}
+
+// AggregateSpecification declares the dimensions and the measures an
+// aggregate endpoint exposes.
+type AggregateSpecification struct {
+	// Dimensions are the API fields the rows can be grouped by
+	Dimensions []string
+	// Measures maps the API fields to the aggregate functions allowed for
+	// them. COUNT without a field is always allowed.
+	Measures map[string][]databaseutil.AggregateFunction
+}
+
+// ParsedAggregateEndpointInput is the parsed input of an aggregate endpoint.
+type ParsedAggregateEndpointInput struct {
+	// Columns are the names of the output columns: the dimensions followed by
+	// the aliases of the measures
+	Columns []string
+	Options entity.AggregateOptions
+}
+
+// AggregateServiceFunc represents a function type to run a grouped aggregate
+// query, e.g. NewAggregateServiceFunc.
+type AggregateServiceFunc func(
+	ctx context.Context,
+	opts entity.AggregateOptions,
+) ([][]any, error)
+
+// NewAggregateServiceFunc creates an aggregate service function running the
+// queries with the entity helpers in a managed transaction, so the queries
+// are scoped to the tenant and the row scope of the request.
+//
+// Parameters:
+//   - helpers: The entity helpers of the aggregated entity.
+//
+// Returns:
+//   - A new AggregateServiceFunc.
+func NewAggregateServiceFunc[T any](
+	helpers *entity.EntityHelpers[T],
+) AggregateServiceFunc {
+	return func(
+		ctx context.Context,
+		opts entity.AggregateOptions,
+	) ([][]any, error) {
+		return helpers.GetAggregatesWithManagedTransaction(ctx, opts)
+	}
+}
+
+// AggregateOutput is the tabular output of an aggregate endpoint. Each row
+// has a value for each column.
+type AggregateOutput struct {
+	Columns []string `json:"columns"`
+	Rows    [][]any  `json:"rows"`
+}
+
+// ParseAggregateEndpointInput parses input for an aggregate endpoint,
+// validating the dimensions and the measures against the specification and
+// translating them with the selectors and the orders into database options.
+// The orders may refer to the dimensions and the aliases of the measures.
+//
+// Parameters:
+//   - apiFields: A mapping of API fields to corresponding database fields.
+//   - specification: The dimensions and the measures of the endpoint.
+//   - dimensions: The dimensions to group by.
+//   - measures: The measures to compute for each group.
+//   - selectors: The selectors filtering the aggregated rows.
+//   - orders: The orders of the groups.
+//   - inputPage: The pagination of the groups.
+//   - maxPageCount: The maximum number of groups per page.
+//
+// Returns:
+//   - A pointer to a ParsedAggregateEndpointInput.
+//   - InvalidDimensionError or InvalidMeasureError if a dimension or a
+//     measure is not allowed, or an error of the selectors, the orders or
+//     the page.
+func ParseAggregateEndpointInput(
+	apiFields APIFields,
+	specification AggregateSpecification,
+	dimensions []string,
+	measures []Measure,
+	selectors []selector.Selector,
+	orders []order.Order,
+	inputPage *page.Page,
+	maxPageCount int,
+) (*ParsedAggregateEndpointInput, error) {
+	parsed := &ParsedAggregateEndpointInput{}
+	orderFields := map[string]databaseutil.Order{}
+
+	for _, dimension := range dimensions {
+		dbField, ok := apiFields[dimension]
+		if !ok ||
+			!slices.Contains(specification.Dimensions, dimension) ||
+			slices.Contains(parsed.Columns, dimension) {
+			return nil, InvalidDimensionError.WithData(
+				InvalidDimensionErrorData{Dimension: dimension},
+			)
+		}
+		parsed.Columns = append(parsed.Columns, dimension)
+		parsed.Options.Dimensions = append(
+			parsed.Options.Dimensions,
+			databaseutil.Projection{
+				Table:  dbField.Table,
+				Column: dbField.Column,
+				Alias:  dimension,
+			},
+		)
+		orderFields[dimension] = databaseutil.Order{
+			Table: dbField.Table,
+			Field: dbField.Column,
+		}
+	}
+
+	for _, measure := range measures {
+		aggregate, err := toAggregate(measure, apiFields, specification)
+		if err != nil {
+			return nil, err
+		}
+		if slices.Contains(parsed.Columns, aggregate.Alias) {
+			return nil, InvalidMeasureError.WithData(
+				InvalidMeasureErrorData{Measure: measure},
+			)
+		}
+		parsed.Columns = append(parsed.Columns, aggregate.Alias)
+		parsed.Options.Measures = append(parsed.Options.Measures, *aggregate)
+		orderFields[aggregate.Alias] = databaseutil.Order{
+			Field: aggregate.Alias,
+		}
+	}
+	if len(parsed.Options.Measures) == 0 {
+		return nil, InvalidMeasureError.WithData(InvalidMeasureErrorData{})
+	}
+
+	validOrders, err := order.ValidateAndDeduplicateOrders(
+		orders,
+		parsed.Columns,
+	)
+	if err != nil {
+		return nil, err
+	}
+	for _, o := range validOrders {
+		dbOrder := orderFields[o.Field]
+		dbOrder.Direction = order.DirectionDatabaseTranslations[o.Direction]
+		parsed.Options.Orders = append(parsed.Options.Orders, dbOrder)
+	}
+
+	dbSelectors, err := selector.ToDBSelectors(selectors, apiFields)
+	if err != nil {
+		return nil, err
+	}
+	parsed.Options.Selectors = dbSelectors
+
+	if inputPage == nil {
+		inputPage = &page.Page{Limit: maxPageCount}
+	}
+	if err := inputPage.Validate(maxPageCount); err != nil {
+		return nil, err
+	}
+	parsed.Options.Page = inputPage
+
+	return parsed, nil
+}
+
+// toAggregate translates a measure to a database aggregate.
+func toAggregate(
+	measure Measure,
+	apiFields APIFields,
+	specification AggregateSpecification,
+) (*databaseutil.Aggregate, error) {
+	if measure.Field == "" && measure.Function == databaseutil.AggregateCount {
+		return &databaseutil.Aggregate{
+			Function: measure.Function,
+			Alias:    measure.Alias(),
+		}, nil
+	}
+
+	dbField, ok := apiFields[measure.Field]
+	if !ok || !slices.Contains(
+		specification.Measures[measure.Field],
+		measure.Function,
+	) {
+		return nil, InvalidMeasureError.WithData(
+			InvalidMeasureErrorData{Measure: measure},
+		)
+	}
+	return &databaseutil.Aggregate{
+		Function: measure.Function,
+		Table:    dbField.Table,
+		Column:   dbField.Column,
+		Alias:    measure.Alias(),
+	}, nil
+}
+
+// AggregateInvoke handles the invocation of an aggregate endpoint.
+//
+// Parameters:
+//   - writer: The HTTP response writer.
+//   - request: The HTTP request.
+//   - input: Input data to the endpoint, implementing ParseableInput.
+//   - serviceFn: Function to run the aggregate query.
+//
+// Returns:
+//   - Pointer to the tabular output or an error.
+func AggregateInvoke[I ParseableInput[ParsedAggregateEndpointInput]](
+	writer http.ResponseWriter,
+	request *http.Request,
+	input I,
+	serviceFn AggregateServiceFunc,
+) (*AggregateOutput, error) {
+	parsedInput, err := input.Parse(request)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := serviceFn(request.Context(), parsedInput.Options)
+	if err != nil {
+		return nil, err
+	}
+	if rows == nil {
+		rows = [][]any{}
+	}
+
+	return &AggregateOutput{Columns: parsedInput.Columns, Rows: rows}, nil
+}
+
+// AggregateEndpointDefinition creates an endpoint definition for a grouped
+// aggregate request, e.g. GET /orders/report.
+//
+// Parameters:
+//   - specification: The input specification for the request.
+//   - serviceFn: Function to run the aggregate query.
+//   - expectedErrors: A list of expected errors to handle.
+//   - stackBuilder: A factory function to create a middleware stack
+//     builder.
+//   - opts: Options for configuring the input logic.
+//   - sendFn: Function to send the request.
+//   - options: Additional endpoint options to configure.
+//
+// Returns:
+//   - An Endpoint instance.
+func AggregateEndpointDefinition[I ParseableInput[ParsedAggregateEndpointInput], W any](
+	specification InputSpecification[I],
+	serviceFn AggregateServiceFunc,
+	expectedErrors []inputlogic.ExpectedError,
+	stackBuilder StackBuilder,
+	opts inputlogic.Options[I],
+	sendFn SendFunc[I, W],
+	options ...EndpointOption[I, AggregateOutput, W],
+) *Endpoint[I, AggregateOutput, W] {
+	callback := func(
+		writer http.ResponseWriter,
+		request *http.Request,
+		input *I,
+	) (*AggregateOutput, error) {
+		return AggregateInvoke(writer, request, *input, serviceFn)
+	}
+
+	return GenericEndpointDefinition(
+		specification,
+		callback,
+		expectedErrors,
+		stackBuilder,
+		opts,
+		sendFn,
+		options...,
+	)
+}
@@ -0,0 +1,232 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pakkasys/fluidapi/core/api"
+	"github.com/pakkasys/fluidapi/database/entity"
+	"github.com/pakkasys/fluidapi/database/util"
+	utilmock "github.com/pakkasys/fluidapi/database/util/mock"
+	"github.com/pakkasys/fluidapi/endpoint/middleware/inputlogic"
+	"github.com/pakkasys/fluidapi/endpoint/order"
+	"github.com/pakkasys/fluidapi/endpoint/page"
+	"github.com/pakkasys/fluidapi/endpoint/predicate"
+	"github.com/pakkasys/fluidapi/endpoint/selector"
+	endpointutil "github.com/pakkasys/fluidapi/endpoint/util"
+	"github.com/stretchr/testify/assert"
+)
+
+var aggregateAPIFields = APIFields{
+	"status": {Table: "orders", Column: "status"},
+	"total":  {Table: "orders", Column: "total"},
+	"secret": {Table: "orders", Column: "secret"},
+}
+
+var aggregateSpecification = AggregateSpecification{
+	Dimensions: []string{"status"},
+	Measures: map[string][]util.AggregateFunction{
+		"total": {util.AggregateSum, util.AggregateAvg},
+	},
+}
+
+// aggregateInput is the input of an aggregate endpoint.
+type aggregateInput struct {
+	dimensions []string
+	measures   []Measure
+}
+
+func (i aggregateInput) Validate() []inputlogic.FieldError {
+	return nil
+}
+
+func (i aggregateInput) Parse(
+	r *http.Request,
+) (*ParsedAggregateEndpointInput, error) {
+	return ParseAggregateEndpointInput(
+		aggregateAPIFields,
+		aggregateSpecification,
+		i.dimensions,
+		i.measures,
+		[]selector.Selector{{
+			AllowedPredicates: []predicate.Predicate{predicate.GREATER},
+			Field:             "total",
+			Predicate:         predicate.GREATER,
+			Value:             0,
+		}},
+		[]order.Order{{Field: "sum_total", Direction: order.DIRECTION_DESC}},
+		nil,
+		100,
+	)
+}
+
+// TestParseAggregateEndpointInput tests translating the dimensions, the
+// measures and the orders to aggregate options.
+func TestParseAggregateEndpointInput(t *testing.T) {
+	parsed, err := aggregateInput{
+		dimensions: []string{"status"},
+		measures: []Measure{
+			{Function: util.AggregateCount},
+			{Function: util.AggregateSum, Field: "total"},
+		},
+	}.Parse(nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"status", "count", "sum_total"}, parsed.Columns)
+	assert.Equal(t, entity.AggregateOptions{
+		Selectors: []util.Selector{{
+			Table:     "orders",
+			Field:     "total",
+			Predicate: util.GREATER,
+			Value:     0,
+		}},
+		Dimensions: []util.Projection{
+			{Table: "orders", Column: "status", Alias: "status"},
+		},
+		Measures: []util.Aggregate{
+			{Function: util.AggregateCount, Alias: "count"},
+			{
+				Function: util.AggregateSum,
+				Table:    "orders",
+				Column:   "total",
+				Alias:    "sum_total",
+			},
+		},
+		Orders: []util.Order{{Field: "sum_total", Direction: util.OrderDesc}},
+		Page:   &page.Page{Limit: 100},
+	}, parsed.Options)
+}
+
+// TestParseAggregateEndpointInput_Invalid tests the validation of the
+// dimensions, the measures and the orders.
+func TestParseAggregateEndpointInput_Invalid(t *testing.T) {
+	sum := Measure{Function: util.AggregateSum, Field: "total"}
+	tests := []struct {
+		name     string
+		input    aggregateInput
+		expected string
+	}{
+		{
+			name:     "dimension not allowed",
+			input:    aggregateInput{dimensions: []string{"secret"}},
+			expected: InvalidDimensionError.ID,
+		},
+		{
+			name:     "duplicate dimension",
+			input:    aggregateInput{dimensions: []string{"status", "status"}},
+			expected: InvalidDimensionError.ID,
+		},
+		{
+			name:     "no measures",
+			input:    aggregateInput{dimensions: []string{"status"}},
+			expected: InvalidMeasureError.ID,
+		},
+		{
+			name: "function not allowed",
+			input: aggregateInput{measures: []Measure{
+				{Function: util.AggregateMax, Field: "total"},
+			}},
+			expected: InvalidMeasureError.ID,
+		},
+		{
+			name:     "duplicate measure",
+			input:    aggregateInput{measures: []Measure{sum, sum}},
+			expected: InvalidMeasureError.ID,
+		},
+		{
+			name: "order not selected",
+			input: aggregateInput{measures: []Measure{
+				{Function: util.AggregateAvg, Field: "total"},
+			}},
+			expected: order.InvalidOrderFieldError.ID,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			parsed, err := test.input.Parse(nil)
+
+			assert.Nil(t, parsed)
+			apiErr, ok := err.(api.APIError)
+			assert.True(t, ok)
+			assert.Equal(t, test.expected, apiErr.GetID())
+		})
+	}
+}
+
+// TestAggregateInvoke tests returning the rows of the service as a table.
+func TestAggregateInvoke(t *testing.T) {
+	input := aggregateInput{
+		dimensions: []string{"status"},
+		measures:   []Measure{{Function: util.AggregateSum, Field: "total"}},
+	}
+	var options entity.AggregateOptions
+
+	output, err := AggregateInvoke(
+		httptest.NewRecorder(),
+		httptest.NewRequest(http.MethodGet, "/orders/report", nil),
+		input,
+		func(
+			ctx context.Context,
+			opts entity.AggregateOptions,
+		) ([][]any, error) {
+			options = opts
+			return nil, nil
+		},
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(t, &AggregateOutput{
+		Columns: []string{"status", "sum_total"},
+		Rows:    [][]any{},
+	}, output)
+	assert.Len(t, options.Measures, 1)
+}
+
+// TestAggregateInvoke_ServiceError tests that service errors are returned.
+func TestAggregateInvoke_ServiceError(t *testing.T) {
+	output, err := AggregateInvoke(
+		httptest.NewRecorder(),
+		httptest.NewRequest(http.MethodGet, "/orders/report", nil),
+		aggregateInput{measures: []Measure{
+			{Function: util.AggregateSum, Field: "total"},
+		}},
+		func(
+			ctx context.Context,
+			opts entity.AggregateOptions,
+		) ([][]any, error) {
+			return nil, errors.New("service error")
+		},
+	)
+
+	assert.Nil(t, output)
+	assert.EqualError(t, err, "service error")
+}
+
+// TestNewAggregateServiceFunc tests that the queries are run with the entity
+// helpers.
+func TestNewAggregateServiceFunc(t *testing.T) {
+	mockTx := new(utilmock.MockTx)
+	mockTx.On("Rollback").Return(nil)
+	serviceFn := NewAggregateServiceFunc(&entity.EntityHelpers[struct{}]{
+		TableName: "orders",
+		GetTxFn: func(ctx context.Context) (util.Tx, error) {
+			return mockTx, nil
+		},
+		TenantResolver: func(ctx context.Context) (*entity.Tenant, error) {
+			return nil, errors.New("tenant error")
+		},
+	})
+
+	rows, err := serviceFn(
+		endpointutil.NewContext(context.Background()),
+		entity.AggregateOptions{},
+	)
+
+	assert.Nil(t, rows)
+	assert.EqualError(t, err, "tenant error")
+	mockTx.AssertExpectations(t)
+}
@@ -0,0 +1,463 @@
+package runner
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/pakkasys/fluidapi/core/api"
+	"github.com/pakkasys/fluidapi/database/entity"
+	"github.com/pakkasys/fluidapi/database/transaction"
+	"github.com/pakkasys/fluidapi/database/util"
+	"github.com/pakkasys/fluidapi/endpoint/middleware/inputlogic"
+	"github.com/pakkasys/fluidapi/endpoint/predicate"
+	"github.com/pakkasys/fluidapi/endpoint/selector"
+	"github.com/pakkasys/fluidapi/endpoint/update"
+)
+
+type BatchOperationErrorData struct {
+	Index int `json:"index"`
+}
+
+var UnknownBatchEntityError = api.NewError[BatchOperationErrorData]("UNKNOWN_BATCH_ENTITY")
+var BatchOperationNotAllowedError = api.NewError[BatchOperationErrorData]("BATCH_OPERATION_NOT_ALLOWED")
+var InvalidBatchDataError = api.NewError[BatchOperationErrorData]("INVALID_BATCH_DATA")
+
+type UnknownBatchFieldErrorData struct {
+	Field string `json:"field"`
+}
+
+var UnknownBatchFieldError = api.NewError[UnknownBatchFieldErrorData]("UNKNOWN_BATCH_FIELD")
+
+type TooManyBatchOperationsErrorData struct {
+	MaxOperations int `json:"max_operations"`
+}
+
+var TooManyBatchOperationsError = api.NewError[TooManyBatchOperationsErrorData]("TOO_MANY_BATCH_OPERATIONS")
+
+// BatchOperationType is the type of a batch operation.
+type BatchOperationType string
+
+const (
+	BatchCreate BatchOperationType = "create"
+	BatchUpdate BatchOperationType = "update"
+	BatchDelete BatchOperationType = "delete"
+)
+
+// BatchSelector selects the entities of an update or delete operation.
+type BatchSelector struct {
+	Field     string              `json:"field"`
+	Predicate predicate.Predicate `json:"predicate"`
+	Value     any                 `json:"value"`
+}
+
+// BatchFieldUpdate is a field update of an update operation.
+type BatchFieldUpdate struct {
+	Field string `json:"field"`
+	Value any    `json:"value"`
+}
+
+// BatchOperation is a single operation of a batch.
+type BatchOperation struct {
+	// Entity is the name of the registered entity
+	Entity string `json:"entity"`
+	// Operation is the type of the operation
+	Operation BatchOperationType `json:"operation"`
+	// Data is the entity to create
+	Data json.RawMessage `json:"data,omitempty"`
+	// Selectors select the entities to update or delete
+	Selectors []BatchSelector `json:"selectors,omitempty"`
+	// Updates are the field updates of an update operation
+	Updates []BatchFieldUpdate `json:"updates,omitempty"`
+}
+
+// BatchInput is the input of the batch endpoint.
+type BatchInput struct {
+	Operations []BatchOperation `json:"operations"`
+}
+
+// Validate validates the input.
+func (i BatchInput) Validate() []inputlogic.FieldError {
+	if len(i.Operations) == 0 {
+		return []inputlogic.FieldError{
+			{Field: "operations", Message: "must not be empty"},
+		}
+	}
+
+	var fieldErrors []inputlogic.FieldError
+	for index, operation := range i.Operations {
+		switch operation.Operation {
+		case BatchCreate, BatchUpdate, BatchDelete:
+		default:
+			fieldErrors = append(fieldErrors, inputlogic.FieldError{
+				Field: fmt.Sprintf("operations[%d].operation", index),
+				Message: fmt.Sprintf(
+					"must be one of %s, %s, %s",
+					BatchCreate,
+					BatchUpdate,
+					BatchDelete,
+				),
+			})
+		}
+	}
+	return fieldErrors
+}
+
+// BatchResult is the result of a single batch operation.
+type BatchResult struct {
+	// Status is the HTTP status code of the operation
+	Status int `json:"status"`
+	// Count is the number of updated or deleted entities
+	Count *int64 `json:"count,omitempty"`
+	// Error is the error of a failed operation
+	Error *api.Error[any] `json:"error,omitempty"`
+}
+
+// BatchOutput is the output of the batch endpoint.
+type BatchOutput struct {
+	// Committed tells whether the operations were committed
+	Committed bool `json:"committed"`
+	// Results are the results of the operations in input order
+	Results []BatchResult `json:"results"`
+}
+
+// BatchEntity executes the batch operations of an entity. Operations with a
+// nil function are not allowed.
+type BatchEntity struct {
+	// APIFields maps the API fields of the selectors and updates to database
+	// fields
+	APIFields APIFields
+	// AllowedPredicates are the predicates allowed in the selectors. All
+	// predicates are allowed if empty.
+	AllowedPredicates []predicate.Predicate
+	// Create creates the entity decoded from the data
+	Create func(
+		ctx context.Context,
+		preparer util.Preparer,
+		data json.RawMessage,
+	) error
+	// Update updates the selected entities
+	Update func(
+		ctx context.Context,
+		preparer util.Preparer,
+		selectors []util.Selector,
+		updates []entity.Update,
+	) (int64, error)
+	// Delete deletes the selected entities
+	Delete func(
+		ctx context.Context,
+		preparer util.Preparer,
+		selectors []util.Selector,
+	) (int64, error)
+}
+
+// NewBatchEntity creates a batch entity executing the operations with the
+// entity helpers. Created entities are decoded from the data into T using its
+// JSON field names. Only the fields of apiFields may be set, so the data of a
+// create operation returns UnknownBatchFieldError for any other field of T.
+//
+// Parameters:
+//   - helpers: The entity helpers of the entity.
+//   - apiFields: A mapping of API fields to corresponding database fields.
+//
+// Returns:
+//   - A new BatchEntity.
+func NewBatchEntity[T any](
+	helpers *entity.EntityHelpers[T],
+	apiFields APIFields,
+) *BatchEntity {
+	return &BatchEntity{
+		APIFields: apiFields,
+		Create: func(
+			ctx context.Context,
+			preparer util.Preparer,
+			data json.RawMessage,
+		) error {
+			if err := checkBatchFields(data, apiFields); err != nil {
+				return err
+			}
+			object := new(T)
+			if err := json.Unmarshal(data, object); err != nil {
+				return err
+			}
+			_, err := helpers.CreateEntity(ctx, preparer, object, nil)
+			return err
+		},
+		Update: func(
+			ctx context.Context,
+			preparer util.Preparer,
+			selectors []util.Selector,
+			updates []entity.Update,
+		) (int64, error) {
+			return helpers.UpdateEntities(ctx, preparer, selectors, updates)
+		},
+		Delete: func(
+			ctx context.Context,
+			preparer util.Preparer,
+			selectors []util.Selector,
+		) (int64, error) {
+			return helpers.DeleteEntities(ctx, preparer, selectors, nil)
+		},
+	}
+}
+
+// checkBatchFields returns UnknownBatchFieldError if the data of a create
+// operation has a field that is not an API field.
+func checkBatchFields(data json.RawMessage, apiFields APIFields) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	for field := range fields {
+		if _, ok := apiFields[field]; !ok {
+			return UnknownBatchFieldError.WithData(
+				UnknownBatchFieldErrorData{Field: field},
+			)
+		}
+	}
+	return nil
+}
+
+// BatchConfig configures the batch endpoint.
+type BatchConfig struct {
+	// Entities are the entities available to the operations by name
+	Entities map[string]*BatchEntity
+	// GetTxFn returns the transaction the operations are executed in
+	GetTxFn func(ctx context.Context) (util.Tx, error)
+	// MaxOperations is the maximum number of operations in a batch. There is
+	// no limit if zero.
+	MaxOperations int
+}
+
+// BatchEndpointDefinition creates an endpoint definition executing a list of
+// create, update and delete operations atomically in one managed transaction.
+//
+// The output contains the status code of every operation in input order. If
+// an operation fails, the transaction is rolled back, the failed operation
+// gets the status of its error from expectedErrors and the other operations
+// get http.StatusFailedDependency.
+//
+// Parameters:
+//   - specification: The input specification of the endpoint.
+//   - config: The entities and the transaction of the operations.
+//   - expectedErrors: The expected errors of the operations, e.g.
+//     BatchErrors.
+//   - stackBuilder: A factory function to create a middleware stack
+//     builder.
+//   - opts: Options for configuring the input logic.
+//   - sendFn: Function to send the request.
+//   - options: Additional endpoint options to configure.
+//
+// Returns:
+//   - An Endpoint instance.
+func BatchEndpointDefinition[W any](
+	specification InputSpecification[BatchInput],
+	config BatchConfig,
+	expectedErrors []inputlogic.ExpectedError,
+	stackBuilder StackBuilder,
+	opts inputlogic.Options[BatchInput],
+	sendFn SendFunc[BatchInput, W],
+	options ...EndpointOption[BatchInput, BatchOutput, W],
+) *Endpoint[BatchInput, BatchOutput, W] {
+	callback := func(
+		writer http.ResponseWriter,
+		request *http.Request,
+		input *BatchInput,
+	) (*BatchOutput, error) {
+		return BatchInvoke(request.Context(), input, config, expectedErrors)
+	}
+
+	return GenericEndpointDefinition(
+		specification,
+		callback,
+		expectedErrors,
+		stackBuilder,
+		opts,
+		sendFn,
+		options...,
+	)
+}
+
+// batchOperationFailure signals a failed operation to roll back the
+// transaction.
+type batchOperationFailure struct {
+	index int
+	err   error
+}
+
+func (f *batchOperationFailure) Error() string {
+	return f.err.Error()
+}
+
+// BatchInvoke executes the operations of the batch input in one managed
+// transaction.
+//
+// Parameters:
+//   - ctx: The context to use when getting and setting the transaction.
+//   - input: The batch input.
+//   - config: The entities and the transaction of the operations.
+//   - expectedErrors: The expected errors of the operations.
+//
+// Returns:
+//   - The results of the operations.
+//   - An error if the transaction fails or the batch is too large.
+func BatchInvoke(
+	ctx context.Context,
+	input *BatchInput,
+	config BatchConfig,
+	expectedErrors []inputlogic.ExpectedError,
+) (*BatchOutput, error) {
+	if config.MaxOperations > 0 &&
+		len(input.Operations) > config.MaxOperations {
+		return nil, TooManyBatchOperationsError.WithData(
+			TooManyBatchOperationsErrorData{
+				MaxOperations: config.MaxOperations,
+			},
+		)
+	}
+
+	results := make([]BatchResult, len(input.Operations))
+	_, err := transaction.ExecuteManagedTransaction(
+		ctx,
+		config.GetTxFn,
+		func(ctx context.Context, tx util.Tx) (struct{}, error) {
+			for i := range input.Operations {
+				result, err := executeBatchOperation(
+					ctx,
+					tx,
+					i,
+					&input.Operations[i],
+					config.Entities,
+				)
+				if err != nil {
+					return struct{}{}, &batchOperationFailure{
+						index: i,
+						err:   err,
+					}
+				}
+				results[i] = *result
+			}
+			return struct{}{}, nil
+		},
+	)
+
+	var failure *batchOperationFailure
+	if errors.As(err, &failure) {
+		for i := range results {
+			results[i] = BatchResult{Status: http.StatusFailedDependency}
+		}
+		status, apiError := inputlogic.ErrorHandler{}.Handle(
+			failure.err,
+			expectedErrors,
+		)
+		results[failure.index] = BatchResult{Status: status, Error: apiError}
+		return &BatchOutput{Committed: false, Results: results}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &BatchOutput{Committed: true, Results: results}, nil
+}
+
+func executeBatchOperation(
+	ctx context.Context,
+	preparer util.Preparer,
+	index int,
+	operation *BatchOperation,
+	entities map[string]*BatchEntity,
+) (*BatchResult, error) {
+	errorData := BatchOperationErrorData{Index: index}
+	batchEntity, ok := entities[operation.Entity]
+	if !ok {
+		return nil, UnknownBatchEntityError.WithData(errorData)
+	}
+
+	switch operation.Operation {
+	case BatchCreate:
+		if batchEntity.Create == nil {
+			return nil, BatchOperationNotAllowedError.WithData(errorData)
+		}
+		err := batchEntity.Create(ctx, preparer, operation.Data)
+		var syntaxError *json.SyntaxError
+		var typeError *json.UnmarshalTypeError
+		if errors.As(err, &syntaxError) || errors.As(err, &typeError) {
+			return nil, InvalidBatchDataError.WithData(errorData)
+		}
+		if err != nil {
+			return nil, err
+		}
+		return &BatchResult{Status: http.StatusCreated}, nil
+	case BatchUpdate:
+		if batchEntity.Update == nil {
+			return nil, BatchOperationNotAllowedError.WithData(errorData)
+		}
+		selectors, err := batchEntity.selectors(operation.Selectors)
+		if err != nil {
+			return nil, err
+		}
+		updates := make([]update.Update, len(operation.Updates))
+		for i, fieldUpdate := range operation.Updates {
+			updates[i] = update.Update(fieldUpdate)
+		}
+		dbUpdates, err := update.ToDBUpdates(updates, batchEntity.APIFields)
+		if err != nil {
+			return nil, err
+		}
+		if len(dbUpdates) == 0 {
+			return nil, NeedAtLeastOneUpdateError
+		}
+		count, err := batchEntity.Update(ctx, preparer, selectors, dbUpdates)
+		if err != nil {
+			return nil, err
+		}
+		return &BatchResult{Status: http.StatusOK, Count: &count}, nil
+	case BatchDelete:
+		if batchEntity.Delete == nil {
+			return nil, BatchOperationNotAllowedError.WithData(errorData)
+		}
+		selectors, err := batchEntity.selectors(operation.Selectors)
+		if err != nil {
+			return nil, err
+		}
+		count, err := batchEntity.Delete(ctx, preparer, selectors)
+		if err != nil {
+			return nil, err
+		}
+		return &BatchResult{Status: http.StatusOK, Count: &count}, nil
+	default:
+		return nil, BatchOperationNotAllowedError.WithData(errorData)
+	}
+}
+
+// selectors translates the selectors of an operation to database selectors.
+// At least one selector is required so that an operation cannot affect every
+// row of the table.
+func (e *BatchEntity) selectors(
+	batchSelectors []BatchSelector,
+) ([]util.Selector, error) {
+	allowedPredicates := e.AllowedPredicates
+	if len(allowedPredicates) == 0 {
+		allowedPredicates = predicate.AllPredicates
+	}
+
+	selectors := make([]selector.Selector, len(batchSelectors))
+	for i, batchSelector := range batchSelectors {
+		selectors[i] = selector.Selector{
+			AllowedPredicates: allowedPredicates,
+			Field:             batchSelector.Field,
+			Predicate:         batchSelector.Predicate,
+			Value:             batchSelector.Value,
+		}
+	}
+	dbSelectors, err := selector.ToDBSelectors(selectors, e.APIFields)
+	if err != nil {
+		return nil, err
+	}
+	if len(dbSelectors) == 0 {
+		return nil, NeedAtLeastOneSelectorError
+	}
+	return dbSelectors, nil
+}
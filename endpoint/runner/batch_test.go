@@ -0,0 +1,318 @@
+package runner
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/pakkasys/fluidapi/core/api"
+	"github.com/pakkasys/fluidapi/database/entity"
+	"github.com/pakkasys/fluidapi/database/util"
+	"github.com/pakkasys/fluidapi/database/util/fake"
+	"github.com/pakkasys/fluidapi/endpoint/middleware/inputlogic"
+	"github.com/pakkasys/fluidapi/endpoint/predicate"
+	endpointutil "github.com/pakkasys/fluidapi/endpoint/util"
+	"github.com/stretchr/testify/assert"
+)
+
+type batchUser struct {
+	ID    int    `json:"id"`
+	Name  string `json:"name"`
+	Admin bool   `json:"admin"`
+}
+
+func newBatchConfig() (*fake.DB, BatchConfig) {
+	db := fake.NewDB()
+	db.CreateTable("users", fake.TableOptions{
+		Columns:    []string{"id", "name"},
+		PrimaryKey: "id",
+		UniqueKeys: [][]string{{"name"}},
+	})
+
+	helpers := &entity.EntityHelpers[batchUser]{
+		TableName: "users",
+		InserterFn: func(u *batchUser) ([]string, []any) {
+			return []string{"name"}, []any{u.Name}
+		},
+		SQLUtil: fake.SQLUtil{},
+	}
+	users := NewBatchEntity(helpers, APIFields{
+		"id":   {Table: "users", Column: "id"},
+		"name": {Table: "users", Column: "name"},
+	})
+	readOnly := &BatchEntity{APIFields: users.APIFields}
+
+	return db, BatchConfig{
+		Entities: map[string]*BatchEntity{
+			"users":     users,
+			"read_only": readOnly,
+		},
+		GetTxFn: func(ctx context.Context) (util.Tx, error) {
+			return db.BeginTx(ctx, nil)
+		},
+	}
+}
+
+func idSelector(id int) []BatchSelector {
+	return []BatchSelector{
+		{Field: "id", Predicate: predicate.EQUAL, Value: id},
+	}
+}
+
+// TestBatchInvoke tests executing and committing the operations.
+func TestBatchInvoke(t *testing.T) {
+	db, config := newBatchConfig()
+
+	output, err := BatchInvoke(
+		endpointutil.NewContext(context.Background()),
+		&BatchInput{Operations: []BatchOperation{
+			{
+				Entity:    "users",
+				Operation: BatchCreate,
+				Data:      json.RawMessage(`{"name": "alice"}`),
+			},
+			{
+				Entity:    "users",
+				Operation: BatchCreate,
+				Data:      json.RawMessage(`{"name": "bob"}`),
+			},
+			{
+				Entity:    "users",
+				Operation: BatchUpdate,
+				Selectors: idSelector(1),
+				Updates: []BatchFieldUpdate{
+					{Field: "name", Value: "carol"},
+				},
+			},
+			{
+				Entity:    "users",
+				Operation: BatchDelete,
+				Selectors: idSelector(2),
+			},
+		}},
+		config,
+		BatchErrors,
+	)
+
+	one := int64(1)
+	assert.NoError(t, err)
+	assert.Equal(t, &BatchOutput{
+		Committed: true,
+		Results: []BatchResult{
+			{Status: http.StatusCreated},
+			{Status: http.StatusCreated},
+			{Status: http.StatusOK, Count: &one},
+			{Status: http.StatusOK, Count: &one},
+		},
+	}, output)
+	assert.Equal(
+		t,
+		[]map[string]any{{"id": int64(1), "name": "carol"}},
+		db.Rows("users"),
+	)
+}
+
+// TestBatchInvoke_RollBack tests that a failing operation rolls back the
+// batch.
+func TestBatchInvoke_RollBack(t *testing.T) {
+	db, config := newBatchConfig()
+
+	output, err := BatchInvoke(
+		endpointutil.NewContext(context.Background()),
+		&BatchInput{Operations: []BatchOperation{
+			{
+				Entity:    "users",
+				Operation: BatchCreate,
+				Data:      json.RawMessage(`{"name": "alice"}`),
+			},
+			{
+				Entity:    "users",
+				Operation: BatchCreate,
+				Data:      json.RawMessage(`{"name": "alice"}`),
+			},
+			{
+				Entity:    "users",
+				Operation: BatchDelete,
+				Selectors: idSelector(1),
+			},
+		}},
+		config,
+		BatchErrors,
+	)
+
+	var data any
+	assert.NoError(t, err)
+	assert.Equal(t, &BatchOutput{
+		Committed: false,
+		Results: []BatchResult{
+			{Status: http.StatusFailedDependency},
+			{
+				Status: http.StatusBadRequest,
+				Error:  &api.Error[any]{ID: "DUPLICATE_ENTRY", Data: &data},
+			},
+			{Status: http.StatusFailedDependency},
+		},
+	}, output)
+	assert.Empty(t, db.Rows("users"))
+}
+
+// TestBatchInvoke_OperationErrors tests the errors of invalid operations.
+func TestBatchInvoke_OperationErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		operation BatchOperation
+		expected  string
+	}{
+		{
+			name:      "unknown entity",
+			operation: BatchOperation{Entity: "posts", Operation: BatchDelete},
+			expected:  UnknownBatchEntityError.ID,
+		},
+		{
+			name: "operation not allowed",
+			operation: BatchOperation{
+				Entity:    "read_only",
+				Operation: BatchDelete,
+				Selectors: idSelector(1),
+			},
+			expected: BatchOperationNotAllowedError.ID,
+		},
+		{
+			name: "invalid data",
+			operation: BatchOperation{
+				Entity:    "users",
+				Operation: BatchCreate,
+				Data:      json.RawMessage(`{"name": 1}`),
+			},
+			expected: InvalidBatchDataError.ID,
+		},
+		{
+			name: "no selectors",
+			operation: BatchOperation{
+				Entity:    "users",
+				Operation: BatchDelete,
+			},
+			expected: NeedAtLeastOneSelectorError.ID,
+		},
+		{
+			name: "no updates",
+			operation: BatchOperation{
+				Entity:    "users",
+				Operation: BatchUpdate,
+				Selectors: idSelector(1),
+			},
+			expected: NeedAtLeastOneUpdateError.ID,
+		},
+		{
+			name: "unknown field",
+			operation: BatchOperation{
+				Entity:    "users",
+				Operation: BatchDelete,
+				Selectors: []BatchSelector{
+					{Field: "age", Predicate: predicate.EQUAL, Value: 1},
+				},
+			},
+			expected: "INVALID_DATABASE_SELECTOR_TRANSLATION",
+		},
+	} {
+		_, config := newBatchConfig()
+
+		output, err := BatchInvoke(
+			endpointutil.NewContext(context.Background()),
+			&BatchInput{Operations: []BatchOperation{tc.operation}},
+			config,
+			BatchErrors,
+		)
+
+		assert.NoError(t, err, tc.name)
+		assert.False(t, output.Committed, tc.name)
+		assert.Equal(t, tc.expected, output.Results[0].Error.ID, tc.name)
+	}
+}
+
+// TestBatchInvoke_UnknownField tests that a create operation cannot set a
+// field of the entity that is not an API field.
+func TestBatchInvoke_UnknownField(t *testing.T) {
+	db, config := newBatchConfig()
+
+	output, err := BatchInvoke(
+		endpointutil.NewContext(context.Background()),
+		&BatchInput{Operations: []BatchOperation{
+			{
+				Entity:    "users",
+				Operation: BatchCreate,
+				Data:      json.RawMessage(`{"name": "eve", "admin": true}`),
+			},
+		}},
+		config,
+		BatchErrors,
+	)
+
+	assert.NoError(t, err)
+	assert.False(t, output.Committed)
+	assert.Equal(t, http.StatusBadRequest, output.Results[0].Status)
+	assert.Equal(t, UnknownBatchFieldError.ID, output.Results[0].Error.ID)
+	assert.Equal(
+		t,
+		&UnknownBatchFieldErrorData{Field: "admin"},
+		*output.Results[0].Error.Data,
+	)
+	assert.Empty(t, db.Rows("users"))
+}
+
+// TestBatchInvoke_TooManyOperations tests the operation limit.
+func TestBatchInvoke_TooManyOperations(t *testing.T) {
+	_, config := newBatchConfig()
+	config.MaxOperations = 1
+
+	output, err := BatchInvoke(
+		endpointutil.NewContext(context.Background()),
+		&BatchInput{Operations: make([]BatchOperation, 2)},
+		config,
+		BatchErrors,
+	)
+
+	assert.Nil(t, output)
+	assert.Equal(t, TooManyBatchOperationsError.WithData(
+		TooManyBatchOperationsErrorData{MaxOperations: 1},
+	), err)
+}
+
+// TestBatchInput_Validate tests the validation of the batch input.
+func TestBatchInput_Validate(t *testing.T) {
+	assert.Equal(
+		t,
+		[]inputlogic.FieldError{
+			{Field: "operations", Message: "must not be empty"},
+		},
+		BatchInput{}.Validate(),
+	)
+	assert.Equal(
+		t,
+		[]inputlogic.FieldError{
+			{
+				Field:   "operations[1].operation",
+				Message: "must be one of create, update, delete",
+			},
+		},
+		BatchInput{Operations: []BatchOperation{
+			{Operation: BatchCreate},
+			{Operation: "upsert"},
+		}}.Validate(),
+	)
+}
+
+// TestBatchErrors_OperationNotAllowed tests that operations that are not
+// allowed are rejected as bad requests.
+func TestBatchErrors_OperationNotAllowed(t *testing.T) {
+	status, apiError := inputlogic.ErrorHandler{}.Handle(
+		BatchOperationNotAllowedError.WithData(
+			BatchOperationErrorData{Index: 1},
+		),
+		BatchErrors,
+	)
+
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, BatchOperationNotAllowedError.ID, apiError.ID)
+}
@@ -0,0 +1,343 @@
+package runner
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	"path"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const clientPackagePath = "github.com/pakkasys/fluidapi/core/client"
+
+// ClientMethod is a method of a generated client sending the requests of an
+// endpoint with the SendFunc of its client.
+type ClientMethod struct {
+	// Name of the method, e.g. "CreateUser"
+	Name string
+	// Package is the import path of the package declaring the endpoint
+	Package string
+	// Endpoint is the name of the exported variable of the endpoint in the
+	// package, e.g. "CreateUserEndpoint"
+	Endpoint string
+
+	url        string
+	method     string
+	inputType  reflect.Type
+	outputType reflect.Type
+}
+
+// NewClientMethod returns the client method of an endpoint declared as an
+// exported package variable.
+//
+// Parameters:
+//   - name: The name of the method, e.g. "CreateUser".
+//   - pkg: The import path of the package declaring the endpoint.
+//   - variable: The name of the variable of the endpoint in the package.
+//   - endpoint: The endpoint.
+//
+// Returns:
+//   - The client method.
+func NewClientMethod[I any, O any, W any](
+	name string,
+	pkg string,
+	variable string,
+	endpoint *Endpoint[I, O, W],
+) ClientMethod {
+	return ClientMethod{
+		Name:       name,
+		Package:    pkg,
+		Endpoint:   variable,
+		url:        endpoint.Client.URL,
+		method:     endpoint.Client.Method,
+		inputType:  reflect.TypeFor[I](),
+		outputType: reflect.TypeFor[W](),
+	}
+}
+
+// ClientGenerator generates the Go source of a strongly-typed client of a
+// service, with one method per endpoint taking the input of the endpoint and
+// returning its client.Response. The methods call the SendFunc of the
+// endpoint clients, so the generated client imports the packages declaring
+// the endpoints.
+//
+// The source is typically generated by a program run with go:generate:
+//
+//	source, err := runner.ClientGenerator{
+//		Package: "usersclient",
+//		Methods: []runner.ClientMethod{
+//			runner.NewClientMethod(
+//				"CreateUser",
+//				"example.com/app/users",
+//				"CreateUserEndpoint",
+//				users.CreateUserEndpoint,
+//			),
+//		},
+//	}.Generate()
+type ClientGenerator struct {
+	// Package is the name of the package of the generated client
+	Package string
+	// TypeName is the name of the client type. Defaults to "Client".
+	TypeName string
+	// Methods are the methods of the client
+	Methods []ClientMethod
+}
+
+// Generate returns the formatted Go source of the client.
+//
+// Returns:
+//   - The source, or an error if a name is not a valid exported identifier, a
+//     method name is duplicated or a type cannot be referenced from the
+//     generated package, e.g. an unexported type or an unnamed struct.
+func (g ClientGenerator) Generate() ([]byte, error) {
+	typeName := g.TypeName
+	if typeName == "" {
+		typeName = "Client"
+	}
+	if !token.IsIdentifier(g.Package) {
+		return nil, fmt.Errorf("invalid package name: %q", g.Package)
+	}
+	if !token.IsExported(typeName) || !token.IsIdentifier(typeName) {
+		return nil, fmt.Errorf("invalid client type name: %q", typeName)
+	}
+
+	imports := &clientImports{aliases: map[string]string{}}
+	clientAlias := imports.alias(clientPackagePath)
+
+	var methods bytes.Buffer
+	names := map[string]bool{}
+	for _, method := range g.Methods {
+		if !token.IsExported(method.Name) || !token.IsIdentifier(method.Name) {
+			return nil, fmt.Errorf("invalid method name: %q", method.Name)
+		}
+		if names[method.Name] {
+			return nil, fmt.Errorf("duplicate method name: %q", method.Name)
+		}
+		names[method.Name] = true
+
+		inputType, err := imports.typeExpr(method.inputType)
+		if err != nil {
+			return nil, fmt.Errorf("method %s: %w", method.Name, err)
+		}
+		outputType, err := imports.typeExpr(method.outputType)
+		if err != nil {
+			return nil, fmt.Errorf("method %s: %w", method.Name, err)
+		}
+
+		fmt.Fprintf(
+			&methods,
+			"\n// %s sends a request to the %s %s endpoint.\n",
+			method.Name,
+			method.method,
+			method.url,
+		)
+		fmt.Fprintf(
+			&methods,
+			"func (c *%s) %s(input *%s) (*%s.Response[%s, %s], error) {\n",
+			typeName,
+			method.Name,
+			inputType,
+			clientAlias,
+			inputType,
+			outputType,
+		)
+		fmt.Fprintf(
+			&methods,
+			"\treturn %s.%s.Client.Send(input, c.Host)\n}\n",
+			imports.alias(method.Package),
+			method.Endpoint,
+		)
+	}
+
+	var source bytes.Buffer
+	source.WriteString("// Code generated by fluidapi. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&source, "package %s\n\n", g.Package)
+	source.WriteString("import (\n")
+	for _, importPath := range imports.sorted() {
+		fmt.Fprintf(
+			&source,
+			"\t%s %s\n",
+			imports.aliases[importPath],
+			strconv.Quote(importPath),
+		)
+	}
+	source.WriteString(")\n\n")
+	fmt.Fprintf(
+		&source,
+		"// %s is the client of the service.\n"+
+			"type %s struct {\n"+
+			"\t// Host is the host the requests are sent to\n"+
+			"\tHost string\n"+
+			"}\n\n",
+		typeName,
+		typeName,
+	)
+	fmt.Fprintf(
+		&source,
+		"// New%s returns a new client sending the requests to the host.\n"+
+			"func New%s(host string) *%s {\n"+
+			"\treturn &%s{Host: host}\n"+
+			"}\n",
+		typeName,
+		typeName,
+		typeName,
+		typeName,
+	)
+	source.Write(methods.Bytes())
+
+	return format.Source(source.Bytes())
+}
+
+// clientImports assigns unique aliases to the imported packages.
+type clientImports struct {
+	aliases map[string]string
+	used    map[string]bool
+}
+
+func (i *clientImports) alias(importPath string) string {
+	if alias, ok := i.aliases[importPath]; ok {
+		return alias
+	}
+	if i.used == nil {
+		i.used = map[string]bool{}
+	}
+
+	base := strings.Map(func(r rune) rune {
+		if r == '-' || r == '.' {
+			return '_'
+		}
+		return r
+	}, path.Base(importPath))
+	alias := base
+	for n := 2; i.used[alias] || token.Lookup(alias).IsKeyword(); n++ {
+		alias = base + strconv.Itoa(n)
+	}
+	i.used[alias] = true
+	i.aliases[importPath] = alias
+	return alias
+}
+
+func (i *clientImports) sorted() []string {
+	paths := make([]string, 0, len(i.aliases))
+	for importPath := range i.aliases {
+		paths = append(paths, importPath)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// typeExpr returns the Go expression of a type in the generated package.
+func (i *clientImports) typeExpr(t reflect.Type) (string, error) {
+	if t.Name() == "" {
+		switch t.Kind() {
+		case reflect.Pointer, reflect.Slice:
+			elem, err := i.typeExpr(t.Elem())
+			if err != nil {
+				return "", err
+			}
+			if t.Kind() == reflect.Pointer {
+				return "*" + elem, nil
+			}
+			return "[]" + elem, nil
+		case reflect.Map:
+			key, err := i.typeExpr(t.Key())
+			if err != nil {
+				return "", err
+			}
+			elem, err := i.typeExpr(t.Elem())
+			if err != nil {
+				return "", err
+			}
+			return "map[" + key + "]" + elem, nil
+		case reflect.Interface:
+			if t.NumMethod() == 0 {
+				return "any", nil
+			}
+		}
+		return "", fmt.Errorf("unsupported unnamed type: %s", t)
+	}
+	if t.PkgPath() == "" {
+		return t.Name(), nil
+	}
+	return i.namedTypeExpr(t.PkgPath(), t.Name())
+}
+
+// namedTypeExpr returns the Go expression of a named type, including the
+// type arguments of generic types named by their import paths, e.g.
+// "ListOutput[example.com/app/users.User]".
+func (i *clientImports) namedTypeExpr(
+	importPath string,
+	name string,
+) (string, error) {
+	base, arguments, generic := strings.Cut(name, "[")
+	if !token.IsExported(base) {
+		return "", fmt.Errorf("unexported type: %s.%s", importPath, base)
+	}
+	expr := i.alias(importPath) + "." + base
+	if !generic {
+		return expr, nil
+	}
+
+	var argumentExprs []string
+	for _, argument := range splitTypeArguments(
+		strings.TrimSuffix(arguments, "]"),
+	) {
+		argumentExpr, err := i.typeNameExpr(argument)
+		if err != nil {
+			return "", err
+		}
+		argumentExprs = append(argumentExprs, argumentExpr)
+	}
+	return expr + "[" + strings.Join(argumentExprs, ", ") + "]", nil
+}
+
+// typeNameExpr returns the Go expression of a type argument in the name of a
+// generic type.
+func (i *clientImports) typeNameExpr(name string) (string, error) {
+	switch {
+	case strings.HasPrefix(name, "*"):
+		elem, err := i.typeNameExpr(name[1:])
+		return "*" + elem, err
+	case strings.HasPrefix(name, "[]"):
+		elem, err := i.typeNameExpr(name[2:])
+		return "[]" + elem, err
+	case strings.HasPrefix(name, "map["), strings.HasPrefix(name, "struct"),
+		strings.HasPrefix(name, "func"):
+		return "", fmt.Errorf("unsupported type argument: %s", name)
+	case name == "interface {}":
+		return "any", nil
+	}
+
+	head, _, _ := strings.Cut(name, "[")
+	slash := strings.LastIndex(head, "/")
+	dot := strings.Index(head[slash+1:], ".")
+	if dot < 0 {
+		return name, nil
+	}
+	dot += slash + 1
+	return i.namedTypeExpr(name[:dot], name[dot+1:])
+}
+
+// splitTypeArguments splits the type arguments of a generic type name.
+func splitTypeArguments(arguments string) []string {
+	var split []string
+	depth, start := 0, 0
+	for i, r := range arguments {
+		switch r {
+		case '[':
+			depth++
+		case ']':
+			depth--
+		case ',':
+			if depth == 0 {
+				split = append(split, arguments[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(split, arguments[start:])
+}
@@ -0,0 +1,195 @@
+package runner
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/pakkasys/fluidapi/core/client"
+	"github.com/pakkasys/fluidapi/endpoint/page"
+	"github.com/stretchr/testify/assert"
+)
+
+type clientGenInput struct{}
+
+func clientGenEndpoint[I any, W any](
+	method string,
+	url string,
+) *Endpoint[I, I, W] {
+	return &Endpoint[I, I, W]{
+		Client: &Client[I, I, W]{
+			URL:    url,
+			Method: method,
+			Send: func(input *I, host string) (*client.Response[I, W], error) {
+				return nil, nil
+			},
+		},
+	}
+}
+
+// TestClientGenerator_Generate tests generating the source of a client.
+func TestClientGenerator_Generate(t *testing.T) {
+	source, err := ClientGenerator{
+		Package: "usersclient",
+		Methods: []ClientMethod{
+			NewClientMethod(
+				"GetUser",
+				"example.com/app/users",
+				"GetUserEndpoint",
+				clientGenEndpoint[page.Page, page.Page](
+					http.MethodGet,
+					"/users/{id}",
+				),
+			),
+			NewClientMethod(
+				"ListUsers",
+				"example.com/app/users",
+				"ListUsersEndpoint",
+				clientGenEndpoint[page.Page, ListOutput[*page.Page]](
+					http.MethodGet,
+					"/users",
+				),
+			),
+		},
+	}.Generate()
+
+	assert.NoError(t, err)
+	assert.Equal(t, `// Code generated by fluidapi. DO NOT EDIT.
+
+package usersclient
+
+import (
+	users "example.com/app/users"
+	client "github.com/pakkasys/fluidapi/core/client"
+	page "github.com/pakkasys/fluidapi/endpoint/page"
+	runner "github.com/pakkasys/fluidapi/endpoint/runner"
+)
+
+// Client is the client of the service.
+type Client struct {
+	// Host is the host the requests are sent to
+	Host string
+}
+
+// NewClient returns a new client sending the requests to the host.
+func NewClient(host string) *Client {
+	return &Client{Host: host}
+}
+
+// GetUser sends a request to the GET /users/{id} endpoint.
+func (c *Client) GetUser(input *page.Page) (*client.Response[page.Page, page.Page], error) {
+	return users.GetUserEndpoint.Client.Send(input, c.Host)
+}
+
+// ListUsers sends a request to the GET /users endpoint.
+func (c *Client) ListUsers(input *page.Page) (*client.Response[page.Page, runner.ListOutput[*page.Page]], error) {
+	return users.ListUsersEndpoint.Client.Send(input, c.Host)
+}
+`, string(source))
+}
+
+// TestClientGenerator_Generate_Errors tests the errors of invalid names and
+// types.
+func TestClientGenerator_Generate_Errors(t *testing.T) {
+	endpoint := clientGenEndpoint[page.Page, page.Page]("GET", "/")
+	method := NewClientMethod("Get", "example.com/app", "Endpoint", endpoint)
+	tests := []struct {
+		name      string
+		generator ClientGenerator
+		err       string
+	}{
+		{
+			name:      "package",
+			generator: ClientGenerator{Package: "my-client"},
+			err:       `invalid package name: "my-client"`,
+		},
+		{
+			name: "type",
+			generator: ClientGenerator{
+				Package:  "client",
+				TypeName: "client",
+			},
+			err: `invalid client type name: "client"`,
+		},
+		{
+			name: "method",
+			generator: ClientGenerator{
+				Package: "client",
+				Methods: []ClientMethod{{Name: "get"}},
+			},
+			err: `invalid method name: "get"`,
+		},
+		{
+			name: "duplicate method",
+			generator: ClientGenerator{
+				Package: "client",
+				Methods: []ClientMethod{method, method},
+			},
+			err: `duplicate method name: "Get"`,
+		},
+		{
+			name: "unnamed type",
+			generator: ClientGenerator{
+				Package: "client",
+				Methods: []ClientMethod{NewClientMethod(
+					"Get",
+					"example.com/app",
+					"Endpoint",
+					clientGenEndpoint[struct{}, page.Page]("GET", "/"),
+				)},
+			},
+			err: "method Get: unsupported unnamed type: struct {}",
+		},
+		{
+			name: "unexported type",
+			generator: ClientGenerator{
+				Package: "client",
+				Methods: []ClientMethod{NewClientMethod(
+					"Get",
+					"example.com/app",
+					"Endpoint",
+					clientGenEndpoint[clientGenInput, page.Page]("GET", "/"),
+				)},
+			},
+			err: "method Get: unexported type: " +
+				"github.com/pakkasys/fluidapi/endpoint/runner.clientGenInput",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.generator.Generate()
+
+			assert.EqualError(t, err, tt.err)
+		})
+	}
+}
+
+// TestClientImports_typeExpr tests the expressions of the types and the
+// aliases of the imported packages.
+func TestClientImports_typeExpr(t *testing.T) {
+	imports := &clientImports{aliases: map[string]string{}}
+	imports.alias("example.com/other/page")
+
+	tests := []struct {
+		value    any
+		expected string
+	}{
+		{value: "", expected: "string"},
+		{value: []*page.Page{}, expected: "[]*page2.Page"},
+		{value: map[string]any{}, expected: "map[string]any"},
+		{
+			value:    ListOutput[[]page.Page]{},
+			expected: "runner.ListOutput[[]page2.Page]",
+		},
+	}
+
+	for _, tt := range tests {
+		expr, err := imports.typeExpr(reflect.TypeOf(tt.value))
+
+		assert.NoError(t, err)
+		assert.Equal(t, tt.expected, expr)
+	}
+	assert.Equal(t, "type_2", imports.alias("example.com/type-2"))
+	assert.Equal(t, "go2", imports.alias("example.com/go"))
+}
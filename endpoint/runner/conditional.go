@@ -0,0 +1,300 @@
+package runner
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/pakkasys/fluidapi/core/api"
+	"github.com/pakkasys/fluidapi/database/entity"
+	"github.com/pakkasys/fluidapi/endpoint/etag"
+	"github.com/pakkasys/fluidapi/endpoint/middleware/inputlogic"
+)
+
+// VersionFunc represents a function type returning the version of an entity
+// from which its entity tag is derived, e.g. a version number or the
+// updated_at field.
+type VersionFunc[Entity any] func(entity *Entity) any
+
+// ConditionalGetByIDEndpointDefinition creates an endpoint definition for a
+// GET request retrieving a single entity by its ID, like
+// GetByIDEndpointDefinition, with an ETag header derived from the version of
+// the entity. Requests with a matching If-None-Match header are answered with
+// 304 Not Modified.
+//
+// Parameters:
+//   - specification: The input specification for the GET request.
+//   - getEntityFn: Function to retrieve the entity from the database.
+//   - entityNotFoundFn: Function returning the error used when the entity
+//     does not exist. EntityNotFoundError is used if nil.
+//   - versionFn: Function returning the version of the entity.
+//   - toOutputFn: Function to convert the entity to output format.
+//   - expectedErrors: A list of expected errors to handle.
+//   - stackBuilder: A factory function to create a middleware stack
+//     builder.
+//   - opts: Options for configuring the input logic.
+//   - sendFn: Function to send the request.
+//   - options: Additional endpoint options to configure.
+//
+// Returns:
+//   - An Endpoint instance.
+func ConditionalGetByIDEndpointDefinition[I ParseableInput[ParsedGetByIDEndpointInput], O any, E any, W any](
+	specification InputSpecification[I],
+	getEntityFn GetByIDServiceFunc[E],
+	entityNotFoundFn func() error,
+	versionFn VersionFunc[E],
+	toOutputFn ToGetByIDEndpointOutput[E, O],
+	expectedErrors []inputlogic.ExpectedError,
+	stackBuilder StackBuilder,
+	opts inputlogic.Options[I],
+	sendFn SendFunc[I, W],
+	options ...EndpointOption[I, O, W],
+) *Endpoint[I, O, W] {
+	callback := func(
+		writer http.ResponseWriter,
+		request *http.Request,
+		input *I,
+	) (*O, error) {
+		return ConditionalGetByIDInvoke(
+			writer,
+			request,
+			*input,
+			getEntityFn,
+			entityNotFoundFn,
+			versionFn,
+			toOutputFn,
+		)
+	}
+
+	return GenericEndpointDefinition(
+		specification,
+		callback,
+		withNotFoundError(expectedErrors, entityNotFoundFn),
+		stackBuilder,
+		opts,
+		sendFn,
+		options...,
+	)
+}
+
+// ConditionalUpdateEndpointDefinition creates an endpoint definition for an
+// UPDATE request, like UpdateEndpointDefinition, with If-Match
+// preconditions. If the request has an If-Match header, the entity is
+// retrieved and the update fails with inputlogic.PreconditionFailedError
+// and status 412 unless the header matches the entity tag of the entity.
+//
+// Parameters:
+//   - specification: The input specification for the UPDATE request.
+//   - getEntityFn: Function to retrieve the entity from the database.
+//   - entityNotFoundFn: Function returning the error getEntityFn returns
+//     when the entity does not exist, e.g. the EntityNotFoundFn of the
+//     entity helpers. entity.ErrEntityNotFound is always recognized.
+//   - versionFn: Function returning the version of the entity.
+//   - updateEntitiesFn: Function to update entities in the database.
+//   - toOutputFn: Function to convert the update result to output format.
+//   - expectedErrors: A list of expected errors to handle.
+//   - stackBuilder: A factory function to create a middleware stack
+//     builder.
+//   - opts: Options for configuring the input logic.
+//   - sendFn: Function to send the request.
+//   - options: Additional endpoint options to configure.
+//
+// Returns:
+//   - An Endpoint instance.
+func ConditionalUpdateEndpointDefinition[I ParseableInput[ParsedUpdateEndpointInput], O any, E any, W any](
+	specification InputSpecification[I],
+	getEntityFn GetByIDServiceFunc[E],
+	entityNotFoundFn func() error,
+	versionFn VersionFunc[E],
+	updateEntitiesFn UpdateServiceFunc,
+	toOutputFn ToUpdateEndpointOutput[O],
+	expectedErrors []inputlogic.ExpectedError,
+	stackBuilder StackBuilder,
+	opts inputlogic.Options[I],
+	sendFn SendFunc[I, W],
+	options ...EndpointOption[I, O, W],
+) *Endpoint[I, O, W] {
+	callback := func(
+		writer http.ResponseWriter,
+		request *http.Request,
+		input *I,
+	) (*O, error) {
+		return ConditionalUpdateInvoke(
+			writer,
+			request,
+			*input,
+			getEntityFn,
+			entityNotFoundFn,
+			versionFn,
+			updateEntitiesFn,
+			toOutputFn,
+		)
+	}
+
+	return GenericEndpointDefinition(
+		specification,
+		callback,
+		expectedErrors,
+		stackBuilder,
+		opts,
+		sendFn,
+		options...,
+	)
+}
+
+// ConditionalGetByIDInvoke handles the invocation of a GET endpoint
+// retrieving a single entity by its ID with an entity tag. The ETag header is
+// set from the version of the entity, and inputlogic.NotModifiedError is
+// returned if the If-None-Match header of the request matches it.
+//
+// Parameters:
+//   - writer: The HTTP response writer.
+//   - request: The HTTP request.
+//   - input: Input data to the endpoint, implementing ParseableInput.
+//   - serviceFn: Function to retrieve the entity from the database.
+//   - entityNotFoundFn: Function returning the error used when the entity
+//     does not exist. EntityNotFoundError is used if nil.
+//   - versionFn: Function returning the version of the entity.
+//   - toEndpointOutputFn: Function to convert the entity to endpoint output.
+//
+// Returns:
+// - Pointer to the output object or an error.
+func ConditionalGetByIDInvoke[I ParseableInput[ParsedGetByIDEndpointInput], O any, E any](
+	writer http.ResponseWriter,
+	request *http.Request,
+	input I,
+	serviceFn GetByIDServiceFunc[E],
+	entityNotFoundFn func() error,
+	versionFn VersionFunc[E],
+	toEndpointOutputFn ToGetByIDEndpointOutput[E, O],
+) (*O, error) {
+	parsedInput, err := input.Parse(request)
+	if err != nil {
+		return nil, err
+	}
+
+	found, err := serviceFn(
+		request.Context(),
+		entity.GetOptions{
+			Options: entity.Options{
+				Selectors: parsedInput.DatabaseSelectors,
+			},
+		},
+	)
+	if errors.Is(err, entity.ErrEntityNotFound) {
+		return nil, notFoundError(entityNotFoundFn)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if found == nil {
+		return nil, notFoundError(entityNotFoundFn)
+	}
+
+	tag := etag.FromVersion(versionFn(found))
+	writer.Header().Set("ETag", tag)
+	if etag.MatchesNoneMatch(request.Header.Get("If-None-Match"), tag) {
+		return nil, inputlogic.NotModifiedError
+	}
+
+	return toEndpointOutputFn(found), nil
+}
+
+// ConditionalUpdateInvoke handles the invocation of an UPDATE endpoint with
+// If-Match preconditions. If the request has an If-Match header, the entity
+// selected by the input is retrieved and inputlogic.PreconditionFailedError
+// is returned if the entity does not exist or the header does not match its
+// entity tag. The check and the update are not atomic unless both run in the
+// same transaction, e.g. with the transaction middleware.
+//
+// Parameters:
+//   - writer: The HTTP response writer.
+//   - request: The HTTP request.
+//   - input: Input data to the endpoint, implementing ParseableInput.
+//   - getEntityFn: Function to retrieve the entity from the database.
+//   - entityNotFoundFn: Function returning the error getEntityFn returns
+//     when the entity does not exist. entity.ErrEntityNotFound is always
+//     recognized.
+//   - versionFn: Function returning the version of the entity.
+//   - serviceFn: Function to perform update operations in the database.
+//   - toEndpointOutputFn: Function to convert the update result to endpoint
+//     output.
+//
+// Returns:
+// - Pointer to the output object or an error.
+func ConditionalUpdateInvoke[I ParseableInput[ParsedUpdateEndpointInput], O any, E any](
+	writer http.ResponseWriter,
+	request *http.Request,
+	input I,
+	getEntityFn GetByIDServiceFunc[E],
+	entityNotFoundFn func() error,
+	versionFn VersionFunc[E],
+	serviceFn UpdateServiceFunc,
+	toEndpointOutputFn ToUpdateEndpointOutput[O],
+) (*O, error) {
+	parsedInput, err := input.Parse(request)
+	if err != nil {
+		return nil, err
+	}
+
+	if ifMatch := request.Header.Get("If-Match"); ifMatch != "" {
+		found, err := getEntityFn(
+			request.Context(),
+			entity.GetOptions{
+				Options: entity.Options{
+					Selectors: parsedInput.DatabaseSelectors,
+				},
+			},
+		)
+		if isNotFoundError(err, entityNotFoundFn) {
+			return nil, inputlogic.PreconditionFailedError
+		}
+		if err != nil {
+			return nil, err
+		}
+		if found == nil || !etag.MatchesIfMatch(
+			ifMatch,
+			etag.FromVersion(versionFn(found)),
+		) {
+			return nil, inputlogic.PreconditionFailedError
+		}
+	}
+
+	api.RecordOperation(
+		request.Context(),
+		parsedInput.DatabaseSelectors,
+		parsedInput.DatabaseUpdates,
+	)
+	count, err := serviceFn(
+		request.Context(),
+		parsedInput.DatabaseSelectors,
+		parsedInput.DatabaseUpdates,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return toEndpointOutputFn(count), nil
+}
+
+// isNotFoundError returns true if the error is entity.ErrEntityNotFound or
+// the error of the entity not found function. API errors are compared by
+// their IDs.
+func isNotFoundError(err error, entityNotFoundFn func() error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, entity.ErrEntityNotFound) {
+		return true
+	}
+	if entityNotFoundFn == nil {
+		return false
+	}
+	notFoundErr := entityNotFoundFn()
+	if errors.Is(err, notFoundErr) {
+		return true
+	}
+	notFoundAPIErr, ok := notFoundErr.(api.APIError)
+	var apiErr api.APIError
+	return ok && errors.As(err, &apiErr) &&
+		apiErr.GetID() == notFoundAPIErr.GetID()
+}
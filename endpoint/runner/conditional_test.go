@@ -0,0 +1,316 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pakkasys/fluidapi/core/api"
+	"github.com/pakkasys/fluidapi/core/client"
+	"github.com/pakkasys/fluidapi/database/entity"
+	"github.com/pakkasys/fluidapi/database/util"
+	"github.com/pakkasys/fluidapi/endpoint/etag"
+	"github.com/pakkasys/fluidapi/endpoint/middleware"
+	"github.com/pakkasys/fluidapi/endpoint/middleware/inputlogic"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type versionedUser struct {
+	Name    string
+	Version int
+}
+
+func userVersion(user *versionedUser) any {
+	return user.Version
+}
+
+func getVersionedUser(
+	ctx context.Context,
+	opts entity.GetOptions,
+) (*versionedUser, error) {
+	if opts.Selectors[0].Value != "1" {
+		return nil, nil
+	}
+	return &versionedUser{Name: "user 1", Version: 3}, nil
+}
+
+// TestConditionalGetByIDInvoke tests setting the ETag header and answering
+// matching If-None-Match headers.
+func TestConditionalGetByIDInvoke(t *testing.T) {
+	tag := etag.FromVersion(3)
+	tests := []struct {
+		name        string
+		id          string
+		ifNoneMatch string
+		wantOutput  string
+		wantErr     error
+	}{
+		{"No header", "1", "", "user 1", nil},
+		{"Changed", "1", etag.FromVersion(2), "user 1", nil},
+		{"Not modified", "1", tag, "", inputlogic.NotModifiedError},
+		{"Not found", "2", tag, "", EntityNotFoundError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/users/"+tt.id, nil)
+			req.SetPathValue("id", tt.id)
+			if tt.ifNoneMatch != "" {
+				req.Header.Set("If-None-Match", tt.ifNoneMatch)
+			}
+			rr := httptest.NewRecorder()
+
+			output, err := ConditionalGetByIDInvoke(
+				rr,
+				req,
+				MockParseableGetByIDInput{},
+				getVersionedUser,
+				nil,
+				userVersion,
+				func(user *versionedUser) *string { return &user.Name },
+			)
+
+			assert.Equal(t, tt.wantErr, err)
+			if tt.wantOutput == "" {
+				assert.Nil(t, output)
+			} else {
+				assert.Equal(t, tt.wantOutput, *output)
+				assert.Equal(t, tag, rr.Header().Get("ETag"))
+			}
+		})
+	}
+}
+
+// TestConditionalUpdateInvoke tests the If-Match preconditions of updates.
+func TestConditionalUpdateInvoke(t *testing.T) {
+	tests := []struct {
+		name        string
+		id          string
+		ifMatch     string
+		wantUpdated bool
+		wantErr     error
+	}{
+		{"No header", "1", "", true, nil},
+		{"Match", "1", etag.FromVersion(3), true, nil},
+		{"Any", "1", "*", true, nil},
+		{
+			"Mismatch",
+			"1",
+			etag.FromVersion(2),
+			false,
+			inputlogic.PreconditionFailedError,
+		},
+		{"Not found", "2", "*", false, inputlogic.PreconditionFailedError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockHelper := new(MockHelper)
+			mockInput := MockParseableUpdateInputInvoke{helper: mockHelper}
+			mockHelper.On("Parse").Return(&ParsedUpdateEndpointInput{
+				DatabaseSelectors: util.Selectors{
+					{Field: "id", Predicate: util.EQUAL, Value: tt.id},
+				},
+			}, nil)
+			req := httptest.NewRequest(http.MethodPut, "/users", nil)
+			if tt.ifMatch != "" {
+				req.Header.Set("If-Match", tt.ifMatch)
+			}
+
+			updated := false
+			output, err := ConditionalUpdateInvoke(
+				httptest.NewRecorder(),
+				req,
+				mockInput,
+				getVersionedUser,
+				nil,
+				userVersion,
+				func(
+					ctx context.Context,
+					databaseSelectors []util.Selector,
+					databaseUpdates []entity.Update,
+				) (int64, error) {
+					updated = true
+					return 1, nil
+				},
+				MockToUpdateEndpointOutput,
+			)
+
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.wantUpdated, updated)
+			if tt.wantErr == nil {
+				assert.Equal(t, "update successful", *output)
+			}
+		})
+	}
+}
+
+// TestConditionalUpdateInvoke_GetError tests that the error of retrieving the
+// entity is returned.
+func TestConditionalUpdateInvoke_GetError(t *testing.T) {
+	mockHelper := new(MockHelper)
+	mockInput := MockParseableUpdateInputInvoke{helper: mockHelper}
+	mockHelper.On("Parse").Return(&ParsedUpdateEndpointInput{}, nil)
+	req := httptest.NewRequest(http.MethodPut, "/users", nil)
+	req.Header.Set("If-Match", "*")
+	getErr := errors.New("get error")
+
+	output, err := ConditionalUpdateInvoke(
+		httptest.NewRecorder(),
+		req,
+		mockInput,
+		func(
+			ctx context.Context,
+			opts entity.GetOptions,
+		) (*versionedUser, error) {
+			return nil, getErr
+		},
+		nil,
+		userVersion,
+		func(
+			ctx context.Context,
+			databaseSelectors []util.Selector,
+			databaseUpdates []entity.Update,
+		) (int64, error) {
+			t.Fatal("update should not be called")
+			return 0, nil
+		},
+		MockToUpdateEndpointOutput,
+	)
+
+	assert.Nil(t, output)
+	assert.Equal(t, getErr, err)
+}
+
+// TestConditionalUpdateInvoke_GetNotFound tests that the not found errors of
+// retrieving the entity fail the precondition.
+func TestConditionalUpdateInvoke_GetNotFound(t *testing.T) {
+	customNotFound := api.NewError[any]("USER_NOT_FOUND")
+	tests := []struct {
+		name             string
+		getErr           error
+		entityNotFoundFn func() error
+	}{
+		{"Entity not found", entity.ErrEntityNotFound, nil},
+		{
+			"Custom not found",
+			customNotFound,
+			func() error { return customNotFound },
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockHelper := new(MockHelper)
+			mockInput := MockParseableUpdateInputInvoke{helper: mockHelper}
+			mockHelper.On("Parse").Return(&ParsedUpdateEndpointInput{}, nil)
+			req := httptest.NewRequest(http.MethodPut, "/users", nil)
+			req.Header.Set("If-Match", "*")
+
+			output, err := ConditionalUpdateInvoke(
+				httptest.NewRecorder(),
+				req,
+				mockInput,
+				func(
+					ctx context.Context,
+					opts entity.GetOptions,
+				) (*versionedUser, error) {
+					return nil, tt.getErr
+				},
+				tt.entityNotFoundFn,
+				userVersion,
+				func(
+					ctx context.Context,
+					databaseSelectors []util.Selector,
+					databaseUpdates []entity.Update,
+				) (int64, error) {
+					t.Fatal("update should not be called")
+					return 0, nil
+				},
+				MockToUpdateEndpointOutput,
+			)
+
+			assert.Nil(t, output)
+			assert.Equal(t, inputlogic.PreconditionFailedError, err)
+		})
+	}
+}
+
+// TestConditionalGetByIDEndpointDefinition tests answering conditional
+// requests through the endpoint definition.
+func TestConditionalGetByIDEndpointDefinition(t *testing.T) {
+	specification := InputSpecification[MockParseableGetByIDInput]{
+		URL:    "/users/{id}",
+		Method: http.MethodGet,
+		InputFactory: func() *MockParseableGetByIDInput {
+			return new(MockParseableGetByIDInput)
+		},
+	}
+
+	stackBuilder := new(MockStackBuilder)
+	stackBuilder.On("MustAddMiddleware", mock.Anything).Return(stackBuilder)
+	stackBuilder.On("Build").Return(middleware.Stack(stackBuilder.middlewares))
+
+	sendFn := func(
+		input *MockParseableGetByIDInput,
+		host string,
+	) (*client.Response[MockParseableGetByIDInput, any], error) {
+		return nil, nil
+	}
+
+	mockObjectPicker := new(MockObjectPicker[MockParseableGetByIDInput])
+	mockObjectPicker.On(
+		"PickObject",
+		mock.Anything,
+		mock.Anything,
+		mock.Anything,
+	).
+		Return(&MockParseableGetByIDInput{}, nil)
+
+	mockOutputHandler := new(MockOutputHandler)
+	mockOutputHandler.On(
+		"ProcessOutput",
+		mock.Anything,
+		mock.Anything,
+		mock.MatchedBy(func(output *string) bool {
+			return *output == "user 1"
+		}),
+		nil,
+		http.StatusOK,
+	).Return(nil).Once()
+
+	endpoint := ConditionalGetByIDEndpointDefinition(
+		specification,
+		getVersionedUser,
+		nil,
+		userVersion,
+		func(user *versionedUser) *string { return &user.Name },
+		nil,
+		stackBuilder,
+		inputlogic.Options[MockParseableGetByIDInput]{
+			ObjectPicker:  mockObjectPicker,
+			OutputHandler: mockOutputHandler,
+		},
+		sendFn,
+	)
+
+	mux := http.NewServeMux()
+	mux.Handle(
+		"/users/{id}",
+		api.ApplyMiddlewares(
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}),
+			endpoint.Definition.MiddlewareStack.Middlewares()...,
+		),
+	)
+
+	first := httptest.NewRecorder()
+	mux.ServeHTTP(first, httptest.NewRequest(http.MethodGet, "/users/1", nil))
+	req := httptest.NewRequest(http.MethodGet, "/users/1", nil)
+	req.Header.Set("If-None-Match", first.Header().Get("ETag"))
+	second := httptest.NewRecorder()
+	mux.ServeHTTP(second, req)
+
+	assert.Equal(t, etag.FromVersion(3), first.Header().Get("ETag"))
+	assert.Equal(t, http.StatusNotModified, second.Code)
+	mockOutputHandler.AssertExpectations(t)
+}
@@ -0,0 +1,40 @@
+package runner
+
+import (
+	"context"
+
+	"github.com/pakkasys/fluidapi/database/entity"
+	"github.com/pakkasys/fluidapi/database/util"
+)
+
+// CachedGetCountFunc wraps a GetCountFunc with a count cache. The counts are
+// keyed by the table, the selectors and the joins, so the wrapped function
+// must not depend on other request data, e.g. the tenant of the request.
+//
+// Parameters:
+//   - getCountFn: The function to get the count of entities.
+//   - countCache: The cache for the counts.
+//   - table: The table counted.
+//
+// Returns:
+//   - A GetCountFunc serving the counts from the cache.
+func CachedGetCountFunc(
+	getCountFn GetCountFunc,
+	countCache *entity.CountCache,
+	table string,
+) GetCountFunc {
+	return func(
+		ctx context.Context,
+		selectors []util.Selector,
+		joins []util.Join,
+	) (int, error) {
+		return countCache.Count(
+			ctx,
+			table,
+			entity.DBOptionsCount{Selectors: selectors, Joins: joins},
+			func(ctx context.Context) (int, error) {
+				return getCountFn(ctx, selectors, joins)
+			},
+		)
+	}
+}
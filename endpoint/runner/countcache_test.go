@@ -0,0 +1,42 @@
+package runner
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pakkasys/fluidapi/database/cache"
+	"github.com/pakkasys/fluidapi/database/entity"
+	"github.com/pakkasys/fluidapi/database/util"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCachedGetCountFunc tests that counts with the same selectors are served
+// from the cache.
+func TestCachedGetCountFunc(t *testing.T) {
+	calls := 0
+	getCountFn := CachedGetCountFunc(
+		func(
+			ctx context.Context,
+			selectors []util.Selector,
+			joins []util.Join,
+		) (int, error) {
+			calls++
+			return 10 + len(selectors), nil
+		},
+		entity.NewCountCache(cache.NewMemoryCache(), time.Minute, 0),
+		"users",
+	)
+
+	selectors := []util.Selector{{Field: "age", Value: 20}}
+	for range 2 {
+		count, err := getCountFn(context.Background(), selectors, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, 11, count)
+	}
+	count, err := getCountFn(context.Background(), nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 10, count)
+
+	assert.Equal(t, 2, calls)
+}
@@ -0,0 +1,285 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+
+	"github.com/pakkasys/fluidapi/database/entity"
+	databaseutil "github.com/pakkasys/fluidapi/database/util"
+	"github.com/pakkasys/fluidapi/endpoint/order"
+	"github.com/pakkasys/fluidapi/endpoint/page"
+)
+
+// CursorValuesFunc returns the values of the API order fields of an entity,
+// which are used as the keys of the cursors of the response.
+type CursorValuesFunc[Entity any] func(entity *Entity) map[string]any
+
+// ToCursorGetEndpointOutput represents a function type to convert service
+// output and the cursors of the next and the previous pages to endpoint
+// output.
+type ToCursorGetEndpointOutput[ServiceOutput any, EndpointOutput any] func(
+	froms []ServiceOutput,
+	cursors page.Cursors,
+) *EndpointOutput
+
+// CursorGetInvoke handles the invocation of a GET endpoint paginated with
+// cursors. The parsed orders must end in a unique field, e.g. the ID. One row
+// more than the page limit is retrieved to find out whether there is a next
+// page.
+//
+// Parameters:
+//   - writer: The HTTP response writer.
+//   - request: The HTTP request.
+//   - input: Input data to the endpoint, implementing ParseableInput.
+//   - serviceFn: Function to retrieve entities from the database.
+//   - valuesFn: Function returning the values of the order fields of an
+//     entity.
+//   - toEndpointOutputFn: Function to convert service output and the cursors
+//     to endpoint output.
+//
+// Returns:
+// - Pointer to the output object or an error.
+func CursorGetInvoke[I ParseableInput[ParsedGetEndpointInput], O any, E any](
+	writer http.ResponseWriter,
+	request *http.Request,
+	input I,
+	serviceFn GetServiceFunc[E],
+	valuesFn CursorValuesFunc[E],
+	toEndpointOutputFn ToCursorGetEndpointOutput[E, O],
+) (*O, error) {
+	parsedInput, err := input.Parse(request)
+	if err != nil {
+		return nil, err
+	}
+	parsedInput.CheckDeprecations(request.Context())
+
+	entities, cursors, err := runCursorGetService(
+		request.Context(),
+		parsedInput,
+		serviceFn,
+		valuesFn,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return toEndpointOutputFn(entities, cursors), nil
+}
+
+// runCursorGetService retrieves the entities of a page paginated with cursors
+// and returns them in the orders of the request with the cursors of the next
+// and the previous pages.
+func runCursorGetService[E any](
+	ctx context.Context,
+	parsedInput *ParsedGetEndpointInput,
+	serviceFn GetServiceFunc[E],
+	valuesFn CursorValuesFunc[E],
+) ([]E, page.Cursors, error) {
+	if len(parsedInput.CursorOrders) == 0 {
+		return nil, page.Cursors{}, fmt.Errorf(
+			"cursor pagination requires orders",
+		)
+	}
+
+	limit := parsedInput.Page.Limit
+	entities, err := serviceFn(
+		ctx,
+		entity.GetOptions{
+			Options: entity.Options{
+				Selectors:   parsedInput.DatabaseSelectors,
+				Orders:      parsedInput.Orders,
+				Page:        &page.Page{Limit: limit + 1},
+				Joins:       parsedInput.Joins,
+				Projections: parsedInput.Projections,
+			},
+		},
+	)
+	if err != nil {
+		return nil, page.Cursors{}, err
+	}
+
+	hasMore := len(entities) > limit
+	if hasMore {
+		entities = entities[:limit]
+	}
+	before := parsedInput.Cursor != nil && parsedInput.Cursor.Before
+	if before {
+		slices.Reverse(entities)
+	}
+	if err := runPreloads(ctx, parsedInput, entities); err != nil {
+		return nil, page.Cursors{}, err
+	}
+
+	cursors, err := responseCursors(
+		parsedInput,
+		entities,
+		valuesFn,
+		before || hasMore,
+		(before && hasMore) || (!before && parsedInput.Cursor != nil),
+	)
+	if err != nil {
+		return nil, page.Cursors{}, err
+	}
+	return entities, cursors, nil
+}
+
+// responseCursors returns the cursors after the last and before the first
+// entity.
+func responseCursors[E any](
+	parsedInput *ParsedGetEndpointInput,
+	entities []E,
+	valuesFn CursorValuesFunc[E],
+	hasNext bool,
+	hasPrevious bool,
+) (page.Cursors, error) {
+	var cursors page.Cursors
+	if len(entities) == 0 {
+		return cursors, nil
+	}
+
+	var err error
+	if hasNext {
+		cursors.Next, err = encodeCursor(
+			parsedInput.CursorOrders,
+			valuesFn(&entities[len(entities)-1]),
+			false,
+		)
+		if err != nil {
+			return page.Cursors{}, err
+		}
+	}
+	if hasPrevious {
+		cursors.Previous, err = encodeCursor(
+			parsedInput.CursorOrders,
+			valuesFn(&entities[0]),
+			true,
+		)
+		if err != nil {
+			return page.Cursors{}, err
+		}
+	}
+	return cursors, nil
+}
+
+func encodeCursor(
+	orders []order.Order,
+	values map[string]any,
+	before bool,
+) (string, error) {
+	cursor := &page.Cursor{Before: before}
+	for _, o := range orders {
+		value, ok := values[o.Field]
+		if !ok || value == nil {
+			return "", fmt.Errorf("missing cursor value for field: %s", o.Field)
+		}
+		cursor.Keys = append(cursor.Keys, page.CursorKey{
+			Field:      o.Field,
+			Descending: isDescending(o.Direction),
+			Value:      value,
+		})
+	}
+	return cursor.Encode()
+}
+
+// parseCursor decodes the cursor token and returns the orders of the cursor.
+// The orders of the request must be empty or equal the orders of the cursor.
+func parseCursor(
+	token string,
+	orders []order.Order,
+) (*page.Cursor, []order.Order, error) {
+	cursor, err := page.DecodeCursor(token)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cursorOrders := make([]order.Order, len(cursor.Keys))
+	for i, key := range cursor.Keys {
+		if slices.ContainsFunc(cursorOrders[:i], func(o order.Order) bool {
+			return o.Field == key.Field
+		}) {
+			return nil, nil, page.InvalidCursorError
+		}
+		cursorOrders[i] = order.Order{
+			Field:     key.Field,
+			Direction: order.DIRECTION_ASC,
+		}
+		if key.Descending {
+			cursorOrders[i].Direction = order.DIRECTION_DESC
+		}
+	}
+
+	if len(orders) == 0 {
+		return cursor, cursorOrders, nil
+	}
+	if !slices.EqualFunc(orders, cursorOrders, func(a, b order.Order) bool {
+		return a.Field == b.Field &&
+			isDescending(a.Direction) == isDescending(b.Direction)
+	}) {
+		return nil, nil, page.InvalidCursorError
+	}
+	return cursor, cursorOrders, nil
+}
+
+// cursorDBOrders returns the database orders of the query. The directions are
+// reversed for the rows before the cursor, so that the nearest rows are
+// retrieved first.
+func cursorDBOrders(
+	cursor *page.Cursor,
+	dbOrders []databaseutil.Order,
+) []databaseutil.Order {
+	if !cursor.Before {
+		return dbOrders
+	}
+	reversed := make([]databaseutil.Order, len(dbOrders))
+	for i, o := range dbOrders {
+		reversed[i] = o
+		if o.Direction == databaseutil.OrderDesc {
+			reversed[i].Direction = databaseutil.OrderAsc
+		} else {
+			reversed[i].Direction = databaseutil.OrderDesc
+		}
+	}
+	return reversed
+}
+
+// keysetSelector returns the selector of the rows following the cursor in the
+// database orders of the query, e.g. for the orders (a ASC, b DESC):
+// a > ? OR (a = ? AND b < ?).
+func keysetSelector(
+	cursor *page.Cursor,
+	dbOrders []databaseutil.Order,
+) databaseutil.Selector {
+	groups := make([][]databaseutil.Selector, len(dbOrders))
+	for i, o := range dbOrders {
+		group := make([]databaseutil.Selector, 0, i+1)
+		for j := range i {
+			group = append(group, databaseutil.Selector{
+				Table:     dbOrders[j].Table,
+				Field:     dbOrders[j].Field,
+				Predicate: databaseutil.EQUAL,
+				Value:     cursor.Keys[j].Value,
+			})
+		}
+		predicate := databaseutil.GREATER
+		if o.Direction == databaseutil.OrderDesc {
+			predicate = databaseutil.LESS
+		}
+		groups[i] = append(group, databaseutil.Selector{
+			Table:     o.Table,
+			Field:     o.Field,
+			Predicate: predicate,
+			Value:     cursor.Keys[i].Value,
+		})
+	}
+	return databaseutil.Selector{
+		Predicate: databaseutil.OR,
+		Value:     groups,
+	}
+}
+
+func isDescending(direction order.OrderDirection) bool {
+	return order.DirectionDatabaseTranslations[direction] ==
+		databaseutil.OrderDesc
+}
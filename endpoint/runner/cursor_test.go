@@ -0,0 +1,226 @@
+package runner
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pakkasys/fluidapi/database/entity"
+	"github.com/pakkasys/fluidapi/database/util"
+	"github.com/pakkasys/fluidapi/endpoint/middleware/inputlogic"
+	"github.com/pakkasys/fluidapi/endpoint/order"
+	"github.com/pakkasys/fluidapi/endpoint/page"
+	"github.com/stretchr/testify/assert"
+)
+
+var cursorAPIFields = APIFields{
+	"name": {Table: "user", Column: "name"},
+	"id":   {Table: "user", Column: "id"},
+}
+
+var cursorOrders = []order.Order{
+	{Field: "name", Direction: order.DIRECTION_ASC},
+	{Field: "id", Direction: order.DIRECTION_DESC},
+}
+
+type cursorUser struct {
+	ID   int64
+	Name string
+}
+
+func cursorUserValues(user *cursorUser) map[string]any {
+	return map[string]any{"id": user.ID, "name": user.Name}
+}
+
+// cursorInput is a GET input paginated with cursors.
+type cursorInput struct {
+	orders []order.Order
+	page   *page.Page
+}
+
+func (i cursorInput) Validate() []inputlogic.FieldError {
+	return nil
+}
+
+func (i cursorInput) Parse(r *http.Request) (*ParsedGetEndpointInput, error) {
+	return ParseGetEndpointInput(
+		cursorAPIFields,
+		nil,
+		i.orders,
+		[]string{"name", "id"},
+		i.page,
+		10,
+		false,
+	)
+}
+
+type cursorOutput struct {
+	users   []cursorUser
+	cursors page.Cursors
+}
+
+func cursorInvoke(
+	t *testing.T,
+	input cursorInput,
+	users []cursorUser,
+) (*cursorOutput, entity.GetOptions) {
+	var opts entity.GetOptions
+	output, err := CursorGetInvoke(
+		httptest.NewRecorder(),
+		httptest.NewRequest(http.MethodGet, "/users", nil),
+		input,
+		func(ctx context.Context, o entity.GetOptions) ([]cursorUser, error) {
+			opts = o
+			return users, nil
+		},
+		cursorUserValues,
+		func(froms []cursorUser, cursors page.Cursors) *cursorOutput {
+			return &cursorOutput{users: froms, cursors: cursors}
+		},
+	)
+	assert.NoError(t, err)
+	return output, opts
+}
+
+func decodeTestCursor(t *testing.T, token string) *page.Cursor {
+	cursor, err := page.DecodeCursor(token)
+	assert.NoError(t, err)
+	return cursor
+}
+
+// TestCursorGetInvoke tests paging forwards and backwards with the cursors
+// of the responses.
+func TestCursorGetInvoke(t *testing.T) {
+	// The first page has a next page.
+	output, opts := cursorInvoke(
+		t,
+		cursorInput{orders: cursorOrders, page: &page.Page{Limit: 2}},
+		[]cursorUser{{3, "a"}, {2, "b"}, {1, "c"}},
+	)
+	assert.Equal(t, []cursorUser{{3, "a"}, {2, "b"}}, output.users)
+	assert.Equal(t, 3, opts.Page.Limit)
+	assert.Empty(t, output.cursors.Previous)
+	assert.Equal(t, &page.Cursor{Keys: []page.CursorKey{
+		{Field: "name", Value: "b"},
+		{Field: "id", Descending: true, Value: int64(2)},
+	}}, decodeTestCursor(t, output.cursors.Next))
+
+	// The last page has a previous page.
+	output, opts = cursorInvoke(
+		t,
+		cursorInput{page: &page.Page{Limit: 2, Cursor: output.cursors.Next}},
+		[]cursorUser{{1, "c"}},
+	)
+	assert.Equal(t, []cursorUser{{1, "c"}}, output.users)
+	assert.Empty(t, output.cursors.Next)
+	assert.Equal(t, []util.Order{
+		{Table: "user", Field: "name", Direction: util.OrderAsc},
+		{Table: "user", Field: "id", Direction: util.OrderDesc},
+	}, opts.Orders)
+	where, values := util.WhereClause(opts.Selectors)
+	assert.Equal(
+		t,
+		"WHERE ((`user`.`name` > ?) OR "+
+			"(`user`.`name` = ? AND `user`.`id` < ?))",
+		where,
+	)
+	assert.Equal(t, []any{"b", "b", int64(2)}, values)
+	previous := decodeTestCursor(t, output.cursors.Previous)
+	assert.True(t, previous.Before)
+	assert.Equal(t, "c", previous.Keys[0].Value)
+
+	// The rows before the cursor are retrieved in reverse order.
+	output, opts = cursorInvoke(
+		t,
+		cursorInput{page: &page.Page{
+			Limit:  2,
+			Cursor: output.cursors.Previous,
+		}},
+		[]cursorUser{{2, "b"}, {3, "a"}},
+	)
+	assert.Equal(t, []cursorUser{{3, "a"}, {2, "b"}}, output.users)
+	assert.Equal(t, []util.Order{
+		{Table: "user", Field: "name", Direction: util.OrderDesc},
+		{Table: "user", Field: "id", Direction: util.OrderAsc},
+	}, opts.Orders)
+	where, _ = util.WhereClause(opts.Selectors)
+	assert.Equal(
+		t,
+		"WHERE ((`user`.`name` < ?) OR "+
+			"(`user`.`name` = ? AND `user`.`id` > ?))",
+		where,
+	)
+	assert.Empty(t, output.cursors.Previous)
+	assert.Equal(t, "b", decodeTestCursor(t, output.cursors.Next).Keys[0].Value)
+}
+
+// TestCursorGetInvoke_Errors tests the errors of cursor pagination.
+func TestCursorGetInvoke_Errors(t *testing.T) {
+	service := func(
+		ctx context.Context,
+		opts entity.GetOptions,
+	) ([]cursorUser, error) {
+		return []cursorUser{{1, "a"}, {2, "b"}}, nil
+	}
+	toOutput := func(froms []cursorUser, cursors page.Cursors) *cursorOutput {
+		return &cursorOutput{}
+	}
+	invoke := func(
+		input cursorInput,
+		valuesFn CursorValuesFunc[cursorUser],
+	) error {
+		_, err := CursorGetInvoke(
+			httptest.NewRecorder(),
+			httptest.NewRequest(http.MethodGet, "/users", nil),
+			input,
+			service,
+			valuesFn,
+			toOutput,
+		)
+		return err
+	}
+
+	err := invoke(cursorInput{page: &page.Page{Limit: 1}}, cursorUserValues)
+	assert.EqualError(t, err, "cursor pagination requires orders")
+
+	err = invoke(
+		cursorInput{orders: cursorOrders, page: &page.Page{Limit: 1}},
+		func(user *cursorUser) map[string]any { return nil },
+	)
+	assert.EqualError(t, err, "missing cursor value for field: name")
+}
+
+// TestParseGetEndpointInput_InvalidCursor tests the cursors not matching the
+// orders of the request.
+func TestParseGetEndpointInput_InvalidCursor(t *testing.T) {
+	encode := func(cursor *page.Cursor) string {
+		token, err := cursor.Encode()
+		assert.NoError(t, err)
+		return token
+	}
+	tests := map[string]cursorInput{
+		"malformed": {page: &page.Page{Cursor: "!"}},
+		"other orders": {
+			orders: cursorOrders,
+			page: &page.Page{Cursor: encode(&page.Cursor{
+				Keys: []page.CursorKey{{Field: "id", Value: 1}},
+			})},
+		},
+		"duplicate keys": {page: &page.Page{Cursor: encode(&page.Cursor{
+			Keys: []page.CursorKey{
+				{Field: "id", Value: 1},
+				{Field: "id", Value: 2},
+			},
+		})}},
+	}
+
+	for name, input := range tests {
+		t.Run(name, func(t *testing.T) {
+			parsed, err := input.Parse(nil)
+
+			assert.Nil(t, parsed)
+			assert.ErrorIs(t, err, page.InvalidCursorError)
+		})
+	}
+}
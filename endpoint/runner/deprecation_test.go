@@ -0,0 +1,130 @@
+package runner
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pakkasys/fluidapi/core/api"
+	"github.com/pakkasys/fluidapi/endpoint/middleware"
+	"github.com/pakkasys/fluidapi/endpoint/middleware/inputlogic"
+	"github.com/pakkasys/fluidapi/endpoint/predicate"
+	"github.com/pakkasys/fluidapi/endpoint/selector"
+	"github.com/pakkasys/fluidapi/endpoint/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// deprecatedFilterInput is a GET input filtering by a deprecated field and,
+// within an OR group, with a deprecated predicate.
+type deprecatedFilterInput struct{}
+
+func (i deprecatedFilterInput) Validate() []inputlogic.FieldError {
+	return nil
+}
+
+func (i deprecatedFilterInput) Parse(
+	r *http.Request,
+) (*ParsedGetEndpointInput, error) {
+	allowed := []predicate.Predicate{predicate.EQUAL, predicate.GREATER}
+	deprecated := []predicate.Predicate{predicate.GREATER}
+	return ParseGetEndpointInput(
+		APIFields{
+			"name": {Table: "users", Column: "name", Deprecated: true},
+			"age":  {Table: "users", Column: "age"},
+		},
+		[]selector.Selector{
+			{
+				AllowedPredicates: allowed,
+				Field:             "name",
+				Predicate:         predicate.EQUAL,
+				Value:             "a",
+			},
+			{
+				AllowedPredicates: []predicate.Predicate{predicate.OR},
+				Predicate:         predicate.OR,
+				Value: [][]selector.Selector{{{
+					AllowedPredicates:    allowed,
+					DeprecatedPredicates: deprecated,
+					Field:                "age",
+					Predicate:            predicate.GREATER,
+					Value:                1,
+				}}},
+			},
+		},
+		nil,
+		nil,
+		nil,
+		10,
+		false,
+	)
+}
+
+// TestGetEndpointDefinition_Deprecations tests that the deprecated fields and
+// predicates of the selectors of a GET endpoint are sent as warnings.
+func TestGetEndpointDefinition_Deprecations(t *testing.T) {
+	stackBuilder := new(MockStackBuilder)
+	stackBuilder.On("MustAddMiddleware", mock.Anything).Return(stackBuilder)
+	stackBuilder.On("Build").Return(middleware.Stack{})
+	stackBuilder.MustAddMiddleware(*middleware.DeprecationMiddlewareWrapper())
+
+	mockObjectPicker := new(MockObjectPicker[deprecatedFilterInput])
+	mockObjectPicker.
+		On("PickObject", mock.Anything, mock.Anything, mock.Anything).
+		Return(&deprecatedFilterInput{}, nil)
+
+	mockOutputHandler := new(MockOutputHandler)
+	mockOutputHandler.On(
+		"ProcessOutput",
+		mock.Anything,
+		mock.Anything,
+		mock.Anything,
+		nil,
+		http.StatusOK,
+	).Run(func(args mock.Arguments) {
+		args.Get(0).(http.ResponseWriter).WriteHeader(http.StatusOK)
+	}).Return(nil)
+
+	endpoint := GetEndpointDefinition[
+		deprecatedFilterInput,
+		string,
+		string,
+		any,
+	](
+		InputSpecification[deprecatedFilterInput]{
+			URL:    "/users",
+			Method: http.MethodGet,
+			InputFactory: func() *deprecatedFilterInput {
+				return &deprecatedFilterInput{}
+			},
+		},
+		MockGetServiceFunc,
+		MockGetCountFunc,
+		MockToGetEndpointOutput,
+		nil,
+		stackBuilder,
+		inputlogic.Options[deprecatedFilterInput]{
+			ObjectPicker:  mockObjectPicker,
+			OutputHandler: mockOutputHandler,
+		},
+		nil,
+	)
+	handler := api.ApplyMiddlewares(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}),
+		endpoint.Definition.MiddlewareStack.Middlewares()...,
+	)
+	request := httptest.NewRequest(http.MethodGet, "/users", nil)
+	recorder := httptest.NewRecorder()
+
+	handler.ServeHTTP(
+		recorder,
+		request.WithContext(util.NewContext(request.Context())),
+	)
+
+	assert.Equal(t, "true", recorder.Header().Get("Deprecation"))
+	assert.Equal(t, []string{
+		`299 - "field \"name\" is deprecated"`,
+		`299 - "predicate \">\" is deprecated for field \"age\""`,
+	}, recorder.Header().Values("Warning"))
+	mockOutputHandler.AssertExpectations(t)
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/pakkasys/fluidapi/endpoint/order"
 	"github.com/pakkasys/fluidapi/endpoint/page"
 	"github.com/pakkasys/fluidapi/endpoint/selector"
+	"github.com/pakkasys/fluidapi/endpoint/update"
 )
 
 var CreateErrors []inputlogic.ExpectedError = []inputlogic.ExpectedError{
@@ -49,6 +50,77 @@ var GetErrors []inputlogic.ExpectedError = []inputlogic.ExpectedError{
 		Status:     http.StatusBadRequest,
 		PublicData: true,
 	},
+	{
+		ID:         page.InvalidCursorError.ID,
+		Status:     http.StatusBadRequest,
+		PublicData: true,
+	},
+	{
+		ID:         InvalidFieldError.ID,
+		Status:     http.StatusBadRequest,
+		PublicData: true,
+	},
+	{
+		ID:         InvalidIncludeError.ID,
+		Status:     http.StatusBadRequest,
+		PublicData: true,
+	},
+	{
+		ID:         selector.InvalidFilterError.ID,
+		Status:     http.StatusBadRequest,
+		PublicData: true,
+	},
+}
+
+var AggregateErrors []inputlogic.ExpectedError = []inputlogic.ExpectedError{
+	{
+		ID:         selector.InvalidPredicateError.ID,
+		Status:     http.StatusBadRequest,
+		PublicData: true,
+	},
+	{
+		ID:         selector.PredicateNotAllowedError.ID,
+		Status:     http.StatusBadRequest,
+		PublicData: true,
+	},
+	{
+		ID:         selector.InvalidDatabaseSelectorTranslationError.ID,
+		Status:     http.StatusBadRequest,
+		PublicData: true,
+	},
+	{
+		ID:         selector.InvalidFilterError.ID,
+		Status:     http.StatusBadRequest,
+		PublicData: true,
+	},
+	{
+		ID:         order.InvalidOrderFieldError.ID,
+		Status:     http.StatusBadRequest,
+		PublicData: true,
+	},
+	{
+		ID:         page.MaxPageLimitExceededError.ID,
+		Status:     http.StatusBadRequest,
+		PublicData: true,
+	},
+	{
+		ID:         InvalidDimensionError.ID,
+		Status:     http.StatusBadRequest,
+		PublicData: true,
+	},
+	{
+		ID:         InvalidMeasureError.ID,
+		Status:     http.StatusBadRequest,
+		PublicData: true,
+	},
+}
+
+var GetByIDErrors []inputlogic.ExpectedError = []inputlogic.ExpectedError{
+	{
+		ID:         MissingPathParameterError.ID,
+		Status:     http.StatusBadRequest,
+		PublicData: true,
+	},
 }
 
 var UpdateErrors []inputlogic.ExpectedError = []inputlogic.ExpectedError{
@@ -77,6 +149,11 @@ var UpdateErrors []inputlogic.ExpectedError = []inputlogic.ExpectedError{
 		Status:     http.StatusBadRequest,
 		PublicData: true,
 	},
+	{
+		ID:         update.UnknownPatchFieldError.ID,
+		Status:     http.StatusBadRequest,
+		PublicData: true,
+	},
 	{
 		ID:         order.InvalidOrderFieldError.ID,
 		Status:     http.StatusBadRequest,
@@ -116,3 +193,71 @@ var DeleteErrors []inputlogic.ExpectedError = []inputlogic.ExpectedError{
 		PublicData: true,
 	},
 }
+
+var BatchErrors []inputlogic.ExpectedError = []inputlogic.ExpectedError{
+	{
+		ID:         TooManyBatchOperationsError.ID,
+		Status:     http.StatusBadRequest,
+		PublicData: true,
+	},
+	{
+		ID:         UnknownBatchEntityError.ID,
+		Status:     http.StatusBadRequest,
+		PublicData: true,
+	},
+	{
+		ID:         BatchOperationNotAllowedError.ID,
+		Status:     http.StatusBadRequest,
+		PublicData: true,
+	},
+	{
+		ID:         InvalidBatchDataError.ID,
+		Status:     http.StatusBadRequest,
+		PublicData: true,
+	},
+	{
+		ID:         UnknownBatchFieldError.ID,
+		Status:     http.StatusBadRequest,
+		PublicData: true,
+	},
+	{
+		ID:         selector.InvalidPredicateError.ID,
+		Status:     http.StatusBadRequest,
+		PublicData: true,
+	},
+	{
+		ID:         selector.InvalidDatabaseSelectorTranslationError.ID,
+		Status:     http.StatusBadRequest,
+		PublicData: true,
+	},
+	{
+		ID:         selector.PredicateNotAllowedError.ID,
+		Status:     http.StatusBadRequest,
+		PublicData: true,
+	},
+	{
+		ID:         update.InvalidDatabaseUpdateTranslationError.ID,
+		Status:     http.StatusBadRequest,
+		PublicData: true,
+	},
+	{
+		ID:         NeedAtLeastOneSelectorError.ID,
+		Status:     http.StatusBadRequest,
+		PublicData: true,
+	},
+	{
+		ID:         NeedAtLeastOneUpdateError.ID,
+		Status:     http.StatusBadRequest,
+		PublicData: true,
+	},
+	{
+		ID:         errors.DuplicateEntryError.ID,
+		Status:     http.StatusBadRequest,
+		PublicData: false,
+	},
+	{
+		ID:         errors.ForeignConstraintError.ID,
+		Status:     http.StatusBadRequest,
+		PublicData: false,
+	},
+}
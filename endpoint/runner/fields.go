@@ -0,0 +1,96 @@
+package runner
+
+import (
+	"slices"
+
+	"github.com/pakkasys/fluidapi/core/api"
+	databaseutil "github.com/pakkasys/fluidapi/database/util"
+	"github.com/pakkasys/fluidapi/endpoint/order"
+)
+
+type InvalidFieldErrorData struct {
+	Field string `json:"field"`
+}
+
+var InvalidFieldError = api.NewError[InvalidFieldErrorData]("INVALID_FIELD")
+
+// WithFields sets the sparse fieldset of the request, e.g. the values of a
+// "fields" query parameter. Only the columns of the requested fields and the
+// order fields are selected. All columns are selected if the fields are
+// empty. The row scanners of the entity must read the columns by name, e.g.
+// with util.ScanNamed of the database package, since a positional scanner
+// reads the wrong columns of the projected query.
+//
+//   - fields: The requested API fields.
+func WithFields(fields []string) GetInputOption {
+	return func(o *getInputOptions) {
+		o.fields = fields
+	}
+}
+
+// ToProjections translates API fields to database projections. Duplicate
+// fields are ignored.
+//
+// Parameters:
+//   - fields: The API fields.
+//   - apiFields: A mapping of API fields to corresponding database fields.
+//
+// Returns:
+//   - The deduplicated API fields.
+//   - The projections of the fields.
+//   - InvalidFieldError if a field is unknown.
+func ToProjections(
+	fields []string,
+	apiFields APIFields,
+) ([]string, []databaseutil.Projection, error) {
+	var deduplicated []string
+	var projections []databaseutil.Projection
+	for _, field := range fields {
+		if slices.Contains(deduplicated, field) {
+			continue
+		}
+		dbField, ok := apiFields[field]
+		if !ok {
+			return nil, nil, InvalidFieldError.WithData(
+				InvalidFieldErrorData{Field: field},
+			)
+		}
+		deduplicated = append(deduplicated, field)
+		projections = append(projections, databaseutil.Projection{
+			Table:  dbField.Table,
+			Column: dbField.Column,
+		})
+	}
+	return deduplicated, projections, nil
+}
+
+// fieldProjections returns the requested fields and the projections of the
+// requested fields and the order fields, which are needed e.g. for the
+// cursors. The order fields are already validated.
+func fieldProjections(
+	fields []string,
+	orders []order.Order,
+	apiFields APIFields,
+) ([]string, []databaseutil.Projection, error) {
+	if len(fields) == 0 {
+		return nil, nil, nil
+	}
+	fields, projections, err := ToProjections(fields, apiFields)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, o := range orders {
+		if slices.Contains(fields, o.Field) {
+			continue
+		}
+		dbField := apiFields[o.Field]
+		projection := databaseutil.Projection{
+			Table:  dbField.Table,
+			Column: dbField.Column,
+		}
+		if !slices.Contains(projections, projection) {
+			projections = append(projections, projection)
+		}
+	}
+	return fields, projections, nil
+}
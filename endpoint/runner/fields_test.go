@@ -0,0 +1,87 @@
+package runner
+
+import (
+	"testing"
+
+	"github.com/pakkasys/fluidapi/core/api"
+	"github.com/pakkasys/fluidapi/database/util"
+	"github.com/pakkasys/fluidapi/endpoint/order"
+	"github.com/stretchr/testify/assert"
+)
+
+var fieldsAPIFields = APIFields{
+	"id":    {Table: "user", Column: "id"},
+	"name":  {Table: "user", Column: "name"},
+	"email": {Table: "user", Column: "email"},
+}
+
+// TestToProjections tests translating API fields to projections.
+func TestToProjections(t *testing.T) {
+	fields, projections, err := ToProjections(
+		[]string{"name", "id", "name"},
+		fieldsAPIFields,
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"name", "id"}, fields)
+	assert.Equal(t, []util.Projection{
+		{Table: "user", Column: "name"},
+		{Table: "user", Column: "id"},
+	}, projections)
+}
+
+// TestToProjections_InvalidField tests that unknown fields are rejected.
+func TestToProjections_InvalidField(t *testing.T) {
+	fields, projections, err := ToProjections(
+		[]string{"name", "password"},
+		fieldsAPIFields,
+	)
+
+	assert.Nil(t, fields)
+	assert.Nil(t, projections)
+	apiErr, ok := err.(*api.Error[InvalidFieldErrorData])
+	assert.True(t, ok)
+	assert.Equal(t, InvalidFieldError.ID, apiErr.ID)
+	assert.Equal(t, "password", apiErr.Data.Field)
+}
+
+// TestParseGetEndpointInput_Fields tests that the order fields are selected
+// in addition to the requested fields.
+func TestParseGetEndpointInput_Fields(t *testing.T) {
+	parsed, err := ParseGetEndpointInput(
+		fieldsAPIFields,
+		nil,
+		[]order.Order{{Field: "id", Direction: order.DIRECTION_ASC}},
+		[]string{"id"},
+		nil,
+		10,
+		false,
+		WithFields([]string{"email"}),
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"email"}, parsed.Fields)
+	assert.Equal(t, []util.Projection{
+		{Table: "user", Column: "email"},
+		{Table: "user", Column: "id"},
+	}, parsed.Projections)
+}
+
+// TestParseGetEndpointInput_NoFields tests that all columns are selected if
+// no fields are requested.
+func TestParseGetEndpointInput_NoFields(t *testing.T) {
+	parsed, err := ParseGetEndpointInput(
+		fieldsAPIFields,
+		nil,
+		nil,
+		nil,
+		nil,
+		10,
+		false,
+		WithFields([]string{}),
+	)
+
+	assert.NoError(t, err)
+	assert.Nil(t, parsed.Fields)
+	assert.Nil(t, parsed.Projections)
+}
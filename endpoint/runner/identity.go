@@ -0,0 +1,57 @@
+package runner
+
+import (
+	"context"
+	"slices"
+
+	databaseutil "github.com/pakkasys/fluidapi/database/util"
+	"github.com/pakkasys/fluidapi/endpoint/auth"
+	"github.com/pakkasys/fluidapi/endpoint/dbfield"
+)
+
+// IdentitySelector returns a database selector matching the rows owned by the
+// authenticated client, e.g. the rows whose "user_id" column equals the
+// "sub" claim of the token. Requests without the claim are rejected with
+// auth.UnauthorizedError.
+//
+//   - ctx: The context of the request.
+//   - claim: The claim identifying the client, e.g. "sub".
+//   - dbField: The database field storing the identity.
+func IdentitySelector(
+	ctx context.Context,
+	claim string,
+	dbField dbfield.DBField,
+) (*databaseutil.Selector, error) {
+	value, ok := auth.GetClaims(ctx)[claim]
+	if !ok || value == nil {
+		return nil, auth.UnauthorizedError.WithMessage(
+			"missing identity claim: " + claim,
+		)
+	}
+	return &databaseutil.Selector{
+		Table:     dbField.Table,
+		Field:     dbField.Column,
+		Predicate: databaseutil.EQUAL,
+		Value:     value,
+	}, nil
+}
+
+// ScopeSelectors returns the selectors with the identity selector of the
+// authenticated client added, scoping the rows to the ones of the client.
+//
+//   - ctx: The context of the request.
+//   - selectors: The selectors to scope.
+//   - claim: The claim identifying the client, e.g. "sub".
+//   - dbField: The database field storing the identity.
+func ScopeSelectors(
+	ctx context.Context,
+	selectors databaseutil.Selectors,
+	claim string,
+	dbField dbfield.DBField,
+) (databaseutil.Selectors, error) {
+	identitySelector, err := IdentitySelector(ctx, claim, dbField)
+	if err != nil {
+		return nil, err
+	}
+	return append(slices.Clone(selectors), *identitySelector), nil
+}
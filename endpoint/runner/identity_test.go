@@ -0,0 +1,56 @@
+package runner
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pakkasys/fluidapi/database/util"
+	"github.com/pakkasys/fluidapi/endpoint/auth"
+	"github.com/pakkasys/fluidapi/endpoint/dbfield"
+	endpointutil "github.com/pakkasys/fluidapi/endpoint/util"
+	"github.com/stretchr/testify/assert"
+)
+
+var identityField = dbfield.DBField{Table: "orders", Column: "user_id"}
+
+// TestScopeSelectors tests scoping selectors to the authenticated client.
+func TestScopeSelectors(t *testing.T) {
+	ctx := endpointutil.NewContext(context.Background())
+	auth.SetClaims(ctx, auth.Claims{"sub": "user"})
+	selectors := util.Selectors{
+		{Table: "orders", Field: "id", Predicate: util.EQUAL, Value: 1},
+	}
+
+	scoped, err := ScopeSelectors(ctx, selectors, "sub", identityField)
+
+	assert.NoError(t, err)
+	assert.Len(t, selectors, 1)
+	assert.Equal(t, util.Selectors{
+		{Table: "orders", Field: "id", Predicate: util.EQUAL, Value: 1},
+		{
+			Table:     "orders",
+			Field:     "user_id",
+			Predicate: util.EQUAL,
+			Value:     "user",
+		},
+	}, scoped)
+}
+
+// TestIdentitySelector_Unauthenticated tests that requests without the
+// identity claim are rejected.
+func TestIdentitySelector_Unauthenticated(t *testing.T) {
+	ctx := endpointutil.NewContext(context.Background())
+
+	selector, err := IdentitySelector(ctx, "sub", identityField)
+	assert.Nil(t, selector)
+	assert.Equal(
+		t,
+		auth.UnauthorizedError.WithMessage("missing identity claim: sub"),
+		err,
+	)
+
+	auth.SetClaims(ctx, auth.Claims{"sub": "user"})
+	scoped, err := ScopeSelectors(ctx, nil, "tenant", identityField)
+	assert.Nil(t, scoped)
+	assert.Error(t, err)
+}
@@ -0,0 +1,124 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/pakkasys/fluidapi/core/api"
+	databaseutil "github.com/pakkasys/fluidapi/database/util"
+)
+
+type InvalidIncludeErrorData struct {
+	Include string `json:"include"`
+}
+
+var InvalidIncludeError = api.NewError[InvalidIncludeErrorData]("INVALID_INCLUDE")
+
+// PreloadFunc represents a function type to load the related resources of
+// the retrieved entities and to set them in the entities, e.g. with a single
+// query selecting the related rows by the IDs of the entities.
+type PreloadFunc[Entity any] func(ctx context.Context, entities []Entity) error
+
+// Relation is an expandable relation of an endpoint, requested with the
+// include parameter. The related resource is either joined to the query and
+// scanned with the entity, or preloaded after the entities are retrieved.
+type Relation[Entity any] struct {
+	// Joins are added to the query if the relation is included
+	Joins []databaseutil.Join
+	// Projections are the columns of the joined tables. They are selected
+	// only if the fields of the request are limited, otherwise all columns
+	// are selected.
+	Projections []databaseutil.Projection
+	// Preload loads the related resources if the relation is included
+	Preload PreloadFunc[Entity]
+}
+
+// Relations maps the names of the expandable relations of an endpoint to the
+// relations.
+type Relations[Entity any] map[string]Relation[Entity]
+
+// WithIncludes sets the included relations of the request, e.g. the values
+// of an "include" query parameter.
+//
+//   - includes: The names of the included relations.
+//   - relations: The expandable relations of the endpoint.
+func WithIncludes[Entity any](
+	includes []string,
+	relations Relations[Entity],
+) GetInputOption {
+	return func(o *getInputOptions) {
+		o.resolveIncludes = func(parsed *ParsedGetEndpointInput) error {
+			return resolveIncludes(parsed, includes, relations)
+		}
+	}
+}
+
+// resolveIncludes adds the joins, the projections and the preloads of the
+// included relations to the parsed input.
+func resolveIncludes[Entity any](
+	parsed *ParsedGetEndpointInput,
+	includes []string,
+	relations Relations[Entity],
+) error {
+	for _, include := range includes {
+		if slices.Contains(parsed.Includes, include) {
+			continue
+		}
+		relation, ok := relations[include]
+		if !ok {
+			return InvalidIncludeError.WithData(
+				InvalidIncludeErrorData{Include: include},
+			)
+		}
+		parsed.Includes = append(parsed.Includes, include)
+
+		for _, join := range relation.Joins {
+			if !slices.Contains(parsed.Joins, join) {
+				parsed.Joins = append(parsed.Joins, join)
+			}
+		}
+		if parsed.Projections != nil {
+			parsed.Projections = append(
+				parsed.Projections,
+				relation.Projections...,
+			)
+		}
+		if relation.Preload != nil {
+			parsed.preloads = append(parsed.preloads, preload(relation.Preload))
+		}
+	}
+	return nil
+}
+
+// preload wraps a preload function of an entity type to be stored in the
+// parsed input.
+func preload[Entity any](
+	preloadFn PreloadFunc[Entity],
+) func(ctx context.Context, entities any) error {
+	return func(ctx context.Context, entities any) error {
+		typed, ok := entities.([]Entity)
+		if !ok {
+			return fmt.Errorf("preload entity type mismatch: %T", entities)
+		}
+		return preloadFn(ctx, typed)
+	}
+}
+
+// runPreloads runs the preloads of the included relations for the retrieved
+// entities.
+func runPreloads[Entity any](
+	ctx context.Context,
+	parsed *ParsedGetEndpointInput,
+	entities []Entity,
+) error {
+	if len(entities) == 0 {
+		return nil
+	}
+	for _, preloadFn := range parsed.preloads {
+		if err := preloadFn(ctx, entities); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pakkasys/fluidapi/core/api"
+	"github.com/pakkasys/fluidapi/database/entity"
+	"github.com/pakkasys/fluidapi/database/util"
+	"github.com/stretchr/testify/assert"
+)
+
+type includeUser struct {
+	ID     int64
+	Group  string
+	Groups []string
+}
+
+var includeGroupJoin = util.Join{
+	Type:    util.JoinTypeLeft,
+	Table:   "group",
+	OnLeft:  util.ColumSelector{Table: "user", Column: "group_id"},
+	OnRight: util.ColumSelector{Table: "group", Column: "id"},
+}
+
+func includeRelations(preloadErr error) Relations[includeUser] {
+	return Relations[includeUser]{
+		"group": {
+			Joins: []util.Join{includeGroupJoin},
+			Projections: []util.Projection{
+				{Table: "group", Column: "name", Alias: "group_name"},
+			},
+		},
+		"group_id": {Joins: []util.Join{includeGroupJoin}},
+		"groups": {
+			Preload: func(ctx context.Context, users []includeUser) error {
+				for i := range users {
+					users[i].Groups = []string{"a", "b"}
+				}
+				return preloadErr
+			},
+		},
+	}
+}
+
+// TestParseGetEndpointInput_Includes tests translating the included
+// relations to joins and projections.
+func TestParseGetEndpointInput_Includes(t *testing.T) {
+	parsed, err := ParseGetEndpointInput(
+		fieldsAPIFields,
+		nil,
+		nil,
+		nil,
+		nil,
+		10,
+		false,
+		WithFields([]string{"id"}),
+		WithIncludes(
+			[]string{"group", "group_id", "group"},
+			includeRelations(nil),
+		),
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"group", "group_id"}, parsed.Includes)
+	assert.Equal(t, []util.Join{includeGroupJoin}, parsed.Joins)
+	assert.Equal(t, []util.Projection{
+		{Table: "user", Column: "id"},
+		{Table: "group", Column: "name", Alias: "group_name"},
+	}, parsed.Projections)
+	assert.Empty(t, parsed.preloads)
+}
+
+// TestParseGetEndpointInput_InvalidInclude tests that unknown relations are
+// rejected.
+func TestParseGetEndpointInput_InvalidInclude(t *testing.T) {
+	parsed, err := ParseGetEndpointInput(
+		fieldsAPIFields,
+		nil,
+		nil,
+		nil,
+		nil,
+		10,
+		false,
+		WithIncludes([]string{"owner"}, includeRelations(nil)),
+	)
+
+	assert.Nil(t, parsed)
+	apiErr, ok := err.(*api.Error[InvalidIncludeErrorData])
+	assert.True(t, ok)
+	assert.Equal(t, InvalidIncludeError.ID, apiErr.ID)
+	assert.Equal(t, "owner", apiErr.Data.Include)
+}
+
+// TestListInvoke_Preload tests that the included relations are joined and
+// preloaded.
+func TestListInvoke_Preload(t *testing.T) {
+	tests := []struct {
+		name       string
+		preloadErr error
+	}{
+		{name: "success"},
+		{name: "error", preloadErr: errors.New("preload error")},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			parsed, err := ParseGetEndpointInput(
+				fieldsAPIFields,
+				nil,
+				nil,
+				nil,
+				nil,
+				10,
+				false,
+				WithIncludes(
+					[]string{"groups", "group"},
+					includeRelations(test.preloadErr),
+				),
+			)
+			assert.NoError(t, err)
+			mockInput := new(MockParseableGetInput)
+			mockInput.On("Parse").Return(parsed, nil)
+			var joins []util.Join
+
+			output, err := ListInvoke(
+				httptest.NewRecorder(),
+				httptest.NewRequest(http.MethodGet, "/users", nil),
+				mockInput,
+				func(
+					ctx context.Context,
+					opts entity.GetOptions,
+				) ([]includeUser, error) {
+					joins = opts.Joins
+					return []includeUser{{ID: 1, Group: "g"}}, nil
+				},
+				nil,
+				nil,
+				func(user *includeUser) includeUser { return *user },
+			)
+
+			assert.Equal(t, []util.Join{includeGroupJoin}, joins)
+			if test.preloadErr != nil {
+				assert.Nil(t, output)
+				assert.Equal(t, test.preloadErr, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, []includeUser{
+				{ID: 1, Group: "g", Groups: []string{"a", "b"}},
+			}, output.Items)
+		})
+	}
+}
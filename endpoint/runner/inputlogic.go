@@ -2,7 +2,9 @@
 package runner
 
 import (
+	"context"
 	"net/http"
+	"slices"
 
 	"github.com/pakkasys/fluidapi/core/api"
 	"github.com/pakkasys/fluidapi/core/client"
@@ -10,10 +12,12 @@ import (
 	databaseutil "github.com/pakkasys/fluidapi/database/util"
 	"github.com/pakkasys/fluidapi/endpoint/dbfield"
 	"github.com/pakkasys/fluidapi/endpoint/definition"
+	"github.com/pakkasys/fluidapi/endpoint/deprecation"
 	"github.com/pakkasys/fluidapi/endpoint/middleware"
 	"github.com/pakkasys/fluidapi/endpoint/middleware/inputlogic"
 	"github.com/pakkasys/fluidapi/endpoint/order"
 	"github.com/pakkasys/fluidapi/endpoint/page"
+	"github.com/pakkasys/fluidapi/endpoint/predicate"
 	"github.com/pakkasys/fluidapi/endpoint/selector"
 	"github.com/pakkasys/fluidapi/endpoint/update"
 )
@@ -90,7 +94,8 @@ func WithMiddlewareWrapper[I ValidatedInput, O any, W any](
 }
 
 // GenericEndpointDefinition creates a generic endpoint definition with
-// specified options.
+// specified options. Endpoints with mutating HTTP methods return
+// readonly.ServiceReadOnlyError while the read-only mode is enabled.
 //
 // Parameters:
 //   - specification: The input specification containing URL, method, and input
@@ -112,6 +117,26 @@ func GenericEndpointDefinition[I ValidatedInput, O any, W any](
 	opts inputlogic.Options[I],
 	sendFn SendFunc[I, W],
 	options ...EndpointOption[I, O, W],
+) *Endpoint[I, O, W] {
+	return genericEndpointDefinition(
+		specification,
+		readOnlyGuard(specification.Method, callback),
+		expectedErrors,
+		stackBuilder,
+		opts,
+		sendFn,
+		options...,
+	)
+}
+
+func genericEndpointDefinition[I ValidatedInput, O any, W any](
+	specification InputSpecification[I],
+	callback inputlogic.Callback[I, O],
+	expectedErrors []inputlogic.ExpectedError,
+	stackBuilder StackBuilder,
+	opts inputlogic.Options[I],
+	sendFn SendFunc[I, W],
+	options ...EndpointOption[I, O, W],
 ) *Endpoint[I, O, W] {
 	middlewareWrapper := inputlogic.MiddlewareWrapper(
 		callback,
@@ -198,6 +223,172 @@ func GetEndpointDefinition[I ParseableInput[ParsedGetEndpointInput], O any, E an
 	)
 }
 
+// ListEndpointDefinition creates an endpoint definition for a GET request
+// returning the standard list output, see ListInvoke.
+//
+// Parameters:
+//   - specification: The input specification for the GET request.
+//   - getEntitiesFn: Function to get entities from the database.
+//   - getCountFn: Function to get the count of entities.
+//   - valuesFn: Function returning the values of the order fields of an
+//     entity, or nil if the endpoint is paginated with the offset.
+//   - toItemFn: Function to convert an entity to a list item.
+//   - expectedErrors: A list of expected errors to handle.
+//   - stackBuilder: A factory function to create a middleware stack
+//     builder.
+//   - opts: Options for configuring the input logic.
+//   - sendFn: Function to send the request.
+//   - options: Additional endpoint options to configure.
+//
+// Returns:
+//   - An Endpoint instance.
+func ListEndpointDefinition[I ParseableInput[ParsedGetEndpointInput], T any, E any, W any](
+	specification InputSpecification[I],
+	getEntitiesFn GetServiceFunc[E],
+	getCountFn GetCountFunc,
+	valuesFn CursorValuesFunc[E],
+	toItemFn ToListItemFunc[E, T],
+	expectedErrors []inputlogic.ExpectedError,
+	stackBuilder StackBuilder,
+	opts inputlogic.Options[I],
+	sendFn SendFunc[I, W],
+	options ...EndpointOption[I, ListOutput[T], W],
+) *Endpoint[I, ListOutput[T], W] {
+	callback := func(
+		writer http.ResponseWriter,
+		request *http.Request,
+		input *I,
+	) (*ListOutput[T], error) {
+		return ListInvoke(
+			writer,
+			request,
+			*input,
+			getEntitiesFn,
+			getCountFn,
+			valuesFn,
+			toItemFn,
+		)
+	}
+
+	return GenericEndpointDefinition(
+		specification,
+		callback,
+		expectedErrors,
+		stackBuilder,
+		opts,
+		sendFn,
+		options...,
+	)
+}
+
+// GetByIDEndpointDefinition creates an endpoint definition for a GET request
+// retrieving a single entity by its ID, e.g. GET /users/{id}. The ID is parsed
+// from the path by the input, see ParseGetByIDEndpointInput. The error of
+// entityNotFoundFn is returned with status 404 when the entity does not exist.
+//
+// Parameters:
+//   - specification: The input specification for the GET request.
+//   - getEntityFn: Function to retrieve the entity from the database.
+//   - entityNotFoundFn: Function returning the error used when the entity
+//     does not exist, e.g. the EntityNotFoundFn of the entity helpers.
+//     EntityNotFoundError is used if nil.
+//   - toOutputFn: Function to convert the entity to output format.
+//   - expectedErrors: A list of expected errors to handle.
+//   - stackBuilder: A factory function to create a middleware stack
+//     builder.
+//   - opts: Options for configuring the input logic.
+//   - sendFn: Function to send the request.
+//   - options: Additional endpoint options to configure.
+//
+// Returns:
+//   - An Endpoint instance.
+func GetByIDEndpointDefinition[I ParseableInput[ParsedGetByIDEndpointInput], O any, E any, W any](
+	specification InputSpecification[I],
+	getEntityFn GetByIDServiceFunc[E],
+	entityNotFoundFn func() error,
+	toOutputFn ToGetByIDEndpointOutput[E, O],
+	expectedErrors []inputlogic.ExpectedError,
+	stackBuilder StackBuilder,
+	opts inputlogic.Options[I],
+	sendFn SendFunc[I, W],
+	options ...EndpointOption[I, O, W],
+) *Endpoint[I, O, W] {
+	callback := func(
+		writer http.ResponseWriter,
+		request *http.Request,
+		input *I,
+	) (*O, error) {
+		return GetByIDInvoke(
+			writer,
+			request,
+			*input,
+			getEntityFn,
+			entityNotFoundFn,
+			toOutputFn,
+		)
+	}
+
+	return GenericEndpointDefinition(
+		specification,
+		callback,
+		withNotFoundError(expectedErrors, entityNotFoundFn),
+		stackBuilder,
+		opts,
+		sendFn,
+		options...,
+	)
+}
+
+// CreateEndpointDefinition creates an endpoint definition for a CREATE request.
+//
+// Parameters:
+//   - specification: The input specification for the CREATE request.
+//   - createEntityFn: Function to create the entity in the database.
+//   - toOutputFn: Function to convert the created entity to output format.
+//   - expectedErrors: A list of expected errors to handle.
+//   - stackBuilder: A factory function to create a middleware stack
+//     builder.
+//   - opts: Options for configuring the input logic.
+//   - sendFn: Function to send the request.
+//   - options: Additional endpoint options to configure.
+//
+// Returns:
+//   - An Endpoint instance.
+func CreateEndpointDefinition[I ParseableInput[ParsedCreateEndpointInput[E]], O any, E any, W any](
+	specification InputSpecification[I],
+	createEntityFn CreateServiceFunc[E],
+	toOutputFn ToCreateEndpointOutput[E, O],
+	expectedErrors []inputlogic.ExpectedError,
+	stackBuilder StackBuilder,
+	opts inputlogic.Options[I],
+	sendFn SendFunc[I, W],
+	options ...EndpointOption[I, O, W],
+) *Endpoint[I, O, W] {
+	callback := func(
+		writer http.ResponseWriter,
+		request *http.Request,
+		input *I,
+	) (*O, error) {
+		return CreateInvoke(
+			writer,
+			request,
+			*input,
+			createEntityFn,
+			toOutputFn,
+		)
+	}
+
+	return GenericEndpointDefinition(
+		specification,
+		callback,
+		expectedErrors,
+		stackBuilder,
+		opts,
+		sendFn,
+		options...,
+	)
+}
+
 // UpdateEndpointDefinition creates an endpoint definition for an UPDATE request.
 //
 // Parameters:
@@ -305,6 +496,13 @@ func DeleteEndpointDefinition[I ParseableInput[ParsedDeleteEndpointInput], O any
 
 var NeedAtLeastOneUpdateError = api.NewError[any]("NEED_AT_LEAST_ONE_UPDATE")
 var NeedAtLeastOneSelectorError = api.NewError[any]("NEED_AT_LEAST_ONE_SELECTOR")
+var EntityNotFoundError = api.NewError[any]("ENTITY_NOT_FOUND")
+
+type MissingPathParameterErrorData struct {
+	Name string `json:"name"`
+}
+
+var MissingPathParameterError = api.NewError[MissingPathParameterErrorData]("MISSING_PATH_PARAMETER")
 
 type APIFields map[string]dbfield.DBField
 
@@ -313,6 +511,45 @@ type ParsedGetEndpointInput struct {
 	DatabaseSelectors databaseutil.Selectors
 	Page              *page.Page
 	GetCount          bool
+	// Cursor is the decoded cursor of the request, or nil if none was given
+	Cursor *page.Cursor
+	// CursorOrders are the API orders the cursors of the response are built
+	// from
+	CursorOrders []order.Order
+	// Fields are the requested API fields, or nil if all fields are requested
+	Fields []string
+	// Projections are the columns of the requested fields and the order
+	// fields, or nil if all columns are selected
+	Projections []databaseutil.Projection
+	// Includes are the names of the included relations
+	Includes []string
+	// Joins are the joins of the included relations
+	Joins []databaseutil.Join
+	// preloads load the related resources of the included relations
+	preloads []func(ctx context.Context, entities any) error
+	// selectors and apiFields are the API selectors of the request, checked
+	// for deprecated fields and predicates
+	selectors []selector.Selector
+	apiFields APIFields
+}
+
+// CheckDeprecations records the usage of the fields and predicates of the
+// selectors of the request and adds a deprecation warning to the context for
+// each deprecated one used. The Get invoke functions call it for the parsed
+// input.
+//
+//   - ctx: The endpoint context.
+func (p *ParsedGetEndpointInput) CheckDeprecations(ctx context.Context) {
+	deprecation.CheckSelectors(ctx, p.selectors, p.apiFields)
+}
+
+type ParsedGetByIDEndpointInput struct {
+	DatabaseSelectors databaseutil.Selectors
+}
+
+type ParsedCreateEndpointInput[Entity any] struct {
+	Entity        *Entity
+	UpsertOptions *entity.UpsertOptions
 }
 
 type ParsedUpdateEndpointInput struct {
@@ -327,7 +564,9 @@ type ParsedDeleteEndpointInput struct {
 }
 
 // ParseGetEndpointInput parses input for a GET endpoint, translating
-// API-specific fields into database selectors and orders.
+// API-specific fields into database selectors and orders. If the page has a
+// cursor, the orders default to the orders of the cursor and the keyset of
+// the cursor is translated into a selector of the rows after or before it.
 //
 // Parameters:
 //   - apiFields: A mapping of API fields to corresponding database fields.
@@ -341,6 +580,8 @@ type ParsedDeleteEndpointInput struct {
 //     page.
 //   - getCount: Boolean flag indicating whether the count of results should be
 //     retrieved.
+//   - opts: Options such as WithQueryLimits, WithFields, WithIncludes and
+//     WithSort.
 //
 // Returns:
 //   - A pointer to a ParsedGetEndpointInput containing the translated
@@ -354,10 +595,47 @@ func ParseGetEndpointInput(
 	inputPage *page.Page,
 	maxPageCount int,
 	getCount bool,
+	opts ...GetInputOption,
 ) (*ParsedGetEndpointInput, error) {
-	dbOrders, err := order.ValidateAndTranslateToDBOrders(
+	o := &getInputOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.sort != "" {
+		sortOrders, err := ParseSort(o.sort, allowedOrderFields)
+		if err != nil {
+			return nil, err
+		}
+		orders = append(slices.Clip(orders), sortOrders...)
+	}
+	if err := o.limits.checkInput(selectors, orders); err != nil {
+		return nil, err
+	}
+
+	var cursor *page.Cursor
+	if inputPage != nil && inputPage.Cursor != "" {
+		var err error
+		cursor, orders, err = parseCursor(inputPage.Cursor, orders)
+		if err != nil {
+			return nil, err
+		}
+		inputPage = &page.Page{Limit: inputPage.Limit}
+	}
+
+	cursorOrders, err := order.ValidateAndDeduplicateOrders(
 		orders,
 		allowedOrderFields,
+	)
+	if err != nil {
+		return nil, err
+	}
+	dbOrders, err := order.ToDBOrders(cursorOrders, apiFields)
+	if err != nil {
+		return nil, err
+	}
+	fields, projections, err := fieldProjections(
+		o.fields,
+		cursorOrders,
 		apiFields,
 	)
 	if err != nil {
@@ -378,13 +656,77 @@ func ParseGetEndpointInput(
 	if err != nil {
 		return nil, err
 	}
+	if cursor != nil {
+		dbOrders = cursorDBOrders(cursor, dbOrders)
+		dbSelectors = append(dbSelectors, keysetSelector(cursor, dbOrders))
+	}
 
-	return &ParsedGetEndpointInput{
+	parsed := &ParsedGetEndpointInput{
 		Orders:            dbOrders,
 		DatabaseSelectors: dbSelectors,
 		Page:              inputPage,
 		GetCount:          getCount,
-	}, nil
+		Cursor:            cursor,
+		CursorOrders:      cursorOrders,
+		Fields:            fields,
+		Projections:       projections,
+		selectors:         selectors,
+		apiFields:         apiFields,
+	}
+	if o.resolveIncludes != nil {
+		if err := o.resolveIncludes(parsed); err != nil {
+			return nil, err
+		}
+	}
+	if err := o.limits.checkJoins(parsed.Joins); err != nil {
+		return nil, err
+	}
+	return parsed, nil
+}
+
+// ParseGetByIDEndpointInput parses input for a GET endpoint retrieving a single
+// entity, translating the ID in the request path into a database selector. The
+// endpoint URL must contain the path parameter, e.g. "/users/{id}".
+//
+// Parameters:
+//   - request: The HTTP request.
+//   - pathParameter: The name of the path parameter containing the ID.
+//   - apiField: The API field the ID is matched against.
+//   - apiFields: A mapping of API fields to corresponding database fields.
+//
+// Returns:
+//   - A pointer to a ParsedGetByIDEndpointInput containing the translated
+//     selector.
+//   - An error if the path parameter is missing or the field is unknown.
+func ParseGetByIDEndpointInput(
+	request *http.Request,
+	pathParameter string,
+	apiField string,
+	apiFields APIFields,
+) (*ParsedGetByIDEndpointInput, error) {
+	id := request.PathValue(pathParameter)
+	if id == "" {
+		return nil, MissingPathParameterError.WithData(
+			MissingPathParameterErrorData{Name: pathParameter},
+		)
+	}
+
+	dbSelectors, err := selector.ToDBSelectors(
+		[]selector.Selector{
+			{
+				AllowedPredicates: []predicate.Predicate{predicate.EQUAL},
+				Field:             apiField,
+				Predicate:         predicate.EQUAL,
+				Value:             id,
+			},
+		},
+		apiFields,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ParsedGetByIDEndpointInput{DatabaseSelectors: dbSelectors}, nil
 }
 
 // ParseUpdateEndpointInput parses input for an UPDATE endpoint, translating
@@ -433,6 +775,33 @@ func ParseUpdateEndpointInput(
 	}, nil
 }
 
+// ParsePatchEndpointInput parses input for a PATCH endpoint. Only the fields
+// present in the patch are translated into database updates, so unspecified
+// columns are not overwritten.
+//
+// Parameters:
+//   - apiFields: A mapping of API fields to corresponding database fields.
+//   - selectors: The list of selectors provided by the client, used to filter
+//     the entities to be updated.
+//   - patch: The partial update decoded from the request body.
+//
+// Returns:
+//   - A pointer to a ParsedUpdateEndpointInput containing the translated
+//     selectors and updates.
+//   - An error if parsing fails, such as if the patch contains unknown fields
+//     or no valid selectors or updates are provided.
+func ParsePatchEndpointInput[T any](
+	apiFields APIFields,
+	selectors []selector.Selector,
+	patch update.Patch[T],
+) (*ParsedUpdateEndpointInput, error) {
+	updates, err := patch.Updates()
+	if err != nil {
+		return nil, err
+	}
+	return ParseUpdateEndpointInput(apiFields, selectors, updates, false)
+}
+
 // ParseDeleteEndpointInput parses input for a DELETE endpoint, translating
 // API-specific fields into database selectors and orders.
 //
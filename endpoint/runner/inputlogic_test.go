@@ -2,6 +2,7 @@ package runner
 
 import (
 	"context"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -57,7 +58,9 @@ func (m MockParseableInput) Validate() []inputlogic.FieldError {
 	return nil
 }
 
-func (m MockParseableInput) Parse() (*ParsedGetEndpointInput, error) {
+func (m MockParseableInput) Parse(
+	_ *http.Request,
+) (*ParsedGetEndpointInput, error) {
 	return &ParsedGetEndpointInput{}, nil
 }
 
@@ -120,6 +123,44 @@ func MockToUpdateEndpointOutput(count int64) *string {
 	return &output
 }
 
+// MockParseableGetByIDInput is a mock implementation of the ParseableInput
+// interface.
+type MockParseableGetByIDInput struct{}
+
+func (m MockParseableGetByIDInput) Validate() []inputlogic.FieldError {
+	return nil
+}
+
+func (m MockParseableGetByIDInput) Parse(
+	r *http.Request,
+) (*ParsedGetByIDEndpointInput, error) {
+	return ParseGetByIDEndpointInput(r, "id", "id", APIFields{
+		"id": {Table: "table", Column: "id"},
+	})
+}
+
+// MockToCreateEndpointOutput is a mock implementation of
+// ToCreateEndpointOutput.
+func MockToCreateEndpointOutput(entity *string) *string {
+	output := "created " + *entity
+	return &output
+}
+
+// MockParseableCreateInput is a mock implementation of the ParseableInput
+// interface.
+type MockParseableCreateInput struct{}
+
+func (m MockParseableCreateInput) Validate() []inputlogic.FieldError {
+	return nil
+}
+
+func (m MockParseableCreateInput) Parse(
+	_ *http.Request,
+) (*ParsedCreateEndpointInput[string], error) {
+	entity := "entity"
+	return &ParsedCreateEndpointInput[string]{Entity: &entity}, nil
+}
+
 // MockParseableUpdateInput is a mock implementation of the ParseableInput
 // interface.
 type MockParseableUpdateInput struct{}
@@ -128,7 +169,9 @@ func (m MockParseableUpdateInput) Validate() []inputlogic.FieldError {
 	return nil
 }
 
-func (m MockParseableUpdateInput) Parse() (*ParsedUpdateEndpointInput, error) {
+func (m MockParseableUpdateInput) Parse(
+	_ *http.Request,
+) (*ParsedUpdateEndpointInput, error) {
 	return &ParsedUpdateEndpointInput{}, nil
 }
 
@@ -140,7 +183,9 @@ func (m MockParseableDeleteInput) Validate() []inputlogic.FieldError {
 	return nil
 }
 
-func (m MockParseableDeleteInput) Parse() (*ParsedDeleteEndpointInput, error) {
+func (m MockParseableDeleteInput) Parse(
+	_ *http.Request,
+) (*ParsedDeleteEndpointInput, error) {
 	return &ParsedDeleteEndpointInput{}, nil
 }
 
@@ -547,6 +592,194 @@ func TestUpdateEndpointDefinition(t *testing.T) {
 	mockOutputHandler.AssertExpectations(t)
 }
 
+// TestGetByIDEndpointDefinition tests that GetByIDEndpointDefinition returns
+// the entity of the path ID and responds 404 when it does not exist.
+func TestGetByIDEndpointDefinition(t *testing.T) {
+	notFoundError := api.NewError[any]("USER_NOT_FOUND")
+	specification := InputSpecification[MockParseableGetByIDInput]{
+		URL:    "/users/{id}",
+		Method: http.MethodGet,
+		InputFactory: func() *MockParseableGetByIDInput {
+			return new(MockParseableGetByIDInput)
+		},
+	}
+
+	stackBuilder := new(MockStackBuilder)
+	stackBuilder.On("MustAddMiddleware", mock.Anything).Return(stackBuilder)
+	stackBuilder.On("Build").Return(middleware.Stack(stackBuilder.middlewares))
+
+	sendFn := func(
+		input *MockParseableGetByIDInput,
+		host string,
+	) (*client.Response[MockParseableGetByIDInput, any], error) {
+		return nil, nil
+	}
+
+	mockObjectPicker := new(MockObjectPicker[MockParseableGetByIDInput])
+	mockObjectPicker.On(
+		"PickObject",
+		mock.Anything,
+		mock.Anything,
+		mock.Anything,
+	).
+		Return(&MockParseableGetByIDInput{}, nil)
+
+	mockOutputHandler := new(MockOutputHandler)
+	mockOutputHandler.On(
+		"ProcessOutput",
+		mock.Anything,
+		mock.Anything,
+		mock.MatchedBy(func(output *string) bool {
+			return *output == "user 1"
+		}),
+		nil,
+		http.StatusOK,
+	).Return(nil).Once()
+	mockOutputHandler.On(
+		"ProcessOutput",
+		mock.Anything,
+		mock.Anything,
+		nil,
+		mock.Anything,
+		http.StatusNotFound,
+	).Return(nil).Once()
+
+	opts := inputlogic.Options[MockParseableGetByIDInput]{
+		ObjectPicker:  mockObjectPicker,
+		OutputHandler: mockOutputHandler,
+	}
+
+	mockGetServiceFunc := func(
+		ctx context.Context,
+		opts entity.GetOptions,
+	) (*string, error) {
+		assert.Len(t, opts.Selectors, 1)
+		if opts.Selectors[0].Value != "1" {
+			return nil, nil
+		}
+		user := "user 1"
+		return &user, nil
+	}
+
+	endpoint := GetByIDEndpointDefinition(
+		specification,
+		mockGetServiceFunc,
+		func() error { return notFoundError },
+		func(user *string) *string { return user },
+		GetByIDErrors,
+		stackBuilder,
+		opts,
+		sendFn,
+	)
+
+	assert.Equal(t, "/users/{id}", endpoint.Definition.URL)
+	assert.Equal(t, http.MethodGet, endpoint.Definition.Method)
+
+	mux := http.NewServeMux()
+	mux.Handle(
+		"/users/{id}",
+		api.ApplyMiddlewares(
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}),
+			endpoint.Definition.MiddlewareStack.Middlewares()...,
+		),
+	)
+	for _, url := range []string{"/users/1", "/users/2"} {
+		req := httptest.NewRequest(http.MethodGet, url, nil)
+		mux.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	mockOutputHandler.AssertExpectations(t)
+	assert.Len(t, GetByIDErrors, 1, "Expected errors should not be modified")
+}
+
+// TestCreateEndpointDefinition tests the CreateEndpointDefinition function.
+func TestCreateEndpointDefinition(t *testing.T) {
+	specification := InputSpecification[MockParseableCreateInput]{
+		URL:    "/create",
+		Method: http.MethodPost,
+		InputFactory: func() *MockParseableCreateInput {
+			return new(MockParseableCreateInput)
+		},
+	}
+
+	stackBuilder := new(MockStackBuilder)
+	stackBuilder.On("MustAddMiddleware", mock.Anything).Return(stackBuilder)
+	stackBuilder.On("Build").Return(middleware.Stack(stackBuilder.middlewares))
+
+	sendFn := func(
+		input *MockParseableCreateInput,
+		host string,
+	) (*client.Response[MockParseableCreateInput, any], error) {
+		return nil, nil
+	}
+
+	mockObjectPicker := new(MockObjectPicker[MockParseableCreateInput])
+	mockObjectPicker.On(
+		"PickObject",
+		mock.Anything,
+		mock.Anything,
+		mock.Anything,
+	).
+		Return(&MockParseableCreateInput{}, nil)
+
+	mockOutputHandler := new(MockOutputHandler)
+	mockOutputHandler.On(
+		"ProcessOutput",
+		mock.Anything,
+		mock.Anything,
+		mock.MatchedBy(func(output *string) bool {
+			return *output == "created entity"
+		}),
+		mock.Anything,
+		mock.Anything,
+	).Return(nil)
+
+	opts := inputlogic.Options[MockParseableCreateInput]{
+		ObjectPicker:  mockObjectPicker,
+		OutputHandler: mockOutputHandler,
+	}
+
+	mockCreateServiceFunc := func(
+		ctx context.Context,
+		entity *string,
+		opts *entity.UpsertOptions,
+	) (*string, error) {
+		return entity, nil
+	}
+
+	endpoint := CreateEndpointDefinition(
+		specification,
+		mockCreateServiceFunc,
+		MockToCreateEndpointOutput,
+		[]inputlogic.ExpectedError{},
+		stackBuilder,
+		opts,
+		sendFn,
+	)
+
+	assert.NotNil(t, endpoint, "Endpoint should not be nil")
+	assert.Equal(t, "/create", endpoint.Definition.URL)
+	assert.Equal(t, http.MethodPost, endpoint.Definition.Method)
+	assert.NotNil(t, endpoint.Client, "Endpoint client should not be nil")
+
+	handler := api.ApplyMiddlewares(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}),
+		endpoint.Definition.MiddlewareStack.Middlewares()...,
+	)
+
+	req, err := http.NewRequest(http.MethodPost, "/create", nil)
+	assert.NoError(t, err, "Creating the request should not fail")
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	stackBuilder.AssertExpectations(t)
+	mockObjectPicker.AssertExpectations(t)
+	mockOutputHandler.AssertExpectations(t)
+}
+
 // TestDeleteEndpointDefinition tests the DeleteEndpointDefinition function.
 func TestDeleteEndpointDefinition_WithCallbackExecution(t *testing.T) {
 	specification := InputSpecification[MockParseableDeleteInput]{
@@ -793,6 +1026,51 @@ func TestParseGetEndpointInput_InvalidPage(t *testing.T) {
 	assert.Error(t, err, "ParseGetEndpointInput should return an error for invalid page settings")
 }
 
+// TestParseGetByIDEndpointInput tests translating the path ID into a
+// selector.
+func TestParseGetByIDEndpointInput(t *testing.T) {
+	apiFields := APIFields{"id": {Table: "user", Column: "id"}}
+	req := httptest.NewRequest(http.MethodGet, "/users/5", nil)
+	req.SetPathValue("id", "5")
+
+	parsed, err := ParseGetByIDEndpointInput(req, "id", "id", apiFields)
+
+	assert.NoError(t, err)
+	assert.Equal(t, util.Selectors{
+		{
+			Table:     "user",
+			Field:     "id",
+			Predicate: util.EQUAL,
+			Value:     "5",
+		},
+	}, parsed.DatabaseSelectors)
+}
+
+// TestParseGetByIDEndpointInput_MissingParameter tests that a missing path
+// parameter is rejected.
+func TestParseGetByIDEndpointInput_MissingParameter(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/users", nil)
+
+	parsed, err := ParseGetByIDEndpointInput(req, "id", "id", APIFields{})
+
+	assert.Nil(t, parsed)
+	assert.Equal(t, MissingPathParameterError.WithData(
+		MissingPathParameterErrorData{Name: "id"},
+	), err)
+}
+
+// TestParseGetByIDEndpointInput_UnknownField tests that an unknown API field
+// is rejected.
+func TestParseGetByIDEndpointInput_UnknownField(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/users/5", nil)
+	req.SetPathValue("id", "5")
+
+	parsed, err := ParseGetByIDEndpointInput(req, "id", "id", APIFields{})
+
+	assert.Nil(t, parsed)
+	assert.Error(t, err)
+}
+
 // TestParseUpdateEndpointInput_ValidInput tests ParseUpdateEndpointInput with
 // valid input.
 func TestParseUpdateEndpointInput_ValidInput(t *testing.T) {
@@ -1109,3 +1387,54 @@ func TestParseDeleteEndpointInput_InvalidOrderField(t *testing.T) {
 	assert.Error(t, err, "ParseDeleteEndpointInput should return an error for an invalid order field")
 	assert.Nil(t, result, "ParsedDeleteEndpointInput should be nil for an invalid order field")
 }
+
+// TestParsePatchEndpointInput tests that only the fields present in the patch
+// are turned into database updates.
+func TestParsePatchEndpointInput(t *testing.T) {
+	apiFields := APIFields{
+		"id":    dbfield.DBField{Table: "user", Column: "id"},
+		"name":  dbfield.DBField{Table: "user", Column: "name"},
+		"email": dbfield.DBField{Table: "user", Column: "email"},
+	}
+	selectors := []selector.Selector{
+		{
+			Field:             "id",
+			Predicate:         predicate.EQUAL,
+			Value:             1,
+			AllowedPredicates: []predicate.Predicate{predicate.EQUAL},
+		},
+	}
+	var patch update.Patch[struct {
+		Name  *string `json:"name"`
+		Email *string `json:"email"`
+	}]
+	err := json.Unmarshal([]byte(`{"email": null}`), &patch)
+	assert.NoError(t, err)
+
+	result, err := ParsePatchEndpointInput(apiFields, selectors, patch)
+
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		[]entity.Update{{Field: "email", Value: nil}},
+		result.DatabaseUpdates,
+	)
+	assert.Len(t, result.DatabaseSelectors, 1)
+}
+
+// TestParsePatchEndpointInput_UnknownField tests that unknown patch fields
+// are rejected.
+func TestParsePatchEndpointInput_UnknownField(t *testing.T) {
+	var patch update.Patch[struct {
+		Name *string `json:"name"`
+	}]
+	err := json.Unmarshal([]byte(`{"age": 1}`), &patch)
+	assert.NoError(t, err)
+
+	result, err := ParsePatchEndpointInput(APIFields{}, nil, patch)
+
+	assert.Nil(t, result)
+	assert.Equal(t, update.UnknownPatchFieldError.WithData(
+		update.UnknownPatchFieldErrorData{Field: "age"},
+	), err)
+}
@@ -4,8 +4,11 @@ package runner
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
 
+	"github.com/pakkasys/fluidapi/core/api"
 	"github.com/pakkasys/fluidapi/database/entity"
 	"github.com/pakkasys/fluidapi/database/util"
 	"github.com/pakkasys/fluidapi/endpoint/middleware/inputlogic"
@@ -70,6 +73,35 @@ type ToDeleteEndpointOutput[EndpointOutput any] func(
 	count int64,
 ) *EndpointOutput
 
+// CreateServiceFunc represents a function type to create an entity in the
+// database. The service returns the created entity, e.g. with its generated
+// ID set.
+type CreateServiceFunc[Entity any] func(
+	ctx context.Context,
+	entity *Entity,
+	opts *entity.UpsertOptions,
+) (*Entity, error)
+
+// ToCreateEndpointOutput represents a function type to convert the created
+// entity to endpoint output.
+type ToCreateEndpointOutput[Entity any, EndpointOutput any] func(
+	entity *Entity,
+) *EndpointOutput
+
+// GetByIDServiceFunc represents a function type to retrieve a single entity
+// from the database. The entity helpers' GetEntityWithManagedTransaction
+// satisfies it.
+type GetByIDServiceFunc[Entity any] func(
+	ctx context.Context,
+	opts entity.GetOptions,
+) (*Entity, error)
+
+// ToGetByIDEndpointOutput represents a function type to convert the retrieved
+// entity to endpoint output.
+type ToGetByIDEndpointOutput[Entity any, EndpointOutput any] func(
+	entity *Entity,
+) *EndpointOutput
+
 // GetServiceFunc represents a function type to retrieve entities from the
 // database.
 type GetServiceFunc[Output any] func(
@@ -109,22 +141,110 @@ func GetInvoke[I ParseableInput[ParsedGetEndpointInput], O any, E any](
 	if err != nil {
 		return nil, err
 	}
+	parsedInput.CheckDeprecations(request.Context())
 
 	output, count, err := runGetService(
 		request.Context(),
 		parsedInput,
 		serviceFn,
 		getCountFn,
-		nil,
-		nil,
+		parsedInput.Joins,
+		parsedInput.Projections,
 	)
 	if err != nil {
 		return nil, err
 	}
+	if err := runPreloads(request.Context(), parsedInput, output); err != nil {
+		return nil, err
+	}
 
 	return toEndpointOutputFn(output, &count), nil
 }
 
+// GetByIDInvoke handles the invocation of a GET endpoint retrieving a single
+// entity by its ID.
+//
+// Parameters:
+//   - writer: The HTTP response writer.
+//   - request: The HTTP request.
+//   - input: Input data to the endpoint, implementing ParseableInput.
+//   - serviceFn: Function to retrieve the entity from the database.
+//   - entityNotFoundFn: Function returning the error used when the entity
+//     does not exist. EntityNotFoundError is used if nil.
+//   - toEndpointOutputFn: Function to convert the entity to endpoint output.
+//
+// Returns:
+// - Pointer to the output object or an error.
+func GetByIDInvoke[I ParseableInput[ParsedGetByIDEndpointInput], O any, E any](
+	writer http.ResponseWriter,
+	request *http.Request,
+	input I,
+	serviceFn GetByIDServiceFunc[E],
+	entityNotFoundFn func() error,
+	toEndpointOutputFn ToGetByIDEndpointOutput[E, O],
+) (*O, error) {
+	parsedInput, err := input.Parse(request)
+	if err != nil {
+		return nil, err
+	}
+
+	found, err := serviceFn(
+		request.Context(),
+		entity.GetOptions{
+			Options: entity.Options{
+				Selectors: parsedInput.DatabaseSelectors,
+			},
+		},
+	)
+	if errors.Is(err, entity.ErrEntityNotFound) {
+		return nil, notFoundError(entityNotFoundFn)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if found == nil {
+		return nil, notFoundError(entityNotFoundFn)
+	}
+
+	return toEndpointOutputFn(found), nil
+}
+
+// CreateInvoke handles the invocation of a CREATE endpoint.
+//
+// Parameters:
+//   - writer: The HTTP response writer.
+//   - request: The HTTP request.
+//   - input: Input data to the endpoint, implementing ParseableInput.
+//   - serviceFn: Function to create the entity in the database.
+//   - toEndpointOutputFn: Function to convert the created entity to endpoint
+//     output.
+//
+// Returns:
+// - Pointer to the output object or an error.
+func CreateInvoke[I ParseableInput[ParsedCreateEndpointInput[E]], O any, E any](
+	writer http.ResponseWriter,
+	request *http.Request,
+	input I,
+	serviceFn CreateServiceFunc[E],
+	toEndpointOutputFn ToCreateEndpointOutput[E, O],
+) (*O, error) {
+	parsedInput, err := input.Parse(request)
+	if err != nil {
+		return nil, err
+	}
+
+	created, err := serviceFn(
+		request.Context(),
+		parsedInput.Entity,
+		parsedInput.UpsertOptions,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return toEndpointOutputFn(created), nil
+}
+
 // UpdateInvoke handles the invocation of an UPDATE endpoint.
 //
 // Parameters:
@@ -149,6 +269,11 @@ func UpdateInvoke[I ParseableInput[ParsedUpdateEndpointInput], EndpointOutput an
 		return nil, err
 	}
 
+	api.RecordOperation(
+		request.Context(),
+		parsedInput.DatabaseSelectors,
+		parsedInput.DatabaseUpdates,
+	)
 	count, err := serviceFn(
 		request.Context(),
 		parsedInput.DatabaseSelectors,
@@ -185,6 +310,11 @@ func DeleteInvoke[EndpointInput ParseableInput[ParsedDeleteEndpointInput], Endpo
 		return nil, err
 	}
 
+	api.RecordOperation(
+		request.Context(),
+		parsedInput.DatabaseSelectors,
+		nil,
+	)
 	count, err := serviceFn(
 		request.Context(),
 		parsedInput.DatabaseSelectors,
@@ -257,3 +387,32 @@ func runGetService[Output any](
 		return entities, len(entities), nil
 	}
 }
+
+// notFoundError returns the error of the entity not found function, or
+// EntityNotFoundError if the function is nil.
+func notFoundError(entityNotFoundFn func() error) error {
+	if entityNotFoundFn == nil {
+		return EntityNotFoundError
+	}
+	return entityNotFoundFn()
+}
+
+// withNotFoundError returns the expected errors with the error of the entity
+// not found function handled with status 404.
+func withNotFoundError(
+	expectedErrors []inputlogic.ExpectedError,
+	entityNotFoundFn func() error,
+) []inputlogic.ExpectedError {
+	apiError, ok := notFoundError(entityNotFoundFn).(api.APIError)
+	if !ok {
+		return expectedErrors
+	}
+	return append(
+		slices.Clone(expectedErrors),
+		inputlogic.ExpectedError{
+			ID:         apiError.GetID(),
+			Status:     http.StatusNotFound,
+			PublicData: false,
+		},
+	)
+}
@@ -7,8 +7,10 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/pakkasys/fluidapi/core/api"
 	"github.com/pakkasys/fluidapi/database/entity"
 	"github.com/pakkasys/fluidapi/database/util"
+	"github.com/pakkasys/fluidapi/database/util/fake"
 	"github.com/pakkasys/fluidapi/endpoint/middleware/inputlogic"
 	"github.com/pakkasys/fluidapi/endpoint/page"
 	"github.com/stretchr/testify/assert"
@@ -25,7 +27,9 @@ func (m *MockParseableGetInput) Validate() []inputlogic.FieldError {
 	return nil
 }
 
-func (m *MockParseableGetInput) Parse() (*ParsedGetEndpointInput, error) {
+func (m *MockParseableGetInput) Parse(
+	_ *http.Request,
+) (*ParsedGetEndpointInput, error) {
 	args := m.Called()
 	return args.Get(0).(*ParsedGetEndpointInput), args.Error(1)
 }
@@ -159,7 +163,7 @@ func (m MockParseableUpdateInputInvoke) Validate() []inputlogic.FieldError {
 	return args.Get(0).([]inputlogic.FieldError)
 }
 
-func (m MockParseableUpdateInputInvoke) Parse() (
+func (m MockParseableUpdateInputInvoke) Parse(_ *http.Request) (
 	*ParsedUpdateEndpointInput,
 	error,
 ) {
@@ -217,6 +221,48 @@ func TestUpdateInvoke_Success(t *testing.T) {
 	mockInput.helper.AssertExpectations(t)
 }
 
+// TestUpdateInvoke_RecordOperation tests that UpdateInvoke records the
+// selectors and updates with the operation recorder of the context.
+func TestUpdateInvoke_RecordOperation(t *testing.T) {
+	mockHelper := new(MockHelper)
+	mockInput := MockParseableUpdateInputInvoke{helper: mockHelper}
+	parsedInput := &ParsedUpdateEndpointInput{
+		DatabaseSelectors: util.Selectors{
+			{Table: "table1", Field: "id", Predicate: util.EQUAL, Value: 1},
+		},
+		DatabaseUpdates: []entity.Update{{Field: "name", Value: "new"}},
+	}
+	mockHelper.On("Parse").Return(parsedInput, nil)
+
+	var selectors, updates any
+	req := httptest.NewRequest(http.MethodPut, "/update", nil)
+	req = req.WithContext(api.WithOperationRecorder(
+		req.Context(),
+		func(s any, u any) {
+			selectors = s
+			updates = u
+		},
+	))
+
+	_, err := UpdateInvoke[MockParseableUpdateInput](
+		httptest.NewRecorder(),
+		req,
+		mockInput,
+		func(
+			ctx context.Context,
+			databaseSelectors []util.Selector,
+			databaseUpdates []entity.Update,
+		) (int64, error) {
+			return 1, nil
+		},
+		MockToUpdateEndpointOutput,
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(t, parsedInput.DatabaseSelectors, selectors)
+	assert.Equal(t, parsedInput.DatabaseUpdates, updates)
+}
+
 // TestUpdateInvoke_ParseError tests UpdateInvoke when parsing fails.
 func TestUpdateInvoke_ParseError(t *testing.T) {
 	mockHelper := new(MockHelper)
@@ -302,6 +348,270 @@ type MockParseableUpdateInputInvoke struct {
 	helper *MockHelper
 }
 
+// TestGetByIDInvoke_Success tests GetByIDInvoke with an existing entity.
+func TestGetByIDInvoke_Success(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/users/1", nil)
+	req.SetPathValue("id", "1")
+	rr := httptest.NewRecorder()
+
+	mockGetServiceFunc := func(
+		ctx context.Context,
+		opts entity.GetOptions,
+	) (*string, error) {
+		assert.Equal(t, "1", opts.Selectors[0].Value)
+		user := "user"
+		return &user, nil
+	}
+
+	output, err := GetByIDInvoke(
+		rr,
+		req,
+		MockParseableGetByIDInput{},
+		mockGetServiceFunc,
+		nil,
+		func(user *string) *string { return user },
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "user", *output)
+}
+
+// TestGetByIDInvoke_NotFound tests GetByIDInvoke when the service returns no
+// entity.
+func TestGetByIDInvoke_NotFound(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/users/1", nil)
+	req.SetPathValue("id", "1")
+	rr := httptest.NewRecorder()
+
+	mockGetServiceFunc := func(
+		ctx context.Context,
+		opts entity.GetOptions,
+	) (*string, error) {
+		return nil, nil
+	}
+	toOutput := func(user *string) *string { return user }
+
+	output, err := GetByIDInvoke(
+		rr,
+		req,
+		MockParseableGetByIDInput{},
+		mockGetServiceFunc,
+		nil,
+		toOutput,
+	)
+	assert.Nil(t, output)
+	assert.Equal(t, EntityNotFoundError, err)
+
+	notFound := errors.New("not found")
+	output, err = GetByIDInvoke(
+		rr,
+		req,
+		MockParseableGetByIDInput{},
+		mockGetServiceFunc,
+		func() error { return notFound },
+		toOutput,
+	)
+	assert.Nil(t, output)
+	assert.Equal(t, notFound, err)
+}
+
+// TestGetByIDInvoke_NotFoundHelper tests that the not found error of the
+// entity helpers is returned as the entity not found error.
+func TestGetByIDInvoke_NotFoundHelper(t *testing.T) {
+	db := fake.NewDB()
+	db.CreateTable("table", fake.TableOptions{
+		Columns:    []string{"id", "name"},
+		PrimaryKey: "id",
+	})
+	helpers := &entity.EntityHelpers[batchUser]{
+		TableName: "table",
+		ScanRowFn: func(row util.Row, user *batchUser) error {
+			return row.Scan(&user.ID, &user.Name)
+		},
+	}
+	req := httptest.NewRequest(http.MethodGet, "/users/1", nil)
+	req.SetPathValue("id", "1")
+
+	output, err := GetByIDInvoke(
+		httptest.NewRecorder(),
+		req,
+		MockParseableGetByIDInput{},
+		func(
+			ctx context.Context,
+			opts entity.GetOptions,
+		) (*batchUser, error) {
+			return helpers.GetEntity(ctx, db, opts)
+		},
+		nil,
+		func(user *batchUser) *batchUser { return user },
+	)
+
+	assert.Nil(t, output)
+	assert.Equal(t, EntityNotFoundError, err)
+}
+
+// TestGetByIDInvoke_ParseError tests GetByIDInvoke when parsing fails.
+func TestGetByIDInvoke_ParseError(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/users", nil)
+	rr := httptest.NewRecorder()
+
+	mockGetServiceFunc := func(
+		ctx context.Context,
+		opts entity.GetOptions,
+	) (*string, error) {
+		t.Fatal("service should not be called")
+		return nil, nil
+	}
+
+	output, err := GetByIDInvoke(
+		rr,
+		req,
+		MockParseableGetByIDInput{},
+		mockGetServiceFunc,
+		nil,
+		func(user *string) *string { return user },
+	)
+
+	assert.Nil(t, output)
+	assert.Equal(t, MissingPathParameterError.WithData(
+		MissingPathParameterErrorData{Name: "id"},
+	), err)
+}
+
+// MockParseableCreateInputInvoke is a mock implementation of
+// ParseableInput[ParsedCreateEndpointInput[string]].
+type MockParseableCreateInputInvoke struct {
+	helper *MockHelper
+}
+
+func (m MockParseableCreateInputInvoke) Validate() []inputlogic.FieldError {
+	return nil
+}
+
+func (m MockParseableCreateInputInvoke) Parse(_ *http.Request) (
+	*ParsedCreateEndpointInput[string],
+	error,
+) {
+	if m.helper == nil {
+		panic("MockHelper is not initialized.")
+	}
+	args := m.helper.Called()
+	return args.Get(0).(*ParsedCreateEndpointInput[string]), args.Error(1)
+}
+
+// TestCreateInvoke_Success tests CreateInvoke with valid input and successful
+// create operation.
+func TestCreateInvoke_Success(t *testing.T) {
+	mockHelper := new(MockHelper)
+	mockInput := MockParseableCreateInputInvoke{helper: mockHelper}
+
+	newEntity := "entity"
+	upsertOptions := &entity.UpsertOptions{Mode: entity.InsertModeIgnore}
+	mockInput.helper.On("Parse").Return(&ParsedCreateEndpointInput[string]{
+		Entity:        &newEntity,
+		UpsertOptions: upsertOptions,
+	}, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/create", nil)
+	rr := httptest.NewRecorder()
+
+	mockCreateServiceFunc := func(
+		ctx context.Context,
+		e *string,
+		opts *entity.UpsertOptions,
+	) (*string, error) {
+		assert.Equal(t, &newEntity, e)
+		assert.Equal(t, upsertOptions, opts)
+		return e, nil
+	}
+
+	output, err := CreateInvoke(
+		rr,
+		req,
+		mockInput,
+		mockCreateServiceFunc,
+		MockToCreateEndpointOutput,
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "created entity", *output)
+
+	mockInput.helper.AssertExpectations(t)
+}
+
+// TestCreateInvoke_ParseError tests CreateInvoke when parsing fails.
+func TestCreateInvoke_ParseError(t *testing.T) {
+	mockHelper := new(MockHelper)
+	mockInput := MockParseableCreateInputInvoke{helper: mockHelper}
+
+	mockInput.helper.On("Parse").Return(
+		(*ParsedCreateEndpointInput[string])(nil),
+		errors.New("parse error"),
+	)
+
+	req := httptest.NewRequest(http.MethodPost, "/create", nil)
+	rr := httptest.NewRecorder()
+
+	mockCreateServiceFunc := func(
+		ctx context.Context,
+		e *string,
+		opts *entity.UpsertOptions,
+	) (*string, error) {
+		t.Fatal("service should not be called")
+		return nil, nil
+	}
+
+	output, err := CreateInvoke(
+		rr,
+		req,
+		mockInput,
+		mockCreateServiceFunc,
+		MockToCreateEndpointOutput,
+	)
+
+	assert.EqualError(t, err, "parse error")
+	assert.Nil(t, output)
+
+	mockInput.helper.AssertExpectations(t)
+}
+
+// TestCreateInvoke_ServiceError tests CreateInvoke when the create service
+// function returns an error.
+func TestCreateInvoke_ServiceError(t *testing.T) {
+	mockHelper := new(MockHelper)
+	mockInput := MockParseableCreateInputInvoke{helper: mockHelper}
+
+	newEntity := "entity"
+	mockInput.helper.On("Parse").Return(
+		&ParsedCreateEndpointInput[string]{Entity: &newEntity},
+		nil,
+	)
+
+	req := httptest.NewRequest(http.MethodPost, "/create", nil)
+	rr := httptest.NewRecorder()
+
+	mockCreateServiceFunc := func(
+		ctx context.Context,
+		e *string,
+		opts *entity.UpsertOptions,
+	) (*string, error) {
+		return nil, errors.New("service error")
+	}
+
+	output, err := CreateInvoke(
+		rr,
+		req,
+		mockInput,
+		mockCreateServiceFunc,
+		MockToCreateEndpointOutput,
+	)
+
+	assert.EqualError(t, err, "service error")
+	assert.Nil(t, output)
+
+	mockInput.helper.AssertExpectations(t)
+}
+
 // MockParseableDeleteInputInvoke is a mock implementation of
 // ParseableInput[ParsedDeleteEndpointInput].
 type MockParseableDeleteInputInvoke struct {
@@ -316,7 +626,7 @@ func (m MockParseableDeleteInputInvoke) Validate() []inputlogic.FieldError {
 	return args.Get(0).([]inputlogic.FieldError)
 }
 
-func (m MockParseableDeleteInputInvoke) Parse() (
+func (m MockParseableDeleteInputInvoke) Parse(_ *http.Request) (
 	*ParsedDeleteEndpointInput,
 	error,
 ) {
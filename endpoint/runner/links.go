@@ -0,0 +1,55 @@
+package runner
+
+import (
+	"net/http"
+
+	"github.com/pakkasys/fluidapi/endpoint/definition"
+	"github.com/pakkasys/fluidapi/endpoint/page"
+)
+
+// ListLinks returns the pagination links of a list output to the pages of a
+// list endpoint, i.e. the same links as in the Link header of ListInvoke.
+// The query of the request, e.g. its filters, is kept in the links.
+//
+// Parameters:
+//   - builder: The link builder.
+//   - request: The HTTP request of the list.
+//   - name: The name of the list endpoint.
+//   - parameters: The values of the path parameters of the endpoint URL.
+//   - output: The list output.
+//
+// Returns:
+//   - The "first", "prev", "next" and "last" links of the pages, or an error
+//     if the endpoint is unknown.
+func ListLinks[T any](
+	builder *definition.LinkBuilder,
+	request *http.Request,
+	name string,
+	parameters map[string]string,
+	output *ListOutput[T],
+) (definition.Links, error) {
+	var links []pageLink
+	if output.Offset != nil {
+		links = offsetLinks(
+			page.Page{Offset: *output.Offset, Limit: output.Limit},
+			len(output.Items),
+			output.Count,
+		)
+	} else {
+		links = cursorLinks(output.Limit, page.Cursors{
+			Next:     output.Next,
+			Previous: output.Previous,
+		})
+	}
+
+	pageLinks := definition.Links{}
+	for _, link := range links {
+		query := pageQuery(request.URL.Query(), link.page)
+		built, err := builder.Link(name, parameters, query)
+		if err != nil {
+			return nil, err
+		}
+		pageLinks[link.rel] = built
+	}
+	return pageLinks, nil
+}
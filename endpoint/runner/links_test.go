@@ -0,0 +1,89 @@
+package runner
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pakkasys/fluidapi/endpoint/definition"
+	"github.com/stretchr/testify/assert"
+)
+
+func testListLinkBuilder() *definition.LinkBuilder {
+	return definition.NewLinkBuilder("", definition.EndpointDefinition{
+		Name:   "users.list",
+		URL:    "/v1/users",
+		Method: http.MethodGet,
+	})
+}
+
+// TestListLinks tests the pagination links of a list paginated with the
+// offset.
+func TestListLinks(t *testing.T) {
+	offset := 10
+	count := 25
+
+	links, err := ListLinks(
+		testListLinkBuilder(),
+		httptest.NewRequest(http.MethodGet, "/users?name=a&offset=10", nil),
+		"users.list",
+		nil,
+		&ListOutput[string]{
+			Items:  make([]string, 10),
+			Count:  &count,
+			Limit:  10,
+			Offset: &offset,
+		},
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(t, definition.Links{
+		"first": {
+			Href:   "/v1/users?limit=10&name=a&offset=0",
+			Method: http.MethodGet,
+		},
+		"prev": {
+			Href:   "/v1/users?limit=10&name=a&offset=0",
+			Method: http.MethodGet,
+		},
+		"next": {
+			Href:   "/v1/users?limit=10&name=a&offset=20",
+			Method: http.MethodGet,
+		},
+		"last": {
+			Href:   "/v1/users?limit=10&name=a&offset=20",
+			Method: http.MethodGet,
+		},
+	}, links)
+}
+
+// TestListLinks_Cursor tests the pagination links of a list paginated with
+// cursors.
+func TestListLinks_Cursor(t *testing.T) {
+	links, err := ListLinks(
+		testListLinkBuilder(),
+		httptest.NewRequest(http.MethodGet, "/users?cursor=b", nil),
+		"users.list",
+		nil,
+		&ListOutput[string]{Limit: 5, Next: "c"},
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(t, definition.Links{
+		"first": {Href: "/v1/users?limit=5&offset=0", Method: http.MethodGet},
+		"next":  {Href: "/v1/users?cursor=c&limit=5", Method: http.MethodGet},
+	}, links)
+}
+
+// TestListLinks_UnknownEndpoint tests that the endpoint must be registered.
+func TestListLinks_UnknownEndpoint(t *testing.T) {
+	_, err := ListLinks(
+		testListLinkBuilder(),
+		httptest.NewRequest(http.MethodGet, "/users", nil),
+		"users.search",
+		nil,
+		&ListOutput[string]{Limit: 5},
+	)
+
+	assert.EqualError(t, err, "unknown endpoint: users.search")
+}
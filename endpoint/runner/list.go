@@ -0,0 +1,294 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/pakkasys/fluidapi/database/entity"
+	"github.com/pakkasys/fluidapi/endpoint/page"
+)
+
+// ListOutput is the standard output of list endpoints. The page is described
+// by the limit and the offset or, if paginated with cursors, by the cursors
+// of the next and the previous pages.
+type ListOutput[T any] struct {
+	// Items are the items of the page
+	Items []T `json:"items"`
+	// Count is the total count of the items, set if requested
+	Count *int `json:"count,omitempty"`
+	// Limit is the maximum number of items of the page
+	Limit int `json:"limit"`
+	// Offset is the offset of the page, nil if paginated with cursors
+	Offset *int `json:"offset,omitempty"`
+	// Next is the cursor of the next page, empty if there is none
+	Next string `json:"next,omitempty"`
+	// Previous is the cursor of the previous page, empty if there is none
+	Previous string `json:"previous,omitempty"`
+}
+
+// ToListItemFunc represents a function type to convert an entity to an item
+// of the list output.
+type ToListItemFunc[Entity any, Item any] func(entity *Entity) Item
+
+// PageURLFunc returns the URL of a page of the request, used in the Link
+// header of the list responses.
+type PageURLFunc func(request *http.Request, p page.Page) string
+
+// ListOption configures ListInvoke.
+type ListOption func(*listOptions)
+
+type listOptions struct {
+	pageURLFn PageURLFunc
+}
+
+// WithPageURL sets the function returning the URLs of the Link header.
+// QueryPageURL is used by default.
+//
+//   - pageURLFn: The function returning the URL of a page.
+func WithPageURL(pageURLFn PageURLFunc) ListOption {
+	return func(o *listOptions) {
+		o.pageURLFn = pageURLFn
+	}
+}
+
+// QueryPageURL returns the URL of the request with the page set in the
+// "limit" and the "offset" or the "cursor" query parameters.
+//
+// Parameters:
+//   - request: The HTTP request.
+//   - p: The page.
+//
+// Returns:
+//   - The URL of the page.
+func QueryPageURL(request *http.Request, p page.Page) string {
+	url := *request.URL
+	url.RawQuery = pageQuery(url.Query(), p).Encode()
+	return url.String()
+}
+
+// pageQuery sets the page in the "limit" and the "offset" or the "cursor"
+// parameters of a query.
+func pageQuery(query url.Values, p page.Page) url.Values {
+	query.Set("limit", strconv.Itoa(p.Limit))
+	if p.Cursor != "" {
+		query.Del("offset")
+		query.Set("cursor", p.Cursor)
+	} else {
+		query.Del("cursor")
+		query.Set("offset", strconv.Itoa(p.Offset))
+	}
+	return query
+}
+
+// ListInvoke handles the invocation of a list endpoint. The entities are
+// paginated with cursors if valuesFn is set and with the offset otherwise.
+// Unlike GetInvoke, the total count is retrieved in addition to the items if
+// requested. The first, the previous, the next and the last pages are linked
+// in the Link header of the response (RFC 5988). The last page is linked only
+// if the count is known.
+//
+// Parameters:
+//   - writer: The HTTP response writer.
+//   - request: The HTTP request.
+//   - input: Input data to the endpoint, implementing ParseableInput.
+//   - serviceFn: Function to retrieve entities from the database.
+//   - getCountFn: Function to get the count of entities from the database.
+//   - valuesFn: Function returning the values of the order fields of an
+//     entity, or nil if the endpoint is paginated with the offset.
+//   - toItemFn: Function to convert an entity to a list item.
+//   - opts: Options such as WithPageURL.
+//
+// Returns:
+//   - Pointer to the list output or an error.
+func ListInvoke[I ParseableInput[ParsedGetEndpointInput], T any, E any](
+	writer http.ResponseWriter,
+	request *http.Request,
+	input I,
+	serviceFn GetServiceFunc[E],
+	getCountFn GetCountFunc,
+	valuesFn CursorValuesFunc[E],
+	toItemFn ToListItemFunc[E, T],
+	opts ...ListOption,
+) (*ListOutput[T], error) {
+	o := &listOptions{pageURLFn: QueryPageURL}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	parsedInput, err := input.Parse(request)
+	if err != nil {
+		return nil, err
+	}
+	parsedInput.CheckDeprecations(request.Context())
+	if parsedInput.Cursor != nil && valuesFn == nil {
+		return nil, page.InvalidCursorError
+	}
+	if serviceFn == nil {
+		return nil, fmt.Errorf("GetServiceFunc is nil")
+	}
+
+	count, err := listCount(request.Context(), parsedInput, getCountFn)
+	if err != nil {
+		return nil, err
+	}
+
+	output := &ListOutput[T]{Count: count, Limit: parsedInput.Page.Limit}
+	var entities []E
+	var links []pageLink
+	if valuesFn != nil {
+		var cursors page.Cursors
+		entities, cursors, err = runCursorGetService(
+			request.Context(),
+			parsedInput,
+			serviceFn,
+			valuesFn,
+		)
+		if err != nil {
+			return nil, err
+		}
+		output.Next = cursors.Next
+		output.Previous = cursors.Previous
+		links = cursorLinks(output.Limit, cursors)
+	} else {
+		entities, err = serviceFn(
+			request.Context(),
+			entity.GetOptions{
+				Options: entity.Options{
+					Selectors:   parsedInput.DatabaseSelectors,
+					Orders:      parsedInput.Orders,
+					Page:        parsedInput.Page,
+					Joins:       parsedInput.Joins,
+					Projections: parsedInput.Projections,
+				},
+			},
+		)
+		if err != nil {
+			return nil, err
+		}
+		err = runPreloads(request.Context(), parsedInput, entities)
+		if err != nil {
+			return nil, err
+		}
+		offset := parsedInput.Page.Offset
+		output.Offset = &offset
+		links = offsetLinks(*parsedInput.Page, len(entities), count)
+	}
+
+	output.Items = make([]T, len(entities))
+	for i := range entities {
+		output.Items[i] = toItemFn(&entities[i])
+	}
+
+	setLinkHeader(writer, request, links, o.pageURLFn)
+	return output, nil
+}
+
+// listCount returns the total count of the entities if requested. The keyset
+// selector of the cursor, which is the last selector, is not counted.
+func listCount(
+	ctx context.Context,
+	parsedInput *ParsedGetEndpointInput,
+	getCountFn GetCountFunc,
+) (*int, error) {
+	if !parsedInput.GetCount {
+		return nil, nil
+	}
+	if getCountFn == nil {
+		return nil, fmt.Errorf("GetCountFunc is nil")
+	}
+
+	selectors := parsedInput.DatabaseSelectors
+	if parsedInput.Cursor != nil {
+		selectors = selectors[:len(selectors)-1]
+	}
+	count, err := getCountFn(ctx, selectors, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &count, nil
+}
+
+// pageLink is a link to a page with its relation type.
+type pageLink struct {
+	rel  string
+	page page.Page
+}
+
+// offsetLinks returns the links of a page paginated with the offset. Without
+// the count, there is a next page if the page is full.
+func offsetLinks(p page.Page, itemCount int, count *int) []pageLink {
+	if p.Limit <= 0 {
+		return nil
+	}
+
+	links := []pageLink{{rel: "first", page: page.Page{Limit: p.Limit}}}
+	if p.Offset > 0 {
+		links = append(links, pageLink{
+			rel:  "prev",
+			page: page.Page{Offset: max(p.Offset-p.Limit, 0), Limit: p.Limit},
+		})
+	}
+	hasNext := itemCount >= p.Limit
+	if count != nil {
+		hasNext = p.Offset+p.Limit < *count
+	}
+	if hasNext {
+		links = append(links, pageLink{
+			rel:  "next",
+			page: page.Page{Offset: p.Offset + p.Limit, Limit: p.Limit},
+		})
+	}
+	if count != nil {
+		last := 0
+		if *count > 0 {
+			last = (*count - 1) / p.Limit * p.Limit
+		}
+		links = append(links, pageLink{
+			rel:  "last",
+			page: page.Page{Offset: last, Limit: p.Limit},
+		})
+	}
+	return links
+}
+
+// cursorLinks returns the links of a page paginated with cursors.
+func cursorLinks(limit int, cursors page.Cursors) []pageLink {
+	links := []pageLink{{rel: "first", page: page.Page{Limit: limit}}}
+	if cursors.Previous != "" {
+		links = append(links, pageLink{
+			rel:  "prev",
+			page: page.Page{Limit: limit, Cursor: cursors.Previous},
+		})
+	}
+	if cursors.Next != "" {
+		links = append(links, pageLink{
+			rel:  "next",
+			page: page.Page{Limit: limit, Cursor: cursors.Next},
+		})
+	}
+	return links
+}
+
+func setLinkHeader(
+	writer http.ResponseWriter,
+	request *http.Request,
+	links []pageLink,
+	pageURLFn PageURLFunc,
+) {
+	if len(links) == 0 {
+		return
+	}
+	values := make([]string, len(links))
+	for i, link := range links {
+		values[i] = fmt.Sprintf(
+			"<%s>; rel=%q",
+			pageURLFn(request, link.page),
+			link.rel,
+		)
+	}
+	writer.Header().Set("Link", strings.Join(values, ", "))
+}
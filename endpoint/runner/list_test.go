@@ -0,0 +1,181 @@
+package runner
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pakkasys/fluidapi/database/entity"
+	"github.com/pakkasys/fluidapi/database/util"
+	"github.com/pakkasys/fluidapi/endpoint/page"
+	"github.com/stretchr/testify/assert"
+)
+
+func listUserName(user *cursorUser) string {
+	return user.Name
+}
+
+// listInvoke invokes ListInvoke with a parsed input and the users returned by
+// the service and the count function.
+func listInvoke(
+	t *testing.T,
+	parsedInput *ParsedGetEndpointInput,
+	users []cursorUser,
+	count int,
+	valuesFn CursorValuesFunc[cursorUser],
+) (*ListOutput[string], *httptest.ResponseRecorder, []util.Selector) {
+	mockInput := new(MockParseableGetInput)
+	mockInput.On("Parse").Return(parsedInput, nil)
+	recorder := httptest.NewRecorder()
+	var countSelectors []util.Selector
+
+	output, err := ListInvoke(
+		recorder,
+		httptest.NewRequest(http.MethodGet, "/users?name=a", nil),
+		mockInput,
+		func(ctx context.Context, opts entity.GetOptions) ([]cursorUser, error) {
+			return users, nil
+		},
+		func(
+			ctx context.Context,
+			selectors []util.Selector,
+			joins []util.Join,
+		) (int, error) {
+			countSelectors = selectors
+			return count, nil
+		},
+		valuesFn,
+		listUserName,
+	)
+
+	assert.NoError(t, err)
+	return output, recorder, countSelectors
+}
+
+// TestListInvoke_Offset tests the output and the links of a page paginated
+// with the offset.
+func TestListInvoke_Offset(t *testing.T) {
+	output, recorder, _ := listInvoke(
+		t,
+		&ParsedGetEndpointInput{
+			Page:     &page.Page{Offset: 2, Limit: 2},
+			GetCount: true,
+		},
+		[]cursorUser{{3, "c"}, {4, "d"}},
+		5,
+		nil,
+	)
+
+	offset := 2
+	count := 5
+	assert.Equal(t, &ListOutput[string]{
+		Items:  []string{"c", "d"},
+		Count:  &count,
+		Limit:  2,
+		Offset: &offset,
+	}, output)
+	assert.Equal(
+		t,
+		`</users?limit=2&name=a&offset=0>; rel="first", `+
+			`</users?limit=2&name=a&offset=0>; rel="prev", `+
+			`</users?limit=2&name=a&offset=4>; rel="next", `+
+			`</users?limit=2&name=a&offset=4>; rel="last"`,
+		recorder.Header().Get("Link"),
+	)
+}
+
+// TestListInvoke_Offset_WithoutCount tests that there is a next page if the
+// page is full when the count is not requested.
+func TestListInvoke_Offset_WithoutCount(t *testing.T) {
+	output, recorder, _ := listInvoke(
+		t,
+		&ParsedGetEndpointInput{Page: &page.Page{Limit: 2}},
+		[]cursorUser{{1, "a"}},
+		0,
+		nil,
+	)
+
+	assert.Nil(t, output.Count)
+	assert.Equal(t, []string{"a"}, output.Items)
+	assert.Equal(
+		t,
+		`</users?limit=2&name=a&offset=0>; rel="first"`,
+		recorder.Header().Get("Link"),
+	)
+}
+
+// TestListInvoke_Cursor tests the output and the links of a page paginated
+// with cursors.
+func TestListInvoke_Cursor(t *testing.T) {
+	cursor := &page.Cursor{Keys: []page.CursorKey{{Field: "id", Value: 1}}}
+	token, err := cursor.Encode()
+	assert.NoError(t, err)
+	parsedInput, err := ParseGetEndpointInput(
+		cursorAPIFields,
+		nil,
+		nil,
+		[]string{"id"},
+		&page.Page{Limit: 1, Cursor: token},
+		10,
+		true,
+	)
+	assert.NoError(t, err)
+
+	output, recorder, countSelectors := listInvoke(
+		t,
+		parsedInput,
+		[]cursorUser{{2, "b"}, {3, "c"}},
+		3,
+		cursorUserValues,
+	)
+
+	assert.Empty(t, countSelectors)
+	assert.Equal(t, 3, *output.Count)
+	assert.Nil(t, output.Offset)
+	assert.Equal(t, []string{"b"}, output.Items)
+	assert.Equal(t, int64(2), decodeTestCursor(t, output.Next).Keys[0].Value)
+	assert.True(t, decodeTestCursor(t, output.Previous).Before)
+	assert.Equal(
+		t,
+		`</users?limit=1&name=a&offset=0>; rel="first", `+
+			`</users?cursor=`+output.Previous+`&limit=1&name=a>; rel="prev", `+
+			`</users?cursor=`+output.Next+`&limit=1&name=a>; rel="next"`,
+		recorder.Header().Get("Link"),
+	)
+}
+
+// TestListInvoke_CursorNotSupported tests that a cursor is invalid if the
+// endpoint is paginated with the offset.
+func TestListInvoke_CursorNotSupported(t *testing.T) {
+	mockInput := new(MockParseableGetInput)
+	mockInput.On("Parse").Return(&ParsedGetEndpointInput{
+		Page:   &page.Page{Limit: 1},
+		Cursor: &page.Cursor{},
+	}, nil)
+
+	output, err := ListInvoke(
+		httptest.NewRecorder(),
+		httptest.NewRequest(http.MethodGet, "/users", nil),
+		mockInput,
+		MockGetServiceFunc,
+		nil,
+		nil,
+		func(item *string) string { return *item },
+	)
+
+	assert.Nil(t, output)
+	assert.ErrorIs(t, err, page.InvalidCursorError)
+}
+
+// TestOffsetLinks tests the links of the offset pages.
+func TestOffsetLinks(t *testing.T) {
+	zero := 0
+	links := offsetLinks(page.Page{Limit: 10}, 0, &zero)
+	assert.Equal(t, []pageLink{
+		{rel: "first", page: page.Page{Limit: 10}},
+		{rel: "last", page: page.Page{Limit: 10}},
+	}, links)
+
+	assert.Nil(t, offsetLinks(page.Page{Limit: 0}, 0, nil))
+}
@@ -0,0 +1,129 @@
+package runner
+
+import (
+	"fmt"
+	"reflect"
+
+	databaseutil "github.com/pakkasys/fluidapi/database/util"
+	"github.com/pakkasys/fluidapi/endpoint/middleware/inputlogic"
+	"github.com/pakkasys/fluidapi/endpoint/order"
+	"github.com/pakkasys/fluidapi/endpoint/predicate"
+	"github.com/pakkasys/fluidapi/endpoint/selector"
+)
+
+// QueryLimits limits the complexity of the queries of GET requests, so that
+// a single request can not generate a pathological query. A zero limit is
+// unlimited. The violations are returned as inputlogic.ValidationError.
+type QueryLimits struct {
+	// MaxSelectors is the maximum number of selectors
+	MaxSelectors int
+	// MaxOrders is the maximum number of orders
+	MaxOrders int
+	// MaxJoins is the maximum number of joins of the query, i.e. the joins
+	// of the included relations
+	MaxJoins int
+	// MaxInListLength is the maximum number of values of an IN or NOT_IN
+	// selector
+	MaxInListLength int
+}
+
+// GetInputOption configures ParseGetEndpointInput.
+type GetInputOption func(*getInputOptions)
+
+type getInputOptions struct {
+	limits QueryLimits
+	fields []string
+	sort   string
+	// resolveIncludes adds the included relations to the parsed input
+	resolveIncludes func(*ParsedGetEndpointInput) error
+}
+
+// WithQueryLimits sets the complexity limits of the parsed query.
+//
+//   - limits: The query limits.
+func WithQueryLimits(limits QueryLimits) GetInputOption {
+	return func(o *getInputOptions) {
+		o.limits = limits
+	}
+}
+
+// checkInput checks the limits of the selectors and the orders of the
+// request before they are translated. The selectors of the groups of OR
+// selectors are counted as well.
+func (l QueryLimits) checkInput(
+	selectors []selector.Selector,
+	orders []order.Order,
+) error {
+	selectors = flattenSelectors(selectors)
+	var fieldErrors []inputlogic.FieldError
+	if l.MaxSelectors > 0 && len(selectors) > l.MaxSelectors {
+		fieldErrors = append(fieldErrors, inputlogic.FieldError{
+			Field:   "selectors",
+			Message: fmt.Sprintf("too many selectors, max %d", l.MaxSelectors),
+		})
+	}
+	if l.MaxOrders > 0 && len(orders) > l.MaxOrders {
+		fieldErrors = append(fieldErrors, inputlogic.FieldError{
+			Field:   "orders",
+			Message: fmt.Sprintf("too many orders, max %d", l.MaxOrders),
+		})
+	}
+	if l.MaxInListLength > 0 {
+		for _, s := range selectors {
+			if s.Predicate != predicate.IN && s.Predicate != predicate.NOT_IN {
+				continue
+			}
+			value := reflect.ValueOf(s.Value)
+			if value.Kind() != reflect.Slice && value.Kind() != reflect.Array {
+				continue
+			}
+			if value.Len() > l.MaxInListLength {
+				fieldErrors = append(fieldErrors, inputlogic.FieldError{
+					Field: s.Field,
+					Message: fmt.Sprintf(
+						"too many values, max %d",
+						l.MaxInListLength,
+					),
+				})
+			}
+		}
+	}
+	return limitsError(fieldErrors)
+}
+
+// checkJoins checks the number of joins of the parsed input.
+func (l QueryLimits) checkJoins(joins []databaseutil.Join) error {
+	if l.MaxJoins <= 0 || len(joins) <= l.MaxJoins {
+		return nil
+	}
+	return limitsError([]inputlogic.FieldError{{
+		Field:   "include",
+		Message: fmt.Sprintf("too many joins, max %d", l.MaxJoins),
+	}})
+}
+
+// flattenSelectors returns the selectors with the OR selectors replaced by
+// the selectors of their groups.
+func flattenSelectors(selectors []selector.Selector) []selector.Selector {
+	var flattened []selector.Selector
+	for _, s := range selectors {
+		groups, ok := s.Value.([][]selector.Selector)
+		if s.Predicate != predicate.OR || !ok {
+			flattened = append(flattened, s)
+			continue
+		}
+		for _, group := range groups {
+			flattened = append(flattened, flattenSelectors(group)...)
+		}
+	}
+	return flattened
+}
+
+func limitsError(fieldErrors []inputlogic.FieldError) error {
+	if len(fieldErrors) == 0 {
+		return nil
+	}
+	return inputlogic.ValidationError.WithData(
+		inputlogic.ValidationErrorData{Errors: fieldErrors},
+	)
+}
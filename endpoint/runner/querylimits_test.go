@@ -0,0 +1,179 @@
+package runner
+
+import (
+	"testing"
+
+	"github.com/pakkasys/fluidapi/core/api"
+	databaseutil "github.com/pakkasys/fluidapi/database/util"
+	"github.com/pakkasys/fluidapi/endpoint/middleware/inputlogic"
+	"github.com/pakkasys/fluidapi/endpoint/order"
+	"github.com/pakkasys/fluidapi/endpoint/predicate"
+	"github.com/pakkasys/fluidapi/endpoint/selector"
+	"github.com/stretchr/testify/assert"
+)
+
+var limitsAPIFields = APIFields{
+	"name":  {Table: "user", Column: "name"},
+	"age":   {Table: "user", Column: "age"},
+	"group": {Table: "group", Column: "name"},
+	"role":  {Table: "role", Column: "name"},
+}
+
+// limitsRelations are the relations joining the group and role tables.
+var limitsRelations = Relations[any]{
+	"group": {Joins: []databaseutil.Join{limitsJoin("group")}},
+	"role":  {Joins: []databaseutil.Join{limitsJoin("role")}},
+}
+
+func limitsJoin(table string) databaseutil.Join {
+	return databaseutil.Join{
+		Type:    databaseutil.JoinTypeLeft,
+		Table:   table,
+		OnLeft:  databaseutil.ColumSelector{Table: "user", Column: table},
+		OnRight: databaseutil.ColumSelector{Table: table, Column: "id"},
+	}
+}
+
+func limitsSelector(
+	field string,
+	p predicate.Predicate,
+	value any,
+) selector.Selector {
+	return selector.Selector{
+		AllowedPredicates: []predicate.Predicate{p},
+		Field:             field,
+		Predicate:         p,
+		Value:             value,
+	}
+}
+
+// TestParseGetEndpointInput_QueryLimits tests the field errors of the query
+// limits.
+func TestParseGetEndpointInput_QueryLimits(t *testing.T) {
+	tests := []struct {
+		name      string
+		limits    QueryLimits
+		selectors []selector.Selector
+		orders    []order.Order
+		includes  []string
+		expected  []inputlogic.FieldError
+	}{
+		{
+			name: "within limits",
+			limits: QueryLimits{
+				MaxSelectors:    1,
+				MaxOrders:       1,
+				MaxJoins:        1,
+				MaxInListLength: 2,
+			},
+			selectors: []selector.Selector{
+				limitsSelector("name", predicate.IN, []string{"a", "b"}),
+			},
+			orders: []order.Order{
+				{Field: "group", Direction: order.DIRECTION_ASC},
+			},
+		},
+		{
+			name:   "too many selectors",
+			limits: QueryLimits{MaxSelectors: 1},
+			selectors: []selector.Selector{
+				limitsSelector("name", predicate.EQUAL, "a"),
+				limitsSelector("age", predicate.EQUAL, 1),
+			},
+			expected: []inputlogic.FieldError{
+				{Field: "selectors", Message: "too many selectors, max 1"},
+			},
+		},
+		{
+			name:   "too many selectors in OR groups",
+			limits: QueryLimits{MaxSelectors: 1},
+			selectors: []selector.Selector{
+				limitsSelector(
+					"",
+					predicate.OR,
+					[][]selector.Selector{
+						{limitsSelector("name", predicate.EQUAL, "a")},
+						{limitsSelector("age", predicate.EQUAL, 1)},
+					},
+				),
+			},
+			expected: []inputlogic.FieldError{
+				{Field: "selectors", Message: "too many selectors, max 1"},
+			},
+		},
+		{
+			name:   "too many orders",
+			limits: QueryLimits{MaxOrders: 1},
+			orders: []order.Order{
+				{Field: "name", Direction: order.DIRECTION_ASC},
+				{Field: "age", Direction: order.DIRECTION_DESC},
+			},
+			expected: []inputlogic.FieldError{
+				{Field: "orders", Message: "too many orders, max 1"},
+			},
+		},
+		{
+			name:   "too many IN values",
+			limits: QueryLimits{MaxInListLength: 2},
+			selectors: []selector.Selector{
+				limitsSelector("name", predicate.EQUAL, "abc"),
+				limitsSelector("age", predicate.NOT_IN, []int{1, 2, 3}),
+			},
+			expected: []inputlogic.FieldError{
+				{Field: "age", Message: "too many values, max 2"},
+			},
+		},
+		{
+			name:     "within join limit",
+			limits:   QueryLimits{MaxJoins: 1},
+			includes: []string{"group"},
+		},
+		{
+			name:   "tables of selectors and orders",
+			limits: QueryLimits{MaxJoins: 1},
+			selectors: []selector.Selector{
+				limitsSelector("name", predicate.EQUAL, "a"),
+				limitsSelector("group", predicate.EQUAL, "b"),
+			},
+			orders: []order.Order{
+				{Field: "role", Direction: order.DIRECTION_ASC},
+			},
+			includes: []string{"group"},
+		},
+		{
+			name:     "too many joins",
+			limits:   QueryLimits{MaxJoins: 1},
+			includes: []string{"group", "role"},
+			expected: []inputlogic.FieldError{
+				{Field: "include", Message: "too many joins, max 1"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			parsed, err := ParseGetEndpointInput(
+				limitsAPIFields,
+				test.selectors,
+				test.orders,
+				[]string{"name", "age", "group", "role"},
+				nil,
+				10,
+				false,
+				WithQueryLimits(test.limits),
+				WithIncludes(test.includes, limitsRelations),
+			)
+
+			if test.expected == nil {
+				assert.NoError(t, err)
+				assert.NotNil(t, parsed)
+				return
+			}
+			assert.Nil(t, parsed)
+			apiErr, ok := err.(*api.Error[inputlogic.ValidationErrorData])
+			assert.True(t, ok)
+			assert.Equal(t, inputlogic.ValidationError.ID, apiErr.ID)
+			assert.Equal(t, test.expected, apiErr.Data.Errors)
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package runner
+
+import (
+	"net/http"
+
+	"github.com/pakkasys/fluidapi/core/readonly"
+	"github.com/pakkasys/fluidapi/endpoint/middleware/inputlogic"
+)
+
+// ReadOnlyInput is the input of the read-only mode endpoint.
+type ReadOnlyInput struct {
+	// Enabled sets the read-only mode. If nil, the mode is left unchanged.
+	Enabled *bool `json:"enabled"`
+}
+
+// Validate validates the input.
+func (i ReadOnlyInput) Validate() []inputlogic.FieldError {
+	return nil
+}
+
+// ReadOnlyOutput is the output of the read-only mode endpoint.
+type ReadOnlyOutput struct {
+	// Enabled is the current state of the read-only mode.
+	Enabled bool `json:"enabled"`
+}
+
+// ReadOnlyEndpointDefinition creates an administrative endpoint definition
+// for reading and toggling the read-only mode. The endpoint itself is exempt
+// from the read-only mode so that it can be used to disable it.
+//
+// Parameters:
+//   - specification: The input specification of the endpoint.
+//   - stackBuilder: A middleware stack.
+//   - opts: Options for configuring the input logic.
+//   - sendFn: Function to send the request.
+//   - options: Additional endpoint options to configure.
+//
+// Returns:
+//   - An Endpoint instance.
+func ReadOnlyEndpointDefinition[W any](
+	specification InputSpecification[ReadOnlyInput],
+	stackBuilder StackBuilder,
+	opts inputlogic.Options[ReadOnlyInput],
+	sendFn SendFunc[ReadOnlyInput, W],
+	options ...EndpointOption[ReadOnlyInput, ReadOnlyOutput, W],
+) *Endpoint[ReadOnlyInput, ReadOnlyOutput, W] {
+	callback := func(
+		writer http.ResponseWriter,
+		request *http.Request,
+		input *ReadOnlyInput,
+	) (*ReadOnlyOutput, error) {
+		if input.Enabled != nil {
+			readonly.Set(*input.Enabled)
+		}
+		return &ReadOnlyOutput{Enabled: readonly.IsEnabled()}, nil
+	}
+
+	return genericEndpointDefinition(
+		specification,
+		callback,
+		nil,
+		stackBuilder,
+		opts,
+		sendFn,
+		options...,
+	)
+}
+
+// readOnlyGuard wraps a callback so that it returns
+// readonly.ServiceReadOnlyError for mutating HTTP methods while the read-only
+// mode is enabled.
+func readOnlyGuard[I any, O any](
+	method string,
+	callback inputlogic.Callback[I, O],
+) inputlogic.Callback[I, O] {
+	if !readonly.IsMutatingMethod(method) {
+		return callback
+	}
+	return func(w http.ResponseWriter, r *http.Request, input *I) (*O, error) {
+		if err := readonly.Check(); err != nil {
+			return nil, err
+		}
+		return callback(w, r, input)
+	}
+}
@@ -0,0 +1,135 @@
+package runner
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pakkasys/fluidapi/core/api"
+	"github.com/pakkasys/fluidapi/core/client"
+	"github.com/pakkasys/fluidapi/core/readonly"
+	"github.com/pakkasys/fluidapi/endpoint/middleware"
+	"github.com/pakkasys/fluidapi/endpoint/middleware/inputlogic"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func readOnlyTestCallback(
+	calls *int,
+) inputlogic.Callback[MockValidatedInput, string] {
+	return func(
+		w http.ResponseWriter,
+		r *http.Request,
+		i *MockValidatedInput,
+	) (*string, error) {
+		*calls++
+		output := "output"
+		return &output, nil
+	}
+}
+
+// TestReadOnlyGuard_NonMutatingMethod tests that callbacks of non-mutating
+// methods run while the read-only mode is enabled.
+func TestReadOnlyGuard_NonMutatingMethod(t *testing.T) {
+	readonly.Enable()
+	defer readonly.Disable()
+
+	calls := 0
+	callback := readOnlyGuard(http.MethodGet, readOnlyTestCallback(&calls))
+
+	output, err := callback(nil, nil, &MockValidatedInput{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "output", *output)
+	assert.Equal(t, 1, calls)
+}
+
+// TestReadOnlyGuard_MutatingMethod tests that callbacks of mutating methods
+// return the read-only error while the read-only mode is enabled.
+func TestReadOnlyGuard_MutatingMethod(t *testing.T) {
+	calls := 0
+	callback := readOnlyGuard(http.MethodPost, readOnlyTestCallback(&calls))
+
+	readonly.Enable()
+	defer readonly.Disable()
+
+	output, err := callback(nil, nil, &MockValidatedInput{})
+
+	assert.Nil(t, output)
+	assert.Equal(t, readonly.ServiceReadOnlyError, err)
+	assert.Equal(t, 0, calls)
+}
+
+// TestReadOnlyGuard_Disabled tests that callbacks of mutating methods run
+// while the read-only mode is disabled.
+func TestReadOnlyGuard_Disabled(t *testing.T) {
+	calls := 0
+	callback := readOnlyGuard(http.MethodDelete, readOnlyTestCallback(&calls))
+
+	_, err := callback(nil, nil, &MockValidatedInput{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, calls)
+}
+
+// TestReadOnlyEndpointDefinition tests that the read-only endpoint toggles
+// the read-only mode even while the mode is enabled.
+func TestReadOnlyEndpointDefinition(t *testing.T) {
+	readonly.Enable()
+	defer readonly.Disable()
+
+	disabled := false
+	specification := InputSpecification[ReadOnlyInput]{
+		URL:    "/admin/read-only",
+		Method: http.MethodPut,
+		InputFactory: func() *ReadOnlyInput {
+			return &ReadOnlyInput{}
+		},
+	}
+
+	stackBuilder := new(MockStackBuilder)
+	stackBuilder.On("MustAddMiddleware", mock.Anything).Return(stackBuilder)
+	stackBuilder.On("Build").Return(middleware.Stack{})
+
+	mockObjectPicker := new(MockObjectPicker[ReadOnlyInput])
+	mockObjectPicker.
+		On("PickObject", mock.Anything, mock.Anything, mock.Anything).
+		Return(&ReadOnlyInput{Enabled: &disabled}, nil)
+
+	mockOutputHandler := new(MockOutputHandler)
+	mockOutputHandler.On(
+		"ProcessOutput",
+		mock.Anything,
+		mock.Anything,
+		&ReadOnlyOutput{Enabled: false},
+		nil,
+		http.StatusOK,
+	).Return(nil)
+
+	endpoint := ReadOnlyEndpointDefinition(
+		specification,
+		stackBuilder,
+		inputlogic.Options[ReadOnlyInput]{
+			ObjectPicker:  mockObjectPicker,
+			OutputHandler: mockOutputHandler,
+		},
+		func(
+			input *ReadOnlyInput,
+			host string,
+		) (*client.Response[ReadOnlyInput, any], error) {
+			return nil, nil
+		},
+	)
+
+	handler := api.ApplyMiddlewares(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}),
+		endpoint.Definition.MiddlewareStack.Middlewares()...,
+	)
+	handler.ServeHTTP(
+		httptest.NewRecorder(),
+		httptest.NewRequest(http.MethodPut, "/admin/read-only", nil),
+	)
+
+	assert.False(t, readonly.IsEnabled())
+	mockOutputHandler.AssertExpectations(t)
+}
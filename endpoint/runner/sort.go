@@ -0,0 +1,63 @@
+package runner
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/pakkasys/fluidapi/endpoint/order"
+)
+
+// WithSort sets a compact sort expression of the request, e.g. the value of
+// a "sort" query parameter, see ParseSort. The orders of the expression are
+// added after the structured orders of the request.
+//
+//   - sort: The sort expression.
+func WithSort(sort string) GetInputOption {
+	return func(o *getInputOptions) {
+		o.sort = sort
+	}
+}
+
+// ParseSort parses a compact sort expression into orders. The expression is
+// a comma-separated list of fields, which are sorted in descending order if
+// prefixed with a minus and in ascending order otherwise, e.g.
+// "-created_at,name".
+//
+// Parameters:
+//   - sort: The sort expression.
+//   - allowedOrderFields: The fields that are allowed to be used for ordering.
+//
+// Returns:
+//   - The orders of the expression, or nil if the expression is empty.
+//   - InvalidOrderFieldError if a field is empty or not allowed.
+func ParseSort(
+	sort string,
+	allowedOrderFields []string,
+) ([]order.Order, error) {
+	if strings.TrimSpace(sort) == "" {
+		return nil, nil
+	}
+
+	var orders []order.Order
+	for _, part := range strings.Split(sort, ",") {
+		field := strings.TrimSpace(part)
+		direction := order.DIRECTION_ASC
+		if strings.HasPrefix(field, "-") {
+			field = field[1:]
+			direction = order.DIRECTION_DESC
+		} else {
+			field = strings.TrimPrefix(field, "+")
+		}
+
+		if !slices.Contains(allowedOrderFields, field) {
+			return nil, order.InvalidOrderFieldError.WithData(
+				order.InvalidOrderFieldErrorData{Field: field},
+			)
+		}
+		orders = append(orders, order.Order{
+			Field:     field,
+			Direction: direction,
+		})
+	}
+	return orders, nil
+}
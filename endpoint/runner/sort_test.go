@@ -0,0 +1,76 @@
+package runner
+
+import (
+	"testing"
+
+	"github.com/pakkasys/fluidapi/core/api"
+	"github.com/pakkasys/fluidapi/database/util"
+	"github.com/pakkasys/fluidapi/endpoint/order"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestParseSort tests parsing sort expressions.
+func TestParseSort(t *testing.T) {
+	orders, err := ParseSort(" -created_at, name,+id", []string{
+		"created_at",
+		"name",
+		"id",
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []order.Order{
+		{Field: "created_at", Direction: order.DIRECTION_DESC},
+		{Field: "name", Direction: order.DIRECTION_ASC},
+		{Field: "id", Direction: order.DIRECTION_ASC},
+	}, orders)
+}
+
+// TestParseSort_Empty tests that an empty expression has no orders.
+func TestParseSort_Empty(t *testing.T) {
+	orders, err := ParseSort(" ", []string{"name"})
+
+	assert.NoError(t, err)
+	assert.Nil(t, orders)
+}
+
+// TestParseSort_InvalidField tests that the fields must be allowed.
+func TestParseSort_InvalidField(t *testing.T) {
+	tests := map[string]string{
+		"-password": "password",
+		"name,,id":  "",
+		"name,-":    "",
+	}
+
+	for sort, field := range tests {
+		t.Run(sort, func(t *testing.T) {
+			orders, err := ParseSort(sort, []string{"name", "id"})
+
+			assert.Nil(t, orders)
+			apiErr, ok := err.(*api.Error[order.InvalidOrderFieldErrorData])
+			assert.True(t, ok)
+			assert.Equal(t, order.InvalidOrderFieldError.ID, apiErr.ID)
+			assert.Equal(t, field, apiErr.Data.Field)
+		})
+	}
+}
+
+// TestParseGetEndpointInput_Sort tests that the orders of the sort
+// expression are added after the structured orders.
+func TestParseGetEndpointInput_Sort(t *testing.T) {
+	parsed, err := ParseGetEndpointInput(
+		fieldsAPIFields,
+		nil,
+		[]order.Order{{Field: "name", Direction: order.DIRECTION_ASC}},
+		[]string{"name", "id"},
+		nil,
+		10,
+		false,
+		WithSort("-id,-name"),
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []util.Order{
+		{Table: "user", Field: "name", Direction: util.OrderAsc},
+		{Table: "user", Field: "id", Direction: util.OrderDesc},
+	}, parsed.Orders)
+}
@@ -0,0 +1,71 @@
+package runner
+
+import (
+	"fmt"
+
+	"github.com/pakkasys/fluidapi/core/api"
+	"github.com/pakkasys/fluidapi/endpoint/middleware"
+)
+
+// OrderedStackBuilder is a StackBuilder ordering the middlewares by their
+// dependencies and priorities, see middleware.Stack.Ordered. Adding
+// middlewares returns a new builder, so a builder of the common middlewares
+// can be shared by the endpoints.
+type OrderedStackBuilder struct {
+	stack middleware.Stack
+}
+
+// NewOrderedStackBuilder creates a new stack builder. It panics if the
+// middlewares have duplicate IDs.
+//
+//   - wrappers: The common middlewares of the endpoints.
+func NewOrderedStackBuilder(
+	wrappers ...api.MiddlewareWrapper,
+) *OrderedStackBuilder {
+	builder := &OrderedStackBuilder{}
+	builder.stack = builder.with(wrappers)
+	return builder
+}
+
+// MustAddMiddleware returns a new builder with the middlewares added. It
+// panics if a middleware ID is already in the stack.
+//
+//   - wrappers: The middlewares to add.
+func (b *OrderedStackBuilder) MustAddMiddleware(
+	wrappers ...api.MiddlewareWrapper,
+) StackBuilder {
+	return &OrderedStackBuilder{stack: b.with(wrappers)}
+}
+
+// Validate returns an error if a dependency of a middleware is not in the
+// stack or the dependencies form a cycle.
+func (b *OrderedStackBuilder) Validate() error {
+	_, err := b.stack.Ordered()
+	return err
+}
+
+// Build returns the ordered middleware stack. It panics if the stack is not
+// valid, see Validate.
+func (b *OrderedStackBuilder) Build() middleware.Stack {
+	stack, err := b.stack.Ordered()
+	if err != nil {
+		panic(err.Error())
+	}
+	return stack
+}
+
+// with returns a copy of the stack with the middlewares added.
+func (b *OrderedStackBuilder) with(
+	wrappers []api.MiddlewareWrapper,
+) middleware.Stack {
+	stack := append(middleware.Stack{}, b.stack...)
+	for _, wrapper := range wrappers {
+		for _, mw := range stack {
+			if wrapper.ID != "" && mw.ID == wrapper.ID {
+				panic(fmt.Sprintf("duplicate middleware ID %q", wrapper.ID))
+			}
+		}
+		stack = append(stack, wrapper)
+	}
+	return stack
+}
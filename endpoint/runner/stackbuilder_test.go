@@ -0,0 +1,52 @@
+package runner
+
+import (
+	"testing"
+
+	"github.com/pakkasys/fluidapi/core/api"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestOrderedStackBuilder tests building ordered stacks from a shared
+// builder.
+func TestOrderedStackBuilder(t *testing.T) {
+	builder := NewOrderedStackBuilder(
+		api.MiddlewareWrapper{ID: "access_log", After: []string{"request_id"}},
+		api.MiddlewareWrapper{ID: "request_id"},
+	)
+
+	stack := builder.MustAddMiddleware(
+		api.MiddlewareWrapper{ID: "input_logic", Priority: 100},
+		api.MiddlewareWrapper{ID: "auth"},
+	).Build()
+
+	ids := []string{}
+	for _, mw := range stack {
+		ids = append(ids, mw.ID)
+	}
+	assert.Equal(
+		t,
+		[]string{"request_id", "access_log", "auth", "input_logic"},
+		ids,
+	)
+	assert.Len(t, builder.Build(), 2, "shared builder should not change")
+}
+
+// TestOrderedStackBuilder_Invalid tests the errors and panics of invalid
+// stacks.
+func TestOrderedStackBuilder_Invalid(t *testing.T) {
+	builder := NewOrderedStackBuilder(
+		api.MiddlewareWrapper{ID: "access_log", After: []string{"request_id"}},
+	)
+
+	assert.EqualError(
+		t,
+		builder.Validate(),
+		`middleware "access_log" must run after missing middleware `+
+			`"request_id"`,
+	)
+	assert.Panics(t, func() { builder.Build() })
+	assert.PanicsWithValue(t, `duplicate middleware ID "access_log"`, func() {
+		builder.MustAddMiddleware(api.MiddlewareWrapper{ID: "access_log"})
+	})
+}
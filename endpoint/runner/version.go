@@ -0,0 +1,55 @@
+package runner
+
+import (
+	"net/http"
+
+	"github.com/pakkasys/fluidapi/core/buildinfo"
+	"github.com/pakkasys/fluidapi/endpoint/middleware/inputlogic"
+)
+
+// VersionInput is the input of the version endpoint.
+type VersionInput struct{}
+
+// Validate validates the input.
+func (i VersionInput) Validate() []inputlogic.FieldError {
+	return nil
+}
+
+// VersionEndpointDefinition creates an endpoint definition that returns the
+// build information of the running service.
+//
+// Parameters:
+//   - specification: The input specification of the endpoint.
+//   - stackBuilder: A middleware stack.
+//   - opts: Options for configuring the input logic.
+//   - sendFn: Function to send the request.
+//   - options: Additional endpoint options to configure.
+//
+// Returns:
+//   - An Endpoint instance.
+func VersionEndpointDefinition[W any](
+	specification InputSpecification[VersionInput],
+	stackBuilder StackBuilder,
+	opts inputlogic.Options[VersionInput],
+	sendFn SendFunc[VersionInput, W],
+	options ...EndpointOption[VersionInput, buildinfo.Info, W],
+) *Endpoint[VersionInput, buildinfo.Info, W] {
+	callback := func(
+		writer http.ResponseWriter,
+		request *http.Request,
+		input *VersionInput,
+	) (*buildinfo.Info, error) {
+		info := buildinfo.Get()
+		return &info, nil
+	}
+
+	return GenericEndpointDefinition(
+		specification,
+		callback,
+		nil,
+		stackBuilder,
+		opts,
+		sendFn,
+		options...,
+	)
+}
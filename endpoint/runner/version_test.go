@@ -0,0 +1,76 @@
+package runner
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pakkasys/fluidapi/core/api"
+	"github.com/pakkasys/fluidapi/core/buildinfo"
+	"github.com/pakkasys/fluidapi/core/client"
+	"github.com/pakkasys/fluidapi/endpoint/middleware"
+	"github.com/pakkasys/fluidapi/endpoint/middleware/inputlogic"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// TestVersionEndpointDefinition tests that the version endpoint outputs the
+// build information.
+func TestVersionEndpointDefinition(t *testing.T) {
+	specification := InputSpecification[VersionInput]{
+		URL:    "/version",
+		Method: http.MethodGet,
+		InputFactory: func() *VersionInput {
+			return &VersionInput{}
+		},
+	}
+
+	stackBuilder := new(MockStackBuilder)
+	stackBuilder.On("MustAddMiddleware", mock.Anything).Return(stackBuilder)
+	stackBuilder.On("Build").Return(middleware.Stack{})
+
+	mockObjectPicker := new(MockObjectPicker[VersionInput])
+	mockObjectPicker.
+		On("PickObject", mock.Anything, mock.Anything, mock.Anything).
+		Return(&VersionInput{}, nil)
+
+	info := buildinfo.Get()
+	mockOutputHandler := new(MockOutputHandler)
+	mockOutputHandler.On(
+		"ProcessOutput",
+		mock.Anything,
+		mock.Anything,
+		&info,
+		nil,
+		http.StatusOK,
+	).Return(nil)
+
+	endpoint := VersionEndpointDefinition(
+		specification,
+		stackBuilder,
+		inputlogic.Options[VersionInput]{
+			ObjectPicker:  mockObjectPicker,
+			OutputHandler: mockOutputHandler,
+		},
+		func(
+			input *VersionInput,
+			host string,
+		) (*client.Response[VersionInput, any], error) {
+			return nil, nil
+		},
+	)
+
+	assert.Equal(t, "/version", endpoint.Definition.URL)
+	assert.Equal(t, http.MethodGet, endpoint.Definition.Method)
+
+	handler := api.ApplyMiddlewares(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}),
+		endpoint.Definition.MiddlewareStack.Middlewares()...,
+	)
+	handler.ServeHTTP(
+		httptest.NewRecorder(),
+		httptest.NewRequest(http.MethodGet, "/version", nil),
+	)
+
+	mockOutputHandler.AssertExpectations(t)
+}
@@ -0,0 +1,422 @@
+package selector
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/pakkasys/fluidapi/core/api"
+	"github.com/pakkasys/fluidapi/endpoint/dbfield"
+	"github.com/pakkasys/fluidapi/endpoint/predicate"
+)
+
+type InvalidFilterErrorData struct {
+	Position int    `json:"position"`
+	Message  string `json:"message"`
+}
+
+var InvalidFilterError = api.NewError[InvalidFilterErrorData]("INVALID_FILTER")
+
+// maxFilterDepth is the maximum nesting depth of the parentheses of a filter.
+const maxFilterDepth = 16
+
+// ParseFilter parses a filter expression, e.g. the value of a "filter" query
+// parameter, into selectors. It is meant for clients that can not easily
+// construct the structured selectors.
+//
+// The comparisons are joined with AND and OR, and grouped with parentheses.
+// AND binds tighter than OR. The operators are =, !=, >, >=, <, <=, ~ (LIKE),
+// IN and NOT IN. The values are strings in double quotes, numbers, true, false
+// and null, or lists of them in parentheses for IN and NOT IN. null is only
+// allowed with = and !=. The keywords are case-insensitive.
+//
+// Example:
+//
+//	age>30 AND (name~"Ali%" OR vip=true) AND status IN ("a", "b")
+//
+// The top level comparisons are returned as separate selectors, which are
+// joined with AND. OR expressions are returned as selectors with the OR
+// predicate.
+//
+// Parameters:
+//   - filter: The filter expression.
+//   - apiFields: A mapping of API fields to corresponding database fields.
+//   - allowedPredicates: The allowed predicates of the filterable fields.
+//
+// Returns:
+//   - The selectors of the filter, or nil if the filter is empty.
+//   - InvalidFilterError if the filter is malformed.
+//   - InvalidSelectorFieldError if a field is unknown or not filterable.
+//   - PredicateNotAllowedError if a predicate is not allowed for a field.
+func ParseFilter(
+	filter string,
+	apiFields map[string]dbfield.DBField,
+	allowedPredicates map[string][]predicate.Predicate,
+) ([]Selector, error) {
+	tokens, err := tokenizeFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 1 {
+		return nil, nil
+	}
+
+	p := &filterParser{
+		tokens:            tokens,
+		apiFields:         apiFields,
+		allowedPredicates: allowedPredicates,
+	}
+	selectors, err := p.parseOr(0)
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().kind != tokenEnd {
+		return nil, p.error("unexpected %q", p.peek().text)
+	}
+	return selectors, nil
+}
+
+type tokenKind int
+
+const (
+	tokenEnd tokenKind = iota
+	tokenIdent
+	tokenString
+	tokenNumber
+	tokenOperator
+	tokenLeftParen
+	tokenRightParen
+	tokenComma
+)
+
+type filterToken struct {
+	kind     tokenKind
+	text     string
+	position int
+}
+
+func tokenizeFilter(filter string) ([]filterToken, error) {
+	var tokens []filterToken
+	runes := []rune(filter)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		start := i
+		switch {
+		case unicode.IsSpace(r):
+			i++
+			continue
+		case r == '(':
+			tokens = append(tokens, filterToken{tokenLeftParen, "(", start})
+			i++
+		case r == ')':
+			tokens = append(tokens, filterToken{tokenRightParen, ")", start})
+			i++
+		case r == ',':
+			tokens = append(tokens, filterToken{tokenComma, ",", start})
+			i++
+		case r == '"':
+			value, end, err := scanFilterString(runes, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, filterToken{tokenString, value, start})
+			i = end
+		case strings.ContainsRune("=!<>~", r):
+			i++
+			if i < len(runes) && runes[i] == '=' && r != '=' && r != '~' {
+				i++
+			}
+			operator := string(runes[start:i])
+			if operator == "!" {
+				return nil, filterError(start, "unexpected \"!\"")
+			}
+			tokens = append(tokens, filterToken{tokenOperator, operator, start})
+		case r == '-' || r == '.' || unicode.IsDigit(r):
+			i++
+			for i < len(runes) && (unicode.IsDigit(runes[i]) ||
+				strings.ContainsRune(".eE+-", runes[i])) {
+				i++
+			}
+			tokens = append(tokens, filterToken{
+				tokenNumber,
+				string(runes[start:i]),
+				start,
+			})
+		case isFilterIdentRune(r):
+			for i < len(runes) && isFilterIdentRune(runes[i]) {
+				i++
+			}
+			tokens = append(tokens, filterToken{
+				tokenIdent,
+				string(runes[start:i]),
+				start,
+			})
+		default:
+			return nil, filterError(start, fmt.Sprintf("unexpected %q", r))
+		}
+	}
+	return append(tokens, filterToken{tokenEnd, "", len(runes)}), nil
+}
+
+// scanFilterString scans a string in double quotes starting at i. Double
+// quotes and backslashes are escaped with a backslash.
+func scanFilterString(runes []rune, i int) (string, int, error) {
+	start := i
+	var builder strings.Builder
+	for i++; i < len(runes); i++ {
+		switch runes[i] {
+		case '\\':
+			i++
+			if i == len(runes) {
+				return "", 0, filterError(start, "unterminated string")
+			}
+			builder.WriteRune(runes[i])
+		case '"':
+			return builder.String(), i + 1, nil
+		default:
+			builder.WriteRune(runes[i])
+		}
+	}
+	return "", 0, filterError(start, "unterminated string")
+}
+
+func isFilterIdentRune(r rune) bool {
+	return r == '_' || r == '.' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+type filterParser struct {
+	tokens            []filterToken
+	position          int
+	apiFields         map[string]dbfield.DBField
+	allowedPredicates map[string][]predicate.Predicate
+}
+
+func (p *filterParser) peek() filterToken {
+	return p.tokens[p.position]
+}
+
+func (p *filterParser) next() filterToken {
+	token := p.tokens[p.position]
+	if token.kind != tokenEnd {
+		p.position++
+	}
+	return token
+}
+
+// keyword returns true and consumes the next token if it is the keyword.
+func (p *filterParser) keyword(keyword string) bool {
+	token := p.peek()
+	if token.kind == tokenIdent && strings.EqualFold(token.text, keyword) {
+		p.position++
+		return true
+	}
+	return false
+}
+
+func (p *filterParser) error(format string, args ...any) error {
+	return filterError(p.peek().position, fmt.Sprintf(format, args...))
+}
+
+// parseOr parses the terms joined with OR. A single term is returned as is,
+// multiple terms as an OR selector of the groups.
+func (p *filterParser) parseOr(depth int) ([]Selector, error) {
+	var groups [][]Selector
+	for {
+		group, err := p.parseAnd(depth)
+		if err != nil {
+			return nil, err
+		}
+		groups = append(groups, orGroups(group)...)
+		if !p.keyword("OR") {
+			break
+		}
+	}
+	if len(groups) == 1 {
+		return groups[0], nil
+	}
+	return []Selector{{
+		AllowedPredicates: []predicate.Predicate{predicate.OR},
+		Predicate:         predicate.OR,
+		Value:             groups,
+	}}, nil
+}
+
+// orGroups returns the groups of a nested OR selector, so that they are
+// merged into the enclosing OR.
+func orGroups(group []Selector) [][]Selector {
+	if len(group) == 1 && group[0].Predicate == predicate.OR {
+		return group[0].Value.([][]Selector)
+	}
+	return [][]Selector{group}
+}
+
+// parseAnd parses the factors joined with AND.
+func (p *filterParser) parseAnd(depth int) ([]Selector, error) {
+	var selectors []Selector
+	for {
+		factor, err := p.parseFactor(depth)
+		if err != nil {
+			return nil, err
+		}
+		selectors = append(selectors, factor...)
+		if !p.keyword("AND") {
+			return selectors, nil
+		}
+	}
+}
+
+// parseFactor parses an expression in parentheses or a comparison.
+func (p *filterParser) parseFactor(depth int) ([]Selector, error) {
+	if p.peek().kind != tokenLeftParen {
+		return p.parseComparison()
+	}
+	if depth >= maxFilterDepth {
+		return nil, p.error("too deeply nested, max %d", maxFilterDepth)
+	}
+	p.next()
+	selectors, err := p.parseOr(depth + 1)
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().kind != tokenRightParen {
+		return nil, p.error("expected \")\"")
+	}
+	p.next()
+	return selectors, nil
+}
+
+// parseComparison parses a comparison of a field and a value.
+func (p *filterParser) parseComparison() ([]Selector, error) {
+	fieldToken := p.peek()
+	if fieldToken.kind != tokenIdent {
+		return nil, p.error("expected field")
+	}
+	p.next()
+
+	pred, err := p.parsePredicate()
+	if err != nil {
+		return nil, err
+	}
+	allowNull := pred == predicate.EQUAL || pred == predicate.NOT_EQUAL
+	var value any
+	if pred == predicate.IN || pred == predicate.NOT_IN {
+		value, err = p.parseList()
+	} else {
+		value, err = p.parseValue(allowNull)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	field := fieldToken.text
+	allowed, ok := p.allowedPredicates[field]
+	if _, isAPIField := p.apiFields[field]; !ok || !isAPIField {
+		return nil, InvalidSelectorFieldError.WithData(
+			InvalidSelectorFieldErrorData{Field: field},
+		)
+	}
+	if !slices.Contains(allowed, pred) {
+		return nil, PredicateNotAllowedError.WithData(
+			PredicateNotAllowedErrorData{Predicate: pred},
+		)
+	}
+
+	return []Selector{{
+		AllowedPredicates: allowed,
+		Field:             field,
+		Predicate:         pred,
+		Value:             value,
+	}}, nil
+}
+
+func (p *filterParser) parsePredicate() (predicate.Predicate, error) {
+	token := p.peek()
+	if token.kind == tokenOperator {
+		p.next()
+		return predicate.Predicate(token.text), nil
+	}
+	if p.keyword("IN") {
+		return predicate.IN, nil
+	}
+	if p.keyword("NOT") {
+		if p.keyword("IN") {
+			return predicate.NOT_IN, nil
+		}
+		return "", p.error("expected IN")
+	}
+	return "", p.error("expected operator")
+}
+
+// parseList parses a list of values in parentheses.
+func (p *filterParser) parseList() ([]any, error) {
+	if p.peek().kind != tokenLeftParen {
+		return nil, p.error("expected \"(\"")
+	}
+	p.next()
+
+	values := []any{}
+	for {
+		value, err := p.parseValue(false)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+		switch p.peek().kind {
+		case tokenComma:
+			p.next()
+		case tokenRightParen:
+			p.next()
+			return values, nil
+		default:
+			return nil, p.error("expected \",\" or \")\"")
+		}
+	}
+}
+
+// parseValue parses a string, a number, a boolean or null if allowNull is
+// true. Integers are returned as int64 and other numbers as float64.
+func (p *filterParser) parseValue(allowNull bool) (any, error) {
+	token := p.peek()
+	switch token.kind {
+	case tokenString:
+		p.next()
+		return token.text, nil
+	case tokenNumber:
+		p.next()
+		if i, err := strconv.ParseInt(token.text, 10, 64); err == nil {
+			return i, nil
+		}
+		f, err := strconv.ParseFloat(token.text, 64)
+		if err != nil {
+			return nil, filterError(
+				token.position,
+				fmt.Sprintf("invalid number %q", token.text),
+			)
+		}
+		return f, nil
+	case tokenIdent:
+		switch strings.ToLower(token.text) {
+		case "true":
+			p.next()
+			return true, nil
+		case "false":
+			p.next()
+			return false, nil
+		case "null":
+			if !allowNull {
+				return nil, p.error("null is only allowed with = and !=")
+			}
+			p.next()
+			return nil, nil
+		}
+	}
+	return nil, p.error("expected value")
+}
+
+func filterError(position int, message string) error {
+	return InvalidFilterError.WithData(
+		InvalidFilterErrorData{Position: position, Message: message},
+	)
+}
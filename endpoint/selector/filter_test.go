@@ -0,0 +1,244 @@
+package selector
+
+import (
+	"testing"
+
+	"github.com/pakkasys/fluidapi/core/api"
+	"github.com/pakkasys/fluidapi/database/util"
+	"github.com/pakkasys/fluidapi/endpoint/dbfield"
+	"github.com/pakkasys/fluidapi/endpoint/predicate"
+	"github.com/stretchr/testify/assert"
+)
+
+var filterAPIFields = map[string]dbfield.DBField{
+	"age":    {Table: "user", Column: "age"},
+	"name":   {Table: "user", Column: "name"},
+	"vip":    {Table: "user", Column: "vip"},
+	"status": {Table: "user", Column: "status"},
+	"secret": {Table: "user", Column: "secret"},
+}
+
+var filterAllowedPredicates = map[string][]predicate.Predicate{
+	"age":    predicate.AllPredicates,
+	"name":   {predicate.LIKE, predicate.EQUAL},
+	"vip":    predicate.OnlyEqualPredicate,
+	"status": predicate.AllPredicates,
+}
+
+func filterSelector(
+	field string,
+	p predicate.Predicate,
+	value any,
+) Selector {
+	return Selector{
+		AllowedPredicates: filterAllowedPredicates[field],
+		Field:             field,
+		Predicate:         p,
+		Value:             value,
+	}
+}
+
+// TestParseFilter tests parsing valid filters.
+func TestParseFilter(t *testing.T) {
+	tests := []struct {
+		name     string
+		filter   string
+		expected []Selector
+	}{
+		{name: "empty", filter: "  "},
+		{
+			name:   "comparisons",
+			filter: `age>=30 and age != -1.5 AND vip = TRUE AND name = null`,
+			expected: []Selector{
+				filterSelector("age", predicate.GREATER_OR_EQUAL, int64(30)),
+				filterSelector("age", predicate.NOT_EQUAL, -1.5),
+				filterSelector("vip", predicate.EQUAL, true),
+				filterSelector("name", predicate.EQUAL, nil),
+			},
+		},
+		{
+			name:   "lists and strings",
+			filter: `status IN ("a", "b \"c\"") AND status NOT IN (1)`,
+			expected: []Selector{
+				filterSelector("status", predicate.IN, []any{"a", `b "c"`}),
+				filterSelector("status", predicate.NOT_IN, []any{int64(1)}),
+			},
+		},
+		{
+			name:   "OR and parentheses",
+			filter: `age>30 AND (name~"Ali%" OR vip=true)`,
+			expected: []Selector{
+				filterSelector("age", predicate.GREATER, int64(30)),
+				{
+					AllowedPredicates: []predicate.Predicate{predicate.OR},
+					Predicate:         predicate.OR,
+					Value: [][]Selector{
+						{filterSelector("name", predicate.LIKE, "Ali%")},
+						{filterSelector("vip", predicate.EQUAL, true)},
+					},
+				},
+			},
+		},
+		{
+			name:   "nested OR is merged",
+			filter: `(age<1 OR (age>2 OR vip=false)) OR age=3 AND vip=true`,
+			expected: []Selector{{
+				AllowedPredicates: []predicate.Predicate{predicate.OR},
+				Predicate:         predicate.OR,
+				Value: [][]Selector{
+					{filterSelector("age", predicate.LESS, int64(1))},
+					{filterSelector("age", predicate.GREATER, int64(2))},
+					{filterSelector("vip", predicate.EQUAL, false)},
+					{
+						filterSelector("age", predicate.EQUAL, int64(3)),
+						filterSelector("vip", predicate.EQUAL, true),
+					},
+				},
+			}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			selectors, err := ParseFilter(
+				test.filter,
+				filterAPIFields,
+				filterAllowedPredicates,
+			)
+
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, selectors)
+		})
+	}
+}
+
+// TestParseFilter_InvalidFilter tests the errors of malformed filters.
+func TestParseFilter_InvalidFilter(t *testing.T) {
+	tests := []struct {
+		filter   string
+		expected InvalidFilterErrorData
+	}{
+		{`age >`, InvalidFilterErrorData{5, "expected value"}},
+		{`age 1`, InvalidFilterErrorData{4, "expected operator"}},
+		{`age = 1 vip`, InvalidFilterErrorData{8, `unexpected "vip"`}},
+		{`(age = 1`, InvalidFilterErrorData{8, `expected ")"`}},
+		{`name = "a`, InvalidFilterErrorData{7, "unterminated string"}},
+		{`age ! 1`, InvalidFilterErrorData{4, `unexpected "!"`}},
+		{`age = 1 AND`, InvalidFilterErrorData{11, "expected field"}},
+		{`age NOT 1`, InvalidFilterErrorData{8, "expected IN"}},
+		{`age IN 1`, InvalidFilterErrorData{7, `expected "("`}},
+		{`age IN (1 2)`, InvalidFilterErrorData{10, `expected "," or ")"`}},
+		{`age = 1-2`, InvalidFilterErrorData{6, `invalid number "1-2"`}},
+		{`age = #`, InvalidFilterErrorData{6, `unexpected '#'`}},
+		{
+			`age > null`,
+			InvalidFilterErrorData{6, "null is only allowed with = and !="},
+		},
+		{
+			`age IN (1, null)`,
+			InvalidFilterErrorData{11, "null is only allowed with = and !="},
+		},
+		{
+			`((((((((((((((((((age = 1))))))))))))))))))`,
+			InvalidFilterErrorData{16, "too deeply nested, max 16"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.filter, func(t *testing.T) {
+			selectors, err := ParseFilter(
+				test.filter,
+				filterAPIFields,
+				filterAllowedPredicates,
+			)
+
+			assert.Nil(t, selectors)
+			apiErr, ok := err.(*api.Error[InvalidFilterErrorData])
+			assert.True(t, ok)
+			assert.Equal(t, InvalidFilterError.ID, apiErr.ID)
+			assert.Equal(t, test.expected, *apiErr.Data)
+		})
+	}
+}
+
+// TestParseFilter_NotAllowed tests that the fields and the predicates are
+// validated.
+func TestParseFilter_NotAllowed(t *testing.T) {
+	parse := func(filter string) error {
+		_, err := ParseFilter(filter, filterAPIFields, filterAllowedPredicates)
+		return err
+	}
+	type fieldError = api.Error[InvalidSelectorFieldErrorData]
+	type predicateError = api.Error[PredicateNotAllowedErrorData]
+
+	fieldErr, ok := parse(`secret = "a"`).(*fieldError)
+	assert.True(t, ok)
+	assert.Equal(t, "secret", fieldErr.Data.Field)
+
+	fieldErr, ok = parse(`unknown = 1`).(*fieldError)
+	assert.True(t, ok)
+	assert.Equal(t, "unknown", fieldErr.Data.Field)
+
+	predicateErr, ok := parse(`vip != true`).(*predicateError)
+	assert.True(t, ok)
+	assert.Equal(t, predicate.NOT_EQUAL, predicateErr.Data.Predicate)
+}
+
+// TestParseFilter_ToDBSelectors tests translating a parsed filter to database
+// selectors.
+func TestParseFilter_ToDBSelectors(t *testing.T) {
+	selectors, err := ParseFilter(
+		`age>30 AND (name~"Ali%" OR vip=true)`,
+		filterAPIFields,
+		filterAllowedPredicates,
+	)
+	assert.NoError(t, err)
+
+	dbSelectors, err := ToDBSelectors(selectors, filterAPIFields)
+	assert.NoError(t, err)
+
+	where, values := util.WhereClause(dbSelectors)
+	assert.Equal(
+		t,
+		"WHERE `user`.`age` > ? AND "+
+			"((`user`.`name` LIKE ?) OR (`user`.`vip` = ?))",
+		where,
+	)
+	assert.Equal(t, []any{int64(30), "Ali%", true}, values)
+}
+
+// TestParseFilter_ToDBSelectorsNotIn tests translating a NOT IN filter to
+// database selectors.
+func TestParseFilter_ToDBSelectorsNotIn(t *testing.T) {
+	selectors, err := ParseFilter(
+		`status NOT IN ("a", "b")`,
+		filterAPIFields,
+		filterAllowedPredicates,
+	)
+	assert.NoError(t, err)
+
+	dbSelectors, err := ToDBSelectors(selectors, filterAPIFields)
+	assert.NoError(t, err)
+
+	where, values := util.WhereClause(dbSelectors)
+	assert.Equal(t, "WHERE `user`.`status` NOT IN (?,?)", where)
+	assert.Equal(t, []any{"a", "b"}, values)
+}
+
+// TestToDBSelectors_InvalidOrValue tests that the value of an OR selector
+// must be groups of selectors.
+func TestToDBSelectors_InvalidOrValue(t *testing.T) {
+	dbSelectors, err := ToDBSelectors(
+		[]Selector{{
+			AllowedPredicates: []predicate.Predicate{predicate.OR},
+			Predicate:         predicate.OR,
+			Value:             "age = 1",
+		}},
+		filterAPIFields,
+	)
+
+	assert.Nil(t, dbSelectors)
+	predicateErr, ok := err.(*api.Error[InvalidPredicateErrorData])
+	assert.True(t, ok)
+	assert.Equal(t, predicate.OR, predicateErr.Data.Predicate)
+}
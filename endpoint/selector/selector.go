@@ -39,6 +39,8 @@ var PredicateNotAllowedError = api.NewError[PredicateNotAllowedErrorData]("PREDI
 type Selector struct {
 	// Predicates allowed for this selector
 	AllowedPredicates []predicate.Predicate
+	// Allowed predicates that are deprecated for this selector
+	DeprecatedPredicates []predicate.Predicate
 	// The name of the field being filtered
 	Field string
 	// The predicate for filtering
@@ -80,7 +82,7 @@ func (i Selectors) GetByFields(fields ...string) []Selector {
 
 // ToDBSelectors converts a slice of API-level selectors to database selectors.
 // It validates predicates and translates the fields and predicates for use with
-// the database.
+// the database. The groups of OR selectors are translated recursively.
 //
 // Parameters:
 //   - selectors: A slice of API-level selectors that specify the criteria for
@@ -112,6 +114,15 @@ func ToDBSelectors(
 			)
 		}
 
+		if selector.Predicate == predicate.OR {
+			dbSelector, err := toDBOrSelector(selector, apiToDBFieldMap)
+			if err != nil {
+				return nil, err
+			}
+			databaseSelectors = append(databaseSelectors, *dbSelector)
+			continue
+		}
+
 		// Translate the predicate
 		dbPredicate, ok := predicate.ToDBPredicates[selector.Predicate]
 		if !ok {
@@ -143,3 +154,25 @@ func ToDBSelectors(
 
 	return databaseSelectors, nil
 }
+
+func toDBOrSelector(
+	selector Selector,
+	apiToDBFieldMap map[string]dbfield.DBField,
+) (*util.Selector, error) {
+	groups, ok := selector.Value.([][]Selector)
+	if !ok {
+		return nil, InvalidPredicateError.WithData(
+			InvalidPredicateErrorData{Predicate: selector.Predicate},
+		)
+	}
+
+	dbGroups := make([][]util.Selector, len(groups))
+	for i, group := range groups {
+		dbGroup, err := ToDBSelectors(group, apiToDBFieldMap)
+		if err != nil {
+			return nil, err
+		}
+		dbGroups[i] = dbGroup
+	}
+	return &util.Selector{Predicate: util.OR, Value: dbGroups}, nil
+}
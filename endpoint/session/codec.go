@@ -0,0 +1,130 @@
+package session
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrInvalidCookie is returned for cookies with an invalid signature or
+	// encryption.
+	ErrInvalidCookie = errors.New("invalid session cookie")
+	// ErrExpiredCookie is returned for cookies older than the max age.
+	ErrExpiredCookie = errors.New("expired session cookie")
+)
+
+// CookieCodec signs and optionally encrypts cookie values. The values are
+// signed with HMAC-SHA256 together with the cookie name and the time they
+// were encoded.
+type CookieCodec struct {
+	hashKey []byte
+	aead    cipher.AEAD
+	now     func() time.Time
+}
+
+// NewCookieCodec returns a new cookie codec.
+//
+//   - hashKey: The key signing the values, preferably 32 or 64 bytes.
+//   - encryptionKey: The AES key encrypting the values, 16, 24 or 32 bytes.
+//     If nil, the values are only signed.
+func NewCookieCodec(
+	hashKey []byte,
+	encryptionKey []byte,
+) (*CookieCodec, error) {
+	if len(hashKey) == 0 {
+		return nil, errors.New("hash key cannot be empty")
+	}
+
+	codec := &CookieCodec{hashKey: hashKey, now: time.Now}
+	if encryptionKey != nil {
+		block, err := aes.NewCipher(encryptionKey)
+		if err != nil {
+			return nil, fmt.Errorf("encryption key: %w", err)
+		}
+		codec.aead, err = cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return codec, nil
+}
+
+// Encode encodes the value of the cookie.
+//
+//   - name: The name of the cookie.
+//   - value: The value of the cookie.
+func (c *CookieCodec) Encode(name string, value []byte) (string, error) {
+	if c.aead != nil {
+		nonce := make([]byte, c.aead.NonceSize())
+		if _, err := rand.Read(nonce); err != nil {
+			return "", err
+		}
+		value = c.aead.Seal(nonce, nonce, value, []byte(name))
+	}
+
+	payload := binary.BigEndian.AppendUint64(nil, uint64(c.now().Unix()))
+	payload = append(payload, value...)
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + c.signature(name, encoded), nil
+}
+
+// Decode decodes the value of the cookie.
+//
+//   - name: The name of the cookie.
+//   - encoded: The encoded value of the cookie.
+//   - maxAge: The maximum age of the value. Zero means no limit.
+func (c *CookieCodec) Decode(
+	name string,
+	encoded string,
+	maxAge time.Duration,
+) ([]byte, error) {
+	payloadPart, signature, ok := strings.Cut(encoded, ".")
+	if !ok || !hmac.Equal(
+		[]byte(signature),
+		[]byte(c.signature(name, payloadPart)),
+	) {
+		return nil, ErrInvalidCookie
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(payloadPart)
+	if err != nil || len(payload) < 8 {
+		return nil, ErrInvalidCookie
+	}
+
+	encodedAt := time.Unix(int64(binary.BigEndian.Uint64(payload)), 0)
+	if maxAge > 0 && c.now().Sub(encodedAt) > maxAge {
+		return nil, ErrExpiredCookie
+	}
+
+	value := payload[8:]
+	if c.aead != nil {
+		nonceSize := c.aead.NonceSize()
+		if len(value) < nonceSize {
+			return nil, ErrInvalidCookie
+		}
+		value, err = c.aead.Open(
+			nil,
+			value[:nonceSize],
+			value[nonceSize:],
+			[]byte(name),
+		)
+		if err != nil {
+			return nil, ErrInvalidCookie
+		}
+	}
+	return value, nil
+}
+
+func (c *CookieCodec) signature(name string, payload string) string {
+	mac := hmac.New(sha256.New, c.hashKey)
+	mac.Write([]byte(name + "|" + payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
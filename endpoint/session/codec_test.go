@@ -0,0 +1,64 @@
+package session
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	testHashKey       = []byte("0123456789abcdef0123456789abcdef")
+	testEncryptionKey = []byte("0123456789abcdef")
+)
+
+// TestCookieCodec tests encoding and decoding signed and encrypted values.
+func TestCookieCodec(t *testing.T) {
+	for _, encryptionKey := range [][]byte{nil, testEncryptionKey} {
+		codec, err := NewCookieCodec(testHashKey, encryptionKey)
+		assert.NoError(t, err)
+
+		encoded, err := codec.Encode("session", []byte("value"))
+		assert.NoError(t, err)
+		decoded, err := codec.Decode("session", encoded, time.Minute)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "value", string(decoded))
+		if encryptionKey != nil {
+			assert.NotContains(t, encoded, "dmFsdWU")
+		}
+
+		_, err = codec.Decode("other", encoded, 0)
+		assert.Equal(t, ErrInvalidCookie, err)
+		_, err = codec.Decode("session", encoded+"x", 0)
+		assert.Equal(t, ErrInvalidCookie, err)
+		_, err = codec.Decode("session", "value", 0)
+		assert.Equal(t, ErrInvalidCookie, err)
+	}
+}
+
+// TestCookieCodec_Expired tests rejecting values older than the max age.
+func TestCookieCodec_Expired(t *testing.T) {
+	codec, err := NewCookieCodec(testHashKey, nil)
+	assert.NoError(t, err)
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	codec.now = func() time.Time { return now }
+	encoded, err := codec.Encode("session", []byte("value"))
+	assert.NoError(t, err)
+
+	now = now.Add(time.Hour)
+
+	_, err = codec.Decode("session", encoded, time.Minute)
+	assert.Equal(t, ErrExpiredCookie, err)
+	_, err = codec.Decode("session", encoded, 0)
+	assert.NoError(t, err)
+}
+
+// TestNewCookieCodec_Errors tests the errors of invalid keys.
+func TestNewCookieCodec_Errors(t *testing.T) {
+	_, err := NewCookieCodec(nil, nil)
+	assert.Error(t, err)
+
+	_, err = NewCookieCodec(testHashKey, []byte("short"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,212 @@
+package session
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+)
+
+const (
+	defaultCookieName = "session"
+	defaultMaxAge     = 24 * time.Hour
+)
+
+// Config configures the sessions.
+type Config struct {
+	// Codec signs and encrypts the session cookies.
+	Codec *CookieCodec
+	// Store stores the session values on the server. If nil, the values are
+	// stored in the cookie, which is limited to about 4 KB.
+	Store Store
+	// CookieName is the name of the session cookie. Defaults to "session".
+	CookieName string
+	// MaxAge is the lifetime of the sessions. Defaults to 24 hours.
+	MaxAge time.Duration
+	// Path of the cookie. Defaults to "/".
+	Path string
+	// Domain of the cookie. Optional.
+	Domain string
+	// Secure restricts the cookie to HTTPS.
+	Secure bool
+	// SameSite mode of the cookie. Defaults to http.SameSiteLaxMode.
+	SameSite http.SameSite
+}
+
+// Manager loads and saves the sessions of requests.
+type Manager struct {
+	config Config
+}
+
+// NewManager returns a new session manager.
+//
+//   - config: The configuration of the sessions.
+func NewManager(config Config) *Manager {
+	if config.Codec == nil {
+		panic("codec cannot be nil")
+	}
+	if config.CookieName == "" {
+		config.CookieName = defaultCookieName
+	}
+	if config.MaxAge <= 0 {
+		config.MaxAge = defaultMaxAge
+	}
+	if config.Path == "" {
+		config.Path = "/"
+	}
+	if config.SameSite == 0 {
+		config.SameSite = http.SameSiteLaxMode
+	}
+	return &Manager{config: config}
+}
+
+// Load returns the session of the request. A new session is returned if the
+// request has no valid session cookie.
+//
+//   - r: The HTTP request.
+func (m *Manager) Load(r *http.Request) (*Session, error) {
+	cookie, err := r.Cookie(m.config.CookieName)
+	if err != nil {
+		return m.newSession()
+	}
+	data, err := m.config.Codec.Decode(
+		m.config.CookieName,
+		cookie.Value,
+		m.config.MaxAge,
+	)
+	if err != nil {
+		return m.newSession()
+	}
+
+	if m.config.Store == nil {
+		var values map[string]any
+		if err := json.Unmarshal(data, &values); err != nil {
+			return m.newSession()
+		}
+		return newSession("", values, false), nil
+	}
+
+	id := string(data)
+	values, err := m.config.Store.Get(r.Context(), id)
+	if err != nil {
+		return nil, err
+	}
+	if values == nil {
+		return m.newSession()
+	}
+	return newSession(id, values, false), nil
+}
+
+// Save writes the session cookie and stores the session if it has been
+// modified. It must be called before the response header is written.
+//
+//   - w: The HTTP response writer.
+//   - r: The HTTP request.
+//   - session: The session.
+func (m *Manager) Save(
+	w http.ResponseWriter,
+	r *http.Request,
+	session *Session,
+) error {
+	if !session.IsModified() {
+		return nil
+	}
+
+	session.mu.Lock()
+	defer session.mu.Unlock()
+
+	if session.destroyed {
+		if m.config.Store != nil && !session.isNew {
+			err := m.config.Store.Delete(r.Context(), session.id)
+			if err != nil {
+				return err
+			}
+		}
+		http.SetCookie(w, m.cookie("", -1))
+		return nil
+	}
+
+	var data []byte
+	if m.config.Store == nil {
+		var err error
+		data, err = json.Marshal(session.values)
+		if err != nil {
+			return err
+		}
+	} else {
+		if session.renewed {
+			if err := m.renewID(r, session); err != nil {
+				return err
+			}
+		}
+		err := m.config.Store.Set(
+			r.Context(),
+			session.id,
+			session.values,
+			m.config.MaxAge,
+		)
+		if err != nil {
+			return err
+		}
+		data = []byte(session.id)
+	}
+
+	value, err := m.config.Codec.Encode(m.config.CookieName, data)
+	if err != nil {
+		return err
+	}
+	if len(value) > 4096 {
+		return errors.New("session cookie exceeds 4096 bytes")
+	}
+	http.SetCookie(w, m.cookie(value, int(m.config.MaxAge.Seconds())))
+	return nil
+}
+
+func (m *Manager) renewID(r *http.Request, session *Session) error {
+	if !session.isNew {
+		if err := m.config.Store.Delete(r.Context(), session.id); err != nil {
+			return err
+		}
+	}
+	id, err := newSessionID()
+	if err != nil {
+		return err
+	}
+	session.id = id
+	session.renewed = false
+	return nil
+}
+
+func (m *Manager) newSession() (*Session, error) {
+	if m.config.Store == nil {
+		return newSession("", nil, true), nil
+	}
+	id, err := newSessionID()
+	if err != nil {
+		return nil, err
+	}
+	return newSession(id, nil, true), nil
+}
+
+func (m *Manager) cookie(value string, maxAge int) *http.Cookie {
+	return &http.Cookie{
+		Name:     m.config.CookieName,
+		Value:    value,
+		Path:     m.config.Path,
+		Domain:   m.config.Domain,
+		MaxAge:   maxAge,
+		Secure:   m.config.Secure,
+		HttpOnly: true,
+		SameSite: m.config.SameSite,
+	}
+}
+
+func newSessionID() (string, error) {
+	id := make([]byte, 32)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(id), nil
+}
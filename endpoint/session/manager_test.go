@@ -0,0 +1,150 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestManager(t *testing.T, store Store) *Manager {
+	codec, err := NewCookieCodec(testHashKey, testEncryptionKey)
+	assert.NoError(t, err)
+	return NewManager(Config{Codec: codec, Store: store, Secure: true})
+}
+
+// roundTrip saves the session and loads it from the resulting cookie.
+func roundTrip(t *testing.T, manager *Manager, session *Session) *Session {
+	w := httptest.NewRecorder()
+	err := manager.Save(w, httptest.NewRequest("GET", "/", nil), session)
+	assert.NoError(t, err)
+	r := httptest.NewRequest("GET", "/", nil)
+	for _, cookie := range w.Result().Cookies() {
+		r.AddCookie(cookie)
+	}
+	loaded, err := manager.Load(r)
+	assert.NoError(t, err)
+	return loaded
+}
+
+// TestManager_CookieStore tests storing the values in the cookie.
+func TestManager_CookieStore(t *testing.T) {
+	manager := newTestManager(t, nil)
+	session, err := manager.Load(httptest.NewRequest("GET", "/", nil))
+	assert.NoError(t, err)
+	assert.True(t, session.IsNew())
+
+	session.Set("user", "alice")
+	loaded := roundTrip(t, manager, session)
+
+	assert.False(t, loaded.IsNew())
+	assert.Empty(t, loaded.ID())
+	assert.Equal(t, "alice", loaded.Get("user"))
+}
+
+// TestManager_ServerStore tests storing the values in the store.
+func TestManager_ServerStore(t *testing.T) {
+	store := NewMemoryStore()
+	manager := newTestManager(t, store)
+	session, err := manager.Load(httptest.NewRequest("GET", "/", nil))
+	assert.NoError(t, err)
+	assert.NotEmpty(t, session.ID())
+
+	session.Set("user", "alice")
+	loaded := roundTrip(t, manager, session)
+
+	assert.Equal(t, session.ID(), loaded.ID())
+	assert.Equal(t, "alice", loaded.Get("user"))
+
+	loaded.Renew()
+	renewed := roundTrip(t, manager, loaded)
+	assert.NotEqual(t, session.ID(), renewed.ID())
+	assert.Equal(t, "alice", renewed.Get("user"))
+	values, err := store.Get(context.Background(), session.ID())
+	assert.NoError(t, err)
+	assert.Nil(t, values)
+
+	renewed.Destroy()
+	w := httptest.NewRecorder()
+	err = manager.Save(w, httptest.NewRequest("GET", "/", nil), renewed)
+	assert.NoError(t, err)
+	cookie := w.Result().Cookies()[0]
+	assert.Equal(t, -1, cookie.MaxAge)
+	values, err = store.Get(context.Background(), renewed.ID())
+	assert.NoError(t, err)
+	assert.Nil(t, values)
+}
+
+// TestManager_Save_Unmodified tests that unmodified sessions are not saved.
+func TestManager_Save_Unmodified(t *testing.T) {
+	manager := newTestManager(t, nil)
+	w := httptest.NewRecorder()
+
+	err := manager.Save(
+		w,
+		httptest.NewRequest("GET", "/", nil),
+		newSession("", nil, true),
+	)
+
+	assert.NoError(t, err)
+	assert.Empty(t, w.Result().Cookies())
+}
+
+// TestManager_Load_Invalid tests that invalid cookies start new sessions.
+func TestManager_Load_Invalid(t *testing.T) {
+	manager := newTestManager(t, NewMemoryStore())
+	r := httptest.NewRequest("GET", "/", nil)
+	r.AddCookie(&http.Cookie{Name: "session", Value: "invalid"})
+
+	session, err := manager.Load(r)
+
+	assert.NoError(t, err)
+	assert.True(t, session.IsNew())
+}
+
+type failingStore struct {
+	MemoryStore
+}
+
+func (s *failingStore) Get(
+	ctx context.Context,
+	id string,
+) (map[string]any, error) {
+	return nil, errors.New("store error")
+}
+
+// TestManager_Load_StoreError tests that store errors are returned.
+func TestManager_Load_StoreError(t *testing.T) {
+	manager := newTestManager(t, &failingStore{})
+	value, err := manager.config.Codec.Encode("session", []byte("id"))
+	assert.NoError(t, err)
+	r := httptest.NewRequest("GET", "/", nil)
+	r.AddCookie(&http.Cookie{Name: "session", Value: value})
+
+	_, err = manager.Load(r)
+
+	assert.EqualError(t, err, "store error")
+}
+
+// TestManager_Cookie tests the attributes of the session cookie.
+func TestManager_Cookie(t *testing.T) {
+	manager := newTestManager(t, nil)
+	session := newSession("", nil, true)
+	session.Set("a", 1)
+	w := httptest.NewRecorder()
+
+	err := manager.Save(w, httptest.NewRequest("GET", "/", nil), session)
+	assert.NoError(t, err)
+
+	cookie := w.Result().Cookies()[0]
+	assert.Equal(t, "session", cookie.Name)
+	assert.Equal(t, "/", cookie.Path)
+	assert.Equal(t, int((24 * time.Hour).Seconds()), cookie.MaxAge)
+	assert.True(t, cookie.HttpOnly)
+	assert.True(t, cookie.Secure)
+	assert.Equal(t, http.SameSiteLaxMode, cookie.SameSite)
+}
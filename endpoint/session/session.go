@@ -0,0 +1,133 @@
+// Package session provides cookie-based sessions with the values stored in
+// signed and optionally encrypted cookies or in a server-side store.
+package session
+
+import (
+	"context"
+	"maps"
+	"sync"
+
+	"github.com/pakkasys/fluidapi/endpoint/util"
+)
+
+var sessionDataKey = util.NewDataKey()
+
+// Session is the session of a client. It is safe for concurrent use.
+type Session struct {
+	mu        sync.RWMutex
+	id        string
+	values    map[string]any
+	isNew     bool
+	modified  bool
+	destroyed bool
+	renewed   bool
+}
+
+// newSession returns a session with the ID and values.
+func newSession(id string, values map[string]any, isNew bool) *Session {
+	if values == nil {
+		values = map[string]any{}
+	}
+	return &Session{id: id, values: values, isNew: isNew}
+}
+
+// ID returns the ID of the session. Sessions stored in cookies have no ID.
+func (s *Session) ID() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.id
+}
+
+// IsNew returns whether the session was created by the request.
+func (s *Session) IsNew() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.isNew
+}
+
+// Get returns the value of the key, or nil if it is not set. Values of
+// sessions stored in cookies are decoded from JSON, so numbers are float64.
+//
+//   - key: The key of the value.
+func (s *Session) Get(key string) any {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.values[key]
+}
+
+// Values returns a copy of the values of the session.
+func (s *Session) Values() map[string]any {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return maps.Clone(s.values)
+}
+
+// Set sets the value of the key.
+//
+//   - key: The key of the value.
+//   - value: The value, which must be JSON marshalable.
+func (s *Session) Set(key string, value any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.values[key] = value
+	s.modified = true
+}
+
+// Delete deletes the value of the key.
+//
+//   - key: The key of the value.
+func (s *Session) Delete(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.values, key)
+	s.modified = true
+}
+
+// Destroy deletes the session and its cookie at the end of the request.
+func (s *Session) Destroy() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.values = map[string]any{}
+	s.destroyed = true
+}
+
+// Renew replaces the ID of the session at the end of the request, e.g. after
+// the client has logged in, to prevent session fixation.
+func (s *Session) Renew() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.renewed = true
+	s.modified = true
+}
+
+// IsModified returns whether the session has been modified or destroyed.
+func (s *Session) IsModified() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.modified || s.destroyed
+}
+
+// SetSession stores the session in the custom context.
+//
+//   - ctx: The context with the custom context set.
+//   - session: The session.
+func SetSession(ctx context.Context, session *Session) {
+	util.SetContextValue(ctx, sessionDataKey, session)
+}
+
+// GetSession returns the session of the request, or nil if the session
+// middleware has not run.
+//
+//   - ctx: The context of the request.
+func GetSession(ctx context.Context) *Session {
+	return util.GetContextValue[*Session](ctx, sessionDataKey, nil)
+}
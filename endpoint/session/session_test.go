@@ -0,0 +1,39 @@
+package session
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pakkasys/fluidapi/endpoint/util"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestSession tests setting and deleting the values of a session.
+func TestSession(t *testing.T) {
+	session := newSession("id", map[string]any{"a": 1}, false)
+	assert.Equal(t, "id", session.ID())
+	assert.False(t, session.IsNew())
+	assert.False(t, session.IsModified())
+
+	session.Set("b", "value")
+	session.Delete("a")
+
+	assert.True(t, session.IsModified())
+	assert.Nil(t, session.Get("a"))
+	assert.Equal(t, "value", session.Get("b"))
+	assert.Equal(t, map[string]any{"b": "value"}, session.Values())
+
+	session.Destroy()
+	assert.Empty(t, session.Values())
+}
+
+// TestGetSession tests storing the session in the custom context.
+func TestGetSession(t *testing.T) {
+	ctx := util.NewContext(context.Background())
+	assert.Nil(t, GetSession(ctx))
+
+	session := newSession("", nil, true)
+	SetSession(ctx, session)
+
+	assert.Same(t, session, GetSession(ctx))
+}
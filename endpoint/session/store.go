@@ -0,0 +1,95 @@
+package session
+
+import (
+	"context"
+	"maps"
+	"sync"
+	"time"
+)
+
+// Store stores the values of sessions on the server, e.g. in Redis or a
+// database, so that only the session ID is stored in the cookie.
+type Store interface {
+	// Get returns the values of the session, or nil if the session does not
+	// exist or has expired.
+	Get(ctx context.Context, id string) (map[string]any, error)
+	// Set stores the values of the session for the duration.
+	Set(
+		ctx context.Context,
+		id string,
+		values map[string]any,
+		ttl time.Duration,
+	) error
+	// Delete deletes the session.
+	Delete(ctx context.Context, id string) error
+}
+
+type memorySession struct {
+	values    map[string]any
+	expiresAt time.Time
+}
+
+// MemoryStore is an in-memory session store for single instance services
+// and tests. It is safe for concurrent use.
+type MemoryStore struct {
+	mu       sync.Mutex
+	sessions map[string]memorySession
+	now      func() time.Time
+}
+
+// NewMemoryStore creates a new in-memory session store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		sessions: map[string]memorySession{},
+		now:      time.Now,
+	}
+}
+
+// Get returns the values of the session.
+func (s *MemoryStore) Get(
+	ctx context.Context,
+	id string,
+) (map[string]any, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, ok := s.sessions[id]
+	if !ok {
+		return nil, nil
+	}
+	if !session.expiresAt.IsZero() && !s.now().Before(session.expiresAt) {
+		delete(s.sessions, id)
+		return nil, nil
+	}
+	return maps.Clone(session.values), nil
+}
+
+// Set stores the values of the session. A zero duration never expires.
+func (s *MemoryStore) Set(
+	ctx context.Context,
+	id string,
+	values map[string]any,
+	ttl time.Duration,
+) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var expiresAt time.Time
+	if ttl > 0 {
+		expiresAt = s.now().Add(ttl)
+	}
+	s.sessions[id] = memorySession{
+		values:    maps.Clone(values),
+		expiresAt: expiresAt,
+	}
+	return nil
+}
+
+// Delete deletes the session.
+func (s *MemoryStore) Delete(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.sessions, id)
+	return nil
+}
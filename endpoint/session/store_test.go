@@ -0,0 +1,34 @@
+package session
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestMemoryStore tests storing, expiring and deleting sessions.
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	store.now = func() time.Time { return now }
+
+	assert.NoError(t, store.Set(ctx, "a", map[string]any{"k": 1}, time.Minute))
+	assert.NoError(t, store.Set(ctx, "b", map[string]any{"k": 2}, 0))
+
+	values, err := store.Get(ctx, "a")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{"k": 1}, values)
+
+	now = now.Add(time.Minute)
+	values, err = store.Get(ctx, "a")
+	assert.NoError(t, err)
+	assert.Nil(t, values)
+
+	assert.NoError(t, store.Delete(ctx, "b"))
+	values, err = store.Get(ctx, "b")
+	assert.NoError(t, err)
+	assert.Nil(t, values)
+}
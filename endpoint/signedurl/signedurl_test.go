@@ -0,0 +1,87 @@
+package signedurl
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testSigner(now time.Time) *Signer {
+	signer := NewSigner([]byte("secret"), time.Minute)
+	signer.NowFn = func() time.Time { return now }
+	return signer
+}
+
+// TestSignAndVerify tests that a signed URL is valid.
+func TestSignAndVerify(t *testing.T) {
+	now := time.Unix(1000, 0)
+	signer := testSigner(now)
+	u, _ := url.Parse("https://example.com/downloads?file=report.csv")
+
+	signed := signer.Sign(u, time.Hour)
+
+	assert.Equal(t, "4600", signed.Query().Get(ExpiresParam))
+	assert.NotEmpty(t, signed.Query().Get(SignatureParam))
+	assert.Equal(t, "file=report.csv", u.RawQuery)
+	assert.NoError(t, signer.Verify(signed))
+}
+
+// TestVerify_TamperedParams tests that modified parameters invalidate the
+// signature.
+func TestVerify_TamperedParams(t *testing.T) {
+	signer := testSigner(time.Unix(1000, 0))
+	u, _ := url.Parse("/downloads?file=report.csv")
+	signed := signer.Sign(u, time.Hour)
+
+	query := signed.Query()
+	query.Set("file", "secrets.csv")
+	signed.RawQuery = query.Encode()
+
+	assert.Equal(t, InvalidSignatureError, signer.Verify(signed))
+}
+
+// TestVerify_TamperedPath tests that a modified path invalidates the
+// signature.
+func TestVerify_TamperedPath(t *testing.T) {
+	signer := testSigner(time.Unix(1000, 0))
+	u, _ := url.Parse("/downloads?file=report.csv")
+	signed := signer.Sign(u, time.Hour)
+
+	signed.Path = "/admin"
+
+	assert.Equal(t, InvalidSignatureError, signer.Verify(signed))
+}
+
+// TestVerify_MissingSignature tests that an unsigned URL is invalid.
+func TestVerify_MissingSignature(t *testing.T) {
+	signer := testSigner(time.Unix(1000, 0))
+	u, _ := url.Parse("/downloads?expires=2000")
+
+	assert.Equal(t, InvalidSignatureError, signer.Verify(u))
+}
+
+// TestVerify_ClockSkew tests that an expired URL is accepted within the
+// clock skew tolerance.
+func TestVerify_ClockSkew(t *testing.T) {
+	u, _ := url.Parse("/downloads")
+	signed := testSigner(time.Unix(1000, 0)).Sign(u, time.Minute)
+
+	verifier := testSigner(time.Unix(1100, 0))
+	assert.NoError(t, verifier.Verify(signed))
+
+	verifier = testSigner(time.Unix(1121, 0))
+	assert.Equal(t, SignatureExpiredError, verifier.Verify(signed))
+}
+
+// TestVerify_WrongKey tests that a URL signed with another key is invalid.
+func TestVerify_WrongKey(t *testing.T) {
+	u, _ := url.Parse("/downloads")
+	signed := testSigner(time.Unix(1000, 0)).Sign(u, time.Hour)
+
+	verifier := NewSigner([]byte("other"), 0)
+	verifier.NowFn = func() time.Time { return time.Unix(1000, 0) }
+
+	assert.Equal(t, InvalidSignatureError, verifier.Verify(signed))
+}
@@ -0,0 +1,428 @@
+package testing
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"os"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	stdtesting "testing"
+
+	"github.com/pakkasys/fluidapi/core/api"
+	"github.com/pakkasys/fluidapi/endpoint/definition"
+	"github.com/pakkasys/fluidapi/endpoint/middleware/inputlogic"
+	"github.com/stretchr/testify/assert"
+)
+
+// UpdateContractsEnv is the environment variable that makes
+// AssertContractFile write the contract files instead of comparing them,
+// e.g. UPDATE_CONTRACTS=1 go test ./...
+const UpdateContractsEnv = "UPDATE_CONTRACTS"
+
+// Kinds of the contract cases.
+const (
+	// ContractValid is the case of the valid input
+	ContractValid = "valid"
+	// ContractInvalid is a case of an input violating a validation rule
+	ContractInvalid = "invalid"
+	// ContractError is a case of an error returned by the endpoint
+	ContractError = "error"
+)
+
+// Contract declares the contract of an endpoint, from which the contract
+// test cases are generated.
+type Contract struct {
+	// Endpoint is the endpoint definition
+	Endpoint definition.EndpointDefinition
+	// URL of the requests, e.g. with the path parameters filled in. Defaults
+	// to the URL of the endpoint.
+	URL string
+	// Valid is a valid input, sent as the JSON body of the requests. The
+	// invalid inputs violate the `validate` tag rules of its fields, see
+	// inputlogic.TagValidator, one rule at a time.
+	Valid any
+	// Status of the valid input. Defaults to 200 OK.
+	Status int
+	// ExpectedErrors are the expected errors of the endpoint
+	ExpectedErrors []inputlogic.ExpectedError
+	// ErrorIDs are the IDs of the errors of the endpoint declared in the
+	// error catalog instead of the expected errors
+	ErrorIDs []string
+	// Catalog is the error catalog. Defaults to
+	// inputlogic.DefaultErrorCatalog.
+	Catalog *inputlogic.ErrorCatalog
+}
+
+// ContractCase is a case of a contract test. The cases are stored in the
+// contract files, so changing them is a change of the contract.
+type ContractCase struct {
+	Name string `json:"name"`
+	// Kind is ContractValid, ContractInvalid or ContractError
+	Kind string `json:"kind"`
+	// Body is the JSON body of the request of the valid and invalid cases
+	Body map[string]any `json:"body,omitempty"`
+	// Field is the field of the validation error of the invalid cases
+	Field string `json:"field,omitempty"`
+	// Error is the ID of the error returned by the endpoint of the error
+	// cases
+	Error string `json:"error,omitempty"`
+	// Status is the expected status code
+	Status int `json:"status"`
+	// ErrorID is the expected ID of the error in the response
+	ErrorID string `json:"error_id,omitempty"`
+}
+
+// GenerateContract generates the contract test cases of an endpoint: the
+// valid input, an invalid input for each validation rule of the fields of the
+// input, and the statuses and public IDs of the errors of the endpoint. It
+// returns an error if the contract has no valid input or an error ID is not
+// in the catalog.
+//
+//   - contract: The contract of the endpoint.
+func GenerateContract(contract Contract) ([]ContractCase, error) {
+	if contract.Valid == nil {
+		return nil, fmt.Errorf(
+			"contract of endpoint %s %s has no valid input",
+			contract.Endpoint.Method,
+			contract.Endpoint.URL,
+		)
+	}
+	var valid map[string]any
+	if err := decodeJSON(contract.Valid, &valid); err != nil {
+		return nil, err
+	}
+
+	status := contract.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	cases := []ContractCase{{
+		Name:   ContractValid,
+		Kind:   ContractValid,
+		Body:   valid,
+		Status: status,
+	}}
+
+	cases = append(
+		cases,
+		invalidContractCases(reflect.TypeOf(contract.Valid), valid, "")...,
+	)
+
+	errorCases, err := errorContractCases(contract)
+	if err != nil {
+		return nil, err
+	}
+	return append(cases, errorCases...), nil
+}
+
+// RunContract runs the contract test cases of an endpoint as subtests. The
+// valid and invalid cases are served by the endpoint and their responses are
+// expected to be written by output.Handler with the default envelope. The
+// error cases are checked against the error handling of the inputlogic
+// middleware.
+//
+//   - t: The test.
+//   - contract: The contract of the endpoint.
+//   - cases: The contract test cases, e.g. from GenerateContract.
+func RunContract(
+	t *stdtesting.T,
+	contract Contract,
+	cases []ContractCase,
+) {
+	url := contract.URL
+	if url == "" {
+		url = contract.Endpoint.URL
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.Name, func(t *stdtesting.T) {
+			if tt.Kind == ContractError {
+				errorHandler := inputlogic.ErrorHandler{
+					Catalog: contract.Catalog,
+				}
+				status, apiError := errorHandler.Handle(
+					api.NewError[any](tt.Error),
+					contract.ExpectedErrors,
+				)
+				assert.Equal(t, tt.Status, status)
+				assert.Equal(t, tt.ErrorID, apiError.ID)
+				return
+			}
+
+			response := Serve(
+				contract.Endpoint,
+				NewRequest(contract.Endpoint.Method, url, tt.Body),
+			)
+			response.AssertStatus(t, tt.Status)
+			if tt.Kind == ContractInvalid {
+				response.AssertErrorID(t, tt.ErrorID)
+				assert.Contains(t, validationFields(response), tt.Field)
+			}
+		})
+	}
+}
+
+// AssertContractFile asserts the contract test cases are the cases of the
+// contract file, failing on the changes of the contract. If the environment
+// variable UpdateContractsEnv is set, the file is written instead.
+//
+//   - t: The test.
+//   - path: The path of the contract file, e.g.
+//     "testdata/users.create.json".
+//   - cases: The contract test cases, e.g. from GenerateContract.
+func AssertContractFile(
+	t assert.TestingT,
+	path string,
+	cases []ContractCase,
+) bool {
+	data, err := json.MarshalIndent(cases, "", "  ")
+	if !assert.NoError(t, err) {
+		return false
+	}
+	if os.Getenv(UpdateContractsEnv) != "" {
+		err := os.WriteFile(path, append(data, '\n'), 0o644)
+		return assert.NoError(t, err)
+	}
+
+	expected, err := os.ReadFile(path)
+	if !assert.NoError(
+		t,
+		err,
+		"set %s=1 to write the contract file",
+		UpdateContractsEnv,
+	) {
+		return false
+	}
+	return assert.JSONEq(t, string(expected), string(data))
+}
+
+// invalidContractCases returns the invalid cases of the fields of an input
+// type with the validation rules.
+func invalidContractCases(
+	inputType reflect.Type,
+	valid map[string]any,
+	path string,
+) []ContractCase {
+	for inputType.Kind() == reflect.Pointer {
+		inputType = inputType.Elem()
+	}
+	if inputType.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var cases []ContractCase
+	for i := 0; i < inputType.NumField(); i++ {
+		field := inputType.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fieldPath := name
+		if path != "" {
+			fieldPath = path + "." + name
+		}
+
+		for _, rule := range strings.Split(field.Tag.Get("validate"), ",") {
+			rule = strings.TrimSpace(rule)
+			if rule == "" {
+				continue
+			}
+			value, ok := invalidValue(field.Type, valid[name], rule)
+			if !ok {
+				continue
+			}
+			cases = append(cases, ContractCase{
+				Name:    fieldPath + " " + rule,
+				Kind:    ContractInvalid,
+				Body:    withFieldValue(valid, name, value),
+				Field:   fieldPath,
+				Status:  http.StatusBadRequest,
+				ErrorID: inputlogic.ValidationError.ID,
+			})
+		}
+
+		nested, ok := valid[name].(map[string]any)
+		if !ok {
+			continue
+		}
+		for _, nestedCase := range invalidContractCases(
+			field.Type,
+			nested,
+			fieldPath,
+		) {
+			nestedCase.Body = withFieldValue(valid, name, nestedCase.Body)
+			cases = append(cases, nestedCase)
+		}
+	}
+	return cases
+}
+
+// removedValue marks the fields removed from the bodies.
+type removedValue struct{}
+
+// invalidValue returns a JSON value of a field violating the rule, or false
+// if no such value is derived for the type of the field.
+func invalidValue(
+	fieldType reflect.Type,
+	validValue any,
+	rule string,
+) (any, bool) {
+	name, parameter, _ := strings.Cut(rule, "=")
+	if name == "required" {
+		return removedValue{}, true
+	}
+	for fieldType.Kind() == reflect.Pointer {
+		fieldType = fieldType.Elem()
+	}
+
+	switch name {
+	case "min", "max", "len":
+		bound, err := strconv.Atoi(parameter)
+		if err != nil {
+			return nil, false
+		}
+		size := bound + 1
+		if name == "min" {
+			size = bound - 1
+		}
+		return sizedValue(fieldType, validValue, size)
+	case "oneof":
+		values := strings.Fields(parameter)
+		if fieldType.Kind() == reflect.String {
+			invalid := "invalid"
+			for slices.Contains(values, invalid) {
+				invalid += "_"
+			}
+			return invalid, true
+		}
+		return sizedValue(fieldType, validValue, maxNumber(values)+1)
+	}
+	return nil, false
+}
+
+// sizedValue returns a number of the size, or a string or a slice of the
+// length of the size. Negative sizes are valid only for signed numbers.
+func sizedValue(fieldType reflect.Type, validValue any, size int) (any, bool) {
+	switch fieldType.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Int64, reflect.Float32, reflect.Float64:
+		return size, true
+	}
+	if size < 0 {
+		return nil, false
+	}
+
+	switch fieldType.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64:
+		return size, true
+	case reflect.String:
+		return strings.Repeat("a", size), true
+	case reflect.Slice, reflect.Array:
+		elements, _ := validValue.([]any)
+		if len(elements) == 0 {
+			return nil, false
+		}
+		value := make([]any, size)
+		for i := range value {
+			value[i] = elements[0]
+		}
+		return value, true
+	}
+	return nil, false
+}
+
+func maxNumber(values []string) int {
+	maximum := 0
+	for _, value := range values {
+		if number, err := strconv.Atoi(value); err == nil && number > maximum {
+			maximum = number
+		}
+	}
+	return maximum
+}
+
+// withFieldValue returns a copy of a body with the value of a field set, or
+// the field removed for removedValue.
+func withFieldValue(
+	body map[string]any,
+	field string,
+	value any,
+) map[string]any {
+	copied := maps.Clone(body)
+	if _, ok := value.(removedValue); ok {
+		delete(copied, field)
+	} else {
+		copied[field] = value
+	}
+	return copied
+}
+
+// errorContractCases returns the error cases of the expected errors and the
+// catalog errors of a contract.
+func errorContractCases(contract Contract) ([]ContractCase, error) {
+	catalog := contract.Catalog
+	if catalog == nil {
+		catalog = inputlogic.DefaultErrorCatalog
+	}
+
+	expectedErrors := slices.Clone(contract.ExpectedErrors)
+	for _, id := range contract.ErrorIDs {
+		expectedError, ok := catalog.Get(id)
+		if !ok {
+			return nil, fmt.Errorf("error %q is not in the error catalog", id)
+		}
+		expectedErrors = append(expectedErrors, expectedError)
+	}
+
+	cases := make([]ContractCase, len(expectedErrors))
+	for i, expectedError := range expectedErrors {
+		errorID := expectedError.ID
+		if expectedError.MaskedID != nil {
+			errorID = *expectedError.MaskedID
+		}
+		cases[i] = ContractCase{
+			Name:    "error " + expectedError.ID,
+			Kind:    ContractError,
+			Error:   expectedError.ID,
+			Status:  expectedError.Status,
+			ErrorID: errorID,
+		}
+	}
+	return cases, nil
+}
+
+// validationFields returns the fields of the validation error in the body of
+// a response.
+func validationFields(response *Response) []string {
+	var body struct {
+		Error struct {
+			Data inputlogic.ValidationErrorData `json:"data"`
+		} `json:"error"`
+	}
+	_ = response.DecodeJSON(&body)
+	fields := make([]string, len(body.Error.Data.Errors))
+	for i, fieldError := range body.Error.Data.Errors {
+		fields[i] = fieldError.Field
+	}
+	return fields
+}
+
+// decodeJSON decodes the JSON encoding of a value.
+func decodeJSON(value any, target any) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, target)
+}
@@ -0,0 +1,178 @@
+package testing_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pakkasys/fluidapi/endpoint/middleware/inputlogic"
+	endpointtest "github.com/pakkasys/fluidapi/endpoint/testing"
+	"github.com/stretchr/testify/assert"
+)
+
+type addressInput struct {
+	City string `json:"city" validate:"required,max=5"`
+}
+
+type orderInput struct {
+	Item     string        `json:"item" validate:"required,oneof=book pen"`
+	Quantity int           `json:"quantity" validate:"min=1,max=10"`
+	Tags     []string      `json:"tags" validate:"len=1"`
+	Address  *addressInput `json:"address"`
+	Note     string        `json:"note"`
+}
+
+func (i orderInput) Validate() []inputlogic.FieldError {
+	return inputlogic.TagValidator{}.ValidateStruct(i)
+}
+
+func pickJSON(
+	r *http.Request,
+	w http.ResponseWriter,
+	obj orderInput,
+) (*orderInput, error) {
+	if err := json.NewDecoder(r.Body).Decode(&obj); err != nil {
+		return nil, err
+	}
+	return &obj, nil
+}
+
+func orderContract() endpointtest.Contract {
+	endpoint, _ := endpointtest.NewEndpoint(
+		http.MethodPost,
+		"/orders",
+		func(
+			w http.ResponseWriter,
+			r *http.Request,
+			input *orderInput,
+		) (*orderInput, error) {
+			return input, nil
+		},
+		endpointtest.ObjectPickerFunc[orderInput](pickJSON),
+		nil,
+	)
+	masked := "NOT_FOUND"
+	return endpointtest.Contract{
+		Endpoint: endpoint,
+		Valid: orderInput{
+			Item:     "book",
+			Quantity: 2,
+			Tags:     []string{"gift"},
+			Address:  &addressInput{City: "Oulu"},
+		},
+		ExpectedErrors: []inputlogic.ExpectedError{
+			{ID: "OUT_OF_STOCK", Status: http.StatusConflict},
+			{ID: "HIDDEN", Status: http.StatusNotFound, MaskedID: &masked},
+		},
+		ErrorIDs: []string{"RATE_LIMITED"},
+		Catalog: inputlogic.NewErrorCatalog(inputlogic.ExpectedError{
+			ID:     "RATE_LIMITED",
+			Status: http.StatusTooManyRequests,
+		}),
+	}
+}
+
+// TestGenerateContract tests generating the cases of the validation rules
+// and the errors of an endpoint.
+func TestGenerateContract(t *testing.T) {
+	cases, err := endpointtest.GenerateContract(orderContract())
+
+	assert.NoError(t, err)
+	names := []string{}
+	for _, contractCase := range cases {
+		names = append(names, contractCase.Name)
+	}
+	assert.Equal(t, []string{
+		"valid",
+		"item required",
+		"item oneof=book pen",
+		"quantity min=1",
+		"quantity max=10",
+		"tags len=1",
+		"address.city required",
+		"address.city max=5",
+		"error OUT_OF_STOCK",
+		"error HIDDEN",
+		"error RATE_LIMITED",
+	}, names)
+
+	assert.Equal(t, endpointtest.ContractCase{
+		Name: "address.city max=5",
+		Kind: endpointtest.ContractInvalid,
+		Body: map[string]any{
+			"item":     "book",
+			"quantity": float64(2),
+			"tags":     []any{"gift"},
+			"address":  map[string]any{"city": "aaaaaa"},
+			"note":     "",
+		},
+		Field:   "address.city",
+		Status:  http.StatusBadRequest,
+		ErrorID: inputlogic.ValidationError.ID,
+	}, cases[7])
+	assert.NotContains(t, cases[1].Body, "item")
+	assert.Equal(t, "invalid", cases[2].Body["item"])
+	assert.Equal(t, 0, cases[3].Body["quantity"])
+	assert.Equal(t, []any{"gift", "gift"}, cases[5].Body["tags"])
+	assert.Equal(t, endpointtest.ContractCase{
+		Name:    "error HIDDEN",
+		Kind:    endpointtest.ContractError,
+		Error:   "HIDDEN",
+		Status:  http.StatusNotFound,
+		ErrorID: "NOT_FOUND",
+	}, cases[9])
+}
+
+// TestGenerateContract_Errors tests the errors of invalid contracts.
+func TestGenerateContract_Errors(t *testing.T) {
+	contract := orderContract()
+	contract.Valid = nil
+
+	_, err := endpointtest.GenerateContract(contract)
+
+	assert.EqualError(
+		t,
+		err,
+		"contract of endpoint POST /orders has no valid input",
+	)
+
+	contract = orderContract()
+	contract.ErrorIDs = []string{"UNKNOWN"}
+
+	_, err = endpointtest.GenerateContract(contract)
+
+	assert.EqualError(t, err, `error "UNKNOWN" is not in the error catalog`)
+}
+
+// TestRunContract tests running the generated cases against the endpoint.
+func TestRunContract(t *testing.T) {
+	contract := orderContract()
+	cases, err := endpointtest.GenerateContract(contract)
+	assert.NoError(t, err)
+
+	endpointtest.RunContract(t, contract, cases)
+}
+
+// TestAssertContractFile tests comparing and writing the contract files.
+func TestAssertContractFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "orders.json")
+	cases, err := endpointtest.GenerateContract(orderContract())
+	assert.NoError(t, err)
+
+	failing := &failingT{}
+	assert.False(t, endpointtest.AssertContractFile(failing, path, cases))
+
+	t.Setenv(endpointtest.UpdateContractsEnv, "1")
+	assert.True(t, endpointtest.AssertContractFile(t, path, cases))
+	t.Setenv(endpointtest.UpdateContractsEnv, "")
+
+	assert.True(t, endpointtest.AssertContractFile(t, path, cases))
+
+	cases[0].Status = http.StatusCreated
+	assert.False(t, endpointtest.AssertContractFile(failing, path, cases))
+	assert.Equal(t, 2, failing.failures)
+	_, err = os.Stat(path)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,105 @@
+// Package testing runs endpoint definitions against httptest requests, i.e.
+// their whole middleware stacks with the inputlogic middleware, and provides
+// fakes of the object pickers and output handlers of the endpoints. It is
+// imported with a name, e.g.
+//
+//	import endpointtest "github.com/pakkasys/fluidapi/endpoint/testing"
+package testing
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/pakkasys/fluidapi/core/api"
+	"github.com/pakkasys/fluidapi/endpoint/definition"
+)
+
+// Handler returns the HTTP handler of an endpoint definition as it is served,
+// i.e. its middlewares with the overrides, permissions and deprecation of the
+// definition applied. The requests not matching the method and the URL of
+// the endpoint are answered with 404 Not Found or 405 Method Not Allowed. The
+// path parameters of the URL are available to the middlewares, e.g. to the
+// path object pickers. It panics if the definition cannot be converted to an
+// API endpoint, e.g. if it requires permissions without the authorization
+// middleware.
+//
+//   - endpoint: The endpoint definition.
+func Handler(endpoint definition.EndpointDefinition) http.Handler {
+	endpoints, err := definition.EndpointDefinitionsToAPIEndpoints(
+		[]definition.EndpointDefinition{endpoint},
+	)
+	if err != nil {
+		panic(err)
+	}
+	handler := api.ApplyMiddlewares(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}),
+		endpoints[0].Middlewares...,
+	)
+
+	names := api.PathParameterNames(endpoint.URL)
+	mux := http.NewServeMux()
+	mux.HandleFunc(
+		endpoint.Method+" "+endpoint.URL,
+		func(w http.ResponseWriter, r *http.Request) {
+			parameters := make(map[string]string, len(names))
+			for _, name := range names {
+				parameters[name] = r.PathValue(name)
+			}
+			handler.ServeHTTP(
+				w,
+				r.WithContext(
+					api.WithPathParameters(r.Context(), parameters),
+				),
+			)
+		},
+	)
+	return mux
+}
+
+// Serve serves a request with an endpoint definition and returns the
+// recorded response.
+//
+//   - endpoint: The endpoint definition.
+//   - r: The request, e.g. from NewRequest.
+func Serve(endpoint definition.EndpointDefinition, r *http.Request) *Response {
+	recorder := httptest.NewRecorder()
+	Handler(endpoint).ServeHTTP(recorder, r)
+	return &Response{ResponseRecorder: recorder}
+}
+
+// NewRequest returns a new test request. A body other than nil, a string,
+// a byte slice or a reader is encoded as JSON with the Content-Type header
+// set. It panics if the body cannot be encoded.
+//
+//   - method: The HTTP method.
+//   - target: The URL of the request, e.g. "/users/1?fields=name".
+//   - body: The body of the request. Optional.
+func NewRequest(method string, target string, body any) *http.Request {
+	var reader io.Reader
+	isJSON := false
+	switch body := body.(type) {
+	case nil:
+	case string:
+		reader = bytes.NewBufferString(body)
+	case []byte:
+		reader = bytes.NewReader(body)
+	case io.Reader:
+		reader = body
+	default:
+		data, err := json.Marshal(body)
+		if err != nil {
+			panic(err.Error())
+		}
+		reader = bytes.NewReader(data)
+		isJSON = true
+	}
+
+	r := httptest.NewRequest(method, target, reader)
+	if isJSON {
+		r.Header.Set("Content-Type", "application/json")
+	}
+	return r
+}
@@ -0,0 +1,112 @@
+package testing_test
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/pakkasys/fluidapi/core/api"
+	"github.com/pakkasys/fluidapi/endpoint/definition"
+	"github.com/pakkasys/fluidapi/endpoint/middleware"
+	endpointtest "github.com/pakkasys/fluidapi/endpoint/testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func pathEndpoint(calls *[]string) definition.EndpointDefinition {
+	return definition.EndpointDefinition{
+		URL:    "/users/{id}",
+		Method: http.MethodGet,
+		MiddlewareStack: middleware.Stack{{
+			ID: "path",
+			Middleware: func(next http.Handler) http.Handler {
+				return http.HandlerFunc(
+					func(w http.ResponseWriter, r *http.Request) {
+						*calls = append(
+							*calls,
+							api.PathParameter(r.Context(), "id"),
+						)
+						w.WriteHeader(http.StatusNoContent)
+					},
+				)
+			},
+		}},
+	}
+}
+
+// TestServe tests serving a request with the middlewares of an endpoint and
+// its path parameters.
+func TestServe(t *testing.T) {
+	calls := []string{}
+
+	response := endpointtest.Serve(
+		pathEndpoint(&calls),
+		endpointtest.NewRequest(http.MethodGet, "/users/42", nil),
+	)
+
+	response.AssertStatus(t, http.StatusNoContent)
+	assert.Equal(t, []string{"42"}, calls)
+}
+
+// TestServe_NotMatching tests serving requests not matching the endpoint.
+func TestServe_NotMatching(t *testing.T) {
+	calls := []string{}
+
+	response := endpointtest.Serve(
+		pathEndpoint(&calls),
+		endpointtest.NewRequest(http.MethodGet, "/teams/42", nil),
+	)
+
+	response.AssertStatus(t, http.StatusNotFound)
+
+	response = endpointtest.Serve(
+		pathEndpoint(&calls),
+		endpointtest.NewRequest(http.MethodPost, "/users/42", nil),
+	)
+
+	response.AssertStatus(t, http.StatusMethodNotAllowed)
+	assert.Empty(t, calls)
+}
+
+// TestNewRequest tests the bodies of the test requests.
+func TestNewRequest(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        any
+		expected    string
+		contentType string
+	}{
+		{name: "nil", body: nil, expected: ""},
+		{name: "string", body: "a=1", expected: "a=1"},
+		{name: "bytes", body: []byte("raw"), expected: "raw"},
+		{
+			name:     "reader",
+			body:     strings.NewReader("stream"),
+			expected: "stream",
+		},
+		{
+			name:        "JSON",
+			body:        map[string]any{"name": "alice"},
+			expected:    `{"name":"alice"}`,
+			contentType: "application/json",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := endpointtest.NewRequest(http.MethodPost, "/users", tt.body)
+
+			body, err := io.ReadAll(r.Body)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, string(body))
+			assert.Equal(t, tt.contentType, r.Header.Get("Content-Type"))
+		})
+	}
+}
+
+// TestNewRequest_Panic tests the panic of a body that cannot be encoded.
+func TestNewRequest_Panic(t *testing.T) {
+	assert.Panics(t, func() {
+		endpointtest.NewRequest(http.MethodPost, "/users", func() {})
+	})
+}
@@ -0,0 +1,174 @@
+package testing
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/pakkasys/fluidapi/core/api"
+	"github.com/pakkasys/fluidapi/endpoint/definition"
+	"github.com/pakkasys/fluidapi/endpoint/middleware"
+	"github.com/pakkasys/fluidapi/endpoint/middleware/inputlogic"
+	"github.com/pakkasys/fluidapi/endpoint/output"
+	"github.com/stretchr/testify/assert"
+)
+
+// ObjectPickerFunc is a fake object picker calling the function.
+type ObjectPickerFunc[T any] func(
+	r *http.Request,
+	w http.ResponseWriter,
+	obj T,
+) (*T, error)
+
+// PickObject calls the function.
+//
+//   - r: The HTTP request.
+//   - w: The HTTP response writer.
+//   - obj: The input object of the inputlogic middleware.
+func (f ObjectPickerFunc[T]) PickObject(
+	r *http.Request,
+	w http.ResponseWriter,
+	obj T,
+) (*T, error) {
+	return f(r, w, obj)
+}
+
+// PickInput returns a fake object picker picking a copy of the input for each
+// request, regardless of the request.
+//
+//   - input: The picked input.
+func PickInput[T any](input T) ObjectPickerFunc[T] {
+	return func(r *http.Request, w http.ResponseWriter, obj T) (*T, error) {
+		picked := input
+		return &picked, nil
+	}
+}
+
+// PickError returns a fake object picker failing with the error, e.g. an
+// inputlogic.ValidationError.
+//
+//   - err: The error of the picker.
+func PickError[T any](err error) ObjectPickerFunc[T] {
+	return func(r *http.Request, w http.ResponseWriter, obj T) (*T, error) {
+		return nil, err
+	}
+}
+
+// OutputCall is a recorded call of an output handler.
+type OutputCall struct {
+	Output     any
+	Error      error
+	StatusCode int
+}
+
+// OutputRecorder is an output handler recording the outputs and the errors of
+// an endpoint, e.g. to assert the errors before they are encoded.
+type OutputRecorder struct {
+	// Handler writes the outputs. If nil, output.Handler with the JSON and
+	// XML encoders is used.
+	Handler inputlogic.IOutputHandler
+
+	mu    sync.Mutex
+	calls []OutputCall
+}
+
+// ProcessOutput records the call and writes the output with the handler of
+// the recorder.
+//
+//   - w: The HTTP response writer.
+//   - r: The HTTP request.
+//   - out: The output of the endpoint.
+//   - outError: The error of the endpoint.
+//   - statusCode: The HTTP status code.
+func (o *OutputRecorder) ProcessOutput(
+	w http.ResponseWriter,
+	r *http.Request,
+	out any,
+	outError error,
+	statusCode int,
+) error {
+	o.mu.Lock()
+	o.calls = append(o.calls, OutputCall{
+		Output:     out,
+		Error:      outError,
+		StatusCode: statusCode,
+	})
+	o.mu.Unlock()
+
+	handler := o.Handler
+	if handler == nil {
+		handler = output.NewHandler(nil)
+	}
+	return handler.ProcessOutput(w, r, out, outError, statusCode)
+}
+
+// Calls returns the recorded calls.
+func (o *OutputRecorder) Calls() []OutputCall {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return append([]OutputCall{}, o.calls...)
+}
+
+// Last returns the last recorded call, or false if there are no calls.
+func (o *OutputRecorder) Last() (OutputCall, bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if len(o.calls) == 0 {
+		return OutputCall{}, false
+	}
+	return o.calls[len(o.calls)-1], true
+}
+
+// AssertErrorID asserts the ID of the API error of the last recorded call.
+//
+//   - t: The test.
+//   - id: The expected error ID.
+func (o *OutputRecorder) AssertErrorID(t assert.TestingT, id string) bool {
+	call, ok := o.Last()
+	if !assert.True(t, ok, "no output recorded") {
+		return false
+	}
+	apiError, ok := call.Error.(api.APIError)
+	if !assert.True(t, ok, "not an API error: %v", call.Error) {
+		return false
+	}
+	return assert.Equal(t, id, apiError.GetID())
+}
+
+// NewEndpoint returns an endpoint definition running the callback with the
+// inputlogic middleware after the middlewares, and the recorder of its
+// outputs.
+//
+// Input is the type of input to the callback.
+// Output is the type of output expected from the callback.
+//
+//   - method: The HTTP method of the endpoint.
+//   - url: The URL of the endpoint, e.g. "/users/{id}".
+//   - callback: The function that handles the requests.
+//   - objectPicker: The object picker of the input, e.g. PickInput.
+//   - expectedErrors: The expected errors of the callback.
+//   - middlewares: The middlewares before the inputlogic middleware.
+func NewEndpoint[Input inputlogic.ValidatedInput, Output any](
+	method string,
+	url string,
+	callback inputlogic.Callback[Input, Output],
+	objectPicker inputlogic.IObjectPicker[Input],
+	expectedErrors []inputlogic.ExpectedError,
+	middlewares ...api.MiddlewareWrapper,
+) (definition.EndpointDefinition, *OutputRecorder) {
+	recorder := &OutputRecorder{}
+	stack := append(middleware.Stack{}, middlewares...)
+	stack = append(stack, *inputlogic.MiddlewareWrapper(
+		callback,
+		func() *Input { return new(Input) },
+		expectedErrors,
+		inputlogic.Options[Input]{
+			ObjectPicker:  objectPicker,
+			OutputHandler: recorder,
+		},
+	))
+	return definition.EndpointDefinition{
+		URL:             url,
+		Method:          method,
+		MiddlewareStack: stack,
+	}, recorder
+}
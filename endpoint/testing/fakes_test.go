@@ -0,0 +1,159 @@
+package testing_test
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/pakkasys/fluidapi/core/api"
+	"github.com/pakkasys/fluidapi/endpoint/middleware/inputlogic"
+	endpointtest "github.com/pakkasys/fluidapi/endpoint/testing"
+	"github.com/stretchr/testify/assert"
+)
+
+type createUserInput struct {
+	Name string `json:"name"`
+}
+
+func (i createUserInput) Validate() []inputlogic.FieldError {
+	if i.Name == "" {
+		return []inputlogic.FieldError{{Field: "name", Message: "required"}}
+	}
+	return nil
+}
+
+type userOutput struct {
+	Name string `json:"name"`
+}
+
+var errUserExists = api.NewError[any]("USER_EXISTS")
+
+func createUser(
+	w http.ResponseWriter,
+	r *http.Request,
+	input *createUserInput,
+) (*userOutput, error) {
+	if input.Name == "taken" {
+		return nil, errUserExists
+	}
+	return &userOutput{Name: input.Name}, nil
+}
+
+var createUserErrors = []inputlogic.ExpectedError{
+	{ID: errUserExists.ID, Status: http.StatusConflict},
+}
+
+// TestNewEndpoint tests running a callback with the fake object picker and
+// the output recorder.
+func TestNewEndpoint(t *testing.T) {
+	calls := []string{}
+	endpoint, recorder := endpointtest.NewEndpoint(
+		http.MethodPost,
+		"/users",
+		createUser,
+		endpointtest.PickInput(createUserInput{Name: "alice"}),
+		createUserErrors,
+		api.MiddlewareWrapper{
+			ID: "first",
+			Middleware: func(next http.Handler) http.Handler {
+				return http.HandlerFunc(
+					func(w http.ResponseWriter, r *http.Request) {
+						calls = append(calls, "first")
+						next.ServeHTTP(w, r)
+					},
+				)
+			},
+		},
+	)
+
+	response := endpointtest.Serve(
+		endpoint,
+		endpointtest.NewRequest(http.MethodPost, "/users", nil),
+	)
+
+	response.AssertStatus(t, http.StatusOK)
+	response.AssertPayload(t, userOutput{Name: "alice"})
+	assert.Equal(t, []string{"first"}, calls)
+	assert.Equal(t, []endpointtest.OutputCall{{
+		Output:     &userOutput{Name: "alice"},
+		StatusCode: http.StatusOK,
+	}}, recorder.Calls())
+}
+
+// TestNewEndpoint_Errors tests asserting the errors of the callback and the
+// object picker.
+func TestNewEndpoint_Errors(t *testing.T) {
+	endpoint, recorder := endpointtest.NewEndpoint(
+		http.MethodPost,
+		"/users",
+		createUser,
+		endpointtest.PickInput(createUserInput{Name: "taken"}),
+		createUserErrors,
+	)
+
+	response := endpointtest.Serve(
+		endpoint,
+		endpointtest.NewRequest(http.MethodPost, "/users", nil),
+	)
+
+	response.AssertStatus(t, http.StatusConflict)
+	response.AssertErrorID(t, "USER_EXISTS")
+	recorder.AssertErrorID(t, "USER_EXISTS")
+
+	endpoint, recorder = endpointtest.NewEndpoint(
+		http.MethodPost,
+		"/users",
+		createUser,
+		endpointtest.PickError[createUserInput](errors.New("broken")),
+		createUserErrors,
+	)
+
+	response = endpointtest.Serve(
+		endpoint,
+		endpointtest.NewRequest(http.MethodPost, "/users", nil),
+	)
+
+	response.AssertStatus(t, http.StatusInternalServerError)
+	recorder.AssertErrorID(t, inputlogic.InternalServerError.ID)
+}
+
+// TestObjectPickerFunc tests picking the input with a function.
+func TestObjectPickerFunc(t *testing.T) {
+	endpoint, _ := endpointtest.NewEndpoint(
+		http.MethodPost,
+		"/users",
+		createUser,
+		endpointtest.ObjectPickerFunc[createUserInput](
+			func(
+				r *http.Request,
+				w http.ResponseWriter,
+				obj createUserInput,
+			) (*createUserInput, error) {
+				obj.Name = r.URL.Query().Get("name")
+				return &obj, nil
+			},
+		),
+		createUserErrors,
+	)
+
+	response := endpointtest.Serve(
+		endpoint,
+		endpointtest.NewRequest(http.MethodPost, "/users?name=", nil),
+	)
+
+	response.AssertStatus(t, http.StatusBadRequest)
+	response.AssertErrorID(t, inputlogic.ValidationError.ID)
+}
+
+// TestOutputRecorder_Last tests the last call and the failing assertions
+// without calls.
+func TestOutputRecorder_Last(t *testing.T) {
+	recorder := &endpointtest.OutputRecorder{}
+
+	_, ok := recorder.Last()
+
+	assert.False(t, ok)
+	failing := &failingT{}
+	assert.False(t, recorder.AssertErrorID(failing, "ANY"))
+	assert.Equal(t, 1, failing.failures)
+}
@@ -0,0 +1,98 @@
+package testing
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+
+	"github.com/pakkasys/fluidapi/endpoint/output"
+	"github.com/stretchr/testify/assert"
+)
+
+// Response is the recorded response of an endpoint.
+type Response struct {
+	*httptest.ResponseRecorder
+}
+
+// DecodeJSON decodes the JSON body of the response.
+//
+//   - v: A pointer to the decoded value.
+func (r *Response) DecodeJSON(v any) error {
+	return json.Unmarshal(r.Body.Bytes(), v)
+}
+
+// ErrorID returns the ID of the error in the body of the response, as it is
+// written by output.Handler with the default envelope. It returns an error if
+// the body has no error.
+func (r *Response) ErrorID() (string, error) {
+	var body map[string]json.RawMessage
+	if err := r.DecodeJSON(&body); err != nil {
+		return "", err
+	}
+	var apiError struct {
+		ID string `json:"id"`
+	}
+	data, ok := body[output.DefaultErrorKey]
+	if ok {
+		if err := json.Unmarshal(data, &apiError); err != nil {
+			return "", err
+		}
+	}
+	if apiError.ID == "" {
+		return "", fmt.Errorf("no error in body: %s", r.Body.String())
+	}
+	return apiError.ID, nil
+}
+
+// AssertStatus asserts the status code of the response.
+//
+//   - t: The test.
+//   - status: The expected status code.
+func (r *Response) AssertStatus(t assert.TestingT, status int) bool {
+	return assert.Equal(
+		t,
+		status,
+		r.Code,
+		"unexpected status, body: %s",
+		r.Body.String(),
+	)
+}
+
+// AssertJSON asserts the body of the response is the JSON encoding of a
+// value, ignoring the formatting and the order of the object keys.
+//
+//   - t: The test.
+//   - expected: The expected value, or its JSON encoding as a string.
+func (r *Response) AssertJSON(t assert.TestingT, expected any) bool {
+	expectedJSON, ok := expected.(string)
+	if !ok {
+		data, err := json.Marshal(expected)
+		if !assert.NoError(t, err) {
+			return false
+		}
+		expectedJSON = string(data)
+	}
+	return assert.JSONEq(t, expectedJSON, r.Body.String())
+}
+
+// AssertPayload asserts the payload of the response, as it is written by
+// output.Handler with the default envelope, is the JSON encoding of a value.
+//
+//   - t: The test.
+//   - expected: The expected payload.
+func (r *Response) AssertPayload(t assert.TestingT, expected any) bool {
+	return r.AssertJSON(t, output.Output{Payload: expected})
+}
+
+// AssertErrorID asserts the ID of the error in the body of the response, see
+// ErrorID.
+//
+//   - t: The test.
+//   - id: The expected error ID.
+func (r *Response) AssertErrorID(t assert.TestingT, id string) bool {
+	errorID, err := r.ErrorID()
+	if !assert.NoError(t, err) {
+		return false
+	}
+	return assert.Equal(t, id, errorID)
+}
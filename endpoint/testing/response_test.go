@@ -0,0 +1,72 @@
+package testing_test
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	endpointtest "github.com/pakkasys/fluidapi/endpoint/testing"
+	"github.com/stretchr/testify/assert"
+)
+
+// failingT records the failures of the assertions expected to fail.
+type failingT struct {
+	failures int
+}
+
+func (t *failingT) Errorf(format string, args ...any) {
+	t.failures++
+}
+
+func testResponse(status int, body string) *endpointtest.Response {
+	recorder := httptest.NewRecorder()
+	recorder.WriteHeader(status)
+	recorder.WriteString(body)
+	return &endpointtest.Response{ResponseRecorder: recorder}
+}
+
+// TestResponse_ErrorID tests reading the error IDs of the bodies.
+func TestResponse_ErrorID(t *testing.T) {
+	id, err := testResponse(400, `{"error":{"id":"BAD"}}`).ErrorID()
+
+	assert.NoError(t, err)
+	assert.Equal(t, "BAD", id)
+
+	_, err = testResponse(200, `{"payload":{"id":"1"}}`).ErrorID()
+
+	assert.EqualError(t, err, `no error in body: {"payload":{"id":"1"}}`)
+
+	_, err = testResponse(500, "oops").ErrorID()
+
+	assert.Error(t, err)
+}
+
+// TestResponse_Assertions tests the passing and failing assertions.
+func TestResponse_Assertions(t *testing.T) {
+	response := testResponse(200, `{"payload": {"name": "alice", "age": 3}}`)
+
+	assert.True(t, response.AssertStatus(t, 200))
+	assert.True(t, response.AssertJSON(
+		t,
+		`{"payload":{"age":3,"name":"alice"}}`,
+	))
+	assert.True(t, response.AssertPayload(
+		t,
+		map[string]any{"name": "alice", "age": 3},
+	))
+
+	var decoded struct {
+		Payload struct {
+			Name string `json:"name"`
+		} `json:"payload"`
+	}
+	assert.NoError(t, response.DecodeJSON(&decoded))
+	assert.Equal(t, "alice", decoded.Payload.Name)
+
+	failing := &failingT{}
+	assert.False(t, response.AssertStatus(failing, 404))
+	assert.False(t, response.AssertPayload(failing, map[string]any{}))
+	assert.False(t, response.AssertErrorID(failing, "BAD"))
+	assert.Equal(t, 3, failing.failures)
+	assert.True(t, testResponse(400, `{"error":{"id":"BAD"}}`).
+		AssertErrorID(t, "BAD"))
+}
@@ -0,0 +1,201 @@
+package update
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/pakkasys/fluidapi/core/api"
+)
+
+type UnknownPatchFieldErrorData struct {
+	Field string `json:"field"`
+}
+
+var UnknownPatchFieldError = api.NewError[UnknownPatchFieldErrorData]("UNKNOWN_PATCH_FIELD")
+
+// FieldMask is the set of API fields present in a partial update.
+type FieldMask map[string]struct{}
+
+// NewFieldMask returns a field mask of the given fields.
+//
+// Parameters:
+// - fields: The fields of the mask.
+//
+// Returns:
+// - A new FieldMask.
+func NewFieldMask(fields ...string) FieldMask {
+	mask := FieldMask{}
+	for _, field := range fields {
+		mask[field] = struct{}{}
+	}
+	return mask
+}
+
+// Contains returns whether the field is in the mask.
+func (m FieldMask) Contains(field string) bool {
+	_, ok := m[field]
+	return ok
+}
+
+// Fields returns the fields of the mask in sorted order.
+func (m FieldMask) Fields() []string {
+	fields := make([]string, 0, len(m))
+	for field := range m {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// UnmarshalJSON sets the mask to the keys of a JSON object.
+func (m *FieldMask) UnmarshalJSON(data []byte) error {
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(data, &object); err != nil {
+		return err
+	}
+	*m = FieldMask{}
+	for field := range object {
+		(*m)[field] = struct{}{}
+	}
+	return nil
+}
+
+// Patch is a partial update of the struct T decoded from a JSON object. Only
+// the fields present in the object are turned into updates, so unspecified
+// columns are not overwritten. Fields set to an explicit null are updated to
+// nil.
+//
+// Example:
+//
+//	type UserPatch struct {
+//		Name  *string `json:"name"`
+//		Email *string `json:"email"`
+//	}
+//
+//	type PatchUserInput struct {
+//		ID      int                     `json:"id"`
+//		Updates update.Patch[UserPatch] `json:"updates"`
+//	}
+type Patch[T any] struct {
+	// Value is the decoded struct
+	Value T
+	// Mask contains the fields present in the JSON object
+	Mask FieldMask
+	// nulls contains the fields set to an explicit null
+	nulls FieldMask
+}
+
+// UnmarshalJSON decodes the JSON object into the value and records its keys
+// in the mask.
+func (p *Patch[T]) UnmarshalJSON(data []byte) error {
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(data, &object); err != nil {
+		return err
+	}
+
+	var value T
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+
+	p.Value = value
+	p.Mask = FieldMask{}
+	p.nulls = FieldMask{}
+	for field, raw := range object {
+		p.Mask[field] = struct{}{}
+		if bytes.Equal(bytes.TrimSpace(raw), []byte("null")) {
+			p.nulls[field] = struct{}{}
+		}
+	}
+	return nil
+}
+
+// Updates returns the updates of the fields in the mask, in the field order
+// of T. The fields are named by their JSON names. Pointer values are
+// dereferenced.
+//
+// Returns:
+// - A list of updates.
+// - An error if the mask contains a field that T does not have.
+func (p Patch[T]) Updates() ([]Update, error) {
+	value := reflect.ValueOf(p.Value)
+	for value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return nil, nil
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return nil, nil
+	}
+
+	remaining := NewFieldMask(p.Mask.Fields()...)
+	var updates []Update
+	valueType := value.Type()
+	for i := 0; i < valueType.NumField(); i++ {
+		field := valueType.Field(i)
+		name, ok := jsonFieldName(field)
+		if !ok {
+			continue
+		}
+		maskField, ok := remaining.match(name)
+		if !ok {
+			continue
+		}
+		delete(remaining, maskField)
+
+		var fieldValue any
+		if !p.nulls.Contains(maskField) {
+			fieldValue = indirect(value.Field(i))
+		}
+		updates = append(updates, Update{Field: name, Value: fieldValue})
+	}
+
+	if len(remaining) > 0 {
+		return nil, UnknownPatchFieldError.WithData(
+			UnknownPatchFieldErrorData{Field: remaining.Fields()[0]},
+		)
+	}
+	return updates, nil
+}
+
+// match returns the mask field matching the JSON name. Like encoding/json,
+// an exact match is preferred over a case-insensitive one.
+func (m FieldMask) match(name string) (string, bool) {
+	if m.Contains(name) {
+		return name, true
+	}
+	for _, field := range m.Fields() {
+		if strings.EqualFold(field, name) {
+			return field, true
+		}
+	}
+	return "", false
+}
+
+func jsonFieldName(field reflect.StructField) (string, bool) {
+	if !field.IsExported() {
+		return "", false
+	}
+	name := strings.Split(field.Tag.Get("json"), ",")[0]
+	if name == "-" {
+		return "", false
+	}
+	if name == "" {
+		name = field.Name
+	}
+	return name, true
+}
+
+func indirect(value reflect.Value) any {
+	for value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+	return value.Interface()
+}
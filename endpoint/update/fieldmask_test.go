@@ -0,0 +1,82 @@
+package update
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type userPatch struct {
+	Name    *string `json:"name"`
+	Email   *string `json:"email"`
+	Age     int     `json:"age,omitempty"`
+	Nick    string
+	Ignored string `json:"-"`
+}
+
+// TestFieldMask tests the field mask helpers.
+func TestFieldMask(t *testing.T) {
+	var mask FieldMask
+	err := json.Unmarshal([]byte(`{"b": 1, "a": null}`), &mask)
+
+	assert.NoError(t, err)
+	assert.Equal(t, NewFieldMask("a", "b"), mask)
+	assert.Equal(t, []string{"a", "b"}, mask.Fields())
+	assert.True(t, mask.Contains("a"))
+	assert.False(t, mask.Contains("c"))
+}
+
+// TestPatch_Updates tests that only the present fields are updated.
+func TestPatch_Updates(t *testing.T) {
+	var patch Patch[userPatch]
+	err := json.Unmarshal(
+		[]byte(`{"email": null, "age": 3, "nick": "x"}`),
+		&patch,
+	)
+	assert.NoError(t, err)
+
+	updates, err := patch.Updates()
+
+	assert.NoError(t, err)
+	assert.Equal(t, []Update{
+		{Field: "email", Value: nil},
+		{Field: "age", Value: 3},
+		{Field: "Nick", Value: "x"},
+	}, updates)
+}
+
+// TestPatch_Updates_Pointer tests that pointer values are dereferenced.
+func TestPatch_Updates_Pointer(t *testing.T) {
+	var patch Patch[*userPatch]
+	err := json.Unmarshal([]byte(`{"name": "alice"}`), &patch)
+	assert.NoError(t, err)
+
+	updates, err := patch.Updates()
+
+	assert.NoError(t, err)
+	assert.Equal(t, []Update{{Field: "name", Value: "alice"}}, updates)
+}
+
+// TestPatch_Updates_UnknownField tests that fields T does not have are
+// rejected.
+func TestPatch_Updates_UnknownField(t *testing.T) {
+	var patch Patch[userPatch]
+	err := json.Unmarshal([]byte(`{"name": "a", "Ignored": "b"}`), &patch)
+	assert.NoError(t, err)
+
+	updates, err := patch.Updates()
+
+	assert.Nil(t, updates)
+	assert.Equal(t, UnknownPatchFieldError.WithData(
+		UnknownPatchFieldErrorData{Field: "Ignored"},
+	), err)
+}
+
+// TestPatch_UnmarshalJSON_Invalid tests decoding invalid patches.
+func TestPatch_UnmarshalJSON_Invalid(t *testing.T) {
+	var patch Patch[userPatch]
+
+	assert.Error(t, json.Unmarshal([]byte(`[]`), &patch))
+	assert.Error(t, json.Unmarshal([]byte(`{"age": "x"}`), &patch))
+}
@@ -0,0 +1,101 @@
+package webhook
+
+import (
+	"context"
+
+	"github.com/pakkasys/fluidapi/database/entity"
+	"github.com/pakkasys/fluidapi/database/util"
+)
+
+const (
+	EventCreated = "created"
+	EventUpdated = "updated"
+	EventDeleted = "deleted"
+)
+
+// EntityChange is the data of the update and delete events of an entity.
+type EntityChange struct {
+	// Selectors of the updated or deleted entities.
+	Selectors []util.Selector `json:"selectors"`
+	// Changes are the updated fields and their new values.
+	Changes map[string]any `json:"changes,omitempty"`
+}
+
+// AttachEntity adds hooks to the entity helpers publishing the creates,
+// updates and deletes as events after the transaction commits, or
+// immediately if they are not run in a transaction. The event
+// types are the prefix followed by ".created", ".updated" or ".deleted", e.g.
+// "user.created". The created entity is the data of a create event and an
+// EntityChange the data of the others.
+//
+//   - manager: The webhook manager publishing the events.
+//   - helpers: The entity helpers.
+//   - prefix: The prefix of the event types, e.g. "user".
+func AttachEntity[T any](
+	manager *Manager,
+	helpers *entity.EntityHelpers[T],
+	prefix string,
+) {
+	hooks := &helpers.Hooks
+	hooks.AfterCreate = append(
+		hooks.AfterCreate,
+		func(ctx context.Context, preparer util.Preparer, object *T) error {
+			return manager.publishEntityEvent(
+				ctx,
+				preparer,
+				prefix+"."+EventCreated,
+				object,
+			)
+		},
+	)
+	hooks.AfterUpdate = append(
+		hooks.AfterUpdate,
+		func(
+			ctx context.Context,
+			preparer util.Preparer,
+			selectors []util.Selector,
+			updates entity.Updates,
+		) error {
+			changes := make(map[string]any, len(updates))
+			for _, update := range updates {
+				changes[update.Field] = update.Value
+			}
+			return manager.publishEntityEvent(
+				ctx,
+				preparer,
+				prefix+"."+EventUpdated,
+				EntityChange{Selectors: selectors, Changes: changes},
+			)
+		},
+	)
+	hooks.AfterDelete = append(
+		hooks.AfterDelete,
+		func(
+			ctx context.Context,
+			preparer util.Preparer,
+			selectors []util.Selector,
+		) error {
+			return manager.publishEntityEvent(
+				ctx,
+				preparer,
+				prefix+"."+EventDeleted,
+				EntityChange{Selectors: selectors},
+			)
+		},
+	)
+}
+
+// publishEntityEvent publishes the event after the commit of the transaction
+// or immediately if the preparer is not a transaction.
+func (m *Manager) publishEntityEvent(
+	ctx context.Context,
+	preparer util.Preparer,
+	eventType string,
+	data any,
+) error {
+	if _, isTx := preparer.(util.Tx); isTx {
+		return m.PublishAfterCommit(ctx, eventType, data)
+	}
+	m.publishAndLog(ctx, eventType, data)
+	return nil
+}
@@ -0,0 +1,72 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/pakkasys/fluidapi/database/entity"
+	"github.com/pakkasys/fluidapi/database/util"
+	"github.com/stretchr/testify/assert"
+)
+
+type testUser struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// TestAttachEntity tests publishing the entity changes as events.
+func TestAttachEntity(t *testing.T) {
+	manager, queue := testManager(Config{})
+	manager.Subscribe(Subscription{
+		ID:         "sub",
+		EventTypes: []string{AllEvents},
+	})
+	helpers := &entity.EntityHelpers[testUser]{TableName: "users"}
+	AttachEntity(manager, helpers, "user")
+	ctx := context.Background()
+	selectors := []util.Selector{
+		{Table: "users", Field: "id", Predicate: "=", Value: 1},
+	}
+
+	hooks := helpers.Hooks
+	assert.NoError(t, hooks.AfterCreate[0](
+		ctx,
+		nil,
+		&testUser{ID: 1, Name: "alice"},
+	))
+	assert.NoError(t, hooks.AfterUpdate[0](
+		ctx,
+		nil,
+		selectors,
+		entity.Updates{{Field: "name", Value: "bob"}},
+	))
+	assert.NoError(t, hooks.AfterDelete[0](ctx, nil, selectors))
+
+	expected := []struct {
+		eventType string
+		data      string
+	}{
+		{"user.created", `{"id":1,"name":"alice"}`},
+		{
+			"user.updated",
+			`{"selectors":[{"Table":"users","Field":"id","Predicate":"=",` +
+				`"Value":1}],"changes":{"name":"bob"}}`,
+		},
+		{
+			"user.deleted",
+			`{"selectors":[{"Table":"users","Field":"id","Predicate":"=",` +
+				`"Value":1}]}`,
+		},
+	}
+	for _, event := range expected {
+		delivery, err := queue.Dequeue(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, event.eventType, delivery.EventType)
+		var payload struct {
+			Data json.RawMessage `json:"data"`
+		}
+		assert.NoError(t, json.Unmarshal(delivery.Payload, &payload))
+		assert.JSONEq(t, event.data, string(payload.Data))
+	}
+}
@@ -0,0 +1,150 @@
+package webhook
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Queue stores the pending deliveries, e.g. in memory, a database table or a
+// message broker.
+type Queue interface {
+	// Enqueue adds a delivery to the queue. The delivery must not be
+	// dequeued before its NextAttempt time.
+	Enqueue(ctx context.Context, delivery *Delivery) error
+	// Dequeue removes and returns the next due delivery. It blocks until a
+	// delivery is due or the context is done.
+	Dequeue(ctx context.Context) (*Delivery, error)
+}
+
+// DeadLetterQueue stores the deliveries that failed on the last attempt, so
+// that they can be inspected and redelivered.
+type DeadLetterQueue interface {
+	DeadLetter(ctx context.Context, delivery *Delivery) error
+}
+
+// MemoryQueue is an in-memory delivery queue for single instance services
+// and tests. The pending deliveries are lost when the process exits. It is
+// safe for concurrent use.
+type MemoryQueue struct {
+	mu         sync.Mutex
+	deliveries []*Delivery
+	notify     chan struct{}
+	nowFn      func() time.Time
+}
+
+// NewMemoryQueue creates a new in-memory delivery queue.
+func NewMemoryQueue() *MemoryQueue {
+	return &MemoryQueue{
+		notify: make(chan struct{}),
+		nowFn:  time.Now,
+	}
+}
+
+// Enqueue adds a delivery to the queue.
+func (q *MemoryQueue) Enqueue(ctx context.Context, delivery *Delivery) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.deliveries = append(q.deliveries, delivery)
+	close(q.notify)
+	q.notify = make(chan struct{})
+	return nil
+}
+
+// Dequeue removes and returns the due delivery with the earliest
+// NextAttempt time.
+func (q *MemoryQueue) Dequeue(ctx context.Context) (*Delivery, error) {
+	for {
+		q.mu.Lock()
+		delivery, wait := q.next()
+		notify := q.notify
+		q.mu.Unlock()
+		if delivery != nil {
+			return delivery, nil
+		}
+
+		var timer *time.Timer
+		var due <-chan time.Time
+		if wait > 0 {
+			timer = time.NewTimer(wait)
+			due = timer.C
+		}
+		select {
+		case <-ctx.Done():
+			if timer != nil {
+				timer.Stop()
+			}
+			return nil, ctx.Err()
+		case <-notify:
+		case <-due:
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+	}
+}
+
+// Len returns the number of pending deliveries.
+func (q *MemoryQueue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return len(q.deliveries)
+}
+
+// next removes and returns the earliest due delivery, or returns the time
+// until the earliest delivery is due.
+func (q *MemoryQueue) next() (*Delivery, time.Duration) {
+	if len(q.deliveries) == 0 {
+		return nil, 0
+	}
+	earliest := 0
+	for i, delivery := range q.deliveries {
+		if delivery.NextAttempt.Before(q.deliveries[earliest].NextAttempt) {
+			earliest = i
+		}
+	}
+	delivery := q.deliveries[earliest]
+	wait := delivery.NextAttempt.Sub(q.nowFn())
+	if wait > 0 {
+		return nil, wait
+	}
+	q.deliveries = append(
+		q.deliveries[:earliest],
+		q.deliveries[earliest+1:]...,
+	)
+	return delivery, 0
+}
+
+// MemoryDeadLetters is an in-memory dead letter queue. It is safe for
+// concurrent use.
+type MemoryDeadLetters struct {
+	mu         sync.Mutex
+	deliveries []*Delivery
+}
+
+// NewMemoryDeadLetters creates a new in-memory dead letter queue.
+func NewMemoryDeadLetters() *MemoryDeadLetters {
+	return &MemoryDeadLetters{}
+}
+
+// DeadLetter stores the failed delivery.
+func (d *MemoryDeadLetters) DeadLetter(
+	ctx context.Context,
+	delivery *Delivery,
+) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.deliveries = append(d.deliveries, delivery)
+	return nil
+}
+
+// Deliveries returns the failed deliveries.
+func (d *MemoryDeadLetters) Deliveries() []*Delivery {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return append([]*Delivery{}, d.deliveries...)
+}
@@ -0,0 +1,83 @@
+package webhook
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestMemoryQueue_Order tests dequeueing the due deliveries in the order of
+// their next attempt times.
+func TestMemoryQueue_Order(t *testing.T) {
+	queue := NewMemoryQueue()
+	queue.nowFn = func() time.Time { return testTime }
+	ctx := context.Background()
+
+	assert.NoError(t, queue.Enqueue(ctx, &Delivery{
+		ID:          "later",
+		NextAttempt: testTime.Add(-time.Second),
+	}))
+	assert.NoError(t, queue.Enqueue(ctx, &Delivery{
+		ID:          "earlier",
+		NextAttempt: testTime.Add(-time.Minute),
+	}))
+
+	first, err := queue.Dequeue(ctx)
+	assert.NoError(t, err)
+	second, err := queue.Dequeue(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "earlier", first.ID)
+	assert.Equal(t, "later", second.ID)
+	assert.Equal(t, 0, queue.Len())
+}
+
+// TestMemoryQueue_Wait tests waiting for a delivery to be enqueued and to
+// become due.
+func TestMemoryQueue_Wait(t *testing.T) {
+	queue := NewMemoryQueue()
+	ctx := context.Background()
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		_ = queue.Enqueue(ctx, &Delivery{
+			ID:          "delayed",
+			NextAttempt: time.Now().Add(20 * time.Millisecond),
+		})
+	}()
+
+	delivery, err := queue.Dequeue(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "delayed", delivery.ID)
+	assert.False(t, time.Now().Before(delivery.NextAttempt))
+}
+
+// TestMemoryQueue_Cancel tests that dequeueing stops when the context is
+// done.
+func TestMemoryQueue_Cancel(t *testing.T) {
+	queue := NewMemoryQueue()
+	_ = queue.Enqueue(context.Background(), &Delivery{
+		NextAttempt: time.Now().Add(time.Hour),
+	})
+	ctx, cancel := context.WithTimeout(
+		context.Background(),
+		10*time.Millisecond,
+	)
+	defer cancel()
+
+	delivery, err := queue.Dequeue(ctx)
+
+	assert.Nil(t, delivery)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, 1, queue.Len())
+}
+
+// TestMemoryDeadLetters tests storing the failed deliveries.
+func TestMemoryDeadLetters(t *testing.T) {
+	deadLetters := NewMemoryDeadLetters()
+	delivery := &Delivery{ID: "failed"}
+
+	assert.NoError(t, deadLetters.DeadLetter(context.Background(), delivery))
+	assert.Equal(t, []*Delivery{delivery}, deadLetters.Deliveries())
+}
@@ -0,0 +1,73 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/pakkasys/fluidapi/core/client"
+)
+
+var (
+	// ErrInvalidSignature is returned for a missing or invalid signature.
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	// ErrSignatureExpired is returned for a signature older than the
+	// tolerance.
+	ErrSignatureExpired = errors.New("webhook signature expired")
+)
+
+// Sign returns the value of the signature header of a payload, in the form
+// "t=<unix timestamp>,v1=<hex HMAC-SHA256 of "<timestamp>.<payload>">".
+//
+//   - secret: The secret of the subscription.
+//   - timestamp: The time of the request.
+//   - payload: The request body.
+func Sign(secret []byte, timestamp time.Time, payload []byte) string {
+	t := strconv.FormatInt(timestamp.Unix(), 10)
+	return "t=" + t + ",v1=" + signature(secret, t, payload)
+}
+
+// Verify verifies the signature header of a received webhook request.
+//
+//   - secret: The secret of the subscription.
+//   - header: The value of the signature header.
+//   - payload: The request body.
+//   - tolerance: The maximum age of the signature, or zero for any age.
+//   - now: The current time.
+//
+// Example:
+//
+//	err := webhook.Verify(secret, r.Header.Get(webhook.SignatureHeader),
+//		body, 5*time.Minute, time.Now())
+func Verify(
+	secret []byte,
+	header string,
+	payload []byte,
+	tolerance time.Duration,
+	now time.Time,
+) error {
+	parsed, ok := client.ParseSignatureHeader(header)
+	if !ok {
+		return ErrInvalidSignature
+	}
+
+	expected := signature(secret, parsed.Timestamp, payload)
+	if !hmac.Equal([]byte(parsed.Signature), []byte(expected)) {
+		return ErrInvalidSignature
+	}
+	if tolerance > 0 && now.Sub(parsed.Time) > tolerance {
+		return ErrSignatureExpired
+	}
+	return nil
+}
+
+func signature(secret []byte, timestamp string, payload []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,80 @@
+package webhook
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestSign tests signing a payload.
+func TestSign(t *testing.T) {
+	header := Sign([]byte("secret"), time.Unix(1700000000, 0), []byte("{}"))
+
+	assert.Regexp(t, `^t=1700000000,v1=[0-9a-f]{64}$`, header)
+}
+
+// TestVerify tests verifying the signatures of payloads.
+func TestVerify(t *testing.T) {
+	secret := []byte("secret")
+	signedAt := time.Unix(1700000000, 0)
+	payload := []byte(`{"id":"1"}`)
+	header := Sign(secret, signedAt, payload)
+
+	tests := []struct {
+		name     string
+		secret   []byte
+		header   string
+		payload  []byte
+		now      time.Time
+		expected error
+	}{
+		{"valid", secret, header, payload, signedAt.Add(time.Minute), nil},
+		{
+			"wrong secret",
+			[]byte("other"),
+			header,
+			payload,
+			signedAt,
+			ErrInvalidSignature,
+		},
+		{
+			"modified payload",
+			secret,
+			header,
+			[]byte("{}"),
+			signedAt,
+			ErrInvalidSignature,
+		},
+		{"missing header", secret, "", payload, signedAt, ErrInvalidSignature},
+		{
+			"missing signature",
+			secret,
+			"t=1700000000",
+			payload,
+			signedAt,
+			ErrInvalidSignature,
+		},
+		{
+			"expired",
+			secret,
+			header,
+			payload,
+			signedAt.Add(time.Hour),
+			ErrSignatureExpired,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := Verify(
+				test.secret,
+				test.header,
+				test.payload,
+				5*time.Minute,
+				test.now,
+			)
+			assert.Equal(t, test.expected, err)
+		})
+	}
+}
@@ -0,0 +1,401 @@
+// Package webhook delivers events to the URLs of subscribers with signed HTTP
+// POST requests. Failed deliveries are retried with backoff and moved to a
+// dead letter queue after the last attempt.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/pakkasys/fluidapi/core/backoff"
+	"github.com/pakkasys/fluidapi/core/logging"
+	"github.com/pakkasys/fluidapi/database/idgen"
+	"github.com/pakkasys/fluidapi/database/transaction"
+)
+
+const (
+	// AllEvents subscribes to all event types.
+	AllEvents = "*"
+
+	// IDHeader contains the ID of the event.
+	IDHeader = "X-Webhook-ID"
+	// EventHeader contains the type of the event.
+	EventHeader = "X-Webhook-Event"
+	// SignatureHeader contains the timestamp and the signature of the
+	// request.
+	SignatureHeader = "X-Webhook-Signature"
+
+	defaultMaxAttempts = 5
+	defaultTimeout     = 10 * time.Second
+)
+
+// Event is a published event. It is sent to the subscribers in JSON.
+type Event struct {
+	ID        string    `json:"id"`
+	Type      string    `json:"type"`
+	CreatedAt time.Time `json:"created_at"`
+	Data      any       `json:"data"`
+}
+
+// Subscription is a subscriber URL of event types.
+type Subscription struct {
+	// ID of the subscription.
+	ID string
+	// URL receiving the events.
+	URL string
+	// Secret signing the requests. Optional.
+	Secret string
+	// EventTypes of the subscription, or AllEvents.
+	EventTypes []string
+}
+
+// Delivery is a queued delivery of an event to a subscriber.
+type Delivery struct {
+	ID             string    `json:"id"`
+	EventID        string    `json:"event_id"`
+	EventType      string    `json:"event_type"`
+	SubscriptionID string    `json:"subscription_id"`
+	URL            string    `json:"url"`
+	Secret         string    `json:"secret"`
+	Payload        []byte    `json:"payload"`
+	Attempt        int       `json:"attempt"`
+	NextAttempt    time.Time `json:"next_attempt"`
+	LastError      string    `json:"last_error"`
+}
+
+// Config configures the webhook manager.
+type Config struct {
+	// Queue stores the pending deliveries. Defaults to a MemoryQueue.
+	Queue Queue
+	// DeadLetters stores the deliveries that failed on the last attempt.
+	// Optional, the failed deliveries are logged if nil.
+	DeadLetters DeadLetterQueue
+	// Client sends the requests. Defaults to a client with a timeout of 10
+	// seconds.
+	Client *http.Client
+	// MaxAttempts is the number of attempts of a delivery. Defaults to 5.
+	MaxAttempts int
+	// Backoff returns the delay before the next attempt after the given
+	// number of failed attempts. Defaults to an exponential backoff starting
+	// from one second and capped at one hour.
+	Backoff func(attempt int) time.Duration
+	// Workers is the number of concurrent senders. Defaults to 1.
+	Workers int
+	// IDGenerator generates the IDs of the events and the deliveries.
+	// Defaults to UUIDv7.
+	IDGenerator idgen.Generator[string]
+	// Logger logs the failed deliveries. Optional.
+	Logger logging.Logger
+}
+
+// Manager publishes events to the subscribers and delivers them. It is safe
+// for concurrent use.
+type Manager struct {
+	config        Config
+	mu            sync.RWMutex
+	subscriptions []Subscription
+	nowFn         func() time.Time
+}
+
+// NewManager creates a new webhook manager. The deliveries are sent by Run.
+//
+//   - config: The configuration of the manager.
+func NewManager(config Config) *Manager {
+	if config.Queue == nil {
+		config.Queue = NewMemoryQueue()
+	}
+	if config.Client == nil {
+		config.Client = &http.Client{Timeout: defaultTimeout}
+	}
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = defaultMaxAttempts
+	}
+	if config.Backoff == nil {
+		config.Backoff = backoff.Exponential
+	}
+	if config.Workers <= 0 {
+		config.Workers = 1
+	}
+	if config.IDGenerator == nil {
+		config.IDGenerator = idgen.NewUUIDv7Generator()
+	}
+	if config.Logger == nil {
+		config.Logger = logging.NopLogger{}
+	}
+	return &Manager{config: config, nowFn: time.Now}
+}
+
+// Subscribe adds a subscription. A subscription with the same ID is
+// replaced.
+//
+//   - subscription: The subscription.
+func (m *Manager) Subscribe(subscription Subscription) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.subscriptions = slices.DeleteFunc(
+		m.subscriptions,
+		func(s Subscription) bool { return s.ID == subscription.ID },
+	)
+	m.subscriptions = append(m.subscriptions, subscription)
+}
+
+// Unsubscribe removes a subscription.
+//
+//   - id: The ID of the subscription.
+func (m *Manager) Unsubscribe(id string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.subscriptions = slices.DeleteFunc(
+		m.subscriptions,
+		func(s Subscription) bool { return s.ID == id },
+	)
+}
+
+// Subscriptions returns the subscriptions of an event type.
+//
+//   - eventType: The type of the event.
+func (m *Manager) Subscriptions(eventType string) []Subscription {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	subscriptions := []Subscription{}
+	for _, subscription := range m.subscriptions {
+		if slices.Contains(subscription.EventTypes, eventType) ||
+			slices.Contains(subscription.EventTypes, AllEvents) {
+			subscriptions = append(subscriptions, subscription)
+		}
+	}
+	return subscriptions
+}
+
+// Publish enqueues a delivery of the event for each subscription of its
+// type.
+//
+//   - ctx: The context of the operation.
+//   - eventType: The type of the event, e.g. "user.created".
+//   - data: The data of the event, which must be JSON marshalable.
+func (m *Manager) Publish(
+	ctx context.Context,
+	eventType string,
+	data any,
+) error {
+	subscriptions := m.Subscriptions(eventType)
+	if len(subscriptions) == 0 {
+		return nil
+	}
+
+	eventID, err := m.config.IDGenerator.NewID()
+	if err != nil {
+		return err
+	}
+	payload, err := json.Marshal(Event{
+		ID:        eventID,
+		Type:      eventType,
+		CreatedAt: m.nowFn().UTC(),
+		Data:      data,
+	})
+	if err != nil {
+		return fmt.Errorf("webhook: %w", err)
+	}
+
+	var errs []error
+	for _, subscription := range subscriptions {
+		deliveryID, err := m.config.IDGenerator.NewID()
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		err = m.config.Queue.Enqueue(ctx, &Delivery{
+			ID:             deliveryID,
+			EventID:        eventID,
+			EventType:      eventType,
+			SubscriptionID: subscription.ID,
+			URL:            subscription.URL,
+			Secret:         subscription.Secret,
+			Payload:        payload,
+		})
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// PublishAfterCommit publishes the event after the transaction of the
+// context commits, so that no events are sent for rolled back changes.
+// Publish errors are logged.
+//
+// The transaction must be started with a context created by
+// endpointutil.NewContext, as with transaction.RegisterAfterCommit.
+//
+//   - ctx: The context of the transaction.
+//   - eventType: The type of the event, e.g. "user.created".
+//   - data: The data of the event, which must be JSON marshalable.
+//
+// Returns transaction.ErrNoTransactionScope if the context has no
+// transaction started with a custom context.
+func (m *Manager) PublishAfterCommit(
+	ctx context.Context,
+	eventType string,
+	data any,
+) error {
+	return transaction.RegisterAfterCommit(ctx, func(ctx context.Context) {
+		m.publishAndLog(ctx, eventType, data)
+	})
+}
+
+// publishAndLog publishes the event and logs the publish errors.
+func (m *Manager) publishAndLog(
+	ctx context.Context,
+	eventType string,
+	data any,
+) {
+	if err := m.Publish(ctx, eventType, data); err != nil {
+		m.config.Logger.Error(
+			ctx,
+			"Webhook publish error",
+			"event_type", eventType,
+			"error", err,
+		)
+	}
+}
+
+// Redeliver enqueues a delivery again with its attempts reset, e.g. a
+// delivery of the dead letter queue.
+//
+//   - ctx: The context of the operation.
+//   - delivery: The delivery.
+func (m *Manager) Redeliver(ctx context.Context, delivery *Delivery) error {
+	redelivery := *delivery
+	redelivery.Attempt = 0
+	redelivery.NextAttempt = time.Time{}
+	redelivery.LastError = ""
+	return m.config.Queue.Enqueue(ctx, &redelivery)
+}
+
+// Run sends the queued deliveries until the context is done.
+//
+//   - ctx: The context stopping the workers.
+func (m *Manager) Run(ctx context.Context) error {
+	var wg sync.WaitGroup
+	for range m.config.Workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			m.work(ctx)
+		}()
+	}
+	wg.Wait()
+	return ctx.Err()
+}
+
+func (m *Manager) work(ctx context.Context) {
+	for {
+		delivery, err := m.config.Queue.Dequeue(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			m.config.Logger.Error(ctx, "Webhook queue error", "error", err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(time.Second):
+			}
+			continue
+		}
+		m.deliver(ctx, delivery)
+	}
+}
+
+// deliver sends the delivery, and enqueues it for a retry or moves it to the
+// dead letter queue if it fails.
+func (m *Manager) deliver(ctx context.Context, delivery *Delivery) {
+	delivery.Attempt++
+	err := m.send(ctx, delivery)
+	if err == nil {
+		return
+	}
+	delivery.LastError = err.Error()
+
+	if delivery.Attempt >= m.config.MaxAttempts {
+		m.deadLetter(ctx, delivery)
+		return
+	}
+	delivery.NextAttempt = m.nowFn().Add(m.config.Backoff(delivery.Attempt))
+	if err := m.config.Queue.Enqueue(ctx, delivery); err != nil {
+		m.config.Logger.Error(
+			ctx,
+			"Webhook queue error",
+			"delivery_id", delivery.ID,
+			"error", err,
+		)
+	}
+}
+
+func (m *Manager) send(ctx context.Context, delivery *Delivery) error {
+	request, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodPost,
+		delivery.URL,
+		bytes.NewReader(delivery.Payload),
+	)
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(IDHeader, delivery.EventID)
+	request.Header.Set(EventHeader, delivery.EventType)
+	if delivery.Secret != "" {
+		request.Header.Set(SignatureHeader, Sign(
+			[]byte(delivery.Secret),
+			m.nowFn(),
+			delivery.Payload,
+		))
+	}
+
+	response, err := m.config.Client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(response.Body, 64<<10))
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("unexpected status code: %d", response.StatusCode)
+	}
+	return nil
+}
+
+func (m *Manager) deadLetter(ctx context.Context, delivery *Delivery) {
+	m.config.Logger.Error(
+		ctx,
+		"Webhook delivery failed",
+		"delivery_id", delivery.ID,
+		"event_type", delivery.EventType,
+		"subscription_id", delivery.SubscriptionID,
+		"attempts", delivery.Attempt,
+		"error", delivery.LastError,
+	)
+	if m.config.DeadLetters == nil {
+		return
+	}
+	if err := m.config.DeadLetters.DeadLetter(ctx, delivery); err != nil {
+		m.config.Logger.Error(
+			ctx,
+			"Webhook dead letter error",
+			"delivery_id", delivery.ID,
+			"error", err,
+		)
+	}
+}
@@ -0,0 +1,281 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pakkasys/fluidapi/core/logging/mock"
+	"github.com/pakkasys/fluidapi/database/idgen"
+	"github.com/pakkasys/fluidapi/database/transaction"
+	"github.com/pakkasys/fluidapi/database/util"
+	utilmock "github.com/pakkasys/fluidapi/database/util/mock"
+	endpointutil "github.com/pakkasys/fluidapi/endpoint/util"
+	"github.com/stretchr/testify/assert"
+)
+
+var testTime = time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+// sequentialIDs returns a generator of the IDs "1", "2", ...
+func sequentialIDs() idgen.Generator[string] {
+	var mu sync.Mutex
+	next := 0
+	return idgen.GeneratorFunc[string](func() (string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		next++
+		return strconv.Itoa(next), nil
+	})
+}
+
+func testManager(config Config) (*Manager, *MemoryQueue) {
+	queue := NewMemoryQueue()
+	config.Queue = queue
+	config.IDGenerator = sequentialIDs()
+	manager := NewManager(config)
+	manager.nowFn = func() time.Time { return testTime }
+	return manager, queue
+}
+
+// receivedRequest is a request received by the test subscriber.
+type receivedRequest struct {
+	header http.Header
+	body   []byte
+}
+
+// testSubscriber returns a server answering with the statuses in order and
+// the channel of the received requests.
+func testSubscriber(
+	t *testing.T,
+	statuses ...int,
+) (*httptest.Server, chan receivedRequest) {
+	received := make(chan receivedRequest, 10)
+	var mu sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			mu.Lock()
+			status := http.StatusOK
+			if len(statuses) != 0 {
+				status, statuses = statuses[0], statuses[1:]
+			}
+			mu.Unlock()
+			w.WriteHeader(status)
+			received <- receivedRequest{header: r.Header.Clone(), body: body}
+		},
+	))
+	t.Cleanup(server.Close)
+	return server, received
+}
+
+// TestManager_Subscriptions tests the subscriptions of event types.
+func TestManager_Subscriptions(t *testing.T) {
+	manager, _ := testManager(Config{})
+	manager.Subscribe(Subscription{ID: "a", EventTypes: []string{"x"}})
+	manager.Subscribe(Subscription{ID: "b", EventTypes: []string{AllEvents}})
+	manager.Subscribe(Subscription{ID: "c", EventTypes: []string{"y"}})
+	manager.Subscribe(Subscription{ID: "c", EventTypes: []string{"x"}})
+
+	ids := func(subscriptions []Subscription) []string {
+		result := []string{}
+		for _, subscription := range subscriptions {
+			result = append(result, subscription.ID)
+		}
+		return result
+	}
+	assert.Equal(t, []string{"a", "b", "c"}, ids(manager.Subscriptions("x")))
+	assert.Equal(t, []string{"b"}, ids(manager.Subscriptions("y")))
+
+	manager.Unsubscribe("a")
+	assert.Equal(t, []string{"b", "c"}, ids(manager.Subscriptions("x")))
+}
+
+// TestManager_Publish tests enqueueing a delivery for each subscription.
+func TestManager_Publish(t *testing.T) {
+	manager, queue := testManager(Config{})
+	manager.Subscribe(Subscription{
+		ID:         "sub",
+		URL:        "http://example.com",
+		Secret:     "secret",
+		EventTypes: []string{"user.created"},
+	})
+
+	assert.NoError(t, manager.Publish(context.Background(), "other", 1))
+	assert.Equal(t, 0, queue.Len())
+
+	err := manager.Publish(
+		context.Background(),
+		"user.created",
+		map[string]any{"id": 1},
+	)
+
+	assert.NoError(t, err)
+	delivery, err := queue.Dequeue(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, &Delivery{
+		ID:             "2",
+		EventID:        "1",
+		EventType:      "user.created",
+		SubscriptionID: "sub",
+		URL:            "http://example.com",
+		Secret:         "secret",
+		Payload: []byte(`{"id":"1","type":"user.created",` +
+			`"created_at":"2024-01-02T03:04:05Z","data":{"id":1}}`),
+	}, delivery)
+}
+
+// TestManager_Publish_Errors tests the errors of encoding the event and of
+// the queue.
+func TestManager_Publish_Errors(t *testing.T) {
+	manager, _ := testManager(Config{})
+	manager.Subscribe(Subscription{ID: "sub", EventTypes: []string{"x"}})
+
+	err := manager.Publish(context.Background(), "x", func() {})
+	assert.ErrorContains(t, err, "webhook:")
+
+	queueErr := errors.New("queue error")
+	manager.config.Queue = &failingQueue{err: queueErr}
+	err = manager.Publish(context.Background(), "x", 1)
+	assert.ErrorIs(t, err, queueErr)
+}
+
+// TestManager_Run tests sending a signed delivery.
+func TestManager_Run(t *testing.T) {
+	server, received := testSubscriber(t)
+	manager, _ := testManager(Config{})
+	manager.Subscribe(Subscription{
+		ID:         "sub",
+		URL:        server.URL,
+		Secret:     "secret",
+		EventTypes: []string{"user.created"},
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- manager.Run(ctx) }()
+
+	assert.NoError(t, manager.Publish(ctx, "user.created", "data"))
+	request := <-received
+	cancel()
+
+	assert.ErrorIs(t, <-done, context.Canceled)
+	assert.Equal(t, "1", request.header.Get(IDHeader))
+	assert.Equal(t, "user.created", request.header.Get(EventHeader))
+	assert.Equal(t, "application/json", request.header.Get("Content-Type"))
+	assert.NoError(t, Verify(
+		[]byte("secret"),
+		request.header.Get(SignatureHeader),
+		request.body,
+		0,
+		testTime,
+	))
+	var event Event
+	assert.NoError(t, json.Unmarshal(request.body, &event))
+	assert.Equal(t, "data", event.Data)
+}
+
+// TestManager_Retry tests retrying failed deliveries and moving them to the
+// dead letter queue after the last attempt.
+func TestManager_Retry(t *testing.T) {
+	server, received := testSubscriber(
+		t,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+	)
+	logger := &mock.Recorder{}
+	deadLetters := NewMemoryDeadLetters()
+	manager, queue := testManager(Config{
+		MaxAttempts: 2,
+		Backoff:     func(attempt int) time.Duration { return 0 },
+		DeadLetters: deadLetters,
+		Logger:      logger,
+	})
+	manager.Subscribe(Subscription{
+		ID:         "sub",
+		URL:        server.URL,
+		EventTypes: []string{"x"},
+	})
+	assert.NoError(t, manager.Publish(context.Background(), "x", 1))
+
+	delivery, _ := queue.Dequeue(context.Background())
+	manager.deliver(context.Background(), delivery)
+	<-received
+	assert.Equal(t, 1, queue.Len())
+	assert.Equal(t, "unexpected status code: 500", delivery.LastError)
+
+	delivery, _ = queue.Dequeue(context.Background())
+	manager.deliver(context.Background(), delivery)
+	<-received
+	assert.Equal(t, 0, queue.Len())
+	assert.Equal(t, []*Delivery{delivery}, deadLetters.Deliveries())
+	assert.Equal(t, 2, delivery.Attempt)
+	assert.Equal(t, []string{"Webhook delivery failed"}, logger.Messages())
+
+	assert.NoError(t, manager.Redeliver(context.Background(), delivery))
+	redelivery, _ := queue.Dequeue(context.Background())
+	assert.Equal(t, 0, redelivery.Attempt)
+	assert.Empty(t, redelivery.LastError)
+	manager.deliver(context.Background(), redelivery)
+	<-received
+	assert.Equal(t, 0, queue.Len())
+}
+
+// TestManager_PublishAfterCommit tests that the events of rolled back
+// transactions are not published.
+func TestManager_PublishAfterCommit(t *testing.T) {
+	manager, queue := testManager(Config{})
+	manager.Subscribe(Subscription{ID: "sub", EventTypes: []string{"x"}})
+	mockTx := new(utilmock.MockTx)
+	mockTx.On("Rollback").Return(nil).Once()
+	mockTx.On("Commit").Return(nil).Once()
+	ctx := endpointutil.NewContext(context.Background())
+
+	_, err := transaction.ExecuteManagedTransaction(
+		ctx,
+		func(ctx context.Context) (util.Tx, error) { return mockTx, nil },
+		func(ctx context.Context, tx util.Tx) (int, error) {
+			assert.NoError(t, manager.PublishAfterCommit(ctx, "x", 1))
+			return 0, errors.New("rollback")
+		},
+	)
+	assert.Error(t, err)
+	assert.Equal(t, 0, queue.Len())
+
+	_, err = transaction.ExecuteManagedTransaction(
+		ctx,
+		func(ctx context.Context) (util.Tx, error) { return mockTx, nil },
+		func(ctx context.Context, tx util.Tx) (int, error) {
+			err := manager.PublishAfterCommit(ctx, "x", 1)
+			assert.Equal(t, 0, queue.Len())
+			return 0, err
+		},
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, queue.Len())
+
+	assert.ErrorIs(
+		t,
+		manager.PublishAfterCommit(ctx, "x", 1),
+		transaction.ErrNoTransactionScope,
+	)
+	assert.Equal(t, 1, queue.Len())
+}
+
+// failingQueue is a queue returning an error.
+type failingQueue struct {
+	err error
+}
+
+func (q *failingQueue) Enqueue(ctx context.Context, delivery *Delivery) error {
+	return q.err
+}
+
+func (q *failingQueue) Dequeue(ctx context.Context) (*Delivery, error) {
+	return nil, q.err
+}